@@ -0,0 +1,281 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c88032111/go-gdtu"
+	"github.com/c88032111/go-gdtu/accounts/abi"
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/log"
+)
+
+const (
+	defaultBackfillChunk   = 2000        // Blocks queried per FilterLogs request by default
+	defaultBackfillRetries = 5           // Attempts of a chunk query before giving up by default
+	defaultBackfillDelay   = time.Second // Delay before the first retry of a chunk query by default
+)
+
+// ErrNoHeadReader is returned by a backfill without an end block if the backend
+// can't report the head of the chain.
+var ErrNoHeadReader = errors.New("backend can't retrieve the chain head, backfill end required")
+
+// headReader is implemented by backends able to retrieve the head of the chain.
+type headReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// limitErrors are fragments of the errors returned by providers refusing a log
+// query for spanning too many blocks or results.
+var limitErrors = []string{
+	"query returned more than",
+	"limit exceeded",
+	"too many",
+	"response size",
+	"block range",
+	"query timeout",
+}
+
+// isLimitError returns whether a log query failed because its range was too large.
+func isLimitError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range limitErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// LogID identifies a log across reorgs, which may move the transaction emitting
+// it to another block and shift the log index within the block.
+type LogID struct {
+	TxHash common.Hash `json:"txHash"` // Hash of the transaction emitting the log
+	Index  uint        `json:"index"`  // Position of the log among those of the transaction
+}
+
+// DeliveredLog is a log delivered by a backfill.
+type DeliveredLog struct {
+	LogID
+	Block uint64 `json:"block"` // Number of the block containing the log when delivered
+}
+
+// BackfillCursor is the progress of an event backfill. It is reported after each
+// chunk of blocks through BackfillOpts.Checkpoint, and may be persisted to resume
+// the backfill later on through BackfillOpts.Resume.
+type BackfillCursor struct {
+	Next   uint64         `json:"next"`   // First block not backfilled yet
+	Recent []DeliveredLog `json:"recent"` // Logs delivered within the reorg depth
+}
+
+// backfill is the progress of a running event backfill.
+type backfill struct {
+	next   uint64
+	recent map[LogID]uint64 // Logs delivered within the reorg depth, with their block numbers
+}
+
+// cursor returns the progress of the backfill.
+func (b *backfill) cursor() *BackfillCursor {
+	cursor := &BackfillCursor{Next: b.next}
+	for id, number := range b.recent {
+		cursor.Recent = append(cursor.Recent, DeliveredLog{LogID: id, Block: number})
+	}
+	sort.Slice(cursor.Recent, func(i, j int) bool {
+		return cursor.Recent[i].Block < cursor.Recent[j].Block
+	})
+	return cursor
+}
+
+// BackfillOpts is the collection of options to fine tune the backfill of events
+// within a bound contract.
+type BackfillOpts struct {
+	Start uint64  // First block of the backfill, unless resumed
+	End   *uint64 // Last block of the backfill (nil = head of the chain at the start)
+
+	Resume     *BackfillCursor            // Progress of an earlier backfill to resume (nil = start afresh)
+	Checkpoint func(BackfillCursor) error // Callback invoked with the progress after each chunk (nil = none)
+
+	ChunkSize  uint64        // Blocks queried per request, halved when the provider refuses (0 = 2000)
+	Retries    int           // Attempts of a chunk query before giving up (0 = 5)
+	RetryDelay time.Duration // Delay before the first retry, doubled for each further one (0 = 1s)
+	ReorgDepth uint64        // Blocks re-queried before each chunk, catching logs moved by reorgs
+
+	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
+}
+
+// BackfillLogs retrieves the historical events of a bound contract in a range of
+// blocks, handing them in chain order to the handler. The range is queried in
+// chunks, split further when the provider refuses queries over too many blocks
+// or results, and retried with backoff on other failures.
+//
+// Logs are delivered at least once: after a failure, the backfill resumed from
+// the last checkpoint re-delivers the logs of the chunk in progress. Logs moved
+// into another block by a reorg within the reorg depth aren't delivered twice,
+// however logs dropped by a reorg aren't retracted, so the end of the range is
+// best kept some confirmations behind the head.
+func (c *BoundContract) BackfillLogs(opts *BackfillOpts, name string, handler func(types.Log) error, query ...[]interface{}) (*BackfillCursor, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(BackfillOpts)
+	}
+	ctx := ensureContext(opts.Context)
+
+	// Append the event selector to the query parameters and construct the topic set
+	query = append([][]interface{}{{c.abi.Events[name].ID}}, query...)
+
+	topics, err := abi.MakeTopics(query...)
+	if err != nil {
+		return nil, err
+	}
+	// Resolve the range of the backfill and the tuning parameters
+	var end uint64
+	if opts.End != nil {
+		end = *opts.End
+	} else {
+		reader, ok := c.filterer.(headReader)
+		if !ok {
+			return nil, ErrNoHeadReader
+		}
+		head, err := reader.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		end = head.Number.Uint64()
+	}
+	progress := &backfill{next: opts.Start, recent: make(map[LogID]uint64)}
+	if opts.Resume != nil {
+		progress.next = opts.Resume.Next
+		for _, delivered := range opts.Resume.Recent {
+			progress.recent[delivered.LogID] = delivered.Block
+		}
+	}
+	chunkSize, retries, delay := opts.ChunkSize, opts.Retries, opts.RetryDelay
+	if chunkSize == 0 {
+		chunkSize = defaultBackfillChunk
+	}
+	if retries <= 0 {
+		retries = defaultBackfillRetries
+	}
+	if delay == 0 {
+		delay = defaultBackfillDelay
+	}
+	// Query the range chunk by chunk, shrinking the chunks when the provider
+	// refuses them and growing them back after successes
+	size := chunkSize
+	for progress.next <= end {
+		from, to := progress.next, progress.next+size-1
+		if to > end || to < from {
+			to = end
+		}
+		if from > opts.Start+opts.ReorgDepth {
+			from -= opts.ReorgDepth
+		} else if opts.ReorgDepth > 0 {
+			from = opts.Start
+		}
+		logs, err := c.filterChunk(ctx, topics, from, to, retries, delay)
+		if err != nil {
+			if isLimitError(err) && size > 1 {
+				size /= 2
+				log.Debug("Shrinking event backfill chunk", "contract", c.address, "event", name, "size", size, "err", err)
+				continue
+			}
+			return progress.cursor(), err
+		}
+		if err := progress.deliver(logs, to+1, opts.ReorgDepth, handler); err != nil {
+			return progress.cursor(), err
+		}
+		if opts.Checkpoint != nil {
+			if err := opts.Checkpoint(*progress.cursor()); err != nil {
+				return progress.cursor(), err
+			}
+		}
+		if size < chunkSize {
+			if size *= 2; size > chunkSize {
+				size = chunkSize
+			}
+		}
+	}
+	return progress.cursor(), nil
+}
+
+// filterChunk queries the logs of a chunk of blocks, retrying failures with
+// exponential backoff. Refusals over the size of the chunk are returned right
+// away, to be retried with a smaller one.
+func (c *BoundContract) filterChunk(ctx context.Context, topics [][]common.Hash, from, to uint64, retries int, delay time.Duration) ([]types.Log, error) {
+	query := gdtu.FilterQuery{
+		Addresses: []common.Address{c.address},
+		Topics:    topics,
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+	}
+	for attempt := 1; ; attempt++ {
+		logs, err := c.filterer.FilterLogs(ctx, query)
+		if err == nil || isLimitError(err) || attempt >= retries {
+			return logs, err
+		}
+		log.Debug("Retrying event backfill query", "contract", c.address, "from", from, "to", to, "attempt", attempt, "err", err)
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// deliver hands the logs of a chunk not delivered before to the handler, and
+// advances the cursor to the next block, forgetting the logs delivered before
+// the reorg depth.
+func (b *backfill) deliver(logs []types.Log, next uint64, depth uint64, handler func(types.Log) error) error {
+	var (
+		block common.Hash
+		tx    common.Hash
+		index uint
+	)
+	for _, l := range logs {
+		// Number the logs within their transaction, which survives reorgs
+		if l.BlockHash != block || l.TxHash != tx {
+			block, tx, index = l.BlockHash, l.TxHash, 0
+		} else {
+			index++
+		}
+		id := LogID{TxHash: l.TxHash, Index: index}
+		if _, ok := b.recent[id]; ok {
+			continue
+		}
+		if err := handler(l); err != nil {
+			return err
+		}
+		if depth > 0 {
+			b.recent[id] = l.BlockNumber
+		}
+	}
+	b.next = next
+	for id, number := range b.recent {
+		if number+depth < next {
+			delete(b.recent, id)
+		}
+	}
+	return nil
+}
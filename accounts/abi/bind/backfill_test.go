@@ -0,0 +1,183 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu"
+	"github.com/c88032111/go-gdtu/accounts/abi"
+	"github.com/c88032111/go-gdtu/accounts/abi/bind"
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+)
+
+// mockFilterer serves the logs of a chain with one event every few blocks, refusing
+// queries over too many blocks and failing some queries transiently.
+type mockFilterer struct {
+	logs     []types.Log
+	head     uint64
+	maxRange uint64 // Largest range of blocks served in one query
+	failures int    // Number of queries to fail before serving
+	queries  int
+}
+
+func newMockFilterer(head uint64, every uint64) *mockFilterer {
+	f := &mockFilterer{head: head}
+	for number := uint64(0); number <= head; number += every {
+		f.logs = append(f.logs, mockLog(number, number, 0))
+	}
+	return f
+}
+
+// mockLog creates the log of the given transaction in a block.
+func mockLog(number uint64, tx uint64, index uint) types.Log {
+	return types.Log{
+		BlockNumber: number,
+		BlockHash:   common.BigToHash(new(big.Int).SetUint64(number + 1)),
+		TxHash:      common.BigToHash(new(big.Int).SetUint64(tx + 1)),
+		Index:       index,
+	}
+}
+
+func (f *mockFilterer) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(f.head)}, nil
+}
+
+func (f *mockFilterer) FilterLogs(ctx context.Context, query gdtu.FilterQuery) ([]types.Log, error) {
+	f.queries++
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	if f.maxRange > 0 && to-from+1 > f.maxRange {
+		return nil, fmt.Errorf("query returned more than %d results", f.maxRange)
+	}
+	if f.failures > 0 {
+		f.failures--
+		return nil, errors.New("connection reset")
+	}
+	var logs []types.Log
+	for _, l := range f.logs {
+		if l.BlockNumber >= from && l.BlockNumber <= to {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (f *mockFilterer) SubscribeFilterLogs(ctx context.Context, query gdtu.FilterQuery, ch chan<- types.Log) (gdtu.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestBackfillLogs(t *testing.T) {
+	filterer := newMockFilterer(999, 10)
+	filterer.maxRange, filterer.failures = 100, 2
+
+	contract := bind.NewBoundContract(common.Address{0xde, 0xad}, abi.ABI{}, nil, nil, filterer)
+
+	var (
+		delivered   []uint64
+		checkpoints int
+		last        bind.BackfillCursor
+	)
+	opts := &bind.BackfillOpts{
+		ChunkSize:  500,
+		RetryDelay: time.Millisecond,
+		Checkpoint: func(cursor bind.BackfillCursor) error {
+			checkpoints++
+			last = cursor
+			return nil
+		},
+	}
+	handler := func(l types.Log) error {
+		delivered = append(delivered, l.BlockNumber)
+		return nil
+	}
+	cursor, err := contract.BackfillLogs(opts, "", handler)
+	if err != nil {
+		t.Fatalf("backfill failed: %v", err)
+	}
+	if cursor.Next != 1000 || last.Next != 1000 {
+		t.Fatalf("wrong cursor: have %d (checkpoint %d), want %d", cursor.Next, last.Next, 1000)
+	}
+	if len(delivered) != 100 {
+		t.Fatalf("wrong number of logs delivered: have %d, want %d", len(delivered), 100)
+	}
+	for i, number := range delivered {
+		if number != uint64(i*10) {
+			t.Fatalf("log %d delivered out of order: block %d", i, number)
+		}
+	}
+	if checkpoints < 10 {
+		t.Fatalf("too few checkpoints for refused chunk size: %d", checkpoints)
+	}
+}
+
+func TestBackfillLogsRetriesExhausted(t *testing.T) {
+	filterer := newMockFilterer(99, 10)
+	filterer.failures = 3
+
+	contract := bind.NewBoundContract(common.Address{0xde, 0xad}, abi.ABI{}, nil, nil, filterer)
+	opts := &bind.BackfillOpts{Retries: 3, RetryDelay: time.Millisecond}
+	if _, err := contract.BackfillLogs(opts, "", func(types.Log) error { return nil }); err == nil {
+		t.Fatal("backfill succeeded despite failing queries")
+	}
+	if filterer.queries != 3 {
+		t.Fatalf("wrong number of attempts: have %d, want %d", filterer.queries, 3)
+	}
+}
+
+func TestBackfillLogsResumeAcrossReorg(t *testing.T) {
+	filterer := newMockFilterer(99, 5)
+	contract := bind.NewBoundContract(common.Address{0xde, 0xad}, abi.ABI{}, nil, nil, filterer)
+
+	var delivered []types.Log
+	handler := func(l types.Log) error {
+		delivered = append(delivered, l)
+		return nil
+	}
+	end := uint64(99)
+	cursor, err := contract.BackfillLogs(&bind.BackfillOpts{End: &end, ChunkSize: 30, ReorgDepth: 5}, "", handler)
+	if err != nil {
+		t.Fatalf("backfill failed: %v", err)
+	}
+	// Persist the cursor and reorg the last blocks, moving the transaction of
+	// block 95 into block 97 and adding a new one.
+	blob, err := json.Marshal(cursor)
+	if err != nil {
+		t.Fatalf("failed to encode cursor: %v", err)
+	}
+	var resume bind.BackfillCursor
+	if err := json.Unmarshal(blob, &resume); err != nil {
+		t.Fatalf("failed to decode cursor: %v", err)
+	}
+	filterer.logs = filterer.logs[:len(filterer.logs)-1]
+	filterer.logs = append(filterer.logs, mockLog(97, 95, 0), mockLog(98, 1000, 0), mockLog(105, 105, 0))
+	filterer.head = 109
+
+	delivered = nil
+	if _, err := contract.BackfillLogs(&bind.BackfillOpts{Resume: &resume, ReorgDepth: 5}, "", handler); err != nil {
+		t.Fatalf("resumed backfill failed: %v", err)
+	}
+	if len(delivered) != 2 || delivered[0].BlockNumber != 98 || delivered[1].BlockNumber != 105 {
+		t.Fatalf("wrong logs delivered after reorg: %v", delivered)
+	}
+}
@@ -17,15 +17,70 @@
 package abi
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/crypto"
 )
 
 var (
 	errBadBool = errors.New("abi: improperly encoded boolean value")
 )
 
+// Error is a custom error declared by a contract (introduced in solidity v0.8.4),
+// returned abi-encoded as the revert data, prefixed with its 4-byte selector.
+type Error struct {
+	Name   string
+	Inputs Arguments
+	str    string
+	// Sig contains the string signature according to the ABI spec.
+	// e.g.	 error foo(uint32 a, int b) = "foo(uint32,int256)"
+	// Please note that "int" is substitute for its canonical representation "int256"
+	Sig string
+	// ID returns the canonical representation of the error's signature, the first
+	// 4 bytes of which prefix the revert data.
+	ID common.Hash
+}
+
+// NewError creates a new Error, precomputing its id, signature and string
+// representation.
+func NewError(name string, inputs Arguments) Error {
+	names := make([]string, len(inputs))
+	types := make([]string, len(inputs))
+	for i, input := range inputs {
+		names[i] = fmt.Sprintf("%v %v", input.Type, input.Name)
+		types[i] = input.Type.String()
+	}
+	sig := fmt.Sprintf("%v(%v)", name, strings.Join(types, ","))
+
+	return Error{
+		Name:   name,
+		Inputs: inputs,
+		str:    fmt.Sprintf("error %v(%v)", name, strings.Join(names, ", ")),
+		Sig:    sig,
+		ID:     common.BytesToHash(crypto.Keccak256([]byte(sig))),
+	}
+}
+
+func (e Error) String() string {
+	return e.str
+}
+
+// Unpack decodes the arguments of the error from the revert data.
+func (e Error) Unpack(data []byte) ([]interface{}, error) {
+	if len(data) < 4 {
+		return nil, errors.New("invalid data for unpacking")
+	}
+	if !bytes.Equal(data[:4], e.ID[:4]) {
+		return nil, errors.New("invalid data for unpacking")
+	}
+	return e.Inputs.Unpack(data[4:])
+}
+
 // formatSliceString formats the reflection kind with the given slice size
 // and returns a formatted string representation.
 func formatSliceString(kind reflect.Kind, sliceSize int) string {
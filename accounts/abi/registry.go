@@ -0,0 +1,233 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/c88032111/go-gdtu/common"
+)
+
+// Selector is the 4-byte identifier of a Method or custom Error.
+type Selector [4]byte
+
+// DecodedCall is calldata decoded by a Registry.
+type DecodedCall struct {
+	Method *Method
+	Args   []interface{} // Values of the Method inputs, in declaration order
+}
+
+// DecodedLog is a log decoded by a Registry.
+type DecodedLog struct {
+	Event *Event
+	Args  map[string]interface{} // Values of the Event inputs, indexed ones included
+}
+
+// DecodedError is revert data decoded by a Registry.
+type DecodedError struct {
+	Error *Error
+	Args  []interface{} // Values of the Error inputs, in declaration order
+}
+
+// Registry is a collection of contract ABIs, indexing their Methods, Events and
+// Errors by selector or topic, to decode calldata, logs and revert data without
+// knowing which contract they belong to.
+//
+// Different ABIs may declare the same signature (e.g. the ERC20 and ERC721
+// Transfer events, which only differ in the indexed inputs), or signatures with
+// colliding selectors. All of them are kept, and decoding picks the first one
+// registered which matches the data.
+//
+// It is safe to use a Registry concurrently.
+type Registry struct {
+	lock    sync.RWMutex
+	methods map[Selector][]*Method
+	events  map[common.Hash][]*Event
+	errors  map[Selector][]*Error
+}
+
+// NewRegistry creates an empty ABI registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		methods: make(map[Selector][]*Method),
+		events:  make(map[common.Hash][]*Event),
+		errors:  make(map[Selector][]*Error),
+	}
+}
+
+// Register indexes the Methods, Events and Errors of a contract ABI. Entries
+// with the exact same definition as an already registered one are skipped.
+func (r *Registry) Register(abi ABI) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, method := range abi.Methods {
+		method := method
+		id := toSelector(method.ID)
+		if !containsMethod(r.methods[id], &method) {
+			r.methods[id] = append(r.methods[id], &method)
+		}
+	}
+	for _, event := range abi.Events {
+		event := event
+		if event.Anonymous {
+			continue // Anonymous events have no topic to be found by
+		}
+		if !containsEvent(r.events[event.ID], &event) {
+			r.events[event.ID] = append(r.events[event.ID], &event)
+		}
+	}
+	for _, errABI := range abi.Errors {
+		errABI := errABI
+		id := toSelector(errABI.ID[:4])
+		if !containsError(r.errors[id], &errABI) {
+			r.errors[id] = append(r.errors[id], &errABI)
+		}
+	}
+}
+
+// Methods returns all the registered Methods with the given selector.
+func (r *Registry) Methods(id Selector) []*Method {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return append([]*Method(nil), r.methods[id]...)
+}
+
+// Events returns all the registered Events with the given topic.
+func (r *Registry) Events(topic common.Hash) []*Event {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return append([]*Event(nil), r.events[topic]...)
+}
+
+// Errors returns all the registered Errors with the given selector.
+func (r *Registry) Errors(id Selector) []*Error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return append([]*Error(nil), r.errors[id]...)
+}
+
+// DecodeCall decodes the calldata of a Method call, looking the Method up by
+// the selector prefixing the data.
+func (r *Registry) DecodeCall(data []byte) (*DecodedCall, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("data too short (%d bytes) for abi Method lookup", len(data))
+	}
+	methods := r.Methods(toSelector(data[:4]))
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no Method with id: gd%x", data[:4])
+	}
+	var err error
+	for _, method := range methods {
+		var args []interface{}
+		if args, err = method.Inputs.Unpack(data[4:]); err == nil {
+			return &DecodedCall{Method: method, Args: args}, nil
+		}
+	}
+	return nil, err
+}
+
+// DecodeLog decodes the topics and data of a log, looking the Event up by the
+// first topic.
+func (r *Registry) DecodeLog(topics []common.Hash, data []byte) (*DecodedLog, error) {
+	if len(topics) == 0 {
+		return nil, errors.New("no topics for abi event lookup")
+	}
+	events := r.Events(topics[0])
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no event with id: gd%x", topics[0])
+	}
+	err := errors.New("topic/field count mismatch")
+	for _, event := range events {
+		var indexed Arguments
+		for _, arg := range event.Inputs {
+			if arg.Indexed {
+				indexed = append(indexed, arg)
+			}
+		}
+		if len(indexed) != len(topics)-1 {
+			continue
+		}
+		args := make(map[string]interface{})
+		if err = event.Inputs.NonIndexed().UnpackIntoMap(args, data); err != nil {
+			continue
+		}
+		if err = ParseTopicsIntoMap(args, indexed, topics[1:]); err != nil {
+			continue
+		}
+		return &DecodedLog{Event: event, Args: args}, nil
+	}
+	return nil, err
+}
+
+// DecodeError decodes the revert data of a custom Error, looking the Error up
+// by the selector prefixing the data.
+func (r *Registry) DecodeError(data []byte) (*DecodedError, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("data too short (%d bytes) for abi error lookup", len(data))
+	}
+	errs := r.Errors(toSelector(data[:4]))
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no error with id: gd%x", data[:4])
+	}
+	var err error
+	for _, errABI := range errs {
+		var args []interface{}
+		if args, err = errABI.Unpack(data); err == nil {
+			return &DecodedError{Error: errABI, Args: args}, nil
+		}
+	}
+	return nil, err
+}
+
+// toSelector converts the first 4 bytes of an id into a selector.
+func toSelector(id []byte) (sel Selector) {
+	copy(sel[:], id)
+	return sel
+}
+
+func containsMethod(methods []*Method, method *Method) bool {
+	for _, m := range methods {
+		if m.String() == method.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func containsEvent(events []*Event, event *Event) bool {
+	for _, e := range events {
+		if e.String() == event.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func containsError(errs []*Error, errABI *Error) bool {
+	for _, e := range errs {
+		if e.String() == errABI.String() {
+			return true
+		}
+	}
+	return false
+}
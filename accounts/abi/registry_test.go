@@ -0,0 +1,143 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+)
+
+const registryTokenABI = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}
+]`
+
+const registryNFTABI = `[
+	{"type":"function","name":"approve","inputs":[{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"outputs":[]},
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"tokenId","type":"uint256","indexed":true}]}
+]`
+
+func newTestRegistry(t *testing.T) (*Registry, ABI, ABI) {
+	token, err := JSON(strings.NewReader(registryTokenABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nft, err := JSON(strings.NewReader(registryNFTABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistry()
+	registry.Register(token)
+	registry.Register(nft)
+	registry.Register(token) // Duplicates should be ignored
+
+	return registry, token, nft
+}
+
+func TestRegistryDecodeCall(t *testing.T) {
+	registry, token, nft := newTestRegistry(t)
+
+	to := common.HexToAddress("0x0102030405060708090a0b0c0d0e0f1011121314")
+	for _, contract := range []ABI{token, nft} {
+		for name := range contract.Methods {
+			data, err := contract.Pack(name, to, big.NewInt(42))
+			if err != nil {
+				t.Fatalf("%s: failed to pack: %v", name, err)
+			}
+			call, err := registry.DecodeCall(data)
+			if err != nil {
+				t.Fatalf("%s: failed to decode: %v", name, err)
+			}
+			if call.Method.Name != name {
+				t.Errorf("method mismatch: have %s, want %s", call.Method.Name, name)
+			}
+			if want := []interface{}{to, big.NewInt(42)}; !reflect.DeepEqual(call.Args, want) {
+				t.Errorf("%s: args mismatch: have %v, want %v", name, call.Args, want)
+			}
+		}
+	}
+	if methods := registry.Methods(toSelector(token.Methods["transfer"].ID)); len(methods) != 1 {
+		t.Errorf("duplicate registration not ignored: have %d methods", len(methods))
+	}
+	if _, err := registry.DecodeCall([]byte{0xde, 0xad, 0xbe, 0xef}); err == nil {
+		t.Errorf("unknown selector decoded")
+	}
+	if _, err := registry.DecodeCall([]byte{0x01}); err == nil {
+		t.Errorf("short calldata decoded")
+	}
+}
+
+func TestRegistryDecodeLog(t *testing.T) {
+	registry, token, _ := newTestRegistry(t)
+
+	var (
+		from  = common.HexToAddress("0x1111111111111111111111111111111111111111")
+		to    = common.HexToAddress("0x2222222222222222222222222222222222222222")
+		id    = token.Events["Transfer"].ID
+		value = common.BigToHash(big.NewInt(42))
+	)
+	if events := registry.Events(id); len(events) != 2 {
+		t.Fatalf("colliding events mismatch: have %d, want 2", len(events))
+	}
+	// ERC20 transfer, the value is in the data
+	log, err := registry.DecodeLog([]common.Hash{id, from.Hash(), to.Hash()}, value[:])
+	if err != nil {
+		t.Fatalf("failed to decode token transfer: %v", err)
+	}
+	want := map[string]interface{}{"from": from, "to": to, "value": big.NewInt(42)}
+	if !reflect.DeepEqual(log.Args, want) {
+		t.Errorf("token transfer mismatch: have %v, want %v", log.Args, want)
+	}
+	// ERC721 transfer, the token id is indexed
+	log, err = registry.DecodeLog([]common.Hash{id, from.Hash(), to.Hash(), value}, nil)
+	if err != nil {
+		t.Fatalf("failed to decode nft transfer: %v", err)
+	}
+	want = map[string]interface{}{"from": from, "to": to, "tokenId": big.NewInt(42)}
+	if !reflect.DeepEqual(log.Args, want) {
+		t.Errorf("nft transfer mismatch: have %v, want %v", log.Args, want)
+	}
+	// Neither of them has two topics
+	if _, err := registry.DecodeLog([]common.Hash{id, from.Hash()}, value[:]); err == nil {
+		t.Errorf("log with mismatching topics decoded")
+	}
+}
+
+func TestRegistryDecodeError(t *testing.T) {
+	registry, token, _ := newTestRegistry(t)
+
+	errABI := token.Errors["InsufficientBalance"]
+	args, err := errABI.Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := registry.DecodeError(append(errABI.ID[:4], args...))
+	if err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	if decoded.Error.Sig != "InsufficientBalance(uint256,uint256)" {
+		t.Errorf("error mismatch: have %s", decoded.Error.Sig)
+	}
+	if want := []interface{}{big.NewInt(1), big.NewInt(2)}; !reflect.DeepEqual(decoded.Args, want) {
+		t.Errorf("args mismatch: have %v, want %v", decoded.Args, want)
+	}
+}
@@ -17,9 +17,11 @@
 package external
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
+	"time"
 
 	"github.com/c88032111/go-gdtu"
 	"github.com/c88032111/go-gdtu/accounts"
@@ -32,57 +34,133 @@ import (
 	"github.com/c88032111/go-gdtu/signer/core"
 )
 
+// healthCheckInterval is the time between two health checks of the external
+// signer while the backend has subscribers.
+const healthCheckInterval = 10 * time.Second
+
+// ErrSignerUnavailable is returned if none of the endpoints of an external signer
+// can be reached.
+var ErrSignerUnavailable = errors.New("external signer unavailable")
+
+// ExternalBackend is an account backend serving the wallet of an external signer.
+// While subscribed to, the signer is health checked periodically, the wallet is
+// dropped on outages and arrives again once the signer recovers.
 type ExternalBackend struct {
-	signers []accounts.Wallet
+	signer   *ExternalSigner
+	interval time.Duration // Time between two health checks
+
+	updateFeed  event.Feed              // Event feed to notify wallet availability changes
+	updateScope event.SubscriptionScope // Subscription scope tracking current live listeners
+	updating    bool                    // Whgdtuer the health check loop is running
+	lock        sync.Mutex
 }
 
+// Wallets implements accounts.Backend, returning the external signer unless it
+// is currently unavailable.
 func (eb *ExternalBackend) Wallets() []accounts.Wallet {
-	return eb.signers
+	if !eb.signer.Available() {
+		return nil
+	}
+	return []accounts.Wallet{eb.signer}
 }
 
-func NewExternalBackend(endpoint string) (*ExternalBackend, error) {
-	signer, err := NewExternalSigner(endpoint)
+// NewExternalBackend creates a backend for the external signer reachable at the
+// given endpoints. Additional endpoints are failovers of the first one, which
+// must serve the same accounts.
+func NewExternalBackend(endpoints ...string) (*ExternalBackend, error) {
+	signer, err := NewExternalSigner(endpoints...)
 	if err != nil {
 		return nil, err
 	}
 	return &ExternalBackend{
-		signers: []accounts.Wallet{signer},
+		signer:   signer,
+		interval: healthCheckInterval,
 	}, nil
 }
 
+// Subscribe implements accounts.Backend, creating an async subscription to
+// receive notifications on the availability changes of the external signer.
 func (eb *ExternalBackend) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
-	return event.NewSubscription(func(quit <-chan struct{}) error {
-		<-quit
-		return nil
-	})
+	// We need the mutex to reliably start/stop the health check loop
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+
+	sub := eb.updateScope.Track(eb.updateFeed.Subscribe(sink))
+
+	// Subscribers require an active health check loop, start it
+	if !eb.updating {
+		eb.updating = true
+		go eb.updater()
+	}
+	return sub
+}
+
+// updater is responsible for health checking the external signer and notifying
+// the subscribers about its availability changes.
+func (eb *ExternalBackend) updater() {
+	ticker := time.NewTicker(eb.interval)
+	defer ticker.Stop()
+
+	available := eb.signer.Available()
+	for {
+		<-ticker.C
+
+		if now := eb.signer.checkHealth(); now != available {
+			available = now
+			if available {
+				log.Info("External signer available", "url", eb.signer.URL())
+				eb.updateFeed.Send(accounts.WalletEvent{Wallet: eb.signer, Kind: accounts.WalletArrived})
+			} else {
+				_, err := eb.signer.Status()
+				log.Warn("External signer unavailable", "url", eb.signer.URL(), "err", err)
+				eb.updateFeed.Send(accounts.WalletEvent{Wallet: eb.signer, Kind: accounts.WalletDropped})
+			}
+		}
+		// If all our subscribers left, stop the health checks
+		eb.lock.Lock()
+		if eb.updateScope.Count() == 0 {
+			eb.updating = false
+			eb.lock.Unlock()
+			return
+		}
+		eb.lock.Unlock()
+	}
 }
 
 // ExternalSigner provides an API to interact with an external signer (clef)
 // It proxies request to the external signer while forwarding relevant
 // request headers
+//
+// The signer may be reachable through multiple endpoints, read-only requests
+// failing on the transport level are retried on the next one. The wallet is
+// identified by the first endpoint irrespective of the one in use.
 type ExternalSigner struct {
-	client   *rpc.Client
-	endpoint string
+	endpoint  string   // Primary endpoint, identifying the wallet
+	endpoints []string // All endpoints in failover order
+
+	clientMu sync.Mutex
+	client   *rpc.Client // Connection to the active endpoint, nil if disconnected
+	active   int         // Index of the active endpoint
 	status   string
-	cacheMu  sync.RWMutex
-	cache    []accounts.Account
+	err      error // Failure reaching the signer, nil if available
+
+	cacheMu sync.RWMutex
+	cache   []accounts.Account
 }
 
-func NewExternalSigner(endpoint string) (*ExternalSigner, error) {
-	client, err := rpc.Dial(endpoint)
-	if err != nil {
-		return nil, err
+func NewExternalSigner(endpoints ...string) (*ExternalSigner, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no external signer endpoint")
 	}
 	extsigner := &ExternalSigner{
-		client:   client,
-		endpoint: endpoint,
+		endpoint:  endpoints[0],
+		endpoints: endpoints,
 	}
 	// Check if reachable
-	version, err := extsigner.pingVersion()
-	if err != nil {
+	if !extsigner.checkHealth() {
+		_, err := extsigner.Status()
 		return nil, err
 	}
-	extsigner.status = fmt.Sprintf("ok [version=%v]", version)
 	return extsigner, nil
 }
 
@@ -94,9 +172,24 @@ func (api *ExternalSigner) URL() accounts.URL {
 }
 
 func (api *ExternalSigner) Status() (string, error) {
+	api.clientMu.Lock()
+	defer api.clientMu.Unlock()
+
+	if api.err != nil {
+		return "offline", api.err
+	}
 	return api.status, nil
 }
 
+// Available reports whgdtuer the external signer could be reached on the last
+// health check or request.
+func (api *ExternalSigner) Available() bool {
+	api.clientMu.Lock()
+	defer api.clientMu.Unlock()
+
+	return api.err == nil
+}
+
 func (api *ExternalSigner) Open(passphrase string) error {
 	return fmt.Errorf("operation not supported on external signers")
 }
@@ -160,7 +253,7 @@ func (api *ExternalSigner) signHash(account accounts.Account, hash []byte) ([]by
 func (api *ExternalSigner) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
 	var res hexutil.Bytes
 	var signAddress = common.NewMixedcaseAddress(account.Address)
-	if err := api.client.Call(&res, "account_signData",
+	if err := api.call(&res, "account_signData",
 		mimeType,
 		&signAddress, // Need to use the pointer here, because of how MarshalJSON is defined
 		hexutil.Encode(data)); err != nil {
@@ -176,7 +269,7 @@ func (api *ExternalSigner) SignData(account accounts.Account, mimeType string, d
 func (api *ExternalSigner) SignText(account accounts.Account, text []byte) ([]byte, error) {
 	var signature hexutil.Bytes
 	var signAddress = common.NewMixedcaseAddress(account.Address)
-	if err := api.client.Call(&signature, "account_signData",
+	if err := api.call(&signature, "account_signData",
 		accounts.MimetypeTextPlain,
 		&signAddress, // Need to use the pointer here, because of how MarshalJSON is defined
 		hexutil.Encode(text)); err != nil {
@@ -213,7 +306,7 @@ func (api *ExternalSigner) SignTx(account accounts.Account, tx *types.Transactio
 		From:     common.NewMixedcaseAddress(account.Address),
 	}
 	var res signTransactionResult
-	if err := api.client.Call(&res, "account_signTransaction", args); err != nil {
+	if err := api.call(&res, "account_signTransaction", args); err != nil {
 		return nil, err
 	}
 	return res.Tx, nil
@@ -232,15 +325,108 @@ func (api *ExternalSigner) SignDataWithPassphrase(account accounts.Account, pass
 
 func (api *ExternalSigner) listAccounts() ([]common.Address, error) {
 	var res []common.Address
-	if err := api.client.Call(&res, "account_list"); err != nil {
+	if err := api.call(&res, "account_list"); err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
+// checkHealth pings the external signer, failing over to the next endpoints if
+// the active one is unreachable. It returns whgdtuer the signer is available.
+func (api *ExternalSigner) checkHealth() bool {
+	version, err := api.pingVersion()
+
+	api.clientMu.Lock()
+	defer api.clientMu.Unlock()
+
+	if err != nil {
+		if api.err == nil {
+			api.err = err // Signer reachable, but misbehaving
+		}
+		return false
+	}
+	api.status = fmt.Sprintf("ok [version=%v]", version)
+	if api.active != 0 {
+		api.status = fmt.Sprintf("ok [version=%v, failover=%s]", version, api.endpoints[api.active])
+	}
+	return true
+}
+
+// retryableMethods are the signer methods without side effects, which are safe
+// to resend to another endpoint if a request fails on the transport level.
+var retryableMethods = map[string]bool{
+	"account_version": true,
+	"account_list":    true,
+}
+
+// call invokes a Method of the external signer. Read-only requests failing on the
+// transport level are retried on the next endpoints, reconnecting as needed. Any
+// other request might have reached the signer already (e.g. prompting the user to
+// approve a transaction), so it is not resent, the next request fails over instead.
+// Errors returned by the signer itself are passed through.
+func (api *ExternalSigner) call(result interface{}, method string, args ...interface{}) error {
+	var err error
+	for attempt := 0; attempt < len(api.endpoints); attempt++ {
+		var client *rpc.Client
+		if client, err = api.connect(); err != nil {
+			continue
+		}
+		err = client.Call(result, method, args...)
+		if _, ok := err.(rpc.Error); ok || err == nil {
+			api.clientMu.Lock()
+			api.err = nil
+			api.clientMu.Unlock()
+			return err
+		}
+		api.disconnect(client, err)
+		if !retryableMethods[method] {
+			break
+		}
+	}
+	api.clientMu.Lock()
+	api.err = fmt.Errorf("%w: %v", ErrSignerUnavailable, err)
+	api.clientMu.Unlock()
+
+	return api.err
+}
+
+// connect returns the client of the active endpoint, dialing it if needed. If
+// the endpoint can't be dialed, the next one becomes active.
+func (api *ExternalSigner) connect() (*rpc.Client, error) {
+	api.clientMu.Lock()
+	defer api.clientMu.Unlock()
+
+	if api.client != nil {
+		return api.client, nil
+	}
+	endpoint := api.endpoints[api.active]
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		log.Warn("Failed to dial external signer", "endpoint", endpoint, "err", err)
+		api.active = (api.active + 1) % len(api.endpoints)
+		return nil, err
+	}
+	api.client = client
+	return client, nil
+}
+
+// disconnect drops the client of a failed endpoint, making the next one active.
+func (api *ExternalSigner) disconnect(client *rpc.Client, err error) {
+	api.clientMu.Lock()
+	defer api.clientMu.Unlock()
+
+	if api.client != client {
+		return // Another request failed over already
+	}
+	log.Warn("External signer request failed", "endpoint", api.endpoints[api.active], "err", err)
+	client.Close()
+	api.client = nil
+	api.active = (api.active + 1) % len(api.endpoints)
+}
+
 func (api *ExternalSigner) pingVersion() (string, error) {
 	var v string
-	if err := api.client.Call(&v, "account_version"); err != nil {
+	if err := api.call(&v, "account_version"); err != nil {
 		return "", err
 	}
 	return v, nil
@@ -0,0 +1,195 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package external
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/accounts"
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/rpc"
+)
+
+// testSigner is a minimal clef account API.
+type testSigner struct {
+	version string
+	signs   int32 // Number of signing requests served
+}
+
+func (s *testSigner) Version() string { return s.version }
+
+func (s *testSigner) List() []common.Address { return []common.Address{{0xde, 0xad}} }
+
+func (s *testSigner) SignData(mimeType string, addr common.MixedcaseAddress, data hexutil.Bytes) hexutil.Bytes {
+	atomic.AddInt32(&s.signs, 1)
+	return make(hexutil.Bytes, 65)
+}
+
+// newTestSigner starts a clef account API over HTTP.
+func newTestSigner(t *testing.T, version string) *httptest.Server {
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", &testSigner{version: version}); err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(server)
+}
+
+// Tests that requests fail over to the next endpoint if the active one becomes
+// unreachable, and that an outage of all endpoints is reported.
+func TestExternalSignerFailover(t *testing.T) {
+	primary, backup := newTestSigner(t, "primary"), newTestSigner(t, "backup")
+	defer backup.Close()
+
+	signer, err := NewExternalSigner(primary.URL, backup.URL)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	if status, err := signer.Status(); err != nil || status != "ok [version=primary]" {
+		t.Fatalf("status mismatch: %q, %v", status, err)
+	}
+	primary.Close()
+
+	if !signer.checkHealth() {
+		t.Fatalf("failover to backup failed")
+	}
+	if status, _ := signer.Status(); status != "ok [version=backup, failover="+backup.URL+"]" {
+		t.Fatalf("failover status mismatch: %q", status)
+	}
+	if accs := signer.Accounts(); len(accs) != 1 || accs[0].URL != signer.URL() || signer.URL().Path != primary.URL {
+		t.Fatalf("accounts mismatch: %v", accs)
+	}
+	backup.Close()
+
+	if signer.checkHealth() {
+		t.Fatalf("unreachable signer reported healthy")
+	}
+	if _, err := signer.Status(); !errors.Is(err, ErrSignerUnavailable) {
+		t.Fatalf("outage error mismatch: %v", err)
+	}
+	if _, err := signer.SignText(accounts.Account{Address: common.Address{0xde, 0xad}}, []byte("hello")); !errors.Is(err, ErrSignerUnavailable) {
+		t.Fatalf("signing error mismatch: %v", err)
+	}
+}
+
+// Tests that signing requests failing on the transport level are not resent to
+// another endpoint, only the subsequent requests fail over.
+func TestExternalSignerNoSignRetry(t *testing.T) {
+	var (
+		down   int32
+		backup = &testSigner{version: "backup"}
+		server = rpc.NewServer()
+	)
+	if err := server.RegisterName("account", &testSigner{version: "primary"}); err != nil {
+		t.Fatal(err)
+	}
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	defer primary.Close()
+
+	backupServer := rpc.NewServer()
+	if err := backupServer.RegisterName("account", backup); err != nil {
+		t.Fatal(err)
+	}
+	secondary := httptest.NewServer(backupServer)
+	defer secondary.Close()
+
+	signer, err := NewExternalSigner(primary.URL, secondary.URL)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	atomic.StoreInt32(&down, 1)
+
+	account := accounts.Account{Address: common.Address{0xde, 0xad}}
+	if _, err := signer.SignText(account, []byte("hello")); !errors.Is(err, ErrSignerUnavailable) {
+		t.Fatalf("signing error mismatch: %v", err)
+	}
+	if signs := atomic.LoadInt32(&backup.signs); signs != 0 {
+		t.Fatalf("failed signing request resent to backup")
+	}
+	if _, err := signer.SignText(account, []byte("hello")); err != nil {
+		t.Fatalf("signing on backup failed: %v", err)
+	}
+	if signs := atomic.LoadInt32(&backup.signs); signs != 1 {
+		t.Fatalf("backup signing requests mismatch: have %d, want 1", signs)
+	}
+}
+
+// Tests that the backend drops the wallet on signer outages and announces it
+// again on recovery.
+func TestExternalBackendEvents(t *testing.T) {
+	var down int32
+	signer := rpc.NewServer()
+	if err := signer.RegisterName("account", &testSigner{version: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		signer.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	backend, err := NewExternalBackend(server.URL)
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	backend.interval = 10 * time.Millisecond
+
+	events := make(chan accounts.WalletEvent)
+	sub := backend.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	atomic.StoreInt32(&down, 1)
+	if event := waitWalletEvent(t, events); event.Kind != accounts.WalletDropped {
+		t.Fatalf("event mismatch: have %v, want dropped", event.Kind)
+	}
+	if wallets := backend.Wallets(); len(wallets) != 0 {
+		t.Fatalf("unavailable wallet listed")
+	}
+	atomic.StoreInt32(&down, 0)
+	if event := waitWalletEvent(t, events); event.Kind != accounts.WalletArrived {
+		t.Fatalf("event mismatch: have %v, want arrived", event.Kind)
+	}
+	if wallets := backend.Wallets(); len(wallets) != 1 {
+		t.Fatalf("available wallet not listed")
+	}
+}
+
+func waitWalletEvent(t *testing.T, events chan accounts.WalletEvent) accounts.WalletEvent {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("wallet event timeout")
+	}
+	return accounts.WalletEvent{}
+}
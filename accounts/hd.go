@@ -17,12 +17,19 @@
 package accounts
 
 import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
 	"strings"
+
+	cmath "github.com/c88032111/go-gdtu/common/math"
+	"github.com/c88032111/go-gdtu/crypto"
 )
 
 // DefaultRootDerivationPath is the root path to which custom derivation endpoints
@@ -178,3 +185,54 @@ func LedgerLiveIterator(base DerivationPath) func() DerivationPath {
 		return path
 	}
 }
+
+// masterKeySeed is the HMAC key used to derive the BIP-32 master key from a seed.
+var masterKeySeed = []byte("Bitcoin seed")
+
+// errInvalidHDKey is returned if a derivation step yields an invalid key, which
+// happens with a probability lower than 1 in 2^127.
+var errInvalidHDKey = errors.New("invalid derived key")
+
+// DeriveKey derives the private key at the given BIP-32 path from a seed, such
+// as one generated from a BIP-39 mnemonic. It derives the same keys hardware
+// wallets do for the same seed and path.
+func DeriveKey(seed []byte, path DerivationPath) (*ecdsa.PrivateKey, error) {
+	mac := hmac.New(sha512.New, masterKeySeed)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+	if key.Sign() == 0 || key.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, errInvalidHDKey
+	}
+	for _, component := range path {
+		var (
+			data  []byte
+			index [4]byte
+		)
+		binary.BigEndian.PutUint32(index[:], component)
+		if component >= 0x80000000 {
+			// Hardened child, derived from the private key
+			data = append([]byte{0x00}, cmath.PaddedBigBytes(key, 32)...)
+		} else {
+			// Normal child, derived from the compressed public key
+			x, y := crypto.S256().ScalarBaseMult(cmath.PaddedBigBytes(key, 32))
+			data = crypto.CompressPubkey(&ecdsa.PublicKey{Curve: crypto.S256(), X: x, Y: y})
+		}
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(append(data, index[:]...))
+		sum := mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(crypto.S256().Params().N) >= 0 {
+			return nil, errInvalidHDKey
+		}
+		key = tweak.Add(tweak, key)
+		key.Mod(key, crypto.S256().Params().N)
+		if key.Sign() == 0 {
+			return nil, errInvalidHDKey
+		}
+		chainCode = sum[32:]
+	}
+	return crypto.ToECDSA(cmath.PaddedBigBytes(key, 32))
+}
@@ -17,9 +17,14 @@
 package accounts
 
 import (
+	"encoding/hex"
 	"fmt"
 	"reflect"
 	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/tyler-smith/go-bip39"
 )
 
 // Tests that HD derivation paths can be correctly parsed into our internal binary
@@ -116,3 +121,46 @@ func TestHdPathIteration(t *testing.T) {
 			"m/44'/60'/8'/0/0", "m/44'/60'/9'/0/0",
 		})
 }
+
+// Tests that keys are derived from seeds according to the BIP-32 test vectors
+// and that mnemonic seeds yield the same accounts as hardware wallets.
+func TestHDKeyDerivation(t *testing.T) {
+	seed := common.FromHex("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	master, err := DeriveKey(seed, DerivationPath{})
+	if err != nil {
+		t.Fatalf("master key derivation failed: %v", err)
+	}
+	if have := hex.EncodeToString(crypto.FromECDSA(master)); have != "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35" {
+		t.Errorf("master key mismatch: have %s", have)
+	}
+	for i, tt := range tests {
+		path, err := ParseDerivationPath(tt.path)
+		if err != nil {
+			t.Fatalf("test %d: failed to parse path: %v", i, err)
+		}
+		key, err := DeriveKey(seed, path)
+		if err != nil {
+			t.Fatalf("test %d: derivation failed: %v", i, err)
+		}
+		if have := hex.EncodeToString(crypto.FromECDSA(key)); have != tt.key {
+			t.Errorf("test %d: key mismatch: have %s, want %s", i, have, tt.key)
+		}
+	}
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	key, err := DeriveKey(bip39.NewSeed(mnemonic, ""), DefaultBaseDerivationPath)
+	if err != nil {
+		t.Fatalf("mnemonic derivation failed: %v", err)
+	}
+	if have, want := crypto.PubkeyToAddress(key.PublicKey), common.HexToAddress("9858EfFD232B4033E47d90003D41EC34EcaEda94"); have != want {
+		t.Errorf("mnemonic account mismatch: have %x, want %x", have, want)
+	}
+}
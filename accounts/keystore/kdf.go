@@ -0,0 +1,300 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	argon2idKDF = "argon2id"
+	pbkdf2KDF   = "pbkdf2"
+
+	// StandardArgon2idTime is the number of passes of the Argon2id encryption
+	// algorithm, using 256MB memory and taking approximately 1s CPU time on a
+	// modern processor.
+	StandardArgon2idTime = 3
+
+	// StandardArgon2idMemory is the memory (in KiB) used by the Argon2id
+	// encryption algorithm, using 256MB memory and taking approximately 1s CPU
+	// time on a modern processor.
+	StandardArgon2idMemory = 256 * 1024
+
+	// LightArgon2idTime is the number of passes of the Argon2id encryption
+	// algorithm, using 4MB memory and taking approximately 100ms CPU time on a
+	// modern processor.
+	LightArgon2idTime = 1
+
+	// LightArgon2idMemory is the memory (in KiB) used by the Argon2id encryption
+	// algorithm, using 4MB memory and taking approximately 100ms CPU time on a
+	// modern processor.
+	LightArgon2idMemory = 4 * 1024
+
+	// argon2idThreads is the degree of parallelism of the Argon2id encryption.
+	argon2idThreads = 4
+
+	// maxArgon2idTime and maxArgon2idMemory cap the Argon2id costs accepted from
+	// key files, so a crafted key can't make unlocking exhaust the memory or run
+	// practically forever.
+	maxArgon2idTime   = 64
+	maxArgon2idMemory = 4 * 1024 * 1024
+)
+
+// KDF is a password based key derivation function, deriving the key encrypting
+// an account from its passphrase.
+type KDF interface {
+	// Name returns the identifier of the KDF in the key file.
+	Name() string
+
+	// Params returns the parameters of the KDF stored in the key file, besides
+	// the salt and the derived key length. Higher values are expected to mean
+	// stronger protection.
+	Params() map[string]interface{}
+
+	// DeriveKey derives a key of the given length from the passphrase and salt.
+	DeriveKey(auth, salt []byte, dkLen int) ([]byte, error)
+}
+
+// KDFParser reconstructs a KDF from the parameters stored in a key file.
+type KDFParser func(params map[string]interface{}) (KDF, error)
+
+var (
+	kdfLock    sync.RWMutex
+	kdfParsers = map[string]KDFParser{
+		keyHeaderKDF: parseScrypt,
+		argon2idKDF:  parseArgon2id,
+		pbkdf2KDF:    parsePBKDF2,
+	}
+)
+
+// RegisterKDF makes an additional KDF available for decrypting key files. Keys
+// can be encrypted with any KDF implementation, but only registered ones can be
+// decrypted. It panics if a KDF is registered twice under the same name.
+func RegisterKDF(name string, parser KDFParser) {
+	kdfLock.Lock()
+	defer kdfLock.Unlock()
+
+	if _, ok := kdfParsers[name]; ok {
+		panic(fmt.Sprintf("keystore: KDF %q registered twice", name))
+	}
+	kdfParsers[name] = parser
+}
+
+// parseKDF reconstructs the KDF of a key file from its name and parameters.
+func parseKDF(name string, params map[string]interface{}) (KDF, error) {
+	kdfLock.RLock()
+	parser, ok := kdfParsers[name]
+	kdfLock.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported KDF: %s", name)
+	}
+	return parser(params)
+}
+
+// kdfCoster is implemented by the built-in KDFs, estimating the cost of deriving
+// a key as the number of bytes of memory filled, comparable across algorithms.
+type kdfCoster interface {
+	cost() uint64
+}
+
+// weakerKDF reports whether a key protected by the KDF have should be upgraded
+// to the KDF want. For the same algorithm, it is if any numeric parameter is lower
+// or any other parameter differs. Different algorithms are compared by estimated
+// cost, keys are never switched implicitly to or from algorithms without one.
+func weakerKDF(have, want KDF) bool {
+	if have.Name() != want.Name() {
+		haveCoster, ok := have.(kdfCoster)
+		if !ok {
+			return false
+		}
+		wantCoster, ok := want.(kdfCoster)
+		if !ok {
+			return false
+		}
+		return haveCoster.cost() < wantCoster.cost()
+	}
+	haveParams := have.Params()
+	for name, value := range want.Params() {
+		wantValue, ok := value.(int)
+		if !ok {
+			if haveParams[name] != value {
+				return true
+			}
+			continue
+		}
+		if haveValue, ok := haveParams[name].(int); !ok || haveValue < wantValue {
+			return true
+		}
+	}
+	return false
+}
+
+// ScryptKDF is the scrypt key derivation function, the default of the keystore.
+type ScryptKDF struct {
+	N, R, P int
+}
+
+// NewScryptKDF creates a scrypt KDF with the given N and P parameters.
+func NewScryptKDF(n, p int) *ScryptKDF {
+	return &ScryptKDF{N: n, R: scryptR, P: p}
+}
+
+// Name implements KDF, returning the identifier of scrypt.
+func (kdf *ScryptKDF) Name() string { return keyHeaderKDF }
+
+// Params implements KDF, returning the scrypt cost parameters.
+func (kdf *ScryptKDF) Params() map[string]interface{} {
+	return map[string]interface{}{"n": kdf.N, "r": kdf.R, "p": kdf.P}
+}
+
+// DeriveKey implements KDF, deriving a key using scrypt.
+func (kdf *ScryptKDF) DeriveKey(auth, salt []byte, dkLen int) ([]byte, error) {
+	return scrypt.Key(auth, salt, kdf.N, kdf.R, kdf.P, dkLen)
+}
+
+// cost implements kdfCoster, scrypt filling 128*r*N bytes per parallel lane.
+func (kdf *ScryptKDF) cost() uint64 {
+	return 128 * uint64(kdf.R) * uint64(kdf.N) * uint64(kdf.P)
+}
+
+func parseScrypt(params map[string]interface{}) (KDF, error) {
+	n, err := kdfParam(params, "n")
+	if err != nil {
+		return nil, err
+	}
+	r, err := kdfParam(params, "r")
+	if err != nil {
+		return nil, err
+	}
+	p, err := kdfParam(params, "p")
+	if err != nil {
+		return nil, err
+	}
+	return &ScryptKDF{N: n, R: r, P: p}, nil
+}
+
+// Argon2idKDF is the Argon2id key derivation function, a memory hard function
+// more resistant to GPU and side-channel attacks than scrypt.
+type Argon2idKDF struct {
+	Time    int // Number of passes over the memory
+	Memory  int // Memory used, in KiB
+	Threads int // Degree of parallelism
+}
+
+// NewArgon2idKDF creates an Argon2id KDF with the given time and memory costs.
+func NewArgon2idKDF(time, memory int) *Argon2idKDF {
+	return &Argon2idKDF{Time: time, Memory: memory, Threads: argon2idThreads}
+}
+
+// Name implements KDF, returning the identifier of Argon2id.
+func (kdf *Argon2idKDF) Name() string { return argon2idKDF }
+
+// Params implements KDF, returning the Argon2id cost parameters along with the
+// version of the algorithm.
+func (kdf *Argon2idKDF) Params() map[string]interface{} {
+	return map[string]interface{}{"t": kdf.Time, "m": kdf.Memory, "p": kdf.Threads, "v": argon2.Version}
+}
+
+// DeriveKey implements KDF, deriving a key using Argon2id.
+func (kdf *Argon2idKDF) DeriveKey(auth, salt []byte, dkLen int) ([]byte, error) {
+	return argon2.IDKey(auth, salt, uint32(kdf.Time), uint32(kdf.Memory), uint8(kdf.Threads), uint32(dkLen)), nil
+}
+
+// cost implements kdfCoster, Argon2id filling its memory once per pass.
+func (kdf *Argon2idKDF) cost() uint64 {
+	return 1024 * uint64(kdf.Memory) * uint64(kdf.Time)
+}
+
+func parseArgon2id(params map[string]interface{}) (KDF, error) {
+	version, err := kdfParam(params, "v")
+	if err != nil {
+		return nil, err
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2id version: %d", version)
+	}
+	t, err := kdfParam(params, "t")
+	if err != nil {
+		return nil, err
+	}
+	m, err := kdfParam(params, "m")
+	if err != nil {
+		return nil, err
+	}
+	p, err := kdfParam(params, "p")
+	if err != nil {
+		return nil, err
+	}
+	if t < 1 || m < 8*p || p < 1 || p > 255 {
+		return nil, fmt.Errorf("invalid argon2id parameters: t=%d m=%d p=%d", t, m, p)
+	}
+	if t > maxArgon2idTime || m > maxArgon2idMemory {
+		return nil, fmt.Errorf("argon2id parameters too costly: t=%d (max %d) m=%d (max %d)", t, maxArgon2idTime, m, maxArgon2idMemory)
+	}
+	return &Argon2idKDF{Time: t, Memory: m, Threads: p}, nil
+}
+
+// pbkdf2HMACSHA256 is the PBKDF2 key derivation function, only supported for
+// decrypting old key files.
+type pbkdf2HMACSHA256 struct {
+	c int
+}
+
+func (kdf *pbkdf2HMACSHA256) Name() string { return pbkdf2KDF }
+
+func (kdf *pbkdf2HMACSHA256) Params() map[string]interface{} {
+	return map[string]interface{}{"c": kdf.c, "prf": "hmac-sha256"}
+}
+
+func (kdf *pbkdf2HMACSHA256) DeriveKey(auth, salt []byte, dkLen int) ([]byte, error) {
+	return pbkdf2.Key(auth, salt, kdf.c, dkLen, sha256.New), nil
+}
+
+// cost implements kdfCoster, PBKDF2 not being memory hard at all.
+func (kdf *pbkdf2HMACSHA256) cost() uint64 { return 0 }
+
+func parsePBKDF2(params map[string]interface{}) (KDF, error) {
+	c, err := kdfParam(params, "c")
+	if err != nil {
+		return nil, err
+	}
+	if prf, _ := params["prf"].(string); prf != "hmac-sha256" {
+		return nil, fmt.Errorf("unsupported PBKDF2 PRF: %s", prf)
+	}
+	return &pbkdf2HMACSHA256{c: c}, nil
+}
+
+// kdfParam retrieves an integer KDF parameter from a decoded key file.
+func kdfParam(params map[string]interface{}, name string) (int, error) {
+	switch value := params[name].(type) {
+	case int:
+		return value, nil
+	case float64:
+		return int(value), nil
+	case nil:
+		return 0, fmt.Errorf("missing KDF parameter %q", name)
+	default:
+		return 0, fmt.Errorf("invalid KDF parameter %q: %v", name, value)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"reflect"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/c88032111/go-gdtu/accounts"
@@ -37,6 +38,7 @@ import (
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/c88032111/go-gdtu/event"
+	"github.com/c88032111/go-gdtu/log"
 )
 
 var (
@@ -47,6 +49,10 @@ var (
 	// ErrAccountAlreadyExists is returned if an account attempted to import is
 	// already present in the keystore.
 	ErrAccountAlreadyExists = errors.New("account already exists")
+
+	// ErrReadOnly is returned if a key is attempted to be created, imported,
+	// updated or deleted in a read-only keystore.
+	ErrReadOnly = errors.New("keystore is read-only")
 )
 
 // KeyStoreType is the reflect type of a keystore backend.
@@ -72,6 +78,11 @@ type KeyStore struct {
 
 	mu       sync.RWMutex
 	importMu sync.Mutex // Import Mutex locks the import to prevent two insertions from racing
+
+	readOnly    uint32 // Whgdtuer modifications of the key directory are refused (atomic)
+	upgradeKDF  uint32 // Whgdtuer to re-encrypt keys with a weaker KDF on unlock (atomic)
+	kdfUpgraded uint64 // Number of keys re-encrypted on unlock (atomic)
+	kdfFailed   uint64 // Number of keys failed to be re-encrypted on unlock (atomic)
 }
 
 type unlocked struct {
@@ -82,7 +93,16 @@ type unlocked struct {
 // NewKeyStore creates a keystore for the given directory.
 func NewKeyStore(keydir string, scryptN, scryptP int) *KeyStore {
 	keydir, _ = filepath.Abs(keydir)
-	ks := &KeyStore{storage: &keyStorePassphrase{keydir, scryptN, scryptP, false}}
+	ks := &KeyStore{storage: &keyStorePassphrase{keydir, scryptN, scryptP, false, nil}}
+	ks.init(keydir)
+	return ks
+}
+
+// NewKeyStoreWithKDF creates a keystore for the given directory, encrypting new
+// keys with the given key derivation function.
+func NewKeyStoreWithKDF(keydir string, kdf KDF) *KeyStore {
+	keydir, _ = filepath.Abs(keydir)
+	ks := &KeyStore{storage: &keyStorePassphrase{keydir, StandardScryptN, StandardScryptP, false, kdf}}
 	ks.init(keydir)
 	return ks
 }
@@ -237,6 +257,9 @@ func (ks *KeyStore) Accounts() []accounts.Account {
 // Delete deletes the key matched by account if the passphrase is correct.
 // If the account contains no filename, the address must match a unique key.
 func (ks *KeyStore) Delete(a accounts.Account, passphrase string) error {
+	if ks.isReadOnly() {
+		return ErrReadOnly
+	}
 	// Decrypting the key isn't really necessary, but we do
 	// it anyway to check the password and zero out the key
 	// immediately afterwards.
@@ -342,6 +365,7 @@ func (ks *KeyStore) TimedUnlock(a accounts.Account, passphrase string, timeout t
 	if err != nil {
 		return err
 	}
+	ks.maybeUpgradeKey(a, key, passphrase)
 
 	ks.mu.Lock()
 	defer ks.mu.Unlock()
@@ -366,6 +390,74 @@ func (ks *KeyStore) TimedUnlock(a accounts.Account, passphrase string, timeout t
 	return nil
 }
 
+// SetReadOnly configures the keystore to refuse all modifications of the key
+// directory. Keys can still be unlocked and used for signing, but not created,
+// imported, updated or deleted, and their encryption is never upgraded.
+func (ks *KeyStore) SetReadOnly() {
+	atomic.StoreUint32(&ks.readOnly, 1)
+}
+
+// isReadOnly returns whgdtuer modifications of the key directory are refused.
+func (ks *KeyStore) isReadOnly() bool {
+	return atomic.LoadUint32(&ks.readOnly) == 1
+}
+
+// EnableKDFUpgrade configures the keystore to re-encrypt keys protected by a
+// weaker KDF (or weaker parameters) than the one used for new keys, whenever they
+// are unlocked.
+func (ks *KeyStore) EnableKDFUpgrade() {
+	atomic.StoreUint32(&ks.upgradeKDF, 1)
+}
+
+// KDFMigration is the progress of re-encrypting keys with the KDF of the keystore.
+type KDFMigration struct {
+	Enabled  bool   `json:"enabled"`  // Whgdtuer keys are re-encrypted on unlock
+	KDF      string `json:"kdf"`      // Name of the KDF used to encrypt new keys
+	Upgraded uint64 `json:"upgraded"` // Number of keys re-encrypted since startup
+	Failed   uint64 `json:"failed"`   // Number of keys failed to be re-encrypted since startup
+	Outdated int    `json:"outdated"` // Number of keys still protected by a weaker KDF
+}
+
+// KDFMigration returns the progress of re-encrypting keys protected by a weaker
+// KDF than the one used for new keys.
+func (ks *KeyStore) KDFMigration() KDFMigration {
+	migration := KDFMigration{
+		Enabled:  atomic.LoadUint32(&ks.upgradeKDF) == 1 && !ks.isReadOnly(),
+		Upgraded: atomic.LoadUint64(&ks.kdfUpgraded),
+		Failed:   atomic.LoadUint64(&ks.kdfFailed),
+	}
+	storage, ok := ks.storage.(*keyStorePassphrase)
+	if !ok {
+		return migration
+	}
+	migration.KDF = storage.keyKDF().Name()
+	for _, a := range ks.Accounts() {
+		if outdated, err := storage.outdated(a.URL.Path); err == nil && outdated {
+			migration.Outdated++
+		}
+	}
+	return migration
+}
+
+// maybeUpgradeKey re-encrypts an unlocked key if upgrading is enabled and it's
+// protected by a weaker KDF than the one used for new keys.
+func (ks *KeyStore) maybeUpgradeKey(a accounts.Account, key *Key, auth string) {
+	storage, ok := ks.storage.(*keyStorePassphrase)
+	if !ok || atomic.LoadUint32(&ks.upgradeKDF) == 0 || ks.isReadOnly() {
+		return
+	}
+	if outdated, err := storage.outdated(a.URL.Path); err != nil || !outdated {
+		return
+	}
+	if err := storage.StoreKey(a.URL.Path, key, auth); err != nil {
+		atomic.AddUint64(&ks.kdfFailed, 1)
+		log.Warn("Failed to upgrade key encryption", "address", a.Address, "err", err)
+		return
+	}
+	atomic.AddUint64(&ks.kdfUpgraded, 1)
+	log.Info("Upgraded key encryption", "address", a.Address, "kdf", storage.keyKDF().Name())
+}
+
 // Find resolves the given account into a unique entry in the keystore.
 func (ks *KeyStore) Find(a accounts.Account) (accounts.Account, error) {
 	ks.cache.maybeReload()
@@ -407,6 +499,9 @@ func (ks *KeyStore) expire(addr common.Address, u *unlocked, timeout time.Durati
 // NewAccount generates a new key and stores it into the key directory,
 // encrypting it with the passphrase.
 func (ks *KeyStore) NewAccount(passphrase string) (accounts.Account, error) {
+	if ks.isReadOnly() {
+		return accounts.Account{}, ErrReadOnly
+	}
 	_, account, err := storeNewKey(ks.storage, crand.Reader, passphrase)
 	if err != nil {
 		return accounts.Account{}, err
@@ -435,6 +530,9 @@ func (ks *KeyStore) Export(a accounts.Account, passphrase, newPassphrase string)
 
 // Import stores the given encrypted JSON key into the key directory.
 func (ks *KeyStore) Import(keyJSON []byte, passphrase, newPassphrase string) (accounts.Account, error) {
+	if ks.isReadOnly() {
+		return accounts.Account{}, ErrReadOnly
+	}
 	key, err := DecryptKey(keyJSON, passphrase)
 	if key != nil && key.PrivateKey != nil {
 		defer zeroKey(key.PrivateKey)
@@ -455,6 +553,9 @@ func (ks *KeyStore) Import(keyJSON []byte, passphrase, newPassphrase string) (ac
 
 // ImportECDSA stores the given key into the key directory, encrypting it with the passphrase.
 func (ks *KeyStore) ImportECDSA(priv *ecdsa.PrivateKey, passphrase string) (accounts.Account, error) {
+	if ks.isReadOnly() {
+		return accounts.Account{}, ErrReadOnly
+	}
 	ks.importMu.Lock()
 	defer ks.importMu.Unlock()
 
@@ -479,6 +580,9 @@ func (ks *KeyStore) importKey(key *Key, passphrase string) (accounts.Account, er
 
 // Update changes the passphrase of an existing account.
 func (ks *KeyStore) Update(a accounts.Account, passphrase, newPassphrase string) error {
+	if ks.isReadOnly() {
+		return ErrReadOnly
+	}
 	a, key, err := ks.getDecryptedKey(a, passphrase)
 	if err != nil {
 		return err
@@ -489,6 +593,9 @@ func (ks *KeyStore) Update(a accounts.Account, passphrase, newPassphrase string)
 // ImportPreSaleKey decrypts the given Gdtu presale wallet and stores
 // a key file in the key directory. The key file is encrypted with the same passphrase.
 func (ks *KeyStore) ImportPreSaleKey(keyJSON []byte, passphrase string) (accounts.Account, error) {
+	if ks.isReadOnly() {
+		return accounts.Account{}, ErrReadOnly
+	}
 	a, _, err := importPreSaleKey(ks.storage, keyJSON, passphrase)
 	if err != nil {
 		return a, err
@@ -472,3 +472,102 @@ func tmpKeyStore(t *testing.T, encrypted bool) (string, *KeyStore) {
 	}
 	return d, newKs(d)
 }
+
+// Tests that keys protected by a weaker KDF are re-encrypted when unlocked.
+func TestUnlockUpgradeKDF(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	a1, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reopen the keystore with a stronger KDF, keys shouldn't be touched until
+	// upgrading is enabled
+	ks = NewKeyStoreWithKDF(dir, NewArgon2idKDF(1, 64))
+	if err := ks.Unlock(a1, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if migration := ks.KDFMigration(); migration.Enabled || migration.Upgraded != 0 || migration.Outdated != 1 {
+		t.Fatalf("migration mismatch before enabling: %+v", migration)
+	}
+	ks.EnableKDFUpgrade()
+
+	// A failed unlock shouldn't upgrade the key
+	if err := ks.Unlock(a1, "bar"); err != ErrDecrypt {
+		t.Fatalf("unlock error mismatch: have %v, want %v", err, ErrDecrypt)
+	}
+	if err := ks.Unlock(a1, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	want := KDFMigration{Enabled: true, KDF: "argon2id", Upgraded: 1}
+	if migration := ks.KDFMigration(); migration != want {
+		t.Fatalf("migration mismatch: have %+v, want %+v", migration, want)
+	}
+	keyjson, err := ioutil.ReadFile(a1.URL.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, err := DecryptKey(keyjson, "foo"); err != nil || key.Address != a1.Address {
+		t.Fatalf("upgraded key invalid: %v", err)
+	}
+	// Unlocking again shouldn't re-encrypt
+	if err := ks.Unlock(a1, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if migration := ks.KDFMigration(); migration.Upgraded != 1 {
+		t.Fatalf("key upgraded twice: %+v", migration)
+	}
+}
+
+// Tests that read-only keystores refuse to modify the key directory, but still
+// allow unlocking and signing with the keys.
+func TestReadOnlyKeyStore(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	a1, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyjson, err := ks.Export(a1, "foo", "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks = NewKeyStoreWithKDF(dir, NewArgon2idKDF(1, 64))
+	ks.EnableKDFUpgrade()
+	ks.SetReadOnly()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.NewAccount("foo"); err != ErrReadOnly {
+		t.Errorf("new account error mismatch: have %v, want %v", err, ErrReadOnly)
+	}
+	if _, err := ks.ImportECDSA(key, "foo"); err != ErrReadOnly {
+		t.Errorf("key import error mismatch: have %v, want %v", err, ErrReadOnly)
+	}
+	if _, err := ks.Import(keyjson, "foo", "bar"); err != ErrReadOnly {
+		t.Errorf("json import error mismatch: have %v, want %v", err, ErrReadOnly)
+	}
+	if err := ks.Update(a1, "foo", "bar"); err != ErrReadOnly {
+		t.Errorf("update error mismatch: have %v, want %v", err, ErrReadOnly)
+	}
+	if err := ks.Delete(a1, "foo"); err != ErrReadOnly {
+		t.Errorf("delete error mismatch: have %v, want %v", err, ErrReadOnly)
+	}
+	// Keys are still usable, but never re-encrypted
+	if err := ks.Unlock(a1, "foo"); err != nil {
+		t.Fatalf("unlock failed: %v", err)
+	}
+	if _, err := ks.SignHash(accounts.Account{Address: a1.Address}, testSigData); err != nil {
+		t.Fatalf("signing failed: %v", err)
+	}
+	if migration := ks.KDFMigration(); migration.Enabled || migration.Upgraded != 0 || migration.Outdated != 1 {
+		t.Fatalf("read-only key upgraded: %+v", migration)
+	}
+	if accs := ks.Accounts(); len(accs) != 1 || accs[0].Address != a1.Address {
+		t.Fatalf("accounts mismatch: %v", accs)
+	}
+}
@@ -29,7 +29,6 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -43,8 +42,6 @@ import (
 	"github.com/c88032111/go-gdtu/common/math"
 	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/pbkdf2"
-	"golang.org/x/crypto/scrypt"
 )
 
 const (
@@ -78,6 +75,42 @@ type keyStorePassphrase struct {
 	// reads and decrypts any newly created keyfiles. This should be 'false' in all
 	// cases except tests -- setting this to 'true' is not recommended.
 	skipKeyFileVerification bool
+	// kdf is the key derivation function used to encrypt new keys, overriding
+	// the scrypt parameters above if set.
+	kdf KDF
+}
+
+// keyKDF returns the key derivation function used to encrypt new keys.
+func (ks keyStorePassphrase) keyKDF() KDF {
+	if ks.kdf != nil {
+		return ks.kdf
+	}
+	return NewScryptKDF(ks.scryptN, ks.scryptP)
+}
+
+// outdated reports whether the key file is protected by a weaker KDF than the
+// one used to encrypt new keys.
+func (ks keyStorePassphrase) outdated(filename string) (bool, error) {
+	keyjson, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return false, err
+	}
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(keyjson, &m); err != nil {
+		return false, err
+	}
+	if version, ok := m["version"].(string); ok && version == "1" {
+		return true, nil
+	}
+	k := new(encryptedKeyJSONV3)
+	if err := json.Unmarshal(keyjson, k); err != nil {
+		return false, err
+	}
+	kdf, err := parseKDF(k.Crypto.KDF, k.Crypto.KDFParams)
+	if err != nil {
+		return false, err
+	}
+	return weakerKDF(kdf, ks.keyKDF()), nil
 }
 
 func (ks keyStorePassphrase) GetKey(addr common.Address, filename, auth string) (*Key, error) {
@@ -99,12 +132,19 @@ func (ks keyStorePassphrase) GetKey(addr common.Address, filename, auth string)
 
 // StoreKey generates a key, encrypts with 'auth' and stores in the given directory
 func StoreKey(dir, auth string, scryptN, scryptP int) (accounts.Account, error) {
-	_, a, err := storeNewKey(&keyStorePassphrase{dir, scryptN, scryptP, false}, rand.Reader, auth)
+	_, a, err := storeNewKey(&keyStorePassphrase{dir, scryptN, scryptP, false, nil}, rand.Reader, auth)
+	return a, err
+}
+
+// StoreKeyWithKDF generates a key, encrypts with 'auth' using the given key
+// derivation function and stores in the given directory
+func StoreKeyWithKDF(dir, auth string, kdf KDF) (accounts.Account, error) {
+	_, a, err := storeNewKey(&keyStorePassphrase{dir, StandardScryptN, StandardScryptP, false, kdf}, rand.Reader, auth)
 	return a, err
 }
 
 func (ks keyStorePassphrase) StoreKey(filename string, key *Key, auth string) error {
-	keyjson, err := EncryptKey(key, auth, ks.scryptN, ks.scryptP)
+	keyjson, err := EncryptKeyWithKDF(key, auth, ks.keyKDF())
 	if err != nil {
 		return err
 	}
@@ -139,12 +179,17 @@ func (ks keyStorePassphrase) JoinPath(filename string) string {
 
 // Encryptdata encrypts the data given as 'data' with the password 'auth'.
 func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error) {
+	return EncryptDataWithKDF(data, auth, NewScryptKDF(scryptN, scryptP))
+}
 
+// EncryptDataWithKDF encrypts the data given as 'data' with the password 'auth',
+// deriving the encryption key with the given KDF.
+func EncryptDataWithKDF(data, auth []byte, kdf KDF) (CryptoJSON, error) {
 	salt := make([]byte, 32)
 	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
 		panic("reading from crypto/rand failed: " + err.Error())
 	}
-	derivedKey, err := scrypt.Key(auth, salt, scryptN, scryptR, scryptP, scryptDKLen)
+	derivedKey, err := kdf.DeriveKey(auth, salt, scryptDKLen)
 	if err != nil {
 		return CryptoJSON{}, err
 	}
@@ -160,12 +205,9 @@ func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error)
 	}
 	mac := crypto.Keccak256(derivedKey[16:32], cipherText)
 
-	scryptParamsJSON := make(map[string]interface{}, 5)
-	scryptParamsJSON["n"] = scryptN
-	scryptParamsJSON["r"] = scryptR
-	scryptParamsJSON["p"] = scryptP
-	scryptParamsJSON["dklen"] = scryptDKLen
-	scryptParamsJSON["salt"] = hex.EncodeToString(salt)
+	kdfParamsJSON := kdf.Params()
+	kdfParamsJSON["dklen"] = scryptDKLen
+	kdfParamsJSON["salt"] = hex.EncodeToString(salt)
 	cipherParamsJSON := cipherparamsJSON{
 		IV: hex.EncodeToString(iv),
 	}
@@ -174,8 +216,8 @@ func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error)
 		Cipher:       "aes-128-ctr",
 		CipherText:   hex.EncodeToString(cipherText),
 		CipherParams: cipherParamsJSON,
-		KDF:          keyHeaderKDF,
-		KDFParams:    scryptParamsJSON,
+		KDF:          kdf.Name(),
+		KDFParams:    kdfParamsJSON,
 		MAC:          hex.EncodeToString(mac),
 	}
 	return cryptoStruct, nil
@@ -184,8 +226,14 @@ func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error)
 // EncryptKey encrypts a key using the specified scrypt parameters into a json
 // blob that can be decrypted later on.
 func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
+	return EncryptKeyWithKDF(key, auth, NewScryptKDF(scryptN, scryptP))
+}
+
+// EncryptKeyWithKDF encrypts a key into a json blob that can be decrypted later
+// on, deriving the encryption key from the passphrase with the given KDF.
+func EncryptKeyWithKDF(key *Key, auth string, kdf KDF) ([]byte, error) {
 	keyBytes := math.PaddedBigBytes(key.PrivateKey.D, 32)
-	cryptoStruct, err := EncryptDataV3(keyBytes, []byte(auth), scryptN, scryptP)
+	cryptoStruct, err := EncryptDataWithKDF(keyBytes, []byte(auth), kdf)
 	if err != nil {
 		return nil, err
 	}
@@ -330,39 +378,21 @@ func decryptKeyV1(keyProtected *encryptedKeyJSONV1, auth string) (keyBytes []byt
 }
 
 func getKDFKey(cryptoJSON CryptoJSON, auth string) ([]byte, error) {
-	authArray := []byte(auth)
-	salt, err := hex.DecodeString(cryptoJSON.KDFParams["salt"].(string))
+	salt, ok := cryptoJSON.KDFParams["salt"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing KDF salt")
+	}
+	saltBytes, err := hex.DecodeString(salt)
 	if err != nil {
 		return nil, err
 	}
-	dkLen := ensureInt(cryptoJSON.KDFParams["dklen"])
-
-	if cryptoJSON.KDF == keyHeaderKDF {
-		n := ensureInt(cryptoJSON.KDFParams["n"])
-		r := ensureInt(cryptoJSON.KDFParams["r"])
-		p := ensureInt(cryptoJSON.KDFParams["p"])
-		return scrypt.Key(authArray, salt, n, r, p, dkLen)
-
-	} else if cryptoJSON.KDF == "pbkdf2" {
-		c := ensureInt(cryptoJSON.KDFParams["c"])
-		prf := cryptoJSON.KDFParams["prf"].(string)
-		if prf != "hmac-sha256" {
-			return nil, fmt.Errorf("unsupported PBKDF2 PRF: %s", prf)
-		}
-		key := pbkdf2.Key(authArray, salt, c, dkLen, sha256.New)
-		return key, nil
+	dkLen, err := kdfParam(cryptoJSON.KDFParams, "dklen")
+	if err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("unsupported KDF: %s", cryptoJSON.KDF)
-}
-
-// TODO: can we do without this when unmarshalling dynamic JSON?
-// why do integers in KDF params end up as float64 and not int after
-// unmarshal?
-func ensureInt(x interface{}) int {
-	res, ok := x.(int)
-	if !ok {
-		res = int(x.(float64))
+	kdf, err := parseKDF(cryptoJSON.KDF, cryptoJSON.KDFParams)
+	if err != nil {
+		return nil, err
 	}
-	return res
+	return kdf.DeriveKey([]byte(auth), saltBytes, dkLen)
 }
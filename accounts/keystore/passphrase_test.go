@@ -18,9 +18,11 @@ package keystore
 
 import (
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/c88032111/go-gdtu/common"
+	"golang.org/x/crypto/argon2"
 )
 
 const (
@@ -58,3 +60,86 @@ func TestKeyEncryptDecrypt(t *testing.T) {
 		}
 	}
 }
+
+// Tests that keys can be encrypted and decrypted with all the supported KDFs.
+func TestKeyEncryptDecryptKDF(t *testing.T) {
+	keyjson, err := ioutil.ReadFile("testdata/very-light-scrypt.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := DecryptKey(keyjson, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kdf := range []KDF{NewScryptKDF(veryLightScryptN, veryLightScryptP), NewArgon2idKDF(1, 64)} {
+		keyjson, err := EncryptKeyWithKDF(key, "pass", kdf)
+		if err != nil {
+			t.Fatalf("%s: failed to encrypt key: %v", kdf.Name(), err)
+		}
+		if !strings.Contains(string(keyjson), `"kdf":"`+kdf.Name()+`"`) {
+			t.Errorf("%s: kdf missing from key file: %s", kdf.Name(), keyjson)
+		}
+		if _, err := DecryptKey(keyjson, "bad"); err != ErrDecrypt {
+			t.Errorf("%s: decrypt error mismatch: have %v, want %v", kdf.Name(), err, ErrDecrypt)
+		}
+		decrypted, err := DecryptKey(keyjson, "pass")
+		if err != nil {
+			t.Fatalf("%s: failed to decrypt key: %v", kdf.Name(), err)
+		}
+		if decrypted.Address != key.Address {
+			t.Errorf("%s: key address mismatch: have %x, want %x", kdf.Name(), decrypted.Address, key.Address)
+		}
+	}
+}
+
+// Tests that Argon2id parameters outside of the supported range are rejected when
+// loading a key file.
+func TestParseArgon2idLimits(t *testing.T) {
+	tests := []struct {
+		t, m, p int
+		valid   bool
+	}{
+		{LightArgon2idTime, LightArgon2idMemory, argon2idThreads, true},
+		{maxArgon2idTime, maxArgon2idMemory, argon2idThreads, true},
+		{0, LightArgon2idMemory, argon2idThreads, false},
+		{1, 8*argon2idThreads - 1, argon2idThreads, false},
+		{maxArgon2idTime + 1, LightArgon2idMemory, argon2idThreads, false},
+		{LightArgon2idTime, maxArgon2idMemory + 1, argon2idThreads, false},
+		{1 << 31, 1 << 31, argon2idThreads, false},
+	}
+	for i, tt := range tests {
+		// Key files are decoded from JSON, so the numbers arrive as floats
+		params := map[string]interface{}{
+			"v": float64(argon2.Version),
+			"t": float64(tt.t),
+			"m": float64(tt.m),
+			"p": float64(tt.p),
+		}
+		if _, err := parseKDF(argon2idKDF, params); (err == nil) != tt.valid {
+			t.Errorf("test %d: t=%d m=%d p=%d: error mismatch: have %v, want valid %v", i, tt.t, tt.m, tt.p, err, tt.valid)
+		}
+	}
+}
+
+// Tests that KDF strength is compared correctly.
+func TestWeakerKDF(t *testing.T) {
+	tests := []struct {
+		have, want KDF
+		weaker     bool
+	}{
+		{NewScryptKDF(LightScryptN, LightScryptP), NewScryptKDF(LightScryptN, LightScryptP), false},
+		{NewScryptKDF(LightScryptN, LightScryptP), NewScryptKDF(StandardScryptN, StandardScryptP), true},
+		{NewScryptKDF(StandardScryptN, StandardScryptP), NewScryptKDF(LightScryptN, 1), false},
+		{NewScryptKDF(StandardScryptN, StandardScryptP), NewArgon2idKDF(LightArgon2idTime, LightArgon2idMemory), false},
+		{NewScryptKDF(LightScryptN, LightScryptP), NewArgon2idKDF(StandardArgon2idTime, StandardArgon2idMemory), true},
+		{NewArgon2idKDF(StandardArgon2idTime, StandardArgon2idMemory), NewScryptKDF(StandardScryptN, StandardScryptP), false},
+		{&pbkdf2HMACSHA256{c: 262144}, NewScryptKDF(LightScryptN, LightScryptP), true},
+		{NewArgon2idKDF(LightArgon2idTime, StandardArgon2idMemory), NewArgon2idKDF(StandardArgon2idTime, StandardArgon2idMemory), true},
+		{NewArgon2idKDF(StandardArgon2idTime, StandardArgon2idMemory), NewArgon2idKDF(LightArgon2idTime, LightArgon2idMemory), false},
+	}
+	for i, tt := range tests {
+		if weaker := weakerKDF(tt.have, tt.want); weaker != tt.weaker {
+			t.Errorf("test %d: weaker mismatch: have %v, want %v", i, weaker, tt.weaker)
+		}
+	}
+}
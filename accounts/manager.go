@@ -33,14 +33,23 @@ type Config struct {
 	InsecureUnlockAllowed bool // Whgdtuer account unlocking in insecure environment is allowed
 }
 
+// newBackendEvent lets the manager know it should
+// track the given backend for wallet updates.
+type newBackendEvent struct {
+	backend   Backend
+	processed chan struct{} // Informs event emitter that backend has been integrated
+}
+
 // Manager is an overarching account manager that can communicate with various
 // backends for signing transactions.
 type Manager struct {
-	config   *Config                    // Global account manager configurations
-	backends map[reflect.Type][]Backend // Index of backends currently registered
-	updaters []event.Subscription       // Wallet update subscriptions for all backends
-	updates  chan WalletEvent           // Subscription sink for backend wallet changes
-	wallets  []Wallet                   // Cache of all wallets from all registered backends
+	config      *Config                    // Global account manager configurations
+	backends    map[reflect.Type][]Backend // Index of backends currently registered
+	order       []Backend                  // Backends in registration order, defining precedence
+	updaters    []event.Subscription       // Wallet update subscriptions for all backends
+	updates     chan WalletEvent           // Subscription sink for backend wallet changes
+	newBackends chan newBackendEvent       // Incoming backends to be tracked by the manager
+	wallets     []Wallet                   // Cache of all wallets from all registered backends
 
 	feed event.Feed // Wallet feed notifying of arrivals/departures
 
@@ -65,17 +74,19 @@ func NewManager(config *Config, backends ...Backend) *Manager {
 	}
 	// Assemble the account manager and return
 	am := &Manager{
-		config:   config,
-		backends: make(map[reflect.Type][]Backend),
-		updaters: subs,
-		updates:  updates,
-		wallets:  wallets,
-		quit:     make(chan chan error),
+		config:      config,
+		backends:    make(map[reflect.Type][]Backend),
+		updaters:    subs,
+		updates:     updates,
+		newBackends: make(chan newBackendEvent),
+		wallets:     wallets,
+		quit:        make(chan chan error),
 	}
 	for _, backend := range backends {
 		kind := reflect.TypeOf(backend)
 		am.backends[kind] = append(am.backends[kind], backend)
 	}
+	am.order = append(am.order, backends...)
 	go am.update()
 
 	return am
@@ -88,6 +99,14 @@ func (am *Manager) Close() error {
 	return <-errc
 }
 
+// AddBackend starts the tracking of an additional backend for wallet updates.
+// Once the Method returns, the wallets of the backend are known to the manager.
+func (am *Manager) AddBackend(backend Backend) {
+	done := make(chan struct{})
+	am.newBackends <- newBackendEvent{backend, done}
+	<-done
+}
+
 // Config returns the configuration of account manager.
 func (am *Manager) Config() *Config {
 	return am.config
@@ -123,6 +142,18 @@ func (am *Manager) update() {
 			// Notify any listeners of the event
 			am.feed.Send(event)
 
+		case event := <-am.newBackends:
+			// New backend added, track its wallets and updates
+			am.lock.Lock()
+			backend := event.backend
+			am.wallets = merge(am.wallets, backend.Wallets()...)
+			am.updaters = append(am.updaters, backend.Subscribe(am.updates))
+			kind := reflect.TypeOf(backend)
+			am.backends[kind] = append(am.backends[kind], backend)
+			am.order = append(am.order, backend)
+			am.lock.Unlock()
+			close(event.processed)
+
 		case errc := <-am.quit:
 			// Manager terminating, return
 			errc <- nil
@@ -131,7 +162,8 @@ func (am *Manager) update() {
 	}
 }
 
-// Backends retrieves the backend(s) with the given type from the account manager.
+// Backends retrieves the backend(s) with the given type from the account manager,
+// in the order they were registered.
 func (am *Manager) Backends(kind reflect.Type) []Backend {
 	return am.backends[kind]
 }
@@ -185,16 +217,53 @@ func (am *Manager) Accounts() []common.Address {
 // Find attempts to locate the wallet corresponding to a specific account. Since
 // accounts can be dynamically added to and removed from wallets, this Method has
 // a linear runtime in the number of wallets.
+//
+// If the account is contained in wallets of several backends, the wallet of the
+// earliest registered backend is returned.
 func (am *Manager) Find(account Account) (Wallet, error) {
 	am.lock.RLock()
 	defer am.lock.RUnlock()
 
+	var found []Wallet
 	for _, wallet := range am.wallets {
 		if wallet.Contains(account) {
-			return wallet, nil
+			found = append(found, wallet)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, ErrUnknownAccount
+	case 1:
+		return found[0], nil
+	}
+	for _, backend := range am.order {
+		for _, owned := range backend.Wallets() {
+			for _, wallet := range found {
+				if owned.URL() == wallet.URL() {
+					return wallet, nil
+				}
+			}
+		}
+	}
+	return found[0], nil
+}
+
+// FindDerived attempts to locate the hierarchical deterministic wallet which
+// derives the given address at the given derivation path, without requiring
+// the account to be pinned. The returned account carries the derivation path
+// in its URL, so the wallet can sign with it. Since deriving may require
+// communicating with hardware devices, this method is slow compared to Find.
+func (am *Manager) FindDerived(address common.Address, path DerivationPath) (Wallet, Account, error) {
+	for _, wallet := range am.Wallets() {
+		account, err := wallet.Derive(path, false)
+		if err != nil {
+			continue // Not a (open) HD wallet
+		}
+		if account.Address == address {
+			return wallet, account, nil
 		}
 	}
-	return nil, ErrUnknownAccount
+	return nil, Account{}, ErrUnknownAccount
 }
 
 // Subscribe creates an async subscription to receive notifications when the
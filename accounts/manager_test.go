@@ -0,0 +1,167 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package accounts
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/event"
+)
+
+// testHDWallet is a wallet deriving a fixed address for each known path.
+type testHDWallet struct {
+	Wallet // Unimplemented methods panic
+	url    URL
+	addrs  map[string]common.Address
+}
+
+func (w *testHDWallet) URL() URL { return w.url }
+
+func (w *testHDWallet) Derive(path DerivationPath, pin bool) (Account, error) {
+	addr, ok := w.addrs[path.String()]
+	if !ok {
+		return Account{}, fmt.Errorf("unknown path %v", path)
+	}
+	return Account{Address: addr, URL: URL{Scheme: w.url.Scheme, Path: fmt.Sprintf("%s/%s", w.url.Path, path)}}, nil
+}
+
+// testBackend is a backend with a static set of wallets.
+type testBackend struct {
+	wallets []Wallet
+	feed    event.Feed
+}
+
+func (b *testBackend) Wallets() []Wallet { return b.wallets }
+
+func (b *testBackend) Subscribe(sink chan<- WalletEvent) event.Subscription {
+	return b.feed.Subscribe(sink)
+}
+
+// Tests that accounts can be located on HD wallets by their derivation path.
+func TestManagerFindDerived(t *testing.T) {
+	path0 := DefaultBaseDerivationPath
+	path1, _ := ParseDerivationPath("m/44'/60'/0'/0/1")
+
+	wallet1 := &testHDWallet{
+		url:   URL{Scheme: "test", Path: "one"},
+		addrs: map[string]common.Address{path0.String(): {0x01}},
+	}
+	wallet2 := &testHDWallet{
+		url:   URL{Scheme: "test", Path: "two"},
+		addrs: map[string]common.Address{path0.String(): {0x02}, path1.String(): {0x03}},
+	}
+	am := NewManager(&Config{}, &testBackend{wallets: []Wallet{wallet1, wallet2}})
+	defer am.Close()
+
+	wallet, account, err := am.FindDerived(common.Address{0x03}, path1)
+	if err != nil {
+		t.Fatalf("failed to find derived account: %v", err)
+	}
+	if wallet != wallet2 {
+		t.Errorf("wallet mismatch: have %v, want %v", wallet.URL(), wallet2.URL())
+	}
+	if want := "test://two/" + path1.String(); account.URL.String() != want {
+		t.Errorf("account URL mismatch: have %v, want %v", account.URL, want)
+	}
+	// Addresses not derived at the given path must not be found
+	if _, _, err := am.FindDerived(common.Address{0x02}, path1); err != ErrUnknownAccount {
+		t.Errorf("wrong path: have error %v, want %v", err, ErrUnknownAccount)
+	}
+	if _, _, err := am.FindDerived(common.Address{0x04}, path0); err != ErrUnknownAccount {
+		t.Errorf("unknown address: have error %v, want %v", err, ErrUnknownAccount)
+	}
+}
+
+// Tests that backends added after the creation of the manager are tracked.
+func TestManagerAddBackend(t *testing.T) {
+	am := NewManager(&Config{})
+	defer am.Close()
+
+	wallet := &testHDWallet{url: URL{Scheme: "test", Path: "one"}}
+	backend := &testBackend{wallets: []Wallet{wallet}}
+	am.AddBackend(backend)
+
+	if wallets := am.Wallets(); len(wallets) != 1 || wallets[0] != wallet {
+		t.Fatalf("wallets mismatch: have %v, want [%v]", wallets, wallet.URL())
+	}
+	if backends := am.Backends(reflect.TypeOf(backend)); len(backends) != 1 {
+		t.Fatalf("backend count mismatch: have %d, want 1", len(backends))
+	}
+	// Wallet events of the added backend should be tracked too
+	backend.feed.Send(WalletEvent{Wallet: wallet, Kind: WalletDropped})
+	for i := 0; i < 100 && len(am.Wallets()) != 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if wallets := am.Wallets(); len(wallets) != 0 {
+		t.Fatalf("dropped wallet still tracked: %v", wallets)
+	}
+}
+
+// testAccountWallet is a wallet holding a fixed set of accounts.
+type testAccountWallet struct {
+	Wallet // Unimplemented methods panic
+	url    URL
+	addrs  []common.Address
+}
+
+func (w *testAccountWallet) URL() URL { return w.url }
+
+func (w *testAccountWallet) Contains(account Account) bool {
+	for _, addr := range w.addrs {
+		if addr == account.Address {
+			return true
+		}
+	}
+	return false
+}
+
+// Tests that accounts contained in wallets of several backends are located in
+// the earliest registered one, regardless of the wallet URL ordering.
+func TestManagerFindPrecedence(t *testing.T) {
+	primary := &testAccountWallet{url: URL{Scheme: "test", Path: "zeta"}, addrs: []common.Address{{0x01}}}
+	shared := &testAccountWallet{url: URL{Scheme: "test", Path: "alpha"}, addrs: []common.Address{{0x01}, {0x02}}}
+	added := &testAccountWallet{url: URL{Scheme: "test", Path: "aaa"}, addrs: []common.Address{{0x02}, {0x03}}}
+
+	am := NewManager(&Config{}, &testBackend{wallets: []Wallet{primary}}, &testBackend{wallets: []Wallet{shared}})
+	defer am.Close()
+	am.AddBackend(&testBackend{wallets: []Wallet{added}})
+
+	tests := []struct {
+		addr common.Address
+		want Wallet
+	}{
+		{common.Address{0x01}, primary},
+		{common.Address{0x02}, shared},
+		{common.Address{0x03}, added},
+	}
+	for _, tt := range tests {
+		wallet, err := am.Find(Account{Address: tt.addr})
+		if err != nil {
+			t.Fatalf("%x: failed to find account: %v", tt.addr, err)
+		}
+		if wallet != tt.want {
+			t.Errorf("%x: wallet mismatch: have %v, want %v", tt.addr, wallet.URL(), tt.want.URL())
+		}
+	}
+	if _, err := am.Find(Account{Address: common.Address{0x04}}); err != ErrUnknownAccount {
+		t.Errorf("unknown account: have error %v, want %v", err, ErrUnknownAccount)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"strings"
 	"sync"
 	"time"
 
@@ -553,10 +554,10 @@ func (w *wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID
 	if w.device == nil {
 		return nil, accounts.ErrWalletClosed
 	}
-	// Make sure the requested account is contained within
-	path, ok := w.paths[account.Address]
-	if !ok {
-		return nil, accounts.ErrUnknownAccount
+	// Make sure the requested account is contained within or its path is known
+	path, err := w.findAccountPath(account)
+	if err != nil {
+		return nil, err
 	}
 	// All infos gathered and metadata checks out, request signing
 	<-w.commsLock
@@ -584,6 +585,22 @@ func (w *wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID
 	return signed, nil
 }
 
+// findAccountPath returns the derivation path of an account, either from the
+// tracked accounts or, for accounts which were derived without pinning, from
+// the path embedded in the account URL. The state lock is assumed to be held.
+func (w *wallet) findAccountPath(account accounts.Account) (accounts.DerivationPath, error) {
+	if path, ok := w.paths[account.Address]; ok {
+		return path, nil
+	}
+	if account.URL == (accounts.URL{}) {
+		return nil, accounts.ErrUnknownAccount
+	}
+	if account.URL.Scheme != w.url.Scheme || !strings.HasPrefix(account.URL.Path, w.url.Path+"/") {
+		return nil, fmt.Errorf("URL %s is not for this wallet", account.URL)
+	}
+	return accounts.ParseDerivationPath(strings.TrimPrefix(account.URL.Path, w.url.Path+"/"))
+}
+
 // SignHashWithPassphrase implements accounts.Wallet, however signing arbitrary
 // data is not supported for Ledger wallets, so this Method will always return
 // an error.
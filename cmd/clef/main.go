@@ -121,6 +121,23 @@ var (
 		Usage: "File used to emit audit logs. Set to \"\" to disable",
 		Value: "audit.log",
 	}
+	auditLogFormatFlag = cli.StringFlag{
+		Name:  "auditlog.format",
+		Usage: "Format of the audit logs (text, jsonl). The jsonl entries are hash chained to make tampering evident",
+		Value: "text",
+	}
+	auditLogMaxSizeFlag = cli.Uint64Flag{
+		Name:  "auditlog.maxsize",
+		Usage: "Size in megabytes at which the jsonl audit log is rotated (0 = no rotation)",
+	}
+	auditLogMaxFilesFlag = cli.IntFlag{
+		Name:  "auditlog.maxfiles",
+		Usage: "Number of rotated jsonl audit logs to retain (0 = retain all)",
+	}
+	auditLogRemoteFlag = cli.StringFlag{
+		Name:  "auditlog.remote",
+		Usage: "HTTPS endpoint the jsonl audit log entries are additionally posted to",
+	}
 	ruleFlag = cli.StringFlag{
 		Name:  "rules",
 		Usage: "Path to the rule file to auto-authorize requests with",
@@ -208,6 +225,19 @@ The newaccount command creates a new keystore-backed account. It is a convenienc
 which can be used in lieu of an external UI.`,
 	}
 
+	verifyAuditLogCommand = cli.Command{
+		Action:    utils.MigrateFlags(verifyAuditLog),
+		Name:      "verify-auditlog",
+		Usage:     "Verify the hash chain of a jsonl audit log",
+		ArgsUsage: "<file>",
+		Flags: []cli.Flag{
+			logLevelFlag,
+		},
+		Description: `
+The verify-auditlog command checks that the entries of a jsonl audit log, including
+its retained rotations, form an unbroken hash chain, i.e. that no entry has been
+altered, inserted or removed after it was written.`,
+	}
 	gendocCommand = cli.Command{
 		Action: GenDoc,
 		Name:   "gendoc",
@@ -238,6 +268,10 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			signerSecretFlag,
 			customDBFlag,
 			auditLogFlag,
+			auditLogFormatFlag,
+			auditLogMaxSizeFlag,
+			auditLogMaxFilesFlag,
+			auditLogRemoteFlag,
 			ruleFlag,
 			stdiouiFlag,
 			testFlag,
@@ -267,6 +301,10 @@ func init() {
 		signerSecretFlag,
 		customDBFlag,
 		auditLogFlag,
+		auditLogFormatFlag,
+		auditLogMaxSizeFlag,
+		auditLogMaxFilesFlag,
+		auditLogRemoteFlag,
 		ruleFlag,
 		stdiouiFlag,
 		testFlag,
@@ -279,6 +317,7 @@ func init() {
 		setCredentialCommand,
 		delCredentialCommand,
 		newAccountCommand,
+		verifyAuditLogCommand,
 		gendocCommand}
 	cli.CommandHelpTemplate = flags.CommandHelpTemplate
 	// Override the default app help template
@@ -473,6 +512,19 @@ func removeCredential(ctx *cli.Context) error {
 	return nil
 }
 
+func verifyAuditLog(c *cli.Context) error {
+	if len(c.Args()) < 1 {
+		utils.Fatalf("This command requires the audit log file to be passed as an argument")
+	}
+	path := c.Args().First()
+	count, err := core.VerifyAuditLog(path)
+	if err != nil {
+		utils.Fatalf("Audit log verification failed after %d entries: %v", count, err)
+	}
+	fmt.Printf("Audit log %s verified, %d entries\n", path, count)
+	return nil
+}
+
 func newAccount(c *cli.Context) error {
 	if err := initialize(c); err != nil {
 		return err
@@ -634,11 +686,29 @@ func signer(c *cli.Context) error {
 	api = apiImpl
 	// Audit logging
 	if logfile := c.GlobalString(auditLogFlag.Name); logfile != "" {
-		api, err = core.NewAuditLogger(logfile, api)
+		var auditor *core.AuditLogger
+		switch format := c.GlobalString(auditLogFormatFlag.Name); format {
+		case "text":
+			if c.GlobalIsSet(auditLogMaxSizeFlag.Name) || c.GlobalIsSet(auditLogMaxFilesFlag.Name) || c.GlobalIsSet(auditLogRemoteFlag.Name) {
+				utils.Fatalf("Audit log rotation and remote endpoint require --%s=jsonl", auditLogFormatFlag.Name)
+			}
+			auditor, err = core.NewAuditLogger(logfile, api)
+		case "jsonl":
+			auditor, err = core.NewStructuredAuditLogger(core.AuditConfig{
+				Path:     logfile,
+				MaxSize:  c.GlobalUint64(auditLogMaxSizeFlag.Name) * 1024 * 1024,
+				MaxFiles: c.GlobalInt(auditLogMaxFilesFlag.Name),
+				Remote:   c.GlobalString(auditLogRemoteFlag.Name),
+			}, api)
+		default:
+			utils.Fatalf("Unknown audit log format %q, want text or jsonl", format)
+		}
 		if err != nil {
 			utils.Fatalf(err.Error())
 		}
-		log.Info("Audit logs configured", "file", logfile)
+		defer auditor.Close()
+		api = auditor
+		log.Info("Audit logs configured", "file", logfile, "format", c.GlobalString(auditLogFormatFlag.Name))
 	}
 	// register signer API with server
 	var (
@@ -28,6 +28,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/core/forkid"
+	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/c88032111/go-gdtu/p2p/enode"
 	"github.com/c88032111/go-gdtu/p2p/enr"
 	"github.com/c88032111/go-gdtu/rlp"
@@ -43,11 +46,118 @@ var enrdumpCommand = cli.Command{
 	},
 }
 
+var (
+	enrCommand = cli.Command{
+		Name:  "enr",
+		Usage: "Operations on node records",
+		Subcommands: []cli.Command{
+			enrDecodeCommand,
+			enrCreateCommand,
+		},
+	}
+	enrDecodeCommand = cli.Command{
+		Name:      "decode",
+		Usage:     "Decodes and validates a node record",
+		ArgsUsage: "<record>",
+		Action:    enrDecode,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "file"},
+			enrVerboseFlag,
+		},
+	}
+)
+
+var enrCreateCommand = cli.Command{
+	Name:      "create",
+	Usage:     "Creates signed node records from node key files",
+	ArgsUsage: "<keyfile>...",
+	Action:    enrCreate,
+	Flags:     []cli.Flag{hostFlag, tcpPortFlag, udpPortFlag, enrSeqFlag},
+	Description: `
+Creates a node record with the given endpoint for each key file, signed by its key.
+The node IDs, enode URLs and records are printed as JSON.`,
+}
+
+var enrSeqFlag = cli.Uint64Flag{
+	Name:  "seq",
+	Usage: "Sequence number of the records",
+	Value: 1,
+}
+
+var enrVerboseFlag = cli.BoolFlag{
+	Name:  "verbose",
+	Usage: "Print all key/value pairs of the record",
+}
+
+func enrCreate(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		return fmt.Errorf("need key files as arguments")
+	}
+	ip, err := parseHostFlag(ctx)
+	if err != nil {
+		return err
+	}
+	infos := make([]nodeKeyInfo, ctx.NArg())
+	for i, file := range ctx.Args() {
+		key, err := crypto.LoadECDSA(file)
+		if err != nil {
+			return err
+		}
+		node, err := signNodeRecord(key, ip, ctx.Int(tcpPortFlag.Name), ctx.Int(udpPortFlag.Name), ctx.Uint64(enrSeqFlag.Name))
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		infos[i] = newNodeKeyInfo(uint64(i), file, node)
+	}
+	return printJSON(infos)
+}
+
 func enrdump(ctx *cli.Context) error {
-	var source string
+	source, err := recordSource(ctx)
+	if err != nil {
+		return err
+	}
+	r, err := parseRecord(source)
+	if err != nil {
+		return fmt.Errorf("INVALID: %v", err)
+	}
+	dumpRecord(os.Stdout, r)
+	return nil
+}
+
+func enrDecode(ctx *cli.Context) error {
+	source, err := recordSource(ctx)
+	if err != nil {
+		return err
+	}
+	r, err := parseRecord(source)
+	if err != nil {
+		return fmt.Errorf("INVALID: %v", err)
+	}
+	if ctx.Bool(enrVerboseFlag.Name) {
+		dumpRecord(os.Stdout, r)
+	} else if n, err := enode.New(enode.ValidSchemes, r); err == nil {
+		fmt.Printf("Node ID: %v\n", n.ID())
+		dumpNodeURL(os.Stdout, n)
+	}
+	dumpCapabilities(os.Stdout, r)
+
+	problems := lintRecord(r)
+	for _, p := range problems {
+		fmt.Printf("(!) %s\n", p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("record has %d problem(s)", len(problems))
+	}
+	return nil
+}
+
+// recordSource returns the textual node record given either as command-line
+// argument or in the file named by the -file flag.
+func recordSource(ctx *cli.Context) (string, error) {
 	if file := ctx.String("file"); file != "" {
 		if ctx.NArg() != 0 {
-			return fmt.Errorf("can't dump record from command-line argument in -file mode")
+			return "", fmt.Errorf("can't dump record from command-line argument in -file mode")
 		}
 		var b []byte
 		var err error
@@ -57,21 +167,14 @@ func enrdump(ctx *cli.Context) error {
 			b, err = ioutil.ReadFile(file)
 		}
 		if err != nil {
-			return err
+			return "", err
 		}
-		source = string(b)
-	} else if ctx.NArg() == 1 {
-		source = ctx.Args()[0]
-	} else {
-		return fmt.Errorf("need record as argument")
+		return string(b), nil
 	}
-
-	r, err := parseRecord(source)
-	if err != nil {
-		return fmt.Errorf("INVALID: %v", err)
+	if ctx.NArg() == 1 {
+		return ctx.Args()[0], nil
 	}
-	dumpRecord(os.Stdout, r)
-	return nil
+	return "", fmt.Errorf("need record as argument")
 }
 
 // dumpRecord creates a human-readable description of the given node record.
@@ -178,6 +281,9 @@ var attrFormatters = map[string]func(rlp.RawValue) (string, bool){
 	"tcp6": formatAttrUint,
 	"udp":  formatAttrUint,
 	"udp6": formatAttrUint,
+	"gdtu": formatAttrEntry(decodeGdtuEntry),
+	"les":  formatAttrEntry(decodeLesEntry),
+	"snap": formatAttrEntry(decodeSnapEntry),
 }
 
 func formatAttrRaw(v rlp.RawValue) (string, bool) {
@@ -205,3 +311,152 @@ func formatAttrUint(v rlp.RawValue) (string, bool) {
 	}
 	return strconv.FormatUint(x, 10), true
 }
+
+func formatAttrEntry(decode func(rlp.RawValue) (string, error)) func(rlp.RawValue) (string, bool) {
+	return func(v rlp.RawValue) (string, bool) {
+		s, err := decode(v)
+		return s, err == nil
+	}
+}
+
+// protocolEntries contains decoders for the entries advertising the protocols
+// supported by a node, in display order.
+var protocolEntries = []struct {
+	key    string
+	decode func(rlp.RawValue) (string, error)
+}{
+	{"gdtu", decodeGdtuEntry},
+	{"les", decodeLesEntry},
+	{"snap", decodeSnapEntry},
+}
+
+// gdtuEntry is the layout of the "gdtu" entry, as published by package gdtu. The
+// protocol packages don't export their entries, records are accessed through
+// enr.WithEntry with these layouts instead, like the node set filters do.
+type gdtuEntry struct {
+	ForkID forkid.ID
+	Rest   []rlp.RawValue `rlp:"tail"`
+}
+
+// lesEntry is the layout of the "les" entry, as published by LES servers.
+type lesEntry struct {
+	VfxVersion uint
+	Rest       []rlp.RawValue `rlp:"tail"`
+}
+
+// snapEntry is the layout of the "snap" entry, as published by package gdtu.
+type snapEntry struct {
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+func decodeGdtuEntry(v rlp.RawValue) (string, error) {
+	var e gdtuEntry
+	if err := rlp.DecodeBytes(v, &e); err != nil {
+		return "", err
+	}
+	next := "none"
+	if e.ForkID.Next != 0 {
+		next = strconv.FormatUint(e.ForkID.Next, 10)
+	}
+	return fmt.Sprintf("fork ID %v, next fork %s", hexutil.Bytes(e.ForkID.Hash[:]), next) + formatExtraFields(e.Rest), nil
+}
+
+func decodeLesEntry(v rlp.RawValue) (string, error) {
+	var e lesEntry
+	if err := rlp.DecodeBytes(v, &e); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("vflux version %d", e.VfxVersion) + formatExtraFields(e.Rest), nil
+}
+
+func decodeSnapEntry(v rlp.RawValue) (string, error) {
+	var e snapEntry
+	if err := rlp.DecodeBytes(v, &e); err != nil {
+		return "", err
+	}
+	return "supported" + formatExtraFields(e.Rest), nil
+}
+
+// formatExtraFields describes the fields of a protocol entry added by newer
+// versions, which are ignored for forward compatibility.
+func formatExtraFields(rest []rlp.RawValue) string {
+	if len(rest) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d unknown fields)", len(rest))
+}
+
+// dumpCapabilities prints the protocols advertised by the given node record.
+func dumpCapabilities(out io.Writer, r *enr.Record) {
+	var lines []string
+	for _, entry := range protocolEntries {
+		var v rlp.RawValue
+		if r.Load(enr.WithEntry(entry.key, &v)) != nil {
+			continue
+		}
+		desc, err := entry.decode(v)
+		if err != nil {
+			desc = "malformed (!)"
+		}
+		lines = append(lines, fmt.Sprintf("  %-4s %s", entry.key, desc))
+	}
+	if len(lines) == 0 {
+		fmt.Fprintln(out, "Record advertises no known protocols.")
+		return
+	}
+	fmt.Fprintln(out, "Protocols:")
+	for _, line := range lines {
+		fmt.Fprintln(out, line)
+	}
+}
+
+// standardEntries creates the p2p/enr types of the well-known keys, which
+// validate the entries while loading them. The public key is checked along
+// with the signature.
+var standardEntries = map[string]func() enr.Entry{
+	"id":   func() enr.Entry { return new(enr.ID) },
+	"ip":   func() enr.Entry { return new(enr.IPv4) },
+	"ip6":  func() enr.Entry { return new(enr.IPv6) },
+	"tcp":  func() enr.Entry { return new(enr.TCP) },
+	"tcp6": func() enr.Entry { return new(enr.TCP6) },
+	"udp":  func() enr.Entry { return new(enr.UDP) },
+	"udp6": func() enr.Entry { return new(enr.UDP6) },
+}
+
+// lintRecord checks the signature and the well-known entries of a node record,
+// returning a description of every problem found.
+func lintRecord(r *enr.Record) []string {
+	var problems []string
+	if _, err := enode.New(enode.ValidSchemes, r); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid record: %v", err))
+	}
+	decoders := make(map[string]func(rlp.RawValue) (string, error))
+	for _, entry := range protocolEntries {
+		decoders[entry.key] = entry.decode
+	}
+	kv := r.AppendElements(nil)[1:]
+	for i := 0; i < len(kv); i += 2 {
+		key := kv[i].(string)
+		val := kv[i+1].(rlp.RawValue)
+		if decode := decoders[key]; decode != nil {
+			if _, err := decode(val); err != nil {
+				problems = append(problems, fmt.Sprintf("malformed %q entry: %v", key, err))
+			}
+		} else if entry := standardEntries[key]; entry != nil {
+			if err := r.Load(entry()); err != nil {
+				problems = append(problems, fmt.Sprintf("malformed %q entry: %x", key, []byte(val)))
+			}
+		}
+	}
+	// Light clients and snap peers only consider nodes also running gdtu.
+	var gdtu rlp.RawValue
+	if r.Load(enr.WithEntry("gdtu", &gdtu)) != nil {
+		for _, key := range []string{"les", "snap"} {
+			var v rlp.RawValue
+			if r.Load(enr.WithEntry(key, &v)) == nil {
+				problems = append(problems, fmt.Sprintf("%q entry without \"gdtu\" entry", key))
+			}
+		}
+	}
+	return problems
+}
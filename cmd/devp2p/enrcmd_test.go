@@ -0,0 +1,101 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of go-gdtu.
+//
+// go-gdtu is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-gdtu is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// algdtu with go-gdtu. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/core/forkid"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+	"github.com/c88032111/go-gdtu/p2p/enr"
+)
+
+func signedTestRecord(t *testing.T, entries ...enr.Entry) *enr.Record {
+	key, _ := crypto.GenerateKey()
+	var r enr.Record
+	for _, e := range entries {
+		r.Set(e)
+	}
+	if err := enode.SignV4(&r, key); err != nil {
+		t.Fatal(err)
+	}
+	return &r
+}
+
+func TestLintRecord(t *testing.T) {
+	fork := forkid.ID{Hash: [4]byte{0xfc, 0x64, 0xec, 0x04}, Next: 1150000}
+
+	tests := []struct {
+		name     string
+		record   *enr.Record
+		problems []string
+	}{
+		{
+			name:   "valid",
+			record: signedTestRecord(t, enr.IPv4{127, 0, 0, 1}, enr.WithEntry("gdtu", gdtuEntry{ForkID: fork}), enr.WithEntry("les", lesEntry{VfxVersion: 1}), enr.WithEntry("snap", snapEntry{})),
+		},
+		{
+			name:     "malformed les",
+			record:   signedTestRecord(t, enr.WithEntry("gdtu", gdtuEntry{ForkID: fork}), enr.WithEntry("les", "v1")),
+			problems: []string{`malformed "les" entry: rlp: expected input list for main.lesEntry`},
+		},
+		{
+			name:     "malformed ip",
+			record:   signedTestRecord(t, enr.WithEntry("ip", []byte{1, 2, 3})),
+			problems: []string{`malformed "ip" entry: 83010203`},
+		},
+		{
+			name:     "malformed ip6",
+			record:   signedTestRecord(t, enr.WithEntry("ip6", []byte{1, 2, 3, 4, 5, 6})),
+			problems: []string{`malformed "ip6" entry: 86010203040506`},
+		},
+		{
+			name:     "snap without gdtu",
+			record:   signedTestRecord(t, enr.WithEntry("snap", snapEntry{})),
+			problems: []string{`"snap" entry without "gdtu" entry`},
+		},
+	}
+	for _, test := range tests {
+		if problems := lintRecord(test.record); !reflect.DeepEqual(problems, test.problems) {
+			t.Errorf("%s: problems mismatch:\nhave %q\nwant %q", test.name, problems, test.problems)
+		}
+	}
+	// Records with a broken signature are rejected
+	r := signedTestRecord(t, enr.WithEntry("gdtu", gdtuEntry{ForkID: fork}))
+	r.Set(enr.WithEntry("snap", snapEntry{}))
+	if problems := lintRecord(r); len(problems) != 1 {
+		t.Errorf("unsigned record: have problems %q, want invalid signature", problems)
+	}
+}
+
+func TestDumpCapabilities(t *testing.T) {
+	r := signedTestRecord(t, enr.WithEntry("gdtu", gdtuEntry{ForkID: forkid.ID{Hash: [4]byte{0xfc, 0x64, 0xec, 0x04}}}), enr.WithEntry("les", lesEntry{VfxVersion: 1}), enr.WithEntry("snap", snapEntry{}))
+
+	var out bytes.Buffer
+	dumpCapabilities(&out, r)
+	want := `Protocols:
+  gdtu fork ID gdfc64ec04, next fork none
+  les  vflux version 1
+  snap supported
+`
+	if out.String() != want {
+		t.Errorf("output mismatch:\nhave %s\nwant %s", out.String(), want)
+	}
+}
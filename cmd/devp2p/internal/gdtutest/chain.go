@@ -132,7 +132,10 @@ func loadChain(chainfile string, genesis string) (*Chain, error) {
 	if err := json.Unmarshal(chainConfig, &gen); err != nil {
 		return nil, err
 	}
-	gblock := gen.ToBlock(nil)
+	gblock, err := gen.ToBlock(nil)
+	if err != nil {
+		return nil, err
+	}
 
 	// Load chain.rlp.
 	fh, err := os.Open(chainfile)
@@ -17,11 +17,25 @@
 package main
 
 import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/c88032111/go-gdtu/accounts/keystore"
 	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/c88032111/go-gdtu/p2p/enode"
+	"github.com/c88032111/go-gdtu/p2p/enr"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -37,8 +51,16 @@ var (
 	keyGenerateCommand = cli.Command{
 		Name:      "generate",
 		Usage:     "Generates node key files",
-		ArgsUsage: "keyfile",
+		ArgsUsage: "keyfile|directory",
 		Action:    genkey,
+		Flags:     []cli.Flag{keySeedFileFlag, keyCountFlag, hostFlag, tcpPortFlag, udpPortFlag},
+		Description: `
+Generates a node key and writes it to the given file.
+
+With --count, the given number of keys is written to the directory, and their node IDs,
+enode URLs and signed node records are printed as JSON. With --seedfile, the keys are
+derived from the secret seed read from the file (or standard input if the file is "-")
+and their index, generating the same keys on every run.`,
 	}
 	keyToNodeCommand = cli.Command{
 		Name:      "to-enode",
@@ -65,19 +87,80 @@ var (
 		Usage: "UDP port of the node",
 		Value: 30303,
 	}
+	keySeedFileFlag = cli.StringFlag{
+		Name:  "seedfile",
+		Usage: "File containing the secret seed to derive the keys from, - for stdin (anyone knowing it can derive them)",
+	}
+	keyCountFlag = cli.IntFlag{
+		Name:  "count",
+		Usage: "Number of keys to generate into the directory",
+	}
 )
 
+// nodeKeyInfo is the JSON description of a generated node key.
+type nodeKeyInfo struct {
+	Index   uint64   `json:"index"`
+	KeyFile string   `json:"keyfile"`
+	ID      enode.ID `json:"id"`
+	URL     string   `json:"enode"`
+	Record  string   `json:"enr"`
+}
+
 func genkey(ctx *cli.Context) error {
 	if ctx.NArg() != 1 {
 		return fmt.Errorf("need key file as argument")
 	}
-	file := ctx.Args().Get(0)
-
-	key, err := crypto.GenerateKey()
+	var (
+		file   = ctx.Args().Get(0)
+		count  = ctx.Int(keyCountFlag.Name)
+		master []byte
+	)
+	if seedfile := ctx.String(keySeedFileFlag.Name); seedfile != "" {
+		seed, err := readSeed(seedfile)
+		if err != nil {
+			return err
+		}
+		if master, err = seedMasterKey(seed); err != nil {
+			return err
+		}
+	}
+	newKey := func(index uint64) (*ecdsa.PrivateKey, error) {
+		if master != nil {
+			return deriveNodeKey(master, index), nil
+		}
+		return crypto.GenerateKey()
+	}
+	if count <= 0 {
+		key, err := newKey(0)
+		if err != nil {
+			return fmt.Errorf("could not generate key: %v", err)
+		}
+		return crypto.SaveECDSA(file, key)
+	}
+	ip, err := parseHostFlag(ctx)
 	if err != nil {
-		return fmt.Errorf("could not generate key: %v", err)
+		return err
 	}
-	return crypto.SaveECDSA(file, key)
+	if err := os.MkdirAll(file, 0700); err != nil {
+		return err
+	}
+	infos := make([]nodeKeyInfo, count)
+	for i := range infos {
+		key, err := newKey(uint64(i))
+		if err != nil {
+			return fmt.Errorf("could not generate key: %v", err)
+		}
+		keyfile := filepath.Join(file, fmt.Sprintf("node%d.key", i))
+		if err := crypto.SaveECDSA(keyfile, key); err != nil {
+			return err
+		}
+		node, err := signNodeRecord(key, ip, ctx.Int(tcpPortFlag.Name), ctx.Int(udpPortFlag.Name), 1)
+		if err != nil {
+			return err
+		}
+		infos[i] = newNodeKeyInfo(uint64(i), keyfile, node)
+	}
+	return printJSON(infos)
 }
 
 func keyToURL(ctx *cli.Context) error {
@@ -87,7 +170,6 @@ func keyToURL(ctx *cli.Context) error {
 
 	var (
 		file = ctx.Args().Get(0)
-		host = ctx.String(hostFlag.Name)
 		tcp  = ctx.Int(tcpPortFlag.Name)
 		udp  = ctx.Int(udpPortFlag.Name)
 	)
@@ -95,11 +177,110 @@ func keyToURL(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	ip := net.ParseIP(host)
-	if ip == nil {
-		return fmt.Errorf("invalid IP address %q", host)
+	ip, err := parseHostFlag(ctx)
+	if err != nil {
+		return err
 	}
 	node := enode.NewV4(&key.PublicKey, ip, tcp, udp)
 	fmt.Println(node.URLv4())
 	return nil
 }
+
+// parseHostFlag parses the IP address given by the --ip flag.
+func parseHostFlag(ctx *cli.Context) (net.IP, error) {
+	host := ctx.String(hostFlag.Name)
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", host)
+	}
+	return ip, nil
+}
+
+// seedKeySalt is the scrypt salt of node key seeds. A fixed salt is needed for
+// the derivation to be reproducible.
+const seedKeySalt = "devp2p node key seed"
+
+// readSeed reads the secret seed from the given file, or from standard input if
+// the file is "-". Trailing line breaks are dropped.
+func readSeed(file string) ([]byte, error) {
+	var (
+		seed []byte
+		err  error
+	)
+	if file == "-" {
+		seed, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		seed, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read seed: %v", err)
+	}
+	seed = []byte(strings.TrimRight(string(seed), "\r\n"))
+	if len(seed) == 0 {
+		return nil, errors.New("empty seed")
+	}
+	return seed, nil
+}
+
+// seedMasterKey stretches a seed into the master key of the node keys, using
+// scrypt with the parameters of the keystore to slow down guessing of the seed.
+func seedMasterKey(seed []byte) ([]byte, error) {
+	return scrypt.Key(seed, []byte(seedKeySalt), keystore.StandardScryptN, 8, keystore.StandardScryptP, 32)
+}
+
+// deriveNodeKey derives the node key of the given index from a master key. The
+// key is expanded with HKDF, bound to the index, reading further output in the
+// unlikely case of it not being a valid private key.
+func deriveNodeKey(master []byte, index uint64) *ecdsa.PrivateKey {
+	var info [8]byte
+	binary.BigEndian.PutUint64(info[:], index)
+
+	kdf := hkdf.Expand(sha256.New, master, info[:])
+	for {
+		var secret [32]byte
+		if _, err := io.ReadFull(kdf, secret[:]); err != nil {
+			panic(err) // Output of HKDF-SHA256 is limited to 8160 bytes
+		}
+		if key, err := crypto.ToECDSA(secret[:]); err == nil {
+			return key
+		}
+	}
+}
+
+// signNodeRecord creates a node record with the given endpoint and sequence
+// number, signed by the key.
+func signNodeRecord(key *ecdsa.PrivateKey, ip net.IP, tcp, udp int, seq uint64) (*enode.Node, error) {
+	var r enr.Record
+	r.SetSeq(seq)
+	r.Set(enr.IP(ip))
+	if tcp != 0 {
+		r.Set(enr.TCP(tcp))
+	}
+	if udp != 0 {
+		r.Set(enr.UDP(udp))
+	}
+	if err := enode.SignV4(&r, key); err != nil {
+		return nil, err
+	}
+	return enode.New(enode.ValidSchemes, &r)
+}
+
+func newNodeKeyInfo(index uint64, keyfile string, node *enode.Node) nodeKeyInfo {
+	return nodeKeyInfo{
+		Index:   index,
+		KeyFile: keyfile,
+		ID:      node.ID(),
+		URL:     node.URLv4(),
+		Record:  node.String(),
+	}
+}
+
+// printJSON writes the value to stdout as indented JSON.
+func printJSON(v interface{}) error {
+	out, err := json.MarshalIndent(v, "", jsonIndent)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of go-gdtu.
+//
+// go-gdtu is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-gdtu is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// algdtu with go-gdtu. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+)
+
+func TestDeriveNodeKey(t *testing.T) {
+	master := []byte("fleet master key")
+
+	derive := func(master []byte, index uint64) []byte {
+		return crypto.FromECDSA(deriveNodeKey(master, index))
+	}
+	key0 := derive(master, 0)
+	if !bytes.Equal(key0, derive(master, 0)) {
+		t.Fatalf("key derivation not deterministic")
+	}
+	if bytes.Equal(key0, derive(master, 1)) {
+		t.Fatalf("keys of different indexes collide")
+	}
+	if bytes.Equal(key0, derive([]byte("other master key"), 0)) {
+		t.Fatalf("keys of different master keys collide")
+	}
+}
+
+func TestReadSeed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devp2p-seed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "seed")
+	ioutil.WriteFile(file, []byte("fleet seed\n"), 0600)
+	if seed, err := readSeed(file); err != nil || string(seed) != "fleet seed" {
+		t.Fatalf("seed mismatch: %q, %v", seed, err)
+	}
+	ioutil.WriteFile(file, []byte("\r\n"), 0600)
+	if _, err := readSeed(file); err == nil {
+		t.Fatalf("empty seed accepted")
+	}
+}
+
+func TestSignNodeRecord(t *testing.T) {
+	key := deriveNodeKey([]byte("fleet master key"), 0)
+	node, err := signNodeRecord(key, net.IP{10, 0, 0, 1}, 30303, 30301, 7)
+	if err != nil {
+		t.Fatalf("failed to sign record: %v", err)
+	}
+	info := newNodeKeyInfo(0, "node0.key", node)
+	if info.ID != enode.PubkeyToIDV4(&key.PublicKey) {
+		t.Errorf("node ID mismatch: have %v, want %v", info.ID, enode.PubkeyToIDV4(&key.PublicKey))
+	}
+	dec, err := enode.Parse(enode.ValidSchemes, info.Record)
+	if err != nil {
+		t.Fatalf("failed to parse record: %v", err)
+	}
+	if dec.Seq() != 7 || !dec.IP().Equal(net.IP{10, 0, 0, 1}) || dec.TCP() != 30303 || dec.UDP() != 30301 {
+		t.Errorf("record mismatch: seq %d, ip %v, tcp %d, udp %d", dec.Seq(), dec.IP(), dec.TCP(), dec.UDP())
+	}
+	if pub := dec.Pubkey(); pub == nil || crypto.PubkeyToAddress(*pub) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("record public key mismatch")
+	}
+	if url, err := enode.ParseV4(info.URL); err != nil || url.ID() != info.ID {
+		t.Errorf("enode URL mismatch: %v %v", info.URL, err)
+	}
+}
@@ -58,11 +58,13 @@ func init() {
 	// Add subcommands.
 	app.Commands = []cli.Command{
 		enrdumpCommand,
+		enrCommand,
 		keyCommand,
 		discv4Command,
 		discv5Command,
 		dnsCommand,
 		nodesetCommand,
+		nodedbCommand,
 		rlpxCommand,
 	}
 }
@@ -0,0 +1,132 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of go-gdtu.
+//
+// go-gdtu is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-gdtu is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// algdtu with go-gdtu. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/c88032111/go-gdtu/p2p/enode"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	nodedbCommand = cli.Command{
+		Name:  "nodedb",
+		Usage: "Node database tools",
+		Subcommands: []cli.Command{
+			nodedbExportCommand,
+			nodedbImportCommand,
+			nodedbPruneCommand,
+		},
+	}
+	nodedbExportCommand = cli.Command{
+		Name:      "export",
+		Usage:     "Exports the known nodes of a node database to a nodes.json file",
+		Action:    nodedbExport,
+		ArgsUsage: "<nodedb> <nodes.json>",
+		Flags:     []cli.Flag{nodedbMaxAgeFlag},
+	}
+	nodedbImportCommand = cli.Command{
+		Name:      "import",
+		Usage:     "Imports the nodes of a nodes.json file as seeds into a node database",
+		Action:    nodedbImport,
+		ArgsUsage: "<nodedb> <nodes.json>",
+	}
+	nodedbPruneCommand = cli.Command{
+		Name:      "prune",
+		Usage:     "Removes stale nodes from a node database",
+		Action:    nodedbPrune,
+		ArgsUsage: "<nodedb>",
+		Flags:     []cli.Flag{nodedbMaxAgeFlag, nodedbMaxFailsFlag},
+	}
+)
+
+var (
+	nodedbMaxAgeFlag = cli.DurationFlag{
+		Name:  "maxage",
+		Usage: "Only consider nodes seen within this time (export: 0 = all, prune: 0 = 24h)",
+	}
+	nodedbMaxFailsFlag = cli.IntFlag{
+		Name:  "maxfails",
+		Usage: "Remove node endpoints failing this many findnode requests in a row (0 = disabled)",
+	}
+)
+
+// openNodeDB opens the node database given as the first argument. The node
+// database of a running node is locked, so it must be stopped first.
+func openNodeDB(ctx *cli.Context, nargs int) (*enode.DB, error) {
+	if ctx.NArg() < nargs {
+		return nil, fmt.Errorf("need %d arguments", nargs)
+	}
+	db, err := enode.OpenDB(ctx.Args().First())
+	if err != nil {
+		return nil, fmt.Errorf("can't open node database: %v", err)
+	}
+	return db, nil
+}
+
+func nodedbExport(ctx *cli.Context) error {
+	db, err := openNodeDB(ctx, 2)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ns := make(nodeSet)
+	for _, n := range db.Export(ctx.Duration(nodedbMaxAgeFlag.Name)) {
+		entry := nodeJSON{Seq: n.Seq(), N: n}
+		if seen := db.LastPgdtuReceived(n.ID(), n.IP()); seen.Unix() > 0 {
+			entry.LastResponse = seen
+		}
+		ns[n.ID()] = entry
+	}
+	writeNodesJSON(ctx.Args().Get(1), ns)
+	fmt.Printf("Exported %d nodes.\n", len(ns))
+	return nil
+}
+
+func nodedbImport(ctx *cli.Context) error {
+	db, err := openNodeDB(ctx, 2)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ns := loadNodesJSON(ctx.Args().Get(1))
+	if err := ns.verify(); err != nil {
+		return err
+	}
+	imported, err := db.Import(ns.nodes(), time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d of %d nodes.\n", imported, len(ns))
+	return nil
+}
+
+func nodedbPrune(ctx *cli.Context) error {
+	db, err := openNodeDB(ctx, 1)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	db.SetPruning(ctx.Duration(nodedbMaxAgeFlag.Name), ctx.Int(nodedbMaxFailsFlag.Name))
+	fmt.Printf("Pruned %d nodes.\n", db.Prune())
+	return nil
+}
@@ -127,6 +127,8 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
 		chainConfig.DAOForkBlock.Cmp(new(big.Int).SetUint64(pre.Env.Number)) == 0 {
 		misc.ApplyDAOHardFork(statedb)
 	}
+	// Likewise the irregular state changes of the chain config
+	misc.ApplyIrregularChanges(chainConfig, new(big.Int).SetUint64(pre.Env.Number), statedb)
 
 	for i, tx := range txs {
 		msg, err := tx.AsMessage(signer)
@@ -251,7 +251,11 @@ func newFaucet(genesis *core.Genesis, port int, enodes []*enode.Node, network ui
 	cfg.SyncMode = downloader.LightSync
 	cfg.NetworkId = network
 	cfg.Genesis = genesis
-	utils.SetDNSDiscoveryDefaults(&cfg, genesis.ToBlock(nil).Hash())
+	block, err := genesis.ToBlock(nil)
+	if err != nil {
+		return nil, err
+	}
+	utils.SetDNSDiscoveryDefaults(&cfg, block.Hash())
 
 	lesBackend, err := les.New(stack, &cfg)
 	if err != nil {
@@ -260,7 +264,7 @@ func newFaucet(genesis *core.Genesis, port int, enodes []*enode.Node, network ui
 
 	// Assemble the gdtustats monitoring and reporting service'
 	if stats != "" {
-		if err := gdtustats.New(stack, lesBackend.ApiBackend, lesBackend.Engine(), stats); err != nil {
+		if _, err := gdtustats.New(stack, lesBackend.ApiBackend, lesBackend.Engine(), stats); err != nil {
 			return nil, err
 		}
 	}
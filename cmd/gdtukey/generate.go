@@ -23,16 +23,20 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/c88032111/go-gdtu/accounts"
 	"github.com/c88032111/go-gdtu/accounts/keystore"
 	"github.com/c88032111/go-gdtu/cmd/utils"
 	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/google/uuid"
+	"github.com/tyler-smith/go-bip39"
 	"gopkg.in/urfave/cli.v1"
 )
 
 type outputGenerate struct {
 	Address      string
 	AddressEIP55 string
+	Path         string `json:",omitempty"`
+	Mnemonic     string `json:",omitempty"`
 }
 
 var commandGenerate = cli.Command{
@@ -44,6 +48,11 @@ Generate a new keyfile.
 
 If you want to encrypt an existing private key, it can be specified by setting
 --privatekey with the location of the file containing the private key.
+
+The key can also be derived from a BIP-39 mnemonic at the derivation path given
+by --path: either from a new mnemonic generated by setting --mnemonic, or from an
+existing one contained in the file given by --mnemonicfile. Make sure to write a
+newly generated mnemonic down, it is printed only once!
 `,
 	Flags: []cli.Flag{
 		passphraseFlag,
@@ -56,6 +65,13 @@ If you want to encrypt an existing private key, it can be specified by setting
 			Name:  "lightkdf",
 			Usage: "use less secure scrypt parameters",
 		},
+		cli.BoolFlag{
+			Name:  "mnemonic",
+			Usage: "generate a new BIP-39 mnemonic and derive the key from it",
+		},
+		mnemonicFileFlag,
+		mnemonicPassphraseFlag,
+		derivationPathFlag,
 	},
 	Action: func(ctx *cli.Context) error {
 		// Check if keyfile path given and make sure it doesn't already exist.
@@ -69,14 +85,31 @@ If you want to encrypt an existing private key, it can be specified by setting
 			utils.Fatalf("Error checking if keyfile exists: %v", err)
 		}
 
-		var privateKey *ecdsa.PrivateKey
-		var err error
+		var (
+			privateKey *ecdsa.PrivateKey
+			path       accounts.DerivationPath
+			mnemonic   string
+			err        error
+		)
 		if file := ctx.String("privatekey"); file != "" {
 			// Load private key from file.
 			privateKey, err = crypto.LoadECDSA(file)
 			if err != nil {
 				utils.Fatalf("Can't load private key: %v", err)
 			}
+		} else if ctx.IsSet(mnemonicFileFlag.Name) {
+			// Derive private key from an existing mnemonic.
+			privateKey, path = deriveMnemonicKey(ctx, getMnemonic(ctx))
+		} else if ctx.Bool("mnemonic") {
+			// Derive private key from a new random mnemonic.
+			entropy, err := bip39.NewEntropy(256)
+			if err != nil {
+				utils.Fatalf("Failed to generate random entropy: %v", err)
+			}
+			if mnemonic, err = bip39.NewMnemonic(entropy); err != nil {
+				utils.Fatalf("Failed to generate mnemonic: %v", err)
+			}
+			privateKey, path = deriveMnemonicKey(ctx, mnemonic)
 		} else {
 			// If not loaded, generate random.
 			privateKey, err = crypto.GenerateKey()
@@ -117,12 +150,22 @@ If you want to encrypt an existing private key, it can be specified by setting
 
 		// Output some information.
 		out := outputGenerate{
-			Address: key.Address.Hex(),
+			Address:  key.Address.Hex(),
+			Mnemonic: mnemonic,
+		}
+		if path != nil {
+			out.Path = path.String()
 		}
 		if ctx.Bool(jsonFlag.Name) {
 			mustPrintJSON(out)
 		} else {
 			fmt.Println("Address:", out.Address)
+			if out.Path != "" {
+				fmt.Println("Path:", out.Path)
+			}
+			if out.Mnemonic != "" {
+				fmt.Println("Mnemonic:", out.Mnemonic)
+			}
 		}
 		return nil
 	},
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/c88032111/go-gdtu/accounts"
 	"github.com/c88032111/go-gdtu/internal/flags"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -38,10 +39,12 @@ func init() {
 	app = flags.NewApp(gitCommit, gitDate, "an Gdtu key manager")
 	app.Commands = []cli.Command{
 		commandGenerate,
+		commandDerive,
 		commandInspect,
 		commandChangePassphrase,
 		commandSignMessage,
 		commandVerifyMessage,
+		commandSignTx,
 	}
 	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
 }
@@ -56,6 +59,19 @@ var (
 		Name:  "json",
 		Usage: "output JSON instead of human-readable format",
 	}
+	mnemonicFileFlag = cli.StringFlag{
+		Name:  "mnemonicfile",
+		Usage: "the file that contains the BIP-39 mnemonic to derive the key from",
+	}
+	mnemonicPassphraseFlag = cli.StringFlag{
+		Name:  "mnemonicpasswordfile",
+		Usage: "the file that contains the optional BIP-39 passphrase of the mnemonic",
+	}
+	derivationPathFlag = cli.StringFlag{
+		Name:  "path",
+		Usage: "the BIP-32 derivation path of the key",
+		Value: accounts.DefaultBaseDerivationPath.String(),
+	}
 )
 
 func main() {
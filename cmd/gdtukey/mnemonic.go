@@ -0,0 +1,160 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of go-gdtu.
+//
+// go-gdtu is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-gdtu is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// algdtu with go-gdtu. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/c88032111/go-gdtu/accounts"
+	"github.com/c88032111/go-gdtu/cmd/utils"
+	"github.com/c88032111/go-gdtu/console/prompt"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/tyler-smith/go-bip39"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputDerive struct {
+	Path       string
+	Address    string
+	PrivateKey string `json:",omitempty"`
+}
+
+var commandDerive = cli.Command{
+	Name:  "derive",
+	Usage: "derive accounts from a mnemonic",
+	Description: `
+Derive the accounts of a BIP-39 mnemonic at the given BIP-32 derivation path.
+
+Multiple consecutive accounts can be derived by setting --count, increasing the
+last component of the path. Private key information can be printed by using the
+--private flag; make sure to use this feature with great caution!`,
+	Flags: []cli.Flag{
+		jsonFlag,
+		mnemonicFileFlag,
+		mnemonicPassphraseFlag,
+		derivationPathFlag,
+		cli.IntFlag{
+			Name:  "count",
+			Usage: "number of consecutive accounts to derive",
+			Value: 1,
+		},
+		cli.BoolFlag{
+			Name:  "private",
+			Usage: "include the private keys in the output",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		seed := getMnemonicSeed(ctx, getMnemonic(ctx))
+		base := getDerivationPath(ctx)
+
+		var (
+			next = accounts.DefaultIterator(base)
+			out  []outputDerive
+		)
+		for i := 0; i < ctx.Int("count"); i++ {
+			path := next()
+			key, err := accounts.DeriveKey(seed, path)
+			if err != nil {
+				utils.Fatalf("Failed to derive key at %s: %v", path, err)
+			}
+			derived := outputDerive{
+				Path:    path.String(),
+				Address: crypto.PubkeyToAddress(key.PublicKey).Hex(),
+			}
+			if ctx.Bool("private") {
+				derived.PrivateKey = hex.EncodeToString(crypto.FromECDSA(key))
+			}
+			out = append(out, derived)
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			for _, derived := range out {
+				fmt.Println("Path:       ", derived.Path)
+				fmt.Println("Address:    ", derived.Address)
+				if derived.PrivateKey != "" {
+					fmt.Println("Private key:", derived.PrivateKey)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// getMnemonic obtains a BIP-39 mnemonic given by the user. It first checks the
+// --mnemonicfile command line flag and ultimately prompts the user for it.
+func getMnemonic(ctx *cli.Context) string {
+	var mnemonic string
+	if file := ctx.String(mnemonicFileFlag.Name); file != "" {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			utils.Fatalf("Failed to read mnemonic file '%s': %v", file, err)
+		}
+		mnemonic = string(content)
+	} else {
+		input, err := prompt.Stdin.PromptPassword("Mnemonic: ")
+		if err != nil {
+			utils.Fatalf("Failed to read mnemonic: %v", err)
+		}
+		mnemonic = input
+	}
+	// Tolerate any spacing between the words
+	return strings.Join(strings.Fields(mnemonic), " ")
+}
+
+// getMnemonicSeed validates a BIP-39 mnemonic and converts it into the seed the
+// keys are derived from, using the passphrase given by the --mnemonicpasswordfile
+// flag, if any.
+func getMnemonicSeed(ctx *cli.Context, mnemonic string) []byte {
+	var passphrase string
+	if file := ctx.String(mnemonicPassphraseFlag.Name); file != "" {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			utils.Fatalf("Failed to read mnemonic password file '%s': %v", file, err)
+		}
+		passphrase = strings.TrimRight(string(content), "\r\n")
+	}
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		utils.Fatalf("Invalid mnemonic: %v", err)
+	}
+	return seed
+}
+
+// getDerivationPath parses the derivation path given by the --path flag.
+func getDerivationPath(ctx *cli.Context) accounts.DerivationPath {
+	path, err := accounts.ParseDerivationPath(ctx.String(derivationPathFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid derivation path: %v", err)
+	}
+	return path
+}
+
+// deriveMnemonicKey derives the key at the derivation path given by the --path
+// flag from a BIP-39 mnemonic.
+func deriveMnemonicKey(ctx *cli.Context, mnemonic string) (*ecdsa.PrivateKey, accounts.DerivationPath) {
+	path := getDerivationPath(ctx)
+	key, err := accounts.DeriveKey(getMnemonicSeed(ctx, mnemonic), path)
+	if err != nil {
+		utils.Fatalf("Failed to derive key at %s: %v", path, err)
+	}
+	return key, path
+}
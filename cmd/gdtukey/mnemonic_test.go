@@ -0,0 +1,90 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of go-gdtu.
+//
+// go-gdtu is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-gdtu is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// algdtu with go-gdtu. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestMnemonicDeriveGenerate(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "gdtukey-test")
+	if err != nil {
+		t.Fatal("Can't create temporary directory:", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	mnemonicfile := filepath.Join(tmpdir, "the-mnemonic")
+	if err := ioutil.WriteFile(mnemonicfile, []byte(testMnemonic+"\n"), 0600); err != nil {
+		t.Fatal("Can't write mnemonic file:", err)
+	}
+	// Derive the first accounts of the mnemonic.
+	derive := runGdtukey(t, "derive", "--mnemonicfile", mnemonicfile, "--count", "2")
+	_, matches := derive.ExpectRegexp(`Path:        (m/44'/60'/0'/0/0)
+Address:     (gd[0-9a-fA-F]{40})
+Path:        (m/44'/60'/0'/0/1)
+Address:     (gd[0-9a-fA-F]{40})
+`)
+	derive.ExpectExit()
+
+	if !strings.EqualFold(matches[2], "gd9858EfFD232B4033E47d90003D41EC34EcaEda94") {
+		t.Errorf("derived account mismatch: have %s", matches[2])
+	}
+	// Convert the mnemonic into a keyfile at the second account.
+	keyfile := filepath.Join(tmpdir, "the-keyfile")
+	generate := runGdtukey(t, "generate", "--lightkdf", "--mnemonicfile", mnemonicfile, "--path", "m/44'/60'/0'/0/1", keyfile)
+	generate.Expect(`
+!! Unsupported terminal, password will be echoed.
+Password: {{.InputLine "foobar"}}
+Repeat password: {{.InputLine "foobar"}}
+`)
+	_, generated := generate.ExpectRegexp(`Address: (gd[0-9a-fA-F]{40})
+Path: m/44'/60'/0'/0/1
+`)
+	generate.ExpectExit()
+
+	if generated[1] != matches[4] {
+		t.Errorf("keyfile account mismatch: have %s, want %s", generated[1], matches[4])
+	}
+	// Generate a keyfile from a new mnemonic and derive it again.
+	keyfile = filepath.Join(tmpdir, "the-new-keyfile")
+	generate = runGdtukey(t, "generate", "--lightkdf", "--mnemonic", keyfile)
+	generate.Expect(`
+!! Unsupported terminal, password will be echoed.
+Password: {{.InputLine "foobar"}}
+Repeat password: {{.InputLine "foobar"}}
+`)
+	_, generated = generate.ExpectRegexp(`Address: (gd[0-9a-fA-F]{40})
+Path: m/44'/60'/0'/0/0
+Mnemonic: ([a-z ]+)
+`)
+	generate.ExpectExit()
+
+	if err := ioutil.WriteFile(mnemonicfile, []byte(generated[2]), 0600); err != nil {
+		t.Fatal("Can't write mnemonic file:", err)
+	}
+	derive = runGdtukey(t, "derive", "--mnemonicfile", mnemonicfile)
+	derive.ExpectRegexp(`Path:        m/44'/60'/0'/0/0
+Address:     ` + generated[1] + `
+`)
+	derive.ExpectExit()
+}
@@ -0,0 +1,233 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of go-gdtu.
+//
+// go-gdtu is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-gdtu is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// algdtu with go-gdtu. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/c88032111/go-gdtu/accounts/keystore"
+	"github.com/c88032111/go-gdtu/cmd/utils"
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/crypto"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputSignTx struct {
+	From string
+	Hash string
+	Raw  string
+}
+
+// txDescription is the JSON description of a transaction to sign. The type is
+// optional, defaulting to an access list transaction if an access list is given
+// and to a legacy transaction otherwise.
+type txDescription struct {
+	Type       *hexutil.Uint64   `json:"type"`
+	ChainID    *hexutil.Big      `json:"chainId"`
+	Nonce      *hexutil.Uint64   `json:"nonce"`
+	GasPrice   *hexutil.Big      `json:"gasPrice"`
+	Gas        *hexutil.Uint64   `json:"gas"`
+	To         *common.Address   `json:"to"`
+	Value      *hexutil.Big      `json:"value"`
+	Data       *hexutil.Bytes    `json:"data"`
+	Input      *hexutil.Bytes    `json:"input"`
+	AccessList *types.AccessList `json:"accessList"`
+}
+
+// txData assembles the transaction described, to be signed for the given chain.
+func (desc *txDescription) txData(chainID *big.Int) (types.TxData, error) {
+	if desc.Nonce == nil {
+		return nil, errors.New("missing required field 'nonce' in transaction")
+	}
+	if desc.GasPrice == nil {
+		return nil, errors.New("missing required field 'gasPrice' in transaction")
+	}
+	if desc.Gas == nil {
+		return nil, errors.New("missing required field 'gas' in transaction")
+	}
+	if desc.ChainID != nil && (*big.Int)(desc.ChainID).Cmp(chainID) != 0 {
+		return nil, fmt.Errorf("transaction chain ID %v does not match %v", (*big.Int)(desc.ChainID), chainID)
+	}
+	if desc.Data != nil && desc.Input != nil && !bytes.Equal(*desc.Data, *desc.Input) {
+		return nil, errors.New("both 'data' and 'input' are set and not equal")
+	}
+	var (
+		value = new(big.Int)
+		data  []byte
+	)
+	if desc.Value != nil {
+		value = (*big.Int)(desc.Value)
+	}
+	if desc.Input != nil {
+		data = *desc.Input
+	} else if desc.Data != nil {
+		data = *desc.Data
+	}
+	txType := uint64(types.LegacyTxType)
+	if desc.Type != nil {
+		txType = uint64(*desc.Type)
+	} else if desc.AccessList != nil {
+		txType = types.AccessListTxType
+	}
+	switch txType {
+	case types.LegacyTxType:
+		if desc.AccessList != nil {
+			return nil, errors.New("legacy transactions have no access list")
+		}
+		return &types.LegacyTx{
+			Nonce:    uint64(*desc.Nonce),
+			GasPrice: (*big.Int)(desc.GasPrice),
+			Gas:      uint64(*desc.Gas),
+			To:       desc.To,
+			Value:    value,
+			Data:     data,
+		}, nil
+
+	case types.AccessListTxType:
+		var accessList types.AccessList
+		if desc.AccessList != nil {
+			accessList = *desc.AccessList
+		}
+		return &types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      uint64(*desc.Nonce),
+			GasPrice:   (*big.Int)(desc.GasPrice),
+			Gas:        uint64(*desc.Gas),
+			To:         desc.To,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %d", txType)
+	}
+}
+
+var commandSignTx = cli.Command{
+	Name:      "signtx",
+	Usage:     "sign a transaction",
+	ArgsUsage: "<keyfile> <txfile>",
+	Description: `
+Sign the transaction described by the JSON file with a keyfile, outputting the
+hash and the RLP encoding of the signed transaction, ready to be submitted by
+gdtu_sendRawTransaction.
+
+The transaction is described by the fields "type", "nonce", "gasPrice", "gas",
+"to", "value", "input" (or "data") and "accessList", in the same format as the
+RPC API. Legacy (type 0) and access list (type 1) transactions are supported.
+
+If you want to sign with a raw private key instead of a keyfile, it can be
+specified by setting --privatekey with the location of the file containing the
+private key, omitting the keyfile.
+`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		jsonFlag,
+		cli.Uint64Flag{
+			Name:  "chainid",
+			Usage: "the chain ID to sign the transaction for",
+		},
+		cli.StringFlag{
+			Name:  "privatekey",
+			Usage: "file containing a raw private key to sign with",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if !ctx.IsSet("chainid") || ctx.Uint64("chainid") == 0 {
+			utils.Fatalf("The chain ID must be specified with --chainid")
+		}
+		chainID := new(big.Int).SetUint64(ctx.Uint64("chainid"))
+
+		// Load the private key from the keyfile or the raw key file.
+		var (
+			privateKey *ecdsa.PrivateKey
+			txfilepath string
+			err        error
+		)
+		if file := ctx.String("privatekey"); file != "" {
+			if ctx.NArg() != 1 {
+				utils.Fatalf("Expected the transaction file as the only argument")
+			}
+			txfilepath = ctx.Args().First()
+			if privateKey, err = crypto.LoadECDSA(file); err != nil {
+				utils.Fatalf("Can't load private key: %v", err)
+			}
+		} else {
+			if ctx.NArg() != 2 {
+				utils.Fatalf("Expected the keyfile and the transaction file as arguments")
+			}
+			keyfilepath := ctx.Args().First()
+			txfilepath = ctx.Args().Get(1)
+
+			keyjson, err := ioutil.ReadFile(keyfilepath)
+			if err != nil {
+				utils.Fatalf("Failed to read the keyfile at '%s': %v", keyfilepath, err)
+			}
+			key, err := keystore.DecryptKey(keyjson, getPassphrase(ctx, false))
+			if err != nil {
+				utils.Fatalf("Error decrypting key: %v", err)
+			}
+			privateKey = key.PrivateKey
+		}
+		// Assemble the described transaction, rejecting any unknown fields to
+		// catch typos before signing.
+		txjson, err := ioutil.ReadFile(txfilepath)
+		if err != nil {
+			utils.Fatalf("Failed to read the transaction file at '%s': %v", txfilepath, err)
+		}
+		var desc txDescription
+		dec := json.NewDecoder(bytes.NewReader(txjson))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&desc); err != nil {
+			utils.Fatalf("Invalid transaction description: %v", err)
+		}
+		txdata, err := desc.txData(chainID)
+		if err != nil {
+			utils.Fatalf("Invalid transaction description: %v", err)
+		}
+		tx, err := types.SignNewTx(privateKey, types.LatestSignerForChainID(chainID), txdata)
+		if err != nil {
+			utils.Fatalf("Failed to sign transaction: %v", err)
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			utils.Fatalf("Failed to encode transaction: %v", err)
+		}
+		out := outputSignTx{
+			From: crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+			Hash: tx.Hash().Hex(),
+			Raw:  hexutil.Encode(raw),
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("From:", out.From)
+			fmt.Println("Hash:", out.Hash)
+			fmt.Println("Raw:", out.Raw)
+		}
+		return nil
+	},
+}
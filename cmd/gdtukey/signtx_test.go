@@ -0,0 +1,98 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of go-gdtu.
+//
+// go-gdtu is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-gdtu is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// algdtu with go-gdtu. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/crypto"
+)
+
+func TestSignTx(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "gdtukey-test")
+	if err != nil {
+		t.Fatal("Can't create temporary directory:", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	key, _ := crypto.GenerateKey()
+	keyfile := filepath.Join(tmpdir, "the-key")
+	if err := crypto.SaveECDSA(keyfile, key); err != nil {
+		t.Fatal("Can't save private key:", err)
+	}
+	tests := []struct {
+		desc   string
+		txType uint8
+	}{
+		{`{"nonce": "gd1", "gasPrice": "gd3b9aca00", "gas": "gd5208", "to": "gd000000000000000000000000000000000000dead", "value": "gd1"}`, types.LegacyTxType},
+		{`{"nonce": "gd2", "gasPrice": "gd3b9aca00", "gas": "gd10000", "input": "gd1234", "accessList": [{"address": "gd000000000000000000000000000000000000dead", "storageKeys": []}]}`, types.AccessListTxType},
+	}
+	for i, tt := range tests {
+		txfile := filepath.Join(tmpdir, "the-tx")
+		if err := ioutil.WriteFile(txfile, []byte(tt.desc), 0600); err != nil {
+			t.Fatal("Can't write transaction file:", err)
+		}
+		sign := runGdtukey(t, "signtx", "--chainid", "1337", "--privatekey", keyfile, txfile)
+		_, matches := sign.ExpectRegexp(`From: (gd[0-9a-fA-F]{40})\nHash: (gd[0-9a-f]{64})\nRaw: (gd[0-9a-f]+)\n`)
+		sign.ExpectExit()
+
+		out := outputSignTx{From: matches[1], Hash: matches[2], Raw: matches[3]}
+		raw, err := hexutil.Decode(out.Raw)
+		if err != nil {
+			t.Fatalf("test %d: invalid raw transaction: %v", i, err)
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			t.Fatalf("test %d: failed to decode transaction: %v", i, err)
+		}
+		if tx.Type() != tt.txType {
+			t.Errorf("test %d: type mismatch: have %d, want %d", i, tx.Type(), tt.txType)
+		}
+		if tx.Hash().Hex() != out.Hash {
+			t.Errorf("test %d: hash mismatch: have %s, want %s", i, out.Hash, tx.Hash().Hex())
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1337)), tx)
+		if err != nil {
+			t.Fatalf("test %d: failed to recover sender: %v", i, err)
+		}
+		if want := crypto.PubkeyToAddress(key.PublicKey); from != want || out.From != want.Hex() {
+			t.Errorf("test %d: sender mismatch: have %x, want %x", i, from, want)
+		}
+		if tx.ChainId().Uint64() != 1337 {
+			t.Errorf("test %d: chain ID mismatch: have %v, want 1337", i, tx.ChainId())
+		}
+	}
+	// Ensure mismatching chain IDs and unknown fields are rejected
+	for _, desc := range []string{
+		`{"chainId": "gd1", "nonce": "gd1", "gasPrice": "gd1", "gas": "gd5208"}`,
+		`{"nonce": "gd1", "gasPrice": "gd1", "gas": "gd5208", "too": "gd000000000000000000000000000000000000dead"}`,
+	} {
+		txfile := filepath.Join(tmpdir, "the-tx")
+		if err := ioutil.WriteFile(txfile, []byte(desc), 0600); err != nil {
+			t.Fatal("Can't write transaction file:", err)
+		}
+		sign := runGdtukey(t, "signtx", "--chainid", "1337", "--privatekey", keyfile, txfile)
+		sign.ExpectRegexp(`Fatal: Invalid transaction description: .*\n`)
+		sign.ExpectExit()
+	}
+}
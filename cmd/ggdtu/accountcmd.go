@@ -96,6 +96,7 @@ Make sure you backup your keys regularly.`,
 				Flags: []cli.Flag{
 					utils.DataDirFlag,
 					utils.KeyStoreDirFlag,
+					utils.ExtraKeyStoreDirsFlag,
 				},
 				Description: `
 Print a short summary of all accounts`,
@@ -109,6 +110,7 @@ Print a short summary of all accounts`,
 					utils.KeyStoreDirFlag,
 					utils.PasswordFileFlag,
 					utils.LightKDFFlag,
+					utils.KeyStoreKDFFlag,
 				},
 				Description: `
     ggdtu account new
@@ -268,15 +270,19 @@ func accountCreate(ctx *cli.Context) error {
 		}
 	}
 	utils.SetNodeConfig(ctx, &cfg.Node)
-	scryptN, scryptP, keydir, err := cfg.Node.AccountConfig()
+	_, _, keydir, err := cfg.Node.AccountConfig()
 
+	if err != nil {
+		utils.Fatalf("Failed to read configuration: %v", err)
+	}
+	kdf, err := cfg.Node.AccountKDF()
 	if err != nil {
 		utils.Fatalf("Failed to read configuration: %v", err)
 	}
 
 	password := utils.GetPassPhraseWithList("Your new account is locked with a password. Please give a password. Do not forget this password.", true, 0, utils.MakePasswordList(ctx))
 
-	account, err := keystore.StoreKey(keydir, password, scryptN, scryptP)
+	account, err := keystore.StoreKeyWithKDF(keydir, password, kdf)
 
 	if err != nil {
 		utils.Fatalf("Failed to create account: %v", err)
@@ -92,6 +92,7 @@ The dumpgenesis command dumps the genesis block configuration in JSON format to
 			utils.MetricsInfluxDBPasswordFlag,
 			utils.MetricsInfluxDBTagsFlag,
 			utils.TxLookupLimitFlag,
+			utils.ImportTrustedFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
@@ -99,7 +100,11 @@ The import command imports blocks from an RLP-encoded form. The form can be one
 with several RLP-encoded blocks, or several files can be used.
 
 If only one file is used, import error will result in failure. If several files are used,
-processing will proceed even if an individual RLP-file import failure occurs.`,
+processing will proceed even if an individual RLP-file import failure occurs.
+
+The --import.trusted flag enables a trusted replay mode for reprocessing exported
+chains: every transaction is still executed, but the state root is only hashed and
+verified every N blocks (and at the end of each batch).`,
 	}
 	exportCommand = cli.Command{
 		Action:    utils.MigrateFlags(exportChain),
@@ -253,13 +258,13 @@ func importChain(ctx *cli.Context) error {
 	var importErr error
 
 	if len(ctx.Args()) == 1 {
-		if err := utils.ImportChain(chain, ctx.Args().First()); err != nil {
+		if err := utils.ImportChain(chain, ctx.Args().First(), ctx.GlobalUint64(utils.ImportTrustedFlag.Name)); err != nil {
 			importErr = err
 			log.Error("Import error", "err", err)
 		}
 	} else {
 		for _, arg := range ctx.Args() {
-			if err := utils.ImportChain(chain, arg); err != nil {
+			if err := utils.ImportChain(chain, arg, ctx.GlobalUint64(utils.ImportTrustedFlag.Name)); err != nil {
 				importErr = err
 				log.Error("Import error", "file", arg, "err", err)
 			}
@@ -115,6 +115,13 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, ggdtuConfig) {
 		Metrics: metrics.DefaultConfig,
 	}
 
+	// Apply the configuration profile, the config file and flags override it.
+	if name := ctx.GlobalString(utils.ProfileFlag.Name); name != "" {
+		if err := gdtuconfig.ApplyProfile(&cfg.Gdtu, name); err != nil {
+			utils.Fatalf("%v", err)
+		}
+	}
+
 	// Load config file.
 	if file := ctx.GlobalString(configFileFlag.Name); file != "" {
 		if err := loadConfig(file, &cfg); err != nil {
@@ -129,6 +136,9 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, ggdtuConfig) {
 		utils.Fatalf("Failed to create the protocol stack: %v", err)
 	}
 	utils.SetGdtuConfig(ctx, stack, &cfg.Gdtu)
+	if err := cfg.Gdtu.Validate(); err != nil {
+		utils.Fatalf("%v", err)
+	}
 	if ctx.GlobalIsSet(utils.GdtustatsURLFlag.Name) {
 		cfg.Gdtustats.URL = ctx.GlobalString(utils.GdtustatsURLFlag.Name)
 	}
@@ -153,6 +163,10 @@ func makeFullNode(ctx *cli.Context) (*node.Node, gdtuapi.Backend) {
 	if cfg.Gdtustats.URL != "" {
 		utils.RegisterGdtustatsService(stack, backend, cfg.Gdtustats.URL)
 	}
+	// Load any plugins last, so they can build on the built-in services
+	if err := stack.LoadPlugins(); err != nil {
+		utils.Fatalf("%v", err)
+	}
 	return stack, backend
 }
 
@@ -19,17 +19,23 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/c88032111/go-gdtu/cmd/utils"
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/common/hexutil"
 	"github.com/c88032111/go-gdtu/console/prompt"
+	"github.com/c88032111/go-gdtu/core"
 	"github.com/c88032111/go-gdtu/core/rawdb"
 	"github.com/c88032111/go-gdtu/gdtudb"
 	"github.com/c88032111/go-gdtu/gdtudb/leveldb"
 	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/trie"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -59,6 +65,9 @@ Remove blockchain and state databases`,
 			dbGetCmd,
 			dbDeleteCmd,
 			dbPutCmd,
+			dbRebuildTxIndexCmd,
+			dbVerifyCmd,
+			dbRollbackCmd,
 		},
 	}
 	dbInspectCmd = cli.Command{
@@ -105,6 +114,80 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		Description: `This command sets a given database key to the given value. 
 WARNING: This is a low-level operation which may cause database corruption!`,
 	}
+	dbRebuildTxIndexCmd = cli.Command{
+		Action:    dbRebuildTxIndex,
+		Name:      "rebuild-txindex",
+		Usage:     "Rebuild or extend the transaction lookup index",
+		ArgsUsage: "",
+		Flags: []cli.Flag{
+			txIndexFromFlag,
+			txIndexToFlag,
+			txIndexRateFlag,
+		},
+		Description: `This command (re)indexes the transactions of the canonical blocks from
+the given block number up to the head, regardless of the indices already present.
+It can be used to repair the index or to extend it beyond the configured
+transaction lookup limit without resyncing. Note, a node started with a lower
+--txlookuplimit will prune the extended part of the index again.
+
+The blocks are indexed from the newest one downwards, reporting the progress
+periodically. If interrupted, the already indexed blocks are kept and the
+rebuild can be resumed from the reported block.`,
+	}
+	dbVerifyCmd = cli.Command{
+		Action:    dbVerify,
+		Name:      "verify",
+		Usage:     "Verify the integrity of the canonical chain data",
+		ArgsUsage: "",
+		Flags: []cli.Flag{
+			verifyFromFlag,
+			verifyToFlag,
+			verifySampleFlag,
+		},
+		Description: `This command checks the header hash chain and the presence of the block
+bodies and receipts of the canonical blocks in the given range. For a random
+sample of the blocks, the transaction, uncle and receipt roots are recomputed
+and compared to the headers, and the transaction lookup entries are verified.
+It can be used to check the database after a crash or an unclean shutdown.`,
+	}
+	dbRollbackCmd = cli.Command{
+		Action:    dbRollback,
+		Name:      "rollback",
+		Usage:     "Roll the database schema back to an older version",
+		ArgsUsage: "<version>",
+		Description: `This command runs the rollbacks of the database migrations down to the
+given schema version, so the database can be opened by the older release which
+supports it. The command fails without modifying the database if any of the
+migrations can't be rolled back.`,
+	}
+)
+
+var (
+	txIndexFromFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "First block number to index",
+	}
+	txIndexToFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last block number to index (default = chain head)",
+	}
+	txIndexRateFlag = cli.Uint64Flag{
+		Name:  "rate",
+		Usage: "Maximum number of blocks to index per second (0 = unlimited)",
+	}
+	verifyFromFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "First block number to verify",
+	}
+	verifyToFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last block number to verify (default = chain head)",
+	}
+	verifySampleFlag = cli.StringFlag{
+		Name:  "sample",
+		Usage: "Fraction of the blocks to verify in depth, e.g. 1% or 0.01",
+		Value: "1%",
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -339,3 +422,139 @@ func dbPut(ctx *cli.Context) error {
 	}
 	return db.Put(key, value)
 }
+
+// dbRebuildTxIndex (re)creates the transaction lookup index of a block range
+func dbRebuildTxIndex(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	head := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadBlockHash(db))
+	if head == nil {
+		return fmt.Errorf("head block missing")
+	}
+	var (
+		from = ctx.Uint64(txIndexFromFlag.Name)
+		to   = *head
+	)
+	if ctx.IsSet(txIndexToFlag.Name) {
+		to = ctx.Uint64(txIndexToFlag.Name)
+	}
+	if to > *head {
+		return fmt.Errorf("last block #%d is above the chain head #%d", to, *head)
+	}
+	if from > to {
+		return fmt.Errorf("first block #%d is above the last block #%d", from, to)
+	}
+	if tail := rawdb.ReadTxIndexTail(db); tail != nil && to+1 < *tail {
+		return fmt.Errorf("last block #%d leaves a gap below the index tail #%d", to, *tail)
+	}
+	if limit := ctx.GlobalUint64(utils.TxLookupLimitFlag.Name); limit != 0 && *head >= limit && from < *head-limit+1 {
+		log.Warn("Index extends beyond the transaction lookup limit", "limit", limit, "oldest", *head-limit+1)
+	}
+	interrupt := make(chan struct{})
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	go func() {
+		<-sigc
+		log.Info("Interrupted, stopping transaction indexing")
+		close(interrupt)
+	}()
+	log.Info("Rebuilding transaction index", "from", from, "to", to, "rate", ctx.Uint64(txIndexRateFlag.Name))
+	if tail := rawdb.RebuildTxIndex(db, from, to+1, ctx.Uint64(txIndexRateFlag.Name), interrupt); tail > from {
+		return fmt.Errorf("interrupted, resume with --%s=%d", txIndexToFlag.Name, tail-1)
+	}
+	return nil
+}
+
+// dbRollback downgrades the database schema to the given version.
+func dbRollback(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	version, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid version: %v", err)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	if err := core.RollbackDatabase(db, version); err != nil {
+		return err
+	}
+	log.Info("Rolled back database", "version", version)
+	return nil
+}
+
+// dbVerify checks the integrity of the canonical chain data of a block range.
+func dbVerify(ctx *cli.Context) error {
+	sample, err := parseFraction(ctx.String(verifySampleFlag.Name))
+	if err != nil {
+		return err
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	head := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadBlockHash(db))
+	if head == nil {
+		return fmt.Errorf("head block missing")
+	}
+	var (
+		from = ctx.Uint64(verifyFromFlag.Name)
+		to   = *head
+	)
+	if ctx.IsSet(verifyToFlag.Name) {
+		to = ctx.Uint64(verifyToFlag.Name)
+	}
+	if to > *head {
+		return fmt.Errorf("last block #%d is above the chain head #%d", to, *head)
+	}
+	if from > to {
+		return fmt.Errorf("first block #%d is above the last block #%d", from, to)
+	}
+	interrupt := make(chan struct{})
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	go func() {
+		<-sigc
+		log.Info("Interrupted, stopping chain verification")
+		close(interrupt)
+	}()
+	log.Info("Verifying chain data", "from", from, "to", to, "sample", sample)
+	start := time.Now()
+	report := rawdb.InspectChain(db, from, to, sample, trie.NewStackTrie(nil), interrupt)
+	for _, issue := range report.Issues {
+		fmt.Println(issue)
+	}
+	fmt.Printf("Checked %d blocks, sampled %d, found %d issues in %v\n", report.Checked, report.Sampled, report.Total, common.PrettyDuration(time.Since(start)))
+	if report.Total > 0 {
+		return fmt.Errorf("chain data verification failed with %d issues", report.Total)
+	}
+	return nil
+}
+
+// parseFraction parses a fraction given either as a percentage or as a number
+// between 0 and 1.
+func parseFraction(s string) (float64, error) {
+	var (
+		value float64
+		err   error
+	)
+	if strings.HasSuffix(s, "%") {
+		value, err = strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		value /= 100
+	} else {
+		value, err = strconv.ParseFloat(s, 64)
+	}
+	if err != nil || value < 0 || value > 1 {
+		return 0, fmt.Errorf("invalid fraction %q", s)
+	}
+	return value, nil
+}
@@ -63,13 +63,17 @@ var (
 		utils.BootnodesFlag,
 		utils.DataDirFlag,
 		utils.AncientFlag,
+		utils.AncientThresholdFlag,
+		utils.AncientAutoTuneFlag,
 		utils.MinFreeDiskSpaceFlag,
 		utils.KeyStoreDirFlag,
+		utils.ExtraKeyStoreDirsFlag,
 		utils.ExternalSignerFlag,
 		utils.NoUSBFlag,
 		utils.USBFlag,
 		utils.SmartCardDaemonPathFlag,
 		utils.OverrideBerlinFlag,
+		utils.GovernanceFileFlag,
 		utils.GdtuashCacheDirFlag,
 		utils.GdtuashCachesInMemoryFlag,
 		utils.GdtuashCachesOnDiskFlag,
@@ -82,6 +86,8 @@ var (
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
+		utils.TxPoolRemoteJournalFlag,
+		utils.TxPoolRemoteJournalLimitFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
@@ -90,20 +96,37 @@ var (
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
 		utils.SyncModeFlag,
+		utils.ProfileFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
+		utils.PluginsFlag,
 		utils.SnapshotFlag,
+		utils.SnapshotThrottleFlag,
+		utils.SnapshotMaxDelayFlag,
+		utils.SnapshotIdleFlag,
+		utils.SnapshotCompactionFlag,
 		utils.TxLookupLimitFlag,
+		utils.SideChainRetentionFlag,
+		utils.PeerBanDurationFlag,
 		utils.LightServeFlag,
+		utils.LightServeMaxFlag,
 		utils.LightIngressFlag,
 		utils.LightEgressFlag,
 		utils.LightMaxPeersFlag,
 		utils.LightNoPruneFlag,
 		utils.LightKDFFlag,
+		utils.KeyStoreKDFFlag,
+		utils.KeyStoreUpgradeFlag,
 		utils.UltraLightServersFlag,
 		utils.UltraLightFractionFlag,
 		utils.UltraLightOnlyAnnounceFlag,
 		utils.LightNoSyncServeFlag,
+		utils.LightServerFilterFlag,
+		utils.LightOdrCacheFlag,
+		utils.LightWebsocketFlag,
+		utils.LightWebsocketServersFlag,
+		utils.LightServersPerNetworkFlag,
+		utils.LightNetworkMapFlag,
 		utils.WhitelistFlag,
 		utils.BloomFilterSizeFlag,
 		utils.CacheFlag,
@@ -113,6 +136,7 @@ var (
 		utils.CacheTrieRejournalFlag,
 		utils.CacheGCFlag,
 		utils.CacheSnapshotFlag,
+		utils.CacheCodeFlag,
 		utils.CacheNoPrefetchFlag,
 		utils.CachePreimagesFlag,
 		utils.ListenPortFlag,
@@ -128,9 +152,13 @@ var (
 		utils.MinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNoVerfiyFlag,
+		utils.MinerBuilderFlag,
+		utils.MinerBuilderTimeoutFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
+		utils.SessionResumptionFlag,
+		utils.CompressionFlag,
 		utils.NetrestrictFlag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
@@ -138,6 +166,8 @@ var (
 		utils.MainnetFlag,
 		utils.DeveloperFlag,
 		utils.DeveloperPeriodFlag,
+		utils.DeveloperAccountsFlag,
+		utils.DeveloperResetFlag,
 		utils.RopstenFlag,
 		utils.RinkebyFlag,
 		utils.GoerliFlag,
@@ -150,8 +180,12 @@ var (
 		utils.GpoBlocksFlag,
 		utils.GpoPercentileFlag,
 		utils.GpoMaxGasPriceFlag,
+		utils.GpoPoolWeightFlag,
+		utils.GpoTrendWeightFlag,
 		utils.EWASMInterpreterFlag,
 		utils.EVMInterpreterFlag,
+		utils.VMSampleFlag,
+		utils.VMParallelFlag,
 		configFileFlag,
 	}
 
@@ -172,6 +206,8 @@ var (
 		utils.GraphQLVirtualHostsFlag,
 		utils.HTTPApiFlag,
 		utils.HTTPPathPrefixFlag,
+		utils.HTTPEnableHTTP2Flag,
+		utils.HTTPKeepAliveFlag,
 		utils.WSEnabledFlag,
 		utils.WSListenAddrFlag,
 		utils.WSPortFlag,
@@ -183,6 +219,11 @@ var (
 		utils.InsecureUnlockAllowedFlag,
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalTxFeeCapFlag,
+		utils.RPCCallTimeoutFlag,
+		utils.RPCSlowCallFlag,
+		utils.RPCTLSCertFlag,
+		utils.RPCTLSKeyFlag,
+		utils.RPCTLSClientCAFlag,
 		utils.AllowUnprotectedTxs,
 	}
 
@@ -442,6 +483,14 @@ func startNode(ctx *cli.Context, stack *node.Node, backend gdtuapi.Backend) {
 			utils.Fatalf("Failed to start mining: %v", err)
 		}
 	}
+	// Print the named developer accounts for test frameworks to pick them up
+	if ctx.GlobalBool(utils.DeveloperFlag.Name) {
+		if accounts := utils.MakeDeveloperAccounts(ctx); len(accounts) > 0 {
+			if err := utils.PrintDeveloperAccounts(os.Stdout, accounts); err != nil {
+				utils.Fatalf("Failed to print developer accounts: %v", err)
+			}
+		}
+	}
 }
 
 // unlockAccounts unlocks any account specifically requested.
@@ -462,9 +511,24 @@ func unlockAccounts(ctx *cli.Context, stack *node.Node) {
 	if !stack.Config().InsecureUnlockAllowed && stack.Config().ExtRPCEnabled() {
 		utils.Fatalf("Account unlock with HTTP access is forbidden!")
 	}
-	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	keystores := stack.AccountManager().Backends(keystore.KeyStoreType)
 	passwords := utils.MakePasswordList(ctx)
 	for i, account := range unlocks {
-		unlockAccount(ks, account, i, passwords)
+		unlockAccount(keystoreFor(keystores, account), account, i, passwords)
+	}
+}
+
+// keystoreFor returns the keystore holding the given account, preferring the
+// primary keystore over the additional ones. Accounts given by index always
+// refer to the primary keystore.
+func keystoreFor(keystores []accounts.Backend, account string) *keystore.KeyStore {
+	if common.IsHexAddress(account) {
+		address := common.HexToAddress(account)
+		for _, backend := range keystores {
+			if ks := backend.(*keystore.KeyStore); ks.HasAddress(address) {
+				return ks
+			}
+		}
 	}
+	return keystores[0].(*keystore.KeyStore)
 }
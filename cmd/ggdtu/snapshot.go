@@ -63,6 +63,7 @@ var (
 					utils.GoerliFlag,
 					utils.CacheTrieJournalFlag,
 					utils.BloomFilterSizeFlag,
+					utils.MinFreeDiskSpaceFlag,
 				},
 				Description: `
 ggdtu snapshot prune-state <state-root>
@@ -73,6 +74,12 @@ two version states are available: genesis and the specific one.
 
 The default pruning target is the HEAD-127 state.
 
+The pruning reports its progress and an estimate of the reclaimable space
+before deleting anything. If interrupted, running the command again (or
+starting Ggdtu) resumes the pruning from where it stopped. The command
+refuses to start if writing the state bloom filter would leave less free
+disk space than "--datadir.minfreedisk".
+
 WARNING: It's necessary to delete the trie clean cache after the pruning.
 If you specify another directory for the trie clean cache via "--cache.trie.journal"
 during the use of Ggdtu, please also specify it here for correct deletion. Otherwise
@@ -155,6 +162,22 @@ func pruneState(ctx *cli.Context) error {
 	chain, chaindb := utils.MakeChain(ctx, stack, true)
 	defer chaindb.Close()
 
+	// Ensure there's enough disk space for the state bloom filter, unless an
+	// interrupted pruning is resumed with the one already written.
+	interrupted, err := pruner.Interrupted(stack.ResolvePath(""))
+	if err != nil {
+		log.Error("Failed to check pruning status", "error", err)
+		return err
+	}
+	if !interrupted {
+		needed := ctx.GlobalUint64(utils.BloomFilterSizeFlag.Name) * 1024 * 1024
+		if err := utils.CheckFreeDiskSpace(ctx, stack.InstanceDir(), needed); err != nil {
+			log.Error("Refusing to prune state", "error", err)
+			return err
+		}
+	} else {
+		log.Info("Resuming interrupted state pruning")
+	}
 	pruner, err := pruner.NewPruner(chaindb, chain.CurrentBlock().Header(), stack.ResolvePath(""), stack.ResolvePath(config.Gdtu.TrieCleanCacheJournal), ctx.GlobalUint64(utils.BloomFilterSizeFlag.Name))
 	if err != nil {
 		log.Error("Failed to open snapshot tree", "error", err)
@@ -186,7 +209,7 @@ func verifyState(ctx *cli.Context) error {
 	chain, chaindb := utils.MakeChain(ctx, stack, true)
 	defer chaindb.Close()
 
-	snaptree, err := snapshot.New(chaindb, trie.NewDatabase(chaindb), 256, chain.CurrentBlock().Root(), false, false, false)
+	snaptree, err := snapshot.New(chaindb, trie.NewDatabase(chaindb), 256, chain.CurrentBlock().Root(), false, false, false, nil, nil)
 	if err != nil {
 		log.Error("Failed to open snapshot tree", "error", err)
 		return err
@@ -36,8 +36,11 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			configFileFlag,
 			utils.DataDirFlag,
 			utils.AncientFlag,
+			utils.AncientThresholdFlag,
+			utils.AncientAutoTuneFlag,
 			utils.MinFreeDiskSpaceFlag,
 			utils.KeyStoreDirFlag,
+			utils.ExtraKeyStoreDirsFlag,
 			utils.USBFlag,
 			utils.SmartCardDaemonPathFlag,
 			utils.NetworkIdFlag,
@@ -47,19 +50,26 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.YoloV3Flag,
 			utils.RopstenFlag,
 			utils.SyncModeFlag,
+			utils.ProfileFlag,
 			utils.ExitWhenSyncedFlag,
 			utils.GCModeFlag,
 			utils.TxLookupLimitFlag,
+			utils.SideChainRetentionFlag,
+			utils.PeerBanDurationFlag,
 			utils.GdtustatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
+			utils.KeyStoreKDFFlag,
+			utils.KeyStoreUpgradeFlag,
 			utils.WhitelistFlag,
+			utils.GovernanceFileFlag,
 		},
 	},
 	{
 		Name: "LIGHT CLIENT",
 		Flags: []cli.Flag{
 			utils.LightServeFlag,
+			utils.LightServeMaxFlag,
 			utils.LightIngressFlag,
 			utils.LightEgressFlag,
 			utils.LightMaxPeersFlag,
@@ -68,6 +78,12 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.UltraLightOnlyAnnounceFlag,
 			utils.LightNoPruneFlag,
 			utils.LightNoSyncServeFlag,
+			utils.LightServerFilterFlag,
+			utils.LightOdrCacheFlag,
+			utils.LightWebsocketFlag,
+			utils.LightWebsocketServersFlag,
+			utils.LightServersPerNetworkFlag,
+			utils.LightNetworkMapFlag,
 		},
 	},
 	{
@@ -75,6 +91,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 		Flags: []cli.Flag{
 			utils.DeveloperFlag,
 			utils.DeveloperPeriodFlag,
+			utils.DeveloperAccountsFlag,
+			utils.DeveloperResetFlag,
 		},
 	},
 	{
@@ -97,6 +115,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolNoLocalsFlag,
 			utils.TxPoolJournalFlag,
 			utils.TxPoolRejournalFlag,
+			utils.TxPoolRemoteJournalFlag,
+			utils.TxPoolRemoteJournalLimitFlag,
 			utils.TxPoolPriceLimitFlag,
 			utils.TxPoolPriceBumpFlag,
 			utils.TxPoolAccountSlotsFlag,
@@ -116,6 +136,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.CacheTrieRejournalFlag,
 			utils.CacheGCFlag,
 			utils.CacheSnapshotFlag,
+			utils.CacheCodeFlag,
 			utils.CacheNoPrefetchFlag,
 			utils.CachePreimagesFlag,
 		},
@@ -139,6 +160,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.HTTPPortFlag,
 			utils.HTTPApiFlag,
 			utils.HTTPPathPrefixFlag,
+			utils.HTTPEnableHTTP2Flag,
+			utils.HTTPKeepAliveFlag,
 			utils.HTTPCORSDomainFlag,
 			utils.HTTPVirtualHostsFlag,
 			utils.WSEnabledFlag,
@@ -152,6 +175,11 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.GraphQLVirtualHostsFlag,
 			utils.RPCGlobalGasCapFlag,
 			utils.RPCGlobalTxFeeCapFlag,
+			utils.RPCCallTimeoutFlag,
+			utils.RPCSlowCallFlag,
+			utils.RPCTLSCertFlag,
+			utils.RPCTLSKeyFlag,
+			utils.RPCTLSClientCAFlag,
 			utils.AllowUnprotectedTxs,
 			utils.JSpathFlag,
 			utils.ExecFlag,
@@ -169,6 +197,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.NATFlag,
 			utils.NoDiscoverFlag,
 			utils.DiscoveryV5Flag,
+			utils.SessionResumptionFlag,
+			utils.CompressionFlag,
 			utils.NetrestrictFlag,
 			utils.NodeKeyFileFlag,
 			utils.NodeKeyHexFlag,
@@ -187,6 +217,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.MinerExtraDataFlag,
 			utils.MinerRecommitIntervalFlag,
 			utils.MinerNoVerfiyFlag,
+			utils.MinerBuilderFlag,
+			utils.MinerBuilderTimeoutFlag,
 		},
 	},
 	{
@@ -195,6 +227,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.GpoBlocksFlag,
 			utils.GpoPercentileFlag,
 			utils.GpoMaxGasPriceFlag,
+			utils.GpoPoolWeightFlag,
+			utils.GpoTrendWeightFlag,
 		},
 	},
 	{
@@ -202,6 +236,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 		Flags: []cli.Flag{
 			utils.VMEnableDebugFlag,
 			utils.EVMInterpreterFlag,
+			utils.VMSampleFlag,
+			utils.VMParallelFlag,
 			utils.EWASMInterpreterFlag,
 		},
 	},
@@ -231,7 +267,12 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 	{
 		Name: "MISC",
 		Flags: []cli.Flag{
+			utils.PluginsFlag,
 			utils.SnapshotFlag,
+			utils.SnapshotThrottleFlag,
+			utils.SnapshotMaxDelayFlag,
+			utils.SnapshotIdleFlag,
+			utils.SnapshotCompactionFlag,
 			utils.BloomFilterSizeFlag,
 			cli.HelpFlag,
 		},
@@ -165,6 +165,12 @@ func main() {
 					Usage:     "disconnect a node from a peer node",
 					Action:    disconnectNode,
 				},
+				{
+					Name:      "logs",
+					ArgsUsage: "<node>",
+					Usage:     "show the log output captured by a node",
+					Action:    nodeLogs,
+				},
 				{
 					Name:      "rpc",
 					ArgsUsage: "<node> <Method> [<args>]",
@@ -378,6 +384,19 @@ func disconnectNode(ctx *cli.Context) error {
 	return nil
 }
 
+func nodeLogs(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 1 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	logs, err := client.NodeLogs(args[0])
+	if err != nil {
+		return err
+	}
+	_, err = ctx.App.Writer.Write(logs)
+	return err
+}
+
 func rpcNode(ctx *cli.Context) error {
 	args := ctx.Args()
 	if len(args) < 2 {
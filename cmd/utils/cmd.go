@@ -30,9 +30,8 @@ import (
 
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/core"
-	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/state"
 	"github.com/c88032111/go-gdtu/core/types"
-	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/c88032111/go-gdtu/gdtu/gdtuconfig"
 	"github.com/c88032111/go-gdtu/gdtudb"
 	"github.com/c88032111/go-gdtu/internal/debug"
@@ -75,12 +74,7 @@ func StartNode(ctx *cli.Context, stack *node.Node) {
 		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
 		defer signal.Stop(sigc)
 
-		minFreeDiskSpace := gdtuconfig.Defaults.TrieDirtyCache
-		if ctx.GlobalIsSet(MinFreeDiskSpaceFlag.Name) {
-			minFreeDiskSpace = ctx.GlobalInt(MinFreeDiskSpaceFlag.Name)
-		} else if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
-			minFreeDiskSpace = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
-		}
+		minFreeDiskSpace := freeDiskSpaceLimit(ctx)
 		if minFreeDiskSpace > 0 {
 			go monitorFreeDiskSpace(sigc, stack.InstanceDir(), uint64(minFreeDiskSpace)*1024*1024)
 		}
@@ -99,6 +93,36 @@ func StartNode(ctx *cli.Context, stack *node.Node) {
 	}()
 }
 
+// freeDiskSpaceLimit returns the minimum free disk space in MB configured for
+// running the node, 0 if disabled.
+func freeDiskSpaceLimit(ctx *cli.Context) int {
+	minFreeDiskSpace := gdtuconfig.Defaults.TrieDirtyCache
+	if ctx.GlobalIsSet(MinFreeDiskSpaceFlag.Name) {
+		minFreeDiskSpace = ctx.GlobalInt(MinFreeDiskSpaceFlag.Name)
+	} else if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
+		minFreeDiskSpace = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
+	}
+	return minFreeDiskSpace
+}
+
+// CheckFreeDiskSpace returns an error if writing the given number of bytes to
+// the disk of the given path would leave less than the minimum free disk space
+// configured for running the node.
+func CheckFreeDiskSpace(ctx *cli.Context, path string, needed uint64) error {
+	freeSpace, err := getFreeDiskSpace(path)
+	if err != nil {
+		return err
+	}
+	required := needed
+	if limit := freeDiskSpaceLimit(ctx); limit > 0 {
+		required += uint64(limit) * 1024 * 1024
+	}
+	if freeSpace < required {
+		return fmt.Errorf("insufficient disk space: %v available, %v required", common.StorageSize(freeSpace), common.StorageSize(required))
+	}
+	return nil
+}
+
 func monitorFreeDiskSpace(sigc chan os.Signal, path string, freeDiskSpaceCritical uint64) {
 	for {
 		freeSpace, err := getFreeDiskSpace(path)
@@ -117,7 +141,12 @@ func monitorFreeDiskSpace(sigc chan os.Signal, path string, freeDiskSpaceCritica
 	}
 }
 
-func ImportChain(chain *core.BlockChain, fn string) error {
+// ImportChain imports the RLP encoded blocks from the given file into the chain.
+//
+// If trusted is non-zero, the blocks are imported in trusted replay mode, fully
+// executing every transaction but only verifying the state root on every N-th
+// block. It is meant to speed up reprocessing previously exported chains.
+func ImportChain(chain *core.BlockChain, fn string, trusted uint64) error {
 	// Watch for Ctrl-C while the import is running.
 	// If a signal is received, the import will stop at the next batch.
 	interrupt := make(chan os.Signal, 1)
@@ -193,7 +222,11 @@ func ImportChain(chain *core.BlockChain, fn string) error {
 			log.Info("Skipping batch as all blocks present", "batch", batch, "first", blocks[0].Hash(), "last", blocks[i-1].Hash())
 			continue
 		}
-		if _, err := chain.InsertChain(missing); err != nil {
+		insert := chain.InsertChain
+		if trusted > 0 {
+			insert = func(blocks types.Blocks) (int, error) { return chain.InsertChainTrusted(blocks, trusted) }
+		}
+		if _, err := insert(missing); err != nil {
 			return fmt.Errorf("invalid block %d: %v", n, err)
 		}
 	}
@@ -282,36 +315,18 @@ func ImportPreimages(db gdtudb.Database, fn string) error {
 
 	var reader io.Reader = fh
 	if strings.HasSuffix(fn, ".gz") {
-		if reader, err = gzip.NewReader(reader); err != nil {
-			return err
-		}
-	}
-	stream := rlp.NewStream(reader, 0)
-
-	// Import the preimages in batches to prevent disk trashing
-	preimages := make(map[common.Hash][]byte)
-
-	for {
-		// Read the next entry and ensure it's not junk
-		var blob []byte
-
-		if err := stream.Decode(&blob); err != nil {
-			if err == io.EOF {
-				break
-			}
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
 			return err
 		}
-		// Accumulate the preimages and flush when enough ws gathered
-		preimages[crypto.Keccak256Hash(blob)] = common.CopyBytes(blob)
-		if len(preimages) > 1024 {
-			rawdb.WritePreimages(db, preimages)
-			preimages = make(map[common.Hash][]byte)
-		}
+		defer gz.Close()
+		reader = gz
 	}
-	// Flush the last batch preimage data
-	if len(preimages) > 0 {
-		rawdb.WritePreimages(db, preimages)
+	count, err := state.ImportPreimages(db, reader)
+	if err != nil {
+		return err
 	}
+	log.Info("Imported preimages", "file", fn, "count", count)
 	return nil
 }
 
@@ -333,14 +348,10 @@ func ExportPreimages(db gdtudb.Database, fn string) error {
 		defer writer.(*gzip.Writer).Close()
 	}
 	// Iterate over the preimages and export them
-	it := db.NewIterator([]byte("secure-key-"), nil)
-	defer it.Release()
-
-	for it.Next() {
-		if err := rlp.Encode(writer, it.Value()); err != nil {
-			return err
-		}
+	count, err := state.ExportPreimages(db, writer)
+	if err != nil {
+		return err
 	}
-	log.Info("Exported preimages", "file", fn)
+	log.Info("Exported preimages", "file", fn, "count", count)
 	return nil
 }
@@ -0,0 +1,119 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of go-gdtu.
+//
+// go-gdtu is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-gdtu is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// algdtu with go-gdtu. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/node"
+	"github.com/c88032111/go-gdtu/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// DeveloperAccountBalance is the genesis balance of the named developer accounts.
+var DeveloperAccountBalance = new(big.Int).Mul(big.NewInt(1000000), big.NewInt(params.Gdtur))
+
+// DeveloperAccount is a named account pre-funded in the genesis of the developer
+// network. Its key is derived from the name, so the account stays the same across
+// runs and chain resets.
+type DeveloperAccount struct {
+	Name    string
+	Address common.Address
+	Key     *ecdsa.PrivateKey
+}
+
+// MarshalJSON encodes the account along with its private key, for use by test
+// frameworks signing transactions locally.
+func (a DeveloperAccount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name       string         `json:"name"`
+		Address    common.Address `json:"address"`
+		PrivateKey hexutil.Bytes  `json:"privateKey"`
+	}{a.Name, a.Address, crypto.FromECDSA(a.Key)})
+}
+
+// MakeDeveloperAccounts derives the named developer accounts requested on the
+// command line.
+func MakeDeveloperAccounts(ctx *cli.Context) []DeveloperAccount {
+	var (
+		accounts []DeveloperAccount
+		seen     = make(map[string]bool)
+	)
+	for _, name := range strings.Split(ctx.GlobalString(DeveloperAccountsFlag.Name), ",") {
+		if name = strings.TrimSpace(name); name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		key, err := crypto.ToECDSA(crypto.Keccak256([]byte("gdtu developer account: " + name)))
+		if err != nil {
+			Fatalf("Failed to derive developer account %q: %v", name, err)
+		}
+		accounts = append(accounts, DeveloperAccount{
+			Name:    name,
+			Address: crypto.PubkeyToAddress(key.PublicKey),
+			Key:     key,
+		})
+	}
+	return accounts
+}
+
+// PrintDeveloperAccounts writes the named developer accounts as a JSON list.
+func PrintDeveloperAccounts(w io.Writer, accounts []DeveloperAccount) error {
+	blob, err := json.MarshalIndent(accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(blob))
+	return err
+}
+
+// resetDeveloperChain deletes the chain database of the developer network, so
+// that the node starts over from a fresh genesis block.
+func resetDeveloperChain(ctx *cli.Context, stack *node.Node) {
+	dirs := []string{stack.ResolvePath("chaindata")}
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		dirs = append(dirs, ctx.GlobalString(AncientFlag.Name))
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			Fatalf("Failed to reset developer chain: %v", err)
+		}
+		log.Info("Reset developer chain", "path", dir)
+	}
+}
+
+// fundDeveloperAccounts pre-funds the named developer accounts in the genesis.
+func fundDeveloperAccounts(genesis *core.Genesis, accounts []DeveloperAccount) {
+	for _, account := range accounts {
+		genesis.Alloc[account.Address] = core.GenesisAccount{Balance: new(big.Int).Set(DeveloperAccountBalance)}
+	}
+}
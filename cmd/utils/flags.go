@@ -114,6 +114,15 @@ var (
 		Name:  "datadir.ancient",
 		Usage: "Data directory for ancient chain segments (default = inside chaindata)",
 	}
+	AncientThresholdFlag = cli.Uint64Flag{
+		Name:  "datadir.ancient.threshold",
+		Usage: "Number of recent blocks to keep in the key-value store before moving them to the ancient store",
+		Value: params.FullImmutabilityThreshold,
+	}
+	AncientAutoTuneFlag = cli.BoolFlag{
+		Name:  "datadir.ancient.autotune",
+		Usage: "Tune the number of recent blocks kept in the key-value store to the disk speed",
+	}
 	MinFreeDiskSpaceFlag = DirectoryFlag{
 		Name:  "datadir.minfreedisk",
 		Usage: "Minimum free disk space in MB, once reached triggers auto shut down (default = --cache.gc converted to MB, 0 = disabled)",
@@ -122,6 +131,14 @@ var (
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
 	}
+	ExtraKeyStoreDirsFlag = cli.StringFlag{
+		Name:  "keystore.extra",
+		Usage: "Comma separated additional keystore directories to read accounts from (the primary keystore takes precedence)",
+	}
+	PluginsFlag = cli.StringFlag{
+		Name:  "plugins",
+		Usage: "Comma separated list of Go plugin files to load into the node at startup",
+	}
 	NoUSBFlag = cli.BoolFlag{
 		Name:  "nousb",
 		Usage: "Disables monitoring for and managing USB hardware wallets (deprecated)",
@@ -168,6 +185,14 @@ var (
 		Name:  "dev.period",
 		Usage: "Block period to use in developer mode (0 = mine only if transaction pending)",
 	}
+	DeveloperAccountsFlag = cli.StringFlag{
+		Name:  "dev.accounts",
+		Usage: "Comma separated names of additional pre-funded accounts to create in developer mode",
+	}
+	DeveloperResetFlag = cli.BoolFlag{
+		Name:  "dev.reset",
+		Usage: "Delete the existing developer chain and start over from a fresh genesis block",
+	}
 	IdentityFlag = cli.StringFlag{
 		Name:  "identity",
 		Usage: "Custom node name",
@@ -203,6 +228,10 @@ var (
 		Usage: `Blockchain sync mode ("fast", "full", "snap" or "light")`,
 		Value: &defaultSyncMode,
 	}
+	ProfileFlag = cli.StringFlag{
+		Name:  "profile",
+		Usage: "Configuration preset for the role of the node (" + strings.Join(gdtuconfig.ProfileNames(), ", ") + ")",
+	}
 	GCModeFlag = cli.StringFlag{
 		Name:  "gcmode",
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
@@ -212,18 +241,60 @@ var (
 		Name:  "snapshot",
 		Usage: `Enables snapshot-database mode (default = enable)`,
 	}
+	SnapshotThrottleFlag = cli.DurationFlag{
+		Name:  "snapshot.throttle",
+		Usage: "Block import time above which snapshot generation is slowed down (0 = disabled)",
+		Value: gdtuconfig.Defaults.SnapshotThrottle,
+	}
+	SnapshotMaxDelayFlag = cli.DurationFlag{
+		Name:  "snapshot.maxdelay",
+		Usage: "Maximum pause between snapshot generation batches while block import is slow",
+		Value: gdtuconfig.Defaults.SnapshotMaxDelay,
+	}
+	SnapshotIdleFlag = cli.DurationFlag{
+		Name:  "snapshot.idle",
+		Usage: "Time without block imports after which snapshot diffs are persisted in the background (0 = disabled)",
+		Value: gdtuconfig.Defaults.SnapshotIdle,
+	}
+	SnapshotCompactionFlag = cli.IntFlag{
+		Name:  "snapshot.compaction",
+		Usage: "Aggressiveness of persisting snapshot diffs, higher values defer less during block import bursts",
+		Value: gdtuconfig.Defaults.SnapshotCompaction,
+	}
 	TxLookupLimitFlag = cli.Uint64Flag{
 		Name:  "txlookuplimit",
 		Usage: "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
 		Value: gdtuconfig.Defaults.TxLookupLimit,
 	}
+	SideChainRetentionFlag = cli.Uint64Flag{
+		Name:  "sidechain.retention",
+		Usage: "Number of recent blocks to keep side chain blocks for (0 = forever)",
+		Value: gdtuconfig.Defaults.SideChainRetention,
+	}
+	PeerBanDurationFlag = cli.DurationFlag{
+		Name:  "sync.banduration",
+		Usage: "Duration to ban peers repeatedly delivering invalid chain data (0 = disabled)",
+	}
+	ImportTrustedFlag = cli.Uint64Flag{
+		Name:  "import.trusted",
+		Usage: "Trusted replay mode: only hash and verify the state root every N imported blocks (0 = every block)",
+	}
 	LightKDFFlag = cli.BoolFlag{
 		Name:  "lightkdf",
 		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
 	}
+	KeyStoreKDFFlag = cli.StringFlag{
+		Name:  "keystore.kdf",
+		Usage: `Key-derivation function used to encrypt new keys ("scrypt" or "argon2id")`,
+		Value: "scrypt",
+	}
+	KeyStoreUpgradeFlag = cli.BoolFlag{
+		Name:  "keystore.upgrade",
+		Usage: "Re-encrypt keys protected by a weaker key-derivation function when unlocked",
+	}
 	WhitelistFlag = cli.StringFlag{
 		Name:  "whitelist",
-		Usage: "Comma separated block number-to-hash mappings to enforce (<number>=<hash>)",
+		Usage: "Comma separated block requirements to enforce (<number>[-<last>]=[hash:|root:|extra:]<value>)",
 	}
 	BloomFilterSizeFlag = cli.Uint64Flag{
 		Name:  "bloomfilter.size",
@@ -234,12 +305,21 @@ var (
 		Name:  "override.berlin",
 		Usage: "Manually specify Berlin fork-block, overriding the bundled setting",
 	}
+	GovernanceFileFlag = cli.StringFlag{
+		Name:  "governance.file",
+		Usage: "Chain configuration file reloaded at runtime to schedule future forks",
+	}
 	// Light server and client settings
 	LightServeFlag = cli.IntFlag{
 		Name:  "light.serve",
 		Usage: "Maximum percentage of time allowed for serving LES requests (multi-threaded processing allows values over 100)",
 		Value: gdtuconfig.Defaults.LightServ,
 	}
+	LightServeMaxFlag = cli.IntFlag{
+		Name:  "light.servemax",
+		Usage: "Maximum percentage of time for serving LES requests when scaling with the free CPU and disk capacity (0 = no scaling)",
+		Value: gdtuconfig.Defaults.LightServMax,
+	}
 	LightIngressFlag = cli.IntFlag{
 		Name:  "light.ingress",
 		Usage: "Incoming bandwidth limit for serving light clients (kilobytes/sec, 0 = unlimited)",
@@ -277,6 +357,32 @@ var (
 		Name:  "light.nosyncserve",
 		Usage: "Enables serving light clients before syncing",
 	}
+	LightServerFilterFlag = cli.BoolFlag{
+		Name:  "light.serverfilter",
+		Usage: "Let les/5 servers filter the logs of block ranges instead of checking the receipts of every candidate block (omissions can't be detected)",
+	}
+	LightOdrCacheFlag = cli.IntFlag{
+		Name:  "light.odrcache",
+		Usage: "Number of on-demand retrieval results cached by light clients (0 = disabled)",
+		Value: gdtuconfig.Defaults.LightOdrCache,
+	}
+	LightWebsocketFlag = cli.BoolFlag{
+		Name:  "light.websocket",
+		Usage: "Accept light clients tunneled over WebSocket on the HTTP-RPC endpoint (path /les)",
+	}
+	LightWebsocketServersFlag = cli.StringFlag{
+		Name:  "light.wsservers",
+		Usage: "Comma separated list of light servers to connect to over WebSocket (ws(s)://<node id>@host[:port]/path)",
+		Value: "",
+	}
+	LightServersPerNetworkFlag = cli.IntFlag{
+		Name:  "light.pernetwork",
+		Usage: "Maximum number of light servers to attach to within a single network (0 = unlimited)",
+	}
+	LightNetworkMapFlag = cli.StringFlag{
+		Name:  "light.networkmap",
+		Usage: "File mapping IP prefixes to networks (<cidr> <network> per line), used with --light.pernetwork instead of grouping servers by subnet",
+	}
 	// Gdtuash settings
 	GdtuashCacheDirFlag = DirectoryFlag{
 		Name:  "gdtuash.cachedir",
@@ -334,6 +440,15 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: core.DefaultTxPoolConfig.Rejournal,
 	}
+	TxPoolRemoteJournalFlag = cli.StringFlag{
+		Name:  "txpool.remotejournal",
+		Usage: "Disk journal for remote transactions to survive node restarts (disabled if empty)",
+	}
+	TxPoolRemoteJournalLimitFlag = cli.Uint64Flag{
+		Name:  "txpool.remotejournallimit",
+		Usage: "Maximum number of remote transactions to journal",
+		Value: core.DefaultTxPoolConfig.RemoteJournalLimit,
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -405,6 +520,11 @@ var (
 		Usage: "Percentage of cache memory allowance to use for snapshot caching (default = 10% full mode, 20% archive mode)",
 		Value: 10,
 	}
+	CacheCodeFlag = cli.IntFlag{
+		Name:  "cache.code",
+		Usage: "Megabytes of memory allocated to the contract code and analysis cache shared between EVMs (0 = disabled)",
+		Value: gdtuconfig.Defaults.CodeCache,
+	}
 	CacheNoPrefetchFlag = cli.BoolFlag{
 		Name:  "cache.noprefetch",
 		Usage: "Disable heuristic state prefetch during block import (less CPU and disk IO, more time waiting for data)",
@@ -460,6 +580,15 @@ var (
 		Name:  "miner.noverify",
 		Usage: "Disable remote sealing verification",
 	}
+	MinerBuilderFlag = cli.StringFlag{
+		Name:  "miner.builder",
+		Usage: "RPC endpoint of an external builder requested for the block payloads",
+	}
+	MinerBuilderTimeoutFlag = cli.DurationFlag{
+		Name:  "miner.builder.timeout",
+		Usage: "Time to wait for the external builder before building blocks locally",
+		Value: gdtuconfig.Defaults.Miner.BuilderTimeout,
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -473,7 +602,7 @@ var (
 	}
 	ExternalSignerFlag = cli.StringFlag{
 		Name:  "signer",
-		Usage: "External signer (url or path to ipc file)",
+		Usage: "External signer (url or path to ipc file, comma separated failover endpoints)",
 		Value: "",
 	}
 	VMEnableDebugFlag = cli.BoolFlag{
@@ -494,6 +623,28 @@ var (
 		Usage: "Sets a cap on transaction fee (in gdtuer) that can be sent via the RPC APIs (0 = no cap)",
 		Value: gdtuconfig.Defaults.RPCTxFeeCap,
 	}
+	RPCCallTimeoutFlag = cli.DurationFlag{
+		Name:  "rpc.calltimeout",
+		Usage: "Maximum execution time of an RPC call served over IPC, HTTP or WebSocket (0 = unlimited)",
+		Value: node.DefaultConfig.RPCCallTimeout,
+	}
+	RPCSlowCallFlag = cli.DurationFlag{
+		Name:  "rpc.slowcall",
+		Usage: "Execution time above which RPC calls are logged as slow (0 = disabled)",
+		Value: node.DefaultConfig.RPCSlowCallThreshold,
+	}
+	RPCTLSCertFlag = cli.StringFlag{
+		Name:  "rpc.tls.cert",
+		Usage: "PEM certificate chain to serve the HTTP and WS RPC servers over TLS with, reloaded when renewed",
+	}
+	RPCTLSKeyFlag = cli.StringFlag{
+		Name:  "rpc.tls.key",
+		Usage: "PEM private key of the RPC TLS certificate, reloaded when renewed",
+	}
+	RPCTLSClientCAFlag = cli.StringFlag{
+		Name:  "rpc.tls.clientca",
+		Usage: "PEM certificate authorities to require and verify RPC TLS client certificates against",
+	}
 	// Logging and debug settings
 	GdtustatsURLFlag = cli.StringFlag{
 		Name:  "gdtustats",
@@ -550,6 +701,14 @@ var (
 		Usage: "HTTP path path prefix on which JSON-RPC is served. Use '/' to serve on all paths.",
 		Value: "",
 	}
+	HTTPEnableHTTP2Flag = cli.BoolFlag{
+		Name:  "http.h2c",
+		Usage: "Enable HTTP/2 over unencrypted connections on the HTTP-RPC server",
+	}
+	HTTPKeepAliveFlag = cli.DurationFlag{
+		Name:  "http.keepalive",
+		Usage: "TCP keep-alive period of HTTP-RPC connections (0 = default, negative disables)",
+	}
 	GraphQLEnabledFlag = cli.BoolFlag{
 		Name:  "graphql",
 		Usage: "Enable GraphQL on the HTTP-RPC server. Note that GraphQL can only be started if an HTTP server is started as well.",
@@ -648,6 +807,14 @@ var (
 		Name:  "v5disc",
 		Usage: "Enables the experimental RLPx V5 (Topic Discovery) mechanism",
 	}
+	SessionResumptionFlag = cli.BoolFlag{
+		Name:  "resumption",
+		Usage: "Enables resuming the RLPx sessions of recently connected peers without a full handshake",
+	}
+	CompressionFlag = cli.StringFlag{
+		Name:  "compression",
+		Usage: "Comma separated message compression codecs to negotiate with peers, by preference (snappy is the fallback)",
+	}
 	NetrestrictFlag = cli.StringFlag{
 		Name:  "netrestrict",
 		Usage: "Restricts network communication to the given IP networks (CIDR masks)",
@@ -680,6 +847,16 @@ var (
 		Usage: "Maximum gas price will be recommended by gpo",
 		Value: gdtuconfig.Defaults.GPO.MaxPrice.Int64(),
 	}
+	GpoPoolWeightFlag = cli.IntFlag{
+		Name:  "gpo.poolweight",
+		Usage: "Percentage the suggested gas price rises with a block worth of pending transactions",
+		Value: gdtuconfig.Defaults.GPO.PoolWeight,
+	}
+	GpoTrendWeightFlag = cli.IntFlag{
+		Name:  "gpo.trendweight",
+		Usage: "Percentage of the rise of the lowest included gas prices added to the suggested gas price",
+		Value: gdtuconfig.Defaults.GPO.TrendWeight,
+	}
 
 	// Metrics flags
 	MetricsEnabledFlag = cli.BoolFlag{
@@ -748,6 +925,14 @@ var (
 		Usage: "External EVM configuration (default = built-in interpreter)",
 		Value: "",
 	}
+	VMParallelFlag = cli.BoolFlag{
+		Name:  "vm.parallel",
+		Usage: "Speculatively execute the transactions of imported blocks in parallel (experimental)",
+	}
+	VMSampleFlag = cli.Uint64Flag{
+		Name:  "vm.sample",
+		Usage: "Sample one out of every N executed opcodes to profile hot contracts (0 = disabled)",
+	}
 )
 
 // MakeDataDir retrieves the currently requested data directory, terminating
@@ -948,6 +1133,12 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(HTTPPathPrefixFlag.Name) {
 		cfg.HTTPPathPrefix = ctx.GlobalString(HTTPPathPrefixFlag.Name)
 	}
+	if ctx.GlobalIsSet(HTTPEnableHTTP2Flag.Name) {
+		cfg.HTTPEnableHTTP2 = ctx.GlobalBool(HTTPEnableHTTP2Flag.Name)
+	}
+	if ctx.GlobalIsSet(HTTPKeepAliveFlag.Name) {
+		cfg.HTTPKeepAlive = ctx.GlobalDuration(HTTPKeepAliveFlag.Name)
+	}
 	if ctx.GlobalIsSet(AllowUnprotectedTxs.Name) {
 		cfg.AllowUnprotectedTxs = ctx.GlobalBool(AllowUnprotectedTxs.Name)
 	}
@@ -1007,6 +1198,9 @@ func setLes(ctx *cli.Context, cfg *gdtuconfig.Config) {
 	if ctx.GlobalIsSet(LightServeFlag.Name) {
 		cfg.LightServ = ctx.GlobalInt(LightServeFlag.Name)
 	}
+	if ctx.GlobalIsSet(LightServeMaxFlag.Name) {
+		cfg.LightServMax = ctx.GlobalInt(LightServeMaxFlag.Name)
+	}
 	if ctx.GlobalIsSet(LightIngressFlag.Name) {
 		cfg.LightIngress = ctx.GlobalInt(LightIngressFlag.Name)
 	}
@@ -1035,6 +1229,24 @@ func setLes(ctx *cli.Context, cfg *gdtuconfig.Config) {
 	if ctx.GlobalIsSet(LightNoSyncServeFlag.Name) {
 		cfg.LightNoSyncServe = ctx.GlobalBool(LightNoSyncServeFlag.Name)
 	}
+	if ctx.GlobalIsSet(LightServerFilterFlag.Name) {
+		cfg.LightServerFilter = ctx.GlobalBool(LightServerFilterFlag.Name)
+	}
+	if ctx.GlobalIsSet(LightOdrCacheFlag.Name) {
+		cfg.LightOdrCache = ctx.GlobalInt(LightOdrCacheFlag.Name)
+	}
+	if ctx.GlobalIsSet(LightWebsocketFlag.Name) {
+		cfg.LightWebsocket = ctx.GlobalBool(LightWebsocketFlag.Name)
+	}
+	if ctx.GlobalIsSet(LightWebsocketServersFlag.Name) {
+		cfg.LightWebsocketServers = strings.Split(ctx.GlobalString(LightWebsocketServersFlag.Name), ",")
+	}
+	if ctx.GlobalIsSet(LightServersPerNetworkFlag.Name) {
+		cfg.LightServersPerNetwork = ctx.GlobalInt(LightServersPerNetworkFlag.Name)
+	}
+	if ctx.GlobalIsSet(LightNetworkMapFlag.Name) {
+		cfg.LightNetworkMap = ctx.GlobalString(LightNetworkMapFlag.Name)
+	}
 }
 
 // MakeDatabaseHandles raises out the number of allowed file handles per process
@@ -1170,6 +1382,12 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	} else if forceV5Discovery {
 		cfg.DiscoveryV5 = true
 	}
+	if ctx.GlobalIsSet(SessionResumptionFlag.Name) {
+		cfg.SessionResumption = ctx.GlobalBool(SessionResumptionFlag.Name)
+	}
+	if ctx.GlobalIsSet(CompressionFlag.Name) {
+		cfg.Compression = SplitAndTrim(ctx.GlobalString(CompressionFlag.Name))
+	}
 
 	if netrestrict := ctx.GlobalString(NetrestrictFlag.Name); netrestrict != "" {
 		list, err := netutil.ParseNetlist(netrestrict)
@@ -1202,13 +1420,25 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(ExternalSignerFlag.Name) {
 		cfg.ExternalSigner = ctx.GlobalString(ExternalSignerFlag.Name)
 	}
+	if ctx.GlobalIsSet(PluginsFlag.Name) {
+		cfg.Plugins = SplitAndTrim(ctx.GlobalString(PluginsFlag.Name))
+	}
 
 	if ctx.GlobalIsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.GlobalString(KeyStoreDirFlag.Name)
 	}
+	if ctx.GlobalIsSet(ExtraKeyStoreDirsFlag.Name) {
+		cfg.ExtraKeyStoreDirs = SplitAndTrim(ctx.GlobalString(ExtraKeyStoreDirsFlag.Name))
+	}
 	if ctx.GlobalIsSet(LightKDFFlag.Name) {
 		cfg.UseLightweightKDF = ctx.GlobalBool(LightKDFFlag.Name)
 	}
+	if ctx.GlobalIsSet(KeyStoreKDFFlag.Name) {
+		cfg.KeyStoreKDF = ctx.GlobalString(KeyStoreKDFFlag.Name)
+	}
+	if ctx.GlobalIsSet(KeyStoreUpgradeFlag.Name) {
+		cfg.UpgradeKeyStoreKDF = ctx.GlobalBool(KeyStoreUpgradeFlag.Name)
+	}
 	if ctx.GlobalIsSet(NoUSBFlag.Name) || cfg.NoUSB {
 		log.Warn("Option nousb is deprecated and USB is deactivated by default. Use --usb to enable")
 	}
@@ -1218,6 +1448,21 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(InsecureUnlockAllowedFlag.Name) {
 		cfg.InsecureUnlockAllowed = ctx.GlobalBool(InsecureUnlockAllowedFlag.Name)
 	}
+	if ctx.GlobalIsSet(RPCCallTimeoutFlag.Name) {
+		cfg.RPCCallTimeout = ctx.GlobalDuration(RPCCallTimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCSlowCallFlag.Name) {
+		cfg.RPCSlowCallThreshold = ctx.GlobalDuration(RPCSlowCallFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCTLSCertFlag.Name) {
+		cfg.RPCTLSCert = ctx.GlobalString(RPCTLSCertFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCTLSKeyFlag.Name) {
+		cfg.RPCTLSKey = ctx.GlobalString(RPCTLSKeyFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCTLSClientCAFlag.Name) {
+		cfg.RPCTLSClientCA = ctx.GlobalString(RPCTLSClientCAFlag.Name)
+	}
 }
 
 func setSmartCard(ctx *cli.Context, cfg *node.Config) {
@@ -1273,6 +1518,8 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config, light bool) {
 	if light {
 		cfg.Blocks = gdtuconfig.LightClientGPO.Blocks
 		cfg.Percentile = gdtuconfig.LightClientGPO.Percentile
+		cfg.PoolWeight = gdtuconfig.LightClientGPO.PoolWeight
+		cfg.TrendWeight = gdtuconfig.LightClientGPO.TrendWeight
 	}
 	if ctx.GlobalIsSet(GpoBlocksFlag.Name) {
 		cfg.Blocks = ctx.GlobalInt(GpoBlocksFlag.Name)
@@ -1283,6 +1530,12 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config, light bool) {
 	if ctx.GlobalIsSet(GpoMaxGasPriceFlag.Name) {
 		cfg.MaxPrice = big.NewInt(ctx.GlobalInt64(GpoMaxGasPriceFlag.Name))
 	}
+	if ctx.GlobalIsSet(GpoPoolWeightFlag.Name) {
+		cfg.PoolWeight = ctx.GlobalInt(GpoPoolWeightFlag.Name)
+	}
+	if ctx.GlobalIsSet(GpoTrendWeightFlag.Name) {
+		cfg.TrendWeight = ctx.GlobalInt(GpoTrendWeightFlag.Name)
+	}
 }
 
 func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
@@ -1305,6 +1558,12 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolRemoteJournalFlag.Name) {
+		cfg.RemoteJournal = ctx.GlobalString(TxPoolRemoteJournalFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolRemoteJournalLimitFlag.Name) {
+		cfg.RemoteJournalLimit = ctx.GlobalUint64(TxPoolRemoteJournalLimitFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1377,6 +1636,12 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.GlobalIsSet(MinerNoVerfiyFlag.Name) {
 		cfg.Noverify = ctx.GlobalBool(MinerNoVerfiyFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerBuilderFlag.Name) {
+		cfg.Builder = ctx.GlobalString(MinerBuilderFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerBuilderTimeoutFlag.Name) {
+		cfg.BuilderTimeout = ctx.GlobalDuration(MinerBuilderTimeoutFlag.Name)
+	}
 }
 
 func setWhitelist(ctx *cli.Context, cfg *gdtuconfig.Config) {
@@ -1384,21 +1649,12 @@ func setWhitelist(ctx *cli.Context, cfg *gdtuconfig.Config) {
 	if whitelist == "" {
 		return
 	}
-	cfg.Whitelist = make(map[uint64]common.Hash)
 	for _, entry := range strings.Split(whitelist, ",") {
-		parts := strings.Split(entry, "=")
-		if len(parts) != 2 {
-			Fatalf("Invalid whitelist entry: %s", entry)
-		}
-		number, err := strconv.ParseUint(parts[0], 0, 64)
+		rule, err := gdtuconfig.ParseWhitelistRule(entry)
 		if err != nil {
-			Fatalf("Invalid whitelist block number %s: %v", parts[0], err)
-		}
-		var hash common.Hash
-		if err = hash.UnmarshalText([]byte(parts[1])); err != nil {
-			Fatalf("Invalid whitelist hash %s: %v", parts[1], err)
+			Fatalf("Invalid whitelist entry %s: %v", entry, err)
 		}
-		cfg.Whitelist[number] = hash
+		cfg.WhitelistRules = append(cfg.WhitelistRules, rule)
 	}
 }
 
@@ -1481,6 +1737,12 @@ func SetGdtuConfig(ctx *cli.Context, stack *node.Node, cfg *gdtuconfig.Config) {
 	if ctx.GlobalIsSet(AncientFlag.Name) {
 		cfg.DatabaseFreezer = ctx.GlobalString(AncientFlag.Name)
 	}
+	if ctx.GlobalIsSet(AncientThresholdFlag.Name) {
+		cfg.DatabaseFreezerThreshold = ctx.GlobalUint64(AncientThresholdFlag.Name)
+	}
+	if ctx.GlobalIsSet(AncientAutoTuneFlag.Name) {
+		cfg.DatabaseFreezerAutoTune = ctx.GlobalBool(AncientAutoTuneFlag.Name)
+	}
 
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
@@ -1491,6 +1753,12 @@ func SetGdtuConfig(ctx *cli.Context, stack *node.Node, cfg *gdtuconfig.Config) {
 	if ctx.GlobalIsSet(CacheNoPrefetchFlag.Name) {
 		cfg.NoPrefetch = ctx.GlobalBool(CacheNoPrefetchFlag.Name)
 	}
+	if ctx.GlobalIsSet(CacheCodeFlag.Name) {
+		cfg.CodeCache = ctx.GlobalInt(CacheCodeFlag.Name)
+	}
+	if ctx.GlobalIsSet(GovernanceFileFlag.Name) {
+		cfg.GovernanceFile = ctx.GlobalString(GovernanceFileFlag.Name)
+	}
 	// Read the value from the flag no matter if it's set or not.
 	cfg.Preimages = ctx.GlobalBool(CachePreimagesFlag.Name)
 	if cfg.NoPruning && !cfg.Preimages {
@@ -1500,6 +1768,12 @@ func SetGdtuConfig(ctx *cli.Context, stack *node.Node, cfg *gdtuconfig.Config) {
 	if ctx.GlobalIsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.GlobalUint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.GlobalIsSet(SideChainRetentionFlag.Name) {
+		cfg.SideChainRetention = ctx.GlobalUint64(SideChainRetentionFlag.Name)
+	}
+	if ctx.GlobalIsSet(PeerBanDurationFlag.Name) {
+		cfg.PeerBanDuration = ctx.GlobalDuration(PeerBanDurationFlag.Name)
+	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
 	}
@@ -1524,6 +1798,18 @@ func SetGdtuConfig(ctx *cli.Context, stack *node.Node, cfg *gdtuconfig.Config) {
 			cfg.SnapshotCache = 0 // Disabled
 		}
 	}
+	if ctx.GlobalIsSet(SnapshotThrottleFlag.Name) {
+		cfg.SnapshotThrottle = ctx.GlobalDuration(SnapshotThrottleFlag.Name)
+	}
+	if ctx.GlobalIsSet(SnapshotMaxDelayFlag.Name) {
+		cfg.SnapshotMaxDelay = ctx.GlobalDuration(SnapshotMaxDelayFlag.Name)
+	}
+	if ctx.GlobalIsSet(SnapshotIdleFlag.Name) {
+		cfg.SnapshotIdle = ctx.GlobalDuration(SnapshotIdleFlag.Name)
+	}
+	if ctx.GlobalIsSet(SnapshotCompactionFlag.Name) {
+		cfg.SnapshotCompaction = ctx.GlobalInt(SnapshotCompactionFlag.Name)
+	}
 	if ctx.GlobalIsSet(DocRootFlag.Name) {
 		cfg.DocRoot = ctx.GlobalString(DocRootFlag.Name)
 	}
@@ -1539,6 +1825,12 @@ func SetGdtuConfig(ctx *cli.Context, stack *node.Node, cfg *gdtuconfig.Config) {
 	if ctx.GlobalIsSet(EVMInterpreterFlag.Name) {
 		cfg.EVMInterpreter = ctx.GlobalString(EVMInterpreterFlag.Name)
 	}
+	if ctx.GlobalIsSet(VMSampleFlag.Name) {
+		cfg.OpcodeSampleInterval = ctx.GlobalUint64(VMSampleFlag.Name)
+	}
+	if ctx.GlobalIsSet(VMParallelFlag.Name) {
+		cfg.ParallelExecution = ctx.GlobalBool(VMParallelFlag.Name)
+	}
 	if ctx.GlobalIsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.GlobalUint64(RPCGlobalGasCapFlag.Name)
 	}
@@ -1623,14 +1915,35 @@ func SetGdtuConfig(ctx *cli.Context, stack *node.Node, cfg *gdtuconfig.Config) {
 		}
 		log.Info("Using developer account", "address", developer.Address)
 
+		// Import and unlock the named developer accounts
+		named := MakeDeveloperAccounts(ctx)
+		for _, account := range named {
+			if !ks.HasAddress(account.Address) {
+				if _, err := ks.ImportECDSA(account.Key, passphrase); err != nil {
+					Fatalf("Failed to import developer account %q: %v", account.Name, err)
+				}
+			}
+			if err := ks.Unlock(accounts.Account{Address: account.Address}, passphrase); err != nil {
+				Fatalf("Failed to unlock developer account %q: %v", account.Name, err)
+			}
+			log.Info("Using named developer account", "name", account.Name, "address", account.Address)
+		}
 		// Create a new developer genesis block or reuse existing one
 		cfg.Genesis = core.DeveloperGenesisBlock(uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name)), developer.Address)
+		fundDeveloperAccounts(cfg.Genesis, named)
+
+		if ctx.GlobalBool(DeveloperResetFlag.Name) {
+			resetDeveloperChain(ctx, stack)
+		}
 		if ctx.GlobalIsSet(DataDirFlag.Name) {
 			// Check if we have an already initialized chain and fall back to
 			// that if so. Otherwise we need to generate a new genesis spec.
 			chaindb := MakeChainDatabase(ctx, stack)
 			if rawdb.ReadCanonicalHash(chaindb, 0) != (common.Hash{}) {
 				cfg.Genesis = nil // fallback to db content
+				if len(named) > 0 {
+					log.Warn("Named developer accounts are only funded in a fresh chain, use --dev.reset to recreate it")
+				}
 			}
 			chaindb.Close()
 		}
@@ -1690,10 +2003,12 @@ func RegisterGdtuService(stack *node.Node, cfg *gdtuconfig.Config) gdtuapi.Backe
 
 // RegisterGdtustatsService configures the Gdtu Stats daemon and adds it to
 // the given node.
-func RegisterGdtustatsService(stack *node.Node, backend gdtuapi.Backend, url string) {
-	if err := gdtustats.New(stack, backend, backend.Engine(), url); err != nil {
+func RegisterGdtustatsService(stack *node.Node, backend gdtuapi.Backend, url string) *gdtustats.Service {
+	service, err := gdtustats.New(stack, backend, backend.Engine(), url)
+	if err != nil {
 		Fatalf("Failed to register the Gdtu Stats service: %v", err)
 	}
+	return service
 }
 
 // RegisterGraphQLService is a utility function to construct a new service and register it against a node.
@@ -120,6 +120,12 @@ func (api *API) Discard(address common.Address) {
 	delete(api.clique.proposals, address)
 }
 
+// GetEquivocations retrieves the evidences of signers sealing two different
+// blocks at the same height, as detected by the local node.
+func (api *API) GetEquivocations() ([]*Equivocation, error) {
+	return api.clique.Equivocations()
+}
+
 type status struct {
 	InturnPercent float64                `json:"inturnPercent"`
 	SigningStatus map[common.Address]int `json:"sealerActivity"`
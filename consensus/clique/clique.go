@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/c88032111/go-gdtu/accounts"
@@ -34,8 +35,10 @@ import (
 	"github.com/c88032111/go-gdtu/core/state"
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/event"
 	"github.com/c88032111/go-gdtu/gdtudb"
 	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/metrics"
 	"github.com/c88032111/go-gdtu/params"
 	"github.com/c88032111/go-gdtu/rlp"
 	"github.com/c88032111/go-gdtu/rpc"
@@ -48,10 +51,16 @@ const (
 	checkpointInterval = 1024 // Number of blocks after which to save the vote snapshot to the database
 	inmemorySnapshots  = 128  // Number of recent vote snapshots to keep in memory
 	inmemorySignatures = 4096 // Number of recent block signatures to keep in memory
+	inmemoryVerified   = 4096 // Number of recently verified block seals to keep in memory
 
 	wiggleTime = 500 * time.Millisecond // Random delay (per signer) to allow concurrent signers
 )
 
+var (
+	sealCacheHitMeter  = metrics.NewRegisteredMeter("consensus/clique/seal/cache/hit", nil)
+	sealCacheMissMeter = metrics.NewRegisteredMeter("consensus/clique/seal/cache/miss", nil)
+)
+
 // Clique proof-of-authority protocol constants.
 var (
 	epochLength = uint64(30000) // Default number of blocks after which to checkpoint and reset the pending votes
@@ -174,6 +183,11 @@ type Clique struct {
 
 	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
 	signatures *lru.ARCCache // Signatures of recent blocks to speed up mining
+	verified   *lru.ARCCache // Hashes of recent blocks with verified seals to avoid reverifying them
+	seals      *lru.ARCCache // Headers of recent verified seals by signer and height to detect equivocations
+	sealsLock  sync.Mutex    // Serialises the equivocation checks of concurrent verifications
+
+	equivocationFeed event.Feed // Feed announcing the detected equivocations
 
 	proposals map[common.Address]bool // Current list of proposals we are pushing
 
@@ -181,6 +195,8 @@ type Clique struct {
 	signFn SignerFn       // Signer function to authorize hashes with
 	lock   sync.RWMutex   // Protects the signer fields
 
+	emptyBlocks uint32 // Number of empty blocks allowed to be sealed on 0-period chains (atomic)
+
 	// The fields below are for testing only
 	fakeDiff bool // Skip difficulty verifications
 }
@@ -196,12 +212,16 @@ func New(config *params.CliqueConfig, db gdtudb.Database) *Clique {
 	// Allocate the snapshot caches and create the engine
 	recents, _ := lru.NewARC(inmemorySnapshots)
 	signatures, _ := lru.NewARC(inmemorySignatures)
+	verified, _ := lru.NewARC(inmemoryVerified)
+	seals, _ := lru.NewARC(inmemorySeals)
 
 	return &Clique{
 		config:     &conf,
 		db:         db,
 		recents:    recents,
 		signatures: signatures,
+		verified:   verified,
+		seals:      seals,
 		proposals:  make(map[common.Address]bool),
 	}
 }
@@ -297,6 +317,9 @@ func (c *Clique) verifyHeader(chain consensus.ChainHeaderReader, header *types.H
 	if err := misc.VerifyForkHashes(chain.Config(), header, false); err != nil {
 		return err
 	}
+	if err := types.VerifyHeaderExtensions(chain.Config(), header); err != nil {
+		return err
+	}
 	// All basic checks passed, verify cascading fields
 	return c.verifyCascadingFields(chain, header, parents)
 }
@@ -444,6 +467,16 @@ func (c *Clique) verifySeal(chain consensus.ChainHeaderReader, header *types.Hea
 	if number == 0 {
 		return errUnknownBlock
 	}
+	// Headers are verified by both the block fetcher and the chain insertion, skip
+	// the seals already found valid. The header hash commits to all ancestors, so
+	// the outcome can't change.
+	hash := header.Hash()
+	if c.verified.Contains(hash) {
+		sealCacheHitMeter.Mark(1)
+		return nil
+	}
+	sealCacheMissMeter.Mark(1)
+
 	// Retrieve the snapshot needed to verify this header and cache it
 	snap, err := c.snapshot(chain, number-1, header.ParentHash, parents)
 	if err != nil {
@@ -476,6 +509,10 @@ func (c *Clique) verifySeal(chain consensus.ChainHeaderReader, header *types.Hea
 			return errWrgdtuDifficulty
 		}
 	}
+	if evidence := c.checkEquivocation(signer, header); evidence != nil {
+		c.equivocationFeed.Send(evidence)
+	}
+	c.verified.Add(hash, struct{}{})
 	return nil
 }
 
@@ -572,6 +609,26 @@ func (c *Clique) Authorize(signer common.Address, signFn SignerFn) {
 	c.signFn = signFn
 }
 
+// AllowEmptyBlocks sets the number of empty blocks that may be sealed on a chain
+// with a 0 period, which otherwise only seals blocks containing transactions.
+func (c *Clique) AllowEmptyBlocks(n uint32) {
+	atomic.StoreUint32(&c.emptyBlocks, n)
+}
+
+// takeEmptyBlock consumes one of the allowed empty blocks, reporting whether an
+// empty block may be sealed.
+func (c *Clique) takeEmptyBlock() bool {
+	for {
+		n := atomic.LoadUint32(&c.emptyBlocks)
+		if n == 0 {
+			return false
+		}
+		if atomic.CompareAndSwapUint32(&c.emptyBlocks, n, n-1) {
+			return true
+		}
+	}
+}
+
 // Seal implements consensus.Engine, attempting to create a sealed block using
 // the local signing credentials.
 func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
@@ -583,7 +640,8 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 		return errUnknownBlock
 	}
 	// For 0-period chains, refuse to seal empty blocks (no reward but would spin sealing)
-	if c.config.Period == 0 && len(block.Transactions()) == 0 {
+	// unless explicitly requested
+	if c.config.Period == 0 && len(block.Transactions()) == 0 && !c.takeEmptyBlock() {
 		log.Info("Sealing paused, waiting for transactions")
 		return nil
 	}
@@ -706,7 +764,7 @@ func CliqueRLP(header *types.Header) []byte {
 }
 
 func encodeSigHeader(w io.Writer, header *types.Header) {
-	err := rlp.Encode(w, []interface{}{
+	enc := []interface{}{
 		header.ParentHash,
 		header.UncleHash,
 		header.Coinbase,
@@ -722,8 +780,11 @@ func encodeSigHeader(w io.Writer, header *types.Header) {
 		header.Extra[:len(header.Extra)-crypto.SignatureLength], // Yes, this will panic if extra is too short
 		header.MixDigest,
 		header.Nonce,
-	})
-	if err != nil {
+	}
+	for _, ext := range header.Extensions {
+		enc = append(enc, ext)
+	}
+	if err := rlp.Encode(w, enc); err != nil {
 		panic("can't encode: " + err.Error())
 	}
 }
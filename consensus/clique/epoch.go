@@ -0,0 +1,126 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/params"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Epoch proofs
+//
+// Every epoch checkpoint header embeds the full list of authorized signers, so
+// a light client knowing the signers of an epoch can skip the headers until the
+// next checkpoint, if it can be convinced that the next checkpoint is canonical.
+//
+// An epoch proof consists of the checkpoint header followed by a few consecutive
+// headers built on top of it. The proof is accepted if more than half of the
+// signers trusted from the previous checkpoint signed one of its headers. As a
+// signer may only sign one block out of every floor(N/2)+1 consecutive ones, the
+// first floor(N/2)+1 headers are signed by distinct signers. Signers voted in
+// during the epoch may also appear in the proof, but don't count towards the
+// majority. If the signer set changed too much within a single epoch, the proof
+// can't be verified and the headers must be synced one by one.
+
+// errInvalidEpochProof is returned if an epoch proof is malformed or is not
+// signed by enough trusted signers.
+var errInvalidEpochProof = errors.New("invalid epoch proof")
+
+// CheckpointSigners returns the list of authorized signers embedded in the
+// extra-data of an epoch checkpoint header.
+func CheckpointSigners(header *types.Header) ([]common.Address, error) {
+	if len(header.Extra) < extraVanity+extraSeal {
+		return nil, errMissingSignature
+	}
+	signersBytes := len(header.Extra) - extraVanity - extraSeal
+	if signersBytes%common.AddressLength != 0 {
+		return nil, errInvalidCheckpointSigners
+	}
+	signers := make([]common.Address, signersBytes/common.AddressLength)
+	for i := 0; i < len(signers); i++ {
+		copy(signers[i][:], header.Extra[extraVanity+i*common.AddressLength:])
+	}
+	return signers, nil
+}
+
+// EpochProofLength returns the number of headers an epoch proof for a checkpoint
+// with the given number of signers should contain.
+func EpochProofLength(signers int) int {
+	return signers/2 + 1
+}
+
+// VerifyEpochProof verifies that the epoch checkpoint header at the front of the
+// given headers was built upon by a majority of the trusted signers, which are
+// the signers of the previous checkpoint. The signers authorized by the verified
+// checkpoint are returned.
+//
+// Only the signatures and the linkage of the headers are verified. The other
+// header fields must be verified once the chain is synced on top of them.
+func VerifyEpochProof(config *params.CliqueConfig, trusted []common.Address, headers []*types.Header) ([]common.Address, error) {
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("%w: no headers", errInvalidEpochProof)
+	}
+	checkpoint := headers[0]
+	if number := checkpoint.Number.Uint64(); number == 0 || number%config.Epoch != 0 {
+		return nil, fmt.Errorf("%w: block #%d is not an epoch checkpoint", errInvalidEpochProof, number)
+	}
+	signers, err := CheckpointSigners(checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidEpochProof, err)
+	}
+	var (
+		allowed = make(map[common.Address]bool) // Whgdtuer the signer is trusted
+		signed  = make(map[common.Address]bool)
+		votes   int
+	)
+	for _, signer := range signers {
+		allowed[signer] = false
+	}
+	for _, signer := range trusted {
+		allowed[signer] = true
+	}
+	sigcache, _ := lru.NewARC(len(headers))
+	for i, header := range headers {
+		if i > 0 {
+			parent := headers[i-1]
+			if header.Number.Uint64() != parent.Number.Uint64()+1 || header.ParentHash != parent.Hash() {
+				return nil, fmt.Errorf("%w: header #%d not linked to its parent", errInvalidEpochProof, header.Number)
+			}
+		}
+		signer, err := ecrecover(header, sigcache)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidEpochProof, err)
+		}
+		isTrusted, ok := allowed[signer]
+		if !ok {
+			return nil, fmt.Errorf("%w: header #%d signed by unauthorized signer %x", errInvalidEpochProof, header.Number, signer)
+		}
+		if isTrusted && !signed[signer] {
+			votes++
+		}
+		signed[signer] = true
+	}
+	if votes <= len(trusted)/2 {
+		return nil, fmt.Errorf("%w: signed by %d of %d trusted signers", errInvalidEpochProof, votes, len(trusted))
+	}
+	return signers, nil
+}
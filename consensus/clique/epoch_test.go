@@ -0,0 +1,100 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// Tests that epoch proofs are only accepted if signed by a majority of the
+// trusted signers.
+func TestVerifyEpochProof(t *testing.T) {
+	var (
+		accounts = newTesterAccountPool()
+		config   = &params.CliqueConfig{Period: 1, Epoch: 10}
+		trusted  = []common.Address{accounts.address("A"), accounts.address("B"), accounts.address("C")}
+	)
+	// makeProof creates a proof for the checkpoint #number authorizing the given
+	// signers, with its headers signed by the given accounts
+	makeProof := func(number uint64, checkpoint []string, signers ...string) []*types.Header {
+		var headers []*types.Header
+		for i, signer := range signers {
+			header := &types.Header{
+				Number:     new(big.Int).SetUint64(number + uint64(i)),
+				Difficulty: diffInTurn,
+				Extra:      make([]byte, extraVanity+extraSeal),
+			}
+			if i == 0 {
+				header.Extra = make([]byte, extraVanity+len(checkpoint)*common.AddressLength+extraSeal)
+				accounts.checkpoint(header, checkpoint)
+			} else {
+				header.ParentHash = headers[i-1].Hash()
+			}
+			accounts.sign(header, signer)
+			headers = append(headers, header)
+		}
+		return headers
+	}
+	tests := []struct {
+		headers []*types.Header
+		fail    bool
+	}{
+		// Signed by a trusted majority
+		{headers: makeProof(10, []string{"A", "B", "C"}, "A", "B")},
+		// Signer voted in during the epoch, but a trusted majority signed anyway
+		{headers: makeProof(10, []string{"A", "B", "C", "D"}, "A", "D", "B")},
+		// Repeated signatures only count once
+		{headers: makeProof(10, []string{"A", "B", "C"}, "A", "A"), fail: true},
+		// New signers don't count towards the majority
+		{headers: makeProof(10, []string{"A", "D", "E"}, "A", "D", "E"), fail: true},
+		// Signers not authorized at all are rejected
+		{headers: makeProof(10, []string{"A", "B", "C"}, "A", "B", "X"), fail: true},
+		// Headers which are not epoch checkpoints are rejected
+		{headers: makeProof(11, []string{"A", "B", "C"}, "A", "B"), fail: true},
+		// Empty proofs are rejected
+		{headers: nil, fail: true},
+	}
+	for i, tt := range tests {
+		signers, err := VerifyEpochProof(config, trusted, tt.headers)
+		if tt.fail {
+			if !errors.Is(err, errInvalidEpochProof) {
+				t.Errorf("test %d: have error %v, want %v", i, err, errInvalidEpochProof)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: failed to verify proof: %v", i, err)
+			continue
+		}
+		if want, _ := CheckpointSigners(tt.headers[0]); len(signers) != len(want) {
+			t.Errorf("test %d: signer count mismatch: have %d, want %d", i, len(signers), len(want))
+		}
+	}
+	// Proofs whose headers aren't linked are rejected
+	headers := makeProof(10, []string{"A", "B", "C"}, "A", "B")
+	headers[1].ParentHash = common.Hash{0x01}
+	accounts.sign(headers[1], "B")
+	if _, err := VerifyEpochProof(config, trusted, headers); !errors.Is(err, errInvalidEpochProof) {
+		t.Errorf("unlinked proof: have error %v, want %v", err, errInvalidEpochProof)
+	}
+}
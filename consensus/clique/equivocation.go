@@ -0,0 +1,153 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+package clique
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/event"
+	"github.com/c88032111/go-gdtu/gdtudb"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/metrics"
+)
+
+// Equivocation detection
+//
+// A clique signer is only ever supposed to sign a single block at any height. A
+// signer sealing two different blocks at the same height (double-signing) tries
+// to fork the network, which the protocol itself doesn't punish. The engine keeps
+// track of the seals it verifies and records any conflicting pair as evidence,
+// so that the operators of the network can vote the misbehaving signer out.
+
+const inmemorySeals = 4096 // Number of recent verified seals to keep in memory for equivocation checks
+
+// equivocationPrefix is the database key prefix of the persisted equivocation
+// evidences, followed by the signer address and the block number.
+var equivocationPrefix = []byte("clique-equivocation-")
+
+var equivocationMeter = metrics.NewRegisteredMeter("consensus/clique/equivocations", nil)
+
+// Equivocation is the evidence of a signer sealing two different blocks at the
+// same height.
+type Equivocation struct {
+	Signer common.Address `json:"signer"` // Signer sealing both headers
+	Number uint64         `json:"number"` // Height of the conflicting headers
+	First  *types.Header  `json:"first"`  // Header first seen by the local node
+	Second *types.Header  `json:"second"` // Conflicting header sealed by the same signer
+}
+
+// sealKey identifies the seal of a signer at a given height.
+type sealKey struct {
+	signer common.Address
+	number uint64
+}
+
+// equivocationKey = equivocationPrefix + signer + number (uint64 big endian)
+func equivocationKey(signer common.Address, number uint64) []byte {
+	key := make([]byte, len(equivocationPrefix)+common.AddressLength+8)
+	copy(key, equivocationPrefix)
+	copy(key[len(equivocationPrefix):], signer[:])
+	binary.BigEndian.PutUint64(key[len(equivocationPrefix)+common.AddressLength:], number)
+	return key
+}
+
+// checkEquivocation records the seal of a verified header and checks whether the
+// same signer has already sealed a different block at the same height. Any new
+// conflict found is persisted and returned as evidence.
+func (c *Clique) checkEquivocation(signer common.Address, header *types.Header) *Equivocation {
+	c.sealsLock.Lock()
+	defer c.sealsLock.Unlock()
+
+	key := sealKey{signer: signer, number: header.Number.Uint64()}
+
+	prev, ok := c.seals.Get(key)
+	if !ok {
+		c.seals.Add(key, header)
+		return nil
+	}
+	first := prev.(*types.Header)
+
+	// Seals over the same content are not conflicting (the signature might still
+	// differ if the signer doesn't use deterministic nonces)
+	if SealHash(first) == SealHash(header) {
+		return nil
+	}
+	// Only keep the first evidence of a signer equivocating at a given height
+	dbkey := equivocationKey(signer, key.number)
+	if has, _ := c.db.Has(dbkey); has {
+		return nil
+	}
+	evidence := &Equivocation{
+		Signer: signer,
+		Number: key.number,
+		First:  first,
+		Second: header,
+	}
+	blob, err := json.Marshal(evidence)
+	if err != nil {
+		log.Error("Failed to encode equivocation", "err", err)
+		return nil
+	}
+	if err := c.db.Put(dbkey, blob); err != nil {
+		log.Error("Failed to store equivocation", "err", err)
+		return nil
+	}
+	equivocationMeter.Mark(1)
+	log.Warn("Signer equivocation detected", "signer", signer, "number", key.number, "first", first.Hash(), "second", header.Hash())
+	return evidence
+}
+
+// SubscribeEquivocations registers a subscription for the equivocations detected
+// by the engine.
+func (c *Clique) SubscribeEquivocations(ch chan<- *Equivocation) event.Subscription {
+	return c.equivocationFeed.Subscribe(ch)
+}
+
+// Equivocations retrieves all the equivocation evidences persisted in the
+// database, ordered by block number.
+func (c *Clique) Equivocations() ([]*Equivocation, error) {
+	return readEquivocations(c.db)
+}
+
+// readEquivocations retrieves all the equivocation evidences stored in the given
+// database, ordered by block number.
+func readEquivocations(db gdtudb.Iteratee) ([]*Equivocation, error) {
+	it := db.NewIterator(equivocationPrefix, nil)
+	defer it.Release()
+
+	evidences := []*Equivocation{}
+	for it.Next() {
+		if len(it.Key()) != len(equivocationPrefix)+common.AddressLength+8 {
+			continue
+		}
+		evidence := new(Equivocation)
+		if err := json.Unmarshal(it.Value(), evidence); err != nil {
+			return nil, err
+		}
+		evidences = append(evidences, evidence)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(evidences, func(i, j int) bool {
+		return evidences[i].Number < evidences[j].Number
+	})
+	return evidences, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+package clique
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// Tests that two different blocks sealed by the same signer at the same height
+// are detected and persisted as an equivocation.
+func TestEquivocationDetection(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		key, _ = crypto.GenerateKey()
+		signer = crypto.PubkeyToAddress(key.PublicKey)
+		engine = New(params.AllCliqueProtocolChanges.Clique, db)
+	)
+	seal := func(number int64, time uint64) *types.Header {
+		header := &types.Header{
+			Number:     big.NewInt(number),
+			Time:       time,
+			Difficulty: diffInTurn,
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		sig, _ := crypto.Sign(SealHash(header).Bytes(), key)
+		copy(header.Extra[extraVanity:], sig)
+		return header
+	}
+	first, second, other := seal(5, 100), seal(5, 101), seal(6, 102)
+
+	// Distinct heights and repeated seals are not equivocations
+	for i, header := range []*types.Header{first, other, first} {
+		if evidence := engine.checkEquivocation(signer, header); evidence != nil {
+			t.Fatalf("header %d: unexpected equivocation: %v", i, evidence)
+		}
+	}
+	// A conflicting seal at the same height must be reported once
+	evidence := engine.checkEquivocation(signer, second)
+	if evidence == nil {
+		t.Fatalf("equivocation not detected")
+	}
+	if evidence.Signer != signer || evidence.Number != 5 || evidence.First.Hash() != first.Hash() || evidence.Second.Hash() != second.Hash() {
+		t.Fatalf("evidence mismatch: have %+v", evidence)
+	}
+	if evidence := engine.checkEquivocation(signer, second); evidence != nil {
+		t.Fatalf("equivocation reported twice")
+	}
+	// The evidence must be retrievable, even after a restart
+	for i, engine := range []*Clique{engine, New(params.AllCliqueProtocolChanges.Clique, db)} {
+		evidences, err := engine.Equivocations()
+		if err != nil {
+			t.Fatalf("engine %d: failed to retrieve equivocations: %v", i, err)
+		}
+		if len(evidences) != 1 {
+			t.Fatalf("engine %d: equivocation count mismatch: have %d, want 1", i, len(evidences))
+		}
+		if evidences[0].Signer != signer || evidences[0].First.Hash() != first.Hash() || evidences[0].Second.Hash() != second.Hash() {
+			t.Errorf("engine %d: evidence mismatch: have %+v", i, evidences[0])
+		}
+	}
+}
@@ -412,7 +412,11 @@ func TestClique(t *testing.T) {
 		engine := New(config.Clique, db)
 		engine.fakeDiff = true
 
-		blocks, _ := core.GenerateChain(&config, genesis.ToBlock(db), engine, db, len(tt.votes), func(j int, gen *core.BlockGen) {
+		gblock, err := genesis.ToBlock(db)
+		if err != nil {
+			t.Fatalf("test %d: failed to create genesis block: %v", i, err)
+		}
+		blocks, _ := core.GenerateChain(&config, gblock, engine, db, len(tt.votes), func(j int, gen *core.BlockGen) {
 			// Cast the vote contained in this block
 			gen.SetCoinbase(accounts.address(tt.votes[j].voted))
 			if tt.votes[j].auth {
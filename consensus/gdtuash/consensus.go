@@ -306,6 +306,9 @@ func (gdtuash *Gdtuash) verifyHeader(chain consensus.ChainHeaderReader, header,
 	if err := misc.VerifyForkHashes(chain.Config(), header, uncle); err != nil {
 		return err
 	}
+	if err := types.VerifyHeaderExtensions(chain.Config(), header); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -512,6 +515,16 @@ func (gdtuash *Gdtuash) verifySeal(chain consensus.ChainHeaderReader, header *ty
 	if gdtuash.shared != nil {
 		return gdtuash.shared.verifySeal(chain, header, fulldag)
 	}
+	// Headers are verified by both the block fetcher and the chain insertion, skip
+	// the seals already found valid
+	hash := header.Hash()
+	if gdtuash.verified != nil {
+		if gdtuash.verified.Contains(hash) {
+			sealCacheHitMeter.Mark(1)
+			return nil
+		}
+		sealCacheMissMeter.Mark(1)
+	}
 	// Ensure that we have a valid difficulty for the block
 	if header.Difficulty.Sign() <= 0 {
 		return errInvalidDifficulty
@@ -559,6 +572,9 @@ func (gdtuash *Gdtuash) verifySeal(chain consensus.ChainHeaderReader, header *ty
 	if new(big.Int).SetBytes(result).Cmp(target) > 0 {
 		return errInvalidPoW
 	}
+	if gdtuash.verified != nil {
+		gdtuash.verified.Add(hash, struct{}{})
+	}
 	return nil
 }
 
@@ -595,7 +611,7 @@ func (gdtuash *Gdtuash) FinalizeAndAssemble(chain consensus.ChainHeaderReader, h
 func (gdtuash *Gdtuash) SealHash(header *types.Header) (hash common.Hash) {
 	hasher := sha3.NewLegacyKeccak256()
 
-	rlp.Encode(hasher, []interface{}{
+	enc := []interface{}{
 		header.ParentHash,
 		header.UncleHash,
 		header.Coinbase,
@@ -609,7 +625,11 @@ func (gdtuash *Gdtuash) SealHash(header *types.Header) (hash common.Hash) {
 		header.GasUsed,
 		header.Time,
 		header.Extra,
-	})
+	}
+	for _, ext := range header.Extensions {
+		enc = append(enc, ext)
+	}
+	rlp.Encode(hasher, enc)
 	hasher.Sum(hash[:0])
 	return hash
 }
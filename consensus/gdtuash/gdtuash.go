@@ -38,6 +38,7 @@ import (
 	"github.com/c88032111/go-gdtu/metrics"
 	"github.com/c88032111/go-gdtu/rpc"
 	"github.com/edsrzf/mmap-go"
+	lrucache "github.com/hashicorp/golang-lru"
 	"github.com/hashicorp/golang-lru/simplelru"
 )
 
@@ -55,8 +56,20 @@ var (
 
 	// dumpMagic is a dataset dump header to sanity check a data dump.
 	dumpMagic = []uint32{0xbaddcafe, 0xfee1dead}
+
+	sealCacheHitMeter  = metrics.NewRegisteredMeter("consensus/gdtuash/seal/cache/hit", nil)
+	sealCacheMissMeter = metrics.NewRegisteredMeter("consensus/gdtuash/seal/cache/miss", nil)
 )
 
+// verifiedSeals is the number of recently verified header seals to keep in memory.
+const verifiedSeals = 4096
+
+// newVerifiedCache creates the cache of the header hashes with verified seals.
+func newVerifiedCache() *lrucache.ARCCache {
+	cache, _ := lrucache.NewARC(verifiedSeals)
+	return cache
+}
+
 // isLittleEndian returns whether the local system is running in little or big
 // endian byte order.
 func isLittleEndian() bool {
@@ -419,8 +432,9 @@ type Config struct {
 type Gdtuash struct {
 	config Config
 
-	caches   *lru // In memory caches to avoid regenerating too often
-	datasets *lru // In memory datasets to avoid regenerating too often
+	caches   *lru               // In memory caches to avoid regenerating too often
+	datasets *lru               // In memory datasets to avoid regenerating too often
+	verified *lrucache.ARCCache // Hashes of headers with recently verified seals to avoid reverifying them
 
 	// Mining related fields
 	rand     *rand.Rand    // Properly seeded random source for nonces
@@ -459,6 +473,7 @@ func New(config Config, notify []string, noverify bool) *Gdtuash {
 		config:   config,
 		caches:   newlru("cache", config.CachesInMem, newCache),
 		datasets: newlru("dataset", config.DatasetsInMem, newDataset),
+		verified: newVerifiedCache(),
 		update:   make(chan struct{}),
 		hashrate: metrics.NewMeterForced(),
 	}
@@ -473,6 +488,7 @@ func NewTester(notify []string, noverify bool) *Gdtuash {
 		config:   Config{PowMode: ModeTest, Log: log.Root()},
 		caches:   newlru("cache", 1, newCache),
 		datasets: newlru("dataset", 1, newDataset),
+		verified: newVerifiedCache(),
 		update:   make(chan struct{}),
 		hashrate: metrics.NewMeterForced(),
 	}
@@ -54,6 +54,42 @@ func TestTestMode(t *testing.T) {
 	}
 }
 
+// Tests that verified seals are cached by header hash, and that changing any
+// field of a verified header requires verifying it again.
+func TestSealCache(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+
+	gdtuash := NewTester(nil, false)
+	defer gdtuash.Close()
+
+	results := make(chan *types.Block)
+	if err := gdtuash.Seal(nil, types.NewBlockWithHeader(header), results, nil); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	block := <-results
+	header.Nonce = types.EncodeNonce(block.Nonce())
+	header.MixDigest = block.MixDigest()
+
+	if gdtuash.verified.Contains(header.Hash()) {
+		t.Fatalf("unverified seal cached")
+	}
+	for i := 0; i < 2; i++ {
+		if err := gdtuash.verifySeal(nil, header, false); err != nil {
+			t.Fatalf("verification %d failed: %v", i, err)
+		}
+		if !gdtuash.verified.Contains(header.Hash()) {
+			t.Fatalf("verification %d: verified seal not cached", i)
+		}
+	}
+	header.Nonce = types.EncodeNonce(block.Nonce() + 1)
+	if err := gdtuash.verifySeal(nil, header, false); err == nil {
+		t.Fatalf("invalid seal verified")
+	}
+	if gdtuash.verified.Contains(header.Hash()) {
+		t.Fatalf("invalid seal cached")
+	}
+}
+
 // This test checks that cache lru logic doesn't crash under load.
 // It reproduces https://github.com/c88032111/go-gdtu/issues/14943
 func TestCacheFileEvict(t *testing.T) {
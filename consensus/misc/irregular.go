@@ -0,0 +1,49 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+
+	"github.com/c88032111/go-gdtu/core/state"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// ApplyIrregularChanges modifies the state database according to the irregular
+// state changes scheduled for the given block in the chain config. Transfers
+// exceeding the balance of their source account move the entire balance.
+func ApplyIrregularChanges(config *params.ChainConfig, number *big.Int, statedb *state.StateDB) {
+	for _, change := range config.IrregularChangesAt(number) {
+		for _, transfer := range change.Transfers {
+			amount := statedb.GetBalance(transfer.From)
+			if transfer.Amount != nil && transfer.Amount.Cmp(amount) < 0 {
+				amount = transfer.Amount
+			}
+			if !statedb.Exist(transfer.To) {
+				statedb.CreateAccount(transfer.To)
+			}
+			statedb.SubBalance(transfer.From, amount)
+			statedb.AddBalance(transfer.To, amount)
+		}
+		for _, code := range change.Code {
+			if !statedb.Exist(code.Address) {
+				statedb.CreateAccount(code.Address)
+			}
+			statedb.SetCode(code.Address, code.Code)
+		}
+	}
+}
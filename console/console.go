@@ -204,6 +204,11 @@ func (c *Console) initExtensions() error {
 		}
 	}
 
+	// Expose the server Methods not covered by the extensions.
+	if err := c.initMethods(); err != nil {
+		return err
+	}
+
 	// Apply aliases.
 	c.jsre.Do(func(vm *goja.Runtime) {
 		web3 := getObject(vm, "web3")
@@ -216,6 +221,74 @@ func (c *Console) initExtensions() error {
 	return nil
 }
 
+// initMethods retrieves the Methods available on the server and registers the
+// ones not yet known to web3.js, so that the namespaces of custom APIs show up
+// in the console and its autocompletion. Calls must pass at least the required
+// parameters of the Method, the optional trailing ones may be omitted. Servers
+// not supporting Method introspection are silently ignored.
+func (c *Console) initMethods() error {
+	methods, err := c.client.SupportedMethods()
+	if err != nil {
+		return nil
+	}
+	namespaces := make([]string, 0, len(methods))
+	for namespace := range methods {
+		if namespace != "web3" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	// Collect the Methods missing from the web3 object. Existing entries are
+	// checked by key, as reading them would invoke the web3.js property getters.
+	var missing []string
+	c.jsre.Do(func(vm *goja.Runtime) {
+		web3 := getObject(vm, "web3")
+		for _, namespace := range namespaces {
+			known := make(map[string]struct{})
+			if obj := web3.Get(namespace); obj != nil && !goja.IsUndefined(obj) && !goja.IsNull(obj) {
+				for _, key := range obj.ToObject(vm).Keys() {
+					known[key] = struct{}{}
+				}
+			}
+			var defs []string
+			for _, method := range methods[namespace] {
+				if _, ok := known[method.Name]; ok || method.Subscription {
+					continue
+				}
+				defs = append(defs, fmt.Sprintf("method(%q, %q, %d, %d)",
+					method.Name, namespace+"_"+method.Name, method.Params, method.Required))
+			}
+			if len(defs) > 0 {
+				missing = append(missing, fmt.Sprintf("web3._extend({property: %q, Methods: [%s]});", namespace, strings.Join(defs, ", ")))
+			}
+		}
+	})
+	if len(missing) == 0 {
+		return nil
+	}
+	if err := c.jsre.Compile("methods.js", fmt.Sprintf(methodsJS, strings.Join(missing, "\n"))); err != nil {
+		return fmt.Errorf("methods.js: %v", err)
+	}
+	return nil
+}
+
+// methodsJS wraps the definitions of the Methods registered by initMethods. The
+// argument count is validated before formatting, as web3.js would only check
+// the formatted parameters, sending null for the missing required ones.
+const methodsJS = `(function() {
+	function method(name, call, params, required) {
+		var m = new web3._extend.Method({name: name, call: call, params: params});
+		m.validateArgs = function(args) {
+			if (args.length < required || args.length > params) {
+				throw new Error('Invalid number of input parameters to RPC Method');
+			}
+		};
+		return m;
+	}
+%s
+})();`
+
 // initAdmin creates additional admin APIs implemented by the bridge.
 func (c *Console) initAdmin(vm *goja.Runtime, bridge *bridge) {
 	if admin := getObject(vm, "admin"); admin != nil {
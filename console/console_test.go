@@ -35,6 +35,7 @@ import (
 	"github.com/c88032111/go-gdtu/internal/jsre"
 	"github.com/c88032111/go-gdtu/miner"
 	"github.com/c88032111/go-gdtu/node"
+	"github.com/c88032111/go-gdtu/rpc"
 )
 
 const (
@@ -340,3 +341,58 @@ func TestIndenting(t *testing.T) {
 		}
 	}
 }
+
+// customAPI is an RPC service unknown to the web3.js extensions.
+type customAPI struct{}
+
+func (customAPI) Echo(msg string, times *int) string {
+	if times == nil {
+		return msg
+	}
+	return strings.Repeat(msg, *times)
+}
+
+// Tests that Methods of RPC namespaces without web3.js extensions are exposed
+// in the console and its autocompletion.
+func TestCustomNamespace(t *testing.T) {
+	workspace, err := ioutil.TempDir("", "console-tester-")
+	if err != nil {
+		t.Fatalf("failed to create temporary workspace: %v", err)
+	}
+	defer os.RemoveAll(workspace)
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("custom", new(customAPI)); err != nil {
+		t.Fatalf("failed to register custom API: %v", err)
+	}
+	printer := new(bytes.Buffer)
+	console, err := New(Config{
+		DataDir:  workspace,
+		DocRoot:  "testdata",
+		Client:   rpc.DialInProc(server),
+		Prompter: &hookedPrompter{scheduler: make(chan string)},
+		Printer:  printer,
+	})
+	if err != nil {
+		t.Fatalf("failed to create JavaScript console: %v", err)
+	}
+	defer console.Stop(false)
+
+	if _, completions, _ := console.AutoCompleteInput("custom.e", 8); len(completions) != 1 || completions[0] != "custom.echo" {
+		t.Errorf("completions mismatch: have %v, want [custom.echo]", completions)
+	}
+	console.Evaluate("custom.echo('gdtu')")
+	console.Evaluate("web3.custom.echo('rpc', 2)")
+	if output := printer.String(); !strings.Contains(output, `"gdtu"`) || !strings.Contains(output, `"rpcrpc"`) {
+		t.Errorf("custom Method output mismatch: have %s", output)
+	}
+	// Missing required and surplus parameters must be rejected
+	for _, call := range []string{"custom.echo()", "custom.echo('rpc', 2, 3)"} {
+		printer.Reset()
+		console.Evaluate(call)
+		if output := printer.String(); !strings.Contains(output, "Invalid number of input parameters") {
+			t.Errorf("%s: output mismatch: have %s", call, output)
+		}
+	}
+}
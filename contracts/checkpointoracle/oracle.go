@@ -55,12 +55,15 @@ func (oracle *CheckpointOracle) Contract() *contract.CheckpointOracle {
 }
 
 // LookupCheckpointEvents searches checkpoint event for specific section in the
-// given log batches.
+// given log batches. Events emitted by other contracts are ignored.
 func (oracle *CheckpointOracle) LookupCheckpointEvents(blockLogs [][]*types.Log, section uint64, hash common.Hash) []*contract.CheckpointOracleNewCheckpointVote {
 	var votes []*contract.CheckpointOracleNewCheckpointVote
 
 	for _, logs := range blockLogs {
 		for _, log := range logs {
+			if log.Address != oracle.address {
+				continue
+			}
 			event, err := oracle.contract.ParseNewCheckpointVote(*log)
 			if err != nil {
 				continue
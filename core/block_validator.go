@@ -31,15 +31,13 @@ import (
 //
 // BlockValidator implements Validator.
 type BlockValidator struct {
-	config *params.ChainConfig // Chain configuration options
-	bc     *BlockChain         // Canonical block chain
-	engine consensus.Engine    // Consensus engine used for validating
+	bc     *BlockChain      // Canonical block chain
+	engine consensus.Engine // Consensus engine used for validating
 }
 
 // NewBlockValidator returns a new block validator which is safe for re-use
-func NewBlockValidator(config *params.ChainConfig, blockchain *BlockChain, engine consensus.Engine) *BlockValidator {
+func NewBlockValidator(blockchain *BlockChain, engine consensus.Engine) *BlockValidator {
 	validator := &BlockValidator{
-		config: config,
 		engine: engine,
 		bc:     blockchain,
 	}
@@ -79,6 +77,21 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 // itself. ValidateState returns a database batch if the validation was a success
 // otherwise nil and an error is returned.
 func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateDB, receipts types.Receipts, usedGas uint64) error {
+	header := block.Header()
+	if err := validateReceipts(block, receipts, usedGas); err != nil {
+		return err
+	}
+	// Validate the state root against the received state root and throw
+	// an error if they don't match.
+	if root := statedb.IntermediateRoot(v.bc.Config().IsEIP158(header.Number)); header.Root != root {
+		return fmt.Errorf("invalid merkle root (remote: %x local: %x)", header.Root, root)
+	}
+	return nil
+}
+
+// validateReceipts validates the gas used, the bloom and the receipt root of a
+// block against the receipts produced by processing it.
+func validateReceipts(block *types.Block, receipts types.Receipts, usedGas uint64) error {
 	header := block.Header()
 	if block.GasUsed() != usedGas {
 		return fmt.Errorf("invalid gas used (remote: %d local: %d)", block.GasUsed(), usedGas)
@@ -94,11 +107,6 @@ func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateD
 	if receiptSha != header.ReceiptHash {
 		return fmt.Errorf("invalid receipt root hash (remote: %x local: %x)", header.ReceiptHash, receiptSha)
 	}
-	// Validate the state root against the received state root and throw
-	// an error if they don't match.
-	if root := statedb.IntermediateRoot(v.config.IsEIP158(header.Number)); header.Root != root {
-		return fmt.Errorf("invalid merkle root (remote: %x local: %x)", header.Root, root)
-	}
 	return nil
 }
 
@@ -138,3 +146,43 @@ func CalcGasLimit(parent *types.Block, gasFloor, gasCeil uint64) uint64 {
 	}
 	return limit
 }
+
+// GasLimitStrategy decides the gas limit of the blocks built on top of a parent,
+// given the gas floor and ceil targeted by the miner. Custom strategies allow
+// chains to implement their own ramp rules, but the returned limits are bound to
+// the change allowed by the consensus rules anyway, see ClampGasLimit.
+type GasLimitStrategy interface {
+	GasLimit(parent *types.Block, gasFloor, gasCeil uint64) uint64
+}
+
+// GasLimitStrategyFunc is an adapter to allow the use of ordinary functions as
+// gas limit strategies.
+type GasLimitStrategyFunc func(parent *types.Block, gasFloor, gasCeil uint64) uint64
+
+// GasLimit implements GasLimitStrategy, calling f(parent, gasFloor, gasCeil).
+func (f GasLimitStrategyFunc) GasLimit(parent *types.Block, gasFloor, gasCeil uint64) uint64 {
+	return f(parent, gasFloor, gasCeil)
+}
+
+// DefaultGasLimitStrategy is the gas limit strategy used if none is configured,
+// see CalcGasLimit.
+var DefaultGasLimitStrategy GasLimitStrategy = GasLimitStrategyFunc(CalcGasLimit)
+
+// ClampGasLimit bounds a gas limit to the range allowed by the consensus rules
+// for a block whose parent has the given gas limit.
+func ClampGasLimit(parentGasLimit, limit uint64) uint64 {
+	// The change must be strictly less than parentGasLimit / 1024
+	delta := parentGasLimit / params.GasLimitBoundDivisor
+	if delta > 0 {
+		delta--
+	}
+	if limit > parentGasLimit+delta {
+		limit = parentGasLimit + delta
+	} else if limit+delta < parentGasLimit {
+		limit = parentGasLimit - delta
+	}
+	if limit < params.MinGasLimit {
+		limit = params.MinGasLimit
+	}
+	return limit
+}
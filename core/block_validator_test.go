@@ -197,3 +197,22 @@ func testHeaderConcurrentAbortion(t *testing.T, threads int) {
 		t.Errorf("verification count too large: have %d, want below %d", verified, 2*threads)
 	}
 }
+
+// Tests that gas limits are bound to the change allowed by the consensus rules.
+func TestClampGasLimit(t *testing.T) {
+	tests := []struct {
+		parent, limit, want uint64
+	}{
+		{8000000, 8000000, 8000000},
+		{8000000, 8005000, 8005000},
+		{8000000, 9000000, 8007811},
+		{8000000, 7000000, 7992189},
+		{5000, 0, params.MinGasLimit},
+		{5000, 10000, 5003},
+	}
+	for i, tt := range tests {
+		if have := ClampGasLimit(tt.parent, tt.limit); have != tt.want {
+			t.Errorf("test %d: gas limit mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+}
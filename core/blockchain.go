@@ -23,6 +23,7 @@ import (
 	"io"
 	"math/big"
 	mrand "math/rand"
+	"reflect"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -51,6 +52,7 @@ var (
 	headBlockGauge     = metrics.NewRegisteredGauge("chain/head/block", nil)
 	headHeaderGauge    = metrics.NewRegisteredGauge("chain/head/header", nil)
 	headFastBlockGauge = metrics.NewRegisteredGauge("chain/head/receipt", nil)
+	headCoalescedMeter = metrics.NewRegisteredMeter("chain/head/coalesced", nil)
 
 	accountReadTimer   = metrics.NewRegisteredTimer("chain/account/reads", nil)
 	accountHashTimer   = metrics.NewRegisteredTimer("chain/account/hashes", nil)
@@ -114,6 +116,9 @@ const (
 	// - Version 8
 	//  The following incompatible database changes were added:
 	//    * New scheme for contract code in order to separate the codes and trie nodes
+	//
+	// Later versions ship a migration converting existing databases, registered
+	// in dbMigrations.
 	BlockChainVersion uint64 = 8
 )
 
@@ -128,7 +133,13 @@ type CacheConfig struct {
 	TrieDirtyDisabled   bool          // Whgdtuer to disable trie write caching and GC altoggdtuer (archive node)
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
+	SnapshotThrottle    time.Duration // Block import time above which snapshot generation slows down (0 = never)
+	SnapshotMaxDelay    time.Duration // Maximum pause between snapshot generation batches under import pressure
+	SnapshotIdle        time.Duration // Time without block imports after which snapshot diffs are persisted (0 = never)
+	SnapshotCompaction  int           // Aggressiveness of persisting snapshot diffs, higher means sooner
 	Preimages           bool          // Whgdtuer to store preimage of trie key to the disk
+	SideChainRetention  uint64        // Number of blocks below the head to keep side chain blocks for (0 = forever)
+	CodeCacheLimit      int           // Memory allowance (MB) for the contract code cache shared between EVMs (0 = disabled)
 
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 }
@@ -158,8 +169,8 @@ var defaultCacheConfig = &CacheConfig{
 // included in the canonical one where as GetBlockByNumber always represents the
 // canonical chain.
 type BlockChain struct {
-	chainConfig *params.ChainConfig // Chain & network configuration
-	cacheConfig *CacheConfig        // Cache configuration for pruning
+	chainConfig atomic.Value // Chain & network configuration (*params.ChainConfig), replaced on updates
+	cacheConfig *CacheConfig // Cache configuration for pruning
 
 	db     gdtudb.Database // Low level persistent database to store final content in
 	snaps  *snapshot.Tree  // Snapshot tree for fast trie leaf access
@@ -180,6 +191,7 @@ type BlockChain struct {
 	chainHeadFeed event.Feed
 	logsFeed      event.Feed
 	blockProcFeed event.Feed
+	configFeed    event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 
@@ -227,7 +239,6 @@ func NewBlockChain(db gdtudb.Database, cacheConfig *CacheConfig, chainConfig *pa
 	futureBlocks, _ := lru.New(maxFutureBlocks)
 
 	bc := &BlockChain{
-		chainConfig: chainConfig,
 		cacheConfig: cacheConfig,
 		db:          db,
 		triegc:      prque.New(nil),
@@ -247,9 +258,13 @@ func NewBlockChain(db gdtudb.Database, cacheConfig *CacheConfig, chainConfig *pa
 		engine:         engine,
 		vmConfig:       vmConfig,
 	}
-	bc.validator = NewBlockValidator(chainConfig, bc, engine)
-	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
-	bc.processor = NewStateProcessor(chainConfig, bc, engine)
+	if cacheConfig.CodeCacheLimit > 0 && bc.vmConfig.CodeCache == nil {
+		bc.vmConfig.CodeCache = vm.NewCodeCache(cacheConfig.CodeCacheLimit * 1024 * 1024)
+	}
+	bc.chainConfig.Store(chainConfig)
+	bc.validator = NewBlockValidator(bc, engine)
+	bc.prefetcher = newStatePrefetcher(bc, engine)
+	bc.processor = NewStateProcessor(bc, engine)
 
 	var err error
 	bc.hc, err = NewHeaderChain(db, chainConfig, engine, bc.insertStopped)
@@ -372,7 +387,15 @@ func NewBlockChain(db gdtudb.Database, cacheConfig *CacheConfig, chainConfig *pa
 			log.Warn("Enabling snapshot recovery", "chainhead", head.NumberU64(), "diskbase", *layer)
 			recover = true
 		}
-		bc.snaps, _ = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.cacheConfig.SnapshotLimit, head.Root(), !bc.cacheConfig.SnapshotWait, true, recover)
+		var throttle *snapshot.Throttle
+		if bc.cacheConfig.SnapshotThrottle > 0 {
+			throttle = snapshot.NewThrottle(bc.cacheConfig.SnapshotThrottle, bc.cacheConfig.SnapshotMaxDelay)
+		}
+		var scheduler *snapshot.Scheduler
+		if bc.cacheConfig.SnapshotIdle > 0 {
+			scheduler = snapshot.NewScheduler(bc.cacheConfig.SnapshotIdle, bc.cacheConfig.SnapshotCompaction)
+		}
+		bc.snaps, _ = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.cacheConfig.SnapshotLimit, head.Root(), !bc.cacheConfig.SnapshotWait, true, recover, throttle, scheduler)
 	}
 	// Take ownership of this particular state
 	go bc.update()
@@ -382,6 +405,15 @@ func NewBlockChain(db gdtudb.Database, cacheConfig *CacheConfig, chainConfig *pa
 		bc.wg.Add(1)
 		go bc.maintainTxIndex(txIndexBlock)
 	}
+	// If side chain pruning is required, spin it up.
+	if bc.cacheConfig.SideChainRetention > 0 {
+		if bc.cacheConfig.SideChainRetention < TriesInMemory {
+			log.Warn("Sanitizing invalid side chain retention", "provided", bc.cacheConfig.SideChainRetention, "updated", TriesInMemory)
+			bc.cacheConfig.SideChainRetention = TriesInMemory
+		}
+		bc.wg.Add(1)
+		go bc.maintainSideChains()
+	}
 	// If periodic cache journal is required, spin it up.
 	if bc.cacheConfig.TrieCleanRejournal > 0 {
 		if bc.cacheConfig.TrieCleanRejournal < time.Minute {
@@ -476,9 +508,26 @@ func (bc *BlockChain) loadLastState() error {
 // SetHead rewinds the local chain to a new head. Depending on whether the node
 // was fast synced or full synced and in which state, the Method will try to
 // delete minimal data from disk whilst retaining chain consistency.
+//
+// If the head block was rewound and the state snapshot doesn't cover the new one,
+// the snapshot is regenerated in the background. A head event is fired for the
+// new head block, so that the subscribers (e.g. the transaction pool) drop the
+// state of the discarded blocks.
 func (bc *BlockChain) SetHead(head uint64) error {
-	_, err := bc.SetHeadBeyondRoot(head, common.Hash{})
-	return err
+	prev := bc.CurrentBlock()
+	if _, err := bc.SetHeadBeyondRoot(head, common.Hash{}); err != nil {
+		return err
+	}
+	current := bc.CurrentBlock()
+	if current.Hash() == prev.Hash() {
+		return nil
+	}
+	if bc.snaps != nil && bc.snaps.Snapshot(current.Root()) == nil {
+		log.Warn("Rebuilding state snapshot after rewind", "number", current.NumberU64(), "root", current.Root())
+		bc.snaps.Rebuild(current.Root())
+	}
+	bc.chainHeadFeed.Send(ChainHeadEvent{Block: current})
+	return nil
 }
 
 // SetHeadBeyondRoot rewinds the local chain to a new head with the extra condition
@@ -924,7 +973,7 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	if number == nil {
 		return nil
 	}
-	receipts := rawdb.ReadReceipts(bc.db, hash, *number, bc.chainConfig)
+	receipts := rawdb.ReadReceipts(bc.db, hash, *number, bc.Config())
 	if receipts == nil {
 		return nil
 	}
@@ -932,6 +981,24 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	return receipts
 }
 
+// GetReceiptsRLP retrieves the receipts of all transactions in a given block in
+// the consensus encoding, without deriving their metadata fields.
+func (bc *BlockChain) GetReceiptsRLP(hash common.Hash) rlp.RawValue {
+	if receipts, ok := bc.receiptsCache.Get(hash); ok {
+		encoded, err := rlp.EncodeToBytes(receipts.(types.Receipts))
+		if err != nil {
+			log.Error("Failed to encode receipts", "hash", hash, "err", err)
+			return nil
+		}
+		return encoded
+	}
+	number := rawdb.ReadHeaderNumber(bc.db, hash)
+	if number == nil {
+		return nil
+	}
+	return rawdb.ReadNetworkReceiptsRLP(bc.db, hash, *number)
+}
+
 // GetBlocksFromHash returns the block corresponding to hash and up to n-1 ancestors.
 // [deprecated by gdtu/62]
 func (bc *BlockChain) GetBlocksFromHash(hash common.Hash, n int) (blocks []*types.Block) {
@@ -1230,7 +1297,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 				}
 				h := rawdb.ReadCanonicalHash(bc.db, frozen)
 				b := rawdb.ReadBlock(bc.db, h, frozen)
-				size += rawdb.WriteAncientBlock(bc.db, b, rawdb.ReadReceipts(bc.db, h, frozen, bc.chainConfig), rawdb.ReadTd(bc.db, h, frozen))
+				size += rawdb.WriteAncientBlock(bc.db, b, rawdb.ReadReceipts(bc.db, h, frozen, bc.Config()), rawdb.ReadTd(bc.db, h, frozen))
 				count += 1
 
 				// Always keep genesis block in active database.
@@ -1527,7 +1594,7 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 		log.Crit("Failed to write block into disk", "err", err)
 	}
 	// Commit all cached state changes into underlying memory database.
-	root, err := state.Commit(bc.chainConfig.IsEIP158(block.Number()))
+	root, err := state.Commit(bc.Config().IsEIP158(block.Number()))
 	if err != nil {
 		return NonStatTy, err
 	}
@@ -1706,8 +1773,8 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 	if atomic.LoadInt32(&bc.procInterrupt) == 1 {
 		return 0, nil
 	}
-	// Start a parallel signature recovery (signer will fluke on fork transition, minimal perf loss)
-	senderCacher.recoverFromBlocks(types.MakeSigner(bc.chainConfig, chain[0].Number()), chain)
+	// Start a parallel signature recovery
+	senderCacher.recoverFromBlocks(bc.Config(), chain)
 
 	var (
 		stats     = insertStats{startTime: mclock.Now()}
@@ -1716,7 +1783,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 	// Fire a single chain head event if we've progressed the chain
 	defer func() {
 		if lastCanon != nil && bc.CurrentBlock().Hash() == lastCanon.Hash() {
-			bc.chainHeadFeed.Send(ChainHeadEvent{lastCanon})
+			bc.chainHeadFeed.Send(ChainHeadEvent{Block: lastCanon})
 		}
 	}()
 	// Start the parallel header verifier
@@ -1834,7 +1901,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		// its header and body was already in the database).
 		if err == ErrKnownBlock {
 			logger := log.Debug
-			if bc.chainConfig.Clique == nil {
+			if bc.Config().Clique == nil {
 				logger = log.Warn
 			}
 			logger("Inserted known block", "number", block.Number(), "hash", block.Hash(),
@@ -1948,6 +2015,10 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		blockWriteTimer.Update(time.Since(substart) - statedb.AccountCommits - statedb.StorageCommits - statedb.SnapshotCommits)
 		blockInsertTimer.UpdateSince(start)
 
+		// Let the snapshot generator know how loaded the import is
+		if bc.snaps != nil {
+			bc.snaps.ReportImport(time.Since(start))
+		}
 		switch status {
 		case CanonStatTy:
 			log.Debug("Inserted new block", "number", block.Number(), "hash", block.Hash(),
@@ -2145,7 +2216,7 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			if number == nil {
 				return
 			}
-			receipts := rawdb.ReadReceipts(bc.db, hash, *number, bc.chainConfig)
+			receipts := rawdb.ReadReceipts(bc.db, hash, *number, bc.Config())
 
 			var logs []*types.Log
 			for _, receipt := range receipts {
@@ -2297,12 +2368,27 @@ func (bc *BlockChain) update() {
 		select {
 		case <-futureTimer.C:
 			bc.procFutureBlocks()
+			bc.compactSnapshots()
 		case <-bc.quit:
 			return
 		}
 	}
 }
 
+// compactSnapshots persists the accumulated snapshot diffs while the chain is
+// idle, holding the insertion lock to keep the snapshot tree from changing.
+func (bc *BlockChain) compactSnapshots() {
+	if bc.snaps == nil {
+		return
+	}
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	if err := bc.snaps.Compact(bc.CurrentBlock().Root(), TriesInMemory); err != nil {
+		log.Debug("Failed to compact snapshot diffs", "err", err)
+	}
+}
+
 // maintainTxIndex is responsible for the construction and deletion of the
 // transaction index.
 //
@@ -2409,7 +2495,7 @@ Hash: gd%x
 
 Error: %v
 ##############################
-`, bc.chainConfig, block.Number(), block.Hash(), receiptString, err))
+`, bc.Config(), block.Number(), block.Hash(), receiptString, err))
 }
 
 // InsertHeaderChain attempts to insert the given header chain in to the local
@@ -2515,7 +2601,52 @@ func (bc *BlockChain) GetTransactionLookup(hash common.Hash) *rawdb.LegacyTxLook
 }
 
 // Config retrieves the chain's fork configuration.
-func (bc *BlockChain) Config() *params.ChainConfig { return bc.chainConfig }
+func (bc *BlockChain) Config() *params.ChainConfig {
+	return bc.chainConfig.Load().(*params.ChainConfig)
+}
+
+// UpdateChainConfig replaces the chain's fork configuration at runtime, allowing
+// future forks to be scheduled or rescheduled without restarting the node. The new
+// configuration is rejected if it changes the chain ID, the consensus engine or any
+// fork the chain has already passed.
+//
+// Note, the previous configuration is never modified, only swapped out. Readers
+// holding on to it keep a consistent view, the update takes effect with the next
+// block they process.
+func (bc *BlockChain) UpdateChainConfig(config *params.ChainConfig) error {
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	current := bc.Config()
+	if config.ChainID == nil || config.ChainID.Cmp(current.ChainID) != 0 {
+		return fmt.Errorf("mismatching chain ID: have %v, want %v", config.ChainID, current.ChainID)
+	}
+	if !reflect.DeepEqual(config.Gdtuash, current.Gdtuash) || !reflect.DeepEqual(config.Clique, current.Clique) {
+		return errors.New("consensus engine configuration can't be changed")
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		return err
+	}
+	// Headers may be imported ahead of the blocks, check against the highest one
+	head := bc.CurrentHeader().Number.Uint64()
+	if compat := current.CheckCompatible(config, head); compat != nil {
+		return compat
+	}
+	if reflect.DeepEqual(config, current) {
+		return nil
+	}
+	// Keep a private copy, so the caller can't modify the live configuration
+	cpy := *config
+	config = &cpy
+
+	rawdb.WriteChainConfig(bc.db, bc.genesisBlock.Hash(), config)
+	bc.chainConfig.Store(config)
+	bc.hc.setConfig(config)
+
+	log.Info("Updated chain configuration", "head", head, "config", config)
+	bc.configFeed.Send(ChainConfigEvent{Config: config})
+	return nil
+}
 
 // Engine retrieves the blockchain's consensus engine.
 func (bc *BlockChain) Engine() consensus.Engine { return bc.engine }
@@ -2525,6 +2656,11 @@ func (bc *BlockChain) SubscribeRemovedLogsEvent(ch chan<- RemovedLogsEvent) even
 	return bc.scope.Track(bc.rmLogsFeed.Subscribe(ch))
 }
 
+// SubscribeChainConfigEvent registers a subscription of ChainConfigEvent.
+func (bc *BlockChain) SubscribeChainConfigEvent(ch chan<- ChainConfigEvent) event.Subscription {
+	return bc.scope.Track(bc.configFeed.Subscribe(ch))
+}
+
 // SubscribeChainEvent registers a subscription of ChainEvent.
 func (bc *BlockChain) SubscribeChainEvent(ch chan<- ChainEvent) event.Subscription {
 	return bc.scope.Track(bc.chainFeed.Subscribe(ch))
@@ -2535,6 +2671,48 @@ func (bc *BlockChain) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Su
 	return bc.scope.Track(bc.chainHeadFeed.Subscribe(ch))
 }
 
+// SubscribeChainHeadEventCoalesced registers a subscription of ChainHeadEvent
+// which never blocks the chain on a slow subscriber. If new heads arrive before
+// the previous one is consumed, only the latest is delivered, with the number of
+// dropped events in its Skipped field.
+func (bc *BlockChain) SubscribeChainHeadEventCoalesced(ch chan<- ChainHeadEvent) event.Subscription {
+	return coalesceChainHeads(bc.SubscribeChainHeadEvent, ch)
+}
+
+// coalesceChainHeads subscribes to chain head events with the given subscriber,
+// forwarding them to ch. Events not yet consumed are replaced by newer ones.
+func coalesceChainHeads(subscribe func(chan<- ChainHeadEvent) event.Subscription, ch chan<- ChainHeadEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		heads := make(chan ChainHeadEvent)
+		sub := subscribe(heads)
+		defer sub.Unsubscribe()
+
+		var (
+			pending ChainHeadEvent
+			out     chan<- ChainHeadEvent // nil while there's nothing to deliver
+		)
+		for {
+			select {
+			case head := <-heads:
+				if out != nil {
+					head.Skipped += pending.Skipped + 1
+					headCoalescedMeter.Mark(1)
+				}
+				pending, out = head, ch
+
+			case out <- pending:
+				pending, out = ChainHeadEvent{}, nil
+
+			case err := <-sub.Err():
+				return err
+
+			case <-quit:
+				return nil
+			}
+		}
+	})
+}
+
 // SubscribeChainSideEvent registers a subscription of ChainSideEvent.
 func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Subscription {
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
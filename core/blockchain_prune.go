@@ -0,0 +1,169 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/mclock"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/metrics"
+)
+
+const (
+	// sideChainPruneInterval is the time between two rounds of side chain pruning.
+	sideChainPruneInterval = time.Minute
+
+	// sideChainPruneBatch is the number of block heights pruned while holding the
+	// chain lock, before yielding to block imports.
+	sideChainPruneBatch = 1024
+)
+
+var (
+	sideChainPrunedMeter    = metrics.NewRegisteredMeter("chain/sidechain/pruned", nil)
+	sideChainReclaimedMeter = metrics.NewRegisteredMeter("chain/sidechain/reclaimed", nil)
+)
+
+// maintainSideChains periodically deletes the side chain blocks older than the
+// configured retention depth, which would otherwise accumulate forever in the
+// key-value store of nodes running without the freezer.
+func (bc *BlockChain) maintainSideChains() {
+	defer bc.wg.Done()
+
+	ticker := time.NewTicker(sideChainPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		if !bc.pruneSideChains() {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
+// pruneSideChains deletes the side chain blocks below the retention depth not
+// pruned yet, in batches to not stall block imports. It returns false if the
+// chain was stopped meanwhile.
+func (bc *BlockChain) pruneSideChains() bool {
+	var (
+		start   = mclock.Now()
+		tail    = bc.sideChainPruneTail()
+		pruned  int
+		size    common.StorageSize
+		running = true
+	)
+	for running {
+		// Hold the chain lock, so no reorg can make any of the blocks canonical
+		// while they are being deleted
+		bc.chainmu.Lock()
+		head := bc.CurrentBlock().NumberU64()
+		if head < bc.cacheConfig.SideChainRetention || tail >= head-bc.cacheConfig.SideChainRetention {
+			bc.chainmu.Unlock()
+			break
+		}
+		limit := head - bc.cacheConfig.SideChainRetention
+		if limit > tail+sideChainPruneBatch {
+			limit = tail + sideChainPruneBatch
+		}
+		next, n, s := bc.pruneSideChainRange(tail, limit)
+		bc.chainmu.Unlock()
+
+		tail, pruned, size = next, pruned+n, size+s
+		if next < limit {
+			// A height without canonical block holds the tail back, retry later
+			break
+		}
+
+		select {
+		case <-bc.quit:
+			running = false
+		default:
+		}
+	}
+	if pruned > 0 {
+		log.Info("Pruned side chain blocks", "blocks", pruned, "size", size, "tail", tail, "elapsed", common.PrettyDuration(mclock.Now()-start))
+	}
+	return running
+}
+
+// sideChainPruneTail returns the first block whose side chains haven't been
+// pruned yet. If the pruner never ran, the side chains of the frozen blocks are
+// considered pruned, as they are deleted by the freezer.
+func (bc *BlockChain) sideChainPruneTail() uint64 {
+	if tail := rawdb.ReadSideChainPruneTail(bc.db); tail != nil {
+		return *tail
+	}
+	frozen, _ := bc.db.Ancients()
+	return frozen
+}
+
+// pruneSideChainRange deletes the non-canonical blocks in the [from, to) range
+// and advances the prune tail, returning the new tail, the number of blocks
+// deleted and the space reclaimed. The chain lock is assumed to be held.
+//
+// Pruning stops at the first height without a canonical block, so the tail is
+// never moved past blocks which were left alone.
+func (bc *BlockChain) pruneSideChainRange(from, to uint64) (uint64, int, common.StorageSize) {
+	var (
+		batch  = bc.db.NewBatch()
+		pruned int
+		size   common.StorageSize
+	)
+	number := from
+	for ; number < to; number++ {
+		canonical := rawdb.ReadCanonicalHash(bc.db, number)
+		if canonical == (common.Hash{}) {
+			// Stale side chains of a height without canonical block can't be
+			// told apart from a chain being synced, leave them alone
+			break
+		}
+		for _, hash := range rawdb.ReadAllHashes(bc.db, number) {
+			if hash == canonical {
+				continue
+			}
+			size += common.StorageSize(len(rawdb.ReadHeaderRLP(bc.db, hash, number)) + len(rawdb.ReadBodyRLP(bc.db, hash, number)) + len(rawdb.ReadReceiptsRLP(bc.db, hash, number)))
+			rawdb.DeleteBlock(batch, hash, number)
+			bc.forgetBlock(hash)
+			pruned++
+		}
+	}
+	rawdb.WriteSideChainPruneTail(batch, number)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete side chain blocks", "err", err)
+	}
+	sideChainPrunedMeter.Mark(int64(pruned))
+	sideChainReclaimedMeter.Mark(int64(size))
+	return number, pruned, size
+}
+
+// forgetBlock drops a deleted block from the in-memory caches.
+func (bc *BlockChain) forgetBlock(hash common.Hash) {
+	bc.blockCache.Remove(hash)
+	bc.bodyCache.Remove(hash)
+	bc.bodyRLPCache.Remove(hash)
+	bc.receiptsCache.Remove(hash)
+
+	bc.hc.headerCache.Remove(hash)
+	bc.hc.tdCache.Remove(hash)
+	bc.hc.numberCache.Remove(hash)
+}
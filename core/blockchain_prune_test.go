@@ -0,0 +1,140 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/consensus/gdtuash"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// Tests that side chain blocks below the retention depth are pruned, while the
+// canonical chain and the recent side chains are retained.
+func TestSideChainPruning(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+	blockchain, _ := NewBlockChain(db, &CacheConfig{
+		TrieCleanLimit:     256,
+		TrieDirtyLimit:     256,
+		TrieTimeLimit:      defaultCacheConfig.TrieTimeLimit,
+		SideChainRetention: TriesInMemory,
+	}, gspec.Config, gdtuash.NewFaker(), vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+
+	chain, _ := GenerateChain(gspec.Config, genesis, gdtuash.NewFaker(), db, 300, func(i int, gen *BlockGen) {})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Import a side chain forking below and another above the retention depth
+	var sides []*types.Block
+	for _, fork := range []int{49, 249} {
+		side, _ := GenerateChain(gspec.Config, chain[fork], gdtuash.NewFaker(), db, 5, func(i int, gen *BlockGen) {
+			gen.SetCoinbase(common.Address{0xde, 0xad})
+		})
+		if _, err := blockchain.InsertChain(side); err != nil {
+			t.Fatalf("failed to insert side chain: %v", err)
+		}
+		sides = append(sides, side...)
+	}
+	if !blockchain.pruneSideChains() {
+		t.Fatalf("pruning interrupted")
+	}
+	if tail := rawdb.ReadSideChainPruneTail(db); tail == nil || *tail != 300-TriesInMemory {
+		t.Fatalf("wrong prune tail: have %v, want %d", tail, 300-TriesInMemory)
+	}
+	for i, block := range sides {
+		pruned := i < 5
+		if have := rawdb.HasHeader(db, block.Hash(), block.NumberU64()); have == pruned {
+			t.Errorf("side block #%d: header present %v, want %v", block.NumberU64(), have, !pruned)
+		}
+		if have := blockchain.GetBlockByHash(block.Hash()) != nil; have == pruned {
+			t.Errorf("side block #%d: block retrievable %v, want %v", block.NumberU64(), have, !pruned)
+		}
+	}
+	for _, block := range chain {
+		if blockchain.GetBlockByHash(block.Hash()) == nil {
+			t.Fatalf("canonical block #%d pruned", block.NumberU64())
+		}
+	}
+}
+
+// Tests that the prune tail is not advanced past a height without canonical block,
+// so its side chains are pruned once the height becomes canonical again.
+func TestSideChainPruningGap(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+	// Enable the retention only after creating the chain, so the background
+	// pruner doesn't race with the manual pruning rounds
+	blockchain, _ := NewBlockChain(db, &CacheConfig{
+		TrieCleanLimit: 256,
+		TrieDirtyLimit: 256,
+		TrieTimeLimit:  defaultCacheConfig.TrieTimeLimit,
+	}, gspec.Config, gdtuash.NewFaker(), vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+	blockchain.cacheConfig.SideChainRetention = TriesInMemory
+
+	chain, _ := GenerateChain(gspec.Config, genesis, gdtuash.NewFaker(), db, 300, func(i int, gen *BlockGen) {})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	side, _ := GenerateChain(gspec.Config, chain[48], gdtuash.NewFaker(), db, 5, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{0xde, 0xad})
+	})
+	if _, err := blockchain.InsertChain(side); err != nil {
+		t.Fatalf("failed to insert side chain: %v", err)
+	}
+	// Drop the canonical mapping in the middle of the side chain and prune
+	rawdb.DeleteCanonicalHash(db, 52)
+
+	if !blockchain.pruneSideChains() {
+		t.Fatalf("pruning interrupted")
+	}
+	if tail := rawdb.ReadSideChainPruneTail(db); tail == nil || *tail != 52 {
+		t.Fatalf("wrong prune tail: have %v, want %d", tail, 52)
+	}
+	for _, block := range side {
+		pruned := block.NumberU64() < 52
+		if have := rawdb.HasHeader(db, block.Hash(), block.NumberU64()); have == pruned {
+			t.Errorf("side block #%d: header present %v, want %v", block.NumberU64(), have, !pruned)
+		}
+	}
+	// Restore the canonical mapping and ensure the remaining side blocks are pruned
+	rawdb.WriteCanonicalHash(db, chain[51].Hash(), 52)
+
+	if !blockchain.pruneSideChains() {
+		t.Fatalf("pruning interrupted")
+	}
+	if tail := rawdb.ReadSideChainPruneTail(db); tail == nil || *tail != 300-TriesInMemory {
+		t.Fatalf("wrong prune tail: have %v, want %d", tail, 300-TriesInMemory)
+	}
+	for _, block := range side {
+		if rawdb.HasHeader(db, block.Hash(), block.NumberU64()) {
+			t.Errorf("side block #%d: not pruned", block.NumberU64())
+		}
+	}
+}
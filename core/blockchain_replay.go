@@ -0,0 +1,216 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/mclock"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/state"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/trie"
+)
+
+// InsertChainTrusted imports a batch of blocks originating from a trusted source
+// (e.g. an exported chain being reprocessed offline). Every transaction is fully
+// executed and the receipts are verified, but the state trie is only hashed and
+// its root verified on every interval-th block and on the last block of the
+// batch. The blocks in between are stored without state.
+//
+// Pre-Byzantium receipts embed intermediate state roots, so such blocks are
+// always fully verified. If the batch does not directly extend the current head
+// or the interval is less than two, a regular InsertChain is done instead.
+func (bc *BlockChain) InsertChainTrusted(chain types.Blocks, interval uint64) (int, error) {
+	// Sanity check that we have somgdtuing meaningful to import
+	if len(chain) == 0 {
+		return 0, nil
+	}
+	if interval < 2 || chain[0].ParentHash() != bc.CurrentBlock().Hash() {
+		return bc.InsertChain(chain)
+	}
+	// Do a sanity check that the provided chain is actually ordered and linked
+	for i := 1; i < len(chain); i++ {
+		block, prev := chain[i], chain[i-1]
+		if block.NumberU64() != prev.NumberU64()+1 || block.ParentHash() != prev.Hash() {
+			return 0, fmt.Errorf("non contiguous insert: item %d is #%d [%x…], item %d is #%d [%x…] (parent [%x…])", i-1, prev.NumberU64(),
+				prev.Hash().Bytes()[:4], i, block.NumberU64(), block.Hash().Bytes()[:4], block.ParentHash().Bytes()[:4])
+		}
+	}
+	bc.blockProcFeed.Send(true)
+	defer bc.blockProcFeed.Send(false)
+
+	bc.wg.Add(1)
+	bc.chainmu.Lock()
+	n, err := bc.insertChainTrusted(chain, interval)
+	bc.chainmu.Unlock()
+	bc.wg.Done()
+
+	// If the import failed between two checkpoints, the head block is stateless.
+	// Rewind it to the last verified one, retaining the block data.
+	if head := bc.CurrentBlock(); err != nil && !bc.HasState(head.Root()) {
+		log.Warn("Rewinding unverified trusted import", "number", head.Number(), "hash", head.Hash())
+		if err := bc.SetHead(head.NumberU64()); err != nil {
+			log.Error("Failed to rewind trusted import", "err", err)
+		}
+	}
+	return n, err
+}
+
+// insertChainTrusted is the internal implementation of InsertChainTrusted, which
+// assumes that 1) chains are contiguous and extend the current head, and 2) The
+// chain mutex is held.
+func (bc *BlockChain) insertChainTrusted(chain types.Blocks, interval uint64) (int, error) {
+	// If the chain is terminating, don't even bother starting up
+	if bc.insertStopped() {
+		return 0, nil
+	}
+	// Start a parallel signature recovery
+	senderCacher.recoverFromBlocks(bc.Config(), chain)
+
+	var (
+		stats     = insertStats{startTime: mclock.Now()}
+		lastCanon *types.Block
+	)
+	// Fire a single chain head event if we've progressed the chain
+	defer func() {
+		if lastCanon != nil && bc.CurrentBlock().Hash() == lastCanon.Hash() {
+			bc.chainHeadFeed.Send(ChainHeadEvent{Block: lastCanon})
+		}
+	}()
+	// Start the parallel header verifier
+	headers := make([]*types.Header, len(chain))
+	seals := make([]bool, len(chain))
+
+	for i, block := range chain {
+		headers[i] = block.Header()
+		seals[i] = true
+	}
+	abort, results := bc.engine.VerifyHeaders(bc, headers, seals)
+	defer close(abort)
+
+	statedb, err := state.New(bc.CurrentBlock().Root(), bc.stateCache, bc.snaps)
+	if err != nil {
+		return 0, err
+	}
+	for i, block := range chain {
+		// If the chain is terminating, stop processing blocks
+		if bc.insertStopped() {
+			log.Debug("Abort during trusted block processing")
+			return i, nil
+		}
+		// If the header is a banned one, straight out abort
+		if BadHashes[block.Hash()] {
+			bc.reportBlock(block, nil, ErrBlacklistedHash)
+			return i, ErrBlacklistedHash
+		}
+		// Validate the header and the body, the state is not needed for it
+		if err := <-results; err != nil {
+			bc.reportBlock(block, nil, err)
+			return i, err
+		}
+		if err := bc.engine.VerifyUncles(bc, block); err != nil {
+			bc.reportBlock(block, nil, err)
+			return i, err
+		}
+		if hash := types.CalcUncleHash(block.Uncles()); hash != block.UncleHash() {
+			err := fmt.Errorf("uncle root hash mismatch: have %x, want %x", hash, block.UncleHash())
+			bc.reportBlock(block, nil, err)
+			return i, err
+		}
+		if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != block.TxHash() {
+			err := fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, block.TxHash())
+			bc.reportBlock(block, nil, err)
+			return i, err
+		}
+		// Process the block, deferring the trie hashing if it's not a checkpoint
+		start := time.Now()
+
+		checkpoint := i == len(chain)-1 || block.NumberU64()%interval == 0 || !bc.Config().IsByzantium(block.Number())
+		statedb.SetDeferredHashing(!checkpoint)
+		statedb.ResetLogs() // The state is reused across blocks, logs are not
+
+		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+		if err != nil {
+			bc.reportBlock(block, receipts, err)
+			return i, err
+		}
+		if checkpoint {
+			// Checkpoint reached, verify and commit the accumulated state
+			if err := bc.validator.ValidateState(block, statedb, receipts, usedGas); err != nil {
+				bc.reportBlock(block, receipts, err)
+				return i, err
+			}
+			status, err := bc.writeBlockWithState(block, receipts, logs, statedb, false)
+			if err != nil {
+				return i, err
+			}
+			if status != CanonStatTy {
+				return i, fmt.Errorf("trusted block #%d [%x…] not canonical", block.NumberU64(), block.Hash().Bytes()[:4])
+			}
+			bc.gcproc += time.Since(start)
+
+			if statedb, err = state.New(block.Root(), bc.stateCache, bc.snaps); err != nil {
+				return i, err
+			}
+		} else {
+			// Intermediate block, verify the receipts and store it without state
+			if err := validateReceipts(block, receipts, usedGas); err != nil {
+				bc.reportBlock(block, receipts, err)
+				return i, err
+			}
+			bc.writeBlockWithoutStateTrusted(block, receipts, logs)
+		}
+		log.Debug("Inserted trusted block", "number", block.Number(), "hash", block.Hash(),
+			"txs", len(block.Transactions()), "gas", block.GasUsed(), "verified", checkpoint,
+			"elapsed", common.PrettyDuration(time.Since(start)))
+
+		lastCanon = block
+		stats.processed++
+		stats.usedGas += usedGas
+
+		dirty, _ := bc.stateCache.TrieDB().Size()
+		stats.report(chain, i, dirty)
+	}
+	return len(chain), nil
+}
+
+// writeBlockWithoutStateTrusted writes a block and its receipts into the database
+// and sets it as the new head, without it having any associated state. It is
+// only meant to be used during trusted imports, where the state of some later
+// block will be verified and committed.
+func (bc *BlockChain) writeBlockWithoutStateTrusted(block *types.Block, receipts []*types.Receipt, logs []*types.Log) {
+	td := new(big.Int).Add(block.Difficulty(), bc.GetTd(block.ParentHash(), block.NumberU64()-1))
+
+	batch := bc.db.NewBatch()
+	rawdb.WriteTd(batch, block.Hash(), block.NumberU64(), td)
+	rawdb.WriteBlock(batch, block)
+	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write block into disk", "err", err)
+	}
+	bc.writeHeadBlock(block)
+
+	bc.chainFeed.Send(ChainEvent{Block: block, Hash: block.Hash(), Logs: logs})
+	if len(logs) > 0 {
+		bc.logsFeed.Send(logs)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/consensus/gdtuash"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// newTrustedReplayChain generates a chain of value transfers to replay, along
+// with a fresh blockchain to import it into.
+func newTrustedReplayChain(t *testing.T, n int) ([]*types.Block, *BlockChain) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(params.Gdtur)}},
+		}
+		engine  = gdtuash.NewFaker()
+		signer  = types.LatestSigner(gspec.Config)
+		gendb   = rawdb.NewMemoryDatabase()
+		genesis = gspec.MustCommit(gendb)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, gendb, n, func(i int, b *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{byte(i)}, big.NewInt(1000), params.TxGas, big.NewInt(1), nil), signer, key)
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		b.AddTx(tx)
+	})
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+
+	chain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	return blocks, chain
+}
+
+// Tests that a trusted replay only commits state on checkpoints but ends up with
+// the same chain as a full import.
+func TestInsertChainTrusted(t *testing.T) {
+	blocks, chain := newTrustedReplayChain(t, 10)
+	defer chain.Stop()
+
+	if n, err := chain.InsertChainTrusted(blocks, 4); err != nil {
+		t.Fatalf("failed to import block %d: %v", n, err)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[len(blocks)-1].Hash() {
+		t.Fatalf("head mismatch: have #%d [%x], want #%d", head.NumberU64(), head.Hash(), blocks[len(blocks)-1].NumberU64())
+	}
+	for _, block := range blocks {
+		number := block.NumberU64()
+		if hash := rawdb.ReadCanonicalHash(chain.db, number); hash != block.Hash() {
+			t.Errorf("block #%d: canonical hash mismatch: have %x, want %x", number, hash, block.Hash())
+		}
+		if receipts := chain.GetReceiptsByHash(block.Hash()); len(receipts) != 1 {
+			t.Errorf("block #%d: receipt count mismatch: have %d, want 1", number, len(receipts))
+		}
+		checkpoint := number%4 == 0 || number == uint64(len(blocks))
+		if have := chain.HasState(block.Root()); have != checkpoint {
+			t.Errorf("block #%d: state availability mismatch: have %v, want %v", number, have, checkpoint)
+		}
+	}
+}
+
+// Tests that the logs of the blocks imported without state between checkpoints
+// are indexed from the start of their own block.
+func TestInsertChainTrustedLogs(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		emitter = common.HexToAddress("0xc0de")
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address: {Balance: big.NewInt(params.Gdtur)},
+				// PUSH1 0, PUSH1 0, LOG0, STOP
+				emitter: {Balance: common.Big0, Code: []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG0), byte(vm.STOP)}},
+			},
+		}
+		engine  = gdtuash.NewFaker()
+		signer  = types.LatestSigner(gspec.Config)
+		gendb   = rawdb.NewMemoryDatabase()
+		genesis = gspec.MustCommit(gendb)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, gendb, 6, func(i int, b *BlockGen) {
+		for j := 0; j < 2; j++ {
+			tx, err := types.SignTx(types.NewTransaction(b.TxNonce(address), emitter, common.Big0, 100000, big.NewInt(1), nil), signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			b.AddTx(tx)
+		}
+	})
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+
+	chain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	events := make(chan ChainEvent, len(blocks))
+	sub := chain.SubscribeChainEvent(events)
+	defer sub.Unsubscribe()
+
+	if n, err := chain.InsertChainTrusted(blocks, 4); err != nil {
+		t.Fatalf("failed to import block %d: %v", n, err)
+	}
+	for i := range blocks {
+		ev := <-events
+		if len(ev.Logs) != 2 {
+			t.Fatalf("block #%d: log count mismatch: have %d, want 2", ev.Block.NumberU64(), len(ev.Logs))
+		}
+		for j, log := range ev.Logs {
+			if log.Index != uint(j) {
+				t.Errorf("block #%d (event %d): log %d index mismatch: have %d, want %d", ev.Block.NumberU64(), i, j, log.Index, j)
+			}
+			if log.BlockHash != ev.Hash {
+				t.Errorf("block #%d: log %d block hash mismatch: have %x, want %x", ev.Block.NumberU64(), j, log.BlockHash, ev.Hash)
+			}
+		}
+	}
+}
+
+// Tests that a trusted replay detects a bad state root at the end of the batch
+// and rewinds the head to the last verified checkpoint.
+func TestInsertChainTrustedBadRoot(t *testing.T) {
+	blocks, chain := newTrustedReplayChain(t, 10)
+	defer chain.Stop()
+
+	header := blocks[len(blocks)-1].Header()
+	header.Root = common.Hash{0x01}
+	blocks[len(blocks)-1] = blocks[len(blocks)-1].WithSeal(header)
+
+	if _, err := chain.InsertChainTrusted(blocks, 4); err == nil {
+		t.Fatalf("bad state root accepted")
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[7].Hash() {
+		t.Fatalf("head mismatch: have #%d [%x], want #%d [%x]", head.NumberU64(), head.Hash(), blocks[7].NumberU64(), blocks[7].Hash())
+	}
+}
@@ -2167,3 +2167,67 @@ func verifyCutoff(t *testing.T, chain *BlockChain, canonical bool, inserted type
 func uint64ptr(n uint64) *uint64 {
 	return &n
 }
+
+// Tests that rewinding the chain regenerates the state snapshot if it doesn't
+// cover the new head, and notifies the head event subscribers.
+func TestSetHeadSnapshotAndEvent(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		genesis = new(Genesis).MustCommit(db)
+		engine  = gdtuash.NewFullFaker()
+		config  = &CacheConfig{
+			TrieCleanLimit:    256,
+			TrieDirtyLimit:    256,
+			TrieTimeLimit:     5 * time.Minute,
+			TrieDirtyDisabled: true,
+			SnapshotLimit:     256,
+			SnapshotWait:      true,
+		}
+	)
+	chain, err := NewBlockChain(db, config, params.AllGdtuashProtocolChanges, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, engine, rawdb.NewMemoryDatabase(), 10, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x02})
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("Failed to import chain: %v", err)
+	}
+	// Flatten the snapshot into the head, dropping the layers of the ancestors
+	if err := chain.snaps.Cap(blocks[9].Root(), 0); err != nil {
+		t.Fatalf("Failed to flatten snapshots: %v", err)
+	}
+	if chain.snaps.Snapshot(blocks[4].Root()) != nil {
+		t.Fatalf("Snapshot of rewind target available before rewind")
+	}
+	heads := make(chan ChainHeadEvent, 1)
+	sub := chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	if err := chain.SetHead(5); err != nil {
+		t.Fatalf("Failed to rewind chain: %v", err)
+	}
+	select {
+	case ev := <-heads:
+		if ev.Block.Hash() != blocks[4].Hash() {
+			t.Errorf("Head event mismatch: have #%d, want #%d", ev.Block.NumberU64(), blocks[4].NumberU64())
+		}
+	default:
+		t.Errorf("No head event fired for rewind")
+	}
+	if chain.snaps.Snapshot(blocks[4].Root()) == nil {
+		t.Errorf("Snapshot not regenerated for new head")
+	}
+	// Setting the head to the current one must not fire events
+	if err := chain.SetHead(5); err != nil {
+		t.Fatalf("Failed to repair chain: %v", err)
+	}
+	select {
+	case ev := <-heads:
+		t.Errorf("Unexpected head event for #%d", ev.Block.NumberU64())
+	default:
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/core/vm"
 	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/event"
 	"github.com/c88032111/go-gdtu/gdtudb"
 	"github.com/c88032111/go-gdtu/params"
 	"github.com/c88032111/go-gdtu/trie"
@@ -511,7 +512,7 @@ func testReorgBadHashes(t *testing.T, full bool) {
 	blockchain.Stop()
 
 	// Create a new BlockChain and check that it rolled back the state.
-	ncm, err := NewBlockChain(blockchain.db, nil, blockchain.chainConfig, gdtuash.NewFaker(), vm.Config{}, nil, nil)
+	ncm, err := NewBlockChain(blockchain.db, nil, blockchain.Config(), gdtuash.NewFaker(), vm.Config{}, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to create new chain manager: %v", err)
 	}
@@ -1216,7 +1217,7 @@ func TestCanonicalBlockRetrieval(t *testing.T) {
 	}
 	defer blockchain.Stop()
 
-	chain, _ := GenerateChain(blockchain.chainConfig, blockchain.genesisBlock, gdtuash.NewFaker(), blockchain.db, 10, func(i int, gen *BlockGen) {})
+	chain, _ := GenerateChain(blockchain.Config(), blockchain.genesisBlock, gdtuash.NewFaker(), blockchain.db, 10, func(i int, gen *BlockGen) {})
 
 	var pend sync.WaitGroup
 	pend.Add(len(chain))
@@ -3108,3 +3109,111 @@ func TestEIP2718Transition(t *testing.T) {
 
 	}
 }
+
+// Tests that coalescing head subscriptions deliver only the latest head to slow
+// subscribers, without blocking the feed.
+func TestChainHeadCoalescing(t *testing.T) {
+	var (
+		feed = new(event.Feed)
+		ch   = make(chan ChainHeadEvent)
+		sub  = coalesceChainHeads(func(ch chan<- ChainHeadEvent) event.Subscription { return feed.Subscribe(ch) }, ch)
+	)
+	head := func(n int64) ChainHeadEvent {
+		return ChainHeadEvent{Block: types.NewBlockWithHeader(&types.Header{Number: big.NewInt(n)})}
+	}
+	// Wait for the coalescer to subscribe, then flood it with heads
+	for feed.Send(head(1)) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := int64(2); i <= 5; i++ {
+		feed.Send(head(i))
+	}
+	if ev := <-ch; ev.Block.NumberU64() != 5 || ev.Skipped != 4 {
+		t.Fatalf("coalesced head mismatch: have #%d (%d skipped), want #5 (4 skipped)", ev.Block.NumberU64(), ev.Skipped)
+	}
+	// A subscriber keeping up shouldn't miss any heads
+	feed.Send(head(6))
+	if ev := <-ch; ev.Block.NumberU64() != 6 || ev.Skipped != 0 {
+		t.Fatalf("head mismatch: have #%d (%d skipped), want #6 (0 skipped)", ev.Block.NumberU64(), ev.Skipped)
+	}
+	// Unsubscribing should release the upstream subscription
+	sub.Unsubscribe()
+	for feed.Send(head(7)) != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Tests that the chain configuration can be updated at runtime to schedule future
+// forks, while changes to already passed forks are rejected.
+func TestUpdateChainConfig(t *testing.T) {
+	config := *params.AllGdtuashProtocolChanges
+	config.BerlinBlock = nil
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: &config}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := GenerateChain(&config, genesis, gdtuash.NewFaker(), db, 4, nil)
+
+	chain, err := NewBlockChain(db, nil, &config, gdtuash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	events := make(chan ChainConfigEvent, 1)
+	sub := chain.SubscribeChainConfigEvent(events)
+	defer sub.Unsubscribe()
+
+	// Scheduling a future fork should be accepted, persisted and announced
+	previous := chain.Config()
+
+	update := config
+	update.BerlinBlock = big.NewInt(10)
+	if err := chain.UpdateChainConfig(&update); err != nil {
+		t.Fatalf("failed to schedule fork: %v", err)
+	}
+	update.BerlinBlock = big.NewInt(20) // caller modifications must not leak into the chain
+
+	if previous.BerlinBlock != nil {
+		t.Errorf("previous config modified: berlin at %v", previous.BerlinBlock)
+	}
+	if chain.hc.Config() != chain.Config() {
+		t.Errorf("header chain config not updated")
+	}
+	if !chain.Config().IsBerlin(big.NewInt(10)) || chain.Config().IsBerlin(big.NewInt(9)) {
+		t.Errorf("fork not scheduled: berlin at %v", chain.Config().BerlinBlock)
+	}
+	if stored := rawdb.ReadChainConfig(db, genesis.Hash()); stored == nil || stored.BerlinBlock.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("updated config not persisted: %v", stored)
+	}
+	select {
+	case ev := <-events:
+		if ev.Config.BerlinBlock.Cmp(big.NewInt(10)) != 0 {
+			t.Errorf("config event mismatch: berlin at %v", ev.Config.BerlinBlock)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("no config event delivered")
+	}
+	// Changing an already passed fork must be rejected
+	update = *chain.Config()
+	update.MuirGlacierBlock = big.NewInt(2)
+	if err := chain.UpdateChainConfig(&update); err == nil {
+		t.Errorf("passed fork change accepted")
+	} else if _, ok := err.(*params.ConfigCompatError); !ok {
+		t.Errorf("passed fork change error mismatch: have %v, want *params.ConfigCompatError", err)
+	}
+	// Changing the chain ID must be rejected
+	update = *chain.Config()
+	update.ChainID = big.NewInt(1)
+	if err := chain.UpdateChainConfig(&update); err == nil {
+		t.Errorf("chain ID change accepted")
+	}
+	if chain.Config().MuirGlacierBlock.Sign() != 0 || chain.Config().ChainID.Cmp(big.NewInt(1337)) != 0 {
+		t.Errorf("rejected update applied: %v", chain.Config())
+	}
+}
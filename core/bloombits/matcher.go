@@ -58,8 +58,9 @@ type partialMatches struct {
 // bit with the given number of fetch elements, or a response for such a request.
 // It can also have the actual results set to be used as a delivery data struct.
 //
-// The contest and error fields are used by the light client to terminate matching
-// early if an error is encountered on some path of the pipeline.
+// The context and error fields are used by the retrievers to terminate matching
+// early if the caller goes away or an error is encountered on some path of the
+// pipeline.
 type Retrieval struct {
 	Bit      uint
 	Sections []uint64
@@ -153,15 +154,29 @@ func (m *Matcher) Start(ctx context.Context, begin, end uint64, results chan uin
 
 	// Initiate a new matching round
 	session := &MatcherSession{
-		matcher: m,
-		quit:    make(chan struct{}),
-		ctx:     ctx,
+		matcher:  m,
+		quit:     make(chan struct{}),
+		ctx:      ctx,
+		started:  time.Now(),
+		sections: end/m.sectionSize - begin/m.sectionSize + 1,
 	}
 	for _, scheduler := range m.schedulers {
 		scheduler.reset()
 	}
 	sink := m.run(begin, end, cap(results), session)
 
+	// Abort the session if the caller goes away or its deadline expires
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				session.setError(ctx.Err())
+				session.terminate()
+			case <-session.quit:
+			}
+		}()
+	}
+
 	// Read the output from the result sink and deliver to the user
 	session.pend.Add(1)
 	go func() {
@@ -205,9 +220,11 @@ func (m *Matcher) Start(ctx context.Context, begin, end uint64, results chan uin
 						case <-session.quit:
 							return
 						case results <- i:
+							atomic.AddUint64(&session.matches, 1)
 						}
 					}
 				}
+				atomic.AddUint64(&session.processed, 1)
 			}
 		}
 	}()
@@ -369,6 +386,9 @@ func (m *Matcher) subMatch(source chan *partialMatches, dist chan *request, bloo
 						return
 					case results <- &partialMatches{subres.section, orVector}:
 					}
+				} else {
+					// No potential matches left, the section is done with
+					atomic.AddUint64(&session.processed, 1)
 				}
 			}
 		}
@@ -478,6 +498,7 @@ func (m *Matcher) distributor(dist chan *request, session *MatcherSession) {
 				bitsets = append(bitsets, bitset)
 			}
 			m.schedulers[result.Bit].deliver(sections, bitsets)
+			atomic.AddUint64(&session.retrievals, uint64(len(sections)))
 			allocs--
 
 			// Reschedule missing sections and allocate bit if newly available
@@ -502,16 +523,32 @@ func (m *Matcher) distributor(dist chan *request, session *MatcherSession) {
 	}
 }
 
+// MatcherStats contains the progress of a matcher session.
+type MatcherStats struct {
+	Sections   uint64        // Number of sections in the filtered range
+	Processed  uint64        // Number of sections which went through the whole pipeline
+	Retrievals uint64        // Number of bloom bit vectors retrieved
+	Matches    uint64        // Number of potentially matching blocks found
+	Elapsed    time.Duration // Time since the session was started
+}
+
 // MatcherSession is returned by a started matcher to be used as a terminator
 // for the actively running matching operation.
 type MatcherSession struct {
 	matcher *Matcher
 
-	closer sync.Once     // Sync object to ensure we only ever close once
-	quit   chan struct{} // Quit channel to request pipeline termination
+	closer  sync.Once       // Sync object to ensure we only ever close once
+	quitter sync.Once       // Sync object to ensure we only ever signal termination once
+	quit    chan struct{}   // Quit channel to request pipeline termination
+	ctx     context.Context // Context used to abort filtering and retrievals
+	errLock sync.Mutex
+	err     error // Global error to track retrieval failures deep in the chain
 
-	ctx context.Context // Context used by the light client to abort filtering
-	err atomic.Value    // Global error to track retrieval failures deep in the chain
+	started    time.Time
+	sections   uint64
+	processed  uint64 // Sections done with (atomic access)
+	retrievals uint64 // Bit vectors retrieved (atomic access)
+	matches    uint64 // Blocks delivered as potential matches (atomic access)
 
 	pend sync.WaitGroup
 }
@@ -522,17 +559,45 @@ type MatcherSession struct {
 func (s *MatcherSession) Close() {
 	s.closer.Do(func() {
 		// Signal termination and wait for all goroutines to tear down
-		close(s.quit)
+		s.terminate()
 		s.pend.Wait()
 	})
 }
 
-// Error returns any failure encountered during the matching session.
+// terminate signals all goroutines of the session to stop, without waiting
+// for them to tear down.
+func (s *MatcherSession) terminate() {
+	s.quitter.Do(func() { close(s.quit) })
+}
+
+// Error returns any failure encountered during the matching session. If the
+// session was aborted through its context, the context error is returned.
 func (s *MatcherSession) Error() error {
-	if err := s.err.Load(); err != nil {
-		return err.(error)
+	s.errLock.Lock()
+	defer s.errLock.Unlock()
+
+	return s.err
+}
+
+// setError records a failure of the session, keeping only the first one.
+func (s *MatcherSession) setError(err error) {
+	s.errLock.Lock()
+	defer s.errLock.Unlock()
+
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// Stats returns the current progress of the matching session.
+func (s *MatcherSession) Stats() MatcherStats {
+	return MatcherStats{
+		Sections:   s.sections,
+		Processed:  atomic.LoadUint64(&s.processed),
+		Retrievals: atomic.LoadUint64(&s.retrievals),
+		Matches:    atomic.LoadUint64(&s.matches),
+		Elapsed:    time.Since(s.started),
 	}
-	return nil
 }
 
 // allocateRetrieval assigns a bloom bit index to a client process that can either
@@ -630,8 +695,8 @@ func (s *MatcherSession) Multiplex(batch int, wait time.Duration, mux chan chan
 
 			result := <-request
 			if result.Error != nil {
-				s.err.Store(result.Error)
-				s.Close()
+				s.setError(result.Error)
+				s.terminate()
 			}
 			s.deliverSections(result.Bit, result.Sections, result.Bitsets)
 		}
@@ -122,6 +122,72 @@ func makeRandomIndexes(lengths []int, max int) [][]bloomIndexes {
 	return res
 }
 
+// Tests that a matcher session is aborted when its context is cancelled, even
+// if no retrievals are being serviced.
+func TestMatcherCancellation(t *testing.T) {
+	matcher := NewMatcher(testSectionSize, [][][]byte{{[]byte("address")}})
+	matches := make(chan uint64, 16)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session, err := matcher.Start(ctx, 0, 4*testSectionSize, matches)
+	if err != nil {
+		t.Fatalf("failed to start matcher session: %v", err)
+	}
+	defer session.Close()
+
+	cancel()
+	select {
+	case _, ok := <-matches:
+		if ok {
+			t.Fatalf("unexpected match delivered")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("session not terminated after cancellation")
+	}
+	if err := session.Error(); err != context.Canceled {
+		t.Fatalf("session error mismatch: have %v, want %v", err, context.Canceled)
+	}
+}
+
+// Tests that a matcher session reports its progress.
+func TestMatcherStats(t *testing.T) {
+	filter := [][]bloomIndexes{{{10, 20, 30}}}
+	matcher := NewMatcher(testSectionSize, nil)
+	matcher.filters = filter
+	for _, bit := range filter[0][0] {
+		matcher.addScheduler(bit)
+	}
+	quit := make(chan struct{})
+	defer close(quit)
+
+	var (
+		blocks  = uint64(4 * testSectionSize)
+		matches = make(chan uint64, 16)
+	)
+	session, err := matcher.Start(context.Background(), 0, blocks-1, matches)
+	if err != nil {
+		t.Fatalf("failed to start matcher session: %v", err)
+	}
+	startRetrievers(session, quit, new(uint32), 16)
+
+	var found uint64
+	for range matches {
+		found++
+	}
+	session.Close()
+
+	stats := session.Stats()
+	if stats.Sections != 4 || stats.Processed != 4 {
+		t.Errorf("section progress mismatch: have %d/%d, want 4/4", stats.Processed, stats.Sections)
+	}
+	if stats.Matches != found {
+		t.Errorf("match count mismatch: have %d, want %d", stats.Matches, found)
+	}
+	if want := uint64(4 * 3); stats.Retrievals < want {
+		t.Errorf("retrieval count too low: have %d, want at least %d", stats.Retrievals, want)
+	}
+}
+
 // testMatcherDiffBatches runs the given matches test in single-delivery and also
 // in batches delivery mode, verifying that all kinds of deliveries are handled
 // correctly withn.
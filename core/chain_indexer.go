@@ -239,6 +239,13 @@ func (c *ChainIndexer) eventLoop(currentHeader *types.Header, events chan ChainH
 	}
 }
 
+// Rewind invalidates the sections of the indexer and its children beyond the
+// given head, e.g. after the chain was rewound with SetHead, the blocks beyond
+// it being deleted.
+func (c *ChainIndexer) Rewind(head uint64) {
+	c.newHead(head, true)
+}
+
 // newHead notifies the indexer about new chain heads and/or reorgs.
 func (c *ChainIndexer) newHead(head uint64, reorg bool) {
 	c.lock.Lock()
@@ -71,4 +71,8 @@ var (
 	// ErrTxTypeNotSupported is returned if a transaction is not supported in the
 	// current network configuration.
 	ErrTxTypeNotSupported = types.ErrTxTypeNotSupported
+
+	// ErrConditionNotMet is returned if the local validity conditions attached
+	// to a transaction do not hold against the pending block.
+	ErrConditionNotMet = types.ErrConditionNotMet
 )
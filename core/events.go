@@ -19,6 +19,7 @@ package core
 import (
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/params"
 )
 
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
@@ -40,4 +41,13 @@ type ChainSideEvent struct {
 	Block *types.Block
 }
 
-type ChainHeadEvent struct{ Block *types.Block }
+type ChainHeadEvent struct {
+	Block *types.Block
+
+	// Skipped is the number of earlier head events dropped in favour of this one,
+	// only set for coalescing subscriptions.
+	Skipped int
+}
+
+// ChainConfigEvent is posted when the chain configuration is updated at runtime.
+type ChainConfigEvent struct{ Config *params.ChainConfig }
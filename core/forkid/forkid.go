@@ -233,6 +233,13 @@ func gatherForks(config *params.ChainConfig) []uint64 {
 			forks = append(forks, rule.Uint64())
 		}
 	}
+	// Header extensions change the block encoding, so their activation has to
+	// separate peers even if not scheduled by a fork rule of the config
+	for _, ext := range types.HeaderExtensions {
+		if block := ext.Block(config); block != nil {
+			forks = append(forks, block.Uint64())
+		}
+	}
 	// Sort the fork block numbers to permit chronological XOR
 	for i := 0; i < len(forks); i++ {
 		for j := i + 1; j < len(forks); j++ {
@@ -19,9 +19,11 @@ package forkid
 import (
 	"bytes"
 	"math"
+	"math/big"
 	"testing"
 
 	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/params"
 	"github.com/c88032111/go-gdtu/rlp"
 )
@@ -233,3 +235,27 @@ func TestEncoding(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the activation of header extensions is part of the fork ID, so
+// that peers disagreeing on the header layout are separated.
+func TestHeaderExtensionForks(t *testing.T) {
+	defer func(exts []types.HeaderExtension) { types.HeaderExtensions = exts }(types.HeaderExtensions)
+
+	config := *params.MainnetChainConfig
+	before := NewID(&config, params.MainnetGenesisHash, 20000000)
+
+	types.HeaderExtensions = []types.HeaderExtension{
+		{Name: "test", Block: func(config *params.ChainConfig) *big.Int { return big.NewInt(15000000) }},
+	}
+	if id := NewID(&config, params.MainnetGenesisHash, 14999999); id.Next != 15000000 {
+		t.Errorf("next fork mismatch: have %d, want 15000000", id.Next)
+	}
+	if id := NewID(&config, params.MainnetGenesisHash, 20000000); id.Hash == before.Hash {
+		t.Errorf("fork ID unchanged by header extension activation")
+	}
+	// Extensions activated along with a fork rule don't add a fork
+	types.HeaderExtensions[0].Block = func(config *params.ChainConfig) *big.Int { return config.BerlinBlock }
+	if id := NewID(&config, params.MainnetGenesisHash, 20000000); id != before {
+		t.Errorf("fork ID mismatch: have %v, want %v", id, before)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 
 	"github.com/c88032111/go-gdtu/common"
@@ -31,6 +32,7 @@ import (
 	"github.com/c88032111/go-gdtu/core/rawdb"
 	"github.com/c88032111/go-gdtu/core/state"
 	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/core/vm"
 	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/c88032111/go-gdtu/gdtudb"
 	"github.com/c88032111/go-gdtu/log"
@@ -81,11 +83,12 @@ func (ga *GenesisAlloc) UnmarshalJSON(data []byte) error {
 
 // GenesisAccount is an account in the state of the genesis block.
 type GenesisAccount struct {
-	Code       []byte                      `json:"code,omitempty"`
-	Storage    map[common.Hash]common.Hash `json:"storage,omitempty"`
-	Balance    *big.Int                    `json:"balance" gencodec:"required"`
-	Nonce      uint64                      `json:"nonce,omitempty"`
-	PrivateKey []byte                      `json:"secretKey,omitempty"` // for tests
+	Code        []byte                      `json:"code,omitempty"`
+	Constructor []byte                      `json:"constructor,omitempty"` // init code run at genesis to produce the code
+	Storage     map[common.Hash]common.Hash `json:"storage,omitempty"`
+	Balance     *big.Int                    `json:"balance" gencodec:"required"`
+	Nonce       uint64                      `json:"nonce,omitempty"`
+	PrivateKey  []byte                      `json:"secretKey,omitempty"` // for tests
 }
 
 // field type overrides for gencodec
@@ -101,11 +104,12 @@ type genesisSpecMarshaling struct {
 }
 
 type genesisAccountMarshaling struct {
-	Code       hexutil.Bytes
-	Balance    *math.HexOrDecimal256
-	Nonce      math.HexOrDecimal64
-	Storage    map[storageJSON]storageJSON
-	PrivateKey hexutil.Bytes
+	Code        hexutil.Bytes
+	Constructor hexutil.Bytes
+	Balance     *math.HexOrDecimal256
+	Nonce       math.HexOrDecimal64
+	Storage     map[storageJSON]storageJSON
+	PrivateKey  hexutil.Bytes
 }
 
 // storageJSON represents a 256 bit byte array, but allows less than 256 bits when
@@ -183,11 +187,15 @@ func SetupGenesisBlockWithOverride(db gdtudb.Database, genesis *Genesis, overrid
 			genesis = DefaultGenesisBlock()
 		}
 		// Ensure the stored genesis matches with the given one.
-		hash := genesis.ToBlock(nil).Hash()
+		block, err := genesis.ToBlock(nil)
+		if err != nil {
+			return genesis.Config, common.Hash{}, err
+		}
+		hash := block.Hash()
 		if hash != stored {
 			return genesis.Config, hash, &GenesisMismatchError{stored, hash}
 		}
-		block, err := genesis.Commit(db)
+		block, err = genesis.Commit(db)
 		if err != nil {
 			return genesis.Config, hash, err
 		}
@@ -195,7 +203,11 @@ func SetupGenesisBlockWithOverride(db gdtudb.Database, genesis *Genesis, overrid
 	}
 	// Check whether the genesis block is already written.
 	if genesis != nil {
-		hash := genesis.ToBlock(nil).Hash()
+		block, err := genesis.ToBlock(nil)
+		if err != nil {
+			return genesis.Config, common.Hash{}, err
+		}
+		hash := block.Hash()
 		if hash != stored {
 			return genesis.Config, hash, &GenesisMismatchError{stored, hash}
 		}
@@ -254,8 +266,9 @@ func (g *Genesis) configOrDefault(ghash common.Hash) *params.ChainConfig {
 }
 
 // ToBlock creates the genesis block and writes state of a genesis specification
-// to the given database (or discards it if nil).
-func (g *Genesis) ToBlock(db gdtudb.Database) *types.Block {
+// to the given database (or discards it if nil). An error is returned if the
+// constructor of a genesis account fails.
+func (g *Genesis) ToBlock(db gdtudb.Database) (*types.Block, error) {
 	if db == nil {
 		db = rawdb.NewMemoryDatabase()
 	}
@@ -268,7 +281,6 @@ func (g *Genesis) ToBlock(db gdtudb.Database) *types.Block {
 			statedb.SetState(addr, key, value)
 		}
 	}
-	root := statedb.IntermediateRoot(false)
 	head := &types.Header{
 		Number:     new(big.Int).SetUint64(g.Number),
 		Nonce:      types.EncodeNonce(g.Nonce),
@@ -280,7 +292,6 @@ func (g *Genesis) ToBlock(db gdtudb.Database) *types.Block {
 		Difficulty: g.Difficulty,
 		MixDigest:  g.Mixhash,
 		Coinbase:   g.Coinbase,
-		Root:       root,
 	}
 	if g.GasLimit == 0 {
 		head.GasLimit = params.GenesisGasLimit
@@ -288,16 +299,87 @@ func (g *Genesis) ToBlock(db gdtudb.Database) *types.Block {
 	if g.Difficulty == nil {
 		head.Difficulty = params.GenesisDifficulty
 	}
+	if err := g.runConstructors(statedb, head); err != nil {
+		return nil, err
+	}
+	head.Root = statedb.IntermediateRoot(false)
+
 	statedb.Commit(false)
-	statedb.Database().TrieDB().Commit(root, true, nil)
+	statedb.Database().TrieDB().Commit(head.Root, true, nil)
+
+	return types.NewBlock(head, nil, nil, nil, trie.NewStackTrie(nil)), nil
+}
+
+// runConstructors executes the constructors of the genesis accounts on top of the
+// static allocation, in ascending address order, and stores the returned runtime
+// code as the code of the accounts. The constructors are run within the genesis
+// block as if called by the zero address, and may use up to its gas limit each.
+func (g *Genesis) runConstructors(statedb *state.StateDB, head *types.Header) error {
+	var addrs []common.Address
+	for addr, account := range g.Alloc {
+		if len(account.Constructor) == 0 {
+			continue
+		}
+		if len(account.Code) > 0 {
+			return fmt.Errorf("genesis account %x has both code and constructor", addr)
+		}
+		addrs = append(addrs, addr)
+	}
+	if len(addrs) == 0 {
+		return nil
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+
+	config := g.Config
+	if config == nil {
+		config = params.AllGdtuashProtocolChanges
+	}
+	blockCtx := vm.BlockContext{
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		Coinbase:    head.Coinbase,
+		GasLimit:    head.GasLimit,
+		BlockNumber: new(big.Int).Set(head.Number),
+		Time:        new(big.Int).SetUint64(head.Time),
+		Difficulty:  new(big.Int).Set(head.Difficulty),
+	}
+	evm := vm.NewEVM(blockCtx, vm.TxContext{GasPrice: new(big.Int)}, statedb, config, vm.Config{})
+	eip158 := config.IsEIP158(head.Number)
+
+	for _, addr := range addrs {
+		addr, code := addr, g.Alloc[addr].Constructor
+
+		// Mirror the nonce of contracts created by transactions
+		if eip158 && statedb.GetNonce(addr) == 0 {
+			statedb.SetNonce(addr, 1)
+		}
+		// Set up the access list of a contract creation transaction
+		if config.IsBerlin(head.Number) {
+			statedb.PrepareAccessList(common.Address{}, &addr, evm.ActivePrecompiles(), nil)
+		}
+		contract := vm.NewContract(vm.AccountRef(common.Address{}), vm.AccountRef(addr), new(big.Int), head.GasLimit)
+		contract.SetCallCode(&addr, crypto.Keccak256Hash(code), code)
 
-	return types.NewBlock(head, nil, nil, nil, trie.NewStackTrie(nil))
+		ret, err := evm.Interpreter().Run(contract, nil, false)
+		if err != nil {
+			return fmt.Errorf("genesis constructor of %x failed: %v", addr, err)
+		}
+		if eip158 && len(ret) > params.MaxCodeSize {
+			return fmt.Errorf("genesis constructor of %x failed: %v", addr, vm.ErrMaxCodeSizeExceeded)
+		}
+		statedb.SetCode(addr, ret)
+	}
+	return nil
 }
 
 // Commit writes the block and state of a genesis specification to the database.
 // The block is committed as the canonical head block.
 func (g *Genesis) Commit(db gdtudb.Database) (*types.Block, error) {
-	block := g.ToBlock(db)
+	block, err := g.ToBlock(db)
+	if err != nil {
+		return nil, err
+	}
 	if block.Number().Sign() != 0 {
 		return nil, fmt.Errorf("can't commit genesis block with number > 0")
 	}
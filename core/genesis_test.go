@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"math/big"
 	"reflect"
 	"testing"
@@ -24,6 +25,7 @@ import (
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/consensus/gdtuash"
 	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/state"
 	"github.com/c88032111/go-gdtu/core/vm"
 	"github.com/c88032111/go-gdtu/gdtudb"
 	"github.com/c88032111/go-gdtu/params"
@@ -31,11 +33,11 @@ import (
 )
 
 func TestDefaultGenesisBlock(t *testing.T) {
-	block := DefaultGenesisBlock().ToBlock(nil)
+	block, _ := DefaultGenesisBlock().ToBlock(nil)
 	if block.Hash() != params.MainnetGenesisHash {
 		t.Errorf("wrgdtu mainnet genesis hash, got %v, want %v", block.Hash(), params.MainnetGenesisHash)
 	}
-	block = DefaultRopstenGenesisBlock().ToBlock(nil)
+	block, _ = DefaultRopstenGenesisBlock().ToBlock(nil)
 	if block.Hash() != params.RopstenGenesisHash {
 		t.Errorf("wrgdtu ropsten genesis hash, got %v, want %v", block.Hash(), params.RopstenGenesisHash)
 	}
@@ -162,3 +164,55 @@ func TestSetupGenesis(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the constructors of genesis accounts are executed, deploying the
+// returned code along with the storage set up by the constructor.
+func TestGenesisConstructors(t *testing.T) {
+	var (
+		addr = common.Address{0xc0, 0xde}
+		// Stores 42 into slot 0 and deploys a runtime returning 42
+		runtime = common.FromHex("602a60005260206000f3")
+		init    = append(common.FromHex("602a600055600a6011600039600a6000f3"), runtime...)
+	)
+	genesis := &Genesis{
+		Config: params.AllGdtuashProtocolChanges,
+		Alloc: GenesisAlloc{
+			addr: {Constructor: init, Balance: big.NewInt(1), Storage: map[common.Hash]common.Hash{{0x01}: {0x01}}},
+		},
+	}
+	db := rawdb.NewMemoryDatabase()
+	block, err := genesis.Commit(db)
+	if err != nil {
+		t.Fatalf("failed to commit genesis: %v", err)
+	}
+	statedb, err := state.New(block.Root(), state.NewDatabase(db), nil)
+	if err != nil {
+		t.Fatalf("failed to open genesis state: %v", err)
+	}
+	if code := statedb.GetCode(addr); !bytes.Equal(code, runtime) {
+		t.Errorf("code mismatch: have %x, want %x", code, runtime)
+	}
+	if value := statedb.GetState(addr, common.Hash{}); value != common.BigToHash(big.NewInt(42)) {
+		t.Errorf("constructed storage mismatch: have %x, want 42", value)
+	}
+	if value := statedb.GetState(addr, common.Hash{0x01}); value != (common.Hash{0x01}) {
+		t.Errorf("static storage mismatch: have %x, want %x", value, common.Hash{0x01})
+	}
+	if nonce := statedb.GetNonce(addr); nonce != 1 {
+		t.Errorf("nonce mismatch: have %d, want 1", nonce)
+	}
+	// Failing constructors and ambiguous accounts are rejected
+	for _, account := range []GenesisAccount{
+		{Constructor: common.FromHex("60006000fd"), Balance: new(big.Int)},
+		{Constructor: init, Code: runtime, Balance: new(big.Int)},
+	} {
+		genesis.Alloc[addr] = account
+		if _, err := genesis.Commit(rawdb.NewMemoryDatabase()); err == nil {
+			t.Errorf("invalid constructor account %+v accepted", account)
+		}
+		// Setting up a database with a malformed genesis must fail gracefully
+		if _, _, err := SetupGenesisBlock(db, genesis); err == nil {
+			t.Errorf("invalid constructor account %+v accepted on setup", account)
+		}
+	}
+}
@@ -56,7 +56,7 @@ const (
 // It is not thread safe either, the encapsulating chain structures should do
 // the necessary mutex locking/unlocking.
 type HeaderChain struct {
-	config *params.ChainConfig
+	config atomic.Value // Chain & network configuration (*params.ChainConfig)
 
 	chainDb       gdtudb.Database
 	genesisHeader *types.Header
@@ -88,7 +88,6 @@ func NewHeaderChain(chainDb gdtudb.Database, config *params.ChainConfig, engine
 	}
 
 	hc := &HeaderChain{
-		config:        config,
 		chainDb:       chainDb,
 		headerCache:   headerCache,
 		tdCache:       tdCache,
@@ -97,6 +96,7 @@ func NewHeaderChain(chainDb gdtudb.Database, config *params.ChainConfig, engine
 		rand:          mrand.New(mrand.NewSource(seed.Int64())),
 		engine:        engine,
 	}
+	hc.config.Store(config)
 
 	hc.genesisHeader = hc.GetHeaderByNumber(0)
 	if hc.genesisHeader == nil {
@@ -641,7 +641,14 @@ func (hc *HeaderChain) SetGenesis(head *types.Header) {
 }
 
 // Config retrieves the header chain's chain configuration.
-func (hc *HeaderChain) Config() *params.ChainConfig { return hc.config }
+func (hc *HeaderChain) Config() *params.ChainConfig {
+	return hc.config.Load().(*params.ChainConfig)
+}
+
+// setConfig replaces the header chain's chain configuration.
+func (hc *HeaderChain) setConfig(config *params.ChainConfig) {
+	hc.config.Store(config)
+}
 
 // Engine retrieves the header chain's consensus engine.
 func (hc *HeaderChain) Engine() consensus.Engine { return hc.engine }
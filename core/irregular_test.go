@@ -0,0 +1,90 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/consensus/gdtuash"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// Tests that the irregular state changes of the chain config are applied at
+// their block, both when generating and when importing a chain.
+func TestIrregularChanges(t *testing.T) {
+	var (
+		drained = common.Address{0xde, 0xad}
+		partial = common.Address{0xde, 0xaf}
+		refund  = common.Address{0xbe, 0xef}
+		code    = []byte{0x60, 0x00, 0x60, 0x00, 0xfd}
+	)
+	change := &params.IrregularChange{
+		Block: big.NewInt(2),
+		Transfers: []params.IrregularTransfer{
+			{From: drained, To: refund},
+			{From: partial, To: refund, Amount: big.NewInt(300)},
+		},
+		Code: []params.IrregularCode{{Address: drained, Code: code}},
+	}
+	change.Hash = change.ComputeHash()
+
+	config := *params.TestChainConfig
+	config.IrregularChanges = []*params.IrregularChange{change}
+
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			drained: {Balance: big.NewInt(1000)},
+			partial: {Balance: big.NewInt(1000)},
+		},
+	}
+	genDb := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(genDb)
+	blocks, _ := GenerateChain(&config, genesis, gdtuash.NewFaker(), genDb, 3, func(i int, gen *BlockGen) {})
+
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+	chain, _ := NewBlockChain(db, nil, &config, gdtuash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	before, _ := chain.StateAt(blocks[0].Root())
+	if have := before.GetBalance(drained); have.Int64() != 1000 {
+		t.Fatalf("balance changed before block 2: have %v", have)
+	}
+	after, _ := chain.StateAt(chain.CurrentBlock().Root())
+	for addr, want := range map[common.Address]int64{drained: 0, partial: 700, refund: 1300} {
+		if have := after.GetBalance(addr); have.Int64() != want {
+			t.Errorf("balance of %x mismatch: have %v, want %d", addr, have, want)
+		}
+	}
+	if have := after.GetCode(drained); !bytes.Equal(have, code) {
+		t.Errorf("code mismatch: have %x, want %x", have, code)
+	}
+	// A chain config with a tampered change set must be refused
+	change.Transfers[1].Amount = big.NewInt(1000)
+	if _, err := (&Genesis{Config: &config}).Commit(rawdb.NewMemoryDatabase()); err == nil {
+		t.Fatalf("tampered irregular change accepted")
+	}
+}
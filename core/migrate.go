@@ -0,0 +1,209 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/gdtudb"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// migrationLogInterval is the time between two progress reports of a running
+// database migration.
+const migrationLogInterval = 8 * time.Second
+
+// legacyDatabaseVersion is the last database version predating migrations. Older
+// databases were always stamped with the current version on startup, whatever
+// their layout, so they keep being upgraded that way.
+const legacyDatabaseVersion = 8
+
+// MigrationProgress is the callback a migration reports its progress through,
+// in arbitrary units of work done out of a total (zero if unknown).
+type MigrationProgress func(done, total uint64)
+
+// Migration is a database schema change, converting a database of the previous
+// schema version into its own version and optionally back.
+//
+// A migration may be interrupted at any point and is rerun from scratch on the
+// next start, so it must tolerate data it already converted.
+type Migration struct {
+	Version uint64 // Schema version the migration upgrades the database to
+	Name    string // Short description of the schema change
+
+	Up   func(db gdtudb.Database, progress MigrationProgress) error // Forward migration from Version-1
+	Down func(db gdtudb.Database, progress MigrationProgress) error // Rollback to Version-1, nil if impossible
+}
+
+// dbMigrations is the registry of the schema changes since the database version
+// 8, in ascending version order. Each change to the database layout bumps the
+// BlockChainVersion and ships a migration converting existing databases.
+var dbMigrations []*Migration
+
+// MigrateDatabase upgrades the database to the BlockChainVersion, running the
+// registered migrations after checking the consistency of the freezer and the
+// key-value store. Unversioned databases are stamped with the current version,
+// ones predating migrations with the legacy version first.
+func MigrateDatabase(db gdtudb.Database) error {
+	return migrateDatabase(db, dbMigrations, BlockChainVersion)
+}
+
+// RollbackDatabase downgrades the database to the given schema version, so it
+// can be used by an older release, running the rollbacks of the registered
+// migrations.
+func RollbackDatabase(db gdtudb.Database, version uint64) error {
+	return rollbackDatabase(db, dbMigrations, BlockChainVersion, version)
+}
+
+// findMigration returns the registered migration to the given version.
+func findMigration(migrations []*Migration, version uint64) *Migration {
+	for _, m := range migrations {
+		if m.Version == version {
+			return m
+		}
+	}
+	return nil
+}
+
+func migrateDatabase(db gdtudb.Database, migrations []*Migration, target uint64) error {
+	current := rawdb.ReadDatabaseVersion(db)
+	if current != nil && *current < legacyDatabaseVersion {
+		log.Warn("Upgrade blockchain database version", "from", *current, "to", legacyDatabaseVersion)
+		rawdb.WriteDatabaseVersion(db, legacyDatabaseVersion)
+
+		version := uint64(legacyDatabaseVersion)
+		current = &version
+	}
+	switch {
+	case current == nil:
+		// New database, or one populated by tools not stamping the version
+		rawdb.WriteDatabaseVersion(db, target)
+		return nil
+	case *current == target:
+		return nil
+	case *current > target:
+		return fmt.Errorf("database version is v%d, Ggdtu %s only supports v%d: roll it back with a newer release", *current, params.VersionWithMeta, target)
+	}
+	// Ensure every step of the upgrade is covered before touching anything
+	for version := *current + 1; version <= target; version++ {
+		if findMigration(migrations, version) == nil {
+			return fmt.Errorf("database version is v%d, no migration to v%d available: resync required", *current, version)
+		}
+	}
+	if err := checkDatabaseConsistency(db); err != nil {
+		return fmt.Errorf("database inconsistent, refusing to migrate: %v", err)
+	}
+	for version := *current + 1; version <= target; version++ {
+		m := findMigration(migrations, version)
+		if err := runMigration(db, m, "upgrade", m.Up); err != nil {
+			return err
+		}
+		rawdb.WriteDatabaseVersion(db, version)
+	}
+	return nil
+}
+
+func rollbackDatabase(db gdtudb.Database, migrations []*Migration, latest, target uint64) error {
+	current := rawdb.ReadDatabaseVersion(db)
+	switch {
+	case current == nil:
+		return fmt.Errorf("database version unknown")
+	case *current > latest:
+		return fmt.Errorf("database version is v%d, Ggdtu %s only supports v%d", *current, params.VersionWithMeta, latest)
+	case *current <= target:
+		return fmt.Errorf("database version is v%d, nothing to roll back to v%d", *current, target)
+	}
+	for version := *current; version > target; version-- {
+		if m := findMigration(migrations, version); m == nil || m.Down == nil {
+			return fmt.Errorf("migration to v%d can't be rolled back", version)
+		}
+	}
+	if err := checkDatabaseConsistency(db); err != nil {
+		return fmt.Errorf("database inconsistent, refusing to roll back: %v", err)
+	}
+	for version := *current; version > target; version-- {
+		m := findMigration(migrations, version)
+		if err := runMigration(db, m, "rollback", m.Down); err != nil {
+			return err
+		}
+		rawdb.WriteDatabaseVersion(db, version-1)
+	}
+	return nil
+}
+
+// runMigration runs one direction of a migration, reporting its progress.
+func runMigration(db gdtudb.Database, m *Migration, direction string, run func(gdtudb.Database, MigrationProgress) error) error {
+	var (
+		start  = time.Now()
+		logged = start
+	)
+	log.Warn("Migrating database", "version", m.Version, "name", m.Name, "direction", direction)
+	progress := func(done, total uint64) {
+		if time.Since(logged) < migrationLogInterval {
+			return
+		}
+		if total > 0 {
+			log.Info("Migrating database", "version", m.Version, "done", done, "total", total,
+				"progress", fmt.Sprintf("%.2f%%", float64(done)*100/float64(total)), "elapsed", common.PrettyDuration(time.Since(start)))
+		} else {
+			log.Info("Migrating database", "version", m.Version, "done", done, "elapsed", common.PrettyDuration(time.Since(start)))
+		}
+		logged = time.Now()
+	}
+	if err := run(db, progress); err != nil {
+		return fmt.Errorf("database migration to v%d (%s) failed: %v", m.Version, m.Name, err)
+	}
+	log.Info("Migrated database", "version", m.Version, "direction", direction, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// checkDatabaseConsistency checks that the chain segments in the freezer and in
+// the key-value store link up, so a migration doesn't operate on a database
+// which lost data in an unclean shutdown.
+func checkDatabaseConsistency(db gdtudb.Database) error {
+	frozen, err := db.Ancients()
+	if err != nil || frozen == 0 {
+		return nil // No freezer or nothing frozen yet
+	}
+	head := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadHeaderHash(db))
+	if head == nil {
+		return fmt.Errorf("head header missing with %d frozen blocks", frozen)
+	}
+	if *head+1 < frozen {
+		return fmt.Errorf("head header #%d below the frozen blocks #%d", *head, frozen-1)
+	}
+	last := rawdb.ReadCanonicalHash(db, frozen-1)
+	if last == (common.Hash{}) {
+		return fmt.Errorf("last frozen block #%d missing", frozen-1)
+	}
+	if *head < frozen {
+		return nil
+	}
+	hash := rawdb.ReadCanonicalHash(db, frozen)
+	header := rawdb.ReadHeader(db, hash, frozen)
+	if header == nil {
+		return fmt.Errorf("first unfrozen header #%d missing", frozen)
+	}
+	if header.ParentHash != last {
+		return fmt.Errorf("freezer and key-value store diverge at block #%d: parent %x, frozen %x", frozen, header.ParentHash, last)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/consensus/gdtuash"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/gdtudb"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// testMigration creates a migration marking its application in the database.
+func testMigration(version uint64, rollback bool) *Migration {
+	key := []byte(fmt.Sprintf("migrated-%d", version))
+	m := &Migration{
+		Version: version,
+		Name:    fmt.Sprintf("test v%d", version),
+		Up: func(db gdtudb.Database, progress MigrationProgress) error {
+			progress(1, 1)
+			return db.Put(key, []byte{1})
+		},
+	}
+	if rollback {
+		m.Down = func(db gdtudb.Database, progress MigrationProgress) error {
+			return db.Delete(key)
+		}
+	}
+	return m
+}
+
+func checkVersion(t *testing.T, db gdtudb.Database, want uint64) {
+	t.Helper()
+	if have := rawdb.ReadDatabaseVersion(db); have == nil || *have != want {
+		t.Fatalf("database version mismatch: have %v, want %d", have, want)
+	}
+}
+
+func TestMigrateDatabase(t *testing.T) {
+	migrations := []*Migration{testMigration(9, true), testMigration(10, true), testMigration(11, false)}
+
+	// Unversioned databases are stamped with the target version
+	db := rawdb.NewMemoryDatabase()
+	if err := migrateDatabase(db, migrations, 10); err != nil {
+		t.Fatalf("failed to stamp new database: %v", err)
+	}
+	checkVersion(t, db, 10)
+	if has, _ := db.Has([]byte("migrated-10")); has {
+		t.Fatalf("migration ran on new database")
+	}
+	// Older databases are upgraded step by step
+	db = rawdb.NewMemoryDatabase()
+	rawdb.WriteDatabaseVersion(db, 8)
+	if err := migrateDatabase(db, migrations, 11); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	checkVersion(t, db, 11)
+	for _, version := range []int{9, 10, 11} {
+		if has, _ := db.Has([]byte(fmt.Sprintf("migrated-%d", version))); !has {
+			t.Fatalf("migration to v%d not applied", version)
+		}
+	}
+	// Newer databases are refused
+	if err := migrateDatabase(db, migrations, 10); err == nil {
+		t.Fatalf("newer database accepted")
+	}
+	checkVersion(t, db, 11)
+
+	// Databases predating migrations are stamped with the legacy version first
+	db = rawdb.NewMemoryDatabase()
+	rawdb.WriteDatabaseVersion(db, 7)
+	if err := migrateDatabase(db, migrations, 11); err != nil {
+		t.Fatalf("failed to migrate legacy database: %v", err)
+	}
+	checkVersion(t, db, 11)
+
+	// Databases without migration path are refused without modifications
+	gapped := []*Migration{testMigration(9, true), testMigration(11, false)}
+
+	db = rawdb.NewMemoryDatabase()
+	rawdb.WriteDatabaseVersion(db, 8)
+	if err := migrateDatabase(db, gapped, 11); err == nil {
+		t.Fatalf("database without migration path accepted")
+	}
+	checkVersion(t, db, 8)
+	if has, _ := db.Has([]byte("migrated-9")); has {
+		t.Fatalf("migration ran without complete migration path")
+	}
+	// Failed migrations keep the version of the last successful one
+	failing := append(migrations[:1:1], &Migration{
+		Version: 10,
+		Up: func(db gdtudb.Database, progress MigrationProgress) error {
+			return errors.New("boom")
+		},
+	})
+	db = rawdb.NewMemoryDatabase()
+	rawdb.WriteDatabaseVersion(db, 8)
+	if err := migrateDatabase(db, failing, 10); err == nil {
+		t.Fatalf("failed migration not reported")
+	}
+	checkVersion(t, db, 9)
+}
+
+func TestRollbackDatabase(t *testing.T) {
+	migrations := []*Migration{testMigration(9, true), testMigration(10, true), testMigration(11, false)}
+
+	db := rawdb.NewMemoryDatabase()
+	rawdb.WriteDatabaseVersion(db, 8)
+	if err := migrateDatabase(db, migrations, 11); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	// Migrations without rollback can't be rolled back
+	if err := rollbackDatabase(db, migrations, 11, 8); err == nil {
+		t.Fatalf("irreversible migration rolled back")
+	}
+	checkVersion(t, db, 11)
+
+	// Reversible ones are rolled back in reverse order
+	rawdb.WriteDatabaseVersion(db, 10)
+	if err := rollbackDatabase(db, migrations, 11, 8); err != nil {
+		t.Fatalf("failed to roll back database: %v", err)
+	}
+	checkVersion(t, db, 8)
+	for _, version := range []int{9, 10} {
+		if has, _ := db.Has([]byte(fmt.Sprintf("migrated-%d", version))); has {
+			t.Fatalf("migration to v%d not rolled back", version)
+		}
+	}
+	if err := rollbackDatabase(db, migrations, 11, 8); err == nil {
+		t.Fatalf("rollback to current version accepted")
+	}
+}
+
+// Tests that the consistency check detects a gap or a fork between the freezer
+// and the key-value store.
+func TestMigrationConsistencyCheck(t *testing.T) {
+	frdir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temp freezer dir: %v", err)
+	}
+	defer os.RemoveAll(frdir)
+
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), frdir, "")
+	if err != nil {
+		t.Fatalf("failed to create temp freezer db: %v", err)
+	}
+	defer db.Close()
+
+	var (
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+		td      = big.NewInt(1)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, gdtuash.NewFaker(), db, 8, nil)
+	forks, _ := GenerateChain(gspec.Config, genesis, gdtuash.NewFaker(), db, 8, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{0xde, 0xad})
+	})
+	if err := checkDatabaseConsistency(db); err != nil {
+		t.Fatalf("database without frozen blocks reported inconsistent: %v", err)
+	}
+	// Freeze the first blocks and keep the rest in the key-value store
+	rawdb.WriteAncientBlock(db, genesis, nil, td)
+	for _, block := range blocks[:4] {
+		rawdb.WriteAncientBlock(db, block, nil, td)
+	}
+	for _, block := range blocks[4:] {
+		rawdb.WriteHeader(db, block.Header())
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	}
+	rawdb.WriteHeadHeaderHash(db, blocks[7].Hash())
+	if err := checkDatabaseConsistency(db); err != nil {
+		t.Fatalf("consistent database reported inconsistent: %v", err)
+	}
+	// Fork the key-value store off the frozen blocks
+	rawdb.WriteHeader(db, forks[4].Header())
+	rawdb.WriteCanonicalHash(db, forks[4].Hash(), 5)
+	if err := checkDatabaseConsistency(db); err == nil {
+		t.Fatalf("forked key-value store not detected")
+	}
+	// Drop the first unfrozen header
+	rawdb.DeleteHeader(db, forks[4].Hash(), 5)
+	if err := checkDatabaseConsistency(db); err == nil {
+		t.Fatalf("missing header not detected")
+	}
+}
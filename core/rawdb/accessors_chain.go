@@ -252,6 +252,26 @@ func WriteTxIndexTail(db gdtudb.KeyValueWriter, number uint64) {
 	}
 }
 
+// ReadSideChainPruneTail retrieves the number of the first block whose side
+// chains haven't been pruned yet. If the corresponding entry is non-existent in
+// database it means no pruning has been done yet.
+func ReadSideChainPruneTail(db gdtudb.KeyValueReader) *uint64 {
+	data, _ := db.Get(sideChainPruneTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteSideChainPruneTail stores the number of the first block whose side chains
+// haven't been pruned yet into database.
+func WriteSideChainPruneTail(db gdtudb.KeyValueWriter, number uint64) {
+	if err := db.Put(sideChainPruneTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the side chain prune tail", "err", err)
+	}
+}
+
 // ReadFastTxLookupLimit retrieves the tx lookup limit used in fast sync.
 func ReadFastTxLookupLimit(db gdtudb.KeyValueReader) *uint64 {
 	data, _ := db.Get(fastTxLookupLimitKey)
@@ -582,6 +602,28 @@ func ReadRawReceipts(db gdtudb.Reader, hash common.Hash, number uint64) types.Re
 	return receipts
 }
 
+// ReadNetworkReceiptsRLP retrieves all the transaction receipts belgdtuing to a
+// block in the consensus encoding served to peers. Unlike ReadReceipts, it
+// doesn't decode the transactions of the block body nor derive the metadata
+// fields of the receipts.
+func ReadNetworkReceiptsRLP(db gdtudb.Reader, hash common.Hash, number uint64) rlp.RawValue {
+	data := ReadReceiptsRLP(db, hash, number)
+	if len(data) == 0 {
+		return nil
+	}
+	body := ReadBodyRLP(db, hash, number)
+	if len(body) == 0 {
+		log.Error("Missing body but have receipt", "hash", hash, "number", number)
+		return nil
+	}
+	receipts, err := types.EncodeReceiptsForNetwork(data, types.RawBody(body))
+	if err != nil {
+		log.Error("Failed to encode block receipts", "hash", hash, "number", number, "err", err)
+		return nil
+	}
+	return receipts
+}
+
 // ReadReceipts retrieves all the transaction receipts belgdtuing to a block, including
 // its correspoinding metadata fields. If it is unable to populate these metadata
 // fields then nil is returned.
@@ -527,3 +527,32 @@ func TestCanonicalHashIteration(t *testing.T) {
 		}
 	}
 }
+
+// Tests that receipts are served in the network encoding without decoding the
+// block body.
+func TestNetworkReceiptsRLP(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	to := common.Address{0xde, 0xad}
+	tx1 := types.NewTransaction(1, to, big.NewInt(1), 1, big.NewInt(1), nil)
+	tx2 := types.NewTx(&types.AccessListTx{ChainID: big.NewInt(1), Nonce: 2, To: &to, Gas: 2, GasPrice: big.NewInt(2)})
+	body := &types.Body{Transactions: types.Transactions{tx1, tx2}}
+
+	receipt1 := &types.Receipt{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 1, Logs: []*types.Log{{Address: to}}}
+	receipt2 := &types.Receipt{Status: types.ReceiptStatusFailed, CumulativeGasUsed: 2, Logs: []*types.Log{}}
+
+	hash := common.BytesToHash([]byte{0x03, 0x14})
+	WriteReceipts(db, hash, 0, types.Receipts{receipt1, receipt2})
+	if enc := ReadNetworkReceiptsRLP(db, hash, 0); enc != nil {
+		t.Fatalf("receipts returned without body: %x", enc)
+	}
+	WriteBody(db, hash, 0, body)
+
+	want, err := rlp.EncodeToBytes(ReadReceipts(db, hash, 0, params.TestChainConfig))
+	if err != nil {
+		t.Fatalf("failed to encode receipts: %v", err)
+	}
+	if have := ReadNetworkReceiptsRLP(db, hash, 0); !bytes.Equal(have, want) {
+		t.Fatalf("network receipts mismatch:\nhave %x\nwant %x", have, want)
+	}
+}
@@ -104,15 +104,28 @@ func ReadTransaction(db gdtudb.Reader, hash common.Hash) (*types.Transaction, co
 	if blockHash == (common.Hash{}) {
 		return nil, common.Hash{}, 0, 0
 	}
-	body := ReadBody(db, blockHash, *blockNumber)
-	if body == nil {
+	// Locate the transaction by hashing the raw transactions, decoding only the
+	// one looked up
+	body := types.RawBody(ReadBodyRLP(db, blockHash, *blockNumber))
+	if len(body) == 0 {
 		log.Error("Transaction referenced missing", "number", blockNumber, "hash", blockHash)
 		return nil, common.Hash{}, 0, 0
 	}
-	for txIndex, tx := range body.Transactions {
-		if tx.Hash() == hash {
-			return tx, blockHash, *blockNumber, uint64(txIndex)
+	hashes, err := body.TransactionHashes()
+	if err != nil {
+		log.Error("Invalid block body RLP", "hash", blockHash, "err", err)
+		return nil, common.Hash{}, 0, 0
+	}
+	for txIndex, txHash := range hashes {
+		if txHash != hash {
+			continue
+		}
+		tx, err := body.Transaction(txIndex)
+		if err != nil {
+			log.Error("Invalid transaction RLP", "hash", blockHash, "txhash", hash, "err", err)
+			return nil, common.Hash{}, 0, 0
 		}
+		return tx, blockHash, *blockNumber, uint64(txIndex)
 	}
 	log.Error("Transaction not found", "number", blockNumber, "hash", blockHash, "txhash", hash)
 	return nil, common.Hash{}, 0, 0
@@ -176,6 +176,29 @@ func DeleteSnapshotRecoveryNumber(db gdtudb.KeyValueWriter) {
 	}
 }
 
+// ReadStatePruningProgress retrieves the serialized progress of an interrupted
+// offline state pruning.
+func ReadStatePruningProgress(db gdtudb.KeyValueReader) []byte {
+	data, _ := db.Get(statePruningProgressKey)
+	return data
+}
+
+// WriteStatePruningProgress stores the serialized progress of the offline state
+// pruning.
+func WriteStatePruningProgress(db gdtudb.KeyValueWriter, progress []byte) {
+	if err := db.Put(statePruningProgressKey, progress); err != nil {
+		log.Crit("Failed to store state pruning progress", "err", err)
+	}
+}
+
+// DeleteStatePruningProgress deletes the serialized progress of the offline
+// state pruning.
+func DeleteStatePruningProgress(db gdtudb.KeyValueWriter) {
+	if err := db.Delete(statePruningProgressKey); err != nil {
+		log.Crit("Failed to remove state pruning progress", "err", err)
+	}
+}
+
 // ReadSnapshotSyncStatus retrieves the serialized sync status saved at shutdown.
 func ReadSnapshotSyncStatus(db gdtudb.KeyValueReader) []byte {
 	data, _ := db.Get(snapshotSyncStatusKey)
@@ -39,6 +39,12 @@ func WritePreimages(db gdtudb.KeyValueWriter, preimages map[common.Hash][]byte)
 	preimageHitCounter.Inc(int64(len(preimages)))
 }
 
+// IteratePreimages returns an iterator over all the stored preimages. The keys
+// of the iterator are prefixed with the preimage schema prefix.
+func IteratePreimages(db gdtudb.Iteratee) gdtudb.Iterator {
+	return db.NewIterator(preimagePrefix, nil)
+}
+
 // ReadCode retrieves the contract code of the provided code hash.
 func ReadCode(db gdtudb.KeyValueReader, hash common.Hash) []byte {
 	// Try with the legacy code scheme first, if not then try with current
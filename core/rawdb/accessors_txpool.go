@@ -0,0 +1,63 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/gdtudb"
+	"github.com/c88032111/go-gdtu/log"
+)
+
+// ReadLocalTransactions retrieves all the journaled local transactions awaiting
+// inclusion. Undecodable entries are skipped.
+func ReadLocalTransactions(db gdtudb.Iteratee) []*types.Transaction {
+	it := db.NewIterator(localTxPrefix, nil)
+	defer it.Release()
+
+	var txs []*types.Transaction
+	for it.Next() {
+		if len(it.Key()) != len(localTxPrefix)+common.HashLength {
+			continue
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(it.Value()); err != nil {
+			log.Warn("Invalid journaled local transaction", "key", common.BytesToHash(it.Key()[len(localTxPrefix):]), "err", err)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// WriteLocalTransaction journals a local transaction awaiting inclusion.
+func WriteLocalTransaction(db gdtudb.KeyValueWriter, tx *types.Transaction) {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		log.Crit("Failed to encode local transaction", "err", err)
+	}
+	if err := db.Put(localTxKey(tx.Hash()), data); err != nil {
+		log.Crit("Failed to store local transaction", "err", err)
+	}
+}
+
+// DeleteLocalTransaction removes a local transaction from the journal.
+func DeleteLocalTransaction(db gdtudb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(localTxKey(hash)); err != nil {
+		log.Crit("Failed to delete local transaction", "err", err)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/gdtudb"
+	"github.com/c88032111/go-gdtu/log"
+)
+
+// maxChainIssues is the maximum number of issues retained in a chain report, the
+// rest is only counted.
+const maxChainIssues = 1000
+
+// ChainIssue is an inconsistency found in the chain data of a block.
+type ChainIssue struct {
+	Number uint64
+	Hash   common.Hash
+	Detail string
+}
+
+func (issue ChainIssue) String() string {
+	return fmt.Sprintf("#%d [%x…]: %s", issue.Number, issue.Hash[:4], issue.Detail)
+}
+
+// ChainReport is the outcome of a chain data integrity check.
+type ChainReport struct {
+	Checked uint64       // Number of blocks whose headers and data presence were checked
+	Sampled uint64       // Number of blocks whose contents were verified in depth
+	Issues  []ChainIssue // Inconsistencies found, up to maxChainIssues
+	Total   uint64       // Total number of inconsistencies found
+}
+
+// report records an inconsistency of a block.
+func (r *ChainReport) report(number uint64, hash common.Hash, format string, args ...interface{}) {
+	r.Total++
+	if len(r.Issues) < maxChainIssues {
+		r.Issues = append(r.Issues, ChainIssue{Number: number, Hash: hash, Detail: fmt.Sprintf(format, args...)})
+	}
+}
+
+// InspectChain checks the integrity of the canonical chain data between the given
+// block numbers (both inclusive). The hash chain of the headers and the presence
+// of the block bodies and receipts are checked for every block. For the given
+// fraction of randomly sampled blocks, the transaction, uncle and receipt roots
+// are recomputed using the hasher, and the transaction lookup entries verified.
+//
+// The check stops early if the interrupt channel is closed, reporting the blocks
+// checked so far.
+func InspectChain(db gdtudb.Database, from, to uint64, sample float64, hasher types.TrieHasher, interrupt chan struct{}) *ChainReport {
+	var (
+		report = new(ChainReport)
+		tail   = ReadTxIndexTail(db)
+		parent common.Hash
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := from; number <= to; number++ {
+		select {
+		case <-interrupt:
+			log.Warn("Chain inspection interrupted", "number", number)
+			return report
+		default:
+		}
+		report.Checked++
+
+		hash := ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			report.report(number, hash, "canonical hash missing")
+			parent = common.Hash{}
+			continue
+		}
+		header := ReadHeader(db, hash, number)
+		switch {
+		case header == nil:
+			report.report(number, hash, "header missing")
+		case header.Hash() != hash:
+			report.report(number, hash, "header hash mismatch: have %x", header.Hash())
+		case number > from && parent != (common.Hash{}) && header.ParentHash != parent:
+			report.report(number, hash, "parent hash mismatch: have %x, want %x", header.ParentHash, parent)
+		}
+		parent = hash
+
+		hasBody, hasReceipts := HasBody(db, hash, number), HasReceipts(db, hash, number)
+		if !hasBody {
+			report.report(number, hash, "body missing")
+		}
+		if !hasReceipts {
+			report.report(number, hash, "receipts missing")
+		}
+		if header != nil && hasBody && hasReceipts && rand.Float64() < sample {
+			report.Sampled++
+			inspectBlock(db, header, tail, hasher, report)
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Inspecting chain", "number", number, "to", to, "issues", report.Total, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	return report
+}
+
+// inspectBlock verifies the Merkle roots of the body and receipts of a block
+// against its header, and the transaction lookup entries of its transactions.
+func inspectBlock(db gdtudb.Database, header *types.Header, tail *uint64, hasher types.TrieHasher, report *ChainReport) {
+	var (
+		number = header.Number.Uint64()
+		hash   = header.Hash()
+	)
+	body := ReadBody(db, hash, number)
+	if body == nil {
+		report.report(number, hash, "body undecodable")
+		return
+	}
+	if root := deriveRoot(types.Transactions(body.Transactions), hasher); root != header.TxHash {
+		report.report(number, hash, "transaction root mismatch: have %x, want %x", root, header.TxHash)
+	}
+	if uncles := types.CalcUncleHash(body.Uncles); uncles != header.UncleHash {
+		report.report(number, hash, "uncle hash mismatch: have %x, want %x", uncles, header.UncleHash)
+	}
+	receipts := ReadRawReceipts(db, hash, number)
+	switch {
+	case receipts == nil:
+		report.report(number, hash, "receipts undecodable")
+	case len(receipts) != len(body.Transactions):
+		report.report(number, hash, "receipt count mismatch: have %d, want %d", len(receipts), len(body.Transactions))
+	default:
+		if root := deriveRoot(receipts, hasher); root != header.ReceiptHash {
+			report.report(number, hash, "receipt root mismatch: have %x, want %x", root, header.ReceiptHash)
+		}
+	}
+	// Transactions below the index tail are not indexed, above it they must be.
+	// Without a tail, the indexing state is unknown and only the existing entries
+	// are verified.
+	for _, tx := range body.Transactions {
+		entry := ReadTxLookupEntry(db, tx.Hash())
+		switch {
+		case entry == nil && tail != nil && number >= *tail:
+			report.report(number, hash, "transaction lookup missing for %x", tx.Hash())
+		case entry != nil && *entry != number && ReadCanonicalHash(db, *entry) != (common.Hash{}):
+			report.report(number, hash, "transaction lookup for %x points to block #%d", tx.Hash(), *entry)
+		}
+	}
+}
+
+// deriveRoot computes the Merkle root of a list, as done by types.NewBlock.
+func deriveRoot(list types.DerivableList, hasher types.TrieHasher) common.Hash {
+	if list.Len() == 0 {
+		return types.EmptyRootHash
+	}
+	return types.DeriveSha(list, hasher)
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+)
+
+// Tests that the chain inspection detects broken hash chains, missing data, root
+// mismatches and wrong transaction lookup entries.
+func TestInspectChain(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var (
+		blocks []*types.Block
+		parent common.Hash
+	)
+	for i := uint64(0); i <= 10; i++ {
+		var (
+			txs      []*types.Transaction
+			receipts []*types.Receipt
+		)
+		if i > 0 {
+			txs = append(txs, types.NewTransaction(i, common.Address{0x11}, big.NewInt(111), 1111, big.NewInt(11111), []byte{0x11}))
+			receipts = append(receipts, &types.Receipt{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: i, Logs: []*types.Log{}})
+		}
+		block := types.NewBlock(&types.Header{Number: new(big.Int).SetUint64(i), ParentHash: parent}, txs, nil, receipts, newHasher())
+		WriteBlock(db, block)
+		WriteReceipts(db, block.Hash(), i, receipts)
+		WriteCanonicalHash(db, block.Hash(), i)
+		WriteTxLookupEntriesByBlock(db, block)
+		blocks, parent = append(blocks, block), block.Hash()
+	}
+	WriteTxIndexTail(db, 0)
+
+	if report := InspectChain(db, 0, 10, 1, newHasher(), nil); report.Total != 0 {
+		t.Fatalf("issues found in a healthy chain: %v", report.Issues)
+	}
+	// Corrupt various parts of the chain data
+	DeleteReceipts(db, blocks[2].Hash(), 2)
+
+	other := types.NewTransaction(100, common.Address{0x22}, big.NewInt(0), 0, big.NewInt(0), nil)
+	WriteBody(db, blocks[4].Hash(), 4, &types.Body{Transactions: []*types.Transaction{other}})
+
+	WriteTxLookupEntries(db, 7, []common.Hash{blocks[6].Transactions()[0].Hash()})
+	DeleteTxLookupEntry(db, blocks[8].Transactions()[0].Hash())
+
+	fork := types.NewBlock(&types.Header{Number: big.NewInt(9), Extra: []byte{0x01}}, nil, nil, nil, newHasher())
+	WriteHeader(db, fork.Header())
+	WriteCanonicalHash(db, fork.Hash(), 9)
+
+	want := map[uint64][]string{
+		2: {"receipts missing"},
+		4: {"transaction root mismatch", "transaction lookup missing"},
+		6: {"transaction lookup for"},
+		8: {"transaction lookup missing"},
+		9: {"parent hash mismatch", "body missing", "receipts missing"},
+	}
+	report := InspectChain(db, 0, 10, 1, newHasher(), nil)
+
+	have := make(map[uint64][]string)
+	for _, issue := range report.Issues {
+		have[issue.Number] = append(have[issue.Number], issue.Detail)
+	}
+	for number, details := range want {
+		if len(have[number]) != len(details) {
+			t.Errorf("block %d: issue mismatch: have %v, want %v", number, have[number], details)
+			continue
+		}
+		for i, detail := range details {
+			if !strings.HasPrefix(have[number][i], detail) {
+				t.Errorf("block %d: issue %d mismatch: have %q, want %q", number, i, have[number][i], detail)
+			}
+		}
+	}
+	// Block 10 links to the replaced block 9 and must be reported as well
+	if report.Total != 9 {
+		t.Errorf("issue count mismatch: have %d, want %d: %v", report.Total, 9, report.Issues)
+	}
+	// Without sampling, only the hash chain and the data presence are checked
+	if report := InspectChain(db, 0, 10, 0, newHasher(), nil); report.Sampled != 0 || report.Total != 5 {
+		t.Errorf("unsampled issue count mismatch: have %d (%d sampled), want 5", report.Total, report.Sampled)
+	}
+}
@@ -207,6 +207,15 @@ func indexTransactions(db gdtudb.Database, from uint64, to uint64, interrupt cha
 		// for stats reporting
 		blocks, txs = 0, 0
 	)
+	// If blocks which are already indexed are re-indexed, the tail must not be
+	// moved above the existing one. Neither may it be moved down if the range
+	// doesn't reach it, leaving a gap of unindexed blocks above the new tail.
+	oldTail := ReadTxIndexTail(db)
+	writeTail := func(tail uint64) {
+		if oldTail == nil || (tail < *oldTail && to >= *oldTail) {
+			WriteTxIndexTail(batch, tail)
+		}
+	}
 	for chanDelivery := range hashesCh {
 		// Push the delivery into the queue and process contiguous ranges.
 		// Since we iterate in reverse, so lower numbers have lower prio, and
@@ -229,7 +238,7 @@ func indexTransactions(db gdtudb.Database, from uint64, to uint64, interrupt cha
 			txs += len(delivery.hashes)
 			// If enough data was accumulated in memory or we're at the last block, dump to disk
 			if batch.ValueSize() > gdtudb.IdealBatchSize {
-				WriteTxIndexTail(batch, lastNum) // Also write the tail here
+				writeTail(lastNum) // Also write the tail here
 				if err := batch.Write(); err != nil {
 					log.Crit("Failed writing batch to db", "error", err)
 					return
@@ -246,7 +255,7 @@ func indexTransactions(db gdtudb.Database, from uint64, to uint64, interrupt cha
 	// Flush the new indexing tail and the last committed data. It can also happen
 	// that the last batch is empty because nothing to index, but the tail has to
 	// be flushed anyway.
-	WriteTxIndexTail(batch, lastNum)
+	writeTail(lastNum)
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed writing batch to db", "error", err)
 		return
@@ -276,6 +285,52 @@ func indexTransactionsForTesting(db gdtudb.Database, from uint64, to uint64, int
 	indexTransactions(db, from, to, interrupt, hook)
 }
 
+// RebuildTxIndex (re)creates the txlookup indices of the specified block range,
+// regardless of the blocks already indexed. It can be used to repair the index
+// or to extend it beyond the current tail. The tail is moved down if the range
+// starts below it and reaches up to it, but it's never raised.
+//
+// The indexing is throttled to the given number of blocks per second, zero
+// meaning unlimited, so that it can run alongside other database activity.
+// Since the range is processed in reverse, the progress and the estimated time
+// left are reported periodically.
+//
+// There is a passed channel, the whole procedure will be interrupted if any
+// signal received. The lowest block of the range rebuilt is returned, which is
+// from unless interrupted, so an interrupted rebuild can be resumed up to it.
+func RebuildTxIndex(db gdtudb.Database, from uint64, to uint64, rate uint64, interrupt chan struct{}) uint64 {
+	var (
+		start   = time.Now()
+		logged  = start
+		indexed uint64
+		tail    = to
+	)
+	hook := func(number uint64) bool {
+		if rate > 0 {
+			if wait := time.Duration(indexed+1)*time.Second/time.Duration(rate) - time.Since(start); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-interrupt:
+					return false
+				}
+			}
+		}
+		indexed, tail = indexed+1, number
+
+		if time.Since(logged) > 8*time.Second {
+			var (
+				elapsed = time.Since(start)
+				left    = time.Duration(float64(elapsed) / float64(indexed) * float64(number-from))
+			)
+			log.Info("Rebuilding transaction index", "indexed", indexed, "total", to-from, "tail", number, "elapsed", common.PrettyDuration(elapsed), "eta", common.PrettyDuration(left))
+			logged = time.Now()
+		}
+		return true
+	}
+	indexTransactions(db, from, to, interrupt, hook)
+	return tail
+}
+
 // unindexTransactions removes txlookup indices of the specified block range.
 //
 // There is a passed channel, the whole procedure will be interrupted if any
@@ -22,6 +22,7 @@ import (
 	"sort"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/core/types"
@@ -163,3 +164,71 @@ func TestIndexTransactions(t *testing.T) {
 	verify(8, 11, true, 8)
 	verify(0, 8, false, 8)
 }
+
+func TestRebuildTxIndex(t *testing.T) {
+	// Construct test chain db
+	chainDb := NewMemoryDatabase()
+
+	var txs []*types.Transaction
+	for i := uint64(0); i <= 10; i++ {
+		var block *types.Block
+		if i == 0 {
+			block = types.NewBlock(&types.Header{Number: big.NewInt(int64(i))}, nil, nil, nil, newHasher()) // Empty genesis block
+		} else {
+			tx := types.NewTransaction(i, common.BytesToAddress([]byte{0x11}), big.NewInt(111), 1111, big.NewInt(11111), []byte{0x11, 0x11, 0x11})
+			txs = append(txs, tx)
+			block = types.NewBlock(&types.Header{Number: big.NewInt(int64(i))}, []*types.Transaction{tx}, nil, nil, newHasher())
+		}
+		WriteBlock(chainDb, block)
+		WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+	}
+	// verify checks that the tx indices in the range [from, to) exist and that
+	// the tail is as expected.
+	verify := func(from, to int, tail uint64) {
+		for i := from; i < to; i++ {
+			if i == 0 {
+				continue
+			}
+			if number := ReadTxLookupEntry(chainDb, txs[i-1].Hash()); number == nil || *number != uint64(i) {
+				t.Fatalf("Transaction indice of block #%d missing", i)
+			}
+		}
+		if number := ReadTxIndexTail(chainDb); number == nil || *number != tail {
+			t.Fatalf("Transaction tail mismatch: have %v, want %d", number, tail)
+		}
+	}
+	IndexTransactions(chainDb, 5, 11, nil)
+	verify(5, 11, 5)
+
+	// Index a range below the tail not reaching it, the tail must not be moved
+	// across the gap of unindexed blocks
+	if tail := RebuildTxIndex(chainDb, 1, 3, 0, nil); tail != 1 {
+		t.Fatalf("Rebuilt tail mismatch: have %d, want 1", tail)
+	}
+	verify(1, 3, 5)
+	verify(5, 11, 5)
+
+	// Repair a missing indice within the indexed range, the tail must stay
+	DeleteTxLookupEntry(chainDb, txs[6].Hash())
+	if tail := RebuildTxIndex(chainDb, 6, 11, 0, nil); tail != 6 {
+		t.Fatalf("Rebuilt tail mismatch: have %d, want 6", tail)
+	}
+	verify(5, 11, 5)
+
+	// Extend the index beyond the tail with throttling
+	start := time.Now()
+	if tail := RebuildTxIndex(chainDb, 1, 11, 200, nil); tail != 1 {
+		t.Fatalf("Rebuilt tail mismatch: have %d, want 1", tail)
+	}
+	verify(1, 11, 1)
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("Rebuild not throttled: 10 blocks indexed in %v", elapsed)
+	}
+	// An interrupted rebuild must not lose the already indexed blocks
+	signal := make(chan struct{})
+	close(signal)
+	if tail := RebuildTxIndex(chainDb, 0, 11, 1, signal); tail != 11 {
+		t.Fatalf("Interrupted rebuild tail mismatch: have %d, want 11", tail)
+	}
+	verify(1, 11, 1)
+}
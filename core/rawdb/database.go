@@ -110,6 +110,21 @@ func (db *nofreezedb) Sync() error {
 	return errNotSupported
 }
 
+// AncientThreshold returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) AncientThreshold() (uint64, error) {
+	return 0, errNotSupported
+}
+
+// SetAncientThreshold returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) SetAncientThreshold(threshold uint64) error {
+	return errNotSupported
+}
+
+// TuneAncientThreshold returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) TuneAncientThreshold(max uint64) error {
+	return errNotSupported
+}
+
 // NewDatabase creates a high level database on top of a given key-value data
 // store without a freezer moving immutable chain segments into cold storage.
 func NewDatabase(db gdtudb.KeyValueStore) gdtudb.Database {
@@ -294,6 +309,7 @@ func InspectDatabase(db gdtudb.Database, keyPrefix, keyStart []byte) error {
 		preimages       stat
 		bloomBits       stat
 		cliqueSnaps     stat
+		skeletonHeaders stat
 
 		// Ancient store statistics
 		ancientHeadersSize  common.StorageSize
@@ -352,6 +368,8 @@ func InspectDatabase(db gdtudb.Database, keyPrefix, keyStart []byte) error {
 			bloomBits.Add(size)
 		case bytes.HasPrefix(key, []byte("clique-")) && len(key) == 7+common.HashLength:
 			cliqueSnaps.Add(size)
+		case bytes.HasPrefix(key, skeletonHeaderPrefix) && len(key) == (len(skeletonHeaderPrefix)+8):
+			skeletonHeaders.Add(size)
 		case bytes.HasPrefix(key, []byte("cht-")) ||
 			bytes.HasPrefix(key, []byte("chtIndexV2-")) ||
 			bytes.HasPrefix(key, []byte("chtRootV2-")): // Canonical hash trie
@@ -368,7 +386,7 @@ func InspectDatabase(db gdtudb.Database, keyPrefix, keyStart []byte) error {
 				databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, lastPivotKey,
 				fastTrieProgressKey, snapshotRootKey, snapshotJournalKey, snapshotGeneratorKey,
 				snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey, uncleanShutdownKey,
-				badBlockKey,
+				badBlockKey, skeletonSyncStatusKey, statePruningProgressKey, sideChainPruneTailKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -415,6 +433,7 @@ func InspectDatabase(db gdtudb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Account snapshot", accountSnaps.Size(), accountSnaps.Count()},
 		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
 		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
+		{"Key-Value store", "Skeleton headers", skeletonHeaders.Size(), skeletonHeaders.Count()},
 		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
 		{"Key-Value store", "Shutdown metadata", shutdownInfo.Size(), shutdownInfo.Count()},
 		{"Ancient store", "Headers", ancientHeadersSize.String(), ancients.String()},
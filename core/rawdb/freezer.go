@@ -75,6 +75,7 @@ type freezer struct {
 
 	tables       map[string]*freezerTable // Data tables for storing everything
 	instanceLock fileutil.Releaser        // File-system lock to prevent double opens
+	tuner        atomic.Value             // Threshold tuner (*freezerTuner), nil if the threshold is fixed
 
 	trigger chan chan struct{} // Manual blocking freeze trigger, test determinism
 
@@ -112,6 +113,8 @@ func newFreezer(datadir string, namespace string) (*freezer, error) {
 		trigger:      make(chan chan struct{}),
 		quit:         make(chan struct{}),
 	}
+	freezer.tuner.Store((*freezerTuner)(nil))
+
 	for name, disableSnappy := range freezerNoSnappy {
 		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, disableSnappy)
 		if err != nil {
@@ -121,6 +124,7 @@ func newFreezer(datadir string, namespace string) (*freezer, error) {
 			lock.Release()
 			return nil, err
 		}
+		table.instrument(namespace+"ancient/", nil)
 		freezer.tables[name] = table
 	}
 	if err := freezer.repair(); err != nil {
@@ -166,6 +170,9 @@ func (f *freezer) HasAncient(kind string, number uint64) (bool, error) {
 // Ancient retrieves an ancient binary blob from the append-only immutable files.
 func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
 	if table := f.tables[kind]; table != nil {
+		if tuner := f.tuner.Load().(*freezerTuner); tuner != nil {
+			defer tuner.record(number, time.Now())
+		}
 		return table.Retrieve(number)
 	}
 	return nil, errUnknownTable
@@ -176,6 +183,34 @@ func (f *freezer) Ancients() (uint64, error) {
 	return atomic.LoadUint64(&f.frozen), nil
 }
 
+// AncientThreshold returns the number of recent blocks not to freeze.
+func (f *freezer) AncientThreshold() (uint64, error) {
+	return atomic.LoadUint64(&f.threshold), nil
+}
+
+// SetAncientThreshold changes the number of recent blocks not to freeze. As
+// frozen blocks are considered final, the threshold can't be lowered below the
+// immutability threshold. An enabled tuner continues from the new value.
+func (f *freezer) SetAncientThreshold(threshold uint64) error {
+	if threshold < params.FullImmutabilityThreshold {
+		return fmt.Errorf("freezer threshold %d below immutability threshold %d", threshold, params.FullImmutabilityThreshold)
+	}
+	atomic.StoreUint64(&f.threshold, threshold)
+	return nil
+}
+
+// TuneAncientThreshold enables tuning the freezer threshold between the
+// immutability threshold and max based on the ancient reads, or disables it
+// if max is zero.
+func (f *freezer) TuneAncientThreshold(max uint64) error {
+	if max == 0 {
+		f.tuner.Store((*freezerTuner)(nil))
+		return nil
+	}
+	f.tuner.Store(newFreezerTuner(max))
+	return nil
+}
+
 // AncientSize returns the ancient size of the specified category.
 func (f *freezer) AncientSize(kind string) (uint64, error) {
 	if table := f.tables[kind]; table != nil {
@@ -245,16 +280,25 @@ func (f *freezer) TruncateAncients(items uint64) error {
 	return nil
 }
 
-// Sync flushes all data tables to disk.
+// Sync flushes all data tables to disk. The tables are synced concurrently, so
+// a batch of appended blocks costs a single round of fsyncs.
 func (f *freezer) Sync() error {
-	var errs []error
+	var (
+		errs = make(chan error, len(f.tables))
+		all  []error
+	)
 	for _, table := range f.tables {
-		if err := table.Sync(); err != nil {
-			errs = append(errs, err)
+		go func(table *freezerTable) {
+			errs <- table.Sync()
+		}(table)
+	}
+	for range f.tables {
+		if err := <-errs; err != nil {
+			all = append(all, err)
 		}
 	}
-	if errs != nil {
-		return fmt.Errorf("%v", errs)
+	if all != nil {
+		return fmt.Errorf("%v", all)
 	}
 	return nil
 }
@@ -301,6 +345,9 @@ func (f *freezer) freeze(db gdtudb.KeyValueStore) {
 			continue
 		}
 		number := ReadHeaderNumber(nfdb, hash)
+		if tuner := f.tuner.Load().(*freezerTuner); tuner != nil && number != nil {
+			atomic.StoreUint64(&f.threshold, tuner.tune(*number, atomic.LoadUint64(&f.threshold)))
+		}
 		threshold := atomic.LoadUint64(&f.threshold)
 
 		switch {
@@ -20,11 +20,13 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"os"
 	"path/filepath"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/log"
@@ -43,8 +45,29 @@ var (
 
 	// errNotSupported is returned if the database doesn't support the required operation.
 	errNotSupported = errors.New("this operation is not supported")
+
+	// errChecksumMismatch is returned if an item read from the freezer table doesn't
+	// match the checksum recorded when it was written.
+	errChecksumMismatch = errors.New("checksum mismatch")
+)
+
+const (
+	// checksumHeaderSize is the size of the checksum file header, holding the
+	// number of the first item checksummed. Items written by versions before
+	// checksums were introduced aren't checksummed.
+	checksumHeaderSize = 8
+
+	// checksumSize is the size of the CRC32 checksum of an item.
+	checksumSize = 4
+
+	// freezerWriteBuffer is the amount of data appended to a table which is kept
+	// in memory before being written out to the files.
+	freezerWriteBuffer = 2 * 1024 * 1024
 )
 
+// checksumTable is the CRC32 polynomial table used to checksum the items.
+var checksumTable = crc32.MakeTable(crc32.Castagnoli)
+
 // indexEntry contains the number/id of the file that the data resides in, aswell as the
 // offset within the file to the end of the data
 // In serialized form, the filenum is stored as uint16.
@@ -71,8 +94,12 @@ func (i *indexEntry) marshallBinary() []byte {
 }
 
 // freezerTable represents a single chained data table within the freezer (e.g. blocks).
-// It consists of a data file (snappy encoded arbitrary data blobs) and an indexEntry
-// file (uncompressed 64 bit indices into the data file).
+// It consists of a data file (snappy encoded arbitrary data blobs), an indexEntry
+// file (uncompressed 64 bit indices into the data file) and a checksum file (CRC32
+// of each stored blob).
+//
+// Appended items are buffered in memory and written out in bulk when the buffer
+// fills up, the table is synced, or a buffered item is read.
 type freezerTable struct {
 	// WARNING: The `items` field is accessed atomically. On 32 bit platforms, only
 	// 64-bit aligned fields can be atomic. The struct is guaranteed to be so aligned,
@@ -89,6 +116,13 @@ type freezerTable struct {
 	headId uint32              // number of the currently active head file
 	tailId uint32              // number of the earliest file
 	index  *os.File            // File descriptor for the indexEntry file of the table
+	crcs   *os.File            // File descriptor for the checksum file of the table
+
+	crcStart uint64 // Number of the first item with a checksum
+
+	headBuf  []byte // Data appended to the head file not written out yet
+	indexBuf []byte // Index entries appended not written out yet
+	crcBuf   []byte // Checksums appended not written out yet
 
 	// In the case that old items are deleted (from the tail), we use itemOffset
 	// to count how many historic items have ggdtu missing.
@@ -99,6 +133,11 @@ type freezerTable struct {
 	writeMeter metrics.Meter // Meter for measuring the effective amount of data written
 	sizeGauge  metrics.Gauge // Gauge for tracking the combined size of all freezer tables
 
+	readTimer  metrics.Timer // Timer for measuring the latency of item retrievals
+	writeTimer metrics.Timer // Timer for measuring the latency of writing buffered items out
+	indexTimer metrics.Timer // Timer for measuring the latency of index lookups
+	filesGauge metrics.Gauge // Gauge for tracking the number of open data files
+
 	logger log.Logger   // Logger with database path and table name ambedded
 	lock   sync.RWMutex // Mutex protecting the data file descriptors
 }
@@ -159,25 +198,35 @@ func newCustomTable(path string, name string, readMeter metrics.Meter, writeMete
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
 	}
-	var idxName string
+	var idxName, crcName string
 	if noCompression {
 		// Raw idx
-		idxName = fmt.Sprintf("%s.ridx", name)
+		idxName, crcName = fmt.Sprintf("%s.ridx", name), fmt.Sprintf("%s.rcrc", name)
 	} else {
 		// Compressed idx
-		idxName = fmt.Sprintf("%s.cidx", name)
+		idxName, crcName = fmt.Sprintf("%s.cidx", name), fmt.Sprintf("%s.ccrc", name)
 	}
 	offsets, err := openFreezerFileForAppend(filepath.Join(path, idxName))
 	if err != nil {
 		return nil, err
 	}
+	crcs, err := openFreezerFileForAppend(filepath.Join(path, crcName))
+	if err != nil {
+		offsets.Close()
+		return nil, err
+	}
 	// Create the table and repair any past inconsistency
 	tab := &freezerTable{
 		index:         offsets,
+		crcs:          crcs,
 		files:         make(map[uint32]*os.File),
 		readMeter:     readMeter,
 		writeMeter:    writeMeter,
 		sizeGauge:     sizeGauge,
+		readTimer:     metrics.NilTimer{},
+		writeTimer:    metrics.NilTimer{},
+		indexTimer:    metrics.NilTimer{},
+		filesGauge:    metrics.NilGauge{},
 		name:          name,
 		path:          path,
 		logger:        log.New("database", path, "table", name),
@@ -188,6 +237,10 @@ func newCustomTable(path string, name string, readMeter metrics.Meter, writeMete
 		tab.Close()
 		return nil, err
 	}
+	if err := tab.repairChecksums(); err != nil {
+		tab.Close()
+		return nil, err
+	}
 	// Initialize the starting size counter
 	size, err := tab.sizeNolock()
 	if err != nil {
@@ -199,6 +252,20 @@ func newCustomTable(path string, name string, readMeter metrics.Meter, writeMete
 	return tab, nil
 }
 
+// instrument registers the per-table metrics of the freezer table in the given
+// registry, named after the table under the given prefix.
+func (t *freezerTable) instrument(prefix string, r metrics.Registry) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	prefix = prefix + t.name + "/"
+	t.readTimer = metrics.NewRegisteredTimer(prefix+"read", r)
+	t.writeTimer = metrics.NewRegisteredTimer(prefix+"write", r)
+	t.indexTimer = metrics.NewRegisteredTimer(prefix+"index", r)
+	t.filesGauge = metrics.NewRegisteredGauge(prefix+"files", r)
+	t.filesGauge.Update(int64(len(t.files)))
+}
+
 // repair cross checks the head and the index file and truncates them to
 // be in sync with each other after a potential crash / data loss.
 func (t *freezerTable) repair() error {
@@ -329,6 +396,72 @@ func (t *freezerTable) preopen() (err error) {
 	return err
 }
 
+// repairChecksums cross checks the checksum file with the repaired index, and
+// truncates the dangling checksums after a potential crash. As checksums are
+// written out before the data, only tables predating them (or tampered with)
+// may lack the checksums of items, which are then only recorded for new items.
+func (t *freezerTable) repairChecksums() error {
+	stat, err := t.crcs.Stat()
+	if err != nil {
+		return err
+	}
+	size := stat.Size()
+	if size < checksumHeaderSize {
+		if t.items > 0 {
+			t.logger.Info("Enabling freezer table checksums", "items", t.items)
+		}
+		if err := t.resetChecksums(t.items); err != nil {
+			return err
+		}
+		size = checksumHeaderSize
+	} else {
+		header := make([]byte, checksumHeaderSize)
+		if _, err := t.crcs.ReadAt(header, 0); err != nil {
+			return err
+		}
+		t.crcStart = binary.BigEndian.Uint64(header)
+	}
+	covered := t.crcStart + uint64(size-checksumHeaderSize)/checksumSize
+	switch {
+	case t.crcStart > t.items:
+		t.logger.Warn("Resetting checksums beyond the table", "items", t.items, "checksummed", t.crcStart)
+		if err := t.resetChecksums(t.items); err != nil {
+			return err
+		}
+	case covered > t.items:
+		t.logger.Debug("Truncating dangling checksums", "items", t.items, "checksummed", covered)
+		if err := truncateFreezerFile(t.crcs, checksumHeaderSize+int64(t.items-t.crcStart)*checksumSize); err != nil {
+			return err
+		}
+	case covered < t.items:
+		t.logger.Warn("Resetting checksums missing items", "items", t.items, "checksummed", covered)
+		if err := t.resetChecksums(t.items); err != nil {
+			return err
+		}
+	default:
+		// Checksums in sync, make sure any partial one is dropped
+		if err := truncateFreezerFile(t.crcs, checksumHeaderSize+int64(t.items-t.crcStart)*checksumSize); err != nil {
+			return err
+		}
+	}
+	return t.crcs.Sync()
+}
+
+// resetChecksums empties the checksum file, recording checksums from the given
+// item onwards.
+func (t *freezerTable) resetChecksums(start uint64) error {
+	if err := truncateFreezerFile(t.crcs, 0); err != nil {
+		return err
+	}
+	header := make([]byte, checksumHeaderSize)
+	binary.BigEndian.PutUint64(header, start)
+	if _, err := t.crcs.Write(header); err != nil {
+		return err
+	}
+	t.crcStart = start
+	return nil
+}
+
 // truncate discards any recent data above the provided threshold number.
 func (t *freezerTable) truncate(items uint64) error {
 	t.lock.Lock()
@@ -339,6 +472,10 @@ func (t *freezerTable) truncate(items uint64) error {
 	if existing <= items {
 		return nil
 	}
+	// Write out any buffered items, to truncate the files only
+	if err := t.flush(); err != nil {
+		return err
+	}
 	// We need to truncate, save the old size for metrics tracking
 	oldSize, err := t.sizeNolock()
 	if err != nil {
@@ -379,6 +516,14 @@ func (t *freezerTable) truncate(items uint64) error {
 	if err := truncateFreezerFile(t.head, int64(expected.offset)); err != nil {
 		return err
 	}
+	// Drop the checksums of the discarded items
+	if items < t.crcStart {
+		if err := t.resetChecksums(items); err != nil {
+			return err
+		}
+	} else if err := truncateFreezerFile(t.crcs, checksumHeaderSize+int64(items-t.crcStart)*checksumSize); err != nil {
+		return err
+	}
 	// All data files truncated, set internal counters and return
 	atomic.StoreUint64(&t.items, items)
 	atomic.StoreUint32(&t.headBytes, expected.offset)
@@ -399,17 +544,26 @@ func (t *freezerTable) Close() error {
 	defer t.lock.Unlock()
 
 	var errs []error
+	if err := t.flush(); err != nil {
+		errs = append(errs, err)
+	}
 	if err := t.index.Close(); err != nil {
 		errs = append(errs, err)
 	}
 	t.index = nil
 
+	if err := t.crcs.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	t.crcs = nil
+
 	for _, f := range t.files {
 		if err := f.Close(); err != nil {
 			errs = append(errs, err)
 		}
 	}
 	t.head = nil
+	t.filesGauge.Update(0)
 
 	if errs != nil {
 		return fmt.Errorf("%v", errs)
@@ -432,6 +586,7 @@ func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error
 			return nil, err
 		}
 		t.files[num] = f
+		t.filesGauge.Update(int64(len(t.files)))
 	}
 	return f, err
 }
@@ -442,6 +597,7 @@ func (t *freezerTable) releaseFile(num uint32) {
 	if f, exist := t.files[num]; exist {
 		delete(t.files, num)
 		f.Close()
+		t.filesGauge.Update(int64(len(t.files)))
 	}
 }
 
@@ -456,6 +612,7 @@ func (t *freezerTable) releaseFilesAfter(num uint32, remove bool) {
 			}
 		}
 	}
+	t.filesGauge.Update(int64(len(t.files)))
 }
 
 // Append injects a binary blob at the end of the freezer table. The item number
@@ -465,19 +622,19 @@ func (t *freezerTable) releaseFilesAfter(num uint32, remove bool) {
 // Note, this Method will *not* flush any data to disk so be sure to explicitly
 // fsync before irreversibly deleting data from the database.
 func (t *freezerTable) Append(item uint64, blob []byte) error {
-	// Read lock prevents competition with truncate
-	t.lock.RLock()
+	// Write lock prevents competition with readers flushing the buffers
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
 	// Ensure the table is still accessible
 	if t.index == nil || t.head == nil {
-		t.lock.RUnlock()
 		return errClosed
 	}
 	// Ensure only the next item can be written, nothing else
 	if atomic.LoadUint64(&t.items) != item {
-		t.lock.RUnlock()
 		return fmt.Errorf("appending unexpected item: want %d, have %d", t.items, item)
 	}
-	// Encode the blob and write it into the data file
+	// Encode the blob and buffer it for the data file
 	if !t.noCompression {
 		blob = snappy.Encode(nil, blob)
 	}
@@ -485,14 +642,14 @@ func (t *freezerTable) Append(item uint64, blob []byte) error {
 	if t.headBytes+bLen < bLen ||
 		t.headBytes+bLen > t.maxFileSize {
 		// we need a new file, writing would overflow
-		t.lock.RUnlock()
-		t.lock.Lock()
+		if err := t.flush(); err != nil {
+			return err
+		}
 		nextID := atomic.LoadUint32(&t.headId) + 1
 		// We open the next file in truncated mode -- if this file already
 		// exists, we need to start over from scratch on it
 		newHead, err := t.openFile(nextID, openFreezerFileTruncated)
 		if err != nil {
-			t.lock.Unlock()
 			return err
 		}
 		// Close old file, and reopen in RDONLY mode
@@ -503,29 +660,63 @@ func (t *freezerTable) Append(item uint64, blob []byte) error {
 		t.head = newHead
 		atomic.StoreUint32(&t.headBytes, 0)
 		atomic.StoreUint32(&t.headId, nextID)
-		t.lock.Unlock()
-		t.lock.RLock()
-	}
-
-	defer t.lock.RUnlock()
-	if _, err := t.head.Write(blob); err != nil {
-		return err
 	}
+	t.headBuf = append(t.headBuf, blob...)
 	newOffset := atomic.AddUint32(&t.headBytes, bLen)
 	idx := indexEntry{
 		filenum: atomic.LoadUint32(&t.headId),
 		offset:  newOffset,
 	}
-	// Write indexEntry
-	t.index.Write(idx.marshallBinary())
+	t.indexBuf = append(t.indexBuf, idx.marshallBinary()...)
 
-	t.writeMeter.Mark(int64(bLen + indexEntrySize))
-	t.sizeGauge.Inc(int64(bLen + indexEntrySize))
+	var crc [checksumSize]byte
+	binary.BigEndian.PutUint32(crc[:], crc32.Checksum(blob, checksumTable))
+	t.crcBuf = append(t.crcBuf, crc[:]...)
+
+	t.writeMeter.Mark(int64(bLen + indexEntrySize + checksumSize))
+	t.sizeGauge.Inc(int64(bLen + indexEntrySize + checksumSize))
 
 	atomic.AddUint64(&t.items, 1)
+
+	if len(t.headBuf) >= freezerWriteBuffer {
+		return t.flush()
+	}
+	return nil
+}
+
+// flush writes the buffered items out to the files of the table. The checksums
+// are written first and the index last, so a crash in between leaves dangling
+// checksums and data to be truncated, but never items without checksums. The
+// write lock is assumed to be held.
+func (t *freezerTable) flush() error {
+	if len(t.indexBuf) == 0 {
+		return nil
+	}
+	if t.index == nil || t.head == nil {
+		return errClosed
+	}
+	defer t.writeTimer.UpdateSince(time.Now())
+
+	if _, err := t.crcs.Write(t.crcBuf); err != nil {
+		return err
+	}
+	if _, err := t.head.Write(t.headBuf); err != nil {
+		return err
+	}
+	if _, err := t.index.Write(t.indexBuf); err != nil {
+		return err
+	}
+	t.crcBuf, t.headBuf, t.indexBuf = t.crcBuf[:0], t.headBuf[:0], t.indexBuf[:0]
 	return nil
 }
 
+// buffered returns whgdtuer the item is still buffered in memory, not written
+// out to the files yet. The read lock is assumed to be held.
+func (t *freezerTable) buffered(item uint64) bool {
+	pending := uint64(len(t.indexBuf) / indexEntrySize)
+	return pending > 0 && item >= atomic.LoadUint64(&t.items)-pending
+}
+
 // getBounds returns the indexes for the item
 // returns start, end, filenumber and error
 func (t *freezerTable) getBounds(item uint64) (uint32, uint32, uint32, error) {
@@ -562,7 +753,20 @@ func (t *freezerTable) getBounds(item uint64) (uint32, uint32, uint32, error) {
 // Retrieve looks up the data offset of an item with the given number and retrieves
 // the raw binary blob from the data file.
 func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
+	start := time.Now()
+
 	t.lock.RLock()
+	// Write out the buffered items if the requested one is among them
+	if t.buffered(item) {
+		t.lock.RUnlock()
+		t.lock.Lock()
+		err := t.flush()
+		t.lock.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		t.lock.RLock()
+	}
 	// Ensure the table and the item is accessible
 	if t.index == nil || t.head == nil {
 		t.lock.RUnlock()
@@ -577,7 +781,9 @@ func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
 		t.lock.RUnlock()
 		return nil, errOutOfBounds
 	}
+	lookup := time.Now()
 	startOffset, endOffset, filenum, err := t.getBounds(item - uint64(t.itemOffset))
+	t.indexTimer.UpdateSince(lookup)
 	if err != nil {
 		t.lock.RUnlock()
 		return nil, err
@@ -593,8 +799,22 @@ func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
 		t.lock.RUnlock()
 		return nil, err
 	}
+	// Verify the checksum of the blob, unless written before checksums
+	if item >= t.crcStart {
+		crc := make([]byte, checksumSize)
+		if _, err := t.crcs.ReadAt(crc, checksumHeaderSize+int64(item-t.crcStart)*checksumSize); err != nil {
+			t.lock.RUnlock()
+			return nil, err
+		}
+		if binary.BigEndian.Uint32(crc) != crc32.Checksum(blob, checksumTable) {
+			t.lock.RUnlock()
+			t.logger.Error("Corrupted freezer item", "item", item)
+			return nil, fmt.Errorf("%w: item %d", errChecksumMismatch, item)
+		}
+	}
 	t.lock.RUnlock()
 	t.readMeter.Mark(int64(len(blob) + 2*indexEntrySize))
+	t.readTimer.UpdateSince(start)
 
 	if t.noCompression {
 		return blob, nil
@@ -623,13 +843,32 @@ func (t *freezerTable) sizeNolock() (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
-	total := uint64(t.maxFileSize)*uint64(t.headId-t.tailId) + uint64(t.headBytes) + uint64(stat.Size())
-	return total, nil
+	crcStat, err := t.crcs.Stat()
+	if err != nil {
+		return 0, err
+	}
+	total := uint64(t.maxFileSize)*uint64(t.headId-t.tailId) + uint64(t.headBytes) + uint64(stat.Size()) + uint64(crcStat.Size())
+	return total + uint64(len(t.indexBuf)+len(t.crcBuf)), nil
 }
 
 // Sync pushes any pending data from memory out to disk. This is an expensive
 // operation, so use it with care.
 func (t *freezerTable) Sync() error {
+	t.lock.Lock()
+	err := t.flush()
+	t.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil || t.head == nil {
+		return errClosed
+	}
+	if err := t.crcs.Sync(); err != nil {
+		return err
+	}
 	if err := t.index.Sync(); err != nil {
 		return err
 	}
@@ -18,7 +18,9 @@ package rawdb
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -599,6 +601,7 @@ func TestOffset(t *testing.T) {
 				t.Fatal("expected err")
 			}
 		}
+		f.Close()
 	}
 	checkPresent(4)
 	// Now, let's pretend we have deleted 1M items
@@ -630,6 +633,106 @@ func TestOffset(t *testing.T) {
 	checkPresent(1000000)
 }
 
+// TestFreezerChecksums tests that corrupted items are detected by their checksums,
+// and that tables written before checksums remain readable.
+func TestFreezerChecksums(t *testing.T) {
+	t.Parallel()
+	var (
+		fname      = fmt.Sprintf("checksums-%d", rand.Uint64())
+		rm, wm, sg = metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge()
+	)
+	f, err := newCustomTable(os.TempDir(), fname, rm, wm, sg, 100, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := 0; x < 10; x++ {
+		f.Append(uint64(x), getChunk(20, x))
+	}
+	// Buffered items can be read before being written out
+	if got, err := f.Retrieve(9); err != nil || !bytes.Equal(got, getChunk(20, 9)) {
+		t.Fatalf("buffered item: got %x, err %v", got, err)
+	}
+	f.Close()
+
+	// Flip a bit of item 6, which is the second item of the second data file
+	p := filepath.Join(os.TempDir(), fmt.Sprintf("%s.0001.rdat", fname))
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[25] ^= 0x01
+	if err := ioutil.WriteFile(p, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err = newCustomTable(os.TempDir(), fname, rm, wm, sg, 100, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Retrieve(6); !errors.Is(err, errChecksumMismatch) {
+		t.Fatalf("corrupted item: have error %v, want %v", err, errChecksumMismatch)
+	}
+	if _, err := f.Retrieve(5); err != nil {
+		t.Fatalf("intact item: %v", err)
+	}
+	f.Close()
+
+	// Drop the checksums as if written by an older version, the existing items
+	// aren't verified any more but new ones are
+	if err := os.Remove(filepath.Join(os.TempDir(), fmt.Sprintf("%s.rcrc", fname))); err != nil {
+		t.Fatal(err)
+	}
+	f, err = newCustomTable(os.TempDir(), fname, rm, wm, sg, 100, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.Retrieve(6); err != nil {
+		t.Fatalf("item without checksum: %v", err)
+	}
+	f.Append(10, getChunk(20, 10))
+	if err := f.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if f.crcStart != 10 {
+		t.Fatalf("wrong first checksummed item: have %d, want %d", f.crcStart, 10)
+	}
+	if got, err := f.Retrieve(10); err != nil || !bytes.Equal(got, getChunk(20, 10)) {
+		t.Fatalf("new item: got %x, err %v", got, err)
+	}
+}
+
+// TestFreezerInstrument tests that the per-table metrics are registered under the
+// table name and that an instrumented table keeps working.
+func TestFreezerInstrument(t *testing.T) {
+	t.Parallel()
+	fname := fmt.Sprintf("instrument-%d", rand.Uint64())
+	f, err := newCustomTable(os.TempDir(), fname, metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge(), 50, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	registry := metrics.NewRegistry()
+	f.instrument("ancient/", registry)
+	for _, name := range []string{"read", "write", "index", "files"} {
+		if registry.Get("ancient/"+fname+"/"+name) == nil {
+			t.Errorf("metric %q not registered", name)
+		}
+	}
+	for x := 0; x < 10; x++ {
+		f.Append(uint64(x), getChunk(15, x))
+	}
+	if err := f.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	for x := 0; x < 10; x++ {
+		if got, err := f.Retrieve(uint64(x)); err != nil || !bytes.Equal(got, getChunk(15, x)) {
+			t.Fatalf("item %d: got %x, err %v", x, got, err)
+		}
+	}
+}
+
 // TODO (?)
 // - test that if we remove several head-files, aswell as data last data-file,
 //   the index is truncated accordingly
@@ -0,0 +1,116 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+const (
+	// freezerTuneStep is the number of blocks by which the tuner moves the
+	// freezer threshold in a single adjustment.
+	freezerTuneStep = 10000
+
+	// freezerTuneMinReads is the number of ancient reads needed within a tuning
+	// round to consider the measurements representative.
+	freezerTuneMinReads = 100
+
+	// freezerSlowRead is the average ancient read latency above which the disk
+	// is considered slow, making it worthwhile to serve recent blocks from the
+	// cached key-value store instead.
+	freezerSlowRead = 2 * time.Millisecond
+
+	// freezerRecentRatio is the fraction of the ancient reads that need to hit
+	// just past the threshold for it to be grown.
+	freezerRecentRatio = 0.1
+)
+
+// freezerTuner adjusts the freezer threshold based on the ancient reads done
+// since the last adjustment. If the disk is slow and a significant part of the
+// reads target blocks which were frozen only recently, the threshold is grown
+// to retain more of the recent chain in the key-value store. If the reads are
+// fast or hardly ever touch the recent chain, it is shrunk back to avoid bloating
+// the key-value store.
+type freezerTuner struct {
+	max uint64 // Maximum threshold the tuner may pick
+
+	// Statistics of the current tuning round (atomic access)
+	reads   uint64 // Number of ancient reads
+	recent  uint64 // Number of ancient reads within one step past the threshold
+	latency uint64 // Accumulated ancient read latency in nanoseconds
+	border  uint64 // Lowest block number considered recent (0 = unknown yet)
+}
+
+// newFreezerTuner creates a threshold tuner limited to the given maximum.
+func newFreezerTuner(max uint64) *freezerTuner {
+	if max < params.FullImmutabilityThreshold {
+		max = params.FullImmutabilityThreshold
+	}
+	return &freezerTuner{max: max}
+}
+
+// record accounts an ancient read of the given block number started at start.
+func (t *freezerTuner) record(number uint64, start time.Time) {
+	atomic.AddUint64(&t.reads, 1)
+	atomic.AddUint64(&t.latency, uint64(time.Since(start)))
+	if border := atomic.LoadUint64(&t.border); border != 0 && number >= border {
+		atomic.AddUint64(&t.recent, 1)
+	}
+}
+
+// tune evaluates the reads since the last call and returns the new threshold
+// to use with the given chain head.
+func (t *freezerTuner) tune(head uint64, threshold uint64) uint64 {
+	var (
+		reads   = atomic.SwapUint64(&t.reads, 0)
+		recent  = atomic.SwapUint64(&t.recent, 0)
+		latency = atomic.SwapUint64(&t.latency, 0)
+	)
+	updated := threshold
+	if reads >= freezerTuneMinReads {
+		avg := time.Duration(latency / reads)
+		switch {
+		case avg >= freezerSlowRead && float64(recent) >= float64(reads)*freezerRecentRatio:
+			updated = threshold + freezerTuneStep
+		case avg < freezerSlowRead || recent == 0:
+			if threshold > freezerTuneStep {
+				updated = threshold - freezerTuneStep
+			}
+		}
+		if updated > t.max {
+			updated = t.max
+		}
+		if updated < params.FullImmutabilityThreshold {
+			updated = params.FullImmutabilityThreshold
+		}
+		if updated != threshold {
+			log.Info("Tuned freezer threshold", "old", threshold, "new", updated, "reads", reads, "recent", recent, "latency", common.PrettyDuration(avg))
+		}
+	}
+	// Blocks within one step past the new threshold would be retained by growing it
+	if head > updated+freezerTuneStep {
+		atomic.StoreUint64(&t.border, head-updated-freezerTuneStep)
+	} else {
+		atomic.StoreUint64(&t.border, 0)
+	}
+	return updated
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// Tests that the freezer threshold tuner grows the threshold on slow reads of
+// the recent chain and shrinks it back once the reads become fast.
+func TestFreezerTuner(t *testing.T) {
+	var (
+		head      = uint64(1000000)
+		threshold = uint64(params.FullImmutabilityThreshold)
+		tuner     = newFreezerTuner(threshold + freezerTuneStep)
+	)
+	// The initial round has no reads, so only the recent border is set up
+	if updated := tuner.tune(head, threshold); updated != threshold {
+		t.Fatalf("threshold changed without reads: have %d, want %d", updated, threshold)
+	}
+	recent := head - threshold - freezerTuneStep/2
+	slow := time.Now().Add(-2 * freezerSlowRead)
+
+	// Slow reads of recently frozen blocks should grow the threshold
+	for i := 0; i < freezerTuneMinReads; i++ {
+		tuner.record(recent, slow)
+	}
+	if threshold = tuner.tune(head, threshold); threshold != params.FullImmutabilityThreshold+freezerTuneStep {
+		t.Fatalf("threshold not grown on slow reads: have %d", threshold)
+	}
+	// The threshold should not be grown beyond the maximum
+	for i := 0; i < freezerTuneMinReads; i++ {
+		tuner.record(recent, slow)
+	}
+	if threshold = tuner.tune(head, threshold); threshold != params.FullImmutabilityThreshold+freezerTuneStep {
+		t.Fatalf("threshold grown beyond maximum: have %d", threshold)
+	}
+	// Slow reads of the deep chain only should shrink it
+	for i := 0; i < freezerTuneMinReads; i++ {
+		tuner.record(1, slow)
+	}
+	if threshold = tuner.tune(head, threshold); threshold != params.FullImmutabilityThreshold {
+		t.Fatalf("threshold not shrunk on deep reads: have %d", threshold)
+	}
+	// Fast reads should never shrink it below the immutability threshold
+	for i := 0; i < freezerTuneMinReads; i++ {
+		tuner.record(recent, time.Now())
+	}
+	if threshold = tuner.tune(head, threshold); threshold != params.FullImmutabilityThreshold {
+		t.Fatalf("threshold shrunk below immutability threshold: have %d", threshold)
+	}
+}
+
+// Tests that the freezer threshold can be changed, but not below the
+// immutability threshold.
+func TestFreezerThreshold(t *testing.T) {
+	frdir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temp freezer dir: %v", err)
+	}
+	defer os.RemoveAll(frdir)
+
+	db, err := NewDatabaseWithFreezer(NewMemoryDatabase(), frdir, "")
+	if err != nil {
+		t.Fatalf("failed to create database with ancient backend: %v", err)
+	}
+	defer db.Close()
+
+	if threshold, err := db.AncientThreshold(); err != nil || threshold != params.FullImmutabilityThreshold {
+		t.Fatalf("default threshold mismatch: have %d (%v), want %d", threshold, err, params.FullImmutabilityThreshold)
+	}
+	if err := db.SetAncientThreshold(params.FullImmutabilityThreshold - 1); err == nil {
+		t.Fatalf("threshold below immutability threshold accepted")
+	}
+	if err := db.SetAncientThreshold(2 * params.FullImmutabilityThreshold); err != nil {
+		t.Fatalf("failed to set threshold: %v", err)
+	}
+	if threshold, _ := db.AncientThreshold(); threshold != 2*params.FullImmutabilityThreshold {
+		t.Fatalf("threshold mismatch: have %d, want %d", threshold, 2*params.FullImmutabilityThreshold)
+	}
+	if _, err := NewMemoryDatabase().AncientThreshold(); err != errNotSupported {
+		t.Fatalf("threshold of freezerless database: have error %v, want %v", err, errNotSupported)
+	}
+}
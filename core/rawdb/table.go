@@ -92,6 +92,24 @@ func (t *table) Sync() error {
 	return t.db.Sync()
 }
 
+// AncientThreshold is a noop passthrough that just forwards the request to the
+// underlying database.
+func (t *table) AncientThreshold() (uint64, error) {
+	return t.db.AncientThreshold()
+}
+
+// SetAncientThreshold is a noop passthrough that just forwards the request to the
+// underlying database.
+func (t *table) SetAncientThreshold(threshold uint64) error {
+	return t.db.SetAncientThreshold(threshold)
+}
+
+// TuneAncientThreshold is a noop passthrough that just forwards the request to the
+// underlying database.
+func (t *table) TuneAncientThreshold(max uint64) error {
+	return t.db.TuneAncientThreshold(max)
+}
+
 // Put inserts the given value into the database at a prefixed version of the
 // provided key.
 func (t *table) Put(key []byte, value []byte) error {
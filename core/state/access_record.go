@@ -0,0 +1,200 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+)
+
+// accountField is a part of an account tracked separately for conflicts, so that
+// e.g. a value transfer to a contract doesn't conflict with a call to it.
+type accountField uint8
+
+const (
+	fieldExist   accountField = iota // Existence, emptiness and suicide status
+	fieldBalance                     // Account balance
+	fieldNonce                       // Account nonce
+	fieldCode                        // Contract code
+	fieldStorage                     // Entire storage, written when it's wiped
+)
+
+// accountKey identifies a tracked part of an account.
+type accountKey struct {
+	addr  common.Address
+	field accountField
+}
+
+// slotKey identifies a storage slot of an account.
+type slotKey struct {
+	addr common.Address
+	slot common.Hash
+}
+
+// AccessRecord is the record of the state read and modified while executing a
+// transaction. It's used to detect conflicts between transactions executed
+// speculatively in parallel, and to apply the modifications of a transaction
+// free of conflicts to another state.
+//
+// Balance changes are tracked as writes only, since additions and subtractions
+// commute. This avoids every transaction conflicting on the coinbase fee payment.
+// Any code depending on a balance reads it explicitly first.
+type AccessRecord struct {
+	accountReads  map[accountKey]struct{}
+	slotReads     map[slotKey]struct{}
+	accountWrites map[accountKey]struct{}
+	slotWrites    map[slotKey]struct{}
+
+	ops       []func(*StateDB) // Modifications to replay, in order
+	revisions map[int]int      // Number of modifications at each snapshot revision
+}
+
+// NewAccessRecord creates an empty access record.
+func NewAccessRecord() *AccessRecord {
+	return &AccessRecord{
+		accountReads:  make(map[accountKey]struct{}),
+		slotReads:     make(map[slotKey]struct{}),
+		accountWrites: make(map[accountKey]struct{}),
+		slotWrites:    make(map[slotKey]struct{}),
+		revisions:     make(map[int]int),
+	}
+}
+
+// Conflicts reports whgdtuer any state read by the recorded transaction was
+// written in the given record, i.e. by a transaction preceding it.
+func (r *AccessRecord) Conflicts(writes *AccessRecord) bool {
+	for key := range r.accountReads {
+		if _, ok := writes.accountWrites[key]; ok {
+			return true
+		}
+	}
+	for key := range r.slotReads {
+		if _, ok := writes.slotWrites[key]; ok {
+			return true
+		}
+		if _, ok := writes.accountWrites[accountKey{key.addr, fieldStorage}]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeWrites adds the state written in the given record to this one.
+func (r *AccessRecord) MergeWrites(other *AccessRecord) {
+	for key := range other.accountWrites {
+		r.accountWrites[key] = struct{}{}
+	}
+	for key := range other.slotWrites {
+		r.slotWrites[key] = struct{}{}
+	}
+}
+
+// Apply replays the recorded modifications onto the given state.
+func (r *AccessRecord) Apply(s *StateDB) {
+	for _, op := range r.ops {
+		op(s)
+	}
+}
+
+// readAccount records a read of the given account fields.
+func (r *AccessRecord) readAccount(addr common.Address, fields ...accountField) {
+	for _, field := range fields {
+		r.accountReads[accountKey{addr, field}] = struct{}{}
+	}
+}
+
+// readSlot records a read of the given storage slot.
+func (r *AccessRecord) readSlot(addr common.Address, slot common.Hash) {
+	r.slotReads[slotKey{addr, slot}] = struct{}{}
+}
+
+// writeAccount records a write of the given account fields.
+func (r *AccessRecord) writeAccount(addr common.Address, fields ...accountField) {
+	for _, field := range fields {
+		r.accountWrites[accountKey{addr, field}] = struct{}{}
+	}
+}
+
+// writeSlot records a write of the given storage slot.
+func (r *AccessRecord) writeSlot(addr common.Address, slot common.Hash) {
+	r.slotWrites[slotKey{addr, slot}] = struct{}{}
+}
+
+// record appends a modification to replay.
+func (r *AccessRecord) record(op func(*StateDB)) {
+	r.ops = append(r.ops, op)
+}
+
+// snapshot marks the number of modifications at a new revision.
+func (r *AccessRecord) snapshot(revid int) {
+	r.revisions[revid] = len(r.ops)
+}
+
+// revert drops the modifications made since the given revision. The reads and
+// writes are retained, which only makes the conflict detection conservative.
+func (r *AccessRecord) revert(revid int) {
+	if n, ok := r.revisions[revid]; ok {
+		r.ops = r.ops[:n]
+	}
+}
+
+// recordBalance records a balance change of the given account. Creating an account
+// or touching an empty one changes its existence too.
+func (s *StateDB) recordBalance(addr common.Address, op func(*StateDB)) {
+	if obj := s.getStateObject(addr); obj == nil || obj.empty() {
+		s.recorder.writeAccount(addr, fieldExist)
+	}
+	s.recorder.writeAccount(addr, fieldBalance)
+	s.recorder.record(op)
+}
+
+// recordCreation records the creation of the given account if it doesn't exist.
+func (s *StateDB) recordCreation(addr common.Address) {
+	if s.getStateObject(addr) == nil {
+		s.recorder.writeAccount(addr, fieldExist)
+	}
+}
+
+// copyLog creates a copy of the consensus fields of a log, for adding it to
+// another state.
+func copyLog(log *types.Log) *types.Log {
+	return &types.Log{
+		Address:     log.Address,
+		Topics:      append([]common.Hash(nil), log.Topics...),
+		Data:        common.CopyBytes(log.Data),
+		BlockNumber: log.BlockNumber,
+	}
+}
+
+// copyAmount copies a balance change, the caller may reuse the original.
+func copyAmount(amount *big.Int) *big.Int {
+	return new(big.Int).Set(amount)
+}
+
+// StartAccessRecord starts recording the state accessed and modified, returning
+// the record which is filled in until StopAccessRecord is called.
+func (s *StateDB) StartAccessRecord() *AccessRecord {
+	s.recorder = NewAccessRecord()
+	return s.recorder
+}
+
+// StopAccessRecord stops recording the state accesses.
+func (s *StateDB) StopAccessRecord() {
+	s.recorder = nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+)
+
+// Tests that the access record detects read/write conflicts, treats balance
+// changes as commutative and drops reverted modifications.
+func TestAccessRecordConflicts(t *testing.T) {
+	var (
+		alice    = common.Address{0x01}
+		bob      = common.Address{0x02}
+		contract = common.Address{0x03}
+		slot     = common.Hash{0x01}
+	)
+	base, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	base.SetBalance(alice, big.NewInt(100))
+	base.SetBalance(bob, big.NewInt(100))
+	base.SetCode(contract, []byte{0x00})
+	base.IntermediateRoot(true)
+
+	// First transaction credits bob and writes a slot of the contract
+	first := base.Copy()
+	writes := first.StartAccessRecord()
+	first.GetBalance(alice)
+	first.SubBalance(alice, big.NewInt(10))
+	first.AddBalance(bob, big.NewInt(10))
+	first.SetState(contract, slot, common.Hash{0xff})
+
+	// Second transaction credits bob too and calls the contract, reading another slot
+	second := base.Copy()
+	reads := second.StartAccessRecord()
+	second.AddBalance(bob, big.NewInt(5))
+	second.GetCode(contract)
+	second.GetState(contract, common.Hash{0x02})
+
+	id := second.Snapshot()
+	second.SetState(contract, common.Hash{0x02}, common.Hash{0x01})
+	second.RevertToSnapshot(id)
+
+	if reads.Conflicts(writes) {
+		t.Fatalf("independent transactions conflict")
+	}
+	// Replaying the second on top of the first should merge the balance changes,
+	// without the reverted slot write
+	reads.Apply(first)
+	if balance := first.GetBalance(bob); balance.Cmp(big.NewInt(115)) != 0 {
+		t.Errorf("merged balance mismatch: have %v, want 115", balance)
+	}
+	if value := first.GetState(contract, common.Hash{0x02}); value != (common.Hash{}) {
+		t.Errorf("reverted slot write replayed: %x", value)
+	}
+	// Reading the written slot or balance should conflict
+	third := base.Copy()
+	record := third.StartAccessRecord()
+	third.GetState(contract, slot)
+	if !record.Conflicts(writes) {
+		t.Errorf("slot read of written slot doesn't conflict")
+	}
+	fourth := base.Copy()
+	record = fourth.StartAccessRecord()
+	fourth.GetBalance(bob)
+	if !record.Conflicts(writes) {
+		t.Errorf("balance read of credited account doesn't conflict")
+	}
+	// Any slot read should conflict with a storage wipe
+	fifth := base.Copy()
+	wipes := fifth.StartAccessRecord()
+	fifth.Suicide(contract)
+
+	record = NewAccessRecord()
+	record.readSlot(contract, common.Hash{0x42})
+	if !record.Conflicts(wipes) {
+		t.Errorf("slot read of wiped storage doesn't conflict")
+	}
+}
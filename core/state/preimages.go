@@ -0,0 +1,119 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"io"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/gdtudb"
+	"github.com/c88032111/go-gdtu/rlp"
+	"github.com/c88032111/go-gdtu/trie"
+)
+
+// preimageBatchSize is the number of preimages imported in one database batch.
+const preimageBatchSize = 1024
+
+// ExportPreimages writes all the preimages stored in the database to w, as a
+// stream of RLP encoded byte strings. The number of preimages written is
+// returned.
+func ExportPreimages(db gdtudb.Iteratee, w io.Writer) (uint64, error) {
+	it := rawdb.IteratePreimages(db)
+	defer it.Release()
+
+	var count uint64
+	for it.Next() {
+		if err := rlp.Encode(w, it.Value()); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, it.Error()
+}
+
+// ImportPreimages reads a stream of RLP encoded preimages, as written by
+// ExportPreimages, storing them into the database. The number of preimages
+// imported is returned.
+func ImportPreimages(db gdtudb.Database, r io.Reader) (uint64, error) {
+	var (
+		stream    = rlp.NewStream(r, 0)
+		preimages = make(map[common.Hash][]byte)
+		count     uint64
+	)
+	for {
+		var blob []byte
+		if err := stream.Decode(&blob); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return count, err
+		}
+		preimages[crypto.Keccak256Hash(blob)] = blob
+		if len(preimages) >= preimageBatchSize {
+			rawdb.WritePreimages(db, preimages)
+			count += uint64(len(preimages))
+			preimages = make(map[common.Hash][]byte)
+		}
+	}
+	if len(preimages) > 0 {
+		rawdb.WritePreimages(db, preimages)
+		count += uint64(len(preimages))
+	}
+	return count, nil
+}
+
+// CheckPreimages verifies that the preimages of the account and storage keys of
+// a state are known, checking at most limit keys in trie order. The number of
+// keys checked and of those missing a preimage are returned.
+func CheckPreimages(db Database, root common.Hash, limit int) (checked int, missing int, err error) {
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		return 0, 0, err
+	}
+	check := func(tr Trie, key []byte) {
+		checked++
+		if tr.GetKey(key) == nil {
+			missing++
+		}
+	}
+	accounts := trie.NewIterator(tr.NodeIterator(nil))
+	for checked < limit && accounts.Next() {
+		check(tr, accounts.Key)
+
+		var account Account
+		if err := rlp.DecodeBytes(accounts.Value, &account); err != nil {
+			return checked, missing, err
+		}
+		if account.Root == emptyRoot {
+			continue
+		}
+		storage, err := db.OpenStorageTrie(common.BytesToHash(accounts.Key), account.Root)
+		if err != nil {
+			return checked, missing, err
+		}
+		slots := trie.NewIterator(storage.NodeIterator(nil))
+		for checked < limit && slots.Next() {
+			check(storage, slots.Key)
+		}
+		if slots.Err != nil {
+			return checked, missing, slots.Err
+		}
+	}
+	return checked, missing, accounts.Err
+}
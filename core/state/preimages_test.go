@@ -0,0 +1,76 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/crypto"
+)
+
+func TestPreimageExportImport(t *testing.T) {
+	// Create a state with a few accounts and storage slots, recording preimages
+	diskdb := rawdb.NewMemoryDatabase()
+	sdb := NewDatabase(diskdb)
+	statedb, _ := New(common.Hash{}, sdb, nil)
+	for i := byte(0); i < 10; i++ {
+		addr := common.Address{0xde, 0xad, i}
+		statedb.SetBalance(addr, big.NewInt(int64(i)+1))
+		statedb.SetState(addr, common.Hash{i}, common.Hash{0x01})
+	}
+	root, _ := statedb.Commit(false)
+	if err := sdb.TrieDB().Commit(root, false, nil); err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if checked, missing, err := CheckPreimages(sdb, root, 100); err != nil || checked != 20 || missing != 0 {
+		t.Fatalf("check of complete state: checked %d, missing %d, err %v", checked, missing, err)
+	}
+	// Export the preimages and copy the state without them to another database
+	buf := new(bytes.Buffer)
+	exported, err := ExportPreimages(diskdb, buf)
+	if err != nil || exported != 20 {
+		t.Fatalf("export failed: exported %d, err %v", exported, err)
+	}
+	copydb := rawdb.NewMemoryDatabase()
+	it := diskdb.NewIterator(nil, nil)
+	for it.Next() {
+		if !bytes.HasPrefix(it.Key(), []byte("secure-key-")) {
+			copydb.Put(it.Key(), it.Value())
+		}
+	}
+	it.Release()
+
+	if checked, missing, err := CheckPreimages(NewDatabase(copydb), root, 5); err != nil || checked != 5 || missing != 5 {
+		t.Fatalf("check of state without preimages: checked %d, missing %d, err %v", checked, missing, err)
+	}
+	// Import the preimages and check they're complete again
+	imported, err := ImportPreimages(copydb, buf)
+	if err != nil || imported != 20 {
+		t.Fatalf("import failed: imported %d, err %v", imported, err)
+	}
+	if checked, missing, err := CheckPreimages(NewDatabase(copydb), root, 100); err != nil || checked != 20 || missing != 0 {
+		t.Fatalf("check of imported state: checked %d, missing %d, err %v", checked, missing, err)
+	}
+	addr := common.Address{0xde, 0xad, 0x03}
+	if preimage := rawdb.ReadPreimage(copydb, crypto.Keccak256Hash(addr.Bytes())); !bytes.Equal(preimage, addr.Bytes()) {
+		t.Fatalf("wrong imported preimage: have %x, want %x", preimage, addr)
+	}
+}
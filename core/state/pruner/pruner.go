@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
@@ -54,6 +55,12 @@ const (
 	// triggering range compaction. It's a quite arbitrary number but just
 	// to avoid triggering range compaction because of small deletion.
 	rangeCompactionThreshold = 100000
+
+	// estimateSamples is the number of random positions in the key space sampled
+	// for estimating the reclaimable state data, each sample covering at most
+	// estimateSampleSize trie nodes.
+	estimateSamples    = 64
+	estimateSampleSize = 1024
 )
 
 var (
@@ -86,7 +93,7 @@ type Pruner struct {
 
 // NewPruner creates the pruner instance.
 func NewPruner(db gdtudb.Database, headHeader *types.Header, datadir, trieCachePath string, bloomSize uint64) (*Pruner, error) {
-	snaptree, err := snapshot.New(db, trie.NewDatabase(db), 256, headHeader.Root, false, false, false)
+	snaptree, err := snapshot.New(db, trie.NewDatabase(db), 256, headHeader.Root, false, false, false, nil, nil)
 	if err != nil {
 		return nil, err // The relevant snapshot(s) might not exist
 	}
@@ -109,7 +116,126 @@ func NewPruner(db gdtudb.Database, headHeader *types.Header, datadir, trieCacheP
 	}, nil
 }
 
-func prune(maindb gdtudb.Database, stateBloom *stateBloom, middleStateRoots map[common.Hash]struct{}, start time.Time) error {
+// pruningProgress is the position of an interrupted pruning, persisted together
+// with every batch of deletions so that the pruning can be resumed from there.
+type pruningProgress struct {
+	Root   common.Hash // Target state root of the pruning
+	Marker []byte      // Last key processed before the interruption
+}
+
+// readPruningMarker retrieves the position from where a pruning towards the
+// given state root should resume, or nil if it should start from scratch.
+func readPruningMarker(db gdtudb.KeyValueReader, root common.Hash) []byte {
+	blob := rawdb.ReadStatePruningProgress(db)
+	if len(blob) == 0 {
+		return nil
+	}
+	var progress pruningProgress
+	if err := rlp.DecodeBytes(blob, &progress); err != nil || progress.Root != root {
+		return nil
+	}
+	return progress.Marker
+}
+
+// writePruningMarker stores the position of the pruning into the given batch.
+func writePruningMarker(db gdtudb.KeyValueWriter, root common.Hash, marker []byte) {
+	blob, err := rlp.EncodeToBytes(&pruningProgress{Root: root, Marker: marker})
+	if err != nil {
+		panic(err) // Cannot happen, here to catch dev errors
+	}
+	rawdb.WriteStatePruningProgress(db, blob)
+}
+
+// keyPosition returns the position of a key in the key space, approximated by
+// its first 8 bytes.
+func keyPosition(key []byte) uint64 {
+	var pos [8]byte
+	copy(pos[:], key)
+	return binary.BigEndian.Uint64(pos[:])
+}
+
+// keyProgress returns the position of a key in the key space as a percentage.
+func keyProgress(key []byte) float64 {
+	return float64(keyPosition(key)) / math.MaxUint64 * 100
+}
+
+// isPrunable checks whgdtuer a database entry is a state entry (trie node or
+// contract code) not belgdtuing to the retained states, returning the key to
+// check in the bloom filter as well.
+func isPrunable(key []byte, stateBloom *stateBloom, middleStateRoots map[common.Hash]struct{}) (bool, error) {
+	isCode, codeKey := rawdb.IsCodeKey(key)
+	if len(key) != common.HashLength && !isCode {
+		return false, nil
+	}
+	checkKey := key
+	if isCode {
+		checkKey = codeKey
+	}
+	if _, exist := middleStateRoots[common.BytesToHash(checkKey)]; exist {
+		log.Debug("Forcibly delete the middle state roots", "hash", common.BytesToHash(checkKey))
+		return true, nil
+	}
+	ok, err := stateBloom.Contain(checkKey)
+	if err != nil {
+		return false, err
+	}
+	return !ok, nil
+}
+
+// estimatePrunable estimates the total size of the trie nodes in the database
+// and the part of it to be pruned. Trie node keys are uniformly distributed, so
+// the density of a few randomly positioned samples extrapolates to the entire
+// key space.
+func estimatePrunable(maindb gdtudb.Database, stateBloom *stateBloom, middleStateRoots map[common.Hash]struct{}) (total, prunable common.StorageSize, err error) {
+	var (
+		span    float64 // Portion of the key space covered by the samples
+		sampled common.StorageSize
+		pruned  common.StorageSize
+	)
+	for i := 0; i < estimateSamples; i++ {
+		start := make([]byte, common.HashLength)
+		rand.Read(start)
+
+		var (
+			iter  = maindb.NewIterator(nil, start)
+			first []byte
+			last  []byte
+			nodes int
+		)
+		for steps := 0; iter.Next() && nodes < estimateSampleSize && steps < 16*estimateSampleSize; steps++ {
+			key := iter.Key()
+			if len(key) != common.HashLength {
+				continue
+			}
+			if first == nil {
+				first = common.CopyBytes(key)
+			}
+			last = common.CopyBytes(key)
+			nodes++
+
+			size := common.StorageSize(len(key) + len(iter.Value()))
+			sampled += size
+			ok, err := isPrunable(key, stateBloom, middleStateRoots)
+			if err != nil {
+				iter.Release()
+				return 0, 0, err
+			}
+			if ok {
+				pruned += size
+			}
+		}
+		iter.Release()
+		if first != nil {
+			span += float64(keyPosition(last)-keyPosition(first)) / math.MaxUint64
+		}
+	}
+	if span <= 0 {
+		return sampled, pruned, nil // Tiny database, everything sampled
+	}
+	return common.StorageSize(float64(sampled) / span), common.StorageSize(float64(pruned) / span), nil
+}
+
+func prune(maindb gdtudb.Database, stateBloom *stateBloom, root common.Hash, middleStateRoots map[common.Hash]struct{}, start time.Time) error {
 	// Delete all stale trie nodes in the disk. With the help of state bloom
 	// the trie nodes(and codes) belgdtu to the active state will be filtered
 	// out. A very small part of stale tries will also be filtered because of
@@ -117,14 +243,22 @@ func prune(maindb gdtudb.Database, stateBloom *stateBloom, middleStateRoots map[
 	// that the false-positive is low enough(~0.05%). The probablity of the
 	// dangling node is the state root is super low. So the dangling nodes in
 	// theory will never ever be visited again.
+	//
+	// The deletion resumes from the persisted marker if a previous run towards
+	// the same target was interrupted.
 	var (
 		count  int
 		size   common.StorageSize
 		pstart = time.Now()
 		logged = time.Now()
+		marker = readPruningMarker(maindb, root)
+		begin  = keyPosition(marker)
 		batch  = maindb.NewBatch()
-		iter   = maindb.NewIterator(nil, nil)
+		iter   = maindb.NewIterator(nil, marker)
 	)
+	if marker != nil {
+		log.Info("Resuming state pruning", "progress", fmt.Sprintf("%.2f%%", keyProgress(marker)))
+	}
 	for iter.Next() {
 		key := iter.Key()
 
@@ -132,41 +266,33 @@ func prune(maindb gdtudb.Database, stateBloom *stateBloom, middleStateRoots map[
 		// - trie node
 		// - legacy contract code
 		// - new-scheme contract code
-		isCode, codeKey := rawdb.IsCodeKey(key)
-		if len(key) == common.HashLength || isCode {
-			checkKey := key
-			if isCode {
-				checkKey = codeKey
-			}
-			if _, exist := middleStateRoots[common.BytesToHash(checkKey)]; exist {
-				log.Debug("Forcibly delete the middle state roots", "hash", common.BytesToHash(checkKey))
-			} else {
-				if ok, err := stateBloom.Contain(checkKey); err != nil {
-					return err
-				} else if ok {
-					continue
-				}
-			}
+		ok, err := isPrunable(key, stateBloom, middleStateRoots)
+		if err != nil {
+			return err
+		}
+		if ok {
 			count += 1
 			size += common.StorageSize(len(key) + len(iter.Value()))
 			batch.Delete(key)
 
 			var eta time.Duration // Realistically will never remain uninited
-			if done := binary.BigEndian.Uint64(key[:8]); done > 0 {
+			if pos := keyPosition(key); pos > begin {
 				var (
-					left  = math.MaxUint64 - binary.BigEndian.Uint64(key[:8])
+					done  = pos - begin
+					left  = math.MaxUint64 - pos
 					speed = done/uint64(time.Since(pstart)/time.Millisecond+1) + 1 // +1s to avoid division by zero
 				)
 				eta = time.Duration(left/speed) * time.Millisecond
 			}
 			if time.Since(logged) > 8*time.Second {
-				log.Info("Pruning state data", "nodes", count, "size", size,
+				log.Info("Pruning state data", "nodes", count, "size", size, "progress", fmt.Sprintf("%.2f%%", keyProgress(key)),
 					"elapsed", common.PrettyDuration(time.Since(pstart)), "eta", common.PrettyDuration(eta))
 				logged = time.Now()
 			}
 			// Recreate the iterator after every batch commit in order
 			// to allow the underlying compactor to delete the entries.
 			if batch.ValueSize() >= gdtudb.IdealBatchSize {
+				writePruningMarker(batch, root, key)
 				batch.Write()
 				batch.Reset()
 
@@ -311,7 +437,14 @@ func (p *Pruner) Prune(root common.Hash) error {
 	}
 	log.Info("State bloom filter committed", "name", filterName)
 
-	if err := prune(p.db, p.stateBloom, middleRoots, start); err != nil {
+	// Sample the database to let the user know how much is about to be reclaimed
+	total, prunable, err := estimatePrunable(p.db, p.stateBloom, middleRoots)
+	if err != nil {
+		return err
+	}
+	log.Info("Estimated reclaimable state data", "trienodes", total, "prunable", prunable)
+
+	if err := prune(p.db, p.stateBloom, root, middleRoots, start); err != nil {
 		return err
 	}
 	// Pruning is done, now drop the "useless" layers from the snapshot.
@@ -331,10 +464,21 @@ func (p *Pruner) Prune(root common.Hash) error {
 	// finished. If any crashes or manual exit happens before this,
 	// `RecoverPruning` will pick it up in the next restarts to redo all
 	// the things.
+	rawdb.DeleteStatePruningProgress(p.db)
 	os.RemoveAll(filterName)
 	return nil
 }
 
+// Interrupted reports whgdtuer an interrupted pruning is pending in the given
+// datadir. It is resumed by the next pruning or node startup.
+func Interrupted(datadir string) (bool, error) {
+	path, _, err := findBloomFilter(datadir)
+	if err != nil {
+		return false, err
+	}
+	return path != "", nil
+}
+
 // RecoverPruning will resume the pruning procedure during the system restart.
 // This function is used in this case: user tries to prune state data, but the
 // system was interrupted midway because of crash or manual-kill. In this case
@@ -362,7 +506,7 @@ func RecoverPruning(datadir string, db gdtudb.Database, trieCachePath string) er
 	// - The state HEAD is rewound already because of multiple incomplete `prune-state`
 	// In this case, even the state HEAD is not exactly matched with snapshot, it
 	// still feasible to recover the pruning correctly.
-	snaptree, err := snapshot.New(db, trie.NewDatabase(db), 256, headHeader.Root, false, false, true)
+	snaptree, err := snapshot.New(db, trie.NewDatabase(db), 256, headHeader.Root, false, false, true, nil, nil)
 	if err != nil {
 		return err // The relevant snapshot(s) might not exist
 	}
@@ -396,7 +540,7 @@ func RecoverPruning(datadir string, db gdtudb.Database, trieCachePath string) er
 		log.Error("Pruning target state is not existent")
 		return errors.New("non-existent target state")
 	}
-	if err := prune(db, stateBloom, middleRoots, time.Now()); err != nil {
+	if err := prune(db, stateBloom, stateBloomRoot, middleRoots, time.Now()); err != nil {
 		return err
 	}
 	// Pruning is done, now drop the "useless" layers from the snapshot.
@@ -416,6 +560,7 @@ func RecoverPruning(datadir string, db gdtudb.Database, trieCachePath string) er
 	// finished. If any crashes or manual exit happens before this,
 	// `RecoverPruning` will pick it up in the next restarts to redo all
 	// the things.
+	rawdb.DeleteStatePruningProgress(db)
 	os.RemoveAll(stateBloomPath)
 	return nil
 }
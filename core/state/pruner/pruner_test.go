@@ -0,0 +1,96 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/gdtudb"
+)
+
+// makePrunableDB creates a database with random trie nodes, half of which are
+// retained by the returned state bloom. The keys are returned in sorted order.
+func makePrunableDB(t *testing.T, n int) (gdtudb.Database, *stateBloom, [][]byte) {
+	db := rawdb.NewMemoryDatabase()
+	bloom, err := newStateBloomWithSize(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = make([]byte, common.HashLength)
+		rand.Read(keys[i])
+		db.Put(keys[i], make([]byte, 100))
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	for i := 0; i < n; i += 2 {
+		bloom.Put(keys[i], nil)
+	}
+	return db, bloom, keys
+}
+
+// Tests that an interrupted pruning resumes from the persisted marker.
+func TestPruneResume(t *testing.T) {
+	db, bloom, keys := makePrunableDB(t, 2000)
+	root := common.Hash{0x01}
+
+	// Pretend the first half was already processed by an interrupted run
+	marker := keys[len(keys)/2]
+	writePruningMarker(db, root, marker)
+
+	if err := prune(db, bloom, root, nil, time.Now()); err != nil {
+		t.Fatalf("pruning failed: %v", err)
+	}
+	for i, key := range keys {
+		has, _ := db.Has(key)
+		switch {
+		case i%2 == 0 && !has:
+			t.Fatalf("retained node %d deleted", i)
+		case i%2 == 1 && i < len(keys)/2 && !has:
+			t.Fatalf("node %d before the marker deleted", i)
+		case i%2 == 1 && i > len(keys)/2 && has:
+			t.Fatalf("stale node %d after the marker not deleted", i)
+		}
+	}
+	// A marker of a different target must be ignored
+	if readPruningMarker(db, common.Hash{0x02}) != nil {
+		t.Error("marker of different pruning target returned")
+	}
+}
+
+// Tests that the reclaimable state data is estimated reasonably.
+func TestEstimatePrunable(t *testing.T) {
+	db, bloom, keys := makePrunableDB(t, 4000)
+
+	total, prunable, err := estimatePrunable(db, bloom, nil)
+	if err != nil {
+		t.Fatalf("estimation failed: %v", err)
+	}
+	exact := common.StorageSize(len(keys) * (common.HashLength + 100))
+	if total < exact*3/4 || total > exact*5/4 {
+		t.Errorf("total size estimate off: have %v, want ~%v", total, exact)
+	}
+	if prunable < exact*3/8 || prunable > exact*5/8 {
+		t.Errorf("prunable size estimate off: have %v, want ~%v", prunable, exact/2)
+	}
+}
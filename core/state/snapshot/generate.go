@@ -94,7 +94,7 @@ func (gs *generatorStats) Log(msg string, root common.Hash, marker []byte) {
 // generateSnapshot regenerates a brand new snapshot based on an existing state
 // database and head block asynchronously. The snapshot is returned immediately
 // and generation is continued in the background until done.
-func generateSnapshot(diskdb gdtudb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash, wiper chan struct{}) *diskLayer {
+func generateSnapshot(diskdb gdtudb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash, wiper chan struct{}, throttle *Throttle) *diskLayer {
 	// Wipe any previously existing snapshot from the database if no wiper is
 	// currently in progress.
 	if wiper == nil {
@@ -119,6 +119,7 @@ func generateSnapshot(diskdb gdtudb.KeyValueStore, triedb *trie.Database, cache
 		genMarker:  genMarker,
 		genPending: make(chan struct{}),
 		genAbort:   make(chan chan *generatorStats),
+		throttle:   throttle,
 	}
 	go base.generate(stats)
 	log.Debug("Start snapshot generation", "root", root)
@@ -241,6 +242,10 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 				dl.genMarker = marker
 				dl.lock.Unlock()
 			}
+			// Give way to block import if it's under pressure
+			if abort == nil {
+				abort = dl.throttle.wait(dl.genAbort)
+			}
 			if abort != nil {
 				stats.Log("Aborting state snapshot generation", dl.root, accountHash[:])
 				abort <- stats
@@ -286,6 +291,9 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 						dl.genMarker = marker
 						dl.lock.Unlock()
 					}
+					if abort == nil {
+						abort = dl.throttle.wait(dl.genAbort)
+					}
 					if abort != nil {
 						stats.Log("Aborting state snapshot generation", dl.root, append(accountHash[:], storeIt.Key...))
 						abort <- stats
@@ -147,7 +147,7 @@ func loadAndParseJournal(db gdtudb.KeyValueStore, base *diskLayer) (snapshot, jo
 }
 
 // loadSnapshot loads a pre-existing state snapshot backed by a key-value store.
-func loadSnapshot(diskdb gdtudb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash, recovery bool) (snapshot, error) {
+func loadSnapshot(diskdb gdtudb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash, recovery bool, throttle *Throttle) (snapshot, error) {
 	// Retrieve the block number and hash of the snapshot, failing if no snapshot
 	// is present in the database (or crashed mid-update).
 	baseRoot := rawdb.ReadSnapshotRoot(diskdb)
@@ -155,10 +155,11 @@ func loadSnapshot(diskdb gdtudb.KeyValueStore, triedb *trie.Database, cache int,
 		return nil, errors.New("missing or corrupted snapshot")
 	}
 	base := &diskLayer{
-		diskdb: diskdb,
-		triedb: triedb,
-		cache:  fastcache.New(cache * 1024 * 1024),
-		root:   baseRoot,
+		diskdb:   diskdb,
+		triedb:   triedb,
+		cache:    fastcache.New(cache * 1024 * 1024),
+		root:     baseRoot,
+		throttle: throttle,
 	}
 	var legacy bool
 	snapshot, generator, err := loadAndParseJournal(diskdb, base)
@@ -0,0 +1,108 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common/mclock"
+)
+
+// schedulerMaxDeferral is the largest multiple of the regular memory allowance
+// the accumulator layer may grow to while blocks are being imported.
+const schedulerMaxDeferral = 8
+
+// Scheduler times the persistence of the accumulated diff layers to the disk
+// layer around block import, so that the disk writes don't add to the import
+// latency of busy periods.
+//
+// While blocks are being imported, the bottom-most diff layer is allowed to grow
+// beyond its regular memory allowance before being flushed. Once no block was
+// imported for the idle period, the accumulated diffs are persisted in the
+// background instead, even if they didn't reach the allowance yet.
+//
+// The aggressiveness scales how eagerly the diffs are persisted: an accumulator
+// larger than 1/aggressiveness of the regular allowance is flushed during idle
+// periods, and it may grow up to schedulerMaxDeferral/aggressiveness times the
+// allowance during import bursts (but never less than the allowance itself).
+type Scheduler struct {
+	idle           time.Duration // Time without block imports after which the chain is idle
+	aggressiveness uint64        // Eagerness of persisting the accumulated diffs
+	clock          mclock.Clock
+
+	lock     sync.Mutex
+	imported mclock.AbsTime // Time of the last reported block import
+}
+
+// NewScheduler creates a diff layer persistence scheduler, considering the chain
+// idle if no block was imported for the given period.
+func NewScheduler(idle time.Duration, aggressiveness int) *Scheduler {
+	return newScheduler(idle, aggressiveness, mclock.System{})
+}
+
+func newScheduler(idle time.Duration, aggressiveness int, clock mclock.Clock) *Scheduler {
+	if aggressiveness < 1 {
+		aggressiveness = 1
+	}
+	return &Scheduler{
+		idle:           idle,
+		aggressiveness: uint64(aggressiveness),
+		clock:          clock,
+		imported:       clock.Now(),
+	}
+}
+
+// ReportImport marks the chain busy importing blocks.
+func (s *Scheduler) ReportImport() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.imported = s.clock.Now()
+}
+
+// Idle reports whgdtuer no block was imported for the idle period.
+func (s *Scheduler) Idle() bool {
+	if s == nil {
+		return false
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return time.Duration(s.clock.Now()-s.imported) >= s.idle
+}
+
+// memoryLimit returns the size above which the accumulator layer is persisted
+// while capping the diff layers after a block import.
+func (s *Scheduler) memoryLimit() uint64 {
+	if s == nil || s.Idle() {
+		return aggregatorMemoryLimit
+	}
+	if s.aggressiveness >= schedulerMaxDeferral {
+		return aggregatorMemoryLimit
+	}
+	return aggregatorMemoryLimit * (schedulerMaxDeferral / s.aggressiveness)
+}
+
+// idleLimit returns the size above which the accumulator layer is persisted
+// while the chain is idle.
+func (s *Scheduler) idleLimit() uint64 {
+	return aggregatorMemoryLimit / s.aggressiveness
+}
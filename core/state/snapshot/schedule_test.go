@@ -0,0 +1,122 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/mclock"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+)
+
+// Tests that the scheduler raises the memory allowance of the accumulator while
+// blocks are imported, and lowers it once the chain is idle.
+func TestSchedulerLimits(t *testing.T) {
+	clock := new(mclock.Simulated)
+	scheduler := newScheduler(5*time.Second, 2, clock)
+
+	scheduler.ReportImport()
+	if scheduler.Idle() {
+		t.Fatalf("importing chain reported idle")
+	}
+	if have, want := scheduler.memoryLimit(), 4*aggregatorMemoryLimit; have != want {
+		t.Fatalf("busy memory limit mismatch: have %d, want %d", have, want)
+	}
+	clock.Run(5 * time.Second)
+	if !scheduler.Idle() {
+		t.Fatalf("idle chain reported busy")
+	}
+	if have, want := scheduler.memoryLimit(), aggregatorMemoryLimit; have != want {
+		t.Fatalf("idle memory limit mismatch: have %d, want %d", have, want)
+	}
+	if have, want := scheduler.idleLimit(), aggregatorMemoryLimit/2; have != want {
+		t.Fatalf("idle flush limit mismatch: have %d, want %d", have, want)
+	}
+	// Very aggressive schedulers should never defer beyond the allowance
+	scheduler = newScheduler(time.Second, 16, clock)
+	scheduler.ReportImport()
+	if have, want := scheduler.memoryLimit(), aggregatorMemoryLimit; have != want {
+		t.Fatalf("aggressive memory limit mismatch: have %d, want %d", have, want)
+	}
+	// A nil scheduler should behave as the fixed allowance
+	var nilScheduler *Scheduler
+	nilScheduler.ReportImport()
+	if nilScheduler.Idle() {
+		t.Fatalf("nil scheduler reported idle")
+	}
+	if have, want := nilScheduler.memoryLimit(), aggregatorMemoryLimit; have != want {
+		t.Fatalf("nil memory limit mismatch: have %d, want %d", have, want)
+	}
+}
+
+// Tests that the accumulator layer is kept in memory while blocks are imported
+// and persisted once the chain becomes idle.
+func TestTreeCompact(t *testing.T) {
+	defer func(memcap uint64) { aggregatorMemoryLimit = memcap }(aggregatorMemoryLimit)
+
+	clock := new(mclock.Simulated)
+	base := &diskLayer{
+		diskdb: rawdb.NewMemoryDatabase(),
+		root:   common.Hash{1},
+		cache:  fastcache.New(1024 * 500),
+	}
+	snaps := &Tree{
+		layers: map[common.Hash]snapshot{
+			base.root: base,
+		},
+		scheduler: newScheduler(time.Second, 2, clock),
+	}
+	for i := byte(2); i <= 4; i++ {
+		accounts := randomAccountSet("gda1", "gda2")
+		if err := snaps.Update(common.Hash{i}, common.Hash{i - 1}, nil, accounts, nil); err != nil {
+			t.Fatalf("failed to create diff layer %d: %v", i, err)
+		}
+	}
+	// Set the allowance below the accumulator size, capping must defer it
+	aggregatorMemoryLimit = snaps.layers[common.Hash{2}].(*diffLayer).memory / 2
+
+	snaps.ReportImport(time.Millisecond)
+	if err := snaps.Cap(common.Hash{4}, 2); err != nil {
+		t.Fatalf("failed to cap snapshot tree: %v", err)
+	}
+	if root := snaps.DiskRoot(); root != base.root {
+		t.Fatalf("accumulator persisted during import: disk root %x", root)
+	}
+	if err := snaps.Compact(common.Hash{4}, 2); err != nil {
+		t.Fatalf("failed to compact snapshot tree: %v", err)
+	}
+	if root := snaps.DiskRoot(); root != base.root {
+		t.Fatalf("accumulator compacted during import: disk root %x", root)
+	}
+	// Once idle, compaction should persist the accumulator
+	clock.Run(time.Second)
+	if err := snaps.Compact(common.Hash{4}, 2); err != nil {
+		t.Fatalf("failed to compact snapshot tree: %v", err)
+	}
+	if root := snaps.DiskRoot(); root != (common.Hash{2}) {
+		t.Fatalf("accumulator not compacted while idle: disk root %x", root)
+	}
+	if n := len(snaps.layers); n != 3 {
+		t.Fatalf("layer count mismatch: have %d, want %d", n, 3)
+	}
+	if snap := snaps.Snapshot(common.Hash{4}); snap == nil || snap.(snapshot).Stale() {
+		t.Fatalf("head layer lost by compaction")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/core/rawdb"
@@ -167,6 +168,9 @@ type Tree struct {
 	cache  int                      // Megabytes permitted to use for read caches
 	layers map[common.Hash]snapshot // Collection of all known layers
 	lock   sync.RWMutex
+
+	throttle  *Throttle  // Generation throttle tied to block import pressure (nil = full speed)
+	scheduler *Scheduler // Diff layer persistence scheduler (nil = fixed memory allowance)
 }
 
 // New attempts to load an already existing snapshot from a persistent key-value
@@ -178,19 +182,26 @@ type Tree struct {
 // store, on a background thread. If the memory layers from the journal is not
 // continuous with disk layer or the journal is missing, all diffs will be discarded
 // iff it's in "recovery" mode, otherwise rebuild is mandatory.
-func New(diskdb gdtudb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash, async bool, rebuild bool, recovery bool) (*Tree, error) {
+//
+// The optional throttle slows down snapshot generation while block import is
+// under pressure, with nil the generator always runs at full speed. The optional
+// scheduler defers persisting the diff layers to the idle periods of the chain,
+// with nil they are persisted as soon as they exceed the memory allowance.
+func New(diskdb gdtudb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash, async bool, rebuild bool, recovery bool, throttle *Throttle, scheduler *Scheduler) (*Tree, error) {
 	// Create a new, empty snapshot tree
 	snap := &Tree{
-		diskdb: diskdb,
-		triedb: triedb,
-		cache:  cache,
-		layers: make(map[common.Hash]snapshot),
+		diskdb:    diskdb,
+		triedb:    triedb,
+		cache:     cache,
+		layers:    make(map[common.Hash]snapshot),
+		throttle:  throttle,
+		scheduler: scheduler,
 	}
 	if !async {
 		defer snap.waitBuild()
 	}
 	// Attempt to load a previously persisted snapshot and rebuild one if failed
-	head, err := loadSnapshot(diskdb, triedb, cache, root, recovery)
+	head, err := loadSnapshot(diskdb, triedb, cache, root, recovery, throttle)
 	if err != nil {
 		if rebuild {
 			log.Warn("Failed to load snapshot, regenerating", "err", err)
@@ -307,6 +318,23 @@ func (t *Tree) Update(blockRoot common.Hash, parentRoot common.Hash, destructs m
 // survival is only known *after* capping, we need to omit it from the count if
 // we want to ensure that *at least* the requested number of diff layers remain.
 func (t *Tree) Cap(root common.Hash, layers int) error {
+	return t.capLimit(root, layers, t.scheduler.memoryLimit())
+}
+
+// Compact persists the accumulator layer below the permitted number of layers
+// into the disk layer if the chain is idle, so that the disk writes are done
+// ahead of the next block imports instead of during them. Without a scheduler,
+// the diff layers are only ever persisted by Cap.
+func (t *Tree) Compact(root common.Hash, layers int) error {
+	if !t.scheduler.Idle() {
+		return nil
+	}
+	return t.capLimit(root, layers, t.scheduler.idleLimit())
+}
+
+// capLimit flattens all layers beyond the permitted number, persisting the
+// accumulator layer if it reaches the given memory limit.
+func (t *Tree) capLimit(root common.Hash, layers int, limit uint64) error {
 	// Retrieve the head snapshot to cap from
 	snap := t.Snapshot(root)
 	if snap == nil {
@@ -340,7 +368,7 @@ func (t *Tree) Cap(root common.Hash, layers int) error {
 		t.layers = map[common.Hash]snapshot{base.root: base}
 		return nil
 	}
-	persisted := t.cap(diff, layers)
+	persisted := t.cap(diff, layers, limit)
 
 	// Remove any layer that is stale or links into a stale layer
 	children := make(map[common.Hash][]common.Hash)
@@ -381,7 +409,7 @@ func (t *Tree) Cap(root common.Hash, layers int) error {
 
 // cap traverses downwards the diff tree until the number of allowed layers are
 // crossed. All diffs beyond the permitted number are flattened downwards. If the
-// layer limit is reached, memory cap is also enforced (but not before).
+// layer limit is reached, the memory limit is also enforced (but not before).
 //
 // The Method returns the new disk layer if diffs were persisted into it.
 //
@@ -390,7 +418,7 @@ func (t *Tree) Cap(root common.Hash, layers int) error {
 // which may or may not overflow and cascade to disk. Since this last layer's
 // survival is only known *after* capping, we need to omit it from the count if
 // we want to ensure that *at least* the requested number of diff layers remain.
-func (t *Tree) cap(diff *diffLayer, layers int) *diskLayer {
+func (t *Tree) cap(diff *diffLayer, layers int, limit uint64) *diskLayer {
 	// Dive until we run out of layers or reach the persistent database
 	for i := 0; i < layers-1; i++ {
 		// If we still have diff layers below, continue down
@@ -417,7 +445,7 @@ func (t *Tree) cap(diff *diffLayer, layers int) *diskLayer {
 		defer diff.lock.Unlock()
 
 		diff.parent = flattened
-		if flattened.memory < aggregatorMemoryLimit {
+		if flattened.memory < limit {
 			// Accumulator layer is smaller than the limit, so we can abort, unless
 			// there's a snapshot being generated currently. In that case, the trie
 			// will move fron underneath the generator so we **must** merge all the
@@ -549,6 +577,7 @@ func diffToDisk(bottom *diffLayer) *diskLayer {
 		triedb:     base.triedb,
 		genMarker:  base.genMarker,
 		genPending: base.genPending,
+		throttle:   base.throttle,
 	}
 	// If snapshot generation hasn't finished yet, port over all the starts and
 	// continue where the previous round left off.
@@ -672,7 +701,7 @@ func (t *Tree) Rebuild(root common.Hash) {
 	// generator will run a wiper first if there's not one running right now.
 	log.Info("Rebuilding state snapshot")
 	t.layers = map[common.Hash]snapshot{
-		root: generateSnapshot(t.diskdb, t.triedb, t.cache, root, wiper),
+		root: generateSnapshot(t.diskdb, t.triedb, t.cache, root, wiper, t.throttle),
 	}
 }
 
@@ -780,6 +809,62 @@ func (t *Tree) generating() (bool, error) {
 	return layer.genMarker != nil, nil
 }
 
+// Generating reports whgdtuer the snapshot is still under construction, in which
+// case it can't yet serve the entire state.
+func (t *Tree) Generating() (bool, error) {
+	return t.generating()
+}
+
+// GeneratorProgress is the state of the snapshot generation, as of the last batch
+// persisted by the generator.
+type GeneratorProgress struct {
+	Root     common.Hash        // State root the snapshot is generated for
+	Done     bool               // Whgdtuer the generation is complete
+	Wiping   bool               // Whgdtuer a previous snapshot is still being wiped
+	Marker   []byte             // Account hash (and storage slot hash) generated up to
+	Accounts uint64             // Number of accounts generated
+	Slots    uint64             // Number of storage slots generated
+	Storage  common.StorageSize // Size of the generated accounts and storage slots
+	Delay    time.Duration      // Current pause between batches due to block import pressure
+}
+
+// GeneratorProgress retrieves the progress of the snapshot generation.
+func (t *Tree) GeneratorProgress() (*GeneratorProgress, error) {
+	t.lock.RLock()
+	root := t.diskRoot()
+	t.lock.RUnlock()
+
+	if root == (common.Hash{}) {
+		return nil, errors.New("disk layer is missing")
+	}
+	blob := rawdb.ReadSnapshotGenerator(t.diskdb)
+	if len(blob) == 0 {
+		return nil, errors.New("generator progress is missing")
+	}
+	var generator journalGenerator
+	if err := rlp.DecodeBytes(blob, &generator); err != nil {
+		return nil, err
+	}
+	return &GeneratorProgress{
+		Root:     root,
+		Done:     generator.Done,
+		Wiping:   generator.Wiping,
+		Marker:   generator.Marker,
+		Accounts: generator.Accounts,
+		Slots:    generator.Slots,
+		Storage:  common.StorageSize(generator.Storage),
+		Delay:    t.throttle.Delay(),
+	}, nil
+}
+
+// ReportImport notifies the snapshot generator about the time it took to import
+// a block, allowing it to slow down while block import is under pressure. It also
+// defers persisting the diff layers until the block imports calm down.
+func (t *Tree) ReportImport(elapsed time.Duration) {
+	t.throttle.ReportImport(elapsed)
+	t.scheduler.ReportImport()
+}
+
 // diskRoot is a external helper function to return the disk layer root.
 func (t *Tree) DiskRoot() common.Hash {
 	t.lock.Lock()
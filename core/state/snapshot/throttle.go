@@ -0,0 +1,121 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common/mclock"
+)
+
+const (
+	// throttleStep is the smallest non-zero pause inserted between two batches
+	// of the generator.
+	throttleStep = 10 * time.Millisecond
+
+	// throttleIdle is the time since the last block import after which the chain
+	// is considered idle and the generator runs at full speed.
+	throttleIdle = 3 * time.Second
+)
+
+// Throttle slows down the snapshot generator while block import is under
+// pressure, so that the two don't compete for the disk.
+//
+// Every block import slower than the threshold doubles the pause inserted after
+// each generator batch (up to a maximum), every faster one halves it. If no block
+// was imported for a while, the generator runs without pauses.
+type Throttle struct {
+	threshold time.Duration // Block import time above which generation is slowed down
+	maxDelay  time.Duration // Maximum pause between two generator batches
+	clock     mclock.Clock
+
+	lock     sync.Mutex
+	delay    time.Duration  // Current pause between two generator batches
+	imported mclock.AbsTime // Time of the last reported block import
+}
+
+// NewThrottle creates a generator throttle slowing down generation if importing
+// a block takes longer than threshold.
+func NewThrottle(threshold, maxDelay time.Duration) *Throttle {
+	return newThrottle(threshold, maxDelay, mclock.System{})
+}
+
+func newThrottle(threshold, maxDelay time.Duration, clock mclock.Clock) *Throttle {
+	return &Throttle{
+		threshold: threshold,
+		maxDelay:  maxDelay,
+		clock:     clock,
+	}
+}
+
+// ReportImport adjusts the generation speed according to the time it took to
+// import a block.
+func (t *Throttle) ReportImport(elapsed time.Duration) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.imported = t.clock.Now()
+	if elapsed > t.threshold {
+		t.delay *= 2
+		if t.delay < throttleStep {
+			t.delay = throttleStep
+		}
+		if t.delay > t.maxDelay {
+			t.delay = t.maxDelay
+		}
+		return
+	}
+	if t.delay /= 2; t.delay < throttleStep {
+		t.delay = 0
+	}
+}
+
+// Delay returns the current pause inserted between two generator batches.
+func (t *Throttle) Delay() time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if time.Duration(t.clock.Now()-t.imported) > throttleIdle {
+		return 0
+	}
+	return t.delay
+}
+
+// wait pauses the generator for the current delay. If an abort request arrives
+// meanwhile, it is returned so the generator can stop right away.
+func (t *Throttle) wait(abort chan chan *generatorStats) chan *generatorStats {
+	delay := t.Delay()
+	if delay == 0 {
+		return nil
+	}
+	timer := t.clock.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case req := <-abort:
+		return req
+	case <-timer.C():
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common/mclock"
+)
+
+// Tests that the generator throttle follows the block import pressure.
+func TestThrottle(t *testing.T) {
+	clock := new(mclock.Simulated)
+	throttle := newThrottle(100*time.Millisecond, 50*time.Millisecond, clock)
+
+	// Slow imports should increase the delay up to the maximum
+	for i, want := range []time.Duration{throttleStep, 2 * throttleStep, 4 * throttleStep, 5 * throttleStep} {
+		throttle.ReportImport(time.Second)
+		if have := throttle.Delay(); have != want {
+			t.Fatalf("slow import %d: delay mismatch: have %v, want %v", i, have, want)
+		}
+	}
+	// Fast imports should decrease it until disabled
+	for i, want := range []time.Duration{25 * time.Millisecond, 12500 * time.Microsecond, 0} {
+		throttle.ReportImport(time.Millisecond)
+		if have := throttle.Delay(); have != want {
+			t.Fatalf("fast import %d: delay mismatch: have %v, want %v", i, have, want)
+		}
+	}
+	// An idle chain should not throttle generation
+	throttle.ReportImport(time.Second)
+	if throttle.Delay() == 0 {
+		t.Fatalf("slow import didn't throttle")
+	}
+	clock.Run(throttleIdle + time.Second)
+	if have := throttle.Delay(); have != 0 {
+		t.Fatalf("idle chain throttled: have %v", have)
+	}
+	// A nil throttle should never pause
+	var nilThrottle *Throttle
+	nilThrottle.ReportImport(time.Second)
+	if have := nilThrottle.Delay(); have != 0 {
+		t.Fatalf("nil throttle paused: have %v", have)
+	}
+}
+
+// Tests that a throttled generator can be aborted while pausing.
+func TestThrottleAbort(t *testing.T) {
+	clock := new(mclock.Simulated)
+	throttle := newThrottle(0, time.Hour, clock)
+	throttle.ReportImport(time.Second)
+
+	var (
+		abort = make(chan chan *generatorStats)
+		req   = make(chan *generatorStats)
+		done  = make(chan chan *generatorStats)
+	)
+	go func() { done <- throttle.wait(abort) }()
+
+	abort <- req
+	if have := <-done; have != req {
+		t.Fatalf("abort request mismatch: have %v, want %v", have, req)
+	}
+	// Without an abort request, the wait should end after the delay
+	go func() { done <- throttle.wait(abort) }()
+	clock.WaitForTimers(1)
+	clock.Run(throttleStep)
+	if have := <-done; have != nil {
+		t.Fatalf("unexpected abort request: %v", have)
+	}
+}
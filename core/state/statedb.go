@@ -99,6 +99,12 @@ type StateDB struct {
 	// Per-transaction access list
 	accessList *accessList
 
+	// Record of the state accesses, if recording
+	recorder *AccessRecord
+
+	// Whether trie hashing is deferred to a later IntermediateRoot call
+	deferHashing bool
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -183,6 +189,10 @@ func (s *StateDB) Error() error {
 }
 
 func (s *StateDB) AddLog(log *types.Log) {
+	if s.recorder != nil {
+		replay := copyLog(log)
+		s.recorder.record(func(s *StateDB) { s.AddLog(copyLog(replay)) })
+	}
 	s.journal.append(addLogChange{txhash: s.thash})
 
 	log.TxHash = s.thash
@@ -193,6 +203,14 @@ func (s *StateDB) AddLog(log *types.Log) {
 	s.logSize++
 }
 
+// ResetLogs discards the logs collected so far and restarts their indexing. It
+// allows reusing the state for processing the next block, which numbers its logs
+// from zero again.
+func (s *StateDB) ResetLogs() {
+	s.logs = make(map[common.Hash][]*types.Log)
+	s.logSize = 0
+}
+
 func (s *StateDB) GetLogs(hash common.Hash) []*types.Log {
 	return s.logs[hash]
 }
@@ -207,6 +225,10 @@ func (s *StateDB) Logs() []*types.Log {
 
 // AddPreimage records a SHA3 preimage seen by the VM.
 func (s *StateDB) AddPreimage(hash common.Hash, preimage []byte) {
+	if s.recorder != nil {
+		replay := common.CopyBytes(preimage)
+		s.recorder.record(func(s *StateDB) { s.AddPreimage(hash, replay) })
+	}
 	if _, ok := s.preimages[hash]; !ok {
 		s.journal.append(addPreimageChange{hash: hash})
 		pi := make([]byte, len(preimage))
@@ -239,18 +261,27 @@ func (s *StateDB) SubRefund(gas uint64) {
 // Exist reports whether the given account address exists in the state.
 // Notably this also returns true for suicided accounts.
 func (s *StateDB) Exist(addr common.Address) bool {
+	if s.recorder != nil {
+		s.recorder.readAccount(addr, fieldExist)
+	}
 	return s.getStateObject(addr) != nil
 }
 
 // Empty returns whether the state object is either non-existent
 // or empty according to the EIP161 specification (balance = nonce = code = 0)
 func (s *StateDB) Empty(addr common.Address) bool {
+	if s.recorder != nil {
+		s.recorder.readAccount(addr, fieldExist, fieldBalance, fieldNonce, fieldCode)
+	}
 	so := s.getStateObject(addr)
 	return so == nil || so.empty()
 }
 
 // GetBalance retrieves the balance from the given address or 0 if object not found
 func (s *StateDB) GetBalance(addr common.Address) *big.Int {
+	if s.recorder != nil {
+		s.recorder.readAccount(addr, fieldBalance)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.Balance()
@@ -259,6 +290,9 @@ func (s *StateDB) GetBalance(addr common.Address) *big.Int {
 }
 
 func (s *StateDB) GetNonce(addr common.Address) uint64 {
+	if s.recorder != nil {
+		s.recorder.readAccount(addr, fieldNonce)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.Nonce()
@@ -278,6 +312,9 @@ func (s *StateDB) BlockHash() common.Hash {
 }
 
 func (s *StateDB) GetCode(addr common.Address) []byte {
+	if s.recorder != nil {
+		s.recorder.readAccount(addr, fieldCode)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.Code(s.db)
@@ -286,6 +323,9 @@ func (s *StateDB) GetCode(addr common.Address) []byte {
 }
 
 func (s *StateDB) GetCodeSize(addr common.Address) int {
+	if s.recorder != nil {
+		s.recorder.readAccount(addr, fieldCode)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.CodeSize(s.db)
@@ -294,6 +334,9 @@ func (s *StateDB) GetCodeSize(addr common.Address) int {
 }
 
 func (s *StateDB) GetCodeHash(addr common.Address) common.Hash {
+	if s.recorder != nil {
+		s.recorder.readAccount(addr, fieldExist, fieldCode)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject == nil {
 		return common.Hash{}
@@ -303,6 +346,9 @@ func (s *StateDB) GetCodeHash(addr common.Address) common.Hash {
 
 // GetState retrieves a value from the given account's storage trie.
 func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
+	if s.recorder != nil {
+		s.recorder.readSlot(addr, hash)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetState(s.db, hash)
@@ -346,6 +392,9 @@ func (s *StateDB) GetStorageProofByHash(a common.Address, key common.Hash) ([][]
 
 // GetCommittedState retrieves a value from the given account's committed storage trie.
 func (s *StateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
+	if s.recorder != nil {
+		s.recorder.readSlot(addr, hash)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetCommittedState(s.db, hash)
@@ -371,6 +420,9 @@ func (s *StateDB) StorageTrie(addr common.Address) Trie {
 }
 
 func (s *StateDB) HasSuicided(addr common.Address) bool {
+	if s.recorder != nil {
+		s.recorder.readAccount(addr, fieldExist)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.suicided
@@ -384,6 +436,10 @@ func (s *StateDB) HasSuicided(addr common.Address) bool {
 
 // AddBalance adds amount to the account associated with addr.
 func (s *StateDB) AddBalance(addr common.Address, amount *big.Int) {
+	if s.recorder != nil {
+		replay := copyAmount(amount)
+		s.recordBalance(addr, func(s *StateDB) { s.AddBalance(addr, replay) })
+	}
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.AddBalance(amount)
@@ -392,6 +448,10 @@ func (s *StateDB) AddBalance(addr common.Address, amount *big.Int) {
 
 // SubBalance subtracts amount from the account associated with addr.
 func (s *StateDB) SubBalance(addr common.Address, amount *big.Int) {
+	if s.recorder != nil {
+		replay := copyAmount(amount)
+		s.recordBalance(addr, func(s *StateDB) { s.SubBalance(addr, replay) })
+	}
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SubBalance(amount)
@@ -399,6 +459,10 @@ func (s *StateDB) SubBalance(addr common.Address, amount *big.Int) {
 }
 
 func (s *StateDB) SetBalance(addr common.Address, amount *big.Int) {
+	if s.recorder != nil {
+		replay := copyAmount(amount)
+		s.recordBalance(addr, func(s *StateDB) { s.SetBalance(addr, replay) })
+	}
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetBalance(amount)
@@ -406,6 +470,11 @@ func (s *StateDB) SetBalance(addr common.Address, amount *big.Int) {
 }
 
 func (s *StateDB) SetNonce(addr common.Address, nonce uint64) {
+	if s.recorder != nil {
+		s.recordCreation(addr)
+		s.recorder.writeAccount(addr, fieldNonce)
+		s.recorder.record(func(s *StateDB) { s.SetNonce(addr, nonce) })
+	}
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetNonce(nonce)
@@ -413,6 +482,12 @@ func (s *StateDB) SetNonce(addr common.Address, nonce uint64) {
 }
 
 func (s *StateDB) SetCode(addr common.Address, code []byte) {
+	if s.recorder != nil {
+		replay := common.CopyBytes(code)
+		s.recordCreation(addr)
+		s.recorder.writeAccount(addr, fieldCode)
+		s.recorder.record(func(s *StateDB) { s.SetCode(addr, replay) })
+	}
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetCode(crypto.Keccak256Hash(code), code)
@@ -420,6 +495,11 @@ func (s *StateDB) SetCode(addr common.Address, code []byte) {
 }
 
 func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
+	if s.recorder != nil {
+		s.recordCreation(addr)
+		s.recorder.writeSlot(addr, key)
+		s.recorder.record(func(s *StateDB) { s.SetState(addr, key, value) })
+	}
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetState(s.db, key, value)
@@ -429,6 +509,15 @@ func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
 // SetStorage replaces the entire storage for the specified account with given
 // storage. This function should only be used for debugging.
 func (s *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common.Hash) {
+	if s.recorder != nil {
+		replay := make(map[common.Hash]common.Hash, len(storage))
+		for key, value := range storage {
+			replay[key] = value
+		}
+		s.recordCreation(addr)
+		s.recorder.writeAccount(addr, fieldStorage)
+		s.recorder.record(func(s *StateDB) { s.SetStorage(addr, replay) })
+	}
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetStorage(storage)
@@ -441,6 +530,11 @@ func (s *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common
 // The account's state object is still available until the state is committed,
 // getStateObject will return a non-nil account after Suicide.
 func (s *StateDB) Suicide(addr common.Address) bool {
+	if s.recorder != nil {
+		s.recorder.readAccount(addr, fieldExist)
+		s.recorder.writeAccount(addr, fieldExist, fieldBalance, fieldNonce, fieldCode, fieldStorage)
+		s.recorder.record(func(s *StateDB) { s.Suicide(addr) })
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject == nil {
 		return false
@@ -621,6 +715,11 @@ func (s *StateDB) createObject(addr common.Address) (newobj, prev *stateObject)
 //
 // Carrying over the balance ensures that Gdtur doesn't disappear.
 func (s *StateDB) CreateAccount(addr common.Address) {
+	if s.recorder != nil {
+		// The balance is carried over, but the addition commutes with others
+		s.recorder.writeAccount(addr, fieldExist, fieldBalance, fieldNonce, fieldCode, fieldStorage)
+		s.recorder.record(func(s *StateDB) { s.CreateAccount(addr) })
+	}
 	newObj, prev := s.createObject(addr)
 	if prev != nil {
 		newObj.setBalance(prev.data.Balance)
@@ -761,6 +860,9 @@ func (s *StateDB) Snapshot() int {
 	id := s.nextRevisionId
 	s.nextRevisionId++
 	s.validRevisions = append(s.validRevisions, revision{id, s.journal.length()})
+	if s.recorder != nil {
+		s.recorder.snapshot(id)
+	}
 	return id
 }
 
@@ -777,6 +879,9 @@ func (s *StateDB) RevertToSnapshot(revid int) {
 
 	// Replay the journal to undo changes and remove invalidated snapshots
 	s.journal.revert(s, snapshot)
+	if s.recorder != nil {
+		s.recorder.revert(revid)
+	}
 	s.validRevisions = s.validRevisions[:idx]
 }
 
@@ -838,6 +943,11 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	// Finalise all the dirty storage states and write them into the tries
 	s.Finalise(deleteEmptyObjects)
 
+	// If hashing is deferred, leave the finalised objects pending until a root
+	// is requested with hashing enabled again
+	if s.deferHashing {
+		return common.Hash{}
+	}
 	// If there was a trie prefetcher operating, it gets aborted and irrevocably
 	// modified after we start retrieving tries. Remove it from the statedb after
 	// this round of use.
@@ -892,6 +1002,15 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	return s.trie.Hash()
 }
 
+// SetDeferredHashing toggles whether IntermediateRoot skips updating and hashing
+// the tries, returning an empty root instead. Finalised changes are accumulated
+// and hashed in one go by the first IntermediateRoot or Commit call after the
+// deferral is disabled. This is only safe if nothing relies on the intermediate
+// roots, e.g. when reprocessing a trusted chain of post-Byzantium blocks.
+func (s *StateDB) SetDeferredHashing(deferred bool) {
+	s.deferHashing = deferred
+}
+
 // Prepare sets the current transaction hash and index and block hash which is
 // used when the EVM emits new state logs.
 func (s *StateDB) Prepare(thash, bhash common.Hash, ti int) {
@@ -915,6 +1034,7 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (common.Hash, error) {
 		return common.Hash{}, fmt.Errorf("commit aborted due to earlier error: %v", s.dbErr)
 	}
 	// Finalize any pending changes and merge everything into the tries
+	s.deferHashing = false
 	s.IntermediateRoot(deleteEmptyObjects)
 
 	// Commit objects to the trie, measuring the elapsed time
@@ -0,0 +1,137 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/gdtudb"
+	"github.com/c88032111/go-gdtu/trie"
+)
+
+// Witness is the subset of the state needed to access some accounts and storage
+// slots: the trie nodes on the paths to them and the codes of the contracts. The
+// entries are self-authenticating, as they are keyed by their hashes.
+type Witness struct {
+	Nodes [][]byte // Trie nodes (and legacy codes) accessed
+	Codes [][]byte // Contract codes accessed
+}
+
+// Database creates a state database backed purely by the witness. Accessing any
+// state not covered by the witness results in a missing trie node error.
+func (w *Witness) Database() Database {
+	db := rawdb.NewMemoryDatabase()
+	for _, node := range w.Nodes {
+		db.Put(crypto.Keccak256(node), node)
+	}
+	for _, code := range w.Codes {
+		rawdb.WriteCode(db, crypto.Keccak256Hash(code), code)
+	}
+	return NewDatabase(db)
+}
+
+// WitnessRecorder is a state database which reads through another one, recording
+// all the trie nodes and contract codes accessed into a witness.
+//
+// The recorder doesn't share the caches of the source database, so all accesses
+// are recorded, but it should only be used for a single state transition.
+type WitnessRecorder struct {
+	Database
+	reader *witnessReader
+}
+
+// NewWitnessRecorder creates a recording state database reading from db.
+func NewWitnessRecorder(db Database) *WitnessRecorder {
+	reader := &witnessReader{
+		KeyValueStore: db.TrieDB().DiskDB(),
+		source:        db.TrieDB(),
+		nodes:         make(map[common.Hash][]byte),
+		codes:         make(map[common.Hash][]byte),
+	}
+	return &WitnessRecorder{
+		Database: NewDatabase(rawdb.NewDatabase(reader)),
+		reader:   reader,
+	}
+}
+
+// Witness returns the trie nodes and codes accessed so far.
+func (r *WitnessRecorder) Witness() *Witness {
+	r.reader.lock.Lock()
+	defer r.reader.lock.Unlock()
+
+	return &Witness{
+		Nodes: sortedBlobs(r.reader.nodes),
+		Codes: sortedBlobs(r.reader.codes),
+	}
+}
+
+// witnessReader is a key-value store recording the trie nodes and contract codes
+// read from it. Trie nodes are retrieved through the source trie database, so
+// nodes not yet flushed to disk are also found.
+type witnessReader struct {
+	gdtudb.KeyValueStore
+	source *trie.Database
+
+	lock  sync.Mutex
+	nodes map[common.Hash][]byte
+	codes map[common.Hash][]byte
+}
+
+// Get retrieves the given key, recording it if it's a trie node or code.
+func (r *witnessReader) Get(key []byte) ([]byte, error) {
+	if len(key) == common.HashLength {
+		hash := common.BytesToHash(key)
+		blob, err := r.source.Node(hash)
+		if err != nil {
+			return nil, err
+		}
+		r.lock.Lock()
+		r.nodes[hash] = blob
+		r.lock.Unlock()
+		return blob, nil
+	}
+	blob, err := r.KeyValueStore.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if ok, hash := rawdb.IsCodeKey(key); ok {
+		r.lock.Lock()
+		r.codes[common.BytesToHash(hash)] = blob
+		r.lock.Unlock()
+	}
+	return blob, nil
+}
+
+// sortedBlobs returns the values of a hash keyed set, ordered by their keys.
+func sortedBlobs(set map[common.Hash][]byte) [][]byte {
+	hashes := make([]common.Hash, 0, len(set))
+	for hash := range set {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+
+	blobs := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		blobs[i] = common.CopyBytes(set[hash])
+	}
+	return blobs
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/c88032111/go-gdtu/core/state"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/metrics"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+var (
+	parallelTxMeter       = metrics.NewRegisteredMeter("chain/parallel/txs", nil)
+	parallelConflictMeter = metrics.NewRegisteredMeter("chain/parallel/conflicts", nil)
+)
+
+// speculation is the outcome of executing a transaction on top of the pre-block
+// state, disregarding the preceding transactions of the block.
+type speculation struct {
+	result *ExecutionResult
+	record *state.AccessRecord // State accessed and modified by the transaction
+	err    error
+}
+
+// parallelizable reports whgdtuer the transactions of the block can be executed
+// speculatively in parallel. The intermediate roots of pre-Byzantium receipts
+// require serial execution, and tracers are not safe for concurrent use.
+func (p *StateProcessor) parallelizable(config *params.ChainConfig, block *types.Block, cfg vm.Config) bool {
+	if !cfg.ParallelExecution || cfg.Debug || cfg.Sampler != nil {
+		return false
+	}
+	return len(block.Transactions()) > 1 && config.IsByzantium(block.Number())
+}
+
+// processParallel executes the transactions of the block speculatively in parallel
+// on copies of the pre-block state, then applies their modifications to the state
+// in order. Any transaction which read state written by a preceding one, or which
+// failed, is executed again on top of the preceding ones. The outcome is the same
+// as executing all transactions serially.
+func (p *StateProcessor) processParallel(config *params.ChainConfig, block *types.Block, statedb *state.StateDB, cfg vm.Config, gp *GasPool, usedGas *uint64) (types.Receipts, []*types.Log, error) {
+	var (
+		header = block.Header()
+		txs    = block.Transactions()
+		signer = types.MakeSigner(config, header.Number)
+		msgs   = make([]types.Message, len(txs))
+	)
+	for i, tx := range txs {
+		msg, err := tx.AsMessage(signer)
+		if err != nil {
+			return nil, nil, err
+		}
+		msgs[i] = msg
+	}
+	specs := p.speculate(config, block, msgs, statedb, cfg)
+
+	// Apply the transactions in order, executing the conflicting ones again
+	var (
+		receipts  types.Receipts
+		allLogs   []*types.Log
+		written   = state.NewAccessRecord() // State written by the applied transactions
+		conflicts int
+		vmenv     = vm.NewEVM(NewEVMBlockContext(header, p.bc, nil), vm.TxContext{}, statedb, config, cfg)
+	)
+	for i, tx := range txs {
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+
+		spec := specs[i]
+		if spec.err == nil && gp.Gas() >= msgs[i].Gas() && !spec.record.Conflicts(written) {
+			spec.record.Apply(statedb)
+			statedb.Finalise(true)
+
+			// The gas pool is charged the full gas, then refunded the remainder
+			gp.SubGas(spec.result.UsedGas)
+			*usedGas += spec.result.UsedGas
+
+			receipt := newReceipt(msgs[i], spec.result, statedb, header, tx, nil, *usedGas)
+			receipts = append(receipts, receipt)
+			allLogs = append(allLogs, receipt.Logs...)
+			written.MergeWrites(spec.record)
+			continue
+		}
+		conflicts++
+
+		record := statedb.StartAccessRecord()
+		receipt, err := applyTransaction(msgs[i], config, p.bc, nil, gp, statedb, header, tx, usedGas, vmenv)
+		statedb.StopAccessRecord()
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+		written.MergeWrites(record)
+	}
+	parallelTxMeter.Mark(int64(len(txs)))
+	parallelConflictMeter.Mark(int64(conflicts))
+
+	return receipts, allLogs, nil
+}
+
+// speculate executes the messages on top of copies of the given state, on a
+// bounded number of concurrent workers.
+func (p *StateProcessor) speculate(config *params.ChainConfig, block *types.Block, msgs []types.Message, statedb *state.StateDB, cfg vm.Config) []*speculation {
+	var (
+		txs     = block.Transactions()
+		specs   = make([]*speculation, len(txs))
+		tasks   = make(chan int, len(txs))
+		workers = runtime.NumCPU()
+		pend    sync.WaitGroup
+	)
+	// Copy the state once without the prefetcher, the workers only read the base
+	base := statedb.Copy()
+	base.StopPrefetcher()
+
+	for i := range txs {
+		tasks <- i
+	}
+	close(tasks)
+
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	for w := 0; w < workers; w++ {
+		pend.Add(1)
+		go func() {
+			defer pend.Done()
+
+			// Block contexts cache the block hashes, so each worker needs its own
+			blockContext := NewEVMBlockContext(block.Header(), p.bc, nil)
+			for i := range tasks {
+				spec := base.Copy()
+				spec.Prepare(txs[i].Hash(), block.Hash(), i)
+				record := spec.StartAccessRecord()
+
+				evm := vm.NewEVM(blockContext, NewEVMTxContext(msgs[i]), spec, config, cfg)
+				result, err := ApplyMessage(evm, msgs[i], new(GasPool).AddGas(msgs[i].Gas()))
+				specs[i] = &speculation{result: result, record: record, err: err}
+			}
+		}()
+	}
+	pend.Wait()
+	return specs
+}
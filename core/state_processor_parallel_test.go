@@ -0,0 +1,120 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/consensus/gdtuash"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// Tests that blocks processed speculatively in parallel yield the same state and
+// receipts as the serial processing, both with conflicting and independent
+// transactions in the same block.
+func TestParallelProcessing(t *testing.T) {
+	var (
+		keys    = make([]*ecdsa.PrivateKey, 6)
+		addrs   = make([]common.Address, 6)
+		funds   = big.NewInt(1000000000000000)
+		counter = common.Address{0xcc}
+		alloc   = GenesisAlloc{
+			// Counter contract, incrementing slot 0 and emitting a log on every call
+			counter: {Balance: big.NewInt(0), Code: []byte{
+				byte(vm.PUSH1), 0, byte(vm.SLOAD),
+				byte(vm.PUSH1), 1, byte(vm.ADD),
+				byte(vm.PUSH1), 0, byte(vm.SSTORE),
+				byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG0),
+				byte(vm.STOP),
+			}},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		alloc[addrs[i]] = GenesisAccount{Balance: funds}
+	}
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: alloc}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, gdtuash.NewFaker(), db, 4, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+
+		send := func(key *ecdsa.PrivateKey, to common.Address, gas uint64) {
+			from := crypto.PubkeyToAddress(key.PublicKey)
+			tx, err := types.SignTx(types.NewTransaction(b.TxNonce(from), to, big.NewInt(1000), gas, big.NewInt(1), nil), signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign tx: %v", err)
+			}
+			b.AddTx(tx)
+		}
+		// Independent transfers to fresh accounts
+		for j, key := range keys[:4] {
+			send(key, common.Address{0xaa, byte(i), byte(j)}, params.TxGas)
+		}
+		// Conflicting on the nonce and balance of the sender
+		send(keys[0], common.Address{0xbb, byte(i)}, params.TxGas)
+
+		// Conflicting on the counter slot
+		send(keys[4], counter, 100000)
+		send(keys[5], counter, 100000)
+	})
+	// Import the chain with parallel processing, the block validation ensures that
+	// the state root, receipts and bloom match the serially generated ones
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+
+	chain, err := NewBlockChain(diskdb, nil, gspec.Config, gdtuash.NewFaker(), vm.Config{ParallelExecution: true}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert with parallel processing: %v", n, err)
+	}
+	state, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to retrieve head state: %v", err)
+	}
+	if have := state.GetState(counter, common.Hash{}); have != common.BigToHash(big.NewInt(8)) {
+		t.Errorf("counter mismatch: have %x, want 8", have)
+	}
+	for _, block := range blocks {
+		receipts := chain.GetReceiptsByHash(block.Hash())
+		if len(receipts) != len(block.Transactions()) {
+			t.Fatalf("block %d: receipt count mismatch: have %d, want %d", block.NumberU64(), len(receipts), len(block.Transactions()))
+		}
+		for i, receipt := range receipts {
+			for _, log := range receipt.Logs {
+				if log.TxIndex != uint(i) || log.TxHash != block.Transactions()[i].Hash() {
+					t.Errorf("block %d, tx %d: log mismatch: index %d, hash %x", block.NumberU64(), i, log.TxIndex, log.TxHash)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/consensus"
+	"github.com/c88032111/go-gdtu/core/state"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/params"
+	"github.com/c88032111/go-gdtu/trie"
+)
+
+// ErrIncompleteWitness is returned if executing a block accesses state or
+// ancestor headers not contained in the supplied witness.
+var ErrIncompleteWitness = errors.New("incomplete witness")
+
+// Witness is everything needed to execute a block without a database: the parts
+// of the parent state accessed by the block and the ancestor headers.
+type Witness struct {
+	Headers []*types.Header // Parent header first, followed by the ancestors accessed
+	State   *state.Witness
+}
+
+// witnessChain is a header chain reconstructed from the headers of a witness.
+// Headers are looked up by hash, so the chain only contains authentic ancestors
+// of the parent.
+type witnessChain struct {
+	config  *params.ChainConfig
+	engine  consensus.Engine
+	parent  *types.Header
+	headers map[common.Hash]*types.Header
+}
+
+func (c *witnessChain) Config() *params.ChainConfig { return c.config }
+func (c *witnessChain) Engine() consensus.Engine    { return c.engine }
+func (c *witnessChain) CurrentHeader() *types.Header {
+	return c.parent
+}
+
+func (c *witnessChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.headers[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+
+func (c *witnessChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	return c.headers[hash]
+}
+
+func (c *witnessChain) GetHeaderByNumber(number uint64) *types.Header {
+	for header := c.parent; header != nil; header = c.headers[header.ParentHash] {
+		if n := header.Number.Uint64(); n == number {
+			return header
+		} else if n < number {
+			break
+		}
+	}
+	return nil
+}
+
+// ExecuteStatelessBlock executes a block against the state contained in the
+// witness and verifies the outcome (receipts, gas, bloom and post state root)
+// against the block header. The header itself, including its seal, is not
+// verified, that is up to the caller.
+//
+// As the witness entries are authenticated by their hashes, this allows blocks
+// received from untrusted sources to be verified without a full database.
+func ExecuteStatelessBlock(config *params.ChainConfig, engine consensus.Engine, block *types.Block, witness *Witness) (types.Receipts, error) {
+	if len(witness.Headers) == 0 || witness.State == nil {
+		return nil, ErrIncompleteWitness
+	}
+	chain := &witnessChain{
+		config:  config,
+		engine:  engine,
+		headers: make(map[common.Hash]*types.Header, len(witness.Headers)),
+	}
+	for _, header := range witness.Headers {
+		chain.headers[header.Hash()] = header
+	}
+	if chain.parent = chain.GetHeader(block.ParentHash(), block.NumberU64()-1); chain.parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	// Validate the body against the header, the state is not needed for it
+	if hash := types.CalcUncleHash(block.Uncles()); hash != block.UncleHash() {
+		return nil, fmt.Errorf("uncle root hash mismatch: have %x, want %x", hash, block.UncleHash())
+	}
+	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != block.TxHash() {
+		return nil, fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, block.TxHash())
+	}
+	// Execute the block on top of the witness state. Any access to state outside
+	// of the witness is recorded as a database error in the state, making the
+	// outcome meaningless.
+	statedb, err := state.New(chain.parent.Root, witness.State.Database(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIncompleteWitness, err)
+	}
+	processor := &StateProcessor{bc: chain, engine: engine}
+	receipts, _, usedGas, err := processor.Process(block, statedb, vm.Config{})
+	if dberr := statedb.Error(); dberr != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIncompleteWitness, dberr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	root := statedb.IntermediateRoot(config.IsEIP158(block.Number()))
+	if err := statedb.Error(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIncompleteWitness, err)
+	}
+	if err := validateReceipts(block, receipts, usedGas); err != nil {
+		return nil, err
+	}
+	if root != block.Root() {
+		return nil, fmt.Errorf("invalid merkle root (remote: %x local: %x)", block.Root(), root)
+	}
+	return receipts, nil
+}
+
+// witnessRecordingChain is the canonical chain, recording the headers accessed
+// during block execution.
+type witnessRecordingChain struct {
+	*BlockChain
+	headers map[common.Hash]*types.Header
+}
+
+func (c *witnessRecordingChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header := c.BlockChain.GetHeader(hash, number)
+	if header != nil {
+		c.headers[hash] = header
+	}
+	return header
+}
+
+// BlockWitness executes a block on top of its locally available parent state
+// and returns the witness needed to execute it statelessly.
+func (bc *BlockChain) BlockWitness(block *types.Block) (*Witness, error) {
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	recorder := state.NewWitnessRecorder(bc.stateCache)
+	statedb, err := state.New(parent.Root, recorder, nil)
+	if err != nil {
+		return nil, err
+	}
+	chain := &witnessRecordingChain{BlockChain: bc, headers: make(map[common.Hash]*types.Header)}
+	processor := &StateProcessor{bc: chain, engine: bc.engine}
+	if _, _, _, err := processor.Process(block, statedb, vm.Config{}); err != nil {
+		return nil, err
+	}
+	// Hash the post state too, collecting the nodes needed to apply the changes
+	statedb.IntermediateRoot(bc.Config().IsEIP158(block.Number()))
+	if err := statedb.Error(); err != nil {
+		return nil, err
+	}
+	delete(chain.headers, parent.Hash())
+	ancestors := make([]*types.Header, 0, len(chain.headers))
+	for _, header := range chain.headers {
+		ancestors = append(ancestors, header)
+	}
+	sort.Slice(ancestors, func(i, j int) bool { return ancestors[i].Number.Uint64() > ancestors[j].Number.Uint64() })
+
+	return &Witness{
+		Headers: append([]*types.Header{parent}, ancestors...),
+		State:   recorder.Witness(),
+	}, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/consensus/gdtuash"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/state"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// Tests that blocks can be executed against the witness recorded from a full
+// node, and that incomplete witnesses and invalid blocks are detected.
+func TestExecuteStatelessBlock(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = rawdb.NewMemoryDatabase()
+		engine  = gdtuash.NewFaker()
+		config  = params.TestChainConfig
+		signer  = types.LatestSigner(config)
+		storer  = common.Address{0xaa}
+		genesis = &Genesis{
+			Config: config,
+			Alloc: GenesisAlloc{
+				addr: {Balance: big.NewInt(1000000000000000000)},
+				// Stores the hash of the block two blocks ago into slot 0
+				storer: {Code: []byte{0x60, 0x02, 0x43, 0x03, 0x40, 0x60, 0x00, 0x55, 0x00}, Balance: big.NewInt(0)},
+			},
+		}
+	)
+	genesis.MustCommit(db)
+
+	// Generate the blocks one by one on an archive chain, as BLOCKHASH needs the
+	// ancestors, and import them into a chain keeping the recent state in memory
+	gendb := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(gendb)
+
+	archiveCaching := *defaultCacheConfig
+	archiveCaching.TrieDirtyDisabled = true
+	genchain, _ := NewBlockChain(gendb, &archiveCaching, config, engine, vm.Config{}, nil, nil)
+	defer genchain.Stop()
+
+	chain, _ := NewBlockChain(db, nil, config, engine, vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	var blocks []*types.Block
+	for i := 0; i < 4; i++ {
+		generated, _ := GenerateChain(config, genchain.CurrentBlock(), engine, gendb, 1, func(_ int, b *BlockGen) {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{byte(i + 1)}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+			b.AddTx(tx)
+			tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(addr), storer, big.NewInt(0), 100000, nil, nil), signer, key)
+			b.AddTxWithChain(genchain, tx)
+		})
+		if _, err := genchain.InsertChain(generated); err != nil {
+			t.Fatalf("failed to generate block %d: %v", i, err)
+		}
+		blocks = append(blocks, generated...)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	block := blocks[3]
+	witness, err := chain.BlockWitness(block)
+	if err != nil {
+		t.Fatalf("failed to create witness: %v", err)
+	}
+	if len(witness.Headers) == 0 || witness.Headers[0].Hash() != block.ParentHash() {
+		t.Fatalf("witness doesn't start with the parent header")
+	}
+	if len(witness.State.Codes) != 1 {
+		t.Fatalf("witness code count mismatch: have %d, want 1", len(witness.State.Codes))
+	}
+	receipts, err := ExecuteStatelessBlock(config, engine, block, witness)
+	if err != nil {
+		t.Fatalf("failed to execute block statelessly: %v", err)
+	}
+	if len(receipts) != len(block.Transactions()) {
+		t.Fatalf("receipt count mismatch: have %d, want %d", len(receipts), len(block.Transactions()))
+	}
+	// Drop each state node in turn, the block must not pass as valid
+	for i := range witness.State.Nodes {
+		nodes := append(append([][]byte{}, witness.State.Nodes[:i]...), witness.State.Nodes[i+1:]...)
+		partial := &Witness{Headers: witness.Headers, State: &state.Witness{Nodes: nodes, Codes: witness.State.Codes}}
+		if _, err := ExecuteStatelessBlock(config, engine, block, partial); err == nil {
+			t.Fatalf("block passed with state node %d missing", i)
+		}
+	}
+	// Drop the contract code, which must be reported as incomplete witness
+	partial := &Witness{Headers: witness.Headers, State: &state.Witness{Nodes: witness.State.Nodes}}
+	if _, err := ExecuteStatelessBlock(config, engine, block, partial); !errors.Is(err, ErrIncompleteWitness) {
+		t.Fatalf("missing code: have error %v, want %v", err, ErrIncompleteWitness)
+	}
+	// Execute a block claiming a wrong post state
+	header := block.Header()
+	header.Root = common.Hash{0x01}
+	forged := types.NewBlockWithHeader(header).WithBody(block.Transactions(), block.Uncles())
+	if _, err := ExecuteStatelessBlock(config, engine, forged, witness); err == nil {
+		t.Fatalf("block with invalid root accepted")
+	}
+}
@@ -19,12 +19,32 @@ package core
 import (
 	"runtime"
 
+	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/metrics"
+	"github.com/c88032111/go-gdtu/params"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// senderCacheLimit is the number of recovered transaction senders to keep
+// around, shared between the transaction pool, block fetcher and importer.
+const senderCacheLimit = 65536
+
+var (
+	senderCacheHitMeter  = metrics.NewRegisteredMeter("core/senders/cache/hit", nil)
+	senderCacheMissMeter = metrics.NewRegisteredMeter("core/senders/cache/miss", nil)
 )
 
 // senderCacher is a concurrent transaction sender recoverer and cacher.
 var senderCacher = newTxSenderCacher(runtime.NumCPU())
 
+// txSender is a recovered transaction sender along with the signer used to
+// derive it.
+type txSender struct {
+	signer types.Signer
+	from   common.Address
+}
+
 // txSenderCacherRequest is a request for recovering transaction senders with a
 // specific signature scheme and caching it into the transactions themselves.
 //
@@ -39,17 +59,24 @@ type txSenderCacherRequest struct {
 
 // txSenderCacher is a helper structure to concurrently ecrecover transaction
 // senders from digital signatures on background threads.
+//
+// Recovered senders are additionally tracked in a cache keyed by transaction
+// hash, so that different instances of the same transaction (e.g. a pooled one
+// and one decoded from a block body) only need to be ecrecovered once.
 type txSenderCacher struct {
 	threads int
 	tasks   chan *txSenderCacherRequest
+	senders *lru.Cache // Transaction hash -> recovered sender
 }
 
 // newTxSenderCacher creates a new transaction sender background cacher and starts
 // as many processing goroutines as allowed by the GOMAXPROCS on construction.
 func newTxSenderCacher(threads int) *txSenderCacher {
+	senders, _ := lru.New(senderCacheLimit)
 	cacher := &txSenderCacher{
 		tasks:   make(chan *txSenderCacherRequest, threads),
 		threads: threads,
+		senders: senders,
 	}
 	for i := 0; i < threads; i++ {
 		go cacher.cache()
@@ -62,11 +89,33 @@ func newTxSenderCacher(threads int) *txSenderCacher {
 func (cacher *txSenderCacher) cache() {
 	for task := range cacher.tasks {
 		for i := 0; i < len(task.txs); i += task.inc {
-			types.Sender(task.signer, task.txs[i])
+			cacher.sender(task.signer, task.txs[i])
 		}
 	}
 }
 
+// sender retrieves the sender of a transaction, either from the shared cache
+// or by recovering it from the signature. The result is cached both into the
+// transaction itself and into the shared cache.
+func (cacher *txSenderCacher) sender(signer types.Signer, tx *types.Transaction) (common.Address, error) {
+	hash := tx.Hash()
+	if cached, ok := cacher.senders.Get(hash); ok {
+		if entry := cached.(txSender); entry.signer.Equal(signer) {
+			senderCacheHitMeter.Mark(1)
+			types.CacheSender(signer, tx, entry.from)
+			return entry.from, nil
+		}
+	}
+	senderCacheMissMeter.Mark(1)
+
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	cacher.senders.Add(hash, txSender{signer: signer, from: from})
+	return from, nil
+}
+
 // recover recovers the senders from a batch of transactions and caches them
 // back into the same data structures. There is no validation being done, nor
 // any reaction to invalid signatures. That is up to calling code later.
@@ -92,14 +141,28 @@ func (cacher *txSenderCacher) recover(signer types.Signer, txs []*types.Transact
 // recoverFromBlocks recovers the senders from a batch of blocks and caches them
 // back into the same data structures. There is no validation being done, nor
 // any reaction to invalid signatures. That is up to calling code later.
-func (cacher *txSenderCacher) recoverFromBlocks(signer types.Signer, blocks []*types.Block) {
-	count := 0
-	for _, block := range blocks {
-		count += len(block.Transactions())
-	}
-	txs := make([]*types.Transaction, 0, count)
+//
+// Every block is recovered with the signer valid at its own number, so batches
+// crossing a fork transition are fully precomputed too.
+func (cacher *txSenderCacher) recoverFromBlocks(config *params.ChainConfig, blocks []*types.Block) {
+	var (
+		signer types.Signer
+		txs    []*types.Transaction
+	)
 	for _, block := range blocks {
+		if next := types.MakeSigner(config, block.Number()); signer == nil || !signer.Equal(next) {
+			cacher.recover(signer, txs)
+			signer, txs = next, nil
+		}
 		txs = append(txs, block.Transactions()...)
 	}
 	cacher.recover(signer, txs)
 }
+
+// RecoverSenders starts recovering the transaction senders of the given blocks
+// on background threads. It can be used by block sources (e.g. the fetcher) to
+// precompute the signatures ahead of import; the results are shared with the
+// transaction pool and the block processor.
+func RecoverSenders(config *params.ChainConfig, blocks []*types.Block) {
+	senderCacher.recoverFromBlocks(config, blocks)
+}
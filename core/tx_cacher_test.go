@@ -0,0 +1,65 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/params"
+	"github.com/c88032111/go-gdtu/rlp"
+)
+
+// Tests that senders recovered for one instance of a transaction are shared
+// with other instances of the same transaction, but only for equal signers.
+func TestSenderCacherSharing(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(params.TestChainConfig.ChainID)
+
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), params.TxGas, big.NewInt(1), nil), signer, key)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	cacher := newTxSenderCacher(1)
+	from, err := cacher.sender(signer, tx)
+	if err != nil {
+		t.Fatalf("failed to recover sender: %v", err)
+	}
+	if want := crypto.PubkeyToAddress(key.PublicKey); from != want {
+		t.Fatalf("sender mismatch: have %x, want %x", from, want)
+	}
+	// Decode a fresh copy of the transaction and ensure it's served from the cache
+	blob, _ := rlp.EncodeToBytes(tx)
+	cpy := new(types.Transaction)
+	if err := rlp.DecodeBytes(blob, cpy); err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if cached, ok := cacher.senders.Get(cpy.Hash()); !ok || cached.(txSender).from != from {
+		t.Fatalf("sender not shared: have %v", cached)
+	}
+	if have, _ := cacher.sender(signer, cpy); have != from {
+		t.Fatalf("cached sender mismatch: have %x, want %x", have, from)
+	}
+	// A different signer must not be served from the cache
+	other := types.NewEIP155Signer(big.NewInt(2))
+	if have, err := cacher.sender(other, cpy); err == nil && have == from {
+		t.Fatalf("sender recovered with mismatching signer")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"time"
 
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/core/types"
@@ -178,3 +179,109 @@ func (journal *txJournal) close() error {
 	}
 	return err
 }
+
+// remoteTxEntry is a journaled remote transaction along with its sender and the
+// time it was first seen, so stale entries can be dropped on load without
+// recovering their signatures.
+type remoteTxEntry struct {
+	Time   uint64
+	Sender common.Address
+	Tx     *types.Transaction
+}
+
+// remoteTxJournal is a snapshot of the remote transactions of the pool, which
+// is regenerated periodically and on shutdown so a restart doesn't empty the
+// pool. Unlike the local journal, new transactions are not appended to it.
+type remoteTxJournal struct {
+	path string // Filesystem path to store the transactions at
+}
+
+// newRemoteTxJournal creates a new remote transaction journal.
+func newRemoteTxJournal(path string) *remoteTxJournal {
+	return &remoteTxJournal{
+		path: path,
+	}
+}
+
+// load parses the remote transaction journal from disk, dropping the entries
+// older than the lifetime or deemed stale by the filter, and loads the rest into
+// the specified pool.
+func (journal *remoteTxJournal) load(lifetime time.Duration, stale func(common.Address, *types.Transaction) bool, add func([]*types.Transaction) []error) error {
+	// Skip the parsing if the journal file doesn't exist at all
+	if _, err := os.Stat(journal.path); os.IsNotExist(err) {
+		return nil
+	}
+	input, err := os.Open(journal.path)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	var (
+		stream  = rlp.NewStream(input, 0)
+		batch   types.Transactions
+		failure error
+
+		total, expired, stales, dropped int
+	)
+	for {
+		var entry remoteTxEntry
+		if err = stream.Decode(&entry); err != nil {
+			if err != io.EOF {
+				failure = err
+			}
+			break
+		}
+		total++
+
+		seen := time.Unix(int64(entry.Time), 0)
+		if time.Since(seen) > lifetime {
+			expired++
+			continue
+		}
+		if stale(entry.Sender, entry.Tx) {
+			stales++
+			continue
+		}
+		entry.Tx.SetTime(seen)
+		batch = append(batch, entry.Tx)
+	}
+	// Add the remaining transactions in small-ish batches
+	for len(batch) > 0 {
+		n := len(batch)
+		if n > 1024 {
+			n = 1024
+		}
+		for _, err := range add(batch[:n]) {
+			if err != nil {
+				log.Debug("Failed to add journaled remote transaction", "err", err)
+				dropped++
+			}
+		}
+		batch = batch[n:]
+	}
+	log.Info("Loaded remote transaction journal", "transactions", total, "expired", expired, "stale", stales, "dropped", dropped)
+
+	return failure
+}
+
+// dump replaces the remote transaction journal with the given entries.
+func (journal *remoteTxJournal) dump(entries []remoteTxEntry) error {
+	replacement, err := os.OpenFile(journal.path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err = rlp.Encode(replacement, &entry); err != nil {
+			replacement.Close()
+			return err
+		}
+	}
+	replacement.Close()
+
+	if err = os.Rename(journal.path+".new", journal.path); err != nil {
+		return err
+	}
+	log.Debug("Regenerated remote transaction journal", "transactions", len(entries))
+	return nil
+}
@@ -110,6 +110,7 @@ var (
 	invalidTxMeter     = metrics.NewRegisteredMeter("txpool/invalid", nil)
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
 	overflowedTxMeter  = metrics.NewRegisteredMeter("txpool/overflowed", nil)
+	conditionalTxMeter = metrics.NewRegisteredMeter("txpool/conditional", nil) // Dropped due to failed conditions
 
 	pendingGauge = metrics.NewRegisteredGauge("txpool/pending", nil)
 	queuedGauge  = metrics.NewRegisteredGauge("txpool/queued", nil)
@@ -130,6 +131,7 @@ const (
 // blockChain provides the state of blockchain and current gas limit to do
 // some pre checks in tx pool and event subscribers.
 type blockChain interface {
+	Config() *params.ChainConfig
 	CurrentBlock() *types.Block
 	GetBlock(hash common.Hash, number uint64) *types.Block
 	StateAt(root common.Hash) (*state.StateDB, error)
@@ -144,6 +146,9 @@ type TxPoolConfig struct {
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
 
+	RemoteJournal      string // Journal of remote transactions to survive node restarts (empty = disabled)
+	RemoteJournalLimit uint64 // Maximum number of remote transactions to journal
+
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
@@ -161,6 +166,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	Journal:   "transactions.rlp",
 	Rejournal: time.Hour,
 
+	RemoteJournalLimit: 4096,
+
 	PriceLimit: 1,
 	PriceBump:  10,
 
@@ -208,6 +215,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
 	}
+	if conf.RemoteJournal != "" && conf.RemoteJournalLimit < 1 {
+		log.Warn("Sanitizing invalid txpool remote journal limit", "provided", conf.RemoteJournalLimit, "updated", DefaultTxPoolConfig.RemoteJournalLimit)
+		conf.RemoteJournalLimit = DefaultTxPoolConfig.RemoteJournalLimit
+	}
 	return conf
 }
 
@@ -219,25 +230,28 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 // current state) and future transactions. Transactions move between those
 // two states over time as they are received and processed.
 type TxPool struct {
-	config      TxPoolConfig
-	chainconfig *params.ChainConfig
-	chain       blockChain
-	gasPrice    *big.Int
-	txFeed      event.Feed
-	scope       event.SubscriptionScope
-	signer      types.Signer
-	mu          sync.RWMutex
+	config   TxPoolConfig
+	chain    blockChain
+	gasPrice *big.Int
+	txFeed   event.Feed
+	scope    event.SubscriptionScope
+	signer   types.Signer
+	mu       sync.RWMutex
 
+	eip155   bool // Fork indicator whether we are accepting replay protected transactions.
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
 	eip2718  bool // Fork indicator whether we are using EIP-2718 type transactions.
 
 	currentState  *state.StateDB // Current state in the blockchain head
 	pendingNonces *txNoncer      // Pending state tracking virtual nonces
 	currentMaxGas uint64         // Current gas limit for transaction caps
+	pendingNumber *big.Int       // Number of the next block built on the current head
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
+	remoteJournal *remoteTxJournal // Journal of remote transactions to back up to disk
+
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
 	beats   map[common.Address]time.Time // Last heartbeat from each known account
@@ -260,6 +274,9 @@ type txpoolResetRequest struct {
 
 // NewTxPool creates a new transaction pool to gather, sort and filter inbound
 // transactions from the network.
+//
+// The fork rules are tracked from the chain's current configuration on every
+// head change, the given config only fixes the chain ID to sign against.
 func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain blockChain) *TxPool {
 	// Sanitize the input to ensure no vulnerable gas prices are set
 	config = (&config).sanitize()
@@ -267,9 +284,8 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	// Create the transaction pool with its initial settings
 	pool := &TxPool{
 		config:          config,
-		chainconfig:     chainconfig,
 		chain:           chain,
-		signer:          types.LatestSigner(chainconfig),
+		signer:          types.LatestSignerForChainID(chainconfig.ChainID),
 		pending:         make(map[common.Address]*txList),
 		queue:           make(map[common.Address]*txList),
 		beats:           make(map[common.Address]time.Time),
@@ -305,6 +321,14 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If remote journaling is enabled, load from disk after the local transactions
+	if config.RemoteJournal != "" {
+		pool.remoteJournal = newRemoteTxJournal(config.RemoteJournal)
+
+		if err := pool.remoteJournal.load(pool.config.Lifetime, pool.staleRemote, pool.AddRemotes); err != nil {
+			log.Warn("Failed to load remote transaction journal", "err", err)
+		}
+	}
 
 	// Subscribe events from blockchain and start the main event loop.
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
@@ -387,6 +411,15 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+			if pool.remoteJournal != nil {
+				pool.mu.RLock()
+				entries := pool.remotes()
+				pool.mu.RUnlock()
+
+				if err := pool.remoteJournal.dump(entries); err != nil {
+					log.Warn("Failed to regenerate remote tx journal", "err", err)
+				}
+			}
 		}
 	}
 }
@@ -403,6 +436,15 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.remoteJournal != nil {
+		pool.mu.RLock()
+		entries := pool.remotes()
+		pool.mu.RUnlock()
+
+		if err := pool.remoteJournal.dump(entries); err != nil {
+			log.Warn("Failed to regenerate remote tx journal", "err", err)
+		}
+	}
 	log.Info("Transaction pool stopped")
 }
 
@@ -482,6 +524,23 @@ func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common
 	return pending, queued
 }
 
+// ContentFrom retrieves the data content of the transaction pool, returning the
+// pending as well as queued transactions of this address, sorted by nonce.
+func (pool *TxPool) ContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	var pending types.Transactions
+	if list, ok := pool.pending[addr]; ok {
+		pending = list.Flatten()
+	}
+	var queued types.Transactions
+	if list, ok := pool.queue[addr]; ok {
+		queued = list.Flatten()
+	}
+	return pending, queued
+}
+
 // Pending retrieves all currently processable transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -520,6 +579,55 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// remotes retrieves the remote transactions to journal, up to the configured
+// limit. Executable transactions are preferred over queued ones, and accounts
+// paying more for their first transaction over others. Transactions older than
+// the queue lifetime are skipped. The caller must hold pool.mu.
+func (pool *TxPool) remotes() []remoteTxEntry {
+	var (
+		entries = make([]remoteTxEntry, 0, pool.config.RemoteJournalLimit)
+		limit   = int(pool.config.RemoteJournalLimit)
+	)
+	for _, lists := range []map[common.Address]*txList{pool.pending, pool.queue} {
+		var accounts []types.Transactions
+		for addr, list := range lists {
+			if !pool.locals.contains(addr) {
+				accounts = append(accounts, list.Flatten())
+			}
+		}
+		sort.Slice(accounts, func(i, j int) bool {
+			return accounts[i][0].GasPriceCmp(accounts[j][0]) > 0
+		})
+		for _, txs := range accounts {
+			for _, tx := range txs {
+				if len(entries) >= limit {
+					return entries
+				}
+				if time.Since(tx.Time()) > pool.config.Lifetime {
+					continue
+				}
+				from, _ := types.Sender(pool.signer, tx) // already validated
+				entries = append(entries, remoteTxEntry{
+					Time:   uint64(tx.Time().Unix()),
+					Sender: from,
+					Tx:     tx,
+				})
+			}
+		}
+	}
+	return entries
+}
+
+// staleRemote reports whether a journaled remote transaction can be dropped
+// without full validation, because its nonce has already been used or its sender
+// can't afford it.
+func (pool *TxPool) staleRemote(from common.Address, tx *types.Transaction) bool {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.currentState.GetNonce(from) > tx.Nonce() || pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
@@ -527,6 +635,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if !pool.eip2718 && tx.Type() != types.LegacyTxType {
 		return ErrTxTypeNotSupported
 	}
+	// The signer accepts all transaction types of the chain, enforce EIP-155 here
+	if !pool.eip155 && tx.Protected() {
+		return ErrInvalidSender
+	}
 	// Reject transactions over defined size to prevent DOS attacks
 	if uint64(tx.Size()) > txMaxSize {
 		return ErrOversizedData
@@ -540,8 +652,9 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if pool.currentMaxGas < tx.Gas() {
 		return ErrGasLimit
 	}
-	// Make sure the transaction is signed properly.
-	from, err := types.Sender(pool.signer, tx)
+	// Make sure the transaction is signed properly, sharing the recovered
+	// sender with the block importer.
+	from, err := senderCacher.sender(pool.signer, tx)
 	if err != nil {
 		return ErrInvalidSender
 	}
@@ -566,6 +679,12 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if tx.Gas() < intrGas {
 		return ErrIntrinsicGas
 	}
+	// Ensure the local validity conditions of the transaction hold
+	if cond := tx.Conditional(); cond != nil {
+		if err := cond.Validate(pool.pendingNumber, pool.currentState); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -712,6 +831,10 @@ func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
 	if pool.journal == nil || !pool.locals.contains(from) {
 		return
 	}
+	// Conditions aren't journaled, don't resurrect the transaction without them
+	if tx.Conditional() != nil {
+		return
+	}
 	if err := pool.journal.insert(tx); err != nil {
 		log.Warn("Failed to journal local transaction", "err", err)
 	}
@@ -1067,6 +1190,9 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 		// Reset from the old head to the new, rescheduling any reorged transactions
 		pool.reset(reset.oldHead, reset.newHead)
 
+		// Drop any conditional transactions invalidated by the new head
+		pool.dropFailedConditionals()
+
 		// Nonces were reset, discard any events that became stale
 		for addr := range events {
 			events[addr].Forward(pool.pendingNonces.get(addr))
@@ -1203,8 +1329,12 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 
 	// Update all fork indicator by next pending block number.
 	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
-	pool.istanbul = pool.chainconfig.IsIstanbul(next)
-	pool.eip2718 = pool.chainconfig.IsBerlin(next)
+	pool.pendingNumber = next
+
+	config := pool.chain.Config()
+	pool.eip155 = config.IsEIP155(next)
+	pool.istanbul = config.IsIstanbul(next)
+	pool.eip2718 = config.IsBerlin(next)
 }
 
 // promoteExecutables moves transactions that have become processable from the
@@ -1405,6 +1535,29 @@ func (pool *TxPool) truncateQueue() {
 	}
 }
 
+// dropFailedConditionals removes all transactions whose local validity conditions
+// no longer hold against the current head. Any subsequent transactions of the same
+// account are moved back into the future queue.
+//
+// Note, this Method assumes the pool lock is held!
+func (pool *TxPool) dropFailedConditionals() {
+	var failed []common.Hash
+	pool.all.Range(func(hash common.Hash, tx *types.Transaction, local bool) bool {
+		if cond := tx.Conditional(); cond != nil {
+			if err := cond.Validate(pool.pendingNumber, pool.currentState); err != nil {
+				log.Trace("Removed conditional transaction", "hash", hash, "err", err)
+				failed = append(failed, hash)
+			}
+		}
+		return true
+	}, true, true)
+
+	for _, hash := range failed {
+		pool.removeTx(hash, true)
+	}
+	conditionalTxMeter.Mark(int64(len(failed)))
+}
+
 // demoteUnexecutables removes invalid and processed transactions from the pools
 // executable/pending queue and any subsequent transactions that become unexecutable
 // are moved back into the future queue.
@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -34,6 +35,7 @@ import (
 	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/c88032111/go-gdtu/event"
 	"github.com/c88032111/go-gdtu/params"
+	"github.com/c88032111/go-gdtu/rlp"
 	"github.com/c88032111/go-gdtu/trie"
 )
 
@@ -52,6 +54,10 @@ type testBlockChain struct {
 	chainHeadFeed *event.Feed
 }
 
+func (bc *testBlockChain) Config() *params.ChainConfig {
+	return params.TestChainConfig
+}
+
 func (bc *testBlockChain) CurrentBlock() *types.Block {
 	return types.NewBlock(&types.Header{
 		GasLimit: bc.gasLimit,
@@ -370,6 +376,64 @@ func TestTransactionChainFork(t *testing.T) {
 	}
 }
 
+// Tests that transactions with local validity conditions are only accepted while
+// the conditions hold, and are dropped from the pool once they are invalidated.
+func TestTransactionConditional(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+
+	contract, slot := common.Address{0xde, 0xad}, common.Hash{0x01}
+	pool.currentState.SetState(contract, slot, common.Hash{0x01})
+
+	// Transactions with expired or unmatched conditions must be rejected
+	expired := transaction(0, 100000, key)
+	expired.SetConditional(&types.TransactionConditional{BlockNumberMax: big.NewInt(0)})
+	if err := pool.AddLocal(expired); !errors.Is(err, ErrConditionNotMet) {
+		t.Fatalf("expired transaction error mismatch: have %v, want %v", err, ErrConditionNotMet)
+	}
+	mismatch := transaction(0, 100000, key)
+	mismatch.SetConditional(&types.TransactionConditional{
+		KnownAccounts: map[common.Address]map[common.Hash]common.Hash{contract: {slot: common.Hash{0x02}}},
+	})
+	if err := pool.AddLocal(mismatch); !errors.Is(err, ErrConditionNotMet) {
+		t.Fatalf("mismatching transaction error mismatch: have %v, want %v", err, ErrConditionNotMet)
+	}
+	// Add a transaction whose conditions hold, followed by an unconditional one
+	tx0 := transaction(0, 100000, key)
+	tx0.SetConditional(&types.TransactionConditional{
+		BlockNumberMax: big.NewInt(1),
+		KnownAccounts:  map[common.Address]map[common.Hash]common.Hash{contract: {slot: common.Hash{0x01}}},
+	})
+	if err := pool.AddLocal(tx0); err != nil {
+		t.Fatalf("failed to add conditional transaction: %v", err)
+	}
+	if err := pool.AddLocal(transaction(1, 100000, key)); err != nil {
+		t.Fatalf("failed to add unconditional transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 2/0", pending, queued)
+	}
+	// Invalidate the conditions and ensure the transaction is dropped, with the
+	// subsequent one moved back into the future queue
+	pool.currentState.SetState(contract, slot, common.Hash{0x02})
+	<-pool.requestReset(nil, nil)
+
+	if pool.all.Get(tx0.Hash()) != nil {
+		t.Errorf("conditional transaction not dropped")
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 1 {
+		t.Errorf("pool stats mismatch: have %d/%d, want 0/1", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestTransactionDoubleNonce(t *testing.T) {
 	t.Parallel()
 
@@ -740,6 +804,37 @@ func TestTransactionGapFilling(t *testing.T) {
 	}
 }
 
+// Tests that the content of a single account can be retrieved from the pool,
+// without the transactions of other accounts.
+func TestTransactionContentFrom(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	other, _ := crypto.GenerateKey()
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(other.PublicKey), big.NewInt(1000000))
+
+	pool.AddRemotesSync([]*types.Transaction{
+		transaction(1, 100000, key),
+		transaction(0, 100000, key),
+		transaction(3, 100000, key),
+		transaction(0, 100000, other),
+	})
+	pending, queued := pool.ContentFrom(account)
+	if len(pending) != 2 || pending[0].Nonce() != 0 || pending[1].Nonce() != 1 {
+		t.Fatalf("pending transactions mismatched: have %d", len(pending))
+	}
+	if len(queued) != 1 || queued[0].Nonce() != 3 {
+		t.Fatalf("queued transactions mismatched: have %d", len(queued))
+	}
+	if pending, queued := pool.ContentFrom(common.Address{0xde, 0xad}); len(pending) != 0 || len(queued) != 0 {
+		t.Fatalf("unknown account has transactions: %d pending, %d queued", len(pending), len(queued))
+	}
+}
+
 // Tests that if the transaction count belgdtuing to a single account goes above
 // some threshold, the higher transactions are dropped to prevent DOS attacks.
 func TestTransactionQueueAccountLimiting(t *testing.T) {
@@ -1859,6 +1954,93 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that remote transactions are journaled up to the configured limit and
+// that stale and expired ones are dropped when they are loaded back.
+func TestTransactionRemoteJournaling(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary path for the journal
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.RemoteJournal = filepath.Join(dir, "remotes.rlp")
+	config.RemoteJournalLimit = 3
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	local, _ := crypto.GenerateKey()
+	cheap, _ := crypto.GenerateKey()
+	pricy, _ := crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{local, cheap, pricy} {
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	if err := pool.AddLocal(pricedTransaction(0, 100000, big.NewInt(5), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	// Add more remotes than the journal limit, the queued and cheapest ones
+	// should be left out
+	remotes := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), cheap),
+		pricedTransaction(2, 100000, big.NewInt(1), cheap),
+		pricedTransaction(0, 100000, big.NewInt(2), pricy),
+		pricedTransaction(1, 100000, big.NewInt(2), pricy),
+	}
+	for i, err := range pool.AddRemotesSync(remotes) {
+		if err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", i, err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 4 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 4/1", pending, queued)
+	}
+	pool.Stop()
+
+	// Append an expired entry to the journal
+	expired, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(expired.PublicKey), big.NewInt(1000000000))
+
+	file, err := os.OpenFile(config.RemoteJournal, os.O_WRONLY|os.O_APPEND, 0755)
+	if err != nil {
+		t.Fatalf("failed to open remote journal: %v", err)
+	}
+	entry := remoteTxEntry{
+		Time:   uint64(time.Now().Add(-2 * config.Lifetime).Unix()),
+		Sender: crypto.PubkeyToAddress(expired.PublicKey),
+		Tx:     pricedTransaction(0, 100000, big.NewInt(1), expired),
+	}
+	if err := rlp.Encode(file, &entry); err != nil {
+		t.Fatalf("failed to append expired entry: %v", err)
+	}
+	file.Close()
+
+	// Include the first transaction of the pricy account, and restart the pool
+	statedb.SetNonce(crypto.PubkeyToAddress(pricy.PublicKey), 1)
+	blockchain = &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	<-pool.requestPromoteExecutables(newAccountSet(pool.signer))
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 2/0", pending, queued)
+	}
+	for _, tx := range []*types.Transaction{remotes[0], remotes[3]} {
+		if pool.Get(tx.Hash()) == nil {
+			t.Errorf("journaled transaction %x missing", tx.Hash())
+		}
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
@@ -82,6 +82,11 @@ type Header struct {
 	Extra       []byte         `json:"extraData"        gencodec:"required"`
 	MixDigest   common.Hash    `json:"mixHash"`
 	Nonce       BlockNonce     `json:"nonce"`
+
+	// Extensions are the RLP encoded header fields introduced by forks, in the
+	// order of HeaderExtensions. Nodes unaware of a fork still decode, hash and
+	// relay the headers carrying its fields.
+	Extensions []rlp.RawValue `json:"extensions,omitempty" rlp:"tail"`
 }
 
 // field type overrides for gencodec
@@ -92,6 +97,7 @@ type headerMarshaling struct {
 	GasUsed    hexutil.Uint64
 	Time       hexutil.Uint64
 	Extra      hexutil.Bytes
+	Extensions []hexutil.Bytes
 	Hash       common.Hash `json:"hash"` // adds call to Hash() in MarshalJSON
 }
 
@@ -106,7 +112,11 @@ var headerSize = common.StorageSize(reflect.TypeOf(Header{}).Size())
 // Size returns the approximate memory used by all internal contents. It is used
 // to approximate and limit the memory consumption of various caches.
 func (h *Header) Size() common.StorageSize {
-	return headerSize + common.StorageSize(len(h.Extra)+(h.Difficulty.BitLen()+h.Number.BitLen())/8)
+	size := headerSize + common.StorageSize(len(h.Extra)+(h.Difficulty.BitLen()+h.Number.BitLen())/8)
+	for _, ext := range h.Extensions {
+		size += common.StorageSize(len(ext))
+	}
+	return size
 }
 
 // SanityCheck checks a few basic things -- these checks are way beyond what
@@ -255,6 +265,12 @@ func CopyHeader(h *Header) *Header {
 		cpy.Extra = make([]byte, len(h.Extra))
 		copy(cpy.Extra, h.Extra)
 	}
+	if len(h.Extensions) > 0 {
+		cpy.Extensions = make([]rlp.RawValue, len(h.Extensions))
+		for i, ext := range h.Extensions {
+			cpy.Extensions[i] = common.CopyBytes(ext)
+		}
+	}
 	return &cpy
 }
 
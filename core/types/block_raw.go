@@ -0,0 +1,166 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/rlp"
+)
+
+var errRawBodyIndex = errors.New("transaction index out of range")
+
+// RawBody is a block body in its RLP encoding, as stored in the database and
+// sent over the network. It gives access to the transactions of the body without
+// decoding all of them, which is a large part of the cost of serving peers.
+type RawBody rlp.RawValue
+
+// rawTransactions splits the transaction list of the body into the encodings of
+// the individual transactions.
+func (b RawBody) rawTransactions() ([]rlp.RawValue, error) {
+	body, _, err := rlp.SplitList(b)
+	if err != nil {
+		return nil, err
+	}
+	list, _, err := rlp.SplitList(body)
+	if err != nil {
+		return nil, err
+	}
+	var txs []rlp.RawValue
+	for len(list) > 0 {
+		_, _, rest, err := rlp.Split(list)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, list[:len(list)-len(rest)])
+		list = rest
+	}
+	return txs, nil
+}
+
+// TransactionCount returns the number of transactions in the body.
+func (b RawBody) TransactionCount() (int, error) {
+	body, _, err := rlp.SplitList(b)
+	if err != nil {
+		return 0, err
+	}
+	list, _, err := rlp.SplitList(body)
+	if err != nil {
+		return 0, err
+	}
+	return rlp.CountValues(list)
+}
+
+// TransactionHashes returns the hashes of the transactions in the body, hashing
+// their encodings without decoding them.
+func (b RawBody) TransactionHashes() ([]common.Hash, error) {
+	txs, err := b.rawTransactions()
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		// Legacy transactions are hashed as a list, typed ones are wrapped in
+		// a string and hashed without the string header
+		kind, content, _, err := rlp.Split(tx)
+		if err != nil {
+			return nil, err
+		}
+		if kind == rlp.List {
+			hashes[i] = crypto.Keccak256Hash(tx)
+		} else {
+			hashes[i] = crypto.Keccak256Hash(content)
+		}
+	}
+	return hashes, nil
+}
+
+// TransactionTypes returns the types of the transactions in the body.
+func (b RawBody) TransactionTypes() ([]uint8, error) {
+	txs, err := b.rawTransactions()
+	if err != nil {
+		return nil, err
+	}
+	types := make([]uint8, len(txs))
+	for i, tx := range txs {
+		kind, content, _, err := rlp.Split(tx)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case kind == rlp.List:
+			types[i] = LegacyTxType
+		case len(content) == 0:
+			return nil, errEmptyTypedTx
+		default:
+			types[i] = content[0]
+		}
+	}
+	return types, nil
+}
+
+// Transaction decodes the transaction at the given index of the body.
+func (b RawBody) Transaction(index int) (*Transaction, error) {
+	txs, err := b.rawTransactions()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(txs) {
+		return nil, fmt.Errorf("%w: %d, %d transactions", errRawBodyIndex, index, len(txs))
+	}
+	tx := new(Transaction)
+	if err := rlp.DecodeBytes(txs[index], tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Decode decodes the entire body.
+func (b RawBody) Decode() (*Body, error) {
+	body := new(Body)
+	if err := rlp.DecodeBytes(b, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// EncodeReceiptsForNetwork converts the receipts of a block from their storage
+// encoding to the consensus encoding served to peers, which includes the bloom
+// filter and the transaction type of each receipt. The types are taken from the
+// raw body of the block, so the transactions don't need to be decoded.
+func EncodeReceiptsForNetwork(stored rlp.RawValue, body RawBody) (rlp.RawValue, error) {
+	var receipts []*ReceiptForStorage
+	if err := rlp.DecodeBytes(stored, &receipts); err != nil {
+		return nil, err
+	}
+	types, err := body.TransactionTypes()
+	if err != nil {
+		return nil, err
+	}
+	if len(types) != len(receipts) {
+		return nil, fmt.Errorf("receipt count mismatch: %d transactions, %d receipts", len(types), len(receipts))
+	}
+	out := make(Receipts, len(receipts))
+	for i, receipt := range receipts {
+		out[i] = (*Receipt)(receipt)
+		out[i].Type = types[i]
+	}
+	return rlp.EncodeToBytes(out)
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/rlp"
+)
+
+// Tests that the transactions of a raw body are accessible without decoding
+// the entire body, for both legacy and typed transactions.
+func TestRawBody(t *testing.T) {
+	to := common.Address{0xde, 0xad}
+	body := &Body{
+		Transactions: Transactions{
+			NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1), nil),
+			NewTx(&AccessListTx{ChainID: big.NewInt(1), Nonce: 1, To: &to, Value: big.NewInt(2), Gas: 30000, GasPrice: big.NewInt(1), AccessList: AccessList{{Address: to}}}),
+			NewTransaction(2, to, big.NewInt(3), 21000, big.NewInt(1), []byte{0x01}),
+		},
+		Uncles: []*Header{{Number: big.NewInt(1), Difficulty: big.NewInt(1)}},
+	}
+	enc, err := rlp.EncodeToBytes(body)
+	if err != nil {
+		t.Fatalf("failed to encode body: %v", err)
+	}
+	raw := RawBody(enc)
+
+	if n, err := raw.TransactionCount(); err != nil || n != 3 {
+		t.Errorf("transaction count mismatch: have %d, want 3 (err %v)", n, err)
+	}
+	hashes, err := raw.TransactionHashes()
+	if err != nil {
+		t.Fatalf("failed to hash transactions: %v", err)
+	}
+	types, err := raw.TransactionTypes()
+	if err != nil {
+		t.Fatalf("failed to read transaction types: %v", err)
+	}
+	for i, tx := range body.Transactions {
+		if hashes[i] != tx.Hash() {
+			t.Errorf("tx %d: hash mismatch: have %x, want %x", i, hashes[i], tx.Hash())
+		}
+		if types[i] != tx.Type() {
+			t.Errorf("tx %d: type mismatch: have %d, want %d", i, types[i], tx.Type())
+		}
+		dec, err := raw.Transaction(i)
+		if err != nil {
+			t.Fatalf("tx %d: failed to decode: %v", i, err)
+		}
+		if dec.Hash() != tx.Hash() {
+			t.Errorf("tx %d: decoded hash mismatch: have %x, want %x", i, dec.Hash(), tx.Hash())
+		}
+	}
+	if _, err := raw.Transaction(3); !errors.Is(err, errRawBodyIndex) {
+		t.Errorf("out of range error mismatch: have %v, want %v", err, errRawBodyIndex)
+	}
+	dec, err := raw.Decode()
+	if err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if len(dec.Transactions) != 3 || len(dec.Uncles) != 1 || dec.Uncles[0].Hash() != body.Uncles[0].Hash() {
+		t.Errorf("decoded body mismatch")
+	}
+	// Check the conversion of the stored receipts to the network encoding
+	receipts := Receipts{
+		{Status: ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*Log{{Address: to, Topics: []common.Hash{{1}}}}},
+		{Status: ReceiptStatusFailed, CumulativeGasUsed: 51000, Logs: []*Log{}},
+		{Status: ReceiptStatusSuccessful, CumulativeGasUsed: 72000, Logs: []*Log{}},
+	}
+	stored := make([]*ReceiptForStorage, len(receipts))
+	for i, receipt := range receipts {
+		receipt.Type = body.Transactions[i].Type()
+		receipt.Bloom = CreateBloom(Receipts{receipt})
+		stored[i] = (*ReceiptForStorage)(receipt)
+	}
+	storedEnc, _ := rlp.EncodeToBytes(stored)
+	want, _ := rlp.EncodeToBytes(receipts)
+
+	have, err := EncodeReceiptsForNetwork(storedEnc, raw)
+	if err != nil {
+		t.Fatalf("failed to encode receipts: %v", err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Errorf("network receipts mismatch:\nhave %x\nwant %x", have, want)
+	}
+	if _, err := EncodeReceiptsForNetwork(storedEnc[:0], raw); err == nil {
+		t.Errorf("invalid receipts accepted")
+	}
+}
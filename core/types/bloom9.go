@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/common/hexutil"
 	"github.com/c88032111/go-gdtu/crypto"
 )
@@ -84,10 +85,7 @@ func (b Bloom) Bytes() []byte {
 
 // Test checks if the given topic is present in the bloom filter
 func (b Bloom) Test(topic []byte) bool {
-	i1, v1, i2, v2, i3, v3 := bloomValues(topic, make([]byte, 6))
-	return v1 == v1&b[i1] &&
-		v2 == v2&b[i2] &&
-		v3 == v3&b[i3]
+	return newBloomBits(topic, make([]byte, 6)).in(&b)
 }
 
 // MarshalText encodes b as a hex string with gd prefix.
@@ -158,3 +156,90 @@ func bloomValues(data []byte, hashbuf []byte) (uint, byte, uint, byte, uint, byt
 func BloomLookup(bin Bloom, topic bytesBacked) bool {
 	return bin.Test(topic.Bytes())
 }
+
+// bloomBits are the three bits set in a bloom filter for a single value.
+type bloomBits struct {
+	i1, i2, i3 uint
+	v1, v2, v3 byte
+}
+
+// newBloomBits calculates the bloom bits of the given value.
+func newBloomBits(data []byte, buf []byte) bloomBits {
+	var bits bloomBits
+	bits.i1, bits.v1, bits.i2, bits.v2, bits.i3, bits.v3 = bloomValues(data, buf)
+	return bits
+}
+
+// in checks whether all the bits are set in the bloom filter.
+func (bits bloomBits) in(b *Bloom) bool {
+	return bits.v1 == bits.v1&b[bits.i1] &&
+		bits.v2 == bits.v2&b[bits.i2] &&
+		bits.v3 == bits.v3&b[bits.i3]
+}
+
+// BloomProbe is a log filter query (addresses and topics, with the same meaning
+// as in gdtu_getLogs) converted into bloom bits, to cheaply check whether a block
+// may contain matching logs based on its header bloom, without hashing the query
+// for every block.
+//
+// Bloom filters have false positives, so a matching block still needs its logs
+// filtered, but a non-matching block is guaranteed not to contain matching logs.
+type BloomProbe struct {
+	addresses []bloomBits   // Any of them needs to be present, empty matches all
+	topics    [][]bloomBits // Any of them needs to be present per position, empty matches all
+}
+
+// NewBloomProbe creates a bloom probe for logs emitted by any of the addresses,
+// with topics matching the given criteria: one set of alternatives per topic
+// position, an empty set being a wildcard.
+func NewBloomProbe(addresses []common.Address, topics [][]common.Hash) *BloomProbe {
+	var (
+		buf   = make([]byte, 6)
+		probe = &BloomProbe{
+			addresses: make([]bloomBits, len(addresses)),
+			topics:    make([][]bloomBits, len(topics)),
+		}
+	)
+	for i, addr := range addresses {
+		probe.addresses[i] = newBloomBits(addr.Bytes(), buf)
+	}
+	for i, sub := range topics {
+		probe.topics[i] = make([]bloomBits, len(sub))
+		for j, topic := range sub {
+			probe.topics[i][j] = newBloomBits(topic.Bytes(), buf)
+		}
+	}
+	return probe
+}
+
+// Test checks whether logs matching the probe may be present in a bloom filter.
+func (p *BloomProbe) Test(bloom Bloom) bool {
+	if !anyBloomBits(&bloom, p.addresses) {
+		return false
+	}
+	for _, sub := range p.topics {
+		if !anyBloomBits(&bloom, sub) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestHeader checks whether logs matching the probe may be present in a block.
+func (p *BloomProbe) TestHeader(header *Header) bool {
+	return p.Test(header.Bloom)
+}
+
+// anyBloomBits checks whether any of the values is present in the bloom filter,
+// an empty set of values matching all filters.
+func anyBloomBits(bloom *Bloom, values []bloomBits) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, bits := range values {
+		if bits.in(bloom) {
+			return true
+		}
+	}
+	return false
+}
@@ -76,6 +76,54 @@ func TestBloomExtensively(t *testing.T) {
 	}
 }
 
+func TestBloomProbe(t *testing.T) {
+	var (
+		addr1  = common.HexToAddress("gd1111111111111111111111111111111111111111")
+		addr2  = common.HexToAddress("gd2222222222222222222222222222222222222222")
+		topic1 = common.HexToHash("gd01")
+		topic2 = common.HexToHash("gd02")
+		topic3 = common.HexToHash("gd03")
+	)
+	bloom := BytesToBloom(LogsBloom([]*Log{{Address: addr1, Topics: []common.Hash{topic1, topic2}}}))
+
+	tests := []struct {
+		addresses []common.Address
+		topics    [][]common.Hash
+		want      bool
+	}{
+		{nil, nil, true},
+		{[]common.Address{addr1}, nil, true},
+		{[]common.Address{addr2}, nil, false},
+		{[]common.Address{addr2, addr1}, nil, true},
+		{nil, [][]common.Hash{{topic1}}, true},
+		{nil, [][]common.Hash{{topic3}}, false},
+		{nil, [][]common.Hash{{}, {topic2}}, true},
+		{nil, [][]common.Hash{{topic3, topic2}, {}}, true},
+		{[]common.Address{addr1}, [][]common.Hash{{topic1}, {topic3}}, false},
+		{[]common.Address{addr1}, [][]common.Hash{{topic1}, {topic2}}, true},
+	}
+	for i, tt := range tests {
+		probe := NewBloomProbe(tt.addresses, tt.topics)
+		if have := probe.Test(bloom); have != tt.want {
+			t.Errorf("test %d: probe result mismatch: have %v, want %v", i, have, tt.want)
+		}
+		if have := probe.TestHeader(&Header{Bloom: bloom}); have != tt.want {
+			t.Errorf("test %d: header probe result mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+func BenchmarkBloomProbe(b *testing.B) {
+	var (
+		bloom = BytesToBloom(LogsBloom([]*Log{{Address: common.BytesToAddress([]byte("test"))}}))
+		probe = NewBloomProbe([]common.Address{common.BytesToAddress([]byte("test"))}, [][]common.Hash{{common.HexToHash("gd01")}})
+	)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		probe.Test(bloom)
+	}
+}
+
 func BenchmarkBloom9(b *testing.B) {
 	test := []byte("testestestest")
 	for i := 0; i < b.N; i++ {
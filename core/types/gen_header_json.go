@@ -9,6 +9,7 @@ import (
 
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/rlp"
 )
 
 var _ = (*headerMarshaling)(nil)
@@ -16,22 +17,23 @@ var _ = (*headerMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (h Header) MarshalJSON() ([]byte, error) {
 	type Header struct {
-		ParentHash  common.Hash    `json:"parentHash"       gencodec:"required"`
-		UncleHash   common.Hash    `json:"sha3Uncles"       gencodec:"required"`
-		Coinbase    common.Address `json:"miner"            gencodec:"required"`
-		Root        common.Hash    `json:"stateRoot"        gencodec:"required"`
-		TxHash      common.Hash    `json:"transactionsRoot" gencodec:"required"`
-		ReceiptHash common.Hash    `json:"receiptsRoot"     gencodec:"required"`
-		Bloom       Bloom          `json:"logsBloom"        gencodec:"required"`
-		Difficulty  *hexutil.Big   `json:"difficulty"       gencodec:"required"`
-		Number      *hexutil.Big   `json:"number"           gencodec:"required"`
-		GasLimit    hexutil.Uint64 `json:"gasLimit"         gencodec:"required"`
-		GasUsed     hexutil.Uint64 `json:"gasUsed"          gencodec:"required"`
-		Time        hexutil.Uint64 `json:"timestamp"        gencodec:"required"`
-		Extra       hexutil.Bytes  `json:"extraData"        gencodec:"required"`
-		MixDigest   common.Hash    `json:"mixHash"`
-		Nonce       BlockNonce     `json:"nonce"`
-		Hash        common.Hash    `json:"hash"`
+		ParentHash  common.Hash     `json:"parentHash"       gencodec:"required"`
+		UncleHash   common.Hash     `json:"sha3Uncles"       gencodec:"required"`
+		Coinbase    common.Address  `json:"miner"            gencodec:"required"`
+		Root        common.Hash     `json:"stateRoot"        gencodec:"required"`
+		TxHash      common.Hash     `json:"transactionsRoot" gencodec:"required"`
+		ReceiptHash common.Hash     `json:"receiptsRoot"     gencodec:"required"`
+		Bloom       Bloom           `json:"logsBloom"        gencodec:"required"`
+		Difficulty  *hexutil.Big    `json:"difficulty"       gencodec:"required"`
+		Number      *hexutil.Big    `json:"number"           gencodec:"required"`
+		GasLimit    hexutil.Uint64  `json:"gasLimit"         gencodec:"required"`
+		GasUsed     hexutil.Uint64  `json:"gasUsed"          gencodec:"required"`
+		Time        hexutil.Uint64  `json:"timestamp"        gencodec:"required"`
+		Extra       hexutil.Bytes   `json:"extraData"        gencodec:"required"`
+		MixDigest   common.Hash     `json:"mixHash"`
+		Nonce       BlockNonce      `json:"nonce"`
+		Extensions  []hexutil.Bytes `json:"extensions,omitempty" rlp:"tail"`
+		Hash        common.Hash     `json:"hash"`
 	}
 	var enc Header
 	enc.ParentHash = h.ParentHash
@@ -49,6 +51,12 @@ func (h Header) MarshalJSON() ([]byte, error) {
 	enc.Extra = h.Extra
 	enc.MixDigest = h.MixDigest
 	enc.Nonce = h.Nonce
+	if h.Extensions != nil {
+		enc.Extensions = make([]hexutil.Bytes, len(h.Extensions))
+		for k, v := range h.Extensions {
+			enc.Extensions[k] = hexutil.Bytes(v)
+		}
+	}
 	enc.Hash = h.Hash()
 	return json.Marshal(&enc)
 }
@@ -71,6 +79,7 @@ func (h *Header) UnmarshalJSON(input []byte) error {
 		Extra       *hexutil.Bytes  `json:"extraData"        gencodec:"required"`
 		MixDigest   *common.Hash    `json:"mixHash"`
 		Nonce       *BlockNonce     `json:"nonce"`
+		Extensions  []hexutil.Bytes `json:"extensions,omitempty" rlp:"tail"`
 	}
 	var dec Header
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -134,5 +143,11 @@ func (h *Header) UnmarshalJSON(input []byte) error {
 	if dec.Nonce != nil {
 		h.Nonce = *dec.Nonce
 	}
+	if dec.Extensions != nil {
+		h.Extensions = make([]rlp.RawValue, len(dec.Extensions))
+		for k, v := range dec.Extensions {
+			h.Extensions[k] = rlp.RawValue(v)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,115 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/c88032111/go-gdtu/params"
+	"github.com/c88032111/go-gdtu/rlp"
+)
+
+// ErrHeaderExtensions is returned if the extension fields of a header don't
+// match the forks active at its number.
+var ErrHeaderExtensions = errors.New("invalid header extensions")
+
+// HeaderExtension is a header field introduced by a fork. Extension fields are
+// appended to the RLP list of the header in registration order, so old headers
+// keep their encoding and hash, and nodes unaware of the fork still decode the
+// new ones.
+//
+// The activation blocks of the extensions are part of the fork ID, so peers
+// disagreeing on the header layout are rejected in the protocol handshake.
+type HeaderExtension struct {
+	Name  string                                    // Name of the field, for error reporting
+	Block func(config *params.ChainConfig) *big.Int // Activation block in a chain config, nil if not scheduled
+}
+
+// Active returns whgdtuer the field is mandatory at the given block number.
+func (ext HeaderExtension) Active(config *params.ChainConfig, number *big.Int) bool {
+	block := ext.Block(config)
+	return block != nil && block.Cmp(number) <= 0
+}
+
+// HeaderExtensions is the registry of the header extension fields, in the order
+// of their activation. A fork introducing a header field registers it here and
+// accesses it through the index of its registration.
+var HeaderExtensions []HeaderExtension
+
+// VerifyHeaderExtensions checks that the header carries exactly the extension
+// fields of the forks active at its number.
+func VerifyHeaderExtensions(config *params.ChainConfig, header *Header) error {
+	want := 0
+	for _, ext := range HeaderExtensions {
+		if !ext.Active(config, header.Number) {
+			break
+		}
+		want++
+	}
+	if len(header.Extensions) == want {
+		return nil
+	}
+	if len(header.Extensions) > want {
+		return fmt.Errorf("%w: %d fields, %d activated", ErrHeaderExtensions, len(header.Extensions), want)
+	}
+	return fmt.Errorf("%w: missing %s field", ErrHeaderExtensions, HeaderExtensions[len(header.Extensions)].Name)
+}
+
+// Extension decodes the extension field at the given registry index into val,
+// reporting whgdtuer the header carries the field.
+func (h *Header) Extension(index int, val interface{}) (bool, error) {
+	if index >= len(h.Extensions) {
+		return false, nil
+	}
+	if err := rlp.DecodeBytes(h.Extensions[index], val); err != nil {
+		return true, fmt.Errorf("invalid header extension %d: %v", index, err)
+	}
+	return true, nil
+}
+
+// SetExtension encodes val into the extension field at the given registry index.
+// The fields before it must already be set, as extensions can't be skipped.
+func (h *Header) SetExtension(index int, val interface{}) error {
+	if index > len(h.Extensions) {
+		return fmt.Errorf("header extension %d set before extension %d", index, len(h.Extensions))
+	}
+	enc, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return err
+	}
+	if index == len(h.Extensions) {
+		h.Extensions = append(h.Extensions, enc)
+	} else {
+		h.Extensions[index] = enc
+	}
+	return nil
+}
+
+// DecodeRLP implements rlp.Decoder, leaving the extensions of headers without
+// any extension fields nil rather than empty.
+func (h *Header) DecodeRLP(s *rlp.Stream) error {
+	type header Header // drop the Methods to avoid recursion
+	if err := s.Decode((*header)(h)); err != nil {
+		return err
+	}
+	if len(h.Extensions) == 0 {
+		h.Extensions = nil
+	}
+	return nil
+}
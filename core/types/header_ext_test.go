@@ -0,0 +1,176 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/params"
+	"github.com/c88032111/go-gdtu/rlp"
+)
+
+func newExtensionTestHeader() *Header {
+	return &Header{
+		ParentHash: common.Hash{0x01},
+		Coinbase:   common.Address{0xde, 0xad},
+		Difficulty: big.NewInt(131072),
+		Number:     big.NewInt(100),
+		GasLimit:   8000000,
+		Time:       1600000000,
+		Extra:      []byte("test"),
+	}
+}
+
+// Tests that headers without extensions keep their legacy encoding, and that
+// extension fields are appended to it and survive a decoding round trip.
+func TestHeaderExtensionsEncoding(t *testing.T) {
+	header := newExtensionTestHeader()
+	legacy, err := rlp.EncodeToBytes([]interface{}{
+		header.ParentHash, header.UncleHash, header.Coinbase, header.Root, header.TxHash,
+		header.ReceiptHash, header.Bloom, header.Difficulty, header.Number, header.GasLimit,
+		header.GasUsed, header.Time, header.Extra, header.MixDigest, header.Nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+	if !bytes.Equal(enc, legacy) {
+		t.Fatalf("header encoding changed:\nhave %x\nwant %x", enc, legacy)
+	}
+	var dec Header
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatalf("failed to decode header: %v", err)
+	}
+	if dec.Extensions != nil {
+		t.Fatalf("legacy header decoded with extensions: %x", dec.Extensions)
+	}
+	// Extend the header with fields unknown to this node
+	if err := header.SetExtension(0, big.NewInt(1000000000)); err != nil {
+		t.Fatalf("failed to set extension: %v", err)
+	}
+	if err := header.SetExtension(1, common.Hash{0xff}); err != nil {
+		t.Fatalf("failed to set extension: %v", err)
+	}
+	if err := header.SetExtension(3, uint64(1)); err == nil {
+		t.Fatalf("extension set with gap")
+	}
+	if header.Hash() == dec.Hash() {
+		t.Fatalf("extensions not covered by the header hash")
+	}
+	enc, err = rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatalf("failed to encode extended header: %v", err)
+	}
+	dec = Header{}
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatalf("failed to decode extended header: %v", err)
+	}
+	if dec.Hash() != header.Hash() {
+		t.Fatalf("extended header hash mismatch: have %x, want %x", dec.Hash(), header.Hash())
+	}
+	var (
+		fee  big.Int
+		root common.Hash
+	)
+	if ok, err := dec.Extension(0, &fee); !ok || err != nil || fee.Int64() != 1000000000 {
+		t.Fatalf("extension 0 mismatch: %v %v %v", ok, err, &fee)
+	}
+	if ok, err := dec.Extension(1, &root); !ok || err != nil || root != (common.Hash{0xff}) {
+		t.Fatalf("extension 1 mismatch: %v %v %x", ok, err, root)
+	}
+	if ok, _ := dec.Extension(2, &root); ok {
+		t.Fatalf("missing extension reported present")
+	}
+	// Copies must not share the extensions of the original
+	cpy := CopyHeader(header)
+	cpy.Extensions[0][1] = 0x00
+	if cpy.Hash() == header.Hash() {
+		t.Fatalf("header copy shares extensions")
+	}
+}
+
+// Tests that extension fields round trip through the JSON encoding.
+func TestHeaderExtensionsJSON(t *testing.T) {
+	header := newExtensionTestHeader()
+	header.SetExtension(0, uint64(7))
+
+	blob, err := json.Marshal(header)
+	if err != nil {
+		t.Fatalf("failed to marshal header: %v", err)
+	}
+	var dec Header
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatalf("failed to unmarshal header: %v", err)
+	}
+	if dec.Hash() != header.Hash() {
+		t.Fatalf("header hash mismatch: have %x, want %x", dec.Hash(), header.Hash())
+	}
+	// Headers without extensions omit the field
+	blob, err = json.Marshal(newExtensionTestHeader())
+	if err != nil {
+		t.Fatalf("failed to marshal header: %v", err)
+	}
+	if bytes.Contains(blob, []byte("extensions")) {
+		t.Fatalf("empty extensions marshalled: %s", blob)
+	}
+}
+
+// Tests that headers are required to carry exactly the extension fields of the
+// activated forks.
+func TestVerifyHeaderExtensions(t *testing.T) {
+	defer func(exts []HeaderExtension) { HeaderExtensions = exts }(HeaderExtensions)
+	HeaderExtensions = []HeaderExtension{
+		{Name: "first", Block: func(*params.ChainConfig) *big.Int { return big.NewInt(10) }},
+		{Name: "second", Block: func(*params.ChainConfig) *big.Int { return big.NewInt(20) }},
+		{Name: "unscheduled", Block: func(*params.ChainConfig) *big.Int { return nil }},
+	}
+	tests := []struct {
+		number int64
+		fields int
+		fail   bool
+	}{
+		{number: 5, fields: 0},
+		{number: 5, fields: 1, fail: true},
+		{number: 10, fields: 0, fail: true},
+		{number: 10, fields: 1},
+		{number: 15, fields: 2, fail: true},
+		{number: 20, fields: 1, fail: true},
+		{number: 20, fields: 2},
+	}
+	for i, tt := range tests {
+		header := newExtensionTestHeader()
+		header.Number = big.NewInt(tt.number)
+		for j := 0; j < tt.fields; j++ {
+			header.SetExtension(j, uint64(j))
+		}
+		err := VerifyHeaderExtensions(params.TestChainConfig, header)
+		if tt.fail != (err != nil) {
+			t.Errorf("test %d: error mismatch: have %v, want failure %v", i, err, tt.fail)
+		}
+		if err != nil && !errors.Is(err, ErrHeaderExtensions) {
+			t.Errorf("test %d: error type mismatch: %v", i, err)
+		}
+	}
+}
@@ -49,6 +49,8 @@ type Transaction struct {
 	inner TxData    // Consensus contents of a transaction
 	time  time.Time // Time first seen locally (spam avoidance)
 
+	conditional *TransactionConditional // Local validity conditions (not part of consensus)
+
 	// caches
 	hash atomic.Value
 	size atomic.Value
@@ -301,6 +303,29 @@ func (tx *Transaction) GasPriceIntCmp(other *big.Int) int {
 	return tx.inner.gasPrice().Cmp(other)
 }
 
+// Time returns the time the transaction was first seen locally.
+func (tx *Transaction) Time() time.Time {
+	return tx.time
+}
+
+// SetTime overrides the time the transaction was first seen locally, used when
+// restoring transactions seen before a restart.
+func (tx *Transaction) SetTime(t time.Time) {
+	tx.time = t
+}
+
+// Conditional returns the local validity conditions of the transaction, or nil
+// if it may be included unconditionally.
+func (tx *Transaction) Conditional() *TransactionConditional {
+	return tx.conditional
+}
+
+// SetConditional attaches local validity conditions to the transaction. It must
+// be called before the transaction is handed to the pool.
+func (tx *Transaction) SetConditional(c *TransactionConditional) {
+	tx.conditional = c
+}
+
 // Hash returns the transaction hash.
 func (tx *Transaction) Hash() common.Hash {
 	if hash := tx.hash.Load(); hash != nil {
@@ -0,0 +1,60 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/c88032111/go-gdtu/common"
+)
+
+// ErrConditionNotMet is returned if a transaction's validity conditions do not
+// hold against the block and state it is checked against.
+var ErrConditionNotMet = errors.New("transaction conditions not met")
+
+// StorageReader is the subset of the state needed to check the storage
+// conditions of a transaction.
+type StorageReader interface {
+	GetState(addr common.Address, slot common.Hash) common.Hash
+}
+
+// TransactionConditional is a set of validity conditions attached locally to a
+// transaction. They are not part of the consensus encoding and are neither
+// signed, hashed nor propagated to the network: they only instruct the local
+// node when it may pool and include the transaction.
+type TransactionConditional struct {
+	BlockNumberMax *big.Int                                       // Highest block number the transaction may be included in (nil = unbounded)
+	KnownAccounts  map[common.Address]map[common.Hash]common.Hash // Storage slots that must hold the given values
+}
+
+// Validate checks whether the conditions hold for inclusion into the block with
+// the given number, on top of the given state.
+func (c *TransactionConditional) Validate(number *big.Int, state StorageReader) error {
+	if c.BlockNumberMax != nil && number.Cmp(c.BlockNumberMax) > 0 {
+		return fmt.Errorf("%w: block number %v above maximum %v", ErrConditionNotMet, number, c.BlockNumberMax)
+	}
+	for addr, slots := range c.KnownAccounts {
+		for slot, want := range slots {
+			if have := state.GetState(addr, slot); have != want {
+				return fmt.Errorf("%w: storage slot %x of %x is %x, want %x", ErrConditionNotMet, slot, addr, have, want)
+			}
+		}
+	}
+	return nil
+}
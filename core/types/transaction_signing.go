@@ -142,6 +142,16 @@ func Sender(signer Signer, tx *Transaction) (common.Address, error) {
 	return addr, nil
 }
 
+// CacheSender stores a sender address derived elsewhere into the signature cache
+// of the transaction, allowing subsequent Sender calls with an equal signer to
+// skip the signature recovery. It is meant to share recovered senders between
+// distinct instances of the same transaction (e.g. one received into the pool
+// and one decoded from a block body), so the caller must guarantee that the
+// address was derived from a transaction with the same hash and signer.
+func CacheSender(signer Signer, tx *Transaction, from common.Address) {
+	tx.from.Store(sigCache{signer: signer, from: from})
+}
+
 // Signer encapsulates transaction signature handling. The name of this type is slightly
 // misleading because Signers don't actually sign, they're just for validating and
 // processing of signatures.
@@ -0,0 +1,163 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/metrics"
+)
+
+var (
+	codeCacheHitMeter      = metrics.NewRegisteredMeter("vm/codecache/hit", nil)
+	codeCacheMissMeter     = metrics.NewRegisteredMeter("vm/codecache/miss", nil)
+	codeCacheAnalysisMeter = metrics.NewRegisteredMeter("vm/codecache/analysis", nil)
+	codeCacheSizeGauge     = metrics.NewRegisteredGauge("vm/codecache/size", nil)
+)
+
+// codeCacheEntry is a contract code cached along with its JUMPDEST analysis,
+// which is only computed once a jump is executed.
+type codeCacheEntry struct {
+	hash     common.Hash
+	code     []byte
+	analysis bitvec
+}
+
+// size returns the memory used by the entry.
+func (e *codeCacheEntry) size() int {
+	return len(e.code) + len(e.analysis)
+}
+
+// CodeCache is a size bounded LRU cache of contract codes and their JUMPDEST
+// analyses keyed by code hash. It is meant to be shared between EVM instances,
+// so hot contracts are neither copied out of the state nor analysed again by
+// every transaction and call. It is safe for concurrent use.
+//
+// The cached codes are shared, the EVM never modifies the code of a contract.
+type CodeCache struct {
+	limit int                           // Maximum size of the cached codes and analyses
+	size  int                           // Current size of the cached codes and analyses
+	items map[common.Hash]*list.Element // Cached entries keyed by code hash
+	order *list.List                    // Cached entries, most recently used first
+	lock  sync.Mutex
+}
+
+// NewCodeCache creates a code cache holding at most limit bytes of contract
+// code and analysis.
+func NewCodeCache(limit int) *CodeCache {
+	return &CodeCache{
+		limit: limit,
+		items: make(map[common.Hash]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Code retrieves the code with the given hash, or nil if it's not cached.
+func (c *CodeCache) Code(hash common.Hash) []byte {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.items[hash]
+	if !ok {
+		codeCacheMissMeter.Mark(1)
+		return nil
+	}
+	codeCacheHitMeter.Mark(1)
+	c.order.MoveToFront(elem)
+	return elem.Value.(*codeCacheEntry).code
+}
+
+// Add inserts a code into the cache, evicting the least recently used ones if
+// the cache grows over its limit. Empty codes and codes larger than the whole
+// cache are not cached.
+func (c *CodeCache) Add(hash common.Hash, code []byte) {
+	if len(code) == 0 || len(code) > c.limit {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.items[hash]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	entry := &codeCacheEntry{hash: hash, code: code}
+	c.items[hash] = c.order.PushFront(entry)
+	c.grow(entry.size())
+}
+
+// analysis retrieves the JUMPDEST analysis of a code, computing and caching it
+// if it isn't known yet.
+func (c *CodeCache) analysis(hash common.Hash, code []byte) bitvec {
+	c.lock.Lock()
+	elem, ok := c.items[hash]
+	if ok && elem.Value.(*codeCacheEntry).analysis != nil {
+		c.order.MoveToFront(elem)
+		analysis := elem.Value.(*codeCacheEntry).analysis
+		c.lock.Unlock()
+		return analysis
+	}
+	c.lock.Unlock()
+
+	// Analyse the code without holding the lock and cache the result
+	codeCacheAnalysisMeter.Mark(1)
+	analysis := codeBitmap(code)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.items[hash]; ok {
+		entry := elem.Value.(*codeCacheEntry)
+		if entry.analysis == nil {
+			entry.analysis = analysis
+			c.grow(len(analysis))
+		}
+		return analysis
+	}
+	if len(code)+len(analysis) > c.limit {
+		return analysis
+	}
+	entry := &codeCacheEntry{hash: hash, code: code, analysis: analysis}
+	c.items[hash] = c.order.PushFront(entry)
+	c.grow(entry.size())
+	return analysis
+}
+
+// grow accounts for the given amount of added data, evicting the least recently
+// used entries until the cache fits into its limit. The caller must hold the lock.
+func (c *CodeCache) grow(size int) {
+	c.size += size
+	for c.size > c.limit {
+		elem := c.order.Back()
+		entry := elem.Value.(*codeCacheEntry)
+
+		c.order.Remove(elem)
+		delete(c.items, entry.hash)
+		c.size -= entry.size()
+	}
+	codeCacheSizeGauge.Update(int64(c.size))
+}
+
+// Len returns the number of cached codes.
+func (c *CodeCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.items)
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/state"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// Tests that the code cache evicts the least recently used codes once it grows
+// over its limit and refuses codes not fitting into it at all.
+func TestCodeCacheEviction(t *testing.T) {
+	cache := NewCodeCache(100)
+
+	codes := make([][]byte, 4)
+	for i := range codes {
+		codes[i] = bytes.Repeat([]byte{byte(i + 1)}, 40)
+	}
+	hash := func(i int) common.Hash { return crypto.Keccak256Hash(codes[i]) }
+
+	cache.Add(hash(0), codes[0])
+	cache.Add(hash(1), codes[1])
+	if cache.Code(hash(0)) == nil { // Touch the first code so the second is evicted
+		t.Fatalf("code 0 missing")
+	}
+	cache.Add(hash(2), codes[2])
+
+	if cache.Len() != 2 {
+		t.Fatalf("cached code count mismatch: have %d, want %d", cache.Len(), 2)
+	}
+	if code := cache.Code(hash(0)); !bytes.Equal(code, codes[0]) {
+		t.Errorf("code 0 mismatch: have %x, want %x", code, codes[0])
+	}
+	if code := cache.Code(hash(1)); code != nil {
+		t.Errorf("code 1 not evicted")
+	}
+	if code := cache.Code(hash(2)); !bytes.Equal(code, codes[2]) {
+		t.Errorf("code 2 mismatch: have %x, want %x", code, codes[2])
+	}
+	// Ensure empty and oversized codes are not cached
+	cache.Add(crypto.Keccak256Hash(nil), nil)
+
+	huge := make([]byte, 101)
+	cache.Add(crypto.Keccak256Hash(huge), huge)
+
+	if cache.Len() != 2 {
+		t.Fatalf("cached code count mismatch: have %d, want %d", cache.Len(), 2)
+	}
+}
+
+// Tests that JUMPDEST analyses are computed once and served from the cache for
+// every contract afterwards, both for cached and uncached codes.
+func TestCodeCacheAnalysis(t *testing.T) {
+	cache := NewCodeCache(1024)
+
+	code := []byte{byte(PUSH1), 0x04, byte(JUMP), 0xfe, byte(JUMPDEST), byte(STOP)}
+	hash := crypto.Keccak256Hash(code)
+
+	first := cache.analysis(hash, code)
+	if cache.Len() != 1 {
+		t.Fatalf("analysed code not cached")
+	}
+	second := cache.analysis(hash, code)
+	if &first[0] != &second[0] {
+		t.Errorf("analysis recomputed for cached code")
+	}
+	if !first.codeSegment(4) || first.codeSegment(1) {
+		t.Errorf("analysis mismatch: %x", first)
+	}
+}
+
+// Tests that EVMs sharing a code cache load contract codes through it and
+// produce the same results as without a cache.
+func TestCodeCacheSharedEVMs(t *testing.T) {
+	var (
+		address = common.Address{0xde, 0xad}
+		code    = []byte{byte(PUSH1), 0x04, byte(JUMP), 0xfe, byte(JUMPDEST), byte(PUSH1), 0x2a, byte(PUSH1), 0x00, byte(MSTORE), byte(PUSH1), 0x20, byte(PUSH1), 0x00, byte(RETURN)}
+		cache   = NewCodeCache(1024)
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.CreateAccount(address)
+	statedb.SetCode(address, code)
+
+	vmctx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+	}
+	for i := 0; i < 2; i++ {
+		vmenv := NewEVM(vmctx, TxContext{}, statedb, params.AllGdtuashProtocolChanges, Config{CodeCache: cache})
+		ret, _, err := vmenv.Call(AccountRef(common.Address{}), address, nil, 100000, new(big.Int))
+		if err != nil {
+			t.Fatalf("run %d: call failed: %v", i, err)
+		}
+		if new(big.Int).SetBytes(ret).Uint64() != 0x2a {
+			t.Fatalf("run %d: return mismatch: have %x, want %x", i, ret, 0x2a)
+		}
+	}
+	if cache.Len() != 1 {
+		t.Fatalf("cached code count mismatch: have %d, want %d", cache.Len(), 1)
+	}
+	entry := cache.items[crypto.Keccak256Hash(code)].Value.(*codeCacheEntry)
+	if entry.analysis == nil {
+		t.Errorf("jumpdest analysis not cached")
+	}
+}
@@ -52,6 +52,7 @@ type Contract struct {
 
 	jumpdests map[common.Hash]bitvec // Aggregated result of JUMPDEST analysis.
 	analysis  bitvec                 // Locally cached result of JUMPDEST analysis
+	codeCache *CodeCache             // Shared cache of JUMPDEST analyses, if any
 
 	Code     []byte
 	CodeHash common.Hash
@@ -110,9 +111,13 @@ func (c *Contract) isCode(udest uint64) bool {
 		// Does parent context have the analysis?
 		analysis, exist := c.jumpdests[c.CodeHash]
 		if !exist {
-			// Do the analysis and save in parent context
-			// We do not need to store it in c.analysis
-			analysis = codeBitmap(c.Code)
+			// Do the analysis (or retrieve it from the shared cache) and
+			// save in parent context. We do not need to store it in c.analysis
+			if c.codeCache != nil {
+				analysis = c.codeCache.analysis(c.CodeHash, c.Code)
+			} else {
+				analysis = codeBitmap(c.Code)
+			}
 			c.jumpdests[c.CodeHash] = analysis
 		}
 		// Also stash it in current contract for faster access
@@ -0,0 +1,374 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
+)
+
+var (
+	// ErrDebuggerDone is returned when resuming a debugger whose execution has
+	// already ended.
+	ErrDebuggerDone = errors.New("execution ended")
+
+	// errDebuggerStopped is the error of executions stopped by the debugger.
+	errDebuggerStopped = errors.New("stopped by debugger")
+)
+
+// Reasons why a debugged execution paused.
+const (
+	DebugPauseStep       = "step"       // Requested number of steps executed
+	DebugPauseBreakpoint = "breakpoint" // Breakpoint hit
+	DebugPauseDone       = "done"       // Execution ended
+)
+
+// Breakpoint is a condition pausing a debugged execution before executing an
+// operation. All the set fields of a breakpoint have to match.
+type Breakpoint struct {
+	Address *common.Address // Executing contract, any if nil
+	PC      *uint64         // Program counter, any if nil
+	Op      *OpCode         // Operation, any if nil
+	Slot    *common.Hash    // Storage key accessed by SLOAD or SSTORE, any if nil
+}
+
+// matches reports whgdtuer the breakpoint matches the given step.
+func (b *Breakpoint) matches(step *DebugStep, stack *Stack) bool {
+	if b.Address != nil && *b.Address != step.Address {
+		return false
+	}
+	if b.PC != nil && *b.PC != step.PC {
+		return false
+	}
+	if b.Op != nil && *b.Op != step.Op {
+		return false
+	}
+	if b.Slot != nil {
+		if (step.Op != SLOAD && step.Op != SSTORE) || stack.len() < 1 {
+			return false
+		}
+		if common.Hash(stack.Back(0).Bytes32()) != *b.Slot {
+			return false
+		}
+	}
+	return true
+}
+
+// MemoryDelta is a modified region of the memory.
+type MemoryDelta struct {
+	Offset uint64        `json:"offset"`
+	Data   hexutil.Bytes `json:"data"`
+}
+
+// StorageDelta is a storage slot written by an operation.
+type StorageDelta struct {
+	Address common.Address `json:"address"`
+	Key     common.Hash    `json:"key"`
+	Value   common.Hash    `json:"value"`
+}
+
+// DebugStep is the state of the interpreter before executing an operation. The
+// stack, memory and storage are reported as the changes since the previous
+// step, so applying the steps in order reproduces the full state: pop
+// StackPops items and push StackPushes, resize the memory to MemorySize and
+// copy in the Memory deltas, then apply the Storage writes. Storage writes are
+// not rolled back if the frame writing them reverts.
+type DebugStep struct {
+	Index   uint64         `json:"index"`
+	Depth   int            `json:"depth"`
+	Address common.Address `json:"address"`
+	PC      uint64         `json:"pc"`
+	Op      OpCode         `json:"-"`
+	OpName  string         `json:"op"`
+	Gas     uint64         `json:"gas"`
+	Cost    uint64         `json:"gasCost"`
+	Error   string         `json:"error,omitempty"`
+
+	StackPops   int            `json:"stackPops"`
+	StackPushes []common.Hash  `json:"stackPushes"`
+	MemorySize  int            `json:"memorySize"`
+	Memory      []MemoryDelta  `json:"memory,omitempty"`
+	Storage     []StorageDelta `json:"storage,omitempty"`
+}
+
+// DebugResult reports the steps executed since the debugged execution was last
+// paused, and why it paused again.
+type DebugResult struct {
+	Steps      []*DebugStep  `json:"steps"`
+	Reason     string        `json:"reason"`
+	Breakpoint int           `json:"breakpoint"` // Index of the breakpoint hit, -1 if none
+	Output     hexutil.Bytes `json:"output,omitempty"`
+	GasUsed    uint64        `json:"gasUsed,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// debugCommand is a request to resume a paused execution.
+type debugCommand struct {
+	steps  uint64 // Number of steps to execute before pausing
+	breaks bool   // Whgdtuer to pause on breakpoints
+}
+
+// Debugger is a Tracer pausing the execution before operations, to be driven
+// step by step by a controller. The execution is deterministic: it only
+// advances while the controller is waiting in Resume, and is paused at a well
+// defined step whenever Resume returns.
+//
+// The execution runs in its own goroutine, which must call Finish when done.
+// The controller calls Wait for the first pause, then Resume to continue.
+type Debugger struct {
+	lock sync.Mutex // Serializes the controller calls
+	done bool
+
+	bpLock      sync.Mutex
+	breakpoints []Breakpoint
+
+	cmds    chan debugCommand
+	results chan *DebugResult
+
+	// Fields only accessed by the executing goroutine
+	cmd       debugCommand
+	executed  uint64
+	index     uint64
+	batch     []*DebugStep
+	stopped   bool
+	stack     []common.Hash
+	memory    []byte
+	storage   *StorageDelta
+	output    []byte
+	gasUsed   uint64
+	returnErr error
+}
+
+// NewDebugger creates a debugger pausing at the first operation and on the
+// given breakpoints.
+func NewDebugger(breakpoints []Breakpoint) *Debugger {
+	return &Debugger{
+		breakpoints: breakpoints,
+		cmds:        make(chan debugCommand),
+		results:     make(chan *DebugResult),
+		cmd:         debugCommand{steps: 1},
+	}
+}
+
+// SetBreakpoints replaces the breakpoints of the debugger.
+func (d *Debugger) SetBreakpoints(breakpoints []Breakpoint) {
+	d.bpLock.Lock()
+	defer d.bpLock.Unlock()
+
+	d.breakpoints = breakpoints
+}
+
+// Wait waits for the execution to pause at its first operation, or to end if
+// it doesn't execute any code.
+func (d *Debugger) Wait() *DebugResult {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.receive()
+}
+
+// Resume continues the paused execution for at most the given number of steps,
+// pausing earlier on a breakpoint if breaks is set. It returns the steps
+// executed and the reason of the pause.
+func (d *Debugger) Resume(steps uint64, breaks bool) (*DebugResult, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.done {
+		return nil, ErrDebuggerDone
+	}
+	d.cmds <- debugCommand{steps: steps, breaks: breaks}
+	return d.receive(), nil
+}
+
+// Stop aborts the paused execution, waiting for it to end.
+func (d *Debugger) Stop() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.done {
+		return
+	}
+	close(d.cmds)
+	d.receive()
+}
+
+// Finish reports the end of the execution. It must be called by the executing
+// goroutine once the execution returned, whgdtuer it failed or not.
+func (d *Debugger) Finish(err error) {
+	res := &DebugResult{
+		Steps:      d.batch,
+		Reason:     DebugPauseDone,
+		Breakpoint: -1,
+		Output:     d.output,
+		GasUsed:    d.gasUsed,
+	}
+	if err == nil {
+		err = d.returnErr
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	d.batch = nil
+	d.results <- res
+}
+
+// receive waits for the execution to pause. It must be called with the lock
+// held.
+func (d *Debugger) receive() *DebugResult {
+	res := <-d.results
+	if res.Reason == DebugPauseDone {
+		d.done = true
+	}
+	return res
+}
+
+// CaptureStart implements the Tracer interface.
+func (d *Debugger) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState implements the Tracer interface, recording the step and pausing
+// the execution if requested.
+func (d *Debugger) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, rData []byte, contract *Contract, depth int, err error) error {
+	if d.stopped {
+		return nil
+	}
+	step := &DebugStep{
+		Index:   d.index,
+		Depth:   depth,
+		Address: contract.Address(),
+		PC:      pc,
+		Op:      op,
+		OpName:  op.String(),
+		Gas:     gas,
+		Cost:    cost,
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	d.diffStack(step, stack)
+	d.diffMemory(step, memory)
+	if d.storage != nil {
+		step.Storage = []StorageDelta{*d.storage}
+		d.storage = nil
+	}
+	if op == SSTORE && err == nil && stack.len() >= 2 {
+		d.storage = &StorageDelta{
+			Address: contract.Address(),
+			Key:     stack.Back(0).Bytes32(),
+			Value:   stack.Back(1).Bytes32(),
+		}
+	}
+	d.index++
+	d.executed++
+	d.batch = append(d.batch, step)
+
+	// Pause if the requested steps were executed or a breakpoint was hit
+	reason, hit := "", -1
+	if d.cmd.breaks {
+		d.bpLock.Lock()
+		for i := range d.breakpoints {
+			if d.breakpoints[i].matches(step, stack) {
+				hit = i
+				break
+			}
+		}
+		d.bpLock.Unlock()
+	}
+	switch {
+	case hit >= 0:
+		reason = DebugPauseBreakpoint
+	case d.executed >= d.cmd.steps:
+		reason = DebugPauseStep
+	default:
+		return nil
+	}
+	d.results <- &DebugResult{Steps: d.batch, Reason: reason, Breakpoint: hit}
+	d.batch = nil
+
+	cmd, ok := <-d.cmds
+	if !ok {
+		d.stopped = true
+		d.returnErr = errDebuggerStopped
+		env.Cancel()
+		return nil
+	}
+	d.cmd, d.executed = cmd, 0
+	return nil
+}
+
+// diffStack records the stack changes since the previous step.
+func (d *Debugger) diffStack(step *DebugStep, stack *Stack) {
+	data := stack.Data()
+
+	kept := 0
+	for kept < len(data) && kept < len(d.stack) && d.stack[kept] == common.Hash(data[kept].Bytes32()) {
+		kept++
+	}
+	step.StackPops = len(d.stack) - kept
+	step.StackPushes = make([]common.Hash, 0, len(data)-kept)
+	for i := kept; i < len(data); i++ {
+		step.StackPushes = append(step.StackPushes, data[i].Bytes32())
+	}
+	d.stack = append(d.stack[:kept], step.StackPushes...)
+}
+
+// diffMemory records the memory changes since the previous step, with a word
+// granularity.
+func (d *Debugger) diffMemory(step *DebugStep, memory *Memory) {
+	data := memory.Data()
+	step.MemorySize = len(data)
+
+	for offset := 0; offset < len(data); offset += 32 {
+		end := offset + 32
+		if end > len(data) {
+			end = len(data)
+		}
+		if end <= len(d.memory) && bytes.Equal(data[offset:end], d.memory[offset:end]) {
+			continue
+		}
+		// Extend the previous delta if adjacent, otherwise start a new one
+		if n := len(step.Memory); n > 0 && step.Memory[n-1].Offset+uint64(len(step.Memory[n-1].Data)) == uint64(offset) {
+			step.Memory[n-1].Data = append(step.Memory[n-1].Data, data[offset:end]...)
+		} else {
+			step.Memory = append(step.Memory, MemoryDelta{Offset: uint64(offset), Data: common.CopyBytes(data[offset:end])})
+		}
+	}
+	d.memory = append(d.memory[:0], data...)
+}
+
+// CaptureFault implements the Tracer interface.
+func (d *Debugger) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements the Tracer interface, recording the outcome of the
+// execution.
+func (d *Debugger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	d.output, d.gasUsed, d.returnErr = common.CopyBytes(output), gasUsed, err
+	if d.stopped {
+		d.returnErr = errDebuggerStopped
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/state"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// startDebugger executes the given code in the background under the control
+// of a debugger.
+func startDebugger(code []byte, breakpoints []Breakpoint) (*Debugger, common.Address) {
+	address := common.BytesToAddress([]byte("contract"))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.CreateAccount(address)
+	statedb.SetCode(address, code)
+
+	debugger := NewDebugger(breakpoints)
+	vmctx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+	}
+	vmenv := NewEVM(vmctx, TxContext{}, statedb, params.AllGdtuashProtocolChanges, Config{Debug: true, Tracer: debugger})
+	go func() {
+		_, _, err := vmenv.Call(AccountRef(common.Address{}), address, nil, 10000000, new(big.Int))
+		debugger.Finish(err)
+	}()
+	return debugger, address
+}
+
+// checkSteps checks the program counters of the given steps.
+func checkSteps(t *testing.T, res *DebugResult, reason string, pcs ...uint64) {
+	t.Helper()
+
+	if res.Reason != reason {
+		t.Errorf("pause reason mismatch: have %s, want %s", res.Reason, reason)
+	}
+	if len(res.Steps) != len(pcs) {
+		t.Fatalf("step count mismatch: have %d, want %d", len(res.Steps), len(pcs))
+	}
+	for i, step := range res.Steps {
+		if step.PC != pcs[i] {
+			t.Errorf("step %d: pc mismatch: have %d, want %d", i, step.PC, pcs[i])
+		}
+	}
+}
+
+// Tests stepping through an execution, pausing on breakpoints and the reported
+// state deltas.
+func TestDebugger(t *testing.T) {
+	code := []byte{
+		byte(PUSH1), 0x2a, byte(PUSH1), 0x00, byte(MSTORE), // pc 0, 2, 4
+		byte(PUSH1), 0x07, byte(PUSH1), 0x01, byte(SSTORE), // pc 5, 7, 9
+		byte(PUSH1), 0x01, byte(SLOAD), byte(POP), byte(STOP), // pc 10, 12, 13, 14
+	}
+	slot := common.Hash{31: 0x01}
+	debugger, address := startDebugger(code, []Breakpoint{{Slot: &slot}})
+
+	// The execution pauses before the first operation
+	res := debugger.Wait()
+	checkSteps(t, res, DebugPauseStep, 0)
+	if step := res.Steps[0]; step.Address != address || step.Op != PUSH1 || step.Depth != 1 {
+		t.Errorf("first step mismatch: %+v", step)
+	}
+	// Step through the memory store, ignoring breakpoints
+	if res, _ = debugger.Resume(2, false); res == nil {
+		t.Fatal("resume failed")
+	}
+	checkSteps(t, res, DebugPauseStep, 2, 4)
+	if step := res.Steps[1]; step.StackPops != 0 || len(step.StackPushes) != 1 || step.StackPushes[0] != (common.Hash{}) || step.MemorySize != 32 {
+		t.Errorf("stack or memory delta mismatch: %+v", step)
+	}
+	// Continue to the storage write, checking the effects of the memory store
+	res, _ = debugger.Resume(100, true)
+	checkSteps(t, res, DebugPauseBreakpoint, 5, 7, 9)
+	if res.Breakpoint != 0 {
+		t.Errorf("breakpoint mismatch: have %d, want 0", res.Breakpoint)
+	}
+	if step := res.Steps[0]; step.StackPops != 2 || len(step.StackPushes) != 0 || len(step.Memory) != 1 || step.Memory[0].Offset != 0 || step.Memory[0].Data[31] != 0x2a {
+		t.Errorf("stack or memory delta mismatch: %+v", step)
+	}
+	// Continue to the storage read, checking the storage write
+	res, _ = debugger.Resume(100, true)
+	checkSteps(t, res, DebugPauseBreakpoint, 10, 12)
+	want := StorageDelta{Address: address, Key: slot, Value: common.Hash{31: 0x07}}
+	if storage := res.Steps[0].Storage; len(storage) != 1 || storage[0] != want {
+		t.Errorf("storage delta mismatch: have %v, want %v", storage, want)
+	}
+	// Run to the end, breakpoints are not hit anymore
+	res, _ = debugger.Resume(100, true)
+	checkSteps(t, res, DebugPauseDone, 13, 14)
+	if res.Error != "" {
+		t.Errorf("execution failed: %s", res.Error)
+	}
+	if _, err := debugger.Resume(1, false); err != ErrDebuggerDone {
+		t.Errorf("resume error mismatch: have %v, want %v", err, ErrDebuggerDone)
+	}
+}
+
+// Tests that stopping the debugger aborts the execution.
+func TestDebuggerStop(t *testing.T) {
+	// Assemble an infinite loop
+	code := []byte{byte(JUMPDEST), byte(PUSH1), 0x00, byte(JUMP)}
+	debugger, _ := startDebugger(code, nil)
+
+	debugger.Wait()
+	if res, err := debugger.Resume(10, false); err != nil || len(res.Steps) != 10 {
+		t.Fatalf("resume failed: %v", err)
+	}
+	debugger.Stop()
+	if _, err := debugger.Resume(1, false); err != ErrDebuggerDone {
+		t.Errorf("resume error mismatch: have %v, want %v", err, ErrDebuggerDone)
+	}
+}
@@ -211,6 +211,22 @@ func (evm *EVM) Cancelled() bool {
 	return atomic.LoadInt32(&evm.abort) == 1
 }
 
+// resolveCode returns the code hash and code of an account, going through the
+// shared code cache if one is configured.
+func (evm *EVM) resolveCode(addr common.Address) (common.Hash, []byte) {
+	hash := evm.StateDB.GetCodeHash(addr)
+	cache := evm.vmConfig.CodeCache
+	if cache == nil || hash == (common.Hash{}) || hash == emptyCodeHash {
+		return hash, evm.StateDB.GetCode(addr)
+	}
+	if code := cache.Code(hash); code != nil {
+		return hash, code
+	}
+	code := evm.StateDB.GetCode(addr)
+	cache.Add(hash, code)
+	return hash, code
+}
+
 // Interpreter returns the current interpreter
 func (evm *EVM) Interpreter() Interpreter {
 	return evm.interpreter
@@ -261,7 +277,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	} else {
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
-		code := evm.StateDB.GetCode(addr)
+		codeHash, code := evm.resolveCode(addr)
 		if len(code) == 0 {
 			ret, err = nil, nil // gas is unchanged
 		} else {
@@ -269,7 +285,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 			// If the account has no code, we can abort here
 			// The depth-check is already done, and precompiles handled above
 			contract := NewContract(caller, AccountRef(addrCopy), value, gas)
-			contract.SetCallCode(&addrCopy, evm.StateDB.GetCodeHash(addrCopy), code)
+			contract.SetCallCode(&addrCopy, codeHash, code)
 			ret, err = run(evm, contract, input, false)
 			gas = contract.Gas
 		}
@@ -321,7 +337,8 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
 		contract := NewContract(caller, AccountRef(caller.Address()), value, gas)
-		contract.SetCallCode(&addrCopy, evm.StateDB.GetCodeHash(addrCopy), evm.StateDB.GetCode(addrCopy))
+		codeHash, code := evm.resolveCode(addrCopy)
+		contract.SetCallCode(&addrCopy, codeHash, code)
 		ret, err = run(evm, contract, input, false)
 		gas = contract.Gas
 	}
@@ -356,7 +373,8 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 		addrCopy := addr
 		// Initialise a new contract and make initialise the delegate values
 		contract := NewContract(caller, AccountRef(caller.Address()), nil, gas).AsDelegate()
-		contract.SetCallCode(&addrCopy, evm.StateDB.GetCodeHash(addrCopy), evm.StateDB.GetCode(addrCopy))
+		codeHash, code := evm.resolveCode(addrCopy)
+		contract.SetCallCode(&addrCopy, codeHash, code)
 		ret, err = run(evm, contract, input, false)
 		gas = contract.Gas
 	}
@@ -404,7 +422,8 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
 		contract := NewContract(caller, AccountRef(addrCopy), new(big.Int), gas)
-		contract.SetCallCode(&addrCopy, evm.StateDB.GetCodeHash(addrCopy), evm.StateDB.GetCode(addrCopy))
+		codeHash, code := evm.resolveCode(addrCopy)
+		contract.SetCallCode(&addrCopy, codeHash, code)
 		// When an error was returned by the EVM or when setting the creation code
 		// above we revert to the snapshot and consume any gas remaining. Additionally
 		// when we're in Homestead this also counts for code storage gas errors.
@@ -18,6 +18,8 @@ package vm
 
 import (
 	"hash"
+	"math/rand"
+	"sync"
 	"sync/atomic"
 
 	"github.com/c88032111/go-gdtu/common"
@@ -27,10 +29,11 @@ import (
 
 // Config are the configuration options for the Interpreter
 type Config struct {
-	Debug                   bool   // Enables debugging
-	Tracer                  Tracer // Opcode logger
-	NoRecursion             bool   // Disables call, callcode, delegate call and create
-	EnablePreimageRecording bool   // Enables recording of SHA3/keccak preimages
+	Debug                   bool          // Enables debugging
+	Tracer                  Tracer        // Opcode logger
+	Sampler                 OpcodeSampler // Opcode sampler, notified about every Nth opcode (nil = disabled)
+	NoRecursion             bool          // Disables call, callcode, delegate call and create
+	EnablePreimageRecording bool          // Enables recording of SHA3/keccak preimages
 
 	JumpTable [256]*operation // EVM instruction table, automatically populated if unset
 
@@ -38,6 +41,10 @@ type Config struct {
 	EVMInterpreter   string // External EVM interpreter options
 
 	ExtraEips []int // Additional EIPS that are to be enabled
+
+	ParallelExecution bool // Speculatively executes block transactions in parallel (experimental)
+
+	CodeCache *CodeCache // Contract code and JUMPDEST analysis cache shared between EVMs (nil = disabled)
 }
 
 // Interpreter is used to run Gdtu based contracts and will utilise the
@@ -70,6 +77,27 @@ type callCtx struct {
 	contract *Contract
 }
 
+var callCtxPool = sync.Pool{
+	New: func() interface{} {
+		return new(callCtx)
+	},
+}
+
+// newCallCtx retrieves a call context from the pool and binds it to the given
+// execution state.
+func newCallCtx(memory *Memory, stack *Stack, contract *Contract) *callCtx {
+	ctx := callCtxPool.Get().(*callCtx)
+	ctx.memory, ctx.stack, ctx.contract = memory, stack, contract
+	return ctx
+}
+
+// returnCallCtx releases the call context to the pool, dropping its references
+// so the pool doesn't keep the execution state alive.
+func returnCallCtx(ctx *callCtx) {
+	ctx.memory, ctx.stack, ctx.contract = nil, nil, nil
+	callCtxPool.Put(ctx)
+}
+
 // keccakState wraps sha3.state. In addition to the usual hash Methods, it also supports
 // Read to get a variable amount of data from the hash state. Read is faster than Sum
 // because it doesn't copy the internal state, but also modifies the internal state.
@@ -88,6 +116,8 @@ type EVMInterpreter struct {
 
 	readOnly   bool   // Whgdtuer to throw on stateful modifications
 	returnData []byte // Last CALL's return data for subsequent reuse
+
+	sampleSkip uint64 // Number of opcodes to execute until the next sample
 }
 
 // NewEVMInterpreter returns a new instance of the Interpreter.
@@ -125,10 +155,18 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 		cfg.JumpTable = jt
 	}
 
-	return &EVMInterpreter{
+	interpreter := &EVMInterpreter{
 		evm: evm,
 		cfg: cfg,
 	}
+	// Start sampling at a random opcode, otherwise the opcodes following the
+	// start of short transactions would never be sampled.
+	if cfg.Sampler != nil {
+		if interval := cfg.Sampler.SampleInterval(); interval > 0 {
+			interpreter.sampleSkip = uint64(rand.Int63n(int64(interval))) + 1
+		}
+	}
+	return interpreter
 }
 
 // Run loops and evaluates the contract's code with the given input data and returns
@@ -158,16 +196,14 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 	if len(contract.Code) == 0 {
 		return nil, nil
 	}
+	// Resolve the JUMPDEST analyses through the shared code cache if configured
+	contract.codeCache = in.cfg.CodeCache
 
 	var (
-		op          OpCode        // current opcode
-		mem         = NewMemory() // bound memory
-		stack       = newstack()  // local stack
-		callContext = &callCtx{
-			memory:   mem,
-			stack:    stack,
-			contract: contract,
-		}
+		op          OpCode                             // current opcode
+		mem         = newMemory()                      // bound memory
+		stack       = newstack()                       // local stack
+		callContext = newCallCtx(mem, stack, contract) // scope of the current call
 		// For optimisation reason we're using uint64 as the program counter.
 		// It's theoretically possible to go above 2^64. The YP defines the PC
 		// to be uint256. Practically much less so feasible.
@@ -184,6 +220,15 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 	// they are returned to the pools
 	defer func() {
 		returnStack(stack)
+		returnCallCtx(callContext)
+
+		// Tracers may retain the memory past the call, so only recycle it if no
+		// tracer is attached. The returned data is sliced out of the memory, so it
+		// needs to be copied before releasing it.
+		if !in.cfg.Debug {
+			ret = common.CopyBytes(ret)
+			returnMemory(mem)
+		}
 	}()
 	contract.Input = input
 
@@ -220,6 +265,12 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		if operation == nil {
 			return nil, &ErrInvalidOpCode{opcode: op}
 		}
+		if in.sampleSkip > 0 {
+			if in.sampleSkip--; in.sampleSkip == 0 {
+				in.sampleSkip = in.cfg.Sampler.SampleInterval()
+				in.cfg.Sampler.SampleOp(contract, pc, op, contract.Gas, in.evm.depth)
+			}
+		}
 		// Validate stack
 		if sLen := stack.len(); sLen < operation.minStack {
 			return nil, &ErrStackUnderflow{stackLen: sLen, required: operation.minStack}
@@ -0,0 +1,219 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+)
+
+// OpcodeGas is the aggregated gas consumption of a single opcode during an
+// execution.
+type OpcodeGas struct {
+	Count uint64 `json:"count"`
+	Gas   uint64 `json:"gas"`
+}
+
+// OpcodeGasDiff is the difference in gas consumption of a single opcode
+// between two executions.
+type OpcodeGasDiff struct {
+	Op          string `json:"op"`
+	BaseCount   uint64 `json:"baseCount"`
+	BaseGas     uint64 `json:"baseGas"`
+	TargetCount uint64 `json:"targetCount"`
+	TargetGas   uint64 `json:"targetGas"`
+}
+
+// GasProfiler is an EVM state logger which aggregates the gas spent per opcode
+// and records the accounts and storage slots touched by an execution. It is
+// cheap enough to run on arbitrary transactions and its output is fully
+// deterministic, making it suitable for comparing executions against each other.
+//
+// Note, the cost of call opcodes includes the gas forwarded to the callee, which
+// is consistent with what the interpreter reports to all tracers.
+type GasProfiler struct {
+	ops      map[OpCode]*OpcodeGas
+	accounts map[common.Address]struct{}
+	slots    map[common.Address]map[common.Hash]struct{}
+	creates  []int // Depths of the CREATE and CREATE2 ops waiting for their result
+
+	gasUsed uint64
+	output  []byte
+	err     error
+}
+
+// NewGasProfiler returns a new gas profiling tracer. The coinbase is recorded as
+// touched up front, since it's credited with the fees outside of the EVM.
+func NewGasProfiler(coinbase common.Address) *GasProfiler {
+	return &GasProfiler{
+		ops:      make(map[OpCode]*OpcodeGas),
+		accounts: map[common.Address]struct{}{coinbase: {}},
+		slots:    make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (p *GasProfiler) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	p.accounts[from] = struct{}{}
+	p.accounts[to] = struct{}{}
+	return nil
+}
+
+// CaptureState implements the Tracer interface, accumulating the cost of the
+// opcode and tracking the state entries it may modify.
+func (p *GasProfiler) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, rData []byte, contract *Contract, depth int, err error) error {
+	stats := p.ops[op]
+	if stats == nil {
+		stats = new(OpcodeGas)
+		p.ops[op] = stats
+	}
+	stats.Count++
+	stats.Gas += cost
+
+	// The address created by a CREATE or CREATE2 is on the top of the stack when
+	// the next op of the same frame executes. Creations of aborted frames don't
+	// have a result, drop them once the execution returns to a shallower depth.
+	for n := len(p.creates); n > 0 && p.creates[n-1] >= depth; n = len(p.creates) {
+		if p.creates[n-1] == depth && stack.len() >= 1 {
+			if addr := common.Address(stack.data[stack.len()-1].Bytes20()); addr != (common.Address{}) {
+				p.accounts[addr] = struct{}{}
+			}
+		}
+		p.creates = p.creates[:n-1]
+	}
+	switch {
+	case op == SSTORE && stack.len() >= 1:
+		addr := contract.Address()
+		if p.slots[addr] == nil {
+			p.slots[addr] = make(map[common.Hash]struct{})
+		}
+		p.slots[addr][common.Hash(stack.data[stack.len()-1].Bytes32())] = struct{}{}
+		p.accounts[addr] = struct{}{}
+
+	case (op == CALL || op == CALLCODE) && stack.len() >= 2:
+		p.accounts[common.Address(stack.data[stack.len()-2].Bytes20())] = struct{}{}
+
+	case op == CREATE || op == CREATE2:
+		p.creates = append(p.creates, depth)
+
+	case op == SELFDESTRUCT && stack.len() >= 1:
+		p.accounts[contract.Address()] = struct{}{}
+		p.accounts[common.Address(stack.data[stack.len()-1].Bytes20())] = struct{}{}
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (p *GasProfiler) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (p *GasProfiler) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	p.gasUsed = gasUsed
+	p.output = output
+	p.err = err
+	return nil
+}
+
+// Opcodes returns the aggregated gas consumption of each executed opcode.
+func (p *GasProfiler) Opcodes() map[OpCode]OpcodeGas {
+	ops := make(map[OpCode]OpcodeGas, len(p.ops))
+	for op, stats := range p.ops {
+		ops[op] = *stats
+	}
+	return ops
+}
+
+// Accounts returns the sorted list of accounts touched by the execution.
+func (p *GasProfiler) Accounts() []common.Address {
+	accounts := make([]common.Address, 0, len(p.accounts))
+	for addr := range p.accounts {
+		accounts = append(accounts, addr)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return bytes.Compare(accounts[i][:], accounts[j][:]) < 0
+	})
+	return accounts
+}
+
+// Slots returns the sorted list of storage slots written by the execution
+// within the given account.
+func (p *GasProfiler) Slots(addr common.Address) []common.Hash {
+	slots := make([]common.Hash, 0, len(p.slots[addr]))
+	for slot := range p.slots[addr] {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		return bytes.Compare(slots[i][:], slots[j][:]) < 0
+	})
+	return slots
+}
+
+// GasUsed returns the gas consumed by the execution, as reported by the EVM.
+func (p *GasProfiler) GasUsed() uint64 { return p.gasUsed }
+
+// Error returns the VM error captured by the trace.
+func (p *GasProfiler) Error() error { return p.err }
+
+// Output returns the VM return value captured by the trace.
+func (p *GasProfiler) Output() []byte { return p.output }
+
+// DiffGasProfiles compares the per-opcode gas consumption of two executions,
+// returning an entry for every opcode whose count or cost differs. The result
+// is ordered by opcode value so that it is deterministic.
+func DiffGasProfiles(base, target *GasProfiler) []OpcodeGasDiff {
+	ops := make(map[OpCode]struct{})
+	for op := range base.ops {
+		ops[op] = struct{}{}
+	}
+	for op := range target.ops {
+		ops[op] = struct{}{}
+	}
+	sorted := make([]OpCode, 0, len(ops))
+	for op := range ops {
+		sorted = append(sorted, op)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var diffs []OpcodeGasDiff
+	for _, op := range sorted {
+		var a, b OpcodeGas
+		if stats := base.ops[op]; stats != nil {
+			a = *stats
+		}
+		if stats := target.ops[op]; stats != nil {
+			b = *stats
+		}
+		if a == b {
+			continue
+		}
+		diffs = append(diffs, OpcodeGasDiff{
+			Op:          op.String(),
+			BaseCount:   a.Count,
+			BaseGas:     a.Gas,
+			TargetCount: b.Count,
+			TargetGas:   b.Gas,
+		})
+	}
+	return diffs
+}
@@ -18,6 +18,7 @@ package vm
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/holiman/uint256"
 )
@@ -28,11 +29,39 @@ type Memory struct {
 	lastGasCost uint64
 }
 
+// memoryPoolLimit is the maximum capacity of a memory buffer returned to the pool.
+// Larger buffers are rare and are dropped instead of being pinned by the pool.
+const memoryPoolLimit = 64 * 1024
+
+var memoryPool = sync.Pool{
+	New: func() interface{} {
+		return new(Memory)
+	},
+}
+
 // NewMemory returns a new memory model.
 func NewMemory() *Memory {
 	return &Memory{}
 }
 
+// newMemory retrieves a memory model from the pool. Its buffer is empty, but may
+// have capacity left from a previous use, which Resize zeroes on expansion.
+func newMemory() *Memory {
+	return memoryPool.Get().(*Memory)
+}
+
+// returnMemory releases the memory model to the pool. Neither the memory nor any
+// slice of its buffer may be used afterwards.
+func returnMemory(m *Memory) {
+	if cap(m.store) > memoryPoolLimit {
+		m.store = nil
+	} else {
+		m.store = m.store[:0]
+	}
+	m.lastGasCost = 0
+	memoryPool.Put(m)
+}
+
 // Set sets offset + size to value
 func (m *Memory) Set(offset, size uint64, value []byte) {
 	// It's possible the offset is greater than 0 and size equals 0. This is because
@@ -0,0 +1,53 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Tests that recycled memory is zeroed on expansion and that oversized buffers
+// are not retained by the pool.
+func TestMemoryRecycle(t *testing.T) {
+	mem := newMemory()
+	mem.Resize(64)
+	mem.Set(0, 64, bytes.Repeat([]byte{0xff}, 64))
+	returnMemory(mem)
+
+	if mem.Len() != 0 {
+		t.Fatalf("returned memory not emptied: %d bytes", mem.Len())
+	}
+	mem.Resize(64)
+	if !bytes.Equal(mem.Data(), make([]byte, 64)) {
+		t.Fatalf("recycled memory not zeroed: %x", mem.Data())
+	}
+	mem.Resize(memoryPoolLimit + 1)
+	returnMemory(mem)
+	if mem.store != nil {
+		t.Fatalf("oversized memory retained: %d bytes capacity", cap(mem.store))
+	}
+}
+
+func BenchmarkMemoryRecycle(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mem := newMemory()
+		mem.Resize(1024)
+		returnMemory(mem)
+	}
+}
@@ -101,6 +101,33 @@ func TestExecute(t *testing.T) {
 	}
 }
 
+// Tests that the data returned by an execution is not overwritten by subsequent
+// executions recycling the interpreter memory.
+func TestExecuteReturnRetained(t *testing.T) {
+	returner := func(value byte) []byte {
+		return []byte{
+			byte(vm.PUSH1), value,
+			byte(vm.PUSH1), 0,
+			byte(vm.MSTORE),
+			byte(vm.PUSH1), 32,
+			byte(vm.PUSH1), 0,
+			byte(vm.RETURN),
+		}
+	}
+	first, _, err := Execute(returner(10), nil, nil)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	for i := 0; i < 16; i++ {
+		if _, _, err := Execute(returner(20), nil, nil); err != nil {
+			t.Fatal("didn't expect error", err)
+		}
+	}
+	if num := new(big.Int).SetBytes(first); num.Cmp(big.NewInt(10)) != 0 {
+		t.Error("Expected 10, got", num)
+	}
+}
+
 func TestCall(t *testing.T) {
 	state, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
 	address := common.HexToAddress("gd0a")
@@ -0,0 +1,172 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/metrics"
+)
+
+var (
+	sampleMeter      = metrics.NewRegisteredMeter("vm/sample/ops", nil)
+	sampleDepthGauge = metrics.NewRegisteredGauge("vm/sample/depth", nil)
+)
+
+// OpcodeSampler is notified about one out of every SampleInterval opcodes
+// executed by the interpreter. Unlike a Tracer, it doesn't get access to the
+// memory and stack, so it is cheap enough to run on a busy node.
+//
+// The interpreter calls SampleOp from multiple goroutines concurrently.
+type OpcodeSampler interface {
+	// SampleInterval returns the number of executed opcodes per sample.
+	SampleInterval() uint64
+
+	// SampleOp is called with the opcode about to be executed, the program
+	// counter and the gas available before its execution.
+	SampleOp(contract *Contract, pc uint64, op OpCode, gas uint64, depth int)
+}
+
+// ContractProfile is the aggregated samples of the code of a single contract.
+type ContractProfile struct {
+	Address common.Address    // Address of the executed code
+	Samples uint64            // Number of samples taken within the code
+	Gas     uint64            // Total gas available at the samples
+	Ops     map[OpCode]uint64 // Number of samples per opcode
+}
+
+// OpcodeProfiler is an OpcodeSampler aggregating the samples per contract, to
+// find the contracts keeping the interpreter busy. Overall sampling statistics
+// are also reported to the metrics system.
+type OpcodeProfiler struct {
+	interval uint64 // Number of executed opcodes per sample
+	limit    int    // Maximum number of contracts to track
+
+	lock      sync.Mutex
+	contracts map[common.Address]*ContractProfile
+	opMeters  map[OpCode]metrics.Meter
+}
+
+// NewOpcodeProfiler creates a profiler sampling one out of every interval opcodes
+// and tracking at most limit contracts. If more contracts are sampled, the ones
+// with the fewest samples are dropped.
+func NewOpcodeProfiler(interval uint64, limit int) *OpcodeProfiler {
+	if interval == 0 {
+		interval = 1
+	}
+	return &OpcodeProfiler{
+		interval:  interval,
+		limit:     limit,
+		contracts: make(map[common.Address]*ContractProfile),
+		opMeters:  make(map[OpCode]metrics.Meter),
+	}
+}
+
+// SampleInterval implements OpcodeSampler, returning the number of executed
+// opcodes per sample.
+func (p *OpcodeProfiler) SampleInterval() uint64 {
+	return p.interval
+}
+
+// SampleOp implements OpcodeSampler, accounting the sample to the contract whose
+// code is executing.
+func (p *OpcodeProfiler) SampleOp(contract *Contract, pc uint64, op OpCode, gas uint64, depth int) {
+	addr := contract.Address()
+	if contract.CodeAddr != nil {
+		addr = *contract.CodeAddr
+	}
+	sampleMeter.Mark(1)
+	sampleDepthGauge.Update(int64(depth))
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	meter := p.opMeters[op]
+	if meter == nil {
+		meter = metrics.GetOrRegisterMeter("vm/sample/op/"+op.String(), nil)
+		p.opMeters[op] = meter
+	}
+	meter.Mark(1)
+
+	profile := p.contracts[addr]
+	if profile == nil {
+		if len(p.contracts) >= p.limit {
+			p.evict()
+		}
+		profile = &ContractProfile{Address: addr, Ops: make(map[OpCode]uint64)}
+		p.contracts[addr] = profile
+	}
+	profile.Samples++
+	profile.Gas += gas
+	profile.Ops[op]++
+}
+
+// evict drops the contract with the fewest samples. The lock is assumed to be
+// held.
+func (p *OpcodeProfiler) evict() {
+	var drop *ContractProfile
+	for _, profile := range p.contracts {
+		if drop == nil || profile.Samples < drop.Samples {
+			drop = profile
+		}
+	}
+	if drop != nil {
+		delete(p.contracts, drop.Address)
+	}
+}
+
+// Top returns the profiles of the n most sampled contracts, in decreasing order
+// of samples.
+func (p *OpcodeProfiler) Top(n int) []ContractProfile {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	profiles := make([]ContractProfile, 0, len(p.contracts))
+	for _, profile := range p.contracts {
+		ops := make(map[OpCode]uint64, len(profile.Ops))
+		for op, samples := range profile.Ops {
+			ops[op] = samples
+		}
+		profiles = append(profiles, ContractProfile{
+			Address: profile.Address,
+			Samples: profile.Samples,
+			Gas:     profile.Gas,
+			Ops:     ops,
+		})
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Samples != profiles[j].Samples {
+			return profiles[i].Samples > profiles[j].Samples
+		}
+		return bytes.Compare(profiles[i].Address[:], profiles[j].Address[:]) < 0
+	})
+	if n < len(profiles) {
+		profiles = profiles[:n]
+	}
+	return profiles
+}
+
+// Reset drops all the aggregated samples.
+func (p *OpcodeProfiler) Reset() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.contracts = make(map[common.Address]*ContractProfile)
+}
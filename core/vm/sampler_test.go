@@ -0,0 +1,103 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/state"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// Tests that the interpreter samples one out of every N opcodes.
+func TestOpcodeSampling(t *testing.T) {
+	// Assemble a contract executing 100 PUSH1-POP pairs, then STOP
+	var code []byte
+	for i := 0; i < 100; i++ {
+		code = append(code, byte(PUSH1), 0x01, byte(POP))
+	}
+	code = append(code, byte(STOP))
+
+	address := common.BytesToAddress([]byte("contract"))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.CreateAccount(address)
+	statedb.SetCode(address, code)
+
+	profiler := NewOpcodeProfiler(10, 16)
+	vmctx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+	}
+	vmenv := NewEVM(vmctx, TxContext{}, statedb, params.AllGdtuashProtocolChanges, Config{Sampler: profiler})
+	if _, _, err := vmenv.Call(AccountRef(common.Address{}), address, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("failed to execute contract: %v", err)
+	}
+	// 201 opcodes were executed, depending on the starting offset, 20 or 21 of
+	// them should have been sampled
+	profiles := profiler.Top(10)
+	if len(profiles) != 1 {
+		t.Fatalf("profiled contracts mismatch: have %d, want 1", len(profiles))
+	}
+	profile := profiles[0]
+	if profile.Address != address {
+		t.Errorf("profiled contract mismatch: have %x, want %x", profile.Address, address)
+	}
+	if profile.Samples < 20 || profile.Samples > 21 {
+		t.Errorf("sample count mismatch: have %d, want 20 or 21", profile.Samples)
+	}
+	// With an even interval, either only the PUSH1s (and the STOP) or only the
+	// POPs get sampled
+	pushes, pops, stops := profile.Ops[PUSH1], profile.Ops[POP], profile.Ops[STOP]
+	if pushes+pops+stops != profile.Samples || (pushes > 0 && pops > 0) || (pops > 0 && stops > 0) {
+		t.Errorf("opcode samples mismatch: %v", profile.Ops)
+	}
+	profiler.Reset()
+	if profiles := profiler.Top(10); len(profiles) != 0 {
+		t.Errorf("profiles not reset: %v", profiles)
+	}
+}
+
+// Tests that the profiler tracks a limited number of contracts, dropping the
+// least sampled ones.
+func TestOpcodeProfilerLimit(t *testing.T) {
+	profiler := NewOpcodeProfiler(1, 2)
+
+	contract := func(addr byte) *Contract {
+		return NewContract(AccountRef(common.Address{}), AccountRef(common.Address{addr}), new(big.Int), 0)
+	}
+	profiler.SampleOp(contract(1), 0, ADD, 100, 1)
+	profiler.SampleOp(contract(1), 1, ADD, 100, 1)
+	profiler.SampleOp(contract(2), 0, MUL, 100, 1)
+	profiler.SampleOp(contract(3), 0, SUB, 100, 1)
+
+	profiles := profiler.Top(10)
+	if len(profiles) != 2 {
+		t.Fatalf("profiled contracts mismatch: have %d, want 2", len(profiles))
+	}
+	if profiles[0].Address != (common.Address{1}) || profiles[0].Samples != 2 || profiles[0].Gas != 200 {
+		t.Errorf("most sampled contract mismatch: %+v", profiles[0])
+	}
+	if profiles[1].Address != (common.Address{3}) {
+		t.Errorf("least sampled contract not evicted: have %x", profiles[1].Address)
+	}
+	if top := profiler.Top(1); len(top) != 1 || top[0].Address != (common.Address{1}) {
+		t.Errorf("top contract mismatch: %+v", top)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package event
+
+import "reflect"
+
+// bridgeBuffer is the number of events buffered by a bridge before it blocks the
+// sender.
+const bridgeBuffer = 16
+
+// FeedToMux relays the values sent on a feed to the subscribers of a TypeMux, so
+// that legacy TypeMux subscribers keep working while the producer of the events
+// migrates to a Feed. The element type of the feed is given by a sample value.
+// The relay stops when the returned subscription is unsubscribed or the mux is
+// stopped.
+func FeedToMux(feed *Feed, mux *TypeMux, sample interface{}) Subscription {
+	ch := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, reflect.TypeOf(sample)), bridgeBuffer)
+	sub := feed.Subscribe(ch.Interface())
+
+	return NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: ch},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(quit)},
+		}
+		for {
+			if chosen, value, _ := reflect.Select(cases); chosen == 1 {
+				return nil
+			} else if err := mux.Post(value.Interface()); err == ErrMuxClosed {
+				return nil
+			}
+		}
+	})
+}
+
+// MuxToFeed relays the events of the given type posted to a TypeMux to the
+// subscribers of a feed, so that consumers can migrate to a Feed before the
+// producer of the events does. The relay stops when the returned subscription
+// is unsubscribed or the mux is stopped.
+func MuxToFeed(mux *TypeMux, feed *Feed, sample interface{}) Subscription {
+	sub := mux.Subscribe(sample)
+
+	return NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev, ok := <-sub.Chan():
+				if !ok {
+					return nil
+				}
+				feed.Send(ev.Data)
+			case <-quit:
+				return nil
+			}
+		}
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFeedToMux(t *testing.T) {
+	var (
+		feed Feed
+		mux  = new(TypeMux)
+	)
+	bridge := FeedToMux(&feed, mux, testEvent(0))
+	sub := mux.Subscribe(testEvent(0))
+
+	for i := 0; i < 3; i++ {
+		feed.Send(testEvent(i))
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case ev := <-sub.Chan():
+			if ev.Data != testEvent(i) {
+				t.Fatalf("event %d mismatch: have %v, want %v", i, ev.Data, testEvent(i))
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not relayed", i)
+		}
+	}
+	// Events sent after unsubscribing are not relayed anymore
+	bridge.Unsubscribe()
+	if sent := feed.Send(testEvent(3)); sent != 0 {
+		t.Fatalf("event sent to %d subscribers after unsubscribing", sent)
+	}
+	// The bridge stops once the mux is stopped
+	bridge = FeedToMux(&feed, mux, testEvent(0))
+	mux.Stop()
+	feed.Send(testEvent(4))
+	select {
+	case err := <-bridge.Err():
+		if err != nil {
+			t.Fatalf("bridge failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("bridge not stopped with the mux")
+	}
+}
+
+func TestMuxToFeed(t *testing.T) {
+	var (
+		feed Feed
+		mux  = new(TypeMux)
+		ch   = make(chan testEvent)
+	)
+	sub := feed.Subscribe(ch)
+	defer sub.Unsubscribe()
+
+	bridge := MuxToFeed(mux, &feed, testEvent(0))
+	go func() {
+		for i := 0; i < 3; i++ {
+			mux.Post(testEvent(i))
+		}
+		mux.Post("ignored")
+	}()
+	for i := 0; i < 3; i++ {
+		select {
+		case ev := <-ch:
+			if ev != testEvent(i) {
+				t.Fatalf("event %d mismatch: have %v, want %v", i, ev, testEvent(i))
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not relayed", i)
+		}
+	}
+	mux.Stop()
+	select {
+	case <-bridge.Err():
+	case <-time.After(time.Second):
+		t.Fatal("bridge not stopped with the mux")
+	}
+}
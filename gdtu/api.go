@@ -19,10 +19,12 @@ package gdtu
 import (
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math/big"
+	"net/http"
 	"os"
 	"runtime"
 	"strings"
@@ -138,6 +140,15 @@ func (api *PrivateMinerAPI) SetGasPrice(gasPrice hexutil.Big) bool {
 	return true
 }
 
+// SetGasLimits sets the gas floor and ceil targeted by the gas limit of the
+// mined blocks.
+func (api *PrivateMinerAPI) SetGasLimits(floor, ceil hexutil.Uint64) (bool, error) {
+	if err := api.e.Miner().SetGasLimits(uint64(floor), uint64(ceil)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // SetGdturbase sets the gdtuerbase of the miner
 func (api *PrivateMinerAPI) SetGdturbase(gdtuerbase common.Address) bool {
 	api.e.SetGdturbase(gdtuerbase)
@@ -154,6 +165,18 @@ func (api *PrivateMinerAPI) GetHashrate() uint64 {
 	return api.e.miner.HashRate()
 }
 
+// Mine seals the given number of blocks right away and returns their hashes, even
+// on developer chains only sealing blocks with pending transactions. It's meant
+// for test frameworks driving a developer chain.
+func (api *PrivateMinerAPI) Mine(ctx context.Context, blocks hexutil.Uint64) ([]common.Hash, error) {
+	mined, err := api.e.Miner().MineBlocks(ctx, int(blocks))
+	hashes := make([]common.Hash, len(mined))
+	for i, block := range mined {
+		hashes[i] = block.Hash()
+	}
+	return hashes, err
+}
+
 // PrivateAdminAPI is the collection of Gdtu full node-related APIs
 // exposed over the private admin endpoint.
 type PrivateAdminAPI struct {
@@ -166,6 +189,42 @@ func NewPrivateAdminAPI(gdtu *Gdtu) *PrivateAdminAPI {
 	return &PrivateAdminAPI{gdtu: gdtu}
 }
 
+// SnapAvailability reports whgdtuer the local state is complete enough to be
+// served over the snap protocol and, if not, the reason why.
+func (api *PrivateAdminAPI) SnapAvailability() SnapAvailability {
+	return api.gdtu.handler.snapAvail.Status()
+}
+
+// PeerBans returns the peers which recently failed sync, along with whgdtuer
+// they are banned.
+func (api *PrivateAdminAPI) PeerBans() []PeerBan {
+	return api.gdtu.handler.bans.list()
+}
+
+// ClearPeerBans removes the failure record and ban of the given node ID or IP
+// address, or all of them if no key is given. The number of cleared entries is
+// returned.
+func (api *PrivateAdminAPI) ClearPeerBans(key *string) int {
+	if key == nil {
+		return api.gdtu.handler.bans.clear("")
+	}
+	return api.gdtu.handler.bans.clear(*key)
+}
+
+// SlowestPeers returns the n peers (default 10) with the longest mean round trips
+// of the given request type (headers, bodies, receipts, nodedata or pooledtxs),
+// or of all the request types if none is given.
+func (api *PrivateAdminAPI) SlowestPeers(n *int, kind *string) ([]*PeerLatency, error) {
+	limit, requests := 10, ""
+	if n != nil {
+		limit = *n
+	}
+	if kind != nil {
+		requests = *kind
+	}
+	return api.gdtu.handler.peers.slowestPeers(limit, requests)
+}
+
 // ExportChain exports the current blockchain into a local file,
 // or a range of blocks if first and last are non-nil
 func (api *PrivateAdminAPI) ExportChain(file string, first *uint64, last *uint64) (bool, error) {
@@ -205,6 +264,70 @@ func (api *PrivateAdminAPI) ExportChain(file string, first *uint64, last *uint64
 	return true, nil
 }
 
+// ExportChainToURL streams a range of blocks to the chain import endpoint of
+// another node (e.g. http://host:8545/chain/import), or any URL accepting the
+// RLP encoded blocks in the body of a POST request. The number of blocks sent is
+// returned.
+func (api *PrivateAdminAPI) ExportChainToURL(ctx context.Context, url string, first uint64, last *uint64) (uint64, error) {
+	chain := api.gdtu.BlockChain()
+	end := chain.CurrentBlock().NumberU64()
+	if last != nil {
+		end = *last
+	}
+	if first > end || end > chain.CurrentBlock().NumberU64() {
+		return 0, fmt.Errorf("invalid block range %d-%d", first, end)
+	}
+	// Stream the blocks through a pipe into the request body, sending it with a
+	// chunked transfer encoding
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(exportChain(chain, writer, first, end))
+	}()
+	defer reader.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var result chainImportResult
+		if err := json.NewDecoder(res.Body).Decode(&result); err == nil && result.Error != "" {
+			return uint64(result.Blocks), fmt.Errorf("remote import failed: %s", result.Error)
+		}
+		return 0, fmt.Errorf("remote import failed: %s", res.Status)
+	}
+	return end - first + 1, nil
+}
+
+// ImportChainFromURL imports the blocks streamed from the chain export endpoint
+// of another node (e.g. http://host:8545/chain/export?first=1&last=1000), or any
+// URL serving RLP encoded blocks. The number of blocks read is returned.
+func (api *PrivateAdminAPI) ImportChainFromURL(ctx context.Context, url string) (uint64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("chain export failed: %s", res.Status)
+	}
+	blocks, err := importChain(api.gdtu.BlockChain(), res.Body)
+	return uint64(blocks), err
+}
+
 func hasAllBlocks(chain *core.BlockChain, bs []*types.Block) bool {
 	for _, b := range bs {
 		if !chain.HasBlock(b.Hash(), b.NumberU64()) {
@@ -231,6 +354,16 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 		}
 	}
 
+	if _, err := importChain(api.gdtu.BlockChain(), reader); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// importChain imports the RLP encoded blocks read from a stream in batches,
+// skipping the batches already present in the chain. The number of blocks read
+// is returned.
+func importChain(chain *core.BlockChain, reader io.Reader) (int, error) {
 	// Run actual the import in pre-configured batches
 	stream := rlp.NewStream(reader, 0)
 
@@ -242,7 +375,7 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 			if err := stream.Decode(block); err == io.EOF {
 				break
 			} else if err != nil {
-				return false, fmt.Errorf("block %d: failed to parse: %v", index, err)
+				return index, fmt.Errorf("block %d: failed to parse: %v", index, err)
 			}
 			blocks = append(blocks, block)
 			index++
@@ -251,17 +384,61 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 			break
 		}
 
-		if hasAllBlocks(api.gdtu.BlockChain(), blocks) {
+		if hasAllBlocks(chain, blocks) {
 			blocks = blocks[:0]
 			continue
 		}
 		// Import the batch and reset the buffer
-		if _, err := api.gdtu.BlockChain().InsertChain(blocks); err != nil {
-			return false, fmt.Errorf("batch %d: failed to insert: %v", batch, err)
+		if _, err := chain.InsertChain(blocks); err != nil {
+			return index, fmt.Errorf("batch %d: failed to insert: %v", batch, err)
 		}
 		blocks = blocks[:0]
 	}
-	return true, nil
+	return index, nil
+}
+
+// ExportPreimages exports the recorded preimages of the hashed trie keys into a
+// local file, gzip compressed if the file name ends in .gz. The number of
+// preimages exported is returned.
+func (api *PrivateAdminAPI) ExportPreimages(file string) (uint64, error) {
+	if _, err := os.Stat(file); err == nil {
+		// File already exists. Allowing overwrite could be a DoS vector,
+		// since the 'file' may point to arbitrary paths on the drive
+		return 0, errors.New("location would overwrite an existing file")
+	}
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	var writer io.Writer = out
+	if strings.HasSuffix(file, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	return state.ExportPreimages(api.gdtu.ChainDb(), writer)
+}
+
+// ImportPreimages imports the preimages of hashed trie keys exported by another
+// node from a local file. The number of preimages imported is returned.
+func (api *PrivateAdminAPI) ImportPreimages(file string) (uint64, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	var reader io.Reader = in
+	if strings.HasSuffix(file, ".gz") {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return 0, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	return state.ImportPreimages(api.gdtu.ChainDb(), reader)
 }
 
 // PublicDebugAPI is the collection of Gdtu full node APIs exposed
@@ -543,3 +720,94 @@ func (api *PrivateDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Bloc
 	}
 	return dirty, nil
 }
+
+// SnapshotProgress is the state of the snapshot generation.
+type SnapshotProgress struct {
+	Root     common.Hash    `json:"root"`     // State root the snapshot is generated for
+	Done     bool           `json:"done"`     // Whgdtuer the generation is complete
+	Wiping   bool           `json:"wiping"`   // Whgdtuer a previous snapshot is still being wiped
+	Marker   hexutil.Bytes  `json:"marker"`   // Account hash (and storage slot hash) generated up to
+	Accounts hexutil.Uint64 `json:"accounts"` // Number of accounts generated
+	Slots    hexutil.Uint64 `json:"slots"`    // Number of storage slots generated
+	Storage  hexutil.Uint64 `json:"storage"`  // Size of the generated data in bytes
+	Delay    string         `json:"delay"`    // Current pause between batches due to block import pressure
+}
+
+// SnapshotProgress retrieves the progress of the state snapshot generation.
+func (api *PrivateDebugAPI) SnapshotProgress() (*SnapshotProgress, error) {
+	snaps := api.gdtu.BlockChain().Snapshots()
+	if snaps == nil {
+		return nil, errors.New("snapshots are disabled")
+	}
+	progress, err := snaps.GeneratorProgress()
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotProgress{
+		Root:     progress.Root,
+		Done:     progress.Done,
+		Wiping:   progress.Wiping,
+		Marker:   progress.Marker,
+		Accounts: hexutil.Uint64(progress.Accounts),
+		Slots:    hexutil.Uint64(progress.Slots),
+		Storage:  hexutil.Uint64(progress.Storage),
+		Delay:    common.PrettyDuration(progress.Delay).String(),
+	}, nil
+}
+
+// ContractProfile is the opcode sampling profile of a contract.
+type ContractProfile struct {
+	Address common.Address    `json:"address"`
+	Samples uint64            `json:"samples"`
+	Gas     uint64            `json:"gas"` // Total gas available at the samples
+	Ops     map[string]uint64 `json:"ops"`
+}
+
+// OpcodeProfile returns the opcode sampling profiles of the most frequently
+// sampled contracts, 10 unless the count is specified.
+func (api *PrivateDebugAPI) OpcodeProfile(count *int) ([]ContractProfile, error) {
+	if api.gdtu.opcodeProfiler == nil {
+		return nil, errors.New("opcode sampling is disabled")
+	}
+	n := 10
+	if count != nil {
+		n = *count
+	}
+	var profiles []ContractProfile
+	for _, profile := range api.gdtu.opcodeProfiler.Top(n) {
+		ops := make(map[string]uint64, len(profile.Ops))
+		for op, samples := range profile.Ops {
+			ops[op.String()] = samples
+		}
+		profiles = append(profiles, ContractProfile{
+			Address: profile.Address,
+			Samples: profile.Samples,
+			Gas:     profile.Gas,
+			Ops:     ops,
+		})
+	}
+	return profiles, nil
+}
+
+// ResetOpcodeProfile drops the opcode sampling profiles collected so far.
+func (api *PrivateDebugAPI) ResetOpcodeProfile() error {
+	if api.gdtu.opcodeProfiler == nil {
+		return errors.New("opcode sampling is disabled")
+	}
+	api.gdtu.opcodeProfiler.Reset()
+	return nil
+}
+
+// FreezerThreshold returns the number of recent blocks kept in the key-value
+// store before being moved into the ancient store.
+func (api *PrivateDebugAPI) FreezerThreshold() (hexutil.Uint64, error) {
+	threshold, err := api.gdtu.ChainDb().AncientThreshold()
+	return hexutil.Uint64(threshold), err
+}
+
+// SetFreezerThreshold changes the number of recent blocks kept in the key-value
+// store before being moved into the ancient store. If automatic tuning is
+// enabled, it continues from the new threshold.
+func (api *PrivateDebugAPI) SetFreezerThreshold(threshold hexutil.Uint64) error {
+	return api.gdtu.ChainDb().SetAncientThreshold(uint64(threshold))
+}
@@ -56,9 +56,15 @@ func (b *GdtuAPIBackend) CurrentBlock() *types.Block {
 	return b.gdtu.blockchain.CurrentBlock()
 }
 
-func (b *GdtuAPIBackend) SetHead(number uint64) {
+// SetHead rewinds the chain to the given block, cancelling any synchronisation
+// in progress and discarding the bloom bits sections beyond the new head.
+func (b *GdtuAPIBackend) SetHead(number uint64) error {
 	b.gdtu.handler.downloader.Cancel()
-	b.gdtu.blockchain.SetHead(number)
+	if err := b.gdtu.blockchain.SetHead(number); err != nil {
+		return err
+	}
+	b.gdtu.bloomIndexer.Rewind(b.gdtu.blockchain.CurrentHeader().Number.Uint64())
+	return nil
 }
 
 func (b *GdtuAPIBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
@@ -261,6 +267,10 @@ func (b *GdtuAPIBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.gdtu.TxPool().Content()
 }
 
+func (b *GdtuAPIBackend) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	return b.gdtu.TxPool().ContentFrom(addr)
+}
+
 func (b *GdtuAPIBackend) TxPool() *core.TxPool {
 	return b.gdtu.TxPool()
 }
@@ -277,6 +287,10 @@ func (b *GdtuAPIBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *GdtuAPIBackend) SuggestFees(ctx context.Context) (*gasprice.FeeSuggestions, error) {
+	return b.gpo.SuggestFees(ctx)
+}
+
 func (b *GdtuAPIBackend) ChainDb() gdtudb.Database {
 	return b.gdtu.ChainDb()
 }
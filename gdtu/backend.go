@@ -34,6 +34,7 @@ import (
 	"github.com/c88032111/go-gdtu/core"
 	"github.com/c88032111/go-gdtu/core/bloombits"
 	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/state"
 	"github.com/c88032111/go-gdtu/core/state/pruner"
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/core/vm"
@@ -60,6 +61,14 @@ import (
 // Deprecated: use gdtuconfig.Config instead.
 type Config = gdtuconfig.Config
 
+// opcodeProfileLimit is the maximum number of contracts tracked by the opcode
+// sampling profiler.
+const opcodeProfileLimit = 1024
+
+// preimageCheckLimit is the maximum number of state keys checked for preimages
+// on startup.
+const preimageCheckLimit = 1024
+
 // Gdtu implements the Gdtu full node service.
 type Gdtu struct {
 	config *gdtuconfig.Config
@@ -82,6 +91,9 @@ type Gdtu struct {
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
 	closeBloomHandler chan struct{}
 
+	opcodeProfiler *vm.OpcodeProfiler // Opcode sampling profiler, nil if disabled
+	governance     *governance        // Chain configuration reloader, nil if disabled
+
 	APIBackend *GdtuAPIBackend
 
 	miner      *miner.Miner
@@ -106,6 +118,11 @@ func New(stack *node.Node, config *gdtuconfig.Config) (*Gdtu, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
+	for i := range config.WhitelistRules {
+		if err := config.WhitelistRules[i].Validate(); err != nil {
+			return nil, fmt.Errorf("invalid whitelist rule %d: %v", i, err)
+		}
+	}
 	if config.Miner.GasPrice == nil || config.Miner.GasPrice.Cmp(common.Big0) <= 0 {
 		log.Warn("Sanitizing invalid miner gas price", "provided", config.Miner.GasPrice, "updated", gdtuconfig.Defaults.Miner.GasPrice)
 		config.Miner.GasPrice = new(big.Int).Set(gdtuconfig.Defaults.Miner.GasPrice)
@@ -126,6 +143,19 @@ func New(stack *node.Node, config *gdtuconfig.Config) (*Gdtu, error) {
 	if err != nil {
 		return nil, err
 	}
+	threshold := uint64(params.FullImmutabilityThreshold)
+	if config.DatabaseFreezerThreshold != 0 {
+		threshold = config.DatabaseFreezerThreshold
+		if err := chainDb.SetAncientThreshold(threshold); err != nil {
+			return nil, fmt.Errorf("failed to set freezer threshold: %v", err)
+		}
+	}
+	if config.DatabaseFreezerAutoTune {
+		// Allow the tuner to keep up to four times the configured recent chain
+		if err := chainDb.TuneAncientThreshold(4 * threshold); err != nil {
+			return nil, fmt.Errorf("failed to enable freezer tuning: %v", err)
+		}
+	}
 	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideBerlin)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
@@ -158,11 +188,8 @@ func New(stack *node.Node, config *gdtuconfig.Config) (*Gdtu, error) {
 	log.Info("Initialising Gdtu protocol", "network", config.NetworkId, "dbversion", dbVer)
 
 	if !config.SkipBcVersionCheck {
-		if bcVersion != nil && *bcVersion > core.BlockChainVersion {
-			return nil, fmt.Errorf("database version is v%d, Ggdtu %s only supports v%d", *bcVersion, params.VersionWithMeta, core.BlockChainVersion)
-		} else if bcVersion == nil || *bcVersion < core.BlockChainVersion {
-			log.Warn("Upgrade blockchain database version", "from", dbVer, "to", core.BlockChainVersion)
-			rawdb.WriteDatabaseVersion(chainDb, core.BlockChainVersion)
+		if err := core.MigrateDatabase(chainDb); err != nil {
+			return nil, err
 		}
 	}
 	var (
@@ -170,6 +197,7 @@ func New(stack *node.Node, config *gdtuconfig.Config) (*Gdtu, error) {
 			EnablePreimageRecording: config.EnablePreimageRecording,
 			EWASMInterpreter:        config.EWASMInterpreter,
 			EVMInterpreter:          config.EVMInterpreter,
+			ParallelExecution:       config.ParallelExecution,
 		}
 		cacheConfig = &core.CacheConfig{
 			TrieCleanLimit:      config.TrieCleanCache,
@@ -180,9 +208,19 @@ func New(stack *node.Node, config *gdtuconfig.Config) (*Gdtu, error) {
 			TrieDirtyDisabled:   config.NoPruning,
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
+			SnapshotThrottle:    config.SnapshotThrottle,
+			SnapshotMaxDelay:    config.SnapshotMaxDelay,
+			SnapshotIdle:        config.SnapshotIdle,
+			SnapshotCompaction:  config.SnapshotCompaction,
+			CodeCacheLimit:      config.CodeCache,
 			Preimages:           config.Preimages,
+			SideChainRetention:  config.SideChainRetention,
 		}
 	)
+	if config.OpcodeSampleInterval > 0 {
+		gdtu.opcodeProfiler = vm.NewOpcodeProfiler(config.OpcodeSampleInterval, opcodeProfileLimit)
+		vmConfig.Sampler = gdtu.opcodeProfiler
+	}
 	gdtu.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, gdtu.engine, vmConfig, gdtu.shouldPreserve, &config.TxLookupLimit)
 	if err != nil {
 		return nil, err
@@ -193,11 +231,33 @@ func New(stack *node.Node, config *gdtuconfig.Config) (*Gdtu, error) {
 		gdtu.blockchain.SetHead(compat.RewindTo)
 		rawdb.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
+	// Apply the fork schedule of the governance file, if any, before any other
+	// subsystem starts relying on it.
+	if config.GovernanceFile != "" {
+		gdtu.governance = newGovernance(stack.ResolvePath(config.GovernanceFile), gdtu.blockchain)
+		if err := gdtu.governance.reload(); err != nil {
+			return nil, fmt.Errorf("failed to load governance file: %v", err)
+		}
+	}
 	gdtu.bloomIndexer.Start(gdtu.blockchain)
 
+	// Preimages are only recorded for the state written after enabling them,
+	// warn if they're missing since the debug APIs would report hashed keys
+	if config.Preimages {
+		if head := gdtu.blockchain.CurrentBlock(); head.NumberU64() > 0 {
+			checked, missing, err := state.CheckPreimages(gdtu.blockchain.StateCache(), head.Root(), preimageCheckLimit)
+			if err == nil && missing > 0 {
+				log.Warn("Preimages missing for existing state", "checked", checked, "missing", missing, "hint", "import them with import-preimages or admin.importPreimages")
+			}
+		}
+	}
+
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.RemoteJournal != "" {
+		config.TxPool.RemoteJournal = stack.ResolvePath(config.TxPool.RemoteJournal)
+	}
 	gdtu.txPool = core.NewTxPool(config.TxPool, chainConfig, gdtu.blockchain)
 
 	// Permit the downloader to use the trie cache allowance during fast sync
@@ -216,6 +276,9 @@ func New(stack *node.Node, config *gdtuconfig.Config) (*Gdtu, error) {
 		EventMux:   gdtu.eventMux,
 		Checkpoint: checkpoint,
 		Whitelist:  config.Whitelist,
+
+		WhitelistRules:  config.WhitelistRules,
+		PeerBanDuration: config.PeerBanDuration,
 	}); err != nil {
 		return nil, err
 	}
@@ -247,6 +310,16 @@ func New(stack *node.Node, config *gdtuconfig.Config) (*Gdtu, error) {
 	stack.RegisterAPIs(gdtu.APIs())
 	stack.RegisterProtocols(gdtu.Protocols())
 	stack.RegisterLifecycle(gdtu)
+
+	// Stream chain segments over HTTP if the admin API is exposed there
+	for _, module := range stack.Config().HTTPModules {
+		if module == "admin" {
+			handler := node.NewHTTPHandlerStack(&chainStreamHandler{chain: gdtu.blockchain}, stack.Config().HTTPCors, stack.Config().HTTPVirtualHosts)
+			stack.RegisterHandler("Chain export", chainExportPath, handler)
+			stack.RegisterHandler("Chain import", chainImportPath, handler)
+			break
+		}
+	}
 	// Check for unclean shutdown
 	if uncleanShutdowns, discards, err := rawdb.PushUncleanShutdownMarker(chainDb); err != nil {
 		log.Error("Could not update unclean-shutdown-marker list", "error", err)
@@ -303,7 +376,7 @@ func (s *Gdtu) APIs() []rpc.API {
 		}, {
 			Namespace: "gdtu",
 			Version:   "1.0",
-			Service:   downloader.NewPublicDownloaderAPI(s.handler.downloader, s.eventMux),
+			Service:   downloader.NewPublicDownloaderAPI(s.handler.downloader),
 			Public:    true,
 		}, {
 			Namespace: "miner",
@@ -502,12 +575,22 @@ func (s *Gdtu) Synced() bool                       { return atomic.LoadUint32(&s
 func (s *Gdtu) ArchiveMode() bool                  { return s.config.NoPruning }
 func (s *Gdtu) BloomIndexer() *core.ChainIndexer   { return s.bloomIndexer }
 
+// AddTxFilter registers an ingress filter for the transactions received from the
+// network, evaluated before they are added to the transaction pool.
+func (s *Gdtu) AddTxFilter(filter TxFilter) {
+	s.handler.txFilters.add(filter)
+}
+
 // Protocols returns all the currently configured
 // network protocols to start.
 func (s *Gdtu) Protocols() []p2p.Protocol {
 	protos := gdtu.MakeProtocols((*gdtuHandler)(s.handler), s.networkID, s.gdtuDialCandidates)
 	if s.config.SnapshotCache > 0 {
-		protos = append(protos, snap.MakeProtocols((*snapHandler)(s.handler), s.snapDialCandidates)...)
+		snapProtos := snap.MakeProtocols((*snapHandler)(s.handler), s.snapDialCandidates)
+		for i := range snapProtos {
+			snapProtos[i].Available = s.handler.snapAvail.Negotiable
+		}
+		protos = append(protos, snapProtos...)
 	}
 	return protos
 }
@@ -528,8 +611,20 @@ func (s *Gdtu) Start() error {
 		}
 		maxPeers -= s.config.LightPeers
 	}
+	// Publish the snap entries of the node record only while the state is servable
+	for _, proto := range s.p2pServer.Protocols {
+		if proto.Name == snap.ProtocolName {
+			s.handler.snapAvail.advertise(s.p2pServer.LocalNode(), proto.Attributes)
+			break
+		}
+	}
 	// Start the networking layer and the light server if requested
 	s.handler.Start(maxPeers)
+
+	// Start watching the governance file for fork schedule updates
+	if s.governance != nil {
+		s.governance.start()
+	}
 	return nil
 }
 
@@ -540,6 +635,9 @@ func (s *Gdtu) Stop() error {
 	s.handler.Stop()
 
 	// Then stop everything else.
+	if s.governance != nil {
+		s.governance.stop()
+	}
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
 	s.txPool.Stop()
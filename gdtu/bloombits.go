@@ -55,6 +55,11 @@ func (gdtu *Gdtu) startBloomHandlers(sectionSize uint64) {
 					task := <-request
 					task.Bitsets = make([][]byte, len(task.Sections))
 					for i, section := range task.Sections {
+						// Stop early if the filter was abandoned or timed out
+						if task.Context != nil && task.Context.Err() != nil {
+							task.Error = task.Context.Err()
+							break
+						}
 						head := rawdb.ReadCanonicalHash(gdtu.chainDb, (section+1)*sectionSize-1)
 						if compVector, err := rawdb.ReadBloomBits(gdtu.chainDb, task.Bit, section, head); err == nil {
 							if blob, err := bitutil.DecompressBytes(compVector, int(sectionSize/8)); err == nil {
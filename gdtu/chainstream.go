@@ -0,0 +1,157 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/log"
+)
+
+const (
+	chainExportPath = "/chain/export" // HTTP path streaming a chain segment out of the node
+	chainImportPath = "/chain/import" // HTTP path streaming a chain segment into the node
+)
+
+// chainStreamHandler moves chain segments in and out of a node over HTTP, as
+// streams of RLP encoded blocks (the format of the export and import commands).
+// Segments are exported with a GET request to chainExportPath, taking the range
+// of blocks from the first and last query parameters. They are imported with a
+// POST request to chainImportPath carrying the blocks in its body. Both support
+// gzip compression.
+type chainStreamHandler struct {
+	chain *core.BlockChain
+}
+
+// chainImportResult is the response to a chain segment import.
+type chainImportResult struct {
+	Blocks int    `json:"blocks"`          // Number of blocks read from the stream
+	Head   uint64 `json:"head"`            // Number of the head block after the import
+	Error  string `json:"error,omitempty"` // Failure of the import, if any
+}
+
+// ServeHTTP implements http.Handler.
+func (h *chainStreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == chainExportPath && r.Method == http.MethodGet:
+		h.export(w, r)
+	case r.URL.Path == chainImportPath && r.Method == http.MethodPost:
+		h.importChain(w, r)
+	case r.URL.Path == chainExportPath || r.URL.Path == chainImportPath:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// export streams the requested range of blocks, the whole chain by default.
+func (h *chainStreamHandler) export(w http.ResponseWriter, r *http.Request) {
+	var (
+		head  = h.chain.CurrentBlock().NumberU64()
+		first = uint64(0)
+		last  = head
+		err   error
+	)
+	query := r.URL.Query()
+	if arg := query.Get("first"); arg != "" {
+		if first, err = strconv.ParseUint(arg, 0, 64); err != nil {
+			http.Error(w, fmt.Sprintf("invalid first block: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if arg := query.Get("last"); arg != "" {
+		if last, err = strconv.ParseUint(arg, 0, 64); err != nil {
+			http.Error(w, fmt.Sprintf("invalid last block: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if first > last || last > head {
+		http.Error(w, fmt.Sprintf("invalid block range %d-%d, head is %d", first, last, head), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+
+	var out io.Writer = w
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	}
+	// The response is streamed as the blocks are read, failures past this point
+	// can only be reported by cutting the stream short
+	log.Info("Streaming chain segment", "first", first, "last", last, "remote", r.RemoteAddr)
+	if err := exportChain(h.chain, out, first, last); err != nil {
+		log.Warn("Chain segment streaming failed", "first", first, "last", last, "remote", r.RemoteAddr, "err", err)
+	}
+}
+
+// importChain inserts the blocks streamed in the request body into the chain.
+func (h *chainStreamHandler) importChain(w http.ResponseWriter, r *http.Request) {
+	var in io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid gzip stream: %v", err), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		in = gz
+	}
+	log.Info("Importing streamed chain segment", "remote", r.RemoteAddr)
+	blocks, err := importChain(h.chain, in)
+
+	result := chainImportResult{Blocks: blocks, Head: h.chain.CurrentBlock().NumberU64()}
+	status := http.StatusOK
+	if err != nil {
+		log.Warn("Streamed chain segment import failed", "blocks", blocks, "remote", r.RemoteAddr, "err", err)
+		result.Error, status = err.Error(), http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// exportChain writes the given range of canonical blocks RLP encoded. Unlike
+// BlockChain.ExportN, the chain isn't locked for the whole export, so that slow
+// readers don't stall block imports. The export fails if the range is reorged
+// while being exported.
+func exportChain(chain *core.BlockChain, w io.Writer, first, last uint64) error {
+	var parent common.Hash
+	for nr := first; nr <= last; nr++ {
+		block := chain.GetBlockByNumber(nr)
+		if block == nil {
+			return fmt.Errorf("export failed on #%d: not found", nr)
+		}
+		if nr > first && block.ParentHash() != parent {
+			return fmt.Errorf("export failed on #%d: chain reorged", nr)
+		}
+		if err := block.EncodeRLP(w); err != nil {
+			return err
+		}
+		parent = block.Hash()
+	}
+	return nil
+}
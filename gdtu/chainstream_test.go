@@ -0,0 +1,150 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/consensus/gdtuash"
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// newStreamTestChain creates a chain with the given number of blocks.
+func newStreamTestChain(t *testing.T, blocks int) *core.BlockChain {
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, gdtuash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	bs, _ := core.GenerateChain(params.TestChainConfig, genesis, gdtuash.NewFaker(), db, blocks, nil)
+	if _, err := chain.InsertChain(bs); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return chain
+}
+
+// Tests that chain segments can be streamed between nodes over HTTP.
+func TestChainStream(t *testing.T) {
+	source := newStreamTestChain(t, 64)
+	defer source.Stop()
+	sink := newStreamTestChain(t, 0)
+	defer sink.Stop()
+
+	exporter := httptest.NewServer(&chainStreamHandler{chain: source})
+	defer exporter.Close()
+	importer := httptest.NewServer(&chainStreamHandler{chain: sink})
+	defer importer.Close()
+
+	// Invalid ranges and methods must be rejected
+	for _, url := range []string{"/chain/export?first=10&last=5", "/chain/export?last=65", "/chain/export?first=x"} {
+		res, err := http.Get(exporter.URL + url)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", url, err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status mismatch: have %d, want %d", url, res.StatusCode, http.StatusBadRequest)
+		}
+	}
+	if res, err := http.Post(exporter.URL+"/chain/export", "", nil); err != nil {
+		t.Fatalf("export post failed: %v", err)
+	} else if res.Body.Close(); res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("export post status mismatch: have %d, want %d", res.StatusCode, http.StatusMethodNotAllowed)
+	}
+	// Move the first segment over in gzip, the client decompressing it on the fly
+	res, err := http.Get(exporter.URL + "/chain/export?first=1&last=32")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	segment, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !res.Uncompressed {
+		t.Errorf("export not compressed")
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(segment)
+	gz.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, importer.URL+"/chain/import", &compressed)
+	req.Header.Set("Content-Encoding", "gzip")
+	if result := postChainImport(t, req); result.Blocks != 32 || result.Head != 32 || result.Error != "" {
+		t.Fatalf("first import mismatch: %+v", result)
+	}
+	// Stream the rest of the chain (overlapping) straight from the exporter
+	res, err = http.Get(exporter.URL + "/chain/export?first=20")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	defer res.Body.Close()
+	req, _ = http.NewRequest(http.MethodPost, importer.URL+"/chain/import", res.Body)
+	if result := postChainImport(t, req); result.Blocks != 45 || result.Head != 64 || result.Error != "" {
+		t.Fatalf("second import mismatch: %+v", result)
+	}
+	if sink.CurrentBlock().Hash() != source.CurrentBlock().Hash() {
+		t.Fatalf("head mismatch: have %x, want %x", sink.CurrentBlock().Hash(), source.CurrentBlock().Hash())
+	}
+	// Streams with gaps must fail
+	res, err = http.Get(exporter.URL + "/chain/export?first=3")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	defer res.Body.Close()
+	req, _ = http.NewRequest(http.MethodPost, newStreamTestServer(t).URL+"/chain/import", res.Body)
+	if result := postChainImport(t, req); result.Error == "" || result.Head != 0 {
+		t.Fatalf("gapped import succeeded: %+v", result)
+	}
+}
+
+// newStreamTestServer creates a chain stream server for an empty chain.
+func newStreamTestServer(t *testing.T) *httptest.Server {
+	chain := newStreamTestChain(t, 0)
+	server := httptest.NewServer(&chainStreamHandler{chain: chain})
+	t.Cleanup(func() {
+		server.Close()
+		chain.Stop()
+	})
+	return server
+}
+
+// postChainImport sends a chain import request and decodes its result.
+func postChainImport(t *testing.T, req *http.Request) *chainImportResult {
+	t.Helper()
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	result := new(chainImportResult)
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		t.Fatalf("failed to decode import result: %v", err)
+	}
+	return result
+}
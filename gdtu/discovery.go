@@ -40,8 +40,8 @@ func (e gdtuEntry) ENRKey() string {
 
 // startGdtuEntryUpdate starts the ENR updater loop.
 func (gdtu *Gdtu) startGdtuEntryUpdate(ln *enode.LocalNode) {
-	var newHead = make(chan core.ChainHeadEvent, 10)
-	sub := gdtu.blockchain.SubscribeChainHeadEvent(newHead)
+	var newHead = make(chan core.ChainHeadEvent)
+	sub := gdtu.blockchain.SubscribeChainHeadEventCoalesced(newHead)
 
 	go func() {
 		defer sub.Unsubscribe()
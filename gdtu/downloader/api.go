@@ -21,7 +21,7 @@ import (
 	"sync"
 
 	"github.com/c88032111/go-gdtu"
-	"github.com/c88032111/go-gdtu/event"
+	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/rpc"
 )
 
@@ -29,19 +29,17 @@ import (
 // It offers only Methods that operates on data that can be available to anyone without security risks.
 type PublicDownloaderAPI struct {
 	d                         *Downloader
-	mux                       *event.TypeMux
 	installSyncSubscription   chan chan interface{}
 	uninstallSyncSubscription chan *uninstallSyncSubscriptionRequest
 }
 
 // NewPublicDownloaderAPI create a new PublicDownloaderAPI. The API has an internal event loop that
-// listens for events from the downloader through the global event mux. In case it receives one of
-// these events it broadcasts it to all syncing subscriptions that are installed through the
+// listens for the sync events of the downloader. In case it receives one of these events it
+// broadcasts it to all syncing subscriptions that are installed through the
 // installSyncSubscription channel.
-func NewPublicDownloaderAPI(d *Downloader, m *event.TypeMux) *PublicDownloaderAPI {
+func NewPublicDownloaderAPI(d *Downloader) *PublicDownloaderAPI {
 	api := &PublicDownloaderAPI{
 		d:                         d,
-		mux:                       m,
 		installSyncSubscription:   make(chan chan interface{}),
 		uninstallSyncSubscription: make(chan *uninstallSyncSubscriptionRequest),
 	}
@@ -51,13 +49,15 @@ func NewPublicDownloaderAPI(d *Downloader, m *event.TypeMux) *PublicDownloaderAP
 	return api
 }
 
-// eventLoop runs a loop until the event mux closes. It will install and uninstall new
+// eventLoop runs a loop until the downloader terminates. It will install and uninstall new
 // sync subscriptions and broadcasts sync status updates to the installed sync subscriptions.
 func (api *PublicDownloaderAPI) eventLoop() {
 	var (
-		sub               = api.mux.Subscribe(StartEvent{}, DoneEvent{}, FailedEvent{})
+		events            = make(chan SyncEvent)
+		sub               = api.d.SubscribeSyncEvents(events)
 		syncSubscriptions = make(map[chan interface{}]struct{})
 	)
+	defer sub.Unsubscribe()
 
 	for {
 		select {
@@ -66,19 +66,16 @@ func (api *PublicDownloaderAPI) eventLoop() {
 		case u := <-api.uninstallSyncSubscription:
 			delete(syncSubscriptions, u.c)
 			close(u.uninstalled)
-		case event := <-sub.Chan():
-			if event == nil {
-				return
-			}
-
+		case <-api.d.quitCh:
+			return
+		case event := <-events:
 			var notification interface{}
-			switch event.Data.(type) {
-			case StartEvent:
+			if event.Start != nil {
 				notification = &SyncingResult{
 					Syncing: true,
 					Status:  api.d.Progress(),
 				}
-			case DoneEvent, FailedEvent:
+			} else {
 				notification = false
 			}
 			// broadcast
@@ -119,6 +116,55 @@ func (api *PublicDownloaderAPI) Syncing(ctx context.Context) (*rpc.Subscription,
 	return rpcSub, nil
 }
 
+// SyncEvents provides a notification for every synchronisation status change of this
+// node: when a synchronisation starts, when it's done and when it failed.
+func (api *PublicDownloaderAPI) SyncEvents(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan SyncEvent, 16)
+		sub := api.d.SubscribeSyncEvents(events)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case event := <-events:
+				notifier.Notify(rpcSub.ID, newSyncEventResult(event))
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// SyncEventResult is the notification of a synchronisation status change.
+type SyncEventResult struct {
+	Type   string        `json:"type"` // "start", "done" or "failed"
+	Latest *types.Header `json:"latest,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// newSyncEventResult creates the notification of a sync event.
+func newSyncEventResult(event SyncEvent) *SyncEventResult {
+	switch {
+	case event.Done != nil:
+		return &SyncEventResult{Type: "done", Latest: event.Done.Latest}
+	case event.Failed != nil:
+		return &SyncEventResult{Type: "failed", Error: event.Failed.Err.Error()}
+	default:
+		return &SyncEventResult{Type: "start"}
+	}
+}
+
 // SyncingResult provides information about the current synchronisation status for this node.
 type SyncingResult struct {
 	Syncing bool              `json:"syncing"`
@@ -0,0 +1,81 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"fmt"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/gdtu/protocols/gdtu"
+)
+
+// dispatcher is implemented by peers tagging their requests with ids. Responses
+// to requests dispatched through it are matched to the requests by id instead
+// of by peer, so a late response to an abandoned request is dropped instead of
+// being taken for the response to the next one.
+type dispatcher interface {
+	DispatchHeadersByHash(origin common.Hash, amount int, skip int, reverse bool, sink chan *gdtu.Response) (*gdtu.Request, error)
+	DispatchHeadersByNumber(origin uint64, amount int, skip int, reverse bool, sink chan *gdtu.Response) (*gdtu.Request, error)
+	DispatchBodies(hashes []common.Hash, sink chan *gdtu.Response) (*gdtu.Request, error)
+	DispatchReceipts(hashes []common.Hash, sink chan *gdtu.Response) (*gdtu.Request, error)
+	DispatchNodeData(hashes []common.Hash, sink chan *gdtu.Response) (*gdtu.Request, error)
+}
+
+// dispatchLoop delivers the responses to the requests dispatched to a peer
+// supporting request ids, until the peer is unregistered.
+func (d *Downloader) dispatchLoop(p *peerConnection) {
+	for {
+		select {
+		case res := <-p.sink:
+			d.deliverResponse(p, res)
+		case <-p.quit:
+			return
+		case <-d.quitCh:
+			return
+		}
+	}
+}
+
+// deliverResponse injects the response to a dispatched request into the download
+// schedule, dropping it if the request was abandoned in the meantime.
+func (d *Downloader) deliverResponse(p *peerConnection, res *gdtu.Response) {
+	var (
+		code    uint64
+		deliver func() error
+	)
+	switch packet := res.Res.(type) {
+	case *gdtu.BlockHeadersPacket:
+		code, deliver = gdtu.BlockHeadersMsg, func() error { return d.DeliverHeaders(p.id, *packet) }
+	case *gdtu.BlockBodiesPacket:
+		txs, uncles := packet.Unpack()
+		code, deliver = gdtu.BlockBodiesMsg, func() error { return d.DeliverBodies(p.id, txs, uncles) }
+	case *gdtu.ReceiptsPacket:
+		code, deliver = gdtu.ReceiptsMsg, func() error { return d.DeliverReceipts(p.id, *packet) }
+	case *gdtu.NodeDataPacket:
+		code, deliver = gdtu.NodeDataMsg, func() error { return d.DeliverNodeData(p.id, *packet) }
+	default:
+		p.log.Warn("Unexpected dispatched response", "type", fmt.Sprintf("%T", res.Res))
+		return
+	}
+	if !p.complete(code, res.Req) {
+		p.log.Debug("Dropping response to abandoned request", "code", code)
+		return
+	}
+	if err := deliver(); err != nil {
+		p.log.Debug("Failed to deliver dispatched response", "code", code, "err", err)
+	}
+}
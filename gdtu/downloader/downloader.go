@@ -101,8 +101,9 @@ type Downloader struct {
 	rttEstimate   uint64 // Round trip time to target for download requests
 	rttConfidence uint64 // Confidence in the estimated RTT (unit: millionths to allow atomic ops)
 
-	mode uint32         // Synchronisation mode defining the strategy used (per sync cycle), use d.getMode() to get the SyncMode
-	mux  *event.TypeMux // Event multiplexer to announce sync operation events
+	mode     uint32         // Synchronisation mode defining the strategy used (per sync cycle), use d.getMode() to get the SyncMode
+	mux      *event.TypeMux // Event multiplexer to announce sync operation events (deprecated, use syncFeed)
+	syncFeed event.Feed     // Feed announcing the sync status changes as SyncEvents
 
 	checkpoint uint64   // Checkpoint block number to enforce head against (e.g. fast sync)
 	genesis    uint64   // Genesis block number to limit sync to (e.g. light client CHT)
@@ -216,6 +217,9 @@ type BlockChain interface {
 }
 
 // New creates a new downloader to fetch hashes and blocks from remote peers.
+//
+// The sync status changes are posted to the event mux if one is given. This is
+// deprecated in favour of SubscribeSyncEvents, and the mux may be nil.
 func New(checkpoint uint64, stateDb gdtudb.Database, stateBloom *trie.SyncBloom, mux *event.TypeMux, chain BlockChain, lightchain LightChain, dropPeer peerDropFn) *Downloader {
 	if lightchain == nil {
 		lightchain = chain
@@ -301,10 +305,14 @@ func (d *Downloader) RegisterPeer(id string, version uint, peer Peer) error {
 		logger = log.New("peer", id[:8])
 	}
 	logger.Trace("Registering sync peer")
-	if err := d.peers.Register(newPeerConnection(id, version, peer, logger)); err != nil {
+	p := newPeerConnection(id, version, peer, logger)
+	if err := d.peers.Register(p); err != nil {
 		logger.Error("Failed to register sync peer", "err", err)
 		return err
 	}
+	if p.dispatcher != nil {
+		go d.dispatchLoop(p)
+	}
 	d.qosReduceConfidence()
 
 	return nil
@@ -328,15 +336,23 @@ func (d *Downloader) UnregisterPeer(id string) error {
 		logger = log.New("peer", id[:8])
 	}
 	logger.Trace("Unregistering sync peer")
+	p := d.peers.Peer(id)
 	if err := d.peers.Unregister(id); err != nil {
 		logger.Error("Failed to unregister sync peer", "err", err)
 		return err
 	}
+	p.close()
 	d.queue.Revoke(id)
 
 	return nil
 }
 
+// IsInvalidChain reports whgdtuer a sync failure was caused by the peer delivering
+// provably invalid chain data, as opposed to timeouts, stalls or missing peers.
+func IsInvalidChain(err error) bool {
+	return errors.Is(err, errInvalidChain) || errors.Is(err, errInvalidBody) || errors.Is(err, errInvalidReceipt)
+}
+
 // Synchronise tries to sync up our local block chain with a remote peer, both
 // adding various sanity checks as well as wrapping it with various log entries.
 func (d *Downloader) Synchronise(id string, head common.Hash, td *big.Int, mode SyncMode) error {
@@ -442,6 +458,29 @@ func (d *Downloader) synchronise(id string, hash common.Hash, td *big.Int, mode
 	return d.syncWithPeer(p, hash, td)
 }
 
+// SubscribeSyncEvents registers a subscription for the sync status changes of
+// the downloader.
+func (d *Downloader) SubscribeSyncEvents(ch chan<- SyncEvent) event.Subscription {
+	return d.syncFeed.Subscribe(ch)
+}
+
+// postSyncEvent announces a sync status change to the feed subscribers and the
+// legacy event mux.
+func (d *Downloader) postSyncEvent(ev SyncEvent) {
+	d.syncFeed.Send(ev)
+	if d.mux == nil {
+		return
+	}
+	switch {
+	case ev.Start != nil:
+		d.mux.Post(*ev.Start)
+	case ev.Done != nil:
+		d.mux.Post(*ev.Done)
+	case ev.Failed != nil:
+		d.mux.Post(*ev.Failed)
+	}
+}
+
 func (d *Downloader) getMode() SyncMode {
 	return SyncMode(atomic.LoadUint32(&d.mode))
 }
@@ -449,14 +488,14 @@ func (d *Downloader) getMode() SyncMode {
 // syncWithPeer starts a block synchronization based on the hash chain from the
 // specified peer and head hash.
 func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.Int) (err error) {
-	d.mux.Post(StartEvent{})
+	d.postSyncEvent(SyncEvent{Start: &StartEvent{}})
 	defer func() {
 		// reset on error
 		if err != nil {
-			d.mux.Post(FailedEvent{err})
+			d.postSyncEvent(SyncEvent{Failed: &FailedEvent{err}})
 		} else {
 			latest := d.lightchain.CurrentHeader()
-			d.mux.Post(DoneEvent{latest})
+			d.postSyncEvent(SyncEvent{Done: &DoneEvent{latest}})
 		}
 	}()
 	if p.version < 32 {
@@ -657,7 +696,7 @@ func (d *Downloader) fetchHead(p *peerConnection) (head *types.Header, pivot *ty
 	if mode == FastSync {
 		fetch = 2 // head + pivot headers
 	}
-	go p.peer.RequestHeadersByHash(latest, fetch, fsMinFullBlocks-1, true)
+	go p.requestHeadersByHash(latest, fetch, fsMinFullBlocks-1, true)
 
 	ttl := d.requestTTL()
 	timeout := time.After(ttl)
@@ -837,7 +876,7 @@ func (d *Downloader) findAncestorSpanSearch(p *peerConnection, mode SyncMode, re
 	from, count, skip, max := calculateRequestSpan(remoteHeight, localHeight)
 
 	p.log.Trace("Span searching for common ancestor", "count", count, "from", from, "skip", skip)
-	go p.peer.RequestHeadersByNumber(uint64(from), count, skip, false)
+	go p.requestHeadersByNumber(uint64(from), count, skip, false)
 
 	// Wait for the remote response to the head fetch
 	number, hash := uint64(0), common.Hash{}
@@ -934,7 +973,7 @@ func (d *Downloader) findAncestorBinarySearch(p *peerConnection, mode SyncMode,
 		ttl := d.requestTTL()
 		timeout := time.After(ttl)
 
-		go p.peer.RequestHeadersByNumber(check, 1, 0, false)
+		go p.requestHeadersByNumber(check, 1, 0, false)
 
 		// Wait until a reply arrives to this request
 		for arrived := false; !arrived; {
@@ -1029,10 +1068,10 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64) error {
 
 		if skeleton {
 			p.log.Trace("Fetching skeleton headers", "count", MaxHeaderFetch, "from", from)
-			go p.peer.RequestHeadersByNumber(from+uint64(MaxHeaderFetch)-1, MaxSkeletonSize, MaxHeaderFetch-1, false)
+			go p.requestHeadersByNumber(from+uint64(MaxHeaderFetch)-1, MaxSkeletonSize, MaxHeaderFetch-1, false)
 		} else {
 			p.log.Trace("Fetching full headers", "count", MaxHeaderFetch, "from", from)
-			go p.peer.RequestHeadersByNumber(from, MaxHeaderFetch, 0, false)
+			go p.requestHeadersByNumber(from, MaxHeaderFetch, 0, false)
 		}
 	}
 	getNextPivot := func() {
@@ -1047,7 +1086,7 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64) error {
 		d.pivotLock.RUnlock()
 
 		p.log.Trace("Fetching next pivot header", "number", pivot+uint64(fsMinFullBlocks))
-		go p.peer.RequestHeadersByNumber(pivot+uint64(fsMinFullBlocks), 2, fsMinFullBlocks-9, false) // move +64 when it's 2x64-8 deep
+		go p.requestHeadersByNumber(pivot+uint64(fsMinFullBlocks), 2, fsMinFullBlocks-9, false) // move +64 when it's 2x64-8 deep
 	}
 	// Start pulling the header chain skeleton until all is done
 	ancestor := from
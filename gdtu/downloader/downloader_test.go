@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"math/rand"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -31,7 +32,9 @@ import (
 	"github.com/c88032111/go-gdtu/core/rawdb"
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/event"
+	gdtuproto "github.com/c88032111/go-gdtu/gdtu/protocols/gdtu"
 	"github.com/c88032111/go-gdtu/gdtudb"
+	"github.com/c88032111/go-gdtu/log"
 	"github.com/c88032111/go-gdtu/trie"
 )
 
@@ -462,6 +465,13 @@ func (dlp *downloadTesterPeer) RequestReceipts(hashes []common.Hash) error {
 // peer in the download tester. The returned function can be used to retrieve
 // batches of node state data from the particularly requested peer.
 func (dlp *downloadTesterPeer) RequestNodeData(hashes []common.Hash) error {
+	results := dlp.nodeData(hashes)
+	go dlp.dl.downloader.DeliverNodeData(dlp.id, results)
+	return nil
+}
+
+// nodeData retrieves the node state data the peer has of the requested hashes.
+func (dlp *downloadTesterPeer) nodeData(hashes []common.Hash) [][]byte {
 	dlp.dl.lock.RLock()
 	defer dlp.dl.lock.RUnlock()
 
@@ -473,8 +483,7 @@ func (dlp *downloadTesterPeer) RequestNodeData(hashes []common.Hash) error {
 			}
 		}
 	}
-	go dlp.dl.downloader.DeliverNodeData(dlp.id, results)
-	return nil
+	return results
 }
 
 // assertOwnChain checks if the local chain contains the correct number of items
@@ -1736,3 +1745,253 @@ func testCheckpointEnforcement(t *testing.T, protocol uint, mode SyncMode) {
 		assertOwnChain(t, tester, chain.len())
 	}
 }
+
+// Tests that the gap between a trusted head and the local chain can be filled by
+// retrieving the headers backwards, and that the skeleton store is cleaned up.
+func TestBackfillHeaders(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", 66, chain)
+
+	// Import a short prefix of the chain, simulating a deep rollback
+	if _, err := tester.InsertHeaderChain(chain.headersByNumber(1, 100, 0, false), 1); err != nil {
+		t.Fatalf("failed to import chain prefix: %v", err)
+	}
+	head := chain.headerm[chain.chain[chain.len()-1]]
+	if err := tester.downloader.BackfillHeaders("peer", head); err != nil {
+		t.Fatalf("failed to backfill headers: %v", err)
+	}
+	for number := 0; number < chain.len(); number++ {
+		if tester.GetHeaderByHash(chain.chain[number]) == nil {
+			t.Fatalf("header #%d missing after backfill", number)
+		}
+	}
+	if rawdb.ReadSkeletonHeader(tester.stateDb, head.Number.Uint64()) != nil {
+		t.Errorf("skeleton head not deleted")
+	}
+	if len(rawdb.ReadSkeletonSyncStatus(tester.stateDb)) != 0 {
+		t.Errorf("skeleton status not deleted")
+	}
+}
+
+// dispatchTesterPeer is a test peer supporting request ids, answering the
+// dispatched requests in random order.
+type dispatchTesterPeer struct {
+	*downloadTesterPeer
+
+	lock       sync.Mutex
+	dispatched int // Number of requests dispatched
+	inflight   int // Number of requests currently in flight
+	peak       int // Maximum number of requests in flight at once
+}
+
+// dispatch answers a request with the given response after a random delay.
+func (dlp *dispatchTesterPeer) dispatch(res interface{}, sink chan *gdtuproto.Response) (*gdtuproto.Request, error) {
+	dlp.lock.Lock()
+	dlp.dispatched++
+	dlp.inflight++
+	if dlp.inflight > dlp.peak {
+		dlp.peak = dlp.inflight
+	}
+	dlp.lock.Unlock()
+
+	req := new(gdtuproto.Request)
+	go func() {
+		time.Sleep(time.Duration(rand.Intn(10)) * time.Millisecond)
+
+		dlp.lock.Lock()
+		dlp.inflight--
+		dlp.lock.Unlock()
+		sink <- &gdtuproto.Response{Req: req, Res: res}
+	}()
+	return req, nil
+}
+
+func (dlp *dispatchTesterPeer) DispatchHeadersByHash(origin common.Hash, amount int, skip int, reverse bool, sink chan *gdtuproto.Response) (*gdtuproto.Request, error) {
+	headers := gdtuproto.BlockHeadersPacket(dlp.chain.headersByHash(origin, amount, skip, reverse))
+	return dlp.dispatch(&headers, sink)
+}
+
+func (dlp *dispatchTesterPeer) DispatchHeadersByNumber(origin uint64, amount int, skip int, reverse bool, sink chan *gdtuproto.Response) (*gdtuproto.Request, error) {
+	headers := gdtuproto.BlockHeadersPacket(dlp.chain.headersByNumber(origin, amount, skip, reverse))
+	return dlp.dispatch(&headers, sink)
+}
+
+func (dlp *dispatchTesterPeer) DispatchBodies(hashes []common.Hash, sink chan *gdtuproto.Response) (*gdtuproto.Request, error) {
+	txs, uncles := dlp.chain.bodies(hashes)
+	bodies := make(gdtuproto.BlockBodiesPacket, len(txs))
+	for i := range txs {
+		bodies[i] = &gdtuproto.BlockBody{Transactions: txs[i], Uncles: uncles[i]}
+	}
+	return dlp.dispatch(&bodies, sink)
+}
+
+func (dlp *dispatchTesterPeer) DispatchReceipts(hashes []common.Hash, sink chan *gdtuproto.Response) (*gdtuproto.Request, error) {
+	receipts := gdtuproto.ReceiptsPacket(dlp.chain.receipts(hashes))
+	return dlp.dispatch(&receipts, sink)
+}
+
+func (dlp *dispatchTesterPeer) DispatchNodeData(hashes []common.Hash, sink chan *gdtuproto.Response) (*gdtuproto.Request, error) {
+	data := gdtuproto.NodeDataPacket(dlp.nodeData(hashes))
+	return dlp.dispatch(&data, sink)
+}
+
+// Tests that syncing with peers supporting request ids retrieves all the data
+// through dispatched requests.
+func TestDispatchedSync(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []SyncMode{FullSync, FastSync} {
+		tester := newTester()
+
+		chain := testChainBase.shorten(blockCacheMaxItems - 15)
+		peer := &dispatchTesterPeer{downloadTesterPeer: &downloadTesterPeer{dl: tester, id: "peer", chain: chain}}
+		tester.lock.Lock()
+		tester.peers["peer"] = peer.downloadTesterPeer
+		tester.lock.Unlock()
+
+		if err := tester.downloader.RegisterPeer("peer", gdtuproto.GDTU34, peer); err != nil {
+			t.Fatalf("failed to register peer: %v", err)
+		}
+		if err := tester.sync("peer", nil, mode); err != nil {
+			t.Fatalf("%v: failed to synchronise blocks: %v", mode, err)
+		}
+		assertOwnChain(t, tester, chain.len())
+
+		peer.lock.Lock()
+		if peer.dispatched == 0 {
+			t.Errorf("%v: no requests dispatched", mode)
+		}
+		peer.lock.Unlock()
+		tester.terminate()
+	}
+}
+
+// Tests that a late response to an abandoned dispatched request is not taken
+// for the response to the request replacing it.
+func TestDispatchedAbandonedRequest(t *testing.T) {
+	tester := newTester()
+	defer tester.terminate()
+
+	peer := &dispatchTesterPeer{downloadTesterPeer: &downloadTesterPeer{dl: tester, id: "peer", chain: testChainBase}}
+	p := newPeerConnection("peer", gdtuproto.GDTU34, peer, log.New())
+	if p.dispatcher == nil {
+		t.Fatalf("peer with request ids not dispatching")
+	}
+	if newPeerConnection("peer", gdtuproto.GDTU33, peer, log.New()).dispatcher != nil {
+		t.Fatalf("peer without request ids dispatching")
+	}
+	var stale, fresh *gdtuproto.Request
+	p.dispatch(gdtuproto.BlockHeadersMsg, func() (req *gdtuproto.Request, err error) {
+		stale, err = peer.DispatchHeadersByNumber(0, 1, 0, false, make(chan *gdtuproto.Response, 1))
+		return stale, err
+	})
+	p.dispatch(gdtuproto.BlockHeadersMsg, func() (req *gdtuproto.Request, err error) {
+		fresh, err = peer.DispatchHeadersByNumber(0, 1, 0, false, make(chan *gdtuproto.Response, 1))
+		return fresh, err
+	})
+
+	if p.complete(gdtuproto.BlockHeadersMsg, stale) {
+		t.Errorf("response to abandoned request accepted")
+	}
+	if !p.complete(gdtuproto.BlockHeadersMsg, fresh) {
+		t.Errorf("response to tracked request rejected")
+	}
+	if p.complete(gdtuproto.BlockHeadersMsg, fresh) {
+		t.Errorf("duplicate response accepted")
+	}
+}
+
+// Tests that the backfill keeps multiple requests in flight to peers supporting
+// request ids, linking up the responses arriving out of order.
+func TestBackfillHeadersPipelined(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	peer := &dispatchTesterPeer{downloadTesterPeer: &downloadTesterPeer{dl: tester, id: "peer", chain: chain}}
+	if err := tester.downloader.RegisterPeer("peer", gdtuproto.GDTU34, peer); err != nil {
+		t.Fatalf("failed to register peer: %v", err)
+	}
+	if _, err := tester.InsertHeaderChain(chain.headersByNumber(1, 100, 0, false), 1); err != nil {
+		t.Fatalf("failed to import chain prefix: %v", err)
+	}
+	head := chain.headerm[chain.chain[chain.len()-1]]
+	if err := tester.downloader.BackfillHeaders("peer", head); err != nil {
+		t.Fatalf("failed to backfill headers: %v", err)
+	}
+	for number := 0; number < chain.len(); number++ {
+		if tester.GetHeaderByHash(chain.chain[number]) == nil {
+			t.Fatalf("header #%d missing after backfill", number)
+		}
+	}
+	peer.lock.Lock()
+	defer peer.lock.Unlock()
+	if peer.peak < 2 {
+		t.Errorf("requests not pipelined: at most %d in flight", peer.peak)
+	}
+}
+
+// Tests that the sync status changes are announced in order to the feed and the
+// legacy event mux subscribers.
+func TestSyncEvents(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+
+	events := make(chan SyncEvent, 4)
+	sub := tester.downloader.SubscribeSyncEvents(events)
+	defer sub.Unsubscribe()
+	legacy := tester.downloader.mux.Subscribe(StartEvent{}, DoneEvent{}, FailedEvent{})
+	defer legacy.Unsubscribe()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", 34, chain)
+	tester.newPeer("stale", 31, chain)
+
+	// A legacy mux subscriber needs to be drained for the sync to proceed
+	go func() {
+		for range legacy.Chan() {
+		}
+	}()
+	if err := tester.sync("peer", nil, FullSync); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	if err := tester.sync("stale", nil, FullSync); !errors.Is(err, errTooOld) {
+		t.Fatalf("stale peer sync error mismatch: have %v, want %v", err, errTooOld)
+	}
+	if ev := <-events; ev.Start == nil {
+		t.Fatalf("start event mismatch: %+v", ev)
+	}
+	if ev := <-events; ev.Done == nil || ev.Done.Latest.Hash() != chain.headBlock().Hash() {
+		t.Fatalf("done event mismatch: %+v", ev)
+	}
+	if ev := <-events; ev.Start == nil {
+		t.Fatalf("start event mismatch: %+v", ev)
+	}
+	if ev := <-events; ev.Failed == nil || !errors.Is(ev.Failed.Err, errTooOld) {
+		t.Fatalf("failed event mismatch: %+v", ev)
+	}
+}
+
+// Tests that only provably invalid chain data is reported as such, not timeouts
+// or stalls which honest but slow peers may cause.
+func TestIsInvalidChain(t *testing.T) {
+	for _, err := range []error{errInvalidChain, fmt.Errorf("%w: %v", errInvalidChain, errInvalidBody), errInvalidReceipt} {
+		if !IsInvalidChain(err) {
+			t.Errorf("%v: not reported as invalid chain", err)
+		}
+	}
+	for _, err := range []error{errTimeout, errStallingPeer, errPeersUnavailable, fmt.Errorf("%w: header request timed out", errBadPeer)} {
+		if IsInvalidChain(err) {
+			t.Errorf("%v: reported as invalid chain", err)
+		}
+	}
+}
@@ -23,3 +23,11 @@ type DoneEvent struct {
 }
 type StartEvent struct{}
 type FailedEvent struct{ Err error }
+
+// SyncEvent is sent on every synchronisation status change of the downloader.
+// Exactly one of the fields is set, in the order the changes happened.
+type SyncEvent struct {
+	Start  *StartEvent
+	Done   *DoneEvent
+	Failed *FailedEvent
+}
@@ -70,6 +70,12 @@ type peerConnection struct {
 
 	peer Peer
 
+	dispatcher dispatcher               // Request dispatcher of the peer, nil if it lacks request ids
+	sink       chan *gdtu.Response      // Channel the responses to dispatched requests arrive on
+	requests   map[uint64]*gdtu.Request // Dispatched requests awaiting a response, by response code
+	reqLock    sync.Mutex               // Lock protecting the dispatched requests
+	quit       chan struct{}            // Closed when the peer is unregistered
+
 	version uint       // Gdtu protocol version number to switch strategies
 	log     log.Logger // Contextual logger to add extra infos to peer logs
 	lock    sync.RWMutex
@@ -114,13 +120,119 @@ func (w *lightPeerWrapper) RequestNodeData([]common.Hash) error {
 
 // newPeerConnection creates a new downloader peer.
 func newPeerConnection(id string, version uint, peer Peer, logger log.Logger) *peerConnection {
-	return &peerConnection{
+	p := &peerConnection{
 		id:      id,
 		lacking: make(map[common.Hash]struct{}),
 		peer:    peer,
 		version: version,
 		log:     logger,
+		quit:    make(chan struct{}),
+	}
+	if dispatcher, ok := peer.(dispatcher); ok && version >= gdtu.GDTU34 {
+		p.dispatcher = dispatcher
+		p.sink = make(chan *gdtu.Response, 1)
+		p.requests = make(map[uint64]*gdtu.Request)
+	}
+	return p
+}
+
+// close abandons the dispatched requests of an unregistered peer.
+func (p *peerConnection) close() {
+	p.reqLock.Lock()
+	defer p.reqLock.Unlock()
+
+	for code, req := range p.requests {
+		req.Close()
+		delete(p.requests, code)
+	}
+	close(p.quit)
+}
+
+// dispatch sends a request through the peer's dispatcher and records it as the
+// one awaiting a response of the given code, abandoning the previous one. The
+// lock is held across the send, so a quick response can't be matched against
+// the request before it's recorded.
+func (p *peerConnection) dispatch(code uint64, send func() (*gdtu.Request, error)) error {
+	p.reqLock.Lock()
+	defer p.reqLock.Unlock()
+
+	req, err := send()
+	if err != nil {
+		return err
+	}
+	if prev := p.requests[code]; prev != nil {
+		prev.Close()
+	}
+	p.requests[code] = req
+	return nil
+}
+
+// complete reports whgdtuer a response of the given code belongs to the tracked
+// request, and stops tracking the request if so.
+func (p *peerConnection) complete(code uint64, req *gdtu.Request) bool {
+	p.reqLock.Lock()
+	defer p.reqLock.Unlock()
+
+	if p.requests[code] != req {
+		return false
+	}
+	delete(p.requests, code)
+	return true
+}
+
+// requestHeadersByHash requests a batch of headers based on the hash of an
+// origin block, dispatching the request if the peer supports request ids.
+func (p *peerConnection) requestHeadersByHash(origin common.Hash, amount int, skip int, reverse bool) error {
+	if p.dispatcher == nil {
+		return p.peer.RequestHeadersByHash(origin, amount, skip, reverse)
+	}
+	return p.dispatch(gdtu.BlockHeadersMsg, func() (*gdtu.Request, error) {
+		return p.dispatcher.DispatchHeadersByHash(origin, amount, skip, reverse, p.sink)
+	})
+}
+
+// requestHeadersByNumber requests a batch of headers based on the number of an
+// origin block, dispatching the request if the peer supports request ids.
+func (p *peerConnection) requestHeadersByNumber(origin uint64, amount int, skip int, reverse bool) error {
+	if p.dispatcher == nil {
+		return p.peer.RequestHeadersByNumber(origin, amount, skip, reverse)
+	}
+	return p.dispatch(gdtu.BlockHeadersMsg, func() (*gdtu.Request, error) {
+		return p.dispatcher.DispatchHeadersByNumber(origin, amount, skip, reverse, p.sink)
+	})
+}
+
+// requestBodies requests a batch of block bodies, dispatching the request if
+// the peer supports request ids.
+func (p *peerConnection) requestBodies(hashes []common.Hash) error {
+	if p.dispatcher == nil {
+		return p.peer.RequestBodies(hashes)
+	}
+	return p.dispatch(gdtu.BlockBodiesMsg, func() (*gdtu.Request, error) {
+		return p.dispatcher.DispatchBodies(hashes, p.sink)
+	})
+}
+
+// requestReceipts requests a batch of receipts, dispatching the request if the
+// peer supports request ids.
+func (p *peerConnection) requestReceipts(hashes []common.Hash) error {
+	if p.dispatcher == nil {
+		return p.peer.RequestReceipts(hashes)
+	}
+	return p.dispatch(gdtu.ReceiptsMsg, func() (*gdtu.Request, error) {
+		return p.dispatcher.DispatchReceipts(hashes, p.sink)
+	})
+}
+
+// requestNodeData requests a batch of state data, dispatching the request if
+// the peer supports request ids.
+func (p *peerConnection) requestNodeData(hashes []common.Hash) error {
+	if p.dispatcher == nil {
+		return p.peer.RequestNodeData(hashes)
 	}
+	return p.dispatch(gdtu.NodeDataMsg, func() (*gdtu.Request, error) {
+		return p.dispatcher.DispatchNodeData(hashes, p.sink)
+	})
 }
 
 // Reset clears the internal state of a peer entity.
@@ -150,7 +262,7 @@ func (p *peerConnection) FetchHeaders(from uint64, count int) error {
 	p.headerStarted = time.Now()
 
 	// Issue the header retrieval request (absolute upwards without gaps)
-	go p.peer.RequestHeadersByNumber(from, count, 0, false)
+	go p.requestHeadersByNumber(from, count, 0, false)
 
 	return nil
 }
@@ -169,7 +281,7 @@ func (p *peerConnection) FetchBodies(request *fetchRequest) error {
 		for _, header := range request.Headers {
 			hashes = append(hashes, header.Hash())
 		}
-		p.peer.RequestBodies(hashes)
+		p.requestBodies(hashes)
 	}()
 
 	return nil
@@ -189,7 +301,7 @@ func (p *peerConnection) FetchReceipts(request *fetchRequest) error {
 		for _, header := range request.Headers {
 			hashes = append(hashes, header.Hash())
 		}
-		p.peer.RequestReceipts(hashes)
+		p.requestReceipts(hashes)
 	}()
 
 	return nil
@@ -203,7 +315,7 @@ func (p *peerConnection) FetchNodeData(hashes []common.Hash) error {
 	}
 	p.stateStarted = time.Now()
 
-	go p.peer.RequestNodeData(hashes)
+	go p.requestNodeData(hashes)
 
 	return nil
 }
@@ -0,0 +1,259 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build syncsim
+
+package downloader
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/gdtu/protocols/gdtu"
+	"github.com/c88032111/go-gdtu/rlp"
+)
+
+// ChainSource is the chain data served by a simulated peer.
+type ChainSource interface {
+	// Head returns the hash and total difficulty of the head block.
+	Head() (common.Hash, *big.Int)
+
+	// HeadersByHash and HeadersByNumber return the headers of a header query.
+	HeadersByHash(origin common.Hash, amount int, skip int, reverse bool) []*types.Header
+	HeadersByNumber(origin uint64, amount int, skip int, reverse bool) []*types.Header
+
+	// Bodies, Receipts and NodeData return the data available for the hashes.
+	Bodies(hashes []common.Hash) ([][]*types.Transaction, [][]*types.Header)
+	Receipts(hashes []common.Hash) [][]*types.Receipt
+	NodeData(hashes []common.Hash) [][]byte
+}
+
+// NetworkModel describes the simulated network link of a peer.
+type NetworkModel struct {
+	Latency   time.Duration // One-way delay of requests and responses
+	Jitter    time.Duration // Maximum random delay added to each response
+	Bandwidth int           // Response bytes transferred per second (0 = unlimited)
+	DropRate  float64       // Fraction of requests left unanswered
+	ErrorRate float64       // Fraction of requests answered with an empty response
+}
+
+// SimulatedPeer is a peer serving chain data to a downloader over a simulated
+// network link. Responses are delivered once the modelled round trip and the
+// transfer of the encoded response over the link complete. Transfers of a peer
+// share the link, so they are delivered one after the other.
+type SimulatedPeer struct {
+	id     string
+	source ChainSource
+	model  NetworkModel
+	d      *Downloader
+
+	rand *rand.Rand // Randomness of the jitter, drops and errors
+	busy time.Time  // Time the link finishes the pending transfers
+	lock sync.Mutex // Protects the randomness and the link state
+
+	requests uint64 // Number of requests received
+	dropped  uint64 // Number of requests left unanswered
+	failed   uint64 // Number of requests answered with an empty response
+	bytes    uint64 // Number of response bytes transferred
+}
+
+// NewSimulatedPeer creates a peer serving the chain data of a source over a link
+// following the network model. The seed makes the randomness of the link
+// reproducible.
+func NewSimulatedPeer(id string, source ChainSource, model NetworkModel, seed int64) *SimulatedPeer {
+	return &SimulatedPeer{
+		id:     id,
+		source: source,
+		model:  model,
+		rand:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Head implements Peer, retrieving the head of the served chain.
+func (p *SimulatedPeer) Head() (common.Hash, *big.Int) {
+	return p.source.Head()
+}
+
+// RequestHeadersByHash implements Peer, serving a header query with a hash origin.
+func (p *SimulatedPeer) RequestHeadersByHash(origin common.Hash, amount int, skip int, reverse bool) error {
+	headers := p.source.HeadersByHash(origin, amount, skip, reverse)
+	p.deliver(headers, func(empty bool) {
+		if empty {
+			headers = nil
+		}
+		p.d.DeliverHeaders(p.id, headers)
+	})
+	return nil
+}
+
+// RequestHeadersByNumber implements Peer, serving a header query with a number
+// origin.
+func (p *SimulatedPeer) RequestHeadersByNumber(origin uint64, amount int, skip int, reverse bool) error {
+	headers := p.source.HeadersByNumber(origin, amount, skip, reverse)
+	p.deliver(headers, func(empty bool) {
+		if empty {
+			headers = nil
+		}
+		p.d.DeliverHeaders(p.id, headers)
+	})
+	return nil
+}
+
+// RequestBodies implements Peer, serving a block body query.
+func (p *SimulatedPeer) RequestBodies(hashes []common.Hash) error {
+	txs, uncles := p.source.Bodies(hashes)
+	p.deliver([]interface{}{txs, uncles}, func(empty bool) {
+		if empty {
+			txs, uncles = nil, nil
+		}
+		p.d.DeliverBodies(p.id, txs, uncles)
+	})
+	return nil
+}
+
+// RequestReceipts implements Peer, serving a receipt query.
+func (p *SimulatedPeer) RequestReceipts(hashes []common.Hash) error {
+	receipts := p.source.Receipts(hashes)
+	p.deliver(receipts, func(empty bool) {
+		if empty {
+			receipts = nil
+		}
+		p.d.DeliverReceipts(p.id, receipts)
+	})
+	return nil
+}
+
+// RequestNodeData implements Peer, serving a state data query.
+func (p *SimulatedPeer) RequestNodeData(hashes []common.Hash) error {
+	data := p.source.NodeData(hashes)
+	p.deliver(data, func(empty bool) {
+		if empty {
+			data = nil
+		}
+		p.d.DeliverNodeData(p.id, data)
+	})
+	return nil
+}
+
+// deliver schedules the delivery of a response according to the network model.
+// The callback is invoked with whether an empty response should be delivered,
+// and isn't invoked at all for dropped requests.
+func (p *SimulatedPeer) deliver(response interface{}, callback func(empty bool)) {
+	atomic.AddUint64(&p.requests, 1)
+
+	p.lock.Lock()
+	var (
+		drop  = p.rand.Float64() < p.model.DropRate
+		empty = !drop && p.rand.Float64() < p.model.ErrorRate
+		delay = 2 * p.model.Latency
+	)
+	if p.model.Jitter > 0 {
+		delay += time.Duration(p.rand.Int63n(int64(p.model.Jitter)))
+	}
+	if drop {
+		p.lock.Unlock()
+		atomic.AddUint64(&p.dropped, 1)
+		return
+	}
+	var size int
+	if !empty {
+		if blob, err := rlp.EncodeToBytes(response); err == nil {
+			size = len(blob)
+		}
+	}
+	// Responses are transferred over the link once they arrive, after the
+	// transfers already in progress
+	arrival := time.Now().Add(delay)
+	if p.busy.After(arrival) {
+		arrival = p.busy
+	}
+	if p.model.Bandwidth > 0 {
+		arrival = arrival.Add(time.Duration(size) * time.Second / time.Duration(p.model.Bandwidth))
+	}
+	p.busy = arrival
+	p.lock.Unlock()
+
+	if empty {
+		atomic.AddUint64(&p.failed, 1)
+	}
+	atomic.AddUint64(&p.bytes, uint64(size))
+	time.AfterFunc(time.Until(arrival), func() { callback(empty) })
+}
+
+// SyncReport is the outcome of a simulated synchronisation.
+type SyncReport struct {
+	Mode     SyncMode
+	Blocks   uint64        // Number of blocks (or headers in light mode) synchronised
+	Duration time.Duration // Time taken by the synchronisation
+	Requests uint64        // Number of requests made to the peers
+	Dropped  uint64        // Number of requests left unanswered
+	Failed   uint64        // Number of requests answered with an empty response
+	Bytes    uint64        // Number of response bytes transferred
+}
+
+// BlocksPerSecond returns the synchronisation throughput.
+func (r *SyncReport) BlocksPerSecond() float64 {
+	if r.Duration == 0 {
+		return 0
+	}
+	return float64(r.Blocks) / r.Duration.Seconds()
+}
+
+func (r *SyncReport) String() string {
+	return fmt.Sprintf("%s sync: %d blocks in %v (%.1f blocks/s), %d requests (%d dropped, %d failed), %s transferred",
+		r.Mode, r.Blocks, common.PrettyDuration(r.Duration), r.BlocksPerSecond(), r.Requests, r.Dropped, r.Failed, common.StorageSize(r.Bytes))
+}
+
+// SimulateSync registers the simulated peers with the downloader and synchronises
+// with the head of the first one, reporting the achieved throughput. The peers
+// are unregistered afterwards.
+func SimulateSync(d *Downloader, mode SyncMode, peers ...*SimulatedPeer) (*SyncReport, error) {
+	if len(peers) == 0 {
+		return nil, errNoPeers
+	}
+	for _, peer := range peers {
+		peer.d = d
+		if err := d.RegisterPeer(peer.id, gdtu.GDTU34, peer); err != nil {
+			return nil, err
+		}
+		defer d.UnregisterPeer(peer.id)
+	}
+	head, td := peers[0].Head()
+
+	start := time.Now()
+	if err := d.Synchronise(peers[0].id, head, td, mode); err != nil {
+		return nil, err
+	}
+	progress := d.Progress()
+	report := &SyncReport{
+		Mode:     mode,
+		Blocks:   progress.CurrentBlock - progress.StartingBlock,
+		Duration: time.Since(start),
+	}
+	for _, peer := range peers {
+		report.Requests += atomic.LoadUint64(&peer.requests)
+		report.Dropped += atomic.LoadUint64(&peer.dropped)
+		report.Failed += atomic.LoadUint64(&peer.failed)
+		report.Bytes += atomic.LoadUint64(&peer.bytes)
+	}
+	return report, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build syncsim
+
+package downloader
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+)
+
+// testChainSource serves a test chain to simulated peers.
+type testChainSource struct {
+	chain *testChain
+}
+
+func (s *testChainSource) Head() (common.Hash, *big.Int) {
+	head := s.chain.headBlock().Hash()
+	return head, s.chain.td(head)
+}
+
+func (s *testChainSource) HeadersByHash(origin common.Hash, amount int, skip int, reverse bool) []*types.Header {
+	return s.chain.headersByHash(origin, amount, skip, reverse)
+}
+
+func (s *testChainSource) HeadersByNumber(origin uint64, amount int, skip int, reverse bool) []*types.Header {
+	return s.chain.headersByNumber(origin, amount, skip, reverse)
+}
+
+func (s *testChainSource) Bodies(hashes []common.Hash) ([][]*types.Transaction, [][]*types.Header) {
+	return s.chain.bodies(hashes)
+}
+
+func (s *testChainSource) Receipts(hashes []common.Hash) [][]*types.Receipt {
+	return s.chain.receipts(hashes)
+}
+
+func (s *testChainSource) NodeData(hashes []common.Hash) [][]byte {
+	results := make([][]byte, 0, len(hashes))
+	for _, hash := range hashes {
+		if data, err := testDB.Get(hash.Bytes()); err == nil {
+			results = append(results, data)
+		}
+	}
+	return results
+}
+
+// Network models the synchronisation is benchmarked with.
+var simulatedNetworks = []struct {
+	name  string
+	peers int
+	model NetworkModel
+}{
+	{"ideal", 1, NetworkModel{}},
+	{"lan", 4, NetworkModel{Latency: time.Millisecond, Bandwidth: 100 * 1024 * 1024}},
+	{"wan", 8, NetworkModel{Latency: 50 * time.Millisecond, Jitter: 50 * time.Millisecond, Bandwidth: 1024 * 1024}},
+	{"flaky", 8, NetworkModel{Latency: 50 * time.Millisecond, Jitter: 100 * time.Millisecond, Bandwidth: 256 * 1024, ErrorRate: 0.1}},
+}
+
+// simulateSync synchronises a fresh downloader with the base test chain, served
+// by the given number of simulated peers. Failures of the master peer abort the
+// sync, so only the other peers are subject to drops and errors.
+func simulateSync(peers int, model NetworkModel, mode SyncMode, seed int64) (*SyncReport, error) {
+	tester := newTester()
+	defer tester.terminate()
+
+	reliable := model
+	reliable.DropRate, reliable.ErrorRate = 0, 0
+
+	sims := []*SimulatedPeer{NewSimulatedPeer("peer-0", &testChainSource{testChainBase}, reliable, seed)}
+	for i := 1; i < peers; i++ {
+		sims = append(sims, NewSimulatedPeer(fmt.Sprintf("peer-%d", i), &testChainSource{testChainBase}, model, seed+int64(i)))
+	}
+	return SimulateSync(tester.downloader, mode, sims...)
+}
+
+// Tests that synchronisation over the simulated networks completes.
+func TestSimulatedSync(t *testing.T) {
+	for _, network := range simulatedNetworks {
+		for _, mode := range []SyncMode{FullSync, FastSync, LightSync} {
+			report, err := simulateSync(network.peers, network.model, mode, 1)
+			if err != nil {
+				t.Errorf("%s/%s: sync failed: %v", network.name, mode, err)
+				continue
+			}
+			if want := uint64(testChainBase.len() - 1); report.Blocks != want {
+				t.Errorf("%s/%s: synced block count mismatch: have %d, want %d", network.name, mode, report.Blocks, want)
+			}
+			t.Logf("%s network: %v", network.name, report)
+		}
+	}
+}
+
+// The sync benchmarks report the throughput over the simulated networks, run them
+// with: go test -tags syncsim -run NONE -bench SimulatedSync ./gdtu/downloader
+func BenchmarkSimulatedSyncFull(b *testing.B)  { benchmarkSimulatedSync(b, FullSync) }
+func BenchmarkSimulatedSyncFast(b *testing.B)  { benchmarkSimulatedSync(b, FastSync) }
+func BenchmarkSimulatedSyncLight(b *testing.B) { benchmarkSimulatedSync(b, LightSync) }
+
+func benchmarkSimulatedSync(b *testing.B, mode SyncMode) {
+	for _, network := range simulatedNetworks {
+		b.Run(network.name, func(b *testing.B) {
+			var (
+				blocks   uint64
+				bytes    uint64
+				duration time.Duration
+			)
+			for i := 0; i < b.N; i++ {
+				report, err := simulateSync(network.peers, network.model, mode, int64(i))
+				if err != nil {
+					b.Fatalf("sync failed: %v", err)
+				}
+				blocks, bytes, duration = blocks+report.Blocks, bytes+report.Bytes, duration+report.Duration
+			}
+			b.ReportMetric(float64(blocks)/duration.Seconds(), "blocks/s")
+			b.ReportMetric(float64(bytes)/float64(b.N), "bytes/op")
+		})
+	}
+}
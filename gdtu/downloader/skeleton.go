@@ -0,0 +1,321 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/gdtu/protocols/gdtu"
+	"github.com/c88032111/go-gdtu/gdtudb"
+	"github.com/c88032111/go-gdtu/log"
+)
+
+// skeletonPipeline is the number of skeleton header batches requested ahead
+// from peers supporting request ids.
+const skeletonPipeline = 4
+
+// skeletonStatus is the progress of a backward header fill, persisted into the
+// database so that an interrupted fill can be resumed after a restart.
+type skeletonStatus struct {
+	Head     uint64      `json:"head"`     // Number of the trusted head the fill started from
+	HeadHash common.Hash `json:"headHash"` // Hash of the trusted head the fill started from
+	Tail     uint64      `json:"tail"`     // Number of the lowest header retrieved so far
+	Next     common.Hash `json:"next"`     // Hash of the parent of the tail, next to retrieve
+}
+
+// BackfillHeaders fills the gap between a trusted head header (e.g. one derived
+// from a checkpoint oracle) and the locally stored chain (ancient or not), so a
+// node recovering from a deep rollback does not need to resync from genesis.
+//
+// Headers are retrieved backwards from the trusted head via the given peer, each
+// batch linked by parent hash to the previous one, and persisted into a separate
+// skeleton store until a header is found whose parent is known locally. The gap
+// is then verified and imported forward into the local header chain.
+func (d *Downloader) BackfillHeaders(id string, head *types.Header) error {
+	// Make sure only one goroutine is ever allowed past this point at once
+	if !atomic.CompareAndSwapInt32(&d.synchronising, 0, 1) {
+		return errBusy
+	}
+	defer atomic.StoreInt32(&d.synchronising, 0)
+
+	p := d.peers.Peer(id)
+	if p == nil {
+		return errUnknownPeer
+	}
+	// Create cancel channel for aborting mid-flight and mark the master peer
+	d.cancelLock.Lock()
+	d.cancelCh = make(chan struct{})
+	d.cancelPeer = id
+	d.cancelLock.Unlock()
+
+	defer d.Cancel() // No matter what, we can't leave the cancel channel open
+
+	status := d.loadSkeleton(head)
+	if err := d.fetchSkeleton(p, status); err != nil {
+		return err
+	}
+	return d.commitSkeleton(status)
+}
+
+// loadSkeleton retrieves the persisted backward fill progress if it belongs to
+// the same trusted head, or resets the skeleton store to start a new fill.
+func (d *Downloader) loadSkeleton(head *types.Header) *skeletonStatus {
+	if blob := rawdb.ReadSkeletonSyncStatus(d.stateDB); len(blob) > 0 {
+		status := new(skeletonStatus)
+		if err := json.Unmarshal(blob, status); err == nil {
+			if status.HeadHash == head.Hash() {
+				log.Info("Resuming header backfill", "head", status.Head, "tail", status.Tail)
+				return status
+			}
+			// Unrelated leftover fill, delete it
+			batch := d.stateDB.NewBatch()
+			for number := status.Tail; number <= status.Head; number++ {
+				rawdb.DeleteSkeletonHeader(batch, number)
+			}
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to delete stale skeleton headers", "err", err)
+			}
+		}
+	}
+	status := &skeletonStatus{
+		Head:     head.Number.Uint64(),
+		HeadHash: head.Hash(),
+		Tail:     head.Number.Uint64(),
+		Next:     head.ParentHash,
+	}
+	batch := d.stateDB.NewBatch()
+	rawdb.WriteSkeletonHeader(batch, head)
+	d.saveSkeleton(batch, status)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write skeleton head", "err", err)
+	}
+	log.Info("Starting header backfill", "head", status.Head, "hash", status.HeadHash)
+	return status
+}
+
+// saveSkeleton writes the backward fill progress into a database batch.
+func (d *Downloader) saveSkeleton(batch gdtudb.KeyValueWriter, status *skeletonStatus) {
+	blob, err := json.Marshal(status)
+	if err != nil {
+		panic(err) // Cannot happen, here to catch dev errors
+	}
+	rawdb.WriteSkeletonSyncStatus(batch, blob)
+}
+
+// fetchSkeleton retrieves headers backwards from the tail of the skeleton until
+// it links up with the local chain.
+func (d *Downloader) fetchSkeleton(p *peerConnection, status *skeletonStatus) error {
+	if p.dispatcher != nil {
+		return d.fetchSkeletonPipelined(p, status)
+	}
+	for {
+		// If the tail links to the local chain, the gap is filled
+		if d.skeletonLinked(status) {
+			return nil
+		}
+		count := MaxHeaderFetch
+		if uint64(count) > status.Tail {
+			count = int(status.Tail)
+		}
+		p.log.Trace("Fetching skeleton headers", "count", count, "from", status.Tail-1)
+		go p.requestHeadersByHash(status.Next, count, 0, true)
+
+		headers, err := d.waitSkeletonHeaders(p)
+		if err != nil {
+			return err
+		}
+		if len(headers) == 0 || len(headers) > count {
+			return fmt.Errorf("%w: returned headers %d != requested %d", errBadPeer, len(headers), count)
+		}
+		if err := d.writeSkeletonHeaders(status, headers); err != nil {
+			return err
+		}
+	}
+}
+
+// fetchSkeletonPipelined retrieves headers backwards from the tail of the
+// skeleton until it links up with the local chain, keeping multiple requests
+// in flight to a peer supporting request ids. The batches are requested by
+// number and linked by hash once they arrived, in order.
+func (d *Downloader) fetchSkeletonPipelined(p *peerConnection, status *skeletonStatus) error {
+	type batch struct {
+		from  uint64 // Number of the highest header requested
+		count int    // Number of headers requested
+	}
+	var (
+		sink    = make(chan *gdtu.Response, skeletonPipeline)
+		pending = make(map[*gdtu.Request]batch)
+		arrived = make(map[uint64][]*types.Header) // Responses not linked yet, by highest number
+		next    = status.Tail                      // Number above the next batch to request
+	)
+	defer func() {
+		for req := range pending {
+			req.Close()
+		}
+	}()
+	for {
+		// If the tail links to the local chain, the gap is filled
+		if d.skeletonLinked(status) {
+			return nil
+		}
+		// Keep the pipeline full, as long as there are headers left to request
+		for len(pending)+len(arrived) < skeletonPipeline && next > 0 {
+			count := MaxHeaderFetch
+			if uint64(count) > next {
+				count = int(next)
+			}
+			p.log.Trace("Fetching skeleton headers", "count", count, "from", next-1)
+			req, err := p.dispatcher.DispatchHeadersByNumber(next-1, count, 0, true, sink)
+			if err != nil {
+				return err
+			}
+			pending[req] = batch{from: next - 1, count: count}
+			next -= uint64(count)
+		}
+		// Wait for any of the requests to be answered
+		select {
+		case <-d.cancelCh:
+			return errCanceled
+
+		case res := <-sink:
+			req, ok := pending[res.Req]
+			if !ok {
+				continue
+			}
+			delete(pending, res.Req)
+
+			headers := *res.Res.(*gdtu.BlockHeadersPacket)
+			if len(headers) != req.count {
+				return fmt.Errorf("%w: returned headers %d != requested %d", errBadPeer, len(headers), req.count)
+			}
+			arrived[req.from] = headers
+
+		case <-time.After(d.requestTTL()):
+			p.log.Debug("Waiting for skeleton headers timed out")
+			return errTimeout
+		}
+		// Link up all the batches arrived directly below the tail
+		for status.Tail > 0 && !d.skeletonLinked(status) {
+			headers, ok := arrived[status.Tail-1]
+			if !ok {
+				break
+			}
+			delete(arrived, status.Tail-1)
+			if err := d.writeSkeletonHeaders(status, headers); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// skeletonLinked reports whgdtuer the tail of the skeleton links to the local
+// chain.
+func (d *Downloader) skeletonLinked(status *skeletonStatus) bool {
+	return status.Tail == 0 || d.lightchain.HasHeader(status.Next, status.Tail-1)
+}
+
+// writeSkeletonHeaders ensures that a batch of headers retrieved in reverse is
+// linked to the tail of the skeleton, and writes them until the skeleton links
+// to the local chain.
+func (d *Downloader) writeSkeletonHeaders(status *skeletonStatus, headers []*types.Header) error {
+	batch := d.stateDB.NewBatch()
+	for _, header := range headers {
+		if header.Hash() != status.Next || header.Number.Uint64()+1 != status.Tail {
+			return fmt.Errorf("%w: skeleton link broken at #%d [%x…]", errInvalidChain, status.Tail-1, status.Next[:4])
+		}
+		rawdb.WriteSkeletonHeader(batch, header)
+		status.Tail, status.Next = header.Number.Uint64(), header.ParentHash
+
+		if d.skeletonLinked(status) {
+			break
+		}
+	}
+	d.saveSkeleton(batch, status)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write skeleton headers", "err", err)
+	}
+	log.Debug("Backfilled skeleton headers", "head", status.Head, "tail", status.Tail)
+	return nil
+}
+
+// waitSkeletonHeaders waits for a header response from the given peer.
+func (d *Downloader) waitSkeletonHeaders(p *peerConnection) ([]*types.Header, error) {
+	timeout := time.After(d.requestTTL())
+	for {
+		select {
+		case <-d.cancelCh:
+			return nil, errCanceled
+
+		case packet := <-d.headerCh:
+			// Discard anything not from the origin peer
+			if packet.PeerId() != p.id {
+				log.Debug("Received headers from incorrect peer", "peer", packet.PeerId())
+				break
+			}
+			return packet.(*headerPack).headers, nil
+
+		case <-timeout:
+			p.log.Debug("Waiting for skeleton headers timed out")
+			return nil, errTimeout
+
+		case <-d.bodyCh:
+		case <-d.receiptCh:
+			// Out of bounds delivery, ignore
+		}
+	}
+}
+
+// commitSkeleton imports the backfilled headers forward into the local chain,
+// verifying them on the way, and deletes the skeleton once done.
+func (d *Downloader) commitSkeleton(status *skeletonStatus) error {
+	for from := status.Tail; from <= status.Head; {
+		select {
+		case <-d.cancelCh:
+			return errCanceled
+		default:
+		}
+		var headers []*types.Header
+		for number := from; number <= status.Head && len(headers) < maxHeadersProcess; number++ {
+			header := rawdb.ReadSkeletonHeader(d.stateDB, number)
+			if header == nil {
+				return fmt.Errorf("missing skeleton header #%d", number)
+			}
+			headers = append(headers, header)
+		}
+		if n, err := d.lightchain.InsertHeaderChain(headers, fsHeaderCheckFrequency); err != nil {
+			log.Warn("Invalid backfilled header", "number", headers[n].Number, "hash", headers[n].Hash(), "err", err)
+			return fmt.Errorf("%w: %v", errInvalidChain, err)
+		}
+		from += uint64(len(headers))
+	}
+	batch := d.stateDB.NewBatch()
+	for number := status.Tail; number <= status.Head; number++ {
+		rawdb.DeleteSkeletonHeader(batch, number)
+	}
+	rawdb.DeleteSkeletonSyncStatus(batch)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete skeleton headers", "err", err)
+	}
+	log.Info("Header backfill completed", "from", status.Tail, "to", status.Head)
+	return nil
+}
@@ -56,6 +56,9 @@ type PublicFilterAPI struct {
 	filtersMu sync.Mutex
 	filters   map[rpc.ID]*filter
 	timeout   time.Duration
+
+	heads   *rpc.Fanout // shared by the newHeads subscriptions
+	pending *rpc.Fanout // shared by the newPendingTransactions subscriptions
 }
 
 // NewPublicFilterAPI returns a new PublicFilterAPI instance.
@@ -67,6 +70,8 @@ func NewPublicFilterAPI(backend Backend, lightMode bool, timeout time.Duration)
 		filters: make(map[rpc.ID]*filter),
 		timeout: timeout,
 	}
+	api.heads = rpc.NewFanout(rpc.DefaultFanoutQueue, api.feedHeads)
+	api.pending = rpc.NewFanout(rpc.DefaultFanoutQueue, api.feedPendingTxs)
 	go api.timeoutLoop(timeout)
 
 	return api
@@ -143,36 +148,33 @@ func (api *PublicFilterAPI) NewPendingTransactionFilter() rpc.ID {
 // NewPendingTransactions creates a subscription that is triggered each time a transaction
 // enters the transaction pool and was signed from one of the transactions this nodes manages.
 func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
-	notifier, supported := rpc.NotifierFromContext(ctx)
-	if !supported {
-		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
-	}
-
-	rpcSub := notifier.CreateSubscription()
+	return api.pending.Subscribe(ctx)
+}
 
+// feedPendingTxs feeds the pending transaction hashes of the event system to
+// the subscriptions of NewPendingTransactions.
+func (api *PublicFilterAPI) feedPendingTxs(send func(interface{})) func() {
+	var (
+		txHashes     = make(chan []common.Hash, 128)
+		pendingTxSub = api.events.SubscribePendingTxs(txHashes)
+		quit         = make(chan struct{})
+	)
 	go func() {
-		txHashes := make(chan []common.Hash, 128)
-		pendingTxSub := api.events.SubscribePendingTxs(txHashes)
-
+		defer pendingTxSub.Unsubscribe()
 		for {
 			select {
 			case hashes := <-txHashes:
 				// To keep the original behaviour, send a single tx hash in one notification.
 				// TODO(rjl493456442) Send a batch of tx hashes in one notification
 				for _, h := range hashes {
-					notifier.Notify(rpcSub.ID, h)
+					send(h)
 				}
-			case <-rpcSub.Err():
-				pendingTxSub.Unsubscribe()
-				return
-			case <-notifier.Closed():
-				pendingTxSub.Unsubscribe()
+			case <-quit:
 				return
 			}
 		}
 	}()
-
-	return rpcSub, nil
+	return func() { close(quit) }
 }
 
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
@@ -212,32 +214,29 @@ func (api *PublicFilterAPI) NewBlockFilter() rpc.ID {
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
 func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
-	notifier, supported := rpc.NotifierFromContext(ctx)
-	if !supported {
-		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
-	}
-
-	rpcSub := notifier.CreateSubscription()
+	return api.heads.Subscribe(ctx)
+}
 
+// feedHeads feeds the chain heads of the event system to the subscriptions
+// of NewHeads.
+func (api *PublicFilterAPI) feedHeads(send func(interface{})) func() {
+	var (
+		headers    = make(chan *types.Header)
+		headersSub = api.events.SubscribeNewHeads(headers)
+		quit       = make(chan struct{})
+	)
 	go func() {
-		headers := make(chan *types.Header)
-		headersSub := api.events.SubscribeNewHeads(headers)
-
+		defer headersSub.Unsubscribe()
 		for {
 			select {
 			case h := <-headers:
-				notifier.Notify(rpcSub.ID, h)
-			case <-rpcSub.Err():
-				headersSub.Unsubscribe()
-				return
-			case <-notifier.Closed():
-				headersSub.Unsubscribe()
+				send(h)
+			case <-quit:
 				return
 			}
 		}
 	}()
-
-	return rpcSub, nil
+	return func() { close(quit) }
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
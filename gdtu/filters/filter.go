@@ -27,6 +27,7 @@ import (
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/event"
 	"github.com/c88032111/go-gdtu/gdtudb"
+	"github.com/c88032111/go-gdtu/log"
 	"github.com/c88032111/go-gdtu/rpc"
 )
 
@@ -47,6 +48,17 @@ type Backend interface {
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 }
 
+// ErrRangeFilterUnavailable is returned by range filter backends which can't
+// filter the requested range, falling back to filtering the blocks locally.
+var ErrRangeFilterUnavailable = errors.New("range filter unavailable")
+
+// RangeFilterBackend is implemented by backends which can filter the logs of a
+// block range remotely, such as light clients connected to les/5 servers, instead
+// of retrieving the receipts of every candidate block.
+type RangeFilterBackend interface {
+	FilterLogs(ctx context.Context, begin, end uint64, addresses []common.Address, topics [][]common.Hash) ([]*types.Log, error)
+}
+
 // Filter can be used to retrieve and filter logs.
 type Filter struct {
 	backend Backend
@@ -54,6 +66,7 @@ type Filter struct {
 	db        gdtudb.Database
 	addresses []common.Address
 	topics    [][]common.Hash
+	probe     *types.BloomProbe // Bloom bits of the addresses and topics
 
 	block      common.Hash // Block hash if filtering a single block
 	begin, end int64       // Range interval if filtering multiple blocks
@@ -110,6 +123,7 @@ func newFilter(backend Backend, addresses []common.Address, topics [][]common.Ha
 		backend:   backend,
 		addresses: addresses,
 		topics:    topics,
+		probe:     types.NewBloomProbe(addresses, topics),
 		db:        backend.ChainDb(),
 	}
 }
@@ -142,6 +156,16 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end == -1 {
 		end = head
 	}
+	// Let the backend filter the range if it's able to do so
+	if backend, ok := f.backend.(RangeFilterBackend); ok && uint64(f.begin) <= end {
+		logs, err := backend.FilterLogs(ctx, uint64(f.begin), end, f.addresses, f.topics)
+		if err != ErrRangeFilterUnavailable {
+			if err == nil {
+				f.begin = int64(end) + 1
+			}
+			return logs, err
+		}
+	}
 	// Gather all indexed logs, and finish with non indexed ones
 	var (
 		logs []*types.Log
@@ -174,6 +198,11 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 		return nil, err
 	}
 	defer session.Close()
+	defer func() {
+		stats := session.Stats()
+		log.Debug("Bloom filter session finished", "sections", stats.Sections, "processed", stats.Processed,
+			"retrievals", stats.Retrievals, "matches", stats.Matches, "elapsed", common.PrettyDuration(stats.Elapsed))
+	}()
 
 	f.backend.ServiceFilter(ctx, session)
 
@@ -231,7 +260,7 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, e
 
 // blockLogs returns the logs matching the filter criteria within a single block.
 func (f *Filter) blockLogs(ctx context.Context, header *types.Header) (logs []*types.Log, err error) {
-	if bloomFilter(header.Bloom, f.addresses, f.topics) {
+	if f.probe.TestHeader(header) {
 		found, err := f.checkMatches(ctx, header)
 		if err != nil {
 			return logs, err
@@ -319,30 +348,5 @@ Logs:
 }
 
 func bloomFilter(bloom types.Bloom, addresses []common.Address, topics [][]common.Hash) bool {
-	if len(addresses) > 0 {
-		var included bool
-		for _, addr := range addresses {
-			if types.BloomLookup(bloom, addr) {
-				included = true
-				break
-			}
-		}
-		if !included {
-			return false
-		}
-	}
-
-	for _, sub := range topics {
-		included := len(sub) == 0 // empty rule set == wildcard
-		for _, topic := range sub {
-			if types.BloomLookup(bloom, topic) {
-				included = true
-				break
-			}
-		}
-		if !included {
-			return false
-		}
-	}
-	return true
+	return types.NewBloomProbe(addresses, topics).Test(bloom)
 }
@@ -0,0 +1,68 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+)
+
+// FeePercentiles are the percentiles of the sampled transaction prices reported
+// in the fee suggestions.
+var FeePercentiles = []int{10, 25, 50, 75, 90}
+
+// FeeSuggestions is a bundle of fee recommendations derived from the same sample
+// of recent transactions. Blocks have no base fee, so the entire gas price of a
+// transaction is paid to the miner as tip.
+type FeeSuggestions struct {
+	Tips     []*big.Int // Tips at FeePercentiles of the sampled transactions
+	Slow     *big.Int   // Tip for inclusion when the network is not busy
+	Standard *big.Int   // Tip suggested by SuggestPrice
+	Fast     *big.Int   // Tip for inclusion in one of the next few blocks
+}
+
+// SuggestFees returns fee recommendations for various inclusion urgencies. The
+// standard tip is sampled at the configured percentile, the slow and fast ones
+// halfway below and above it, all of them raised by the congestion signals.
+func (gpo *Oracle) SuggestFees(ctx context.Context) (*FeeSuggestions, error) {
+	price, sample, err := gpo.sample(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fees := &FeeSuggestions{
+		Slow:     gpo.congestionPrice(gpo.pick(sample, gpo.percentile/2, price)),
+		Standard: gpo.congestionPrice(price),
+		Fast:     gpo.congestionPrice(gpo.pick(sample, gpo.percentile+(100-gpo.percentile)/2, price)),
+	}
+	for _, percentile := range FeePercentiles {
+		fees.Tips = append(fees.Tips, gpo.pick(sample, percentile, price))
+	}
+	return fees, nil
+}
+
+// pick returns the price at the given percentile of the sorted sample, capped
+// at the maximum price. The fallback is returned if the sample is empty.
+func (gpo *Oracle) pick(sample []*big.Int, percentile int, fallback *big.Int) *big.Int {
+	if len(sample) == 0 {
+		return fallback
+	}
+	price := sample[(len(sample)-1)*percentile/100]
+	if price.Cmp(gpo.maxPrice) > 0 {
+		price = gpo.maxPrice
+	}
+	return new(big.Int).Set(price)
+}
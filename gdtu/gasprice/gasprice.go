@@ -18,6 +18,7 @@ package gasprice
 
 import (
 	"context"
+	"math"
 	"math/big"
 	"sort"
 	"sync"
@@ -38,6 +39,12 @@ type Config struct {
 	Percentile int
 	Default    *big.Int `toml:",omitempty"`
 	MaxPrice   *big.Int `toml:",omitempty"`
+
+	// Congestion weights, in percent of the sampled price. PoolWeight is the raise
+	// with a block worth of pending transactions in the pool, TrendWeight the share
+	// of the rise of the lowest included prices over the sampled blocks passed on.
+	PoolWeight  int `toml:",omitempty"`
+	TrendWeight int `toml:",omitempty"`
 }
 
 // OracleBackend includes all necessary background APIs for oracle.
@@ -47,18 +54,29 @@ type OracleBackend interface {
 	ChainConfig() *params.ChainConfig
 }
 
+// PoolBackend is implemented by oracle backends with a transaction pool, whose
+// occupancy raises the suggestions ahead of full blocks.
+type PoolBackend interface {
+	Stats() (pending int, queued int)
+}
+
 // Oracle recommends gas prices based on the content of recent
 // blocks. Suitable for both light and full clients.
 type Oracle struct {
-	backend   OracleBackend
-	lastHead  common.Hash
-	lastPrice *big.Int
-	maxPrice  *big.Int
-	cacheLock sync.RWMutex
-	fetchLock sync.Mutex
+	backend    OracleBackend
+	lastHead   common.Hash
+	lastPrice  *big.Int
+	lastSample []*big.Int // Sorted gas prices sampled at lastHead
+	lastTrend  float64    // Relative rise of the lowest included prices at lastHead
+	lastSlots  uint64     // Number of transfers fitting in the block at lastHead
+	maxPrice   *big.Int
+	cacheLock  sync.RWMutex
+	fetchLock  sync.Mutex
 
 	checkBlocks int
 	percentile  int
+	poolWeight  int
+	trendWeight int
 }
 
 // NewOracle returns a new gasprice oracle which can recommend suitable
@@ -83,37 +101,85 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 		maxPrice = DefaultMaxPrice
 		log.Warn("Sanitizing invalid gasprice oracle price cap", "provided", params.MaxPrice, "updated", maxPrice)
 	}
+	poolWeight := params.PoolWeight
+	if poolWeight < 0 {
+		poolWeight = 0
+		log.Warn("Sanitizing invalid gasprice oracle pool weight", "provided", params.PoolWeight, "updated", poolWeight)
+	}
+	trendWeight := params.TrendWeight
+	if trendWeight < 0 {
+		trendWeight = 0
+		log.Warn("Sanitizing invalid gasprice oracle trend weight", "provided", params.TrendWeight, "updated", trendWeight)
+	}
 	return &Oracle{
 		backend:     backend,
 		lastPrice:   params.Default,
 		maxPrice:    maxPrice,
 		checkBlocks: blocks,
 		percentile:  percent,
+		poolWeight:  poolWeight,
+		trendWeight: trendWeight,
 	}
 }
 
 // SuggestPrice returns a gasprice so that newly created transaction can
 // have a very high chance to be included in the following blocks.
 func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
+	price, _, err := gpo.sample(ctx)
+	if err != nil {
+		return price, err
+	}
+	return gpo.congestionPrice(price), nil
+}
+
+// congestionPrice raises a sampled price ahead of congestion, proportionally to
+// the pending transactions in the pool relative to the block capacity and to the
+// rise of the lowest prices included in the sampled blocks. Contrary to the
+// sample, the pool occupancy is reevaluated on every call.
+func (gpo *Oracle) congestionPrice(price *big.Int) *big.Int {
+	gpo.cacheLock.RLock()
+	trend, slots := gpo.lastTrend, gpo.lastSlots
+	gpo.cacheLock.RUnlock()
+
+	factor := trend * float64(gpo.trendWeight) / 100
+	if pool, ok := gpo.backend.(PoolBackend); ok && gpo.poolWeight > 0 && slots > 0 {
+		pending, _ := pool.Stats()
+		factor += math.Min(float64(pending)/float64(slots), 1) * float64(gpo.poolWeight) / 100
+	}
+	permille := int64(factor * 1000)
+	if permille <= 0 {
+		return price
+	}
+	raised := new(big.Int).Mul(price, big.NewInt(1000+permille))
+	raised.Div(raised, big.NewInt(1000))
+	if raised.Cmp(gpo.maxPrice) > 0 {
+		raised.Set(gpo.maxPrice)
+	}
+	return raised
+}
+
+// sample returns the gasprice suggested for the current head, along with the
+// sorted list of transaction gas prices it was picked from.
+func (gpo *Oracle) sample(ctx context.Context) (*big.Int, []*big.Int, error) {
 	head, _ := gpo.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
 	headHash := head.Hash()
 
 	// If the latest gasprice is still available, return it.
 	gpo.cacheLock.RLock()
-	lastHead, lastPrice := gpo.lastHead, gpo.lastPrice
+	lastHead, lastPrice, lastSample := gpo.lastHead, gpo.lastPrice, gpo.lastSample
 	gpo.cacheLock.RUnlock()
 	if headHash == lastHead {
-		return lastPrice, nil
+		return lastPrice, lastSample, nil
 	}
 	gpo.fetchLock.Lock()
 	defer gpo.fetchLock.Unlock()
 
 	// Try checking the cache again, maybe the last fetch fetched what we need
 	gpo.cacheLock.RLock()
-	lastHead, lastPrice = gpo.lastHead, gpo.lastPrice
+	lastHead, lastPrice, lastSample = gpo.lastHead, gpo.lastPrice, gpo.lastSample
 	gpo.cacheLock.RUnlock()
 	if headHash == lastHead {
-		return lastPrice, nil
+		return lastPrice, lastSample, nil
 	}
 	var (
 		sent, exp int
@@ -121,6 +187,8 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 		result    = make(chan getBlockPricesResult, gpo.checkBlocks)
 		quit      = make(chan struct{})
 		txPrices  []*big.Int
+
+		newest, oldest *getBlockPricesResult // Sampled blocks with included transactions
 	)
 	for sent < gpo.checkBlocks && number > 0 {
 		go gpo.getBlockPrices(ctx, types.MakeSigner(gpo.backend.ChainConfig(), big.NewInt(int64(number))), number, sampleNumber, result, quit)
@@ -132,9 +200,17 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 		res := <-result
 		if res.err != nil {
 			close(quit)
-			return lastPrice, res.err
+			return lastPrice, lastSample, res.err
 		}
 		exp--
+		if len(res.prices) > 0 {
+			if newest == nil || res.number > newest.number {
+				newest = &res
+			}
+			if oldest == nil || res.number < oldest.number {
+				oldest = &res
+			}
+		}
 		// Nothing returned. There are two special cases here:
 		// - The block is empty
 		// - All the transactions included are sent by the miner itself.
@@ -161,14 +237,25 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	if price.Cmp(gpo.maxPrice) > 0 {
 		price = new(big.Int).Set(gpo.maxPrice)
 	}
+	// Track the trend of the lowest prices included in the sampled blocks
+	var trend float64
+	if newest != nil && oldest != nil && oldest.prices[0].Sign() > 0 {
+		rise := new(big.Int).Sub(newest.prices[0], oldest.prices[0])
+		trend, _ = new(big.Float).Quo(new(big.Float).SetInt(rise), new(big.Float).SetInt(oldest.prices[0])).Float64()
+		trend = math.Max(0, math.Min(trend, 1))
+	}
 	gpo.cacheLock.Lock()
 	gpo.lastHead = headHash
 	gpo.lastPrice = price
+	gpo.lastSample = txPrices
+	gpo.lastTrend = trend
+	gpo.lastSlots = head.GasLimit / params.TxGas
 	gpo.cacheLock.Unlock()
-	return price, nil
+	return price, txPrices, nil
 }
 
 type getBlockPricesResult struct {
+	number uint64
 	prices []*big.Int
 	err    error
 }
@@ -187,7 +274,7 @@ func (gpo *Oracle) getBlockPrices(ctx context.Context, signer types.Signer, bloc
 	block, err := gpo.backend.BlockByNumber(ctx, rpc.BlockNumber(blockNum))
 	if block == nil {
 		select {
-		case result <- getBlockPricesResult{nil, err}:
+		case result <- getBlockPricesResult{blockNum, nil, err}:
 		case <-quit:
 		}
 		return
@@ -208,7 +295,7 @@ func (gpo *Oracle) getBlockPrices(ctx context.Context, signer types.Signer, bloc
 		}
 	}
 	select {
-	case result <- getBlockPricesResult{prices, nil}:
+	case result <- getBlockPricesResult{blockNum, prices, nil}:
 	case <-quit:
 	}
 }
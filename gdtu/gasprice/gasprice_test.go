@@ -116,3 +116,114 @@ func TestSuggestPrice(t *testing.T) {
 		t.Fatalf("Gas price mismatch, want %d, got %d", expect, got)
 	}
 }
+
+func TestSuggestFees(t *testing.T) {
+	config := Config{
+		Blocks:     3,
+		Percentile: 60,
+		Default:    big.NewInt(params.GWei),
+	}
+	backend := newTestBackend(t)
+	oracle := NewOracle(backend, config)
+
+	// The gas price sampled is: 32G, 31G, 30G, 29G, 28G, 27G
+	fees, err := oracle.SuggestFees(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to retrieve fee suggestions: %v", err)
+	}
+	for i, want := range []int64{27, 28, 29, 30, 31} {
+		if fees.Tips[i].Cmp(big.NewInt(params.GWei*want)) != 0 {
+			t.Errorf("Tip mismatch at percentile %d, want %dG, got %d", FeePercentiles[i], want, fees.Tips[i])
+		}
+	}
+	for name, have := range map[string]struct {
+		price *big.Int
+		want  int64
+	}{
+		"slow":     {fees.Slow, 28},
+		"standard": {fees.Standard, 30},
+		"fast":     {fees.Fast, 31},
+	} {
+		if have.price.Cmp(big.NewInt(params.GWei*have.want)) != 0 {
+			t.Errorf("%s price mismatch, want %dG, got %d", name, have.want, have.price)
+		}
+	}
+	// The standard price should be the regular suggestion
+	if price, _ := oracle.SuggestPrice(context.Background()); price.Cmp(fees.Standard) != 0 {
+		t.Errorf("Standard price mismatch, want %d, got %d", price, fees.Standard)
+	}
+}
+
+// testPoolBackend is a testBackend with a transaction pool of configurable size.
+type testPoolBackend struct {
+	*testBackend
+	pending int
+}
+
+func (b *testPoolBackend) Stats() (int, int) {
+	return b.pending, 0
+}
+
+func TestSuggestPriceCongestion(t *testing.T) {
+	backend := &testPoolBackend{testBackend: newTestBackend(t)}
+	slots := int(backend.chain.CurrentHeader().GasLimit / params.TxGas)
+
+	// The gas price sampled is: 32G, 31G, 30G, 29G, 28G, 27G and the lowest
+	// included prices rose from 27G to 32G, by 18.5%
+	tests := []struct {
+		pool, trend int
+		pending     int
+		maxPrice    int64
+		want        int64 // Permille of 30G
+	}{
+		{pool: 0, trend: 0, pending: slots, want: 1000},
+		{pool: 50, trend: 0, pending: 0, want: 1000},
+		{pool: 50, trend: 0, pending: 2 * slots, want: 1500},
+		{pool: 0, trend: 100, pending: slots, want: 1185},
+		{pool: 50, trend: 100, pending: slots, want: 1685},
+		{pool: 50, trend: 100, pending: slots, maxPrice: 40, want: 1333},
+	}
+	for i, tt := range tests {
+		config := Config{
+			Blocks:      3,
+			Percentile:  60,
+			Default:     big.NewInt(params.GWei),
+			PoolWeight:  tt.pool,
+			TrendWeight: tt.trend,
+		}
+		if tt.maxPrice != 0 {
+			config.MaxPrice = big.NewInt(tt.maxPrice * params.GWei)
+		}
+		backend.pending = tt.pending
+		oracle := NewOracle(backend, config)
+
+		got, err := oracle.SuggestPrice(context.Background())
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve recommended gas price: %v", i, err)
+		}
+		want := big.NewInt(30 * params.GWei * tt.want / 1000)
+		if tt.maxPrice != 0 {
+			want = big.NewInt(tt.maxPrice * params.GWei)
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("test %d: gas price mismatch, want %d, got %d", i, want, got)
+		}
+		fees, err := oracle.SuggestFees(context.Background())
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve fee suggestions: %v", i, err)
+		}
+		if fees.Standard.Cmp(got) != 0 {
+			t.Errorf("test %d: standard fee mismatch, want %d, got %d", i, got, fees.Standard)
+		}
+	}
+	// The pool occupancy is reevaluated without a new head
+	oracle := NewOracle(backend, Config{Blocks: 3, Percentile: 60, Default: big.NewInt(params.GWei), PoolWeight: 50})
+	backend.pending = 0
+	if price, _ := oracle.SuggestPrice(context.Background()); price.Cmp(big.NewInt(30*params.GWei)) != 0 {
+		t.Fatalf("gas price mismatch with empty pool: %d", price)
+	}
+	backend.pending = slots
+	if price, _ := oracle.SuggestPrice(context.Background()); price.Cmp(big.NewInt(45*params.GWei)) != 0 {
+		t.Fatalf("gas price mismatch with full pool: %d", price)
+	}
+}
@@ -41,12 +41,16 @@ import (
 
 // FullNodeGPO contains default gasprice oracle settings for full node.
 var FullNodeGPO = gasprice.Config{
-	Blocks:     20,
-	Percentile: 60,
-	MaxPrice:   gasprice.DefaultMaxPrice,
+	Blocks:      20,
+	Percentile:  60,
+	MaxPrice:    gasprice.DefaultMaxPrice,
+	PoolWeight:  20,
+	TrendWeight: 50,
 }
 
-// LightClientGPO contains default gasprice oracle settings for light client.
+// LightClientGPO contains default gasprice oracle settings for light client. The
+// light transaction pool only holds the local transactions, so it isn't used as
+// congestion signal.
 var LightClientGPO = gasprice.Config{
 	Blocks:     2,
 	Percentile: 60,
@@ -67,7 +71,9 @@ var Defaults = Config{
 	},
 	NetworkId:               1,
 	TxLookupLimit:           2350000,
+	SideChainRetention:      90000,
 	LightPeers:              100,
+	LightOdrCache:           1024,
 	UltraLightFraction:      75,
 	DatabaseCache:           512,
 	TrieCleanCache:          154,
@@ -76,11 +82,18 @@ var Defaults = Config{
 	TrieDirtyCache:          256,
 	TrieTimeout:             60 * time.Minute,
 	SnapshotCache:           102,
+	SnapshotThrottle:        500 * time.Millisecond,
+	SnapshotMaxDelay:        time.Second,
+	SnapshotIdle:            10 * time.Second,
+	SnapshotCompaction:      2,
+	CodeCache:               32,
 	Miner: miner.Config{
 		GasFloor: 8000000,
 		GasCeil:  8000000,
 		GasPrice: big.NewInt(params.GWei),
 		Recommit: 3 * time.Second,
+
+		BuilderTimeout: time.Second,
 	},
 	TxPool:      core.DefaultTxPoolConfig,
 	RPCGasCap:   25000000,
@@ -131,18 +144,37 @@ type Config struct {
 
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 
+	SideChainRetention uint64 `toml:",omitempty"` // Number of blocks from head to keep side chain blocks for (0 = forever)
+
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
 
+	// Whitelist rules on the hashes, state roots or extra-data of block ranges
+	WhitelistRules []WhitelistRule `toml:",omitempty"`
+
+	// Duration to ban peers repeatedly delivering invalid chain data (0 = disabled, the default)
+	PeerBanDuration time.Duration `toml:",omitempty"`
+
 	// Light client options
 	LightServ          int  `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
+	LightServMax       int  `toml:",omitempty"` // Upper bound of LightServ when scaled with the resource headroom (0 = static LightServ)
 	LightIngress       int  `toml:",omitempty"` // Incoming bandwidth limit for light servers
 	LightEgress        int  `toml:",omitempty"` // Outgoing bandwidth limit for light servers
 	LightPeers         int  `toml:",omitempty"` // Maximum number of LES client peers
 	LightNoPrune       bool `toml:",omitempty"` // Whgdtuer to disable light chain pruning
 	LightNoSyncServe   bool `toml:",omitempty"` // Whgdtuer to serve light clients before syncing
+	LightOdrCache      int  `toml:",omitempty"` // Number of on-demand retrieval results cached by light clients (0 = disabled)
+	LightServerFilter  bool `toml:",omitempty"` // Whgdtuer light clients let les/5 servers filter log ranges (results can't be proven complete)
+	LightWebsocket     bool `toml:",omitempty"` // Whgdtuer to accept light clients tunneled over WebSocket on the HTTP endpoint
 	SyncFromCheckpoint bool `toml:",omitempty"` // Whgdtuer to sync the header chain from the configured checkpoint
 
+	// Light client WebSocket tunnel options
+	LightWebsocketServers []string `toml:",omitempty"` // List of ws(s)://<node id>@host[:port]/path server URLs
+
+	// Light client server diversity options
+	LightServersPerNetwork int    `toml:",omitempty"` // Maximum number of light servers to attach to within a single network (0 = unlimited)
+	LightNetworkMap        string `toml:",omitempty"` // File mapping IP prefixes to networks (empty = group servers by subnet)
+
 	// Ultra Light client options
 	UltraLightServers      []string `toml:",omitempty"` // List of trusted ultra light servers
 	UltraLightFraction     int      `toml:",omitempty"` // Percentage of trusted servers to accept an announcement
@@ -154,12 +186,20 @@ type Config struct {
 	DatabaseCache      int
 	DatabaseFreezer    string
 
+	DatabaseFreezerThreshold uint64 `toml:",omitempty"` // Number of recent blocks not to freeze (0 = immutability threshold)
+	DatabaseFreezerAutoTune  bool   `toml:",omitempty"` // Whgdtuer to tune the freezer threshold to the disk speed
+
 	TrieCleanCache          int
 	TrieCleanCacheJournal   string        `toml:",omitempty"` // Disk journal directory for trie cache to survive node restarts
 	TrieCleanCacheRejournal time.Duration `toml:",omitempty"` // Time interval to regenerate the journal for clean cache
 	TrieDirtyCache          int
 	TrieTimeout             time.Duration
 	SnapshotCache           int
+	SnapshotThrottle        time.Duration `toml:",omitempty"` // Block import time above which snapshot generation slows down
+	SnapshotMaxDelay        time.Duration `toml:",omitempty"` // Maximum pause between snapshot generation batches
+	SnapshotIdle            time.Duration `toml:",omitempty"` // Time without block imports after which snapshot diffs are persisted
+	SnapshotCompaction      int           `toml:",omitempty"` // Aggressiveness of persisting snapshot diffs
+	CodeCache               int           `toml:",omitempty"` // Memory allowance (MB) for the contract code cache shared between EVMs
 	Preimages               bool
 
 	// Mining options
@@ -186,6 +226,12 @@ type Config struct {
 	// Type of the EVM interpreter ("" for default)
 	EVMInterpreter string
 
+	// Number of executed opcodes per sample of the opcode profiler (0 = disabled)
+	OpcodeSampleInterval uint64 `toml:",omitempty"`
+
+	// Speculatively execute the transactions of imported blocks in parallel (experimental)
+	ParallelExecution bool `toml:",omitempty"`
+
 	// RPCGasCap is the global gas cap for gdtu-call variants.
 	RPCGasCap uint64 `toml:",omitempty"`
 
@@ -201,6 +247,10 @@ type Config struct {
 
 	// Berlin block override (TODO: remove after the fork)
 	OverrideBerlin *big.Int `toml:",omitempty"`
+
+	// GovernanceFile is the path of a chain configuration file which is watched
+	// and reloaded at runtime to schedule future forks.
+	GovernanceFile string `toml:",omitempty"`
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain configuration.
@@ -18,49 +18,69 @@ import (
 // MarshalTOML marshals as TOML.
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               uint64
-		SyncMode                downloader.SyncMode
-		GdtuDiscoveryURLs       []string
-		SnapDiscoveryURLs       []string
-		NoPruning               bool
-		NoPrefetch              bool
-		TxLookupLimit           uint64                 `toml:",omitempty"`
-		Whitelist               map[uint64]common.Hash `toml:"-"`
-		LightServ               int                    `toml:",omitempty"`
-		LightIngress            int                    `toml:",omitempty"`
-		LightEgress             int                    `toml:",omitempty"`
-		LightPeers              int                    `toml:",omitempty"`
-		LightNoPrune            bool                   `toml:",omitempty"`
-		LightNoSyncServe        bool                   `toml:",omitempty"`
-		SyncFromCheckpoint      bool                   `toml:",omitempty"`
-		UltraLightServers       []string               `toml:",omitempty"`
-		UltraLightFraction      int                    `toml:",omitempty"`
-		UltraLightOnlyAnnounce  bool                   `toml:",omitempty"`
-		SkipBcVersionCheck      bool                   `toml:"-"`
-		DatabaseHandles         int                    `toml:"-"`
-		DatabaseCache           int
-		DatabaseFreezer         string
-		TrieCleanCache          int
-		TrieCleanCacheJournal   string        `toml:",omitempty"`
-		TrieCleanCacheRejournal time.Duration `toml:",omitempty"`
-		TrieDirtyCache          int
-		TrieTimeout             time.Duration
-		SnapshotCache           int
-		Preimages               bool
-		Miner                   miner.Config
-		Gdtuash                 gdtuash.Config
-		TxPool                  core.TxPoolConfig
-		GPO                     gasprice.Config
-		EnablePreimageRecording bool
-		DocRoot                 string `toml:"-"`
-		EWASMInterpreter        string
-		EVMInterpreter          string
-		RPCGasCap               uint64                         `toml:",omitempty"`
-		RPCTxFeeCap             float64                        `toml:",omitempty"`
-		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
-		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
-		OverrideBerlin          *big.Int                       `toml:",omitempty"`
+		Genesis                  *core.Genesis `toml:",omitempty"`
+		NetworkId                uint64
+		SyncMode                 downloader.SyncMode
+		GdtuDiscoveryURLs        []string
+		SnapDiscoveryURLs        []string
+		NoPruning                bool
+		NoPrefetch               bool
+		TxLookupLimit            uint64                 `toml:",omitempty"`
+		SideChainRetention       uint64                 `toml:",omitempty"`
+		Whitelist                map[uint64]common.Hash `toml:"-"`
+		WhitelistRules           []WhitelistRule        `toml:",omitempty"`
+		PeerBanDuration          time.Duration          `toml:",omitempty"`
+		LightServ                int                    `toml:",omitempty"`
+		LightServMax             int                    `toml:",omitempty"`
+		LightIngress             int                    `toml:",omitempty"`
+		LightEgress              int                    `toml:",omitempty"`
+		LightPeers               int                    `toml:",omitempty"`
+		LightNoPrune             bool                   `toml:",omitempty"`
+		LightNoSyncServe         bool                   `toml:",omitempty"`
+		LightServerFilter        bool                   `toml:",omitempty"`
+		LightOdrCache            int                    `toml:",omitempty"`
+		LightWebsocket           bool                   `toml:",omitempty"`
+		SyncFromCheckpoint       bool                   `toml:",omitempty"`
+		LightWebsocketServers    []string               `toml:",omitempty"`
+		LightServersPerNetwork   int                    `toml:",omitempty"`
+		LightNetworkMap          string                 `toml:",omitempty"`
+		UltraLightServers        []string               `toml:",omitempty"`
+		UltraLightFraction       int                    `toml:",omitempty"`
+		UltraLightOnlyAnnounce   bool                   `toml:",omitempty"`
+		SkipBcVersionCheck       bool                   `toml:"-"`
+		DatabaseHandles          int                    `toml:"-"`
+		DatabaseCache            int
+		DatabaseFreezer          string
+		DatabaseFreezerThreshold uint64 `toml:",omitempty"`
+		DatabaseFreezerAutoTune  bool   `toml:",omitempty"`
+		TrieCleanCache           int
+		TrieCleanCacheJournal    string        `toml:",omitempty"`
+		TrieCleanCacheRejournal  time.Duration `toml:",omitempty"`
+		TrieDirtyCache           int
+		TrieTimeout              time.Duration
+		SnapshotCache            int
+		SnapshotThrottle         time.Duration `toml:",omitempty"`
+		SnapshotMaxDelay         time.Duration `toml:",omitempty"`
+		SnapshotIdle             time.Duration `toml:",omitempty"`
+		SnapshotCompaction       int           `toml:",omitempty"`
+		CodeCache                int           `toml:",omitempty"`
+		Preimages                bool
+		Miner                    miner.Config
+		Gdtuash                  gdtuash.Config
+		TxPool                   core.TxPoolConfig
+		GPO                      gasprice.Config
+		EnablePreimageRecording  bool
+		DocRoot                  string `toml:"-"`
+		EWASMInterpreter         string
+		EVMInterpreter           string
+		OpcodeSampleInterval     uint64                         `toml:",omitempty"`
+		ParallelExecution        bool                           `toml:",omitempty"`
+		RPCGasCap                uint64                         `toml:",omitempty"`
+		RPCTxFeeCap              float64                        `toml:",omitempty"`
+		Checkpoint               *params.TrustedCheckpoint      `toml:",omitempty"`
+		CheckpointOracle         *params.CheckpointOracleConfig `toml:",omitempty"`
+		OverrideBerlin           *big.Int                       `toml:",omitempty"`
+		GovernanceFile           string                         `toml:",omitempty"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -71,14 +91,24 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
 	enc.TxLookupLimit = c.TxLookupLimit
+	enc.SideChainRetention = c.SideChainRetention
 	enc.Whitelist = c.Whitelist
+	enc.WhitelistRules = c.WhitelistRules
+	enc.PeerBanDuration = c.PeerBanDuration
 	enc.LightServ = c.LightServ
+	enc.LightServMax = c.LightServMax
 	enc.LightIngress = c.LightIngress
 	enc.LightEgress = c.LightEgress
 	enc.LightPeers = c.LightPeers
 	enc.LightNoPrune = c.LightNoPrune
 	enc.LightNoSyncServe = c.LightNoSyncServe
+	enc.LightServerFilter = c.LightServerFilter
+	enc.LightOdrCache = c.LightOdrCache
+	enc.LightWebsocket = c.LightWebsocket
 	enc.SyncFromCheckpoint = c.SyncFromCheckpoint
+	enc.LightWebsocketServers = c.LightWebsocketServers
+	enc.LightServersPerNetwork = c.LightServersPerNetwork
+	enc.LightNetworkMap = c.LightNetworkMap
 	enc.UltraLightServers = c.UltraLightServers
 	enc.UltraLightFraction = c.UltraLightFraction
 	enc.UltraLightOnlyAnnounce = c.UltraLightOnlyAnnounce
@@ -86,12 +116,19 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
 	enc.DatabaseFreezer = c.DatabaseFreezer
+	enc.DatabaseFreezerThreshold = c.DatabaseFreezerThreshold
+	enc.DatabaseFreezerAutoTune = c.DatabaseFreezerAutoTune
 	enc.TrieCleanCache = c.TrieCleanCache
 	enc.TrieCleanCacheJournal = c.TrieCleanCacheJournal
 	enc.TrieCleanCacheRejournal = c.TrieCleanCacheRejournal
 	enc.TrieDirtyCache = c.TrieDirtyCache
 	enc.TrieTimeout = c.TrieTimeout
 	enc.SnapshotCache = c.SnapshotCache
+	enc.SnapshotThrottle = c.SnapshotThrottle
+	enc.SnapshotMaxDelay = c.SnapshotMaxDelay
+	enc.SnapshotIdle = c.SnapshotIdle
+	enc.SnapshotCompaction = c.SnapshotCompaction
+	enc.CodeCache = c.CodeCache
 	enc.Preimages = c.Preimages
 	enc.Miner = c.Miner
 	enc.Gdtuash = c.Gdtuash
@@ -101,60 +138,83 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.DocRoot = c.DocRoot
 	enc.EWASMInterpreter = c.EWASMInterpreter
 	enc.EVMInterpreter = c.EVMInterpreter
+	enc.OpcodeSampleInterval = c.OpcodeSampleInterval
+	enc.ParallelExecution = c.ParallelExecution
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
 	enc.OverrideBerlin = c.OverrideBerlin
+	enc.GovernanceFile = c.GovernanceFile
 	return &enc, nil
 }
 
 // UnmarshalTOML unmarshals from TOML.
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               *uint64
-		SyncMode                *downloader.SyncMode
-		GdtuDiscoveryURLs       []string
-		SnapDiscoveryURLs       []string
-		NoPruning               *bool
-		NoPrefetch              *bool
-		TxLookupLimit           *uint64                `toml:",omitempty"`
-		Whitelist               map[uint64]common.Hash `toml:"-"`
-		LightServ               *int                   `toml:",omitempty"`
-		LightIngress            *int                   `toml:",omitempty"`
-		LightEgress             *int                   `toml:",omitempty"`
-		LightPeers              *int                   `toml:",omitempty"`
-		LightNoPrune            *bool                  `toml:",omitempty"`
-		LightNoSyncServe        *bool                  `toml:",omitempty"`
-		SyncFromCheckpoint      *bool                  `toml:",omitempty"`
-		UltraLightServers       []string               `toml:",omitempty"`
-		UltraLightFraction      *int                   `toml:",omitempty"`
-		UltraLightOnlyAnnounce  *bool                  `toml:",omitempty"`
-		SkipBcVersionCheck      *bool                  `toml:"-"`
-		DatabaseHandles         *int                   `toml:"-"`
-		DatabaseCache           *int
-		DatabaseFreezer         *string
-		TrieCleanCache          *int
-		TrieCleanCacheJournal   *string        `toml:",omitempty"`
-		TrieCleanCacheRejournal *time.Duration `toml:",omitempty"`
-		TrieDirtyCache          *int
-		TrieTimeout             *time.Duration
-		SnapshotCache           *int
-		Preimages               *bool
-		Miner                   *miner.Config
-		Gdtuash                 *gdtuash.Config
-		TxPool                  *core.TxPoolConfig
-		GPO                     *gasprice.Config
-		EnablePreimageRecording *bool
-		DocRoot                 *string `toml:"-"`
-		EWASMInterpreter        *string
-		EVMInterpreter          *string
-		RPCGasCap               *uint64                        `toml:",omitempty"`
-		RPCTxFeeCap             *float64                       `toml:",omitempty"`
-		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
-		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
-		OverrideBerlin          *big.Int                       `toml:",omitempty"`
+		Genesis                  *core.Genesis `toml:",omitempty"`
+		NetworkId                *uint64
+		SyncMode                 *downloader.SyncMode
+		GdtuDiscoveryURLs        []string
+		SnapDiscoveryURLs        []string
+		NoPruning                *bool
+		NoPrefetch               *bool
+		TxLookupLimit            *uint64                `toml:",omitempty"`
+		SideChainRetention       *uint64                `toml:",omitempty"`
+		Whitelist                map[uint64]common.Hash `toml:"-"`
+		WhitelistRules           []WhitelistRule        `toml:",omitempty"`
+		PeerBanDuration          *time.Duration         `toml:",omitempty"`
+		LightServ                *int                   `toml:",omitempty"`
+		LightServMax             *int                   `toml:",omitempty"`
+		LightIngress             *int                   `toml:",omitempty"`
+		LightEgress              *int                   `toml:",omitempty"`
+		LightPeers               *int                   `toml:",omitempty"`
+		LightNoPrune             *bool                  `toml:",omitempty"`
+		LightNoSyncServe         *bool                  `toml:",omitempty"`
+		LightServerFilter        *bool                  `toml:",omitempty"`
+		LightOdrCache            *int                   `toml:",omitempty"`
+		LightWebsocket           *bool                  `toml:",omitempty"`
+		SyncFromCheckpoint       *bool                  `toml:",omitempty"`
+		LightWebsocketServers    []string               `toml:",omitempty"`
+		LightServersPerNetwork   *int                   `toml:",omitempty"`
+		LightNetworkMap          *string                `toml:",omitempty"`
+		UltraLightServers        []string               `toml:",omitempty"`
+		UltraLightFraction       *int                   `toml:",omitempty"`
+		UltraLightOnlyAnnounce   *bool                  `toml:",omitempty"`
+		SkipBcVersionCheck       *bool                  `toml:"-"`
+		DatabaseHandles          *int                   `toml:"-"`
+		DatabaseCache            *int
+		DatabaseFreezer          *string
+		DatabaseFreezerThreshold *uint64 `toml:",omitempty"`
+		DatabaseFreezerAutoTune  *bool   `toml:",omitempty"`
+		TrieCleanCache           *int
+		TrieCleanCacheJournal    *string        `toml:",omitempty"`
+		TrieCleanCacheRejournal  *time.Duration `toml:",omitempty"`
+		TrieDirtyCache           *int
+		TrieTimeout              *time.Duration
+		SnapshotCache            *int
+		SnapshotThrottle         *time.Duration `toml:",omitempty"`
+		SnapshotMaxDelay         *time.Duration `toml:",omitempty"`
+		SnapshotIdle             *time.Duration `toml:",omitempty"`
+		SnapshotCompaction       *int           `toml:",omitempty"`
+		CodeCache                *int           `toml:",omitempty"`
+		Preimages                *bool
+		Miner                    *miner.Config
+		Gdtuash                  *gdtuash.Config
+		TxPool                   *core.TxPoolConfig
+		GPO                      *gasprice.Config
+		EnablePreimageRecording  *bool
+		DocRoot                  *string `toml:"-"`
+		EWASMInterpreter         *string
+		EVMInterpreter           *string
+		OpcodeSampleInterval     *uint64                        `toml:",omitempty"`
+		ParallelExecution        *bool                          `toml:",omitempty"`
+		RPCGasCap                *uint64                        `toml:",omitempty"`
+		RPCTxFeeCap              *float64                       `toml:",omitempty"`
+		Checkpoint               *params.TrustedCheckpoint      `toml:",omitempty"`
+		CheckpointOracle         *params.CheckpointOracleConfig `toml:",omitempty"`
+		OverrideBerlin           *big.Int                       `toml:",omitempty"`
+		GovernanceFile           *string                        `toml:",omitempty"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -184,12 +244,24 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.TxLookupLimit != nil {
 		c.TxLookupLimit = *dec.TxLookupLimit
 	}
+	if dec.SideChainRetention != nil {
+		c.SideChainRetention = *dec.SideChainRetention
+	}
 	if dec.Whitelist != nil {
 		c.Whitelist = dec.Whitelist
 	}
+	if dec.WhitelistRules != nil {
+		c.WhitelistRules = dec.WhitelistRules
+	}
+	if dec.PeerBanDuration != nil {
+		c.PeerBanDuration = *dec.PeerBanDuration
+	}
 	if dec.LightServ != nil {
 		c.LightServ = *dec.LightServ
 	}
+	if dec.LightServMax != nil {
+		c.LightServMax = *dec.LightServMax
+	}
 	if dec.LightIngress != nil {
 		c.LightIngress = *dec.LightIngress
 	}
@@ -205,9 +277,27 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.LightNoSyncServe != nil {
 		c.LightNoSyncServe = *dec.LightNoSyncServe
 	}
+	if dec.LightServerFilter != nil {
+		c.LightServerFilter = *dec.LightServerFilter
+	}
+	if dec.LightOdrCache != nil {
+		c.LightOdrCache = *dec.LightOdrCache
+	}
+	if dec.LightWebsocket != nil {
+		c.LightWebsocket = *dec.LightWebsocket
+	}
 	if dec.SyncFromCheckpoint != nil {
 		c.SyncFromCheckpoint = *dec.SyncFromCheckpoint
 	}
+	if dec.LightWebsocketServers != nil {
+		c.LightWebsocketServers = dec.LightWebsocketServers
+	}
+	if dec.LightServersPerNetwork != nil {
+		c.LightServersPerNetwork = *dec.LightServersPerNetwork
+	}
+	if dec.LightNetworkMap != nil {
+		c.LightNetworkMap = *dec.LightNetworkMap
+	}
 	if dec.UltraLightServers != nil {
 		c.UltraLightServers = dec.UltraLightServers
 	}
@@ -229,6 +319,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseFreezer != nil {
 		c.DatabaseFreezer = *dec.DatabaseFreezer
 	}
+	if dec.DatabaseFreezerThreshold != nil {
+		c.DatabaseFreezerThreshold = *dec.DatabaseFreezerThreshold
+	}
+	if dec.DatabaseFreezerAutoTune != nil {
+		c.DatabaseFreezerAutoTune = *dec.DatabaseFreezerAutoTune
+	}
 	if dec.TrieCleanCache != nil {
 		c.TrieCleanCache = *dec.TrieCleanCache
 	}
@@ -247,6 +343,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SnapshotCache != nil {
 		c.SnapshotCache = *dec.SnapshotCache
 	}
+	if dec.SnapshotThrottle != nil {
+		c.SnapshotThrottle = *dec.SnapshotThrottle
+	}
+	if dec.SnapshotMaxDelay != nil {
+		c.SnapshotMaxDelay = *dec.SnapshotMaxDelay
+	}
+	if dec.SnapshotIdle != nil {
+		c.SnapshotIdle = *dec.SnapshotIdle
+	}
+	if dec.SnapshotCompaction != nil {
+		c.SnapshotCompaction = *dec.SnapshotCompaction
+	}
+	if dec.CodeCache != nil {
+		c.CodeCache = *dec.CodeCache
+	}
 	if dec.Preimages != nil {
 		c.Preimages = *dec.Preimages
 	}
@@ -274,6 +385,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EVMInterpreter != nil {
 		c.EVMInterpreter = *dec.EVMInterpreter
 	}
+	if dec.OpcodeSampleInterval != nil {
+		c.OpcodeSampleInterval = *dec.OpcodeSampleInterval
+	}
+	if dec.ParallelExecution != nil {
+		c.ParallelExecution = *dec.ParallelExecution
+	}
 	if dec.RPCGasCap != nil {
 		c.RPCGasCap = *dec.RPCGasCap
 	}
@@ -289,5 +406,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.OverrideBerlin != nil {
 		c.OverrideBerlin = dec.OverrideBerlin
 	}
+	if dec.GovernanceFile != nil {
+		c.GovernanceFile = *dec.GovernanceFile
+	}
 	return nil
 }
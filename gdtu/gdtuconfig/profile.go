@@ -0,0 +1,132 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtuconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/c88032111/go-gdtu/gdtu/downloader"
+)
+
+// minTrieDirtyCache is the smallest trie dirty cache (in MB) accepted for nodes
+// keeping the full state history.
+const minTrieDirtyCache = 16
+
+// Profile is a named configuration preset, setting coherent values for the
+// options depending on the role of the node. Profiles are applied on top of the
+// defaults, any explicitly configured option takes precedence.
+type Profile struct {
+	Name        string
+	Description string
+	apply       func(cfg *Config)
+}
+
+// Profiles contains the available configuration presets.
+var Profiles = []Profile{
+	{
+		Name:        "full",
+		Description: "Full node keeping the recent state and transaction index",
+		apply: func(cfg *Config) {
+			cfg.SyncMode = downloader.FastSync
+			cfg.NoPruning = false
+			cfg.TxLookupLimit = Defaults.TxLookupLimit
+			cfg.SnapshotCache = Defaults.SnapshotCache
+		},
+	},
+	{
+		Name:        "archive",
+		Description: "Archive node keeping all historical state and the full transaction index",
+		apply: func(cfg *Config) {
+			cfg.SyncMode = downloader.FullSync
+			cfg.NoPruning = true
+			cfg.Preimages = true
+			cfg.TxLookupLimit = 0
+			cfg.SnapshotCache = 2 * Defaults.SnapshotCache
+		},
+	},
+	{
+		Name:        "light-server",
+		Description: "Full node serving light clients, keeping the full transaction index",
+		apply: func(cfg *Config) {
+			cfg.SyncMode = downloader.FastSync
+			cfg.NoPruning = false
+			cfg.TxLookupLimit = 0
+			cfg.LightServ = 50
+			cfg.LightPeers = Defaults.LightPeers
+		},
+	},
+	{
+		Name:        "validator",
+		Description: "Block producing node, keeping only the data needed to build blocks",
+		apply: func(cfg *Config) {
+			cfg.SyncMode = downloader.FullSync
+			cfg.NoPruning = false
+			cfg.TxLookupLimit = 1024
+			cfg.LightServ = 0
+			cfg.TrieDirtyCache = 2 * Defaults.TrieDirtyCache
+		},
+	},
+}
+
+// ProfileNames returns the names of the available configuration presets.
+func ProfileNames() []string {
+	names := make([]string, len(Profiles))
+	for i, profile := range Profiles {
+		names[i] = profile.Name
+	}
+	return names
+}
+
+// ApplyProfile sets the options of the named configuration preset.
+func ApplyProfile(cfg *Config, name string) error {
+	for _, profile := range Profiles {
+		if profile.Name == name {
+			profile.apply(cfg)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown profile %q, available: %s", name, strings.Join(ProfileNames(), ", "))
+}
+
+// Validate checks the configuration for contradictory option combinations,
+// returning an error describing all of them and how to resolve them.
+func (c *Config) Validate() error {
+	var problems []string
+	if c.NoPruning && c.TrieDirtyCache < minTrieDirtyCache {
+		problems = append(problems, fmt.Sprintf("archive mode needs a trie dirty cache of at least %d MB, have %d MB (raise --cache or --cache.gc)", minTrieDirtyCache, c.TrieDirtyCache))
+	}
+	if c.SyncMode == downloader.SnapSync && c.SnapshotCache == 0 {
+		problems = append(problems, "snap sync requires the snapshot, which is disabled (remove --snapshot=false or use --syncmode=fast)")
+	}
+	if c.LightServ < 0 {
+		problems = append(problems, fmt.Sprintf("light serving percentage can't be negative, have %d (set --light.serve to 0 or above)", c.LightServ))
+	}
+	if c.LightServ > 0 {
+		if c.SyncMode == downloader.LightSync {
+			problems = append(problems, "light clients can't serve other light clients (use --syncmode=fast or --syncmode=full)")
+		}
+		if c.LightPeers == 0 {
+			problems = append(problems, "light serving is enabled but no light client may connect (raise --light.maxpeers)")
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+}
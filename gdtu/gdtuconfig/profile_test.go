@@ -0,0 +1,101 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtuconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/gdtu/downloader"
+)
+
+// Tests that all the profiles result in a valid configuration.
+func TestProfilesValid(t *testing.T) {
+	if err := Defaults.Validate(); err != nil {
+		t.Fatalf("default configuration invalid: %v", err)
+	}
+	for _, name := range ProfileNames() {
+		cfg := Defaults
+		if err := ApplyProfile(&cfg, name); err != nil {
+			t.Fatalf("profile %s: failed to apply: %v", name, err)
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("profile %s: invalid configuration: %v", name, err)
+		}
+	}
+	cfg := Defaults
+	if err := ApplyProfile(&cfg, "unknown"); err == nil {
+		t.Errorf("unknown profile applied")
+	}
+}
+
+// Tests that contradictory option combinations are rejected.
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		errs   []string
+	}{
+		{
+			name: "archive without dirty cache",
+			modify: func(cfg *Config) {
+				cfg.NoPruning = true
+				cfg.TrieDirtyCache = 0
+			},
+			errs: []string{"--cache.gc"},
+		},
+		{
+			name: "snap sync without snapshot",
+			modify: func(cfg *Config) {
+				cfg.SyncMode = downloader.SnapSync
+				cfg.SnapshotCache = 0
+			},
+			errs: []string{"--snapshot"},
+		},
+		{
+			name: "light client serving",
+			modify: func(cfg *Config) {
+				cfg.SyncMode = downloader.LightSync
+				cfg.LightServ = 50
+				cfg.LightPeers = 0
+			},
+			errs: []string{"--syncmode=fast", "--light.maxpeers"},
+		},
+		{
+			name: "negative light serving",
+			modify: func(cfg *Config) {
+				cfg.LightServ = -1
+			},
+			errs: []string{"--light.serve"},
+		},
+	}
+	for _, tt := range tests {
+		cfg := Defaults
+		tt.modify(&cfg)
+
+		err := cfg.Validate()
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+			continue
+		}
+		for _, want := range tt.errs {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: error %q doesn't mention %q", tt.name, err, want)
+			}
+		}
+	}
+}
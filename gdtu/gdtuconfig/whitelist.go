@@ -0,0 +1,97 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtuconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
+)
+
+// WhitelistRule is a requirement on the headers of a range of blocks. Peers
+// serving headers violating any rule are considered to be on another fork and
+// get dropped. Unset fields are not checked.
+type WhitelistRule struct {
+	First       uint64        // First block number the rule applies to
+	Last        uint64        // Last block number the rule applies to (inclusive)
+	Hash        common.Hash   `toml:",omitempty"` // Required block hash, only for single block rules
+	Root        common.Hash   `toml:",omitempty"` // Required state root, only for single block rules
+	ExtraPrefix hexutil.Bytes `toml:",omitempty"` // Required prefix of the extra-data
+}
+
+// Validate checks that the rule covers a valid block range and requires
+// somgdtuing.
+func (r *WhitelistRule) Validate() error {
+	if r.Last < r.First {
+		return fmt.Errorf("invalid block range %d-%d", r.First, r.Last)
+	}
+	if r.Hash != (common.Hash{}) && r.First != r.Last {
+		return fmt.Errorf("block hash required for range %d-%d", r.First, r.Last)
+	}
+	if r.Root != (common.Hash{}) && r.First != r.Last {
+		return fmt.Errorf("state root required for range %d-%d", r.First, r.Last)
+	}
+	if r.Hash == (common.Hash{}) && r.Root == (common.Hash{}) && len(r.ExtraPrefix) == 0 {
+		return fmt.Errorf("no requirement for blocks %d-%d", r.First, r.Last)
+	}
+	return nil
+}
+
+// ParseWhitelistRule parses a whitelist rule of the form <range>=[<kind>:]<value>,
+// where the range is a block number or an inclusive range of block numbers
+// separated by a dash, and the kind is one of hash (the default), root or extra.
+func ParseWhitelistRule(entry string) (WhitelistRule, error) {
+	var rule WhitelistRule
+
+	parts := strings.Split(entry, "=")
+	if len(parts) != 2 {
+		return rule, errors.New("invalid entry, want <range>=[<kind>:]<value>")
+	}
+	bounds := strings.SplitN(parts[0], "-", 2)
+	first, err := strconv.ParseUint(bounds[0], 0, 64)
+	if err != nil {
+		return rule, fmt.Errorf("invalid block number %s: %v", bounds[0], err)
+	}
+	rule.First, rule.Last = first, first
+	if len(bounds) == 2 {
+		if rule.Last, err = strconv.ParseUint(bounds[1], 0, 64); err != nil {
+			return rule, fmt.Errorf("invalid block number %s: %v", bounds[1], err)
+		}
+	}
+	kind, value := "hash", parts[1]
+	if i := strings.Index(value, ":"); i >= 0 {
+		kind, value = value[:i], value[i+1:]
+	}
+	switch kind {
+	case "hash":
+		err = rule.Hash.UnmarshalText([]byte(value))
+	case "root":
+		err = rule.Root.UnmarshalText([]byte(value))
+	case "extra":
+		err = rule.ExtraPrefix.UnmarshalText([]byte(value))
+	default:
+		return rule, fmt.Errorf("unknown requirement %q, want hash, root or extra", kind)
+	}
+	if err != nil {
+		return rule, fmt.Errorf("invalid %s %s: %v", kind, value, err)
+	}
+	return rule, rule.Validate()
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtuconfig
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+)
+
+func TestParseWhitelistRule(t *testing.T) {
+	hash := common.Hash{0xde, 0xad}
+	tests := []struct {
+		entry string
+		want  WhitelistRule
+		fail  bool
+	}{
+		{entry: "100=" + hash.Hex(), want: WhitelistRule{First: 100, Last: 100, Hash: hash}},
+		{entry: "100=hash:" + hash.Hex(), want: WhitelistRule{First: 100, Last: 100, Hash: hash}},
+		{entry: "100=root:" + hash.Hex(), want: WhitelistRule{First: 100, Last: 100, Root: hash}},
+		{entry: "100-109=extra:gd64616f", want: WhitelistRule{First: 100, Last: 109, ExtraPrefix: []byte("dao")}},
+		{entry: "100-200=" + hash.Hex(), fail: true},      // hash of a range
+		{entry: "100-200=root:" + hash.Hex(), fail: true}, // root of a range
+		{entry: "200-100=root:" + hash.Hex(), fail: true}, // inverted range
+		{entry: "100=extra:gd", fail: true},               // empty requirement
+		{entry: "100=nonce:gd01", fail: true},             // unknown kind
+		{entry: "100", fail: true},
+		{entry: "abc=" + hash.Hex(), fail: true},
+		{entry: "100=root:gd01", fail: true},
+	}
+	for i, tt := range tests {
+		rule, err := ParseWhitelistRule(tt.entry)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected failure for %q", i, tt.entry)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: failed to parse %q: %v", i, tt.entry, err)
+			continue
+		}
+		if !reflect.DeepEqual(rule, tt.want) {
+			t.Errorf("test %d: rule mismatch: have %+v, want %+v", i, rule, tt.want)
+		}
+	}
+}
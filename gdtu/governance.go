@@ -0,0 +1,135 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+package gdtu
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// governanceReloadInterval is the interval at which the governance file is checked
+// for modifications.
+const governanceReloadInterval = 10 * time.Second
+
+// governance watches a chain configuration file on disk and applies any changes
+// made to its fork schedule to the running chain. This allows the operators of a
+// private network to coordinate upgrades by distributing a new configuration ahead
+// of the fork block, instead of restarting all nodes at the fork boundary.
+type governance struct {
+	path    string
+	chain   *core.BlockChain
+	modTime time.Time // Modification time of the last loaded configuration
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newGovernance creates a reloader applying the chain configuration in the file
+// at path to the given chain.
+func newGovernance(path string, chain *core.BlockChain) *governance {
+	return &governance{
+		path:  path,
+		chain: chain,
+		quit:  make(chan struct{}),
+	}
+}
+
+// reload applies the configuration in the governance file to the chain if the
+// file was modified since it was last loaded.
+func (g *governance) reload() error {
+	info, err := os.Stat(g.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(g.modTime) {
+		return nil
+	}
+	config, err := loadGovernanceFile(g.path)
+	if err != nil {
+		return err
+	}
+	// Only remember the file as loaded if it was accepted, so that a rejected
+	// configuration is reported again until fixed.
+	if err := g.chain.UpdateChainConfig(config); err != nil {
+		return err
+	}
+	g.modTime = info.ModTime()
+	return nil
+}
+
+// start launches the background loop reloading the governance file whenever
+// it changes.
+func (g *governance) start() {
+	g.wg.Add(1)
+	go g.loop()
+}
+
+// stop terminates the reload loop.
+func (g *governance) stop() {
+	close(g.quit)
+	g.wg.Wait()
+}
+
+func (g *governance) loop() {
+	defer g.wg.Done()
+
+	ticker := time.NewTicker(governanceReloadInterval)
+	defer ticker.Stop()
+
+	var lastErr string
+	for {
+		select {
+		case <-ticker.C:
+			if err := g.reload(); err != nil {
+				// Don't flood the logs with the same rejection on every tick
+				if err.Error() != lastErr {
+					log.Error("Failed to reload governance file", "path", g.path, "err", err)
+				}
+				lastErr = err.Error()
+				continue
+			}
+			lastErr = ""
+		case <-g.quit:
+			return
+		}
+	}
+}
+
+// loadGovernanceFile reads a chain configuration from the given JSON file, which
+// has the same format as the config section of a genesis specification.
+func loadGovernanceFile(path string) (*params.ChainConfig, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(blob))
+	dec.DisallowUnknownFields()
+
+	config := new(params.ChainConfig)
+	if err := dec.Decode(config); err != nil {
+		return nil, fmt.Errorf("invalid chain config: %v", err)
+	}
+	return config, nil
+}
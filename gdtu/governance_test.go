@@ -0,0 +1,115 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+package gdtu
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/consensus/gdtuash"
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/core/forkid"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/gdtu/downloader"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// Tests that forks scheduled through the governance file are applied to the chain
+// and picked up by the fork ID filter validating remote peers.
+func TestGovernanceReload(t *testing.T) {
+	config := *params.TestChainConfig
+	config.BerlinBlock = nil
+
+	db := rawdb.NewMemoryDatabase()
+	(&core.Genesis{Config: &config}).MustCommit(db)
+
+	chain, _ := core.NewBlockChain(db, nil, &config, gdtuash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	handler, _ := newHandler(&handlerConfig{
+		Database:   db,
+		Chain:      chain,
+		TxPool:     newTestTxPool(),
+		Network:    1,
+		Sync:       downloader.FastSync,
+		BloomCache: 1,
+	})
+	handler.Start(1000)
+	defer handler.Stop()
+
+	// Create a remote fork ID which already passed a fork unknown locally
+	scheduled := config
+	scheduled.BerlinBlock = big.NewInt(10)
+
+	remote := forkid.NewID(&scheduled, chain.Genesis().Hash(), 10)
+	if err := handler.forkFilter.Load().(forkid.Filter)(remote); err == nil {
+		t.Fatalf("remote fork ID accepted before scheduling the fork")
+	}
+	// Schedule the fork through the governance file and check that it's applied
+	dir, err := ioutil.TempDir("", "governance-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	write := func(config *params.ChainConfig, modTime time.Time) {
+		blob, _ := json.Marshal(config)
+		if err := ioutil.WriteFile(path, blob, 0644); err != nil {
+			t.Fatalf("failed to write governance file: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("failed to set governance file time: %v", err)
+		}
+	}
+	write(&scheduled, time.Now())
+
+	gov := newGovernance(path, chain)
+	if err := gov.reload(); err != nil {
+		t.Fatalf("failed to reload governance file: %v", err)
+	}
+	if block := chain.Config().BerlinBlock; block == nil || block.Cmp(scheduled.BerlinBlock) != 0 {
+		t.Fatalf("fork not scheduled: berlin at %v", block)
+	}
+	for i := 0; ; i++ {
+		if err := handler.forkFilter.Load().(forkid.Filter)(remote); err == nil {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("remote fork ID rejected after scheduling the fork")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Ensure invalid updates are rejected, and reported again until fixed
+	rejected := scheduled
+	rejected.ChainID = big.NewInt(2)
+	write(&rejected, time.Now().Add(time.Minute))
+
+	for i := 0; i < 2; i++ {
+		if err := gov.reload(); err == nil {
+			t.Fatalf("attempt %d: invalid governance file accepted", i)
+		}
+	}
+	if chain.Config().ChainID.Cmp(params.TestChainConfig.ChainID) != 0 {
+		t.Fatalf("rejected chain ID applied: %v", chain.Config().ChainID)
+	}
+}
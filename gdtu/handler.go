@@ -20,17 +20,20 @@ import (
 	"errors"
 	"math"
 	"math/big"
+	"net"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/mclock"
 	"github.com/c88032111/go-gdtu/core"
 	"github.com/c88032111/go-gdtu/core/forkid"
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/event"
 	"github.com/c88032111/go-gdtu/gdtu/downloader"
 	"github.com/c88032111/go-gdtu/gdtu/fetcher"
+	"github.com/c88032111/go-gdtu/gdtu/gdtuconfig"
 	"github.com/c88032111/go-gdtu/gdtu/protocols/gdtu"
 	"github.com/c88032111/go-gdtu/gdtu/protocols/snap"
 	"github.com/c88032111/go-gdtu/gdtudb"
@@ -48,6 +51,7 @@ const (
 
 var (
 	syncChallengeTimeout = 15 * time.Second // Time allowance for a node to reply to the sync progress challenge
+	fetcherTimeout       = 5 * time.Second  // Time allowance for a node to reply to a dispatched block fetcher request
 )
 
 // txPool defines the Methods needed from a transaction pool implementation to
@@ -85,18 +89,22 @@ type handlerConfig struct {
 	EventMux   *event.TypeMux            // Legacy event mux, deprecate for `feed`
 	Checkpoint *params.TrustedCheckpoint // Hard coded checkpoint for sync challenges
 	Whitelist  map[uint64]common.Hash    // Hard coded whitelist for sync challenged
+
+	WhitelistRules  []gdtuconfig.WhitelistRule // Rules on the headers of block ranges for sync challenges
+	PeerBanDuration time.Duration              // Duration to ban peers repeatedly failing sync (0 = disabled)
 }
 
 type handler struct {
 	networkID  uint64
-	forkFilter forkid.Filter // Fork ID filter, constant across the lifetime of the node
+	forkFilter atomic.Value // Fork ID filter (forkid.Filter), rebuilt on chain config updates
 
 	fastSync  uint32 // Flag whether fast sync is enabled (gets disabled if we already have blocks)
 	snapSync  uint32 // Flag whether fast sync should operate on top of the snap protocol
 	acceptTxs uint32 // Flag whether we're considered synchronised (enables transaction processing)
 
-	checkpointNumber uint64      // Block number for the sync progress validator to cross reference
-	checkpointHash   common.Hash // Block hash for the sync progress validator to cross reference
+	checkpointNumber uint64       // Block number for the sync progress validator to cross reference
+	checkpointHash   common.Hash  // Block hash for the sync progress validator to cross reference
+	checkpointHeader atomic.Value // Checkpoint header once delivered by a peer (*types.Header)
 
 	database gdtudb.Database
 	txpool   txPool
@@ -113,14 +121,19 @@ type handler struct {
 	txsCh         chan core.NewTxsEvent
 	txsSub        event.Subscription
 	minedBlockSub *event.TypeMuxSubscription
+	configCh      chan core.ChainConfigEvent
+	configSub     event.Subscription
 
-	whitelist map[uint64]common.Hash
+	whitelist *whitelist
+	bans      *peerBans
+	txFilters txFilters // Ingress filters for the transactions received from the network
 
 	// channels for fetcher, syncer, txsyncLoop
 	txsyncCh chan *txsync
 	quitSync chan struct{}
 
 	chainSync *chainSyncer
+	snapAvail *snapAvailability
 	wg        sync.WaitGroup
 	peerWG    sync.WaitGroup
 }
@@ -132,17 +145,19 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		config.EventMux = new(event.TypeMux) // Nicety initialization for tests
 	}
 	h := &handler{
-		networkID:  config.Network,
-		forkFilter: forkid.NewFilter(config.Chain),
-		eventMux:   config.EventMux,
-		database:   config.Database,
-		txpool:     config.TxPool,
-		chain:      config.Chain,
-		peers:      newPeerSet(),
-		whitelist:  config.Whitelist,
-		txsyncCh:   make(chan *txsync),
-		quitSync:   make(chan struct{}),
-	}
+		networkID: config.Network,
+		eventMux:  config.EventMux,
+		database:  config.Database,
+		txpool:    config.TxPool,
+		chain:     config.Chain,
+		peers:     newPeerSet(),
+		whitelist: newWhitelist(config.Whitelist, config.WhitelistRules),
+		bans:      newPeerBans(config.PeerBanDuration, mclock.System{}),
+		txsyncCh:  make(chan *txsync),
+		quitSync:  make(chan struct{}),
+	}
+	h.forkFilter.Store(forkid.NewFilter(config.Chain))
+
 	if config.Sync == downloader.FullSync {
 		// The database seems empty as the current block is the genesis. Yet the fast
 		// block is ahead, so fast sync was enabled for this node at a certain point.
@@ -215,7 +230,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return n, err
 	}
-	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.removePeer)
+	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.dropFaultyPeer)
 
 	fetchTx := func(peer string, hashes []common.Hash) error {
 		p := h.peers.peer(peer)
@@ -224,8 +239,12 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return p.RequestTxs(hashes)
 	}
-	h.txFetcher = fetcher.NewTxFetcher(h.txpool.Has, h.txpool.AddRemotes, fetchTx)
+	addTxs := func(txs []*types.Transaction) []error {
+		return h.txFilters.addRemotes(h.txpool, txs)
+	}
+	h.txFetcher = fetcher.NewTxFetcher(h.txpool.Has, addTxs, fetchTx)
 	h.chainSync = newChainSyncer(h)
+	h.snapAvail = newSnapAvailability(h)
 	return h, nil
 }
 
@@ -239,6 +258,11 @@ func (h *handler) runGdtuPeer(peer *gdtu.Peer, handler gdtu.Handler) error {
 		peer.Log().Error("Snapshot extension barrier failed", "err", err)
 		return err
 	}
+	// Reject peers banned for repeatedly failing sync, unless trusted
+	if !peer.Peer.Info().Network.Trusted && h.bans.banned(peer.Node().ID(), remoteIP(peer.RemoteAddr())) {
+		peer.Log().Debug("Rejecting banned peer", "addr", peer.RemoteAddr())
+		return p2p.DiscUselessPeer
+	}
 	// TODO(karalabe): Not sure why this is needed
 	if !h.chainSync.handlePeerEvent(peer) {
 		return p2p.DiscQuitting
@@ -255,7 +279,7 @@ func (h *handler) runGdtuPeer(peer *gdtu.Peer, handler gdtu.Handler) error {
 		td      = h.chain.GetTd(hash, number)
 	)
 	forkID := forkid.NewID(h.chain.Config(), h.chain.Genesis().Hash(), h.chain.CurrentHeader().Number.Uint64())
-	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter); err != nil {
+	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter.Load().(forkid.Filter)); err != nil {
 		peer.Log().Debug("Gdtu handshake failed", "err", err)
 		return err
 	}
@@ -325,8 +349,8 @@ func (h *handler) runGdtuPeer(peer *gdtu.Peer, handler gdtu.Handler) error {
 			}
 		}()
 	}
-	// If we have any explicit whitelist block hashes, request them
-	for number := range h.whitelist {
+	// If we have any whitelist rules, request the first block of each
+	for _, number := range h.whitelist.challenges() {
 		if err := peer.RequestHeadersByNumber(number, 1, 0, false); err != nil {
 			return err
 		}
@@ -384,6 +408,35 @@ func (h *handler) removePeer(id string) {
 	peer.Peer.Disconnect(p2p.DiscUselessPeer)
 }
 
+// dropFaultyPeer records a sync failure of a peer which propagated an invalid
+// block or header, potentially banning it, and removes it.
+func (h *handler) dropFaultyPeer(id string) {
+	if peer := h.peers.peer(id); peer != nil {
+		h.recordFailure(peer.Peer)
+	}
+	h.removePeer(id)
+}
+
+// recordFailure records a sync failure of a peer in the ban cache. Trusted peers
+// are never banned.
+func (h *handler) recordFailure(peer *gdtu.Peer) {
+	if peer.Peer.Info().Network.Trusted {
+		return
+	}
+	if h.bans.fail(peer.Node().ID(), remoteIP(peer.RemoteAddr())) {
+		peer.Log().Info("Banning peer after repeated sync failures", "addr", peer.RemoteAddr(), "duration", common.PrettyDuration(h.bans.duration))
+	}
+}
+
+// remoteIP returns the IP address of a peer connection, or nil if the connection
+// is not a TCP one (e.g. a test pipe).
+func remoteIP(addr net.Addr) net.IP {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.IP
+	}
+	return nil
+}
+
 func (h *handler) Start(maxPeers int) {
 	h.maxPeers = maxPeers
 
@@ -398,15 +451,26 @@ func (h *handler) Start(maxPeers int) {
 	h.minedBlockSub = h.eventMux.Subscribe(core.NewMinedBlockEvent{})
 	go h.minedBroadcastLoop()
 
+	// refresh the fork filter on chain config updates
+	h.wg.Add(1)
+	h.configCh = make(chan core.ChainConfigEvent, 1)
+	h.configSub = h.chain.SubscribeChainConfigEvent(h.configCh)
+	go h.forkFilterLoop()
+
 	// start sync handlers
 	h.wg.Add(2)
 	go h.chainSync.loop()
 	go h.txsyncLoop64() // TODO(karalabe): Legacy initial tx echange, drop with gdtu/64.
+
+	// track the availability of the state for snap serving
+	h.wg.Add(1)
+	go h.snapAvail.loop(h.quitSync)
 }
 
 func (h *handler) Stop() {
 	h.txsSub.Unsubscribe()        // quits txBroadcastLoop
 	h.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
+	h.configSub.Unsubscribe()     // quits forkFilterLoop
 
 	// Quit chainSync and txsync64.
 	// After this is done, no new peers will be accepted.
@@ -460,6 +524,8 @@ func (h *handler) BroadcastBlock(block *types.Block, propagate bool) {
 // - To a square root of all peers
 // - And, separately, as announcements to all peers which are not known to
 // already have the given transaction.
+// Transactions with local inclusion conditions are not propagated, since the
+// conditions can't be relayed.
 func (h *handler) BroadcastTransactions(txs types.Transactions) {
 	var (
 		annoCount   int // Count of announcements made
@@ -473,6 +539,9 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 	)
 	// Broadcast transactions to a batch of peers not knowing about it
 	for _, tx := range txs {
+		if tx.Conditional() != nil {
+			continue
+		}
 		peers := h.peers.peersWithoutTransaction(tx.Hash())
 		// Send the tx unconditionally to a subset of our peers
 		numDirect := int(math.Sqrt(float64(len(peers))))
@@ -527,3 +596,19 @@ func (h *handler) txBroadcastLoop() {
 		}
 	}
 }
+
+// forkFilterLoop rebuilds the fork ID filter used to validate remote peers
+// whenever the chain configuration is updated, so newly scheduled forks are
+// enforced without restarting the node.
+func (h *handler) forkFilterLoop() {
+	defer h.wg.Done()
+	for {
+		select {
+		case <-h.configCh:
+			h.forkFilter.Store(forkid.NewFilter(h.chain))
+			log.Info("Refreshed fork ID filter", "id", forkid.NewID(h.chain.Config(), h.chain.Genesis().Hash(), h.chain.CurrentHeader().Number.Uint64()))
+		case <-h.configSub.Err():
+			return
+		}
+	}
+}
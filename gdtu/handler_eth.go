@@ -72,12 +72,18 @@ func (h *gdtuHandler) Handle(peer *gdtu.Peer, packet gdtu.Packet) error {
 		return h.handleBodies(peer, txset, uncleset)
 
 	case *gdtu.NodeDataPacket:
+		if peer.Version() >= gdtu.GDTU34 {
+			return nil // Stale or unsolicited, requests are dispatched
+		}
 		if err := h.downloader.DeliverNodeData(peer.ID(), *packet); err != nil {
 			log.Debug("Failed to deliver node state data", "err", err)
 		}
 		return nil
 
 	case *gdtu.ReceiptsPacket:
+		if peer.Version() >= gdtu.GDTU34 {
+			return nil // Stale or unsolicited, requests are dispatched
+		}
 		if err := h.downloader.DeliverReceipts(peer.ID(), *packet); err != nil {
 			log.Debug("Failed to deliver receipts", "err", err)
 		}
@@ -104,6 +110,20 @@ func (h *gdtuHandler) Handle(peer *gdtu.Peer, packet gdtu.Packet) error {
 	}
 }
 
+// VerifyHeaders is invoked from a peer's message handler on every batch of
+// headers it transmits, validating them against the whitelist rules and
+// dropping peers on other forks.
+func (h *gdtuHandler) VerifyHeaders(peer *gdtu.Peer, headers []*types.Header) error {
+	for _, header := range headers {
+		if err := h.whitelist.verify(header); err != nil {
+			peer.Log().Info("Whitelist mismatch, dropping peer", "number", header.Number, "hash", header.Hash(), "err", err)
+			(*handler)(h).recordFailure(peer)
+			return err
+		}
+	}
+	return nil
+}
+
 // handleHeaders is invoked from a peer's message handler when it transmits a batch
 // of headers for the local node to process.
 func (h *gdtuHandler) handleHeaders(peer *gdtu.Peer, headers []*types.Header) error {
@@ -136,18 +156,19 @@ func (h *gdtuHandler) handleHeaders(peer *gdtu.Peer, headers []*types.Header) er
 
 			// Validate the header and either drop the peer or continue
 			if headers[0].Hash() != h.checkpointHash {
+				(*handler)(h).recordFailure(peer)
 				return errors.New("checkpoint hash mismatch")
 			}
+			h.checkpointHeader.Store(headers[0])
 			return nil
 		}
-		// Otherwise if it's a whitelisted block, validate against the set
-		if want, ok := h.whitelist[headers[0].Number.Uint64()]; ok {
-			if hash := headers[0].Hash(); want != hash {
-				peer.Log().Info("Whitelist mismatch, dropping peer", "number", headers[0].Number.Uint64(), "hash", hash, "want", want)
-				return errors.New("whitelist block mismatch")
-			}
-			peer.Log().Debug("Whitelist block verified", "number", headers[0].Number.Uint64(), "hash", want)
-		}
+	}
+	// Requests of the downloader and the fetcher to peers supporting request ids
+	// are dispatched, anything else reaching here is stale or unsolicited
+	if peer.Version() >= gdtu.GDTU34 {
+		return nil
+	}
+	if filter {
 		// Irrelevant of the fork checks, send the header to the fetcher just in case
 		headers = h.blockFetcher.FilterHeaders(peer.ID(), headers, time.Now())
 	}
@@ -163,6 +184,10 @@ func (h *gdtuHandler) handleHeaders(peer *gdtu.Peer, headers []*types.Header) er
 // handleBodies is invoked from a peer's message handler when it transmits a batch
 // of block bodies for the local node to process.
 func (h *gdtuHandler) handleBodies(peer *gdtu.Peer, txs [][]*types.Transaction, uncles [][]*types.Header) error {
+	// Requests to peers supporting request ids are dispatched, drop anything else
+	if peer.Version() >= gdtu.GDTU34 {
+		return nil
+	}
 	// Filter out any explicitly requested bodies, deliver the rest to the downloader
 	filter := len(txs) > 0 || len(uncles) > 0
 	if filter {
@@ -191,16 +216,73 @@ func (h *gdtuHandler) handleBlockAnnounces(peer *gdtu.Peer, hashes []common.Hash
 			unknownNumbers = append(unknownNumbers, numbers[i])
 		}
 	}
+	fetchHeader, fetchBodies := h.fetcherRequesters(peer)
 	for i := 0; i < len(unknownHashes); i++ {
-		h.blockFetcher.Notify(peer.ID(), unknownHashes[i], unknownNumbers[i], time.Now(), peer.RequestOneHeader, peer.RequestBodies)
+		h.blockFetcher.Notify(peer.ID(), unknownHashes[i], unknownNumbers[i], time.Now(), fetchHeader, fetchBodies)
 	}
 	return nil
 }
 
+// fetcherRequesters returns the functions for the block fetcher to retrieve
+// announced headers and bodies from a peer with. Requests to peers supporting
+// request ids are dispatched, so only the replies to them reach the fetcher.
+func (h *gdtuHandler) fetcherRequesters(peer *gdtu.Peer) (func(common.Hash) error, func([]common.Hash) error) {
+	if peer.Version() < gdtu.GDTU34 {
+		return peer.RequestOneHeader, peer.RequestBodies
+	}
+	fetchHeader := func(hash common.Hash) error {
+		sink := make(chan *gdtu.Response, 1)
+		req, err := peer.DispatchHeadersByHash(hash, 1, 0, false, sink)
+		if err != nil {
+			return err
+		}
+		go h.awaitFetcherResponse(req, sink, func(res *gdtu.Response) {
+			h.blockFetcher.FilterHeaders(peer.ID(), *res.Res.(*gdtu.BlockHeadersPacket), time.Now())
+		})
+		return nil
+	}
+	fetchBodies := func(hashes []common.Hash) error {
+		sink := make(chan *gdtu.Response, 1)
+		req, err := peer.DispatchBodies(hashes, sink)
+		if err != nil {
+			return err
+		}
+		go h.awaitFetcherResponse(req, sink, func(res *gdtu.Response) {
+			txs, uncles := res.Res.(*gdtu.BlockBodiesPacket).Unpack()
+			h.blockFetcher.FilterBodies(peer.ID(), txs, uncles, time.Now())
+		})
+		return nil
+	}
+	return fetchHeader, fetchBodies
+}
+
+// awaitFetcherResponse waits for the reply to a dispatched block fetcher request
+// and hands it to deliver, giving up after a timeout.
+func (h *gdtuHandler) awaitFetcherResponse(req *gdtu.Request, sink chan *gdtu.Response, deliver func(*gdtu.Response)) {
+	defer req.Close()
+
+	timeout := time.NewTimer(fetcherTimeout)
+	defer timeout.Stop()
+
+	select {
+	case res := <-sink:
+		deliver(res)
+	case <-timeout.C:
+	case <-h.quitSync:
+	}
+}
+
 // handleBlockBroadcast is invoked from a peer's message handler when it transmits a
 // block broadcast for the local node to process.
 func (h *gdtuHandler) handleBlockBroadcast(peer *gdtu.Peer, block *types.Block, td *big.Int) error {
-	// Schedule the block for import
+	// Drop peers propagating blocks of forks excluded by the whitelist
+	if err := h.whitelist.verify(block.Header()); err != nil {
+		peer.Log().Info("Whitelist mismatch, dropping peer", "number", block.Number(), "hash", block.Hash(), "err", err)
+		(*handler)(h).recordFailure(peer)
+		return err
+	}
+	// Schedule the block for import, recovering the senders in the meantime
+	core.RecoverSenders(h.chain.Config(), types.Blocks{block})
 	h.blockFetcher.Enqueue(peer.ID(), block)
 
 	// Assuming the block is importable by the peer, but possibly not yet done so,
@@ -48,12 +48,13 @@ type testGdtuHandler struct {
 	txBroadcasts    event.Feed
 }
 
-func (h *testGdtuHandler) Chain() *core.BlockChain                { panic("no backing chain") }
-func (h *testGdtuHandler) StateBloom() *trie.SyncBloom            { panic("no backing state bloom") }
-func (h *testGdtuHandler) TxPool() gdtu.TxPool                    { panic("no backing tx pool") }
-func (h *testGdtuHandler) AcceptTxs() bool                        { return true }
-func (h *testGdtuHandler) RunPeer(*gdtu.Peer, gdtu.Handler) error { panic("not used in tests") }
-func (h *testGdtuHandler) PeerInfo(enode.ID) interface{}          { panic("not used in tests") }
+func (h *testGdtuHandler) Chain() *core.BlockChain                         { panic("no backing chain") }
+func (h *testGdtuHandler) StateBloom() *trie.SyncBloom                     { panic("no backing state bloom") }
+func (h *testGdtuHandler) TxPool() gdtu.TxPool                             { panic("no backing tx pool") }
+func (h *testGdtuHandler) AcceptTxs() bool                                 { return true }
+func (h *testGdtuHandler) RunPeer(*gdtu.Peer, gdtu.Handler) error          { panic("not used in tests") }
+func (h *testGdtuHandler) PeerInfo(enode.ID) interface{}                   { panic("not used in tests") }
+func (h *testGdtuHandler) VerifyHeaders(*gdtu.Peer, []*types.Header) error { return nil }
 
 func (h *testGdtuHandler) Handle(peer *gdtu.Peer, packet gdtu.Packet) error {
 	switch packet := packet.(type) {
@@ -462,6 +463,112 @@ func testTransactionPropagation(t *testing.T, protocol uint) {
 	}
 }
 
+// Tests that transactions with local inclusion conditions are neither announced
+// to newly joining peers nor broadcast to existing ones.
+func TestConditionalTransactionsNotPropagated(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler()
+	defer handler.close()
+
+	// newTx creates a signed transaction, optionally with inclusion conditions
+	newTx := func(nonce uint64, conditional bool) *types.Transaction {
+		tx := types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+		tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+		if conditional {
+			tx.SetConditional(&types.TransactionConditional{BlockNumberMax: big.NewInt(1)})
+		}
+		return tx
+	}
+	// Fill the pool with both plain and conditional transactions before the
+	// peer joins, these are announced on connect
+	pooled := []*types.Transaction{newTx(0, false), newTx(1, true), newTx(2, false), newTx(3, true)}
+	go handler.txpool.AddRemotes(pooled) // Need goroutine to not block on feed
+	time.Sleep(250 * time.Millisecond)   // Wait until tx events get out of the system
+
+	p2pSrc, p2pSink := p2p.MsgPipe()
+	defer p2pSrc.Close()
+	defer p2pSink.Close()
+
+	src := gdtu.NewPeer(gdtu.GDTU33, p2p.NewPeer(enode.ID{1}, "", nil), p2pSrc, handler.txpool)
+	sink := gdtu.NewPeer(gdtu.GDTU33, p2p.NewPeer(enode.ID{2}, "", nil), p2pSink, handler.txpool)
+	defer src.Close()
+	defer sink.Close()
+
+	go handler.handler.runGdtuPeer(src, func(peer *gdtu.Peer) error {
+		return gdtu.Handle((*gdtuHandler)(handler.handler), peer)
+	})
+	var (
+		genesis = handler.chain.Genesis()
+		head    = handler.chain.CurrentBlock()
+		td      = handler.chain.GetTd(head.Hash(), head.NumberU64())
+	)
+	if err := sink.Handshake(1, td, head.Hash(), genesis.Hash(), forkid.NewIDWithChain(handler.chain), forkid.NewFilter(handler.chain)); err != nil {
+		t.Fatalf("failed to run protocol handshake")
+	}
+	backend := new(testGdtuHandler)
+
+	anns := make(chan []common.Hash)
+	annSub := backend.txAnnounces.Subscribe(anns)
+	defer annSub.Unsubscribe()
+
+	bcasts := make(chan []*types.Transaction)
+	bcastSub := backend.txBroadcasts.Subscribe(bcasts)
+	defer bcastSub.Unsubscribe()
+
+	go gdtu.Handle(backend, sink)
+
+	// Once the peer joined, broadcast a new batch of transactions too
+	broadcast := []*types.Transaction{newTx(4, true), newTx(5, false)}
+	handler.txpool.AddRemotes(broadcast)
+
+	var (
+		want = map[common.Hash]bool{
+			pooled[0].Hash():    true,
+			pooled[2].Hash():    true,
+			broadcast[1].Hash(): true,
+		}
+		conditional = map[common.Hash]bool{
+			pooled[1].Hash():    true,
+			pooled[3].Hash():    true,
+			broadcast[0].Hash(): true,
+		}
+	)
+	check := func(hash common.Hash) {
+		if conditional[hash] {
+			t.Errorf("conditional transaction propagated: %x", hash)
+		}
+		delete(want, hash)
+	}
+	timeout := time.After(time.Second)
+	for len(want) > 0 {
+		select {
+		case hashes := <-anns:
+			for _, hash := range hashes {
+				check(hash)
+			}
+		case txs := <-bcasts:
+			for _, tx := range txs {
+				check(tx.Hash())
+			}
+		case <-timeout:
+			t.Fatalf("plain transactions not propagated: %d missing", len(want))
+		}
+	}
+	// Make sure nothing else trickles in afterwards
+	select {
+	case hashes := <-anns:
+		for _, hash := range hashes {
+			check(hash)
+		}
+	case txs := <-bcasts:
+		for _, tx := range txs {
+			check(tx.Hash())
+		}
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 // Tests that post gdtu protocol handshake, clients perform a mutual checkpoint
 // challenge to validate each other's chains. Hash mismatches, or missing ones
 // during a fast sync should lead to the peer getting dropped.
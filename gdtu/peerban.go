@@ -0,0 +1,180 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common/mclock"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+)
+
+// peerBanThreshold is the number of sync failures within the decay period after
+// which a peer gets banned.
+const peerBanThreshold = 3
+
+// PeerBan is an entry of the sync ban cache, reported through the admin API.
+type PeerBan struct {
+	Key      string    `json:"key"`      // Node ID or IP address of the peer
+	Failures int       `json:"failures"` // Number of recent sync failures
+	Banned   bool      `json:"banned"`   // Whgdtuer the peer is currently banned
+	Expires  time.Time `json:"expires"`  // Time at which the entry is forgotten
+}
+
+// peerBans is a time-decaying cache of peers which repeatedly failed to deliver
+// valid chain data during sync. A dropped peer could otherwise reconnect right
+// away and keep wasting our sync slots.
+//
+// Failures are tracked both by node ID and IP address, so rotating the node key
+// doesn't evade a ban. Recorded failures are forgotten if no new ones occur for
+// the ban duration, and bans are lifted after the same duration.
+type peerBans struct {
+	duration time.Duration // Ban duration, zero disables banning
+	clock    mclock.Clock
+
+	lock    sync.Mutex
+	entries map[string]*peerBanEntry
+}
+
+// peerBanEntry is the failure record of a single node ID or IP address.
+type peerBanEntry struct {
+	failures int
+	updated  mclock.AbsTime // Time of the last failure
+	until    mclock.AbsTime // Expiration of the ban, zero if not banned
+}
+
+// newPeerBans creates a ban cache banning peers for the given duration.
+func newPeerBans(duration time.Duration, clock mclock.Clock) *peerBans {
+	return &peerBans{
+		duration: duration,
+		clock:    clock,
+		entries:  make(map[string]*peerBanEntry),
+	}
+}
+
+// peerBanKeys returns the cache keys of a peer.
+func peerBanKeys(id enode.ID, ip net.IP) []string {
+	keys := []string{id.String()}
+	if ip != nil {
+		keys = append(keys, ip.String())
+	}
+	return keys
+}
+
+// fail records a sync failure of the peer, banning it if it failed too often.
+// Whgdtuer the peer got banned is returned.
+func (b *peerBans) fail(id enode.ID, ip net.IP) bool {
+	if b.duration == 0 {
+		return false
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.clock.Now()
+	b.expire(now)
+
+	var banned bool
+	for _, key := range peerBanKeys(id, ip) {
+		entry := b.entries[key]
+		if entry == nil {
+			entry = new(peerBanEntry)
+			b.entries[key] = entry
+		}
+		entry.failures++
+		entry.updated = now
+		if entry.failures >= peerBanThreshold {
+			entry.until = now.Add(b.duration)
+			banned = true
+		}
+	}
+	return banned
+}
+
+// banned reports whgdtuer the peer is banned, either by node ID or IP address.
+func (b *peerBans) banned(id enode.ID, ip net.IP) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.clock.Now()
+	b.expire(now)
+
+	for _, key := range peerBanKeys(id, ip) {
+		if entry := b.entries[key]; entry != nil && entry.until > now {
+			return true
+		}
+	}
+	return false
+}
+
+// list returns the current entries of the cache, ordered by key.
+func (b *peerBans) list() []PeerBan {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.clock.Now()
+	b.expire(now)
+
+	list := make([]PeerBan, 0, len(b.entries))
+	for key, entry := range b.entries {
+		expires := entry.updated.Add(b.duration)
+		if entry.until > expires {
+			expires = entry.until
+		}
+		list = append(list, PeerBan{
+			Key:      key,
+			Failures: entry.failures,
+			Banned:   entry.until > now,
+			Expires:  time.Now().Add(time.Duration(expires - now)),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+// clear removes the entry of the given node ID or IP address, or all entries if
+// the key is empty. The number of removed entries is returned.
+func (b *peerBans) clear(key string) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if key == "" {
+		n := len(b.entries)
+		b.entries = make(map[string]*peerBanEntry)
+		return n
+	}
+	if _, ok := b.entries[key]; !ok {
+		return 0
+	}
+	delete(b.entries, key)
+	return 1
+}
+
+// expire drops the bans which ran out and the failures which decayed. The lock
+// is assumed to be held.
+func (b *peerBans) expire(now mclock.AbsTime) {
+	for key, entry := range b.entries {
+		if entry.until > now {
+			continue
+		}
+		if entry.until != 0 || now-entry.updated >= mclock.AbsTime(b.duration) {
+			delete(b.entries, key)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common/mclock"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+)
+
+// Tests that peers get banned after repeated failures and that both the bans
+// and the failures decay over time.
+func TestPeerBans(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		bans  = newPeerBans(time.Minute, clock)
+		id    = enode.ID{0x01}
+		other = enode.ID{0x02}
+		ip    = net.IP{10, 0, 0, 1}
+	)
+	// Failures spread wider than the decay period should not trigger a ban
+	for i := 0; i < 2*peerBanThreshold; i++ {
+		if bans.fail(id, ip) {
+			t.Fatalf("peer banned after decayed failures")
+		}
+		clock.Run(time.Minute)
+	}
+	if bans.banned(id, ip) {
+		t.Fatalf("peer banned after decayed failures")
+	}
+	// Repeated failures should ban the peer, both by ID and by IP
+	for i := 0; i < peerBanThreshold-1; i++ {
+		if bans.fail(id, ip) {
+			t.Fatalf("peer banned after %d failures", i+1)
+		}
+	}
+	if !bans.fail(id, ip) {
+		t.Fatalf("peer not banned after %d failures", peerBanThreshold)
+	}
+	if !bans.banned(id, nil) || !bans.banned(other, ip) {
+		t.Fatalf("peer not banned by both ID and IP")
+	}
+	if bans.banned(other, nil) {
+		t.Fatalf("unrelated peer banned")
+	}
+	if list := bans.list(); len(list) != 2 || !list[0].Banned || !list[1].Banned {
+		t.Fatalf("ban list mismatch: %v", list)
+	}
+	// The ban should be lifted after the ban duration
+	clock.Run(time.Minute)
+	if bans.banned(id, ip) {
+		t.Fatalf("ban not lifted after expiration")
+	}
+	if list := bans.list(); len(list) != 0 {
+		t.Fatalf("expired entries not dropped: %v", list)
+	}
+	// Bans should be clearable by key
+	for i := 0; i < peerBanThreshold; i++ {
+		bans.fail(id, ip)
+	}
+	if n := bans.clear(ip.String()); n != 1 {
+		t.Fatalf("cleared entries mismatch: have %d, want 1", n)
+	}
+	if bans.banned(other, ip) || !bans.banned(id, nil) {
+		t.Fatalf("wrong entry cleared")
+	}
+	if n := bans.clear(""); n != 1 {
+		t.Fatalf("cleared entries mismatch: have %d, want 1", n)
+	}
+	if bans.banned(id, ip) {
+		t.Fatalf("ban not cleared")
+	}
+	// A zero duration should disable banning
+	disabled := newPeerBans(0, clock)
+	for i := 0; i < peerBanThreshold; i++ {
+		disabled.fail(id, ip)
+	}
+	if disabled.banned(id, ip) {
+		t.Fatalf("peer banned with banning disabled")
+	}
+}
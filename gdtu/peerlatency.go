@@ -0,0 +1,93 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/c88032111/go-gdtu/gdtu/protocols/gdtu"
+)
+
+// PeerLatency is the summary of the request round trips of a peer, reported
+// through the admin API.
+type PeerLatency struct {
+	ID         string                          `json:"id"`         // Node ID of the peer
+	Name       string                          `json:"name"`       // Client name advertised by the peer
+	RemoteAddr string                          `json:"remoteAddr"` // Network address of the peer
+	Score      float64                         `json:"score"`      // Mean round trip the peers are ranked by, in milliseconds
+	Requests   map[string]*gdtu.RequestLatency `json:"requests"`   // Round trip statistics per request type
+}
+
+// latencyScore returns the mean round trip of the given request type, or of all
+// the request types weighted by their responses if none is given, along with
+// whgdtuer any response was measured.
+func latencyScore(requests map[string]*gdtu.RequestLatency, kind string) (float64, bool) {
+	if kind != "" {
+		stats := requests[kind]
+		return stats.Mean, stats.Count > 0
+	}
+	var total, count float64
+	for _, stats := range requests {
+		if stats.Count == 0 {
+			continue
+		}
+		total += stats.Mean * float64(stats.Count)
+		count += float64(stats.Count)
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return total / count, true
+}
+
+// slowestPeers returns the n peers with the longest mean request round trips of
+// the given request type, or of all the request types if none is given.
+func (ps *peerSet) slowestPeers(n int, kind string) ([]*PeerLatency, error) {
+	if kind != "" {
+		known := false
+		for _, name := range gdtu.LatencyKinds {
+			known = known || name == kind
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown request type %q, want one of %v", kind, gdtu.LatencyKinds)
+		}
+	}
+	ps.lock.RLock()
+	peers := make([]*PeerLatency, 0, len(ps.peers))
+	for id, p := range ps.peers {
+		requests := p.RequestLatencies()
+		score, ok := latencyScore(requests, kind)
+		if !ok {
+			continue
+		}
+		peers = append(peers, &PeerLatency{
+			ID:         id,
+			Name:       p.Name(),
+			RemoteAddr: p.RemoteAddr().String(),
+			Score:      score,
+			Requests:   requests,
+		})
+	}
+	ps.lock.RUnlock()
+
+	sort.Slice(peers, func(i, j int) bool { return peers[i].Score > peers[j].Score })
+	if len(peers) > n {
+		peers = peers[:n]
+	}
+	return peers, nil
+}
@@ -37,21 +37,31 @@ func (e enrEntry) ENRKey() string {
 }
 
 // StartENRUpdater starts the `gdtu` ENR updater loop, which listens for chain
-// head events and updates the requested node record whenever a fork is passed.
+// head and configuration events and updates the requested node record whenever
+// a fork is passed or scheduled.
 func StartENRUpdater(chain *core.BlockChain, ln *enode.LocalNode) {
-	var newHead = make(chan core.ChainHeadEvent, 10)
-	sub := chain.SubscribeChainHeadEvent(newHead)
+	var (
+		newHead   = make(chan core.ChainHeadEvent)
+		newConfig = make(chan core.ChainConfigEvent)
+	)
+	sub := chain.SubscribeChainHeadEventCoalesced(newHead)
+	configSub := chain.SubscribeChainConfigEvent(newConfig)
 
 	go func() {
 		defer sub.Unsubscribe()
+		defer configSub.Unsubscribe()
 		for {
 			select {
 			case <-newHead:
 				ln.Set(currentENREntry(chain))
+			case <-newConfig:
+				ln.Set(currentENREntry(chain))
 			case <-sub.Err():
 				// Would be nice to sync with Stop, but there is no
 				// good way to do that.
 				return
+			case <-configSub.Err():
+				return
 			}
 		}
 	}()
@@ -0,0 +1,200 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/metrics"
+)
+
+var (
+	// errNoRequestIds is returned when dispatching a request to a peer of a
+	// protocol version without request ids, whose responses can't be matched.
+	errNoRequestIds = errors.New("protocol version without request ids")
+
+	dispatchedResponseMeter = metrics.NewRegisteredMeter("gdtu/protocols/gdtu/dispatch/responses", nil)
+	droppedResponseMeter    = metrics.NewRegisteredMeter("gdtu/protocols/gdtu/dispatch/dropped", nil)
+)
+
+// Request is a request dispatched to a peer, whose response is routed back to
+// the requester by request id. Any number of requests can be in flight to the
+// same peer, their responses are delivered independently of the order they
+// arrive in. Delivering a response blocks the message handling of the peer until
+// the requester accepts it or closes the request, so sinks should be buffered.
+type Request struct {
+	peer *Peer
+	id   uint64
+	want uint64 // Message code of the expected response
+
+	sink   chan *Response // Channel to deliver the response on
+	cancel chan struct{}  // Closed when the requester is no longer interested
+	sent   time.Time
+}
+
+// Response is the reply of a peer to a dispatched request.
+type Response struct {
+	Req  *Request      // Request the response belongs to
+	Res  interface{}   // Response packet, e.g. *BlockHeadersPacket
+	Time time.Duration // Round trip of the request
+}
+
+// Close stops waiting for the response of the request. A response arriving
+// afterwards is dropped.
+func (r *Request) Close() {
+	if r.peer == nil {
+		return
+	}
+	r.peer.dispatchLock.Lock()
+	defer r.peer.dispatchLock.Unlock()
+
+	if _, ok := r.peer.dispatched[r.id]; ok {
+		delete(r.peer.dispatched, r.id)
+		close(r.cancel)
+	}
+}
+
+// Peer returns the id of the peer the request was sent to.
+func (r *Request) Peer() string {
+	return r.peer.id
+}
+
+// dispatchRequest sends a request to the peer, routing the response of the given
+// message code to sink.
+func (p *Peer) dispatchRequest(code uint64, want uint64, sink chan *Response, data func(id uint64) interface{}) (*Request, error) {
+	if p.Version() < GDTU34 {
+		return nil, errNoRequestIds
+	}
+	req := &Request{
+		peer:   p,
+		want:   want,
+		sink:   sink,
+		cancel: make(chan struct{}),
+		sent:   time.Now(),
+	}
+	p.dispatchLock.Lock()
+	for {
+		req.id = rand.Uint64()
+		if _, ok := p.dispatched[req.id]; !ok {
+			break
+		}
+	}
+	p.dispatched[req.id] = req
+	p.dispatchLock.Unlock()
+
+	if err := p.sendRequest(code, req.id, data(req.id)); err != nil {
+		req.Close()
+		return nil, err
+	}
+	return req, nil
+}
+
+// dispatchResponse delivers a response to the dispatched request of the given
+// id, reporting whgdtuer there was one. Responses without a dispatched request
+// are left to the backend handlers.
+func (p *Peer) dispatchResponse(code uint64, id uint64, res interface{}) bool {
+	p.dispatchLock.Lock()
+	req := p.dispatched[id]
+	if req == nil || req.want != code {
+		p.dispatchLock.Unlock()
+		return false
+	}
+	delete(p.dispatched, id)
+	p.dispatchLock.Unlock()
+
+	select {
+	case req.sink <- &Response{Req: req, Res: res, Time: time.Since(req.sent)}:
+		dispatchedResponseMeter.Mark(1)
+	case <-req.cancel:
+		droppedResponseMeter.Mark(1)
+	case <-p.term:
+	}
+	return true
+}
+
+// DispatchHeadersByNumber requests a batch of headers from the peer, based on
+// the number of an origin block, delivering the response to sink.
+func (p *Peer) DispatchHeadersByNumber(origin uint64, amount int, skip int, reverse bool, sink chan *Response) (*Request, error) {
+	p.Log().Debug("Dispatching batch of headers", "count", amount, "fromnum", origin, "skip", skip, "reverse", reverse)
+	return p.dispatchRequest(GetBlockHeadersMsg, BlockHeadersMsg, sink, func(id uint64) interface{} {
+		return &GetBlockHeadersPacket66{
+			RequestId: id,
+			GetBlockHeadersPacket: &GetBlockHeadersPacket{
+				Origin:  HashOrNumber{Number: origin},
+				Amount:  uint64(amount),
+				Skip:    uint64(skip),
+				Reverse: reverse,
+			},
+		}
+	})
+}
+
+// DispatchHeadersByHash requests a batch of headers from the peer, based on the
+// hash of an origin block, delivering the response to sink.
+func (p *Peer) DispatchHeadersByHash(origin common.Hash, amount int, skip int, reverse bool, sink chan *Response) (*Request, error) {
+	p.Log().Debug("Dispatching batch of headers", "count", amount, "fromhash", origin, "skip", skip, "reverse", reverse)
+	return p.dispatchRequest(GetBlockHeadersMsg, BlockHeadersMsg, sink, func(id uint64) interface{} {
+		return &GetBlockHeadersPacket66{
+			RequestId: id,
+			GetBlockHeadersPacket: &GetBlockHeadersPacket{
+				Origin:  HashOrNumber{Hash: origin},
+				Amount:  uint64(amount),
+				Skip:    uint64(skip),
+				Reverse: reverse,
+			},
+		}
+	})
+}
+
+// DispatchBodies requests the bodies of a batch of blocks from the peer,
+// delivering the response to sink.
+func (p *Peer) DispatchBodies(hashes []common.Hash, sink chan *Response) (*Request, error) {
+	p.Log().Debug("Dispatching batch of block bodies", "count", len(hashes))
+	return p.dispatchRequest(GetBlockBodiesMsg, BlockBodiesMsg, sink, func(id uint64) interface{} {
+		return &GetBlockBodiesPacket66{
+			RequestId:            id,
+			GetBlockBodiesPacket: hashes,
+		}
+	})
+}
+
+// DispatchNodeData requests a batch of state trie nodes or contract codes from
+// the peer, delivering the response to sink.
+func (p *Peer) DispatchNodeData(hashes []common.Hash, sink chan *Response) (*Request, error) {
+	p.Log().Debug("Dispatching batch of state data", "count", len(hashes))
+	return p.dispatchRequest(GetNodeDataMsg, NodeDataMsg, sink, func(id uint64) interface{} {
+		return &GetNodeDataPacket66{
+			RequestId:         id,
+			GetNodeDataPacket: hashes,
+		}
+	})
+}
+
+// DispatchReceipts requests the receipts of a batch of blocks from the peer,
+// delivering the response to sink.
+func (p *Peer) DispatchReceipts(hashes []common.Hash, sink chan *Response) (*Request, error) {
+	p.Log().Debug("Dispatching batch of receipts", "count", len(hashes))
+	return p.dispatchRequest(GetReceiptsMsg, ReceiptsMsg, sink, func(id uint64) interface{} {
+		return &GetReceiptsPacket66{
+			RequestId:         id,
+			GetReceiptsPacket: hashes,
+		}
+	})
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/p2p"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+)
+
+// Tests that the responses of dispatched requests are routed to their requests
+// by id, even if answered out of order.
+func TestDispatchOutOfOrder(t *testing.T) {
+	backend := newTestBackend(0)
+	defer backend.close()
+
+	app, net := p2p.MsgPipe()
+	defer app.Close()
+
+	peer := NewPeer(GDTU34, p2p.NewPeer(enode.ID{0xde, 0xad}, "peer", nil), net, backend.TxPool())
+	defer peer.Close()
+	go Handle(backend, peer)
+
+	// Dispatch a few requests and answer them in reverse order. Message pipe
+	// writes block until read, so read the requests in the background.
+	queryCh := make(chan *GetBlockHeadersPacket66)
+	go func() {
+		for {
+			msg, err := app.ReadMsg()
+			if err != nil {
+				return
+			}
+			query := new(GetBlockHeadersPacket66)
+			if err := msg.Decode(query); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+			queryCh <- query
+		}
+	}()
+	sink := make(chan *Response, 3)
+	reqs := make(map[*Request]uint64)
+	var queries []*GetBlockHeadersPacket66
+	for _, origin := range []uint64{10, 20, 30} {
+		req, err := peer.DispatchHeadersByNumber(origin, 1, 0, false, sink)
+		if err != nil {
+			t.Fatalf("failed to dispatch request: %v", err)
+		}
+		reqs[req] = origin
+		queries = append(queries, <-queryCh)
+	}
+	for i := len(queries) - 1; i >= 0; i-- {
+		header := &types.Header{Number: new(big.Int).SetUint64(queries[i].Origin.Number), Difficulty: big.NewInt(1)}
+		if err := p2p.Send(app, BlockHeadersMsg, &BlockHeadersPacket66{RequestId: queries[i].RequestId, BlockHeadersPacket: BlockHeadersPacket{header}}); err != nil {
+			t.Fatalf("failed to send response: %v", err)
+		}
+	}
+	for i := 0; i < len(queries); i++ {
+		select {
+		case res := <-sink:
+			origin, ok := reqs[res.Req]
+			if !ok {
+				t.Fatalf("response to unknown request")
+			}
+			delete(reqs, res.Req)
+
+			headers := *res.Res.(*BlockHeadersPacket)
+			if len(headers) != 1 || headers[0].Number.Uint64() != origin {
+				t.Errorf("response mismatch: have %v, want #%d", headers, origin)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("response %d timeout", i)
+		}
+	}
+	// Closed requests don't get their responses routed any more
+	req, err := peer.DispatchHeadersByNumber(40, 1, 0, false, sink)
+	if err != nil {
+		t.Fatalf("failed to dispatch request: %v", err)
+	}
+	<-queryCh
+	req.Close()
+	if peer.dispatchResponse(BlockHeadersMsg, req.id, &BlockHeadersPacket{}) {
+		t.Errorf("response to closed request routed")
+	}
+}
+
+// Tests that requests can't be dispatched to peers without request ids.
+func TestDispatchLegacyPeer(t *testing.T) {
+	backend := newTestBackend(0)
+	defer backend.close()
+
+	app, net := p2p.MsgPipe()
+	defer app.Close()
+
+	peer := NewPeer(GDTU33, p2p.NewPeer(enode.ID{0xde, 0xad}, "peer", nil), net, backend.TxPool())
+	defer peer.Close()
+
+	if _, err := peer.DispatchHeadersByNumber(1, 1, 0, false, make(chan *Response, 1)); err != errNoRequestIds {
+		t.Errorf("dispatch error mismatch: have %v, want %v", err, errNoRequestIds)
+	}
+}
+
+// headerRejectingBackend is a test backend rejecting all received headers.
+type headerRejectingBackend struct {
+	*testBackend
+}
+
+var errHeadersRejected = errors.New("headers rejected")
+
+func (b headerRejectingBackend) VerifyHeaders(*Peer, []*types.Header) error {
+	return errHeadersRejected
+}
+
+// Tests that the backend verifies the headers of dispatched responses before
+// they are routed, dropping the peer if they are rejected.
+func TestDispatchVerifyHeaders(t *testing.T) {
+	backend := newTestBackend(0)
+	defer backend.close()
+
+	app, net := p2p.MsgPipe()
+	defer app.Close()
+
+	peer := NewPeer(GDTU34, p2p.NewPeer(enode.ID{0xde, 0xad}, "peer", nil), net, backend.TxPool())
+	defer peer.Close()
+
+	errc := make(chan error, 1)
+	go func() { errc <- Handle(headerRejectingBackend{backend}, peer) }()
+
+	sink := make(chan *Response, 1)
+	go peer.DispatchHeadersByNumber(10, 1, 0, false, sink)
+
+	msg, err := app.ReadMsg()
+	if err != nil {
+		t.Fatalf("failed to read request: %v", err)
+	}
+	query := new(GetBlockHeadersPacket66)
+	if err := msg.Decode(query); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+	header := &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(1)}
+	if err := p2p.Send(app, BlockHeadersMsg, &BlockHeadersPacket66{RequestId: query.RequestId, BlockHeadersPacket: BlockHeadersPacket{header}}); err != nil {
+		t.Fatalf("failed to send response: %v", err)
+	}
+	select {
+	case err := <-errc:
+		if err != errHeadersRejected {
+			t.Errorf("handler error mismatch: have %v, want %v", err, errHeadersRejected)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("peer not dropped")
+	}
+	select {
+	case <-sink:
+		t.Errorf("rejected headers routed to requester")
+	default:
+	}
+}
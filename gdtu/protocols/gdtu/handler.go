@@ -87,6 +87,11 @@ type Backend interface {
 	// PeerInfo retrieves all known `gdtu` information about a peer.
 	PeerInfo(id enode.ID) interface{}
 
+	// VerifyHeaders is a callback to be invoked on every batch of headers
+	// received from the remote peer, before it is routed to its requester or
+	// forwarded to Handle. Returning an error drops the peer.
+	VerifyHeaders(peer *Peer, headers []*types.Header) error
+
 	// Handle is a callback to be invoked when a data packet is received from
 	// the remote peer. Only packets not consumed by the protocol handler will
 	// be forwarded to the backend.
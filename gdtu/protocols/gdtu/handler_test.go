@@ -105,6 +105,8 @@ func (b *testBackend) PeerInfo(enode.ID) interface{} { panic("not implemented")
 func (b *testBackend) AcceptTxs() bool {
 	panic("data processing tests should be done in the handler package")
 }
+func (b *testBackend) VerifyHeaders(*Peer, []*types.Header) error { return nil }
+
 func (b *testBackend) Handle(*Peer, Packet) error {
 	panic("data processing tests should be done in the handler package")
 }
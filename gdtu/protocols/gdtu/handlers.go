@@ -255,19 +255,16 @@ func answerGetReceiptsQuery(backend Backend, query GetReceiptsPacket, peer *Peer
 			break
 		}
 		// Retrieve the requested block's receipts
-		results := backend.Chain().GetReceiptsByHash(hash)
-		if results == nil {
+		encoded := backend.Chain().GetReceiptsRLP(hash)
+		if encoded == nil {
 			if header := backend.Chain().GetHeaderByHash(hash); header == nil || header.ReceiptHash != types.EmptyRootHash {
 				continue
 			}
+			encoded = rlp.EmptyList
 		}
-		// If known, encode and queue for response packet
-		if encoded, err := rlp.EncodeToBytes(results); err != nil {
-			log.Error("Failed to encode receipt", "err", err)
-		} else {
-			receipts = append(receipts, encoded)
-			bytes += len(encoded)
-		}
+		// If known, queue for response packet
+		receipts = append(receipts, encoded)
+		bytes += len(encoded)
 	}
 	return receipts
 }
@@ -318,6 +315,11 @@ func handleBlockHeaders(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(res); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	peer.latency.received(BlockHeadersMsg, 0)
+
+	if err := backend.VerifyHeaders(peer, *res); err != nil {
+		return err
+	}
 	return backend.Handle(peer, res)
 }
 
@@ -327,6 +329,15 @@ func handleBlockHeaders66(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(res); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	peer.latency.received(BlockHeadersMsg, res.RequestId)
+
+	if err := backend.VerifyHeaders(peer, res.BlockHeadersPacket); err != nil {
+		return err
+	}
+	// Route the response to its requester if it was dispatched
+	if peer.dispatchResponse(BlockHeadersMsg, res.RequestId, &res.BlockHeadersPacket) {
+		return nil
+	}
 	return backend.Handle(peer, &res.BlockHeadersPacket)
 }
 
@@ -336,6 +347,8 @@ func handleBlockBodies(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(res); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	peer.latency.received(BlockBodiesMsg, 0)
+
 	return backend.Handle(peer, res)
 }
 
@@ -345,6 +358,12 @@ func handleBlockBodies66(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(res); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	peer.latency.received(BlockBodiesMsg, res.RequestId)
+
+	// Route the response to its requester if it was dispatched
+	if peer.dispatchResponse(BlockBodiesMsg, res.RequestId, &res.BlockBodiesPacket) {
+		return nil
+	}
 	return backend.Handle(peer, &res.BlockBodiesPacket)
 }
 
@@ -354,6 +373,8 @@ func handleNodeData(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(res); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	peer.latency.received(NodeDataMsg, 0)
+
 	return backend.Handle(peer, res)
 }
 
@@ -363,6 +384,12 @@ func handleNodeData66(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(res); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	peer.latency.received(NodeDataMsg, res.RequestId)
+
+	// Route the response to its requester if it was dispatched
+	if peer.dispatchResponse(NodeDataMsg, res.RequestId, &res.NodeDataPacket) {
+		return nil
+	}
 	return backend.Handle(peer, &res.NodeDataPacket)
 }
 
@@ -372,6 +399,8 @@ func handleReceipts(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(res); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	peer.latency.received(ReceiptsMsg, 0)
+
 	return backend.Handle(peer, res)
 }
 
@@ -381,6 +410,12 @@ func handleReceipts66(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(res); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	peer.latency.received(ReceiptsMsg, res.RequestId)
+
+	// Route the response to its requester if it was dispatched
+	if peer.dispatchResponse(ReceiptsMsg, res.RequestId, &res.ReceiptsPacket) {
+		return nil
+	}
 	return backend.Handle(peer, &res.ReceiptsPacket)
 }
 
@@ -432,9 +467,10 @@ func answerGetPooledTransactions(backend Backend, query GetPooledTransactionsPac
 		if bytes >= softResponseLimit {
 			break
 		}
-		// Retrieve the requested transaction, skipping if unknown to us
+		// Retrieve the requested transaction, skipping if unknown to us or bound
+		// to local inclusion conditions, which can't be relayed
 		tx := backend.TxPool().Get(hash)
-		if tx == nil {
+		if tx == nil || tx.Conditional() != nil {
 			continue
 		}
 		// If known, encode and queue for response packet
@@ -479,6 +515,8 @@ func handlePooledTransactions(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(&txs); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	peer.latency.received(PooledTransactionsMsg, 0)
+
 	for i, tx := range txs {
 		// Validate and mark the remote transaction
 		if tx == nil {
@@ -499,6 +537,8 @@ func handlePooledTransactions66(backend Backend, msg Decoder, peer *Peer) error
 	if err := msg.Decode(&txs); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	peer.latency.received(PooledTransactionsMsg, txs.RequestId)
+
 	for i, tx := range txs.PooledTransactionsPacket {
 		// Validate and mark the remote transaction
 		if tx == nil {
@@ -100,6 +100,8 @@ func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.H
 	if status.Genesis != genesis {
 		return fmt.Errorf("%w: %x (!= %x)", errGenesisMismatch, status.Genesis, genesis)
 	}
+	// The fork ID also separates peers disagreeing on the header extensions, as
+	// their activation blocks are included in it.
 	if err := forkFilter(status.ForkID); err != nil {
 		return fmt.Errorf("%w: %v", errForkIDRejected, err)
 	}
@@ -0,0 +1,216 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common/mclock"
+	"github.com/c88032111/go-gdtu/metrics"
+)
+
+const (
+	// latencySamples is the number of most recent round trips kept per request
+	// type of a peer to estimate the percentiles from.
+	latencySamples = 64
+
+	// maxPendingRequests is the maximum number of unanswered requests tracked per
+	// request type of a peer, before forgetting the oldest ones.
+	maxPendingRequests = 256
+
+	// pendingRequestTimeout is the time after which an unanswered request isn't
+	// tracked any more, as the remote peer most probably dropped it.
+	pendingRequestTimeout = time.Minute
+)
+
+// latencyKinds maps the request message codes to the names of the request types
+// whose round trips are measured.
+var latencyKinds = map[uint64]string{
+	GetBlockHeadersMsg:       "headers",
+	GetBlockBodiesMsg:        "bodies",
+	GetReceiptsMsg:           "receipts",
+	GetNodeDataMsg:           "nodedata",
+	GetPooledTransactionsMsg: "pooledtxs",
+}
+
+// LatencyKinds is the list of request type names whose round trips are measured.
+var LatencyKinds = []string{"headers", "bodies", "receipts", "nodedata", "pooledtxs"}
+
+// responseRequests maps the response message codes to those of their requests.
+var responseRequests = map[uint64]uint64{
+	BlockHeadersMsg:       GetBlockHeadersMsg,
+	BlockBodiesMsg:        GetBlockBodiesMsg,
+	ReceiptsMsg:           GetReceiptsMsg,
+	NodeDataMsg:           GetNodeDataMsg,
+	PooledTransactionsMsg: GetPooledTransactionsMsg,
+}
+
+// latencyHistograms are the round trip histograms of all peers per request type.
+var latencyHistograms = make(map[uint64]metrics.Histogram)
+
+func init() {
+	for code, kind := range latencyKinds {
+		latencyHistograms[code] = metrics.NewRegisteredHistogram("gdtu/protocols/gdtu/rtt/"+kind, nil, metrics.NewExpDecaySample(1028, 0.015))
+	}
+}
+
+// RequestLatency is the summary of the round trips of one type of requests sent
+// to a peer, in milliseconds.
+type RequestLatency struct {
+	Count   uint64  `json:"count"`   // Number of responses received
+	Pending int     `json:"pending"` // Number of requests awaiting a response
+	Mean    float64 `json:"mean"`    // Mean round trip of the recent responses
+	Median  float64 `json:"median"`  // Median round trip of the recent responses
+	P95     float64 `json:"p95"`     // 95th percentile round trip of the recent responses
+	Max     float64 `json:"max"`     // Longest round trip of the recent responses
+}
+
+// pendingRequest is a request sent to a peer awaiting its response.
+type pendingRequest struct {
+	id   uint64 // Request id of gdtu/66 requests, zero before
+	sent mclock.AbsTime
+}
+
+// requestLatency tracks the round trips of one type of requests sent to a peer.
+type requestLatency struct {
+	pending []pendingRequest              // Unanswered requests in the order sent
+	samples [latencySamples]time.Duration // Ring buffer of the recent round trips
+	count   uint64                        // Number of responses received
+}
+
+// latencyTracker measures the round trips of the requests sent to a peer, per
+// request type. Responses of gdtu/66 are matched to their requests by id, older
+// protocol versions are assumed to answer in order.
+type latencyTracker struct {
+	clock mclock.Clock
+	kinds map[uint64]*requestLatency
+	lock  sync.Mutex
+}
+
+// newLatencyTracker creates a round trip tracker for a peer.
+func newLatencyTracker(clock mclock.Clock) *latencyTracker {
+	t := &latencyTracker{
+		clock: clock,
+		kinds: make(map[uint64]*requestLatency),
+	}
+	for code := range latencyKinds {
+		t.kinds[code] = new(requestLatency)
+	}
+	return t
+}
+
+// sent records a request with the given message code being sent.
+func (t *latencyTracker) sent(code uint64, id uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	kind, ok := t.kinds[code]
+	if !ok {
+		return
+	}
+	now := t.clock.Now()
+	for len(kind.pending) > 0 && (len(kind.pending) >= maxPendingRequests || time.Duration(now-kind.pending[0].sent) > pendingRequestTimeout) {
+		kind.pending = kind.pending[1:]
+	}
+	kind.pending = append(kind.pending, pendingRequest{id: id, sent: now})
+}
+
+// failed forgets a request with the given message code which couldn't be sent.
+func (t *latencyTracker) failed(code uint64, id uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if kind, ok := t.kinds[code]; ok {
+		kind.remove(id)
+	}
+}
+
+// received records a response with the given message code, measuring the round
+// trip of the matching request, if any.
+func (t *latencyTracker) received(code uint64, id uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	code, ok := responseRequests[code]
+	if !ok {
+		return
+	}
+	kind := t.kinds[code]
+	req, ok := kind.remove(id)
+	if !ok {
+		return
+	}
+	rtt := time.Duration(t.clock.Now() - req.sent)
+	kind.samples[kind.count%latencySamples] = rtt
+	kind.count++
+
+	latencyHistograms[code].Update(rtt.Microseconds())
+}
+
+// remove drops the pending request with the given id, or the oldest one for
+// requests without ids.
+func (kind *requestLatency) remove(id uint64) (pendingRequest, bool) {
+	for i, req := range kind.pending {
+		if req.id == id {
+			kind.pending = append(kind.pending[:i:i], kind.pending[i+1:]...)
+			return req, true
+		}
+	}
+	return pendingRequest{}, false
+}
+
+// summary returns the round trip statistics of the request type.
+func (kind *requestLatency) summary() *RequestLatency {
+	n := kind.count
+	if n > latencySamples {
+		n = latencySamples
+	}
+	summary := &RequestLatency{Count: kind.count, Pending: len(kind.pending)}
+	if n == 0 {
+		return summary
+	}
+	samples := make([]time.Duration, n)
+	copy(samples, kind.samples[:n])
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	var total time.Duration
+	for _, rtt := range samples {
+		total += rtt
+	}
+	millis := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+
+	summary.Mean = millis(total / time.Duration(n))
+	summary.Median = millis(samples[n/2])
+	summary.P95 = millis(samples[(n*95-1)/100])
+	summary.Max = millis(samples[n-1])
+	return summary
+}
+
+// summary returns the round trip statistics of all request types, keyed by the
+// request type names.
+func (t *latencyTracker) summary() map[string]*RequestLatency {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	summary := make(map[string]*RequestLatency, len(t.kinds))
+	for code, kind := range t.kinds {
+		summary[latencyKinds[code]] = kind.summary()
+	}
+	return summary
+}
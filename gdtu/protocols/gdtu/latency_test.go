@@ -0,0 +1,79 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common/mclock"
+)
+
+func TestLatencyTracker(t *testing.T) {
+	clock := new(mclock.Simulated)
+	tracker := newLatencyTracker(clock)
+
+	// Responses with request ids are matched even if answered out of order
+	tracker.sent(GetBlockHeadersMsg, 1)
+	clock.Run(10 * time.Millisecond)
+	tracker.sent(GetBlockHeadersMsg, 2)
+	clock.Run(10 * time.Millisecond)
+	tracker.received(BlockHeadersMsg, 2)
+	clock.Run(20 * time.Millisecond)
+	tracker.received(BlockHeadersMsg, 1)
+	tracker.received(BlockHeadersMsg, 3) // unsolicited
+
+	headers := tracker.summary()["headers"]
+	if headers.Count != 2 || headers.Pending != 0 {
+		t.Fatalf("wrong header request counts: %d responses, %d pending", headers.Count, headers.Pending)
+	}
+	if headers.Median != 40 || headers.Max != 40 || headers.Mean != 25 {
+		t.Fatalf("wrong header round trips: mean %v, median %v, max %v", headers.Mean, headers.Median, headers.Max)
+	}
+	// Responses without request ids are matched in order, skipping failed sends
+	tracker.sent(GetBlockBodiesMsg, 0)
+	tracker.sent(GetReceiptsMsg, 0)
+	tracker.failed(GetReceiptsMsg, 0)
+	clock.Run(30 * time.Millisecond)
+	tracker.sent(GetBlockBodiesMsg, 0)
+	clock.Run(5 * time.Millisecond)
+	tracker.received(BlockBodiesMsg, 0)
+
+	summary := tracker.summary()
+	if bodies := summary["bodies"]; bodies.Count != 1 || bodies.Pending != 1 || bodies.Max != 35 {
+		t.Fatalf("wrong body round trips: %d responses, %d pending, max %v", bodies.Count, bodies.Pending, bodies.Max)
+	}
+	if receipts := summary["receipts"]; receipts.Count != 0 || receipts.Pending != 0 {
+		t.Fatalf("failed receipt request tracked: %d responses, %d pending", receipts.Count, receipts.Pending)
+	}
+	// Unanswered requests are forgotten after a while
+	clock.Run(2 * pendingRequestTimeout)
+	tracker.sent(GetBlockBodiesMsg, 0)
+	if bodies := tracker.summary()["bodies"]; bodies.Pending != 1 {
+		t.Fatalf("stale body request not forgotten: %d pending", bodies.Pending)
+	}
+	// Percentiles are computed over the recent round trips only
+	for i := 0; i < 2*latencySamples; i++ {
+		tracker.sent(GetNodeDataMsg, uint64(i))
+		clock.Run(time.Duration(i) * time.Millisecond)
+		tracker.received(NodeDataMsg, uint64(i))
+	}
+	nodes := tracker.summary()["nodedata"]
+	if nodes.Count != 2*latencySamples || nodes.Median != 96 || nodes.P95 != 124 || nodes.Max != 127 {
+		t.Fatalf("wrong node data round trips: %d responses, median %v, p95 %v, max %v", nodes.Count, nodes.Median, nodes.P95, nodes.Max)
+	}
+}
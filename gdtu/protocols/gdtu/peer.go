@@ -22,6 +22,7 @@ import (
 	"sync"
 
 	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/mclock"
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/p2p"
 	"github.com/c88032111/go-gdtu/rlp"
@@ -84,6 +85,11 @@ type Peer struct {
 	txBroadcast chan []common.Hash // Channel used to queue transaction propagation requests
 	txAnnounce  chan []common.Hash // Channel used to queue transaction announcement requests
 
+	latency *latencyTracker // Round trips of the requests sent to the peer
+
+	dispatched   map[uint64]*Request // Requests awaiting a response routed by id
+	dispatchLock sync.Mutex          // Mutex protecting the dispatched requests
+
 	term chan struct{} // Termination channel to stop the broadcasters
 	lock sync.RWMutex  // Mutex protecting the internal fields
 }
@@ -103,6 +109,8 @@ func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool) *Pe
 		txBroadcast:     make(chan []common.Hash),
 		txAnnounce:      make(chan []common.Hash),
 		txpool:          txpool,
+		latency:         newLatencyTracker(mclock.System{}),
+		dispatched:      make(map[uint64]*Request),
 		term:            make(chan struct{}),
 	}
 	// Start up all the broadcasters
@@ -402,6 +410,23 @@ func (p *Peer) ReplyReceiptsRLP(id uint64, receipts []rlp.RawValue) error {
 	})
 }
 
+// sendRequest sends a request to the peer, tracking it to measure the round trip
+// of its response.
+func (p *Peer) sendRequest(code uint64, id uint64, data interface{}) error {
+	p.latency.sent(code, id)
+	if err := p2p.Send(p.rw, code, data); err != nil {
+		p.latency.failed(code, id)
+		return err
+	}
+	return nil
+}
+
+// RequestLatencies returns the round trip statistics of the requests sent to the
+// peer, keyed by request type.
+func (p *Peer) RequestLatencies() map[string]*RequestLatency {
+	return p.latency.summary()
+}
+
 // RequestOneHeader is a wrapper around the header query functions to fetch a
 // single header. It is used solely by the fetcher.
 func (p *Peer) RequestOneHeader(hash common.Hash) error {
@@ -413,12 +438,13 @@ func (p *Peer) RequestOneHeader(hash common.Hash) error {
 		Reverse: false,
 	}
 	if p.Version() >= GDTU34 {
-		return p2p.Send(p.rw, GetBlockHeadersMsg, &GetBlockHeadersPacket66{
-			RequestId:             rand.Uint64(),
+		id := rand.Uint64()
+		return p.sendRequest(GetBlockHeadersMsg, id, &GetBlockHeadersPacket66{
+			RequestId:             id,
 			GetBlockHeadersPacket: &query,
 		})
 	}
-	return p2p.Send(p.rw, GetBlockHeadersMsg, &query)
+	return p.sendRequest(GetBlockHeadersMsg, 0, &query)
 }
 
 // RequestHeadersByHash fetches a batch of blocks' headers corresponding to the
@@ -432,12 +458,13 @@ func (p *Peer) RequestHeadersByHash(origin common.Hash, amount int, skip int, re
 		Reverse: reverse,
 	}
 	if p.Version() >= GDTU34 {
-		return p2p.Send(p.rw, GetBlockHeadersMsg, &GetBlockHeadersPacket66{
-			RequestId:             rand.Uint64(),
+		id := rand.Uint64()
+		return p.sendRequest(GetBlockHeadersMsg, id, &GetBlockHeadersPacket66{
+			RequestId:             id,
 			GetBlockHeadersPacket: &query,
 		})
 	}
-	return p2p.Send(p.rw, GetBlockHeadersMsg, &query)
+	return p.sendRequest(GetBlockHeadersMsg, 0, &query)
 }
 
 // RequestHeadersByNumber fetches a batch of blocks' headers corresponding to the
@@ -451,12 +478,13 @@ func (p *Peer) RequestHeadersByNumber(origin uint64, amount int, skip int, rever
 		Reverse: reverse,
 	}
 	if p.Version() >= GDTU34 {
-		return p2p.Send(p.rw, GetBlockHeadersMsg, &GetBlockHeadersPacket66{
-			RequestId:             rand.Uint64(),
+		id := rand.Uint64()
+		return p.sendRequest(GetBlockHeadersMsg, id, &GetBlockHeadersPacket66{
+			RequestId:             id,
 			GetBlockHeadersPacket: &query,
 		})
 	}
-	return p2p.Send(p.rw, GetBlockHeadersMsg, &query)
+	return p.sendRequest(GetBlockHeadersMsg, 0, &query)
 }
 
 // ExpectRequestHeadersByNumber is a testing Method to mirror the recipient side
@@ -476,12 +504,13 @@ func (p *Peer) ExpectRequestHeadersByNumber(origin uint64, amount int, skip int,
 func (p *Peer) RequestBodies(hashes []common.Hash) error {
 	p.Log().Debug("Fetching batch of block bodies", "count", len(hashes))
 	if p.Version() >= GDTU34 {
-		return p2p.Send(p.rw, GetBlockBodiesMsg, &GetBlockBodiesPacket66{
-			RequestId:            rand.Uint64(),
+		id := rand.Uint64()
+		return p.sendRequest(GetBlockBodiesMsg, id, &GetBlockBodiesPacket66{
+			RequestId:            id,
 			GetBlockBodiesPacket: hashes,
 		})
 	}
-	return p2p.Send(p.rw, GetBlockBodiesMsg, GetBlockBodiesPacket(hashes))
+	return p.sendRequest(GetBlockBodiesMsg, 0, GetBlockBodiesPacket(hashes))
 }
 
 // RequestNodeData fetches a batch of arbitrary data from a node's known state
@@ -489,34 +518,37 @@ func (p *Peer) RequestBodies(hashes []common.Hash) error {
 func (p *Peer) RequestNodeData(hashes []common.Hash) error {
 	p.Log().Debug("Fetching batch of state data", "count", len(hashes))
 	if p.Version() >= GDTU34 {
-		return p2p.Send(p.rw, GetNodeDataMsg, &GetNodeDataPacket66{
-			RequestId:         rand.Uint64(),
+		id := rand.Uint64()
+		return p.sendRequest(GetNodeDataMsg, id, &GetNodeDataPacket66{
+			RequestId:         id,
 			GetNodeDataPacket: hashes,
 		})
 	}
-	return p2p.Send(p.rw, GetNodeDataMsg, GetNodeDataPacket(hashes))
+	return p.sendRequest(GetNodeDataMsg, 0, GetNodeDataPacket(hashes))
 }
 
 // RequestReceipts fetches a batch of transaction receipts from a remote node.
 func (p *Peer) RequestReceipts(hashes []common.Hash) error {
 	p.Log().Debug("Fetching batch of receipts", "count", len(hashes))
 	if p.Version() >= GDTU34 {
-		return p2p.Send(p.rw, GetReceiptsMsg, &GetReceiptsPacket66{
-			RequestId:         rand.Uint64(),
+		id := rand.Uint64()
+		return p.sendRequest(GetReceiptsMsg, id, &GetReceiptsPacket66{
+			RequestId:         id,
 			GetReceiptsPacket: hashes,
 		})
 	}
-	return p2p.Send(p.rw, GetReceiptsMsg, GetReceiptsPacket(hashes))
+	return p.sendRequest(GetReceiptsMsg, 0, GetReceiptsPacket(hashes))
 }
 
 // RequestTxs fetches a batch of transactions from a remote node.
 func (p *Peer) RequestTxs(hashes []common.Hash) error {
 	p.Log().Debug("Fetching batch of transactions", "count", len(hashes))
 	if p.Version() >= GDTU34 {
-		return p2p.Send(p.rw, GetPooledTransactionsMsg, &GetPooledTransactionsPacket66{
-			RequestId:                   rand.Uint64(),
+		id := rand.Uint64()
+		return p.sendRequest(GetPooledTransactionsMsg, id, &GetPooledTransactionsPacket66{
+			RequestId:                   id,
 			GetPooledTransactionsPacket: hashes,
 		})
 	}
-	return p2p.Send(p.rw, GetPooledTransactionsMsg, GetPooledTransactionsPacket(hashes))
+	return p.sendRequest(GetPooledTransactionsMsg, 0, GetPooledTransactionsPacket(hashes))
 }
@@ -266,3 +266,29 @@ func TestGdtu66Messages(t *testing.T) {
 		}
 	}
 }
+
+// Tests that headers carrying fork extension fields are relayed unmodified in
+// header packets, keeping their hash.
+func TestBlockHeadersExtensions(t *testing.T) {
+	header := &types.Header{
+		Difficulty: big.NewInt(2222),
+		Number:     big.NewInt(3333),
+		GasLimit:   4444,
+		Extra:      []byte{0x77, 0x88},
+		Extensions: []rlp.RawValue{{0x82, 0x11, 0x22}, {0xc1, 0x01}},
+	}
+	enc, err := rlp.EncodeToBytes(BlockHeadersPacket66{1111, BlockHeadersPacket{header}})
+	if err != nil {
+		t.Fatalf("failed to encode packet: %v", err)
+	}
+	var packet BlockHeadersPacket66
+	if err := rlp.DecodeBytes(enc, &packet); err != nil {
+		t.Fatalf("failed to decode packet: %v", err)
+	}
+	if len(packet.BlockHeadersPacket) != 1 {
+		t.Fatalf("header count mismatch: have %d, want 1", len(packet.BlockHeadersPacket))
+	}
+	if have := packet.BlockHeadersPacket[0]; have.Hash() != header.Hash() || len(have.Extensions) != 2 {
+		t.Fatalf("relayed header mismatch: hash %x, %d extensions", have.Hash(), len(have.Extensions))
+	}
+}
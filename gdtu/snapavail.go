@@ -0,0 +1,204 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+	"github.com/c88032111/go-gdtu/p2p/enr"
+)
+
+const (
+	// snapAvailabilityDepth is the number of recent blocks whose state must be
+	// fully present for the node to serve the snap protocol.
+	snapAvailabilityDepth = 8
+
+	// snapAvailabilityRecheck is the interval at which the state availability
+	// is re-evaluated.
+	snapAvailabilityRecheck = 30 * time.Second
+)
+
+// SnapAvailability is the outcome of a state availability check, reporting
+// whgdtuer the node is able to serve the snap protocol.
+type SnapAvailability struct {
+	Available bool        `json:"available"`
+	Reason    string      `json:"reason,omitempty"` // Why the state is unavailable
+	Number    uint64      `json:"number"`           // Head block at the time of the check
+	Root      common.Hash `json:"root"`             // State root of the head block
+	Checked   time.Time   `json:"checked"`
+}
+
+// snapAvailability tracks whgdtuer the local state is complete enough to be
+// served over the snap protocol. Nodes which are still syncing, healing or
+// generating their snapshot only have parts of the state, so peers would waste
+// their retries on them. While the state is unavailable, the snap capability
+// is withheld from new connections and from the local node record.
+type snapAvailability struct {
+	handler *handler
+	attrs   []enr.Entry // Node record entries of the snap protocol
+
+	available uint32 // Flag whgdtuer the state is available (atomic access)
+
+	lock   sync.Mutex
+	local  *enode.LocalNode // Local node record to update, nil until the node is started
+	status *SnapAvailability
+}
+
+// newSnapAvailability creates a state availability tracker for the handler and
+// runs an initial check.
+func newSnapAvailability(h *handler) *snapAvailability {
+	s := &snapAvailability{handler: h}
+	s.update()
+	return s
+}
+
+// Available reports whgdtuer the state was available at the last check.
+func (s *snapAvailability) Available() bool {
+	return atomic.LoadUint32(&s.available) == 1
+}
+
+// Negotiable reports whgdtuer the snap protocol should be offered to peers. Nodes
+// snap syncing themselves still need it to retrieve the state, they just can't
+// serve it yet.
+func (s *snapAvailability) Negotiable() bool {
+	return s.Available() || atomic.LoadUint32(&s.handler.snapSync) == 1
+}
+
+// Status returns the outcome of the last check.
+func (s *snapAvailability) Status() SnapAvailability {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return *s.status
+}
+
+// advertise sets the local node record in which the snap protocol entries are
+// published while the state is available.
+func (s *snapAvailability) advertise(local *enode.LocalNode, attrs []enr.Entry) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.local, s.attrs = local, attrs
+	s.updateRecord(s.status.Available)
+}
+
+// loop periodically re-evaluates the state availability until quit is closed.
+func (s *snapAvailability) loop(quit chan struct{}) {
+	defer s.handler.wg.Done()
+
+	ticker := time.NewTicker(snapAvailabilityRecheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.update()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// update runs a state availability check and applies the outcome.
+func (s *snapAvailability) update() {
+	status := s.check()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.status != nil && s.status.Available != status.Available {
+		if status.Available {
+			log.Info("State available, serving snap", "number", status.Number, "root", status.Root)
+		} else {
+			log.Info("State unavailable, withholding snap", "reason", status.Reason)
+		}
+		s.updateRecord(status.Available)
+	}
+	s.status = status
+	if status.Available {
+		atomic.StoreUint32(&s.available, 1)
+	} else {
+		atomic.StoreUint32(&s.available, 0)
+	}
+}
+
+// updateRecord adds or removes the snap entries of the local node record. The
+// lock is assumed to be held.
+func (s *snapAvailability) updateRecord(available bool) {
+	if s.local == nil {
+		return
+	}
+	for _, e := range s.attrs {
+		if available {
+			s.local.Set(e)
+		} else {
+			s.local.Delete(e)
+		}
+	}
+}
+
+// check evaluates whgdtuer the state of the recent blocks is complete.
+func (s *snapAvailability) check() *SnapAvailability {
+	var (
+		chain  = s.handler.chain
+		head   = chain.CurrentBlock()
+		status = &SnapAvailability{
+			Number:  head.NumberU64(),
+			Root:    head.Root(),
+			Checked: time.Now(),
+		}
+	)
+	snaps := chain.Snapshots()
+	if snaps == nil {
+		status.Reason = "snapshots disabled"
+		return status
+	}
+	if atomic.LoadUint32(&s.handler.fastSync) == 1 {
+		status.Reason = "state sync in progress"
+		return status
+	}
+	if generating, err := snaps.Generating(); err != nil {
+		status.Reason = fmt.Sprintf("snapshot unusable: %v", err)
+		return status
+	} else if generating {
+		status.Reason = "snapshot generation in progress"
+		return status
+	}
+	for i := uint64(0); i < snapAvailabilityDepth && i <= head.NumberU64(); i++ {
+		header := chain.GetHeaderByNumber(head.NumberU64() - i)
+		if header == nil {
+			status.Reason = fmt.Sprintf("missing header #%d", head.NumberU64()-i)
+			return status
+		}
+		if !chain.HasState(header.Root) {
+			status.Reason = fmt.Sprintf("missing state of block #%d", header.Number)
+			return status
+		}
+		if snaps.Snapshot(header.Root) == nil {
+			status.Reason = fmt.Sprintf("missing snapshot of block #%d", header.Number)
+			return status
+		}
+	}
+	status.Available = true
+	return status
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/consensus/gdtuash"
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/gdtu/downloader"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+	"github.com/c88032111/go-gdtu/p2p/enr"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// testSnapEntry is a node record entry standing in for the snap protocol one.
+type testSnapEntry struct{}
+
+func (testSnapEntry) ENRKey() string { return "snap" }
+
+// Tests that the snap availability reflects whgdtuer the node has the state of
+// the recent blocks.
+func TestSnapAvailability(t *testing.T) {
+	// A node which still needs to sync its state can't serve it
+	syncing := newTestHandler()
+	defer syncing.close()
+
+	if status := syncing.handler.snapAvail.Status(); status.Available || status.Reason != "state sync in progress" {
+		t.Fatalf("syncing node: have available %v (%s), want unavailable", status.Available, status.Reason)
+	}
+	// A node with its state and snapshot in place can serve it
+	synced := newTestHandlerWithBlocks(16)
+	defer synced.close()
+
+	avail := synced.handler.snapAvail
+	avail.update()
+	if status := avail.Status(); !status.Available || status.Number != 16 {
+		t.Fatalf("synced node: have available %v (%s) at #%d, want available at #16", status.Available, status.Reason, status.Number)
+	}
+	if !avail.Available() {
+		t.Fatalf("synced node: availability flag not set")
+	}
+	// A node without snapshots can't serve snap
+	db := rawdb.NewMemoryDatabase()
+	(&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	chain, _ := core.NewBlockChain(db, &core.CacheConfig{TrieCleanLimit: 16, TrieDirtyLimit: 16}, params.TestChainConfig, gdtuash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	handler, _ := newHandler(&handlerConfig{
+		Database:   db,
+		Chain:      chain,
+		TxPool:     newTestTxPool(),
+		Network:    1,
+		Sync:       downloader.FullSync,
+		BloomCache: 1,
+	})
+	if status := handler.snapAvail.Status(); status.Available || status.Reason != "snapshots disabled" {
+		t.Fatalf("snapshotless node: have available %v (%s), want unavailable", status.Available, status.Reason)
+	}
+}
+
+// Tests that a node snap syncing its state still negotiates the snap protocol,
+// even though it can't serve it yet.
+func TestSnapAvailabilitySnapSyncing(t *testing.T) {
+	syncing := newTestHandler()
+	defer syncing.close()
+
+	avail := syncing.handler.snapAvail
+	if avail.Available() || avail.Negotiable() {
+		t.Fatalf("full syncing node: have available %v, negotiable %v, want neither", avail.Available(), avail.Negotiable())
+	}
+	atomic.StoreUint32(&syncing.handler.snapSync, 1)
+	if avail.Available() {
+		t.Fatalf("snap syncing node: state reported available")
+	}
+	if !avail.Negotiable() {
+		t.Fatalf("snap syncing node: snap protocol not negotiable")
+	}
+}
+
+// Tests that the snap entries of the node record follow the availability.
+func TestSnapAvailabilityRecord(t *testing.T) {
+	synced := newTestHandlerWithBlocks(1)
+	defer synced.close()
+
+	key, _ := crypto.GenerateKey()
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+	local := enode.NewLocalNode(db, key)
+
+	avail := synced.handler.snapAvail
+	avail.update()
+	avail.advertise(local, []enr.Entry{testSnapEntry{}})
+	if local.Node().Load(enr.WithEntry("snap", new(struct{}))) != nil {
+		t.Fatalf("snap entry missing while available")
+	}
+	// Force the state to become unavailable and check that the entry is dropped
+	atomic.StoreUint32(&synced.handler.fastSync, 1)
+	avail.update()
+	if local.Node().Load(enr.WithEntry("snap", new(struct{}))) == nil {
+		t.Fatalf("snap entry present while unavailable")
+	}
+}
@@ -56,7 +56,12 @@ func (h *handler) syncTransactions(p *gdtu.Peer) {
 	var txs types.Transactions
 	pending, _ := h.txpool.Pending()
 	for _, batch := range pending {
-		txs = append(txs, batch...)
+		for _, tx := range batch {
+			// Transactions with local inclusion conditions are never propagated
+			if tx.Conditional() == nil {
+				txs = append(txs, tx)
+			}
+		}
 	}
 	if len(txs) == 0 {
 		return
@@ -320,9 +325,18 @@ func (h *handler) doSync(op *chainSyncOp) error {
 			log.Warn("Update txLookup limit", "provided", limit, "updated", *stored)
 		}
 	}
+	// If the local chain was rolled back below the checkpoint, fill the gap
+	// backwards from it before syncing forward
+	h.backfillCheckpoint(op.peer)
+
 	// Run the sync cycle, and disable fast sync if we're past the pivot block
 	err := h.downloader.Synchronise(op.peer.ID(), op.head, op.td, op.mode)
 	if err != nil {
+		// Only count provably invalid data against the peer, slow peers aren't
+		// worth a ban
+		if downloader.IsInvalidChain(err) {
+			h.recordFailure(op.peer)
+		}
 		return err
 	}
 	if atomic.LoadUint32(&h.fastSync) == 1 {
@@ -354,3 +368,25 @@ func (h *handler) doSync(op *chainSyncOp) error {
 	}
 	return nil
 }
+
+// backfillCheckpoint fills the header gap between the trusted checkpoint and the
+// local chain backwards, if the node already froze a chain into the ancient store
+// but got rolled back below the checkpoint. Fresh nodes sync forward as usual.
+func (h *handler) backfillCheckpoint(peer *gdtu.Peer) {
+	checkpoint, ok := h.checkpointHeader.Load().(*types.Header)
+	if !ok || h.chain.HasHeader(checkpoint.Hash(), checkpoint.Number.Uint64()) {
+		return
+	}
+	if h.chain.CurrentHeader().Number.Uint64() >= checkpoint.Number.Uint64() {
+		return
+	}
+	if frozen, err := h.database.Ancients(); err != nil || frozen == 0 {
+		return
+	}
+	if err := h.downloader.BackfillHeaders(peer.ID(), checkpoint); err != nil {
+		log.Warn("Checkpoint header backfill failed", "peer", peer.ID(), "err", err)
+		if downloader.IsInvalidChain(err) {
+			h.recordFailure(peer)
+		}
+	}
+}
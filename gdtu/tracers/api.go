@@ -75,6 +75,10 @@ type Backend interface {
 // API is the collection of tracing APIs exposed over the private debugging endpoint.
 type API struct {
 	backend Backend
+
+	debugLock     sync.Mutex
+	debugSessions map[rpc.ID]*debugSession // Transactions being debugged
+	debugPending  int                      // Debug sessions being started, holding a slot
 }
 
 // NewAPI creates a new API definition for the tracing Methods of the Gdtu service.
@@ -744,7 +748,7 @@ func (api *API) TraceCall(ctx context.Context, args gdtuapi.CallArgs, blockNrOrH
 // executes the given message in the provided environment. The return value will
 // be tracer dependent.
 func (api *API) traceTx(ctx context.Context, message core.Message, txctx *txTraceContext, vmctx vm.BlockContext, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
-	// Assemble the structured logger or the JavaScript tracer
+	// Assemble the structured logger or the native/JavaScript tracer
 	var (
 		tracer    vm.Tracer
 		err       error
@@ -759,15 +763,23 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *txTrac
 				return nil, err
 			}
 		}
-		// Constuct the JavaScript tracer to execute with
-		if tracer, err = New(*config.Tracer, txContext); err != nil {
+		// Constuct the native or JavaScript tracer to execute with
+		var stoppable NativeTracer
+		if ctor, ok := nativeTracer(*config.Tracer); ok {
+			stoppable, err = ctor(txContext)
+		} else {
+			stoppable, err = New(*config.Tracer, txContext)
+		}
+		if err != nil {
 			return nil, err
 		}
+		tracer = stoppable
+
 		// Handle timeouts and RPC cancellations
 		deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
 		go func() {
 			<-deadlineCtx.Done()
-			tracer.(*Tracer).Stop(errors.New("execution timeout"))
+			stoppable.Stop(errors.New("execution timeout"))
 		}()
 		defer cancel()
 
@@ -803,7 +815,7 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *txTrac
 			StructLogs:  gdtuapi.FormatLogs(tracer.StructLogs()),
 		}, nil
 
-	case *Tracer:
+	case NativeTracer:
 		return tracer.GetResult()
 
 	default:
@@ -0,0 +1,262 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/rpc"
+)
+
+const (
+	// defaultDebugStepLimit is the number of steps a debugged transaction is
+	// continued for at most if no other limit is given.
+	defaultDebugStepLimit = 10000
+
+	// maxDebugStepLimit is the maximum number of steps a debugged transaction
+	// can be resumed for in a single call.
+	maxDebugStepLimit = 100000
+
+	// maxDebugSessions is the number of transactions that can be debugged
+	// concurrently, each of them holding on to a state.
+	maxDebugSessions = 16
+
+	// debugSessionTimeout is the time after which an idle debug session is
+	// stopped.
+	debugSessionTimeout = 5 * time.Minute
+)
+
+var (
+	errDebugSessionNotFound = errors.New("debug session not found")
+	errTooManyDebugSessions = errors.New("too many debug sessions")
+)
+
+// DebugBreakpoint is a condition pausing a debugged transaction. All the given
+// fields of a breakpoint have to match.
+type DebugBreakpoint struct {
+	Address *common.Address `json:"address"` // Executing contract
+	PC      *uint64         `json:"pc"`      // Program counter
+	Op      *string         `json:"op"`      // Operation name, e.g. SSTORE
+	Slot    *common.Hash    `json:"slot"`    // Storage key accessed by SLOAD or SSTORE
+}
+
+// DebugConfig holds the options of a debugged transaction.
+type DebugConfig struct {
+	Breakpoints []DebugBreakpoint `json:"breakpoints"`
+	Reexec      *uint64           `json:"reexec"`
+}
+
+// DebugSessionResult is the state of a debugged transaction after it paused.
+// The session is omitted once the execution ended.
+type DebugSessionResult struct {
+	Session rpc.ID `json:"session,omitempty"`
+	*vm.DebugResult
+}
+
+// debugSession is a transaction being debugged.
+type debugSession struct {
+	debugger *vm.Debugger
+	timer    *time.Timer
+}
+
+// DebugTransaction re-executes a transaction under the control of a debugger,
+// pausing before its first operation. The execution is advanced by DebugStep
+// and DebugContinue, and stays paused between these calls, so each call reports
+// the steps executed since the previous one, as deltas of the stack, memory and
+// storage. The session ends when the execution does, on DebugStop or after five
+// minutes of inactivity.
+func (api *API) DebugTransaction(ctx context.Context, hash common.Hash, config *DebugConfig) (*DebugSessionResult, error) {
+	if config == nil {
+		config = new(DebugConfig)
+	}
+	breakpoints, err := convertBreakpoints(config.Breakpoints)
+	if err != nil {
+		return nil, err
+	}
+	// Reserve a session slot, releasing it once the session is stored or failed
+	api.debugLock.Lock()
+	if len(api.debugSessions)+api.debugPending >= maxDebugSessions {
+		api.debugLock.Unlock()
+		return nil, errTooManyDebugSessions
+	}
+	api.debugPending++
+	api.debugLock.Unlock()
+
+	defer func() {
+		api.debugLock.Lock()
+		api.debugPending--
+		api.debugLock.Unlock()
+	}()
+	_, blockHash, blockNumber, index, err := api.backend.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if blockNumber == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	reexec := defaultTraceReexec
+	if config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	block, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(blockNumber), blockHash)
+	if err != nil {
+		return nil, err
+	}
+	msg, vmctx, statedb, release, err := api.backend.StateAtTransaction(ctx, block, int(index), reexec)
+	if err != nil {
+		return nil, err
+	}
+	// Execute the transaction in the background, paused by the debugger
+	debugger := vm.NewDebugger(breakpoints)
+	go func() {
+		defer release()
+
+		vmenv := vm.NewEVM(vmctx, core.NewEVMTxContext(msg), statedb, api.backend.ChainConfig(), vm.Config{Debug: true, Tracer: debugger})
+		statedb.Prepare(hash, blockHash, int(index))
+		_, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
+		debugger.Finish(err)
+	}()
+	res := debugger.Wait()
+	if res.Reason == vm.DebugPauseDone {
+		return &DebugSessionResult{DebugResult: res}, nil
+	}
+	id := rpc.NewID()
+
+	api.debugLock.Lock()
+	defer api.debugLock.Unlock()
+	if api.debugSessions == nil {
+		api.debugSessions = make(map[rpc.ID]*debugSession)
+	}
+	api.debugSessions[id] = &debugSession{
+		debugger: debugger,
+		timer:    time.AfterFunc(debugSessionTimeout, func() { api.DebugStop(id) }),
+	}
+	return &DebugSessionResult{Session: id, DebugResult: res}, nil
+}
+
+// DebugStep executes the given number of operations of a debugged transaction,
+// one if not specified, ignoring the breakpoints.
+func (api *API) DebugStep(session rpc.ID, steps *uint64) (*DebugSessionResult, error) {
+	limit := uint64(1)
+	if steps != nil {
+		limit = *steps
+	}
+	return api.resumeDebugSession(session, limit, false)
+}
+
+// DebugContinue continues a debugged transaction until it hits a breakpoint or
+// ends, pausing after at most the given number of steps.
+func (api *API) DebugContinue(session rpc.ID, steps *uint64) (*DebugSessionResult, error) {
+	limit := uint64(defaultDebugStepLimit)
+	if steps != nil {
+		limit = *steps
+	}
+	return api.resumeDebugSession(session, limit, true)
+}
+
+// DebugSetBreakpoints replaces the breakpoints of a debugged transaction.
+func (api *API) DebugSetBreakpoints(session rpc.ID, breakpoints []DebugBreakpoint) error {
+	bps, err := convertBreakpoints(breakpoints)
+	if err != nil {
+		return err
+	}
+	s, err := api.debugSession(session)
+	if err != nil {
+		return err
+	}
+	s.debugger.SetBreakpoints(bps)
+	return nil
+}
+
+// DebugStop aborts a debugged transaction, ending its session.
+func (api *API) DebugStop(session rpc.ID) error {
+	api.debugLock.Lock()
+	s := api.debugSessions[session]
+	delete(api.debugSessions, session)
+	api.debugLock.Unlock()
+
+	if s == nil {
+		return errDebugSessionNotFound
+	}
+	s.timer.Stop()
+	s.debugger.Stop()
+	return nil
+}
+
+// debugSession retrieves a debug session, extending its lifetime.
+func (api *API) debugSession(id rpc.ID) (*debugSession, error) {
+	api.debugLock.Lock()
+	defer api.debugLock.Unlock()
+
+	s := api.debugSessions[id]
+	if s == nil {
+		return nil, errDebugSessionNotFound
+	}
+	s.timer.Reset(debugSessionTimeout)
+	return s, nil
+}
+
+// resumeDebugSession continues a debugged transaction for at most the given
+// number of steps, ending the session if the execution ends.
+func (api *API) resumeDebugSession(id rpc.ID, steps uint64, breaks bool) (*DebugSessionResult, error) {
+	if steps == 0 || steps > maxDebugStepLimit {
+		return nil, fmt.Errorf("step limit must be between 1 and %d", maxDebugStepLimit)
+	}
+	s, err := api.debugSession(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.debugger.Resume(steps, breaks)
+	if err == vm.ErrDebuggerDone {
+		return nil, errDebugSessionNotFound
+	}
+	if res.Reason != vm.DebugPauseDone {
+		return &DebugSessionResult{Session: id, DebugResult: res}, nil
+	}
+	api.debugLock.Lock()
+	delete(api.debugSessions, id)
+	api.debugLock.Unlock()
+	s.timer.Stop()
+
+	return &DebugSessionResult{DebugResult: res}, nil
+}
+
+// convertBreakpoints validates the given breakpoints and converts them to the
+// form used by the debugger.
+func convertBreakpoints(breakpoints []DebugBreakpoint) ([]vm.Breakpoint, error) {
+	bps := make([]vm.Breakpoint, len(breakpoints))
+	for i, bp := range breakpoints {
+		if bp.Address == nil && bp.PC == nil && bp.Op == nil && bp.Slot == nil {
+			return nil, fmt.Errorf("breakpoint %d: no condition", i)
+		}
+		bps[i] = vm.Breakpoint{Address: bp.Address, PC: bp.PC, Slot: bp.Slot}
+		if bp.Op != nil {
+			op := vm.StringToOp(*bp.Op)
+			if op == vm.STOP && *bp.Op != "STOP" {
+				return nil, fmt.Errorf("breakpoint %d: unknown operation %q", i, *bp.Op)
+			}
+			bps[i].Op = &op
+		}
+	}
+	return bps, nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/core/state"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/internal/gdtuapi"
+	"github.com/c88032111/go-gdtu/params"
+	"github.com/c88032111/go-gdtu/rpc"
+)
+
+// DiffConfig holds the chain configurations to compare an execution under.
+//
+// Both configs are specified as overrides on top of the chain config of the
+// node: only the fields present in the JSON object are changed, so a fork can
+// be scheduled with e.g. {"berlinBlock": 0} or disabled with {"berlinBlock": null}.
+type DiffConfig struct {
+	Base   json.RawMessage `json:"base"`   // Overrides of the reference execution (default = node config)
+	Target json.RawMessage `json:"target"` // Overrides of the compared execution (mandatory)
+	Reexec *uint64         `json:"reexec"`
+}
+
+// CallProfile is the outcome of a single execution within a diff.
+type CallProfile struct {
+	Gas         uint64        `json:"gas"`
+	Failed      bool          `json:"failed"`
+	Error       string        `json:"error,omitempty"`
+	ReturnValue hexutil.Bytes `json:"returnValue"`
+}
+
+// StateDiff is a single state entry which ended up different between the two
+// executions of a diff.
+type StateDiff struct {
+	Address common.Address `json:"address"`
+	Field   string         `json:"field"` // One of balance, nonce, code or storage
+	Key     *common.Hash   `json:"key,omitempty"`
+	Base    interface{}    `json:"base"`
+	Target  interface{}    `json:"target"`
+}
+
+// CallDiffResult is the structured difference between executing the same
+// message under two chain configurations.
+type CallDiffResult struct {
+	Base     *CallProfile       `json:"base"`
+	Target   *CallProfile       `json:"target"`
+	GasDelta int64              `json:"gasDelta"`
+	Opcodes  []vm.OpcodeGasDiff `json:"opcodes"`
+	State    []StateDiff        `json:"state"`
+}
+
+// TraceCallDiff executes the given call on top of the provided block under two
+// chain configurations and reports the differences in gas consumption and in
+// the resulting state. It is meant to help contract authors estimate the impact
+// of an upcoming fork on their contracts.
+func (api *API) TraceCallDiff(ctx context.Context, args gdtuapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *DiffConfig) (*CallDiffResult, error) {
+	if config == nil || len(config.Target) == 0 {
+		return nil, errors.New("target chain config required")
+	}
+	baseConfig, err := overrideChainConfig(api.backend.ChainConfig(), config.Base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base config: %v", err)
+	}
+	targetConfig, err := overrideChainConfig(api.backend.ChainConfig(), config.Target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target config: %v", err)
+	}
+	// Try to retrieve the specified block
+	var block *types.Block
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = api.blockByHash(ctx, hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		block, err = api.blockByNumber(ctx, number)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// try to recompute the state
+	reexec := defaultTraceReexec
+	if config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	statedb, release, err := api.backend.StateAtBlock(ctx, block, reexec)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var (
+		msg   = args.ToMessage(api.backend.RPCGasCap())
+		vmctx = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+	)
+	baseState, targetState := statedb.Copy(), statedb.Copy()
+
+	baseProf, baseRes, err := profileCall(msg, vmctx, baseState, baseConfig)
+	if err != nil {
+		return nil, fmt.Errorf("base execution failed: %v", err)
+	}
+	targetProf, targetRes, err := profileCall(msg, vmctx, targetState, targetConfig)
+	if err != nil {
+		return nil, fmt.Errorf("target execution failed: %v", err)
+	}
+	return &CallDiffResult{
+		Base:     baseRes,
+		Target:   targetRes,
+		GasDelta: int64(targetRes.Gas) - int64(baseRes.Gas),
+		Opcodes:  vm.DiffGasProfiles(baseProf, targetProf),
+		State:    diffStates(baseState, baseProf, targetState, targetProf),
+	}, nil
+}
+
+// overrideChainConfig creates a deep copy of the given chain config with the
+// JSON encoded overrides applied on top.
+func overrideChainConfig(config *params.ChainConfig, overrides json.RawMessage) (*params.ChainConfig, error) {
+	// Round trip through JSON to avoid sharing the fork block numbers
+	blob, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	cpy := new(params.ChainConfig)
+	if err := json.Unmarshal(blob, cpy); err != nil {
+		return nil, err
+	}
+	if len(overrides) > 0 {
+		if err := json.Unmarshal(overrides, cpy); err != nil {
+			return nil, err
+		}
+	}
+	if err := cpy.CheckConfigForkOrder(); err != nil {
+		return nil, err
+	}
+	return cpy, nil
+}
+
+// profileCall executes the given message with a gas profiler attached, leaving
+// the resulting changes in the provided state database.
+func profileCall(msg core.Message, vmctx vm.BlockContext, statedb *state.StateDB, config *params.ChainConfig) (*vm.GasProfiler, *CallProfile, error) {
+	profiler := vm.NewGasProfiler(vmctx.Coinbase)
+	vmenv := vm.NewEVM(vmctx, core.NewEVMTxContext(msg), statedb, config, vm.Config{Debug: true, Tracer: profiler})
+
+	result, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
+	if err != nil {
+		return nil, nil, err
+	}
+	res := &CallProfile{
+		Gas:         result.UsedGas,
+		Failed:      result.Failed(),
+		ReturnValue: result.Return(),
+	}
+	if result.Err != nil {
+		res.Error = result.Err.Error()
+		if len(result.Revert()) > 0 {
+			res.ReturnValue = result.Revert()
+		}
+	}
+	return profiler, res, nil
+}
+
+// diffStates compares the accounts and storage slots touched by either of the
+// two executions and returns the entries that ended up different.
+func diffStates(base *state.StateDB, baseProf *vm.GasProfiler, target *state.StateDB, targetProf *vm.GasProfiler) []StateDiff {
+	var (
+		diffs []StateDiff
+		seen  = make(map[common.Address]struct{})
+	)
+	for _, addr := range append(baseProf.Accounts(), targetProf.Accounts()...) {
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+
+		if a, b := base.GetBalance(addr), target.GetBalance(addr); a.Cmp(b) != 0 {
+			diffs = append(diffs, StateDiff{Address: addr, Field: "balance", Base: (*hexutil.Big)(a), Target: (*hexutil.Big)(b)})
+		}
+		if a, b := base.GetNonce(addr), target.GetNonce(addr); a != b {
+			diffs = append(diffs, StateDiff{Address: addr, Field: "nonce", Base: hexutil.Uint64(a), Target: hexutil.Uint64(b)})
+		}
+		if a, b := base.GetCodeHash(addr), target.GetCodeHash(addr); a != b {
+			diffs = append(diffs, StateDiff{Address: addr, Field: "code", Base: a, Target: b})
+		}
+		slots := make(map[common.Hash]struct{})
+		for _, slot := range append(baseProf.Slots(addr), targetProf.Slots(addr)...) {
+			if _, ok := slots[slot]; ok {
+				continue
+			}
+			slots[slot] = struct{}{}
+
+			if a, b := base.GetState(addr, slot), target.GetState(addr, slot); a != b {
+				key := slot
+				diffs = append(diffs, StateDiff{Address: addr, Field: "storage", Key: &key, Base: a, Target: b})
+			}
+		}
+	}
+	return diffs
+}
@@ -25,6 +25,7 @@ import (
 	"math/big"
 	"reflect"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -321,6 +322,121 @@ func TestTraceCall(t *testing.T) {
 	}
 }
 
+func TestTraceCallDiff(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts and a contract which reads a storage slot
+	accounts := newAccounts(1)
+	contract := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Gdtur)},
+		contract: {
+			// PUSH1 0, SLOAD, PUSH1 1, SSTORE, STOP
+			Code:    []byte{byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.PUSH1), 0x01, byte(vm.SSTORE), byte(vm.STOP)},
+			Storage: map[common.Hash]common.Hash{{}: common.BigToHash(big.NewInt(7))},
+			Balance: big.NewInt(0),
+		},
+	}}
+	api := NewAPI(newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {}))
+
+	var (
+		args = gdtuapi.CallArgs{From: &accounts[0].addr, To: &contract}
+		head = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	if _, err := api.TraceCallDiff(context.Background(), args, head, nil); err == nil {
+		t.Fatalf("expected error for missing target config")
+	}
+	result, err := api.TraceCallDiff(context.Background(), args, head, &DiffConfig{
+		Base:   []byte(`{"berlinBlock": null}`),
+		Target: []byte(`{}`),
+	})
+	if err != nil {
+		t.Fatalf("failed to diff call: %v", err)
+	}
+	if result.Base.Failed || result.Target.Failed {
+		t.Fatalf("unexpected execution failure: base %v, target %v", result.Base.Error, result.Target.Error)
+	}
+	if want := int64(result.Target.Gas) - int64(result.Base.Gas); result.GasDelta != want || want <= 0 {
+		t.Errorf("gas delta mismatch: have %d, want %d (positive)", result.GasDelta, want)
+	}
+	var sload bool
+	for _, op := range result.Opcodes {
+		if op.Op == "SLOAD" {
+			sload = true
+			if op.BaseGas != params.SloadGasEIP2200 || op.TargetGas != vm.ColdSloadCostEIP2929 {
+				t.Errorf("SLOAD gas mismatch: have %d/%d, want %d/%d", op.BaseGas, op.TargetGas, params.SloadGasEIP2200, vm.ColdSloadCostEIP2929)
+			}
+		}
+	}
+	if !sload {
+		t.Errorf("SLOAD missing from opcode diff: %v", result.Opcodes)
+	}
+	// Both executions wrote the same value, only the gas charged differs
+	for _, diff := range result.State {
+		if diff.Field == "storage" {
+			t.Errorf("unexpected storage diff: %+v", diff)
+		}
+	}
+}
+
+// Tests that the state diff covers the contracts created by the call and the
+// coinbase collecting the fees, which aren't touched by any call opcode.
+func TestTraceCallDiffCreate(t *testing.T) {
+	t.Parallel()
+
+	// Initialize a factory which endows a new, codeless contract with a value
+	// depending on the gas left after a storage read
+	accounts := newAccounts(1)
+	factory := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Gdtur)},
+		factory: {
+			// PUSH1 0, SLOAD, POP, PUSH1 0, PUSH1 0, GAS, PUSH1 0xff, AND, CREATE, STOP
+			Code: []byte{
+				byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.POP),
+				byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00,
+				byte(vm.GAS), byte(vm.PUSH1), 0xff, byte(vm.AND),
+				byte(vm.CREATE), byte(vm.STOP),
+			},
+			Balance: big.NewInt(params.Gdtur),
+		},
+	}}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0xc0})
+	})
+	api := NewAPI(backend)
+
+	var (
+		price    = (*hexutil.Big)(big.NewInt(1))
+		args     = gdtuapi.CallArgs{From: &accounts[0].addr, To: &factory, GasPrice: price}
+		head     = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		created  = crypto.CreateAddress(factory, 0)
+		coinbase = backend.chain.CurrentHeader().Coinbase
+	)
+	result, err := api.TraceCallDiff(context.Background(), args, head, &DiffConfig{
+		Base:   []byte(`{"berlinBlock": null}`),
+		Target: []byte(`{}`),
+	})
+	if err != nil {
+		t.Fatalf("failed to diff call: %v", err)
+	}
+	if result.Base.Failed || result.Target.Failed {
+		t.Fatalf("unexpected execution failure: base %v, target %v", result.Base.Error, result.Target.Error)
+	}
+	diffed := make(map[common.Address]bool)
+	for _, diff := range result.State {
+		if diff.Field == "balance" {
+			diffed[diff.Address] = true
+		}
+	}
+	if !diffed[created] {
+		t.Errorf("created contract %x missing from state diff: %+v", created, result.State)
+	}
+	if !diffed[coinbase] {
+		t.Errorf("coinbase %x missing from state diff: %+v", coinbase, result.State)
+	}
+}
+
 func TestTraceTransaction(t *testing.T) {
 	t.Parallel()
 
@@ -485,3 +601,139 @@ func newAccounts(n int) (accounts Accounts) {
 	sort.Sort(accounts)
 	return accounts
 }
+
+func TestDebugTransaction(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts and a contract adding two numbers
+	accounts := newAccounts(1)
+	contract := common.Address{0xde, 0xad}
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Gdtur)},
+		contract: {Balance: big.NewInt(0), Code: []byte{
+			byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x02, // pc 0, 2
+			byte(vm.ADD), byte(vm.POP), byte(vm.STOP), // pc 4, 5, 6
+		}},
+	}}
+	var (
+		signer  = types.HomesteadSigner{}
+		targets []common.Hash
+	)
+	api := NewAPI(newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		for nonce, to := range []common.Address{contract, contract, accounts[0].addr} {
+			tx, _ := types.SignTx(types.NewTransaction(uint64(nonce), to, big.NewInt(0), 100000, big.NewInt(0), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+			targets = append(targets, tx.Hash())
+		}
+	}))
+	// Pause on the addition and step over it
+	res, err := api.DebugTransaction(context.Background(), targets[0], nil)
+	if err != nil {
+		t.Fatalf("failed to debug transaction: %v", err)
+	}
+	if res.Session == "" || len(res.Steps) != 1 || res.Steps[0].PC != 0 {
+		t.Fatalf("initial pause mismatch: %+v", res)
+	}
+	op := "ADD"
+	if err := api.DebugSetBreakpoints(res.Session, []DebugBreakpoint{{Op: &op}}); err != nil {
+		t.Fatalf("failed to set breakpoints: %v", err)
+	}
+	cont, err := api.DebugContinue(res.Session, nil)
+	if err != nil {
+		t.Fatalf("failed to continue: %v", err)
+	}
+	if cont.Reason != vm.DebugPauseBreakpoint || len(cont.Steps) != 2 || cont.Steps[1].PC != 4 {
+		t.Fatalf("breakpoint pause mismatch: %+v", cont.DebugResult)
+	}
+	step, err := api.DebugStep(res.Session, nil)
+	if err != nil {
+		t.Fatalf("failed to step: %v", err)
+	}
+	if len(step.Steps) != 1 || step.Steps[0].OpName != "POP" || step.Steps[0].StackPops != 2 {
+		t.Fatalf("step mismatch: %+v", step.Steps)
+	}
+	end, err := api.DebugContinue(res.Session, nil)
+	if err != nil {
+		t.Fatalf("failed to continue: %v", err)
+	}
+	if end.Reason != vm.DebugPauseDone || end.Session != "" || end.Error != "" {
+		t.Fatalf("end mismatch: %+v", end.DebugResult)
+	}
+	if _, err := api.DebugStep(res.Session, nil); err != errDebugSessionNotFound {
+		t.Errorf("step error mismatch: have %v, want %v", err, errDebugSessionNotFound)
+	}
+	// Stop a session halfway
+	res, err = api.DebugTransaction(context.Background(), targets[1], nil)
+	if err != nil {
+		t.Fatalf("failed to debug transaction: %v", err)
+	}
+	if err := api.DebugStop(res.Session); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+	if _, err := api.DebugContinue(res.Session, nil); err != errDebugSessionNotFound {
+		t.Errorf("continue error mismatch: have %v, want %v", err, errDebugSessionNotFound)
+	}
+	// Transactions not executing code end right away
+	res, err = api.DebugTransaction(context.Background(), targets[2], nil)
+	if err != nil {
+		t.Fatalf("failed to debug transaction: %v", err)
+	}
+	if res.Session != "" || res.Reason != vm.DebugPauseDone || len(res.Steps) != 0 {
+		t.Errorf("transfer result mismatch: %+v", res)
+	}
+	if len(api.debugSessions) != 0 {
+		t.Errorf("sessions left: %d", len(api.debugSessions))
+	}
+}
+
+// Tests that concurrently started debug sessions don't exceed the limit.
+func TestDebugTransactionLimit(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(1)
+	contract := common.Address{0xde, 0xad}
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Gdtur)},
+		contract:         {Balance: big.NewInt(0), Code: []byte{byte(vm.PUSH1), 0x01, byte(vm.POP), byte(vm.STOP)}},
+	}}
+	var target common.Hash
+	api := NewAPI(newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(0, contract, big.NewInt(0), 100000, big.NewInt(0), nil), types.HomesteadSigner{}, accounts[0].key)
+		b.AddTx(tx)
+		target = tx.Hash()
+	}))
+	var (
+		errc = make(chan error, 2*maxDebugSessions)
+		pend sync.WaitGroup
+	)
+	for i := 0; i < 2*maxDebugSessions; i++ {
+		pend.Add(1)
+		go func() {
+			defer pend.Done()
+			_, err := api.DebugTransaction(context.Background(), target, nil)
+			errc <- err
+		}()
+	}
+	pend.Wait()
+	close(errc)
+
+	var started int
+	for err := range errc {
+		switch err {
+		case nil:
+			started++
+		case errTooManyDebugSessions:
+		default:
+			t.Fatalf("failed to debug transaction: %v", err)
+		}
+	}
+	if started == 0 || started > maxDebugSessions {
+		t.Errorf("started sessions mismatch: have %d, want 1..%d", started, maxDebugSessions)
+	}
+	if len(api.debugSessions) != started || api.debugPending != 0 {
+		t.Errorf("session accounting mismatch: %d sessions, %d pending, %d started", len(api.debugSessions), api.debugPending, started)
+	}
+	for id := range api.debugSessions {
+		api.DebugStop(id)
+	}
+}
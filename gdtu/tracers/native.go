@@ -0,0 +1,92 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/c88032111/go-gdtu/core/vm"
+)
+
+// NativeTracer is a transaction tracer implemented in Go, selectable by name in
+// the tracing APIs just like the built in JavaScript tracers, but without the
+// overhead of running the JavaScript interpreter on every opcode.
+type NativeTracer interface {
+	vm.Tracer
+
+	// GetResult returns the JSON encoded result of the trace, called once the
+	// transaction finished executing.
+	GetResult() (json.RawMessage, error)
+
+	// Stop aborts tracing with the given error. It is called from a different
+	// goroutine than the Capture methods, when the trace times out or the RPC
+	// request is cancelled.
+	Stop(err error)
+}
+
+// NativeTracerConstructor creates a new NativeTracer instance to trace a single
+// transaction.
+type NativeTracerConstructor func(txCtx vm.TxContext) (NativeTracer, error)
+
+var (
+	nativeLock sync.RWMutex
+	native     = make(map[string]NativeTracerConstructor)
+)
+
+// RegisterNativeTracer makes a native tracer available by the provided name. If
+// a JavaScript tracer with the same name exists, the native one takes precedence.
+//
+// RegisterNativeTracer panics if called twice with the same name or if the
+// constructor is nil.
+func RegisterNativeTracer(name string, ctor NativeTracerConstructor) {
+	nativeLock.Lock()
+	defer nativeLock.Unlock()
+
+	if ctor == nil {
+		panic("tracers: nil native tracer constructor for " + name)
+	}
+	if _, ok := native[name]; ok {
+		panic(fmt.Sprintf("tracers: native tracer %q registered twice", name))
+	}
+	native[name] = ctor
+}
+
+// NativeTracers returns the names of all the registered native tracers, sorted
+// alphabetically.
+func NativeTracers() []string {
+	nativeLock.RLock()
+	defer nativeLock.RUnlock()
+
+	names := make([]string, 0, len(native))
+	for name := range native {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// nativeTracer retrieves the constructor of a native tracer by name.
+func nativeTracer(name string) (NativeTracerConstructor, bool) {
+	nativeLock.RLock()
+	defer nativeLock.RUnlock()
+
+	ctor, ok := native[name]
+	return ctor, ok
+}
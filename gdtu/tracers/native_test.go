@@ -0,0 +1,133 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/core/vm"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// opCountTracer is a native tracer counting the executed opcodes.
+type opCountTracer struct {
+	gasPrice *big.Int
+	count    int
+	gasUsed  uint64
+	stopped  uint32
+}
+
+func (t *opCountTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+func (t *opCountTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	if atomic.LoadUint32(&t.stopped) == 1 {
+		env.Cancel()
+		return nil
+	}
+	t.count++
+	return nil
+}
+
+func (t *opCountTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *opCountTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) error {
+	t.gasUsed = gasUsed
+	return nil
+}
+
+func (t *opCountTracer) GetResult() (json.RawMessage, error) {
+	return json.Marshal(map[string]interface{}{
+		"count":    t.count,
+		"gasUsed":  t.gasUsed,
+		"gasPrice": t.gasPrice,
+	})
+}
+
+func (t *opCountTracer) Stop(err error) {
+	atomic.StoreUint32(&t.stopped, 1)
+}
+
+func init() {
+	RegisterNativeTracer("opCountTracer", func(txCtx vm.TxContext) (NativeTracer, error) {
+		return &opCountTracer{gasPrice: txCtx.GasPrice}, nil
+	})
+}
+
+// Tests that registered native tracers can be selected by name in the tracing
+// APIs.
+func TestNativeTracer(t *testing.T) {
+	t.Parallel()
+
+	// Deploy a contract executing a few opcodes and call it
+	accounts := newAccounts(1)
+	contract := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Gdtur)},
+		contract: {
+			Balance: big.NewInt(0),
+			Code:    []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x02, byte(vm.ADD), byte(vm.POP), byte(vm.STOP)},
+		},
+	}}
+	var target common.Hash
+	api := NewAPI(newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), contract, big.NewInt(0), 50000, big.NewInt(1), nil), types.HomesteadSigner{}, accounts[0].key)
+		b.AddTx(tx)
+		target = tx.Hash()
+	}))
+	name := "opCountTracer"
+	result, err := api.TraceTransaction(context.Background(), target, &TraceConfig{Tracer: &name})
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	var have struct {
+		Count    int    `json:"count"`
+		GasUsed  uint64 `json:"gasUsed"`
+		GasPrice int64  `json:"gasPrice"`
+	}
+	if err := json.Unmarshal(result.(json.RawMessage), &have); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if have.Count != 5 {
+		t.Errorf("opcode count mismatch: have %d, want 5", have.Count)
+	}
+	if want := uint64(3 + 3 + 3 + 2); have.GasUsed != want {
+		t.Errorf("gas used mismatch: have %d, want %d", have.GasUsed, want)
+	}
+	if have.GasPrice != 1 {
+		t.Errorf("gas price mismatch: have %d, want 1", have.GasPrice)
+	}
+	// Native tracers should be listed, JavaScript ones should still be found
+	if names := NativeTracers(); len(names) != 1 || names[0] != name {
+		t.Errorf("native tracers mismatch: have %v", names)
+	}
+	name = "callTracer"
+	if _, err := api.TraceTransaction(context.Background(), target, &TraceConfig{Tracer: &name}); err != nil {
+		t.Errorf("failed to trace with javascript tracer: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/metrics"
+)
+
+// errTxFiltered is returned by the built-in ingress filters for the transactions
+// rejected by the operator policy.
+var errTxFiltered = errors.New("transaction rejected by ingress filter")
+
+var txFilteredMeter = metrics.NewRegisteredMeter("gdtu/txfilter/rejected", nil)
+
+// TxFilter is an operator policy check run on every transaction received from the
+// network, before it's added to the transaction pool. Returning an error drops the
+// transaction. Locally submitted transactions are not filtered.
+//
+// Filters are run on the transaction fetcher's loop, so they must be fast and safe
+// for concurrent use.
+type TxFilter func(tx *types.Transaction) error
+
+// txFilters is the set of ingress transaction filters registered on the handler.
+type txFilters struct {
+	filters []TxFilter
+	lock    sync.RWMutex
+}
+
+// add registers a new ingress filter.
+func (f *txFilters) add(filter TxFilter) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.filters = append(f.filters, filter)
+}
+
+// check runs all registered filters on the transaction, returning the error of
+// the first one rejecting it.
+func (f *txFilters) check(tx *types.Transaction) error {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	for _, filter := range f.filters {
+		if err := filter(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// empty reports whgdtuer no filters are registered.
+func (f *txFilters) empty() bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return len(f.filters) == 0
+}
+
+// addRemotes filters the transactions, adding the accepted ones to the pool. The
+// returned errors match the transactions by index, as those of the pool.
+func (f *txFilters) addRemotes(pool txPool, txs []*types.Transaction) []error {
+	if f.empty() {
+		return pool.AddRemotes(txs)
+	}
+	var (
+		errs     = make([]error, len(txs))
+		accepted = make([]*types.Transaction, 0, len(txs))
+		indices  = make([]int, 0, len(txs))
+	)
+	for i, tx := range txs {
+		if err := f.check(tx); err != nil {
+			errs[i] = err
+			continue
+		}
+		accepted = append(accepted, tx)
+		indices = append(indices, i)
+	}
+	txFilteredMeter.Mark(int64(len(txs) - len(accepted)))
+
+	if len(accepted) > 0 {
+		for i, err := range pool.AddRemotes(accepted) {
+			errs[indices[i]] = err
+		}
+	}
+	return errs
+}
+
+// RecipientFilter creates an ingress filter rejecting the transactions sent to
+// any of the given addresses, e.g. calls to specific contracts.
+func RecipientFilter(addrs []common.Address) TxFilter {
+	blocked := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		blocked[addr] = struct{}{}
+	}
+	return func(tx *types.Transaction) error {
+		if to := tx.To(); to != nil {
+			if _, ok := blocked[*to]; ok {
+				return errTxFiltered
+			}
+		}
+		return nil
+	}
+}
+
+// CalldataFilter creates an ingress filter rejecting the transactions whose input
+// data starts with any of the given prefixes, e.g. specific method selectors.
+func CalldataFilter(prefixes [][]byte) TxFilter {
+	return func(tx *types.Transaction) error {
+		data := tx.Data()
+		for _, prefix := range prefixes {
+			if bytes.HasPrefix(data, prefix) {
+				return errTxFiltered
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+)
+
+// Tests that the ingress filters drop the rejected transactions before they reach
+// the pool, and that the errors are reported for the right transactions.
+func TestTxFilters(t *testing.T) {
+	var (
+		blockedTo   = common.Address{0xde, 0xad}
+		allowedTo   = common.Address{0xbe, 0xef}
+		blockedData = []byte{0xa9, 0x05, 0x9c, 0xbb}
+	)
+	newTx := func(nonce uint64, to common.Address, data []byte) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, to, big.NewInt(0), 100000, big.NewInt(0), data), types.HomesteadSigner{}, testKey)
+		return tx
+	}
+	txs := []*types.Transaction{
+		newTx(0, allowedTo, nil),
+		newTx(1, blockedTo, nil),
+		newTx(2, allowedTo, append(blockedData, 0x01)),
+		newTx(3, allowedTo, []byte{0x01}),
+	}
+	var (
+		pool    = newTestTxPool()
+		filters txFilters
+	)
+	// Without filters all transactions are added
+	for i, err := range filters.addRemotes(pool, txs[:1]) {
+		if err != nil {
+			t.Fatalf("tx %d: unexpected error: %v", i, err)
+		}
+	}
+	filters.add(RecipientFilter([]common.Address{blockedTo}))
+	filters.add(CalldataFilter([][]byte{blockedData}))
+
+	errs := filters.addRemotes(pool, txs)
+	if len(errs) != len(txs) {
+		t.Fatalf("error count mismatch: have %d, want %d", len(errs), len(txs))
+	}
+	for i, tx := range txs {
+		rejected := i == 1 || i == 2
+		if rejected && errs[i] != errTxFiltered {
+			t.Errorf("tx %d: error mismatch: have %v, want %v", i, errs[i], errTxFiltered)
+		}
+		if !rejected && errs[i] != nil {
+			t.Errorf("tx %d: unexpected error: %v", i, errs[i])
+		}
+		if included := pool.Has(tx.Hash()); included == rejected {
+			t.Errorf("tx %d: pool inclusion mismatch: have %v, want %v", i, included, !rejected)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/gdtu/gdtuconfig"
+)
+
+// errWhitelistMismatch is returned if a header violates a whitelist rule.
+var errWhitelistMismatch = errors.New("whitelist block mismatch")
+
+// whitelist is the set of rules the headers delivered by peers must satisfy, to
+// partition the node from the chains forking off the whitelisted one.
+type whitelist struct {
+	rules []gdtuconfig.WhitelistRule // Rules sorted by their first block number
+}
+
+// newWhitelist creates a whitelist out of the required block hashes and the
+// generic rules.
+func newWhitelist(hashes map[uint64]common.Hash, rules []gdtuconfig.WhitelistRule) *whitelist {
+	w := &whitelist{rules: append([]gdtuconfig.WhitelistRule{}, rules...)}
+	for number, hash := range hashes {
+		w.rules = append(w.rules, gdtuconfig.WhitelistRule{First: number, Last: number, Hash: hash})
+	}
+	sort.SliceStable(w.rules, func(i, j int) bool { return w.rules[i].First < w.rules[j].First })
+	return w
+}
+
+// challenges returns the block numbers to request from newly connected peers,
+// the first block of every rule.
+func (w *whitelist) challenges() []uint64 {
+	var numbers []uint64
+	for _, rule := range w.rules {
+		if len(numbers) == 0 || numbers[len(numbers)-1] != rule.First {
+			numbers = append(numbers, rule.First)
+		}
+	}
+	return numbers
+}
+
+// verify checks a header against all rules covering its block number.
+func (w *whitelist) verify(header *types.Header) error {
+	number := header.Number.Uint64()
+	for _, rule := range w.rules {
+		if rule.First > number {
+			break
+		}
+		if rule.Last < number {
+			continue
+		}
+		if rule.Hash != (common.Hash{}) {
+			if hash := header.Hash(); hash != rule.Hash {
+				return fmt.Errorf("%w: block %d hash %x, want %x", errWhitelistMismatch, number, hash, rule.Hash)
+			}
+		}
+		if rule.Root != (common.Hash{}) && header.Root != rule.Root {
+			return fmt.Errorf("%w: block %d state root %x, want %x", errWhitelistMismatch, number, header.Root, rule.Root)
+		}
+		if len(rule.ExtraPrefix) > 0 && !bytes.HasPrefix(header.Extra, rule.ExtraPrefix) {
+			return fmt.Errorf("%w: block %d extra-data %x, want prefix %x", errWhitelistMismatch, number, header.Extra, []byte(rule.ExtraPrefix))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtu
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/gdtu/gdtuconfig"
+)
+
+// Tests that headers are checked against all whitelist rules covering them.
+func TestWhitelistVerify(t *testing.T) {
+	pinned := &types.Header{Number: big.NewInt(5), Extra: []byte("pinned")}
+	w := newWhitelist(map[uint64]common.Hash{5: pinned.Hash()}, []gdtuconfig.WhitelistRule{
+		{First: 10, Last: 19, ExtraPrefix: []byte("fork")},
+		{First: 15, Last: 15, Root: common.Hash{0xde, 0xad}},
+	})
+	if have, want := w.challenges(), []uint64{5, 10, 15}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("challenge mismatch: have %v, want %v", have, want)
+	}
+	tests := []struct {
+		header *types.Header
+		fail   bool
+	}{
+		{header: pinned},
+		{header: &types.Header{Number: big.NewInt(5)}, fail: true},
+		{header: &types.Header{Number: big.NewInt(9)}},
+		{header: &types.Header{Number: big.NewInt(10), Extra: []byte("fork-1")}},
+		{header: &types.Header{Number: big.NewInt(19), Extra: []byte("other")}, fail: true},
+		{header: &types.Header{Number: big.NewInt(15), Extra: []byte("fork"), Root: common.Hash{0xde, 0xad}}},
+		{header: &types.Header{Number: big.NewInt(15), Extra: []byte("fork")}, fail: true},
+		{header: &types.Header{Number: big.NewInt(20)}},
+	}
+	for i, tt := range tests {
+		err := w.verify(tt.header)
+		if tt.fail != (err != nil) {
+			t.Errorf("test %d: error mismatch: have %v, want failure %v", i, err, tt.fail)
+		}
+		if err != nil && !errors.Is(err, errWhitelistMismatch) {
+			t.Errorf("test %d: error type mismatch: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtuclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/c88032111/go-gdtu"
+)
+
+// ChainReader is the subset of the client Methods the contract bindings and
+// most applications rely on. Code depending on it rather than on *Client can
+// be unit tested against the in-memory implementation in package fakeclient,
+// without running a node or a simulated backend.
+type ChainReader interface {
+	gdtu.ChainReader
+	gdtu.TransactionReader
+	gdtu.ChainStateReader
+	gdtu.PendingStateReader
+	gdtu.ContractCaller
+	gdtu.PendingContractCaller
+	gdtu.LogFilterer
+	gdtu.TransactionSender
+	gdtu.GasPricer
+	gdtu.GasEstimator
+
+	// ChainID retrieves the chain ID used for transaction replay protection.
+	ChainID(ctx context.Context) (*big.Int, error)
+
+	// BlockNumber returns the number of the most recent block.
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// Verify that Client implements the ChainReader interface.
+var _ ChainReader = (*Client)(nil)
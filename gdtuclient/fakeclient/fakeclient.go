@@ -0,0 +1,675 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package fakeclient provides an in-memory implementation of the gdtuclient
+// ChainReader interface for unit tests.
+//
+// The fake client doesn't execute transactions: it keeps a chain of blocks built
+// from the transactions sent to it and a state which tests set up explicitly.
+// Contract calls are answered by handlers registered per contract address.
+package fakeclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/c88032111/go-gdtu"
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/event"
+	"github.com/c88032111/go-gdtu/gdtuclient"
+	"github.com/c88032111/go-gdtu/params"
+	"github.com/c88032111/go-gdtu/trie"
+)
+
+var errNoCallHandler = errors.New("no call handler registered for contract")
+
+// CallHandler answers the calls made to a contract.
+type CallHandler func(call gdtu.CallMsg) ([]byte, error)
+
+// account is the state of an account, as set up by the test.
+type account struct {
+	balance *big.Int
+	nonce   uint64
+	code    []byte
+	storage map[common.Hash]common.Hash
+}
+
+// txLookup is the position of a mined transaction.
+type txLookup struct {
+	block *types.Block
+	index int
+}
+
+// Client is an in-memory fake of the gdtuclient.Client.
+type Client struct {
+	chainID *big.Int
+	signer  types.Signer
+
+	blocks   []*types.Block // Canonical chain, indexed by number
+	byHash   map[common.Hash]*types.Block
+	receipts map[common.Hash]types.Receipts // Receipts of the blocks by hash
+	logs     map[common.Hash][]*types.Log   // Logs of the blocks by hash
+	txs      map[common.Hash]txLookup
+	pending  []*types.Transaction // Transactions sent but not committed yet
+	accounts map[common.Address]*account
+	handlers map[common.Address]CallHandler
+	gasPrice *big.Int
+	gasLimit uint64 // Gas estimate returned for contract interactions
+	lock     sync.RWMutex
+
+	headFeed event.Feed
+	logsFeed event.Feed
+	scope    event.SubscriptionScope
+}
+
+// Verify that Client implements the ChainReader interface.
+var _ gdtuclient.ChainReader = (*Client)(nil)
+
+// New creates a fake client with an empty genesis block.
+func New(chainID *big.Int) *Client {
+	genesis := types.NewBlockWithHeader(&types.Header{
+		Number:     new(big.Int),
+		Difficulty: big.NewInt(1),
+		GasLimit:   params.GenesisGasLimit,
+	})
+	return &Client{
+		chainID:  new(big.Int).Set(chainID),
+		signer:   types.LatestSignerForChainID(chainID),
+		blocks:   []*types.Block{genesis},
+		byHash:   map[common.Hash]*types.Block{genesis.Hash(): genesis},
+		receipts: map[common.Hash]types.Receipts{genesis.Hash(): nil},
+		logs:     make(map[common.Hash][]*types.Log),
+		txs:      make(map[common.Hash]txLookup),
+		accounts: make(map[common.Address]*account),
+		handlers: make(map[common.Address]CallHandler),
+		gasPrice: big.NewInt(params.GWei),
+		gasLimit: 100000,
+	}
+}
+
+// Close terminates all subscriptions.
+func (c *Client) Close() {
+	c.scope.Close()
+}
+
+// account returns the state of an account, creating it if needed. The lock is
+// assumed to be held.
+func (c *Client) account(addr common.Address) *account {
+	acc, ok := c.accounts[addr]
+	if !ok {
+		acc = &account{balance: new(big.Int), storage: make(map[common.Hash]common.Hash)}
+		c.accounts[addr] = acc
+	}
+	return acc
+}
+
+// SetBalance sets the balance of an account.
+func (c *Client) SetBalance(addr common.Address, balance *big.Int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.account(addr).balance = new(big.Int).Set(balance)
+}
+
+// SetNonce sets the nonce of an account.
+func (c *Client) SetNonce(addr common.Address, nonce uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.account(addr).nonce = nonce
+}
+
+// SetCode sets the code of a contract.
+func (c *Client) SetCode(addr common.Address, code []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.account(addr).code = common.CopyBytes(code)
+}
+
+// SetStorage sets a storage slot of a contract.
+func (c *Client) SetStorage(addr common.Address, key, value common.Hash) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.account(addr).storage[key] = value
+}
+
+// SetGasPrice sets the gas price suggested to the callers.
+func (c *Client) SetGasPrice(price *big.Int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.gasPrice = new(big.Int).Set(price)
+}
+
+// SetGasEstimate sets the gas estimated for contract interactions. Plain value
+// transfers are always estimated at the intrinsic transaction gas.
+func (c *Client) SetGasEstimate(gas uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.gasLimit = gas
+}
+
+// HandleCall registers the handler answering the calls to a contract. If the
+// contract has no code yet, a placeholder is set.
+func (c *Client) HandleCall(addr common.Address, handler CallHandler) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.handlers[addr] = handler
+	if acc := c.account(addr); len(acc.code) == 0 {
+		acc.code = []byte{0x00}
+	}
+}
+
+// SentTransactions returns the transactions sent but not committed yet.
+func (c *Client) SentTransactions() []*types.Transaction {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return append([]*types.Transaction{}, c.pending...)
+}
+
+// Commit includes the sent transactions into a new block, along with the given
+// logs. Logs are attached to the receipt of the transaction they reference,
+// the ones referencing none of the transactions to the last one, if any.
+//
+// No transaction is executed: all of them succeed using their whole gas limit,
+// only the nonces of the senders are updated, and the input of contract
+// creations is stored as code of the created contracts.
+func (c *Client) Commit(logs ...*types.Log) *types.Block {
+	c.lock.Lock()
+
+	parent := c.blocks[len(c.blocks)-1]
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		Difficulty: big.NewInt(1),
+		GasLimit:   parent.GasLimit(),
+		Time:       parent.Time() + 10,
+	}
+	var (
+		txs      = c.pending
+		receipts = make(types.Receipts, len(txs))
+	)
+	for i, tx := range txs {
+		from, _ := types.Sender(c.signer, tx) // checked when sent
+		c.account(from).nonce = tx.Nonce() + 1
+
+		header.GasUsed += tx.Gas()
+		receipts[i] = &types.Receipt{
+			Type:              tx.Type(),
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: header.GasUsed,
+			TxHash:            tx.Hash(),
+			GasUsed:           tx.Gas(),
+			TransactionIndex:  uint(i),
+			Logs:              []*types.Log{},
+		}
+		if tx.To() == nil {
+			receipts[i].ContractAddress = crypto.CreateAddress(from, tx.Nonce())
+			c.account(receipts[i].ContractAddress).code = common.CopyBytes(tx.Data())
+		}
+	}
+	var delivered []*types.Log
+	for i, log := range logs {
+		cpy := *log
+		cpy.BlockNumber, cpy.Index = header.Number.Uint64(), uint(i)
+
+		receipt := receiptOf(receipts, cpy.TxHash)
+		if receipt == nil && len(receipts) > 0 {
+			receipt = receipts[len(receipts)-1]
+		}
+		if receipt != nil {
+			cpy.TxHash, cpy.TxIndex = receipt.TxHash, receipt.TransactionIndex
+			receipt.Logs = append(receipt.Logs, &cpy)
+		}
+		delivered = append(delivered, &cpy)
+	}
+	for _, receipt := range receipts {
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	}
+	header.Bloom = types.CreateBloom(receipts)
+
+	block := types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
+	for _, receipt := range receipts {
+		receipt.BlockHash, receipt.BlockNumber = block.Hash(), block.Number()
+	}
+	for _, log := range delivered {
+		log.BlockHash = block.Hash()
+	}
+	for i, tx := range txs {
+		c.txs[tx.Hash()] = txLookup{block: block, index: i}
+	}
+	c.blocks = append(c.blocks, block)
+	c.byHash[block.Hash()] = block
+	c.receipts[block.Hash()] = receipts
+	c.logs[block.Hash()] = delivered
+	c.pending = nil
+
+	c.lock.Unlock()
+
+	c.headFeed.Send(block.Header())
+	if len(delivered) > 0 {
+		c.logsFeed.Send(delivered)
+	}
+	return block
+}
+
+// receiptOf returns the receipt of the given transaction, if included.
+func receiptOf(receipts types.Receipts, hash common.Hash) *types.Receipt {
+	for _, receipt := range receipts {
+		if receipt.TxHash == hash {
+			return receipt
+		}
+	}
+	return nil
+}
+
+// ChainID retrieves the chain ID used for transaction replay protection.
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(c.chainID), nil
+}
+
+// BlockNumber returns the number of the most recent block.
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return uint64(len(c.blocks) - 1), nil
+}
+
+// blockByNumber returns the canonical block with the given number, or the head
+// block if the number is nil. The lock is assumed to be held.
+func (c *Client) blockByNumber(number *big.Int) (*types.Block, error) {
+	if number == nil {
+		return c.blocks[len(c.blocks)-1], nil
+	}
+	if !number.IsUint64() || number.Uint64() >= uint64(len(c.blocks)) {
+		return nil, gdtu.NotFound
+	}
+	return c.blocks[number.Uint64()], nil
+}
+
+// BlockByHash returns the block with the given hash.
+func (c *Client) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if block, ok := c.byHash[hash]; ok {
+		return block, nil
+	}
+	return nil, gdtu.NotFound
+}
+
+// BlockByNumber returns the block with the given number, or the head block if
+// the number is nil.
+func (c *Client) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.blockByNumber(number)
+}
+
+// HeaderByHash returns the header of the block with the given hash.
+func (c *Client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	block, err := c.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return block.Header(), nil
+}
+
+// HeaderByNumber returns the header of the block with the given number, or the
+// head header if the number is nil.
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	block, err := c.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return block.Header(), nil
+}
+
+// TransactionCount returns the number of transactions in the given block.
+func (c *Client) TransactionCount(ctx context.Context, blockHash common.Hash) (uint, error) {
+	block, err := c.BlockByHash(ctx, blockHash)
+	if err != nil {
+		return 0, err
+	}
+	return uint(len(block.Transactions())), nil
+}
+
+// TransactionInBlock returns a single transaction at index in the given block.
+func (c *Client) TransactionInBlock(ctx context.Context, blockHash common.Hash, index uint) (*types.Transaction, error) {
+	block, err := c.BlockByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if index >= uint(len(block.Transactions())) {
+		return nil, gdtu.NotFound
+	}
+	return block.Transactions()[index], nil
+}
+
+// SubscribeNewHead subscribes to notifications about the committed blocks.
+func (c *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (gdtu.Subscription, error) {
+	return c.scope.Track(c.headFeed.Subscribe(ch)), nil
+}
+
+// TransactionByHash returns the transaction with the given hash, along with
+// whgdtuer it's still pending.
+func (c *Client) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if lookup, ok := c.txs[hash]; ok {
+		return lookup.block.Transactions()[lookup.index], false, nil
+	}
+	for _, tx := range c.pending {
+		if tx.Hash() == hash {
+			return tx, true, nil
+		}
+	}
+	return nil, false, gdtu.NotFound
+}
+
+// TransactionReceipt returns the receipt of a committed transaction.
+func (c *Client) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	lookup, ok := c.txs[hash]
+	if !ok {
+		return nil, gdtu.NotFound
+	}
+	return c.receipts[lookup.block.Hash()][lookup.index], nil
+}
+
+// BalanceAt returns the balance of an account. The fake state isn't versioned,
+// the block number is ignored.
+func (c *Client) BalanceAt(ctx context.Context, addr common.Address, number *big.Int) (*big.Int, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if acc, ok := c.accounts[addr]; ok {
+		return new(big.Int).Set(acc.balance), nil
+	}
+	return new(big.Int), nil
+}
+
+// StorageAt returns the value of a storage slot of a contract. The fake state
+// isn't versioned, the block number is ignored.
+func (c *Client) StorageAt(ctx context.Context, addr common.Address, key common.Hash, number *big.Int) ([]byte, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if acc, ok := c.accounts[addr]; ok {
+		value := acc.storage[key]
+		return value[:], nil
+	}
+	return common.Hash{}.Bytes(), nil
+}
+
+// CodeAt returns the code of a contract. The fake state isn't versioned, the
+// block number is ignored.
+func (c *Client) CodeAt(ctx context.Context, addr common.Address, number *big.Int) ([]byte, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if acc, ok := c.accounts[addr]; ok {
+		return common.CopyBytes(acc.code), nil
+	}
+	return nil, nil
+}
+
+// NonceAt returns the nonce of an account, excluding the transactions not
+// committed yet. The fake state isn't versioned, the block number is ignored.
+func (c *Client) NonceAt(ctx context.Context, addr common.Address, number *big.Int) (uint64, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if acc, ok := c.accounts[addr]; ok {
+		return acc.nonce, nil
+	}
+	return 0, nil
+}
+
+// PendingBalanceAt returns the balance of an account.
+func (c *Client) PendingBalanceAt(ctx context.Context, addr common.Address) (*big.Int, error) {
+	return c.BalanceAt(ctx, addr, nil)
+}
+
+// PendingStorageAt returns the value of a storage slot of a contract.
+func (c *Client) PendingStorageAt(ctx context.Context, addr common.Address, key common.Hash) ([]byte, error) {
+	return c.StorageAt(ctx, addr, key, nil)
+}
+
+// PendingCodeAt returns the code of a contract.
+func (c *Client) PendingCodeAt(ctx context.Context, addr common.Address) ([]byte, error) {
+	return c.CodeAt(ctx, addr, nil)
+}
+
+// PendingNonceAt returns the nonce of an account, including the transactions
+// sent but not committed yet.
+func (c *Client) PendingNonceAt(ctx context.Context, addr common.Address) (uint64, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.pendingNonce(addr), nil
+}
+
+// pendingNonce returns the next nonce of an account. The lock is assumed to be
+// held.
+func (c *Client) pendingNonce(addr common.Address) uint64 {
+	var nonce uint64
+	if acc, ok := c.accounts[addr]; ok {
+		nonce = acc.nonce
+	}
+	for _, tx := range c.pending {
+		if from, _ := types.Sender(c.signer, tx); from == addr && tx.Nonce() >= nonce {
+			nonce = tx.Nonce() + 1
+		}
+	}
+	return nonce
+}
+
+// PendingTransactionCount returns the number of transactions sent but not
+// committed yet.
+func (c *Client) PendingTransactionCount(ctx context.Context) (uint, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return uint(len(c.pending)), nil
+}
+
+// CallContract answers a contract call through the handler registered for the
+// called contract. The block number is ignored.
+func (c *Client) CallContract(ctx context.Context, call gdtu.CallMsg, number *big.Int) ([]byte, error) {
+	c.lock.RLock()
+	var (
+		handler CallHandler
+		code    []byte
+	)
+	if call.To != nil {
+		handler = c.handlers[*call.To]
+		if acc, ok := c.accounts[*call.To]; ok {
+			code = acc.code
+		}
+	}
+	c.lock.RUnlock()
+
+	switch {
+	case handler != nil:
+		return handler(call)
+	case call.To == nil || len(code) == 0:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: %x", errNoCallHandler, *call.To)
+	}
+}
+
+// PendingCallContract answers a contract call through the handler registered for
+// the called contract.
+func (c *Client) PendingCallContract(ctx context.Context, call gdtu.CallMsg) ([]byte, error) {
+	return c.CallContract(ctx, call, nil)
+}
+
+// SuggestGasPrice returns the configured gas price.
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return new(big.Int).Set(c.gasPrice), nil
+}
+
+// EstimateGas returns the intrinsic transaction gas for transfers to accounts
+// without code and the configured estimate for contract interactions.
+func (c *Client) EstimateGas(ctx context.Context, call gdtu.CallMsg) (uint64, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if call.To != nil {
+		if acc, ok := c.accounts[*call.To]; !ok || len(acc.code) == 0 {
+			return params.TxGas, nil
+		}
+	}
+	return c.gasLimit, nil
+}
+
+// SendTransaction queues a signed transaction for inclusion into the next
+// committed block.
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	from, err := types.Sender(c.signer, tx)
+	if err != nil {
+		return fmt.Errorf("invalid transaction: %v", err)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if nonce := c.pendingNonce(from); tx.Nonce() != nonce {
+		return fmt.Errorf("invalid transaction nonce: have %d, want %d", tx.Nonce(), nonce)
+	}
+	c.pending = append(c.pending, tx)
+	return nil
+}
+
+// FilterLogs returns the logs of the committed blocks matching the query.
+func (c *Client) FilterLogs(ctx context.Context, q gdtu.FilterQuery) ([]types.Log, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var blocks []*types.Block
+	if q.BlockHash != nil {
+		block, ok := c.byHash[*q.BlockHash]
+		if !ok {
+			return nil, gdtu.NotFound
+		}
+		blocks = append(blocks, block)
+	} else {
+		from, to := uint64(len(c.blocks)-1), uint64(len(c.blocks)-1)
+		if q.FromBlock != nil {
+			if !q.FromBlock.IsUint64() || q.FromBlock.Uint64() > to {
+				return nil, nil
+			}
+			from = q.FromBlock.Uint64()
+		}
+		if q.ToBlock != nil && q.ToBlock.IsUint64() && q.ToBlock.Uint64() < to {
+			to = q.ToBlock.Uint64()
+		}
+		for number := from; number <= to; number++ {
+			blocks = append(blocks, c.blocks[number])
+		}
+	}
+	var logs []types.Log
+	for _, block := range blocks {
+		for _, log := range c.logs[block.Hash()] {
+			if matchLog(log, q) {
+				logs = append(logs, *log)
+			}
+		}
+	}
+	return logs, nil
+}
+
+// SubscribeFilterLogs subscribes to the logs of the committed blocks matching the
+// query. The block range of the query is ignored.
+func (c *Client) SubscribeFilterLogs(ctx context.Context, q gdtu.FilterQuery, ch chan<- types.Log) (gdtu.Subscription, error) {
+	var (
+		logsCh  = make(chan []*types.Log)
+		logsSub = c.logsFeed.Subscribe(logsCh)
+	)
+	sub := event.NewSubscription(func(quit <-chan struct{}) error {
+		defer logsSub.Unsubscribe()
+
+		for {
+			select {
+			case logs := <-logsCh:
+				for _, log := range logs {
+					if !matchLog(log, q) {
+						continue
+					}
+					select {
+					case ch <- *log:
+					case <-quit:
+						return nil
+					}
+				}
+			case err := <-logsSub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	})
+	return c.scope.Track(sub), nil
+}
+
+// matchLog reports whgdtuer a log matches the address and topic criteria of the
+// given filter query.
+func matchLog(log *types.Log, q gdtu.FilterQuery) bool {
+	if len(q.Addresses) > 0 {
+		found := false
+		for _, addr := range q.Addresses {
+			found = found || addr == log.Address
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(q.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, alternatives := range q.Topics {
+		if len(alternatives) == 0 {
+			continue // wildcard
+		}
+		found := false
+		for _, topic := range alternatives {
+			found = found || topic == log.Topics[i]
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
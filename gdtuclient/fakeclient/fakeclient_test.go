@@ -0,0 +1,180 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package fakeclient
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu"
+	"github.com/c88032111/go-gdtu/accounts/abi"
+	"github.com/c88032111/go-gdtu/accounts/abi/bind"
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/crypto"
+)
+
+// Verify that the fake client can back the contract bindings.
+var (
+	_ bind.ContractBackend = (*Client)(nil)
+	_ bind.DeployBackend   = (*Client)(nil)
+)
+
+const testABI = `[
+	{"type":"function","name":"value","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+	{"type":"function","name":"poke","inputs":[],"outputs":[]},
+	{"type":"event","name":"Poked","inputs":[{"name":"who","type":"address","indexed":true}],"anonymous":false}
+]`
+
+// Tests that contracts can be deployed, called, transacted with and watched
+// through the bindings on top of the fake client.
+func TestBindings(t *testing.T) {
+	chainID := big.NewInt(1337)
+	client := New(chainID)
+	defer client.Close()
+
+	key, _ := crypto.GenerateKey()
+	auth, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(testABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Deploy the contract and wait for it to be committed
+	addr, tx, contract, err := bind.DeployContract(auth, parsed, []byte{0x60, 0x00}, client)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	if pending, _ := client.PendingTransactionCount(context.Background()); pending != 1 {
+		t.Fatalf("pending transaction count mismatch: have %d, want 1", pending)
+	}
+	client.Commit()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	deployed, err := bind.WaitDeployed(ctx, client, tx)
+	if err != nil {
+		t.Fatalf("failed to wait for deployment: %v", err)
+	}
+	if deployed != addr {
+		t.Fatalf("deployed address mismatch: have %x, want %x", deployed, addr)
+	}
+	// Answer calls to the contract through a handler
+	client.HandleCall(addr, func(call gdtu.CallMsg) ([]byte, error) {
+		return parsed.Methods["value"].Outputs.Pack(big.NewInt(42))
+	})
+	var out []interface{}
+	if err := contract.Call(nil, &out, "value"); err != nil {
+		t.Fatalf("failed to call contract: %v", err)
+	}
+	if value := out[0].(*big.Int); value.Int64() != 42 {
+		t.Fatalf("call result mismatch: have %v, want 42", value)
+	}
+	// Transact with the contract, watching and filtering its logs
+	logs, sub, err := contract.WatchLogs(nil, "Poked")
+	if err != nil {
+		t.Fatalf("failed to watch logs: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	tx, err = contract.Transact(auth, "poke")
+	if err != nil {
+		t.Fatalf("failed to transact: %v", err)
+	}
+	if tx.Nonce() != 1 {
+		t.Fatalf("transaction nonce mismatch: have %d, want 1", tx.Nonce())
+	}
+	poked := &types.Log{
+		Address: addr,
+		Topics:  []common.Hash{parsed.Events["Poked"].ID, common.BytesToHash(auth.From.Bytes())},
+		TxHash:  tx.Hash(),
+	}
+	block := client.Commit(poked, &types.Log{Address: common.Address{0xde, 0xad}})
+
+	select {
+	case log := <-logs:
+		if log.TxHash != tx.Hash() || log.BlockHash != block.Hash() {
+			t.Fatalf("watched log mismatch: tx %x, block %x", log.TxHash, log.BlockHash)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("log not delivered")
+	}
+	filtered, filterSub, err := contract.FilterLogs(&bind.FilterOpts{Start: 0}, "Poked")
+	if err != nil {
+		t.Fatalf("failed to filter logs: %v", err)
+	}
+	defer filterSub.Unsubscribe()
+	if log := <-filtered; log.BlockNumber != 2 {
+		t.Fatalf("filtered log block mismatch: have %d, want 2", log.BlockNumber)
+	}
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		t.Fatalf("failed to wait for transaction: %v", err)
+	}
+	if len(receipt.Logs) != 2 || receipt.BlockHash != block.Hash() {
+		t.Fatalf("receipt mismatch: %d logs, block %x", len(receipt.Logs), receipt.BlockHash)
+	}
+}
+
+// Tests that transactions with wrong nonces or chain ids are rejected.
+func TestSendTransactionValidation(t *testing.T) {
+	client := New(big.NewInt(1337))
+	defer client.Close()
+
+	key, _ := crypto.GenerateKey()
+	sign := func(nonce uint64, chainID int64) *types.Transaction {
+		tx := types.NewTransaction(nonce, common.Address{0xde, 0xad}, big.NewInt(1), 21000, big.NewInt(1), nil)
+		signed, err := types.SignTx(tx, types.LatestSignerForChainID(big.NewInt(chainID)), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	if err := client.SendTransaction(context.Background(), sign(0, 1)); err == nil {
+		t.Fatalf("transaction of other chain accepted")
+	}
+	if err := client.SendTransaction(context.Background(), sign(1, 1337)); err == nil {
+		t.Fatalf("transaction with nonce gap accepted")
+	}
+	tx := sign(0, 1337)
+	if err := client.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	if _, pending, err := client.TransactionByHash(context.Background(), tx.Hash()); err != nil || !pending {
+		t.Fatalf("sent transaction not pending: %v", err)
+	}
+	client.Commit()
+
+	if _, pending, err := client.TransactionByHash(context.Background(), tx.Hash()); err != nil || pending {
+		t.Fatalf("committed transaction still pending: %v", err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	if nonce, _ := client.NonceAt(context.Background(), from, nil); nonce != 1 {
+		t.Fatalf("nonce mismatch: have %d, want 1", nonce)
+	}
+	if err := client.SendTransaction(context.Background(), tx); err == nil {
+		t.Fatalf("replayed transaction accepted")
+	}
+	if _, err := client.HeaderByNumber(context.Background(), big.NewInt(2)); err != gdtu.NotFound {
+		t.Fatalf("future header lookup error mismatch: have %v, want %v", err, gdtu.NotFound)
+	}
+}
@@ -225,7 +225,7 @@ func generateTestChain() (*core.Genesis, []*types.Block) {
 		g.OffsetTime(5)
 		g.SetExtra([]byte("test"))
 	}
-	gblock := genesis.ToBlock(db)
+	gblock, _ := genesis.ToBlock(db)
 	engine := gdtuash.NewFaker()
 	blocks, _ := core.GenerateChain(config, gblock, engine, db, 1, generate)
 	blocks = append([]*types.Block{gblock}, blocks...)
@@ -265,6 +265,9 @@ func TestGdtuClient(t *testing.T) {
 		"TestAtFunctions": {
 			func(t *testing.T) { testAtFunctions(t, client) },
 		},
+		"TestBlockReceipts": {
+			func(t *testing.T) { testBlockReceipts(t, chain, client) },
+		},
 	}
 
 	t.Parallel()
@@ -572,3 +575,27 @@ func sendTransaction(ec *Client) error {
 	// Send transaction
 	return ec.SendTransaction(context.Background(), signedTx)
 }
+
+func testBlockReceipts(t *testing.T, chain []*types.Block, client *rpc.Client) {
+	ec := NewClient(client)
+
+	// Blocks without transactions have an empty receipt list
+	receipts, err := ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithNumber(1))
+	if err != nil {
+		t.Fatalf("BlockReceipts by number failed: %v", err)
+	}
+	if receipts == nil || len(receipts) != 0 {
+		t.Fatalf("receipts mismatch: have %v, want empty", receipts)
+	}
+	receipts, err = ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithHash(chain[1].Hash(), false))
+	if err != nil {
+		t.Fatalf("BlockReceipts by hash failed: %v", err)
+	}
+	if receipts == nil || len(receipts) != 0 {
+		t.Fatalf("receipts mismatch: have %v, want empty", receipts)
+	}
+	// Unknown blocks are reported as not found
+	if _, err := ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithHash(common.Hash{0x01}, false)); err != gdtu.NotFound {
+		t.Fatalf("error mismatch: have %v, want %v", err, gdtu.NotFound)
+	}
+}
@@ -110,11 +110,29 @@ type Writer interface {
 	AncientWriter
 }
 
+// AncientTuner contains the Methods required to adjust how much of the recent
+// chain is retained in the key-value store before being moved into the ancient
+// store.
+type AncientTuner interface {
+	// AncientThreshold returns the number of recent blocks not to move into the
+	// ancient store.
+	AncientThreshold() (uint64, error)
+
+	// SetAncientThreshold changes the number of recent blocks not to move into
+	// the ancient store.
+	SetAncientThreshold(threshold uint64) error
+
+	// TuneAncientThreshold enables automatic tuning of the threshold based on
+	// the observed ancient reads, up to the given maximum. Zero disables it.
+	TuneAncientThreshold(max uint64) error
+}
+
 // AncientStore contains all the Methods required to allow handling different
 // ancient data stores backing immutable chain data store.
 type AncientStore interface {
 	AncientReader
 	AncientWriter
+	AncientTuner
 	io.Closer
 }
 
@@ -123,6 +141,7 @@ type AncientStore interface {
 type Database interface {
 	Reader
 	Writer
+	AncientTuner
 	Batcher
 	Iteratee
 	Stater
@@ -21,9 +21,11 @@ package leveldb
 
 import (
 	"fmt"
+	"math/rand"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/c88032111/go-gdtu/common"
@@ -74,6 +76,16 @@ type Database struct {
 	level0CompGauge    metrics.Gauge // Gauge for tracking the number of table compaction in level0
 	nonlevel0CompGauge metrics.Gauge // Gauge for tracking the number of table compaction in non0 level
 	seekCompGauge      metrics.Gauge // Gauge for tracking the number of table compaction caused by read opt
+	compBacklogGauge   metrics.Gauge // Gauge for tracking the number of level0 tables waiting for compaction
+	writeStallGauge    metrics.Gauge // Gauge for tracking whether writes are paused due to database compaction
+	openFilesGauge     metrics.Gauge // Gauge for tracking the number of table files held open
+	probeLatencyGauge  metrics.Gauge // Gauge for tracking the latency of the periodic self-test read
+
+	readAmpGauge  metrics.GaugeFloat64 // Gauge for tracking the disk reads per byte read by the user
+	writeAmpGauge metrics.GaugeFloat64 // Gauge for tracking the disk writes per byte written by the user
+
+	userRead  uint64 // Number of bytes retrieved by the user, accessed atomically
+	userWrite uint64 // Number of bytes inserted by the user, accessed atomically
 
 	quitLock sync.Mutex      // Mutex protecting the quit channel access
 	quitChan chan chan error // Quit channel to stop the metrics collection before closing the database
@@ -139,6 +151,12 @@ func NewCustom(file string, namespace string, customize func(options *opt.Option
 	ldb.level0CompGauge = metrics.NewRegisteredGauge(namespace+"compact/level0", nil)
 	ldb.nonlevel0CompGauge = metrics.NewRegisteredGauge(namespace+"compact/nonlevel0", nil)
 	ldb.seekCompGauge = metrics.NewRegisteredGauge(namespace+"compact/seek", nil)
+	ldb.compBacklogGauge = metrics.NewRegisteredGauge(namespace+"compact/backlog", nil)
+	ldb.writeStallGauge = metrics.NewRegisteredGauge(namespace+"compact/writedelay/stall", nil)
+	ldb.openFilesGauge = metrics.NewRegisteredGauge(namespace+"disk/openfiles", nil)
+	ldb.probeLatencyGauge = metrics.NewRegisteredGauge(namespace+"disk/probe", nil)
+	ldb.readAmpGauge = metrics.NewRegisteredGaugeFloat64(namespace+"disk/readamp", nil)
+	ldb.writeAmpGauge = metrics.NewRegisteredGaugeFloat64(namespace+"disk/writeamp", nil)
 
 	// Start up the metrics gathering and return
 	go ldb.meter(metricsGatheringInterval)
@@ -187,11 +205,13 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	atomic.AddUint64(&db.userRead, uint64(len(key)+len(dat)))
 	return dat, nil
 }
 
 // Put inserts the given value into the key-value store.
 func (db *Database) Put(key []byte, value []byte) error {
+	atomic.AddUint64(&db.userWrite, uint64(len(key)+len(value)))
 	return db.db.Put(key, value, nil)
 }
 
@@ -204,8 +224,9 @@ func (db *Database) Delete(key []byte) error {
 // database until a final write is called.
 func (db *Database) NewBatch() gdtudb.Batch {
 	return &batch{
-		db: db.db,
-		b:  new(leveldb.Batch),
+		db:      db.db,
+		b:       new(leveldb.Batch),
+		written: &db.userWrite,
 	}
 }
 
@@ -260,8 +281,11 @@ func (db *Database) meter(refresh time.Duration) {
 	for i := 0; i < 2; i++ {
 		compactions[i] = make([]float64, 4)
 	}
-	// Create storage for iostats.
-	var iostats [2]float64
+	// Create storage for iostats and the user traffic they are compared against.
+	var (
+		iostats   [2]float64
+		userstats [2]uint64
+	)
 
 	// Create storage and warning log tracer for write delay.
 	var (
@@ -362,6 +386,13 @@ func (db *Database) meter(refresh time.Duration) {
 		}
 		// If a warning that db is performing compaction has been displayed, any subsequent
 		// warnings will be withheld for one minute not to overwhelm the user.
+		if db.writeStallGauge != nil {
+			if paused {
+				db.writeStallGauge.Update(1)
+			} else {
+				db.writeStallGauge.Update(0)
+			}
+		}
 		if paused && delayN-delaystats[0] == 0 && duration.Nanoseconds()-delaystats[1] == 0 &&
 			time.Now().After(lastWritePaused.Add(degradationWarnInterval)) {
 			db.log.Warn("Database compacting, degraded performance")
@@ -399,7 +430,16 @@ func (db *Database) meter(refresh time.Duration) {
 		if db.diskWriteMeter != nil {
 			db.diskWriteMeter.Mark(int64((nWrite - iostats[1]) * 1024 * 1024))
 		}
+		// Calculate the amplification of the user traffic since the last round
+		userRead, userWrite := atomic.LoadUint64(&db.userRead), atomic.LoadUint64(&db.userWrite)
+		if delta := userRead - userstats[0]; delta > 0 && db.readAmpGauge != nil {
+			db.readAmpGauge.Update((nRead - iostats[0]) * 1024 * 1024 / float64(delta))
+		}
+		if delta := userWrite - userstats[1]; delta > 0 && db.writeAmpGauge != nil {
+			db.writeAmpGauge.Update((nWrite - iostats[1]) * 1024 * 1024 / float64(delta))
+		}
 		iostats[0], iostats[1] = nRead, nWrite
+		userstats[0], userstats[1] = userRead, userWrite
 
 		compCount, err := db.db.GetProperty("leveldb.compcount")
 		if err != nil {
@@ -424,6 +464,36 @@ func (db *Database) meter(refresh time.Duration) {
 		db.nonlevel0CompGauge.Update(int64(nonLevel0Comp))
 		db.seekCompGauge.Update(int64(seekComp))
 
+		// Retrieve the compaction backlog and the number of open files
+		level0Tables, err := db.db.GetProperty("leveldb.num-files-at-level0")
+		if err != nil {
+			db.log.Error("Failed to read database level0 tables", "err", err)
+			merr = err
+			continue
+		}
+		openedTables, err := db.db.GetProperty("leveldb.openedtables")
+		if err != nil {
+			db.log.Error("Failed to read database opened tables", "err", err)
+			merr = err
+			continue
+		}
+		if n, err := strconv.ParseInt(level0Tables, 10, 64); err == nil && db.compBacklogGauge != nil {
+			db.compBacklogGauge.Update(n)
+		}
+		if n, err := strconv.ParseInt(openedTables, 10, 64); err == nil && db.openFilesGauge != nil {
+			db.openFilesGauge.Update(n)
+		}
+		// Measure the read latency, it's only worth the disk access if someone's watching
+		if metrics.Enabled && db.probeLatencyGauge != nil {
+			latency, err := db.probe()
+			if err != nil {
+				db.log.Error("Database read probe failed", "err", err)
+				merr = err
+				continue
+			}
+			db.probeLatencyGauge.Update(int64(latency))
+		}
+
 		// Sleep a bit, then repeat the stats collection
 		select {
 		case errc = <-db.quitChan:
@@ -440,12 +510,27 @@ func (db *Database) meter(refresh time.Duration) {
 	errc <- merr
 }
 
+// probe measures the latency of a random read from the database, by positioning
+// an iterator at a random key and retrieving the entry following it. Unlike the
+// cumulative statistics, this catches a database which became slow to serve reads.
+func (db *Database) probe() (time.Duration, error) {
+	key := make([]byte, common.HashLength)
+	rand.Read(key)
+
+	start := time.Now()
+	it := db.db.NewIterator(&util.Range{Start: key}, nil)
+	it.Next()
+	it.Release()
+	return time.Since(start), it.Error()
+}
+
 // batch is a write-only leveldb batch that commits changes to its host database
 // when Write is called. A batch cannot be used concurrently.
 type batch struct {
-	db   *leveldb.DB
-	b    *leveldb.Batch
-	size int
+	db      *leveldb.DB
+	b       *leveldb.Batch
+	size    int
+	written *uint64 // User write counter of the host database
 }
 
 // Put inserts the given value into the batch for later committing.
@@ -469,6 +554,7 @@ func (b *batch) ValueSize() int {
 
 // Write flushes any accumulated data to disk.
 func (b *batch) Write() error {
+	atomic.AddUint64(b.written, uint64(len(b.b.Dump())))
 	return b.db.Write(b.b, nil)
 }
 
@@ -38,3 +38,37 @@ func TestLevelDB(t *testing.T) {
 		})
 	})
 }
+
+func TestLevelDBHealth(t *testing.T) {
+	ldb, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &Database{db: ldb}
+	defer db.Close()
+
+	// Check that the user traffic is accounted for the amplification
+	if err := db.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	batch := db.NewBatch()
+	batch.Put([]byte("batched"), []byte("value"))
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Get([]byte("key")); err != nil {
+		t.Fatal(err)
+	}
+	if db.userWrite <= uint64(len("key")+len("value")) {
+		t.Errorf("batch write not accounted: have %d bytes written", db.userWrite)
+	}
+	if have, want := db.userRead, uint64(len("key")+len("value")); have != want {
+		t.Errorf("read bytes mismatch: have %d, want %d", have, want)
+	}
+	// Check that the read probe works on both populated and empty ranges
+	for i := 0; i < 16; i++ {
+		if _, err := db.probe(); err != nil {
+			t.Fatalf("probe %d failed: %v", i, err)
+		}
+	}
+}
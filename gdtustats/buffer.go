@@ -0,0 +1,102 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtustats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/log"
+)
+
+const (
+	// bufferedBlocksLimit is the maximum number of block reports retained while
+	// the stats server is unreachable.
+	bufferedBlocksLimit = 256
+
+	// bufferedStatsLimit is the maximum number of node stats snapshots retained
+	// while the stats server is unreachable (an hour at the full report rate).
+	bufferedStatsLimit = 240
+)
+
+// statsSnapshot is the node and pending transaction stats taken at some point
+// while the stats server was unreachable.
+type statsSnapshot struct {
+	time    time.Time
+	node    *NodeStats
+	pending *PendingStats
+}
+
+// reportBuffer retains the reports which couldn't be delivered while the stats
+// server was unreachable, so they can be replayed on reconnect instead of leaving
+// gaps on the monitoring page. Once full, the oldest reports are dropped.
+type reportBuffer struct {
+	blocks []*BlockStats
+	known  map[common.Hash]struct{} // Hashes of the buffered blocks
+	stats  []*statsSnapshot
+}
+
+func newReportBuffer() *reportBuffer {
+	return &reportBuffer{known: make(map[common.Hash]struct{})}
+}
+
+// addBlock buffers a block report, unless the same block is already buffered.
+func (b *reportBuffer) addBlock(block *BlockStats) {
+	if _, ok := b.known[block.Hash]; ok {
+		return
+	}
+	if len(b.blocks) >= bufferedBlocksLimit {
+		delete(b.known, b.blocks[0].Hash)
+		b.blocks = b.blocks[1:]
+	}
+	b.blocks = append(b.blocks, block)
+	b.known[block.Hash] = struct{}{}
+}
+
+// addStats buffers a snapshot of the node stats.
+func (b *reportBuffer) addStats(snapshot *statsSnapshot) {
+	if len(b.stats) >= bufferedStatsLimit {
+		b.stats = b.stats[1:]
+	}
+	b.stats = append(b.stats, snapshot)
+}
+
+// empty reports whgdtuer there is nothing to replay.
+func (b *reportBuffer) empty() bool {
+	return len(b.blocks) == 0 && len(b.stats) == 0
+}
+
+// history returns the buffered blocks in the order of a history report, the
+// highest block first.
+func (b *reportBuffer) history() []*BlockStats {
+	history := make([]*BlockStats, len(b.blocks))
+	copy(history, b.blocks)
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Number.Cmp(history[j].Number) > 0
+	})
+	return history
+}
+
+// reset drops all buffered reports after they have been replayed.
+func (b *reportBuffer) reset() {
+	if !b.empty() {
+		log.Debug("Replayed buffered stats reports", "blocks", len(b.blocks), "stats", len(b.stats))
+	}
+	b.blocks, b.stats = nil, nil
+	b.known = make(map[common.Hash]struct{})
+}
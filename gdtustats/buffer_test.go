@@ -0,0 +1,79 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtustats
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+)
+
+func testBlockStats(number int64) *BlockStats {
+	return &BlockStats{
+		Number: big.NewInt(number),
+		Hash:   common.BigToHash(big.NewInt(number)),
+	}
+}
+
+// Tests that the report buffer retains the most recent reports within its limits
+// and replays the blocks highest first.
+func TestReportBuffer(t *testing.T) {
+	buffer := newReportBuffer()
+	if !buffer.empty() {
+		t.Fatalf("new buffer not empty")
+	}
+	// Add more blocks than retained, including duplicates
+	for i := int64(0); i < bufferedBlocksLimit+10; i++ {
+		buffer.addBlock(testBlockStats(i))
+		buffer.addBlock(testBlockStats(i))
+	}
+	history := buffer.history()
+	if len(history) != bufferedBlocksLimit {
+		t.Fatalf("buffered block count mismatch: have %d, want %d", len(history), bufferedBlocksLimit)
+	}
+	for i, block := range history {
+		if want := int64(bufferedBlocksLimit + 9 - i); block.Number.Int64() != want {
+			t.Fatalf("history item %d: have block %d, want %d", i, block.Number, want)
+		}
+	}
+	// Blocks dropped due to the limit can be buffered again
+	buffer.addBlock(testBlockStats(0))
+	if history := buffer.history(); history[len(history)-1].Number.Int64() != 0 {
+		t.Fatalf("dropped block not buffered again")
+	}
+	// Add more stats than retained
+	start := time.Now()
+	for i := 0; i < bufferedStatsLimit+10; i++ {
+		buffer.addStats(&statsSnapshot{time: start.Add(time.Duration(i) * time.Second)})
+	}
+	if len(buffer.stats) != bufferedStatsLimit {
+		t.Fatalf("buffered stats count mismatch: have %d, want %d", len(buffer.stats), bufferedStatsLimit)
+	}
+	if oldest := buffer.stats[0].time; !oldest.Equal(start.Add(10 * time.Second)) {
+		t.Fatalf("oldest stats mismatch: have %v, want %v", oldest, start.Add(10*time.Second))
+	}
+	buffer.reset()
+	if !buffer.empty() {
+		t.Fatalf("buffer not empty after reset")
+	}
+	buffer.addBlock(testBlockStats(1))
+	if len(buffer.history()) != 1 {
+		t.Fatalf("block not buffered after reset")
+	}
+}
@@ -58,6 +58,9 @@ const (
 	txChanSize = 4096
 	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
 	chainHeadChanSize = 10
+
+	// fullReportInterval is the time between two full stats reports.
+	fullReportInterval = 15 * time.Second
 )
 
 // backend encompasses the bare-minimum functionality needed for gdtustats reporting
@@ -95,6 +98,20 @@ type Service struct {
 	pgdtuCh chan struct{} // Pgdtu notifications are fed into this channel
 	histCh  chan []uint64 // History request block numbers are fed into this channel
 
+	reportFeed event.Feed              // Feed delivering the assembled reports to local subscribers
+	scope      event.SubscriptionScope // Subscription scope tracking the local subscribers
+}
+
+// Report is a set of stats assembled for reporting, delivered to the local
+// subscribers along with being sent to the stats server. Only the fields which
+// were assembled are set: a block report on every new chain head, a pending
+// report on transaction pool changes and both node and pending reports at the
+// full report interval.
+type Report struct {
+	Time    time.Time     // Time the stats were assembled at
+	Block   *BlockStats   // Stats of a new chain head
+	Pending *PendingStats // Stats of the pending transactions
+	Node    *NodeStats    // Stats of the local node
 }
 
 // connWrapper is a wrapper to prevent concurrent-write or concurrent-read on the
@@ -143,12 +160,12 @@ func (w *connWrapper) Close() error {
 }
 
 // New returns a monitoring service ready for stats reporting.
-func New(node *node.Node, backend backend, engine consensus.Engine, url string) error {
+func New(node *node.Node, backend backend, engine consensus.Engine, url string) (*Service, error) {
 	// Parse the netstats connection url
 	re := regexp.MustCompile("([^:@]*)(:([^@]*))?@(.+)")
 	parts := re.FindStringSubmatch(url)
 	if len(parts) != 5 {
-		return fmt.Errorf("invalid netstats url: \"%s\", should be nodename:secret@host:port", url)
+		return nil, fmt.Errorf("invalid netstats url: \"%s\", should be nodename:secret@host:port", url)
 	}
 	gdtustats := &Service{
 		backend: backend,
@@ -162,7 +179,7 @@ func New(node *node.Node, backend backend, engine consensus.Engine, url string)
 	}
 
 	node.RegisterLifecycle(gdtustats)
-	return nil
+	return gdtustats, nil
 }
 
 // Start implements node.Lifecycle, starting up the monitoring and reporting daemon.
@@ -175,10 +192,34 @@ func (s *Service) Start() error {
 
 // Stop implements node.Lifecycle, terminating the monitoring and reporting daemon.
 func (s *Service) Stop() error {
+	s.scope.Close()
 	log.Info("Stats daemon stopped")
 	return nil
 }
 
+// SubscribeReports registers a subscription for the stats assembled by the
+// daemon, allowing them to be exported to custom destinations. The reports are
+// delivered whgdtuer the stats server is reachable or not.
+func (s *Service) SubscribeReports(ch chan<- Report) event.Subscription {
+	return s.scope.Track(s.reportFeed.Subscribe(ch))
+}
+
+// publish delivers a report to the local subscribers.
+func (s *Service) publish(report Report) {
+	if report.Time.IsZero() {
+		report.Time = time.Now()
+	}
+	s.reportFeed.Send(report)
+}
+
+// publishPending assembles the pending transaction stats for the local
+// subscribers only, avoiding the transaction pool lookup if there are none.
+func (s *Service) publishPending() {
+	if s.scope.Count() > 0 {
+		s.publish(Report{Pending: s.assemblePendStats()})
+	}
+}
+
 // loop keeps trying to connect to the netstats server, reporting chain events
 // until termination.
 func (s *Service) loop() {
@@ -243,11 +284,28 @@ func (s *Service) loop() {
 
 	errTimer := time.NewTimer(0)
 	defer errTimer.Stop()
+
+	// Buffer the reports while the server is unreachable to replay them later
+	buffer := newReportBuffer()
+	offlineReport := time.NewTicker(fullReportInterval)
+	defer offlineReport.Stop()
+
 	// Loop reporting until termination
 	for {
 		select {
 		case <-quitCh:
 			return
+		case head := <-headCh:
+			details := s.assembleBlockStats(head)
+			s.publish(Report{Block: details})
+			buffer.addBlock(details)
+			s.publishPending()
+		case <-txCh:
+			s.publishPending()
+		case <-offlineReport.C:
+			snapshot := s.snapshotStats()
+			s.publish(Report{Time: snapshot.time, Node: snapshot.node, Pending: snapshot.pending})
+			buffer.addStats(snapshot)
 		case <-errTimer.C:
 			// Establish a websocket connection to the server on any supported URL
 			var (
@@ -279,15 +337,22 @@ func (s *Service) loop() {
 			}
 			go s.readLoop(conn)
 
+			// Fill the gap left by the outage before reporting the current state
+			if err = s.replayBuffered(conn, buffer); err != nil {
+				log.Warn("Buffered stats replay failed", "err", err)
+				conn.Close()
+				errTimer.Reset(0)
+				continue
+			}
 			// Send the initial stats so our node looks decent from the get go
-			if err = s.report(conn); err != nil {
+			if err = s.report(conn, s.snapshotStats()); err != nil {
 				log.Warn("Initial stats report failed", "err", err)
 				conn.Close()
 				errTimer.Reset(0)
 				continue
 			}
 			// Keep sending status updates until the connection breaks
-			fullReport := time.NewTicker(15 * time.Second)
+			fullReport := time.NewTicker(fullReportInterval)
 
 			for err == nil {
 				select {
@@ -298,22 +363,32 @@ func (s *Service) loop() {
 					return
 
 				case <-fullReport.C:
-					if err = s.report(conn); err != nil {
+					snapshot := s.snapshotStats()
+					s.publish(Report{Time: snapshot.time, Node: snapshot.node, Pending: snapshot.pending})
+					if err = s.report(conn, snapshot); err != nil {
 						log.Warn("Full stats report failed", "err", err)
+						buffer.addStats(snapshot)
 					}
 				case list := <-s.histCh:
 					if err = s.reportHistory(conn, list); err != nil {
 						log.Warn("Requested history report failed", "err", err)
 					}
 				case head := <-headCh:
-					if err = s.reportBlock(conn, head); err != nil {
+					details := s.assembleBlockStats(head)
+					s.publish(Report{Block: details})
+					if err = s.reportBlock(conn, details); err != nil {
 						log.Warn("Block stats report failed", "err", err)
+						buffer.addBlock(details)
 					}
-					if err = s.reportPending(conn); err != nil {
+					pending := s.assemblePendStats()
+					s.publish(Report{Pending: pending})
+					if err = s.reportPending(conn, pending); err != nil {
 						log.Warn("Post-block transaction stats report failed", "err", err)
 					}
 				case <-txCh:
-					if err = s.reportPending(conn); err != nil {
+					pending := s.assemblePendStats()
+					s.publish(Report{Pending: pending})
+					if err = s.reportPending(conn, pending); err != nil {
 						log.Warn("Transaction stats report failed", "err", err)
 					}
 				}
@@ -484,20 +559,21 @@ func (s *Service) login(conn *connWrapper) error {
 	return nil
 }
 
-// report collects all possible data to report and send it to the stats server.
-// This should only be used on reconnects or rarely to avoid overloading the
-// server. Use the individual Methods for reporting subscribed events.
-func (s *Service) report(conn *connWrapper) error {
+// report collects all possible data to report and send it to the stats server,
+// using the given snapshot for the node and pending transaction stats. This
+// should only be used on reconnects or rarely to avoid overloading the server.
+// Use the individual Methods for reporting subscribed events.
+func (s *Service) report(conn *connWrapper, snapshot *statsSnapshot) error {
 	if err := s.reportLatency(conn); err != nil {
 		return err
 	}
-	if err := s.reportBlock(conn, nil); err != nil {
+	if err := s.reportBlock(conn, s.assembleBlockStats(nil)); err != nil {
 		return err
 	}
-	if err := s.reportPending(conn); err != nil {
+	if err := s.reportPending(conn, snapshot.pending); err != nil {
 		return err
 	}
-	if err := s.reportStats(conn); err != nil {
+	if err := s.reportStats(conn, snapshot.node); err != nil {
 		return err
 	}
 	return nil
@@ -540,8 +616,8 @@ func (s *Service) reportLatency(conn *connWrapper) error {
 	return conn.WriteJSON(stats)
 }
 
-// blockStats is the information to report about individual blocks.
-type blockStats struct {
+// BlockStats is the information to report about individual blocks.
+type BlockStats struct {
 	Number     *big.Int       `json:"number"`
 	Hash       common.Hash    `json:"hash"`
 	ParentHash common.Hash    `json:"parentHash"`
@@ -551,14 +627,14 @@ type blockStats struct {
 	GasLimit   uint64         `json:"gasLimit"`
 	Diff       string         `json:"difficulty"`
 	TotalDiff  string         `json:"totalDifficulty"`
-	Txs        []txStats      `json:"transactions"`
+	Txs        []TxStats      `json:"transactions"`
 	TxHash     common.Hash    `json:"transactionsRoot"`
 	Root       common.Hash    `json:"stateRoot"`
 	Uncles     uncleStats     `json:"uncles"`
 }
 
-// txStats is the information to report about individual transactions.
-type txStats struct {
+// TxStats is the information to report about individual transactions.
+type TxStats struct {
 	Hash common.Hash `json:"hash"`
 }
 
@@ -573,11 +649,8 @@ func (s uncleStats) MarshalJSON() ([]byte, error) {
 	return []byte("[]"), nil
 }
 
-// reportBlock retrieves the current chain head and reports it to the stats server.
-func (s *Service) reportBlock(conn *connWrapper, block *types.Block) error {
-	// Gather the block details from the header or block chain
-	details := s.assembleBlockStats(block)
-
+// reportBlock reports the assembled stats of a chain head to the stats server.
+func (s *Service) reportBlock(conn *connWrapper, details *BlockStats) error {
 	// Assemble the block report and send it to the server
 	log.Trace("Sending new block to gdtustats", "number", details.Number, "hash", details.Hash)
 
@@ -593,12 +666,12 @@ func (s *Service) reportBlock(conn *connWrapper, block *types.Block) error {
 
 // assembleBlockStats retrieves any required metadata to report a single block
 // and assembles the block stats. If block is nil, the current head is processed.
-func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
+func (s *Service) assembleBlockStats(block *types.Block) *BlockStats {
 	// Gather the block infos from the local blockchain
 	var (
 		header *types.Header
 		td     *big.Int
-		txs    []txStats
+		txs    []TxStats
 		uncles []*types.Header
 	)
 
@@ -611,7 +684,7 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 		header = block.Header()
 		td = fullBackend.GetTd(context.Background(), header.Hash())
 
-		txs = make([]txStats, len(block.Transactions()))
+		txs = make([]TxStats, len(block.Transactions()))
 		for i, tx := range block.Transactions() {
 			txs[i].Hash = tx.Hash()
 		}
@@ -624,13 +697,13 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 			header = s.backend.CurrentHeader()
 		}
 		td = s.backend.GetTd(context.Background(), header.Hash())
-		txs = []txStats{}
+		txs = []TxStats{}
 	}
 
 	// Assemble and return the block stats
 	author, _ := s.engine.Author(header)
 
-	return &blockStats{
+	return &BlockStats{
 		Number:     header.Number,
 		Hash:       header.Hash(),
 		ParentHash: header.ParentHash,
@@ -667,7 +740,7 @@ func (s *Service) reportHistory(conn *connWrapper, list []uint64) error {
 		}
 	}
 	// Gather the batch of blocks to report
-	history := make([]*blockStats, len(indexes))
+	history := make([]*BlockStats, len(indexes))
 	for i, number := range indexes {
 		fullBackend, ok := s.backend.(fullNodeBackend)
 		// Retrieve the next block if it's known to us
@@ -704,24 +777,20 @@ func (s *Service) reportHistory(conn *connWrapper, list []uint64) error {
 	return conn.WriteJSON(report)
 }
 
-// pendStats is the information to report about pending transactions.
-type pendStats struct {
+// PendingStats is the information to report about pending transactions.
+type PendingStats struct {
 	Pending int `json:"pending"`
 }
 
-// reportPending retrieves the current number of pending transactions and reports
-// it to the stats server.
-func (s *Service) reportPending(conn *connWrapper) error {
-	// Retrieve the pending count from the local blockchain
-	pending, _ := s.backend.Stats()
-	// Assemble the transaction stats and send it to the server
-	log.Trace("Sending pending transactions to gdtustats", "count", pending)
+// reportPending reports the assembled pending transaction stats to the stats
+// server.
+func (s *Service) reportPending(conn *connWrapper, pending *PendingStats) error {
+	// Send the transaction stats to the server
+	log.Trace("Sending pending transactions to gdtustats", "count", pending.Pending)
 
 	stats := map[string]interface{}{
-		"id": s.node,
-		"stats": &pendStats{
-			Pending: pending,
-		},
+		"id":    s.node,
+		"stats": pending,
 	}
 	report := map[string][]interface{}{
 		"emit": {"pending", stats},
@@ -729,8 +798,16 @@ func (s *Service) reportPending(conn *connWrapper) error {
 	return conn.WriteJSON(report)
 }
 
-// nodeStats is the information to report about the local node.
-type nodeStats struct {
+// assemblePendStats retrieves the current number of pending transactions.
+func (s *Service) assemblePendStats() *PendingStats {
+	pending, _ := s.backend.Stats()
+	return &PendingStats{
+		Pending: pending,
+	}
+}
+
+// NodeStats is the information to report about the local node.
+type NodeStats struct {
 	Active   bool `json:"active"`
 	Syncing  bool `json:"syncing"`
 	Mining   bool `json:"mining"`
@@ -740,9 +817,25 @@ type nodeStats struct {
 	Uptime   int  `json:"uptime"`
 }
 
-// reportStats retrieves various stats about the node at the networking and
-// mining layer and reports it to the stats server.
-func (s *Service) reportStats(conn *connWrapper) error {
+// reportStats reports the assembled stats about the node at the networking and
+// mining layer to the stats server.
+func (s *Service) reportStats(conn *connWrapper, node *NodeStats) error {
+	// Send the node stats to the server
+	log.Trace("Sending node details to gdtustats")
+
+	stats := map[string]interface{}{
+		"id":    s.node,
+		"stats": node,
+	}
+	report := map[string][]interface{}{
+		"emit": {"stats", stats},
+	}
+	return conn.WriteJSON(report)
+}
+
+// assembleNodeStats gathers various stats about the node at the networking and
+// mining layer.
+func (s *Service) assembleNodeStats() *NodeStats {
 	// Gather the syncing and mining infos from the local miner instance
 	var (
 		mining   bool
@@ -765,23 +858,57 @@ func (s *Service) reportStats(conn *connWrapper) error {
 		sync := s.backend.Downloader().Progress()
 		syncing = s.backend.CurrentHeader().Number.Uint64() >= sync.HighestBlock
 	}
-	// Assemble the node stats and send it to the server
-	log.Trace("Sending node details to gdtustats")
+	return &NodeStats{
+		Active:   true,
+		Mining:   mining,
+		Hashrate: hashrate,
+		Peers:    s.server.PeerCount(),
+		GasPrice: gasprice,
+		Syncing:  syncing,
+		Uptime:   100,
+	}
+}
 
-	stats := map[string]interface{}{
-		"id": s.node,
-		"stats": &nodeStats{
-			Active:   true,
-			Mining:   mining,
-			Hashrate: hashrate,
-			Peers:    s.server.PeerCount(),
-			GasPrice: gasprice,
-			Syncing:  syncing,
-			Uptime:   100,
-		},
+// snapshotStats takes a snapshot of the node and pending transaction stats to
+// report once the stats server is reachable again.
+func (s *Service) snapshotStats() *statsSnapshot {
+	return &statsSnapshot{
+		time:    time.Now(),
+		node:    s.assembleNodeStats(),
+		pending: s.assemblePendStats(),
 	}
-	report := map[string][]interface{}{
-		"emit": {"stats", stats},
+}
+
+// replayBuffered sends the reports buffered while the stats server was
+// unreachable: the blocks as a history report, followed by the stats snapshots
+// along with the time they were taken at.
+func (s *Service) replayBuffered(conn *connWrapper, buffer *reportBuffer) error {
+	if history := buffer.history(); len(history) > 0 {
+		log.Trace("Sending buffered blocks to gdtustats", "first", history[len(history)-1].Number, "last", history[0].Number)
+
+		stats := map[string]interface{}{
+			"id":      s.node,
+			"history": history,
+		}
+		report := map[string][]interface{}{
+			"emit": {"history", stats},
+		}
+		if err := conn.WriteJSON(report); err != nil {
+			return err
+		}
 	}
-	return conn.WriteJSON(report)
+	for _, snapshot := range buffer.stats {
+		timestamp := snapshot.time.UnixNano() / int64(time.Millisecond)
+		reports := []map[string][]interface{}{
+			{"emit": {"stats", map[string]interface{}{"id": s.node, "stats": snapshot.node, "timestamp": timestamp}}},
+			{"emit": {"pending", map[string]interface{}{"id": s.node, "stats": snapshot.pending, "timestamp": timestamp}}},
+		}
+		for _, report := range reports {
+			if err := conn.WriteJSON(report); err != nil {
+				return err
+			}
+		}
+	}
+	buffer.reset()
+	return nil
 }
@@ -0,0 +1,108 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtustats
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/consensus/gdtuash"
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/event"
+	"github.com/c88032111/go-gdtu/gdtu/downloader"
+	"github.com/c88032111/go-gdtu/rpc"
+)
+
+// testBackend is a light backend feeding chain and transaction events to the
+// stats daemon on demand.
+type testBackend struct {
+	headFeed event.Feed
+	txFeed   event.Feed
+	head     *types.Header
+}
+
+func (b *testBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.headFeed.Subscribe(ch)
+}
+
+func (b *testBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
+	return b.txFeed.Subscribe(ch)
+}
+
+func (b *testBackend) CurrentHeader() *types.Header { return b.head }
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	return b.head, nil
+}
+
+func (b *testBackend) GetTd(ctx context.Context, hash common.Hash) *big.Int { return big.NewInt(1) }
+func (b *testBackend) Stats() (pending int, queued int)                     { return 3, 0 }
+func (b *testBackend) Downloader() *downloader.Downloader                   { return nil }
+
+// Tests that the assembled reports are delivered to the local subscribers even
+// if the stats server is unreachable.
+func TestSubscribeReports(t *testing.T) {
+	backend := &testBackend{head: &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1)}}
+	service := &Service{
+		backend: backend,
+		engine:  gdtuash.NewFaker(),
+		host:    "127.0.0.1:0",
+		pgdtuCh: make(chan struct{}),
+		histCh:  make(chan []uint64, 1),
+	}
+	reports := make(chan Report, 4)
+	sub := service.SubscribeReports(reports)
+	defer sub.Unsubscribe()
+
+	service.Start()
+	defer service.Stop()
+
+	// Wait for the daemon to subscribe to the chain events and feed a head
+	for backend.headFeed.Send(core.ChainHeadEvent{Block: types.NewBlockWithHeader(backend.head)}) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case report := <-reports:
+		if report.Block == nil || report.Block.Number.Uint64() != 1 {
+			t.Fatalf("block report mismatch: have %+v", report)
+		}
+		if report.Time.IsZero() {
+			t.Errorf("report time not set")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("block report timeout")
+	}
+	select {
+	case report := <-reports:
+		if report.Pending == nil || report.Pending.Pending != 3 {
+			t.Fatalf("pending report mismatch: have %+v", report)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("pending report timeout")
+	}
+	// Stopping the daemon should end the subscription
+	service.Stop()
+	select {
+	case <-sub.Err():
+	case <-time.After(time.Second):
+		t.Fatalf("subscription not closed on stop")
+	}
+}
@@ -660,13 +660,22 @@ func (b *Block) TransactionCount(ctx context.Context) (*int32, error) {
 	return &count, err
 }
 
-func (b *Block) Transactions(ctx context.Context) (*[]*Transaction, error) {
+func (b *Block) Transactions(ctx context.Context, args struct {
+	First *int32
+	Skip  *int32
+}) (*[]*Transaction, error) {
 	block, err := b.resolve(ctx)
 	if err != nil || block == nil {
 		return nil, err
 	}
-	ret := make([]*Transaction, 0, len(block.Transactions()))
-	for i, tx := range block.Transactions() {
+	txs := block.Transactions()
+	start, end, err := paginate(len(txs), args.First, args.Skip)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, end-start)
+	for i := start; i < end; i++ {
+		tx := txs[i]
 		ret = append(ret, &Transaction{
 			backend: b.backend,
 			hash:    tx.Hash(),
@@ -734,15 +743,44 @@ type BlockFilterCriteria struct {
 	Topics *[][]common.Hash
 }
 
-// runFilter accepts a filter and executes it, returning all its results as
-// `Log` objects.
-func runFilter(ctx context.Context, be gdtuapi.Backend, filter *filters.Filter) ([]*Log, error) {
+// paginate returns the bounds of the requested page of a list with the given
+// length. If first is nil, all items after the skipped ones are included.
+func paginate(length int, first, skip *int32) (int, int, error) {
+	start, end := 0, length
+	if skip != nil {
+		if *skip < 0 {
+			return 0, 0, errors.New("skip must not be negative")
+		}
+		if int(*skip) < length {
+			start = int(*skip)
+		} else {
+			start = length
+		}
+	}
+	if first != nil {
+		if *first < 0 {
+			return 0, 0, errors.New("first must not be negative")
+		}
+		if start+int(*first) < end {
+			end = start + int(*first)
+		}
+	}
+	return start, end, nil
+}
+
+// runFilter accepts a filter and executes it, returning the requested page of
+// its results as `Log` objects.
+func runFilter(ctx context.Context, be gdtuapi.Backend, filter *filters.Filter, first, skip *int32) ([]*Log, error) {
 	logs, err := filter.Logs(ctx)
 	if err != nil || logs == nil {
 		return nil, err
 	}
-	ret := make([]*Log, 0, len(logs))
-	for _, log := range logs {
+	start, end, err := paginate(len(logs), first, skip)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, end-start)
+	for _, log := range logs[start:end] {
 		ret = append(ret, &Log{
 			backend:     be,
 			transaction: &Transaction{backend: be, hash: log.TxHash},
@@ -752,7 +790,11 @@ func runFilter(ctx context.Context, be gdtuapi.Backend, filter *filters.Filter)
 	return ret, nil
 }
 
-func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+func (b *Block) Logs(ctx context.Context, args struct {
+	Filter BlockFilterCriteria
+	First  *int32
+	Skip   *int32
+}) ([]*Log, error) {
 	var addresses []common.Address
 	if args.Filter.Addresses != nil {
 		addresses = *args.Filter.Addresses
@@ -772,8 +814,8 @@ func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteri
 	// Construct the range filter
 	filter := filters.NewBlockFilter(b.backend, hash, addresses, topics)
 
-	// Run the filter and return all the logs
-	return runFilter(ctx, b.backend, filter)
+	// Run the filter and return the requested logs
+	return runFilter(ctx, b.backend, filter, args.First, args.Skip)
 }
 
 func (b *Block) Account(ctx context.Context, args struct {
@@ -869,13 +911,21 @@ func (p *Pending) TransactionCount(ctx context.Context) (int32, error) {
 	return int32(len(txs)), err
 }
 
-func (p *Pending) Transactions(ctx context.Context) (*[]*Transaction, error) {
+func (p *Pending) Transactions(ctx context.Context, args struct {
+	First *int32
+	Skip  *int32
+}) (*[]*Transaction, error) {
 	txs, err := p.backend.GetPoolTransactions()
 	if err != nil {
 		return nil, err
 	}
-	ret := make([]*Transaction, 0, len(txs))
-	for i, tx := range txs {
+	start, end, err := paginate(len(txs), args.First, args.Skip)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, end-start)
+	for i := start; i < end; i++ {
+		tx := txs[i]
 		ret = append(ret, &Transaction{
 			backend: p.backend,
 			hash:    tx.Hash(),
@@ -1044,7 +1094,11 @@ type FilterCriteria struct {
 	Topics *[][]common.Hash
 }
 
-func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria }) ([]*Log, error) {
+func (r *Resolver) Logs(ctx context.Context, args struct {
+	Filter FilterCriteria
+	First  *int32
+	Skip   *int32
+}) ([]*Log, error) {
 	// Convert the RPC block numbers into internal representations
 	begin := rpc.LatestBlockNumber.Int64()
 	if args.Filter.FromBlock != nil {
@@ -1064,7 +1118,7 @@ func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria
 	}
 	// Construct the range filter
 	filter := filters.NewRangeFilter(filters.Backend(r.backend), begin, end, addresses, topics)
-	return runFilter(ctx, r.backend, filter)
+	return runFilter(ctx, r.backend, filter, args.First, args.Skip)
 }
 
 func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
@@ -17,6 +17,7 @@
 package graphql
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -122,6 +123,16 @@ func TestGraphQLBlockSerialization(t *testing.T) {
 			want: `{"errors":[{"message":"strconv.ParseInt: parsing \"a\": invalid syntax"}],"data":{}}`,
 			code: 400,
 		},
+		{ // Should paginate the transactions of a block
+			body: `{"query": "{block{transactions(first:1,skip:2){hash}}}","variables": null}`,
+			want: `{"data":{"block":{"transactions":[]}}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{block{transactions(first:-1){hash}}}","variables": null}`,
+			want: `{"errors":[{"message":"first must not be negative","path":["block","transactions"]}],"data":{"block":{"transactions":null}}}`,
+			code: 400,
+		},
 		{
 			body: `{"query": "{bleh{number}}","variables": null}"`,
 			want: `{"errors":[{"message":"Cannot query field \"bleh\" on type \"Query\".","locations":[{"line":1,"column":2}]}]}`,
@@ -157,6 +168,68 @@ func TestGraphQLBlockSerialization(t *testing.T) {
 	}
 }
 
+// Tests that GraphQL queries can be run over the JSON-RPC API.
+func TestGraphQLRPCQuery(t *testing.T) {
+	stack := createNode(t, true)
+	defer stack.Close()
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	client, err := stack.Attach()
+	if err != nil {
+		t.Fatalf("could not attach to node: %v", err)
+	}
+	defer client.Close()
+
+	var resp struct {
+		Data   json.RawMessage
+		Errors []interface{}
+	}
+	if err := client.Call(&resp, "graphql_query", "{block{number}}"); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if have, want := string(resp.Data), `{"block":{"number":10}}`; have != want || len(resp.Errors) != 0 {
+		t.Fatalf("response mismatch: have %s (errors %v), want %s", have, resp.Errors, want)
+	}
+	vars := map[string]interface{}{"num": "3"}
+	if err := client.Call(&resp, "graphql_query", "query q($num: Lgdtu) {block(number: $num){number}}", "q", vars); err != nil {
+		t.Fatalf("query with variables failed: %v", err)
+	}
+	if have, want := string(resp.Data), `{"block":{"number":3}}`; have != want {
+		t.Fatalf("response mismatch: have %s, want %s", have, want)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	num := func(n int32) *int32 { return &n }
+	tests := []struct {
+		length      int
+		first, skip *int32
+		start, end  int
+		fail        bool
+	}{
+		{length: 5, start: 0, end: 5},
+		{length: 5, first: num(2), start: 0, end: 2},
+		{length: 5, skip: num(2), start: 2, end: 5},
+		{length: 5, first: num(2), skip: num(2), start: 2, end: 4},
+		{length: 5, first: num(10), skip: num(4), start: 4, end: 5},
+		{length: 5, skip: num(10), start: 5, end: 5},
+		{length: 5, first: num(0), start: 0, end: 0},
+		{length: 5, first: num(-1), fail: true},
+		{length: 5, skip: num(-1), fail: true},
+	}
+	for i, tt := range tests {
+		start, end, err := paginate(tt.length, tt.first, tt.skip)
+		if (err != nil) != tt.fail {
+			t.Errorf("test %d: error mismatch: have %v, want failure %v", i, err, tt.fail)
+			continue
+		}
+		if !tt.fail && (start != tt.start || end != tt.end) {
+			t.Errorf("test %d: bounds mismatch: have [%d, %d), want [%d, %d)", i, start, end, tt.start, tt.end)
+		}
+	}
+}
+
 // Tests that a graphQL request is not handled successfully when graphql is not enabled on the specified endpoint
 func TestGraphQLHTTPOnSamePort_GQLRequest_Unsuccessful(t *testing.T) {
 	stack := createNode(t, false)
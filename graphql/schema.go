@@ -195,13 +195,15 @@ const schema string = `
         ommerHash: Bytes32!
         # Transactions is a list of transactions associated with this block. If
         # transactions are unavailable for this block, this field will be null.
-        transactions: [Transaction!]
+        # At most first transactions are returned after skipping the given number.
+        transactions(first: Int, skip: Int): [Transaction!]
         # TransactionAt returns the transaction at the specified index. If
         # transactions are unavailable for this block, or if the index is out of
         # bounds, this field will be null.
         transactionAt(index: Int!): Transaction
-        # Logs returns a filtered set of logs from this block.
-        logs(filter: BlockFilterCriteria!): [Log!]!
+        # Logs returns a filtered set of logs from this block. At most first logs
+        # are returned after skipping the given number.
+        logs(filter: BlockFilterCriteria!, first: Int, skip: Int): [Log!]!
         # Account fetches an Gdtu account at the current block's state.
         account(address: Address!): Account!
         # Call executes a local call operation at the current block's state.
@@ -283,8 +285,9 @@ const schema string = `
     type Pending {
       # TransactionCount is the number of transactions in the pending state.
       transactionCount: Int!
-      # Transactions is a list of transactions in the current pending state.
-      transactions: [Transaction!]
+      # Transactions is a list of transactions in the current pending state. At
+      # most first transactions are returned after skipping the given number.
+      transactions(first: Int, skip: Int): [Transaction!]
       # Account fetches an Gdtu account for the pending state.
       account(address: Address!): Account!
       # Call executes a local call operation for the pending state.
@@ -305,8 +308,9 @@ const schema string = `
         pending: Pending!
         # Transaction returns a transaction specified by its hash.
         transaction(hash: Bytes32!): Transaction
-        # Logs returns log entries matching the provided filter.
-        logs(filter: FilterCriteria!): [Log!]!
+        # Logs returns log entries matching the provided filter. At most first
+        # logs are returned after skipping the given number.
+        logs(filter: FilterCriteria!, first: Int, skip: Int): [Log!]!
         # GasPrice returns the node's estimate of a gas price sufficient to
         # ensure a transaction is mined in a timely fashion.
         gasPrice: BigInt!
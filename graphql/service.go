@@ -17,11 +17,13 @@
 package graphql
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
 	"github.com/c88032111/go-gdtu/internal/gdtuapi"
 	"github.com/c88032111/go-gdtu/node"
+	"github.com/c88032111/go-gdtu/rpc"
 	"github.com/graph-gophers/graphql-go"
 )
 
@@ -55,6 +57,28 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// PublicGraphQLAPI exposes the GraphQL schema over the JSON-RPC transports, so
+// the same queries can be run over IPC and WebSocket connections.
+type PublicGraphQLAPI struct {
+	schema *graphql.Schema
+}
+
+// Query executes a GraphQL query, returning its data and errors the same way
+// the HTTP endpoint does.
+func (api *PublicGraphQLAPI) Query(ctx context.Context, query string, operationName *string, variables *map[string]interface{}) *graphql.Response {
+	var (
+		op   string
+		vars map[string]interface{}
+	)
+	if operationName != nil {
+		op = *operationName
+	}
+	if variables != nil {
+		vars = *variables
+	}
+	return api.schema.Exec(ctx, query, op, vars)
+}
+
 // New constructs a new GraphQL service instance.
 func New(stack *node.Node, backend gdtuapi.Backend, cors, vhosts []string) error {
 	if backend == nil {
@@ -76,6 +100,13 @@ func newHandler(stack *node.Node, backend gdtuapi.Backend, cors, vhosts []string
 	h := handler{Schema: s}
 	handler := node.NewHTTPHandlerStack(h, cors, vhosts)
 
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "graphql",
+		Version:   "1.0",
+		Service:   &PublicGraphQLAPI{schema: s},
+		Public:    true,
+	}})
+
 	stack.RegisterHandler("GraphQL UI", "/graphql/ui", GraphiQL{})
 	stack.RegisterHandler("GraphQL", "/graphql", handler)
 	stack.RegisterHandler("GraphQL", "/graphql/", handler)
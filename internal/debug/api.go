@@ -49,6 +49,9 @@ type HandlerT struct {
 	cpuFile   string
 	traceW    io.WriteCloser
 	traceFile string
+	profiles  *profileStore
+	blockRate int        // Block profile rate set through the API, restored after captures
+	samplerMu sync.Mutex // Serializes the block and mutex profile captures
 }
 
 // Verbosity sets the log verbosity ceiling. The verbosity of individual packages
@@ -153,7 +156,11 @@ func (*HandlerT) BlockProfile(file string, nsec uint) error {
 
 // SetBlockProfileRate sets the rate of goroutine block profile data collection.
 // rate 0 disables block profiling.
-func (*HandlerT) SetBlockProfileRate(rate int) {
+func (h *HandlerT) SetBlockProfileRate(rate int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.blockRate = rate
 	runtime.SetBlockProfileRate(rate)
 }
 
@@ -0,0 +1,311 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/log"
+)
+
+const (
+	// profileRetention is the time captured profiles are kept for download.
+	profileRetention = time.Hour
+
+	// maxProfiles is the maximum number of profiles kept, the oldest finished
+	// profiles are dropped when it is exceeded.
+	maxProfiles = 32
+
+	// maxProfileDuration is the longest a profile may be captured for.
+	maxProfileDuration = 10 * time.Minute
+)
+
+var (
+	errUnknownProfile  = errors.New("unknown profile")
+	errProfileRunning  = errors.New("profile still being captured")
+	errTooManyProfiles = errors.New("too many profiles being captured")
+	errProfileTooLong  = fmt.Errorf("profile duration exceeds %v", maxProfileDuration)
+	errInvalidProfile  = errors.New("invalid profile kind, want cpu, heap, block, mutex or goroutine")
+)
+
+// ProfileInfo describes a profile captured through the API.
+type ProfileInfo struct {
+	ID       string        `json:"id"`
+	Kind     string        `json:"kind"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	Done     bool          `json:"done"`
+	Size     int           `json:"size"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// profile is a profile kept for download.
+type profile struct {
+	info    ProfileInfo
+	data    []byte
+	expires time.Time
+}
+
+// profileStore keeps the profiles captured through the API in memory, so they
+// can be downloaded over RPC by operators without access to the file system.
+type profileStore struct {
+	lock     sync.Mutex
+	profiles map[string]*profile
+	counter  uint64
+	now      func() time.Time
+}
+
+func newProfileStore() *profileStore {
+	return &profileStore{profiles: make(map[string]*profile), now: time.Now}
+}
+
+// expire drops the profiles past their retention, and the oldest finished ones
+// above the given count. It must be called with the lock held.
+func (s *profileStore) expire(limit int) {
+	now := s.now()
+	for id, p := range s.profiles {
+		if p.info.Done && now.After(p.expires) {
+			delete(s.profiles, id)
+		}
+	}
+	if len(s.profiles) <= limit {
+		return
+	}
+	var done []*profile
+	for _, p := range s.profiles {
+		if p.info.Done {
+			done = append(done, p)
+		}
+	}
+	sort.Slice(done, func(i, j int) bool { return done[i].info.Started.Before(done[j].info.Started) })
+	for i := 0; i < len(done) && len(s.profiles) > limit; i++ {
+		delete(s.profiles, done[i].info.ID)
+	}
+}
+
+// add registers a new profile being captured, returning its ID.
+func (s *profileStore) add(kind string, duration time.Duration) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.expire(maxProfiles - 1)
+	if len(s.profiles) >= maxProfiles {
+		return "", errTooManyProfiles
+	}
+	s.counter++
+	id := fmt.Sprintf("%s-%d", kind, s.counter)
+	s.profiles[id] = &profile{info: ProfileInfo{ID: id, Kind: kind, Started: s.now(), Duration: duration}}
+	return id, nil
+}
+
+// finish stores the data of a captured profile, reporting whgdtuer the profile
+// is still wanted.
+func (s *profileStore) finish(id string, data []byte, err error) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	p := s.profiles[id]
+	if p == nil {
+		return false
+	}
+	p.info.Done, p.info.Size, p.data = true, len(data), data
+	if err != nil {
+		p.info.Error = err.Error()
+	}
+	p.expires = s.now().Add(profileRetention)
+	return true
+}
+
+// CaptureProfile starts capturing a profile of the given kind (cpu, heap, block,
+// mutex or goroutine) for nsec seconds in the background. The cpu, block and
+// mutex profiles record the events during that time, the heap and goroutine
+// profiles are snapshots taken at its end. The returned ID identifies the
+// profile for download, it is kept for an hour after completion.
+func (h *HandlerT) CaptureProfile(kind string, nsec uint) (string, error) {
+	duration := time.Duration(nsec) * time.Second
+	if duration > maxProfileDuration {
+		return "", errProfileTooLong
+	}
+	var capture func(time.Duration) ([]byte, error)
+	switch kind {
+	case "cpu":
+		capture = h.captureCPUProfile
+	case "block":
+		capture = h.captureBlockProfile
+	case "mutex":
+		capture = h.captureMutexProfile
+	case "heap", "goroutine":
+		capture = func(d time.Duration) ([]byte, error) {
+			return captureProfile(kind, d)
+		}
+	default:
+		return "", errInvalidProfile
+	}
+	id, err := h.store().add(kind, duration)
+	if err != nil {
+		return "", err
+	}
+	log.Info("Capturing profile", "id", id, "duration", duration)
+	go func() {
+		data, err := capture(duration)
+		if !h.store().finish(id, data, err) {
+			return
+		}
+		if err != nil {
+			log.Warn("Failed to capture profile", "id", id, "err", err)
+			return
+		}
+		log.Info("Captured profile", "id", id, "size", len(data))
+	}()
+	return id, nil
+}
+
+// Profiles lists the profiles captured through the API, in capture order.
+func (h *HandlerT) Profiles() []ProfileInfo {
+	s := h.store()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.expire(maxProfiles)
+	infos := make([]ProfileInfo, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		infos = append(infos, p.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Started.Before(infos[j].Started) })
+	return infos
+}
+
+// DownloadProfile returns the pprof encoded data of a captured profile.
+func (h *HandlerT) DownloadProfile(id string) (hexutil.Bytes, error) {
+	s := h.store()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.expire(maxProfiles)
+	p := s.profiles[id]
+	switch {
+	case p == nil:
+		return nil, errUnknownProfile
+	case !p.info.Done:
+		return nil, errProfileRunning
+	case p.info.Error != "":
+		return nil, errors.New(p.info.Error)
+	}
+	return p.data, nil
+}
+
+// DeleteProfile drops a captured profile. The data of profiles still being
+// captured is discarded on completion.
+func (h *HandlerT) DeleteProfile(id string) error {
+	s := h.store()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.profiles[id] == nil {
+		return errUnknownProfile
+	}
+	delete(s.profiles, id)
+	return nil
+}
+
+// store returns the store of the captured profiles, creating it on the
+// first use.
+func (h *HandlerT) store() *profileStore {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.profiles == nil {
+		h.profiles = newProfileStore()
+	}
+	return h.profiles
+}
+
+// captureBlockProfile records a block profile for the given duration, sampling
+// every blocking event and restoring the configured rate afterwards.
+func (h *HandlerT) captureBlockProfile(duration time.Duration) ([]byte, error) {
+	h.samplerMu.Lock()
+	defer h.samplerMu.Unlock()
+
+	runtime.SetBlockProfileRate(1)
+	defer func() {
+		h.mu.Lock()
+		runtime.SetBlockProfileRate(h.blockRate)
+		h.mu.Unlock()
+	}()
+	return captureProfile("block", duration)
+}
+
+// captureMutexProfile records a mutex profile for the given duration, sampling
+// every contention event and restoring the previous fraction afterwards.
+func (h *HandlerT) captureMutexProfile(duration time.Duration) ([]byte, error) {
+	h.samplerMu.Lock()
+	defer h.samplerMu.Unlock()
+
+	defer runtime.SetMutexProfileFraction(runtime.SetMutexProfileFraction(1))
+	return captureProfile("mutex", duration)
+}
+
+// captureCPUProfile records a CPU profile for the given duration, unless CPU
+// profiling is already in progress.
+func (h *HandlerT) captureCPUProfile(duration time.Duration) ([]byte, error) {
+	h.mu.Lock()
+	if h.cpuW != nil {
+		h.mu.Unlock()
+		return nil, errors.New("CPU profiling already in progress")
+	}
+	buf := new(bytes.Buffer)
+	if err := pprof.StartCPUProfile(buf); err != nil {
+		h.mu.Unlock()
+		return nil, err
+	}
+	h.cpuW, h.cpuFile = nopCloser{buf}, "memory"
+	h.mu.Unlock()
+
+	time.Sleep(duration)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if w, ok := h.cpuW.(nopCloser); !ok || w.Buffer != buf {
+		return nil, errors.New("CPU profile stopped externally")
+	}
+	pprof.StopCPUProfile()
+	h.cpuW, h.cpuFile = nil, ""
+	return buf.Bytes(), nil
+}
+
+// captureProfile waits for the given duration and writes the named profile.
+func captureProfile(name string, duration time.Duration) ([]byte, error) {
+	time.Sleep(duration)
+
+	buf := new(bytes.Buffer)
+	if err := pprof.Lookup(name).WriteTo(buf, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type nopCloser struct{ *bytes.Buffer }
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,121 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// waitProfile waits until the profile of the given ID is captured.
+func waitProfile(t *testing.T, h *HandlerT, id string) ProfileInfo {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		for _, info := range h.Profiles() {
+			if info.ID == id && info.Done {
+				return info
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("profile %s not captured", id)
+	return ProfileInfo{}
+}
+
+func TestCaptureProfile(t *testing.T) {
+	h := new(HandlerT)
+	if _, err := h.CaptureProfile("unknown", 0); err != errInvalidProfile {
+		t.Fatalf("invalid kind error mismatch: have %v, want %v", err, errInvalidProfile)
+	}
+	if _, err := h.CaptureProfile("heap", uint(maxProfileDuration/time.Second)+1); err != errProfileTooLong {
+		t.Fatalf("duration error mismatch: have %v, want %v", err, errProfileTooLong)
+	}
+	for _, kind := range []string{"heap", "goroutine", "block", "mutex", "cpu"} {
+		id, err := h.CaptureProfile(kind, 0)
+		if err != nil {
+			t.Fatalf("%s: failed to start profile: %v", kind, err)
+		}
+		info := waitProfile(t, h, id)
+		if info.Kind != kind || info.Error != "" || info.Size == 0 {
+			t.Fatalf("%s: profile info mismatch: %+v", kind, info)
+		}
+		data, err := h.DownloadProfile(id)
+		if err != nil || len(data) != info.Size {
+			t.Fatalf("%s: download failed: %v, %d bytes", kind, err, len(data))
+		}
+	}
+	if have := len(h.Profiles()); have != 5 {
+		t.Fatalf("profile count mismatch: have %d, want 5", have)
+	}
+	id := h.Profiles()[0].ID
+	if err := h.DeleteProfile(id); err != nil {
+		t.Fatalf("failed to delete profile: %v", err)
+	}
+	if _, err := h.DownloadProfile(id); err != errUnknownProfile {
+		t.Fatalf("deleted profile error mismatch: have %v, want %v", err, errUnknownProfile)
+	}
+}
+
+// Tests that capturing mutex profiles restores the previously configured
+// sampling fraction.
+func TestCaptureProfileRates(t *testing.T) {
+	h := new(HandlerT)
+	defer runtime.SetMutexProfileFraction(runtime.SetMutexProfileFraction(5))
+
+	id, err := h.CaptureProfile("mutex", 0)
+	if err != nil {
+		t.Fatalf("failed to start profile: %v", err)
+	}
+	waitProfile(t, h, id)
+	if fraction := runtime.SetMutexProfileFraction(-1); fraction != 5 {
+		t.Fatalf("mutex profile fraction not restored: have %d, want 5", fraction)
+	}
+}
+
+func TestProfileExpiry(t *testing.T) {
+	var (
+		h   = new(HandlerT)
+		now = time.Now()
+	)
+	h.store().now = func() time.Time { return now }
+
+	id, _ := h.CaptureProfile("heap", 0)
+	waitProfile(t, h, id)
+
+	now = now.Add(profileRetention - time.Second)
+	if _, err := h.DownloadProfile(id); err != nil {
+		t.Fatalf("profile expired early: %v", err)
+	}
+	now = now.Add(2 * time.Second)
+	if _, err := h.DownloadProfile(id); err != errUnknownProfile {
+		t.Fatalf("expired profile error mismatch: have %v, want %v", err, errUnknownProfile)
+	}
+	// Finished profiles are dropped above the maximum count, the oldest first
+	for i := 0; i < maxProfiles+4; i++ {
+		now = now.Add(time.Second)
+		id, err := h.CaptureProfile("goroutine", 0)
+		if err != nil {
+			t.Fatalf("failed to start profile %d: %v", i, err)
+		}
+		waitProfile(t, h, id)
+	}
+	infos := h.Profiles()
+	if len(infos) != maxProfiles || infos[0].ID != "goroutine-6" {
+		t.Fatalf("retained profiles mismatch: %d profiles, oldest %s", len(infos), infos[0].ID)
+	}
+}
@@ -22,7 +22,9 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/c88032111/go-gdtu/accounts"
@@ -38,6 +40,7 @@ import (
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/core/vm"
 	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/gdtu/gasprice"
 	"github.com/c88032111/go-gdtu/log"
 	"github.com/c88032111/go-gdtu/p2p"
 	"github.com/c88032111/go-gdtu/params"
@@ -64,6 +67,60 @@ func (s *PublicGdtuAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
 	return (*hexutil.Big)(price), err
 }
 
+// MaxPriorityFeePerGas returns a suggestion for a gas tip. As blocks have no
+// base fee, the tip is the entire gas price.
+func (s *PublicGdtuAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	price, err := s.b.SuggestPrice(ctx)
+	return (*hexutil.Big)(price), err
+}
+
+// FeeSuggestion is the fee to pay for a transaction, both as legacy gas price and
+// as tip.
+type FeeSuggestion struct {
+	GasPrice             *hexutil.Big `json:"gasPrice"`
+	MaxPriorityFeePerGas *hexutil.Big `json:"maxPriorityFeePerGas"`
+}
+
+// FeeUrgency contains the fees suggested for various inclusion urgencies.
+type FeeUrgency struct {
+	Slow     FeeSuggestion `json:"slow"`
+	Standard FeeSuggestion `json:"standard"`
+	Fast     FeeSuggestion `json:"fast"`
+}
+
+// FeeSuggestions is the bundle of fee recommendations returned by FeeSuggestions.
+type FeeSuggestions struct {
+	FeeSuggestion
+	TipPercentiles map[string]*hexutil.Big `json:"tipPercentiles"` // Tips paid by recent transactions, keyed by percentile
+	Urgency        FeeUrgency              `json:"urgency"`
+}
+
+// FeeSuggestions returns the suggested gas price along with the tips paid by
+// recent transactions and the fees suggested for slow, standard and fast
+// inclusion, all derived from the same sample of recent blocks.
+func (s *PublicGdtuAPI) FeeSuggestions(ctx context.Context) (*FeeSuggestions, error) {
+	fees, err := s.b.SuggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	suggestion := func(price *big.Int) FeeSuggestion {
+		return FeeSuggestion{GasPrice: (*hexutil.Big)(price), MaxPriorityFeePerGas: (*hexutil.Big)(price)}
+	}
+	result := &FeeSuggestions{
+		FeeSuggestion:  suggestion(fees.Standard),
+		TipPercentiles: make(map[string]*hexutil.Big, len(fees.Tips)),
+		Urgency: FeeUrgency{
+			Slow:     suggestion(fees.Slow),
+			Standard: suggestion(fees.Standard),
+			Fast:     suggestion(fees.Fast),
+		},
+	}
+	for i, tip := range fees.Tips {
+		result.TipPercentiles[strconv.Itoa(gasprice.FeePercentiles[i])] = (*hexutil.Big)(tip)
+	}
+	return result, nil
+}
+
 // Syncing returns false in case the node is currently not syncing with the network. It can be up to date or has not
 // yet received the latest block headers from its pears. In case it is synchronizing:
 // - startingBlock: block number this node started to synchronise from
@@ -347,13 +404,39 @@ func (s *PrivateAccountAPI) LockAccount(addr common.Address) bool {
 	return false
 }
 
+// KeystoreMigration returns the progress of re-encrypting the keys of the local
+// keystore with the configured key derivation function.
+func (s *PrivateAccountAPI) KeystoreMigration() (keystore.KDFMigration, error) {
+	ks, err := fetchKeystore(s.am)
+	if err != nil {
+		return keystore.KDFMigration{}, err
+	}
+	return ks.KDFMigration(), nil
+}
+
+// findSigner looks up the wallet containing the requested signer. If a
+// derivation path is given, the account is derived on the fly from the
+// hierarchical deterministic wallets (e.g. USB hardware wallets), so it doesn't
+// need to be pinned beforehand.
+func (s *PrivateAccountAPI) findSigner(addr common.Address, path *string) (accounts.Wallet, accounts.Account, error) {
+	if path == nil || *path == "" {
+		account := accounts.Account{Address: addr}
+		wallet, err := s.am.Find(account)
+		return wallet, account, err
+	}
+	derivPath, err := accounts.ParseDerivationPath(*path)
+	if err != nil {
+		return nil, accounts.Account{}, err
+	}
+	return s.am.FindDerived(addr, derivPath)
+}
+
 // signTransaction sets defaults and signs the given transaction
 // NOTE: the caller needs to ensure that the nonceLock is held, if applicable,
 // and release it after the transaction has been submitted to the tx pool
-func (s *PrivateAccountAPI) signTransaction(ctx context.Context, args *SendTxArgs, passwd string) (*types.Transaction, error) {
+func (s *PrivateAccountAPI) signTransaction(ctx context.Context, args *SendTxArgs, passwd string, path *string) (*types.Transaction, error) {
 	// Look up the wallet containing the requested signer
-	account := accounts.Account{Address: args.From}
-	wallet, err := s.am.Find(account)
+	wallet, account, err := s.findSigner(args.From, path)
 	if err != nil {
 		return nil, err
 	}
@@ -369,15 +452,16 @@ func (s *PrivateAccountAPI) signTransaction(ctx context.Context, args *SendTxArg
 
 // SendTransaction will create a transaction from the given arguments and
 // tries to sign it with the key associated with args.From. If the given passwd isn't
-// able to decrypt the key it fails.
-func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs, passwd string) (common.Hash, error) {
+// able to decrypt the key it fails. The optional derivation path selects the
+// signing account of a hardware wallet which was not derived beforehand.
+func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs, passwd string, path *string) (common.Hash, error) {
 	if args.Nonce == nil {
 		// Hold the addresse's mutex around signing to prevent concurrent assignment of
 		// the same nonce to multiple accounts.
 		s.nonceLock.LockAddr(args.From)
 		defer s.nonceLock.UnlockAddr(args.From)
 	}
-	signed, err := s.signTransaction(ctx, &args, passwd)
+	signed, err := s.signTransaction(ctx, &args, passwd, path)
 	if err != nil {
 		log.Warn("Failed transaction send attempt", "from", args.From, "to", args.To, "value", args.Value.ToInt(), "err", err)
 		return common.Hash{}, err
@@ -388,8 +472,8 @@ func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs
 // SignTransaction will create a transaction from the given arguments and
 // tries to sign it with the key associated with args.From. If the given passwd isn't
 // able to decrypt the key it fails. The transaction is returned in RLP-form, not broadcast
-// to other nodes
-func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args SendTxArgs, passwd string) (*SignTransactionResult, error) {
+// to other nodes. The optional derivation path is handled as in SendTransaction.
+func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args SendTxArgs, passwd string, path *string) (*SignTransactionResult, error) {
 	// No need to obtain the noncelock mutex, since we won't be sending this
 	// tx into the transaction pool, but right back to the user
 	if args.Gas == nil {
@@ -405,7 +489,7 @@ func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args SendTxArgs
 	if err := checkTxFee(args.GasPrice.ToInt(), uint64(*args.Gas), s.b.RPCTxFeeCap()); err != nil {
 		return nil, err
 	}
-	signed, err := s.signTransaction(ctx, &args, passwd)
+	signed, err := s.signTransaction(ctx, &args, passwd, path)
 	if err != nil {
 		log.Warn("Failed transaction sign attempt", "from", args.From, "to", args.To, "value", args.Value.ToInt(), "err", err)
 		return nil, err
@@ -424,13 +508,13 @@ func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args SendTxArgs
 // where the V value will be 27 or 28 for legacy reasons.
 //
 // The key used to calculate the signature is decrypted with the given password.
+// If a derivation path is given, the account is derived from the hardware
+// wallets instead of being looked up amongst the known ones.
 //
 // https://github.com/c88032111/go-gdtu/wiki/Management-APIs#personal_sign
-func (s *PrivateAccountAPI) Sign(ctx context.Context, data hexutil.Bytes, addr common.Address, passwd string) (hexutil.Bytes, error) {
+func (s *PrivateAccountAPI) Sign(ctx context.Context, data hexutil.Bytes, addr common.Address, passwd string, path *string) (hexutil.Bytes, error) {
 	// Look up the wallet containing the requested signer
-	account := accounts.Account{Address: addr}
-
-	wallet, err := s.b.AccountManager().Find(account)
+	wallet, account, err := s.findSigner(addr, path)
 	if err != nil {
 		return nil, err
 	}
@@ -472,8 +556,8 @@ func (s *PrivateAccountAPI) EcRecover(ctx context.Context, data, sig hexutil.Byt
 
 // SignAndSendTransaction was renamed to SendTransaction. This Method is deprecated
 // and will be removed in the future. It primary goal is to give clients time to update.
-func (s *PrivateAccountAPI) SignAndSendTransaction(ctx context.Context, args SendTxArgs, passwd string) (common.Hash, error) {
-	return s.SendTransaction(ctx, args, passwd)
+func (s *PrivateAccountAPI) SignAndSendTransaction(ctx context.Context, args SendTxArgs, passwd string, path *string) (common.Hash, error) {
+	return s.SendTransaction(ctx, args, passwd, path)
 }
 
 // InitializeWallet initializes a new wallet at the provided URL, by generating and returning a new private key.
@@ -674,6 +758,32 @@ func (s *PublicBlockChainAPI) GetBlockByHash(ctx context.Context, hash common.Ha
 	return nil, err
 }
 
+// GetBlockReceipts returns the receipts of all transactions in the given block,
+// in the same format as GetTransactionReceipt. It saves indexers from having
+// to retrieve the receipts of a block one transaction at a time.
+func (s *PublicBlockChainAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	block, err := s.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil {
+		// Unknown blocks are reported as null, same as the other block getters
+		return nil, err
+	}
+	receipts, err := s.b.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if len(txs) != len(receipts) {
+		return nil, fmt.Errorf("receipt count mismatch: have %d, want %d", len(receipts), len(txs))
+	}
+	signer := types.MakeSigner(s.b.ChainConfig(), block.Number())
+
+	result := make([]map[string]interface{}, len(receipts))
+	for i, receipt := range receipts {
+		result[i] = marshalReceipt(receipt, block.Hash(), block.NumberU64(), signer, txs[i], uint64(i))
+	}
+	return result, nil
+}
+
 // GetUncleByBlockNumberAndIndex returns the uncle block for the given block hash and index. When fullTx is true
 // all transactions in the block are returned in full detail, otherwise only the transaction hash is returned.
 func (s *PublicBlockChainAPI) GetUncleByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) (map[string]interface{}, error) {
@@ -1123,7 +1233,7 @@ func FormatLogs(logs []vm.StructLog) []StructLogRes {
 
 // RPCMarshalHeader converts the given header to the RPC output .
 func RPCMarshalHeader(head *types.Header) map[string]interface{} {
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"number":           (*hexutil.Big)(head.Number),
 		"hash":             head.Hash(),
 		"parentHash":       head.ParentHash,
@@ -1142,6 +1252,14 @@ func RPCMarshalHeader(head *types.Header) map[string]interface{} {
 		"transactionsRoot": head.TxHash,
 		"receiptsRoot":     head.ReceiptHash,
 	}
+	if len(head.Extensions) > 0 {
+		exts := make([]hexutil.Bytes, len(head.Extensions))
+		for i, ext := range head.Extensions {
+			exts[i] = hexutil.Bytes(ext)
+		}
+		result["extensions"] = exts
+	}
+	return result
 }
 
 // RPCMarshalBlock converts the given block to the RPC output which depends on fullTx. If inclTx is true transactions are
@@ -1437,12 +1555,18 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	// Derive the sender.
 	bigblock := new(big.Int).SetUint64(blockNumber)
 	signer := types.MakeSigner(s.b.ChainConfig(), bigblock)
+	return marshalReceipt(receipt, blockHash, blockNumber, signer, tx, index), nil
+}
+
+// marshalReceipt converts a receipt into the RPC representation, deriving the
+// transaction related fields from the given transaction.
+func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber uint64, signer types.Signer, tx *types.Transaction, index uint64) map[string]interface{} {
 	from, _ := types.Sender(signer, tx)
 
 	fields := map[string]interface{}{
 		"blockHash":         blockHash,
 		"blockNumber":       hexutil.Uint64(blockNumber),
-		"transactionHash":   hash,
+		"transactionHash":   tx.Hash(),
 		"transactionIndex":  hexutil.Uint64(index),
 		"from":              from,
 		"to":                tx.To(),
@@ -1467,7 +1591,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
-	return fields, nil
+	return fields
 }
 
 // sign is a helper function that signs a transaction with the private key of the given address.
@@ -1690,6 +1814,49 @@ func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, input
 	return SubmitTransaction(ctx, s.b, tx)
 }
 
+// maxConditionalSlots is the maximum number of storage slots a conditional
+// transaction may require, bounding the work of revalidating it on every block.
+const maxConditionalSlots = 1024
+
+// TransactionConditions represents the validity conditions a transaction submitted
+// through SendRawTransactionConditional must satisfy.
+type TransactionConditions struct {
+	BlockNumberMax *hexutil.Big                                   `json:"blockNumberMax"`
+	KnownAccounts  map[common.Address]map[common.Hash]common.Hash `json:"knownAccounts"`
+}
+
+// toConditional converts the conditions into their core representation.
+func (args *TransactionConditions) toConditional() (*types.TransactionConditional, error) {
+	var slots int
+	for _, account := range args.KnownAccounts {
+		slots += len(account)
+	}
+	if slots > maxConditionalSlots {
+		return nil, fmt.Errorf("too many storage conditions: %d > %d", slots, maxConditionalSlots)
+	}
+	return &types.TransactionConditional{
+		BlockNumberMax: (*big.Int)(args.BlockNumberMax),
+		KnownAccounts:  args.KnownAccounts,
+	}, nil
+}
+
+// SendRawTransactionConditional will add the signed transaction to the transaction
+// pool, keeping it there and including it in mined blocks only as long as the given
+// conditions hold. The conditions are enforced by this node only, they are not
+// relayed along with the transaction.
+func (s *PublicTransactionPoolAPI) SendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, conditions TransactionConditions) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	cond, err := conditions.toConditional()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	tx.SetConditional(cond)
+	return SubmitTransaction(ctx, s.b, tx)
+}
+
 // Sign calculates an ECDSA signature for:
 // keccack256("\x19Gdtu Signed Message:\n" + len(message) + message).
 //
@@ -1753,22 +1920,32 @@ func (s *PublicTransactionPoolAPI) SignTransaction(ctx context.Context, args Sen
 }
 
 // PendingTransactions returns the transactions that are in the transaction pool
-// and have a from address that is one of the accounts this node manages.
-func (s *PublicTransactionPoolAPI) PendingTransactions() ([]*RPCTransaction, error) {
-	pending, err := s.b.GetPoolTransactions()
-	if err != nil {
-		return nil, err
-	}
-	accounts := make(map[common.Address]struct{})
-	for _, wallet := range s.b.AccountManager().Wallets() {
-		for _, account := range wallet.Accounts() {
-			accounts[account.Address] = struct{}{}
+// and have a from address that is one of the given accounts, or one of the
+// accounts this node manages if none are given. The transactions are grouped by
+// account and sorted by nonce.
+func (s *PublicTransactionPoolAPI) PendingTransactions(accounts *[]common.Address) ([]*RPCTransaction, error) {
+	var addresses []common.Address
+	if accounts != nil {
+		addresses = *accounts
+	} else {
+		for _, wallet := range s.b.AccountManager().Wallets() {
+			for _, account := range wallet.Accounts() {
+				addresses = append(addresses, account.Address)
+			}
 		}
 	}
-	transactions := make([]*RPCTransaction, 0, len(pending))
-	for _, tx := range pending {
-		from, _ := types.Sender(s.signer, tx)
-		if _, exists := accounts[from]; exists {
+	var (
+		transactions = make([]*RPCTransaction, 0)
+		seen         = make(map[common.Address]struct{})
+	)
+	for _, addr := range addresses {
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+
+		pending, _ := s.b.TxPoolContentFrom(addr)
+		for _, tx := range pending {
 			transactions = append(transactions, newRPCPendingTransaction(tx))
 		}
 	}
@@ -1913,6 +2090,9 @@ func (api *PublicDebugAPI) SeedHash(ctx context.Context, number uint64) (string,
 // debugging endpoint.
 type PrivateDebugAPI struct {
 	b Backend
+
+	setHeadLock    sync.Mutex
+	setHeadPending *pendingSetHead // Chain rewind awaiting confirmation
 }
 
 // NewPrivateDebugAPI creates a new API definition for the private debug Methods
@@ -1944,11 +2124,6 @@ func (api *PrivateDebugAPI) ChaindbCompact() error {
 	return nil
 }
 
-// SetHead rewinds the head of the blockchain to a previous block.
-func (api *PrivateDebugAPI) SetHead(number hexutil.Uint64) {
-	api.b.SetHead(uint64(number))
-}
-
 // PublicNetAPI offers network related RPC Methods
 type PublicNetAPI struct {
 	net            *p2p.Server
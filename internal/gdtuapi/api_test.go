@@ -0,0 +1,54 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtuapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/rpc"
+)
+
+// blockTestBackend is a backend serving a single block lookup, all the other
+// methods panic.
+type blockTestBackend struct {
+	Backend
+	block *types.Block
+	err   error
+}
+
+func (b *blockTestBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Block, error) {
+	return b.block, b.err
+}
+
+// Tests that block receipts of unknown blocks are reported as null, and failed
+// block lookups as errors.
+func TestGetBlockReceiptsLookup(t *testing.T) {
+	number := rpc.BlockNumberOrHashWithNumber(1)
+
+	api := NewPublicBlockChainAPI(&blockTestBackend{})
+	if receipts, err := api.GetBlockReceipts(context.Background(), number); receipts != nil || err != nil {
+		t.Fatalf("unknown block: have %v, %v, want nil, nil", receipts, err)
+	}
+	fail := errors.New("lookup failed")
+	api = NewPublicBlockChainAPI(&blockTestBackend{err: fail})
+	if _, err := api.GetBlockReceipts(context.Background(), number); err != fail {
+		t.Fatalf("failed lookup: have error %v, want %v", err, fail)
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/c88032111/go-gdtu/core/vm"
 	"github.com/c88032111/go-gdtu/event"
 	"github.com/c88032111/go-gdtu/gdtu/downloader"
+	"github.com/c88032111/go-gdtu/gdtu/gasprice"
 	"github.com/c88032111/go-gdtu/gdtudb"
 	"github.com/c88032111/go-gdtu/params"
 	"github.com/c88032111/go-gdtu/rpc"
@@ -42,6 +43,7 @@ type Backend interface {
 	// General Gdtu API
 	Downloader() *downloader.Downloader
 	SuggestPrice(ctx context.Context) (*big.Int, error)
+	SuggestFees(ctx context.Context) (*gasprice.FeeSuggestions, error)
 	ChainDb() gdtudb.Database
 	AccountManager() *accounts.Manager
 	ExtRPCEnabled() bool
@@ -50,7 +52,7 @@ type Backend interface {
 	UnprotectedAllowed() bool // allows only for EIP155 transactions.
 
 	// Blockchain API
-	SetHead(number uint64)
+	SetHead(number uint64) error
 	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
 	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
 	HeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error)
@@ -76,6 +78,7 @@ type Backend interface {
 	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
+	TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions)
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
 
 	// Filter API
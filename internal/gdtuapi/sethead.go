@@ -0,0 +1,138 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtuapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/rpc"
+)
+
+// setHeadTokenExpiry is the time a chain rewind can be confirmed after it was
+// requested.
+const setHeadTokenExpiry = time.Minute
+
+var (
+	errSetHeadUnrequested = errors.New("no chain rewind requested, call without token first")
+	errSetHeadToken       = errors.New("invalid chain rewind token")
+	errSetHeadExpired     = errors.New("chain rewind token expired")
+)
+
+// SetHeadPlan describes a chain rewind requested through debug_setHead.
+type SetHeadPlan struct {
+	Current      hexutil.Uint64  `json:"current"`        // Number of the head block before the rewind
+	Target       hexutil.Uint64  `json:"target"`         // Number of the requested head block
+	TargetHash   common.Hash     `json:"targetHash"`     // Hash of the requested head block
+	Discarded    hexutil.Uint64  `json:"discarded"`      // Number of blocks discarded by the rewind
+	StateMissing bool            `json:"stateMissing"`   // Whether the rewind goes further back to a block with state
+	Token        string          `json:"token"`          // Token confirming the rewind
+	Expires      time.Time       `json:"expires"`        // Time the token expires
+	Executed     bool            `json:"executed"`       // Whether the rewind was performed
+	Head         *hexutil.Uint64 `json:"head,omitempty"` // Number of the head block after the rewind
+}
+
+// pendingSetHead is a chain rewind awaiting confirmation.
+type pendingSetHead struct {
+	plan  SetHeadPlan
+	token []byte
+}
+
+// SetHead rewinds the head of the blockchain to a previous block, discarding all
+// blocks after it along with the derived data (snapshot layers, bloom sections,
+// transaction pool state).
+//
+// The rewind is performed in two steps. Called without a token, the Method only
+// reports what the rewind would do, along with a token that confirms it. Called
+// again with the token within a minute, the rewind is performed, unless the
+// requested block was reorged away in the meantime.
+func (api *PrivateDebugAPI) SetHead(ctx context.Context, number hexutil.Uint64, token *string) (*SetHeadPlan, error) {
+	api.setHeadLock.Lock()
+	defer api.setHeadLock.Unlock()
+
+	plan, err := api.planSetHead(ctx, uint64(number))
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || *token == "" {
+		secret := make([]byte, 16)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		plan.Token = hex.EncodeToString(secret)
+		plan.Expires = time.Now().Add(setHeadTokenExpiry)
+		api.setHeadPending = &pendingSetHead{plan: *plan, token: []byte(plan.Token)}
+
+		log.Info("Chain rewind requested", "current", plan.Current, "target", plan.Target, "discarded", plan.Discarded, "statemissing", plan.StateMissing)
+		return plan, nil
+	}
+	// A rewind was confirmed, make sure it's the one planned
+	pending := api.setHeadPending
+	switch {
+	case pending == nil:
+		return nil, errSetHeadUnrequested
+	case subtle.ConstantTimeCompare(pending.token, []byte(*token)) != 1:
+		return nil, errSetHeadToken
+	case time.Now().After(pending.plan.Expires):
+		api.setHeadPending = nil
+		return nil, errSetHeadExpired
+	case pending.plan.Target != plan.Target || pending.plan.TargetHash != plan.TargetHash:
+		return nil, fmt.Errorf("chain rewind to block #%d [%x…] requested, not #%d [%x…]",
+			pending.plan.Target, pending.plan.TargetHash[:4], plan.Target, plan.TargetHash[:4])
+	}
+	api.setHeadPending = nil
+
+	log.Warn("Rewinding chain on request", "current", plan.Current, "target", plan.Target)
+	if err := api.b.SetHead(uint64(number)); err != nil {
+		return nil, err
+	}
+	head := hexutil.Uint64(api.b.CurrentHeader().Number.Uint64())
+	plan.Token, plan.Expires = pending.plan.Token, pending.plan.Expires
+	plan.Executed, plan.Head = true, &head
+	return plan, nil
+}
+
+// planSetHead describes the rewind of the chain to the given block.
+func (api *PrivateDebugAPI) planSetHead(ctx context.Context, number uint64) (*SetHeadPlan, error) {
+	current := api.b.CurrentHeader().Number.Uint64()
+	if number > current {
+		return nil, fmt.Errorf("block #%d is beyond the current head #%d", number, current)
+	}
+	header, err := api.b.HeaderByNumber(ctx, rpc.BlockNumber(number))
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	_, _, err = api.b.StateAndHeaderByNumber(ctx, rpc.BlockNumber(number))
+	return &SetHeadPlan{
+		Current:      hexutil.Uint64(current),
+		Target:       hexutil.Uint64(number),
+		TargetHash:   header.Hash(),
+		Discarded:    hexutil.Uint64(current - number),
+		StateMissing: err != nil,
+	}, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package gdtuapi
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/core/state"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/rpc"
+)
+
+// setHeadTestBackend is a backend with a chain of headers which can be rewound,
+// all the other methods panic.
+type setHeadTestBackend struct {
+	Backend
+	headers []*types.Header
+	rewinds int
+}
+
+func newSetHeadTestBackend(n int) *setHeadTestBackend {
+	b := new(setHeadTestBackend)
+	for i := 0; i < n; i++ {
+		b.headers = append(b.headers, &types.Header{Number: big.NewInt(int64(i)), Extra: []byte{byte(i)}})
+	}
+	return b
+}
+
+func (b *setHeadTestBackend) CurrentHeader() *types.Header {
+	return b.headers[len(b.headers)-1]
+}
+
+func (b *setHeadTestBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if int(number) >= len(b.headers) {
+		return nil, nil
+	}
+	return b.headers[number], nil
+}
+
+func (b *setHeadTestBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	return nil, nil, errors.New("state not available")
+}
+
+func (b *setHeadTestBackend) SetHead(number uint64) error {
+	b.headers = b.headers[:number+1]
+	b.rewinds++
+	return nil
+}
+
+func TestSetHead(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		backend = newSetHeadTestBackend(10)
+		api     = NewPrivateDebugAPI(backend)
+	)
+	// Confirming without a request fails
+	token := "00"
+	if _, err := api.SetHead(ctx, 5, &token); err != errSetHeadUnrequested {
+		t.Fatalf("unrequested rewind error mismatch: have %v, want %v", err, errSetHeadUnrequested)
+	}
+	// Requesting only plans the rewind
+	plan, err := api.SetHead(ctx, 5, nil)
+	if err != nil {
+		t.Fatalf("failed to request rewind: %v", err)
+	}
+	if plan.Executed || plan.Discarded != 4 || !plan.StateMissing || backend.rewinds != 0 {
+		t.Fatalf("rewind plan mismatch: %+v, %d rewinds", plan, backend.rewinds)
+	}
+	// Invalid tokens and mismatched targets are refused
+	token = "00"
+	if _, err := api.SetHead(ctx, 5, &token); err != errSetHeadToken {
+		t.Fatalf("invalid token error mismatch: have %v, want %v", err, errSetHeadToken)
+	}
+	if _, err := api.SetHead(ctx, 6, &plan.Token); err == nil {
+		t.Fatalf("rewind to mismatched target accepted")
+	}
+	if backend.rewinds != 0 {
+		t.Fatalf("chain rewound on refused confirmation")
+	}
+	// The correct token performs the rewind, once
+	done, err := api.SetHead(ctx, 5, &plan.Token)
+	if err != nil {
+		t.Fatalf("failed to confirm rewind: %v", err)
+	}
+	if !done.Executed || done.Head == nil || *done.Head != 5 || backend.rewinds != 1 {
+		t.Fatalf("rewind result mismatch: %+v, %d rewinds", done, backend.rewinds)
+	}
+	if _, err := api.SetHead(ctx, 5, &plan.Token); err != errSetHeadUnrequested {
+		t.Fatalf("reused token error mismatch: have %v, want %v", err, errSetHeadUnrequested)
+	}
+	// Expired tokens are refused and dropped
+	plan, err = api.SetHead(ctx, 3, nil)
+	if err != nil {
+		t.Fatalf("failed to request rewind: %v", err)
+	}
+	api.setHeadPending.plan.Expires = time.Now().Add(-time.Second)
+	if _, err := api.SetHead(ctx, 3, &plan.Token); err != errSetHeadExpired {
+		t.Fatalf("expired token error mismatch: have %v, want %v", err, errSetHeadExpired)
+	}
+	if _, err := api.SetHead(ctx, 3, &plan.Token); err != errSetHeadUnrequested {
+		t.Fatalf("expired token not dropped: have %v, want %v", err, errSetHeadUnrequested)
+	}
+	if backend.rewinds != 1 || backend.CurrentHeader().Number.Uint64() != 5 {
+		t.Fatalf("chain rewound with expired token")
+	}
+	// Rewinds beyond the head are refused
+	if _, err := api.SetHead(ctx, hexutil.Uint64(10), nil); err == nil {
+		t.Fatalf("rewind beyond the head accepted")
+	}
+}
@@ -25,6 +25,7 @@ var Modules = map[string]string{
 	"gdtuash":    GdtuashJs,
 	"debug":      DebugJs,
 	"gdtu":       GdtuJs,
+	"graphql":    GraphQLJs,
 	"miner":      MinerJs,
 	"net":        NetJs,
 	"personal":   PersonalJs,
@@ -108,6 +109,11 @@ web3._extend({
 			call: 'clique_status',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'getEquivocations',
+			call: 'clique_getEquivocations',
+			params: 0
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -181,6 +187,27 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'exportChainToURL',
+			call: 'admin_exportChainToURL',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'importChainFromURL',
+			call: 'admin_importChainFromURL',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'exportPreimages',
+			call: 'admin_exportPreimages',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'importPreimages',
+			call: 'admin_importPreimages',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -206,6 +233,30 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'peerEvents',
+			call: 'admin_peerEvents',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'peerChurn',
+			call: 'admin_peerChurn',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'clearPeerBans',
+			call: 'admin_clearPeerBans',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'slowestPeers',
+			call: 'admin_slowestPeers',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -220,6 +271,14 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'snapAvailability',
+			getter: 'admin_snapAvailability'
+		}),
+		new web3._extend.Property({
+			name: 'peerBans',
+			getter: 'admin_peerBans'
+		}),
 	]
 });
 `
@@ -254,7 +313,8 @@ web3._extend({
 		new web3._extend.Method({
 			name: 'setHead',
 			call: 'debug_setHead',
-			params: 1
+			params: 2,
+			inputFormatter: [null, null]
 		}),
 		new web3._extend.Method({
 			name: 'seedHash',
@@ -383,6 +443,26 @@ web3._extend({
 			call: 'debug_writeMemProfile',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'captureProfile',
+			call: 'debug_captureProfile',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'profiles',
+			call: 'debug_profiles',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'downloadProfile',
+			call: 'debug_downloadProfile',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'deleteProfile',
+			call: 'debug_deleteProfile',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'traceBlock',
 			call: 'debug_traceBlock',
@@ -437,6 +517,40 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceCallDiff',
+			call: 'debug_traceCallDiff',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'debugTransaction',
+			call: 'debug_debugTransaction',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'debugStep',
+			call: 'debug_debugStep',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'debugContinue',
+			call: 'debug_debugContinue',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'debugSetBreakpoints',
+			call: 'debug_debugSetBreakpoints',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'debugStop',
+			call: 'debug_debugStop',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -448,6 +562,34 @@ web3._extend({
 			call: 'debug_getBadBlocks',
 			params: 0,
 		}),
+		new web3._extend.Method({
+			name: 'snapshotProgress',
+			call: 'debug_snapshotProgress',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'opcodeProfile',
+			call: 'debug_opcodeProfile',
+			params: 1,
+			inputFormatter: [null],
+		}),
+		new web3._extend.Method({
+			name: 'resetOpcodeProfile',
+			call: 'debug_resetOpcodeProfile',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'freezerThreshold',
+			call: 'debug_freezerThreshold',
+			params: 0,
+			outputFormatter: web3._extend.utils.toDecimal
+		}),
+		new web3._extend.Method({
+			name: 'setFreezerThreshold',
+			call: 'debug_setFreezerThreshold',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.Method({
 			name: 'storageRangeAt',
 			call: 'debug_storageRangeAt',
@@ -515,6 +657,11 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'sendRawTransactionConditional',
+			call: 'gdtu_sendRawTransactionConditional',
+			params: 2
+		}),
 		new web3._extend.Method({
 			name: 'fillTransaction',
 			call: 'gdtu_fillTransaction',
@@ -544,6 +691,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, function (val) { return !!val; }]
 		}),
+		new web3._extend.Method({
+			name: 'getBlockReceipts',
+			call: 'gdtu_getBlockReceipts',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransaction',
 			call: 'gdtu_getRawTransactionByHash',
@@ -557,6 +710,19 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.utils.toHex]
 		}),
+		new web3._extend.Method({
+			name: 'getPendingTransactions',
+			call: 'gdtu_pendingTransactions',
+			params: 1,
+			outputFormatter: function(txs) {
+				var formatted = [];
+				for (var i = 0; i < txs.length; i++) {
+					formatted.push(web3._extend.formatters.outputTransactionFormatter(txs[i]));
+					formatted[i].blockHash = null;
+				}
+				return formatted;
+			}
+		}),
 		new web3._extend.Method({
 			name: 'getProof',
 			call: 'gdtu_getProof',
@@ -577,6 +743,15 @@ web3._extend({
 				return formatted;
 			}
 		}),
+		new web3._extend.Property({
+			name: 'maxPriorityFeePerGas',
+			getter: 'gdtu_maxPriorityFeePerGas',
+			outputFormatter: web3._extend.utils.toBigNumber
+		}),
+		new web3._extend.Property({
+			name: 'feeSuggestions',
+			getter: 'gdtu_feeSuggestions'
+		}),
 	]
 });
 `
@@ -612,6 +787,12 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.utils.fromDecimal]
 		}),
+		new web3._extend.Method({
+			name: 'setGasLimits',
+			call: 'miner_setGasLimits',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.Method({
 			name: 'setRecommitInterval',
 			call: 'miner_setRecommitInterval',
@@ -621,6 +802,12 @@ web3._extend({
 			name: 'getHashrate',
 			call: 'miner_getHashrate'
 		}),
+		new web3._extend.Method({
+			name: 'mine',
+			call: 'miner_mine',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
 	],
 	properties: []
 });
@@ -675,6 +862,24 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'signWithPath',
+			call: 'personal_sign',
+			params: 4,
+			inputFormatter: [null, web3._extend.formatters.inputAddressFormatter, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'signTransactionWithPath',
+			call: 'personal_signTransaction',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputTransactionFormatter, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'sendTransactionWithPath',
+			call: 'personal_sendTransaction',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputTransactionFormatter, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'unpair',
 			call: 'personal_unpair',
@@ -691,6 +896,10 @@ web3._extend({
 			name: 'listWallets',
 			getter: 'personal_listWallets'
 		}),
+		new web3._extend.Property({
+			name: 'keystoreMigration',
+			getter: 'personal_keystoreMigration'
+		}),
 	]
 })
 `
@@ -704,6 +913,10 @@ web3._extend({
 			name: 'modules',
 			getter: 'rpc_modules'
 		}),
+		new web3._extend.Property({
+			name: 'methods',
+			getter: 'rpc_methods'
+		}),
 	]
 });
 `
@@ -858,6 +1071,11 @@ web3._extend({
 			call: 'les_addBalance',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'flushOdrCache',
+			call: 'les_flushOdrCache',
+			params: 0
+		}),
 	],
 	properties:
 	[
@@ -897,6 +1115,16 @@ web3._extend({
 			call: 'vflux_value',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'exportServers',
+			call: 'vflux_exportServers',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'importServers',
+			call: 'vflux_importServers',
+			params: 1
+		}),
 	],
 	properties:
 	[
@@ -904,6 +1132,24 @@ web3._extend({
 			name: 'requestStats',
 			getter: 'vflux_requestStats'
 		}),
+		new web3._extend.Property({
+			name: 'servers',
+			getter: 'vflux_servers'
+		}),
+	]
+});
+`
+
+const GraphQLJs = `
+web3._extend({
+	property: 'graphql',
+	Methods: [
+		new web3._extend.Method({
+			name: 'query',
+			call: 'graphql_query',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 	]
 });
 `
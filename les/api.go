@@ -360,3 +360,19 @@ func (api *PrivateLightAPI) GetCheckpointContractAddress() (string, error) {
 	}
 	return api.backend.oracle.Contract().ContractAddr().Hex(), nil
 }
+
+// PrivateLightClientAPI provides an API to manage the local state of a light client.
+type PrivateLightClientAPI struct {
+	odr *LesOdr
+}
+
+// NewPrivateLightClientAPI creates a new LES light client API.
+func NewPrivateLightClientAPI(odr *LesOdr) *PrivateLightClientAPI {
+	return &PrivateLightClientAPI{odr: odr}
+}
+
+// FlushOdrCache drops all cached on-demand retrieval results, forcing them to
+// be fetched from the network again.
+func (api *PrivateLightClientAPI) FlushOdrCache() {
+	api.odr.FlushCache()
+}
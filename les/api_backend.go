@@ -32,6 +32,7 @@ import (
 	"github.com/c88032111/go-gdtu/core/vm"
 	"github.com/c88032111/go-gdtu/event"
 	"github.com/c88032111/go-gdtu/gdtu/downloader"
+	"github.com/c88032111/go-gdtu/gdtu/filters"
 	"github.com/c88032111/go-gdtu/gdtu/gasprice"
 	"github.com/c88032111/go-gdtu/gdtudb"
 	"github.com/c88032111/go-gdtu/light"
@@ -54,9 +55,17 @@ func (b *LesApiBackend) CurrentBlock() *types.Block {
 	return types.NewBlockWithHeader(b.gdtu.BlockChain().CurrentHeader())
 }
 
-func (b *LesApiBackend) SetHead(number uint64) {
+// SetHead rewinds the chain to the given header, cancelling any synchronisation
+// in progress and discarding the indexed sections beyond the new head.
+func (b *LesApiBackend) SetHead(number uint64) error {
 	b.gdtu.handler.downloader.Cancel()
-	b.gdtu.blockchain.SetHead(number)
+	if err := b.gdtu.blockchain.SetHead(number); err != nil {
+		return err
+	}
+	head := b.gdtu.blockchain.CurrentHeader().Number.Uint64()
+	b.gdtu.bloomIndexer.Rewind(head)
+	b.gdtu.chtIndexer.Rewind(head)
+	return nil
 }
 
 func (b *LesApiBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
@@ -164,6 +173,47 @@ func (b *LesApiBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*typ
 	return nil, nil
 }
 
+// FilterLogs filters the logs of a range of canonical blocks on les/5 servers,
+// which only works if the headers of the range are available locally. The logs
+// returned are proven to exist, but a server can't prove it didn't omit any, so
+// it's only used if explicitly enabled, otherwise the receipts of the candidate
+// blocks are checked locally.
+func (b *LesApiBackend) FilterLogs(ctx context.Context, begin, end uint64, addresses []common.Address, topics [][]common.Hash) ([]*types.Log, error) {
+	if !b.gdtu.config.LightServerFilter {
+		return nil, filters.ErrRangeFilterUnavailable
+	}
+	query := LogsQuery{Addresses: addresses, Topics: topics}
+	if query.criteria() > MaxLogsCriteria {
+		return nil, filters.ErrRangeFilterUnavailable
+	}
+	if rawdb.ReadCanonicalHash(b.gdtu.chainDb, begin) == (common.Hash{}) || rawdb.ReadCanonicalHash(b.gdtu.chainDb, end) == (common.Hash{}) {
+		return nil, filters.ErrRangeFilterUnavailable
+	}
+	var served bool
+	for _, p := range b.gdtu.peers.allPeers() {
+		if p.version >= lpv5 {
+			served = true
+			break
+		}
+	}
+	if !served {
+		return nil, filters.ErrRangeFilterUnavailable
+	}
+	var logs []*types.Log
+	for begin <= end {
+		r := &light.LogsRequest{FromBlock: begin, ToBlock: end, Addresses: addresses, Topics: topics}
+		if end-begin >= MaxLogsRange {
+			r.ToBlock = begin + MaxLogsRange - 1
+		}
+		if err := b.gdtu.odr.Retrieve(ctx, r); err != nil {
+			return logs, err
+		}
+		logs = append(logs, r.Logs...)
+		begin += r.Count
+	}
+	return logs, nil
+}
+
 func (b *LesApiBackend) GetTd(ctx context.Context, hash common.Hash) *big.Int {
 	if number := rawdb.ReadHeaderNumber(b.gdtu.chainDb, hash); number != nil {
 		return b.gdtu.blockchain.GetTdOdr(ctx, hash, *number)
@@ -209,10 +259,20 @@ func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.gdtu.txPool.Content()
 }
 
+func (b *LesApiBackend) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	return b.gdtu.txPool.ContentFrom(addr)
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.gdtu.txPool.SubscribeNewTxsEvent(ch)
 }
 
+// SubscribeTxStatusEvent subscribes to the status changes of the transactions
+// relayed by the light client, as pushed by the servers.
+func (b *LesApiBackend) SubscribeTxStatusEvent(ch chan<- TxStatusEvent) event.Subscription {
+	return b.gdtu.relay.SubscribeTxStatus(ch)
+}
+
 func (b *LesApiBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.gdtu.blockchain.SubscribeChainEvent(ch)
 }
@@ -252,6 +312,10 @@ func (b *LesApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *LesApiBackend) SuggestFees(ctx context.Context) (*gasprice.FeeSuggestions, error) {
+	return b.gpo.SuggestFees(ctx)
+}
+
 func (b *LesApiBackend) ChainDb() gdtudb.Database {
 	return b.gdtu.chainDb
 }
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/c88032111/go-gdtu/common/bitutil"
+	"github.com/c88032111/go-gdtu/core/bloombits"
 	"github.com/c88032111/go-gdtu/light"
 )
 
@@ -29,8 +30,9 @@ const (
 	bloomServiceThreads = 16
 
 	// bloomFilterThreads is the number of goroutines used locally per filter to
-	// multiplex requests onto the global servicing goroutines.
-	bloomFilterThreads = 3
+	// multiplex requests onto the global servicing goroutines. The concurrently
+	// requested bits of a filter are retrieved from the servers together.
+	bloomFilterThreads = 8
 
 	// bloomRetrievalBatch is the maximum number of bloom bit retrievals to service
 	// in a single batch.
@@ -39,11 +41,54 @@ const (
 	// bloomRetrievalWait is the maximum time to wait for enough bloom bit requests
 	// to accumulate request an entire batch (avoiding hysteresis).
 	bloomRetrievalWait = time.Microsecond * 100
+
+	// bloomCacheSize is the number of decompressed bloom bit vectors kept in memory
+	// to serve repeated filter queries without touching the database.
+	bloomCacheSize = 1024
 )
 
+// bloomCacheKey identifies a bloom bit vector of a section in the cache.
+type bloomCacheKey struct {
+	bit     uint
+	section uint64
+}
+
 // startBloomHandlers starts a batch of goroutines to accept bloom bit database
 // retrievals from possibly a range of filters and serving the data to satisfy.
+//
+// The retrievals accumulated for up to bloomRetrievalWait are served together,
+// requesting the bits wanted over the same sections in a single round trip.
 func (gdtu *LightGdtu) startBloomHandlers(sectionSize uint64) {
+	batches := make(chan []chan *bloombits.Retrieval)
+	go func() {
+		defer gdtu.wg.Done()
+		for {
+			select {
+			case <-gdtu.closeCh:
+				return
+
+			case request := <-gdtu.bloomRequests:
+				requests := []chan *bloombits.Retrieval{request}
+				timer := time.NewTimer(bloomRetrievalWait)
+			gather:
+				for len(requests) < MaxBloomBitsFetch {
+					select {
+					case request := <-gdtu.bloomRequests:
+						requests = append(requests, request)
+					case <-timer.C:
+						break gather
+					}
+				}
+				timer.Stop()
+
+				select {
+				case batches <- requests:
+				case <-gdtu.closeCh:
+					return
+				}
+			}
+		}
+	}()
 	for i := 0; i < bloomServiceThreads; i++ {
 		go func() {
 			defer gdtu.wg.Done()
@@ -52,24 +97,101 @@ func (gdtu *LightGdtu) startBloomHandlers(sectionSize uint64) {
 				case <-gdtu.closeCh:
 					return
 
-				case request := <-gdtu.bloomRequests:
-					task := <-request
-					task.Bitsets = make([][]byte, len(task.Sections))
-					compVectors, err := light.GetBloomBits(task.Context, gdtu.odr, task.Bit, task.Sections)
-					if err == nil {
-						for i := range task.Sections {
-							if blob, err := bitutil.DecompressBytes(compVectors[i], int(sectionSize/8)); err == nil {
-								task.Bitsets[i] = blob
-							} else {
-								task.Error = err
-							}
-						}
-					} else {
-						task.Error = err
+				case requests := <-batches:
+					tasks := make([]*bloombits.Retrieval, len(requests))
+					for i, request := range requests {
+						tasks[i] = <-request
+					}
+					gdtu.serveBloomTasks(tasks, sectionSize)
+					for i, request := range requests {
+						request <- tasks[i]
 					}
-					request <- task
 				}
 			}
 		}()
 	}
 }
+
+// serveBloomTasks fills the bitsets of the retrieval tasks, from the cache if
+// possible, grouping the retrievals of different bits in the same sections.
+func (gdtu *LightGdtu) serveBloomTasks(tasks []*bloombits.Retrieval, sectionSize uint64) {
+	var pending []*bloombits.Retrieval
+	for _, task := range tasks {
+		task.Bitsets = make([][]byte, len(task.Sections))
+		for i, section := range task.Sections {
+			blob, ok := gdtu.bloomCache.Get(bloomCacheKey{task.Bit, section})
+			if !ok {
+				pending = append(pending, task)
+				break
+			}
+			task.Bitsets[i] = blob.([]byte)
+		}
+	}
+	for len(pending) > 0 {
+		var (
+			group = []*bloombits.Retrieval{pending[0]}
+			rest  []*bloombits.Retrieval
+		)
+		for _, task := range pending[1:] {
+			if task.Context == group[0].Context && sameSections(task.Sections, group[0].Sections) && (len(group)+1)*len(task.Sections) <= MaxBloomBitsFetch {
+				group = append(group, task)
+			} else {
+				rest = append(rest, task)
+			}
+		}
+		gdtu.retrieveBloomBits(group, sectionSize)
+		pending = rest
+	}
+}
+
+// retrieveBloomBits retrieves the bloom bit vectors of a group of retrieval tasks
+// over the same sections in a single request.
+func (gdtu *LightGdtu) retrieveBloomBits(group []*bloombits.Retrieval, sectionSize uint64) {
+	var (
+		ctx         = group[0].Context
+		sections    = group[0].Sections
+		compVectors [][][]byte
+		err         error
+	)
+	if len(sections) > MaxBloomBitsFetch {
+		// Too many sections for a single batch, retrieve them separately
+		var vectors [][]byte
+		if vectors, err = light.GetBloomBits(ctx, gdtu.odr, group[0].Bit, sections); err == nil {
+			compVectors = [][][]byte{vectors}
+		}
+	} else {
+		bits := make([]uint, len(group))
+		for i, task := range group {
+			bits[i] = task.Bit
+		}
+		compVectors, err = light.GetBloomBitsBatch(ctx, gdtu.odr, bits, sections)
+	}
+	for i, task := range group {
+		if err != nil {
+			task.Error = err
+			continue
+		}
+		for j, section := range task.Sections {
+			blob, err := bitutil.DecompressBytes(compVectors[i][j], int(sectionSize/8))
+			if err != nil {
+				task.Error = err
+				continue
+			}
+			task.Bitsets[j] = blob
+			gdtu.bloomCache.Add(bloomCacheKey{task.Bit, section}, blob)
+		}
+	}
+}
+
+// sameSections reports whgdtuer two retrievals are over the same sections.
+func sameSections(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
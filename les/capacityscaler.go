@@ -0,0 +1,239 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/mclock"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/gdtudb"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/metrics"
+)
+
+const (
+	capacityScaleInterval  = time.Second * 10 // Time between two resource measurements
+	capacityScaleSmoothing = 0.3              // Weight of a new measurement in the moving average
+
+	// The serving capacity is lowered as soon as the average CPU usage or disk
+	// latency exceeds its high watermark, and raised only once both stayed below
+	// their low watermarks for capacityScaleUpRounds measurements in a row.
+	cpuHighWatermark      = 0.85
+	cpuLowWatermark       = 0.6
+	diskHighWatermark     = time.Millisecond * 20
+	diskLowWatermark      = time.Millisecond * 5
+	capacityScaleUpRounds = 6
+
+	capacityScaleDown = 0.8 // Factor the serving percentage is multiplied with when scaling down
+	capacityScaleUp   = 0.1 // Fraction of LightServ the serving percentage is raised by when scaling up
+
+	diskProbes = 8 // Number of random header reads timed in a disk latency measurement
+)
+
+// resourceUsage is a measurement of the resources of the node.
+type resourceUsage struct {
+	cpu  float64       // Fraction of the total CPU time used by the node
+	disk time.Duration // Latency of random database reads
+}
+
+// capacityScaler adjusts the percentage of time allowed for serving LES requests
+// between LightServ/4 and LightServMax, following the CPU usage of the node and
+// the latency of its database, so that the total capacity advertised to clients
+// uses the headroom of the node without overloading it. The scaling is additive
+// upwards and multiplicative downwards, with a hysteresis band between the low
+// and high watermarks in which the serving percentage is kept.
+type capacityScaler struct {
+	base, min, max float64 // Configured, lowest and highest serving percentage
+	measure        func() resourceUsage
+
+	lock    sync.Mutex
+	serve   float64       // Current serving percentage
+	usage   resourceUsage // Moving average of the measurements
+	started bool          // Whether the moving average was initialised
+	lowRuns int           // Number of consecutive measurements below the low watermarks
+
+	scaleCh chan float64
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newCapacityScaler creates a scaler for the given serving percentages, returning
+// nil if scaling is disabled.
+func newCapacityScaler(lightServ, lightServMax int, measure func() resourceUsage) *capacityScaler {
+	if lightServ <= 0 || lightServMax <= lightServ {
+		return nil
+	}
+	min := float64(lightServ) / 4
+	if min < 1 {
+		min = 1
+	}
+	return &capacityScaler{
+		base:    float64(lightServ),
+		min:     min,
+		max:     float64(lightServMax),
+		measure: measure,
+		serve:   float64(lightServ),
+		scaleCh: make(chan float64, 1),
+		closeCh: make(chan struct{}),
+	}
+}
+
+// start launches the measurement loop.
+func (cs *capacityScaler) start() {
+	cs.wg.Add(1)
+	go cs.loop()
+}
+
+// stop terminates the measurement loop.
+func (cs *capacityScaler) stop() {
+	close(cs.closeCh)
+	cs.wg.Wait()
+}
+
+// scale returns the ratio of the current serving percentage to LightServ, which
+// the total recharge of the server is multiplied with.
+func (cs *capacityScaler) scale() float64 {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	return cs.serve / cs.base
+}
+
+// loop measures the resource usage periodically and notifies the new scale on
+// scaleCh whenever it changes.
+func (cs *capacityScaler) loop() {
+	defer cs.wg.Done()
+
+	ticker := time.NewTicker(capacityScaleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if scale, changed := cs.update(cs.measure()); changed {
+				// Only the latest scale matters, replace any unprocessed one
+				select {
+				case <-cs.scaleCh:
+				default:
+				}
+				cs.scaleCh <- scale
+			}
+		case <-cs.closeCh:
+			return
+		}
+	}
+}
+
+// update folds a measurement into the moving average and adjusts the serving
+// percentage accordingly. It returns the new scale and whether it changed.
+func (cs *capacityScaler) update(usage resourceUsage) (float64, bool) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	if !cs.started {
+		cs.usage, cs.started = usage, true
+	} else {
+		cs.usage.cpu += (usage.cpu - cs.usage.cpu) * capacityScaleSmoothing
+		cs.usage.disk += time.Duration(float64(usage.disk-cs.usage.disk) * capacityScaleSmoothing)
+	}
+	capacityCPUGauge.Update(int64(cs.usage.cpu * 100))
+	capacityDiskGauge.Update(int64(cs.usage.disk))
+
+	prev := cs.serve
+	switch {
+	case cs.usage.cpu > cpuHighWatermark || cs.usage.disk > diskHighWatermark:
+		cs.lowRuns = 0
+		if cs.serve *= capacityScaleDown; cs.serve < cs.min {
+			cs.serve = cs.min
+		}
+	case cs.usage.cpu < cpuLowWatermark && cs.usage.disk < diskLowWatermark:
+		if cs.lowRuns++; cs.lowRuns < capacityScaleUpRounds {
+			break
+		}
+		cs.lowRuns = 0
+		if cs.serve += cs.base * capacityScaleUp; cs.serve > cs.max {
+			cs.serve = cs.max
+		}
+	default:
+		cs.lowRuns = 0
+	}
+	capacityServeGauge.Update(int64(cs.serve))
+	if cs.serve == prev {
+		return cs.serve / cs.base, false
+	}
+	if cs.serve < prev {
+		capacityScaleDownMeter.Mark(1)
+	} else {
+		capacityScaleUpMeter.Mark(1)
+	}
+	log.Debug("Scaled LES serving capacity", "from", int(prev), "to", int(cs.serve), "cpu", cs.usage.cpu, "disk", cs.usage.disk)
+	return cs.serve / cs.base, true
+}
+
+// resourceMonitor measures the CPU usage of the process and the latency of random
+// header reads from the chain database.
+type resourceMonitor struct {
+	db    gdtudb.Database
+	head  func() uint64
+	clock mclock.Clock
+
+	lastTime mclock.AbsTime
+	lastCPU  int64
+}
+
+// newResourceMonitor creates a monitor reading headers up to the given head.
+func newResourceMonitor(db gdtudb.Database, head func() uint64) *resourceMonitor {
+	m := &resourceMonitor{db: db, head: head, clock: mclock.System{}}
+	m.lastTime, m.lastCPU = m.clock.Now(), m.processCPU()
+	return m
+}
+
+// processCPU returns the CPU time used by the process in 1/100 seconds.
+func (m *resourceMonitor) processCPU() int64 {
+	var stats metrics.CPUStats
+	metrics.ReadCPUStats(&stats)
+	return stats.LocalTime
+}
+
+// measure implements the measurement function of capacityScaler.
+func (m *resourceMonitor) measure() resourceUsage {
+	var usage resourceUsage
+
+	now, cpu := m.clock.Now(), m.processCPU()
+	if elapsed := time.Duration(now - m.lastTime); elapsed > 0 {
+		usage.cpu = float64(cpu-m.lastCPU) / 100 / elapsed.Seconds() / float64(runtime.NumCPU())
+	}
+	m.lastTime, m.lastCPU = now, cpu
+
+	head := m.head()
+	var total time.Duration
+	for i := 0; i < diskProbes; i++ {
+		number := uint64(rand.Int63n(int64(head) + 1))
+		start := m.clock.Now()
+		if hash := rawdb.ReadCanonicalHash(m.db, number); hash != (common.Hash{}) {
+			rawdb.ReadHeaderRLP(m.db, hash, number)
+		}
+		total += time.Duration(m.clock.Now() - start)
+	}
+	usage.disk = total / diskProbes
+	return usage
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCapacityScaler(t *testing.T) {
+	if cs := newCapacityScaler(100, 100, nil); cs != nil {
+		t.Fatalf("scaler created without headroom above LightServ")
+	}
+	cs := newCapacityScaler(100, 300, nil)
+
+	var (
+		idle = resourceUsage{cpu: 0.1, disk: time.Millisecond}
+		busy = resourceUsage{cpu: 0.95, disk: time.Millisecond}
+		slow = resourceUsage{cpu: 0.1, disk: time.Millisecond * 100}
+		fair = resourceUsage{cpu: 0.7, disk: time.Millisecond * 10}
+	)
+	// Capacity is only raised after a series of idle measurements
+	for i := 1; i < capacityScaleUpRounds; i++ {
+		if _, changed := cs.update(idle); changed {
+			t.Fatalf("capacity raised after %d idle measurements", i)
+		}
+	}
+	if scale, changed := cs.update(idle); !changed || scale != 1+capacityScaleUp {
+		t.Fatalf("capacity not raised after idle measurements: scale %v, changed %v", scale, changed)
+	}
+	// Capacity is bounded by LightServMax
+	for i := 0; i < 1000; i++ {
+		cs.update(idle)
+	}
+	if scale := cs.scale(); scale != 3 {
+		t.Fatalf("scale mismatch after idle period: have %v, want %v", scale, 3)
+	}
+	// Slow disk reads scale down, bounded by a quarter of LightServ
+	cs.update(slow)
+	if scale := cs.scale(); scale >= 3 {
+		t.Fatalf("capacity not lowered on disk latency: scale %v", scale)
+	}
+	for i := 0; i < 100; i++ {
+		cs.update(busy)
+	}
+	if scale := cs.scale(); scale != 0.25 {
+		t.Fatalf("scale mismatch after busy period: have %v, want %v", scale, 0.25)
+	}
+	// Capacity is kept while the usage is within the hysteresis band
+	for i := 0; i < 100; i++ {
+		if _, changed := cs.update(fair); changed && i > 10 {
+			t.Fatalf("capacity changed within hysteresis band")
+		}
+	}
+	if scale := cs.scale(); scale != 0.25 {
+		t.Fatalf("scale mismatch after fair period: have %v, want %v", scale, 0.25)
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/c88032111/go-gdtu/accounts/abi/bind"
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/contracts/checkpointoracle"
+	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/c88032111/go-gdtu/log"
 	"github.com/c88032111/go-gdtu/params"
@@ -36,9 +37,14 @@ import (
 // CheckpointOracle is responsible for offering the latest stable checkpoint
 // generated and announced by the contract admins on-chain. The checkpoint can
 // be verified by clients locally during the checkpoint syncing.
+//
+// If multiple oracle contracts are configured, a checkpoint is only considered
+// stable if a quorum of them registered it in the same block, which allows the
+// clients to verify all approvals from the logs of the registration block.
 type CheckpointOracle struct {
-	config   *params.CheckpointOracleConfig
-	contract *checkpointoracle.CheckpointOracle
+	config    *params.CheckpointOracleConfig
+	oracles   []*params.CheckpointOracleConfig     // Configs of all oracle contracts, the primary first
+	contracts []*checkpointoracle.CheckpointOracle // Bound oracle contracts, in the order of the configs
 
 	running  int32                                 // Flag whether the contract backend is set or not
 	getLocal func(uint64) params.TrustedCheckpoint // Function used to retrieve local checkpoint
@@ -53,23 +59,28 @@ type CheckpointOracle struct {
 func New(config *params.CheckpointOracleConfig, getLocal func(uint64) params.TrustedCheckpoint) *CheckpointOracle {
 	return &CheckpointOracle{
 		config:   config,
+		oracles:  config.AllOracles(),
 		getLocal: getLocal,
 	}
 }
 
-// Start binds the contract backend, initializes the oracle instance
+// Start binds the contract backend, initializes the oracle instances
 // and marks the status as available.
 func (oracle *CheckpointOracle) Start(backend bind.ContractBackend) {
-	contract, err := checkpointoracle.NewCheckpointOracle(oracle.config.Address, backend)
-	if err != nil {
-		log.Error("Oracle contract binding failed", "err", err)
-		return
+	contracts := make([]*checkpointoracle.CheckpointOracle, 0, len(oracle.oracles))
+	for _, config := range oracle.oracles {
+		contract, err := checkpointoracle.NewCheckpointOracle(config.Address, backend)
+		if err != nil {
+			log.Error("Oracle contract binding failed", "address", config.Address, "err", err)
+			return
+		}
+		contracts = append(contracts, contract)
 	}
 	if !atomic.CompareAndSwapInt32(&oracle.running, 0, 1) {
 		log.Error("Already bound and listening to registrar")
 		return
 	}
-	oracle.contract = contract
+	oracle.contracts = contracts
 }
 
 // IsRunning returns an indicator whether the oracle is running.
@@ -77,9 +88,20 @@ func (oracle *CheckpointOracle) IsRunning() bool {
 	return atomic.LoadInt32(&oracle.running) == 1
 }
 
-// Contract returns the underlying raw checkpoint oracle contract.
+// Contract returns the underlying raw checkpoint oracle contract of the primary
+// oracle.
 func (oracle *CheckpointOracle) Contract() *checkpointoracle.CheckpointOracle {
-	return oracle.contract
+	if len(oracle.contracts) == 0 {
+		return nil
+	}
+	return oracle.contracts[0]
+}
+
+// registration is the latest checkpoint registered in an oracle contract.
+type registration struct {
+	index  uint64
+	hash   common.Hash
+	height uint64
 }
 
 // StableCheckpoint returns the stable checkpoint which was generated by local
@@ -91,15 +113,33 @@ func (oracle *CheckpointOracle) StableCheckpoint() (*params.TrustedCheckpoint, u
 		return oracle.lastCheckPoint, oracle.lastCheckPointHeight
 	}
 	// Look it up properly
-	// Retrieve the latest checkpoint from the contract, abort if empty
-	latest, hash, height, err := oracle.contract.Contract().GetLatestCheckpoint(nil)
+	// Retrieve the latest checkpoints from the contracts, abort if none is approved
+	// by enough oracles.
+	votes := make(map[registration]int)
+	for _, contract := range oracle.contracts {
+		latest, hash, height, err := contract.Contract().GetLatestCheckpoint(nil)
+		if err != nil || (latest == 0 && hash == [32]byte{}) {
+			continue
+		}
+		votes[registration{index: latest, hash: hash, height: height.Uint64()}]++
+	}
 	oracle.lastCheckTime = time.Now()
-	if err != nil || (latest == 0 && hash == [32]byte{}) {
+
+	var (
+		stable registration
+		found  bool
+	)
+	for reg, count := range votes {
+		if count >= oracle.config.RequiredQuorum() && (!found || reg.index > stable.index) {
+			stable, found = reg, true
+		}
+	}
+	if !found {
 		oracle.lastCheckPointHeight = 0
 		oracle.lastCheckPoint = nil
 		return oracle.lastCheckPoint, oracle.lastCheckPointHeight
 	}
-	local := oracle.getLocal(latest)
+	local := oracle.getLocal(stable.index)
 
 	// The following scenarios may occur:
 	//
@@ -108,19 +148,58 @@ func (oracle *CheckpointOracle) StableCheckpoint() (*params.TrustedCheckpoint, u
 	// * local checkpoint doesn't match with the registered one.
 	//
 	// In both cases, no stable checkpoint will be returned.
-	if local.HashEqual(hash) {
-		oracle.lastCheckPointHeight = height.Uint64()
+	if local.HashEqual(stable.hash) {
+		oracle.lastCheckPointHeight = stable.height
 		oracle.lastCheckPoint = &local
 		return oracle.lastCheckPoint, oracle.lastCheckPointHeight
 	}
 	return nil, 0
 }
 
+// VerifyCheckpoint checks whgdtuer the checkpoint of the given section has been
+// approved by a quorum of the oracles, according to the registration events in
+// the given logs. The signers of the approving oracles are returned.
+func (oracle *CheckpointOracle) VerifyCheckpoint(logs [][]*types.Log, index uint64, hash common.Hash) (bool, []common.Address) {
+	var (
+		approvals int
+		signers   []common.Address
+	)
+	for i, contract := range oracle.contracts {
+		events := contract.LookupCheckpointEvents(logs, index, hash)
+		if len(events) == 0 {
+			continue
+		}
+		var signatures [][]byte
+		for _, event := range events {
+			signatures = append(signatures, append(event.R[:], append(event.S[:], event.V)...))
+		}
+		if valid, approved := verifySigners(oracle.oracles[i], index, hash, signatures); valid {
+			approvals++
+			signers = append(signers, approved...)
+		}
+	}
+	if quorum := oracle.config.RequiredQuorum(); approvals < quorum {
+		log.Warn("Not enough oracles to approve checkpoint", "oracles", approvals, "quorum", quorum)
+		return false, nil
+	}
+	return true, signers
+}
+
 // VerifySigners recovers the signer addresses according to the signature and
-// checks whether there are enough approvals to finalize the checkpoint.
+// checks whether there are enough approvals of the primary oracle to finalize
+// the checkpoint.
 func (oracle *CheckpointOracle) VerifySigners(index uint64, hash [32]byte, signatures [][]byte) (bool, []common.Address) {
+	return verifySigners(oracle.config, index, hash, signatures)
+}
+
+// verifySigners recovers the signer addresses according to the signature and
+// checks whether there are enough approvals of the signers of the given oracle,
+// in effect for the checkpoint section, to finalize the checkpoint.
+func verifySigners(config *params.CheckpointOracleConfig, index uint64, hash [32]byte, signatures [][]byte) (bool, []common.Address) {
+	trusted, threshold := config.SignersAt(index)
+
 	// Short circuit if the given signatures doesn't reach the threshold.
-	if len(signatures) < int(oracle.config.Threshold) {
+	if len(signatures) < int(threshold) {
 		return false, nil
 	}
 	var (
@@ -143,7 +222,7 @@ func (oracle *CheckpointOracle) VerifySigners(index uint64, hash [32]byte, signa
 		//     hash = keccak256(checkpoint_index, section_head, cht_root, bloom_root)
 		buf := make([]byte, 8)
 		binary.BigEndian.PutUint64(buf, index)
-		data := append([]byte{0x19, 0x00}, append(oracle.config.Address.Bytes(), append(buf, hash[:]...)...)...)
+		data := append([]byte{0x19, 0x00}, append(config.Address.Bytes(), append(buf, hash[:]...)...)...)
 		signatures[i][64] -= 27 // Transform V from 27/28 to 0/1 according to the yellow paper for verification.
 		pubkey, err := crypto.Ecrecover(crypto.Keccak256(data), signatures[i])
 		if err != nil {
@@ -154,16 +233,15 @@ func (oracle *CheckpointOracle) VerifySigners(index uint64, hash [32]byte, signa
 		if _, exist := checked[signer]; exist {
 			continue
 		}
-		for _, s := range oracle.config.Signers {
+		for _, s := range trusted {
 			if s == signer {
 				signers = append(signers, signer)
 				checked[signer] = struct{}{}
 			}
 		}
 	}
-	threshold := oracle.config.Threshold
 	if uint64(len(signers)) < threshold {
-		log.Warn("Not enough signers to approve checkpoint", "signers", len(signers), "threshold", threshold)
+		log.Warn("Not enough signers to approve checkpoint", "oracle", config.Address, "signers", len(signers), "threshold", threshold)
 		return false, nil
 	}
 	return true, signers
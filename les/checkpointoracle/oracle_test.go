@@ -0,0 +1,130 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package checkpointoracle
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/accounts/abi"
+	"github.com/c88032111/go-gdtu/accounts/abi/bind/backends"
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/contracts/checkpointoracle/contract"
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// voteLogs creates the registration event logs of a checkpoint in an oracle,
+// signed by the given keys.
+func voteLogs(t *testing.T, oracle common.Address, index uint64, hash common.Hash, keys ...*ecdsa.PrivateKey) []*types.Log {
+	parsed, err := abi.JSON(strings.NewReader(contract.CheckpointOracleABI))
+	if err != nil {
+		t.Fatalf("failed to parse oracle ABI: %v", err)
+	}
+	event := parsed.Events["NewCheckpointVote"]
+
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, index)
+	digest := crypto.Keccak256(append([]byte{0x19, 0x00}, append(oracle.Bytes(), append(buf, hash[:]...)...)...))
+
+	var logs []*types.Log
+	for _, key := range keys {
+		sig, err := crypto.Sign(digest, key)
+		if err != nil {
+			t.Fatalf("failed to sign checkpoint: %v", err)
+		}
+		data, err := event.Inputs.NonIndexed().Pack(hash, sig[64]+27, common.BytesToHash(sig[:32]), common.BytesToHash(sig[32:64]))
+		if err != nil {
+			t.Fatalf("failed to pack vote: %v", err)
+		}
+		logs = append(logs, &types.Log{
+			Address: oracle,
+			Topics:  []common.Hash{event.ID, common.BigToHash(new(big.Int).SetUint64(index))},
+			Data:    data,
+		})
+	}
+	return logs
+}
+
+// Tests that checkpoints are only verified if approved by a quorum of oracles,
+// each according to the signer set in effect for the checkpoint section.
+func TestVerifyCheckpoint(t *testing.T) {
+	var (
+		key1, _ = crypto.GenerateKey()
+		key2, _ = crypto.GenerateKey()
+		key3, _ = crypto.GenerateKey()
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		addr3   = crypto.PubkeyToAddress(key3.PublicKey)
+
+		primary = common.Address{0x01}
+		second  = common.Address{0x02}
+		third   = common.Address{0x03}
+		hash    = common.Hash{0xff}
+	)
+	config := &params.CheckpointOracleConfig{
+		Address:   primary,
+		Signers:   []common.Address{addr1},
+		Threshold: 1,
+		Rotations: []params.CheckpointSignerRotation{
+			{Section: 10, Signers: []common.Address{addr2}, Threshold: 1},
+		},
+		Oracles: []*params.CheckpointOracleConfig{
+			{Address: second, Signers: []common.Address{addr3}, Threshold: 1},
+			{Address: third, Signers: []common.Address{addr3}, Threshold: 1},
+		},
+		Quorum: 2,
+	}
+	oracle := New(config, nil)
+	oracle.Start(backends.NewSimulatedBackend(core.GenesisAlloc{}, 10000000))
+
+	tests := []struct {
+		logs  []*types.Log
+		index uint64
+		valid bool
+	}{
+		// A single oracle doesn't reach the quorum
+		{voteLogs(t, primary, 5, hash, key1), 5, false},
+		// Two oracles reach the quorum
+		{append(voteLogs(t, primary, 5, hash, key1), voteLogs(t, second, 5, hash, key3)...), 5, true},
+		{append(voteLogs(t, second, 5, hash, key3), voteLogs(t, third, 5, hash, key3)...), 5, true},
+		// Rotated out signers don't approve the checkpoint
+		{append(voteLogs(t, primary, 10, hash, key1), voteLogs(t, second, 10, hash, key3)...), 10, false},
+		{append(voteLogs(t, primary, 10, hash, key2), voteLogs(t, second, 10, hash, key3)...), 10, true},
+		// Votes signed for another oracle don't count
+		{append(voteLogs(t, second, 5, hash, key3), relocate(voteLogs(t, second, 5, hash, key3), third)...), 5, false},
+	}
+	for i, test := range tests {
+		valid, _ := oracle.VerifyCheckpoint([][]*types.Log{test.logs}, test.index, hash)
+		if valid != test.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want %v", i, valid, test.valid)
+		}
+	}
+}
+
+// relocate moves the logs to another emitting contract.
+func relocate(logs []*types.Log, address common.Address) []*types.Log {
+	for _, log := range logs {
+		log.Address = address
+	}
+	return logs
+}
@@ -47,6 +47,7 @@ import (
 	"github.com/c88032111/go-gdtu/params"
 	"github.com/c88032111/go-gdtu/rlp"
 	"github.com/c88032111/go-gdtu/rpc"
+	lru "github.com/hashicorp/golang-lru"
 )
 
 type LightGdtu struct {
@@ -66,6 +67,7 @@ type LightGdtu struct {
 
 	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
+	bloomCache    *lru.Cache                     // Cache of the recently retrieved bloom bit vectors
 
 	ApiBackend     *LesApiBackend
 	eventMux       *event.TypeMux
@@ -94,6 +96,7 @@ func New(stack *node.Node, config *gdtuconfig.Config) (*LightGdtu, error) {
 	log.Info("Initialised chain configuration", "config", chainConfig)
 
 	peers := newServerPeerSet()
+	bloomCache, _ := lru.New(bloomCacheSize)
 	lgdtu := &LightGdtu{
 		lesCommons: lesCommons{
 			genesis:     genesisHash,
@@ -110,6 +113,7 @@ func New(stack *node.Node, config *gdtuconfig.Config) (*LightGdtu, error) {
 		accountManager: stack.AccountManager(),
 		engine:         gdtuconfig.CreateConsensusEngine(stack, chainConfig, &config.Gdtuash, nil, false, chainDb),
 		bloomRequests:  make(chan chan *bloombits.Retrieval),
+		bloomCache:     bloomCache,
 		bloomIndexer:   core.NewBloomIndexer(chainDb, params.BloomBitsBlocksClient, params.HelperTrieConfirmations),
 		p2pServer:      stack.Server(),
 		p2pConfig:      &stack.Config().P2P,
@@ -121,11 +125,23 @@ func New(stack *node.Node, config *gdtuconfig.Config) (*LightGdtu, error) {
 	}
 	lgdtu.serverPool, lgdtu.serverPoolIterator = vfc.NewServerPool(lesDb, []byte("serverpool:"), time.Second, prenegQuery, &mclock.System{}, config.UltraLightServers, requestList)
 	lgdtu.serverPool.AddMetrics(suggestedTimeoutGauge, totalValueGauge, serverSelectableGauge, serverConnectedGauge, sessionValueMeter, serverDialedMeter)
+	if config.LightServersPerNetwork > 0 {
+		var resolver vfc.NetworkResolver = vfc.SubnetResolver{}
+		if config.LightNetworkMap != "" {
+			if resolver, err = vfc.LoadPrefixResolver(stack.ResolvePath(config.LightNetworkMap)); err != nil {
+				return nil, fmt.Errorf("failed to load light server network map: %v", err)
+			}
+		}
+		lgdtu.serverPool.SetDiversity(resolver, config.LightServersPerNetwork)
+	}
 
 	lgdtu.retriever = newRetrieveManager(peers, lgdtu.reqDist, lgdtu.serverPool.GetTimeout)
 	lgdtu.relay = newLesTxRelay(peers, lgdtu.retriever)
 
 	lgdtu.odr = NewLesOdr(chainDb, light.DefaultClientIndexerConfig, lgdtu.peers, lgdtu.retriever)
+	cacheConfig := DefaultOdrCacheConfig
+	cacheConfig.Size = config.LightOdrCache
+	lgdtu.odr.SetCache(cacheConfig, &mclock.System{})
 	lgdtu.chtIndexer = light.NewChtIndexer(chainDb, lgdtu.odr, params.CHTFrequency, params.HelperTrieConfirmations, config.LightNoPrune)
 	lgdtu.bloomTrieIndexer = light.NewBloomTrieIndexer(chainDb, lgdtu.odr, params.BloomBitsBlocksClient, params.BloomTrieFrequency, config.LightNoPrune)
 	lgdtu.odr.SetIndexers(lgdtu.chtIndexer, lgdtu.bloomTrieIndexer, lgdtu.bloomIndexer)
@@ -294,7 +310,7 @@ func (s *LightGdtu) APIs() []rpc.API {
 		}, {
 			Namespace: "gdtu",
 			Version:   "1.0",
-			Service:   downloader.NewPublicDownloaderAPI(s.handler.downloader, s.eventMux),
+			Service:   downloader.NewPublicDownloaderAPI(s.handler.downloader),
 			Public:    true,
 		}, {
 			Namespace: "gdtu",
@@ -311,6 +327,11 @@ func (s *LightGdtu) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   NewPrivateLightAPI(&s.lesCommons),
 			Public:    false,
+		}, {
+			Namespace: "les",
+			Version:   "1.0",
+			Service:   NewPrivateLightClientAPI(s.odr),
+			Public:    false,
 		}, {
 			Namespace: "vflux",
 			Version:   "1.0",
@@ -353,10 +374,23 @@ func (s *LightGdtu) Start() error {
 	s.serverPool.AddSource(discovery)
 	s.serverPool.Start()
 	// Start bloom request workers.
-	s.wg.Add(bloomServiceThreads)
+	s.wg.Add(bloomServiceThreads + 1)
 	s.startBloomHandlers(params.BloomBitsBlocksClient)
 	s.handler.start()
 
+	// Connect to the light servers tunneled over WebSocket
+	if len(s.config.LightWebsocketServers) > 0 {
+		var servers []*websocketServer
+		for _, rawurl := range s.config.LightWebsocketServers {
+			server, err := parseWebsocketServer(rawurl)
+			if err != nil {
+				return fmt.Errorf("invalid light server %q: %v", rawurl, err)
+			}
+			servers = append(servers, server)
+		}
+		s.wg.Add(1)
+		go s.websocketDialLoop(servers)
+	}
 	return nil
 }
 
@@ -337,6 +337,66 @@ func (h *clientHandler) handleMsg(p *serverPeer) error {
 			ReqID:   resp.ReqID,
 			Obj:     resp.Status,
 		}
+	case msg.Code == TxStatusUpdateMsg && p.version >= lpv5:
+		p.Log().Trace("Received tx status update")
+		var update TxStatusUpdatePacket
+		if err := msg.Decode(&update); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		if len(update.Hashes) != len(update.Status) {
+			return errResp(ErrDecode, "msg %v: status count mismatch", msg)
+		}
+		if h.backend.relay != nil {
+			h.backend.relay.statusUpdate(update.Hashes, update.Status)
+		}
+	case msg.Code == EpochProofsMsg && p.version >= lpv5:
+		p.Log().Trace("Received epoch proof response")
+		var resp struct {
+			ReqID, BV uint64
+			Proofs    []EpochProof
+		}
+		if err := msg.Decode(&resp); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		p.fcServer.ReceivedReply(resp.ReqID, resp.BV)
+		p.answeredRequest(resp.ReqID)
+		deliverMsg = &Msg{
+			MsgType: MsgEpochProofs,
+			ReqID:   resp.ReqID,
+			Obj:     resp.Proofs,
+		}
+	case msg.Code == BloomBitsMsg && p.version >= lpv5:
+		p.Log().Trace("Received bloombits response")
+		var resp struct {
+			ReqID, BV uint64
+			Data      light.NodeList
+		}
+		if err := msg.Decode(&resp); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		p.fcServer.ReceivedReply(resp.ReqID, resp.BV)
+		p.answeredRequest(resp.ReqID)
+		deliverMsg = &Msg{
+			MsgType: MsgBloomBits,
+			ReqID:   resp.ReqID,
+			Obj:     resp.Data,
+		}
+	case msg.Code == LogsMsg && p.version >= lpv5:
+		p.Log().Trace("Received logs response")
+		var resp struct {
+			ReqID, BV uint64
+			Data      LogsResponse
+		}
+		if err := msg.Decode(&resp); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		p.fcServer.ReceivedReply(resp.ReqID, resp.BV)
+		p.answeredRequest(resp.ReqID)
+		deliverMsg = &Msg{
+			MsgType: MsgLogs,
+			ReqID:   resp.ReqID,
+			Obj:     resp.Data,
+		}
 	case msg.Code == StopMsg && p.version >= lpv3:
 		p.freeze()
 		h.backend.retriever.frozen(p)
@@ -461,6 +521,41 @@ func (pc *peerConnection) RetrieveSingleHeaderByNumber(context context.Context,
 	return header, nil
 }
 
+// RetrieveEpochProofs requests the proofs of the given clique epochs. This
+// function will wait the response until it's timeout or delivered.
+func (pc *peerConnection) RetrieveEpochProofs(context context.Context, epochs []uint64) ([]EpochProof, error) {
+	reqID := genReqID()
+	rq := &distReq{
+		getCost: func(dp distPeer) uint64 {
+			peer := dp.(*serverPeer)
+			return peer.getRequestCost(GetEpochProofsMsg, len(epochs))
+		},
+		canSend: func(dp distPeer) bool {
+			return dp.(*serverPeer) == pc.peer
+		},
+		request: func(dp distPeer) func() {
+			peer := dp.(*serverPeer)
+			cost := peer.getRequestCost(GetEpochProofsMsg, len(epochs))
+			peer.fcServer.QueuedRequest(reqID, cost)
+			return func() { peer.requestEpochProofs(reqID, epochs) }
+		},
+	}
+	var proofs []EpochProof
+	if err := pc.handler.backend.retriever.retrieve(context, reqID, rq, func(peer distPeer, msg *Msg) error {
+		if msg.MsgType != MsgEpochProofs {
+			return errInvalidMessageType
+		}
+		proofs = msg.Obj.([]EpochProof)
+		if len(proofs) == 0 || len(proofs) > len(epochs) {
+			return errInvalidEntryCount
+		}
+		return nil
+	}, nil); err != nil {
+		return nil, err
+	}
+	return proofs, nil
+}
+
 // downloaderPeerNotify implements peerSetNotify
 type downloaderPeerNotify clientHandler
 
@@ -148,14 +148,14 @@ func (c *lesCommons) setupOracle(node *node.Node, genesis common.Hash, gdtuconfi
 		log.Info("Checkpoint oracle is not enabled")
 		return nil
 	}
-	if config.Address == (common.Address{}) || uint64(len(config.Signers)) < config.Threshold {
-		log.Warn("Invalid checkpoint oracle config")
+	if err := config.Validate(); err != nil {
+		log.Warn("Invalid checkpoint oracle config", "err", err)
 		return nil
 	}
 	oracle := checkpointoracle.New(config, c.localCheckpoint)
 	rpcClient, _ := node.Attach()
 	client := gdtuclient.NewClient(rpcClient)
 	oracle.Start(client)
-	log.Info("Configured checkpoint oracle", "address", config.Address, "signers", len(config.Signers), "threshold", config.Threshold)
+	log.Info("Configured checkpoint oracle", "address", config.Address, "signers", len(config.Signers), "threshold", config.Threshold, "oracles", len(config.Oracles)+1, "quorum", config.RequiredQuorum())
 	return oracle
 }
@@ -44,6 +44,10 @@ var (
 		GetHelperTrieProofsMsg: {0, 1000000},
 		SendTxV2Msg:            {0, 450000},
 		GetTxStatusMsg:         {0, 250000},
+		GetEpochProofsMsg:      {0, 500000},
+		SubscribeTxStatusMsg:   {0, 250000},
+		GetBloomBitsMsg:        {0, 1000000},
+		GetLogsMsg:             {150000, 40000},
 	}
 	// maximum incoming message size estimates
 	reqMaxInSize = requestCostTable{
@@ -55,6 +59,10 @@ var (
 		GetHelperTrieProofsMsg: {0, 20},
 		SendTxV2Msg:            {0, 16500},
 		GetTxStatusMsg:         {0, 50},
+		GetEpochProofsMsg:      {0, 10},
+		SubscribeTxStatusMsg:   {0, 50},
+		GetBloomBitsMsg:        {0, 20},
+		GetLogsMsg:             {1500, 0},
 	}
 	// maximum outgoing message size estimates
 	reqMaxOutSize = requestCostTable{
@@ -66,6 +74,10 @@ var (
 		GetHelperTrieProofsMsg: {0, 4000},
 		SendTxV2Msg:            {0, 100},
 		GetTxStatusMsg:         {0, 100},
+		GetEpochProofsMsg:      {0, 20000},
+		SubscribeTxStatusMsg:   {0, 100},
+		GetBloomBitsMsg:        {0, 4000},
+		GetLogsMsg:             {0, 8000},
 	}
 	// request amounts that have to fit into the minimum buffer size minBufferMultiplier times
 	minBufferReqAmount = map[uint64]uint64{
@@ -77,6 +89,10 @@ var (
 		GetHelperTrieProofsMsg: 16,
 		SendTxV2Msg:            8,
 		GetTxStatusMsg:         64,
+		GetEpochProofsMsg:      4,
+		SubscribeTxStatusMsg:   64,
+		GetBloomBitsMsg:        16,
+		GetLogsMsg:             16,
 	}
 	minBufferMultiplier = 3
 )
@@ -293,8 +309,14 @@ func (ct *costTracker) gfLoop() {
 						relativeCostHelperProofHistogram.Update(relCost)
 					case SendTxV2Msg:
 						relativeCostSendTxHistogram.Update(relCost)
-					case GetTxStatusMsg:
+					case GetTxStatusMsg, SubscribeTxStatusMsg:
 						relativeCostTxStatusHistogram.Update(relCost)
+					case GetEpochProofsMsg:
+						relativeCostEpochProofHistogram.Update(relCost)
+					case GetBloomBitsMsg:
+						relativeCostBloomBitsHistogram.Update(relCost)
+					case GetLogsMsg:
+						relativeCostLogsHistogram.Update(relCost)
 					}
 				}
 				// SendTxV2 and GetTxStatus requests are two special cases.
@@ -303,7 +325,7 @@ func (ct *costTracker) gfLoop() {
 				// requests involve txpool query, which is usually unstable.
 				//
 				// TODO(rjl493456442) fixes this.
-				if r.msgCode == SendTxV2Msg || r.msgCode == GetTxStatusMsg {
+				if r.msgCode == SendTxV2Msg || r.msgCode == GetTxStatusMsg || r.msgCode == SubscribeTxStatusMsg {
 					continue
 				}
 				requestServedMeter.Mark(int64(r.servingTime))
@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"math/big"
 	"math/rand"
+	"reflect"
 	"testing"
 	"time"
 
@@ -588,9 +589,93 @@ func testGetBloombitsProofs(t *testing.T, protocol int) {
 	}
 }
 
+// Tests that the bloombits of several bits are proven in a single les/5 request.
+func TestGetBloomBitsLes5(t *testing.T) {
+	var (
+		config       = light.TestServerIndexerConfig
+		waitIndexers = func(cIndexer, bIndexer, btIndexer *core.ChainIndexer) {
+			for {
+				bts, _, _ := btIndexer.Sections()
+				if bts >= 1 {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+		netconfig = testnetConfig{
+			blocks:    int(config.BloomTrieSize + config.BloomTrieConfirms),
+			protocol:  lpv5,
+			indexFn:   waitIndexers,
+			nopruning: true,
+		}
+	)
+	server, _, tearDown := newClientServerEnv(t, netconfig)
+	defer tearDown()
+
+	rawPeer, closePeer, _ := server.newRawPeer(t, "peer", lpv5)
+	defer closePeer()
+
+	bc := server.handler.blockchain
+	root := light.GetBloomTrieRoot(server.db, 0, bc.GetHeaderByNumber(config.BloomTrieSize-1).Hash())
+	trie, _ := trie.New(root, trie.NewDatabase(rawdb.NewTable(server.db, light.BloomTrieTablePrefix)))
+
+	// Request a batch of bits in the first section, the only one with data
+	query := BloomBitsQuery{BloomTrieNum: 0, Sections: []uint64{0}}
+	for bit := uint(0); bit < MaxBloomBitsFetch; bit++ {
+		query.Bits = append(query.Bits, bit*32)
+	}
+	proofs := light.NewNodeSet()
+	for _, bit := range query.Bits {
+		trie.Prove(bloomBitsKey(bit, 0), 0, proofs)
+	}
+	sendRequest(rawPeer.app, GetBloomBitsMsg, 42, query)
+	if err := expectResponse(rawPeer.app, BloomBitsMsg, 42, testBufLimit, proofs.NodeList()); err != nil {
+		t.Errorf("proofs mismatch: %v", err)
+	}
+}
+
+// Tests that log filter requests are served up to the local head, along with the
+// proofs of the matching receipts.
+func TestGetLogsLes5(t *testing.T) {
+	netconfig := testnetConfig{
+		blocks:    4,
+		protocol:  lpv5,
+		nopruning: true,
+	}
+	server, _, tearDown := newClientServerEnv(t, netconfig)
+	defer tearDown()
+
+	rawPeer, closePeer, _ := server.newRawPeer(t, "peer", lpv5)
+	defer closePeer()
+
+	// Request a range exceeding the chain head, expect the known blocks filtered
+	var (
+		bc    = server.handler.blockchain
+		query = LogsQuery{FromBlock: 2, ToBlock: 10}
+		nodes = light.NewNodeSet()
+		resp  = LogsResponse{Count: 3}
+	)
+	for i := query.FromBlock; i <= bc.CurrentBlock().NumberU64(); i++ {
+		block := bc.GetBlockByNumber(i)
+		if matched := proveLogs(block.Transactions(), bc.GetReceiptsByHash(block.Hash()), &query, nodes); len(matched) > 0 {
+			resp.Blocks = append(resp.Blocks, LogsBlock{Hash: block.Hash(), Number: i, Receipts: matched})
+		}
+	}
+	if len(resp.Blocks) == 0 {
+		t.Fatalf("No logs in the test chain")
+	}
+	resp.Proofs = nodes.NodeList()
+
+	sendRequest(rawPeer.app, GetLogsMsg, 42, query)
+	if err := expectResponse(rawPeer.app, LogsMsg, 42, testBufLimit, resp); err != nil {
+		t.Errorf("logs mismatch: %v", err)
+	}
+}
+
 func TestTransactionStatusLes2(t *testing.T) { testTransactionStatus(t, lpv2) }
 func TestTransactionStatusLes3(t *testing.T) { testTransactionStatus(t, lpv3) }
 func TestTransactionStatusLes4(t *testing.T) { testTransactionStatus(t, lpv4) }
+func TestTransactionStatusLes5(t *testing.T) { testTransactionStatus(t, lpv5) }
 
 func testTransactionStatus(t *testing.T, protocol int) {
 	netconfig := testnetConfig{
@@ -691,6 +776,67 @@ func testTransactionStatus(t *testing.T, protocol int) {
 	test(tx2, false, light.TxStatus{Status: core.TxStatusPending})
 }
 
+// Tests that status changes of subscribed transactions are pushed to the client.
+func TestTransactionStatusSubscriptionLes5(t *testing.T) {
+	netconfig := testnetConfig{
+		protocol:  lpv5,
+		nopruning: true,
+	}
+	server, _, tearDown := newClientServerEnv(t, netconfig)
+	defer tearDown()
+
+	rawPeer, closePeer, _ := server.newRawPeer(t, "peer", lpv5)
+	defer closePeer()
+
+	server.handler.addTxsSync = true
+
+	signer := types.HomesteadSigner{}
+	tx, _ := types.SignTx(types.NewTransaction(0, userAddr1, big.NewInt(10000), params.TxGas, big.NewInt(100000000000), nil), signer, bankKey)
+	unknown, _ := types.SignTx(types.NewTransaction(5, userAddr1, big.NewInt(10000), params.TxGas, big.NewInt(100000000000), nil), signer, bankKey)
+
+	// Send the transaction and subscribe to it, along with an unknown one
+	sendRequest(rawPeer.app, SendTxV2Msg, 1, types.Transactions{tx})
+	if err := expectResponse(rawPeer.app, TxStatusMsg, 1, testBufLimit, []light.TxStatus{{Status: core.TxStatusPending}}); err != nil {
+		t.Fatalf("transaction status mismatch: %v", err)
+	}
+	sendRequest(rawPeer.app, SubscribeTxStatusMsg, 2, []common.Hash{tx.Hash(), unknown.Hash()})
+	if err := expectResponse(rawPeer.app, TxStatusMsg, 2, testBufLimit, []light.TxStatus{{Status: core.TxStatusPending}, {Status: core.TxStatusUnknown}}); err != nil {
+		t.Fatalf("transaction status mismatch: %v", err)
+	}
+	// Include the transaction in a block and wait for the status update, skipping
+	// the block announcement
+	chain := server.handler.blockchain
+	gchain, _ := core.GenerateChain(params.TestChainConfig, chain.GetBlockByNumber(0), gdtuash.NewFaker(), server.db, 1, func(i int, block *core.BlockGen) {
+		block.AddTx(tx)
+	})
+	if _, err := chain.InsertChain(gchain); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	want := TxStatusUpdatePacket{
+		Hashes: []common.Hash{tx.Hash()},
+		Status: []light.TxStatus{{Status: core.TxStatusIncluded, Lookup: &rawdb.LegacyTxLookupEntry{BlockHash: gchain[0].Hash(), BlockIndex: 1, Index: 0}}},
+	}
+	for i := 0; i < 2; i++ {
+		msg, err := rawPeer.app.ReadMsg()
+		if err != nil {
+			t.Fatalf("failed to read message: %v", err)
+		}
+		if msg.Code != TxStatusUpdateMsg {
+			msg.Discard()
+			continue
+		}
+		var update TxStatusUpdatePacket
+		if err := msg.Decode(&update); err != nil {
+			t.Fatalf("failed to decode status update: %v", err)
+		}
+		if !reflect.DeepEqual(update, want) {
+			t.Fatalf("status update mismatch: have %+v, want %+v", update, want)
+		}
+		return
+	}
+	t.Fatalf("no status update received")
+}
+
 func TestStopResumeLES3(t *testing.T) { testStopResume(t, lpv3) }
 func TestStopResumeLES4(t *testing.T) { testStopResume(t, lpv4) }
 
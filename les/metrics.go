@@ -40,6 +40,12 @@ var (
 	miscInTxsTrafficMeter        = metrics.NewRegisteredMeter("les/misc/in/traffic/txs", nil)
 	miscInTxStatusPacketsMeter   = metrics.NewRegisteredMeter("les/misc/in/packets/txStatus", nil)
 	miscInTxStatusTrafficMeter   = metrics.NewRegisteredMeter("les/misc/in/traffic/txStatus", nil)
+	miscInEpochProofPacketsMeter = metrics.NewRegisteredMeter("les/misc/in/packets/epochProof", nil)
+	miscInEpochProofTrafficMeter = metrics.NewRegisteredMeter("les/misc/in/traffic/epochProof", nil)
+	miscInBloomBitsPacketsMeter  = metrics.NewRegisteredMeter("les/misc/in/packets/bloomBits", nil)
+	miscInBloomBitsTrafficMeter  = metrics.NewRegisteredMeter("les/misc/in/traffic/bloomBits", nil)
+	miscInLogsPacketsMeter       = metrics.NewRegisteredMeter("les/misc/in/packets/logs", nil)
+	miscInLogsTrafficMeter       = metrics.NewRegisteredMeter("les/misc/in/traffic/logs", nil)
 
 	miscOutPacketsMeter           = metrics.NewRegisteredMeter("les/misc/out/packets/total", nil)
 	miscOutTrafficMeter           = metrics.NewRegisteredMeter("les/misc/out/traffic/total", nil)
@@ -59,6 +65,12 @@ var (
 	miscOutTxsTrafficMeter        = metrics.NewRegisteredMeter("les/misc/out/traffic/txs", nil)
 	miscOutTxStatusPacketsMeter   = metrics.NewRegisteredMeter("les/misc/out/packets/txStatus", nil)
 	miscOutTxStatusTrafficMeter   = metrics.NewRegisteredMeter("les/misc/out/traffic/txStatus", nil)
+	miscOutEpochProofPacketsMeter = metrics.NewRegisteredMeter("les/misc/out/packets/epochProof", nil)
+	miscOutEpochProofTrafficMeter = metrics.NewRegisteredMeter("les/misc/out/traffic/epochProof", nil)
+	miscOutBloomBitsPacketsMeter  = metrics.NewRegisteredMeter("les/misc/out/packets/bloomBits", nil)
+	miscOutBloomBitsTrafficMeter  = metrics.NewRegisteredMeter("les/misc/out/traffic/bloomBits", nil)
+	miscOutLogsPacketsMeter       = metrics.NewRegisteredMeter("les/misc/out/packets/logs", nil)
+	miscOutLogsTrafficMeter       = metrics.NewRegisteredMeter("les/misc/out/traffic/logs", nil)
 
 	miscServingTimeHeaderTimer     = metrics.NewRegisteredTimer("les/misc/serve/header", nil)
 	miscServingTimeBodyTimer       = metrics.NewRegisteredTimer("les/misc/serve/body", nil)
@@ -68,6 +80,9 @@ var (
 	miscServingTimeHelperTrieTimer = metrics.NewRegisteredTimer("les/misc/serve/helperTrie", nil)
 	miscServingTimeTxTimer         = metrics.NewRegisteredTimer("les/misc/serve/txs", nil)
 	miscServingTimeTxStatusTimer   = metrics.NewRegisteredTimer("les/misc/serve/txStatus", nil)
+	miscServingTimeEpochProofTimer = metrics.NewRegisteredTimer("les/misc/serve/epochProof", nil)
+	miscServingTimeBloomBitsTimer  = metrics.NewRegisteredTimer("les/misc/serve/bloomBits", nil)
+	miscServingTimeLogsTimer       = metrics.NewRegisteredTimer("les/misc/serve/logs", nil)
 
 	connectionTimer       = metrics.NewRegisteredTimer("les/connection/duration", nil)
 	serverConnectionGauge = metrics.NewRegisteredGauge("les/connection/server", nil)
@@ -91,6 +106,9 @@ var (
 	relativeCostHelperProofHistogram = metrics.NewRegisteredHistogram("les/server/req/relative/helperTrie", nil, metrics.NewExpDecaySample(1028, 0.015))
 	relativeCostSendTxHistogram      = metrics.NewRegisteredHistogram("les/server/req/relative/txs", nil, metrics.NewExpDecaySample(1028, 0.015))
 	relativeCostTxStatusHistogram    = metrics.NewRegisteredHistogram("les/server/req/relative/txStatus", nil, metrics.NewExpDecaySample(1028, 0.015))
+	relativeCostEpochProofHistogram  = metrics.NewRegisteredHistogram("les/server/req/relative/epochProof", nil, metrics.NewExpDecaySample(1028, 0.015))
+	relativeCostBloomBitsHistogram   = metrics.NewRegisteredHistogram("les/server/req/relative/bloomBits", nil, metrics.NewExpDecaySample(1028, 0.015))
+	relativeCostLogsHistogram        = metrics.NewRegisteredHistogram("les/server/req/relative/logs", nil, metrics.NewExpDecaySample(1028, 0.015))
 
 	globalFactorGauge    = metrics.NewRegisteredGauge("les/server/globalFactor", nil)
 	recentServedGauge    = metrics.NewRegisteredGauge("les/server/recentRequestServed", nil)
@@ -98,6 +116,12 @@ var (
 	sqServedGauge        = metrics.NewRegisteredGauge("les/server/servingQueue/served", nil)
 	sqQueuedGauge        = metrics.NewRegisteredGauge("les/server/servingQueue/queued", nil)
 
+	capacityServeGauge     = metrics.NewRegisteredGauge("les/server/capacity/serve", nil)
+	capacityCPUGauge       = metrics.NewRegisteredGauge("les/server/capacity/cpu", nil)
+	capacityDiskGauge      = metrics.NewRegisteredGauge("les/server/capacity/disk", nil)
+	capacityScaleUpMeter   = metrics.NewRegisteredMeter("les/server/capacity/scaleUp", nil)
+	capacityScaleDownMeter = metrics.NewRegisteredMeter("les/server/capacity/scaleDown", nil)
+
 	clientConnectedMeter    = metrics.NewRegisteredMeter("les/server/clientEvent/connected", nil)
 	clientActivatedMeter    = metrics.NewRegisteredMeter("les/server/clientEvent/activated", nil)
 	clientDeactivatedMeter  = metrics.NewRegisteredMeter("les/server/clientEvent/deactivated", nil)
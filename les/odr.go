@@ -34,6 +34,7 @@ type LesOdr struct {
 	chtIndexer, bloomTrieIndexer, bloomIndexer *core.ChainIndexer
 	peers                                      *serverPeerSet
 	retriever                                  *retrieveManager
+	cache                                      *odrCache
 	stop                                       chan struct{}
 }
 
@@ -64,6 +65,20 @@ func (odr *LesOdr) SetIndexers(chtIndexer, bloomTrieIndexer, bloomIndexer *core.
 	odr.bloomIndexer = bloomIndexer
 }
 
+// SetCache enables caching the retrieval results with the given settings, or
+// disables it if the configured cache size is zero. It must be called before
+// the ODR backend starts serving requests.
+func (odr *LesOdr) SetCache(config OdrCacheConfig, clock mclock.Clock) {
+	odr.cache = newOdrCache(config, clock)
+}
+
+// FlushCache drops all cached retrieval results.
+func (odr *LesOdr) FlushCache() {
+	if odr.cache != nil {
+		odr.cache.flush()
+	}
+}
+
 // ChtIndexer returns the CHT chain indexer
 func (odr *LesOdr) ChtIndexer() *core.ChainIndexer {
 	return odr.chtIndexer
@@ -92,6 +107,9 @@ const (
 	MsgProofsV2
 	MsgHelperTrieProofs
 	MsgTxStatus
+	MsgEpochProofs
+	MsgBloomBits
+	MsgLogs
 )
 
 // Msg encodes a LES message that delivers reply data for a request
@@ -198,6 +216,10 @@ func (odr *LesOdr) RetrieveTxStatus(ctx context.Context, req *light.TxStatusRequ
 // the additional retry mechanism.
 // If the network retrieval was successful, it stores the object in local db.
 func (odr *LesOdr) Retrieve(ctx context.Context, req light.OdrRequest) (err error) {
+	if odr.cache != nil && odr.cache.get(req) {
+		req.StoreResult(odr.db)
+		return nil
+	}
 	lreq := LesRequest(req)
 
 	reqID := genReqID()
@@ -231,5 +253,8 @@ func (odr *LesOdr) Retrieve(ctx context.Context, req light.OdrRequest) (err erro
 		return err
 	}
 	req.StoreResult(odr.db)
+	if odr.cache != nil {
+		odr.cache.add(req)
+	}
 	return nil
 }
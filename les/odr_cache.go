@@ -0,0 +1,202 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"encoding/binary"
+	"math/big"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/mclock"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/light"
+	"github.com/c88032111/go-gdtu/metrics"
+	"github.com/c88032111/go-gdtu/rlp"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// OdrCacheConfig contains the settings of the light client ODR result cache.
+type OdrCacheConfig struct {
+	Size int // Maximum number of cached retrieval results, 0 disables the cache
+
+	HeaderTTL  time.Duration // Lifetime of canonical headers retrieved by number
+	BodyTTL    time.Duration // Lifetime of block bodies
+	ReceiptTTL time.Duration // Lifetime of block receipts
+	ProofTTL   time.Duration // Lifetime of state and storage trie proofs
+	CodeTTL    time.Duration // Lifetime of contract codes
+}
+
+// DefaultOdrCacheConfig contains the default ODR cache settings. Everything is
+// keyed by content, except headers by number which can be reorged, hence the
+// shorter lifetime for those.
+var DefaultOdrCacheConfig = OdrCacheConfig{
+	Size:       1024,
+	HeaderTTL:  time.Minute,
+	BodyTTL:    30 * time.Minute,
+	ReceiptTTL: 30 * time.Minute,
+	ProofTTL:   10 * time.Minute,
+	CodeTTL:    time.Hour,
+}
+
+var (
+	odrCacheHitMeter  = metrics.NewRegisteredMeter("les/client/odrcache/hit", nil)
+	odrCacheMissMeter = metrics.NewRegisteredMeter("les/client/odrcache/miss", nil)
+	odrCacheSizeGauge = metrics.NewRegisteredGauge("les/client/odrcache/size", nil)
+)
+
+// odrCacheEntry is a single retrieval result with its expiration time.
+type odrCacheEntry struct {
+	result  interface{}
+	expires mclock.AbsTime
+}
+
+// chtResult is the cached outcome of a header retrieval by number.
+type chtResult struct {
+	header *types.Header
+	td     *big.Int
+	proof  light.NodeList
+}
+
+// odrCache is an LRU cache of ODR retrieval results where every entry also
+// expires after a lifetime depending on the type of the request.
+//
+// Cached objects are never handed out directly; requests are always filled with
+// copies so callers are free to modify them (e.g. deriving receipt fields).
+type odrCache struct {
+	config OdrCacheConfig
+	clock  mclock.Clock
+	cache  *lru.Cache
+}
+
+// newOdrCache creates an ODR result cache, or returns nil if the cache is disabled.
+func newOdrCache(config OdrCacheConfig, clock mclock.Clock) *odrCache {
+	if config.Size <= 0 {
+		return nil
+	}
+	cache, _ := lru.New(config.Size)
+	return &odrCache{config: config, clock: clock, cache: cache}
+}
+
+// key returns the cache key and lifetime belonging to a request, or an empty
+// key if the request type is not cacheable.
+func (c *odrCache) key(req light.OdrRequest) (string, time.Duration) {
+	switch r := req.(type) {
+	case *light.BlockRequest:
+		return "b" + string(r.Hash.Bytes()), c.config.BodyTTL
+	case *light.ReceiptsRequest:
+		if r.Untrusted {
+			return "", 0
+		}
+		return "r" + string(r.Hash.Bytes()), c.config.ReceiptTTL
+	case *light.TrieRequest:
+		return "p" + string(r.Id.Root.Bytes()) + string(r.Id.AccKey) + string(r.Key), c.config.ProofTTL
+	case *light.CodeRequest:
+		return "c" + string(r.Hash.Bytes()), c.config.CodeTTL
+	case *light.ChtRequest:
+		var enc [16]byte
+		binary.BigEndian.PutUint64(enc[:8], r.ChtNum)
+		binary.BigEndian.PutUint64(enc[8:], r.BlockNum)
+		return "h" + string(enc[:]) + string(r.ChtRoot.Bytes()), c.config.HeaderTTL
+	}
+	return "", 0
+}
+
+// get fills the given request from the cache if a live entry exists for it.
+func (c *odrCache) get(req light.OdrRequest) bool {
+	key, _ := c.key(req)
+	if key == "" {
+		return false
+	}
+	item, ok := c.cache.Get(key)
+	if !ok {
+		odrCacheMissMeter.Mark(1)
+		return false
+	}
+	entry := item.(*odrCacheEntry)
+	if c.clock.Now() >= entry.expires {
+		c.cache.Remove(key)
+		odrCacheMissMeter.Mark(1)
+		odrCacheSizeGauge.Update(int64(c.cache.Len()))
+		return false
+	}
+	switch r := req.(type) {
+	case *light.BlockRequest:
+		r.Rlp = common.CopyBytes(entry.result.([]byte))
+	case *light.ReceiptsRequest:
+		if err := rlp.DecodeBytes(entry.result.([]byte), &r.Receipts); err != nil {
+			c.cache.Remove(key)
+			return false
+		}
+	case *light.TrieRequest:
+		r.Proof = entry.result.(light.NodeList).NodeSet()
+	case *light.CodeRequest:
+		r.Data = common.CopyBytes(entry.result.([]byte))
+	case *light.ChtRequest:
+		res := entry.result.(*chtResult)
+		r.Header, r.Td, r.Proof = types.CopyHeader(res.header), new(big.Int).Set(res.td), res.proof.NodeSet()
+	}
+	odrCacheHitMeter.Mark(1)
+	return true
+}
+
+// add inserts the result of a successfully retrieved request into the cache.
+func (c *odrCache) add(req light.OdrRequest) {
+	key, ttl := c.key(req)
+	if key == "" || ttl <= 0 {
+		return
+	}
+	var result interface{}
+	switch r := req.(type) {
+	case *light.BlockRequest:
+		result = common.CopyBytes(r.Rlp)
+	case *light.ReceiptsRequest:
+		enc, err := rlp.EncodeToBytes(r.Receipts)
+		if err != nil {
+			return
+		}
+		result = enc
+	case *light.TrieRequest:
+		result = copyProof(r.Proof)
+	case *light.CodeRequest:
+		result = common.CopyBytes(r.Data)
+	case *light.ChtRequest:
+		result = &chtResult{header: types.CopyHeader(r.Header), td: new(big.Int).Set(r.Td), proof: copyProof(r.Proof)}
+	}
+	c.cache.Add(key, &odrCacheEntry{result: result, expires: c.clock.Now() + mclock.AbsTime(ttl)})
+	odrCacheSizeGauge.Update(int64(c.cache.Len()))
+}
+
+// copyProof flattens a retrieved proof into an independent node list. Proof
+// sets are mutable databases, so the cache keeps its own copy and hands out a
+// freshly built set on every lookup.
+func copyProof(proof *light.NodeSet) light.NodeList {
+	if proof == nil {
+		return nil
+	}
+	list := proof.NodeList()
+	for i, node := range list {
+		list[i] = common.CopyBytes(node)
+	}
+	return list
+}
+
+// flush drops all cached retrieval results.
+func (c *odrCache) flush() {
+	c.cache.Purge()
+	odrCacheSizeGauge.Update(0)
+}
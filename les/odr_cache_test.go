@@ -0,0 +1,112 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/mclock"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/light"
+)
+
+func TestOdrCacheExpiration(t *testing.T) {
+	var (
+		clock  = &mclock.Simulated{}
+		config = DefaultOdrCacheConfig
+		cache  = newOdrCache(config, clock)
+		hash   = common.Hash{0x01}
+	)
+	cache.add(&light.CodeRequest{Hash: hash, Data: []byte{1, 2, 3}})
+
+	req := &light.CodeRequest{Hash: hash}
+	if !cache.get(req) || !bytes.Equal(req.Data, []byte{1, 2, 3}) {
+		t.Fatalf("cached code mismatch: have %x", req.Data)
+	}
+	// Entries with different keys or of uncacheable requests must not be served
+	if cache.get(&light.CodeRequest{Hash: common.Hash{0x02}}) {
+		t.Fatalf("unknown code served from cache")
+	}
+	untrusted := &light.ReceiptsRequest{Untrusted: true, Hash: hash, Receipts: types.Receipts{}}
+	cache.add(untrusted)
+	if cache.get(&light.ReceiptsRequest{Untrusted: true, Hash: hash}) {
+		t.Fatalf("untrusted receipts served from cache")
+	}
+	// Expire the entry and ensure it's dropped
+	clock.Run(config.CodeTTL)
+	if cache.get(&light.CodeRequest{Hash: hash}) {
+		t.Fatalf("expired code served from cache")
+	}
+	if cache.cache.Len() != 0 {
+		t.Fatalf("expired entry not removed")
+	}
+}
+
+func TestOdrCacheFlush(t *testing.T) {
+	cache := newOdrCache(DefaultOdrCacheConfig, &mclock.Simulated{})
+
+	receipts := types.Receipts{{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*types.Log{}}}
+	cache.add(&light.ReceiptsRequest{Hash: common.Hash{0x01}, Receipts: receipts})
+
+	// Ensure the returned receipts are independent copies
+	req := &light.ReceiptsRequest{Hash: common.Hash{0x01}}
+	if !cache.get(req) || len(req.Receipts) != 1 {
+		t.Fatalf("cached receipts missing")
+	}
+	req.Receipts[0].GasUsed = 21000
+	if receipts[0].GasUsed != 0 {
+		t.Fatalf("cached receipts shared with caller")
+	}
+	cache.flush()
+	if cache.get(&light.ReceiptsRequest{Hash: common.Hash{0x01}}) {
+		t.Fatalf("flushed receipts served from cache")
+	}
+}
+
+func TestOdrCacheDisabled(t *testing.T) {
+	config := DefaultOdrCacheConfig
+	config.Size = 0
+	if cache := newOdrCache(config, &mclock.Simulated{}); cache != nil {
+		t.Fatalf("cache created with zero size")
+	}
+}
+
+func TestOdrCacheProofCopy(t *testing.T) {
+	cache := newOdrCache(DefaultOdrCacheConfig, &mclock.Simulated{})
+
+	node := []byte{0xc2, 0x01, 0x02}
+	proof := light.NodeList{node}.NodeSet()
+	id := &light.TrieID{Root: common.Hash{0x01}}
+	cache.add(&light.TrieRequest{Id: id, Key: []byte{0x02}, Proof: proof})
+
+	// Modifying the inserted set must not leak into the cache
+	proof.Put([]byte{0xff}, []byte{0xff})
+
+	req := &light.TrieRequest{Id: id, Key: []byte{0x02}}
+	if !cache.get(req) || req.Proof.KeyCount() != 1 {
+		t.Fatalf("cached proof mismatch")
+	}
+	// Modifying a served set must not leak into later lookups
+	req.Proof.Put([]byte{0xfe}, []byte{0xfe})
+
+	again := &light.TrieRequest{Id: id, Key: []byte{0x02}}
+	if !cache.get(again) || again.Proof.KeyCount() != 1 {
+		t.Fatalf("cached proof shared with caller")
+	}
+}
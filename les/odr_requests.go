@@ -43,6 +43,7 @@ var (
 	errCHTHashMismatch     = errors.New("cht hash mismatch")
 	errCHTNumberMismatch   = errors.New("cht number mismatch")
 	errUselessNodes        = errors.New("useless nodes in merkle proof nodeset")
+	errUnmatchedReceipt    = errors.New("receipt without matching logs")
 )
 
 type LesOdrRequest interface {
@@ -66,6 +67,10 @@ func LesRequest(req light.OdrRequest) LesOdrRequest {
 		return (*ChtRequest)(r)
 	case *light.BloomRequest:
 		return (*BloomRequest)(r)
+	case *light.BloomBatchRequest:
+		return (*BloomBatchRequest)(r)
+	case *light.LogsRequest:
+		return (*LogsRequest)(r)
 	case *light.TxStatusRequest:
 		return (*TxStatusRequest)(r)
 	default:
@@ -476,6 +481,230 @@ func (r *BloomRequest) Validate(db gdtudb.Database, msg *Msg) error {
 	return nil
 }
 
+// BloomBatchRequest is the ODR request type for retrieving several bloom bits
+// in the same sections. It is sent as a single bloombits request to les/5
+// servers and as a batch of helper trie proof requests to older ones.
+type BloomBatchRequest light.BloomBatchRequest
+
+// amount returns the number of bloombits vectors retrieved by the request.
+func (r *BloomBatchRequest) amount() int {
+	return len(r.BitIdxList) * len(r.SectionIndexList)
+}
+
+// GetCost returns the cost of the given ODR request according to the serving
+// peer's cost table (implementation of LesOdrRequest)
+func (r *BloomBatchRequest) GetCost(peer *serverPeer) uint64 {
+	if peer.version >= lpv5 {
+		return peer.getRequestCost(GetBloomBitsMsg, r.amount())
+	}
+	return peer.getRequestCost(GetHelperTrieProofsMsg, r.amount())
+}
+
+// CanSend tells if a certain peer is suitable for serving the given request
+func (r *BloomBatchRequest) CanSend(peer *serverPeer) bool {
+	peer.lock.RLock()
+	defer peer.lock.RUnlock()
+
+	if peer.version < lpv2 || r.amount() > MaxBloomBitsFetch {
+		return false
+	}
+	return peer.headInfo.Number >= r.Config.BloomTrieConfirms && r.BloomTrieNum <= (peer.headInfo.Number-r.Config.BloomTrieConfirms)/r.Config.BloomTrieSize
+}
+
+// Request sends an ODR request to the LES network (implementation of LesOdrRequest)
+func (r *BloomBatchRequest) Request(reqID uint64, peer *serverPeer) error {
+	peer.Log().Debug("Requesting BloomBits batch", "bloomTrie", r.BloomTrieNum, "bits", r.BitIdxList, "sections", r.SectionIndexList)
+	if peer.version >= lpv5 {
+		return peer.requestBloomBits(reqID, BloomBitsQuery{
+			BloomTrieNum: r.BloomTrieNum,
+			Bits:         r.BitIdxList,
+			Sections:     r.SectionIndexList,
+		})
+	}
+	reqs := make([]HelperTrieReq, 0, r.amount())
+	for _, bitIdx := range r.BitIdxList {
+		for _, sectionIdx := range r.SectionIndexList {
+			reqs = append(reqs, HelperTrieReq{
+				Type:    htBloomBits,
+				TrieIdx: r.BloomTrieNum,
+				Key:     bloomBitsKey(bitIdx, sectionIdx),
+			})
+		}
+	}
+	return peer.requestHelperTrieProofs(reqID, reqs)
+}
+
+// Validate processes an ODR request reply message from the LES network
+// returns true and stores results in memory if the message was a valid reply
+// to the request (implementation of LesOdrRequest)
+func (r *BloomBatchRequest) Validate(db gdtudb.Database, msg *Msg) error {
+	log.Debug("Validating BloomBits batch", "bloomTrie", r.BloomTrieNum, "bits", r.BitIdxList, "sections", r.SectionIndexList)
+
+	var proofs light.NodeList
+	switch msg.MsgType {
+	case MsgBloomBits:
+		proofs = msg.Obj.(light.NodeList)
+	case MsgHelperTrieProofs:
+		proofs = msg.Obj.(HelperTrieResps).Proofs
+	default:
+		return errInvalidMessageType
+	}
+	nodeSet := proofs.NodeSet()
+	reads := &readTraceDB{db: nodeSet}
+
+	// Verify the proofs of every requested vector
+	r.BloomBits = make([][][]byte, len(r.BitIdxList))
+	for i, bitIdx := range r.BitIdxList {
+		r.BloomBits[i] = make([][]byte, len(r.SectionIndexList))
+		for j, sectionIdx := range r.SectionIndexList {
+			value, err := trie.VerifyProof(r.BloomTrieRoot, bloomBitsKey(bitIdx, sectionIdx), reads)
+			if err != nil {
+				return err
+			}
+			r.BloomBits[i][j] = value
+		}
+	}
+	if len(reads.reads) != nodeSet.KeyCount() {
+		return errUselessNodes
+	}
+	r.Proofs = nodeSet
+	return nil
+}
+
+// bloomBitsKey returns the bloom trie key of the bloombits vector of a bit in
+// a section.
+func bloomBitsKey(bitIdx uint, sectionIdx uint64) []byte {
+	var encNumber [10]byte
+	binary.BigEndian.PutUint16(encNumber[:2], uint16(bitIdx))
+	binary.BigEndian.PutUint64(encNumber[2:], sectionIdx)
+	return encNumber[:]
+}
+
+// LogsRequest is the ODR request type for filtering the logs of a range of
+// blocks on les/5 servers.
+type LogsRequest light.LogsRequest
+
+// query returns the log filter query sent to the server.
+func (r *LogsRequest) query() LogsQuery {
+	return LogsQuery{
+		FromBlock: r.FromBlock,
+		ToBlock:   r.ToBlock,
+		Addresses: r.Addresses,
+		Topics:    r.Topics,
+	}
+}
+
+// GetCost returns the cost of the given ODR request according to the serving
+// peer's cost table (implementation of LesOdrRequest)
+func (r *LogsRequest) GetCost(peer *serverPeer) uint64 {
+	query := r.query()
+	return peer.getRequestCost(GetLogsMsg, int(query.amount()))
+}
+
+// CanSend tells if a certain peer is suitable for serving the given request
+func (r *LogsRequest) CanSend(peer *serverPeer) bool {
+	query := r.query()
+	if peer.version < lpv5 || query.amount() == 0 || query.amount() > MaxLogsRange {
+		return false
+	}
+	return peer.HasBlock(common.Hash{}, r.FromBlock, false) && peer.HasBlock(common.Hash{}, r.ToBlock, false)
+}
+
+// Request sends an ODR request to the LES network (implementation of LesOdrRequest)
+func (r *LogsRequest) Request(reqID uint64, peer *serverPeer) error {
+	peer.Log().Debug("Requesting filtered logs", "from", r.FromBlock, "to", r.ToBlock)
+	return peer.requestLogs(reqID, r.query())
+}
+
+// Validate processes an ODR request reply message from the LES network
+// returns true and stores results in memory if the message was a valid reply
+// to the request (implementation of LesOdrRequest)
+func (r *LogsRequest) Validate(db gdtudb.Database, msg *Msg) error {
+	log.Debug("Validating filtered logs", "from", r.FromBlock, "to", r.ToBlock)
+
+	if msg.MsgType != MsgLogs {
+		return errInvalidMessageType
+	}
+	resp := msg.Obj.(LogsResponse)
+	query := r.query()
+	if resp.Count == 0 || resp.Count > query.amount() {
+		return errInvalidEntryCount
+	}
+	var (
+		nodeSet = resp.Proofs.NodeSet()
+		reads   = &readTraceDB{db: nodeSet}
+		next    = r.FromBlock
+		logs    []*types.Log
+	)
+	for _, block := range resp.Blocks {
+		if block.Number < next || block.Number >= r.FromBlock+resp.Count || len(block.Receipts) == 0 {
+			return errInvalidEntryCount
+		}
+		next = block.Number + 1
+
+		// Retrieve our stored header and validate the receipts against it
+		if rawdb.ReadCanonicalHash(db, block.Number) != block.Hash {
+			return errHeaderUnavailable
+		}
+		header := rawdb.ReadHeader(db, block.Hash, block.Number)
+		if header == nil {
+			return errHeaderUnavailable
+		}
+		var nextIndex, nextLog uint64
+		for _, ref := range block.Receipts {
+			if ref.Index < nextIndex || ref.LogIndex < nextLog {
+				return errInvalidEntryCount
+			}
+			key := rlp.AppendUint64(nil, ref.Index)
+			txEnc, err := trie.VerifyProof(header.TxHash, key, reads)
+			if err != nil {
+				return fmt.Errorf("merkle proof verification failed: %v", err)
+			}
+			receiptEnc, err := trie.VerifyProof(header.ReceiptHash, key, reads)
+			if err != nil {
+				return fmt.Errorf("merkle proof verification failed: %v", err)
+			}
+			if len(txEnc) == 0 || len(receiptEnc) == 0 {
+				return errInvalidEntryCount
+			}
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(txEnc); err != nil {
+				return err
+			}
+			// Typed receipts are stored in the trie without the RLP string
+			// header expected by the decoder
+			if receiptEnc[0] < 0xc0 {
+				receiptEnc, _ = rlp.EncodeToBytes(receiptEnc)
+			}
+			receipt := new(types.Receipt)
+			if err := rlp.DecodeBytes(receiptEnc, receipt); err != nil {
+				return err
+			}
+			var matched bool
+			for i, entry := range receipt.Logs {
+				entry.BlockNumber = block.Number
+				entry.BlockHash = block.Hash
+				entry.TxHash = tx.Hash()
+				entry.TxIndex = uint(ref.Index)
+				entry.Index = uint(ref.LogIndex) + uint(i)
+				if query.matches(entry) {
+					logs = append(logs, entry)
+					matched = true
+				}
+			}
+			if !matched {
+				return errUnmatchedReceipt
+			}
+			nextIndex, nextLog = ref.Index+1, ref.LogIndex+uint64(len(receipt.Logs))
+		}
+	}
+	if len(reads.reads) != nodeSet.KeyCount() {
+		return errUselessNodes
+	}
+	r.Logs, r.Count = logs, resp.Count
+	return nil
+}
+
 // TxStatusRequest is the ODR request type for transaction status
 type TxStatusRequest light.TxStatusRequest
 
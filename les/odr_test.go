@@ -37,6 +37,7 @@ import (
 	"github.com/c88032111/go-gdtu/light"
 	"github.com/c88032111/go-gdtu/params"
 	"github.com/c88032111/go-gdtu/rlp"
+	"github.com/c88032111/go-gdtu/trie"
 )
 
 type odrTestFn func(ctx context.Context, db gdtudb.Database, config *params.ChainConfig, bc *core.BlockChain, lc *light.LightChain, bhash common.Hash) []byte
@@ -256,6 +257,79 @@ func testOdr(t *testing.T, protocol int, expFail uint64, checkCached bool, fn od
 	}
 }
 
+func TestOdrBloomBitsBatchLes4(t *testing.T) { testOdrBloomBitsBatch(t, lpv4) }
+func TestOdrBloomBitsBatchLes5(t *testing.T) { testOdrBloomBitsBatch(t, lpv5) }
+
+// testOdrBloomBitsBatch tests that the bloombits of several bits are retrieved
+// and verified in a single request, using helper trie proofs before les/5.
+func testOdrBloomBitsBatch(t *testing.T, protocol int) {
+	var (
+		config       = light.TestClientIndexerConfig
+		waitIndexers = func(cIndexer, bIndexer, btIndexer *core.ChainIndexer) {
+			for {
+				bts, _, _ := btIndexer.Sections()
+				if bts >= 1 {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+		netconfig = testnetConfig{
+			blocks:    int(config.BloomTrieSize + config.BloomTrieConfirms),
+			protocol:  protocol,
+			indexFn:   waitIndexers,
+			connect:   true,
+			nopruning: true,
+		}
+	)
+	server, client, tearDown := newClientServerEnv(t, netconfig)
+	defer tearDown()
+
+	// Wait for the client to sync the bloom trie
+	for {
+		if bts, _, _ := client.bloomTrieIndexer.Sections(); bts >= 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Request a few bits set in the first section along with an unset one
+	root := light.GetBloomTrieRoot(server.db, 0, server.handler.blockchain.GetHeaderByNumber(config.BloomTrieSize-1).Hash())
+	trie, _ := trie.New(root, trie.NewDatabase(rawdb.NewTable(server.db, light.BloomTrieTablePrefix)))
+
+	var bits []uint
+	for bit := uint(0); bit < types.BloomBitLength && len(bits) < 4; bit++ {
+		if blob, _ := trie.TryGet(bloomBitsKey(bit, 0)); len(blob) > 0 {
+			bits = append(bits, bit)
+		}
+	}
+	if len(bits) == 0 {
+		t.Fatalf("No bloombits set in the test chain")
+	}
+	for bit := uint(0); bit < types.BloomBitLength; bit++ {
+		if blob, _ := trie.TryGet(bloomBitsKey(bit, 0)); len(blob) == 0 {
+			bits = append(bits, bit)
+			break
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	vectors, err := light.GetBloomBitsBatch(ctx, client.handler.backend.odr, bits, []uint64{0})
+	if err != nil {
+		t.Fatalf("Failed to retrieve bloombits: %v", err)
+	}
+	for i, bit := range bits {
+		want, _ := trie.TryGet(bloomBitsKey(bit, 0))
+		if !bytes.Equal(vectors[i][0], want) {
+			t.Errorf("bit %d: bloombits mismatch: have %x, want %x", bit, vectors[i][0], want)
+		}
+		sectionHead := rawdb.ReadCanonicalHash(client.db, config.BloomSize-1)
+		if stored, _ := rawdb.ReadBloomBits(client.db, bit, 0, sectionHead); !bytes.Equal(stored, want) {
+			t.Errorf("bit %d: stored bloombits mismatch: have %x, want %x", bit, stored, want)
+		}
+	}
+}
+
 func TestGetTxStatusFromUnindexedPeersLES4(t *testing.T) { testGetTxStatusFromUnindexedPeers(t, lpv4) }
 
 func testGetTxStatusFromUnindexedPeers(t *testing.T, protocol int) {
@@ -436,3 +510,57 @@ func randomHash() common.Hash {
 	}
 	return hash
 }
+
+// Tests that the logs of a block range are filtered by les/5 servers and proven
+// against the locally synced headers.
+func TestOdrLogsLes5(t *testing.T) {
+	netconfig := testnetConfig{
+		blocks:    4,
+		protocol:  lpv5,
+		connect:   true,
+		nopruning: true,
+	}
+	server, client, tearDown := newClientServerEnv(t, netconfig)
+	defer tearDown()
+
+	if head := client.handler.backend.blockchain.CurrentHeader().Number.Uint64(); head != 4 {
+		t.Fatalf("Failed to sync the chain with server, head: %v", head)
+	}
+	// Collect the logs emitted by the server chain
+	var (
+		bc   = server.handler.blockchain
+		want []*types.Log
+	)
+	for i := uint64(0); i <= 4; i++ {
+		for _, receipt := range bc.GetReceiptsByHash(bc.GetHeaderByNumber(i).Hash()) {
+			want = append(want, receipt.Logs...)
+		}
+	}
+	if len(want) == 0 {
+		t.Fatalf("No logs in the test chain")
+	}
+	tests := []struct {
+		addresses []common.Address
+		topics    [][]common.Hash
+		want      []*types.Log
+	}{
+		{nil, nil, want},
+		{[]common.Address{want[0].Address}, [][]common.Hash{{want[0].Topics[0]}}, want[:1]},
+		{[]common.Address{{0xde, 0xad}}, nil, nil},
+	}
+	for i, tt := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		req := &light.LogsRequest{FromBlock: 0, ToBlock: 4, Addresses: tt.addresses, Topics: tt.topics}
+		err := client.handler.backend.odr.Retrieve(ctx, req)
+		cancel()
+		if err != nil {
+			t.Fatalf("test %d: failed to filter logs: %v", i, err)
+		}
+		if req.Count != 5 {
+			t.Errorf("test %d: filtered block count mismatch: have %d, want 5", i, req.Count)
+		}
+		if !reflect.DeepEqual(req.Logs, tt.want) {
+			t.Errorf("test %d: logs mismatch: have %v, want %v", i, req.Logs, tt.want)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"math/big"
 	"math/rand"
 	"net"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -65,6 +66,10 @@ const (
 
 	// handshakeTimeout is the timeout LES handshake will be treated as failed.
 	handshakeTimeout = 5 * time.Second
+
+	maxTxStatusSubs       = 1024 // maximum number of transaction status subscriptions per client
+	txStatusConfirmations = 12   // number of blocks after which an included transaction is no longer tracked
+	txStatusDropDelay     = 2    // number of updates a transaction must be unknown to be reported dropped
 )
 
 const (
@@ -479,6 +484,31 @@ func (p *serverPeer) requestTxStatus(reqID uint64, txHashes []common.Hash) error
 	return p.sendRequest(GetTxStatusMsg, reqID, txHashes, len(txHashes))
 }
 
+// subscribeTxStatus subscribes to the status changes of a batch of transactions.
+func (p *serverPeer) subscribeTxStatus(reqID uint64, txHashes []common.Hash) error {
+	p.Log().Debug("Subscribing to transaction status", "count", len(txHashes))
+	return p.sendRequest(SubscribeTxStatusMsg, reqID, txHashes, len(txHashes))
+}
+
+// requestEpochProofs fetches a batch of clique epoch proofs from a remote node.
+func (p *serverPeer) requestEpochProofs(reqID uint64, epochs []uint64) error {
+	p.Log().Debug("Fetching batch of epoch proofs", "count", len(epochs))
+	return p.sendRequest(GetEpochProofsMsg, reqID, epochs, len(epochs))
+}
+
+// requestBloomBits fetches a batch of bloombits proofs from a remote node.
+func (p *serverPeer) requestBloomBits(reqID uint64, query BloomBitsQuery) error {
+	amount := len(query.Bits) * len(query.Sections)
+	p.Log().Debug("Fetching batch of bloombits", "bits", len(query.Bits), "sections", len(query.Sections))
+	return p.sendRequest(GetBloomBitsMsg, reqID, query, amount)
+}
+
+// requestLogs asks a remote node to filter the logs of a range of blocks.
+func (p *serverPeer) requestLogs(reqID uint64, query LogsQuery) error {
+	p.Log().Debug("Filtering logs", "from", query.FromBlock, "to", query.ToBlock)
+	return p.sendRequest(GetLogsMsg, reqID, query, int(query.amount()))
+}
+
 // sendTxs creates a reply with a batch of transactions to be added to the remote transaction pool.
 func (p *serverPeer) sendTxs(reqID uint64, amount int, txs rlp.RawValue) error {
 	p.Log().Debug("Sending batch of transactions", "amount", amount, "size", len(txs))
@@ -664,6 +694,9 @@ func (p *serverPeer) Handshake(genesis common.Hash, forkid forkid.ID, forkFilter
 
 		if !p.onlyAnnounce {
 			for msgCode := range reqAvgTimeCost {
+				if msgCode >= ProtocolLengths[uint(p.version)] {
+					continue // Message not part of the negotiated protocol version
+				}
 				if p.fcCosts[msgCode] == nil {
 					return errResp(ErrUselessPeer, "peer does not support message %d", msgCode)
 				}
@@ -771,6 +804,16 @@ type clientPeer struct {
 	server   bool
 	errCh    chan error
 	fcClient *flowcontrol.ClientNode // Server side mirror token bucket.
+
+	// txSubLock protects the transaction status subscriptions of the client
+	txSubLock sync.Mutex
+	txSubs    map[common.Hash]*txStatusSub
+}
+
+// txStatusSub is the server side state of a transaction status subscription.
+type txStatusSub struct {
+	status  light.TxStatus // Last status reported to the client
+	missing int            // Number of consecutive updates the transaction was unknown
 }
 
 func newClientPeer(version int, network uint64, p *p2p.Peer, rw p2p.MsgReadWriter) *clientPeer {
@@ -786,6 +829,7 @@ func newClientPeer(version int, network uint64, p *p2p.Peer, rw p2p.MsgReadWrite
 		},
 		invalidCount: utils.LinearExpiredValue{Rate: mclock.AbsTime(time.Hour)},
 		errCh:        make(chan error, 1),
+		txSubs:       make(map[common.Hash]*txStatusSub),
 	}
 }
 
@@ -915,6 +959,77 @@ func (p *clientPeer) replyTxStatus(reqID uint64, stats []light.TxStatus) *reply
 	return &reply{p.rw, TxStatusMsg, reqID, data}
 }
 
+// replyEpochProofs creates a reply with a batch of clique epoch proofs, corresponding to the ones requested.
+func (p *clientPeer) replyEpochProofs(reqID uint64, proofs []EpochProof) *reply {
+	data, _ := rlp.EncodeToBytes(proofs)
+	return &reply{p.rw, EpochProofsMsg, reqID, data}
+}
+
+// replyBloomBits creates a reply with the bloom trie proofs of the requested bloombits.
+func (p *clientPeer) replyBloomBits(reqID uint64, proofs light.NodeList) *reply {
+	data, _ := rlp.EncodeToBytes(proofs)
+	return &reply{p.rw, BloomBitsMsg, reqID, data}
+}
+
+// replyLogs creates a reply with the proven receipts matching a log filter query.
+func (p *clientPeer) replyLogs(reqID uint64, logs LogsResponse) *reply {
+	data, _ := rlp.EncodeToBytes(logs)
+	return &reply{p.rw, LogsMsg, reqID, data}
+}
+
+// subscribeTxStatus subscribes the client to the status changes of the given
+// transactions, starting from their current status. Transactions unknown to the
+// server are not tracked.
+func (p *clientPeer) subscribeTxStatus(hashes []common.Hash, stats []light.TxStatus) {
+	p.txSubLock.Lock()
+	defer p.txSubLock.Unlock()
+
+	for i, hash := range hashes {
+		if stats[i].Status == core.TxStatusUnknown {
+			continue
+		}
+		if _, ok := p.txSubs[hash]; !ok && len(p.txSubs) >= maxTxStatusSubs {
+			continue
+		}
+		p.txSubs[hash] = &txStatusSub{status: stats[i]}
+	}
+}
+
+// updateTxStatus re-evaluates the status of the subscribed transactions and
+// pushes the changed ones to the client. Subscriptions end once a transaction
+// is included deep enough in the chain or once it's dropped by the server.
+func (p *clientPeer) updateTxStatus(head uint64, status func(common.Hash) light.TxStatus) {
+	p.txSubLock.Lock()
+	defer p.txSubLock.Unlock()
+
+	var update TxStatusUpdatePacket
+	for hash, sub := range p.txSubs {
+		stat := status(hash)
+		if stat.Status == core.TxStatusUnknown {
+			// The transaction might just be in transit between the chain and the
+			// pool during a reorg, only consider it dropped if it stays unknown
+			if sub.missing++; sub.missing < txStatusDropDelay {
+				continue
+			}
+			delete(p.txSubs, hash)
+		} else {
+			sub.missing = 0
+			if stat.Status == core.TxStatusIncluded && stat.Lookup.BlockIndex+txStatusConfirmations <= head {
+				delete(p.txSubs, hash)
+			}
+		}
+		if reflect.DeepEqual(stat, sub.status) {
+			continue
+		}
+		sub.status = stat
+		update.Hashes = append(update.Hashes, hash)
+		update.Status = append(update.Status, stat)
+	}
+	if len(update.Hashes) > 0 {
+		p.queueSend(func() { p2p.Send(p.rw, TxStatusUpdateMsg, update) })
+	}
+}
+
 // sendAnnounce announces the availability of a number of blocks through
 // a hash notification.
 func (p *clientPeer) sendAnnounce(request announceData) error {
@@ -27,6 +27,7 @@ import (
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/crypto"
 	vfc "github.com/c88032111/go-gdtu/les/vflux/client"
+	"github.com/c88032111/go-gdtu/light"
 	"github.com/c88032111/go-gdtu/p2p/enode"
 	"github.com/c88032111/go-gdtu/rlp"
 )
@@ -36,17 +37,18 @@ const (
 	lpv2 = 2
 	lpv3 = 3
 	lpv4 = 4
+	lpv5 = 5
 )
 
 // Supported versions of the les protocol (first is primary)
 var (
-	ClientProtocolVersions    = []uint{lpv2, lpv3, lpv4}
-	ServerProtocolVersions    = []uint{lpv2, lpv3, lpv4}
+	ClientProtocolVersions    = []uint{lpv2, lpv3, lpv4, lpv5}
+	ServerProtocolVersions    = []uint{lpv2, lpv3, lpv4, lpv5}
 	AdvertiseProtocolVersions = []uint{lpv2} // clients are searching for the first advertised protocol in the list
 )
 
 // Number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = map[uint]uint64{lpv2: 22, lpv3: 24, lpv4: 24}
+var ProtocolLengths = map[uint]uint64{lpv2: 22, lpv3: 24, lpv4: 24, lpv5: 32}
 
 const (
 	NetworkId          = 1
@@ -82,6 +84,15 @@ const (
 	// Protocol messages introduced in LPV3
 	StopMsg   = 0x16
 	ResumeMsg = 0x17
+	// Protocol messages introduced in LPV5
+	GetEpochProofsMsg    = 0x18
+	EpochProofsMsg       = 0x19
+	SubscribeTxStatusMsg = 0x1a
+	TxStatusUpdateMsg    = 0x1b
+	GetBloomBitsMsg      = 0x1c
+	BloomBitsMsg         = 0x1d
+	GetLogsMsg           = 0x1e
+	LogsMsg              = 0x1f
 )
 
 // GetBlockHeadersData represents a block header query (the request ID is not included)
@@ -140,6 +151,138 @@ type GetTxStatusPacket struct {
 	Hashes []common.Hash
 }
 
+// SubscribeTxStatusPacket represents a transaction status subscription request.
+// The current status of the transactions is returned in a TxStatusMsg reply,
+// later changes are pushed in TxStatusUpdateMsg messages.
+type SubscribeTxStatusPacket struct {
+	ReqID  uint64
+	Hashes []common.Hash
+}
+
+// TxStatusUpdatePacket represents a status change notification of transactions
+// subscribed to by the client.
+type TxStatusUpdatePacket struct {
+	Hashes []common.Hash
+	Status []light.TxStatus
+}
+
+// GetEpochProofsPacket represents a clique epoch proof request
+type GetEpochProofsPacket struct {
+	ReqID  uint64
+	Epochs []uint64
+}
+
+// EpochProof is the proof of a clique epoch checkpoint: the checkpoint header
+// followed by the headers built on top of it and the total difficulty of the
+// checkpoint. Epochs which can't be proven are answered with an empty proof.
+type EpochProof struct {
+	Headers []*types.Header
+	Td      *big.Int
+}
+
+// GetBloomBitsPacket represents a bloombits request
+type GetBloomBitsPacket struct {
+	ReqID uint64
+	Query BloomBitsQuery
+}
+
+// BloomBitsQuery requests the bloom trie proofs of the compressed bloombits
+// vectors of several bit indexes in several sections, all of them proven by the
+// same bloom trie. Every bit is requested in every listed section.
+type BloomBitsQuery struct {
+	BloomTrieNum uint64
+	Bits         []uint
+	Sections     []uint64
+}
+
+// GetLogsPacket represents a log filter request
+type GetLogsPacket struct {
+	ReqID uint64
+	Query LogsQuery
+}
+
+// LogsQuery requests the server to filter the logs of a range of canonical blocks.
+// The address and topic criteria follow the semantics of the filter RPC API.
+type LogsQuery struct {
+	FromBlock, ToBlock uint64
+	Addresses          []common.Address
+	Topics             [][]common.Hash
+}
+
+// amount returns the number of blocks filtered by the query.
+func (q *LogsQuery) amount() uint64 {
+	if q.ToBlock < q.FromBlock {
+		return 0
+	}
+	return q.ToBlock - q.FromBlock + 1
+}
+
+// criteria returns the number of addresses and topics in the query.
+func (q *LogsQuery) criteria() int {
+	count := len(q.Addresses)
+	for _, topics := range q.Topics {
+		count += len(topics)
+	}
+	return count
+}
+
+// matches tells whether a log satisfies the address and topic criteria.
+func (q *LogsQuery) matches(log *types.Log) bool {
+	if len(q.Addresses) > 0 {
+		var found bool
+		for _, addr := range q.Addresses {
+			if addr == log.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(q.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, sub := range q.Topics {
+		match := len(sub) == 0 // empty rule set == wildcard
+		for _, topic := range sub {
+			if log.Topics[i] == topic {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+// LogsResponse is the reply to a log filter request. The server filters the
+// blocks of the query in order and may stop early, Count tells how many blocks
+// were filtered. Every receipt containing matching logs is listed along with the
+// receipt and transaction trie proofs needed to verify it against its header.
+type LogsResponse struct {
+	Count  uint64
+	Blocks []LogsBlock
+	Proofs light.NodeList
+}
+
+// LogsBlock lists the receipts of a block that contain matching logs.
+type LogsBlock struct {
+	Hash     common.Hash
+	Number   uint64
+	Receipts []LogsReceipt
+}
+
+// LogsReceipt references a receipt containing matching logs. The index of its
+// first log in the block can't be proven without the preceding receipts, so it
+// is reported by the server as is.
+type LogsReceipt struct {
+	Index    uint64
+	LogIndex uint64
+}
+
 type requestInfo struct {
 	name                          string
 	maxCount                      uint64
@@ -166,6 +309,10 @@ var (
 		GetHelperTrieProofsMsg: {"GetHelperTrieProofs", MaxHelperTrieProofsFetch, 10, 100},
 		SendTxV2Msg:            {"SendTxV2", MaxTxSend, 1, 0},
 		GetTxStatusMsg:         {"GetTxStatus", MaxTxStatus, 10, 0},
+		GetEpochProofsMsg:      {"GetEpochProofs", MaxEpochProofsFetch, 1, 0},
+		SubscribeTxStatusMsg:   {"SubscribeTxStatus", MaxTxStatus, 10, 0},
+		GetBloomBitsMsg:        {"GetBloomBits", MaxBloomBitsFetch, 10, 100},
+		GetLogsMsg:             {"GetLogs", MaxLogsRange, 1, 100},
 	}
 	requestList    []vfc.RequestInfo
 	requestMapping map[uint32]reqMapping
@@ -54,6 +54,8 @@ func init() {
 }
 
 type gdtuBackend interface {
+	node.Lifecycle
+
 	ArchiveMode() bool
 	BlockChain() *core.BlockChain
 	BloomIndexer() *core.ChainIndexer
@@ -78,6 +80,7 @@ type LesServer struct {
 	defParams    flowcontrol.ServerParams
 	servingQueue *servingQueue
 	clientPool   *clientPool
+	scaler       *capacityScaler // Scaler of the serving capacity, nil if disabled
 
 	minCapacity, maxCapacity uint64
 	threadsIdle              int // Request serving threads count when system is idle.
@@ -130,6 +133,10 @@ func NewLesServer(node *node.Node, e gdtuBackend, config *gdtuconfig.Config) (*L
 	srv.costTracker, srv.minCapacity = newCostTracker(e.ChainDb(), config)
 	srv.oracle = srv.setupOracle(node, e.BlockChain().Genesis().Hash(), config)
 
+	chain := e.BlockChain()
+	monitor := newResourceMonitor(e.ChainDb(), func() uint64 { return chain.CurrentHeader().Number.Uint64() })
+	srv.scaler = newCapacityScaler(config.LightServ, config.LightServMax, monitor.measure)
+
 	// Initialize the bloom trie indexer.
 	e.BloomIndexer().AddChildIndexer(srv.bloomTrieIndexer)
 
@@ -144,6 +151,10 @@ func NewLesServer(node *node.Node, e gdtuBackend, config *gdtuconfig.Config) (*L
 	// to send requests most of the time. Our goal is to serve as many clients as
 	// possible while the actually used server capacity does not exceed the limits
 	totalRecharge := srv.costTracker.totalRecharge()
+	if srv.scaler != nil {
+		// Leave room for the total recharge to grow up to LightServMax
+		totalRecharge = totalRecharge * uint64(config.LightServMax) / uint64(config.LightServ)
+	}
 	srv.maxCapacity = srv.minCapacity * uint64(srv.config.LightPeers)
 	if totalRecharge > srv.maxCapacity {
 		srv.maxCapacity = totalRecharge
@@ -161,7 +172,10 @@ func NewLesServer(node *node.Node, e gdtuBackend, config *gdtuconfig.Config) (*L
 
 	node.RegisterProtocols(srv.Protocols())
 	node.RegisterAPIs(srv.APIs())
-	node.RegisterLifecycle(srv)
+	node.RegisterLifecycleDeps(srv, e) // Only depends on the full node backend
+	if config.LightWebsocket {
+		node.RegisterHandler("LES WebSocket", websocketPath, srv.p2pSrv.WebsocketHandler())
+	}
 
 	// disconnect all peers at nsm shutdown
 	ns.SubscribeField(clientPeerField, func(node *enode.Node, state nodestate.Flags, oldValue, newValue interface{}) {
@@ -219,6 +233,9 @@ func (s *LesServer) Start() error {
 	s.handler.start()
 	s.wg.Add(1)
 	go s.capacityManagement()
+	if s.scaler != nil {
+		s.scaler.start()
+	}
 	if s.p2pSrv.DiscV5 != nil {
 		s.p2pSrv.DiscV5.RegisterTalkHandler("vfx", s.vfluxServer.ServeEncoded)
 	}
@@ -229,6 +246,9 @@ func (s *LesServer) Start() error {
 func (s *LesServer) Stop() error {
 	close(s.closeCh)
 
+	if s.scaler != nil {
+		s.scaler.stop()
+	}
 	s.clientPool.stop()
 	s.ns.Stop()
 	s.fcManager.Stop()
@@ -263,18 +283,27 @@ func (s *LesServer) capacityManagement() {
 	totalCapacity := s.fcManager.SubscribeTotalCapacity(totalCapacityCh)
 	s.clientPool.setLimits(s.config.LightPeers, totalCapacity)
 
+	// The total recharge is scaled with the resource headroom if enabled
+	var (
+		scale   = 1.0
+		scaleCh chan float64
+	)
+	if s.scaler != nil {
+		scale, scaleCh = s.scaler.scale(), s.scaler.scaleCh
+	}
 	var (
 		busy         bool
 		freePeers    uint64
 		blockProcess mclock.AbsTime
 	)
 	updateRecharge := func() {
+		recharge := uint64(float64(totalRecharge) * scale)
 		if busy {
 			s.servingQueue.setThreads(s.threadsBusy)
-			s.fcManager.SetRechargeCurve(flowcontrol.PieceWiseLinear{{0, 0}, {totalRecharge, totalRecharge}})
+			s.fcManager.SetRechargeCurve(flowcontrol.PieceWiseLinear{{0, 0}, {recharge, recharge}})
 		} else {
 			s.servingQueue.setThreads(s.threadsIdle)
-			s.fcManager.SetRechargeCurve(flowcontrol.PieceWiseLinear{{0, 0}, {totalRecharge / 10, totalRecharge}, {totalRecharge, totalRecharge}})
+			s.fcManager.SetRechargeCurve(flowcontrol.PieceWiseLinear{{0, 0}, {recharge / 10, recharge}, {recharge, recharge}})
 		}
 	}
 	updateRecharge()
@@ -291,6 +320,8 @@ func (s *LesServer) capacityManagement() {
 		case totalRecharge = <-totalRechargeCh:
 			totalRechargeGauge.Update(int64(totalRecharge))
 			updateRecharge()
+		case scale = <-scaleCh:
+			updateRecharge()
 		case totalCapacity = <-totalCapacityCh:
 			totalCapacityGauge.Update(int64(totalCapacity))
 			newFreePeers := totalCapacity / s.minCapacity
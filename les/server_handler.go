@@ -55,6 +55,11 @@ const (
 	MaxHelperTrieProofsFetch = 64  // Amount of helper tries to be fetched per retrieval request
 	MaxTxSend                = 64  // Amount of transactions to be send per request
 	MaxTxStatus              = 256 // Amount of transactions to queried per request
+	MaxEpochProofsFetch      = 16  // Amount of clique epoch proofs to be fetched per retrieval request
+	maxEpochProofHeaders     = 128 // Maximum number of headers in a single epoch proof
+	MaxBloomBitsFetch        = 64  // Amount of bloombits vectors to be fetched per retrieval request
+	MaxLogsRange             = 128 // Amount of blocks to be filtered per log filter request
+	MaxLogsCriteria          = 32  // Amount of addresses and topics allowed in a log filter request
 )
 
 var (
@@ -94,8 +99,9 @@ func newServerHandler(server *LesServer, blockchain *core.BlockChain, chainDb gd
 
 // start starts the server handler.
 func (h *serverHandler) start() {
-	h.wg.Add(1)
+	h.wg.Add(2)
 	go h.broadcastLoop()
+	go h.txStatusLoop()
 }
 
 // stop stops the server handler.
@@ -455,6 +461,43 @@ func (h *serverHandler) broadcastLoop() {
 	}
 }
 
+// txStatusLoop pushes the status changes of the transactions subscribed to by
+// the connected clients on every new head.
+func (h *serverHandler) txStatusLoop() {
+	defer h.wg.Done()
+
+	headCh := make(chan core.ChainHeadEvent, 10)
+	headSub := h.blockchain.SubscribeChainHeadEvent(headCh)
+	defer headSub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-headCh:
+			head := ev.Block.NumberU64()
+			h.server.ns.ForEach(nodestate.Flags{}, nodestate.Flags{}, func(node *enode.Node, state nodestate.Flags) {
+				if p, _ := h.server.ns.GetField(node, clientPeerField).(*clientPeer); p != nil && p.version >= lpv5 {
+					p.updateTxStatus(head, h.subscribedTxStatus)
+				}
+			})
+		case <-h.closeCh:
+			return
+		}
+	}
+}
+
+// subscribedTxStatus returns the status of a subscribed transaction. Contrary to
+// txStatus, the chain is checked first, as the pool might not have processed the
+// new head yet.
+func (h *serverHandler) subscribedTxStatus(hash common.Hash) light.TxStatus {
+	if tx, blockHash, number, index := rawdb.ReadTransaction(h.chainDb, hash); tx != nil {
+		return light.TxStatus{
+			Status: core.TxStatusIncluded,
+			Lookup: &rawdb.LegacyTxLookupEntry{BlockHash: blockHash, BlockIndex: number, Index: index},
+		}
+	}
+	return light.TxStatus{Status: h.txpool.Status([]common.Hash{hash})[0]}
+}
+
 // broadcaster sends new header announcements to active client peers
 type broadcaster struct {
 	ns                           *nodestate.NodeStateMachine
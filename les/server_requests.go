@@ -19,9 +19,12 @@ package les
 import (
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 
 	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/consensus/clique"
 	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/core/rawdb"
 	"github.com/c88032111/go-gdtu/core/state"
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/light"
@@ -31,6 +34,8 @@ import (
 	"github.com/c88032111/go-gdtu/trie"
 )
 
+var errInvalidLogsQuery = errors.New("invalid log filter query")
+
 // serverBackend defines the backend functions needed for serving LES requests
 type serverBackend interface {
 	ArchiveMode() bool
@@ -145,6 +150,46 @@ var Les3 = map[uint64]RequestType{
 		ServingTimeMeter: miscServingTimeTxStatusTimer,
 		Handle:           handleGetTxStatus,
 	},
+	GetEpochProofsMsg: {
+		Name:             "clique epoch proof request",
+		MaxCount:         MaxEpochProofsFetch,
+		InPacketsMeter:   miscInEpochProofPacketsMeter,
+		InTrafficMeter:   miscInEpochProofTrafficMeter,
+		OutPacketsMeter:  miscOutEpochProofPacketsMeter,
+		OutTrafficMeter:  miscOutEpochProofTrafficMeter,
+		ServingTimeMeter: miscServingTimeEpochProofTimer,
+		Handle:           handleGetEpochProofs,
+	},
+	SubscribeTxStatusMsg: {
+		Name:             "transaction status subscription request",
+		MaxCount:         MaxTxStatus,
+		InPacketsMeter:   miscInTxStatusPacketsMeter,
+		InTrafficMeter:   miscInTxStatusTrafficMeter,
+		OutPacketsMeter:  miscOutTxStatusPacketsMeter,
+		OutTrafficMeter:  miscOutTxStatusTrafficMeter,
+		ServingTimeMeter: miscServingTimeTxStatusTimer,
+		Handle:           handleSubscribeTxStatus,
+	},
+	GetBloomBitsMsg: {
+		Name:             "bloombits request",
+		MaxCount:         MaxBloomBitsFetch,
+		InPacketsMeter:   miscInBloomBitsPacketsMeter,
+		InTrafficMeter:   miscInBloomBitsTrafficMeter,
+		OutPacketsMeter:  miscOutBloomBitsPacketsMeter,
+		OutTrafficMeter:  miscOutBloomBitsTrafficMeter,
+		ServingTimeMeter: miscServingTimeBloomBitsTimer,
+		Handle:           handleGetBloomBits,
+	},
+	GetLogsMsg: {
+		Name:             "log filter request",
+		MaxCount:         MaxLogsRange,
+		InPacketsMeter:   miscInLogsPacketsMeter,
+		InTrafficMeter:   miscInLogsTrafficMeter,
+		OutPacketsMeter:  miscOutLogsPacketsMeter,
+		OutTrafficMeter:  miscOutLogsTrafficMeter,
+		ServingTimeMeter: miscServingTimeLogsTimer,
+		Handle:           handleGetLogs,
+	},
 }
 
 // handleGetBlockHeaders handles a block header request
@@ -345,20 +390,17 @@ func handleGetReceipts(msg Decoder) (serveRequestFn, uint64, uint64, error) {
 				break
 			}
 			// Retrieve the requested block's receipts, skipping if unknown to us
-			results := bc.GetReceiptsByHash(hash)
-			if results == nil {
+			encoded := bc.GetReceiptsRLP(hash)
+			if encoded == nil {
 				if header := bc.GetHeaderByHash(hash); header == nil || header.ReceiptHash != types.EmptyRootHash {
 					p.bumpInvalid()
 					continue
 				}
+				encoded = rlp.EmptyList
 			}
-			// If known, encode and queue for response packet
-			if encoded, err := rlp.EncodeToBytes(results); err != nil {
-				log.Error("Failed to encode receipt", "err", err)
-			} else {
-				receipts = append(receipts, encoded)
-				bytes += len(encoded)
-			}
+			// If known, queue for response packet
+			receipts = append(receipts, encoded)
+			bytes += len(encoded)
 		}
 		return p.replyReceiptsRLP(r.ReqID, receipts)
 	}, r.ReqID, uint64(len(r.Hashes)), nil
@@ -551,6 +593,25 @@ func handleGetTxStatus(msg Decoder) (serveRequestFn, uint64, uint64, error) {
 	}, r.ReqID, uint64(len(r.Hashes)), nil
 }
 
+// handleSubscribeTxStatus handles a transaction status subscription request
+func handleSubscribeTxStatus(msg Decoder) (serveRequestFn, uint64, uint64, error) {
+	var r SubscribeTxStatusPacket
+	if err := msg.Decode(&r); err != nil {
+		return nil, 0, 0, err
+	}
+	return func(backend serverBackend, p *clientPeer, waitOrStop func() bool) *reply {
+		stats := make([]light.TxStatus, len(r.Hashes))
+		for i, hash := range r.Hashes {
+			if i != 0 && !waitOrStop() {
+				return nil
+			}
+			stats[i] = txStatus(backend, hash)
+		}
+		p.subscribeTxStatus(r.Hashes, stats)
+		return p.replyTxStatus(r.ReqID, stats)
+	}, r.ReqID, uint64(len(r.Hashes)), nil
+}
+
 // txStatus returns the status of a specified transaction.
 func txStatus(b serverBackend, hash common.Hash) light.TxStatus {
 	var stat light.TxStatus
@@ -567,3 +628,180 @@ func txStatus(b serverBackend, hash common.Hash) light.TxStatus {
 	}
 	return stat
 }
+
+// handleGetEpochProofs handles a clique epoch proof request
+func handleGetEpochProofs(msg Decoder) (serveRequestFn, uint64, uint64, error) {
+	var r GetEpochProofsPacket
+	if err := msg.Decode(&r); err != nil {
+		return nil, 0, 0, err
+	}
+	return func(backend serverBackend, p *clientPeer, waitOrStop func() bool) *reply {
+		var (
+			bc     = backend.BlockChain()
+			config = bc.Config().Clique
+			head   = bc.CurrentHeader().Number.Uint64()
+			proofs = make([]EpochProof, len(r.Epochs))
+			size   int
+		)
+		for i, epoch := range r.Epochs {
+			if i != 0 && !waitOrStop() {
+				return nil
+			}
+			// Epochs of non-clique chains, the genesis and epochs not yet reached
+			// can't be proven, leave their proofs empty
+			if config == nil || epoch == 0 || epoch > head/config.Epoch {
+				continue
+			}
+			checkpoint := bc.GetHeaderByNumber(epoch * config.Epoch)
+			if checkpoint == nil {
+				continue
+			}
+			signers, err := clique.CheckpointSigners(checkpoint)
+			if err != nil {
+				continue
+			}
+			count := clique.EpochProofLength(len(signers))
+			if count > maxEpochProofHeaders {
+				count = maxEpochProofHeaders
+			}
+			headers := []*types.Header{checkpoint}
+			for len(headers) < count {
+				header := bc.GetHeaderByNumber(checkpoint.Number.Uint64() + uint64(len(headers)))
+				if header == nil {
+					break
+				}
+				headers = append(headers, header)
+			}
+			proofs[i] = EpochProof{
+				Headers: headers,
+				Td:      bc.GetTd(checkpoint.Hash(), checkpoint.Number.Uint64()),
+			}
+			if size += len(headers) * estHeaderRlpSize; size >= softResponseLimit {
+				proofs = proofs[:i+1]
+				break
+			}
+		}
+		return p.replyEpochProofs(r.ReqID, proofs)
+	}, r.ReqID, uint64(len(r.Epochs)), nil
+}
+
+// handleGetBloomBits handles a bloombits request, proving every requested bit
+// in every requested section with the same bloom trie.
+func handleGetBloomBits(msg Decoder) (serveRequestFn, uint64, uint64, error) {
+	var r GetBloomBitsPacket
+	if err := msg.Decode(&r); err != nil {
+		return nil, 0, 0, err
+	}
+	return func(backend serverBackend, p *clientPeer, waitOrStop func() bool) *reply {
+		bloomTrie := backend.GetHelperTrie(htBloomBits, r.Query.BloomTrieNum)
+		if bloomTrie == nil {
+			return nil
+		}
+		var (
+			nodes     = light.NewNodeSet()
+			encNumber [10]byte
+			first     = true
+		)
+		for _, bit := range r.Query.Bits {
+			binary.BigEndian.PutUint16(encNumber[:2], uint16(bit))
+			for _, section := range r.Query.Sections {
+				if !first && !waitOrStop() {
+					return nil
+				}
+				first = false
+
+				binary.BigEndian.PutUint64(encNumber[2:], section)
+				if err := bloomTrie.Prove(encNumber[:], 0, nodes); err != nil {
+					return nil
+				}
+				if nodes.DataSize() >= softResponseLimit {
+					return p.replyBloomBits(r.ReqID, nodes.NodeList())
+				}
+			}
+		}
+		return p.replyBloomBits(r.ReqID, nodes.NodeList())
+	}, r.ReqID, uint64(len(r.Query.Bits)) * uint64(len(r.Query.Sections)), nil
+}
+
+// handleGetLogs handles a log filter request, filtering the requested blocks in
+// order until the range is exhausted or the response grows too large.
+func handleGetLogs(msg Decoder) (serveRequestFn, uint64, uint64, error) {
+	var r GetLogsPacket
+	if err := msg.Decode(&r); err != nil {
+		return nil, 0, 0, err
+	}
+	if r.Query.amount() == 0 || r.Query.criteria() > MaxLogsCriteria {
+		return nil, 0, 0, errInvalidLogsQuery
+	}
+	return func(backend serverBackend, p *clientPeer, waitOrStop func() bool) *reply {
+		var (
+			bc    = backend.BlockChain()
+			probe = types.NewBloomProbe(r.Query.Addresses, r.Query.Topics)
+			nodes = light.NewNodeSet()
+			resp  LogsResponse
+		)
+		for number := r.Query.FromBlock; number <= r.Query.ToBlock; number++ {
+			if number != r.Query.FromBlock && !waitOrStop() {
+				return nil
+			}
+			header := bc.GetHeaderByNumber(number)
+			if header == nil {
+				break
+			}
+			if probe.TestHeader(header) {
+				block, receipts := bc.GetBlock(header.Hash(), number), bc.GetReceiptsByHash(header.Hash())
+				if block == nil || receipts == nil {
+					break
+				}
+				if matched := proveLogs(block.Transactions(), receipts, &r.Query, nodes); len(matched) > 0 {
+					resp.Blocks = append(resp.Blocks, LogsBlock{
+						Hash:     header.Hash(),
+						Number:   number,
+						Receipts: matched,
+					})
+				}
+			}
+			resp.Count++
+			if nodes.DataSize() >= softResponseLimit {
+				break
+			}
+		}
+		if resp.Count == 0 {
+			p.bumpInvalid()
+		}
+		resp.Proofs = nodes.NodeList()
+		return p.replyLogs(r.ReqID, resp)
+	}, r.ReqID, r.Query.amount(), nil
+}
+
+// proveLogs returns the receipts of a block containing logs matching the query,
+// adding the receipt and transaction trie proofs of each of them to the node set.
+func proveLogs(txs types.Transactions, receipts types.Receipts, query *LogsQuery, nodes *light.NodeSet) []LogsReceipt {
+	var (
+		matched  []LogsReceipt
+		logIndex uint64
+	)
+	for i, receipt := range receipts {
+		for _, entry := range receipt.Logs {
+			if query.matches(entry) {
+				matched = append(matched, LogsReceipt{Index: uint64(i), LogIndex: logIndex})
+				break
+			}
+		}
+		logIndex += uint64(len(receipt.Logs))
+	}
+	if len(matched) == 0 || len(txs) != len(receipts) {
+		return nil
+	}
+	txTrie, _ := trie.New(common.Hash{}, trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	types.DeriveSha(txs, txTrie)
+	receiptTrie, _ := trie.New(common.Hash{}, trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	types.DeriveSha(receipts, receiptTrie)
+
+	for _, receipt := range matched {
+		key := rlp.AppendUint64(nil, receipt.Index)
+		txTrie.Prove(key, 0, nodes)
+		receiptTrie.Prove(key, 0, nodes)
+	}
+	return matched
+}
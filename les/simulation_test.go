@@ -0,0 +1,166 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/consensus/gdtuash"
+	"github.com/c88032111/go-gdtu/core"
+	"github.com/c88032111/go-gdtu/core/rawdb"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/gdtu"
+	"github.com/c88032111/go-gdtu/gdtu/downloader"
+	"github.com/c88032111/go-gdtu/gdtu/gdtuconfig"
+	"github.com/c88032111/go-gdtu/node"
+	"github.com/c88032111/go-gdtu/p2p/simulations"
+	"github.com/c88032111/go-gdtu/p2p/simulations/adapters"
+	"github.com/c88032111/go-gdtu/params"
+)
+
+// simChain creates a genesis spec and a chain of blocks on top of it, moving
+// funds around to create some state to sync.
+func simChain(n int) (*core.Genesis, []*types.Block) {
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  core.GenesisAlloc{bankAddr: {Balance: bankFunds}},
+	}
+	db := rawdb.NewMemoryDatabase()
+	blocks, _ := core.GenerateChain(genesis.Config, genesis.MustCommit(db), gdtuash.NewFaker(), db, n, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(bankAddr), userAddr1, big.NewInt(1000), params.TxGas, nil, nil), types.HomesteadSigner{}, bankKey)
+		block.AddTx(tx)
+	})
+	return genesis, blocks
+}
+
+// simConfig returns the configuration of a simulated node on the given chain.
+func simConfig(genesis *core.Genesis, mode downloader.SyncMode) *gdtuconfig.Config {
+	config := gdtuconfig.Defaults
+	config.Genesis = genesis
+	config.SyncMode = mode
+	config.Gdtuash.PowMode = gdtuash.ModeFake
+	return &config
+}
+
+// Tests that full and light nodes can be run in-process by the simulation
+// adapter, syncing from a full node serving both the snap and les protocols.
+func TestSimulatedSync(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping simulation in short mode")
+	}
+	genesis, blocks := simChain(150)
+	head := blocks[len(blocks)-1]
+
+	adapter := adapters.NewSimAdapter(adapters.LifecycleConstructors{
+		"server": func(ctx *adapters.ServiceContext, stack *node.Node) (node.Lifecycle, error) {
+			config := simConfig(genesis, downloader.FullSync)
+			config.LightServ = testServerCapacity
+			config.LightPeers = testMaxClients
+			config.LightNoSyncServe = true
+
+			backend, err := gdtu.New(stack, config)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := backend.BlockChain().InsertChain(blocks); err != nil {
+				return nil, err
+			}
+			if _, err := NewLesServer(stack, backend, config); err != nil {
+				return nil, err
+			}
+			return backend, nil
+		},
+		"snap": func(ctx *adapters.ServiceContext, stack *node.Node) (node.Lifecycle, error) {
+			return gdtu.New(stack, simConfig(genesis, downloader.SnapSync))
+		},
+		"light": func(ctx *adapters.ServiceContext, stack *node.Node) (node.Lifecycle, error) {
+			return New(stack, simConfig(genesis, downloader.LightSync))
+		},
+	})
+	net := simulations.NewNetwork(adapter, &simulations.NetworkConfig{ID: "0"})
+	defer net.Shutdown()
+
+	nodes := make(map[string]*simulations.Node)
+	for _, service := range []string{"server", "snap", "light"} {
+		config := adapters.RandomNodeConfig()
+		config.Lifecycles = []string{service}
+		n, err := net.NewNodeWithConfig(config)
+		if err != nil {
+			t.Fatalf("failed to create %s node: %v", service, err)
+		}
+		if err := net.Start(n.ID()); err != nil {
+			t.Fatalf("failed to start %s node: %v", service, err)
+		}
+		nodes[service] = n
+	}
+	// The server only advertises the snap protocol once it has verified that its
+	// state is complete, which is rechecked periodically after the chain import
+	server, err := nodes["server"].Client()
+	if err != nil {
+		t.Fatalf("failed to attach to server: %v", err)
+	}
+	timeout := time.After(2 * time.Minute)
+	for {
+		var status gdtu.SnapAvailability
+		if err := server.Call(&status, "admin_snapAvailability"); err != nil {
+			t.Fatalf("failed to retrieve snap availability: %v", err)
+		}
+		if status.Available {
+			break
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("snap serving timed out: %s", status.Reason)
+		case <-time.After(time.Second):
+		}
+	}
+	for _, service := range []string{"snap", "light"} {
+		if err := net.Connect(nodes[service].ID(), nodes["server"].ID()); err != nil {
+			t.Fatalf("failed to connect %s node: %v", service, err)
+		}
+	}
+	service := func(name string) node.Lifecycle {
+		return nodes[name].Node.(*adapters.SimNode).Service(name)
+	}
+	snapChain := service("snap").(*gdtu.Gdtu).BlockChain()
+	lightChain := service("light").(*LightGdtu).BlockChain()
+
+	// Full nodes only start syncing with a single peer after the forced sync
+	// cycle, so give them plenty of time
+	timeout = time.After(time.Minute)
+	for {
+		snapHead, lightHead := snapChain.CurrentBlock().Hash(), lightChain.CurrentHeader().Hash()
+		if snapHead == head.Hash() && lightHead == head.Hash() {
+			break
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("sync timed out: snap head %x, light head %x, want %x", snapHead, lightHead, head.Hash())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	// Snap sync should have retrieved the state of the head block
+	state, err := snapChain.State()
+	if err != nil {
+		t.Fatalf("failed to retrieve synced state: %v", err)
+	}
+	if balance := state.GetBalance(userAddr1); balance.Cmp(big.NewInt(int64(1000*len(blocks)))) != 0 {
+		t.Errorf("synced balance mismatch: have %v, want %v", balance, 1000*len(blocks))
+	}
+}
@@ -67,19 +67,7 @@ func (h *clientHandler) validateCheckpoint(peer *serverPeer) error {
 	if err != nil {
 		return err
 	}
-	events := h.backend.oracle.Contract().LookupCheckpointEvents(logs, peer.checkpoint.SectionIndex, peer.checkpoint.Hash())
-	if len(events) == 0 {
-		return errInvalidCheckpoint
-	}
-	var (
-		index      = events[0].Index
-		hash       = events[0].CheckpointHash
-		signatures [][]byte
-	)
-	for _, event := range events {
-		signatures = append(signatures, append(event.R[:], append(event.S[:], event.V)...))
-	}
-	valid, signers := h.backend.oracle.VerifySigners(index, hash, signatures)
+	valid, signers := h.backend.oracle.VerifyCheckpoint(logs, peer.checkpoint.SectionIndex, peer.checkpoint.Hash())
 	if !valid {
 		return errInvalidCheckpoint
 	}
@@ -192,6 +180,10 @@ func (h *clientHandler) synchronise(peer *serverPeer) {
 		}
 	}
 
+	// On clique chains, skip over the epochs provable by the peer
+	if h.backend.blockchain.Config().Clique != nil && peer.version >= lpv5 {
+		h.syncEpochs(peer)
+	}
 	if h.syncStart != nil {
 		h.syncStart(h.backend.blockchain.CurrentHeader())
 	}
@@ -202,3 +194,45 @@ func (h *clientHandler) synchronise(peer *serverPeer) {
 	}
 	log.Debug("Synchronise finished", "elapsed", common.PrettyDuration(time.Since(start)))
 }
+
+// syncEpochs fast-forwards the local clique chain over the epoch checkpoints
+// the remote peer can prove, instead of verifying every header of the skipped
+// epochs. It stops at the first epoch which can't be proven, leaving the rest
+// to the regular header sync.
+func (h *clientHandler) syncEpochs(peer *serverPeer) {
+	var (
+		chain    = h.backend.blockchain
+		epoch    = chain.Config().Clique.Epoch
+		wrapPeer = &peerConnection{handler: h, peer: peer}
+	)
+	peer.lock.RLock()
+	last := peer.headInfo.Number / epoch
+	peer.lock.RUnlock()
+
+	for {
+		next := chain.CurrentHeader().Number.Uint64()/epoch + 1
+		if next > last {
+			return
+		}
+		epochs := make([]uint64, 0, MaxEpochProofsFetch)
+		for n := next; n <= last && len(epochs) < MaxEpochProofsFetch; n++ {
+			epochs = append(epochs, n)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		proofs, err := wrapPeer.RetrieveEpochProofs(ctx, epochs)
+		cancel()
+		if err != nil {
+			log.Debug("Failed to retrieve epoch proofs", "peer", peer.id, "err", err)
+			return
+		}
+		for _, proof := range proofs {
+			if len(proof.Headers) == 0 {
+				return
+			}
+			if err := chain.InsertEpochProof(proof.Headers, proof.Td); err != nil {
+				log.Debug("Failed to verify epoch proof", "peer", peer.id, "number", proof.Headers[0].Number, "err", err)
+				return
+			}
+		}
+	}
+}
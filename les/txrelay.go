@@ -18,21 +18,35 @@ package les
 
 import (
 	"context"
+	"reflect"
 	"sync"
 
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/event"
+	"github.com/c88032111/go-gdtu/light"
 	"github.com/c88032111/go-gdtu/rlp"
 )
 
+// TxStatusEvent is posted when the status of a relayed transaction, as reported
+// by the servers it was sent to, changes.
+type TxStatusEvent struct {
+	Hash   common.Hash
+	Status light.TxStatus
+}
+
 type lesTxRelay struct {
 	txSent       map[common.Hash]*types.Transaction
 	txPending    map[common.Hash]struct{}
+	txStatus     map[common.Hash]light.TxStatus
 	peerList     []*serverPeer
 	peerStartPos int
 	lock         sync.Mutex
 	stop         chan struct{}
 
+	statusFeed  event.Feed
+	statusScope event.SubscriptionScope
+
 	retriever *retrieveManager
 }
 
@@ -40,6 +54,7 @@ func newLesTxRelay(ps *serverPeerSet, retriever *retrieveManager) *lesTxRelay {
 	r := &lesTxRelay{
 		txSent:    make(map[common.Hash]*types.Transaction),
 		txPending: make(map[common.Hash]struct{}),
+		txStatus:  make(map[common.Hash]light.TxStatus),
 		retriever: retriever,
 		stop:      make(chan struct{}),
 	}
@@ -49,6 +64,36 @@ func newLesTxRelay(ps *serverPeerSet, retriever *retrieveManager) *lesTxRelay {
 
 func (ltrx *lesTxRelay) Stop() {
 	close(ltrx.stop)
+	ltrx.statusScope.Close()
+}
+
+// SubscribeTxStatus subscribes to the status changes of the relayed transactions.
+// Servers supporting it push the changes, so the status doesn't need to be polled.
+func (ltrx *lesTxRelay) SubscribeTxStatus(ch chan<- TxStatusEvent) event.Subscription {
+	return ltrx.statusScope.Track(ltrx.statusFeed.Subscribe(ch))
+}
+
+// statusUpdate processes the transaction statuses reported by a server, posting
+// an event for each relayed transaction whose status changed.
+func (ltrx *lesTxRelay) statusUpdate(hashes []common.Hash, stats []light.TxStatus) {
+	var events []TxStatusEvent
+
+	ltrx.lock.Lock()
+	for i, hash := range hashes {
+		if _, ok := ltrx.txSent[hash]; !ok {
+			continue
+		}
+		if last, ok := ltrx.txStatus[hash]; ok && reflect.DeepEqual(last, stats[i]) {
+			continue
+		}
+		ltrx.txStatus[hash] = stats[i]
+		events = append(events, TxStatusEvent{Hash: hash, Status: stats[i]})
+	}
+	ltrx.lock.Unlock()
+
+	for _, ev := range events {
+		ltrx.statusFeed.Send(ev)
+	}
 }
 
 func (ltrx *lesTxRelay) registerPeer(p *serverPeer) {
@@ -134,7 +179,47 @@ func (ltrx *lesTxRelay) send(txs types.Transactions, count int) {
 			},
 		}
 		go ltrx.retriever.retrieve(context.Background(), reqID, rq, func(p distPeer, msg *Msg) error { return nil }, ltrx.stop)
+
+		if pp.version >= lpv5 {
+			ltrx.subscribe(pp, ll)
+		}
+	}
+}
+
+// subscribe subscribes to the status changes of the given transactions at a
+// server they were sent to.
+func (ltrx *lesTxRelay) subscribe(p *serverPeer, txs types.Transactions) {
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+	reqID := genReqID()
+	rq := &distReq{
+		getCost: func(dp distPeer) uint64 {
+			return dp.(*serverPeer).getRequestCost(SubscribeTxStatusMsg, len(hashes))
+		},
+		canSend: func(dp distPeer) bool {
+			return dp.(*serverPeer) == p
+		},
+		request: func(dp distPeer) func() {
+			peer := dp.(*serverPeer)
+			cost := peer.getRequestCost(SubscribeTxStatusMsg, len(hashes))
+			peer.fcServer.QueuedRequest(reqID, cost)
+			return func() { peer.subscribeTxStatus(reqID, hashes) }
+		},
+	}
+	deliver := func(p distPeer, msg *Msg) error {
+		if msg.MsgType != MsgTxStatus {
+			return errInvalidMessageType
+		}
+		stats := msg.Obj.([]light.TxStatus)
+		if len(stats) != len(hashes) {
+			return errInvalidEntryCount
+		}
+		ltrx.statusUpdate(hashes, stats)
+		return nil
 	}
+	go ltrx.retriever.retrieve(context.Background(), reqID, rq, deliver, ltrx.stop)
 }
 
 func (ltrx *lesTxRelay) Send(txs types.Transactions) {
@@ -174,5 +259,6 @@ func (ltrx *lesTxRelay) Discard(hashes []common.Hash) {
 	for _, hash := range hashes {
 		delete(ltrx.txSent, hash)
 		delete(ltrx.txPending, hash)
+		delete(ltrx.txStatus, hash)
 	}
 }
@@ -17,6 +17,8 @@
 package client
 
 import (
+	"errors"
+	"os"
 	"time"
 
 	"github.com/c88032111/go-gdtu/common/mclock"
@@ -27,11 +29,12 @@ import (
 // PrivateClientAPI implements the vflux client side API
 type PrivateClientAPI struct {
 	vt *ValueTracker
+	sp *ServerPool // Server pool owning the value tracker, nil if used standalone
 }
 
 // NewPrivateClientAPI creates a PrivateClientAPI
 func NewPrivateClientAPI(vt *ValueTracker) *PrivateClientAPI {
-	return &PrivateClientAPI{vt}
+	return &PrivateClientAPI{vt: vt}
 }
 
 // parseNodeStr converts either an enode address or a plain hex node id to enode.ID
@@ -105,3 +108,48 @@ func (api *PrivateClientAPI) Value(nodeStr string, timeout float64) (float64, er
 		return 0, err
 	}
 }
+
+// Servers returns the statistics learned about the known and currently connected
+// servers, in decreasing order of selection weight.
+func (api *PrivateClientAPI) Servers() ([]ServerScore, error) {
+	if api.sp == nil {
+		return nil, errors.New("server pool not available")
+	}
+	return api.sp.Scores()
+}
+
+// ExportServers writes the statistics learned about the known servers into the
+// given file, so that they can be imported by another client. It returns the
+// number of exported servers.
+func (api *PrivateClientAPI) ExportServers(file string) (int, error) {
+	if api.sp == nil {
+		return 0, errors.New("server pool not available")
+	}
+	if _, err := os.Stat(file); err == nil {
+		// File already exists. Allowing overwrite could be a DoS vecotor,
+		// since the 'file' may point to arbitrary paths on the drive
+		return 0, errors.New("location would overwrite an existing file")
+	}
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	return api.sp.Export(out)
+}
+
+// ImportServers loads the server statistics exported into the given file by
+// another client. It returns the number of imported servers.
+func (api *PrivateClientAPI) ImportServers(file string) (int, error) {
+	if api.sp == nil {
+		return 0, errors.New("server pool not available")
+	}
+	in, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	return api.sp.Import(in)
+}
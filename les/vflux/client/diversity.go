@@ -0,0 +1,217 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+	"github.com/c88032111/go-gdtu/p2p/nodestate"
+)
+
+const diversityWait = time.Minute // time a node is excluded from selection after being rejected for diversity
+
+// NetworkResolver maps IP addresses to the network operating them, like an
+// autonomous system, a hosting provider or a country. The server pool uses it to
+// avoid connecting to too many servers operated by the same network.
+type NetworkResolver interface {
+	// Network returns an identifier of the network the address belongs to, or an
+	// empty string if unknown. Servers in unknown networks are not constrained.
+	Network(ip net.IP) string
+}
+
+// SubnetResolver is a NetworkResolver approximating networks by address ranges:
+// IPv4 addresses are grouped by /16 and IPv6 addresses by /32 subnets. It needs no
+// external data, but can't tell apart different networks sharing a subnet or group
+// the subnets of a single provider.
+type SubnetResolver struct{}
+
+// Network implements NetworkResolver.
+func (SubnetResolver) Network(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return (&net.IPNet{IP: ip4.Mask(net.CIDRMask(16, 32)), Mask: net.CIDRMask(16, 32)}).String()
+	}
+	if ip.To16() != nil {
+		return (&net.IPNet{IP: ip.Mask(net.CIDRMask(32, 128)), Mask: net.CIDRMask(32, 128)}).String()
+	}
+	return ""
+}
+
+// PrefixResolver is a NetworkResolver looking up addresses in a table of network
+// prefixes, such as an IP-to-ASN or IP-to-country database. The most specific
+// prefix containing an address determines its network.
+type PrefixResolver struct {
+	prefixes map[int]map[string]string // prefix length -> masked IP -> network
+	lengths  []int                     // prefix lengths present in the table, descending
+}
+
+// NewPrefixResolver creates an empty prefix table.
+func NewPrefixResolver() *PrefixResolver {
+	return &PrefixResolver{prefixes: make(map[int]map[string]string)}
+}
+
+// LoadPrefixResolver creates a prefix table from a file listing one prefix in CIDR
+// notation and the identifier of its network per line, separated by whitespace.
+// Empty lines and lines starting with # are ignored.
+func LoadPrefixResolver(path string) (*PrefixResolver, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		resolver = NewPrefixResolver()
+		scanner  = bufio.NewScanner(file)
+		line     int
+	)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected <prefix> <network>", path, line)
+		}
+		_, prefix, err := net.ParseCIDR(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		resolver.Add(prefix, fields[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return resolver, nil
+}
+
+// Add assigns the addresses of the given prefix to a network.
+func (r *PrefixResolver) Add(prefix *net.IPNet, network string) {
+	ones, bits := prefix.Mask.Size()
+	if bits == 32 {
+		ones += 96 // IPv4 prefixes are stored in their IPv6 mapped form
+	}
+	table, ok := r.prefixes[ones]
+	if !ok {
+		table = make(map[string]string)
+		r.prefixes[ones] = table
+
+		r.lengths = append(r.lengths, ones)
+		for i := len(r.lengths) - 1; i > 0 && r.lengths[i] > r.lengths[i-1]; i-- {
+			r.lengths[i], r.lengths[i-1] = r.lengths[i-1], r.lengths[i]
+		}
+	}
+	table[string(prefix.IP.To16().Mask(net.CIDRMask(ones, 128)))] = network
+}
+
+// Network implements NetworkResolver.
+func (r *PrefixResolver) Network(ip net.IP) string {
+	ip = ip.To16()
+	if ip == nil {
+		return ""
+	}
+	for _, ones := range r.lengths {
+		if network, ok := r.prefixes[ones][string(ip.Mask(net.CIDRMask(ones, 128)))]; ok {
+			return network
+		}
+	}
+	return ""
+}
+
+// diversityFilter limits the number of servers the pool dials or is connected to
+// within any single network.
+type diversityFilter struct {
+	ns       *nodestate.NodeStateMachine
+	resolver NetworkResolver
+	limit    int
+
+	lock    sync.Mutex
+	active  map[enode.ID]string // networks of the dialed and connected servers
+	counts  map[string]int      // number of dialed and connected servers per network
+	trusted map[enode.ID]bool   // always connected servers, exempt from the limit
+}
+
+// newDiversityFilter creates a filter tracking the networks of the servers in the
+// dialing and connected states. It should be created before the state machine is
+// started.
+func newDiversityFilter(ns *nodestate.NodeStateMachine, resolver NetworkResolver, limit int) *diversityFilter {
+	f := &diversityFilter{
+		ns:       ns,
+		resolver: resolver,
+		limit:    limit,
+		active:   make(map[enode.ID]string),
+		counts:   make(map[string]int),
+		trusted:  make(map[enode.ID]bool),
+	}
+	ns.SubscribeState(nodestate.MergeFlags(sfDialing, sfConnected), func(n *enode.Node, oldState, newState nodestate.Flags) {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		switch {
+		case oldState.IsEmpty() && !newState.IsEmpty():
+			if network := resolver.Network(n.IP()); network != "" {
+				f.active[n.ID()] = network
+				f.counts[network]++
+			}
+		case !oldState.IsEmpty() && newState.IsEmpty():
+			if network, ok := f.active[n.ID()]; ok {
+				delete(f.active, n.ID())
+				if f.counts[network]--; f.counts[network] == 0 {
+					delete(f.counts, network)
+				}
+			}
+		}
+	})
+	return f
+}
+
+// setTrusted exempts the given server from the limit.
+func (f *diversityFilter) setTrusted(id enode.ID) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.trusted[id] = true
+}
+
+// check returns whether the given dial candidate may be dialed without exceeding
+// the limit of its network. Rejected candidates are excluded from the selection
+// for a while.
+func (f *diversityFilter) check(node *enode.Node) bool {
+	f.lock.Lock()
+	if f.trusted[node.ID()] {
+		f.lock.Unlock()
+		return true
+	}
+	network := f.resolver.Network(node.IP())
+	if network == "" || f.counts[network] < f.limit {
+		f.lock.Unlock()
+		return true
+	}
+	f.lock.Unlock()
+
+	log.Debug("Skipping server for network diversity", "id", node.ID(), "network", network)
+	f.ns.SetState(node, sfDiversityWait, sfCanDial, diversityWait)
+	return false
+}
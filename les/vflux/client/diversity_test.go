@@ -0,0 +1,115 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+package client
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common/mclock"
+	"github.com/c88032111/go-gdtu/gdtudb/memorydb"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+	"github.com/c88032111/go-gdtu/p2p/enr"
+	"github.com/c88032111/go-gdtu/p2p/nodestate"
+)
+
+func TestPrefixResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "networkmap-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "networks.txt")
+	table := "# prefix network\n10.0.0.0/8 AS1\n10.1.0.0/16 AS2\n\n2001:db8::/32 AS3\n"
+	if err := ioutil.WriteFile(path, []byte(table), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := LoadPrefixResolver(path)
+	if err != nil {
+		t.Fatalf("failed to load prefix table: %v", err)
+	}
+	for _, tt := range []struct {
+		ip, network string
+	}{
+		{"10.2.3.4", "AS1"},
+		{"10.1.3.4", "AS2"}, // most specific prefix wins
+		{"11.1.3.4", ""},
+		{"2001:db8::1", "AS3"},
+		{"2001:db9::1", ""},
+	} {
+		if network := resolver.Network(net.ParseIP(tt.ip)); network != tt.network {
+			t.Errorf("network mismatch for %s: have %q, want %q", tt.ip, network, tt.network)
+		}
+	}
+	if err := ioutil.WriteFile(path, []byte("10.0.0.0 AS1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPrefixResolver(path); err == nil {
+		t.Errorf("invalid prefix table accepted")
+	}
+}
+
+func TestDiversityFilter(t *testing.T) {
+	ns := nodestate.NewNodeStateMachine(memorydb.New(), []byte("ns:"), &mclock.Simulated{}, clientSetup)
+	filter := newDiversityFilter(ns, SubnetResolver{}, 2)
+	ns.Start()
+	defer ns.Stop()
+
+	newNode := func(i int, ip string) *enode.Node {
+		var r enr.Record
+		r.Set(enr.IP(net.ParseIP(ip)))
+		return enode.SignNull(&r, testNodeID(i))
+	}
+	var (
+		first   = newNode(0, "10.0.0.1")
+		second  = newNode(1, "10.0.1.1")
+		third   = newNode(2, "10.0.2.1")
+		other   = newNode(3, "10.1.0.1")
+		trusted = newNode(4, "10.0.3.1")
+	)
+	filter.setTrusted(trusted.ID())
+
+	ns.SetState(first, sfDialing, nodestate.Flags{}, 0)
+	ns.SetState(second, sfConnected, nodestate.Flags{}, 0)
+
+	// The subnet is full, only servers in other subnets or trusted ones are allowed
+	if filter.check(third) {
+		t.Errorf("server over the network limit allowed")
+	}
+	if !filter.check(other) {
+		t.Errorf("server in another network rejected")
+	}
+	if !filter.check(trusted) {
+		t.Errorf("trusted server rejected")
+	}
+	// Rejected servers should be excluded from selection for a while
+	var waiting bool
+	ns.ForEach(sfDiversityWait, nodestate.Flags{}, func(n *enode.Node, state nodestate.Flags) {
+		waiting = waiting || n.ID() == third.ID()
+	})
+	if !waiting {
+		t.Errorf("rejected server not excluded from selection")
+	}
+	// Dropping a server should make room for another one
+	ns.SetState(first, nodestate.Flags{}, sfDialing, 0)
+	if !filter.check(third) {
+		t.Errorf("server rejected after another one was dropped")
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+	"github.com/c88032111/go-gdtu/p2p/enr"
+	"github.com/c88032111/go-gdtu/p2p/nodestate"
+	"github.com/c88032111/go-gdtu/rlp"
+)
+
+const exportVersion = 1 // encoding format of exported server pool statistics
+
+var errPoolNotStarted = errors.New("server pool not started yet")
+
+// serverPoolExport is the portable form of the statistics learned by the server
+// pool: the global value tracker state and the history of each known server.
+type serverPoolExport struct {
+	Version uint
+	Tracker []byte
+	Nodes   []exportedNode
+}
+
+type exportedNode struct {
+	Record  *enr.Record
+	History nodeHistoryEnc
+	Stats   []byte
+}
+
+// Export writes the statistics learned about the known servers to w, so that they
+// can be imported into another (e.g. reinstalled or migrated) client instead of
+// relearning the quality of the servers from scratch.
+func (s *ServerPool) Export(w io.Writer) (int, error) {
+	if atomic.LoadUint32(&s.started) == 0 {
+		return 0, errPoolNotStarted
+	}
+	tracker, err := s.vt.exportState()
+	if err != nil {
+		return 0, err
+	}
+	exp := serverPoolExport{Version: exportVersion, Tracker: tracker}
+	s.ns.ForEach(sfHasValue, nodestate.Flags{}, func(node *enode.Node, state nodestate.Flags) {
+		stats, err := s.vt.exportNode(node.ID())
+		if err != nil || stats == nil {
+			return
+		}
+		n, _ := s.ns.GetField(node, sfiNodeHistory).(nodeHistory)
+		exp.Nodes = append(exp.Nodes, exportedNode{
+			Record: node.Record(),
+			History: nodeHistoryEnc{
+				DialCost:        n.dialCost,
+				RedialWaitStart: uint64(n.redialWaitStart),
+				RedialWaitEnd:   uint64(n.redialWaitEnd),
+			},
+			Stats: stats,
+		})
+	})
+	return len(exp.Nodes), rlp.Encode(w, &exp)
+}
+
+// Import loads previously exported server statistics from r and returns the number
+// of servers imported. The global statistics are replaced by the imported ones,
+// statistics of servers currently connected or being dialed are left untouched.
+func (s *ServerPool) Import(r io.Reader) (int, error) {
+	if atomic.LoadUint32(&s.started) == 0 {
+		return 0, errPoolNotStarted
+	}
+	var exp serverPoolExport
+	if err := rlp.Decode(r, &exp); err != nil {
+		return 0, err
+	}
+	if exp.Version != exportVersion {
+		return 0, fmt.Errorf("unknown server pool export version %d (current version is %d)", exp.Version, exportVersion)
+	}
+	if err := s.vt.importState(exp.Tracker); err != nil {
+		return 0, err
+	}
+	var (
+		imported int
+		unixTime = s.unixTime()
+		busy     = make(map[enode.ID]struct{})
+	)
+	markBusy := func(node *enode.Node, state nodestate.Flags) { busy[node.ID()] = struct{}{} }
+	s.ns.ForEach(sfConnected, nodestate.Flags{}, markBusy)
+	s.ns.ForEach(sfDialing, nodestate.Flags{}, markBusy)
+
+	for _, en := range exp.Nodes {
+		node, err := enode.New(s.validSchemes, en.Record)
+		if err != nil {
+			log.Warn("Skipping invalid exported server", "err", err)
+			continue
+		}
+		if _, ok := busy[node.ID()]; ok {
+			continue
+		}
+		if err := s.vt.importNode(node.ID(), en.Stats); err != nil {
+			log.Warn("Skipping invalid exported server", "id", node.ID(), "err", err)
+			continue
+		}
+		history := nodeHistory{
+			dialCost:        en.History.DialCost,
+			redialWaitStart: int64(en.History.RedialWaitStart),
+			redialWaitEnd:   int64(en.History.RedialWaitEnd),
+		}
+		s.ns.Operation(func() {
+			s.ns.SetFieldSub(node, sfiNodeHistory, history)
+			s.restoreNode(node, unixTime)
+		})
+		imported++
+	}
+	return imported, nil
+}
+
+// ServerScore is the human readable summary of the statistics learned about a
+// server.
+type ServerScore struct {
+	ID         enode.ID `json:"id"`
+	Enode      string   `json:"enode"`
+	Connected  bool     `json:"connected"`
+	Weight     uint64   `json:"weight"`     // Selection weight among the known servers
+	Value      float64  `json:"value"`      // Total service value provided by the server
+	DialCost   uint64   `json:"dialCost"`   // Total cost of the connection attempts
+	RedialWait string   `json:"redialWait"` // Time until the server may be dialed again
+}
+
+// Scores returns the statistics of the known and currently connected servers,
+// in decreasing order of selection weight.
+func (s *ServerPool) Scores() ([]ServerScore, error) {
+	if atomic.LoadUint32(&s.started) == 0 {
+		return nil, errPoolNotStarted
+	}
+	var (
+		scores   = make(map[enode.ID]*ServerScore)
+		unixTime = s.unixTime()
+	)
+	score := func(node *enode.Node, state nodestate.Flags) {
+		if _, ok := scores[node.ID()]; ok {
+			return
+		}
+		n, _ := s.ns.GetField(node, sfiNodeHistory).(nodeHistory)
+		weight, _ := s.ns.GetField(node, sfiNodeWeight).(uint64)
+		_, value := s.serviceValue(node)
+		var wait time.Duration
+		if n.redialWaitEnd > unixTime {
+			wait = time.Duration(n.redialWaitEnd-unixTime) * time.Second
+		}
+		score := &ServerScore{
+			ID:         node.ID(),
+			Enode:      node.String(),
+			Weight:     weight,
+			Value:      value,
+			DialCost:   s.addDialCost(&n, 0),
+			RedialWait: common.PrettyDuration(wait).String(),
+		}
+		scores[node.ID()] = score
+	}
+	s.ns.ForEach(sfConnected, nodestate.Flags{}, func(node *enode.Node, state nodestate.Flags) {
+		score(node, state)
+		scores[node.ID()].Connected = true
+	})
+	s.ns.ForEach(sfHasValue, nodestate.Flags{}, score)
+
+	list := make([]ServerScore, 0, len(scores))
+	for _, score := range scores {
+		list = append(list, *score)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Weight != list[j].Weight {
+			return list[i].Weight > list[j].Weight
+		}
+		return list[i].Value > list[j].Value
+	})
+	return list, nil
+}
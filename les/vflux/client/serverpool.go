@@ -65,6 +65,7 @@ type ServerPool struct {
 	validSchemes        enr.IdentityScheme
 	trustedURLs         []string
 	fillSet             *FillSet
+	diversity           *diversityFilter
 	started, queryFails uint32
 
 	timeoutLock      sync.RWMutex
@@ -103,7 +104,8 @@ var (
 	sfConnected        = clientSetup.NewFlag("connected")
 	sfRedialWait       = clientSetup.NewFlag("redialWait")
 	sfAlwaysConnect    = clientSetup.NewFlag("alwaysConnect")
-	sfDisableSelection = nodestate.MergeFlags(sfQueried, sfCanDial, sfDialing, sfConnected, sfRedialWait)
+	sfDiversityWait    = clientSetup.NewFlag("diversityWait")
+	sfDisableSelection = nodestate.MergeFlags(sfQueried, sfCanDial, sfDialing, sfConnected, sfRedialWait, sfDiversityWait)
 
 	sfiNodeHistory = clientSetup.NewPersistentField("nodeHistory", reflect.TypeOf(nodeHistory{}),
 		func(field interface{}) ([]byte, error) {
@@ -181,7 +183,7 @@ func NewServerPool(db gdtudb.KeyValueStore, dbKey []byte, mixTimeout time.Durati
 	})
 
 	return s, &serverPoolIterator{
-		dialIterator: s.dialIterator,
+		dialIterator: enode.Filter(s.dialIterator, s.checkDiversity),
 		nextFn: func(node *enode.Node) {
 			s.ns.Operation(func() {
 				s.ns.SetStateSub(node, sfDialing, sfCanDial, 0)
@@ -236,6 +238,22 @@ func (s *ServerPool) AddMetrics(
 	}
 }
 
+// SetDiversity limits the number of servers dialed or connected at the same time
+// within any single network, as determined by the given resolver. Trusted servers
+// are not limited. Should be called before Start().
+func (s *ServerPool) SetDiversity(resolver NetworkResolver, limit int) {
+	s.diversity = newDiversityFilter(s.ns, resolver, limit)
+}
+
+// checkDiversity returns whether a dial candidate is allowed by the diversity
+// constraints of the pool, if any.
+func (s *ServerPool) checkDiversity(node *enode.Node) bool {
+	if s.diversity == nil {
+		return true
+	}
+	return s.diversity.check(node)
+}
+
 // AddSource adds a node discovery source to the server pool (should be called before start)
 func (s *ServerPool) AddSource(source enode.Iterator) {
 	if source != nil {
@@ -304,6 +322,9 @@ func (s *ServerPool) Start() {
 	}
 	for _, url := range s.trustedURLs {
 		if node, err := enode.Parse(s.validSchemes, url); err == nil {
+			if s.diversity != nil {
+				s.diversity.setTrusted(node.ID())
+			}
 			s.ns.SetState(node, sfAlwaysConnect, nodestate.Flags{}, 0)
 		} else {
 			log.Error("Invalid trusted server URL", "url", url, "error", err)
@@ -312,22 +333,29 @@ func (s *ServerPool) Start() {
 	unixTime := s.unixTime()
 	s.ns.Operation(func() {
 		s.ns.ForEach(sfHasValue, nodestate.Flags{}, func(node *enode.Node, state nodestate.Flags) {
-			s.calculateWeight(node)
-			if n, ok := s.ns.GetField(node, sfiNodeHistory).(nodeHistory); ok && n.redialWaitEnd > unixTime {
-				wait := n.redialWaitEnd - unixTime
-				lastWait := n.redialWaitEnd - n.redialWaitStart
-				if wait > lastWait {
-					// if the time until expiration is larger than the last suggested
-					// waiting time then the system clock was probably adjusted
-					wait = lastWait
-				}
-				s.ns.SetStateSub(node, sfRedialWait, nodestate.Flags{}, time.Duration(wait)*time.Second)
-			}
+			s.restoreNode(node, unixTime)
 		})
 	})
 	atomic.StoreUint32(&s.started, 1)
 }
 
+// restoreNode calculates the weight of a node with a saved history and restores
+// its remaining redial waiting time.
+// Note: this function should run inside a NodeStateMachine operation
+func (s *ServerPool) restoreNode(node *enode.Node, unixTime int64) {
+	s.calculateWeight(node)
+	if n, ok := s.ns.GetField(node, sfiNodeHistory).(nodeHistory); ok && n.redialWaitEnd > unixTime {
+		wait := n.redialWaitEnd - unixTime
+		lastWait := n.redialWaitEnd - n.redialWaitStart
+		if wait > lastWait {
+			// if the time until expiration is larger than the last suggested
+			// waiting time then the system clock was probably adjusted
+			wait = lastWait
+		}
+		s.ns.SetStateSub(node, sfRedialWait, nodestate.Flags{}, time.Duration(wait)*time.Second)
+	}
+}
+
 // stop stops the server pool
 func (s *ServerPool) Stop() {
 	if s.fillSet != nil {
@@ -568,7 +596,9 @@ func (s *ServerPool) calculateWeight(node *enode.Node) {
 
 // API returns the vflux client API
 func (s *ServerPool) API() *PrivateClientAPI {
-	return NewPrivateClientAPI(s.vt)
+	api := NewPrivateClientAPI(s.vt)
+	api.sp = s
+	return api
 }
 
 type dummyIdentity enode.ID
@@ -17,7 +17,9 @@
 package client
 
 import (
+	"bytes"
 	"math/rand"
+	"reflect"
 	"strconv"
 	"sync/atomic"
 	"testing"
@@ -26,8 +28,11 @@ import (
 	"github.com/c88032111/go-gdtu/common/mclock"
 	"github.com/c88032111/go-gdtu/gdtudb"
 	"github.com/c88032111/go-gdtu/gdtudb/memorydb"
+	"github.com/c88032111/go-gdtu/les/utils"
 	"github.com/c88032111/go-gdtu/p2p/enode"
 	"github.com/c88032111/go-gdtu/p2p/enr"
+	"github.com/c88032111/go-gdtu/p2p/nodestate"
+	"github.com/c88032111/go-gdtu/rlp"
 )
 
 const (
@@ -352,3 +357,104 @@ func testServerPoolTrustedNoDiscovery(t *testing.T, preNeg bool) {
 	s.stop()
 	s.checkNodes(t, trusted)
 }
+
+func TestServerPoolExport(t *testing.T) {
+	s := newServerPoolTest(false, false)
+	nodes := s.setNodes(100, 200, 200, true, false)
+	s.setNodes(100, 20, 20, false, false)
+	s.start()
+	s.run()
+
+	scores, err := s.sp.Scores()
+	if err != nil {
+		t.Fatalf("Failed to retrieve server scores: %v", err)
+	}
+	var connected int
+	for _, score := range scores {
+		if score.Connected {
+			connected++
+		}
+	}
+	if connected != s.conn {
+		t.Errorf("Connected server count mismatch: have %d, want %d", connected, s.conn)
+	}
+	var export bytes.Buffer
+	exported, err := s.sp.Export(&export)
+	if err != nil {
+		t.Fatalf("Failed to export server statistics: %v", err)
+	}
+	s.stop()
+	s.checkNodes(t, nodes)
+
+	// Import the statistics into a fresh client without discovery, it should
+	// find the good servers right away
+	s2 := newServerPoolTest(false, false)
+	s2.clock = s.clock
+	s2.testNodes = s.testNodes
+	s2.input = nil
+	s2.start()
+	imported, err := s2.sp.Import(&export)
+	if err != nil {
+		t.Fatalf("Failed to import server statistics: %v", err)
+	}
+	if imported != exported || imported == 0 {
+		t.Errorf("Imported server count mismatch: have %d, want %d", imported, exported)
+	}
+	s2.run()
+	s2.stop()
+	s2.checkNodes(t, nodes)
+}
+
+func TestServerPoolImportConnected(t *testing.T) {
+	s := newServerPoolTest(false, false)
+	s.setNodes(100, 200, 200, true, false)
+	s.start()
+	s.run()
+	defer s.stop()
+
+	var buf bytes.Buffer
+	if _, err := s.sp.Export(&buf); err != nil {
+		t.Fatalf("Failed to export server statistics: %v", err)
+	}
+	var exp serverPoolExport
+	if err := rlp.Decode(&buf, &exp); err != nil {
+		t.Fatalf("Failed to decode exported statistics: %v", err)
+	}
+	// Re-import the statistics with a marked dial cost into the running pool,
+	// only the servers neither connected nor being dialed should take it
+	marker := utils.ExpiredValue{Base: 123456789}
+	busy := make(map[enode.ID]interface{})
+	markBusy := func(node *enode.Node, state nodestate.Flags) {
+		busy[node.ID()] = s.sp.ns.GetField(node, sfiNodeHistory)
+	}
+	s.sp.ns.ForEach(sfConnected, nodestate.Flags{}, markBusy)
+	s.sp.ns.ForEach(sfDialing, nodestate.Flags{}, markBusy)
+
+	var want int
+	for i := range exp.Nodes {
+		exp.Nodes[i].History.DialCost = marker
+		node, err := enode.New(enode.ValidSchemesForTesting, exp.Nodes[i].Record)
+		if err != nil {
+			t.Fatalf("Invalid exported server record: %v", err)
+		}
+		if _, ok := busy[node.ID()]; !ok {
+			want++
+		}
+	}
+	if len(busy) == 0 || want == len(exp.Nodes) {
+		t.Fatalf("No connected server among the exported ones")
+	}
+	enc, _ := rlp.EncodeToBytes(&exp)
+	imported, err := s.sp.Import(bytes.NewReader(enc))
+	if err != nil {
+		t.Fatalf("Failed to import server statistics: %v", err)
+	}
+	if imported != want {
+		t.Errorf("Imported server count mismatch: have %d, want %d", imported, want)
+	}
+	for id, history := range busy {
+		if have := s.sp.ns.GetField(s.sp.ns.GetNode(id), sfiNodeHistory); !reflect.DeepEqual(have, history) {
+			t.Errorf("History of connected server %v overwritten: have %v, want %v", id, have, history)
+		}
+	}
+}
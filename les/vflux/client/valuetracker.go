@@ -18,6 +18,7 @@ package client
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math"
 	"sync"
@@ -249,6 +250,13 @@ func (vt *ValueTracker) loadFromDb(mapping []string) error {
 	if err != nil {
 		return err
 	}
+	return vt.decodeState(enc, mapping)
+}
+
+// decodeState restores the value tracker's state from its encoded form and converts
+// the saved request basket index mapping if it does not match the specified index
+// to name mapping.
+func (vt *ValueTracker) decodeState(enc []byte, mapping []string) error {
 	r := bytes.NewReader(enc)
 	var version uint
 	if err := rlp.Decode(r, &version); err != nil {
@@ -304,6 +312,19 @@ loop:
 
 // saveToDb saves the value tracker's state to the database
 func (vt *ValueTracker) saveToDb() {
+	enc, err := vt.encodeState()
+	if err != nil {
+		log.Error("Encoding value tracker state failed", "err", err)
+		return
+	}
+	if err := vt.db.Put(vtKey, enc); err != nil {
+		log.Error("Saving value tracker state failed", "err", err)
+	}
+}
+
+// encodeState returns the value tracker's state in the format it is saved to the
+// database.
+func (vt *ValueTracker) encodeState() ([]byte, error) {
 	vte := valueTrackerEncV1{
 		Mappings:         vt.mappings,
 		RefBasketMapping: uint(vt.currentMapping),
@@ -314,17 +335,13 @@ func (vt *ValueTracker) saveToDb() {
 	}
 	enc1, err := rlp.EncodeToBytes(uint(vtVersion))
 	if err != nil {
-		log.Error("Encoding value tracker state failed", "err", err)
-		return
+		return nil, err
 	}
 	enc2, err := rlp.EncodeToBytes(&vte)
 	if err != nil {
-		log.Error("Encoding value tracker state failed", "err", err)
-		return
-	}
-	if err := vt.db.Put(vtKey, append(enc1, enc2...)); err != nil {
-		log.Error("Saving value tracker state failed", "err", err)
+		return nil, err
 	}
+	return append(enc1, enc2...), nil
 }
 
 // Stop saves the value tracker's state and each loaded node's individual state and
@@ -394,33 +411,37 @@ func (vt *ValueTracker) loadOrNewNode(id enode.ID) *NodeValueTracker {
 	if err != nil {
 		return nv
 	}
+	if err := vt.decodeNode(nv, enc); err != nil {
+		log.Error("Failed to decode node value tracker", "id", id, "err", err)
+	}
+	return nv
+}
+
+// decodeNode restores a server node's statistics from their encoded form.
+func (vt *ValueTracker) decodeNode(nv *NodeValueTracker, enc []byte) error {
 	r := bytes.NewReader(enc)
 	var version uint
 	if err := rlp.Decode(r, &version); err != nil {
-		log.Error("Failed to decode node value tracker", "id", id, "err", err)
-		return nv
+		return err
 	}
 	if version != nvtVersion {
-		log.Error("Unknown NodeValueTracker version", "stored", version, "current", nvtVersion)
-		return nv
+		return fmt.Errorf("unknown NodeValueTracker version %d (current version is %d)", version, nvtVersion)
 	}
 	var nve nodeValueTrackerEncV1
 	if err := rlp.Decode(r, &nve); err != nil {
-		log.Error("Failed to decode node value tracker", "id", id, "err", err)
-		return nv
+		return err
+	}
+	if nve.ServerBasketMapping >= uint(len(vt.mappings)) {
+		return fmt.Errorf("unknown request basket mapping %d (current version is %d)", nve.ServerBasketMapping, vt.currentMapping)
 	}
 	nv.rtStats = nve.RtStats
 	nv.lastRtStats = nve.RtStats
 	if int(nve.ServerBasketMapping) == vt.currentMapping {
 		nv.basket.basket = nve.ServerBasket
 	} else {
-		if nve.ServerBasketMapping >= uint(len(vt.mappings)) {
-			log.Error("Unknown request basket mapping", "stored", nve.ServerBasketMapping, "current", vt.currentMapping)
-			return nv
-		}
 		nv.basket.basket = nve.ServerBasket.convertMapping(vt.mappings[nve.ServerBasketMapping], vt.mappings[vt.currentMapping], vt.initRefBasket)
 	}
-	return nv
+	return nil
 }
 
 // saveNode saves a server node's value tracker to the database
@@ -430,6 +451,19 @@ func (vt *ValueTracker) saveNode(id enode.ID, nv *NodeValueTracker) {
 	vt.rtStats.AddStats(&recentRtStats)
 	nv.lastRtStats = nv.rtStats
 
+	enc, err := vt.encodeNode(nv)
+	if err != nil {
+		log.Error("Failed to encode service value information", "id", id, "err", err)
+		return
+	}
+	if err := vt.db.Put(append(vtNodeKey, id[:]...), enc); err != nil {
+		log.Error("Failed to save service value information", "id", id, "err", err)
+	}
+}
+
+// encodeNode returns a server node's statistics in the format they are saved to
+// the database.
+func (vt *ValueTracker) encodeNode(nv *NodeValueTracker) ([]byte, error) {
 	nve := nodeValueTrackerEncV1{
 		RtStats:             nv.rtStats,
 		ServerBasketMapping: uint(vt.currentMapping),
@@ -437,17 +471,13 @@ func (vt *ValueTracker) saveNode(id enode.ID, nv *NodeValueTracker) {
 	}
 	enc1, err := rlp.EncodeToBytes(uint(nvtVersion))
 	if err != nil {
-		log.Error("Failed to encode service value information", "id", id, "err", err)
-		return
+		return nil, err
 	}
 	enc2, err := rlp.EncodeToBytes(&nve)
 	if err != nil {
-		log.Error("Failed to encode service value information", "id", id, "err", err)
-		return
-	}
-	if err := vt.db.Put(append(vtNodeKey, id[:]...), append(enc1, enc2...)); err != nil {
-		log.Error("Failed to save service value information", "id", id, "err", err)
+		return nil, err
 	}
+	return append(enc1, enc2...), nil
 }
 
 // RtStats returns the global response time distribution statistics
@@ -504,3 +534,82 @@ func (vt *ValueTracker) RequestStats() []RequestStatsItem {
 	}
 	return res
 }
+
+// exportState returns the up-to-date global state of the value tracker in its
+// database format.
+func (vt *ValueTracker) exportState() ([]byte, error) {
+	vt.lock.Lock()
+	defer vt.lock.Unlock()
+
+	if vt.connected == nil {
+		return nil, errors.New("value tracker stopped")
+	}
+	vt.periodicUpdate()
+	return vt.encodeState()
+}
+
+// importState replaces the global state of the value tracker with a previously
+// exported one. Expiration during the time elapsed since the export is applied
+// just like when loading the state after an offline period.
+func (vt *ValueTracker) importState(enc []byte) error {
+	vt.lock.Lock()
+	defer vt.lock.Unlock()
+
+	if vt.connected == nil {
+		return errors.New("value tracker stopped")
+	}
+	// Decode into a scratch tracker first so that an invalid export does not leave
+	// a partially overwritten state behind
+	mapping := vt.mappings[vt.currentMapping]
+	imported := &ValueTracker{clock: vt.clock, initRefBasket: vt.initRefBasket, offlineExpRate: vt.offlineExpRate}
+	if err := imported.decodeState(enc, mapping); err != nil {
+		return err
+	}
+	now := vt.clock.Now()
+	vt.statsExpirer.SetLogOffset(now, imported.statsExpirer.LogOffset(now))
+	vt.rtStats = imported.rtStats
+	vt.mappings = imported.mappings
+	vt.currentMapping = imported.currentMapping
+	vt.refBasket.basket = imported.refBasket.basket
+	vt.periodicUpdate()
+	return nil
+}
+
+// exportNode returns the statistics of a server node in their database format or
+// nil if there are no statistics about the node.
+func (vt *ValueTracker) exportNode(id enode.ID) ([]byte, error) {
+	vt.lock.Lock()
+	defer vt.lock.Unlock()
+
+	if nv, ok := vt.connected[id]; ok {
+		nv.lock.Lock()
+		defer nv.lock.Unlock()
+		return vt.encodeNode(nv)
+	}
+	enc, err := vt.db.Get(append(vtNodeKey, id[:]...))
+	if err != nil {
+		return nil, nil
+	}
+	return enc, nil
+}
+
+// importNode stores previously exported statistics of a server node. The statistics
+// of currently connected nodes are not overwritten. The global state should be
+// imported first, so that the request basket mapping of the node can be resolved.
+func (vt *ValueTracker) importNode(id enode.ID, enc []byte) error {
+	vt.lock.Lock()
+	defer vt.lock.Unlock()
+
+	if _, ok := vt.connected[id]; ok {
+		return nil
+	}
+	nv := &NodeValueTracker{vt: vt}
+	if err := vt.decodeNode(nv, enc); err != nil {
+		return err
+	}
+	enc, err := vt.encodeNode(nv)
+	if err != nil {
+		return err
+	}
+	return vt.db.Put(append(vtNodeKey, id[:]...), enc)
+}
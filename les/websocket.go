@@ -0,0 +1,95 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+)
+
+const (
+	// websocketPath is the HTTP path light servers accept tunneled clients on.
+	websocketPath = "/les"
+
+	// websocketRedialInterval is the time between checks whgdtuer the tunneled
+	// servers are still connected, redialing them if not.
+	websocketRedialInterval = 10 * time.Second
+
+	// websocketDialTimeout is the maximum time allowed for the WebSocket and
+	// the protocol handshakes of a tunneled server connection.
+	websocketDialTimeout = 20 * time.Second
+)
+
+// websocketServer is a light server reachable through a WebSocket tunnel.
+type websocketServer struct {
+	node *enode.Node
+	url  string
+}
+
+// parseWebsocketServer parses a tunneled light server address in the form of
+// ws(s)://<node id>@host[:port]/path, where the node id is the hex encoded
+// public key of the server, the same as in enode URLs.
+func parseWebsocketServer(rawurl string) (*websocketServer, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil, fmt.Errorf("invalid URL scheme %q, want ws or wss", u.Scheme)
+	}
+	if u.User == nil {
+		return nil, fmt.Errorf("missing node id in %q", rawurl)
+	}
+	node, err := enode.ParseV4("enode://" + u.User.Username())
+	if err != nil {
+		return nil, fmt.Errorf("invalid node id: %v", err)
+	}
+	u.User = nil
+	return &websocketServer{node: node, url: u.String()}, nil
+}
+
+// websocketDialLoop keeps the configured tunneled light servers connected,
+// redialing them whenever they drop off.
+func (s *LightGdtu) websocketDialLoop(servers []*websocketServer) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(websocketRedialInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, server := range servers {
+			if s.peers.peer(server.node.ID().String()) != nil {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), websocketDialTimeout)
+			if err := s.p2pServer.DialWebsocket(ctx, server.node, server.url); err != nil {
+				log.Debug("Failed to dial tunneled light server", "id", server.node.ID(), "url", server.url, "err", err)
+			}
+			cancel()
+		}
+		select {
+		case <-ticker.C:
+		case <-s.closeCh:
+			return
+		}
+	}
+}
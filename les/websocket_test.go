@@ -0,0 +1,50 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+)
+
+func TestParseWebsocketServer(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	id := fmt.Sprintf("%x", crypto.FromECDSAPub(&key.PublicKey)[1:])
+
+	server, err := parseWebsocketServer("wss://" + id + "@example.org:8546/les")
+	if err != nil {
+		t.Fatalf("failed to parse server: %v", err)
+	}
+	if server.url != "wss://example.org:8546/les" {
+		t.Errorf("url mismatch: have %s, want %s", server.url, "wss://example.org:8546/les")
+	}
+	if want := enode.PubkeyToIDV4(&key.PublicKey); server.node.ID() != want {
+		t.Errorf("node id mismatch: have %v, want %v", server.node.ID(), want)
+	}
+	for _, invalid := range []string{
+		"https://" + id + "@example.org/les", // wrong scheme
+		"wss://example.org/les",              // missing node id
+		"wss://abcd@example.org/les",         // invalid node id
+	} {
+		if _, err := parseWebsocketServer(invalid); err == nil {
+			t.Errorf("%s: expected parse error", invalid)
+		}
+	}
+}
@@ -21,6 +21,7 @@ package light
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 	"sync/atomic"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/consensus"
+	"github.com/c88032111/go-gdtu/consensus/clique"
 	"github.com/c88032111/go-gdtu/core"
 	"github.com/c88032111/go-gdtu/core/rawdb"
 	"github.com/c88032111/go-gdtu/core/state"
@@ -171,13 +173,19 @@ func (lc *LightChain) loadLastState() error {
 }
 
 // SetHead rewinds the local chain to a new head. Everything above the new
-// head will be deleted and the new one set.
+// head will be deleted and the new one set. A head event is fired for the new
+// head.
 func (lc *LightChain) SetHead(head uint64) error {
 	lc.chainmu.Lock()
-	defer lc.chainmu.Unlock()
-
 	lc.hc.SetHead(head, nil, nil)
-	return lc.loadLastState()
+	err := lc.loadLastState()
+	lc.chainmu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	lc.chainHeadFeed.Send(core.ChainHeadEvent{Block: types.NewBlockWithHeader(lc.CurrentHeader())})
+	return nil
 }
 
 // GasLimit returns the gas limit of the current HEAD block.
@@ -535,6 +543,75 @@ func (lc *LightChain) SyncCheckpoint(ctx context.Context, checkpoint *params.Tru
 	return false
 }
 
+// InsertEpochProof fast-forwards a clique chain to an epoch checkpoint, skipping
+// the headers since the previous checkpoint. The proof consists of the checkpoint
+// header followed by consecutive headers built on top of it, which must have been
+// signed by a majority of the signers of the previous checkpoint, which must be
+// present in the local chain. The total difficulty of the checkpoint can't be
+// proven, so it's only checked to be in the range possible for a clique chain.
+func (lc *LightChain) InsertEpochProof(headers []*types.Header, td *big.Int) error {
+	config := lc.hc.Config().Clique
+	if config == nil {
+		return errors.New("epoch proofs are only supported by clique chains")
+	}
+	if len(headers) == 0 || td == nil {
+		return errors.New("empty epoch proof")
+	}
+	var (
+		checkpoint = headers[0]
+		number     = checkpoint.Number.Uint64()
+	)
+	if number < config.Epoch {
+		return fmt.Errorf("block #%d is not an epoch checkpoint", number)
+	}
+	lc.chainmu.Lock()
+	defer lc.chainmu.Unlock()
+
+	// Ensure the proof advances the chain and verify it against the signers of the
+	// previous checkpoint
+	if lc.hc.CurrentHeader().Number.Uint64() >= number {
+		return nil
+	}
+	prev := lc.hc.GetHeaderByNumber(number - config.Epoch)
+	if prev == nil {
+		return fmt.Errorf("missing previous epoch checkpoint #%d", number-config.Epoch)
+	}
+	trusted, err := clique.CheckpointSigners(prev)
+	if err != nil {
+		return err
+	}
+	if _, err := clique.VerifyEpochProof(config, trusted, headers); err != nil {
+		return err
+	}
+	// Each clique block has a difficulty of either 1 or 2, bound the claimed td
+	prevTd := lc.hc.GetTd(prev.Hash(), prev.Number.Uint64())
+	if prevTd == nil {
+		return fmt.Errorf("missing total difficulty of epoch checkpoint #%d", prev.Number)
+	}
+	var (
+		minTd = new(big.Int).Add(prevTd, new(big.Int).SetUint64(config.Epoch))
+		maxTd = new(big.Int).Add(prevTd, new(big.Int).SetUint64(2*config.Epoch))
+	)
+	if td.Cmp(minTd) < 0 || td.Cmp(maxTd) > 0 {
+		return fmt.Errorf("invalid total difficulty %v for epoch checkpoint #%d", td, number)
+	}
+	// Proof verified, update the head to the new checkpoint
+	hash := checkpoint.Hash()
+
+	batch := lc.chainDb.NewBatch()
+	rawdb.WriteHeader(batch, checkpoint)
+	rawdb.WriteTd(batch, hash, number, td)
+	rawdb.WriteCanonicalHash(batch, hash, number)
+	rawdb.WriteHeadHeaderHash(batch, hash)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	lc.hc.SetCurrentHeader(checkpoint)
+
+	log.Info("Updated latest header based on epoch proof", "number", number, "hash", hash, "age", common.PrettyAge(time.Unix(int64(checkpoint.Time), 0)))
+	return nil
+}
+
 // LockChain locks the chain mutex for reading so that multiple canonical hashes can be
 // retrieved while it is guaranteed that they belgdtu to the same version of the chain
 func (lc *LightChain) LockChain() {
@@ -18,15 +18,18 @@ package light
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"math/big"
 	"testing"
 
 	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/consensus/clique"
 	"github.com/c88032111/go-gdtu/consensus/gdtuash"
 	"github.com/c88032111/go-gdtu/core"
 	"github.com/c88032111/go-gdtu/core/rawdb"
 	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/c88032111/go-gdtu/gdtudb"
 	"github.com/c88032111/go-gdtu/params"
 )
@@ -353,3 +356,78 @@ func TestReorgBadHeaderHashes(t *testing.T) {
 		t.Errorf("last header hash mismatch: have: %x, want %x", ncm.CurrentHeader().Hash(), headers[2].Hash())
 	}
 }
+
+// makeCliqueHeaders creates a chain of n clique headers on top of parent, each
+// signed by the given key. Epoch checkpoints embed the signer of the key.
+func makeCliqueHeaders(config *params.CliqueConfig, parent *types.Header, n int, key *ecdsa.PrivateKey) []*types.Header {
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	headers := make([]*types.Header, n)
+	for i := 0; i < n; i++ {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			Difficulty: big.NewInt(2),
+			Time:       parent.Time + 1,
+			Extra:      make([]byte, 32),
+		}
+		if header.Number.Uint64()%config.Epoch == 0 {
+			header.Extra = append(header.Extra, signer[:]...)
+		}
+		header.Extra = append(header.Extra, make([]byte, crypto.SignatureLength)...)
+		sig, _ := crypto.Sign(clique.SealHash(header).Bytes(), key)
+		copy(header.Extra[len(header.Extra)-crypto.SignatureLength:], sig)
+
+		headers[i], parent = header, header
+	}
+	return headers
+}
+
+// Tests that epoch proofs fast-forward a clique light chain from checkpoint to
+// checkpoint, but only if signed by the trusted signers.
+func TestInsertEpochProof(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		other, _ = crypto.GenerateKey()
+		signer   = crypto.PubkeyToAddress(key.PublicKey)
+		db       = rawdb.NewMemoryDatabase()
+		config   = *params.AllCliqueProtocolChanges
+	)
+	config.Clique = &params.CliqueConfig{Period: 0, Epoch: 4}
+
+	gspec := &core.Genesis{
+		Config:     &config,
+		Difficulty: big.NewInt(1),
+		ExtraData:  append(append(make([]byte, 32), signer[:]...), make([]byte, crypto.SignatureLength)...),
+	}
+	genesis := gspec.MustCommit(db)
+	lc, err := NewLightChain(&dummyOdr{db: db}, gspec.Config, clique.New(config.Clique, db), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := makeCliqueHeaders(config.Clique, genesis.Header(), 8, key)
+	forged := makeCliqueHeaders(config.Clique, genesis.Header(), 8, other)
+
+	// Proofs signed by untrusted signers or with out of range difficulties must be rejected
+	if err := lc.InsertEpochProof(forged[3:4], big.NewInt(9)); err == nil {
+		t.Fatalf("forged proof accepted")
+	}
+	if err := lc.InsertEpochProof(headers[3:4], big.NewInt(10)); err == nil {
+		t.Fatalf("proof with invalid td accepted")
+	}
+	if err := lc.InsertEpochProof(headers[7:8], big.NewInt(17)); err == nil {
+		t.Fatalf("proof without previous checkpoint accepted")
+	}
+	// Valid proofs should advance the chain checkpoint by checkpoint
+	for _, number := range []uint64{4, 8} {
+		if err := lc.InsertEpochProof(headers[number-1:number], big.NewInt(int64(2*number+1))); err != nil {
+			t.Fatalf("failed to insert proof for #%d: %v", number, err)
+		}
+		if head := lc.CurrentHeader(); head.Hash() != headers[number-1].Hash() {
+			t.Fatalf("head mismatch: have #%d, want #%d", head.Number, number)
+		}
+		if td := lc.GetTd(headers[number-1].Hash(), number); td == nil || td.Uint64() != 2*number+1 {
+			t.Fatalf("td mismatch for #%d: have %v, want %d", number, td, 2*number+1)
+		}
+	}
+}
@@ -176,6 +176,44 @@ func (req *BloomRequest) StoreResult(db gdtudb.Database) {
 	}
 }
 
+// BloomBatchRequest is the ODR request type for retrieving the bloom filters of
+// several bits in the same sections from a bloom trie in a single round trip.
+type BloomBatchRequest struct {
+	OdrRequest
+	Config           *IndexerConfig
+	BloomTrieNum     uint64
+	BitIdxList       []uint
+	SectionIndexList []uint64
+	BloomTrieRoot    common.Hash
+	BloomBits        [][][]byte // Compressed bit vectors indexed by bit and section
+	Proofs           *NodeSet
+}
+
+// StoreResult stores the retrieved data in local database
+func (req *BloomBatchRequest) StoreResult(db gdtudb.Database) {
+	for j, sectionIdx := range req.SectionIndexList {
+		sectionHead := rawdb.ReadCanonicalHash(db, (sectionIdx+1)*req.Config.BloomSize-1)
+		for i, bitIdx := range req.BitIdxList {
+			rawdb.WriteBloomBits(db, bitIdx, sectionIdx, sectionHead, req.BloomBits[i][j])
+		}
+	}
+}
+
+// LogsRequest is the ODR request type for filtering the logs of a range of
+// canonical blocks on the serving side. The server may filter fewer blocks than
+// requested, Count tells how many were filtered starting from FromBlock.
+type LogsRequest struct {
+	OdrRequest
+	FromBlock, ToBlock uint64
+	Addresses          []common.Address
+	Topics             [][]common.Hash
+	Logs               []*types.Log // Matching logs of the filtered blocks
+	Count              uint64       // Number of blocks filtered
+}
+
+// StoreResult stores the retrieved data in local database
+func (req *LogsRequest) StoreResult(db gdtudb.Database) {}
+
 // TxStatus describes the status of a transaction
 type TxStatus struct {
 	Status core.TxStatus
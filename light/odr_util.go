@@ -269,6 +269,65 @@ func GetBloomBits(ctx context.Context, odr OdrBackend, bit uint, sections []uint
 	return result, nil
 }
 
+// GetBloomBitsBatch retrieves the compressed bloomBits vectors of several bit
+// indexes in the given sections, indexed by bit and section. The vectors missing
+// from the local database are retrieved in a single request.
+func GetBloomBitsBatch(ctx context.Context, odr OdrBackend, bits []uint, sections []uint64) ([][][]byte, error) {
+	var (
+		db     = odr.Database()
+		result = make([][][]byte, len(bits))
+
+		reqBits     []uint
+		reqBitIdx   []int
+		reqSections []uint64
+		reqSecIdx   []int
+		missing     = make([]bool, len(sections))
+	)
+	blooms, _, sectionHead := odr.BloomTrieIndexer().Sections()
+	for i, bit := range bits {
+		result[i] = make([][]byte, len(sections))
+		complete := true
+		for j, section := range sections {
+			sectionHead := rawdb.ReadCanonicalHash(db, (section+1)*odr.IndexerConfig().BloomSize-1)
+			if bloomBits, _ := rawdb.ReadBloomBits(db, bit, section, sectionHead); len(bloomBits) != 0 {
+				result[i][j] = bloomBits
+				continue
+			}
+			if section >= blooms {
+				return nil, errNoTrustedBloomTrie
+			}
+			complete, missing[j] = false, true
+		}
+		if !complete {
+			reqBits, reqBitIdx = append(reqBits, bit), append(reqBitIdx, i)
+		}
+	}
+	if reqBits == nil {
+		return result, nil
+	}
+	for j, section := range sections {
+		if missing[j] {
+			reqSections, reqSecIdx = append(reqSections, section), append(reqSecIdx, j)
+		}
+	}
+	r := &BloomBatchRequest{
+		BloomTrieRoot:    GetBloomTrieRoot(db, blooms-1, sectionHead),
+		BloomTrieNum:     blooms - 1,
+		BitIdxList:       reqBits,
+		SectionIndexList: reqSections,
+		Config:           odr.IndexerConfig(),
+	}
+	if err := odr.Retrieve(ctx, r); err != nil {
+		return nil, err
+	}
+	for i, bitIdx := range reqBitIdx {
+		for j, secIdx := range reqSecIdx {
+			result[bitIdx][secIdx] = r.BloomBits[i][j]
+		}
+	}
+	return result, nil
+}
+
 // GetTransaction retrieves a canonical transaction by hash and also returns
 // its position in the chain. There is no guarantee in the LES protocol that
 // the mined transaction will be retrieved back for sure because of different
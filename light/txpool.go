@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
@@ -48,6 +49,10 @@ var txPermanent = uint64(500)
 // in a block (mined) or rolled back. There are no queued transactions since we
 // always receive all locally signed transactions in the same order as they are
 // created.
+//
+// A pending transaction may be replaced by another one with the same nonce and
+// a sufficiently bumped gas price. Pending transactions are journaled into the
+// database, so they are relayed again after a restart.
 type TxPool struct {
 	config       *params.ChainConfig
 	signer       types.Signer
@@ -66,6 +71,10 @@ type TxPool struct {
 	pending      map[common.Hash]*types.Transaction   // pending transactions by tx hash
 	mined        map[common.Hash][]*types.Transaction // mined transactions by block hash
 	clearIdx     uint64                               // earliest block nr that can contain mined tx info
+	priceBump    uint64                               // Minimum price bump percentage to replace a pending transaction
+
+	accounts   map[common.Address]map[uint64]*types.Transaction // pending transactions by sender and nonce
+	unverified map[common.Address]struct{}                      // senders of reloaded transactions not yet checked against the chain
 
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
 	eip2718  bool // Fork indicator whether we are in the eip2718 stage.
@@ -102,7 +111,12 @@ func NewTxPool(config *params.ChainConfig, chain *LightChain, relay TxRelayBacke
 		chainDb:     chain.Odr().Database(),
 		head:        chain.CurrentHeader().Hash(),
 		clearIdx:    chain.CurrentHeader().Number.Uint64(),
+		priceBump:   core.DefaultTxPoolConfig.PriceBump,
+		accounts:    make(map[common.Address]map[uint64]*types.Transaction),
+		unverified:  make(map[common.Address]struct{}),
 	}
+	pool.loadJournal()
+
 	// Subscribe events from blockchain
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
 	go pool.eventLoop()
@@ -124,6 +138,9 @@ func (pool *TxPool) GetNonce(ctx context.Context, addr common.Address) (uint64,
 	if state.Error() != nil {
 		return 0, state.Error()
 	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
 	sn, ok := pool.nonce[addr]
 	if ok && sn > nonce {
 		nonce = sn
@@ -134,6 +151,98 @@ func (pool *TxPool) GetNonce(ctx context.Context, addr common.Address) (uint64,
 	return nonce, nil
 }
 
+// track adds a transaction to the pending set, raising the pending nonce of its
+// sender if necessary.
+func (pool *TxPool) track(tx *types.Transaction) {
+	from, _ := types.Sender(pool.signer, tx)
+
+	pool.pending[tx.Hash()] = tx
+	if pool.accounts[from] == nil {
+		pool.accounts[from] = make(map[uint64]*types.Transaction)
+	}
+	pool.accounts[from][tx.Nonce()] = tx
+
+	if nonce := tx.Nonce() + 1; nonce > pool.nonce[from] {
+		pool.nonce[from] = nonce
+	}
+}
+
+// untrack removes a transaction from the pending set, lowering the pending nonce
+// of its sender to that of the remaining pending transactions. The nonce in the
+// chain state is used once none remain.
+func (pool *TxPool) untrack(hash common.Hash) {
+	tx := pool.pending[hash]
+	if tx == nil {
+		return
+	}
+	delete(pool.pending, hash)
+
+	from, _ := types.Sender(pool.signer, tx)
+	if txs := pool.accounts[from]; txs[tx.Nonce()] == tx {
+		delete(txs, tx.Nonce())
+	}
+	var nonce uint64
+	for _, tx := range pool.accounts[from] {
+		if tx.Nonce()+1 > nonce {
+			nonce = tx.Nonce() + 1
+		}
+	}
+	if nonce == 0 {
+		delete(pool.accounts, from)
+		delete(pool.nonce, from)
+		return
+	}
+	pool.nonce[from] = nonce
+}
+
+// loadJournal reloads the pending transactions journaled before a restart and
+// passes them to the tx relay backend again. The ones included in the chain in
+// the meantime are dropped once the chain is checked at the next head.
+func (pool *TxPool) loadJournal() {
+	var txs types.Transactions
+	for _, tx := range rawdb.ReadLocalTransactions(pool.chainDb) {
+		from, err := types.Sender(pool.signer, tx)
+		if err != nil || pool.accounts[from][tx.Nonce()] != nil {
+			rawdb.DeleteLocalTransaction(pool.chainDb, tx.Hash())
+			continue
+		}
+		pool.track(tx)
+		pool.unverified[from] = struct{}{}
+		txs = append(txs, tx)
+	}
+	if len(txs) > 0 {
+		log.Info("Loaded local transaction journal", "transactions", len(txs))
+		pool.relay.Send(txs)
+	}
+}
+
+// pruneJournal drops the reloaded transactions which were included in the chain,
+// or superseded by another client using the same key, while the pool was not
+// running. Senders which couldn't be checked are retried at the next head.
+func (pool *TxPool) pruneJournal(ctx context.Context) {
+	state := pool.currentState(ctx)
+	for from := range pool.unverified {
+		nonce := state.GetNonce(from)
+		if state.Error() != nil {
+			return
+		}
+		var stale []common.Hash
+		for _, tx := range pool.accounts[from] {
+			if tx.Nonce() < nonce {
+				stale = append(stale, tx.Hash())
+			}
+		}
+		for _, hash := range stale {
+			pool.untrack(hash)
+			rawdb.DeleteLocalTransaction(pool.chainDb, hash)
+		}
+		if len(stale) > 0 {
+			pool.relay.Discard(stale)
+		}
+		delete(pool.unverified, from)
+	}
+}
+
 // txStateChanges stores the recent changes between pending/mined states of
 // transactions. True means mined, false means rolled back, no entry means no change
 type txStateChanges map[common.Hash]bool
@@ -189,7 +298,7 @@ func (pool *TxPool) checkMinedTxs(ctx context.Context, hash common.Hash, number
 
 		// Update the transaction pool's state
 		for _, tx := range list {
-			delete(pool.pending, tx.Hash())
+			pool.untrack(tx.Hash())
 			txc.setState(tx.Hash(), true)
 		}
 		pool.mined[hash] = list
@@ -205,7 +314,7 @@ func (pool *TxPool) rollbackTxs(hash common.Hash, txc txStateChanges) {
 		for _, tx := range list {
 			txHash := tx.Hash()
 			rawdb.DeleteTxLookupEntry(batch, txHash)
-			pool.pending[txHash] = tx
+			pool.track(tx)
 			txc.setState(txHash, false)
 		}
 		delete(pool.mined, hash)
@@ -222,6 +331,13 @@ func (pool *TxPool) rollbackTxs(hash common.Hash, txc txStateChanges) {
 func (pool *TxPool) reorgOnNewHead(ctx context.Context, newHeader *types.Header) (txStateChanges, error) {
 	txc := make(txStateChanges)
 	oldh := pool.chain.GetHeaderByHash(pool.head)
+	if oldh == nil {
+		// The old head was deleted by a chain rewind, the transactions mined in
+		// the deleted blocks can't be traced any more
+		log.Warn("Transaction pool head discarded, skipping reorg", "old", pool.head, "new", newHeader.Hash())
+		pool.head = newHeader.Hash()
+		return txc, nil
+	}
 	newh := newHeader
 	// find common ancestor, create list of rolled back and new block hashes
 	var oldHashes, newHashes []common.Hash
@@ -266,6 +382,7 @@ func (pool *TxPool) reorgOnNewHead(ctx context.Context, newHeader *types.Header)
 					hashes := make([]common.Hash, len(list))
 					for i, tx := range list {
 						hashes[i] = tx.Hash()
+						rawdb.DeleteLocalTransaction(pool.chainDb, hashes[i])
 					}
 					pool.relay.Discard(hashes)
 					delete(pool.mined, hash)
@@ -308,6 +425,9 @@ func (pool *TxPool) setNewHead(head *types.Header) {
 	defer cancel()
 
 	txc, _ := pool.reorgOnNewHead(ctx, head)
+	if len(pool.unverified) > 0 {
+		pool.pruneJournal(ctx)
+	}
 	m, r := txc.getLists()
 	pool.relay.NewHead(pool.head, m, r)
 
@@ -393,7 +513,9 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 }
 
 // add validates a new transaction and sets its state pending if processable.
-// It also updates the locally stored nonce if necessary.
+// A pending transaction with the same nonce is replaced if the new one bumps
+// the gas price enough. It also updates the locally stored nonce if necessary
+// and journals the transaction.
 func (pool *TxPool) add(ctx context.Context, tx *types.Transaction) error {
 	hash := tx.Hash()
 
@@ -404,16 +526,22 @@ func (pool *TxPool) add(ctx context.Context, tx *types.Transaction) error {
 	if err != nil {
 		return err
 	}
-
-	if _, ok := pool.pending[hash]; !ok {
-		pool.pending[hash] = tx
-
-		nonce := tx.Nonce() + 1
-
-		addr, _ := types.Sender(pool.signer, tx)
-		if nonce > pool.nonce[addr] {
-			pool.nonce[addr] = nonce
+	from, _ := types.Sender(pool.signer, tx)
+	if old := pool.accounts[from][tx.Nonce()]; old != nil {
+		// threshold = oldGP * (100 + priceBump) / 100
+		threshold := new(big.Int).Mul(old.GasPrice(), big.NewInt(100+int64(pool.priceBump)))
+		threshold.Div(threshold, big.NewInt(100))
+		if old.GasPriceCmp(tx) >= 0 || tx.GasPriceIntCmp(threshold) < 0 {
+			return core.ErrReplaceUnderpriced
 		}
+		pool.untrack(old.Hash())
+		rawdb.DeleteLocalTransaction(pool.chainDb, old.Hash())
+		pool.relay.Discard([]common.Hash{old.Hash()})
+		log.Debug("Replaced pending transaction", "old", old.Hash(), "new", hash, "nonce", tx.Nonce())
+	}
+	if _, ok := pool.pending[hash]; !ok {
+		pool.track(tx)
+		rawdb.WriteLocalTransaction(pool.chainDb, tx)
 
 		// Notify the subscribers. This event is posted in a goroutine
 		// because it's possible that somewhere during the post "Remove transaction"
@@ -431,18 +559,11 @@ func (pool *TxPool) add(ctx context.Context, tx *types.Transaction) error {
 func (pool *TxPool) Add(ctx context.Context, tx *types.Transaction) error {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
-	data, err := tx.MarshalBinary()
-	if err != nil {
-		return err
-	}
 
 	if err := pool.add(ctx, tx); err != nil {
 		return err
 	}
-	//fmt.Println("Send", tx.Hash())
 	pool.relay.Send(types.Transactions{tx})
-
-	pool.chainDb.Put(tx.Hash().Bytes(), data)
 	return nil
 }
 
@@ -505,6 +626,27 @@ func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common
 	return pending, queued
 }
 
+// ContentFrom retrieves the data content of the transaction pool, returning the
+// pending as well as queued transactions of this address, sorted by nonce.
+func (pool *TxPool) ContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	// Retrieve the pending transactions and sort by nonce
+	var pending types.Transactions
+	for _, tx := range pool.pending {
+		account, _ := types.Sender(pool.signer, tx)
+		if account != addr {
+			continue
+		}
+		pending = append(pending, tx)
+	}
+	sort.Sort(types.TxByNonce(pending))
+
+	// There are no queued transactions in a light pool, just return an empty list
+	return pending, types.Transactions{}
+}
+
 // RemoveTransactions removes all given transactions from the pool.
 func (pool *TxPool) RemoveTransactions(txs types.Transactions) {
 	pool.mu.Lock()
@@ -514,8 +656,8 @@ func (pool *TxPool) RemoveTransactions(txs types.Transactions) {
 	batch := pool.chainDb.NewBatch()
 	for _, tx := range txs {
 		hash := tx.Hash()
-		delete(pool.pending, hash)
-		batch.Delete(hash.Bytes())
+		pool.untrack(hash)
+		rawdb.DeleteLocalTransaction(batch, hash)
 		hashes = append(hashes, hash)
 	}
 	batch.Write()
@@ -527,7 +669,7 @@ func (pool *TxPool) RemoveTx(hash common.Hash) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 	// delete from pending pool
-	delete(pool.pending, hash)
-	pool.chainDb.Delete(hash[:])
+	pool.untrack(hash)
+	rawdb.DeleteLocalTransaction(pool.chainDb, hash)
 	pool.relay.Discard([]common.Hash{hash})
 }
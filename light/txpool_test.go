@@ -141,3 +141,110 @@ func TestTxPool(t *testing.T) {
 		}
 	}
 }
+
+// Tests that pending transactions can be replaced with a sufficient price bump,
+// that the pending nonces follow them, and that they are reloaded from the
+// journal after a restart until included in the chain.
+func TestTxPoolReplacementAndJournal(t *testing.T) {
+	newTx := func(nonce uint64, price int64) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, acc1Addr, big.NewInt(10000), params.TxGas, big.NewInt(price), nil), types.HomesteadSigner{}, testBankKey)
+		return tx
+	}
+	var (
+		original    = newTx(0, 100)
+		underpriced = newTx(0, 105)
+		replacement = newTx(0, 110)
+		next        = newTx(1, 100)
+
+		sdb     = rawdb.NewMemoryDatabase()
+		ldb     = rawdb.NewMemoryDatabase()
+		gspec   = core.Genesis{Alloc: core.GenesisAlloc{testBankAddress: {Balance: testBankFunds}}}
+		genesis = gspec.MustCommit(sdb)
+	)
+	gspec.MustCommit(ldb)
+
+	// Create a chain including the replacement in the first block
+	blockchain, _ := core.NewBlockChain(sdb, nil, params.TestChainConfig, gdtuash.NewFullFaker(), vm.Config{}, nil, nil)
+	gchain, _ := core.GenerateChain(params.TestChainConfig, genesis, gdtuash.NewFaker(), sdb, 2, func(i int, block *core.BlockGen) {
+		if i == 0 {
+			block.AddTx(replacement)
+		}
+	})
+	if _, err := blockchain.InsertChain(gchain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	var (
+		odr   = &testOdr{sdb: sdb, ldb: ldb, indexerConfig: TestClientIndexerConfig}
+		relay = &testTxRelay{
+			send:    make(chan int, 10),
+			discard: make(chan int, 10),
+			mined:   make(chan int, 10),
+		}
+		ctx = context.Background()
+	)
+	lightchain, _ := NewLightChain(odr, params.TestChainConfig, gdtuash.NewFullFaker(), nil)
+	pool := NewTxPool(params.TestChainConfig, lightchain, relay)
+
+	checkNonce := func(want uint64) {
+		t.Helper()
+		if nonce, err := pool.GetNonce(ctx, testBankAddress); err != nil || nonce != want {
+			t.Fatalf("pending nonce mismatch: have %d (%v), want %d", nonce, err, want)
+		}
+	}
+	if err := pool.Add(ctx, original); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if err := pool.Add(ctx, next); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	checkNonce(2)
+
+	if err := pool.Add(ctx, underpriced); err != core.ErrReplaceUnderpriced {
+		t.Fatalf("underpriced replacement error mismatch: have %v, want %v", err, core.ErrReplaceUnderpriced)
+	}
+	if err := pool.Add(ctx, replacement); err != nil {
+		t.Fatalf("failed to replace transaction: %v", err)
+	}
+	if pool.GetTransaction(original.Hash()) != nil || pool.GetTransaction(replacement.Hash()) == nil {
+		t.Fatalf("transaction not replaced")
+	}
+	if n := <-relay.discard; n != 1 {
+		t.Fatalf("discarded transaction count mismatch: have %d, want 1", n)
+	}
+	pool.RemoveTx(next.Hash())
+	<-relay.discard
+	checkNonce(1)
+	pool.Stop()
+
+	// Include the replacement while the pool is not running, then restart it
+	if _, err := lightchain.InsertHeaderChain([]*types.Header{gchain[0].Header()}, 1); err != nil {
+		t.Fatalf("failed to insert header: %v", err)
+	}
+	for len(relay.send) > 0 {
+		<-relay.send
+	}
+	pool = NewTxPool(params.TestChainConfig, lightchain, relay)
+	defer pool.Stop()
+
+	if n := <-relay.send; n != 1 {
+		t.Fatalf("reloaded transaction count mismatch: have %d, want 1", n)
+	}
+	if pool.GetTransaction(replacement.Hash()) == nil {
+		t.Fatalf("replacement not reloaded from the journal")
+	}
+	// The included transaction is dropped once the chain is checked
+	if _, err := lightchain.InsertHeaderChain([]*types.Header{gchain[1].Header()}, 1); err != nil {
+		t.Fatalf("failed to insert header: %v", err)
+	}
+	select {
+	case <-relay.discard:
+	case <-time.After(time.Second):
+		t.Fatalf("included transaction not dropped")
+	}
+	if pending := pool.Stats(); pending != 0 {
+		t.Fatalf("pending transaction count mismatch: have %d, want 0", pending)
+	}
+	if txs := rawdb.ReadLocalTransactions(ldb); len(txs) != 0 {
+		t.Fatalf("journaled transaction count mismatch: have %d, want 0", len(txs))
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/rpc"
+)
+
+// defaultBuilderTimeout is the time the worker waits for an external payload
+// builder if none is configured.
+const defaultBuilderTimeout = time.Second
+
+// PayloadArgs describes the block an external builder is asked to fill.
+type PayloadArgs struct {
+	ParentHash common.Hash    `json:"parentHash"`
+	Number     hexutil.Uint64 `json:"number"`
+	Timestamp  hexutil.Uint64 `json:"timestamp"`
+	Coinbase   common.Address `json:"coinbase"`
+	GasLimit   hexutil.Uint64 `json:"gasLimit"`
+}
+
+// Payload is the ordered list of transactions an external builder proposes for
+// a block, in their binary encoding.
+type Payload struct {
+	Transactions []hexutil.Bytes `json:"transactions"`
+}
+
+// PayloadBuilder is implemented by external block builders assembling the
+// transactions of the mined blocks in place of the transaction pool.
+//
+// The worker executes the returned payload on top of the parent state, and falls
+// back to building the block locally if the builder fails, times out or proposes
+// an invalid payload.
+type PayloadBuilder interface {
+	BuildPayload(ctx context.Context, args *PayloadArgs) (*Payload, error)
+}
+
+// rpcPayloadBuilder requests payloads from a builder over RPC, through its
+// builder_buildPayload Method.
+type rpcPayloadBuilder struct {
+	url    string
+	lock   sync.Mutex
+	client *rpc.Client
+}
+
+// NewRPCPayloadBuilder creates a payload builder requesting the payloads from
+// the RPC endpoint at the given URL. The connection is established on demand.
+func NewRPCPayloadBuilder(url string) PayloadBuilder {
+	return &rpcPayloadBuilder{url: url}
+}
+
+// BuildPayload implements PayloadBuilder, requesting a payload from the remote
+// builder. The connection is dropped on failure and reestablished on the next
+// request.
+func (b *rpcPayloadBuilder) BuildPayload(ctx context.Context, args *PayloadArgs) (*Payload, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.client == nil {
+		client, err := rpc.DialContext(ctx, b.url)
+		if err != nil {
+			return nil, err
+		}
+		b.client = client
+	}
+	var payload *Payload
+	if err := b.client.CallContext(ctx, &payload, "builder_buildPayload", args); err != nil {
+		b.client.Close()
+		b.client = nil
+		return nil, err
+	}
+	if payload == nil {
+		return nil, errors.New("empty payload")
+	}
+	return payload, nil
+}
+
+// decodePayload decodes the transactions of a payload.
+func decodePayload(payload *Payload) ([]*types.Transaction, error) {
+	txs := make([]*types.Transaction, len(payload.Transactions))
+	for i, enc := range payload.Transactions {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(enc); err != nil {
+			return nil, fmt.Errorf("transaction %d: %v", i, err)
+		}
+		txs[i] = tx
+	}
+	return txs, nil
+}
@@ -18,6 +18,8 @@
 package miner
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -50,6 +52,22 @@ type Config struct {
 	GasPrice  *big.Int       // Minimum gas price for mining a transaction
 	Recommit  time.Duration  // The time interval for miner to re-create mining work.
 	Noverify  bool           // Disable remote mining solution verification(only useful in gdtuash).
+
+	GasLimitStrategy core.GasLimitStrategy `toml:"-"` // Strategy targeting the gas floor and ceil (nil = core.DefaultGasLimitStrategy)
+
+	Builder        string         `toml:",omitempty"` // RPC endpoint of an external payload builder (empty = build blocks locally)
+	BuilderTimeout time.Duration  `toml:",omitempty"` // Time to wait for the external payload builder before building locally
+	PayloadBuilder PayloadBuilder `toml:"-"`          // External payload builder, overriding the Builder endpoint
+}
+
+// mineRetryInterval is the time to wait for a requested block to be sealed before
+// requesting it again.
+const mineRetryInterval = time.Second
+
+// emptyBlockSealer is implemented by consensus engines only sealing empty blocks
+// on request, such as clique with a 0 block period.
+type emptyBlockSealer interface {
+	AllowEmptyBlocks(n uint32)
 }
 
 // Miner creates blocks and searches for proof-of-work values.
@@ -64,6 +82,9 @@ type Miner struct {
 	stopCh   chan struct{}
 }
 
+// New creates a miner. The mined blocks are relayed to the event mux, and the
+// downloader events posted to it pause mining during synchronisation. Posting
+// mined blocks to the mux is deprecated in favour of SubscribeNewMinedBlockEvent.
 func New(gdtu Backend, config *Config, chainConfig *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, isLocalBlock func(block *types.Block) bool) *Miner {
 	miner := &Miner{
 		gdtu:     gdtu,
@@ -159,6 +180,61 @@ func (miner *Miner) Mining() bool {
 	return miner.worker.isRunning()
 }
 
+// MineBlocks seals the given number of blocks on top of the current head, even if
+// there are no transactions to include, returning them once they are imported.
+// Engines sealing empty blocks on request are asked to seal each block right
+// away, for other engines the sealed blocks are only awaited. Mining must be
+// running.
+func (miner *Miner) MineBlocks(ctx context.Context, n int) ([]*types.Block, error) {
+	if !miner.Mining() {
+		return nil, errors.New("mining is not running")
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+	sealer, _ := miner.engine.(emptyBlockSealer)
+	if sealer != nil {
+		defer sealer.AllowEmptyBlocks(0)
+	}
+	heads := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	sub := miner.gdtu.BlockChain().SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	// Request all blocks at once, the worker seals the next one as soon as the
+	// previous one is imported. The request is repeated if sealing stalls, e.g.
+	// because an allowed empty block was superseded by new work.
+	blocks := make([]*types.Block, 0, n)
+	request := func() {
+		if sealer != nil {
+			sealer.AllowEmptyBlocks(uint32(n - len(blocks)))
+			miner.worker.commitNow()
+		}
+	}
+	request()
+
+	retry := time.NewTimer(mineRetryInterval)
+	defer retry.Stop()
+
+	for len(blocks) < n {
+		select {
+		case head := <-heads:
+			blocks = append(blocks, head.Block)
+			if !retry.Stop() {
+				<-retry.C
+			}
+			retry.Reset(mineRetryInterval)
+		case <-retry.C:
+			request()
+			retry.Reset(mineRetryInterval)
+		case err := <-sub.Err():
+			return blocks, err
+		case <-ctx.Done():
+			return blocks, ctx.Err()
+		}
+	}
+	return blocks, nil
+}
+
 func (miner *Miner) HashRate() uint64 {
 	if pow, ok := miner.engine.(consensus.PoW); ok {
 		return uint64(pow.Hashrate())
@@ -174,6 +250,28 @@ func (miner *Miner) SetExtra(extra []byte) error {
 	return nil
 }
 
+// SetGasLimits updates the gas floor and ceil targeted by the gas limit of the
+// mined blocks.
+func (miner *Miner) SetGasLimits(floor, ceil uint64) error {
+	if floor > ceil {
+		return fmt.Errorf("gas floor %d above ceil %d", floor, ceil)
+	}
+	miner.worker.setGasLimits(floor, ceil)
+	return nil
+}
+
+// SetGasLimitStrategy replaces the strategy computing the gas limit of the mined
+// blocks. A nil strategy restores the default one.
+func (miner *Miner) SetGasLimitStrategy(strategy core.GasLimitStrategy) {
+	miner.worker.setGasLimitStrategy(strategy)
+}
+
+// SetPayloadBuilder replaces the external builder of the mined block payloads.
+// A nil builder restores building the blocks from the local transaction pool.
+func (miner *Miner) SetPayloadBuilder(builder PayloadBuilder) {
+	miner.worker.setPayloadBuilder(builder)
+}
+
 // SetRecommitInterval sets the interval for sealing work resubmitting.
 func (miner *Miner) SetRecommitInterval(interval time.Duration) {
 	miner.worker.setRecommitInterval(interval)
@@ -220,3 +318,9 @@ func (miner *Miner) DisablePreseal() {
 func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscription {
 	return miner.worker.pendingLogsFeed.Subscribe(ch)
 }
+
+// SubscribeNewMinedBlockEvent starts delivering the blocks sealed and written
+// into the chain by the miner to the given channel.
+func (miner *Miner) SubscribeNewMinedBlockEvent(ch chan<- core.NewMinedBlockEvent) event.Subscription {
+	return miner.worker.minedFeed.Subscribe(ch)
+}
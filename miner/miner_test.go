@@ -18,6 +18,7 @@
 package miner
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/c88032111/go-gdtu/event"
 	"github.com/c88032111/go-gdtu/gdtu/downloader"
 	"github.com/c88032111/go-gdtu/gdtudb/memorydb"
+	"github.com/c88032111/go-gdtu/params"
 	"github.com/c88032111/go-gdtu/trie"
 )
 
@@ -60,6 +62,10 @@ type testBlockChain struct {
 	chainHeadFeed *event.Feed
 }
 
+func (bc *testBlockChain) Config() *params.ChainConfig {
+	return params.TestChainConfig
+}
+
 func (bc *testBlockChain) CurrentBlock() *types.Block {
 	return types.NewBlock(&types.Header{
 		GasLimit: bc.gasLimit,
@@ -259,3 +265,47 @@ func createMiner(t *testing.T) (*Miner, *event.TypeMux) {
 	// Create Miner
 	return New(backend, &config, chainConfig, mux, engine, nil), mux
 }
+
+func TestMineBlocks(t *testing.T) {
+	// Create a clique chain only sealing blocks with transactions
+	chainConfig := *params.AllCliqueProtocolChanges
+	chainConfig.Clique = &params.CliqueConfig{Period: 0, Epoch: 30000}
+
+	db := rawdb.NewMemoryDatabase()
+	engine := clique.New(chainConfig.Clique, db)
+	backend := newTestWorkerBackend(t, &chainConfig, engine, db, 0)
+	defer backend.chain.Stop()
+
+	miner := New(backend, testConfig, &chainConfig, new(event.TypeMux), engine, nil)
+	defer miner.Close()
+
+	if _, err := miner.MineBlocks(context.Background(), 1); err == nil {
+		t.Fatal("mined blocks without mining running")
+	}
+	miner.Start(testBankAddress)
+	waitForMiningState(t, miner, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	blocks, err := miner.MineBlocks(ctx, 3)
+	if err != nil {
+		t.Fatalf("failed to mine blocks: %v", err)
+	}
+	for i, block := range blocks {
+		if block.NumberU64() != uint64(i+1) {
+			t.Errorf("block %d: number mismatch: have %d, want %d", i, block.NumberU64(), i+1)
+		}
+		if len(block.Transactions()) != 0 {
+			t.Errorf("block %d: have %d transactions, want none", i, len(block.Transactions()))
+		}
+	}
+	if head := backend.chain.CurrentBlock().NumberU64(); head != 3 {
+		t.Fatalf("head mismatch: have %d, want %d", head, 3)
+	}
+	// Without requests, no further empty blocks must be sealed
+	time.Sleep(500 * time.Millisecond)
+	if head := backend.chain.CurrentBlock().NumberU64(); head != 3 {
+		t.Fatalf("empty block sealed without request: head %d", head)
+	}
+}
@@ -18,7 +18,9 @@ package miner
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 	"sync/atomic"
@@ -26,6 +28,7 @@ import (
 
 	mapset "github.com/deckarep/golang-set"
 	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
 	"github.com/c88032111/go-gdtu/consensus"
 	"github.com/c88032111/go-gdtu/consensus/misc"
 	"github.com/c88032111/go-gdtu/core"
@@ -79,6 +82,7 @@ const (
 
 // environment is the worker's current environment and holds all of the current state information.
 type environment struct {
+	config *params.ChainConfig // chain configuration the block is built against
 	signer types.Signer
 
 	state     *state.StateDB // apply state changes here
@@ -131,9 +135,11 @@ type worker struct {
 
 	// Feeds
 	pendingLogsFeed event.Feed
+	minedFeed       event.Feed
 
 	// Subscriptions
-	mux          *event.TypeMux
+	mux          *event.TypeMux     // Legacy event mux, deprecated for minedFeed
+	muxBridge    event.Subscription // Relay of the mined blocks to the legacy event mux
 	txsCh        chan core.NewTxsEvent
 	txsSub       event.Subscription
 	chainHeadCh  chan core.ChainHeadEvent
@@ -155,9 +161,13 @@ type worker struct {
 	remoteUncles map[common.Hash]*types.Block // A set of side blocks as the possible uncle blocks.
 	unconfirmed  *unconfirmedBlocks           // A set of locally mined blocks pending canonicalness confirmations.
 
-	mu       sync.RWMutex // The lock used to protect the coinbase and extra fields
-	coinbase common.Address
-	extra    []byte
+	mu          sync.RWMutex // The lock used to protect the coinbase, extra and gas limit fields
+	coinbase    common.Address
+	extra       []byte
+	gasFloor    uint64
+	gasCeil     uint64
+	gasStrategy core.GasLimitStrategy
+	builder     PayloadBuilder
 
 	pendingMu    sync.RWMutex
 	pendingTasks map[common.Hash]*task
@@ -167,8 +177,9 @@ type worker struct {
 	snapshotState *state.StateDB
 
 	// atomic status counters
-	running int32 // The indicator whether the consensus engine is running or not.
-	newTxs  int32 // New arrival transaction count since last sealing work submitting.
+	running   int32 // The indicator whether the consensus engine is running or not.
+	newTxs    int32 // New arrival transaction count since last sealing work submitting.
+	forceSeal int32 // The indicator whether the next task is sealed even if a duplicate.
 
 	// noempty is the flag used to control whether the feature of pre-seal empty
 	// block is enabled. The default value is false(pre-seal is enabled by default).
@@ -210,6 +221,16 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		startCh:            make(chan struct{}, 1),
 		resubmitIntervalCh: make(chan time.Duration),
 		resubmitAdjustCh:   make(chan *intervalAdjust, resubmitAdjustChanSize),
+		gasFloor:           config.GasFloor,
+		gasCeil:            config.GasCeil,
+		gasStrategy:        config.GasLimitStrategy,
+		builder:            config.PayloadBuilder,
+	}
+	if worker.builder == nil && config.Builder != "" {
+		worker.builder = NewRPCPayloadBuilder(config.Builder)
+	}
+	if mux != nil {
+		worker.muxBridge = event.FeedToMux(&worker.minedFeed, mux, core.NewMinedBlockEvent{})
 	}
 	// Subscribe NewTxsEvent for tx pool
 	worker.txsSub = gdtu.TxPool().SubscribeNewTxsEvent(worker.txsCh)
@@ -250,6 +271,37 @@ func (w *worker) setExtra(extra []byte) {
 	w.extra = extra
 }
 
+// setGasLimits sets the gas floor and ceil targeted by the block gas limit.
+func (w *worker) setGasLimits(floor, ceil uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.gasFloor, w.gasCeil = floor, ceil
+}
+
+// setGasLimitStrategy sets the strategy computing the block gas limit.
+func (w *worker) setGasLimitStrategy(strategy core.GasLimitStrategy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.gasStrategy = strategy
+}
+
+// setPayloadBuilder sets the external builder of the block payloads.
+func (w *worker) setPayloadBuilder(builder PayloadBuilder) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.builder = builder
+}
+
+// gasLimit computes the gas limit of a block built on top of parent. The lock
+// is assumed to be held.
+func (w *worker) gasLimit(parent *types.Block) uint64 {
+	strategy := w.gasStrategy
+	if strategy == nil {
+		strategy = core.DefaultGasLimitStrategy
+	}
+	return core.ClampGasLimit(parent.GasLimit(), strategy.GasLimit(parent, w.gasFloor, w.gasCeil))
+}
+
 // setRecommitInterval updates the interval for miner sealing work recommitting.
 func (w *worker) setRecommitInterval(interval time.Duration) {
 	w.resubmitIntervalCh <- interval
@@ -290,6 +342,16 @@ func (w *worker) start() {
 	w.startCh <- struct{}{}
 }
 
+// commitNow triggers the submission of new work on top of the current head, which
+// is sealed even if identical to the work previously sealed.
+func (w *worker) commitNow() {
+	atomic.StoreInt32(&w.forceSeal, 1)
+	select {
+	case w.startCh <- struct{}{}:
+	default:
+	}
+}
+
 // stop sets the running status as 0.
 func (w *worker) stop() {
 	atomic.StoreInt32(&w.running, 0)
@@ -308,6 +370,9 @@ func (w *worker) close() {
 	}
 	atomic.StoreInt32(&w.running, 0)
 	close(w.exitCh)
+	if w.muxBridge != nil {
+		w.muxBridge.Unsubscribe()
+	}
 }
 
 // recalcRecommit recalculates the resubmitting interval upon feedback.
@@ -551,9 +616,10 @@ func (w *worker) taskLoop() {
 			if w.newTaskHook != nil {
 				w.newTaskHook(task)
 			}
-			// Reject duplicate sealing work due to resubmitting.
+			// Reject duplicate sealing work due to resubmitting, unless a new
+			// sealing attempt was explicitly requested.
 			sealHash := w.engine.SealHash(task.block.Header())
-			if sealHash == prev {
+			if force := atomic.SwapInt32(&w.forceSeal, 0) == 1; sealHash == prev && !force {
 				continue
 			}
 			// Interrupt previous sealing operation
@@ -632,7 +698,7 @@ func (w *worker) resultLoop() {
 				"elapsed", common.PrettyDuration(time.Since(task.createdAt)))
 
 			// Broadcast the block and announce chain insertion event
-			w.mux.Post(core.NewMinedBlockEvent{Block: block})
+			w.minedFeed.Send(core.NewMinedBlockEvent{Block: block})
 
 			// Insert the block into the set of pending ones to resultLoop for confirmations
 			w.unconfirmed.Insert(block.NumberU64(), block.Hash())
@@ -644,7 +710,7 @@ func (w *worker) resultLoop() {
 }
 
 // makeCurrent creates a new environment for the current cycle.
-func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
+func (w *worker) makeCurrent(config *params.ChainConfig, parent *types.Block, header *types.Header) error {
 	// Retrieve the parent state to execute on top and start a prefetcher for
 	// the miner to speed block sealing up a bit
 	state, err := w.chain.StateAt(parent.Root())
@@ -654,7 +720,8 @@ func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 	state.StartPrefetcher("miner")
 
 	env := &environment{
-		signer:    types.MakeSigner(w.chainConfig, header.Number),
+		config:    config,
+		signer:    types.MakeSigner(config, header.Number),
 		state:     state,
 		ancestors: mapset.NewSet(),
 		family:    mapset.NewSet(),
@@ -736,7 +803,7 @@ func (w *worker) updateSnapshot() {
 func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Address) ([]*types.Log, error) {
 	snap := w.current.state.Snapshot()
 
-	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, w.current.gasPool, w.current.state, w.current.header, tx, &w.current.header.GasUsed, *w.chain.GetVMConfig())
+	receipt, err := core.ApplyTransaction(w.current.config, w.chain, &coinbase, w.current.gasPool, w.current.state, w.current.header, tx, &w.current.header.GasUsed, *w.chain.GetVMConfig())
 	if err != nil {
 		w.current.state.RevertToSnapshot(snap)
 		return nil, err
@@ -797,12 +864,22 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		from, _ := types.Sender(w.current.signer, tx)
 		// Check whether the tx is replay protected. If we're not in the EIP155 hf
 		// phase, start ignoring the sender until we do.
-		if tx.Protected() && !w.chainConfig.IsEIP155(w.current.header.Number) {
-			log.Trace("Ignoring reply protected transaction", "hash", tx.Hash(), "eip155", w.chainConfig.EIP155Block)
+		if tx.Protected() && !w.current.config.IsEIP155(w.current.header.Number) {
+			log.Trace("Ignoring reply protected transaction", "hash", tx.Hash(), "eip155", w.current.config.EIP155Block)
 
 			txs.Pop()
 			continue
 		}
+		// Revalidate the local conditions of the transaction against the block
+		// being built, they might have been invalidated by preceding transactions.
+		if cond := tx.Conditional(); cond != nil {
+			if err := cond.Validate(w.current.header.Number, w.current.state); err != nil {
+				log.Trace("Skipping transaction with failed conditions", "hash", tx.Hash(), "err", err)
+
+				txs.Pop()
+				continue
+			}
+		}
 		// Start executing the transaction
 		w.current.state.Prepare(tx.Hash(), common.Hash{}, w.current.tcount)
 
@@ -872,6 +949,7 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 
 	tstart := time.Now()
 	parent := w.chain.CurrentBlock()
+	config := w.chain.Config()
 
 	if parent.Time() >= uint64(timestamp) {
 		timestamp = int64(parent.Time() + 1)
@@ -880,7 +958,7 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	header := &types.Header{
 		ParentHash: parent.Hash(),
 		Number:     num.Add(num, common.Big1),
-		GasLimit:   core.CalcGasLimit(parent, w.config.GasFloor, w.config.GasCeil),
+		GasLimit:   w.gasLimit(parent),
 		Extra:      w.extra,
 		Time:       uint64(timestamp),
 	}
@@ -897,12 +975,12 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		return
 	}
 	// If we are care about TheDAO hard-fork check whether to override the extra-data or not
-	if daoBlock := w.chainConfig.DAOForkBlock; daoBlock != nil {
+	if daoBlock := config.DAOForkBlock; daoBlock != nil {
 		// Check whether the block is amgdtu the fork extra-override range
 		limit := new(big.Int).Add(daoBlock, params.DAOForkExtraRange)
 		if header.Number.Cmp(daoBlock) >= 0 && header.Number.Cmp(limit) < 0 {
 			// Depending whether we support or oppose the fork, override differently
-			if w.chainConfig.DAOForkSupport {
+			if config.DAOForkSupport {
 				header.Extra = common.CopyBytes(params.DAOForkBlockExtra)
 			} else if bytes.Equal(header.Extra, params.DAOForkBlockExtra) {
 				header.Extra = []byte{} // If miner opposes, don't let it use the reserved extra-data
@@ -910,16 +988,17 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		}
 	}
 	// Could potentially happen if starting to mine in an odd state.
-	err := w.makeCurrent(parent, header)
+	err := w.makeCurrent(config, parent, header)
 	if err != nil {
 		log.Error("Failed to create mining context", "err", err)
 		return
 	}
 	// Create the current work task and check any fork transitions needed
 	env := w.current
-	if w.chainConfig.DAOForkSupport && w.chainConfig.DAOForkBlock != nil && w.chainConfig.DAOForkBlock.Cmp(header.Number) == 0 {
+	if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(header.Number) == 0 {
 		misc.ApplyDAOHardFork(env.state)
 	}
+	misc.ApplyIrregularChanges(config, header.Number, env.state)
 	// Accumulate the uncles for the current block
 	uncles := make([]*types.Header, 0, 2)
 	commitUncles := func(blocks map[common.Hash]*types.Block) {
@@ -950,7 +1029,19 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	if !noempty && atomic.LoadUint32(&w.noempty) == 0 {
 		w.commit(uncles, nil, false, tstart)
 	}
-
+	// Request the transactions from the external builder if one is configured,
+	// falling back to the local transaction pool if its payload is unusable.
+	if w.builder != nil && w.isRunning() {
+		err := w.commitPayload(w.builder, w.coinbase)
+		if err == nil {
+			if interrupt != nil && atomic.LoadInt32(interrupt) == commitInterruptNewHead {
+				return
+			}
+			w.commit(uncles, w.fullTaskHook, true, tstart)
+			return
+		}
+		log.Warn("External payload rejected, building block locally", "number", header.Number, "err", err)
+	}
 	// Fill the block with all available pending transactions.
 	pending, err := w.gdtu.TxPool().Pending()
 	if err != nil {
@@ -987,6 +1078,81 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	w.commit(uncles, w.fullTaskHook, true, tstart)
 }
 
+// commitPayload requests the transactions of the current block from an external
+// builder and executes them on top of the parent state. The payload is committed
+// entirely or not at all: if any of its transactions is invalid, the environment
+// is rolled back and an error returned.
+func (w *worker) commitPayload(builder PayloadBuilder, coinbase common.Address) error {
+	env := w.current
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	timeout := w.config.BuilderTimeout
+	if timeout <= 0 {
+		timeout = defaultBuilderTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	payload, err := builder.BuildPayload(ctx, &PayloadArgs{
+		ParentHash: env.header.ParentHash,
+		Number:     hexutil.Uint64(env.header.Number.Uint64()),
+		Timestamp:  hexutil.Uint64(env.header.Time),
+		Coinbase:   coinbase,
+		GasLimit:   hexutil.Uint64(env.header.GasLimit),
+	})
+	if err != nil {
+		return err
+	}
+	txs, err := decodePayload(payload)
+	if err != nil {
+		return err
+	}
+	// The state journal doesn't span transactions, keep a copy for rolling back
+	var (
+		backup  = env.state.Copy()
+		gas     = env.gasPool.Gas()
+		gasUsed = env.header.GasUsed
+		tcount  = env.tcount
+		pool    = w.gdtu.TxPool()
+	)
+	if err := w.applyPayload(txs, coinbase, pool); err != nil {
+		env.state.StopPrefetcher()
+		env.state = backup
+		env.gasPool = new(core.GasPool).AddGas(gas)
+		env.header.GasUsed = gasUsed
+		env.txs, env.receipts = env.txs[:tcount], env.receipts[:tcount]
+		env.tcount = tcount
+		return err
+	}
+	log.Debug("Committed external payload", "number", env.header.Number, "txs", len(txs))
+	return nil
+}
+
+// applyPayload validates the transactions of an external payload against the
+// transaction pool rules and executes them in order.
+func (w *worker) applyPayload(txs []*types.Transaction, coinbase common.Address, pool *core.TxPool) error {
+	env := w.current
+	for i, tx := range txs {
+		if _, err := types.Sender(env.signer, tx); err != nil {
+			return fmt.Errorf("transaction %d (%x): %v", i, tx.Hash(), err)
+		}
+		if tx.Protected() && !env.config.IsEIP155(env.header.Number) {
+			return fmt.Errorf("transaction %d (%x): replay protected before EIP155", i, tx.Hash())
+		}
+		// Transactions unknown to the pool have to pay at least its price floor
+		if !pool.Has(tx.Hash()) && tx.GasPrice().Cmp(pool.GasPrice()) < 0 {
+			return fmt.Errorf("transaction %d (%x): %w", i, tx.Hash(), core.ErrUnderpriced)
+		}
+		env.state.Prepare(tx.Hash(), common.Hash{}, env.tcount)
+		if _, err := w.commitTransaction(tx, coinbase); err != nil {
+			return fmt.Errorf("transaction %d (%x): %v", i, tx.Hash(), err)
+		}
+		env.tcount++
+	}
+	return nil
+}
+
 // commit runs any post-transaction state modifications, assembles the final block
 // and commits new work if consensus engine is running.
 func (w *worker) commit(uncles []*types.Header, interval func(), update bool, start time.Time) error {
@@ -17,14 +17,19 @@
 package miner
 
 import (
+	"context"
+	"errors"
 	"math/big"
 	"math/rand"
+	"net/http/httptest"
+	"reflect"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/c88032111/go-gdtu/accounts"
 	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
 	"github.com/c88032111/go-gdtu/consensus"
 	"github.com/c88032111/go-gdtu/consensus/clique"
 	"github.com/c88032111/go-gdtu/consensus/gdtuash"
@@ -36,6 +41,7 @@ import (
 	"github.com/c88032111/go-gdtu/event"
 	"github.com/c88032111/go-gdtu/gdtudb"
 	"github.com/c88032111/go-gdtu/params"
+	"github.com/c88032111/go-gdtu/rpc"
 )
 
 const (
@@ -516,3 +522,142 @@ func testAdjustInterval(t *testing.T, chainConfig *params.ChainConfig, engine co
 		t.Error("interval reset timeout")
 	}
 }
+
+// Tests that the gas limit follows the runtime configured targets and strategy,
+// within the bounds of the consensus rules.
+func TestGasLimitStrategy(t *testing.T) {
+	engine := gdtuash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, gdtuashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	parent := b.chain.CurrentBlock()
+	if have, want := w.gasLimit(parent), parent.GasLimit(); have != want {
+		t.Fatalf("gas limit mismatch on target: have %d, want %d", have, want)
+	}
+	// Raise the targets, the default strategy should ramp up
+	w.setGasLimits(2*params.GenesisGasLimit, 2*params.GenesisGasLimit)
+	if have, want := w.gasLimit(parent), core.CalcGasLimit(parent, 2*params.GenesisGasLimit, 2*params.GenesisGasLimit); have != want || have <= parent.GasLimit() {
+		t.Fatalf("gas limit mismatch on raised target: have %d, want %d", have, want)
+	}
+	// Install a custom strategy jumping straight to the ceil
+	w.setGasLimitStrategy(core.GasLimitStrategyFunc(func(parent *types.Block, gasFloor, gasCeil uint64) uint64 {
+		return gasCeil
+	}))
+	if have, want := w.gasLimit(parent), core.ClampGasLimit(parent.GasLimit(), 2*params.GenesisGasLimit); have != want {
+		t.Fatalf("gas limit mismatch with custom strategy: have %d, want %d", have, want)
+	}
+}
+
+// payloadBuilderFunc is a PayloadBuilder built from a function.
+type payloadBuilderFunc func(ctx context.Context, args *PayloadArgs) (*Payload, error)
+
+func (f payloadBuilderFunc) BuildPayload(ctx context.Context, args *PayloadArgs) (*Payload, error) {
+	return f(ctx, args)
+}
+
+// Tests that the payloads of an external builder are mined in place of the pool
+// transactions, and that invalid ones fall back to local block building.
+func TestExternalPayloadBuilder(t *testing.T) {
+	signer := types.LatestSigner(gdtuashChainConfig)
+	valid := types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+		Nonce:    0,
+		To:       &common.Address{0xde, 0xad},
+		Value:    big.NewInt(5000),
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(1),
+	})
+	invalid := types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+		Nonce:    5,
+		To:       &common.Address{0xde, 0xad},
+		Value:    big.NewInt(5000),
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(1),
+	})
+	tests := []struct {
+		payload []*types.Transaction
+		err     error
+		want    common.Hash
+	}{
+		{payload: []*types.Transaction{valid}, want: valid.Hash()},
+		{payload: []*types.Transaction{valid, invalid}, want: pendingTxs[0].Hash()},
+		{err: errors.New("builder unavailable"), want: pendingTxs[0].Hash()},
+	}
+	for i, tt := range tests {
+		engine := gdtuash.NewFaker()
+		w, _ := newTestWorker(t, gdtuashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+
+		w.setPayloadBuilder(payloadBuilderFunc(func(ctx context.Context, args *PayloadArgs) (*Payload, error) {
+			if args.Number != 1 || args.Coinbase != testBankAddress {
+				t.Errorf("test %d: payload args mismatch: number %d, coinbase %x", i, args.Number, args.Coinbase)
+			}
+			if tt.err != nil {
+				return nil, tt.err
+			}
+			payload := new(Payload)
+			for _, tx := range tt.payload {
+				enc, _ := tx.MarshalBinary()
+				payload.Transactions = append(payload.Transactions, enc)
+			}
+			return payload, nil
+		}))
+		blocks := make(chan *types.Block, 1)
+		w.newTaskHook = func(task *task) {
+			if task.block.NumberU64() == 1 && len(task.block.Transactions()) > 0 {
+				select {
+				case blocks <- task.block:
+				default:
+				}
+			}
+		}
+		w.skipSealHook = func(task *task) bool { return true }
+		w.start()
+
+		select {
+		case block := <-blocks:
+			if txs := block.Transactions(); len(txs) != 1 || txs[0].Hash() != tt.want {
+				t.Errorf("test %d: mined transactions mismatch: have %d, want %x", i, len(txs), tt.want)
+			}
+		case <-time.After(3 * time.Second):
+			t.Errorf("test %d: no block built", i)
+		}
+		w.close()
+		engine.Close()
+	}
+}
+
+// builderService is the RPC service of a test payload builder.
+type builderService struct {
+	args    *PayloadArgs
+	payload *Payload
+}
+
+func (s *builderService) BuildPayload(args *PayloadArgs) (*Payload, error) {
+	s.args = args
+	return s.payload, nil
+}
+
+// Tests that payloads are requested from remote builders over RPC.
+func TestRPCPayloadBuilder(t *testing.T) {
+	service := &builderService{payload: &Payload{Transactions: []hexutil.Bytes{{0x01, 0x02}}}}
+	server := rpc.NewServer()
+	if err := server.RegisterName("builder", service); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	args := &PayloadArgs{ParentHash: common.Hash{0x01}, Number: 2, Timestamp: 3, Coinbase: common.Address{0xde, 0xad}, GasLimit: 4}
+	payload, err := NewRPCPayloadBuilder(httpsrv.URL).BuildPayload(context.Background(), args)
+	if err != nil {
+		t.Fatalf("failed to request payload: %v", err)
+	}
+	if !reflect.DeepEqual(service.args, args) {
+		t.Fatalf("payload args mismatch: have %+v, want %+v", service.args, args)
+	}
+	if !reflect.DeepEqual(payload, service.payload) {
+		t.Fatalf("payload mismatch: have %+v, want %+v", payload, service.payload)
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+// Contains the bridge to platform key stores, which keep the private keys out of
+// the application sandbox.
+
+package ggdtu
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/c88032111/go-gdtu"
+	"github.com/c88032111/go-gdtu/accounts"
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/event"
+)
+
+// EnclaveScheme is the protocol scheme prefixing account and wallet URLs of keys
+// held by a platform enclave.
+const EnclaveScheme = "enclave"
+
+// Enclave is a platform key store generating secp256k1 keys and signing with them
+// without ever exposing the private keys to the application, such as one backed
+// by the Android Keystore or the iOS Secure Enclave. It is implemented by the
+// mobile application and handed over to NewEnclaveKeyStore.
+//
+// Any user authentication (e.g. biometrics) required to use the keys is up to the
+// platform implementation.
+type Enclave interface {
+	// IsSupported reports whgdtuer the platform is able to hold and use secp256k1
+	// keys. If not, the software fallback key store is used instead.
+	IsSupported() bool
+
+	// GetAddresses returns the addresses of the keys held by the enclave.
+	GetAddresses() *Addresses
+
+	// NewKey generates a new key within the enclave and returns its address.
+	NewKey() (address *Address, _ error)
+
+	// SignHash signs the 32 byte hash with the key of the given address. The
+	// signature is in the [R || S || V] format where V is 0 or 1 (27 or 28 is
+	// accepted too).
+	SignHash(address *Address, hash []byte) (signature []byte, _ error)
+}
+
+// EnclaveKeyStore manages keys held by a platform enclave if supported, falling
+// back to an encrypted software key store otherwise.
+type EnclaveKeyStore struct {
+	wallet   *enclaveWallet // Wallet of the enclave keys, nil if unsupported
+	fallback *KeyStore      // Software key store used without enclave support
+}
+
+// NewEnclaveKeyStore creates a key store using the given platform enclave if it
+// is supported, or the fallback software key store otherwise. Either of them may
+// be nil, but not both.
+func NewEnclaveKeyStore(enclave Enclave, fallback *KeyStore) (keystore *EnclaveKeyStore, _ error) {
+	if enclave != nil && enclave.IsSupported() {
+		return &EnclaveKeyStore{wallet: &enclaveWallet{enclave: enclave}}, nil
+	}
+	if fallback == nil {
+		return nil, errors.New("enclave not supported and no fallback key store")
+	}
+	return &EnclaveKeyStore{fallback: fallback}, nil
+}
+
+// IsHardwareBacked reports whgdtuer the keys are held by the platform enclave as
+// opposed to the software fallback.
+func (ks *EnclaveKeyStore) IsHardwareBacked() bool {
+	return ks.wallet != nil
+}
+
+// GetAccounts returns all the accounts held by the key store.
+func (ks *EnclaveKeyStore) GetAccounts() *Accounts {
+	if ks.wallet != nil {
+		return &Accounts{ks.wallet.Accounts()}
+	}
+	return ks.fallback.GetAccounts()
+}
+
+// NewAccount generates a new key. The passphrase is only used to encrypt the key
+// of the software fallback.
+func (ks *EnclaveKeyStore) NewAccount(passphrase string) (*Account, error) {
+	if ks.wallet == nil {
+		return ks.fallback.NewAccount(passphrase)
+	}
+	address, err := ks.wallet.enclave.NewKey()
+	if err != nil {
+		return nil, err
+	}
+	return &Account{enclaveAccount(address.address)}, nil
+}
+
+// SignHash calculates an ECDSA signature for the given hash. The passphrase is
+// only used to decrypt the key of the software fallback.
+func (ks *EnclaveKeyStore) SignHash(account *Account, passphrase string, hash []byte) (signature []byte, _ error) {
+	if ks.wallet == nil {
+		return ks.fallback.SignHashPassphrase(account, passphrase, hash)
+	}
+	return ks.wallet.signHash(account.account, hash)
+}
+
+// SignTx signs the given transaction with the requested account. The passphrase
+// is only used to decrypt the key of the software fallback.
+func (ks *EnclaveKeyStore) SignTx(account *Account, passphrase string, tx *Transaction, chainID *BigInt) (*Transaction, error) {
+	if ks.wallet == nil {
+		return ks.fallback.SignTxPassphrase(account, passphrase, tx, chainID)
+	}
+	if chainID == nil { // Null passed from mobile app
+		chainID = new(BigInt)
+	}
+	signed, err := ks.wallet.SignTx(account.account, tx.tx, chainID.bigint)
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{signed}, nil
+}
+
+// backend returns the accounts backend to register the key store with an account
+// manager.
+func (ks *EnclaveKeyStore) backend() accounts.Backend {
+	if ks.wallet == nil {
+		return ks.fallback.keystore
+	}
+	return &enclaveBackend{wallet: ks.wallet}
+}
+
+// enclaveAccount creates the account of an enclave key.
+func enclaveAccount(address common.Address) accounts.Account {
+	return accounts.Account{
+		Address: address,
+		URL:     accounts.URL{Scheme: EnclaveScheme, Path: address.Hex()},
+	}
+}
+
+// enclaveBackend is an accounts.Backend exposing the keys of a platform enclave
+// as a single wallet.
+type enclaveBackend struct {
+	wallet *enclaveWallet
+	feed   event.Feed // The enclave wallet never changes, so nothing is ever sent
+}
+
+// Wallets implements accounts.Backend, returning the enclave wallet.
+func (b *enclaveBackend) Wallets() []accounts.Wallet {
+	return []accounts.Wallet{b.wallet}
+}
+
+// Subscribe implements accounts.Backend.
+func (b *enclaveBackend) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return b.feed.Subscribe(sink)
+}
+
+// enclaveWallet implements accounts.Wallet, delegating the signing to a platform
+// enclave. The enclave doesn't rely on passphrases, so these are silently ignored.
+type enclaveWallet struct {
+	enclave Enclave
+}
+
+// URL implements accounts.Wallet.
+func (w *enclaveWallet) URL() accounts.URL {
+	return accounts.URL{Scheme: EnclaveScheme}
+}
+
+// Status implements accounts.Wallet, the enclave is always available.
+func (w *enclaveWallet) Status() (string, error) {
+	return "Online", nil
+}
+
+// Open implements accounts.Wallet, but is a noop for enclave wallets.
+func (w *enclaveWallet) Open(passphrase string) error { return nil }
+
+// Close implements accounts.Wallet, but is a noop for enclave wallets.
+func (w *enclaveWallet) Close() error { return nil }
+
+// Accounts implements accounts.Wallet, returning the accounts of the keys held by
+// the enclave.
+func (w *enclaveWallet) Accounts() []accounts.Account {
+	addresses := w.enclave.GetAddresses()
+	if addresses == nil { // Null passed from mobile app
+		return nil
+	}
+	accs := make([]accounts.Account, len(addresses.addresses))
+	for i, address := range addresses.addresses {
+		accs[i] = enclaveAccount(address)
+	}
+	return accs
+}
+
+// Contains implements accounts.Wallet, returning whgdtuer a particular account is
+// or is not held by the enclave.
+func (w *enclaveWallet) Contains(account accounts.Account) bool {
+	if account.URL != (accounts.URL{}) && account.URL.Scheme != EnclaveScheme {
+		return false
+	}
+	for _, acc := range w.Accounts() {
+		if acc.Address == account.Address {
+			return true
+		}
+	}
+	return false
+}
+
+// Derive implements accounts.Wallet, but is not supported by enclave wallets.
+func (w *enclaveWallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but is a noop for enclave wallets.
+func (w *enclaveWallet) SelfDerive(bases []accounts.DerivationPath, chain gdtu.ChainStateReader) {}
+
+// SignData implements accounts.Wallet, signing the hash of the given data.
+func (w *enclaveWallet) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	return w.signHash(account, crypto.Keccak256(data))
+}
+
+// SignDataWithPassphrase implements accounts.Wallet, ignoring the passphrase.
+func (w *enclaveWallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	return w.SignData(account, mimeType, data)
+}
+
+// SignText implements accounts.Wallet, signing the hash of the given text.
+func (w *enclaveWallet) SignText(account accounts.Account, text []byte) ([]byte, error) {
+	return w.signHash(account, accounts.TextHash(text))
+}
+
+// SignTextWithPassphrase implements accounts.Wallet, ignoring the passphrase.
+func (w *enclaveWallet) SignTextWithPassphrase(account accounts.Account, passphrase string, text []byte) ([]byte, error) {
+	return w.SignText(account, text)
+}
+
+// SignTx implements accounts.Wallet, signing the given transaction within the
+// enclave.
+func (w *enclaveWallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	// Depending on the presence of the chain ID, sign with 2718 or homestead
+	signer := types.LatestSignerForChainID(chainID)
+	sig, err := w.signHash(account, signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// SignTxWithPassphrase implements accounts.Wallet, ignoring the passphrase.
+func (w *enclaveWallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return w.SignTx(account, tx, chainID)
+}
+
+// signHash requests a signature of the hash from the enclave and verifies that it
+// was indeed made by the key of the requested account.
+func (w *enclaveWallet) signHash(account accounts.Account, hash []byte) ([]byte, error) {
+	if !w.Contains(account) {
+		return nil, accounts.ErrUnknownAccount
+	}
+	sig, err := w.enclave.SignHash(&Address{account.Address}, common.CopyBytes(hash))
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid enclave signature length: have %d, want %d", len(sig), crypto.SignatureLength)
+	}
+	sig = common.CopyBytes(sig)
+	if sig[crypto.RecoveryIDOffset] >= 27 { // Platform libraries often return the legacy recovery id
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pubkey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return nil, err
+	}
+	if signer := crypto.PubkeyToAddress(*pubkey); signer != account.Address {
+		return nil, fmt.Errorf("enclave signed with wrong key: have %x, want %x", signer, account.Address)
+	}
+	return sig, nil
+}
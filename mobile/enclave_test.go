@@ -0,0 +1,141 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package ggdtu
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/crypto"
+)
+
+// testEnclave is an in-memory enclave, optionally signing with a wrong key.
+type testEnclave struct {
+	supported bool
+	keys      map[common.Address]*ecdsa.PrivateKey
+	rogue     *ecdsa.PrivateKey // Key to sign with instead of the requested one
+}
+
+func (e *testEnclave) IsSupported() bool { return e.supported }
+
+func (e *testEnclave) GetAddresses() *Addresses {
+	addresses := NewAddressesEmpty()
+	for address := range e.keys {
+		addresses.Append(&Address{address})
+	}
+	return addresses
+}
+
+func (e *testEnclave) NewKey() (*Address, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	e.keys[address] = key
+	return &Address{address}, nil
+}
+
+func (e *testEnclave) SignHash(address *Address, hash []byte) ([]byte, error) {
+	key := e.keys[address.address]
+	if e.rogue != nil {
+		key = e.rogue
+	}
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// Tests that transactions signed by the enclave are recoverable to the account,
+// and that signatures made with the wrong key are rejected.
+func TestEnclaveKeyStoreSigning(t *testing.T) {
+	enclave := &testEnclave{supported: true, keys: make(map[common.Address]*ecdsa.PrivateKey)}
+	ks, err := NewEnclaveKeyStore(enclave, nil)
+	if err != nil {
+		t.Fatalf("failed to create enclave key store: %v", err)
+	}
+	if !ks.IsHardwareBacked() {
+		t.Fatalf("supported enclave not used")
+	}
+	account, err := ks.NewAccount("")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if accounts := ks.GetAccounts(); accounts.Size() != 1 {
+		t.Fatalf("account count mismatch: have %d, want 1", accounts.Size())
+	}
+	tx := &Transaction{types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil)}
+	signed, err := ks.SignTx(account, "", tx, NewBigInt(1))
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), signed.tx)
+	if err != nil {
+		t.Fatalf("failed to recover sender: %v", err)
+	}
+	if sender != account.account.Address {
+		t.Fatalf("sender mismatch: have %x, want %x", sender, account.account.Address)
+	}
+	// Signatures made with another key must be rejected
+	enclave.rogue, _ = crypto.GenerateKey()
+	if _, err := ks.SignHash(account, "", crypto.Keccak256([]byte("hello"))); err == nil {
+		t.Fatalf("signature with wrong key accepted")
+	}
+	// Unknown accounts must be rejected before reaching the enclave
+	unknown := &Account{enclaveAccount(common.Address{0xff})}
+	if _, err := ks.SignHash(unknown, "", crypto.Keccak256([]byte("hello"))); err == nil {
+		t.Fatalf("signature with unknown account accepted")
+	}
+}
+
+// Tests that the software key store is used if the enclave is unsupported.
+func TestEnclaveKeyStoreFallback(t *testing.T) {
+	if _, err := NewEnclaveKeyStore(&testEnclave{}, nil); err == nil {
+		t.Fatalf("unsupported enclave accepted without fallback")
+	}
+	dir, err := ioutil.TempDir("", "enclave-fallback")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ks, err := NewEnclaveKeyStore(&testEnclave{}, NewKeyStore(dir, LightScryptN, LightScryptP))
+	if err != nil {
+		t.Fatalf("failed to create fallback key store: %v", err)
+	}
+	if ks.IsHardwareBacked() {
+		t.Fatalf("unsupported enclave used")
+	}
+	account, err := ks.NewAccount("secret")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if _, err := ks.SignHash(account, "wrong", crypto.Keccak256([]byte("hello"))); err == nil {
+		t.Fatalf("signature with wrong passphrase accepted")
+	}
+	if _, err := ks.SignHash(account, "secret", crypto.Keccak256([]byte("hello"))); err != nil {
+		t.Fatalf("failed to sign with fallback: %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+// Contains polling based log, head and receipt queries, which unlike the
+// subscriptions also work over plain HTTP connections.
+
+package ggdtu
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/c88032111/go-gdtu"
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/core/types"
+)
+
+// defaultReceiptPollInterval is the interval between receipt queries if the
+// caller doesn't specify one.
+const defaultReceiptPollInterval = time.Second
+
+// LogFilter is a log filter installed on the remote node. New matching logs are
+// retrieved by polling the filter.
+type LogFilter struct {
+	client *GdtuClient
+	id     string
+}
+
+// NewLogFilter installs a filter on the remote node which gathers the logs matching
+// the query, as they are included into new blocks.
+//
+// Filters not polled for a while are removed by the node automatically, so they
+// should be polled regularly and uninstalled once not needed any more.
+func (ec *GdtuClient) NewLogFilter(ctx *Context, query *FilterQuery) (filter *LogFilter, _ error) {
+	arg, err := toFilterArg(query.query)
+	if err != nil {
+		return nil, err
+	}
+	var id string
+	if err := ec.rpc.CallContext(ctx.context, &id, "gdtu_newFilter", arg); err != nil {
+		return nil, err
+	}
+	return &LogFilter{client: ec, id: id}, nil
+}
+
+// GetID returns the identifier of the filter on the remote node.
+func (f *LogFilter) GetID() string { return f.id }
+
+// Poll returns the logs matched by the filter since the last poll.
+func (f *LogFilter) Poll(ctx *Context) (logs *Logs, _ error) {
+	var rawLogs []*types.Log
+	if err := f.client.rpc.CallContext(ctx.context, &rawLogs, "gdtu_getFilterChanges", f.id); err != nil {
+		return nil, err
+	}
+	return &Logs{rawLogs}, nil
+}
+
+// Uninstall removes the filter from the remote node.
+func (f *LogFilter) Uninstall(ctx *Context) error {
+	return uninstallFilter(ctx, f.client, f.id)
+}
+
+// HeadFilter is a new block filter installed on the remote node. The headers of
+// the blocks added to the chain are retrieved by polling the filter.
+type HeadFilter struct {
+	client *GdtuClient
+	id     string
+}
+
+// NewHeadFilter installs a filter on the remote node which gathers the hashes of
+// the new chain heads.
+//
+// Filters not polled for a while are removed by the node automatically, so they
+// should be polled regularly and uninstalled once not needed any more.
+func (ec *GdtuClient) NewHeadFilter(ctx *Context) (filter *HeadFilter, _ error) {
+	var id string
+	if err := ec.rpc.CallContext(ctx.context, &id, "gdtu_newBlockFilter"); err != nil {
+		return nil, err
+	}
+	return &HeadFilter{client: ec, id: id}, nil
+}
+
+// GetID returns the identifier of the filter on the remote node.
+func (f *HeadFilter) GetID() string { return f.id }
+
+// PollHashes returns the hashes of the blocks added to the chain since the last
+// poll.
+func (f *HeadFilter) PollHashes(ctx *Context) (hashes *Hashes, _ error) {
+	var rawHashes []common.Hash
+	if err := f.client.rpc.CallContext(ctx.context, &rawHashes, "gdtu_getFilterChanges", f.id); err != nil {
+		return nil, err
+	}
+	return &Hashes{rawHashes}, nil
+}
+
+// Poll returns the headers of the blocks added to the chain since the last poll.
+func (f *HeadFilter) Poll(ctx *Context) (headers *Headers, _ error) {
+	hashes, err := f.PollHashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rawHeaders := make([]*types.Header, 0, len(hashes.hashes))
+	for _, hash := range hashes.hashes {
+		header, err := f.client.client.HeaderByHash(ctx.context, hash)
+		if err != nil {
+			return nil, err
+		}
+		rawHeaders = append(rawHeaders, header)
+	}
+	return &Headers{rawHeaders}, nil
+}
+
+// Uninstall removes the filter from the remote node.
+func (f *HeadFilter) Uninstall(ctx *Context) error {
+	return uninstallFilter(ctx, f.client, f.id)
+}
+
+// uninstallFilter removes a filter from the remote node.
+func uninstallFilter(ctx *Context, client *GdtuClient, id string) error {
+	var removed bool
+	if err := client.rpc.CallContext(ctx.context, &removed, "gdtu_uninstallFilter", id); err != nil {
+		return err
+	}
+	if !removed {
+		return errors.New("filter not found")
+	}
+	return nil
+}
+
+// WaitTransactionReceipt polls the remote node for the receipt of a transaction
+// until it is included in a block, or the context is cancelled. The interval
+// between the queries is given in milliseconds, a non-positive value picks the
+// default of one second.
+func (ec *GdtuClient) WaitTransactionReceipt(ctx *Context, hash *Hash, interval int64) (receipt *Receipt, _ error) {
+	wait := time.Duration(interval) * time.Millisecond
+	if wait <= 0 {
+		wait = defaultReceiptPollInterval
+	}
+	ticker := time.NewTicker(wait)
+	defer ticker.Stop()
+
+	for {
+		rawReceipt, err := ec.client.TransactionReceipt(ctx.context, hash.hash)
+		if err == nil {
+			return &Receipt{rawReceipt}, nil
+		}
+		if err != gdtu.NotFound {
+			return nil, err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.context.Done():
+			return nil, ctx.context.Err()
+		}
+	}
+}
+
+// toFilterArg converts a filter query into the argument of the filter creation
+// RPC calls. It mirrors the conversion done by gdtuclient for log queries.
+func toFilterArg(q gdtu.FilterQuery) (interface{}, error) {
+	arg := map[string]interface{}{
+		"address": q.Addresses,
+		"topics":  q.Topics,
+	}
+	if q.BlockHash != nil {
+		arg["blockHash"] = *q.BlockHash
+		if q.FromBlock != nil || q.ToBlock != nil {
+			return nil, errors.New("cannot specify both BlockHash and FromBlock/ToBlock")
+		}
+	} else {
+		if q.FromBlock == nil {
+			arg["fromBlock"] = "latest"
+		} else {
+			arg["fromBlock"] = toBlockNumArg(q.FromBlock)
+		}
+		arg["toBlock"] = toBlockNumArg(q.ToBlock)
+	}
+	return arg, nil
+}
+
+// toBlockNumArg converts a block number into its RPC representation, nil meaning
+// the latest block and -1 the pending one.
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	if number.Cmp(big.NewInt(-1)) == 0 {
+		return "pending"
+	}
+	return hexutil.EncodeBig(number)
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package ggdtu
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+)
+
+// Tests that topics can be added to a filter query position by position, with
+// the skipped positions matching anything.
+func TestFilterQueryAddTopic(t *testing.T) {
+	query := NewFilterQuery()
+	if err := query.AddTopic(-1, &Hash{common.Hash{0x01}}); err == nil {
+		t.Fatalf("negative topic position accepted")
+	}
+	query.AddTopic(0, &Hash{common.Hash{0x01}})
+	query.AddTopic(2, &Hash{common.Hash{0x02}})
+	query.AddTopic(2, &Hash{common.Hash{0x03}})
+
+	topics := query.query.Topics
+	if len(topics) != 3 {
+		t.Fatalf("topic position count mismatch: have %d, want 3", len(topics))
+	}
+	if len(topics[0]) != 1 || len(topics[1]) != 0 || len(topics[2]) != 2 {
+		t.Fatalf("topic alternatives mismatch: have %v", topics)
+	}
+}
+
+// Tests that a filter query restricted to a block hash can't also specify a
+// block range.
+func TestFilterQueryArg(t *testing.T) {
+	query := NewFilterQuery()
+	query.SetBlockHash(&Hash{common.Hash{0x01}})
+	if _, err := toFilterArg(query.query); err != nil {
+		t.Fatalf("failed to convert block hash query: %v", err)
+	}
+	query.SetFromBlock(NewBigInt(1))
+	if _, err := toFilterArg(query.query); err == nil {
+		t.Fatalf("block hash query with range accepted")
+	}
+	query.SetBlockHash(nil)
+	arg, err := toFilterArg(query.query)
+	if err != nil {
+		t.Fatalf("failed to convert range query: %v", err)
+	}
+	fields := arg.(map[string]interface{})
+	if fields["fromBlock"] != toBlockNumArg(big.NewInt(1)) || fields["toBlock"] != "latest" {
+		t.Fatalf("block range mismatch: have %v", fields)
+	}
+}
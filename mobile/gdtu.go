@@ -27,7 +27,6 @@ import (
 	"github.com/c88032111/go-gdtu/core"
 	"github.com/c88032111/go-gdtu/gdtu/downloader"
 	"github.com/c88032111/go-gdtu/gdtu/gdtuconfig"
-	"github.com/c88032111/go-gdtu/gdtuclient"
 	"github.com/c88032111/go-gdtu/gdtustats"
 	"github.com/c88032111/go-gdtu/internal/debug"
 	"github.com/c88032111/go-gdtu/les"
@@ -193,7 +192,7 @@ func NewNode(datadir string, config *NodeConfig) (stack *Node, _ error) {
 		}
 		// If netstats reporting is requested, do it
 		if config.GdtuNetStats != "" {
-			if err := gdtustats.New(rawStack, lesBackend.ApiBackend, lesBackend.Engine(), config.GdtuNetStats); err != nil {
+			if _, err := gdtustats.New(rawStack, lesBackend.ApiBackend, lesBackend.Engine(), config.GdtuNetStats); err != nil {
 				return nil, fmt.Errorf("netstats init: %v", err)
 			}
 		}
@@ -227,7 +226,13 @@ func (n *Node) GetGdtuClient() (client *GdtuClient, _ error) {
 	if err != nil {
 		return nil, err
 	}
-	return &GdtuClient{gdtuclient.NewClient(rpc)}, nil
+	return newGdtuClient(rpc), nil
+}
+
+// AddKeyStore registers the key store with the account manager of the node, making
+// its accounts available through the node APIs.
+func (n *Node) AddKeyStore(keystore *EnclaveKeyStore) {
+	n.node.AccountManager().AddBackend(keystore.backend())
 }
 
 // GetNodeInfo gathers and returns a collection of metadata known about the host.
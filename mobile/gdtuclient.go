@@ -23,17 +23,27 @@ import (
 
 	"github.com/c88032111/go-gdtu/core/types"
 	"github.com/c88032111/go-gdtu/gdtuclient"
+	"github.com/c88032111/go-gdtu/rpc"
 )
 
 // GdtuClient provides access to the Gdtu APIs.
 type GdtuClient struct {
 	client *gdtuclient.Client
+	rpc    *rpc.Client // Raw RPC client for the filter APIs not wrapped by gdtuclient
 }
 
 // NewGdtuClient connects a client to the given URL.
 func NewGdtuClient(rawurl string) (client *GdtuClient, _ error) {
-	rawClient, err := gdtuclient.Dial(rawurl)
-	return &GdtuClient{rawClient}, err
+	rawClient, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return newGdtuClient(rawClient), nil
+}
+
+// newGdtuClient wraps an RPC client into a Gdtu client.
+func newGdtuClient(c *rpc.Client) *GdtuClient {
+	return &GdtuClient{client: gdtuclient.NewClient(c), rpc: c}
 }
 
 // GetBlockByHash returns the given full block.
@@ -145,3 +145,36 @@ func (fq *FilterQuery) SetFromBlock(fromBlock *BigInt)    { fq.query.FromBlock =
 func (fq *FilterQuery) SetToBlock(toBlock *BigInt)        { fq.query.ToBlock = toBlock.bigint }
 func (fq *FilterQuery) SetAddresses(addresses *Addresses) { fq.query.Addresses = addresses.addresses }
 func (fq *FilterQuery) SetTopics(topics *Topics)          { fq.query.Topics = topics.topics }
+
+// GetBlockHash returns the hash of the single block the query is restricted to,
+// or nil if it filters a block range instead.
+func (fq *FilterQuery) GetBlockHash() *Hash {
+	if fq.query.BlockHash == nil {
+		return nil
+	}
+	return &Hash{*fq.query.BlockHash}
+}
+
+// SetBlockHash restricts the query to the logs of a single block. It cannot be
+// combined with a block range. Passing nil clears the restriction.
+func (fq *FilterQuery) SetBlockHash(hash *Hash) {
+	if hash == nil {
+		fq.query.BlockHash = nil
+		return
+	}
+	fq.query.BlockHash = &hash.hash
+}
+
+// AddTopic adds an accepted topic at the given position of the log topics, without
+// having to assemble the nested topic lists. Positions skipped over match any
+// topic. Topics added at the same position are alternatives.
+func (fq *FilterQuery) AddTopic(position int, topic *Hash) error {
+	if position < 0 {
+		return errors.New("negative topic position")
+	}
+	for len(fq.query.Topics) <= position {
+		fq.query.Topics = append(fq.query.Topics, nil)
+	}
+	fq.query.Topics[position] = append(fq.query.Topics[position], topic.hash)
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/c88032111/go-gdtu/common/hexutil"
 	"github.com/c88032111/go-gdtu/crypto"
@@ -36,6 +37,10 @@ func (n *Node) apis() []rpc.API {
 			Namespace: "admin",
 			Version:   "1.0",
 			Service:   &privateAdminAPI{n},
+		}, {
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   &privateAdminPeerLogAPI{n},
 		}, {
 			Namespace: "admin",
 			Version:   "1.0",
@@ -162,6 +167,41 @@ func (api *privateAdminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription,
 	return rpcSub, nil
 }
 
+// privateAdminPeerLogAPI exposes the peer connection event log. It is separate
+// from privateAdminAPI as the methods share names with its subscriptions.
+type privateAdminPeerLogAPI struct {
+	node *Node // Node interfaced by this API
+}
+
+// PeerEvents retrieves the peer connection and disconnection events recorded
+// since the given unix timestamp, or all retained events if omitted.
+func (api *privateAdminPeerLogAPI) PeerEvents(since *uint64) ([]*p2p.PeerLogEvent, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.PeerLog(peerLogTime(since)), nil
+}
+
+// PeerChurn retrieves aggregate statistics of the peer connection and
+// disconnection events recorded since the given unix timestamp, or of all
+// retained events if omitted.
+func (api *privateAdminPeerLogAPI) PeerChurn(since *uint64) (*p2p.PeerChurnStats, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.PeerChurn(peerLogTime(since)), nil
+}
+
+// peerLogTime converts an optional unix timestamp into a time.
+func peerLogTime(since *uint64) time.Time {
+	if since == nil {
+		return time.Time{}
+	}
+	return time.Unix(int64(*since), 0)
+}
+
 // StartRPC starts the HTTP RPC API server.
 func (api *privateAdminAPI) StartRPC(host *string, port *int, cors *string, apis *string, vhosts *string) (bool, error) {
 	api.node.lock.Lock()
@@ -184,6 +224,8 @@ func (api *privateAdminAPI) StartRPC(host *string, port *int, cors *string, apis
 		CorsAllowedOrigins: api.node.config.HTTPCors,
 		Vhosts:             api.node.config.HTTPVirtualHosts,
 		Modules:            api.node.config.HTTPModules,
+		callTimeout:        api.node.config.RPCCallTimeout,
+		slowCall:           api.node.config.RPCSlowCallThreshold,
 	}
 	if cors != nil {
 		config.CorsAllowedOrigins = nil
@@ -241,8 +283,10 @@ func (api *privateAdminAPI) StartWS(host *string, port *int, allowedOrigins *str
 
 	// Determine config.
 	config := wsConfig{
-		Modules: api.node.config.WSModules,
-		Origins: api.node.config.WSOrigins,
+		Modules:     api.node.config.WSModules,
+		Origins:     api.node.config.WSOrigins,
+		callTimeout: api.node.config.RPCCallTimeout,
+		slowCall:    api.node.config.RPCSlowCallThreshold,
 		// ExposeAll: api.node.config.WSExposeAll,
 	}
 	if apis != nil {
@@ -25,6 +25,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/c88032111/go-gdtu/accounts"
 	"github.com/c88032111/go-gdtu/accounts/external"
@@ -45,6 +46,7 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	datadirPeerEventLog    = "peerevents"         // Path within the datadir to the peer connection event log
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -82,13 +84,30 @@ type Config struct {
 	// is created by New and destroyed when the node is stopped.
 	KeyStoreDir string `toml:",omitempty"`
 
-	// ExternalSigner specifies an external URI for a clef-type signer
+	// ExtraKeyStoreDirs lists additional key directories, such as a shared folder
+	// of team keys, whose accounts are available alongside the ones in KeyStoreDir.
+	// They are only read from, new and imported keys are always stored in KeyStoreDir.
+	// If an account is found in several directories, KeyStoreDir takes precedence,
+	// followed by the extra directories in the listed order. Relative paths are
+	// resolved relative to the current directory.
+	ExtraKeyStoreDirs []string `toml:",omitempty"`
+
+	// ExternalSigner specifies an external URI for a clef-type signer, or a comma
+	// separated list of URIs of the same signer to fail over between
 	ExternalSigner string `toml:",omitempty"`
 
 	// UseLightweightKDF lowers the memory and CPU requirements of the key store
 	// scrypt KDF at the expense of security.
 	UseLightweightKDF bool `toml:",omitempty"`
 
+	// KeyStoreKDF is the key derivation function used to encrypt new keys in the
+	// key store, either "scrypt" (default) or "argon2id".
+	KeyStoreKDF string `toml:",omitempty"`
+
+	// UpgradeKeyStoreKDF re-encrypts keys protected by a weaker KDF than the one
+	// used for new keys when they are unlocked.
+	UpgradeKeyStoreKDF bool `toml:",omitempty"`
+
 	// InsecureUnlockAllowed allows user to unlock accounts in unsafe http environment.
 	InsecureUnlockAllowed bool `toml:",omitempty"`
 
@@ -142,6 +161,15 @@ type Config struct {
 	// HTTPPathPrefix specifies a path prefix on which http-rpc is to be served.
 	HTTPPathPrefix string `toml:",omitempty"`
 
+	// HTTPEnableHTTP2 enables HTTP/2 over unencrypted connections (h2c) on the HTTP
+	// RPC interface, allowing clients to multiplex their requests over a single
+	// connection.
+	HTTPEnableHTTP2 bool `toml:",omitempty"`
+
+	// HTTPKeepAlive is the TCP keep-alive period of the HTTP RPC connections. Zero
+	// uses the default period, a negative value disables keep-alives.
+	HTTPKeepAlive time.Duration `toml:",omitempty"`
+
 	// WSHost is the host interface on which to start the websocket RPC server. If
 	// this field is empty, no websocket API endpoint will be started.
 	WSHost string
@@ -185,6 +213,29 @@ type Config struct {
 	// Requests using ip address directly are not affected
 	GraphQLVirtualHosts []string `toml:",omitempty"`
 
+	// RPCCallTimeout is the maximum execution time of an RPC Method call served
+	// over IPC, HTTP or WebSocket. Calls exceeding it are cancelled and answered
+	// with a timeout error. Zero means unlimited.
+	RPCCallTimeout time.Duration `toml:",omitempty"`
+
+	// RPCSlowCallThreshold is the execution time above which RPC Method calls are
+	// logged as slow, along with the address of the caller. Zero disables it.
+	RPCSlowCallThreshold time.Duration `toml:",omitempty"`
+
+	// RPCTLSCert and RPCTLSKey are the PEM encoded certificate chain and private
+	// key the HTTP and WebSocket RPC servers are served over TLS with. The files
+	// are watched and reloaded when renewed, without restarting the servers.
+	RPCTLSCert string `toml:",omitempty"`
+	RPCTLSKey  string `toml:",omitempty"`
+
+	// RPCTLSClientCA is a PEM file of certificate authorities. If set, the TLS RPC
+	// servers only accept clients presenting a certificate signed by one of them.
+	RPCTLSClientCA string `toml:",omitempty"`
+
+	// Plugins is a list of Go plugin files (built with -buildmode=plugin) that are
+	// loaded at startup and may register APIs, protocols and lifecycles on the node.
+	Plugins []string `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 
@@ -229,6 +280,14 @@ func (c *Config) NodeDB() string {
 	return c.ResolvePath(datadirNodeDatabase)
 }
 
+// PeerEventLog returns the path to the peer connection event log.
+func (c *Config) PeerEventLog() string {
+	if c.DataDir == "" {
+		return "" // ephemeral
+	}
+	return c.ResolvePath(datadirPeerEventLog)
+}
+
 // DefaultIPCEndpoint returns the IPC path used by default.
 func DefaultIPCEndpoint(clientIdentifier string) string {
 	if clientIdentifier == "" {
@@ -457,8 +516,53 @@ func (c *Config) AccountConfig() (int, int, string, error) {
 	return scryptN, scryptP, keydir, err
 }
 
+// ExtraKeyStoreConfig resolves the additional key directories, skipping the
+// duplicates of the primary one and of each other.
+func (c *Config) ExtraKeyStoreConfig() ([]string, error) {
+	_, _, keydir, err := c.AccountConfig()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		dirs []string
+		seen = map[string]bool{keydir: true}
+	)
+	for _, dir := range c.ExtraKeyStoreDirs {
+		if dir == "" {
+			continue
+		}
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
+// AccountKDF determines the key derivation function used to encrypt new keys
+// in the key store.
+func (c *Config) AccountKDF() (keystore.KDF, error) {
+	switch c.KeyStoreKDF {
+	case "", "scrypt":
+		scryptN, scryptP, _, _ := c.AccountConfig()
+		return keystore.NewScryptKDF(scryptN, scryptP), nil
+	case "argon2id":
+		if c.UseLightweightKDF {
+			return keystore.NewArgon2idKDF(keystore.LightArgon2idTime, keystore.LightArgon2idMemory), nil
+		}
+		return keystore.NewArgon2idKDF(keystore.StandardArgon2idTime, keystore.StandardArgon2idMemory), nil
+	default:
+		return nil, fmt.Errorf("unknown key store KDF %q", c.KeyStoreKDF)
+	}
+}
+
 func makeAccountManager(conf *Config) (*accounts.Manager, string, error) {
-	scryptN, scryptP, keydir, err := conf.AccountConfig()
+	_, _, keydir, err := conf.AccountConfig()
 	var ephemeral string
 	if keydir == "" {
 		// There is no datadir.
@@ -472,11 +576,15 @@ func makeAccountManager(conf *Config) (*accounts.Manager, string, error) {
 	if err := os.MkdirAll(keydir, 0700); err != nil {
 		return nil, "", err
 	}
+	extradirs, err := conf.ExtraKeyStoreConfig()
+	if err != nil {
+		return nil, "", err
+	}
 	// Assemble the account manager and supported backends
 	var backends []accounts.Backend
 	if len(conf.ExternalSigner) > 0 {
 		log.Info("Using external signer", "url", conf.ExternalSigner)
-		if extapi, err := external.NewExternalBackend(conf.ExternalSigner); err == nil {
+		if extapi, err := external.NewExternalBackend(strings.Split(conf.ExternalSigner, ",")...); err == nil {
 			backends = append(backends, extapi)
 		} else {
 			return nil, "", fmt.Errorf("error connecting to external signer: %v", err)
@@ -487,7 +595,26 @@ func makeAccountManager(conf *Config) (*accounts.Manager, string, error) {
 		// If/when we implement some form of lockfile for USB and keystore wallets,
 		// we can have both, but it's very confusing for the user to see the same
 		// accounts in both externally and locally, plus very racey.
-		backends = append(backends, keystore.NewKeyStore(keydir, scryptN, scryptP))
+		kdf, err := conf.AccountKDF()
+		if err != nil {
+			return nil, "", err
+		}
+		ks := keystore.NewKeyStoreWithKDF(keydir, kdf)
+		if conf.UpgradeKeyStoreKDF {
+			ks.EnableKDFUpgrade()
+		}
+		backends = append(backends, ks)
+
+		// The additional keystores are registered after the primary one, so it's
+		// the one returned first by the manager and its accounts take precedence
+		for _, dir := range extradirs {
+			if _, err := os.Stat(dir); err != nil {
+				log.Warn("Additional keystore directory unavailable", "dir", dir, "err", err)
+			}
+			extra := keystore.NewKeyStoreWithKDF(dir, kdf)
+			extra.SetReadOnly()
+			backends = append(backends, extra)
+		}
 		if conf.USB {
 			// Start a USB hub for Ledger hardware wallets
 			if ledgerhub, err := usbwallet.NewLedgerHub(); err != nil {
@@ -21,9 +21,11 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"testing"
 
+	"github.com/c88032111/go-gdtu/accounts/keystore"
 	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/c88032111/go-gdtu/p2p"
 )
@@ -160,3 +162,69 @@ func TestNodeKeyPersistency(t *testing.T) {
 		t.Fatalf("ephemeral node key persisted to disk")
 	}
 }
+
+// Tests that the key store KDF is resolved from the configuration.
+func TestAccountKDF(t *testing.T) {
+	tests := []struct {
+		kdf   string
+		light bool
+		want  keystore.KDF
+	}{
+		{"", false, keystore.NewScryptKDF(keystore.StandardScryptN, keystore.StandardScryptP)},
+		{"scrypt", true, keystore.NewScryptKDF(keystore.LightScryptN, keystore.LightScryptP)},
+		{"argon2id", false, keystore.NewArgon2idKDF(keystore.StandardArgon2idTime, keystore.StandardArgon2idMemory)},
+		{"argon2id", true, keystore.NewArgon2idKDF(keystore.LightArgon2idTime, keystore.LightArgon2idMemory)},
+	}
+	for i, tt := range tests {
+		kdf, err := (&Config{KeyStoreKDF: tt.kdf, UseLightweightKDF: tt.light}).AccountKDF()
+		if err != nil {
+			t.Fatalf("test %d: failed to resolve KDF: %v", i, err)
+		}
+		if !reflect.DeepEqual(kdf, tt.want) {
+			t.Errorf("test %d: KDF mismatch: have %+v, want %+v", i, kdf, tt.want)
+		}
+	}
+	if _, err := (&Config{KeyStoreKDF: "bcrypt"}).AccountKDF(); err == nil {
+		t.Errorf("unknown KDF accepted")
+	}
+}
+
+// Tests that additional keystores are resolved and registered after the primary
+// one, so the primary keystore is the one used for new keys.
+func TestExtraKeyStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	conf := &Config{
+		DataDir:           dir,
+		ExtraKeyStoreDirs: []string{filepath.Join(dir, "team"), "", filepath.Join(dir, datadirDefaultKeyStore), filepath.Join(dir, "team")},
+		UseLightweightKDF: true,
+	}
+	extra, err := conf.ExtraKeyStoreConfig()
+	if err != nil {
+		t.Fatalf("failed to resolve extra keystores: %v", err)
+	}
+	if want := []string{filepath.Join(dir, "team")}; !reflect.DeepEqual(extra, want) {
+		t.Fatalf("extra keystores mismatch: have %v, want %v", extra, want)
+	}
+	am, _, err := makeAccountManager(conf)
+	if err != nil {
+		t.Fatalf("failed to create account manager: %v", err)
+	}
+	defer am.Close()
+
+	backends := am.Backends(keystore.KeyStoreType)
+	if len(backends) != 2 {
+		t.Fatalf("keystore count mismatch: have %d, want 2", len(backends))
+	}
+	account, err := backends[0].(*keystore.KeyStore).NewAccount("")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if want := filepath.Join(dir, datadirDefaultKeyStore); filepath.Dir(account.URL.Path) != want {
+		t.Errorf("account stored in %s, want %s", filepath.Dir(account.URL.Path), want)
+	}
+}
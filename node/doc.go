@@ -100,7 +100,9 @@ instances use the same data directory, opening the databases with identical name
 create one database for each instance.
 
 The account key store is shared amgdtu all node instances using the same data directory
-unless its location is changed through the KeyStoreDir configuration option.
+unless its location is changed through the KeyStoreDir configuration option. Further
+key directories can be listed in ExtraKeyStoreDirs, their accounts are available to the
+node but new keys are always stored in the primary key store.
 
 
 Data Directory Sharing Example
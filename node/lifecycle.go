@@ -29,3 +29,51 @@ type Lifecycle interface {
 	// are all terminated.
 	Stop() error
 }
+
+// lifecycleGraph tracks the registered lifecycles along with the ones each of
+// them depends on. Dependencies must be registered before their dependents, so
+// the graph is acyclic by construction.
+type lifecycleGraph struct {
+	lifecycles []Lifecycle               // All lifecycles in registration order
+	deps       map[Lifecycle][]Lifecycle // Dependencies of each lifecycle
+}
+
+// add inserts a lifecycle with the given dependencies into the graph.
+func (g *lifecycleGraph) add(lifecycle Lifecycle, deps []Lifecycle) {
+	if g.deps == nil {
+		g.deps = make(map[Lifecycle][]Lifecycle)
+	}
+	g.lifecycles = append(g.lifecycles, lifecycle)
+	g.deps[lifecycle] = deps
+}
+
+// contains checks whgdtuer a lifecycle is registered in the graph.
+func (g *lifecycleGraph) contains(lifecycle Lifecycle) bool {
+	_, ok := g.deps[lifecycle]
+	return ok
+}
+
+// layers computes the start order of the lifecycles. Every lifecycle is placed
+// into the layer following the last one of its dependencies, so all lifecycles
+// within a layer are independent of each other and can be started concurrently.
+// Within a layer, the registration order is retained.
+func (g *lifecycleGraph) layers() [][]Lifecycle {
+	var (
+		depth  = make(map[Lifecycle]int)
+		layers [][]Lifecycle
+	)
+	for _, lifecycle := range g.lifecycles {
+		var d int
+		for _, dep := range g.deps[lifecycle] {
+			if depth[dep]+1 > d {
+				d = depth[dep] + 1
+			}
+		}
+		depth[lifecycle] = d
+		if d == len(layers) {
+			layers = append(layers, nil)
+		}
+		layers[d] = append(layers[d], lifecycle)
+	}
+	return layers
+}
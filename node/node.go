@@ -50,12 +50,13 @@ type Node struct {
 	state         int               // Tracks state of node lifecycle
 
 	lock          sync.Mutex
-	lifecycles    []Lifecycle // All registered backends, services, and auxiliary services that have a lifecycle
-	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
-	http          *httpServer //
-	ws            *httpServer //
-	ipc           *ipcServer  // Stores information about the ipc http server
-	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	lifecycles    lifecycleGraph // All registered backends, services, and auxiliary services that have a lifecycle
+	running       [][]Lifecycle  // Started lifecycles, in layers of the start order
+	rpcAPIs       []rpc.API      // List of APIs currently provided by the node
+	http          *httpServer    //
+	ws            *httpServer    //
+	ipc           *ipcServer     // Stores information about the ipc http server
+	inprocHandler *rpc.Server    // In-process RPC request handler to process the API requests
 
 	databases map[*closeTrackingDB]struct{} // All open databases
 }
@@ -134,6 +135,9 @@ func New(conf *Config) (*Node, error) {
 	if node.server.Config.NodeDatabase == "" {
 		node.server.Config.NodeDatabase = node.config.NodeDB()
 	}
+	if node.server.Config.PeerEventLog == "" {
+		node.server.Config.PeerEventLog = node.config.PeerEventLog()
+	}
 
 	// Check HTTP/WS prefixes are valid.
 	if err := validatePrefix("HTTP", conf.HTTPPathPrefix); err != nil {
@@ -143,10 +147,20 @@ func New(conf *Config) (*Node, error) {
 		return nil, err
 	}
 
+	// Check the RPC TLS configuration is complete.
+	tls := tlsConfig{certFile: conf.RPCTLSCert, keyFile: conf.RPCTLSKey, clientCAFile: conf.RPCTLSClientCA}
+	if err := tls.validate(); err != nil {
+		return nil, err
+	}
+
 	// Configure RPC servers.
 	node.http = newHTTPServer(node.log, conf.HTTPTimeouts)
+	node.http.http2, node.http.keepAlive = conf.HTTPEnableHTTP2, conf.HTTPKeepAlive
+	node.http.tls = tls
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
+	node.ws.tls = tls
 	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
+	node.ipc.callTimeout, node.ipc.slowCall = conf.RPCCallTimeout, conf.RPCSlowCallThreshold
 
 	return node, nil
 }
@@ -169,8 +183,7 @@ func (n *Node) Start() error {
 	n.state = runningState
 	// open networking and RPC endpoints
 	err := n.openEndpoints()
-	lifecycles := make([]Lifecycle, len(n.lifecycles))
-	copy(lifecycles, n.lifecycles)
+	layers := n.lifecycles.layers()
 	n.lock.Unlock()
 
 	// Check if endpoint startup failed.
@@ -178,20 +191,61 @@ func (n *Node) Start() error {
 		n.doClose(nil)
 		return err
 	}
-	// Start all registered lifecycles.
-	var started []Lifecycle
-	for _, lifecycle := range lifecycles {
-		if err = lifecycle.Start(); err != nil {
+	// Start all registered lifecycles, layer by layer.
+	var started [][]Lifecycle
+	for _, layer := range layers {
+		var ok []Lifecycle
+		ok, err = startLifecycles(layer)
+		started = append(started, ok)
+		if err != nil {
 			break
 		}
-		started = append(started, lifecycle)
 	}
 	// Check if any lifecycle failed to start.
 	if err != nil {
 		n.stopServices(started)
 		n.doClose(nil)
+		return err
 	}
-	return err
+	n.running = started
+	return nil
+}
+
+// startLifecycles concurrently starts a layer of independent lifecycles. It
+// returns the ones started successfully and the first error encountered, by
+// registration order.
+func startLifecycles(layer []Lifecycle) ([]Lifecycle, error) {
+	if len(layer) == 1 {
+		if err := layer[0].Start(); err != nil {
+			return nil, err
+		}
+		return layer, nil
+	}
+	var (
+		errs = make([]error, len(layer))
+		wg   sync.WaitGroup
+	)
+	for i, lifecycle := range layer {
+		wg.Add(1)
+		go func(i int, lifecycle Lifecycle) {
+			defer wg.Done()
+			errs[i] = lifecycle.Start()
+		}(i, lifecycle)
+	}
+	wg.Wait()
+
+	var (
+		started []Lifecycle
+		failure error
+	)
+	for i, err := range errs {
+		if err == nil {
+			started = append(started, layer[i])
+		} else if failure == nil {
+			failure = err
+		}
+	}
+	return started, failure
 }
 
 // Close stops the Node and releases resources acquired in
@@ -210,7 +264,7 @@ func (n *Node) Close() error {
 	case runningState:
 		// The node was started, release resources acquired by Start().
 		var errs []error
-		if err := n.stopServices(n.lifecycles); err != nil {
+		if err := n.stopServices(n.running); err != nil {
 			errs = append(errs, err)
 		}
 		return n.doClose(errs)
@@ -272,27 +326,30 @@ func (n *Node) openEndpoints() error {
 	return err
 }
 
-// containsLifecycle checks if 'lfs' contains 'l'.
-func containsLifecycle(lfs []Lifecycle, l Lifecycle) bool {
-	for _, obj := range lfs {
-		if obj == l {
-			return true
-		}
-	}
-	return false
-}
-
 // stopServices terminates running services, RPC and p2p networking.
 // It is the inverse of Start.
-func (n *Node) stopServices(running []Lifecycle) error {
+func (n *Node) stopServices(running [][]Lifecycle) error {
 	n.stopRPC()
 
-	// Stop running lifecycles in reverse order.
-	failure := &StopError{Services: make(map[reflect.Type]error)}
+	// Stop running lifecycles in reverse order, the ones of a layer concurrently.
+	var (
+		failure = &StopError{Services: make(map[reflect.Type]error)}
+		lock    sync.Mutex
+		wg      sync.WaitGroup
+	)
 	for i := len(running) - 1; i >= 0; i-- {
-		if err := running[i].Stop(); err != nil {
-			failure.Services[reflect.TypeOf(running[i])] = err
+		for _, lifecycle := range running[i] {
+			wg.Add(1)
+			go func(lifecycle Lifecycle) {
+				defer wg.Done()
+				if err := lifecycle.Stop(); err != nil {
+					lock.Lock()
+					failure.Services[reflect.TypeOf(lifecycle)] = err
+					lock.Unlock()
+				}
+			}(lifecycle)
 		}
+		wg.Wait()
 	}
 
 	// Stop p2p networking.
@@ -355,6 +412,8 @@ func (n *Node) startRPC() error {
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
 			prefix:             n.config.HTTPPathPrefix,
+			callTimeout:        n.config.RPCCallTimeout,
+			slowCall:           n.config.RPCSlowCallThreshold,
 		}
 		if err := n.http.setListenAddr(n.config.HTTPHost, n.config.HTTPPort); err != nil {
 			return err
@@ -368,9 +427,11 @@ func (n *Node) startRPC() error {
 	if n.config.WSHost != "" {
 		server := n.wsServerForPort(n.config.WSPort)
 		config := wsConfig{
-			Modules: n.config.WSModules,
-			Origins: n.config.WSOrigins,
-			prefix:  n.config.WSPathPrefix,
+			Modules:     n.config.WSModules,
+			Origins:     n.config.WSOrigins,
+			prefix:      n.config.WSPathPrefix,
+			callTimeout: n.config.RPCCallTimeout,
+			slowCall:    n.config.RPCSlowCallThreshold,
 		}
 		if err := server.setListenAddr(n.config.WSHost, n.config.WSPort); err != nil {
 			return err
@@ -420,18 +481,41 @@ func (n *Node) Wait() {
 	<-n.stop
 }
 
-// RegisterLifecycle registers the given Lifecycle on the node.
+// RegisterLifecycle registers the given Lifecycle on the node. It is started
+// after all lifecycles registered before it, and stopped before them.
 func (n *Node) RegisterLifecycle(lifecycle Lifecycle) {
 	n.lock.Lock()
 	defer n.lock.Unlock()
 
+	deps := make([]Lifecycle, len(n.lifecycles.lifecycles))
+	copy(deps, n.lifecycles.lifecycles)
+	n.registerLifecycle(lifecycle, deps)
+}
+
+// RegisterLifecycleDeps registers the given Lifecycle on the node, declaring the
+// lifecycles it depends on. It is started after its dependencies and stopped
+// before them, but concurrently with the lifecycles it is independent of. The
+// dependencies must already be registered.
+func (n *Node) RegisterLifecycleDeps(lifecycle Lifecycle, deps ...Lifecycle) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for _, dep := range deps {
+		if !n.lifecycles.contains(dep) {
+			panic(fmt.Sprintf("dependency %T of lifecycle %T is not registered", dep, lifecycle))
+		}
+	}
+	n.registerLifecycle(lifecycle, deps)
+}
+
+func (n *Node) registerLifecycle(lifecycle Lifecycle, deps []Lifecycle) {
 	if n.state != initializingState {
 		panic("can't register lifecycle on running/stopped node")
 	}
-	if containsLifecycle(n.lifecycles, lifecycle) {
+	if n.lifecycles.contains(lifecycle) {
 		panic(fmt.Sprintf("attempt to register lifecycle %T more than once", lifecycle))
 	}
-	n.lifecycles = append(n.lifecycles, lifecycle)
+	n.lifecycles.add(lifecycle, deps)
 }
 
 // RegisterProtocols adds backend's protocols to the node's p2p server.
@@ -527,15 +611,22 @@ func (n *Node) IPCEndpoint() string {
 // HTTPEndpoint returns the URL of the HTTP server. Note that this URL does not
 // contain the JSON-RPC path prefix set by HTTPPathPrefix.
 func (n *Node) HTTPEndpoint() string {
+	if n.http.tls.enabled() {
+		return "https://" + n.http.listenAddr()
+	}
 	return "http://" + n.http.listenAddr()
 }
 
 // WSEndpoint returns the current JSON-RPC over WebSocket endpoint.
 func (n *Node) WSEndpoint() string {
+	scheme := "ws://"
+	if n.ws.tls.enabled() {
+		scheme = "wss://"
+	}
 	if n.http.wsAllowed() {
-		return "ws://" + n.http.listenAddr() + n.http.wsConfig.prefix
+		return scheme + n.http.listenAddr() + n.http.wsConfig.prefix
 	}
-	return "ws://" + n.ws.listenAddr() + n.ws.wsConfig.prefix
+	return scheme + n.ws.listenAddr() + n.ws.wsConfig.prefix
 }
 
 // EventMux retrieves the event multiplexer used by all the network services in
@@ -26,7 +26,9 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/c88032111/go-gdtu/gdtudb"
@@ -122,7 +124,7 @@ func TestLifecycleRegistry_Successful(t *testing.T) {
 	noop := NewNoop()
 	stack.RegisterLifecycle(noop)
 
-	if !containsLifecycle(stack.lifecycles, noop) {
+	if !stack.lifecycles.contains(noop) {
 		t.Fatalf("lifecycle was not properly registered on the node, %v", err)
 	}
 }
@@ -264,6 +266,77 @@ func TestLifecycleLifeCycle(t *testing.T) {
 	}
 }
 
+// Tests that lifecycles are started after and stopped before their dependencies,
+// while independent lifecycles are started concurrently.
+func TestLifecycleDependencies(t *testing.T) {
+	stack, _ := New(testNodeConfig())
+	defer stack.Close()
+
+	var (
+		lock   sync.Mutex
+		events []string
+		record = func(event string) {
+			lock.Lock()
+			events = append(events, event)
+			lock.Unlock()
+		}
+		barrier sync.WaitGroup
+	)
+	// A and B only start once both of them are starting, requiring concurrency
+	barrier.Add(2)
+	independent := func(id string) *InstrumentedService {
+		return &InstrumentedService{
+			startHook: func() {
+				barrier.Done()
+				barrier.Wait()
+				record("start " + id)
+			},
+			stopHook: func() { record("stop " + id) },
+		}
+	}
+	a, b := independent("A"), independent("B")
+	c := &InstrumentedService{
+		startHook: func() { record("start C") },
+		stopHook:  func() { record("stop C") },
+	}
+	stack.RegisterLifecycleDeps(a)
+	stack.RegisterLifecycleDeps(b)
+	stack.RegisterLifecycleDeps(c, a, b)
+
+	done := make(chan error, 1)
+	go func() { done <- stack.Start() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("failed to start protocol stack: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("independent lifecycles not started concurrently")
+	}
+	if err := stack.Close(); err != nil {
+		t.Fatalf("failed to stop protocol stack: %v", err)
+	}
+	if len(events) != 6 {
+		t.Fatalf("wrong number of events: %v", events)
+	}
+	if events[2] != "start C" || events[3] != "stop C" {
+		t.Errorf("dependent lifecycle not ordered after its dependencies: %v", events)
+	}
+}
+
+// Tests that registering a lifecycle with an unknown dependency panics.
+func TestLifecycleUnknownDependency(t *testing.T) {
+	stack, _ := New(testNodeConfig())
+	defer stack.Close()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registration with unregistered dependency didn't panic")
+		}
+	}()
+	stack.RegisterLifecycleDeps(new(InstrumentedService), new(InstrumentedService))
+}
+
 // Tests that if a Lifecycle fails to start, all others started before it will be
 // shut down.
 func TestLifecycleStartupError(t *testing.T) {
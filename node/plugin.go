@@ -0,0 +1,75 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// PluginSymbol is the name of the function a Go plugin has to export in order to
+// be loaded into a node.
+const PluginSymbol = "RegisterPlugin"
+
+// PluginFunc is the signature of the function exported by a plugin. It is called
+// while the node is assembled, after the built-in services have been registered,
+// and may register APIs, protocols, lifecycles and HTTP handlers on the node just
+// like the gdtu and les services do. Plugins needing access to other services can
+// use the in-process RPC client returned by Attach once the node is running.
+type PluginFunc = func(stack *Node) error
+
+// LoadPlugins opens the Go plugins listed in the node configuration (built with
+// -buildmode=plugin against the same sources as the node) and lets them register
+// their services. It must be called before the node is started.
+func (n *Node) LoadPlugins() error {
+	for _, path := range n.config.Plugins {
+		if err := n.loadPlugin(path); err != nil {
+			return fmt.Errorf("failed to load plugin %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// loadPlugin opens a single Go plugin and runs its registration function.
+func (n *Node) loadPlugin(path string) error {
+	n.lock.Lock()
+	state := n.state
+	n.lock.Unlock()
+
+	if state != initializingState {
+		return ErrNodeRunning
+	}
+	p, err := plugin.Open(path)
+	if err != nil {
+		return err
+	}
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return err
+	}
+	n.log.Info("Loading plugin", "path", path)
+	return n.registerPlugin(sym)
+}
+
+// registerPlugin runs the registration function exported by a plugin.
+func (n *Node) registerPlugin(sym plugin.Symbol) error {
+	register, ok := sym.(PluginFunc)
+	if !ok {
+		return fmt.Errorf("symbol %s has type %T, want %T", PluginSymbol, sym, PluginFunc(nil))
+	}
+	return register(n)
+}
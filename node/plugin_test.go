@@ -0,0 +1,74 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"testing"
+
+	"github.com/c88032111/go-gdtu/rpc"
+)
+
+// Tests that the registration function of a plugin is invoked and may register
+// services on the node, while symbols of the wrong type are rejected.
+func TestRegisterPlugin(t *testing.T) {
+	stack, err := New(testNodeConfig())
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+
+	if err := stack.registerPlugin(func(*Node) {}); err == nil {
+		t.Fatal("plugin with invalid symbol type registered")
+	}
+	var register PluginFunc = func(stack *Node) error {
+		stack.RegisterAPIs([]rpc.API{{Namespace: "plugin", Version: "1.0", Service: new(struct{})}})
+		stack.RegisterLifecycle(&InstrumentedService{})
+		return nil
+	}
+	if err := stack.registerPlugin(register); err != nil {
+		t.Fatalf("failed to register plugin: %v", err)
+	}
+	if n := len(stack.rpcAPIs); n == 0 || stack.rpcAPIs[n-1].Namespace != "plugin" {
+		t.Fatalf("plugin API not registered")
+	}
+	if len(stack.lifecycles.lifecycles) != 1 {
+		t.Fatalf("plugin lifecycle not registered")
+	}
+}
+
+// Tests that plugins failing to load abort the startup and that plugins can't
+// be loaded into an already running node.
+func TestLoadPlugins(t *testing.T) {
+	config := testNodeConfig()
+	config.Plugins = []string{"does-not-exist.so"}
+
+	stack, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+
+	if err := stack.LoadPlugins(); err == nil {
+		t.Fatal("missing plugin loaded")
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	if err := stack.loadPlugin("does-not-exist.so"); err != ErrNodeRunning {
+		t.Fatalf("plugin load error mismatch: have %v, want %v", err, ErrNodeRunning)
+	}
+}
@@ -28,10 +28,13 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/c88032111/go-gdtu/log"
 	"github.com/c88032111/go-gdtu/rpc"
 	"github.com/rs/cors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // httpConfig is the JSON-RPC/HTTP configuration.
@@ -39,14 +42,18 @@ type httpConfig struct {
 	Modules            []string
 	CorsAllowedOrigins []string
 	Vhosts             []string
-	prefix             string // path prefix on which to mount http handler
+	prefix             string        // path prefix on which to mount http handler
+	callTimeout        time.Duration // maximum execution time of an RPC call
+	slowCall           time.Duration // execution time above which RPC calls are logged
 }
 
 // wsConfig is the JSON-RPC/Websocket configuration
 type wsConfig struct {
-	Origins []string
-	Modules []string
-	prefix  string // path prefix on which to mount ws handler
+	Origins     []string
+	Modules     []string
+	prefix      string        // path prefix on which to mount ws handler
+	callTimeout time.Duration // maximum execution time of an RPC call
+	slowCall    time.Duration // execution time above which RPC calls are logged
 }
 
 type rpcHandler struct {
@@ -55,13 +62,17 @@ type rpcHandler struct {
 }
 
 type httpServer struct {
-	log      log.Logger
-	timeouts rpc.HTTPTimeouts
-	mux      http.ServeMux // registered handlers go here
+	log       log.Logger
+	timeouts  rpc.HTTPTimeouts
+	http2     bool          // Whgdtuer to serve HTTP/2 over unencrypted connections
+	keepAlive time.Duration // TCP keep-alive period of the connections
+	tls       tlsConfig     // TLS certificate configuration, served in plain text if empty
+	mux       http.ServeMux // registered handlers go here
 
 	mu       sync.Mutex
 	server   *http.Server
-	listener net.Listener // non-nil when server is running
+	listener net.Listener  // non-nil when server is running
+	certs    *certReloader // non-nil when server is running over TLS
 
 	// HTTP RPC handler things.
 
@@ -131,21 +142,47 @@ func (h *httpServer) start() error {
 		h.server.WriteTimeout = h.timeouts.WriteTimeout
 		h.server.IdleTimeout = h.timeouts.IdleTimeout
 	}
+	if h.http2 {
+		h.server.Handler = h2c.NewHandler(h, &http2.Server{IdleTimeout: h.server.IdleTimeout})
+	}
+	if h.tls.enabled() {
+		if err := h.setupTLS(); err != nil {
+			h.disableRPC()
+			h.disableWS()
+			return err
+		}
+	}
 
 	// Start the server.
-	listener, err := net.Listen("tcp", h.endpoint)
+	config := net.ListenConfig{KeepAlive: h.keepAlive}
+	listener, err := config.Listen(context.Background(), "tcp", h.endpoint)
 	if err != nil {
 		// If the server fails to start, we need to clear out the RPC and WS
 		// configuration so they can be configured another time.
+		if h.certs != nil {
+			h.certs.stop()
+			h.certs = nil
+		}
 		h.disableRPC()
 		h.disableWS()
 		return err
 	}
 	h.listener = listener
-	go h.server.Serve(listener)
+	if h.certs != nil {
+		go h.server.ServeTLS(listener, "", "")
+	} else {
+		go h.server.Serve(listener)
+	}
 
+	scheme := "http"
+	if h.certs != nil {
+		scheme = "https"
+	}
 	if h.wsAllowed() {
 		url := fmt.Sprintf("ws://%v", listener.Addr())
+		if h.certs != nil {
+			url = fmt.Sprintf("wss://%v", listener.Addr())
+		}
 		if h.wsConfig.prefix != "" {
 			url += h.wsConfig.prefix
 		}
@@ -161,6 +198,8 @@ func (h *httpServer) start() error {
 		"prefix", h.httpConfig.prefix,
 		"cors", strings.Join(h.httpConfig.CorsAllowedOrigins, ","),
 		"vhosts", strings.Join(h.httpConfig.Vhosts, ","),
+		"http2", h.http2,
+		"tls", h.certs != nil,
 	)
 
 	// Log all handlers mounted on server.
@@ -173,13 +212,29 @@ func (h *httpServer) start() error {
 	for _, path := range paths {
 		name := h.handlerNames[path]
 		if !logged[name] {
-			log.Info(name+" enabled", "url", "http://"+listener.Addr().String()+path)
+			log.Info(name+" enabled", "url", scheme+"://"+listener.Addr().String()+path)
 			logged[name] = true
 		}
 	}
 	return nil
 }
 
+// setupTLS loads the certificate the server is served with and starts watching
+// it for renewals. This is internal, the caller must hold h.mu.
+func (h *httpServer) setupTLS() error {
+	certs, err := newCertReloader(h.tls.certFile, h.tls.keyFile, h.log)
+	if err != nil {
+		return err
+	}
+	config, err := newServerTLSConfig(h.tls, certs)
+	if err != nil {
+		certs.stop()
+		return err
+	}
+	h.server.TLSConfig, h.certs = config, certs
+	return nil
+}
+
 func (h *httpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// check if ws request and serve if ws enabled
 	ws := h.wsHandler.Load().(*rpcHandler)
@@ -262,11 +317,14 @@ func (h *httpServer) doStop() {
 	}
 	h.server.Shutdown(context.Background())
 	h.listener.Close()
+	if h.certs != nil {
+		h.certs.stop()
+	}
 	h.log.Info("HTTP server stopped", "endpoint", h.listener.Addr())
 
 	// Clear out everything to allow re-configuring it later.
 	h.host, h.port, h.endpoint = "", 0, ""
-	h.server, h.listener = nil, nil
+	h.server, h.listener, h.certs = nil, nil, nil
 }
 
 // enableRPC turns on JSON-RPC over HTTP on the server.
@@ -280,6 +338,7 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
+	srv.SetCallLimits(config.callTimeout, config.slowCall)
 	if err := RegisterApisFromWhitelist(apis, config.Modules, srv, false); err != nil {
 		return err
 	}
@@ -312,6 +371,7 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
+	srv.SetCallLimits(config.callTimeout, config.slowCall)
 	if err := RegisterApisFromWhitelist(apis, config.Modules, srv, false); err != nil {
 		return err
 	}
@@ -471,8 +531,10 @@ func newGzipHandler(next http.Handler) http.Handler {
 }
 
 type ipcServer struct {
-	log      log.Logger
-	endpoint string
+	log         log.Logger
+	endpoint    string
+	callTimeout time.Duration // maximum execution time of an RPC call
+	slowCall    time.Duration // execution time above which RPC calls are logged
 
 	mu       sync.Mutex
 	listener net.Listener
@@ -496,6 +558,7 @@ func (is *ipcServer) start(apis []rpc.API) error {
 		is.log.Warn("IPC opening failed", "url", is.endpoint, "error", err)
 		return err
 	}
+	srv.SetCallLimits(is.callTimeout, is.slowCall)
 	is.log.Info("IPC endpoint opened", "url", is.endpoint)
 	is.listener, is.srv = listener, srv
 	return nil
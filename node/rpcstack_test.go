@@ -18,7 +18,9 @@ package node
 
 import (
 	"bytes"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -30,6 +32,7 @@ import (
 	"github.com/c88032111/go-gdtu/rpc"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
 )
 
 // TestCorsHandler makes sure CORS are properly handled on the http server.
@@ -229,6 +232,52 @@ func Test_checkPath(t *testing.T) {
 	}
 }
 
+// TestHTTP2 makes sure the server serves HTTP/2 over unencrypted connections
+// only if enabled, and that clients can use it.
+func TestHTTP2(t *testing.T) {
+	h2c := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	body := `{"jsonrpc":"2.0","id":1,"method":"rpc_modules"}`
+	for _, enabled := range []bool{false, true} {
+		srv := newHTTPServer(testlog.Logger(t, log.LvlDebug), rpc.DefaultHTTPTimeouts)
+		srv.http2 = enabled
+		assert.NoError(t, srv.enableRPC(nil, httpConfig{}))
+		assert.NoError(t, srv.setListenAddr("localhost", 0))
+		assert.NoError(t, srv.start())
+
+		url := "http://" + srv.listenAddr()
+		resp, err := h2c.Post(url, "application/json", strings.NewReader(body))
+		if !enabled {
+			if err == nil {
+				resp.Body.Close()
+				t.Fatalf("HTTP/2 request succeeded with HTTP/2 disabled")
+			}
+			srv.stop()
+			continue
+		}
+		if err != nil {
+			t.Fatalf("HTTP/2 request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.ProtoMajor != 2 {
+			t.Fatalf("protocol mismatch: have %s, want HTTP/2", resp.Proto)
+		}
+		client, err := rpc.DialHTTPWithOptions(url, rpc.DialOptions{HTTP2: true})
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		if _, err := client.SupportedModules(); err != nil {
+			t.Fatalf("HTTP/2 client call failed: %v", err)
+		}
+		client.Close()
+		srv.stop()
+	}
+}
+
 func createAndStartServer(t *testing.T, conf *httpConfig, ws bool, wsConf *wsConfig) *httpServer {
 	t.Helper()
 
@@ -0,0 +1,174 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/log"
+)
+
+// certReloadInterval is the time between two checks of the certificate files
+// for renewals.
+const certReloadInterval = time.Minute
+
+// tlsConfig is the TLS configuration of an RPC server.
+type tlsConfig struct {
+	certFile     string // PEM encoded certificate chain
+	keyFile      string // PEM encoded private key
+	clientCAFile string // PEM encoded authorities of accepted client certificates
+}
+
+// enabled returns whgdtuer the server is to be served over TLS.
+func (c tlsConfig) enabled() bool {
+	return c.certFile != ""
+}
+
+// validate checks that the certificate and the key are configured together.
+func (c tlsConfig) validate() error {
+	if (c.certFile == "") != (c.keyFile == "") {
+		return errors.New("RPC TLS certificate and key must be configured together")
+	}
+	if c.clientCAFile != "" && c.certFile == "" {
+		return errors.New("RPC TLS client authentication requires a server certificate")
+	}
+	return nil
+}
+
+// certReloader serves the TLS certificate of an RPC server, reloading it when
+// the certificate or key files are modified, e.g. by an ACME client renewing
+// them.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	log      log.Logger
+
+	cert     *tls.Certificate
+	modTimes [2]time.Time // Modification times of the loaded certificate and key
+	lock     sync.RWMutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newCertReloader loads the certificate and starts watching its files.
+func newCertReloader(certFile, keyFile string, logger log.Logger) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		log:      logger,
+		quit:     make(chan struct{}),
+	}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	r.wg.Add(1)
+	go r.loop()
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, as required by tls.Config.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.cert, nil
+}
+
+// reload loads the certificate if its files were modified since the last load,
+// reporting whgdtuer it did.
+func (r *certReloader) reload() (bool, error) {
+	var modTimes [2]time.Time
+	for i, file := range []string{r.certFile, r.keyFile} {
+		stat, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		modTimes[i] = stat.ModTime()
+	}
+	r.lock.RLock()
+	unchanged := r.cert != nil && modTimes == r.modTimes
+	r.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to load RPC TLS certificate: %v", err)
+	}
+	r.lock.Lock()
+	r.cert, r.modTimes = &cert, modTimes
+	r.lock.Unlock()
+	return true, nil
+}
+
+// loop periodically checks the certificate files for renewals. A renewal which
+// fails to load, e.g. because the files are only partially written, keeps the
+// previous certificate in use until the next check.
+func (r *certReloader) loop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(certReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if reloaded, err := r.reload(); err != nil {
+				r.log.Warn("Failed to reload RPC TLS certificate", "cert", r.certFile, "err", err)
+			} else if reloaded {
+				r.log.Info("Reloaded RPC TLS certificate", "cert", r.certFile)
+			}
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// stop terminates the file watcher.
+func (r *certReloader) stop() {
+	close(r.quit)
+	r.wg.Wait()
+}
+
+// newServerTLSConfig creates the TLS configuration of an RPC server, serving the
+// certificate of the given reloader.
+func newServerTLSConfig(config tlsConfig, reloader *certReloader) (*tls.Config, error) {
+	conf := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if config.clientCAFile != "" {
+		pem, err := ioutil.ReadFile(config.clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RPC TLS client CA: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in RPC TLS client CA %s", config.clientCAFile)
+		}
+		conf.ClientCAs, conf.ClientAuth = pool, tls.RequireAndVerifyClientCert
+	}
+	return conf, nil
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/internal/testlog"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/rpc"
+)
+
+// testCert is a self-signed certificate, usable both as server certificate and
+// as authority of client certificates.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pair tls.Certificate
+}
+
+func newTestCert(t *testing.T, serial int64) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, pair: tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}}
+}
+
+// write stores the certificate and key as PEM files.
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	keyDer, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tlsClient creates an HTTP client trusting the given server certificate and
+// presenting the given client certificate, if any.
+func tlsClient(server *testCert, client *testCert) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(server.cert)
+
+	config := &tls.Config{RootCAs: roots}
+	if client != nil {
+		config.Certificates = []tls.Certificate{client.pair}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}}
+}
+
+// Tests that the RPC server is served over TLS, picking up renewed certificates
+// and enforcing client certificates if configured.
+func TestRPCTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpctls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile  = filepath.Join(dir, "key.pem")
+		caFile   = filepath.Join(dir, "ca.pem")
+		body     = `{"jsonrpc":"2.0","id":1,"method":"rpc_modules"}`
+	)
+	first, second, client := newTestCert(t, 1), newTestCert(t, 2), newTestCert(t, 3)
+	first.write(t, certFile, keyFile)
+
+	srv := newHTTPServer(testlog.Logger(t, log.LvlDebug), rpc.DefaultHTTPTimeouts)
+	srv.tls = tlsConfig{certFile: certFile, keyFile: keyFile}
+	if err := srv.enableRPC(nil, httpConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.setListenAddr("localhost", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.start(); err != nil {
+		t.Fatalf("failed to start TLS server: %v", err)
+	}
+	url := "https://" + srv.listenAddr()
+
+	resp, err := tlsClient(first, nil).Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("TLS request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp, err := http.Post("http://"+srv.listenAddr(), "application/json", strings.NewReader(body)); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("plain text request served on TLS server: %s", resp.Status)
+		}
+	}
+	// Renew the certificate and ensure the new one is served after reloading
+	second.write(t, certFile, keyFile)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+
+	if reloaded, err := srv.certs.reload(); err != nil || !reloaded {
+		t.Fatalf("certificate not reloaded: %v", err)
+	}
+	if _, err := tlsClient(first, nil).Post(url, "application/json", strings.NewReader(body)); err == nil {
+		t.Fatalf("renewed certificate not served")
+	}
+	resp, err = tlsClient(second, nil).Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("TLS request with renewed certificate failed: %v", err)
+	}
+	resp.Body.Close()
+	srv.stop()
+
+	// Restart the server requiring client certificates
+	if err := ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: client.cert.Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	srv.tls.clientCAFile = caFile
+	if err := srv.enableRPC(nil, httpConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.setListenAddr("localhost", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.start(); err != nil {
+		t.Fatalf("failed to start TLS server: %v", err)
+	}
+	defer srv.stop()
+	url = "https://" + srv.listenAddr()
+
+	if _, err := tlsClient(second, nil).Post(url, "application/json", strings.NewReader(body)); err == nil {
+		t.Fatalf("request without client certificate succeeded")
+	}
+	if _, err := tlsClient(second, first).Post(url, "application/json", strings.NewReader(body)); err == nil {
+		t.Fatalf("request with untrusted client certificate succeeded")
+	}
+	resp, err = tlsClient(second, client).Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("request with client certificate failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestRPCTLSConfigValidation(t *testing.T) {
+	tests := []struct {
+		config tlsConfig
+		valid  bool
+	}{
+		{tlsConfig{}, true},
+		{tlsConfig{certFile: "cert", keyFile: "key"}, true},
+		{tlsConfig{certFile: "cert", keyFile: "key", clientCAFile: "ca"}, true},
+		{tlsConfig{certFile: "cert"}, false},
+		{tlsConfig{keyFile: "key"}, false},
+		{tlsConfig{clientCAFile: "ca"}, false},
+	}
+	for i, tt := range tests {
+		if err := tt.config.validate(); (err == nil) != tt.valid {
+			t.Errorf("test %d: validation error %v, want valid %v", i, err, tt.valid)
+		}
+	}
+}
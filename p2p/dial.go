@@ -43,6 +43,9 @@ const (
 	dialStatsLogInterval = 10 * time.Second // printed at most this often
 	dialStatsPeerLimit   = 3                // but not if more than this many dialed peers
 
+	// Dynamic dials are limited per network to make eclipsing harder.
+	dialSubnet, dialSubnet6 = 24, 64
+
 	// Endpoint resolution is throttled with bounded backoff.
 	initialResolveDelay = 60 * time.Second
 	maxResolveDelay     = time.Hour
@@ -58,15 +61,6 @@ type nodeResolver interface {
 	Resolve(*enode.Node) *enode.Node
 }
 
-// tcpDialer implements NodeDialer using real TCP connections.
-type tcpDialer struct {
-	d *net.Dialer
-}
-
-func (t tcpDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
-	return t.d.DialContext(ctx, "tcp", nodeAddr(dest).String())
-}
-
 func nodeAddr(n *enode.Node) net.Addr {
 	return &net.TCPAddr{IP: n.IP(), Port: n.TCP()}
 }
@@ -79,6 +73,7 @@ var (
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNotWhitelisted   = errors.New("not contained in netrestrict whitelist")
 	errNoPort           = errors.New("node does not provide TCP port")
+	errDialIPLimit      = errors.New("too many dials to the same network")
 )
 
 // dialer creates outbound connections and submits them into Server.
@@ -110,6 +105,11 @@ type dialScheduler struct {
 	peers     map[enode.ID]connFlag  // all connected peers
 	dialPeers int                    // current number of dialed peers
 
+	// The IP set limits the dynamic dials and dialed peers per network. The
+	// dialIPs map holds the address of every node occupying a slot in the set.
+	ips     netutil.DistinctNetSet
+	dialIPs map[enode.ID]net.IP
+
 	// The static map tracks all static dial tasks. The subset of usable static dial tasks
 	// (i.e. those passing checkDial) is kept in staticPool. The scheduler prefers
 	// launching random static tasks from the pool over launching dynamic dials from the
@@ -134,6 +134,7 @@ type dialConfig struct {
 	maxDialPeers   int              // maximum number of dialed peers
 	maxActiveDials int              // maximum number of active dials
 	netRestrict    *netutil.Netlist // IP whitelist, disabled if nil
+	ipLimit        int              // maximum number of dynamic dials per /24 (/64 for IPv6), disabled if not positive
 	resolver       nodeResolver
 	dialer         NodeDialer
 	log            log.Logger
@@ -173,7 +174,9 @@ func newDialScheduler(config dialConfig, it enode.Iterator, setupFunc dialSetupF
 		remStaticCh: make(chan *enode.Node),
 		addPeerCh:   make(chan *conn),
 		remPeerCh:   make(chan *conn),
+		dialIPs:     make(map[enode.ID]net.IP),
 	}
+	d.ips = netutil.DistinctNetSet{Subnet: dialSubnet, Subnet6: dialSubnet6, Limit: uint(d.ipLimit)}
 	d.lastStatsLog = d.clock.Now()
 	d.ctx, d.cancel = context.WithCancel(context.Background())
 	d.wg.Add(2)
@@ -242,7 +245,11 @@ loop:
 
 		select {
 		case node := <-nodesCh:
-			if err := d.checkDial(node); err != nil {
+			err := d.checkDial(node)
+			if err == nil {
+				err = d.addDialIP(node)
+			}
+			if err != nil {
 				d.log.Trace("Discarding dial candidate", "id", node.ID(), "ip", node.IP(), "reason", err)
 			} else {
 				d.startDial(newDialTask(node, dynDialedConn))
@@ -251,6 +258,9 @@ loop:
 		case task := <-d.doneCh:
 			id := task.dest.ID()
 			delete(d.dialing, id)
+			if _, ok := d.peers[id]; !ok {
+				d.removeDialIP(id)
+			}
 			d.updateStaticPool(id)
 			d.doneSinceLastLog++
 
@@ -272,6 +282,7 @@ loop:
 				d.dialPeers--
 			}
 			delete(d.peers, c.node.ID())
+			d.removeDialIP(c.node.ID())
 			d.updateStaticPool(c.node.ID())
 
 		case node := <-d.addStaticCh:
@@ -410,6 +421,31 @@ func (d *dialScheduler) checkDial(n *enode.Node) error {
 	return nil
 }
 
+// addDialIP reserves a slot for a dynamic dial in the IP set, returning an error
+// if the network of the node already has too many dials or dialed peers. The slot
+// is held until the dial fails or the resulting peer disconnects. Static dials
+// and LAN addresses are not limited.
+func (d *dialScheduler) addDialIP(n *enode.Node) error {
+	ip := n.IP()
+	if d.ipLimit <= 0 || ip == nil || netutil.IsLAN(ip) {
+		return nil
+	}
+	if !d.ips.Add(ip) {
+		dialIPLimitMeter.Mark(1)
+		return errDialIPLimit
+	}
+	d.dialIPs[n.ID()] = ip
+	return nil
+}
+
+// removeDialIP releases the IP set slot held by the given node, if any.
+func (d *dialScheduler) removeDialIP(id enode.ID) {
+	if ip, ok := d.dialIPs[id]; ok {
+		d.ips.Remove(ip)
+		delete(d.dialIPs, id)
+	}
+}
+
 // startStaticDials starts n static dial tasks.
 func (d *dialScheduler) startStaticDials(n int) (started int) {
 	for started = 0; started < n && len(d.staticPool) > 0; started++ {
@@ -152,6 +152,46 @@ func TestDialSchedNetRestrict(t *testing.T) {
 	})
 }
 
+// This test checks that dynamic dials to the same network are limited.
+func TestDialSchedIPLimit(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*enode.Node{
+		newNode(uintID(0x01), "8.8.8.1:30303"),
+		newNode(uintID(0x02), "8.8.8.2:30303"),
+		newNode(uintID(0x03), "8.8.8.3:30303"), // not dialed because of the IP limit
+		newNode(uintID(0x04), "8.8.9.4:30303"),
+		newNode(uintID(0x05), "8.8.8.5:30303"),
+		newNode(uintID(0x06), "8.8.8.6:30303"),
+	}
+	config := dialConfig{
+		ipLimit:        2,
+		maxActiveDials: 10,
+		maxDialPeers:   10,
+	}
+	runDialTest(t, config, []dialTestRound{
+		{
+			discovered:   nodes[:4],
+			wantNewDials: []*enode.Node{nodes[0], nodes[1], nodes[3]},
+		},
+		// The failed dial frees a slot in the network, the successful one keeps it.
+		{
+			succeeded: []enode.ID{nodes[0].ID()},
+			failed:    []enode.ID{nodes[1].ID()},
+		},
+		{
+			discovered:   nodes[4:5],
+			wantNewDials: nodes[4:5],
+		},
+		// The peer disconnecting frees its slot.
+		{
+			peersRemoved: []enode.ID{nodes[0].ID()},
+			discovered:   nodes[5:6],
+			wantNewDials: nodes[5:6],
+		},
+	})
+}
+
 // This test checks that static dials work and obey the limits.
 func TestDialSchedStaticDial(t *testing.T) {
 	t.Parallel()
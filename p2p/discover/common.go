@@ -19,6 +19,7 @@ package discover
 import (
 	"crypto/ecdsa"
 	"net"
+	"time"
 
 	"github.com/c88032111/go-gdtu/common/mclock"
 	"github.com/c88032111/go-gdtu/log"
@@ -47,6 +48,17 @@ type Config struct {
 	Log          log.Logger         // if set, log messages go here
 	ValidSchemes enr.IdentityScheme // allowed identity schemes
 	Clock        mclock.Clock
+
+	// The IP limits bound the number of table entries in the same /24 network
+	// (/64 for IPv6), both table-wide and per bucket. LAN addresses are exempt.
+	// Zero selects the default limits, negative values disable them.
+	TableIPLimit  int
+	BucketIPLimit int
+
+	// Nodes which sent a valid packet within the liveness freshness are considered
+	// live on revalidation, without pinging them. Zero selects the default,
+	// negative values disable passive liveness verification.
+	LivenessFreshness time.Duration
 }
 
 func (cfg Config) withDefaults() Config {
@@ -59,6 +71,15 @@ func (cfg Config) withDefaults() Config {
 	if cfg.Clock == nil {
 		cfg.Clock = mclock.System{}
 	}
+	if cfg.TableIPLimit == 0 {
+		cfg.TableIPLimit = tableIPLimit
+	}
+	if cfg.BucketIPLimit == 0 {
+		cfg.BucketIPLimit = bucketIPLimit
+	}
+	if cfg.LivenessFreshness == 0 {
+		cfg.LivenessFreshness = livenessFreshness
+	}
 	return cfg
 }
 
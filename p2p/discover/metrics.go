@@ -0,0 +1,30 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import "github.com/c88032111/go-gdtu/metrics"
+
+var (
+	// Nodes rejected because their network exceeds the table or bucket IP limit.
+	tableIPLimitMeter  = metrics.NewRegisteredMeter("discover/table/iplimit", nil)
+	bucketIPLimitMeter = metrics.NewRegisteredMeter("discover/bucket/iplimit", nil)
+
+	// Outcomes of the liveness checks of table nodes.
+	revalidateLiveMeter    = metrics.NewRegisteredMeter("discover/revalidate/live", nil)
+	revalidateDeadMeter    = metrics.NewRegisteredMeter("discover/revalidate/dead", nil)
+	revalidatePassiveMeter = metrics.NewRegisteredMeter("discover/revalidate/passive", nil)
+)
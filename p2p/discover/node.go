@@ -34,6 +34,8 @@ import (
 type node struct {
 	enode.Node
 	addedAt        time.Time // time when the node was added to the table
+	lastReceived   time.Time // time a reply to our own request was last received from the node
+	remoteSeq      uint64    // highest ENR sequence number announced by the node
 	livenessChecks uint      // how often liveness was checked
 }
 
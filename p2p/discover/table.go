@@ -26,6 +26,7 @@ import (
 	crand "crypto/rand"
 	"encoding/binary"
 	"fmt"
+	"math"
 	mrand "math/rand"
 	"net"
 	"sort"
@@ -52,9 +53,11 @@ const (
 	// IP address limits.
 	bucketIPLimit, bucketSubnet = 2, 24 // at most 2 addresses from the same /24
 	tableIPLimit, tableSubnet   = 10, 24
+	ipv6Subnet                  = 64 // IPv6 addresses are grouped by /64
 
 	refreshInterval    = 30 * time.Minute
 	revalidateInterval = 10 * time.Second
+	livenessFreshness  = 2 * time.Minute
 	copyNodesInterval  = 30 * time.Second
 	seedMinTableTime   = 5 * time.Minute
 	seedCount          = 30
@@ -72,7 +75,9 @@ type Table struct {
 	ips     netutil.DistinctNetSet
 
 	log        log.Logger
-	db         *enode.DB // database of known nodes
+	db         *enode.DB         // database of known nodes
+	freshness  time.Duration     // age of received packets still proving liveness (negative = disabled)
+	stats      RevalidationStats // counters of the liveness checks, protected by mutex
 	net        transport
 	refreshReq chan chan struct{}
 	initDone   chan struct{}
@@ -99,7 +104,7 @@ type bucket struct {
 	ips          netutil.DistinctNetSet
 }
 
-func newTable(t transport, db *enode.DB, bootnodes []*enode.Node, log log.Logger) (*Table, error) {
+func newTable(t transport, db *enode.DB, cfg Config) (*Table, error) {
 	tab := &Table{
 		net:        t,
 		db:         db,
@@ -108,15 +113,16 @@ func newTable(t transport, db *enode.DB, bootnodes []*enode.Node, log log.Logger
 		closeReq:   make(chan struct{}),
 		closed:     make(chan struct{}),
 		rand:       mrand.New(mrand.NewSource(0)),
-		ips:        netutil.DistinctNetSet{Subnet: tableSubnet, Limit: tableIPLimit},
-		log:        log,
+		ips:        netutil.DistinctNetSet{Subnet: tableSubnet, Subnet6: ipv6Subnet, Limit: ipLimit(cfg.TableIPLimit)},
+		log:        cfg.Log,
+		freshness:  cfg.LivenessFreshness,
 	}
-	if err := tab.setFallbackNodes(bootnodes); err != nil {
+	if err := tab.setFallbackNodes(cfg.Bootnodes); err != nil {
 		return nil, err
 	}
 	for i := range tab.buckets {
 		tab.buckets[i] = &bucket{
-			ips: netutil.DistinctNetSet{Subnet: bucketSubnet, Limit: bucketIPLimit},
+			ips: netutil.DistinctNetSet{Subnet: bucketSubnet, Subnet6: ipv6Subnet, Limit: ipLimit(cfg.BucketIPLimit)},
 		}
 	}
 	tab.seedRand()
@@ -125,6 +131,15 @@ func newTable(t transport, db *enode.DB, bootnodes []*enode.Node, log log.Logger
 	return tab, nil
 }
 
+// ipLimit converts a configured IP limit into the limit of an address set,
+// negative values meaning no limit at all.
+func ipLimit(limit int) uint {
+	if limit < 0 {
+		return math.MaxUint32
+	}
+	return uint(limit)
+}
+
 func (tab *Table) self() *enode.Node {
 	return tab.net.Self()
 }
@@ -321,7 +336,10 @@ func (tab *Table) doRevalidate(done chan<- struct{}) {
 		// No non-empty bucket found.
 		return
 	}
-
+	// Skip the ping if the node was heard from recently.
+	if tab.revalidatePassive(last, bi) {
+		return
+	}
 	// Ping the selected node and wait for a pgdtu.
 	remoteSeq, err := tab.net.ping(unwrapNode(last))
 
@@ -343,8 +361,13 @@ func (tab *Table) doRevalidate(done chan<- struct{}) {
 		last.livenessChecks++
 		tab.log.Debug("Revalidated node", "b", bi, "id", last.ID(), "checks", last.livenessChecks)
 		tab.bumpInBucket(b, last)
+		tab.stats.Live++
+		revalidateLiveMeter.Mark(1)
 		return
 	}
+	tab.stats.Dead++
+	revalidateDeadMeter.Mark(1)
+
 	// No reply received, pick a replacement or delete the node if there aren't
 	// any replacements.
 	if r := tab.replace(b, last); r != nil {
@@ -354,6 +377,86 @@ func (tab *Table) doRevalidate(done chan<- struct{}) {
 	}
 }
 
+// revalidatePassive moves a node to the front of its bucket without pinging it, if
+// it answered one of our requests within the liveness freshness. Nodes which
+// announced a newer record are always pinged, so the record gets refreshed.
+func (tab *Table) revalidatePassive(n *node, bi int) bool {
+	if tab.freshness < 0 {
+		return false
+	}
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+
+	if n.lastReceived.IsZero() || time.Since(n.lastReceived) > tab.freshness {
+		return false
+	}
+	if n.remoteSeq > n.Seq() {
+		return false
+	}
+	if !tab.bumpInBucket(tab.buckets[bi], n) {
+		return false
+	}
+	n.livenessChecks++
+	tab.log.Trace("Revalidated node passively", "b", bi, "id", n.ID(), "checks", n.livenessChecks)
+	tab.stats.Passive++
+	revalidatePassiveMeter.Mark(1)
+	return true
+}
+
+// markReceived records that the given node answered one of our requests. Only
+// replies matched to a request count as proof of liveness, as anyone can replay
+// other packets signed by the node, and only if they came from the endpoint of
+// the node in the table.
+func (tab *Table) markReceived(id enode.ID, addr *net.UDPAddr) {
+	if tab.freshness < 0 {
+		return
+	}
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+
+	for _, n := range tab.bucket(id).entries {
+		if n.ID() == id {
+			if n.IP().Equal(addr.IP) && n.UDP() == addr.Port {
+				n.lastReceived = time.Now()
+			}
+			return
+		}
+	}
+}
+
+// markSeq records the ENR sequence number announced by the given node.
+func (tab *Table) markSeq(id enode.ID, seq uint64) {
+	if tab.freshness < 0 {
+		return
+	}
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+
+	for _, n := range tab.bucket(id).entries {
+		if n.ID() == id {
+			if seq > n.remoteSeq {
+				n.remoteSeq = seq
+			}
+			return
+		}
+	}
+}
+
+// revalidationStats returns the counters of the liveness checks.
+func (tab *Table) revalidationStats() RevalidationStats {
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+
+	return tab.stats
+}
+
+// RevalidationStats are the counters of the liveness checks of table nodes.
+type RevalidationStats struct {
+	Live    uint64 `json:"live"`    // Nodes which answered the ping
+	Dead    uint64 `json:"dead"`    // Nodes which didn't answer the ping and were dropped
+	Passive uint64 `json:"passive"` // Nodes considered live due to recent packets, without a ping
+}
+
 // nodeToRevalidate returns the last node in a random, non-empty bucket.
 func (tab *Table) nodeToRevalidate() (n *node, bi int) {
 	tab.mutex.Lock()
@@ -549,10 +652,12 @@ func (tab *Table) addIP(b *bucket, ip net.IP) bool {
 	}
 	if !tab.ips.Add(ip) {
 		tab.log.Debug("IP exceeds table limit", "ip", ip)
+		tableIPLimitMeter.Mark(1)
 		return false
 	}
 	if !b.ips.Add(ip) {
 		tab.log.Debug("IP exceeds bucket limit", "ip", ip)
+		bucketIPLimitMeter.Mark(1)
 		tab.ips.Remove(ip)
 		return false
 	}
@@ -92,6 +92,57 @@ func testPingReplace(t *testing.T, newNodeIsResponding, lastInBucketIsResponding
 	}
 }
 
+func TestTable_revalidatePassive(t *testing.T) {
+	transport := newPingRecorder()
+	tab, db := newTestTable(transport)
+	defer db.Close()
+	defer tab.close()
+
+	<-tab.initDone
+
+	tab.mutex.Lock()
+	last := fillBucket(tab, nodeAtDistance(tab.self().ID(), 255, intIP(255)))
+	tab.mutex.Unlock()
+
+	// Packets from another endpoint don't prove liveness.
+	tab.markReceived(last.ID(), &net.UDPAddr{IP: net.IP{10, 0, 0, 1}, Port: last.UDP()})
+	tab.doRevalidate(make(chan struct{}, 1))
+	if !transport.pinged[last.ID()] {
+		t.Fatal("table did not ping last node after packet from other endpoint")
+	}
+	if stats := tab.revalidationStats(); stats != (RevalidationStats{Live: 1}) {
+		t.Fatalf("wrong stats after ping: %+v", stats)
+	}
+	// Packets from the node's endpoint make the ping unnecessary.
+	delete(transport.pinged, last.ID())
+	tab.mutex.Lock()
+	last = tab.bucket(last.ID()).entries[bucketSize-1]
+	tab.mutex.Unlock()
+
+	tab.markReceived(last.ID(), &net.UDPAddr{IP: last.IP(), Port: last.UDP()})
+	tab.doRevalidate(make(chan struct{}, 1))
+	if transport.pinged[last.ID()] {
+		t.Fatal("table pinged last node despite recent packet")
+	}
+	if stats := tab.revalidationStats(); stats != (RevalidationStats{Live: 1, Passive: 1}) {
+		t.Fatalf("wrong stats after passive revalidation: %+v", stats)
+	}
+	tab.mutex.Lock()
+	if front := tab.bucket(last.ID()).entries[0]; front.ID() != last.ID() || front.livenessChecks != 1 {
+		t.Fatalf("passively revalidated node not moved to front")
+	}
+	last = tab.bucket(last.ID()).entries[bucketSize-1]
+	tab.mutex.Unlock()
+
+	// Nodes announcing a newer record are pinged to refresh it.
+	tab.markReceived(last.ID(), &net.UDPAddr{IP: last.IP(), Port: last.UDP()})
+	tab.markSeq(last.ID(), last.Seq()+1)
+	tab.doRevalidate(make(chan struct{}, 1))
+	if !transport.pinged[last.ID()] {
+		t.Fatal("table did not ping last node announcing a newer record")
+	}
+}
+
 func TestBucket_bumpNoDuplicates(t *testing.T) {
 	t.Parallel()
 	cfg := &quick.Config{
@@ -174,12 +225,30 @@ func TestTable_BucketIPLimit(t *testing.T) {
 	checkIPLimitInvariant(t, tab)
 }
 
+// This checks that the IP limits can be disabled for private networks.
+func TestTable_NoIPLimit(t *testing.T) {
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+	tab, _ := newTable(newPingRecorder(), db, Config{TableIPLimit: -1, BucketIPLimit: -1}.withDefaults())
+	go tab.loop()
+	defer tab.close()
+
+	// All nodes fall into the first bucket, exceeding both default limits.
+	for i := 1; i <= tableIPLimit+1; i++ {
+		n := nodeAtDistance(tab.self().ID(), i, net.IP{172, 0, 1, byte(i)})
+		tab.addSeenNode(n)
+	}
+	if tab.len() != tableIPLimit+1 {
+		t.Errorf("wrong number of nodes in table: got %d, want %d", tab.len(), tableIPLimit+1)
+	}
+}
+
 // checkIPLimitInvariant checks that ip limit sets contain an entry for every
 // node in the table and no extra entries.
 func checkIPLimitInvariant(t *testing.T, tab *Table) {
 	t.Helper()
 
-	tabset := netutil.DistinctNetSet{Subnet: tableSubnet, Limit: tableIPLimit}
+	tabset := netutil.DistinctNetSet{Subnet: tableSubnet, Subnet6: ipv6Subnet, Limit: tableIPLimit}
 	for _, b := range tab.buckets {
 		for _, n := range b.entries {
 			tabset.Add(n.IP())
@@ -28,7 +28,6 @@ import (
 	"sync"
 
 	"github.com/c88032111/go-gdtu/crypto"
-	"github.com/c88032111/go-gdtu/log"
 	"github.com/c88032111/go-gdtu/p2p/enode"
 	"github.com/c88032111/go-gdtu/p2p/enr"
 )
@@ -43,7 +42,7 @@ func init() {
 
 func newTestTable(t transport) (*Table, *enode.DB) {
 	db, _ := enode.OpenDB("")
-	tab, _ := newTable(t, db, nil, log.Root())
+	tab, _ := newTable(t, db, Config{}.withDefaults())
 	go tab.loop()
 	return tab, db
 }
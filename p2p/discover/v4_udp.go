@@ -143,7 +143,7 @@ func ListenV4(c UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv4, error) {
 		log:             cfg.Log,
 	}
 
-	tab, err := newTable(t, ln.Database(), cfg.Bootnodes, t.log)
+	tab, err := newTable(t, ln.Database(), cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -161,6 +161,11 @@ func (t *UDPv4) Self() *enode.Node {
 	return t.localNode.Node()
 }
 
+// RevalidationStats returns the counters of the liveness checks of table nodes.
+func (t *UDPv4) RevalidationStats() RevalidationStats {
+	return t.tab.revalidationStats()
+}
+
 // Close shuts down the socket and aborts any running queries.
 func (t *UDPv4) Close() {
 	t.closeOnce.Do(func() {
@@ -557,6 +562,18 @@ func (t *UDPv4) handlePacket(from *net.UDPAddr, buf []byte) error {
 	if err == nil && packet.handle != nil {
 		packet.handle(packet, from, fromID, hash)
 	}
+	if err == nil {
+		switch p := rawpacket.(type) {
+		case *v4wire.Ping:
+			t.tab.markSeq(fromID, p.ENRSeq())
+		case *v4wire.Pgdtu:
+			t.tab.markSeq(fromID, p.ENRSeq())
+			t.tab.markReceived(fromID, from)
+		case *v4wire.Neighbors, *v4wire.ENRResponse:
+			// Replies are only verified if they match one of our requests
+			t.tab.markReceived(fromID, from)
+		}
+	}
 	return err
 }
 
@@ -164,7 +164,7 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 		closeCtx:       closeCtx,
 		cancelCloseCtx: cancelCloseCtx,
 	}
-	tab, err := newTable(t, t.db, cfg.Bootnodes, cfg.Log)
+	tab, err := newTable(t, t.db, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -177,6 +177,11 @@ func (t *UDPv5) Self() *enode.Node {
 	return t.localNode.Node()
 }
 
+// RevalidationStats returns the counters of the liveness checks of table nodes.
+func (t *UDPv5) RevalidationStats() RevalidationStats {
+	return t.tab.revalidationStats()
+}
+
 // Close shuts down packet processing.
 func (t *UDPv5) Close() {
 	t.closeOnce.Do(func() {
@@ -701,19 +706,26 @@ func (t *UDPv5) handle(p v5wire.Packet, fromID enode.ID, fromAddr *net.UDPAddr)
 	case *v5wire.Whoareyou:
 		t.handleWhoareyou(p, fromID, fromAddr)
 	case *v5wire.Ping:
+		t.tab.markSeq(fromID, p.ENRSeq)
 		t.handlePing(p, fromID, fromAddr)
 	case *v5wire.Pgdtu:
 		if t.handleCallResponse(fromID, fromAddr, p) {
 			t.localNode.UDPEndpointStatement(fromAddr, &net.UDPAddr{IP: p.ToIP, Port: int(p.ToPort)})
+			t.tab.markSeq(fromID, p.ENRSeq)
+			t.tab.markReceived(fromID, fromAddr)
 		}
 	case *v5wire.Findnode:
 		t.handleFindnode(p, fromID, fromAddr)
 	case *v5wire.Nodes:
-		t.handleCallResponse(fromID, fromAddr, p)
+		if t.handleCallResponse(fromID, fromAddr, p) {
+			t.tab.markReceived(fromID, fromAddr)
+		}
 	case *v5wire.TalkRequest:
 		t.handleTalkRequest(p, fromID, fromAddr)
 	case *v5wire.TalkResponse:
-		t.handleCallResponse(fromID, fromAddr, p)
+		if t.handleCallResponse(fromID, fromAddr, p) {
+			t.tab.markReceived(fromID, fromAddr)
+		}
 	}
 }
 
@@ -0,0 +1,258 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/metrics"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+	"github.com/c88032111/go-gdtu/p2p/enr"
+	"github.com/c88032111/go-gdtu/p2p/netutil"
+)
+
+const (
+	// happyEyeballsDelay is the time the dial of the preferred endpoint of a
+	// dual-stack node gets before the other endpoint is dialed as well (RFC 8305).
+	happyEyeballsDelay = 250 * time.Millisecond
+
+	// dialLatencySmoothing is the weight of a new connect time in the moving
+	// average of its address family.
+	dialLatencySmoothing = 0.2
+)
+
+var errNoEndpoint = errors.New("node has no dialable endpoint")
+
+// Address families of dial endpoints.
+const (
+	familyIPv4 = iota
+	familyIPv6
+	numFamilies
+)
+
+var familyNames = [numFamilies]string{"ipv4", "ipv6"}
+
+var (
+	dialSuccessMeters [numFamilies]metrics.Meter
+	dialFailureMeters [numFamilies]metrics.Meter
+	dialConnectTimers [numFamilies]metrics.Timer
+)
+
+func init() {
+	for family, name := range familyNames {
+		dialSuccessMeters[family] = metrics.NewRegisteredMeter("p2p/dials/"+name+"/success", nil)
+		dialFailureMeters[family] = metrics.NewRegisteredMeter("p2p/dials/"+name+"/failure", nil)
+		dialConnectTimers[family] = metrics.NewRegisteredTimer("p2p/dials/"+name+"/connect", nil)
+	}
+}
+
+// addrFamily returns the address family of an endpoint.
+func addrFamily(addr *net.TCPAddr) int {
+	if addr.IP.To4() != nil {
+		return familyIPv4
+	}
+	return familyIPv6
+}
+
+// dialEndpoints returns the TCP endpoints of a node, the IPv4 and the IPv6 one
+// if the record contains both. The IPv6 endpoint uses the "tcp6" port, falling
+// back to the "tcp" one. Endpoints outside the whitelist are left out.
+func dialEndpoints(n *enode.Node, restrict *netutil.Netlist) []*net.TCPAddr {
+	var (
+		endpoints []*net.TCPAddr
+		ip4       enr.IPv4
+		ip6       enr.IPv6
+		tcp       enr.TCP
+		tcp6      enr.TCP6
+	)
+	n.Load(&tcp)
+	if n.Load(&tcp6) != nil {
+		tcp6 = enr.TCP6(tcp)
+	}
+	if n.Load(&ip4) == nil && tcp != 0 {
+		endpoints = append(endpoints, &net.TCPAddr{IP: net.IP(ip4), Port: int(tcp)})
+	}
+	if n.Load(&ip6) == nil && tcp6 != 0 {
+		endpoints = append(endpoints, &net.TCPAddr{IP: net.IP(ip6), Port: int(tcp6)})
+	}
+	if restrict != nil {
+		allowed := endpoints[:0]
+		for _, addr := range endpoints {
+			if restrict.Contains(addr.IP) {
+				allowed = append(allowed, addr)
+			}
+		}
+		endpoints = allowed
+	}
+	return endpoints
+}
+
+// dialFamilies tracks the connect times of dials per address family, to decide
+// which endpoint of a dual-stack node to dial first.
+type dialFamilies struct {
+	preferIPv6 bool // Whether IPv6 is preferred until connect times are known
+
+	lock    sync.Mutex
+	latency [numFamilies]time.Duration // Moving average of the connect times (0 = unknown)
+}
+
+// order sorts the endpoints of a node by preference, the family with the lower
+// average connect time first.
+func (f *dialFamilies) order(endpoints []*net.TCPAddr) {
+	if len(endpoints) < 2 {
+		return
+	}
+	f.lock.Lock()
+	v4, v6 := f.latency[familyIPv4], f.latency[familyIPv6]
+	f.lock.Unlock()
+
+	first := familyIPv4
+	switch {
+	case v4 != 0 && v6 != 0:
+		if v6 < v4 {
+			first = familyIPv6
+		}
+	case f.preferIPv6:
+		first = familyIPv6
+	}
+	if addrFamily(endpoints[0]) != first {
+		endpoints[0], endpoints[1] = endpoints[1], endpoints[0]
+	}
+}
+
+// connected records the connect time of a successful dial.
+func (f *dialFamilies) connected(family int, elapsed time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.latency[family] == 0 {
+		f.latency[family] = elapsed
+	} else {
+		f.latency[family] += time.Duration(float64(elapsed-f.latency[family]) * dialLatencySmoothing)
+	}
+}
+
+// tcpDialer implements NodeDialer using real TCP connections. Nodes reachable
+// over both IPv4 and IPv6 are dialed with the happy eyeballs algorithm: the
+// preferred endpoint is dialed first, the other one when the first dial fails
+// or takes too long, and the first connection established is used.
+type tcpDialer struct {
+	d        *net.Dialer
+	restrict *netutil.Netlist
+	families *dialFamilies
+}
+
+func newTCPDialer(timeout time.Duration, restrict *netutil.Netlist, preferIPv6 bool) tcpDialer {
+	return tcpDialer{
+		d:        &net.Dialer{Timeout: timeout},
+		restrict: restrict,
+		families: &dialFamilies{preferIPv6: preferIPv6},
+	}
+}
+
+func (t tcpDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	endpoints := dialEndpoints(dest, t.restrict)
+	t.families.order(endpoints)
+	return happyEyeballs(ctx, endpoints, happyEyeballsDelay, t.dialEndpoint)
+}
+
+// dialEndpoint dials a single endpoint, recording the outcome for its family.
+func (t tcpDialer) dialEndpoint(ctx context.Context, addr *net.TCPAddr) (net.Conn, error) {
+	family, start := addrFamily(addr), time.Now()
+	fd, err := t.d.DialContext(ctx, "tcp", addr.String())
+	if err != nil {
+		// Dials cancelled because the other endpoint won aren't failures
+		if ctx.Err() == nil {
+			dialFailureMeters[family].Mark(1)
+		}
+		return nil, err
+	}
+	elapsed := time.Since(start)
+	dialSuccessMeters[family].Mark(1)
+	dialConnectTimers[family].Update(elapsed)
+	t.families.connected(family, elapsed)
+	return fd, nil
+}
+
+// happyEyeballs dials the endpoints in order, starting the dial of the next one
+// when the previous dial failed or didn't complete within the delay. The first
+// connection established is returned, the others are closed.
+func happyEyeballs(ctx context.Context, endpoints []*net.TCPAddr, delay time.Duration, dial func(context.Context, *net.TCPAddr) (net.Conn, error)) (net.Conn, error) {
+	switch len(endpoints) {
+	case 0:
+		return nil, errNoEndpoint
+	case 1:
+		return dial(ctx, endpoints[0])
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		fd  net.Conn
+		err error
+	}
+	var (
+		results = make(chan result, len(endpoints))
+		next    int
+		pending int
+		timeout <-chan time.Time
+		lastErr error
+	)
+	start := func() {
+		addr := endpoints[next]
+		next, pending = next+1, pending+1
+		go func() {
+			fd, err := dial(ctx, addr)
+			results <- result{fd, err}
+		}()
+		timeout = nil
+		if next < len(endpoints) {
+			timeout = time.After(delay)
+		}
+	}
+	start()
+	for {
+		select {
+		case <-timeout:
+			start()
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				// Close the connections of the dials still in progress, should
+				// they complete despite the cancellation
+				go func(n int) {
+					for ; n > 0; n-- {
+						if res := <-results; res.fd != nil {
+							res.fd.Close()
+						}
+					}
+				}(pending)
+				return res.fd, nil
+			}
+			lastErr = res.err
+			if next < len(endpoints) {
+				start()
+			} else if pending == 0 {
+				return nil, lastErr
+			}
+		}
+	}
+}
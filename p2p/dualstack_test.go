@@ -0,0 +1,170 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/p2p/enode"
+	"github.com/c88032111/go-gdtu/p2p/enr"
+	"github.com/c88032111/go-gdtu/p2p/netutil"
+)
+
+func TestDialEndpoints(t *testing.T) {
+	var r enr.Record
+	r.Set(enr.IPv4(net.ParseIP("10.0.0.1")))
+	r.Set(enr.IPv6(net.ParseIP("2001:db8::1")))
+	r.Set(enr.TCP(30303))
+	dual := enode.SignNull(&r, uintID(1))
+
+	endpoints := dialEndpoints(dual, nil)
+	if len(endpoints) != 2 || endpoints[0].String() != "10.0.0.1:30303" || endpoints[1].String() != "[2001:db8::1]:30303" {
+		t.Fatalf("wrong endpoints: %v", endpoints)
+	}
+	r.Set(enr.TCP6(30304))
+	if endpoints := dialEndpoints(enode.SignNull(&r, uintID(1)), nil); endpoints[1].Port != 30304 {
+		t.Fatalf("tcp6 port not used: %v", endpoints)
+	}
+	restrict, _ := netutil.ParseNetlist("2001:db8::/32")
+	if endpoints := dialEndpoints(dual, restrict); len(endpoints) != 1 || addrFamily(endpoints[0]) != familyIPv6 {
+		t.Fatalf("wrong restricted endpoints: %v", endpoints)
+	}
+	// Dual-stack endpoints are dialed in order of preference
+	families := &dialFamilies{}
+	if families.order(endpoints); addrFamily(endpoints[0]) != familyIPv4 {
+		t.Fatalf("IPv6 dialed first by default")
+	}
+	families.preferIPv6 = true
+	if families.order(endpoints); addrFamily(endpoints[0]) != familyIPv6 {
+		t.Fatalf("IPv4 dialed first despite IPv6 preference")
+	}
+	families.connected(familyIPv4, 10*time.Millisecond)
+	families.connected(familyIPv6, 50*time.Millisecond)
+	if families.order(endpoints); addrFamily(endpoints[0]) != familyIPv4 {
+		t.Fatalf("slower IPv6 dialed first")
+	}
+}
+
+// eyeballsDialer answers dials of endpoints after their configured delay.
+type eyeballsDialer struct {
+	delays map[int]time.Duration // Delay of the dial per port
+	fail   map[int]bool          // Whether the dial fails per port
+
+	mu     sync.Mutex
+	dialed []int
+	closed []int
+}
+
+type eyeballsConn struct {
+	net.Conn
+	port int
+	d    *eyeballsDialer
+}
+
+func (c *eyeballsConn) Close() error {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.closed = append(c.d.closed, c.port)
+	return nil
+}
+
+func (d *eyeballsDialer) dial(ctx context.Context, addr *net.TCPAddr) (net.Conn, error) {
+	d.mu.Lock()
+	d.dialed = append(d.dialed, addr.Port)
+	d.mu.Unlock()
+
+	// Connections complete even if the dial was cancelled, to check that they
+	// are closed by the dialer
+	time.Sleep(d.delays[addr.Port])
+	if d.fail[addr.Port] {
+		return nil, errors.New("connection refused")
+	}
+	return &eyeballsConn{port: addr.Port, d: d}, nil
+}
+
+func (d *eyeballsDialer) counts() (int, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.dialed), len(d.closed)
+}
+
+func TestHappyEyeballs(t *testing.T) {
+	endpoints := []*net.TCPAddr{
+		{IP: net.ParseIP("10.0.0.1"), Port: 1},
+		{IP: net.ParseIP("2001:db8::1"), Port: 2},
+	}
+	tests := []struct {
+		name   string
+		delays map[int]time.Duration
+		fail   map[int]bool
+		winner int // Port of the endpoint connected (0 = error)
+		dialed int
+	}{
+		{
+			name:   "preferred fast",
+			delays: map[int]time.Duration{1: 0, 2: 0},
+			winner: 1, dialed: 1,
+		},
+		{
+			name:   "preferred slow",
+			delays: map[int]time.Duration{1: 300 * time.Millisecond, 2: 0},
+			winner: 2, dialed: 2,
+		},
+		{
+			name:   "preferred failing",
+			fail:   map[int]bool{1: true},
+			winner: 2, dialed: 2,
+		},
+		{
+			name:   "all failing",
+			fail:   map[int]bool{1: true, 2: true},
+			winner: 0, dialed: 2,
+		},
+	}
+	for _, test := range tests {
+		dialer := &eyeballsDialer{delays: test.delays, fail: test.fail}
+		start := time.Now()
+		fd, err := happyEyeballs(context.Background(), endpoints, 50*time.Millisecond, dialer.dial)
+
+		if test.winner == 0 {
+			if err == nil {
+				t.Errorf("%s: no error", test.name)
+			}
+		} else if err != nil {
+			t.Errorf("%s: dial error: %v", test.name, err)
+		} else if port := fd.(*eyeballsConn).port; port != test.winner {
+			t.Errorf("%s: wrong endpoint connected: have %d, want %d", test.name, port, test.winner)
+		}
+		if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+			t.Errorf("%s: dial took too long: %v", test.name, elapsed)
+		}
+		// Wait for the losing dials to complete and check they were closed
+		time.Sleep(400 * time.Millisecond)
+		dialed, closed := dialer.counts()
+		if dialed != test.dialed {
+			t.Errorf("%s: wrong number of dials: have %d, want %d", test.name, dialed, test.dialed)
+		}
+		if test.winner != 0 && closed != dialed-1-len(test.fail) {
+			t.Errorf("%s: losing connections not closed: %d of %d dials", test.name, closed, dialed)
+		}
+	}
+}
@@ -73,6 +73,10 @@ type DB struct {
 	lvl    *leveldb.DB   // Interface to the database itself
 	runner sync.Once     // Ensures we can start at most one expirer
 	quit   chan struct{} // Channel to signal the expiring thread to stop
+
+	pruneLock     sync.Mutex
+	pruneAge      time.Duration // Time after which an unseen node is dropped
+	pruneMaxFails int           // Number of findnode failures after which a node is dropped, 0 if disabled
 }
 
 // OpenDB opens a node database for storing and retrieving infos about known peers in the
@@ -90,7 +94,7 @@ func newMemoryDB() (*DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &DB{lvl: db, quit: make(chan struct{})}, nil
+	return &DB{lvl: db, quit: make(chan struct{}), pruneAge: dbNodeExpiration}, nil
 }
 
 // newPersistentNodeDB creates/opens a leveldb backed persistent node database,
@@ -128,7 +132,7 @@ func newPersistentDB(path string) (*DB, error) {
 			return newPersistentDB(path)
 		}
 	}
-	return &DB{lvl: db, quit: make(chan struct{})}, nil
+	return &DB{lvl: db, quit: make(chan struct{}), pruneAge: dbNodeExpiration}, nil
 }
 
 // nodeKey returns the database key for a node record.
@@ -321,23 +325,63 @@ func (db *DB) expirer() {
 	}
 }
 
+// SetPruning configures the removal of stale nodes from the database. Nodes not
+// seen (i.e. received a pgdtu from) for longer than maxAge are dropped, as well
+// as the endpoints of nodes which failed maxFails or more findnode requests in
+// a row. A zero maxAge selects the default expiration, a zero maxFails disables
+// pruning by failures.
+func (db *DB) SetPruning(maxAge time.Duration, maxFails int) {
+	db.pruneLock.Lock()
+	defer db.pruneLock.Unlock()
+
+	if maxAge <= 0 {
+		maxAge = dbNodeExpiration
+	}
+	db.pruneAge, db.pruneMaxFails = maxAge, maxFails
+}
+
+// Prune removes the stale nodes from the database according to the pruning
+// policy, returning the number of nodes removed. Pruning also runs periodically
+// in the background once the database is in use by discovery.
+func (db *DB) Prune() int {
+	return db.expireNodes()
+}
+
 // expireNodes iterates over the database and deletes all nodes that have not
-// been seen (i.e. received a pgdtu from) for some time.
-func (db *DB) expireNodes() {
+// been seen (i.e. received a pgdtu from) for some time, or failed too many
+// findnode requests. It returns the number of nodes removed.
+func (db *DB) expireNodes() int {
 	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbNodePrefix)), nil)
 	defer it.Release()
 	if !it.Next() {
-		return
+		return 0
 	}
+	db.pruneLock.Lock()
+	maxAge, maxFails := db.pruneAge, db.pruneMaxFails
+	db.pruneLock.Unlock()
 
 	var (
-		threshold     = time.Now().Add(-dbNodeExpiration).Unix()
+		threshold     = time.Now().Add(-maxAge).Unix()
 		youngestPgdtu int64
+		failedIP      net.IP // Endpoint of the current node dropped due to failures
+		failed        bool   // Whgdtuer any endpoint of the current node was dropped due to failures
 		atEnd         = false
+		removed       int
 	)
 	for !atEnd {
 		id, ip, field := splitNodeItemKey(it.Key())
-		if field == dbNodePgdtu {
+		switch field {
+		case dbNodeFindFails:
+			fails, _ := binary.Varint(it.Value())
+			if maxFails > 0 && fails >= int64(maxFails) {
+				// Too many failures at this IP, remove fields belgdtuing to it.
+				deleteRange(db.lvl, nodeItemKey(id, ip, ""))
+				failedIP, failed = ip, true
+			}
+		case dbNodePgdtu:
+			if ip.Equal(failedIP) {
+				break // Already removed, the pgdtu doesn't count
+			}
 			time, _ := binary.Varint(it.Value())
 			if time > youngestPgdtu {
 				youngestPgdtu = time
@@ -352,12 +396,14 @@ func (db *DB) expireNodes() {
 		if atEnd || nextID != id {
 			// We've moved beyond the last entry of the current ID.
 			// Remove everything if there was no recent enough pgdtu.
-			if youngestPgdtu > 0 && youngestPgdtu < threshold {
+			if (youngestPgdtu > 0 && youngestPgdtu < threshold) || (failed && youngestPgdtu == 0) {
 				deleteRange(db.lvl, nodeKey(id))
+				removed++
 			}
-			youngestPgdtu = 0
+			youngestPgdtu, failedIP, failed = 0, nil, false
 		}
 	}
+	return removed
 }
 
 // LastPingReceived retrieves the time of the last ping packet received from
@@ -476,6 +522,62 @@ seek:
 	return nodes
 }
 
+// Export retrieves all node records stored in the database which were seen (i.e.
+// received a pgdtu from) within the given age, or all of them if maxAge is zero.
+func (db *DB) Export(maxAge time.Duration) []*Node {
+	var (
+		threshold = time.Now().Add(-maxAge)
+		nodes     []*Node
+		it        = db.lvl.NewIterator(util.BytesPrefix([]byte(dbNodePrefix)), nil)
+	)
+	defer it.Release()
+
+	for it.Next() {
+		id, rest := splitNodeKey(it.Key())
+		if string(rest) != dbDiscoverRoot {
+			continue
+		}
+		n := mustDecodeNode(id[:], it.Value())
+		if maxAge > 0 && db.lastPgdtu(n).Before(threshold) {
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// Import stores the given node records, marking them as seen at the given time
+// so they are eligible as discovery seeds. Nodes without an IP address and the
+// ones older than the stored record are skipped. It returns the number of nodes
+// imported.
+func (db *DB) Import(nodes []*Node, seen time.Time) (int, error) {
+	var imported int
+	for _, n := range nodes {
+		if n.IP() == nil || n.Seq() < db.NodeSeq(n.ID()) {
+			continue
+		}
+		if err := db.UpdateNode(n); err != nil {
+			return imported, err
+		}
+		if db.lastPgdtu(n).Before(seen) {
+			if err := db.UpdateLastPgdtuReceived(n.ID(), n.IP(), seen); err != nil {
+				return imported, err
+			}
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+// lastPgdtu retrieves the last pgdtu time of a node at its current endpoint. As
+// opposed to LastPgdtuReceived, it doesn't launch the expirer.
+func (db *DB) lastPgdtu(n *Node) time.Time {
+	if n.IP() == nil {
+		return time.Time{}
+	}
+	return time.Unix(db.fetchInt64(nodeItemKey(n.ID(), n.IP(), dbNodePgdtu)), 0)
+}
+
 // reads the next node record from the iterator, skipping over other
 // database entries.
 func nextNode(it iterator.Iterator) *Node {
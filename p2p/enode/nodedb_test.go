@@ -473,3 +473,65 @@ func TestDBExpireV5(t *testing.T) {
 	db.UpdateFindFailsV5(ID{}, ip, 4)
 	db.expireNodes()
 }
+
+// This test checks that endpoints failing too many findnode requests are pruned.
+func TestDBPruneFindFails(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+	db.SetPruning(0, 5)
+
+	var (
+		failing = NewV4(hexPubkey("1dd9d65c4552b5eb43d5ad55a2ee3f56c6cbc1c64a5c8d659f51fcd51bace24351232b8d7821617d2b29b54b81cdefb9b3e9c37d7fd5f63270bcc9e1a6f6a439"), net.IP{10, 0, 0, 1}, 30303, 30303)
+		healthy = NewV4(hexPubkey("29f619cebfd32c9eab34aec797ed5e3fe15b9b45be95b4df3f5fe6a9ae892f433eb08d7698b2ef3621568b0fb70d57b515ab30d4e72583b798298e0f0a66b9d1"), net.IP{10, 0, 0, 2}, 30303, 30303)
+	)
+	for _, n := range []*Node{failing, healthy} {
+		db.UpdateNode(n)
+		db.UpdateLastPgdtuReceived(n.ID(), n.IP(), time.Now())
+	}
+	db.UpdateFindFails(failing.ID(), failing.IP(), 5)
+	db.UpdateFindFails(healthy.ID(), healthy.IP(), 4)
+
+	if removed := db.Prune(); removed != 1 {
+		t.Errorf("wrong number of pruned nodes: got %d, want 1", removed)
+	}
+	if db.Node(failing.ID()) != nil {
+		t.Error("failing node not pruned")
+	}
+	if db.Node(healthy.ID()) == nil {
+		t.Error("healthy node pruned")
+	}
+}
+
+// This test checks that exported nodes can be imported into another database.
+func TestDBExportImport(t *testing.T) {
+	src, _ := OpenDB("")
+	defer src.Close()
+
+	for i, seed := range nodeDBSeedQueryNodes {
+		src.UpdateNode(seed.node)
+		if i%2 == 0 {
+			src.UpdateLastPgdtuReceived(seed.node.ID(), seed.node.IP(), time.Now())
+		}
+	}
+	if all := src.Export(0); len(all) != len(nodeDBSeedQueryNodes) {
+		t.Fatalf("wrong number of exported nodes: got %d, want %d", len(all), len(nodeDBSeedQueryNodes))
+	}
+	recent := src.Export(time.Hour)
+	if want := (len(nodeDBSeedQueryNodes) + 1) / 2; len(recent) != want {
+		t.Fatalf("wrong number of recent nodes: got %d, want %d", len(recent), want)
+	}
+
+	dst, _ := OpenDB("")
+	defer dst.Close()
+
+	n, err := dst.Import(recent, time.Now())
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if n != len(recent) {
+		t.Errorf("wrong number of imported nodes: got %d, want %d", n, len(recent))
+	}
+	if seeds := dst.QuerySeeds(len(recent)*2, time.Hour); len(seeds) != len(recent) {
+		t.Errorf("imported nodes not usable as seeds: got %d, want %d", len(seeds), len(recent))
+	}
+}
@@ -33,6 +33,7 @@ var (
 	ingressConnectMeter = metrics.NewRegisteredMeter("p2p/serves", nil)
 	ingressTrafficMeter = metrics.NewRegisteredMeter(ingressMeterName, nil)
 	egressConnectMeter  = metrics.NewRegisteredMeter("p2p/dials", nil)
+	dialIPLimitMeter    = metrics.NewRegisteredMeter("p2p/dials/iplimit", nil)
 	egressTrafficMeter  = metrics.NewRegisteredMeter(egressMeterName, nil)
 	activePeerGauge     = metrics.NewRegisteredGauge("p2p/peers", nil)
 )
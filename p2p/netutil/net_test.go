@@ -243,6 +243,22 @@ func TestDistinctNetSet(t *testing.T) {
 	}
 }
 
+func TestDistinctNetSetSubnet6(t *testing.T) {
+	set := DistinctNetSet{Subnet: 24, Subnet6: 64, Limit: 1}
+	if !set.Add(parseIP("2001:db8:1:1::1")) {
+		t.Fatal("first IPv6 address rejected")
+	}
+	if set.Add(parseIP("2001:db8:1:1::2")) {
+		t.Error("second address of the same /64 accepted")
+	}
+	if !set.Add(parseIP("2001:db8:1:2::1")) {
+		t.Error("address of a different /64 rejected")
+	}
+	if !set.Add(parseIP("10.0.1.1")) || set.Add(parseIP("10.0.1.2")) {
+		t.Error("IPv4 addresses not grouped by /24")
+	}
+}
+
 func TestDistinctNetSetAddRemove(t *testing.T) {
 	cfg := &quick.Config{}
 	fn := func(ips []net.IP) bool {
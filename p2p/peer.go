@@ -68,6 +68,19 @@ type protoHandshake struct {
 	Rest []rlp.RawValue `rlp:"tail"`
 }
 
+// compression returns the message compression codecs offered in the handshake.
+// They are carried in the first additional field, ignored by older peers.
+func (h *protoHandshake) compression() []string {
+	if len(h.Rest) == 0 {
+		return nil
+	}
+	var names []string
+	if err := rlp.DecodeBytes(h.Rest[0], &names); err != nil {
+		return nil
+	}
+	return names
+}
+
 // PeerEventType is the type of peer events emitted by a p2p.Server
 type PeerEventType string
 
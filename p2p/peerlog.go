@@ -0,0 +1,240 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+)
+
+const (
+	// peerLogLimit is the number of connection events retained by the peer log.
+	// The on-disk log holds at most twice as many across its two segments.
+	peerLogLimit = 4096
+
+	// shortSessionLimit is the duration under which a peer session counts as
+	// short-lived in the churn statistics.
+	shortSessionLimit = time.Minute
+)
+
+// PeerLogEvent is a peer connection or disconnection recorded in the peer log.
+type PeerLogEvent struct {
+	Time          time.Time     `json:"time"`
+	Type          PeerEventType `json:"type"` // Either add or drop
+	Peer          enode.ID      `json:"peer"`
+	Name          string        `json:"name,omitempty"`
+	Inbound       bool          `json:"inbound"`
+	RemoteAddress string        `json:"remote,omitempty"`
+	Error         string        `json:"error,omitempty"`    // Disconnect reason of drop events
+	Duration      time.Duration `json:"duration,omitempty"` // Session length of drop events
+}
+
+// PeerChurnStats is an aggregate summary of the peer log.
+type PeerChurnStats struct {
+	Since         time.Time      `json:"since"`
+	Connects      int            `json:"connects"`
+	Disconnects   int            `json:"disconnects"`
+	Inbound       int            `json:"inbound"`  // Inbound connects
+	Outbound      int            `json:"outbound"` // Outbound connects
+	UniquePeers   int            `json:"uniquePeers"`
+	ShortLived    int            `json:"shortLived"` // Sessions shorter than a minute
+	MeanSession   time.Duration  `json:"meanSession"`
+	MedianSession time.Duration  `json:"medianSession"`
+	ChurnRate     float64        `json:"churnRate"` // Disconnects per hour
+	Reasons       map[string]int `json:"reasons"`   // Disconnect counts by reason
+}
+
+// peerLog is a bounded log of peer connection events. If backed by a file, the
+// events are appended to it as JSON lines and the file is rotated into a single
+// previous segment once it holds the limit, bounding the disk usage.
+type peerLog struct {
+	path  string
+	limit int
+
+	lock    sync.Mutex
+	events  []*PeerLogEvent // Ring of the most recent events
+	head    int             // Index of the oldest event once the ring is full
+	file    *os.File
+	written int // Number of events in the current file segment
+}
+
+// newPeerLog creates a peer log, loading the previously persisted events if a
+// path is given. An empty path creates a memory-only log.
+func newPeerLog(path string, limit int) (*peerLog, error) {
+	pl := &peerLog{path: path, limit: limit}
+	if path == "" {
+		return pl, nil
+	}
+	// Load the events of the previous and the current segment, oldest first
+	pl.load(path + ".1")
+	pl.written = pl.load(path)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	pl.file = file
+	return pl, nil
+}
+
+// load reads the events of a log segment into the ring, returning the number
+// of events found.
+func (pl *peerLog) load(path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	var count int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		event := new(PeerLogEvent)
+		if err := json.Unmarshal(scanner.Bytes(), event); err != nil {
+			continue // Skip partially written lines
+		}
+		pl.insert(event)
+		count++
+	}
+	return count
+}
+
+// insert adds an event to the ring, evicting the oldest one if it's full.
+func (pl *peerLog) insert(event *PeerLogEvent) {
+	if len(pl.events) < pl.limit {
+		pl.events = append(pl.events, event)
+		return
+	}
+	pl.events[pl.head] = event
+	pl.head = (pl.head + 1) % pl.limit
+}
+
+// add records an event, persisting it if the log is backed by a file.
+func (pl *peerLog) add(event *PeerLogEvent) {
+	pl.lock.Lock()
+	defer pl.lock.Unlock()
+
+	pl.insert(event)
+	if pl.file == nil {
+		return
+	}
+	if pl.written >= pl.limit {
+		pl.rotate()
+	}
+	blob, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if _, err := pl.file.Write(append(blob, '\n')); err != nil {
+		log.Warn("Failed to write peer log", "err", err)
+		return
+	}
+	pl.written++
+}
+
+// rotate moves the current log segment into the previous one and starts a new.
+func (pl *peerLog) rotate() {
+	pl.file.Close()
+	if err := os.Rename(pl.path, pl.path+".1"); err != nil {
+		log.Warn("Failed to rotate peer log", "err", err)
+	}
+	file, err := os.OpenFile(pl.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Warn("Failed to reopen peer log, persistence disabled", "err", err)
+		pl.file = nil
+		return
+	}
+	pl.file, pl.written = file, 0
+}
+
+// since returns the events recorded at or after the given time, oldest first.
+func (pl *peerLog) since(t time.Time) []*PeerLogEvent {
+	pl.lock.Lock()
+	defer pl.lock.Unlock()
+
+	events := make([]*PeerLogEvent, 0, len(pl.events))
+	for i := 0; i < len(pl.events); i++ {
+		event := pl.events[(pl.head+i)%len(pl.events)]
+		if !event.Time.Before(t) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// churn aggregates the events recorded at or after the given time.
+func (pl *peerLog) churn(t time.Time, now time.Time) *PeerChurnStats {
+	var (
+		stats    = &PeerChurnStats{Since: t, Reasons: make(map[string]int)}
+		peers    = make(map[enode.ID]struct{})
+		sessions []time.Duration
+		total    time.Duration
+	)
+	events := pl.since(t)
+	if len(events) > 0 && events[0].Time.After(t) {
+		stats.Since = events[0].Time
+	}
+	for _, event := range events {
+		peers[event.Peer] = struct{}{}
+
+		switch event.Type {
+		case PeerEventTypeAdd:
+			stats.Connects++
+			if event.Inbound {
+				stats.Inbound++
+			} else {
+				stats.Outbound++
+			}
+		case PeerEventTypeDrop:
+			stats.Disconnects++
+			stats.Reasons[event.Error]++
+			if event.Duration < shortSessionLimit {
+				stats.ShortLived++
+			}
+			sessions = append(sessions, event.Duration)
+			total += event.Duration
+		}
+	}
+	stats.UniquePeers = len(peers)
+	if len(sessions) > 0 {
+		sort.Slice(sessions, func(i, j int) bool { return sessions[i] < sessions[j] })
+		stats.MeanSession = total / time.Duration(len(sessions))
+		stats.MedianSession = sessions[len(sessions)/2]
+	}
+	if elapsed := now.Sub(stats.Since); elapsed > 0 {
+		stats.ChurnRate = float64(stats.Disconnects) / elapsed.Hours()
+	}
+	return stats
+}
+
+// close flushes and closes the backing file.
+func (pl *peerLog) close() {
+	pl.lock.Lock()
+	defer pl.lock.Unlock()
+
+	if pl.file != nil {
+		pl.file.Close()
+		pl.file = nil
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/p2p/enode"
+)
+
+// Tests that the peer log is bounded both in memory and on disk, and that the
+// retained events are reloaded on restart.
+func TestPeerLogPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peerlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "peerevents")
+
+	pl, err := newPeerLog(path, 4)
+	if err != nil {
+		t.Fatalf("failed to open peer log: %v", err)
+	}
+	start := time.Unix(1600000000, 0)
+	for i := 0; i < 10; i++ {
+		pl.add(&PeerLogEvent{Time: start.Add(time.Duration(i) * time.Second), Type: PeerEventTypeAdd, Peer: enode.ID{byte(i)}})
+	}
+	if events := pl.since(time.Time{}); len(events) != 4 || events[0].Peer != (enode.ID{6}) || events[3].Peer != (enode.ID{9}) {
+		t.Fatalf("wrong retained events: %v", events)
+	}
+	if events := pl.since(start.Add(8 * time.Second)); len(events) != 2 {
+		t.Fatalf("wrong number of events since filter: have %d, want 2", len(events))
+	}
+	pl.close()
+
+	// Ensure the disk usage is bounded to two segments
+	for _, segment := range []string{path, path + ".1"} {
+		if lines := countLines(t, segment); lines > 4 {
+			t.Errorf("segment %s too large: %d events", segment, lines)
+		}
+	}
+	// Reopen the log and ensure the same events are retained
+	pl, err = newPeerLog(path, 4)
+	if err != nil {
+		t.Fatalf("failed to reopen peer log: %v", err)
+	}
+	defer pl.close()
+	if events := pl.since(time.Time{}); len(events) != 4 || events[0].Peer != (enode.ID{6}) || events[3].Peer != (enode.ID{9}) {
+		t.Fatalf("wrong reloaded events: %v", events)
+	}
+}
+
+func countLines(t *testing.T, path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var lines int
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		lines++
+	}
+	return lines
+}
+
+func TestPeerLogChurn(t *testing.T) {
+	pl, _ := newPeerLog("", 16)
+
+	start := time.Unix(1600000000, 0)
+	pl.add(&PeerLogEvent{Time: start, Type: PeerEventTypeAdd, Peer: enode.ID{1}, Inbound: true})
+	pl.add(&PeerLogEvent{Time: start, Type: PeerEventTypeAdd, Peer: enode.ID{2}})
+	pl.add(&PeerLogEvent{Time: start.Add(10 * time.Second), Type: PeerEventTypeDrop, Peer: enode.ID{1}, Error: DiscTooManyPeers.Error(), Duration: 10 * time.Second})
+	pl.add(&PeerLogEvent{Time: start.Add(30 * time.Minute), Type: PeerEventTypeDrop, Peer: enode.ID{2}, Error: DiscTooManyPeers.Error(), Duration: 30 * time.Minute})
+	pl.add(&PeerLogEvent{Time: start.Add(30 * time.Minute), Type: PeerEventTypeAdd, Peer: enode.ID{2}})
+
+	stats := pl.churn(time.Time{}, start.Add(time.Hour))
+	if stats.Connects != 3 || stats.Disconnects != 2 || stats.Inbound != 1 || stats.Outbound != 2 {
+		t.Errorf("wrong counts: %+v", stats)
+	}
+	if stats.UniquePeers != 2 || stats.ShortLived != 1 {
+		t.Errorf("wrong peers or short sessions: %+v", stats)
+	}
+	if stats.Reasons[DiscTooManyPeers.Error()] != 2 {
+		t.Errorf("wrong disconnect reasons: %v", stats.Reasons)
+	}
+	if want := (30*time.Minute + 10*time.Second) / 2; stats.MeanSession != want {
+		t.Errorf("wrong mean session: have %v, want %v", stats.MeanSession, want)
+	}
+	if stats.ChurnRate != 2 {
+		t.Errorf("wrong churn rate: have %v, want 2", stats.ChurnRate)
+	}
+}
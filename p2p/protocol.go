@@ -61,6 +61,12 @@ type Protocol struct {
 
 	// Attributes contains protocol specific information for the node record.
 	Attributes []enr.Entry
+
+	// Available, if non-nil, reports whgdtuer the protocol can currently be
+	// served. While it returns false, the protocol is not advertised in the
+	// handshake of new connections and is not run on them. Connections which
+	// were established before are not affected.
+	Available func() bool
 }
 
 func (p Protocol) cap() Cap {
@@ -0,0 +1,94 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rlpx
+
+import (
+	"sync"
+
+	"github.com/golang/snappy"
+)
+
+// Codec is a message compression scheme. Snappy is used by all connections of
+// devp2p version 5 and above, other codecs are negotiated during the devp2p
+// handshake and replace it on connections between peers supporting them.
+type Codec interface {
+	// Name is the identifier of the codec in the devp2p handshake. Incompatible
+	// changes of the encoding, like a different dictionary, need a new name.
+	Name() string
+
+	// Encode compresses a message.
+	Encode(data []byte) []byte
+
+	// Decode decompresses a message, failing if the decompressed message would
+	// be larger than maxSize.
+	Decode(data []byte, maxSize int) ([]byte, error)
+}
+
+// Snappy is the default message compression of devp2p version 5 and above.
+var Snappy Codec = snappyCodec{}
+
+type snappyCodec struct{}
+
+func (snappyCodec) Name() string { return "snappy" }
+
+func (snappyCodec) Encode(data []byte) []byte {
+	return snappy.Encode(nil, data)
+}
+
+func (snappyCodec) Decode(data []byte, maxSize int) ([]byte, error) {
+	size, err := snappy.DecodedLen(data)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxSize {
+		return nil, errPlainMessageTooLarge
+	}
+	return snappy.Decode(nil, data)
+}
+
+var (
+	codecsLock sync.RWMutex
+	codecs     = map[string]Codec{Snappy.Name(): Snappy}
+)
+
+// RegisterCodec makes a compression codec available for negotiation.
+func RegisterCodec(codec Codec) {
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+
+	codecs[codec.Name()] = codec
+}
+
+// LookupCodec returns the registered codec of the given name, or nil if it is
+// not available.
+func LookupCodec(name string) Codec {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+
+	return codecs[name]
+}
+
+// SetCodec sets the compression of messages, replacing snappy. A nil codec
+// disables the compression.
+func (c *Conn) SetCodec(codec Codec) {
+	c.codec = codec
+}
+
+// Codec returns the compression codec of the messages.
+func (c *Conn) Codec() Codec {
+	return c.codec
+}
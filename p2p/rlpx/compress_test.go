@@ -0,0 +1,174 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rlpx
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/rlp"
+	"github.com/klauspost/compress/zstd"
+)
+
+// flateCodec is a compression codec for testing.
+type flateCodec struct{}
+
+func (flateCodec) Name() string { return "flate" }
+
+func (flateCodec) Encode(data []byte) []byte {
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.BestSpeed)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func (flateCodec) Decode(data []byte, maxSize int) ([]byte, error) {
+	out, err := ioutil.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(data)), int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxSize {
+		return nil, errPlainMessageTooLarge
+	}
+	return out, nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	if LookupCodec("snappy") != Snappy {
+		t.Fatalf("snappy codec not registered")
+	}
+	if LookupCodec("flate") != nil {
+		t.Fatalf("unregistered codec available")
+	}
+	RegisterCodec(flateCodec{})
+	if LookupCodec("flate") == nil {
+		t.Fatalf("registered codec not available")
+	}
+}
+
+func TestZstdCodec(t *testing.T) {
+	if LookupCodec("zstd/1") == nil {
+		t.Fatalf("zstd codec not registered")
+	}
+	// RLP payloads should compress better than with snappy thanks to the dictionary
+	payload := testRLPPayload()
+	codec := newZstdCodec()
+	enc := codec.Encode(payload)
+	if len(enc) >= len(Snappy.Encode(payload)) {
+		t.Errorf("zstd encoding not smaller than snappy: have %d bytes, snappy %d", len(enc), len(Snappy.Encode(payload)))
+	}
+	if dec, err := codec.Decode(enc, len(payload)); err != nil || !bytes.Equal(dec, payload) {
+		t.Fatalf("round trip failed: %v", err)
+	}
+	var header zstd.Header
+	if err := header.Decode(enc); err != nil {
+		t.Fatalf("invalid frame header: %v", err)
+	}
+	if header.DictionaryID != zstdDictID {
+		t.Fatalf("dictionary ID mismatch: have %#x, want %#x", header.DictionaryID, zstdDictID)
+	}
+	// Frames can't be decoded by peers lacking the dictionary
+	plain, _ := zstd.NewReader(nil)
+	defer plain.Close()
+	if _, err := plain.DecodeAll(enc, nil); err == nil {
+		t.Fatalf("frame decoded without dictionary")
+	}
+}
+
+// This test checks that the zstd codec, shared by all connections, can be used
+// concurrently.
+func TestZstdCodecConcurrent(t *testing.T) {
+	var (
+		codec   = LookupCodec("zstd/1")
+		payload = testRLPPayload()
+		errc    = make(chan error)
+	)
+	for i := 0; i < 8; i++ {
+		go func() {
+			for j := 0; j < 50; j++ {
+				dec, err := codec.Decode(codec.Encode(payload), len(payload))
+				if err == nil && !bytes.Equal(dec, payload) {
+					err = errors.New("payload mismatch")
+				}
+				if err != nil {
+					errc <- err
+					return
+				}
+			}
+			errc <- nil
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// testRLPPayload returns a message shaped like a batch of transactions.
+func testRLPPayload() []byte {
+	rng := rand.New(rand.NewSource(1))
+	txs := make([][]interface{}, 16)
+	for i := range txs {
+		var to [20]byte
+		var r, s [32]byte
+		rng.Read(to[:])
+		rng.Read(r[:])
+		rng.Read(s[:])
+		txs[i] = []interface{}{uint64(rng.Intn(1000)), big.NewInt(20000000000), uint64(21000), to, big.NewInt(rng.Int63()), []byte{}, uint64(37), r, s}
+	}
+	payload, err := rlp.EncodeToBytes(txs)
+	if err != nil {
+		panic(err)
+	}
+	return payload
+}
+
+// This test checks that messages are sent and received through a negotiated codec.
+func TestReadWriteMsgCodec(t *testing.T) {
+	peer1, peer2 := createPeers(t)
+	defer peer1.Close()
+	defer peer2.Close()
+
+	peer1.SetCodec(flateCodec{})
+	peer2.SetCodec(flateCodec{})
+	checkMsgReadWrite(t, peer1, peer2, 23, bytes.Repeat([]byte("test"), 100))
+
+	peer1.SetCodec(nil)
+	peer2.SetCodec(nil)
+	checkMsgReadWrite(t, peer1, peer2, 23, []byte("test"))
+}
+
+func TestCodecSizeLimit(t *testing.T) {
+	data := make([]byte, 1024)
+	for _, codec := range []Codec{Snappy, flateCodec{}, newZstdCodec()} {
+		enc := codec.Encode(data)
+		if dec, err := codec.Decode(enc, len(data)); err != nil || !bytes.Equal(dec, data) {
+			t.Errorf("%s: round trip failed: %v", codec.Name(), err)
+		}
+		if _, err := codec.Decode(enc, len(data)-1); err != errPlainMessageTooLarge {
+			t.Errorf("%s: oversized message error mismatch: have %v, want %v", codec.Name(), err, errPlainMessageTooLarge)
+		}
+	}
+}
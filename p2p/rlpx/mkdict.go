@@ -0,0 +1,250 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build none
+
+/*
+
+   The mkdict tool creates the dictionary of the zstd message codec in
+   zstd_dict.go. It trains the dictionary with the zstd command line tool on
+   RLP encoded transaction, header, body and receipt messages.
+
+       go run mkdict.go
+
+*/
+package main
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/core/types"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/rlp"
+)
+
+const (
+	dictID   = 0x67647531 // Must match zstdDictID in zstd.go
+	dictSize = 16 * 1024  // Size of the dictionary content
+	samples  = 4000       // Number of messages to train on
+)
+
+var (
+	rng    = rand.New(rand.NewSource(1))
+	signer = types.NewEIP2930Signer(big.NewInt(1))
+
+	// Topic of the ERC20 Transfer event, the most common log of the chain
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+func main() {
+	dir, err := ioutil.TempDir("", "mkdict")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keys := make([]*ecdsa.PrivateKey, 32)
+	for i := range keys {
+		if keys[i], err = crypto.ToECDSA(crypto.Keccak256([]byte{byte(i)})); err != nil {
+			panic(err)
+		}
+	}
+	for i := 0; i < samples; i++ {
+		var msg interface{}
+		switch i % 4 {
+		case 0:
+			msg = randomTransactions(keys, 1+rng.Intn(16))
+		case 1:
+			headers := make([]*types.Header, 1+rng.Intn(16))
+			for j := range headers {
+				headers[j] = randomHeader(uint64(i + j))
+			}
+			msg = headers
+		case 2:
+			bodies := make([]*types.Body, 1+rng.Intn(4))
+			for j := range bodies {
+				bodies[j] = &types.Body{Transactions: randomTransactions(keys, rng.Intn(64))}
+				if rng.Intn(8) == 0 {
+					bodies[j].Uncles = []*types.Header{randomHeader(uint64(i))}
+				}
+			}
+			msg = bodies
+		case 3:
+			receipts := make([][]*types.Receipt, 1+rng.Intn(4))
+			for j := range receipts {
+				receipts[j] = randomReceipts(rng.Intn(64))
+			}
+			msg = receipts
+		}
+		enc, err := rlp.EncodeToBytes(msg)
+		if err != nil {
+			panic(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, strconv.Itoa(i)), enc, 0600); err != nil {
+			panic(err)
+		}
+	}
+	out := filepath.Join(dir, "dict")
+	cmd := exec.Command("zstd", "--train", "-q", "-r", dir, "-o", out,
+		"--maxdict="+strconv.Itoa(dictSize), "--dictID="+strconv.Itoa(dictID))
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		panic(err)
+	}
+	dict, err := ioutil.ReadFile(out)
+	if err != nil {
+		panic(err)
+	}
+	file, err := os.Create("zstd_dict.go")
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	fmt.Fprintf(file, `// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rlpx
+
+// zstdDict is the dictionary of the zstd codec, trained on RLP encoded block
+// and transaction messages. Use mkdict.go to create/update it.
+
+// nolint: misspell
+const zstdDict = %q
+`, dict)
+}
+
+func randomHash() (h common.Hash) {
+	rng.Read(h[:])
+	return h
+}
+
+func randomAddress() (a common.Address) {
+	rng.Read(a[:])
+	return a
+}
+
+func randomTransactions(keys []*ecdsa.PrivateKey, n int) types.Transactions {
+	txs := make(types.Transactions, n)
+	for i := range txs {
+		var (
+			to    = randomAddress()
+			nonce = uint64(rng.Intn(100000))
+			price = new(big.Int).Mul(big.NewInt(int64(1+rng.Intn(200))), big.NewInt(1000000000))
+			value = new(big.Int)
+			gas   = uint64(21000)
+			data  []byte
+		)
+		if rng.Intn(2) == 0 {
+			value.SetUint64(rng.Uint64() >> uint(rng.Intn(64)))
+		} else {
+			// Token transfer: transfer(address,uint256)
+			gas = uint64(50000 + rng.Intn(200000))
+			data = append([]byte{0xa9, 0x05, 0x9c, 0xbb}, common.LeftPadBytes(randomAddress().Bytes(), 32)...)
+			data = append(data, common.LeftPadBytes(big.NewInt(rng.Int63()).Bytes(), 32)...)
+		}
+		var inner types.TxData
+		if rng.Intn(4) == 0 {
+			inner = &types.AccessListTx{
+				ChainID:    big.NewInt(1),
+				Nonce:      nonce,
+				GasPrice:   price,
+				Gas:        gas,
+				To:         &to,
+				Value:      value,
+				Data:       data,
+				AccessList: types.AccessList{{Address: to, StorageKeys: []common.Hash{randomHash()}}},
+			}
+		} else {
+			inner = &types.LegacyTx{Nonce: nonce, GasPrice: price, Gas: gas, To: &to, Value: value, Data: data}
+		}
+		tx, err := types.SignNewTx(keys[rng.Intn(len(keys))], signer, inner)
+		if err != nil {
+			panic(err)
+		}
+		txs[i] = tx
+	}
+	return txs
+}
+
+func randomHeader(number uint64) *types.Header {
+	var bloom types.Bloom
+	for i, n := 0, rng.Intn(64); i < n; i++ {
+		bloom[rng.Intn(len(bloom))] |= 1 << uint(rng.Intn(8))
+	}
+	header := &types.Header{
+		ParentHash:  randomHash(),
+		UncleHash:   types.EmptyUncleHash,
+		Coinbase:    randomAddress(),
+		Root:        randomHash(),
+		TxHash:      randomHash(),
+		ReceiptHash: randomHash(),
+		Bloom:       bloom,
+		Difficulty:  big.NewInt(7000000000000000 + rng.Int63n(1000000000000000)),
+		Number:      new(big.Int).SetUint64(12000000 + number),
+		GasLimit:    uint64(15000000 + rng.Intn(100000)),
+		GasUsed:     uint64(rng.Intn(15000000)),
+		Time:        uint64(1620000000 + number*13),
+		Extra:       make([]byte, rng.Intn(33)),
+		MixDigest:   randomHash(),
+	}
+	rng.Read(header.Extra)
+	rng.Read(header.Nonce[:])
+	return header
+}
+
+func randomReceipts(n int) []*types.Receipt {
+	receipts := make([]*types.Receipt, n)
+	for i := range receipts {
+		receipt := &types.Receipt{
+			Type:              uint8(rng.Intn(2)),
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+		}
+		for j, logs := 0, rng.Intn(3); j < logs; j++ {
+			receipt.Logs = append(receipt.Logs, &types.Log{
+				Address: randomAddress(),
+				Topics:  []common.Hash{transferTopic, common.BytesToHash(randomAddress().Bytes()), common.BytesToHash(randomAddress().Bytes())},
+				Data:    common.LeftPadBytes(big.NewInt(rng.Int63()).Bytes(), 32),
+			})
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		receipts[i] = receipt
+	}
+	return receipts
+}
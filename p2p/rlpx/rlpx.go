@@ -37,7 +37,6 @@ import (
 	"github.com/c88032111/go-gdtu/crypto"
 	"github.com/c88032111/go-gdtu/crypto/ecies"
 	"github.com/c88032111/go-gdtu/rlp"
-	"github.com/golang/snappy"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -51,7 +50,9 @@ type Conn struct {
 	dialDest  *ecdsa.PublicKey
 	conn      net.Conn
 	handshake *handshakeState
-	snappy    bool
+	codec     Codec
+	sessions  *SessionCache
+	resumed   bool // Whgdtuer the session was resumed from a ticket
 }
 
 type handshakeState struct {
@@ -72,11 +73,26 @@ func NewConn(conn net.Conn, dialDest *ecdsa.PublicKey) *Conn {
 	}
 }
 
+// Initiator reports whgdtuer the local end dialed the connection.
+func (c *Conn) Initiator() bool {
+	return c.dialDest != nil
+}
+
+// SetSessionCache enables session resumption, storing the tickets in the given
+// cache. This must be called before the handshake.
+func (c *Conn) SetSessionCache(sessions *SessionCache) {
+	c.sessions = sessions
+}
+
 // SetSnappy enables or disables snappy compression of messages. This is usually called
 // after the devp2p Hello message exchange when the negotiated version indicates that
 // compression is available on both ends of the connection.
 func (c *Conn) SetSnappy(snappy bool) {
-	c.snappy = snappy
+	if snappy {
+		c.codec = Snappy
+	} else {
+		c.codec = nil
+	}
 }
 
 // SetReadDeadline sets the deadline for all future read operations.
@@ -99,7 +115,6 @@ func (c *Conn) Read() (code uint64, data []byte, wireSize int, err error) {
 	if c.handshake == nil {
 		panic("can't ReadMsg before handshake")
 	}
-
 	frame, err := c.handshake.readFrame(c.conn)
 	if err != nil {
 		return 0, nil, 0, err
@@ -110,17 +125,11 @@ func (c *Conn) Read() (code uint64, data []byte, wireSize int, err error) {
 	}
 	wireSize = len(data)
 
-	// If snappy is enabled, verify and decompress message.
-	if c.snappy {
-		var actualSize int
-		actualSize, err = snappy.DecodedLen(data)
-		if err != nil {
+	// If compression is enabled, verify and decompress message.
+	if c.codec != nil {
+		if data, err = c.codec.Decode(data, maxUint24); err != nil {
 			return code, nil, 0, err
 		}
-		if actualSize > maxUint24 {
-			return code, nil, 0, errPlainMessageTooLarge
-		}
-		data, err = snappy.Decode(nil, data)
 	}
 	return code, data, wireSize, err
 }
@@ -170,7 +179,7 @@ func (h *handshakeState) readFrame(conn io.Reader) ([]byte, error) {
 // Write writes a message to the connection.
 //
 // Write returns the written size of the message data. This may be less than or equal to
-// len(data) depending on whether snappy compression is enabled.
+// len(data) depending on whether compression is enabled.
 func (c *Conn) Write(code uint64, data []byte) (uint32, error) {
 	if c.handshake == nil {
 		panic("can't WriteMsg before handshake")
@@ -178,8 +187,8 @@ func (c *Conn) Write(code uint64, data []byte) (uint32, error) {
 	if len(data) > maxUint24 {
 		return 0, errPlainMessageTooLarge
 	}
-	if c.snappy {
-		data = snappy.Encode(nil, data)
+	if c.codec != nil {
+		data = c.codec.Encode(data)
 	}
 
 	wireSize := uint32(len(data))
@@ -259,9 +268,20 @@ func (c *Conn) Handshake(prv *ecdsa.PrivateKey) (*ecdsa.PublicKey, error) {
 		err error
 	)
 	if c.dialDest != nil {
-		sec, err = initiatorEncHandshake(c.conn, prv, c.dialDest)
+		// Resume the previous session with the remote peer if possible
+		if c.sessions != nil {
+			if ticket := c.sessions.take(c.dialDest); ticket != nil {
+				if sec, err = initiatorResume(c.conn, c.dialDest, ticket, c.sessions); err != nil {
+					return nil, err
+				}
+				c.InitWithSecrets(sec)
+				c.resumed = true
+				return sec.remote, nil
+			}
+		}
+		sec, err = initiatorEncHandshake(c.conn, prv, c.dialDest, c.sessions)
 	} else {
-		sec, err = receiverEncHandshake(c.conn, prv)
+		sec, c.resumed, err = receiverEncHandshake(c.conn, prv, c.sessions)
 	}
 	if err != nil {
 		return nil, err
@@ -336,6 +356,7 @@ type Secrets struct {
 	AES, MAC              []byte
 	EgressMAC, IngressMAC hash.Hash
 	remote                *ecdsa.PublicKey
+	resume                []byte // Secret for resuming the session later
 }
 
 // encHandshake contains the state of the encryption handshake.
@@ -373,21 +394,40 @@ type authRespV4 struct {
 // receiverEncHandshake negotiates a session token on conn.
 // it should be called on the listening side of the connection.
 //
-// prv is the local client's private key.
-func receiverEncHandshake(conn io.ReadWriter, prv *ecdsa.PrivateKey) (s Secrets, err error) {
+// prv is the local client's private key. If sessions is non-nil, previously
+// established sessions may be resumed and tickets are issued to initiators
+// supporting resumption. The returned flag reports whgdtuer the session was
+// resumed from a ticket.
+func receiverEncHandshake(conn io.ReadWriter, prv *ecdsa.PrivateKey, sessions *SessionCache) (s Secrets, resumed bool, err error) {
+	buf := make([]byte, encAuthMsgLen)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return s, false, err
+	}
+	if sessions != nil && isResumePacket(buf) {
+		if s, err := receiverResume(conn, buf, sessions); err != errInvalidTicket {
+			return s, err == nil, err
+		}
+	}
 	authMsg := new(authMsgV4)
-	authPacket, err := readHandshakeMsg(authMsg, encAuthMsgLen, prv, conn)
+	authPacket, err := decodeHandshakeMsg(authMsg, buf, prv, conn)
 	if err != nil {
-		return s, err
+		return s, false, err
 	}
 	h := new(encHandshake)
 	if err := h.handleAuthMsg(authMsg, prv); err != nil {
-		return s, err
+		return s, false, err
 	}
 
 	authRespMsg, err := h.makeAuthResp()
 	if err != nil {
-		return s, err
+		return s, false, err
+	}
+	if sessions != nil && wantsTicket(authMsg) {
+		if secret, err := h.resumeSecret(); err == nil {
+			if id, ok := sessions.issue(h.remote.ExportECDSA(), secret); ok {
+				authRespMsg.Rest = ticketResponse(id)
+			}
+		}
 	}
 	var authRespPacket []byte
 	if authMsg.gotPlain {
@@ -396,12 +436,13 @@ func receiverEncHandshake(conn io.ReadWriter, prv *ecdsa.PrivateKey) (s Secrets,
 		authRespPacket, err = sealEIP8(authRespMsg, h)
 	}
 	if err != nil {
-		return s, err
+		return s, false, err
 	}
 	if _, err = conn.Write(authRespPacket); err != nil {
-		return s, err
+		return s, false, err
 	}
-	return h.secrets(authPacket, authRespPacket)
+	s, err = h.secrets(authPacket, authRespPacket)
+	return s, false, err
 }
 
 func (h *encHandshake) handleAuthMsg(msg *authMsgV4, prv *ecdsa.PrivateKey) error {
@@ -451,6 +492,7 @@ func (h *encHandshake) secrets(auth, authResp []byte) (Secrets, error) {
 		remote: h.remote.ExportECDSA(),
 		AES:    aesSecret,
 		MAC:    crypto.Keccak256(ecdheSecret, aesSecret),
+		resume: crypto.Keccak256(resumeLabel, sharedSecret),
 	}
 
 	// setup sha3 instances for the MACs
@@ -469,6 +511,18 @@ func (h *encHandshake) secrets(auth, authResp []byte) (Secrets, error) {
 	return s, nil
 }
 
+// resumeSecret returns the secret for resuming the session, which is also part
+// of the connection secrets. It is needed before the handshake completes to be
+// able to issue a ticket in the auth response.
+func (h *encHandshake) resumeSecret() ([]byte, error) {
+	ecdheSecret, err := h.randomPrivKey.GenerateShared(h.remoteRandomPub, sskLen, sskLen)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret := crypto.Keccak256(ecdheSecret, crypto.Keccak256(h.respNonce, h.initNonce))
+	return crypto.Keccak256(resumeLabel, sharedSecret), nil
+}
+
 // staticSharedSecret returns the static shared secret, the result
 // of key agreement between the local and remote static node key.
 func (h *encHandshake) staticSharedSecret(prv *ecdsa.PrivateKey) ([]byte, error) {
@@ -478,13 +532,17 @@ func (h *encHandshake) staticSharedSecret(prv *ecdsa.PrivateKey) ([]byte, error)
 // initiatorEncHandshake negotiates a session token on conn.
 // it should be called on the dialing side of the connection.
 //
-// prv is the local client's private key.
-func initiatorEncHandshake(conn io.ReadWriter, prv *ecdsa.PrivateKey, remote *ecdsa.PublicKey) (s Secrets, err error) {
+// prv is the local client's private key. If sessions is non-nil, resumption
+// support is advertised and the ticket issued by the recipient is stored.
+func initiatorEncHandshake(conn io.ReadWriter, prv *ecdsa.PrivateKey, remote *ecdsa.PublicKey, sessions *SessionCache) (s Secrets, err error) {
 	h := &encHandshake{initiator: true, remote: ecies.ImportECDSAPublic(remote)}
 	authMsg, err := h.makeAuthMsg(prv)
 	if err != nil {
 		return s, err
 	}
+	if sessions != nil {
+		authMsg.Rest = ticketRequest()
+	}
 	authPacket, err := sealEIP8(authMsg, h)
 	if err != nil {
 		return s, err
@@ -502,7 +560,13 @@ func initiatorEncHandshake(conn io.ReadWriter, prv *ecdsa.PrivateKey, remote *ec
 	if err := h.handleAuthResp(authRespMsg); err != nil {
 		return s, err
 	}
-	return h.secrets(authPacket, authRespPacket)
+	if s, err = h.secrets(authPacket, authRespPacket); err != nil {
+		return s, err
+	}
+	if id, ok := receivedTicket(authRespMsg); ok && sessions != nil {
+		sessions.store(remote, id, s.resume)
+	}
+	return s, nil
 }
 
 // makeAuthMsg creates the initiator handshake message.
@@ -607,6 +671,14 @@ func readHandshakeMsg(msg plainDecoder, plainSize int, prv *ecdsa.PrivateKey, r
 	if _, err := io.ReadFull(r, buf); err != nil {
 		return buf, err
 	}
+	return decodeHandshakeMsg(msg, buf, prv, r)
+}
+
+// decodeHandshakeMsg decodes a handshake message whose first plain-size bytes
+// were already read into buf, reading the rest of it in the EIP-8 case.
+func decodeHandshakeMsg(msg plainDecoder, buf []byte, prv *ecdsa.PrivateKey, r io.Reader) ([]byte, error) {
+	plainSize := len(buf)
+
 	// Attempt decoding pre-EIP-8 "plain" format.
 	key := ecies.ImportECDSA(prv)
 	if dec, err := key.Decrypt(buf, nil, nil); err == nil {
@@ -0,0 +1,363 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rlpx
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/rlp"
+	"golang.org/x/crypto/sha3"
+)
+
+// Session resumption
+//
+// Peers which both opted into session resumption agree on a resumption secret
+// during the regular handshake: the initiator advertises support with an extra
+// field in its auth message (ignored by peers not knowing about it, as per
+// EIP-8) and the recipient answers with a session ticket id in the auth response.
+//
+// When reconnecting, the initiator sends a resume packet carrying the ticket id
+// and a fresh nonce instead of the auth message. The recipient answers with its
+// own fresh nonce and a new single-use ticket. Both sides derive the connection
+// secrets from the resumption secret and both nonces, without any public key
+// operations, so a replayed resume packet never yields the keys of an earlier
+// session. If the ticket is unknown to the recipient, the connection fails and
+// the next dial falls back to the full handshake, as tickets are single-use on
+// both ends.
+//
+// Each remote peer holds at most one ticket issued by the recipient, issuing a
+// new one invalidates the previous. If the cache is full, the ticket closest to
+// expiry is evicted.
+
+const (
+	resumeVersion = 1    // Version of the resumption mechanism advertised in auth messages
+	resumeMarker  = 0x01 // Marker following the size prefix in resume packets (EIP-8 packets have 0x04)
+	ticketIDLen   = 16   // Length of session ticket ids
+
+	resumePacketLen = encAuthMsgLen                 // Resume packets are padded to the size of a plain auth message
+	resumeAckLen    = shaLen + ticketIDLen + shaLen // Recipient nonce, new ticket id and authentication tag
+)
+
+var (
+	resumeLabel = []byte("rlpx-resume") // Domain separator of resumption secrets
+
+	errResumeRejected = errors.New("session resumption rejected")
+	errInvalidTicket  = errors.New("invalid session ticket")
+)
+
+// sessionTicket is a ticket received from a remote peer, which can be used to
+// resume a session with it.
+type sessionTicket struct {
+	id      [ticketIDLen]byte
+	secret  []byte
+	expires time.Time
+}
+
+// issuedTicket is a ticket handed out to a remote peer.
+type issuedTicket struct {
+	remote  *ecdsa.PublicKey
+	key     string // Encoded public key of the remote peer
+	secret  []byte
+	expires time.Time
+}
+
+// SessionCache stores the session tickets issued to and received from remote
+// peers. A single cache is meant to be shared by all connections of a node.
+type SessionCache struct {
+	limit    int
+	lifetime time.Duration
+
+	lock     sync.Mutex
+	issued   map[[ticketIDLen]byte]*issuedTicket
+	issuedTo map[string][ticketIDLen]byte // Id of the ticket issued to each remote public key
+	received map[string]*sessionTicket    // Keyed by the remote public key
+}
+
+// NewSessionCache creates a session cache holding at most limit tickets of both
+// kinds, each valid for the given lifetime.
+func NewSessionCache(limit int, lifetime time.Duration) *SessionCache {
+	return &SessionCache{
+		limit:    limit,
+		lifetime: lifetime,
+		issued:   make(map[[ticketIDLen]byte]*issuedTicket),
+		issuedTo: make(map[string][ticketIDLen]byte),
+		received: make(map[string]*sessionTicket),
+	}
+}
+
+// issue creates a new ticket for the given remote peer, invalidating any ticket
+// issued to it before. If the cache is full, expired tickets are dropped and if
+// none are, the ticket closest to expiry is evicted.
+func (sc *SessionCache) issue(remote *ecdsa.PublicKey, secret []byte) ([ticketIDLen]byte, bool) {
+	var id [ticketIDLen]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return id, false
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	key := string(crypto.FromECDSAPub(remote))
+	if prev, ok := sc.issuedTo[key]; ok {
+		sc.drop(prev)
+	}
+	now := time.Now()
+	if len(sc.issued) >= sc.limit {
+		var (
+			oldest  [ticketIDLen]byte
+			expires time.Time
+		)
+		for id, ticket := range sc.issued {
+			if now.After(ticket.expires) {
+				sc.drop(id)
+			} else if expires.IsZero() || ticket.expires.Before(expires) {
+				oldest, expires = id, ticket.expires
+			}
+		}
+		if len(sc.issued) >= sc.limit {
+			sc.drop(oldest)
+		}
+	}
+	sc.issued[id] = &issuedTicket{remote: remote, key: key, secret: secret, expires: now.Add(sc.lifetime)}
+	sc.issuedTo[key] = id
+	return id, true
+}
+
+// redeem retrieves and invalidates an issued ticket if the given check accepts
+// it. Ticket ids travel in plaintext, so a ticket failing the check is retained,
+// otherwise anyone could burn it by replaying the id with a bogus tag.
+func (sc *SessionCache) redeem(id [ticketIDLen]byte, check func(*issuedTicket) bool) *issuedTicket {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	ticket := sc.issued[id]
+	if ticket == nil {
+		return nil
+	}
+	if time.Now().After(ticket.expires) {
+		sc.drop(id)
+		return nil
+	}
+	if !check(ticket) {
+		return nil
+	}
+	sc.drop(id)
+	return ticket
+}
+
+// drop invalidates an issued ticket. It must be called with the lock held.
+func (sc *SessionCache) drop(id [ticketIDLen]byte) {
+	if ticket := sc.issued[id]; ticket != nil {
+		delete(sc.issued, id)
+		delete(sc.issuedTo, ticket.key)
+	}
+}
+
+// store saves a ticket received from the given remote peer, replacing any
+// previous one.
+func (sc *SessionCache) store(remote *ecdsa.PublicKey, id [ticketIDLen]byte, secret []byte) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	now := time.Now()
+	key := string(crypto.FromECDSAPub(remote))
+	if _, ok := sc.received[key]; !ok && len(sc.received) >= sc.limit {
+		for key, ticket := range sc.received {
+			if now.After(ticket.expires) {
+				delete(sc.received, key)
+			}
+		}
+		if len(sc.received) >= sc.limit {
+			return
+		}
+	}
+	sc.received[key] = &sessionTicket{id: id, secret: secret, expires: now.Add(sc.lifetime)}
+}
+
+// take retrieves and invalidates the ticket received from the given remote peer.
+func (sc *SessionCache) take(remote *ecdsa.PublicKey) *sessionTicket {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	key := string(crypto.FromECDSAPub(remote))
+	ticket := sc.received[key]
+	if ticket == nil {
+		return nil
+	}
+	delete(sc.received, key)
+	if time.Now().After(ticket.expires) {
+		return nil
+	}
+	return ticket
+}
+
+// resumeSecrets derives the connection secrets of a resumed session from the
+// resumption secret of the ticket and the nonces of both sides.
+func resumeSecrets(ticket []byte, initNonce, respNonce, packet []byte, initiator bool) Secrets {
+	sharedSecret := crypto.Keccak256(ticket, crypto.Keccak256(respNonce, initNonce))
+	aesSecret := crypto.Keccak256(ticket, sharedSecret)
+	s := Secrets{
+		AES:    aesSecret,
+		MAC:    crypto.Keccak256(ticket, aesSecret),
+		resume: crypto.Keccak256(resumeLabel, sharedSecret),
+	}
+	// setup sha3 instances for the MACs
+	mac1 := sha3.NewLegacyKeccak256()
+	mac1.Write(xor(s.MAC, respNonce))
+	mac1.Write(packet)
+	mac2 := sha3.NewLegacyKeccak256()
+	mac2.Write(xor(s.MAC, initNonce))
+	mac2.Write(packet)
+	if initiator {
+		s.EgressMAC, s.IngressMAC = mac1, mac2
+	} else {
+		s.EgressMAC, s.IngressMAC = mac2, mac1
+	}
+	return s
+}
+
+// resumeTag computes the authentication tag of a resume packet.
+func resumeTag(ticket []byte, id [ticketIDLen]byte, initNonce []byte) []byte {
+	return crypto.Keccak256(ticket, id[:], initNonce)
+}
+
+// resumeAckTag computes the authentication tag of a resumption acknowledgement.
+func resumeAckTag(mac []byte, respNonce []byte, next [ticketIDLen]byte) []byte {
+	return crypto.Keccak256(mac, respNonce, next[:])
+}
+
+// initiatorResume resumes a session with a ticket previously received from the
+// remote peer, waiting for the acknowledgement of the recipient and storing the
+// new ticket received with it.
+func initiatorResume(conn io.ReadWriter, remote *ecdsa.PublicKey, ticket *sessionTicket, sessions *SessionCache) (Secrets, error) {
+	initNonce := make([]byte, shaLen)
+	if _, err := rand.Read(initNonce); err != nil {
+		return Secrets{}, err
+	}
+	packet := make([]byte, resumePacketLen)
+	binary.BigEndian.PutUint16(packet, uint16(resumePacketLen-2))
+	packet[2] = resumeMarker
+	n := 3 + copy(packet[3:], ticket.id[:])
+	n += copy(packet[n:], initNonce)
+	n += copy(packet[n:], resumeTag(ticket.secret, ticket.id, initNonce))
+	if _, err := rand.Read(packet[n:]); err != nil {
+		return Secrets{}, err
+	}
+	if _, err := conn.Write(packet); err != nil {
+		return Secrets{}, err
+	}
+	ack := make([]byte, resumeAckLen)
+	if _, err := io.ReadFull(conn, ack); err != nil {
+		return Secrets{}, errResumeRejected
+	}
+	var (
+		respNonce = ack[:shaLen]
+		next      [ticketIDLen]byte
+	)
+	copy(next[:], ack[shaLen:])
+	s := resumeSecrets(ticket.secret, initNonce, respNonce, packet, true)
+	if !hmac.Equal(ack[shaLen+ticketIDLen:], resumeAckTag(s.MAC, respNonce, next)) {
+		return Secrets{}, errResumeRejected
+	}
+	if next != ([ticketIDLen]byte{}) {
+		sessions.store(remote, next, s.resume)
+	}
+	s.remote = remote
+	return s, nil
+}
+
+// receiverResume attempts to resume a session from a resume packet. It returns
+// errInvalidTicket if the packet doesn't resume a known session, in which case
+// it should be handled as a regular auth message.
+func receiverResume(conn io.Writer, packet []byte, sessions *SessionCache) (Secrets, error) {
+	var (
+		id        [ticketIDLen]byte
+		n         = 3 + copy(id[:], packet[3:])
+		initNonce = packet[n : n+shaLen]
+		tag       = packet[n+shaLen : n+2*shaLen]
+	)
+	ticket := sessions.redeem(id, func(ticket *issuedTicket) bool {
+		return hmac.Equal(tag, resumeTag(ticket.secret, id, initNonce))
+	})
+	if ticket == nil {
+		return Secrets{}, errInvalidTicket
+	}
+	respNonce := make([]byte, shaLen)
+	if _, err := rand.Read(respNonce); err != nil {
+		return Secrets{}, err
+	}
+	s := resumeSecrets(ticket.secret, initNonce, respNonce, packet, false)
+	s.remote = ticket.remote
+
+	// Acknowledge the resumption, handing out a new ticket if possible
+	var next [ticketIDLen]byte
+	if nextID, ok := sessions.issue(ticket.remote, s.resume); ok {
+		next = nextID
+	}
+	ack := make([]byte, 0, resumeAckLen)
+	ack = append(ack, respNonce...)
+	ack = append(ack, next[:]...)
+	ack = append(ack, resumeAckTag(s.MAC, respNonce, next)...)
+	if _, err := conn.Write(ack); err != nil {
+		return Secrets{}, err
+	}
+	return s, nil
+}
+
+// ticketRequest returns the auth message extension advertising resumption support.
+func ticketRequest() []rlp.RawValue {
+	enc, _ := rlp.EncodeToBytes(uint(resumeVersion))
+	return []rlp.RawValue{enc}
+}
+
+// wantsTicket checks whgdtuer an auth message advertises resumption support.
+func wantsTicket(msg *authMsgV4) bool {
+	if msg.gotPlain || len(msg.Rest) == 0 {
+		return false
+	}
+	var version uint
+	return rlp.DecodeBytes(msg.Rest[0], &version) == nil && version == resumeVersion
+}
+
+// ticketResponse returns the auth response extension carrying a ticket id.
+func ticketResponse(id [ticketIDLen]byte) []rlp.RawValue {
+	enc, _ := rlp.EncodeToBytes(id)
+	return []rlp.RawValue{enc}
+}
+
+// receivedTicket extracts the ticket id from an auth response, if any.
+func receivedTicket(msg *authRespV4) ([ticketIDLen]byte, bool) {
+	var id [ticketIDLen]byte
+	if len(msg.Rest) == 0 {
+		return id, false
+	}
+	return id, rlp.DecodeBytes(msg.Rest[0], &id) == nil
+}
+
+// isResumePacket checks whgdtuer a handshake packet looks like a resume packet.
+// Plain (pre-EIP-8) auth messages can collide with the marker, so any packet
+// failing the resumption must be processed as an auth message.
+func isResumePacket(packet []byte) bool {
+	return len(packet) >= resumePacketLen && packet[2] == resumeMarker
+}
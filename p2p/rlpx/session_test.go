@@ -0,0 +1,206 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rlpx
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+// tcpPipe creates a connected pair of loopback TCP connections. Unlike net.Pipe,
+// these are buffered.
+func tcpPipe(t *testing.T) (net.Conn, net.Conn) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			t.Error(err)
+		}
+		accepted <- conn
+	}()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, <-accepted
+}
+
+func TestSessionResumption(t *testing.T) {
+	var (
+		key1, key2 = newkey(), newkey()
+		sessions1  = NewSessionCache(16, time.Minute)
+		sessions2  = NewSessionCache(16, time.Minute)
+	)
+	connect := func() (*Conn, *Conn) {
+		conn1, conn2 := tcpPipe(t)
+		peer1, peer2 := NewConn(conn1, &key2.PublicKey), NewConn(conn2, nil)
+		peer1.SetSessionCache(sessions1)
+		peer2.SetSessionCache(sessions2)
+		doHandshake(t, peer1, peer2, key1, key2)
+		return peer1, peer2
+	}
+	// Run a full handshake, which should exchange a ticket
+	peer1, peer2 := connect()
+	if peer1.resumed || peer2.resumed {
+		t.Fatalf("full handshake treated as resumed")
+	}
+	checkMsgReadWrite(t, peer1, peer2, 1, []byte("full"))
+	checkMsgReadWrite(t, peer2, peer1, 2, []byte("full"))
+	peer1.Close()
+	peer2.Close()
+
+	if len(sessions1.received) != 1 || len(sessions2.issued) != 1 {
+		t.Fatalf("tickets not exchanged: received %d, issued %d", len(sessions1.received), len(sessions2.issued))
+	}
+	// Resume the session twice, each resumption should hand out a new ticket
+	for i := 0; i < 2; i++ {
+		peer1, peer2 := connect()
+		if !peer1.resumed || !peer2.resumed {
+			t.Fatalf("resumption %d: session not resumed: initiator %t, recipient %t", i, peer1.resumed, peer2.resumed)
+		}
+		checkMsgReadWrite(t, peer2, peer1, 3, []byte("resumed"))
+		checkMsgReadWrite(t, peer1, peer2, 4, []byte("resumed"))
+		peer1.Close()
+		peer2.Close()
+
+		if len(sessions1.received) != 1 || len(sessions2.issued) != 1 {
+			t.Fatalf("resumption %d: tickets not renewed: received %d, issued %d", i, len(sessions1.received), len(sessions2.issued))
+		}
+	}
+}
+
+func TestSessionResumptionRejected(t *testing.T) {
+	var (
+		key1, key2 = newkey(), newkey()
+		sessions1  = NewSessionCache(16, time.Minute)
+	)
+	// Inject a ticket unknown to the recipient
+	sessions1.store(&key2.PublicKey, [ticketIDLen]byte{1}, make([]byte, shaLen))
+
+	conn1, conn2 := tcpPipe(t)
+	peer1, peer2 := NewConn(conn1, &key2.PublicKey), NewConn(conn2, nil)
+	peer1.SetSessionCache(sessions1)
+	peer2.SetSessionCache(NewSessionCache(16, time.Minute))
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := peer2.Handshake(key2)
+		peer2.Close()
+		errc <- err
+	}()
+	if _, err := peer1.Handshake(key1); err != errResumeRejected {
+		t.Fatalf("wrong error for rejected resumption: have %v, want %v", err, errResumeRejected)
+	}
+	if err := <-errc; err == nil {
+		t.Fatalf("recipient accepted unknown ticket")
+	}
+	peer1.Close()
+
+	// The ticket is single-use, the next connection must do a full handshake
+	if len(sessions1.received) != 0 {
+		t.Fatalf("rejected ticket not dropped")
+	}
+}
+
+func TestSessionResumptionUnsupported(t *testing.T) {
+	var (
+		key1, key2 = newkey(), newkey()
+		sessions1  = NewSessionCache(16, time.Minute)
+	)
+	conn1, conn2 := tcpPipe(t)
+	peer1, peer2 := NewConn(conn1, &key2.PublicKey), NewConn(conn2, nil)
+	peer1.SetSessionCache(sessions1)
+	doHandshake(t, peer1, peer2, key1, key2)
+	checkMsgReadWrite(t, peer1, peer2, 1, []byte("full"))
+	peer1.Close()
+	peer2.Close()
+
+	if len(sessions1.received) != 0 {
+		t.Fatalf("ticket stored from peer without resumption support")
+	}
+}
+
+// acceptTicket is a redemption check accepting any ticket.
+func acceptTicket(*issuedTicket) bool { return true }
+
+// Tests that a resumption attempt with a forged tag doesn't invalidate the ticket,
+// which is still redeemable by its legitimate holder afterwards.
+func TestSessionResumeForgedTag(t *testing.T) {
+	var (
+		sessions = NewSessionCache(2, time.Minute)
+		remote   = &newkey().PublicKey
+		secret   = make([]byte, shaLen)
+	)
+	id, _ := sessions.issue(remote, secret)
+
+	packet := make([]byte, 3+ticketIDLen+2*shaLen)
+	copy(packet[3:], id[:])
+	initNonce := packet[3+ticketIDLen : 3+ticketIDLen+shaLen]
+	initNonce[0] = 0x01
+
+	if _, err := receiverResume(new(bytes.Buffer), packet, sessions); err != errInvalidTicket {
+		t.Fatalf("forged resumption error mismatch: have %v, want %v", err, errInvalidTicket)
+	}
+	if _, ok := sessions.issued[id]; !ok {
+		t.Fatalf("ticket invalidated by forged resumption")
+	}
+	copy(packet[3+ticketIDLen+shaLen:], resumeTag(secret, id, initNonce))
+	if _, err := receiverResume(new(bytes.Buffer), packet, sessions); err != nil {
+		t.Fatalf("legitimate resumption failed: %v", err)
+	}
+	if _, ok := sessions.issued[id]; ok {
+		t.Fatalf("ticket still valid after resumption")
+	}
+}
+
+// Tests that the recipient keeps a single ticket per remote peer, and evicts the
+// ticket closest to expiry once full.
+func TestSessionCacheIssue(t *testing.T) {
+	var (
+		sessions = NewSessionCache(2, time.Minute)
+		remote   = &newkey().PublicKey
+		secret   = make([]byte, shaLen)
+	)
+	first, _ := sessions.issue(remote, secret)
+	second, _ := sessions.issue(remote, secret)
+	if len(sessions.issued) != 1 {
+		t.Fatalf("tickets issued to the same remote: have %d, want 1", len(sessions.issued))
+	}
+	if sessions.redeem(first, acceptTicket) != nil {
+		t.Fatalf("replaced ticket still redeemable")
+	}
+	// Fill up the cache, the oldest ticket must make room for new ones
+	sessions.issue(&newkey().PublicKey, secret)
+	last, _ := sessions.issue(&newkey().PublicKey, secret)
+	if len(sessions.issued) != 2 || len(sessions.issuedTo) != 2 {
+		t.Fatalf("cache limit exceeded: have %d tickets, %d remotes", len(sessions.issued), len(sessions.issuedTo))
+	}
+	if sessions.redeem(second, acceptTicket) != nil {
+		t.Fatalf("oldest ticket not evicted")
+	}
+	if sessions.redeem(last, acceptTicket) == nil {
+		t.Fatalf("new ticket not redeemable")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rlpx
+
+import "github.com/klauspost/compress/zstd"
+
+// zstdDictID is the identifier of the dictionary in the frames of the zstd
+// codec. Changing the dictionary requires a new ID and codec name.
+const zstdDictID = 0x67647531
+
+func init() {
+	RegisterCodec(newZstdCodec())
+}
+
+// zstdCodec compresses messages with zstd, using a dictionary trained on RLP
+// encoded blocks and transactions. The codec is shared by all connections, the
+// encoder and decoder run as many operations in parallel as there are CPUs.
+type zstdCodec struct {
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+func newZstdCodec() *zstdCodec {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderDict([]byte(zstdDict)))
+	if err != nil {
+		panic(err)
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(maxUint24)), zstd.WithDecoderDicts([]byte(zstdDict)))
+	if err != nil {
+		panic(err)
+	}
+	return &zstdCodec{enc: enc, dec: dec}
+}
+
+func (c *zstdCodec) Name() string { return "zstd/1" }
+
+func (c *zstdCodec) Encode(data []byte) []byte {
+	return c.enc.EncodeAll(data, nil)
+}
+
+func (c *zstdCodec) Decode(data []byte, maxSize int) ([]byte, error) {
+	var header zstd.Header
+	if err := header.Decode(data); err != nil {
+		return nil, err
+	}
+	if header.HasFCS && header.FrameContentSize > uint64(maxSize) {
+		return nil, errPlainMessageTooLarge
+	}
+	data, err := c.dec.DecodeAll(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSize {
+		return nil, errPlainMessageTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,23 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rlpx
+
+// zstdDict is the dictionary of the zstd codec, trained on RLP encoded block
+// and transaction messages. Use mkdict.go to create/update it.
+
+// nolint: misspell
+const zstdDict = "7\xa40\xec1udg\x1e\x10 \xdd\x01xD\x04\x9bQ\x1c±/|z\xfd\x9do\xb2Yf\t\xff\xff\xff\xb3H\x95R\x06\x83\t\x14\xc2P\x1cE\xe20\v{=\x00\x04\xe0C\f\x91\f\x14\x93\x8e\n\x89\x91\xc6B\x81@P\x18\x88\xa2\x90`\xc40\f\xc30D\x19c\x8cq\t\x00\x00\x11\x00\x00t\x8c\x11\x81\x18\x00\x00\x03\b\x000\x00\x00\x00\x00\x00\xc0(\x14\t\x01\xa0;\x00\x00\x00\x01\x00\x00\x00\x04\x00\x00\x00\b\x00\x00\x00g\xc1\xa1D*\xad\xec\xc7\xeaV\xa15\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x14\xe3A\x02\x83\xeb\xb8z\xb9\x01\xab\x01\xf9\x01\xa7\x01\x83\b\xf8\xe0\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x8f\x87ȹ\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x01\x00\x00B\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x04\x00\x00\x00\x00\x00\x00\xc8\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x80\x00\x00\x00\x80\b\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x04\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x02\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x01\x05\x00\x02\x00\x00\x00\x00\x00\x04\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x84\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00 \x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x10\x80\x00\x00\x00\x00\x00\x00\x00\x00(\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x01\x00\x00\x00\x00\x00\x00\x00@\x01\x00\x00\b\x00\x00\x00\x87\x1cHbsz\x86n\x83\xb7)J\x83\xe6(\r\x83C\xcd5\x84`\x8f\xf6\u008d\xc7\xe9\xd2\xea\"\xcc\a_\x13\x8c4uĠ\xb5\x89\xa6{\x89\x86(Ô;AS/NF\xb0\xf5\x83\x9d\xcd\xda\xd8\x03\x9ds\x8bM\x8d_\xe8\x98<\x88\x1d\x12H\xf9TJ\xfa)\xf9\x02\xf0\xf9\x02츦\x01\xf8\xa3\x01\x83\x01\x0e\xb9\x85!\x87\x11\xa0\x00\x82R\b\x94\xfe\xdf\x01\xa6ئlf\x99k\x84\t\x9a\x8e\x88\xe9\x9c{\x16C\x81\xb2\x80\xf88\xf7\x94\xfe\xdf\x01\xa6ئlf\x99k\x84\t\x9a\x8e\x88\xe9\x9c{\x16C\xe1\xa0&\xdf&\xa8ԅ\xbb<\x8c\xae\x93\t\xf8\xa2\xf0\xed\x88Ӑ.\xa7\xaa\x02\xbcU\xe0\x0f\x1e\x86\x11\x90/\x80\xa0\xb3\x99ɾ\nd\x8ck\x91\xcb7\xcf\xd8mW}\xbdcHl\xb9D\x93u\a\xe6\xdbѡ\xec\a\x8b\xa03'\xc1\xbc#\xfe\xd3\xc3\xc6\xffe\xd2&\xbf︴2p\x88`9m\xcb2\x04/C`ݹ'\xb8\xeb\x01\xf8\xe8\x01\x83\x01c_\x85\x13\x8e\xcaH\x00\x83\x01л\x00\x10\x00 \x00\x00\x80\x00\x00\x01\x00\x00 \x00\x00\x00\x80\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00@\x00\x02\x00\x00\x00\x00\b\xc0\x00\b\x00\x80\x87\x1a\xc8~<Ϟ\x1c\x83\xb7(a\x83\xe6a\xad\x83L\xc8\xf8\x84`\x8f\xea\xed\x84,\xa4\xe9S\xa0\xf4\xf9\x9ei\xf5\xf8\x99\xe8tk\x1d=qH\x83\xd9\x1cn\x10\x0f%x\x99o\xb2\xa2\x01{\xd6\xe1\x8dv\x88\\6\a\x94d\x92\xaf6\xf9\x02\x17\xa0Csʩ\xd4#\xd7e\x92\xfd\r\x068O\xddFS\x1fS\xb9\xc6g-p:\xb8\x8c\xbf\x17;V\xe7\xa0\x1d\xccM\xe8\xde\xc7]z\xab\x85\xb5g\xb6\xcc\xd4\x1a\xd3\x12E\x1b\x94\x8at\x13\xf0\xa1B\xfd@ԓG\x94\"\x10BS\x0f\xc7B\x06\xd0c\x8b\x98S\xa8nZ\xab\x9aᲠ#\xcb_\xccq8\x0e\x88\xbc\xb7{\t\xf1\x18\xad\x8b\xe6\x1f\xef YY\x82\x05\x9d\x86\a/\xdcT\xbd\xab\xa0\xff\x937d\xdc4yn\xe1\x92\xe40ف\xa0Mx\xee\xe9!T\t\xdbyw\xe4~:qz:Ǡ\xbf\xb6\xe9\xf2|O^\xf6\x95\xc5T牾\xaf\xa3t\xa5˧\xc8F\xc7r7\xf0F/P\x16\xb8\x11\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x02\x00\x00\"\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x01\x00\x00\x01\x80\x80\x00\x00\x00\x00\x00\x00\x00\x00\b@\x00\x02\x00\x00\x00\x00\x00\x00\x10\x10\x00\x00\x00\x00\x00\x00\b\x02\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x01@\x00\x00\x00\x00\x00\x00\x04\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00 \x00\x80\x00\x00\x00@\x00\x04\x00\x00\x00\x00\x00\x00\x04\x80\x01\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x11@\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x04\x00\x00\x00\x00\b\x00\x00@\x03\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x10\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\b\x00\x80\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x10\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x02\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf9\x01:\xf8\x9b\x94\xe3d\xe8\rK\xde\xcf\ru\x8c\xbb\xb1Z\r|\xc0\xf8)\x8f\xe0\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00K\xbc$HO6\xba\xce\xd5z̰\xa3\x04\xfa\xc0\xbf\xe93\xa3\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xcc\x02l,\xfd\x91\xa9\x0e\x82\xb7\xfa\xd2\x01[G\x06\xba\\\x84\x19\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00M\xb30\xe2\x05\xbb'$\xf8\x9b\x94\xf8L\xb8\xb7,p\x9cp\xec\xf0J\xbd\xcc\xee\xa3%\n\xe2\xd1)\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xba\xe69b[\x18#aA \x9f\xb4\xb3p\xa8q\x96\xaaw\x8f\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00=\x1bH\xc7g\f9\x1b\x8b\x10\xc2WE\x84S\x0e\xf3\xed\xab\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x8a\xb2\xf8\xe7\xec?k\xb9\x02I\x01\xf9\x02E\x01\x83\b\xa6ع\x01\x00\x00\x00\x00\x00\x00\x00\x01\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x05\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x01\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x04\x89\xe1\xe2^\x17tU\x13\n\xfa\xabs\xedJ\x14\n\x18#Fj\xe8F\x9c\xb6\xd9\xecBK>\x88;Ӳ\bP\x89\x8cK\xf9\x02\x15\xa0Z\xae \xca\xc2,n\r!\xfb\xa4k\xab\x96|2\xc7\xcfG\x91\xec\x1f-\xf9w\x9d\x9c\xe9\xf6\xe4M\xf3\xa0\x1d\xccM\xe8\xde\xc7]z\xab\x85\xb5g\xb6\xcc\xd4\x1a\xd3\x12E\x1b\x94\x8at\x13\xf0\xa1B\xfd@ԓG\x94\xaabU\xba\xb7\x91\xa1Qgv\xdc\x11r\xb7\x9dlk\xd56J\xa0\xce:E߫\xfe\x03\x99\n\xf3U|\xd8\xeb#\x0f\xbb\x0e*\xb2\xa3Mr\x1a(Q\xd7\x19\xc4\xe3< \xa01\x1d\xfdw6\x92ì\xc5|#\x800\x17\xe2\xfd\xb5*}*b;\xe6\x88HD{7\x91\xae\xb4\v\xa0b\xd2K\x9fΑ\x9f\xe5\xb8\xd2ɳ@\x8b9\x86\x91y\x1b\xd2\xc6(\xf6\\?g3\x1d`\xfeC\xf7\xb9\x01\x00@\x00\x00\x80\x00\x00\b\x00\x00\x00\b\x00\x04\x00\x00\x00\x00\n@\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\b\x00\x00\x10\x04\x00$\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x10\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x04\x00\x00\x80\x00\x00\x00\x00\x02\x00\x04@\x00\x00\x80\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00$\x00\x00\x00\x00\x00\b\x00\x00\x00\x01\x00\x00\x00@\x00\x00\x00\x00\x00\x02\x00\x01\x01\x00\x04\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x000\x00\x00\x00\x00\x04\x00\x00\x01\x02\f\x10\x00\x00@\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x01\x00\x00\x00\x00\x00\x00\x00\x00\x80\"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x04\x00\x01\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x02\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x02\x00\x00\x00\x00\x87\x1a\a\x17\x8f\xaf\xd4\u0083\xb7$r\x83\xe6(\a\x83\x80=\x11\x84`\x8f\xb7ʔ\x7f\xbcx\xa7\x9c\xfe\nA݊\xdaUӀI\xf3\xdfFɈ\xa0\xc5\xcb\x00\xac\x00\x00\x80\x00\x00\x00\x00\x00\x02\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\xf9\x01:\xf8\x9b\x94\x05*\xebh\x19YH\xaaa\xeaT\xf1:\x95#\x8d\xa6]~\v\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xe4T\xf9\";\xaf\xa6\v\x90X\x9b*\x01\x87\x0f\xd4\xfe\v\xb3d\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x001\x12ݾ\x83\xcf\xe8\xee\xe1ݦ\x0f\x1c\xecg%\xd6˃Y\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x005yo\xe7\xcf\x1aIh\xf8\x9b\x94\xab^x\xe3\xc6\xfbm\x86ei\xeb\x1a\xadq\xbc\x13Bq\x96\x80\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x8e\x95I\xc4Q[p˔zX\x83×\xb5;tJ襠\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00w^\xa3\xf7\xf6F\x84\xac\xc0#\xee\x15\x92\n\xc7\xf4I\xb5T\x1a\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00q\x8et\x1e\x8f\xa2\xd7\x03\xb9\x01\xaa\x01\xf9\x01\xa6\x01\x82\xf6\x18\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00 \x00\x00 \x00\x00\x00\x00\x97\xf7\xf9\x01\xa7\x01\x83\x06\x16\x98\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf8\x9d\xf8\x9b\x94\x1a\xf5\x0e\x0fe4\"X\xf6\x8f0\xd3\xd1\xcd\xd7 TΕ\xfb\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf2\x87\xdb{6\x9e+փ\xac\xe7:\t\x062\xb1#i\xe90\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf7\x93\x05d-ɉ!\x1e\xb0M+\xa85\xe1\x92;!T'\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\v\xa0\xe2\x15\xaa\x89\xae&\xb9\x02I\x01\xf9\x02E\x01\x83\x06h\xa0\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00`\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x01\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x80\x00\x00\x00 \x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\b\x00\x00\x10\x00\x00\b\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00B\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\xf9\x01:\xf8\x9b\x94\rE\x15\xb4\xb8\xac\x81\xb2'\x90_\xa1JU\xa00\x9d\xec\xe0\xdf\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\r\x8a)\x1e\xe7\xf4\x96nf\xea-\x19cv\xd2nʳnt\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa8\xe7@!݄\x99uЍ\xfas`p{\xd5;{1\xf2\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00F k\x10ЖH\xbe\xf8\x9b\x94\xe7JD\xac\xe6\xbbɠ\tV\xf3\xda\x10\x93\xdd\"\xf9d\xba^\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xe6ocR\x0e\xc6ʺ\x95\xc0\x84S\x02\xc7\xd9\xcdb$LӠ\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00G\xea\xe7\xf4\x96\xc7\t\"6\\\x05G\xc9U\xba`Q\x05\x8bS\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00j\xf0\xcd\xc6\r\xba\x04G\xb9\x01\r\x01\xf9\x01\t\x01\x83\a\f\xb0\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xc0\xf9\x01\xa7\x01\x83\x05 \x80\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00@\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf8\x9d\xf8\x9b\x94\xde\xf4\xb8\x19M\x92<\xe0,\xe0\x8cץJ\xf3)v\tQ0\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xaf\xf6\xb8-l \xbb\xe09\xe6\xfa5\xf4<\xe4d\x8fdad\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb4g\xe0\xb3\xcc!\xce,O\x83\xc6\xf6ę:\xd2\xea\xba\xc3~\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\"\x1f\f\xb9\xbe&\xb8\xef\xf9\x02E\x01\x83\x05r\x88\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x02\x00\x00\x02\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\b\x00\x00\x00\x00\x00 \x00\x00\b\x00\x00@\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x02\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00@\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x80\x00\x00\x10\x00\x00\x00\x00\x00\x00\x01\x00\x00 \x00\x00\x80@\x01\x00\x00\x00\x00\x00\x00\x00\x00\x18\x00\x00\x00\x00@\x01\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x02@\x00\x80\x00\x00\x00\x00\x00\b\x00\x00 \x00\x00\x01\x00\x00\x00\x00\b\x00@\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00!\x00\x00\x00 \x00 \x00\x10  \x00\x00\x00\x00\x04@\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x01\x00\x00\x00\x00\x87\x1b\x04\x05\xa2n{Ã\xb7%\x12\x83\xe5\xb2l\x83\x99@\x80\x84`\x8f\xbf\xea\x84\vCV}\xa0\x19\xce\xd37;\xcclhHU`\x81v\xb5\xb2\xc0\x144\x17\xe7~\xaeȄ\xec\x00\x18\x88\x98ΡT\x88\xe8-\xea\xebX\x01\xa4\xf6\xf9\x02\x1c\xa0\xbe\xeax^\x17~\x06\xbc\xbc\x91d\xd4N[\x1a\x8c\xbd\xde\xf9\xf88yYG\xad\x7f\x92\xd3\x01{]\b\xa0\x1d\xccM\xe8\xde\xc7]z\xab\x85\xb5g\xb6\xcc\xd4\x1a\xd3\x12E\x1b\x94\x8at\x13\xf0\xa1B\xfd@ԓG\x94j\xebH2\xe8\x7f\xe4\xc4\xd2\r\fd\xbe\t\xedW\x7f\xd2\xc4\x01\xa0q&\xca-\xa0ssԩr>\xecl\xab\xa3\xf04}DE|\x86Ol\x95\xedG\xc7m\x1b\xb5\xea\xa0~SJ\x8f\xe8\x04\xf4\xf5NK\a\xba\xa5t\x8e\x9b\x1b\x16l\x99\xe8.x\xf4\xb1k1Y6\x94\xf3\x94\xa0Z\x11!)\xd6\xc0\x9f\x05D\x18e\x11֎\x1c.\x8a\x1a\xf0\xac_\xdfv\xf9y\x9c\x8c%^\x8b\b\xe1\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x02\x00\x00\x00\x00\"\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x80\x04\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00 \x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\b\x00 @\x00@\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x04\x00\x00\x00\b \x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\xf9\x01:\xf8\x9b\x94\x87\x8d\xd3\xec_\xd2a\"\x18\xfd_ֽ\xa6!1HQ\x10\xb0\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00Ii,\xcb\xc7Ӡ\n\xcc\xef\x80r\x91J\xd1w\x96\xc4\xf9\u00a0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00X\x10\xb4Q\x99ؒ#\xa1\x04Geఛ\r\x93\xf5¬\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00#\xb9\xfeƻ/B9\xf8\x9b\x94W\x95\xf4\x93[\x12\xfc\xff\x000\x15T\xb1\xa2\xf1al\xa5\xe3\x87\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00m\x8ca\xec,\xb7`C\xa9U\xa4w\x11Z\xe5ʎ\x145\x8d\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x004\xba۞\x06\xa3\b\xfa\xdc\x05\x1e\x0f\x84\x14\xfc\x97\x93Z\x16L\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x06`\xff5geAz\xb9\x02I\x01\xf9\x02E\x01\x83\a\xb0\xc0\xb9\x01\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x01\x00 \x00\x00\x00\x00\x00\x10\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x04\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x88\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\b\x00\x00\x00\x00\x00\x00\x80\x00\x00\x02\x00\x00\x00\x00@\x00\x00\x00\x00\x00\xc0@@\x00\x00\x00\x00\x01\x10\x80\x00\x00\x10\x00\x00\x00\x00\x00\x10\x00\x04\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 @\x00\x80\x00\x00\x00\x00\x01@\x00\x00\x00\x00\x02\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x02\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x87\x1a(\xb8Hs\xd9\x11\x83\xb7 \xad\x83\xe5\a\x94\x83'm\x83\x84`\x8f\x86ɝm(W\xd7\x14\xc4F\x0e\x90\xca8/\x1d\xad>[]\x9c\xa4\xdf\x1a$.\xc00\xb1髣\xa0\xcb\xea\x92j\xa1\x16\x80H\xe0b\x7fl\x1b\xb7\xb0\xbaJ\x0e\x14\xa0\xe0\xd1\xf6\xa7\x18#j\xed2F\x8d\xb4\x88\x04\x93d\\\xf1p\xf4\xde\xf9\x02\x1f\xa0\xbf\x130\xb2\xcd\xfd&Ա[\x96\xdeyJ\xf2\x80\xe6go.$\x1e\x8c\xd7\x05\x05,\xc5\x11\xfa\xdbx\xa0\x1d\xccM\xe8\xde\xc7]z\xab\x85\xb5g\xb6\xcc\xd4\x1a\xd3\x12E\x1b\x94\x8at\x13\xf0\xa1B\xfd@ԓG\x94k\xe6\x9fb\xc6|bN\x9fԶr\xb6\x9d\x1etB\x140ʠ\xd7\xc7cts4\xa2\xf1oo{%\xd9\xc3~\xe9\xb6\xf7\x99\xf4\xcc\x03(k\x8f\xc1@m\x00\xd4=\x03\xa0\xcc!ᝃ\x0fQ\xeag\x13\xdf\x19eQ\x82\xe4\x89뭒\x92C\xf0\xb2!6?\x84\x10\x97\xf2⠣N\x1d\x1a1r:\xc03\xe3l\x88v;\x06\x91\xf5\nk\u0094\xbf\xe2\a`\x99\\\xa9˟lѹ\x01\x00\x00\x00\x01\x00\x00 \x00\x00\x04\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00 \x10\x00\x00\x10\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00@\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\t\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x04\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x80\x00\x00 \x04\x00\x00\x00\x00\x00\x80\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xc0\xb9\x01\xab\x01\xf9\x01\xa7\x01\x83\x03\xd8`\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b@\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x04\x80\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf8\x9d\xf8\x9b\x94\xb2\xe7`\xb5H\xb5\xcb\xfb\xf6\x9c[l\x1b\xc9Q{\xe5*\xe5}\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xc1ւ\xed\x8e^\xf8Dk\xd5\b\x0fC4VAT\xad\x85\x7f\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\\cB)\x17o\xde\tf\xd1\xd68\x01\xe4\xf0 \xa7\xd3Y\x1e\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00s\x81\x97\xec\x02\xb72-\xb9\x02I\x01\xf9\x02E\x01\x83\x04*h\xb9\x01\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x87\x1b\xfc\xf2 )\xf7\xa2\x83\xb7'߃\xe5oȃݶ\x95\x84`\x8f\xe4S\x81\x91\xa0\xa8\xbcޑn&\x96\x17|\xf1\x17\x03\x93D\xddKD/cEi\tL\r\xe13\xfe\xbfHI\xe6\x1b\x88\x8f7\x95ʌw\\\x1d\xf9\x02\x1c\xa0\xbd\x06Mܯ\v\x98\xeem\x15܌\xffXxK\xdf\xeb\xf1\xb0x\xa8F\x939ЕPI\xb9[\xa0\xa0\x1d\xccM\xe8\xde\xc7]z\xab\x85\xb5g\xb6\xcc\xd4\x1a\xd3\x12E\x1b\x94\x8at\x13\xf0\xa1B\xfd@ԓG\x94\xdb\t&\xb5\xaa-\x8e\x17c\xe5e\x84\xc1Ơ\nD\tF\xa3\xa0\x82\x87(\xb5\x8e \xd5\xfe\x01\x8c;q\xfeڅ2E\x00\xba6/\xb5\x19\xdak\xcbt\xf9\xd1\xfb7\xa5\xa05\xb6\x14+\x9a\xa3 jꛡ\x92GȚU\x1f\xea+\xe8\x19ur|\xa7d\xa3\xe3\x92\x14\x88k\xa06\x84\xee\xe6X\xa8\xde\xe7\x7f \x8a\x9a\\\x85\x12\\\x8f\x9cj\n\x06\x1e\xc3\xc2\xef\x89Y\x04N\xef\f\xf0\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\b\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\b\x01\x00\x00\x00\x00 \x00\x00\x02\x04\x00\x01\x00\x00\x00\x00\x00\x00\x00\x10\x80\x02\b\x00\x00\x00\x00\x00\x00\x00\x14\x00\x00\x00\x00\x10\x00\x00\x10\x00\x00\b\x00\x10\x01\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x80\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x11\x00\x00\x00\x00\x00\x00\x00\x10\b\x00\x00\x04\x00\x00\x01\x00\b\x00\x00\x00 \x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00@\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00D@\x00\x00\x00\x00\x04\b\x00\x00\x00\x00\x00\x00\x00\x00@\x01\x00\x00\x00\x04\x00\x00\x00\x00\x02\x00\x00\x00\x10\x02\x00\x00\x00\x00\x00\x00\x00\x00\x82\x00 \x80\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x04\x00\x87\x1a\x04\xee\xc6\xc5L׃\xb7'\xe0\x83\xe5\x00\x00\x00\x00\x04\x00\x00\x00\x02\x00\x02\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x04\x00\x00\x00\x00\x00 \xf9\x01:\xf8\x9b\x949\x1e\xe8\x97\x1b\xba\x1d\a\x11;\xadY\xd3S\x877\xfc=\xf8\x83\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xff\xbd\xc0\x06\x05\xf1<\xebw\x92\xd4#\xb1m.|\xa3\xa6x\xa6\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x980&^\xb2J\x9bR\xad(7\xdb9\xaeTU\x18_b\"\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00&\xdf\xfd\xb5\xa6I\x1c\xc0\xf8\x9b\x94\x8d[m\xc4'\x86\xb8zg\x15\xf6\xe1Ypc\x9e\xc51ǅ\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xdeQ\xe4̣\x9dPiO\x1a\xc4\xc0\x9b\xd2\x05\xd0M>`\xb2\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x1fp\"\x86\xeb$\xbb\xae\x8a\xe0VP\x986+R\xb0'\xa0\x80\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00L\xf9\a@}#>\x0e\xf9\x02E\x01\x83\x01H \xb9\x01\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00(\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\n\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\xc0\xb9\x02H\x01\xf9\x02D\x01\x82\xa4\x10\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x04\x00\x04\x00\x00\x00\b\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \b\x00\x00\x00\x00\x00\x00\x02\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 @\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf9\x01:\xf8\x9b\x94\x9d\x00\x83\xfb\xc0ײ\x92=%\x05\x83\xad\r\x04/\x9bp~\x10\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xabH\x92I\xca6n\xa4\x98\x89O\xf8\x88\x942Y\xfdP4\x92\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\xe4\xba\x1ftp=F\xc1ʖ\xd7\x13\xa2\xbflX%m\xbd\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x1a\x82\n]\xb9\xebZ\x80\xf8\x9b\x94\x1bR\x93\x94N4\xaf\xf0lŢ\x1f\xfc͔\"\xb8,D\xf7\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00cY\b\xaf\xfe\xbf\x04\x8f\xf2K>\xe0Nw\nx\xc8;\xa1\x87\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00@\x00\x80\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00@\x00@\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x02\x00\x01\x00\x00\x00\x00\x00\x00\x00\b\x02\x00\x00\x10\x00\x00\x00\x80\x00\x01\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\b\x02\x00\x01\b\x00\x00\x00\x00\x00\x00\b\x00@\x00\x00\x00\x00\x00\x00\x00\x02 \x00\b\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00 \x00\x00\x00\x00\x00\x00\x04 \x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04@\x00\x00\b\x00\x10\x00\x00\x00\x00\x00\b\x01\x00\x00\x80@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x80\x00\x00\x00\x00\x10\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x02\x00\x00\x00\x00\x00\x00\x01 \x00\x00\x00 @\x00\x80\x00\x04\x00\x00\x00\x00\x02\x87\x19\xb6n\xcciޟ\x83\xb7\"\x94\x83\xe5~v\x83d=\xa3\x84`\x8f\x9f\x84\x8bʿ\x15\xc4\xdd{\t˪\xf7\x1c\xa0S{,=\xac\xf1\v\xb4\x97?\xe1G\xef#\xb8\xb3$\x84P\x10\x8am\xd4u\xb5'\x00\x88\xdcx5q\x88\x0f\n\x8b\x92jZ\xdbj\xf9\x04\xc3\xf8\xac\x82\x98\r\x85\x18\xe9\xb2n\x00\x83\x01\x05a\x94c\xf0\xde]\x05$\x85\xcbE\xac\x11\xeaT\r\xe5h\x8b\xbc2\x8e\x80\xb8D\xa9\x05\x9c\xbb\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00R)\xa9\xad\xbb菀\x14\xe7\xf4e\x986&\xf2^\xdaV\xdf\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00]\x7f2\xc39\x9c\xd8[%\xa0u\xce\xd0\xca\xeb\a\xe2\x01IT\xed\xbc\x8e\xfb\xb4\xa3\xc7\xca\x0e\xb5\f\x91\xbd\xb9\x98`\x8e\x9f\xb6B\xc9h\xa0#\\\xf0.\\\xcd6\x9f\xba_\"\xfba\"x\xf8\xeaI\xff\xc8m`\xf9\xbfi\x9aO{t\xbdC\xac\xf8\xad\x83\x01\x83\xa8\x85\tP/\x90\x00\x83\x01\nӔ\xcd\xc1&\xc8d\xb0\x1a\x0f\x89\x1f\xeex!\x94\b\xc1\xee\x94\xf8\x1e\x80\xb8D\xa9\x05\x9c\xbb\x00\x00\x00\x00\x00\x01\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00 \x10\x00\x00\x00\b\x00\x00\x00\x00\x00\x87\x1b\xada\xa5\xfa\xf4d\x83\xb7\x1ek\x83\xe5\x0e\x16\x83F\x16̄`\x8fio\x9eS\x16\xd9X\xba\xbc\x92=\xa7\xc0\x8fκӋ\x12Wy\x19)\"\xfa\x8b\xae~\xac\x8d\xbc\xfdŠ\xbf\x8f$\x19\xbc\xec&\x90\x8cg\x0ePl'!z\xe25,u\x97C?~\xdaNv\x18\xbb\xe8\xb1Ԉ\xcc\xe8\x16\xb0!\xf5m\xb6\xf9\x02\x12\xa0\xd3\x1dd\xf2\x7f\x97\x91\x8b\ti\xaa^2g\xc4`\xe5\r\\Y\x7f\x04\xfb\x89\xfd\x8d\x1c?\n,\x1bѠ\x1d\xccM\xe8\xde\xc7]z\xab\x85\xb5g\xb6\xcc\xd4\x1a\xd3\x12E\x1b\x94\x8at\x13\xf0\xa1B\xfd@ԓG\x94{u\x1e\x80m\xec\x9f&\xb9\"\xdd&>A\xa6b_m\x88Š>\x15Č\xa3k\v봗?\x91V\x97\xf4A\xcd\xcf\xc5\xe9eh\xda猜\xeb)f\xcc\x02\x9d\xa0\x85N\x9dR\n}\v>\xd9(\x03\x86\xd1v\xc7A\xd9G\x93r\xae\x91\xacz\xb5\x95\xe1+nW\xa5Πf\xfa8\x9d3\xd1\xddߌ0\x1d\x05\x00\xa72\x88[K\xca\xcc\x16\vH\x99l\asx^\xe2j\x94\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x02\x00\x00\x00\x00\x04\x00\x00\x10\x01P\x00\x00\bE\x00\x00\x00\x00 \x00\x00\x00\x00\x10\x00\x00\x00\x00\x10\x00@\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x04\x00\x00\x00\x88\x00\x00\x00\x00\x80@\x00\x00\x00\x04\x00\x00\x00\x10\x00\x00\x00\x01\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00@\x00\x00\x00@@@\x00\x02\x00\x80\x00\x00\x00\x00\x02\x18\x00\x00\x00\x10\x01\x00\x00\x00\x00D\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x10\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\b\x00\x00\x80\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x01\x00\x00\x00 \x00\x00  \x00\x00\x00\x00\b\x00\x00\x00\x04\x00\x00\x01\x00\x00\x00\x00)\xb45\x1f\xceYF<\"\x93\xc1ɮ\xc5n^\x8cdb\xf7\u05f9\x01\x00\x00@\x00\x00\x00\x00\x00\x04 \x00\x00\x00\x00\x00\x00 \x00@\x00\b\x00\x00\x00\x00\x00 \x00\b\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00@\x00\x00\x00\x00\x00\x00\x00\x10\x00\x02 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa1\x01\b\x10\x80\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00@\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00@\x04\x00\x00\x00\x10\x00A\x00\x02\x00@\x00\x00\x00\x10\x00\x00\x00\x00\x02@\x00\x00\b\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x01\x10\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x80\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00B\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\b\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\b\x04\x00\x00\x00\x00\x00\x00\x00\x00A\x00\x00\x10\x80 \x00\x87\x1a\x1e\xber\x03Hă\xb7&ރ\xe5\x7f\xf2\x83d\xe7W\x84`\x8f\xd7F\x87\xa6\x15\xe3\xa3\x04\xb1_\xa0\x1b\x1cE)J\x99\x06\x9a\xac\xb59\xce1D\x00\xbe\xe9\xe6\x9e\xeev\x8d\xc0[%\xc0\x01'\x11R\x8a*\x88֎\xe7r\xfd\\PK\xf9\aF\xf9\aC\xf9\x02D\x01\x82R\b\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x02\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00 \x00\x02\x00\x00\x00\x00\x00\x00@\x00\x00\x04\x00\x00\x00\x00\x04\x00\x00\x02\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00A \x80\x01\x00\x80\x00\x00\x00\x00\x00\x00 \x80\x00\x00\x00\x84\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00@\x00\x00\x00\x80\x00\x00\x00\x90\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x01\x00\x00\x00\x00\x00\x00 \x00\x00\x10\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x04\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x01\x00\x04\x00\x00\x00\x04\x00\x00\x00\x00@\x00\x00\b\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x87\x18\xfe\xa0\xb7`\xb0d\x83\xb7'M\x83\xe5s\xbe\x83\x01\x18Ԅ`\x8f\xdc\xe9\x87{6\x9c\xbc\x137\x92\xa0 \x92\xa7\xb13O\x18=AfW\xe4\xf9\xb8b\x1a8)\x17\xab1\x04\xb4\x9a\x8e{\xae\x8c\x8fj^q\x88m\x82S\xe7A\x04\xe2\x93\xf9\x02\x0f\xa0\fo\xd9;\x174\xb3\xa2\xfe\xca̙A\xa2wtrG\x03\xf48\x06P\xdb\x1c\x1c\xbd뀻\xd5Ġ\x1d\xccM\xe8\xde\xc7]z\xab\x85\xb5g\xb6\xcc\xd4\x1a\xd3\x12E\x1b\x94\x8at\x13\xf0\xa1B\xfd@ԓG\x94\xc8@\x93|\xea&;N\x91\x9b\xad$\f\xac\xd7\xc6\xe2f)L\xa0\xb0\x16\xe5\xb3\xfb\x17\xb3\xf3\x91\x9e\xe8\xa5\xed\xe8B;S\x12`\xb9h\xf5\xf4\xcdu\xa4qK\xe1\xb6\\\xf0\xa0*.=\x9c\xeb\xff\xda.\xd8\u0080\xbfSk\xbd\x9a\xe5\f\xab\x93Ү\x8a\x84-P\xd5\x14\xdatwՠP\x15\xc9\x01\xc0L\xe9\xa3\x1f%\x8bvF\xa4\xd24\xb6\x96QL\xf8e+\x97\xc8\x1a\x19|\xb5\x94\xdf0\xb9\x01\x00\x00\x00\x00$\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x02\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x81\x00\x00\x00\x00\b\x00\x80\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x04\x00\x00\x80\x00\x00\x00\x00@\x00\x02\x00\x00\x00\x00\x00\x00\x00\x10\t\x00\x00\x00\x00\x00\x00\x00\x00\xc0\xf9\x01\xa7\x01\x83\x01\x9a(\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x12\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf8\x9d\xf8\x9b\x94\xf7l\xa2\xee&\xf5\xd6\x1dY\xe00H\x8f_G\x88cH\xccT\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x8f\xf8\xb9\xdaC\xaala\xbc\xaf\xde\xd73Mסc\xaf\xcav\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\n{a\x04&\xad\n\a\x87\x17ۥbp\x81\b&\x1d\xef4\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00-\x04\x89RZ\x03\x0eF\xb9\x02I\x01\xf9\x02E\x01\x83\x01\xec0\xb9\x01\x00\x00\x00\x00\x00\x10 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x80\x00\x00\x00@\x00 \x00\x00\x00\x10\x00\x00\x00\x00 \x00\x00(\x00\x00\x00\x04\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x02\x00\x00\x00\x00\x00\x00\x00\x00\b\b\x00\x00\x00\x00\x00\b\x00\x10\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00@\x00\x87\x19\x8fod\xdc|2\x83\xb7*'\x83\xe6@\x87\x83\xcc\x16\x88\x84`\x90\x01\xfb\x8f\x02\xda\x02(du\xde)+AB\x10\x19!\xb5\xa0\xb7c\xe1b\xd3\x1bC\x97\xabF\x7ft\x12\x1c\xa2O\x16\xf0\xce(F\x929@\"\x16\xfa\x1cc܆ǈr7\x974\x17\xf1\x82\x8f\xf9\x02\x1e\xa0\x00\x93N\xcc,\x9cME\xc85\x99,\x80C&c\xed\xe9\xd9\xe4'\xd1)\xd3\xe2\x03\x81\xbds?d\u00a0\x1d\xccM\xe8\xde\xc7]z\xab\x85\xb5g\xb6\xcc\xd4\x1a\xd3\x12E\x1b\x94\x8at\x13\xf0\xa1B\xfd@ԓG\x94r\x84\x04\x95O\xb73\x82\x0f\xc5\xcf\xef\x05x\x1e\xfaf4\xbc\x16\xa0\x13J\x95%R=@B\xd8/,\xf9\x84\xfc\xa6V F\xd6\xcd!:\xcd\xf7\xa9\xcf\xc3\xfc\xd1\x13\xa7̠\xe6]\f,\xad\x9f\xf8P\xe2\f\xa1\xa0\x99\xfa-Œ|\xa3\xe5\xb3\xe1j\xa0\xba\x15\xe7\xfa\xa3\x81\xac\xec\xa0:z\xd0ߠ\xf9\xb1j\x84\xd5L\nx(r\aC\xce<K\xac\xbe\xc0K<(\x87Z\xf8\x0eQV\xb9\x01\x00\x00\x00\x00\x00\x01\x00\x00\x00\b\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00 \x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00D\x00\x00\x00@\x10\x00\x00\x00\x00\x00@\x00\x00\x80\x00\x00\x00\b\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x03\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x04\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x01\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x10\x00\x00\x10\x00\b \x04\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00  \x00\x80\x00\x00\x00\x00\x00\x00\x00\b\x00\x80\x00\x00\x10\x00\x00\x00\x00\x02\x00\x00L\xdc:\a\xa9\x9d#\xc4(B\x99Gg\xd3\x11+\xb9\x01\x00\x00\x00\x10\x00\x02\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x01\x00\x00 \x00\x00\x00@\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x80\x00\x00\x01\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x01\x00\x00\x00\x10\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00H\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\b\x00\x00\x00\x00\x00\x00\x00@\x00\x00\"\x00\x00\x00\x00\x00\x01\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00@\x00\x00\x10\x02\x02\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00@\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x05\x00\x00\x00\x02\x00\x00\x01\x04\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x02\x00\x00 \x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x01\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x87\x1a\x92\xb0\x1d-?\x88\x83\xb7&\u0083\xe5!\xa7\x83\f\xcd^\x84`\x8f\xd5ڗ\xe0-r?߹\xbd\x17\x16R\x9b\xbd㵺;a\x13A1X\xcch\xa0&G\x94l\x95+\xaa\f\xf3R\xc7R\x8b\xd0Ӣ\xcas\a\xda\xc6F\xa9-\xae%\x04\xad\x14\xedU2\x88Y9\xe4\x9f0\xd6\xc6X\xf9 \x9a\xf9\x1e\x85\xf8k\x82W\x14\x85\x04㲒\x00\x82R\b\x94\a\xccz\x1aȯ\x11\xfe\xdc\x17\x05\x8dj\x9e\xfb\x8fwI\xcfͅ\x1aQFA\xa7\x80%\xa0\xdat`\xf5\xd8C2Q\xff \x87\x99\xd1i\x19g\xe7\xe4>\xde^HM\x8a;\x00\xc3:\x1a\xa9\xc8Šn\xc7\xcc\xed*\x1f\xe0\x15\x01Ӫ0\xbb\x9e\x8cnt\xfb\xedx\xa5\xc0r\xea\x9cc|'H \xc5?\xf8g\x83\x01p\u0605\x0f\x99\x82\xde\x00\x82R\b\x94\xa0\xcb\xf2\x13\x103N\x7f\xd4\xe6\x1a\xc3ky\x84\xb8ݖ\x8b\xf1\x1a\x80&\xa0\x82H\xa0h\xf3`i\x80'\x82r\x1f7\"B\xed\x00\x00\x00\x00\x04\x01\x00\x00\x00\x00\x00\x00@\x10\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x02\x00\x02\x00\x00\x00\x01\x00\x00\x00\b\x00\x00 \x00\x00\x00\x00\x00\x00\b \x00\x00\x00\x00\x00\x00\x00\x10\x80\x00\x00\x00\x00\x00\x02\x00\x00\x87\x194T\xacO\x19\x00\x83\xb7%\x1e\x83\xe5}탊-݄`\x8f\xc0\x86\x88\xdf\x15\x8a]Ks\\\f\xa0\x93Y\xb7\xe9B\x82A\xe6\xaf\xfe\xe7 \r\x03\x1cT\xccU\x95\x04\xb2uB-\x828\xe6\xdd\xfa\xb9\x11U\x88\xe5Q\x8a\xa0\xc7j\aF\xf9\x02\x1d\xa0\x9b\xeb):\xbe\xad\xa8\x96\xb1>@L\x8a\xe7\xadS\xf7\xc5\xd8\xd6\x00\xe4`7|Ѣ\xee\xe4\x130g\xa0\x1d\xccM\xe8\xde\xc7]z\xab\x85\xb5g\xb6\xcc\xd4\x1a\xd3\x12E\x1b\x94\x8at\x13\xf0\xa1B\xfd@ԓG\x94mf>P6Ŏ\xd8v\xef#n\xce:\xa3\x9f\"?\xd6H\xa0\xb8\fӠ\x9fn\x10\xaa:\x06\x0f\nd\x10\xae\xaf\xe2\x92ZT\xc6~\x8e\a\xee\xfa\v\xcd8\xecPǠm\xcbCE%\x7f9Kȥ\xe1k7\x99_\xaa\xa7\x13\t\x9cH\xb1\xb1\x8c\"\xbe\xfe,\x95\xac\\\x03\xa0\xfc\xd0\xfb\xd2gz\xb8\x8d\xd9q\xadI\xac2`\xcb\b\xf9\xe6\x9e\x03\x19t\xe7B\xc1\xd2\xfa4\x9b\xb4\xef\xb9\x01\x00\x00\x00\x00\x00\x00\b\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x80\x00\x00\x00\x00\x00\x00\b\x00\x00\x00@\x00\x01\x00\x00\x00\x00\x00\x00\x00\x04@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x02\x80\x00@\x00\x00\x00\x10\x00\x00\x00\x80\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x04\x00\x02\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x02\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x04\x00\x00\x02\x00\x00\x00\x00\x00@\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x02\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x10\x00\x92\v\xa0\x1d\xccM\xe8\xde\xc7]z\xab\x85\xb5g\xb6\xcc\xd4\x1a\xd3\x12E\x1b\x94\x8at\x13\xf0\xa1B\xfd@ԓG\x94\xe2\xbaj\xa4\x03\xb1\xd9\x1fyx\x86\xbe\xd0\x0ep\x98Q\xb9\xe2q\xa0#\xbdߜ\x90\x96\x91\xeb\x84`\xdc6\xbd҂6\xf7x\x06\xfcp\x8d\x0eԎG\x9d.\xa1\xb8\x1a̠\x9c\xdd-\xe25#Șv11\\1\n\xa7\xc0\xdf?_~\x11ꮳ\xef\xb7\v.&j\xaf\x9c\xa0_\x84\x92\x81\xc0\x9e\xc4\xf22\xd4N\x80ǈ\x1f\x13lB\x97od\xd2\xe2\x06\x00\xb9\x86c\xb8\x99ze\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x02\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\b\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x10\x00\x00\x00\x00\x01\x00\x00\x80\x00\x00\x00\x00\x00\x10\x02\x00\x10\x00\x00\x00\x00\x00\x02\x00\x00\x00\b\x00\x00\b\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x04\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x02\x00\b\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\f\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x04\x00\x00\x00\x00\x00\x10\x02\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\b\x04\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00 \x00\x04\x00\x00\x00@\x00 \x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x87\x1b5\x1f\xf8\xef\x13\\\x83\xb7\x1b\u0603\xe5\xf9\xf9\x83n\x15J\x84`\x8fG\xf8\x9f\xf0\xef\xdd\xc2<J\x9e\x95lǢ\x1c\x91l\x17\tʦ\xc7.z\xa7+@:\x82\xbc[\xfa\xfd\xe4\xa0E\xa1\xb4j\xfd\xa0\xc8Ѷj\x83\x05`\x99\xac\x14\xb0\xe4A\x9c\xd8u\xb1Ƥ֓\xafUG\xea\x10\x88d\x1d\xec\xf3\x93k\xf2\xc8\xf9\xcdJ\xf9@\xa3\xb9\x01\f\x01\xf9\x01\b\x01\x82R\b\xb9\x01\x00\x00\x00\x00\x00Թ\x01\x00\x01\x00\x00\x00\x00@ \x00\x00\x00\x00\x00\x00\b\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00@\x00\x00\b\x00 \x00\x00\x01\x00\x00\x00\x02\x00\x00\x00 \x80\x00\x00\x00\x00 \x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x10\x00\x00\x00\x00\x00\b\x00\x00 \x00\x00\x00\x00\x02\x00\x00\x00\b\x00\x00\x00P\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x02@\x00\x00\x00\x00\x00\x00\x10\b\x00\x00\x00\x00\x00\x00\x00\x10@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00$@\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x10\x00\x00\x00\x00 \x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x80\x80\x00\x00\x00\x00\x01\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00$\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x87\x18\xec\x84\f\x16ډ\x83\xb7)}\x83\xe5\x89\x00\x83]\xa60\x84`\x8f\xf9Y\x8b\xf9[\xb0\xd5Ƨ\xb7w\xc0\xa6n\xa0\x8b\xf7\xe1\x18\xfd\x8c\x13\x9b\xd2\xcf\xec\xd1V\x04+\"\x99\xa1N\xe2,\xe4\xffP\x89j8\x0erE\x98\xec\x88 \xb2\xf2\xc6+D\x81\xd5\xf9\x02\v\xa0m\x9fD\xe0m\xe5y\x19\xdb&`\x9e\xd1\x1c\xae8\xa0~\xe8)\x80\xff\xe6\x97f=\x9bR\xc3qM:\xa0\x1d\xccM\xe8\xde\xc7]z\xab\x85\xb5g\xb6\xcc\xd4\x1a\xd3\x12E\x1b\x94\x8at\x13\xf0\xa1B\xfd@ԓG\x946+\xf6\x83\xf1\xb9Ԑ~[Wt\x00\xa9\x03\x96\xc4=\xc7\r\xa0\x1b\x9eu1\xc7\x03\xf7\xd10\xa3\x8f\xaf\xb3B\xb7\xd6\\>{\x18\xe9iv\x86Dy\a$\x93=wn\xa0_\x9cWS|\x00\xc6\x1d\x19\xf7\xa9\xf8V\xc0mz5O)\xab\r\xdeN7\x85#\x0eh\xa1/\xe5\x1e\xa0\\\x15\xed\x97\xdc\xfa\xecI\xf9\xda\xf3Pz,B\xa1σY\x12p\x9a[\xb9n\xa5\x8e\x80\x02\x9f.M\xb9\x01\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00n\xf4b\xe1|\n\xcc$\xb0\x01\xd3=\xe0\x05\xde5$[\xab\"\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb5\xbd7\xf4\x06\xe2\x0e\x98>\xe7\xcd\xf6\b\xafB\xda8\x1aU\xc0\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00W\xc6\x1aR\x04y\xa7\xab\xf9\x01\t\x01\x83\x02\x90@\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xc0\xf9\x02E\x01\x83\x02\xe2H\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00P\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x02\x00\b\x02\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x000\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\b\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xc0\xf9\x01\t\x01\x83\x034P\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xc0\xb9\x01\xab\x01\xf9\x01\xa7\x01\x83\x03\x86X\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xc0\xb9\x01\r\x01\xf9\x01\t\x01\x83\x04|p\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xc0\xb9\x02I\x01\xf9\x02E\x01\x83\x04\xcex\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\b\x00\x00\x00@\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x02\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x18\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00@\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x04\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf9\x01:\xf8\x9b\x94\xccE\x1d\x03\x04钓\xbeA\x81\x8aWA}\f\xf06\x13\xaf\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xc6\x1c3\xdbD3\xaaf\x11\xcf?\xb1+`5e\xf0qΤ\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00<\xc5GsX\xb6\xc5ey\x1e\xce/ti\x1b \x01*\x8a\xba\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x13z\xf58\x82^\xb4h\xf8\x9b\x94\xbb\xc8\xc8\x17\x1a>+\x83se\x1a\xde9\x8d\x06\xba\xbb\xf8%\xd8\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf7Z\x86.d\xfb\xa9\xab\xf2\x7f\n\x7fV\x90W\xcd`\x9a\t\xfe\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00!\x9d\xe0\xe1y{\x069\xbe\xff\xad\xe9\xad\x0ezG\xbby\xe6|\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x1c\x1b-cɔUr\xf9\x02\xe6\xf8\xac\x82w3\x85+ŬX\x00\x83\x03\x83ה\x17ށ,1\x13\xa8Vd\xf8A\xa9\xcd\xd8\xd8\xebY\xfa\x10\x96\x80\xb8D\xa9\x05\x9c\xbb\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00u\xe1\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x008\xd0Kf\xe5<\x04\xc6\xf9\x01\xa7\x01\x83\x02>8\xb9\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00 \x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\xf8\x9d\xf8\x9b\x941\xd8\xc7\x1e\xf0\x84m\xd3#\xa7\x97\xbd\xf2q\xfe\xeds5\x8cQ\xf8c\xa0\xdd\xf2R\xad\x1b\xe2ți°h\xfc7\x8d\xaa\x95+\xa7\xf1cġ\x16(\xf5ZM\xf5#\xb3\xef\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb8\xb1\xcf\xdbT\xac\xd5m\x94\x11,\xde\xdeg\b\x95k\x8e\x83\x97\xa0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb0\x99"
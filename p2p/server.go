@@ -39,6 +39,8 @@ import (
 	"github.com/c88032111/go-gdtu/p2p/enr"
 	"github.com/c88032111/go-gdtu/p2p/nat"
 	"github.com/c88032111/go-gdtu/p2p/netutil"
+	"github.com/c88032111/go-gdtu/p2p/rlpx"
+	"github.com/c88032111/go-gdtu/rlp"
 )
 
 const (
@@ -62,6 +64,13 @@ const (
 
 	// Maximum amount of time allowed for writing a complete message.
 	frameWriteTimeout = 20 * time.Second
+
+	// Maximum number of session tickets kept for resuming sessions, both for
+	// the ones issued to and the ones received from remote peers.
+	sessionTicketLimit = 1024
+
+	// Time after which a session cannot be resumed anymore.
+	sessionTicketLifetime = 30 * time.Minute
 )
 
 var errServerStopped = errors.New("server stopped")
@@ -119,10 +128,35 @@ type Config struct {
 	// IP networks contained in the list are considered.
 	NetRestrict *netutil.Netlist `toml:",omitempty"`
 
+	// The IP limits bound the number of discovery table entries (table-wide and
+	// per bucket) and of dynamically dialed peers in the same /24 network (/64
+	// for IPv6), making it harder to eclipse the node. LAN addresses are exempt.
+	// For the table limits zero selects the default, negative values disable
+	// them, which may be needed in private networks sharing a public subnet.
+	// Dials are not limited unless DialIPLimit is positive.
+	TableIPLimit  int `toml:",omitempty"`
+	BucketIPLimit int `toml:",omitempty"`
+	DialIPLimit   int `toml:",omitempty"`
+
+	// DiscoveryFreshness is the time a packet received from a discovery table
+	// node proves its liveness, sparing the revalidation ping. Zero selects the
+	// default, negative values always ping.
+	DiscoveryFreshness time.Duration `toml:",omitempty"`
+
 	// NodeDatabase is the path to the database containing the previously seen
 	// live nodes in the network.
 	NodeDatabase string `toml:",omitempty"`
 
+	// The node database pruning policy: nodes not seen for longer than the max
+	// age (default one day) are dropped, as well as the endpoints failing the max
+	// number of findnode requests in a row. Zero max fails disables the latter.
+	NodeDatabaseMaxAge   time.Duration `toml:",omitempty"`
+	NodeDatabaseMaxFails int           `toml:",omitempty"`
+
+	// PeerEventLog is the path to the file recording the peer connection and
+	// disconnection events. If empty, the events are only kept in memory.
+	PeerEventLog string `toml:",omitempty"`
+
 	// Protocols should contain the protocols supported
 	// by the server. Matching protocols are launched for
 	// each peer.
@@ -148,6 +182,19 @@ type Config struct {
 	// If NoDial is true, the server will not dial any peers.
 	NoDial bool `toml:",omitempty"`
 
+	// PreferIPv6 makes the dialer try the IPv6 endpoint of nodes reachable over
+	// both IPv4 and IPv6 first, until the connect times show which is faster.
+	PreferIPv6 bool `toml:",omitempty"`
+
+	// SessionResumption enables resuming the encrypted sessions of recently
+	// connected peers supporting it, without a full RLPx handshake.
+	SessionResumption bool `toml:",omitempty"`
+
+	// Compression lists the message compression codecs offered to peers in the
+	// devp2p handshake, by preference. Connections to peers supporting none of
+	// them fall back to snappy.
+	Compression []string `toml:",omitempty"`
+
 	// If EnableMsgEvents is set then the server will emit PeerEvents
 	// whenever a message is sent to or received from a peer
 	EnableMsgEvents bool
@@ -173,9 +220,11 @@ type Server struct {
 	running bool
 
 	listener     net.Listener
+	slots        chan struct{} // Inbound handshake slots, shared by all listeners
 	ourHandshake *protoHandshake
 	loopWG       sync.WaitGroup // loop, listenLoop
 	peerFeed     event.Feed
+	peerLog      *peerLog
 	log          log.Logger
 
 	nodedb    *enode.DB
@@ -221,11 +270,12 @@ const (
 type conn struct {
 	fd net.Conn
 	transport
-	node  *enode.Node
-	flags connFlag
-	cont  chan error // The run loop uses cont to signal errors to SetupConn.
-	caps  []Cap      // valid after the protocol handshake
-	name  string     // valid after the protocol handshake
+	node   *enode.Node
+	flags  connFlag
+	cont   chan error // The run loop uses cont to signal errors to SetupConn.
+	caps   []Cap      // valid after the protocol handshake
+	name   string     // valid after the protocol handshake
+	protos []Protocol // protocols advertised in the handshake, valid after it
 }
 
 type transport interface {
@@ -403,6 +453,31 @@ func (srv *Server) Stop() {
 	close(srv.quit)
 	srv.lock.Unlock()
 	srv.loopWG.Wait()
+	srv.peerLog.close()
+}
+
+// PeerLog returns the peer connection and disconnection events recorded since
+// the given time, oldest first.
+func (srv *Server) PeerLog(since time.Time) []*PeerLogEvent {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	if srv.peerLog == nil {
+		return nil
+	}
+	return srv.peerLog.since(since)
+}
+
+// PeerChurn returns aggregate statistics of the peer connection and
+// disconnection events recorded since the given time.
+func (srv *Server) PeerChurn(since time.Time) *PeerChurnStats {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	if srv.peerLog == nil {
+		return nil
+	}
+	return srv.peerLog.churn(since, time.Now())
 }
 
 // sharedUDPConn implements a shared connection. Write sends messages to the underlying connection while read returns
@@ -457,10 +532,24 @@ func (srv *Server) Start() (err error) {
 	}
 	if srv.newTransport == nil {
 		srv.newTransport = newRLPX
+		if srv.SessionResumption {
+			sessions := rlpx.NewSessionCache(sessionTicketLimit, sessionTicketLifetime)
+			srv.newTransport = func(fd net.Conn, dialDest *ecdsa.PublicKey) transport {
+				t := newRLPX(fd, dialDest).(*rlpxTransport)
+				t.conn.SetSessionCache(sessions)
+				return t
+			}
+		}
 	}
 	if srv.listenFunc == nil {
 		srv.listenFunc = net.Listen
 	}
+	if pl, err := newPeerLog(srv.PeerEventLog, peerLogLimit); err != nil {
+		srv.log.Warn("Failed to open peer event log", "path", srv.PeerEventLog, "err", err)
+		srv.peerLog, _ = newPeerLog("", peerLogLimit)
+	} else {
+		srv.peerLog = pl
+	}
 	srv.quit = make(chan struct{})
 	srv.delpeer = make(chan peerDrop)
 	srv.checkpointPostHandshake = make(chan *conn)
@@ -470,6 +559,16 @@ func (srv *Server) Start() (err error) {
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
 
+	// The slots channel limits the inbound connections pending handshake.
+	tokens := defaultMaxPendingPeers
+	if srv.MaxPendingPeers > 0 {
+		tokens = srv.MaxPendingPeers
+	}
+	srv.slots = make(chan struct{}, tokens)
+	for i := 0; i < tokens; i++ {
+		srv.slots <- struct{}{}
+	}
+
 	if err := srv.setupLocalNode(); err != nil {
 		return err
 	}
@@ -497,11 +596,24 @@ func (srv *Server) setupLocalNode() error {
 	}
 	sort.Sort(capsByNameAndVersion(srv.ourHandshake.Caps))
 
+	if len(srv.Compression) > 0 {
+		for _, name := range srv.Compression {
+			if rlpx.LookupCodec(name) == nil {
+				return fmt.Errorf("unknown compression codec %q", name)
+			}
+		}
+		enc, err := rlp.EncodeToBytes(srv.Compression)
+		if err != nil {
+			return err
+		}
+		srv.ourHandshake.Rest = []rlp.RawValue{enc}
+	}
 	// Create the local node.
 	db, err := enode.OpenDB(srv.Config.NodeDatabase)
 	if err != nil {
 		return err
 	}
+	db.SetPruning(srv.NodeDatabaseMaxAge, srv.NodeDatabaseMaxFails)
 	srv.nodedb = db
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
@@ -579,11 +691,14 @@ func (srv *Server) setupDiscovery() error {
 			sconn = &sharedUDPConn{conn, unhandled}
 		}
 		cfg := discover.Config{
-			PrivateKey:  srv.PrivateKey,
-			NetRestrict: srv.NetRestrict,
-			Bootnodes:   srv.BootstrapNodes,
-			Unhandled:   unhandled,
-			Log:         srv.log,
+			PrivateKey:        srv.PrivateKey,
+			NetRestrict:       srv.NetRestrict,
+			Bootnodes:         srv.BootstrapNodes,
+			Unhandled:         unhandled,
+			Log:               srv.log,
+			TableIPLimit:      srv.TableIPLimit,
+			BucketIPLimit:     srv.BucketIPLimit,
+			LivenessFreshness: srv.DiscoveryFreshness,
 		}
 		ntab, err := discover.ListenV4(conn, srv.localnode, cfg)
 		if err != nil {
@@ -596,10 +711,13 @@ func (srv *Server) setupDiscovery() error {
 	// Discovery V5
 	if srv.DiscoveryV5 {
 		cfg := discover.Config{
-			PrivateKey:  srv.PrivateKey,
-			NetRestrict: srv.NetRestrict,
-			Bootnodes:   srv.BootstrapNodesV5,
-			Log:         srv.log,
+			PrivateKey:        srv.PrivateKey,
+			NetRestrict:       srv.NetRestrict,
+			Bootnodes:         srv.BootstrapNodesV5,
+			Log:               srv.log,
+			TableIPLimit:      srv.TableIPLimit,
+			BucketIPLimit:     srv.BucketIPLimit,
+			LivenessFreshness: srv.DiscoveryFreshness,
 		}
 		var err error
 		if sconn != nil {
@@ -621,6 +739,7 @@ func (srv *Server) setupDialScheduler() {
 		maxActiveDials: srv.MaxPendingPeers,
 		log:            srv.Logger,
 		netRestrict:    srv.NetRestrict,
+		ipLimit:        srv.DialIPLimit,
 		dialer:         srv.Dialer,
 		clock:          srv.clock,
 	}
@@ -628,7 +747,7 @@ func (srv *Server) setupDialScheduler() {
 		config.resolver = srv.ntab
 	}
 	if config.dialer == nil {
-		config.dialer = tcpDialer{&net.Dialer{Timeout: defaultDialTimeout}}
+		config.dialer = newTCPDialer(defaultDialTimeout, srv.NetRestrict, srv.PreferIPv6)
 	}
 	srv.dialsched = newDialScheduler(config, srv.discmix, srv.SetupConn)
 	for _, n := range srv.StaticNodes {
@@ -817,7 +936,7 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 
 func (srv *Server) addPeerChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
 	// Drop connections with no matching protocols.
-	if len(srv.Protocols) > 0 && countMatchingProtocols(srv.Protocols, c.caps) == 0 {
+	if len(srv.Protocols) > 0 && countMatchingProtocols(srv.connProtocols(c), c.caps) == 0 {
 		return DiscUselessPeer
 	}
 	// Repeat the post-handshake checks because the
@@ -831,14 +950,7 @@ func (srv *Server) listenLoop() {
 	srv.log.Debug("TCP listener up", "addr", srv.listener.Addr())
 
 	// The slots channel limits accepts of new connections.
-	tokens := defaultMaxPendingPeers
-	if srv.MaxPendingPeers > 0 {
-		tokens = srv.MaxPendingPeers
-	}
-	slots := make(chan struct{}, tokens)
-	for i := 0; i < tokens; i++ {
-		slots <- struct{}{}
-	}
+	slots := srv.slots
 
 	// Wait for slots to be returned on exit. This ensures all connection goroutines
 	// are down before listenLoop returns.
@@ -972,7 +1084,8 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 	}
 
 	// Run the capability negotiation handshake.
-	phs, err := c.doProtoHandshake(srv.ourHandshake)
+	our, protos := srv.handshakeProtocols()
+	phs, err := c.doProtoHandshake(our)
 	if err != nil {
 		clog.Trace("Failed p2p handshake", "err", err)
 		return err
@@ -981,7 +1094,7 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 		clog.Trace("Wrgdtu devp2p handshake identity", "phsid", hex.EncodeToString(phs.ID))
 		return DiscUnexpectedIdentity
 	}
-	c.caps, c.name = phs.Caps, phs.Name
+	c.caps, c.name, c.protos = phs.Caps, phs.Name, protos
 	err = srv.checkpoint(c, srv.checkpointAddPeer)
 	if err != nil {
 		clog.Trace("Rejected peer", "err", err)
@@ -991,6 +1104,28 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 	return nil
 }
 
+// handshakeProtocols returns the protocol handshake to send on a new connection
+// along with the protocols it advertises, leaving out the protocols which are
+// currently unavailable.
+func (srv *Server) handshakeProtocols() (*protoHandshake, []Protocol) {
+	protos := make([]Protocol, 0, len(srv.Protocols))
+	for _, p := range srv.Protocols {
+		if p.Available == nil || p.Available() {
+			protos = append(protos, p)
+		}
+	}
+	if len(protos) == len(srv.Protocols) {
+		return srv.ourHandshake, srv.Protocols
+	}
+	hs := *srv.ourHandshake
+	hs.Caps = make([]Cap, 0, len(protos))
+	for _, p := range protos {
+		hs.Caps = append(hs.Caps, p.cap())
+	}
+	sort.Sort(capsByNameAndVersion(hs.Caps))
+	return &hs, protos
+}
+
 func nodeFromConn(pubkey *ecdsa.PublicKey, conn net.Conn) *enode.Node {
 	var ip net.IP
 	var port int
@@ -1013,7 +1148,7 @@ func (srv *Server) checkpoint(c *conn, stage chan<- *conn) error {
 }
 
 func (srv *Server) launchPeer(c *conn) *Peer {
-	p := newPeer(srv.log, c, srv.Protocols)
+	p := newPeer(srv.log, c, srv.connProtocols(c))
 	if srv.EnableMsgEvents {
 		// If message events are enabled, pass the peerFeed
 		// to the peer.
@@ -1023,6 +1158,14 @@ func (srv *Server) launchPeer(c *conn) *Peer {
 	return p
 }
 
+// connProtocols returns the protocols to run on a connection.
+func (srv *Server) connProtocols(c *conn) []Protocol {
+	if c.protos != nil {
+		return c.protos
+	}
+	return srv.Protocols
+}
+
 // runPeer runs in its own goroutine for each peer.
 func (srv *Server) runPeer(p *Peer) {
 	if srv.newPeerHook != nil {
@@ -1034,6 +1177,14 @@ func (srv *Server) runPeer(p *Peer) {
 		RemoteAddress: p.RemoteAddr().String(),
 		LocalAddress:  p.LocalAddr().String(),
 	})
+	srv.peerLog.add(&PeerLogEvent{
+		Time:          time.Now(),
+		Type:          PeerEventTypeAdd,
+		Peer:          p.ID(),
+		Name:          p.Name(),
+		Inbound:       p.Inbound(),
+		RemoteAddress: p.RemoteAddr().String(),
+	})
 
 	// Run the per-peer main loop.
 	remoteRequested, err := p.run()
@@ -1043,6 +1194,17 @@ func (srv *Server) runPeer(p *Peer) {
 	// before returning, so this send should not select on srv.quit.
 	srv.delpeer <- peerDrop{p, err, remoteRequested}
 
+	srv.peerLog.add(&PeerLogEvent{
+		Time:          time.Now(),
+		Type:          PeerEventTypeDrop,
+		Peer:          p.ID(),
+		Name:          p.Name(),
+		Inbound:       p.Inbound(),
+		RemoteAddress: p.RemoteAddr().String(),
+		Error:         err.Error(),
+		Duration:      time.Duration(mclock.Now() - p.created),
+	})
+
 	// Broadcast peer drop to external subscribers. This needs to be
 	// after the send to delpeer so subscribers have a consistent view of
 	// the peer set (i.e. Server.Peers() doesn't include the peer when the
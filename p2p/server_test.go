@@ -24,6 +24,7 @@ import (
 	"math/rand"
 	"net"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -453,6 +454,68 @@ func TestServerSetupConn(t *testing.T) {
 	}
 }
 
+// This test checks that unavailable protocols are left out of the handshake and
+// that peers supporting only those are rejected.
+func TestServerUnavailableProtocol(t *testing.T) {
+	var (
+		available = new(int32)
+		srvkey    = newkey()
+		clientkey = newkey()
+	)
+	optional := Protocol{
+		Name:      "optional",
+		Version:   1,
+		Length:    1,
+		Run:       discard.Run,
+		Available: func() bool { return atomic.LoadInt32(available) == 1 },
+	}
+	cfg := Config{
+		PrivateKey:  srvkey,
+		MaxPeers:    10,
+		NoDial:      true,
+		NoDiscovery: true,
+		Protocols:   []Protocol{discard, optional},
+		Logger:      testlog.Logger(t, log.LvlTrace),
+	}
+	tt := &setupTransport{
+		pubkey: &clientkey.PublicKey,
+		phs: protoHandshake{
+			ID:   crypto.FromECDSAPub(&clientkey.PublicKey)[1:],
+			Caps: []Cap{{"optional", 1}},
+		},
+	}
+	srv := &Server{
+		Config:       cfg,
+		newTransport: func(fd net.Conn, dialDest *ecdsa.PublicKey) transport { return tt },
+		log:          cfg.Logger,
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("couldn't start server: %v", err)
+	}
+	defer srv.Stop()
+
+	// Check the advertised capabilities while unavailable and available
+	hs, protos := srv.handshakeProtocols()
+	if want := []Cap{{"discard", 0}}; !reflect.DeepEqual(hs.Caps, want) || len(protos) != 1 {
+		t.Fatalf("unavailable handshake mismatch: have %v, want %v", hs.Caps, want)
+	}
+	if len(srv.ourHandshake.Caps) != 2 {
+		t.Fatalf("base handshake modified: %v", srv.ourHandshake.Caps)
+	}
+	atomic.StoreInt32(available, 1)
+	hs, protos = srv.handshakeProtocols()
+	if want := []Cap{{"discard", 0}, {"optional", 1}}; !reflect.DeepEqual(hs.Caps, want) || len(protos) != 2 {
+		t.Fatalf("available handshake mismatch: have %v, want %v", hs.Caps, want)
+	}
+	// A peer only speaking the unavailable protocol is useless
+	atomic.StoreInt32(available, 0)
+	p1, _ := net.Pipe()
+	srv.SetupConn(p1, inboundConn, nil)
+	if tt.closeErr != DiscUselessPeer {
+		t.Fatalf("close error mismatch: have %v, want %v", tt.closeErr, DiscUselessPeer)
+	}
+}
+
 type setupTransport struct {
 	pubkey            *ecdsa.PublicKey
 	encHandshakeErr   error
@@ -144,6 +144,7 @@ type ExecNode struct {
 	client  *rpc.Client
 	wsAddr  string
 	newCmd  func() *exec.Cmd
+	logs    logBuffer
 }
 
 // Addr returns the node's enode URL
@@ -204,7 +205,7 @@ func (n *ExecNode) Start(snapshots map[string][]byte) (err error) {
 	// start the node
 	cmd := n.newCmd()
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = io.MultiWriter(os.Stderr, &n.logs)
 	cmd.Env = append(os.Environ(),
 		envStatusURL+"="+statusURL,
 		envNodeConfig+"="+string(confData),
@@ -268,6 +269,12 @@ func (n *ExecNode) waitForStartupJSON(ctx context.Context) (string, chan nodeSta
 	return url, ch
 }
 
+// Logs implements LogCapturer, returning the most recent log output of the
+// child process, unless it logs into a file.
+func (n *ExecNode) Logs() []byte {
+	return n.logs.Bytes()
+}
+
 // execCommand returns a command which runs the node locally by exec'ing
 // the current binary but setting argv[0] to "p2p-node" so that the child
 // runs execP2PNode
@@ -395,13 +402,9 @@ func initLogging() {
 		}
 		writer = logWriter
 	}
-	var verbosity = log.LvlInfo
-	if conf.Node.LogVerbosity <= log.LvlTrace && conf.Node.LogVerbosity >= log.LvlCrit {
-		verbosity = conf.Node.LogVerbosity
-	}
 	// Reinitialize the logger
 	glogger = log.NewGlogHandler(log.StreamHandler(writer, log.TerminalFormat(true)))
-	glogger.Verbosity(verbosity)
+	glogger.Verbosity(logVerbosity(conf.Node))
 	log.Root().SetHandler(glogger)
 }
 
@@ -91,28 +91,15 @@ func (s *SimAdapter) NewNode(config *NodeConfig) (Node, error) {
 		return nil, err
 	}
 
-	n, err := node.New(&node.Config{
-		P2P: p2p.Config{
-			PrivateKey:      config.PrivateKey,
-			MaxPeers:        math.MaxInt32,
-			NoDiscovery:     true,
-			Dialer:          s,
-			EnableMsgEvents: config.EnableMsgEvents,
-		},
-		ExternalSigner: config.ExternalSigner,
-		Logger:         log.New("node.id", id.String()),
-	})
-	if err != nil {
-		return nil, err
-	}
-
 	simNode := &SimNode{
 		ID:      id,
 		config:  config,
-		node:    n,
 		adapter: s,
 		running: make(map[string]node.Lifecycle),
 	}
+	if simNode.node, err = simNode.newStack(); err != nil {
+		return nil, err
+	}
 	s.nodes[id] = simNode
 	return simNode, nil
 }
@@ -147,7 +134,7 @@ func (s *SimAdapter) DialRPC(id enode.ID) (*rpc.Client, error) {
 	if !ok {
 		return nil, fmt.Errorf("unknown node: %s", id)
 	}
-	return node.node.Attach()
+	return node.stack().Attach()
 }
 
 // GetNode returns the node with the given ID if it exists
@@ -170,12 +157,57 @@ type SimNode struct {
 	running      map[string]node.Lifecycle
 	client       *rpc.Client
 	registerOnce sync.Once
+	stopped      bool // Whgdtuer the node.Node was closed and needs recreating
+	logs         logBuffer
+}
+
+// newStack creates the devp2p node hosting the services of the SimNode. Nodes
+// without a data directory keep all their databases in memory, isolated from
+// other nodes of the simulation.
+func (sn *SimNode) newStack() (*node.Node, error) {
+	return node.New(&node.Config{
+		DataDir: sn.config.DataDir,
+		P2P: p2p.Config{
+			PrivateKey:      sn.config.PrivateKey,
+			MaxPeers:        math.MaxInt32,
+			NoDiscovery:     true,
+			Dialer:          sn.adapter,
+			EnableMsgEvents: sn.config.EnableMsgEvents,
+		},
+		ExternalSigner: sn.config.ExternalSigner,
+		Logger:         sn.newLogger(),
+	})
+}
+
+// newLogger creates the logger of the devp2p node, writing to the root logger
+// and capturing the output of the node. The services of the node log through
+// the root logger directly, their output is not captured.
+func (sn *SimNode) newLogger() log.Logger {
+	logger := log.New("node.id", sn.ID.String())
+	logger.SetHandler(log.MultiHandler(
+		log.FuncHandler(func(r *log.Record) error { return log.Root().GetHandler().Log(r) }),
+		log.LvlFilterHandler(logVerbosity(sn.config), log.StreamHandler(&sn.logs, log.LogfmtFormat())),
+	))
+	return logger
+}
+
+// Logs implements LogCapturer, returning the most recent log output of the
+// devp2p node.
+func (sn *SimNode) Logs() []byte {
+	return sn.logs.Bytes()
+}
+
+// stack returns the devp2p node currently hosting the services.
+func (sn *SimNode) stack() *node.Node {
+	sn.lock.RLock()
+	defer sn.lock.RUnlock()
+	return sn.node
 }
 
 // Close closes the underlaying node.Node to release
 // acquired resources.
 func (sn *SimNode) Close() error {
-	return sn.node.Close()
+	return sn.stack().Close()
 }
 
 // Addr returns the node's discovery address
@@ -202,7 +234,7 @@ func (sn *SimNode) Client() (*rpc.Client, error) {
 // ServeRPC serves RPC requests over the given connection by creating an
 // in-memory client to the node's RPC server.
 func (sn *SimNode) ServeRPC(conn *websocket.Conn) error {
-	handler, err := sn.node.RPCHandler()
+	handler, err := sn.stack().RPCHandler()
 	if err != nil {
 		return err
 	}
@@ -238,10 +270,27 @@ func (sn *SimNode) Snapshots() (map[string][]byte, error) {
 	return snapshots, nil
 }
 
-// Start registers the services and starts the underlying devp2p node
+// Start registers the services and starts the underlying devp2p node. A stopped
+// node is restarted with a fresh devp2p node and fresh service instances, which
+// retain their state across restarts only if the node has a data directory.
 func (sn *SimNode) Start(snapshots map[string][]byte) error {
+	sn.lock.Lock()
+	if sn.stopped {
+		stack, err := sn.newStack()
+		if err != nil {
+			sn.lock.Unlock()
+			return err
+		}
+		sn.node = stack
+		sn.running = make(map[string]node.Lifecycle)
+		sn.registerOnce = sync.Once{}
+		sn.stopped = false
+	}
+	stack := sn.node
+	sn.lock.Unlock()
+
 	// ensure we only register the services once in the case of the node
-	// being stopped and then started again
+	// being started again without being stopped
 	var regErr error
 	sn.registerOnce.Do(func() {
 		for _, name := range sn.config.Lifecycles {
@@ -253,7 +302,7 @@ func (sn *SimNode) Start(snapshots map[string][]byte) error {
 				ctx.Snapshot = snapshots[name]
 			}
 			serviceFunc := sn.adapter.lifecycles[name]
-			service, err := serviceFunc(ctx, sn.node)
+			service, err := serviceFunc(ctx, stack)
 			if err != nil {
 				regErr = err
 				break
@@ -269,12 +318,12 @@ func (sn *SimNode) Start(snapshots map[string][]byte) error {
 		return regErr
 	}
 
-	if err := sn.node.Start(); err != nil {
+	if err := stack.Start(); err != nil {
 		return err
 	}
 
 	// create an in-process RPC client
-	client, err := sn.node.Attach()
+	client, err := stack.Attach()
 	if err != nil {
 		return err
 	}
@@ -292,8 +341,10 @@ func (sn *SimNode) Stop() error {
 		sn.client.Close()
 		sn.client = nil
 	}
+	sn.stopped = true
+	stack := sn.node
 	sn.lock.Unlock()
-	return sn.node.Close()
+	return stack.Close()
 }
 
 // Service returns a running service by name
@@ -327,7 +378,7 @@ func (sn *SimNode) ServiceMap() map[string]node.Lifecycle {
 
 // Server returns the underlying p2p.Server
 func (sn *SimNode) Server() *p2p.Server {
-	return sn.node.Server()
+	return sn.stack().Server()
 }
 
 // SubscribeEvents subscribes the given channel to peer events from the
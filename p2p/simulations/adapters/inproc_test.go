@@ -20,9 +20,12 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"sync"
 	"testing"
 
+	"github.com/c88032111/go-gdtu/node"
 	"github.com/c88032111/go-gdtu/p2p/simulations/pipes"
 )
 
@@ -200,3 +203,70 @@ func TestNetPipeBidirections(t *testing.T) {
 		}
 	}
 }
+
+// startCounter is a service counting in its database how many times it has been
+// started.
+type startCounter struct {
+	starts uint64
+}
+
+func (c *startCounter) Start() error { return nil }
+func (c *startCounter) Stop() error  { return nil }
+
+func newStartCounter(ctx *ServiceContext, stack *node.Node) (node.Lifecycle, error) {
+	db, err := stack.OpenDatabase("counter", 0, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	c := new(startCounter)
+	if enc, err := db.Get([]byte("starts")); err == nil {
+		c.starts = binary.BigEndian.Uint64(enc)
+	}
+	c.starts++
+
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, c.starts)
+	if err := db.Put([]byte("starts"), enc); err != nil {
+		return nil, err
+	}
+	stack.RegisterLifecycle(c)
+	return c, nil
+}
+
+// Tests that simulation nodes can be restarted, keeping their databases if they
+// have a data directory and starting afresh otherwise.
+func TestSimNodeRestart(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "sim-node-restart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(datadir)
+
+	adapter := NewSimAdapter(LifecycleConstructors{"counter": newStartCounter})
+	for _, dir := range []string{datadir, ""} {
+		config := RandomNodeConfig()
+		config.Lifecycles = []string{"counter"}
+		config.DataDir = dir
+
+		simNode, err := adapter.NewNode(config)
+		if err != nil {
+			t.Fatalf("failed to create node: %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			if err := simNode.Start(nil); err != nil {
+				t.Fatalf("failed to start node: %v", err)
+			}
+			want := uint64(1)
+			if dir != "" {
+				want = uint64(i + 1)
+			}
+			counter := simNode.(*SimNode).Service("counter").(*startCounter)
+			if counter.starts != want {
+				t.Errorf("datadir %q, start %d: start count mismatch: have %d, want %d", dir, i, counter.starts, want)
+			}
+			if err := simNode.Stop(); err != nil {
+				t.Fatalf("failed to stop node: %v", err)
+			}
+		}
+	}
+}
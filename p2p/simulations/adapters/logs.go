@@ -0,0 +1,67 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package adapters
+
+import (
+	"sync"
+
+	"github.com/c88032111/go-gdtu/log"
+)
+
+// logBufferSize is the maximum amount of log output retained per node.
+const logBufferSize = 1024 * 1024
+
+// logBuffer is a bounded buffer retaining the most recent log output of a node,
+// dropping the oldest lines once full.
+type logBuffer struct {
+	buf  []byte
+	lock sync.Mutex
+}
+
+// Write implements io.Writer, appending to the buffer.
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if overflow := len(b.buf) - logBufferSize; overflow > 0 {
+		// Drop whole lines where possible to avoid serving a truncated one
+		drop := overflow
+		for drop < len(b.buf) && b.buf[drop-1] != '\n' {
+			drop++
+		}
+		b.buf = append(b.buf[:0], b.buf[drop:]...)
+	}
+	return len(p), nil
+}
+
+// Bytes returns a copy of the retained log output.
+func (b *logBuffer) Bytes() []byte {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return append([]byte(nil), b.buf...)
+}
+
+// logVerbosity returns the log verbosity configured for a node, defaulting to
+// info level if unset or invalid.
+func logVerbosity(config *NodeConfig) log.Lvl {
+	if config.LogVerbosity <= log.LvlTrace && config.LogVerbosity >= log.LvlCrit {
+		return config.LogVerbosity
+	}
+	return log.LvlInfo
+}
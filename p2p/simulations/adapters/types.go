@@ -67,6 +67,13 @@ type Node interface {
 	Snapshots() (map[string][]byte, error)
 }
 
+// LogCapturer is implemented by nodes which capture their own log output, so it
+// can be inspected while the simulation is running.
+type LogCapturer interface {
+	// Logs returns the most recent log output of the node
+	Logs() []byte
+}
+
 // NodeAdapter is used to create Nodes in a simulation network
 type NodeAdapter interface {
 	// Name returns the name of the adapter for logging purposes
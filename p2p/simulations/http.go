@@ -30,6 +30,7 @@ import (
 	"sync"
 
 	"github.com/c88032111/go-gdtu/event"
+	"github.com/c88032111/go-gdtu/metrics/prometheus"
 	"github.com/c88032111/go-gdtu/p2p"
 	"github.com/c88032111/go-gdtu/p2p/enode"
 	"github.com/c88032111/go-gdtu/p2p/simulations/adapters"
@@ -211,6 +212,24 @@ func (c *Client) RPCClient(ctx context.Context, nodeID string) (*rpc.Client, err
 	return rpc.DialWebsocket(ctx, fmt.Sprintf("%s/nodes/%s/rpc", baseURL, nodeID), "")
 }
 
+// NodeLogs returns the most recent log output captured by a node
+func (c *Client) NodeLogs(nodeID string) ([]byte, error) {
+	res, err := c.client.Get(fmt.Sprintf("%s/nodes/%s/logs", c.URL, nodeID))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	logs, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status: %s: %s", res.Status, logs)
+	}
+	return logs, nil
+}
+
 // Get performs a HTTP GET request decoding the resulting JSON response
 // into "out"
 func (c *Client) Get(path string, out interface{}) error {
@@ -286,6 +305,7 @@ func NewServer(network *Network) *Server {
 	s.GET("/mocker", s.GetMockers)
 	s.POST("/reset", s.ResetNetwork)
 	s.GET("/events", s.StreamNetworkEvents)
+	s.GET("/metrics", s.GetMetrics)
 	s.GET("/snapshot", s.CreateSnapshot)
 	s.POST("/snapshot", s.LoadSnapshot)
 	s.POST("/nodes", s.CreateNode)
@@ -296,6 +316,7 @@ func NewServer(network *Network) *Server {
 	s.POST("/nodes/:nodeid/conn/:peerid", s.ConnectNode)
 	s.DELETE("/nodes/:nodeid/conn/:peerid", s.DisconnectNode)
 	s.GET("/nodes/:nodeid/rpc", s.NodeRPC)
+	s.GET("/nodes/:nodeid/logs", s.GetNodeLogs)
 
 	return s
 }
@@ -378,8 +399,13 @@ func (s *Server) ResetNetwork(w http.ResponseWriter, req *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-// StreamNetworkEvents streams network events as a server-sent-events stream
+// StreamNetworkEvents streams network events as a server-sent-events stream,
+// or as JSON messages if the request is a WebSocket upgrade
 func (s *Server) StreamNetworkEvents(w http.ResponseWriter, req *http.Request) {
+	if websocket.IsWebSocketUpgrade(req) {
+		s.streamNetworkEventsWS(w, req)
+		return
+	}
 	events := make(chan *Event)
 	sub := s.network.events.Subscribe(events)
 	defer sub.Unsubscribe()
@@ -409,14 +435,10 @@ func (s *Server) StreamNetworkEvents(w http.ResponseWriter, req *http.Request) {
 	}
 
 	// check if filtering has been requested
-	var filters MsgFilters
-	if filterParam := req.URL.Query().Get("filter"); filterParam != "" {
-		var err error
-		filters, err = NewMsgFilters(filterParam)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
+	filters, err := eventFilters(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
@@ -428,28 +450,67 @@ func (s *Server) StreamNetworkEvents(w http.ResponseWriter, req *http.Request) {
 
 	// optionally send the existing nodes and connections
 	if req.URL.Query().Get("current") == "true" {
-		snap, err := s.network.Snapshot()
-		if err != nil {
+		if err := s.writeCurrent(writeEvent); err != nil {
 			writeErr(err)
 			return
 		}
-		for _, node := range snap.Nodes {
-			event := NewEvent(&node.Node)
+	}
+
+	clientGgdtu := req.Context().Done()
+	for {
+		select {
+		case event := <-events:
+			// only send message events which match the filters
+			if event.Msg != nil && !filters.Match(event.Msg) {
+				continue
+			}
 			if err := writeEvent(event); err != nil {
 				writeErr(err)
 				return
 			}
+		case <-clientGgdtu:
+			return
 		}
-		for _, conn := range snap.Conns {
-			event := NewEvent(&conn)
-			if err := writeEvent(event); err != nil {
-				writeErr(err)
+	}
+}
+
+// streamNetworkEventsWS streams network events over a WebSocket connection as
+// JSON messages, accepting the same options as the server-sent-events stream
+func (s *Server) streamNetworkEventsWS(w http.ResponseWriter, req *http.Request) {
+	filters, err := eventFilters(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	conn, err := wsUpgrade.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	events := make(chan *Event)
+	sub := s.network.events.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	// read from the connection to process control messages, the client is
+	// gone once reading fails
+	clientGone := make(chan struct{})
+	go func() {
+		defer close(clientGone)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
 				return
 			}
 		}
+	}()
+	writeEvent := func(event *Event) error {
+		return conn.WriteJSON(event)
+	}
+	if req.URL.Query().Get("current") == "true" {
+		if err := s.writeCurrent(writeEvent); err != nil {
+			return
+		}
 	}
-
-	clientGgdtu := req.Context().Done()
 	for {
 		select {
 		case event := <-events:
@@ -458,15 +519,42 @@ func (s *Server) StreamNetworkEvents(w http.ResponseWriter, req *http.Request) {
 				continue
 			}
 			if err := writeEvent(event); err != nil {
-				writeErr(err)
 				return
 			}
-		case <-clientGgdtu:
+		case <-clientGone:
 			return
 		}
 	}
 }
 
+// writeCurrent writes events for the existing nodes and connections
+func (s *Server) writeCurrent(writeEvent func(*Event) error) error {
+	snap, err := s.network.Snapshot()
+	if err != nil {
+		return err
+	}
+	for _, node := range snap.Nodes {
+		if err := writeEvent(NewEvent(&node.Node)); err != nil {
+			return err
+		}
+	}
+	for _, conn := range snap.Conns {
+		if err := writeEvent(NewEvent(&conn)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventFilters returns the message filters requested for an event stream, if
+// any
+func eventFilters(req *http.Request) (MsgFilters, error) {
+	if filterParam := req.URL.Query().Get("filter"); filterParam != "" {
+		return NewMsgFilters(filterParam)
+	}
+	return nil, nil
+}
+
 // NewMsgFilters constructs a collection of message filters from a URL query
 // parameter.
 //
@@ -642,6 +730,26 @@ func (s *Server) DisconnectNode(w http.ResponseWriter, req *http.Request) {
 	s.JSON(w, http.StatusOK, node.NodeInfo())
 }
 
+// GetMetrics returns the connectivity and message counts of the network in
+// prometheus format
+func (s *Server) GetMetrics(w http.ResponseWriter, req *http.Request) {
+	prometheus.Handler(s.network.Metrics()).ServeHTTP(w, req)
+}
+
+// GetNodeLogs returns the most recent log output captured by a node
+func (s *Server) GetNodeLogs(w http.ResponseWriter, req *http.Request) {
+	node := req.Context().Value("node").(*Node)
+
+	capturer, ok := node.Node.(adapters.LogCapturer)
+	if !ok {
+		http.Error(w, "node does not capture logs", http.StatusNotImplemented)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(capturer.Logs())
+}
+
 // Options responds to the OPTIONS HTTP Method by returning a 200 OK response
 // with the "Access-Control-Allow-Headers" header set to "Content-Type"
 func (s *Server) Options(w http.ResponseWriter, req *http.Request) {
@@ -20,10 +20,13 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -36,6 +39,7 @@ import (
 	"github.com/c88032111/go-gdtu/p2p/enode"
 	"github.com/c88032111/go-gdtu/p2p/simulations/adapters"
 	"github.com/c88032111/go-gdtu/rpc"
+	"github.com/gorilla/websocket"
 	"github.com/mattn/go-colorable"
 )
 
@@ -871,3 +875,78 @@ func TestMsgFilterFailBadParams(t *testing.T) {
 		t.Fatalf("expected event subscription to fail but succeeded!")
 	}
 }
+
+// TestHTTPNetworkWebSocket tests streaming the network events over a WebSocket
+// connection and retrieving the network metrics and node logs
+func TestHTTPNetworkWebSocket(t *testing.T) {
+	// start the server and a simulation network
+	network, s := testHTTPServer(t)
+	defer s.Close()
+
+	client := NewClient(s.URL)
+	nodeIDs := startTestNetwork(t, client)
+
+	// stream the current nodes and conns over a websocket
+	url := "ws" + strings.TrimPrefix(s.URL, "http") + "/events?current=true"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("error dialing event stream: %s", err)
+	}
+	defer conn.Close()
+
+	events := make(chan *Event, 100)
+	go func() {
+		for {
+			event := new(Event)
+			if err := conn.ReadJSON(event); err != nil {
+				close(events)
+				return
+			}
+			events <- event
+		}
+	}()
+	x := &expectEvents{t, events, event.NewSubscription(func(quit <-chan struct{}) error { <-quit; return nil })}
+	x.expect(
+		x.nodeEvent(nodeIDs[0], true),
+		x.nodeEvent(nodeIDs[1], true),
+		x.connEvent(nodeIDs[0], nodeIDs[1], true),
+	)
+
+	// check the metrics reflect the network and the reported messages
+	one, other := network.Nodes[0].ID(), network.Nodes[1].ID()
+	network.DidSend(one, other, "metrics", 1)
+	network.DidReceive(one, other, "metrics", 1)
+	network.DidSend(one, other, "metrics", 1)
+
+	res, err := http.Get(s.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("error getting metrics: %s", err)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("error reading metrics: %s", err)
+	}
+	for _, metric := range []string{
+		"simulations_nodes 2\n",
+		"simulations_nodes_up 2\n",
+		"simulations_conns_up 1\n",
+		"simulations_msgs_metrics_1_sent 2\n",
+		"simulations_msgs_metrics_1_received 1\n",
+		fmt.Sprintf("simulations_node_%s_peers 1\n", network.Nodes[0].Config.Name),
+		fmt.Sprintf("simulations_node_%s_msgs_sent ", network.Nodes[0].Config.Name),
+		fmt.Sprintf("simulations_node_%s_msgs_received ", network.Nodes[1].Config.Name),
+	} {
+		if !strings.Contains(string(body), metric) {
+			t.Errorf("metric %q missing from:\n%s", metric, body)
+		}
+	}
+	// check the logs of the devp2p stack are captured
+	logs, err := client.NodeLogs(nodeIDs[0])
+	if err != nil {
+		t.Fatalf("error getting node logs: %s", err)
+	}
+	if !strings.Contains(string(logs), nodeIDs[0]) {
+		t.Errorf("node logs missing node ID:\n%s", logs)
+	}
+}
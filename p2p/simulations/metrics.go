@@ -0,0 +1,148 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/c88032111/go-gdtu/metrics"
+	"github.com/c88032111/go-gdtu/p2p/enode"
+)
+
+// invalidMetricChars matches the characters not allowed in prometheus metric
+// names, which may appear in node and protocol names.
+var invalidMetricChars = regexp.MustCompile("[^a-zA-Z0-9_/]")
+
+// msgKey identifies the messages of a protocol with a given code.
+type msgKey struct {
+	proto string
+	code  uint64
+}
+
+// msgCount is the number of sent and received messages.
+type msgCount struct {
+	sent     uint64
+	received uint64
+}
+
+// msgStats counts the messages exchanged in a simulation network, both per
+// protocol message and per node, so orchestrators can watch the message rates
+// instead of consuming every message event.
+type msgStats struct {
+	msgs  map[msgKey]*msgCount
+	nodes map[enode.ID]*msgCount
+	lock  sync.Mutex
+}
+
+// record counts a message sent or received between two nodes.
+func (s *msgStats) record(sender, receiver enode.ID, proto string, code uint64, received bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.msgs == nil {
+		s.msgs = make(map[msgKey]*msgCount)
+		s.nodes = make(map[enode.ID]*msgCount)
+	}
+	key := msgKey{proto: proto, code: code}
+	if s.msgs[key] == nil {
+		s.msgs[key] = new(msgCount)
+	}
+	// Messages are counted at the node which reported them
+	node := sender
+	if received {
+		node = receiver
+	}
+	if s.nodes[node] == nil {
+		s.nodes[node] = new(msgCount)
+	}
+	if received {
+		s.msgs[key].received++
+		s.nodes[node].received++
+	} else {
+		s.msgs[key].sent++
+		s.nodes[node].sent++
+	}
+}
+
+// reset drops all the message counts.
+func (s *msgStats) reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.msgs, s.nodes = nil, nil
+}
+
+// Metrics returns a snapshot of the network's connectivity and message counts
+// as a metrics registry:
+//
+// * simulations/nodes, simulations/nodes/up         - number of all and running nodes
+// * simulations/conns/up                            - number of established connections
+// * simulations/msgs/<proto>/<code>/{sent,received} - messages per protocol message
+// * simulations/node/<name>/peers                   - number of connections of a node
+// * simulations/node/<name>/msgs/{sent,received}    - messages reported by a node
+func (net *Network) Metrics() metrics.Registry {
+	registry := metrics.NewRegistry()
+	count := func(name string, value uint64) {
+		name = invalidMetricChars.ReplaceAllString(name, "_")
+		metrics.NewRegisteredCounterForced(name, registry).Inc(int64(value))
+	}
+	net.lock.RLock()
+	var (
+		up    uint64
+		conns uint64
+		peers = make(map[enode.ID]uint64)
+	)
+	for _, node := range net.Nodes {
+		if node.Up() {
+			up++
+		}
+	}
+	for _, conn := range net.Conns {
+		if conn.Up {
+			conns++
+			peers[conn.One]++
+			peers[conn.Other]++
+		}
+	}
+	count("simulations/nodes", uint64(len(net.Nodes)))
+	count("simulations/nodes/up", up)
+	count("simulations/conns/up", conns)
+
+	names := make(map[enode.ID]string, len(net.Nodes))
+	for _, node := range net.Nodes {
+		names[node.ID()] = node.Config.Name
+		count(fmt.Sprintf("simulations/node/%s/peers", node.Config.Name), peers[node.ID()])
+	}
+	net.lock.RUnlock()
+
+	net.msgStats.lock.Lock()
+	defer net.msgStats.lock.Unlock()
+
+	for key, msgs := range net.msgStats.msgs {
+		count(fmt.Sprintf("simulations/msgs/%s/%d/sent", key.proto, key.code), msgs.sent)
+		count(fmt.Sprintf("simulations/msgs/%s/%d/received", key.proto, key.code), msgs.received)
+	}
+	for id, msgs := range net.msgStats.nodes {
+		if name, ok := names[id]; ok {
+			count(fmt.Sprintf("simulations/node/%s/msgs/sent", name), msgs.sent)
+			count(fmt.Sprintf("simulations/node/%s/msgs/received", name), msgs.received)
+		}
+	}
+	return registry
+}
@@ -64,6 +64,7 @@ type Network struct {
 
 	nodeAdapter adapters.NodeAdapter
 	events      event.Feed
+	msgStats    msgStats
 	lock        sync.RWMutex
 	quitc       chan struct{}
 }
@@ -385,6 +386,7 @@ func (net *Network) DidSend(sender, receiver enode.ID, proto string, code uint64
 		Code:     code,
 		Received: false,
 	}
+	net.msgStats.record(sender, receiver, proto, code, false)
 	net.events.Send(NewEvent(msg))
 	return nil
 }
@@ -398,6 +400,7 @@ func (net *Network) DidReceive(sender, receiver enode.ID, proto string, code uin
 		Code:     code,
 		Received: true,
 	}
+	net.msgStats.record(sender, receiver, proto, code, true)
 	net.events.Send(NewEvent(msg))
 	return nil
 }
@@ -718,6 +721,7 @@ func (net *Network) Reset() {
 
 	net.Nodes = nil
 	net.Conns = nil
+	net.msgStats.reset()
 }
 
 // Node is a wrapper around adapters.Node which is used to track the status
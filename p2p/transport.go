@@ -140,12 +140,38 @@ func (t *rlpxTransport) doProtoHandshake(our *protoHandshake) (their *protoHands
 	if err := <-werr; err != nil {
 		return nil, fmt.Errorf("write error: %v", err)
 	}
-	// If the protocol version supports Snappy encoding, upgrade immediately
-	t.conn.SetSnappy(their.Version >= snappyProtocolVersion)
+	// If the protocol version supports Snappy encoding, upgrade immediately,
+	// or to a better codec if both ends offered one
+	if their.Version >= snappyProtocolVersion {
+		t.conn.SetCodec(negotiateCodec(our.compression(), their.compression(), t.conn.Initiator()))
+	} else {
+		t.conn.SetCodec(nil)
+	}
 
 	return their, nil
 }
 
+// negotiateCodec picks the message compression codec of a connection out of
+// those offered by both ends, falling back to snappy. The preference of the
+// dialing end decides, so both ends pick the same codec.
+func negotiateCodec(ours, theirs []string, initiator bool) rlpx.Codec {
+	preferred, other := theirs, ours
+	if initiator {
+		preferred, other = ours, theirs
+	}
+	for _, name := range preferred {
+		for _, offered := range other {
+			if name != offered {
+				continue
+			}
+			if codec := rlpx.LookupCodec(name); codec != nil {
+				return codec
+			}
+		}
+	}
+	return rlpx.Snappy
+}
+
 func readProtocolHandshake(rw MsgReader) (*protoHandshake, error) {
 	msg, err := rw.ReadMsg()
 	if err != nil {
@@ -17,13 +17,17 @@
 package p2p
 
 import (
+	"crypto/ecdsa"
 	"errors"
+	"net"
 	"reflect"
 	"sync"
 	"testing"
 
 	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/p2p/rlpx"
 	"github.com/c88032111/go-gdtu/p2p/simulations/pipes"
+	"github.com/c88032111/go-gdtu/rlp"
 	"github.com/davecgh/go-spew/spew"
 )
 
@@ -104,6 +108,83 @@ func TestProtocolHandshake(t *testing.T) {
 	wg.Wait()
 }
 
+// testCodec is a compression codec for testing, leaving the messages as is.
+type testCodec string
+
+func (c testCodec) Name() string                                    { return string(c) }
+func (c testCodec) Encode(data []byte) []byte                       { return data }
+func (c testCodec) Decode(data []byte, maxSize int) ([]byte, error) { return data, nil }
+
+func TestNegotiateCodec(t *testing.T) {
+	rlpx.RegisterCodec(testCodec("test1"))
+	rlpx.RegisterCodec(testCodec("test2"))
+
+	tests := []struct {
+		ours, theirs []string
+		initiator    bool
+		want         string
+	}{
+		{nil, nil, true, "snappy"},
+		{[]string{"test1"}, nil, true, "snappy"},
+		{[]string{"test1"}, []string{"test2"}, false, "snappy"},
+		{[]string{"test1", "test2"}, []string{"test2", "test1"}, true, "test1"},
+		{[]string{"test1", "test2"}, []string{"test2", "test1"}, false, "test2"},
+		{[]string{"unknown", "test2"}, []string{"unknown", "test2"}, true, "test2"},
+	}
+	for i, tt := range tests {
+		if have := negotiateCodec(tt.ours, tt.theirs, tt.initiator).Name(); have != tt.want {
+			t.Errorf("test %d: codec mismatch: have %s, want %s", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that both ends of a connection agree on the negotiated compression.
+func TestProtocolHandshakeCompression(t *testing.T) {
+	rlpx.RegisterCodec(testCodec("test1"))
+	rlpx.RegisterCodec(testCodec("test2"))
+
+	offer := func(names ...string) []rlp.RawValue {
+		enc, _ := rlp.EncodeToBytes(names)
+		return []rlp.RawValue{enc}
+	}
+	var (
+		prv0, _ = crypto.GenerateKey()
+		hs0     = &protoHandshake{Version: 5, ID: crypto.FromECDSAPub(&prv0.PublicKey)[1:], Rest: offer("test2", "test1")}
+		prv1, _ = crypto.GenerateKey()
+		hs1     = &protoHandshake{Version: 5, ID: crypto.FromECDSAPub(&prv1.PublicKey)[1:], Rest: offer("test1", "test2")}
+
+		codecs = make([]string, 2)
+		wg     sync.WaitGroup
+	)
+	fd0, fd1, err := pipes.TCPPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := func(i int, fd net.Conn, dialDest *ecdsa.PublicKey, prv *ecdsa.PrivateKey, hs *protoHandshake) {
+		defer wg.Done()
+		defer fd.Close()
+
+		tr := newRLPX(fd, dialDest).(*rlpxTransport)
+		if _, err := tr.doEncHandshake(prv); err != nil {
+			t.Errorf("enc handshake failed: %v", err)
+			return
+		}
+		if _, err := tr.doProtoHandshake(hs); err != nil {
+			t.Errorf("proto handshake failed: %v", err)
+			return
+		}
+		codecs[i] = tr.conn.Codec().Name()
+	}
+	wg.Add(2)
+	go run(0, fd0, &prv1.PublicKey, prv0, hs0)
+	go run(1, fd1, nil, prv1, hs1)
+	wg.Wait()
+
+	if codecs[0] != "test2" || codecs[1] != "test2" {
+		t.Fatalf("negotiated codecs mismatch: have %v, want the dialer's preference test2", codecs)
+	}
+}
+
 func TestProtocolHandshakeErrors(t *testing.T) {
 	tests := []struct {
 		code uint64
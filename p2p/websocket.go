@@ -0,0 +1,146 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/c88032111/go-gdtu/p2p/enode"
+	"github.com/c88032111/go-gdtu/p2p/netutil"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsReadBuffer  = 4096
+	wsWriteBuffer = 4096
+)
+
+// wsConn tunnels a byte stream through the binary messages of a WebSocket
+// connection, so that the regular RLPx transport can run on top of it.
+type wsConn struct {
+	*websocket.Conn
+	reader io.Reader // Reader of the current inbound message
+}
+
+// Read implements net.Conn, reading the payload of the inbound binary messages
+// as a continuous stream.
+func (c *wsConn) Read(b []byte) (int, error) {
+	for {
+		if c.reader == nil {
+			kind, reader, err := c.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			if kind != websocket.BinaryMessage {
+				continue
+			}
+			c.reader = reader
+		}
+		n, err := c.reader.Read(b)
+		if err == io.EOF {
+			c.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Write implements net.Conn, sending the given bytes as a single binary message.
+func (c *wsConn) Write(b []byte) (int, error) {
+	if err := c.WriteMessage(websocket.BinaryMessage, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// SetDeadline implements net.Conn.
+func (c *wsConn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+// WebsocketHandler returns an HTTP handler which accepts peer connections
+// tunneled over WebSocket. The tunneled connections go through the same RLPx
+// handshakes and inbound connection limits as the ones accepted on the TCP
+// listener; the tunnel only replaces the underlying stream.
+func (srv *Server) WebsocketHandler() http.Handler {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  wsReadBuffer,
+		WriteBufferSize: wsWriteBuffer,
+		CheckOrigin:     func(*http.Request) bool { return true }, // Peers authenticate via RLPx
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.lock.Lock()
+		slots, quit := srv.slots, srv.quit
+		srv.lock.Unlock()
+		if slots == nil {
+			http.Error(w, "p2p server not running", http.StatusServiceUnavailable)
+			return
+		}
+		// Wait for a free handshake slot, shared with the TCP listener
+		select {
+		case <-slots:
+		case <-quit:
+			http.Error(w, "p2p server stopped", http.StatusServiceUnavailable)
+			return
+		case <-r.Context().Done():
+			return
+		}
+		defer func() { slots <- struct{}{} }()
+
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			srv.log.Debug("WebSocket upgrade failed", "addr", r.RemoteAddr, "err", err)
+			return
+		}
+		var fd net.Conn = &wsConn{Conn: ws}
+		if err := srv.checkInboundConn(fd, netutil.AddrIP(fd.RemoteAddr())); err != nil {
+			srv.log.Debug("Rejected inbound WebSocket connection", "addr", fd.RemoteAddr(), "err", err)
+			fd.Close()
+			return
+		}
+		srv.log.Trace("Accepted WebSocket connection", "addr", fd.RemoteAddr())
+		srv.SetupConn(fd, inboundConn, nil)
+	})
+}
+
+// DialWebsocket connects to the given node through a WebSocket tunnel served
+// at the given URL (ws:// or wss://), running the regular RLPx handshakes over
+// it. The node is treated like a static peer. It returns when the connection
+// has been added as a peer or the handshakes have failed.
+func (srv *Server) DialWebsocket(ctx context.Context, node *enode.Node, url string) error {
+	dialer := websocket.Dialer{
+		ReadBufferSize:   wsReadBuffer,
+		WriteBufferSize:  wsWriteBuffer,
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: defaultDialTimeout,
+	}
+	ws, _, err := dialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return err
+	}
+	return srv.SetupConn(&wsConn{Conn: ws}, staticDialedConn, node)
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c88032111/go-gdtu/internal/testlog"
+	"github.com/c88032111/go-gdtu/log"
+	"github.com/gorilla/websocket"
+)
+
+// Tests that two servers can connect through a WebSocket tunnel, running the
+// regular RLPx handshakes over it.
+func TestServerWebsocketTunnel(t *testing.T) {
+	newServer := func(name string) *Server {
+		srv := &Server{Config: Config{
+			Name:        name,
+			MaxPeers:    10,
+			NoDial:      true,
+			NoDiscovery: true,
+			PrivateKey:  newkey(),
+			Logger:      testlog.Logger(t, log.LvlTrace).New("server", name),
+		}}
+		if err := srv.Start(); err != nil {
+			t.Fatalf("could not start server %s: %v", name, err)
+		}
+		return srv
+	}
+	remote := newServer("remote")
+	defer remote.Stop()
+	local := newServer("local")
+	defer local.Stop()
+
+	http := httptest.NewServer(remote.WebsocketHandler())
+	defer http.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := local.DialWebsocket(ctx, remote.Self(), "ws"+strings.TrimPrefix(http.URL, "http")); err != nil {
+		t.Fatalf("failed to dial remote: %v", err)
+	}
+	// Wait for both ends to register the peer
+	for i := 0; ; i++ {
+		if local.PeerCount() == 1 && remote.PeerCount() == 1 {
+			break
+		}
+		if i == 50 {
+			t.Fatalf("peers not connected: local %d, remote %d", local.PeerCount(), remote.PeerCount())
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if id := local.Peers()[0].ID(); id != remote.Self().ID() {
+		t.Fatalf("connected to wrong peer: have %v, want %v", id, remote.Self().ID())
+	}
+}
+
+// Tests that WebSocket connections wait for a free handshake slot like the ones
+// accepted on the TCP listener.
+func TestServerWebsocketSlots(t *testing.T) {
+	srv := &Server{Config: Config{
+		MaxPeers:        10,
+		MaxPendingPeers: 1,
+		NoDial:          true,
+		NoDiscovery:     true,
+		PrivateKey:      newkey(),
+		Logger:          testlog.Logger(t, log.LvlTrace),
+	}}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start server: %v", err)
+	}
+	defer srv.Stop()
+
+	http := httptest.NewServer(srv.WebsocketHandler())
+	defer http.Close()
+	url := "ws" + strings.TrimPrefix(http.URL, "http")
+
+	// Occupy the only slot, the tunnel must not be set up
+	<-srv.slots
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil); err == nil {
+		t.Fatal("WebSocket connection accepted without free handshake slot")
+	}
+	// Release it, the tunnel should be set up now
+	srv.slots <- struct{}{}
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("WebSocket connection failed with free handshake slot: %v", err)
+	}
+	conn.Close()
+}
@@ -18,6 +18,7 @@ package params
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -229,16 +230,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllGdtuashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(GdtuashConfig), nil}
+	AllGdtuashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(GdtuashConfig), nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Gdtu core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(GdtuashConfig), nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(GdtuashConfig), nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -278,10 +279,82 @@ func (c *TrustedCheckpoint) Empty() bool {
 
 // CheckpointOracleConfig represents a set of checkpoint contract(which acts as an oracle)
 // config which used for light client checkpoint syncing.
+//
+// A network may rely on multiple oracle contracts, in which case a checkpoint is
+// only accepted if it's approved by a quorum of them. The signer set of each
+// oracle may be rotated from a given checkpoint section on.
 type CheckpointOracleConfig struct {
 	Address   common.Address   `json:"address"`
 	Signers   []common.Address `json:"signers"`
 	Threshold uint64           `json:"threshold"`
+
+	Rotations []CheckpointSignerRotation `json:"rotations,omitempty"` // Scheduled replacements of the signer set
+	Oracles   []*CheckpointOracleConfig  `json:"oracles,omitempty"`   // Additional oracle contracts (nested oracles are ignored)
+	Quorum    uint64                     `json:"quorum,omitempty"`    // Number of oracles approving a checkpoint (0 = all)
+}
+
+// CheckpointSignerRotation replaces the signer set and threshold of a checkpoint
+// oracle, effective for the checkpoints from the given section index on.
+type CheckpointSignerRotation struct {
+	Section   uint64           `json:"section"`
+	Signers   []common.Address `json:"signers"`
+	Threshold uint64           `json:"threshold"`
+}
+
+// SignersAt returns the signer set and the threshold of the oracle which is in
+// effect for the checkpoint of the given section.
+func (c *CheckpointOracleConfig) SignersAt(section uint64) ([]common.Address, uint64) {
+	signers, threshold := c.Signers, c.Threshold
+	for _, rotation := range c.Rotations {
+		if rotation.Section > section {
+			break
+		}
+		signers, threshold = rotation.Signers, rotation.Threshold
+	}
+	return signers, threshold
+}
+
+// AllOracles returns the configs of all oracle contracts, this one first.
+func (c *CheckpointOracleConfig) AllOracles() []*CheckpointOracleConfig {
+	return append([]*CheckpointOracleConfig{c}, c.Oracles...)
+}
+
+// RequiredQuorum returns the number of oracles which have to approve a checkpoint.
+func (c *CheckpointOracleConfig) RequiredQuorum() int {
+	if c.Quorum == 0 {
+		return len(c.Oracles) + 1
+	}
+	return int(c.Quorum)
+}
+
+// Validate checks the consistency of the oracle configs and rotation schedules.
+func (c *CheckpointOracleConfig) Validate() error {
+	if c.Quorum > uint64(len(c.Oracles)+1) {
+		return fmt.Errorf("quorum %d exceeds oracle count %d", c.Quorum, len(c.Oracles)+1)
+	}
+	seen := make(map[common.Address]bool)
+	for _, oracle := range c.AllOracles() {
+		if oracle == nil || oracle.Address == (common.Address{}) {
+			return errors.New("missing oracle address")
+		}
+		if seen[oracle.Address] {
+			return fmt.Errorf("duplicate oracle %x", oracle.Address)
+		}
+		seen[oracle.Address] = true
+
+		if uint64(len(oracle.Signers)) < oracle.Threshold {
+			return fmt.Errorf("oracle %x: invalid threshold %d for %d signers", oracle.Address, oracle.Threshold, len(oracle.Signers))
+		}
+		for i, rotation := range oracle.Rotations {
+			if i > 0 && rotation.Section <= oracle.Rotations[i-1].Section {
+				return fmt.Errorf("oracle %x: rotations not in ascending section order", oracle.Address)
+			}
+			if uint64(len(rotation.Signers)) < rotation.Threshold {
+				return fmt.Errorf("oracle %x: invalid threshold %d for %d signers at section %d", oracle.Address, rotation.Threshold, len(rotation.Signers), rotation.Section)
+			}
+		}
+	}
+	return nil
 }
 
 // ChainConfig is the core config which determines the blockchain settings.
@@ -317,6 +390,9 @@ type ChainConfig struct {
 	// Various consensus engines
 	Gdtuash *GdtuashConfig `json:"gdtuash,omitempty"`
 	Clique  *CliqueConfig  `json:"clique,omitempty"`
+
+	// Irregular state changes applied at given blocks, in ascending block order
+	IrregularChanges []*IrregularChange `json:"irregularChanges,omitempty"`
 }
 
 // GdtuashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -449,7 +525,8 @@ func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *Confi
 }
 
 // CheckConfigForkOrder checks that we don't "skip" any forks, ggdtu isn't pluggable enough
-// to guarantee that forks can be implemented in a different order than on official networks
+// to guarantee that forks can be implemented in a different order than on official networks.
+// It also validates the irregular state changes against their hashes.
 func (c *ChainConfig) CheckConfigForkOrder() error {
 	type fork struct {
 		name     string
@@ -488,7 +565,7 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
-	return nil
+	return c.checkIrregularChanges()
 }
 
 func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *ConfigCompatError {
@@ -541,7 +618,7 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
-	return nil
+	return c.checkIrregularCompatible(newcfg, head)
 }
 
 // isForkIncompatible returns true if a fork scheduled at s1 cannot be rescheduled to
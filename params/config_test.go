@@ -20,6 +20,8 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -96,3 +98,108 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckpointOracleConfig(t *testing.T) {
+	var (
+		a, b, c = common.Address{0x0a}, common.Address{0x0b}, common.Address{0x0c}
+		config  = &CheckpointOracleConfig{
+			Address:   common.Address{0x01},
+			Signers:   []common.Address{a, b},
+			Threshold: 2,
+			Rotations: []CheckpointSignerRotation{
+				{Section: 10, Signers: []common.Address{b, c}, Threshold: 1},
+				{Section: 20, Signers: []common.Address{c}, Threshold: 1},
+			},
+			Oracles: []*CheckpointOracleConfig{
+				{Address: common.Address{0x02}, Signers: []common.Address{a}, Threshold: 1},
+			},
+		}
+	)
+	tests := []struct {
+		section   uint64
+		signers   []common.Address
+		threshold uint64
+	}{
+		{0, []common.Address{a, b}, 2},
+		{9, []common.Address{a, b}, 2},
+		{10, []common.Address{b, c}, 1},
+		{19, []common.Address{b, c}, 1},
+		{20, []common.Address{c}, 1},
+		{100, []common.Address{c}, 1},
+	}
+	for _, test := range tests {
+		signers, threshold := config.SignersAt(test.section)
+		if !reflect.DeepEqual(signers, test.signers) || threshold != test.threshold {
+			t.Errorf("section %d: signers mismatch: have %x/%d, want %x/%d", test.section, signers, threshold, test.signers, test.threshold)
+		}
+	}
+	if quorum := config.RequiredQuorum(); quorum != 2 {
+		t.Errorf("default quorum mismatch: have %d, want 2", quorum)
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+	// Inconsistent configs must be rejected
+	config.Quorum = 3
+	if err := config.Validate(); err == nil {
+		t.Errorf("quorum above oracle count accepted")
+	}
+	config.Quorum = 1
+	config.Rotations[0], config.Rotations[1] = config.Rotations[1], config.Rotations[0]
+	if err := config.Validate(); err == nil {
+		t.Errorf("unordered rotations accepted")
+	}
+	config.Rotations = nil
+	config.Oracles[0].Address = config.Address
+	if err := config.Validate(); err == nil {
+		t.Errorf("duplicate oracle accepted")
+	}
+	config.Oracles[0].Address = common.Address{0x02}
+	config.Oracles[0].Threshold = 2
+	if err := config.Validate(); err == nil {
+		t.Errorf("threshold above signer count accepted")
+	}
+}
+
+func TestIrregularChanges(t *testing.T) {
+	newChange := func(number int64, amount *big.Int) *IrregularChange {
+		change := &IrregularChange{
+			Block:     big.NewInt(number),
+			Transfers: []IrregularTransfer{{From: common.Address{0xde, 0xad}, To: common.Address{0xbe, 0xef}, Amount: amount}},
+			Code:      []IrregularCode{{Address: common.Address{0xbe, 0xef}, Code: []byte{0x00}}},
+		}
+		change.Hash = change.ComputeHash()
+		return change
+	}
+	// Valid change sets pass the config checks
+	config := &ChainConfig{IrregularChanges: []*IrregularChange{newChange(10, nil), newChange(20, big.NewInt(1))}}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Fatalf("valid changes rejected: %v", err)
+	}
+	if changes := config.IrregularChangesAt(big.NewInt(20)); len(changes) != 1 || changes[0] != config.IrregularChanges[1] {
+		t.Fatalf("changes at block 20 mismatch: %v", changes)
+	}
+	// Tampered, unordered and empty change sets are rejected
+	tampered := newChange(10, nil)
+	tampered.Transfers[0].To = common.Address{0xff}
+	for i, changes := range [][]*IrregularChange{
+		{tampered},
+		{newChange(20, nil), newChange(10, nil)},
+		{newChange(10, big.NewInt(0))},
+		{{Block: big.NewInt(10)}},
+	} {
+		config := &ChainConfig{IrregularChanges: changes}
+		if err := config.CheckConfigForkOrder(); err == nil {
+			t.Errorf("test %d: invalid changes accepted", i)
+		}
+	}
+	// Altering passed changes is incompatible, future ones may be rescheduled
+	altered := &ChainConfig{IrregularChanges: []*IrregularChange{newChange(10, nil), newChange(30, big.NewInt(1))}}
+	if err := config.CheckCompatible(altered, 15); err != nil {
+		t.Fatalf("future change rescheduling rejected: %v", err)
+	}
+	err := config.CheckCompatible(altered, 25)
+	if err == nil || err.RewindTo != 19 {
+		t.Fatalf("passed change alteration mismatch: %v", err)
+	}
+}
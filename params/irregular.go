@@ -0,0 +1,149 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/common/hexutil"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/rlp"
+)
+
+// IrregularChange is a set of state modifications applied at the start of a
+// block, before its transactions, outside of the regular state transition
+// rules. It generalizes the DAO hard-fork drain, allowing the governance of a
+// derived network to schedule interventions in its chain config.
+//
+// The hash commits to the block number and the modifications. Since it is
+// shipped in the same config, it only catches accidental edits of a change set,
+// not deliberate ones. Its main use is identifying a change set when comparing
+// against the config stored in the database, so that altering an already
+// applied change is reported as a compatibility error.
+type IrregularChange struct {
+	Block     *big.Int            `json:"block"`
+	Transfers []IrregularTransfer `json:"transfers,omitempty"`
+	Code      []IrregularCode     `json:"code,omitempty"`
+	Hash      common.Hash         `json:"hash"`
+}
+
+// IrregularTransfer moves balance between two accounts. Without an amount, the
+// entire balance of the source account is moved.
+type IrregularTransfer struct {
+	From   common.Address `json:"from"`
+	To     common.Address `json:"to"`
+	Amount *big.Int       `json:"amount,omitempty"`
+}
+
+// IrregularCode replaces the code of an account.
+type IrregularCode struct {
+	Address common.Address `json:"address"`
+	Code    hexutil.Bytes  `json:"code"`
+}
+
+// ComputeHash returns the hash of the block number and the modifications of the
+// change set.
+func (c *IrregularChange) ComputeHash() common.Hash {
+	blob, err := rlp.EncodeToBytes([]interface{}{c.Block, c.Transfers, c.Code})
+	if err != nil {
+		panic(err)
+	}
+	return crypto.Keccak256Hash(blob)
+}
+
+// Validate checks that the change set is well formed and matches its hash.
+func (c *IrregularChange) Validate() error {
+	if c.Block == nil {
+		return errors.New("missing block number")
+	}
+	if len(c.Transfers) == 0 && len(c.Code) == 0 {
+		return fmt.Errorf("no modifications at block %v", c.Block)
+	}
+	for i, transfer := range c.Transfers {
+		if transfer.Amount != nil && transfer.Amount.Sign() <= 0 {
+			return fmt.Errorf("transfer %d at block %v: invalid amount %v", i, c.Block, transfer.Amount)
+		}
+	}
+	if hash := c.ComputeHash(); hash != c.Hash {
+		return fmt.Errorf("hash mismatch at block %v: have %x, want %x", c.Block, c.Hash, hash)
+	}
+	return nil
+}
+
+// IrregularChangesAt returns the irregular state changes scheduled for the given
+// block.
+func (c *ChainConfig) IrregularChangesAt(num *big.Int) []*IrregularChange {
+	var changes []*IrregularChange
+	for _, change := range c.IrregularChanges {
+		if change.Block != nil && change.Block.Cmp(num) == 0 {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// checkIrregularChanges validates the irregular state changes of the config.
+func (c *ChainConfig) checkIrregularChanges() error {
+	for i, change := range c.IrregularChanges {
+		if change == nil {
+			return fmt.Errorf("irregular state change %d: missing", i)
+		}
+		if err := change.Validate(); err != nil {
+			return fmt.Errorf("irregular state change %d: %v", i, err)
+		}
+		if i > 0 && c.IrregularChanges[i-1].Block.Cmp(change.Block) > 0 {
+			return fmt.Errorf("irregular state change %d: block %v before block %v", i, change.Block, c.IrregularChanges[i-1].Block)
+		}
+	}
+	return nil
+}
+
+// checkIrregularCompatible returns the lowest irregular state change already
+// passed by head which was added, dropped or altered by the new config.
+func (c *ChainConfig) checkIrregularCompatible(newcfg *ChainConfig, head *big.Int) *ConfigCompatError {
+	passed := func(cfg *ChainConfig) map[common.Hash]*big.Int {
+		hashes := make(map[common.Hash]*big.Int)
+		for _, change := range cfg.IrregularChanges {
+			if change != nil && isForked(change.Block, head) {
+				hashes[change.Hash] = change.Block
+			}
+		}
+		return hashes
+	}
+	var (
+		stored = passed(c)
+		fresh  = passed(newcfg)
+		lowest *big.Int
+	)
+	for hash, number := range stored {
+		if _, ok := fresh[hash]; !ok && (lowest == nil || number.Cmp(lowest) < 0) {
+			lowest = number
+		}
+	}
+	for hash, number := range fresh {
+		if _, ok := stored[hash]; !ok && (lowest == nil || number.Cmp(lowest) < 0) {
+			lowest = number
+		}
+	}
+	if lowest != nil {
+		return newCompatError("irregular state change", lowest, lowest)
+	}
+	return nil
+}
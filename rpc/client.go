@@ -78,6 +78,8 @@ type Client struct {
 	isHTTP   bool
 	services *serviceRegistry
 
+	interceptors atomic.Value // []Interceptor wrapping the calls, outermost first
+
 	idCounter uint32
 
 	// This function, if non-nil, is called when the connection is lost.
@@ -185,6 +187,19 @@ func DialContext(ctx context.Context, rawurl string) (*Client, error) {
 	}
 }
 
+// DialWithOptions creates a new RPC client, just like DialContext. The options tune
+// the transport of HTTP connections, and are ignored for other transports.
+func DialWithOptions(ctx context.Context, rawurl string, opts DialOptions) (*Client, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		return DialHTTPWithOptions(rawurl, opts)
+	}
+	return DialContext(ctx, rawurl)
+}
+
 // Client retrieves the client from the context, if any. This can be used to perform
 // 'reverse calls' in a handler Method.
 func ClientFromContext(ctx context.Context) (*Client, bool) {
@@ -248,6 +263,16 @@ func (c *Client) SupportedModules() (map[string]string, error) {
 	return result, err
 }
 
+// SupportedMethods calls the rpc_methods Method, retrieving the Methods and
+// subscriptions available on the server, grouped by namespace.
+func (c *Client) SupportedMethods() (map[string][]MethodInfo, error) {
+	var result map[string][]MethodInfo
+	ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+	defer cancel()
+	err := c.CallContext(ctx, &result, "rpc_methods")
+	return result, err
+}
+
 // Close closes the client, aborting any in-flight requests.
 func (c *Client) Close() {
 	if c.isHTTP {
@@ -288,7 +313,17 @@ func (c *Client) Call(result interface{}, Method string, args ...interface{}) er
 //
 // The result must be a pointer so that package json can unmarshal into it. You
 // can also pass nil, in which case the result is ignored.
+//
+// The call passes through the interceptors added to the client.
 func (c *Client) CallContext(ctx context.Context, result interface{}, Method string, args ...interface{}) error {
+	if interceptors, _ := c.interceptors.Load().([]Interceptor); len(interceptors) > 0 {
+		return chainInterceptors(interceptors, c.call)(ctx, result, Method, args...)
+	}
+	return c.call(ctx, result, Method, args...)
+}
+
+// call performs a JSON-RPC call, bypassing the interceptors.
+func (c *Client) call(ctx context.Context, result interface{}, Method string, args ...interface{}) error {
 	if result != nil && reflect.TypeOf(result).Kind() != reflect.Ptr {
 		return fmt.Errorf("call result parameter must be pointer or nil interface: %v", result)
 	}
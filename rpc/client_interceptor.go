@@ -0,0 +1,96 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Invoker performs a JSON-RPC call, as CallContext does.
+type Invoker func(ctx context.Context, result interface{}, method string, args ...interface{}) error
+
+// Interceptor wraps the calls made through a client, e.g. to retry failed calls,
+// collect metrics or add authentication headers. It must perform the call using
+// next, which may be done multiple times or with a derived context. Returning an
+// error without calling next aborts the call.
+//
+// Interceptors apply to CallContext and Call. Batch calls, notifications and
+// subscriptions are not intercepted.
+type Interceptor func(ctx context.Context, result interface{}, method string, args []interface{}, next Invoker) error
+
+// interceptorsLock serializes the modifications of the interceptor chains.
+var interceptorsLock sync.Mutex
+
+// AddInterceptor appends an interceptor to the chain of the client. Interceptors
+// are run in the order they were added, the first one being the outermost.
+func (c *Client) AddInterceptor(interceptor Interceptor) {
+	interceptorsLock.Lock()
+	defer interceptorsLock.Unlock()
+
+	old, _ := c.interceptors.Load().([]Interceptor)
+	chain := make([]Interceptor, len(old), len(old)+1)
+	copy(chain, old)
+	c.interceptors.Store(append(chain, interceptor))
+}
+
+// chainInterceptors wraps the invoker into the given interceptors.
+func chainInterceptors(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+			return interceptor(ctx, result, method, args, next)
+		}
+	}
+	return invoker
+}
+
+// CallHooks creates an interceptor invoking before prior to every call, and after
+// once the call returned, with its duration and error. The context returned by
+// before is used for the call. Either hook may be nil.
+func CallHooks(before func(ctx context.Context, method string, args []interface{}) context.Context, after func(ctx context.Context, method string, args []interface{}, duration time.Duration, err error)) Interceptor {
+	return func(ctx context.Context, result interface{}, method string, args []interface{}, next Invoker) error {
+		if before != nil {
+			ctx = before(ctx, method, args)
+		}
+		start := time.Now()
+		err := next(ctx, result, method, args...)
+		if after != nil {
+			after(ctx, method, args, time.Since(start), err)
+		}
+		return err
+	}
+}
+
+// headersContextKey is the context key of the HTTP headers of a call.
+type headersContextKey struct{}
+
+// NewContextWithHeaders returns a context carrying HTTP headers, which are added
+// to the requests made with it by HTTP clients, overriding those set through
+// SetHeader. It has no effect for clients using another transport.
+func NewContextWithHeaders(ctx context.Context, headers http.Header) context.Context {
+	if old, ok := ctx.Value(headersContextKey{}).(http.Header); ok {
+		merged := old.Clone()
+		for key, values := range headers {
+			merged[http.CanonicalHeaderKey(key)] = values
+		}
+		headers = merged
+	}
+	return context.WithValue(ctx, headersContextKey{}, headers)
+}
@@ -466,6 +466,94 @@ func TestClientSetHeader(t *testing.T) {
 	}
 }
 
+func TestClientInterceptors(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	// Record the order of the interceptors and retry failed calls once
+	var (
+		order []string
+		calls []string
+		errs  []error
+	)
+	client.AddInterceptor(func(ctx context.Context, result interface{}, method string, args []interface{}, next Invoker) error {
+		order = append(order, "outer")
+		if err := next(ctx, result, method, args...); err != nil {
+			return next(ctx, result, method, args...)
+		}
+		return nil
+	})
+	client.AddInterceptor(CallHooks(
+		func(ctx context.Context, method string, args []interface{}) context.Context {
+			order = append(order, "before")
+			calls = append(calls, method)
+			return ctx
+		},
+		func(ctx context.Context, method string, args []interface{}, duration time.Duration, err error) {
+			order = append(order, "after")
+			if duration <= 0 {
+				t.Errorf("non-positive call duration %v", duration)
+			}
+			errs = append(errs, err)
+		},
+	))
+	var resp echoResult
+	if err := client.Call(&resp, "test_echo", "hello", 10, &echoArgs{"world"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resp, echoResult{"hello", 10, &echoArgs{"world"}}) {
+		t.Errorf("incorrect result %#v", resp)
+	}
+	if want := []string{"outer", "before", "after"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("interceptor order mismatch: have %v, want %v", order, want)
+	}
+	// Failed calls are retried by the outer interceptor
+	if err := client.Call(nil, "test_returnError"); err == nil {
+		t.Fatal("expected error from test_returnError")
+	}
+	if want := []string{"test_echo", "test_returnError", "test_returnError"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("intercepted calls mismatch: have %v, want %v", calls, want)
+	}
+	if len(errs) != 3 || errs[0] != nil || errs[1] == nil || errs[2] == nil {
+		t.Errorf("intercepted errors mismatch: %v", errs)
+	}
+}
+
+func TestClientContextHeaders(t *testing.T) {
+	var gotHeaders []string
+	srv := newTestServer()
+	httpsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = append(gotHeaders, r.Header.Get("Authorization"))
+		srv.ServeHTTP(w, r)
+	}))
+	defer httpsrv.Close()
+	defer srv.Stop()
+
+	client, err := Dial(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	client.SetHeader("Authorization", "static")
+	if _, err := client.SupportedModules(); err != nil {
+		t.Fatal(err)
+	}
+	// Headers injected by an interceptor override the static ones
+	client.AddInterceptor(func(ctx context.Context, result interface{}, method string, args []interface{}, next Invoker) error {
+		ctx = NewContextWithHeaders(ctx, http.Header{"Authorization": []string{"Bearer " + method}})
+		return next(ctx, result, method, args...)
+	})
+	if _, err := client.SupportedModules(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"static", "Bearer rpc_modules"}; !reflect.DeepEqual(gotHeaders, want) {
+		t.Fatalf("header mismatch: have %v, want %v", gotHeaders, want)
+	}
+}
+
 func TestClientHTTP(t *testing.T) {
 	server := newTestServer()
 	defer server.Stop()
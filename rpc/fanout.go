@@ -0,0 +1,224 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/c88032111/go-gdtu/log"
+)
+
+// DefaultFanoutQueue is the number of notifications a fanout subscriber may lag
+// behind before it is evicted, if no other limit is configured.
+const DefaultFanoutQueue = 256
+
+// FanoutSource starts the delivery of the notifications of a fanout, passing
+// them to send, and returns a function stopping it. The source must deliver
+// from its own goroutine, and stop must not wait for it: it may be called from
+// within send. Notifications sent after stopping are dropped.
+type FanoutSource func(send func(data interface{})) (stop func())
+
+// Fanout delivers the notifications of a single source to any number of
+// subscriptions. Each notification is encoded once and shared between all
+// subscribers, which are served from their own bounded queues. Subscribers
+// falling behind by more than the queue size are evicted and their connection
+// is closed, so slow clients can't hold back the source or grow the memory
+// usage of the server.
+//
+// The source is started with the first subscription and stopped when the last
+// one ends.
+type Fanout struct {
+	source FanoutSource
+	queue  int
+
+	lock     sync.Mutex
+	subs     map[*fanoutSub]struct{}
+	stop     func() // stops the running source, nil if not running
+	starting bool   // whgdtuer the source is being started outside the lock
+	epoch    uint64 // incremented on every source start, drops stale sends
+}
+
+// fanoutSub is a subscriber of a fanout.
+type fanoutSub struct {
+	notifier *Notifier
+	sub      *Subscription
+	queue    chan json.RawMessage
+	evicted  chan struct{}
+}
+
+// NewFanout creates a fanout of the given source, evicting subscribers lagging
+// behind by more than queue notifications.
+func NewFanout(queue int, source FanoutSource) *Fanout {
+	if queue <= 0 {
+		queue = DefaultFanoutQueue
+	}
+	return &Fanout{
+		source: source,
+		queue:  queue,
+		subs:   make(map[*fanoutSub]struct{}),
+	}
+}
+
+// Subscribe creates a subscription to the fanout on the connection of the RPC
+// call in ctx. It is meant to be returned by subscription Methods.
+func (f *Fanout) Subscribe(ctx context.Context) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return &Subscription{}, ErrNotificationsUnsupported
+	}
+	s := &fanoutSub{
+		notifier: notifier,
+		sub:      notifier.CreateSubscription(),
+		queue:    make(chan json.RawMessage, f.queue),
+		evicted:  make(chan struct{}),
+	}
+	f.lock.Lock()
+	f.subs[s] = struct{}{}
+	start := f.stop == nil && !f.starting
+	if start {
+		f.epoch++
+		f.starting = true
+	}
+	epoch := f.epoch
+	f.lock.Unlock()
+
+	if start {
+		f.start(epoch)
+	}
+	go f.serve(s)
+	return s.sub, nil
+}
+
+// start runs the source outside the lock, as it may deliver notifications
+// synchronously while starting, and installs its stop function unless all the
+// subscribers left meanwhile.
+func (f *Fanout) start(epoch uint64) {
+	stop := f.source(func(data interface{}) { f.send(epoch, data) })
+
+	f.lock.Lock()
+	if f.epoch != epoch {
+		// Released while starting, another start may already be in progress
+		f.lock.Unlock()
+		stop()
+		return
+	}
+	f.stop, f.starting = stop, false
+	f.lock.Unlock()
+}
+
+// Send delivers a notification to all subscribers. It is an alternative to
+// notifications sent through the source, and doesn't block on slow subscribers.
+func (f *Fanout) Send(data interface{}) {
+	f.send(0, data)
+}
+
+// Subscribers returns the number of subscribers of the fanout.
+func (f *Fanout) Subscribers() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return len(f.subs)
+}
+
+// send encodes a notification and queues it to all subscribers. Notifications
+// of a stopped source, identified by an outdated epoch, are dropped.
+func (f *Fanout) send(epoch uint64, data interface{}) {
+	enc, err := json.Marshal(data)
+	if err != nil {
+		log.Warn("Failed to encode notification", "err", err)
+		return
+	}
+	f.lock.Lock()
+	if epoch != 0 && epoch != f.epoch {
+		f.lock.Unlock()
+		return
+	}
+	for s := range f.subs {
+		select {
+		case s.queue <- enc:
+		default:
+			log.Debug("Evicting slow subscriber", "id", s.sub.ID, "conn", s.notifier.h.conn.remoteAddr())
+			subscriptionEvictedMeter.Mark(1)
+			delete(f.subs, s)
+			close(s.evicted)
+		}
+	}
+	stop := f.release()
+	f.lock.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+// serve delivers the queued notifications of a subscriber until it ends.
+func (f *Fanout) serve(s *fanoutSub) {
+	for {
+		select {
+		case enc := <-s.queue:
+			if err := s.notifier.notify(s.sub.ID, enc); err != nil {
+				f.remove(s)
+				return
+			}
+		case <-s.evicted:
+			s.notifier.close()
+			return
+		case <-s.sub.Err():
+			f.remove(s)
+			return
+		case <-s.notifier.Closed():
+			f.remove(s)
+			return
+		}
+	}
+}
+
+// remove drops a subscriber, stopping the source if it was the last one.
+func (f *Fanout) remove(s *fanoutSub) {
+	f.lock.Lock()
+	delete(f.subs, s)
+	stop := f.release()
+	f.lock.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+// release returns the stop function of the source if there are no subscribers
+// left. The source must be stopped after releasing the lock, as it may be
+// blocked on sending. It must be called with the lock held.
+func (f *Fanout) release() func() {
+	if len(f.subs) > 0 {
+		return nil
+	}
+	if f.starting {
+		// Source still starting, have the starter stop it
+		f.starting = false
+		f.epoch++
+		return nil
+	}
+	if f.stop == nil {
+		return nil
+	}
+	stop := f.stop
+	f.stop = nil
+	f.epoch++
+	return stop
+}
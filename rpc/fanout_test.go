@@ -0,0 +1,217 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fanoutTestSource is a fanout source fed by the test.
+type fanoutTestSource struct {
+	lock    sync.Mutex
+	send    func(interface{})
+	started int
+	stopped int
+}
+
+func (s *fanoutTestSource) start(send func(interface{})) func() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.send = send
+	s.started++
+	return func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.stopped++
+	}
+}
+
+func (s *fanoutTestSource) feed(data interface{}) {
+	s.lock.Lock()
+	send := s.send
+	s.lock.Unlock()
+	send(data)
+}
+
+func (s *fanoutTestSource) counts() (int, int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.started, s.stopped
+}
+
+type fanoutTestService struct{ fanout *Fanout }
+
+func (s *fanoutTestService) Fanout(ctx context.Context) (*Subscription, error) {
+	return s.fanout.Subscribe(ctx)
+}
+
+func newFanoutTestServer(queue int) (*Server, *Fanout, *fanoutTestSource) {
+	source := new(fanoutTestSource)
+	fanout := NewFanout(queue, source.start)
+
+	server := NewServer()
+	server.RegisterName("fan", &fanoutTestService{fanout})
+	return server, fanout, source
+}
+
+// waitSubscribers waits until the fanout has the given number of subscribers.
+func waitSubscribers(t *testing.T, fanout *Fanout, n int) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if fanout.Subscribers() == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("subscriber count mismatch: have %d, want %d", fanout.Subscribers(), n)
+}
+
+// Tests that the notifications of a fanout are delivered to all subscribers,
+// and that the source only runs while there are subscribers.
+func TestFanout(t *testing.T) {
+	server, fanout, source := newFanoutTestServer(16)
+	defer server.Stop()
+
+	var (
+		clients = make([]*Client, 3)
+		subs    = make([]*ClientSubscription, len(clients))
+		chans   = make([]chan int, len(clients))
+	)
+	for i := range clients {
+		clients[i] = DialInProc(server)
+		defer clients[i].Close()
+
+		chans[i] = make(chan int, 16)
+		sub, err := clients[i].Subscribe(context.Background(), "fan", chans[i], "fanout")
+		if err != nil {
+			t.Fatalf("client %d: subscribe failed: %v", i, err)
+		}
+		subs[i] = sub
+	}
+	waitSubscribers(t, fanout, len(clients))
+	if started, stopped := source.counts(); started != 1 || stopped != 0 {
+		t.Fatalf("source started %d times, stopped %d times, want 1 and 0", started, stopped)
+	}
+	for n := 0; n < 10; n++ {
+		source.feed(n)
+	}
+	for i, ch := range chans {
+		for n := 0; n < 10; n++ {
+			select {
+			case v := <-ch:
+				if v != n {
+					t.Fatalf("client %d: notification mismatch: have %d, want %d", i, v, n)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("client %d: notification %d timeout", i, n)
+			}
+		}
+	}
+	for _, sub := range subs {
+		sub.Unsubscribe()
+	}
+	waitSubscribers(t, fanout, 0)
+	if started, stopped := source.counts(); started != 1 || stopped != 1 {
+		t.Fatalf("source started %d times, stopped %d times, want 1 and 1", started, stopped)
+	}
+}
+
+// Tests that subscribers not keeping up with the notifications are evicted
+// without affecting the others.
+func TestFanoutEviction(t *testing.T) {
+	server, fanout, source := newFanoutTestServer(4)
+	defer server.Stop()
+
+	// Subscribe a raw connection which stops reading after the confirmation.
+	p1, p2 := net.Pipe()
+	defer p2.Close()
+	go server.ServeCodec(NewCodec(p1), 0)
+
+	p2.SetDeadline(time.Now().Add(10 * time.Second))
+	p2.Write([]byte(`{"jsonrpc":"2.0","id":1,"Method":"fan_subscribe","params":["fanout"]}`))
+	var resp jsonrpcMessage
+	if err := json.NewDecoder(p2).Decode(&resp); err != nil || resp.Error != nil {
+		t.Fatalf("subscribe failed: %v %v", err, resp.Error)
+	}
+	// Subscribe a client keeping up with the notifications.
+	client := DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan int, 64)
+	sub, err := client.Subscribe(context.Background(), "fan", ch, "fanout")
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	defer sub.Unsubscribe()
+	waitSubscribers(t, fanout, 2)
+
+	for n := 0; n < 32; n++ {
+		source.feed(n)
+		if v := <-ch; v != n {
+			t.Fatalf("notification mismatch: have %d, want %d", v, n)
+		}
+	}
+	waitSubscribers(t, fanout, 1)
+
+	// Check that the connection of the slow subscriber was closed.
+	buf := make([]byte, 1024)
+	for {
+		if _, err := p2.Read(buf); err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				t.Fatal("slow subscriber connection not closed")
+			}
+			break
+		}
+	}
+}
+
+// Tests that a source delivering notifications while starting doesn't deadlock
+// the fanout.
+func TestFanoutSyncStart(t *testing.T) {
+	fanout := NewFanout(16, func(send func(interface{})) func() {
+		send(1)
+		return func() {}
+	})
+	server := NewServer()
+	server.RegisterName("fan", &fanoutTestService{fanout})
+	defer server.Stop()
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan int, 16)
+	sub, err := client.Subscribe(context.Background(), "fan", ch, "fanout")
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	select {
+	case v := <-ch:
+		if v != 1 {
+			t.Fatalf("notification mismatch: have %d, want 1", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("notification timeout")
+	}
+}
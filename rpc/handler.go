@@ -333,8 +333,20 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
-	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	var (
+		timeout, slow = h.reg.callLimits()
+		start         = time.Now()
+		answer        *jsonrpcMessage
+	)
+	if timeout > 0 && callb != h.unsubscribeCb {
+		answer = h.runMethodTimeout(cp.ctx, timeout, msg, callb, args)
+	} else {
+		answer = h.runMethod(cp.ctx, msg, callb, args)
+	}
+	if elapsed := time.Since(start); slow > 0 && elapsed >= slow && callb != h.unsubscribeCb {
+		slowRequestMeter.Mark(1)
+		h.log.Warn("Slow RPC call", "Method", msg.Method, "reqid", idForLog{msg.ID}, "t", elapsed)
+	}
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -393,6 +405,29 @@ func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *cal
 	return msg.response(result)
 }
 
+// runMethodTimeout runs the Method like runMethod, but cancels its context and
+// responds with a timeout error if it doesn't return in time. The Method itself
+// may keep running in the background until it notices the cancellation.
+func (h *handler) runMethodTimeout(ctx context.Context, timeout time.Duration, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan *jsonrpcMessage, 1)
+	go func() {
+		done <- h.runMethod(ctx, msg, callb, args)
+	}()
+	select {
+	case answer := <-done:
+		return answer
+	case <-ctx.Done():
+		if ctx.Err() != context.DeadlineExceeded {
+			return <-done // Connection closed, wait for the Method as before
+		}
+		timeoutRequestMeter.Mark(1)
+		return msg.errorResponse(&timeoutError{timeout})
+	}
+}
+
 // unsubscribe is the callback function for all *_unsubscribe calls.
 func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 	h.subLock.Lock()
@@ -19,16 +19,20 @@ package rpc
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"mime"
+	"net"
 	"net/http"
 	"net/url"
 	"sync"
 	"time"
+
+	"golang.org/x/net/http2"
 )
 
 const (
@@ -131,6 +135,57 @@ func DialHTTP(endpoint string) (*Client, error) {
 	return DialHTTPWithClient(endpoint, new(http.Client))
 }
 
+// DialOptions tunes the transport of clients connecting over HTTP. Clients making
+// many concurrent requests should keep enough idle connections open to the server
+// for reuse, as the default of two leads to connections being opened and closed
+// constantly, which may exhaust the ephemeral ports.
+type DialOptions struct {
+	MaxIdleConns        int           // Maximum number of idle connections kept open (0 = no limit)
+	MaxIdleConnsPerHost int           // Maximum number of idle connections kept open to the server (0 = 2)
+	IdleConnTimeout     time.Duration // Time after which idle connections are closed (0 = never)
+	KeepAlive           time.Duration // TCP keep-alive period (0 = 15s, negative disables)
+
+	// HTTP2 enables HTTP/2, which multiplexes all requests over a single connection.
+	// Plain HTTP endpoints are dialed with HTTP/2 directly (h2c), so the server must
+	// support it.
+	HTTP2 bool
+}
+
+// transport creates the HTTP transport for the given endpoint scheme.
+func (opts DialOptions) transport(scheme string) http.RoundTripper {
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: opts.KeepAlive,
+	}
+	if opts.HTTP2 && scheme == "http" {
+		return &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return dialer.Dial(network, addr)
+			},
+		}
+	}
+	return &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   opts.HTTP2,
+		MaxIdleConns:        opts.MaxIdleConns,
+		MaxIdleConnsPerHost: opts.MaxIdleConnsPerHost,
+		IdleConnTimeout:     opts.IdleConnTimeout,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+}
+
+// DialHTTPWithOptions creates a new RPC client that connects to an RPC server over
+// HTTP using a transport tuned by the given options.
+func DialHTTPWithOptions(endpoint string, opts DialOptions) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return DialHTTPWithClient(endpoint, &http.Client{Transport: opts.transport(u.Scheme)})
+}
+
 func (c *Client) sendHTTP(ctx context.Context, op *requestOp, msg interface{}) error {
 	hc := c.writeConn.(*httpConn)
 	respBody, err := hc.doRequest(ctx, msg)
@@ -187,6 +242,14 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 	hc.mu.Lock()
 	req.Header = hc.headers.Clone()
 	hc.mu.Unlock()
+	if headers, ok := ctx.Value(headersContextKey{}).(http.Header); ok {
+		for key, values := range headers {
+			req.Header.Del(key)
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+	}
 
 	// do request
 	resp, err := hc.client.Do(req)
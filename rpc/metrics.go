@@ -27,6 +27,10 @@ var (
 	successfulRequestGauge = metrics.NewRegisteredGauge("rpc/success", nil)
 	failedReqeustGauge     = metrics.NewRegisteredGauge("rpc/failure", nil)
 	rpcServingTimer        = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+	timeoutRequestMeter    = metrics.NewRegisteredMeter("rpc/timeout", nil)
+	slowRequestMeter       = metrics.NewRegisteredMeter("rpc/slow", nil)
+
+	subscriptionEvictedMeter = metrics.NewRegisteredMeter("rpc/subscriptions/evicted", nil)
 )
 
 func newRPCServingTimer(Method string, valid bool) metrics.Timer {
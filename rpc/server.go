@@ -19,7 +19,10 @@ package rpc
 import (
 	"context"
 	"io"
+	"reflect"
+	"sort"
 	"sync/atomic"
+	"time"
 
 	"github.com/c88032111/go-gdtu/log"
 	mapset "github.com/deckarep/golang-set"
@@ -66,6 +69,16 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 	return s.services.registerName(name, receiver)
 }
 
+// SetCallLimits configures the execution limits of Method calls. Calls running
+// lgdtuer than timeout have their context cancelled and a timeout error is sent
+// to the caller. Calls running lgdtuer than slow are logged, along with the
+// remote address of the caller. A zero duration disables the respective limit.
+//
+// Subscriptions are not affected by the limits.
+func (s *Server) SetCallLimits(timeout, slow time.Duration) {
+	s.services.setCallLimits(timeout, slow)
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
 // the response back using the given codec. It will block until the codec is closed or the
 // server is stopped. In either case the codec is closed.
@@ -145,3 +158,52 @@ func (s *RPCService) Modules() map[string]string {
 	}
 	return modules
 }
+
+// MethodInfo describes a single Method or subscription exposed by an RPC service.
+type MethodInfo struct {
+	Name         string `json:"name"`                   // Method name, without the namespace
+	Params       int    `json:"params"`                 // Number of accepted parameters
+	Required     int    `json:"required"`               // Number of leading mandatory parameters
+	Subscription bool   `json:"subscription,omitempty"` // Whgdtuer the Method is a subscription
+}
+
+// Methods returns the Methods and subscriptions of all the RPC services, grouped
+// by namespace and sorted by name.
+func (s *RPCService) Methods() map[string][]MethodInfo {
+	s.server.services.mu.Lock()
+	defer s.server.services.mu.Unlock()
+
+	methods := make(map[string][]MethodInfo)
+	for name, service := range s.server.services.services {
+		infos := make([]MethodInfo, 0, len(service.callbacks)+len(service.subscriptions))
+		for Method, cb := range service.callbacks {
+			infos = append(infos, cb.info(Method, false))
+		}
+		for Method, cb := range service.subscriptions {
+			infos = append(infos, cb.info(Method, true))
+		}
+		sort.Slice(infos, func(i, j int) bool {
+			if infos[i].Name != infos[j].Name {
+				return infos[i].Name < infos[j].Name
+			}
+			return !infos[i].Subscription
+		})
+		methods[name] = infos
+	}
+	return methods
+}
+
+// info assembles the metadata of a callback registered under the given name.
+// Trailing pointer arguments are optional, they are set to nil if missing.
+func (c *callback) info(name string, subscription bool) MethodInfo {
+	required := len(c.argTypes)
+	for required > 0 && c.argTypes[required-1].Kind() == reflect.Ptr {
+		required--
+	}
+	return MethodInfo{
+		Name:         name,
+		Params:       len(c.argTypes),
+		Required:     required,
+		Subscription: subscription,
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 	"net"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -150,3 +151,61 @@ func TestServerShortLivedConn(t *testing.T) {
 		}
 	}
 }
+
+func TestServerMethods(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	methods, err := client.SupportedMethods()
+	if err != nil {
+		t.Fatal("can't retrieve Methods:", err)
+	}
+	if _, ok := methods["nftest"]; !ok {
+		t.Errorf("missing namespace nftest: %v", methods)
+	}
+	want := map[string]MethodInfo{
+		"echo":         {Name: "echo", Params: 3, Required: 2},
+		"echoWithCtx":  {Name: "echoWithCtx", Params: 3, Required: 2},
+		"noArgsRets":   {Name: "noArgsRets"},
+		"subscription": {Name: "subscription", Subscription: true},
+	}
+	for _, info := range methods["test"] {
+		if expect, ok := want[info.Name]; ok {
+			if !reflect.DeepEqual(info, expect) {
+				t.Errorf("Method %s mismatch: have %+v, want %+v", info.Name, info, expect)
+			}
+			delete(want, info.Name)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing Methods: %v", want)
+	}
+}
+
+func TestServerCallTimeout(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetCallLimits(100*time.Millisecond, 0)
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	// Calls completing in time should be unaffected.
+	if err := client.Call(nil, "test_sleep", 10*time.Millisecond); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	// Calls running too long should be aborted with a timeout error.
+	start := time.Now()
+	err := client.Call(nil, "test_block")
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if rpcErr, ok := err.(Error); !ok || rpcErr.ErrorCode() != -32002 {
+		t.Fatalf("wrgdtu error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("timeout took too long: %v", elapsed)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 
 	"github.com/c88032111/go-gdtu/log"
@@ -39,6 +40,9 @@ var (
 type serviceRegistry struct {
 	mu       sync.Mutex
 	services map[string]service
+
+	callTimeout time.Duration // Maximum execution time of a Method call (0 = unlimited)
+	slowCall    time.Duration // Execution time above which Method calls are logged (0 = disabled)
 }
 
 // service represents a registered object.
@@ -92,6 +96,22 @@ func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
 	return nil
 }
 
+// setCallLimits configures the timeout and slow call threshold of Method calls.
+func (r *serviceRegistry) setCallLimits(timeout, slow time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.callTimeout, r.slowCall = timeout, slow
+}
+
+// callLimits returns the timeout and slow call threshold of Method calls.
+func (r *serviceRegistry) callLimits() (timeout, slow time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.callTimeout, r.slowCall
+}
+
 // callback returns the callback corresponding to the given RPC Method name.
 func (r *serviceRegistry) callback(Method string) *callback {
 	elem := strings.SplitN(Method, serviceMethodSeparator, 2)
@@ -125,7 +125,12 @@ func (n *Notifier) Notify(id ID, data interface{}) error {
 	if err != nil {
 		return err
 	}
+	return n.notify(id, enc)
+}
 
+// notify sends an already encoded notification to the client, allowing the
+// encoding to be shared between subscriptions.
+func (n *Notifier) notify(id ID, enc json.RawMessage) error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
@@ -147,6 +152,13 @@ func (n *Notifier) Closed() <-chan interface{} {
 	return n.h.conn.closed()
 }
 
+// close closes the RPC connection of the notifier, if it supports being closed.
+func (n *Notifier) close() {
+	if c, ok := n.h.conn.(interface{ close() }); ok {
+		c.close()
+	}
+}
+
 // takeSubscription returns the subscription (if one has been created). No subscription can
 // be created after this call.
 func (n *Notifier) takeSubscription() *Subscription {
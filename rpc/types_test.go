@@ -18,6 +18,7 @@ package rpc
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/c88032111/go-gdtu/common"
@@ -122,3 +123,27 @@ func TestBlockNumberOrHash_UnmarshalJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestBlockNumberOrHash_JSONRoundTrip(t *testing.T) {
+	tests := []BlockNumberOrHash{
+		BlockNumberOrHashWithNumber(0),
+		BlockNumberOrHashWithNumber(1),
+		BlockNumberOrHashWithNumber(LatestBlockNumber),
+		BlockNumberOrHashWithNumber(PendingBlockNumber),
+		BlockNumberOrHashWithHash(common.Hash{0x01}, false),
+		BlockNumberOrHashWithHash(common.Hash{0x02}, true),
+	}
+	for i, test := range tests {
+		enc, err := json.Marshal(test)
+		if err != nil {
+			t.Fatalf("test %d: marshal failed: %v", i, err)
+		}
+		var dec BlockNumberOrHash
+		if err := json.Unmarshal(enc, &dec); err != nil {
+			t.Fatalf("test %d: unmarshal of %s failed: %v", i, enc, err)
+		}
+		if !reflect.DeepEqual(dec, test) {
+			t.Errorf("test %d: round trip mismatch: have %v, want %v", i, dec, test)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/crypto"
+	"github.com/c88032111/go-gdtu/log"
+)
+
+// maxAuditEntrySize is the maximum size of a single audit log line accepted when
+// reading back a journal.
+const maxAuditEntrySize = 16 * 1024 * 1024
+
+// AuditConfig configures the structured audit journal of the signer.
+type AuditConfig struct {
+	Path     string // File the audit entries are appended to
+	MaxSize  uint64 // Size in bytes at which the journal is rotated (0 = never)
+	MaxFiles int    // Number of rotated journals retained (0 = all)
+	Remote   string // HTTPS endpoint the entries are also posted to (optional)
+}
+
+// AuditEntry is a single line of the structured audit journal. Every entry
+// commits to its predecessor through the Prev hash, so any entry altered or
+// removed after the fact breaks the chain of all the subsequent ones.
+type AuditEntry struct {
+	Seq    uint64          `json:"seq"`
+	Time   time.Time       `json:"time"`
+	Method string          `json:"method"`
+	Fields json.RawMessage `json:"fields"`
+	Prev   common.Hash     `json:"prev"`
+	Hash   common.Hash     `json:"hash"`
+}
+
+// sealHash returns the hash of the entry, covering all its fields but the hash
+// itself.
+func (e *AuditEntry) sealHash() common.Hash {
+	cpy := *e
+	cpy.Hash = common.Hash{}
+	blob, _ := json.Marshal(&cpy) // can't fail, all fields are plain values
+	return crypto.Keccak256Hash(blob)
+}
+
+// auditJournal is a log handler writing the audit records as hash chained JSON
+// lines, rotating the file when it grows too large.
+type auditJournal struct {
+	config AuditConfig
+	sink   *auditSink // Optional remote sink the entries are forwarded to
+
+	file *os.File
+	size uint64      // Current size of the journal file
+	seq  uint64      // Sequence number of the next entry
+	prev common.Hash // Hash of the last entry written
+	lock sync.Mutex
+}
+
+// newAuditJournal opens the audit journal at the configured path, resuming the
+// hash chain of the entries already present.
+func newAuditJournal(config AuditConfig) (*auditJournal, error) {
+	journal := &auditJournal{config: config}
+
+	// Resume the chain from the last entry, which is in the previous journal if
+	// the current one was just rotated
+	last, err := lastAuditEntry(config.Path)
+	if err != nil {
+		return nil, err
+	}
+	if last == nil {
+		if last, err = lastAuditEntry(rotatedAuditPath(config.Path, 1)); err != nil {
+			return nil, err
+		}
+	}
+	if last != nil {
+		journal.seq, journal.prev = last.Seq+1, last.Hash
+	}
+	if err := journal.open(); err != nil {
+		return nil, err
+	}
+	if config.Remote != "" {
+		if journal.sink, err = newAuditSink(config.Remote); err != nil {
+			journal.file.Close()
+			return nil, err
+		}
+	}
+	return journal, nil
+}
+
+// open opens the journal file for appending.
+func (j *auditJournal) open() error {
+	file, err := os.OpenFile(j.config.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	j.file, j.size = file, uint64(stat.Size())
+	return nil
+}
+
+// Log implements log.Handler, appending the record to the journal.
+func (j *auditJournal) Log(r *log.Record) error {
+	fields, err := json.Marshal(auditFields(r.Ctx))
+	if err != nil {
+		return err
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	entry := &AuditEntry{
+		Seq:    j.seq,
+		Time:   r.Time.UTC(),
+		Method: r.Msg,
+		Fields: fields,
+		Prev:   j.prev,
+	}
+	entry.Hash = entry.sealHash()
+
+	blob, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	blob = append(blob, '\n')
+
+	if j.config.MaxSize > 0 && j.size > 0 && j.size+uint64(len(blob)) > j.config.MaxSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+	if _, err := j.file.Write(blob); err != nil {
+		return err
+	}
+	j.size += uint64(len(blob))
+	j.seq, j.prev = j.seq+1, entry.Hash
+
+	if j.sink != nil {
+		j.sink.send(blob)
+	}
+	return nil
+}
+
+// rotate moves the current journal to the first rotated slot, shifting the older
+// ones and deleting those beyond the retention limit.
+func (j *auditJournal) rotate() error {
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	// Find the oldest rotated journal, dropping anything beyond the limit
+	oldest := 0
+	for {
+		if _, err := os.Stat(rotatedAuditPath(j.config.Path, oldest+1)); err != nil {
+			break
+		}
+		oldest++
+	}
+	for ; j.config.MaxFiles > 0 && oldest >= j.config.MaxFiles; oldest-- {
+		if err := os.Remove(rotatedAuditPath(j.config.Path, oldest)); err != nil {
+			return err
+		}
+	}
+	for i := oldest; i > 0; i-- {
+		if err := os.Rename(rotatedAuditPath(j.config.Path, i), rotatedAuditPath(j.config.Path, i+1)); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(j.config.Path, rotatedAuditPath(j.config.Path, 1)); err != nil {
+		return err
+	}
+	log.Info("Rotated audit log", "path", j.config.Path, "seq", j.seq)
+	return j.open()
+}
+
+// Close flushes the remote sink and closes the journal file.
+func (j *auditJournal) Close() error {
+	if j.sink != nil {
+		j.sink.close()
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return j.file.Close()
+}
+
+// auditFields converts the key-value pairs of a log record into a map which can
+// be encoded as JSON.
+func auditFields(ctx []interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(ctx)/2)
+	for i := 0; i+1 < len(ctx); i += 2 {
+		key, ok := ctx[i].(string)
+		if !ok {
+			key = fmt.Sprint(ctx[i])
+		}
+		if v := reflect.ValueOf(ctx[i+1]); v.Kind() == reflect.Ptr && v.IsNil() {
+			fields[key] = nil
+			continue
+		}
+		switch v := ctx[i+1].(type) {
+		case nil:
+			fields[key] = nil
+		case error:
+			fields[key] = v.Error()
+		case fmt.Stringer:
+			fields[key] = v.String()
+		case []byte:
+			fields[key] = string(v)
+		default:
+			if _, err := json.Marshal(v); err != nil {
+				fields[key] = fmt.Sprintf("%+v", v)
+			} else {
+				fields[key] = v
+			}
+		}
+	}
+	return fields
+}
+
+// rotatedAuditPath returns the path of the n-th most recently rotated journal.
+func rotatedAuditPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// lastAuditEntry returns the last entry of a journal, or nil if the journal is
+// missing or empty.
+func lastAuditEntry(path string) (*AuditEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var last []byte
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxAuditEntrySize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			last = append(last[:0], scanner.Bytes()...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if last == nil {
+		return nil, nil
+	}
+	entry := new(AuditEntry)
+	if err := json.Unmarshal(last, entry); err != nil {
+		return nil, fmt.Errorf("corrupted audit log %s: %v", path, err)
+	}
+	return entry, nil
+}
+
+// VerifyAuditLog checks the hash chain of the audit journal at the given path,
+// including all its rotated predecessors still retained, returning the number
+// of entries verified.
+func VerifyAuditLog(path string) (uint64, error) {
+	paths := []string{path}
+	for i := 1; ; i++ {
+		if _, err := os.Stat(rotatedAuditPath(path, i)); err != nil {
+			break
+		}
+		paths = append([]string{rotatedAuditPath(path, i)}, paths...)
+	}
+	var (
+		count uint64
+		last  *AuditEntry
+	)
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return count, err
+		}
+		last, err = verifyAuditEntries(file, last, func() { count++ })
+		file.Close()
+		if err != nil {
+			return count, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return count, nil
+}
+
+// verifyAuditEntries checks the hash chain of the entries read from r, linking
+// them to the given preceding entry. Unless the journal starts the chain, the
+// first entry can't be checked against its predecessor.
+func verifyAuditEntries(r io.Reader, last *AuditEntry, verified func()) (*AuditEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxAuditEntrySize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		entry := new(AuditEntry)
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if hash := entry.sealHash(); hash != entry.Hash {
+			return nil, fmt.Errorf("line %d: entry %d hash mismatch: have %x, want %x", line, entry.Seq, hash, entry.Hash)
+		}
+		switch {
+		case last != nil && entry.Seq != last.Seq+1:
+			return nil, fmt.Errorf("line %d: entry %d follows entry %d", line, entry.Seq, last.Seq)
+		case last != nil && entry.Prev != last.Hash:
+			return nil, fmt.Errorf("line %d: entry %d not chained to its predecessor", line, entry.Seq)
+		case last == nil && entry.Seq == 0 && entry.Prev != (common.Hash{}):
+			return nil, errors.New("first entry chained to unknown predecessor")
+		}
+		last = entry
+		verified()
+	}
+	return last, scanner.Err()
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/c88032111/go-gdtu/common"
+	"github.com/c88032111/go-gdtu/log"
+)
+
+// Tests that the audit journal rotates and chains its entries across rotations
+// and restarts, and that tampering with any entry is detected.
+func TestAuditJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "auditlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := AuditConfig{Path: filepath.Join(dir, "audit.log"), MaxSize: 1024, MaxFiles: 3}
+	write := func(n int) {
+		journal, err := newAuditJournal(config)
+		if err != nil {
+			t.Fatalf("failed to open journal: %v", err)
+		}
+		logger := log.New("api", "signer")
+		logger.SetHandler(journal)
+		for i := 0; i < n; i++ {
+			logger.Info("SignData", "type", "request", "addr", common.Address{0xde, 0xad}, "data", []byte("hello"), "error", errors.New("denied"), "res", (*common.Hash)(nil))
+		}
+		if err := journal.Close(); err != nil {
+			t.Fatalf("failed to close journal: %v", err)
+		}
+	}
+	write(10)
+	write(10)
+
+	count, err := VerifyAuditLog(config.Path)
+	if err != nil {
+		t.Fatalf("failed to verify journal: %v", err)
+	}
+	// Rotation drops the oldest entries, but the retained ones must still chain
+	last, err := lastAuditEntry(config.Path)
+	if err != nil || last == nil {
+		t.Fatalf("failed to read last entry: %v", err)
+	}
+	if last.Seq != 19 {
+		t.Fatalf("chain not resumed: last entry %d, want 19", last.Seq)
+	}
+	if count == 0 || count >= 20 {
+		t.Fatalf("wrong number of retained entries: %d", count)
+	}
+	if _, err := os.Stat(rotatedAuditPath(config.Path, config.MaxFiles)); err != nil {
+		t.Fatalf("rotated journal missing: %v", err)
+	}
+	if _, err := os.Stat(rotatedAuditPath(config.Path, config.MaxFiles+1)); err == nil {
+		t.Fatalf("journal retained beyond limit")
+	}
+	// Alter an entry of a rotated journal and ensure it's detected
+	path := rotatedAuditPath(config.Path, 1)
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, bytes.Replace(blob, []byte("denied"), []byte("allowed"), 1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyAuditLog(config.Path); err == nil {
+		t.Fatalf("tampered entry not detected")
+	}
+	// Drop an entry altogether and ensure the broken chain is detected
+	lines := bytes.SplitAfter(blob, []byte("\n"))
+	if err := ioutil.WriteFile(path, bytes.Join(append(lines[:1], lines[2:]...), nil), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyAuditLog(config.Path); err == nil {
+		t.Fatalf("removed entry not detected")
+	}
+}
+
+// Tests that the audit entries are posted to the remote endpoint.
+func TestAuditSink(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		received []byte
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		lock.Lock()
+		received = append(received, body...)
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	if _, err := newAuditSink("http://localhost"); err == nil {
+		t.Fatalf("plain http endpoint accepted")
+	}
+	sink, err := newAuditSink(server.URL)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	sink.client = server.Client()
+
+	var want []byte
+	for i := 0; i < 100; i++ {
+		entry := []byte(`{"seq":` + string(rune('0'+i%10)) + "}\n")
+		sink.send(entry)
+		want = append(want, entry...)
+	}
+	sink.close()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if !bytes.Equal(received, want) {
+		t.Fatalf("wrong entries posted: have %d bytes, want %d", len(received), len(want))
+	}
+}
@@ -19,6 +19,7 @@ package core
 import (
 	"context"
 	"encoding/json"
+	"io"
 
 	"github.com/c88032111/go-gdtu/common"
 	"github.com/c88032111/go-gdtu/common/hexutil"
@@ -27,8 +28,9 @@ import (
 )
 
 type AuditLogger struct {
-	log log.Logger
-	api ExternalAPI
+	log    log.Logger
+	api    ExternalAPI
+	closer io.Closer // Structured journal to close on shutdown, if any
 }
 
 func (l *AuditLogger) List(ctx context.Context) ([]common.Address, error) {
@@ -120,5 +122,27 @@ func NewAuditLogger(path string, api ExternalAPI) (*AuditLogger, error) {
 	}
 	l.SetHandler(handler)
 	l.Info("Configured", "audit log", path)
-	return &AuditLogger{l, api}, nil
+	return &AuditLogger{log: l, api: api}, nil
+}
+
+// NewStructuredAuditLogger creates an audit logger writing the entries into a
+// hash chained JSON lines journal, optionally rotated and mirrored to a remote
+// endpoint.
+func NewStructuredAuditLogger(config AuditConfig, api ExternalAPI) (*AuditLogger, error) {
+	journal, err := newAuditJournal(config)
+	if err != nil {
+		return nil, err
+	}
+	l := log.New("api", "signer")
+	l.SetHandler(journal)
+	l.Info("Configured", "audit log", config.Path, "remote", config.Remote)
+	return &AuditLogger{log: l, api: api, closer: journal}, nil
+}
+
+// Close closes the structured journal of the audit logger, if any.
+func (l *AuditLogger) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
 }
@@ -0,0 +1,151 @@
+// Copyright 2021 The go-gdtu Authors
+// This file is part of the go-gdtu library.
+//
+// The go-gdtu library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-gdtu library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// algdtu with the go-gdtu library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/c88032111/go-gdtu/log"
+)
+
+const (
+	auditSinkQueue   = 4096             // Maximum number of entries waiting to be posted
+	auditSinkBatch   = 256              // Maximum number of entries posted in one request
+	auditSinkRetries = 3                // Number of attempts to post a batch before dropping it
+	auditSinkBackoff = time.Second      // Delay between two attempts to post a batch
+	auditSinkTimeout = 10 * time.Second // Time allowed to post the queued entries on shutdown
+)
+
+// auditSink forwards the audit journal entries to a remote HTTPS endpoint, which
+// receives them batched as newline delimited JSON. The local journal remains
+// the authoritative record: entries the endpoint can't accept are dropped with
+// a warning rather than stalling the signer.
+type auditSink struct {
+	url    string
+	client *http.Client
+
+	queue chan []byte
+	quit  chan struct{}
+	done  chan struct{}
+}
+
+// newAuditSink creates a sink posting the audit entries to the given endpoint.
+func newAuditSink(endpoint string) (*auditSink, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit log endpoint: %v", err)
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("audit log endpoint %q is not https", endpoint)
+	}
+	sink := &auditSink{
+		url:    endpoint,
+		client: &http.Client{Timeout: 30 * time.Second},
+		queue:  make(chan []byte, auditSinkQueue),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go sink.loop()
+	return sink, nil
+}
+
+// send queues an entry for posting, dropping it if the endpoint can't keep up.
+func (s *auditSink) send(entry []byte) {
+	select {
+	case s.queue <- entry:
+	default:
+		log.Warn("Audit log endpoint overloaded, dropping entry", "url", s.url)
+	}
+}
+
+// close posts the entries still queued and stops the sink.
+func (s *auditSink) close() {
+	close(s.quit)
+	<-s.done
+}
+
+// loop posts the queued entries in batches until the sink is closed.
+func (s *auditSink) loop() {
+	defer close(s.done)
+
+	for {
+		select {
+		case entry := <-s.queue:
+			s.post(s.batch(entry))
+		case <-s.quit:
+			deadline := time.Now().Add(auditSinkTimeout)
+			for len(s.queue) > 0 && time.Now().Before(deadline) {
+				s.post(s.batch(<-s.queue))
+			}
+			if n := len(s.queue); n > 0 {
+				log.Warn("Dropped unsent audit log entries", "url", s.url, "count", n)
+			}
+			return
+		}
+	}
+}
+
+// batch collects the given entry and the ones queued after it into a request body.
+func (s *auditSink) batch(first []byte) []byte {
+	body := append([]byte{}, first...)
+	for i := 1; i < auditSinkBatch; i++ {
+		select {
+		case entry := <-s.queue:
+			body = append(body, entry...)
+		default:
+			return body
+		}
+	}
+	return body
+}
+
+// post delivers a batch of entries, retrying a few times on failure.
+func (s *auditSink) post(body []byte) {
+	var err error
+	for i := 0; i < auditSinkRetries; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(auditSinkBackoff):
+			case <-s.quit:
+			}
+		}
+		if err = s.postOnce(body); err == nil {
+			return
+		}
+	}
+	log.Warn("Failed to post audit log entries", "url", s.url, "err", err)
+}
+
+func (s *auditSink) postOnce(body []byte) error {
+	resp, err := s.client.Post(s.url, "application/x-ndjson", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
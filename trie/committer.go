@@ -19,6 +19,7 @@ package trie
 import (
 	"errors"
 	"fmt"
+	"runtime"
 	"sync"
 
 	"github.com/c88032111/go-gdtu/common"
@@ -30,6 +31,15 @@ import (
 // some parallelism but not incur too much memory overhead.
 const leafChanSize = 200
 
+// commitWorkers is the maximum number of subtries committed concurrently by a
+// parallel committer.
+var commitWorkers = func() int {
+	if n := runtime.NumCPU(); n < 16 {
+		return n
+	}
+	return 16
+}()
+
 // leaf represents a trie leaf value
 type leaf struct {
 	size int         // size of the rlp data (estimate)
@@ -43,12 +53,19 @@ type leaf struct {
 // some level of parallelism.
 // By 'some level' of parallelism, it's still the case that all leaves will be
 // processed sequentially - onleaf will never be called in parallel or out of order.
+//
+// A parallel committer commits the children of the topmost fullnode concurrently,
+// each on its own committer collecting the nodes instead of storing them. The
+// collected nodes are stored in child order, same as a sequential commit would.
 type committer struct {
 	tmp sliceBuffer
 	sha crypto.KeccakState
 
-	onleaf LeafCallback
-	leafCh chan *leaf
+	onleaf   LeafCallback
+	leafCh   chan *leaf
+	parallel bool    // Whgdtuer to commit the children of the topmost fullnode concurrently
+	collect  bool    // Whgdtuer to collect the nodes instead of storing them
+	leaves   []*leaf // Nodes collected for storing by the parent committer
 }
 
 // committers live in a global sync.Pool
@@ -69,6 +86,9 @@ func newCommitter() *committer {
 func returnCommitterToPool(h *committer) {
 	h.onleaf = nil
 	h.leafCh = nil
+	h.parallel = false
+	h.collect = false
+	h.leaves = nil
 	committerPool.Put(h)
 }
 
@@ -136,6 +156,9 @@ func (c *committer) commit(n node, db *Database) (node, error) {
 
 // commitChildren commits the children of the given fullnode
 func (c *committer) commitChildren(n *fullNode, db *Database) ([17]node, error) {
+	if c.parallel {
+		return c.commitChildrenParallel(n, db)
+	}
 	var children [17]node
 	for i := 0; i < 16; i++ {
 		child := n.Children[i]
@@ -165,6 +188,58 @@ func (c *committer) commitChildren(n *fullNode, db *Database) ([17]node, error)
 	return children, nil
 }
 
+// commitChildrenParallel commits the children of the given fullnode concurrently
+// on a bounded number of workers, then stores the nodes collected by them in the
+// order of the children.
+func (c *committer) commitChildrenParallel(n *fullNode, db *Database) ([17]node, error) {
+	var (
+		children [17]node
+		leaves   [16][]*leaf
+		errs     [16]error
+		pend     sync.WaitGroup
+		slots    = make(chan struct{}, commitWorkers)
+	)
+	// Only the topmost fullnode is committed in parallel
+	c.parallel = false
+
+	for i := 0; i < 16; i++ {
+		child := n.Children[i]
+		if child == nil {
+			continue
+		}
+		if hn, ok := child.(hashNode); ok {
+			children[i] = hn
+			continue
+		}
+		pend.Add(1)
+		slots <- struct{}{}
+		go func(i int, child node) {
+			defer func() {
+				<-slots
+				pend.Done()
+			}()
+			worker := newCommitter()
+			worker.collect = true
+			children[i], errs[i] = worker.commit(child, db)
+			leaves[i] = worker.leaves
+			returnCommitterToPool(worker)
+		}(i, child)
+	}
+	pend.Wait()
+
+	for i := 0; i < 16; i++ {
+		if errs[i] != nil {
+			return children, errs[i]
+		}
+		c.deliver(leaves[i], db)
+	}
+	// For the 17th child, it's possible the type is valuenode.
+	if n.Children[16] != nil {
+		children[16] = n.Children[16]
+	}
+	return children, nil
+}
+
 // store hashes the node n and if we have a storage layer specified, it writes
 // the key/value pair to it and tracks any node->child references as well as any
 // node->external trie references.
@@ -185,9 +260,17 @@ func (c *committer) store(n node, db *Database) node {
 		// The size is used for mem tracking, does not need to be exact
 		size = estimateSize(n)
 	}
-	// If we're using channel-based leaf-reporting, send to channel.
-	// The leaf channel will be active only when there an active leaf-callback
-	if c.leafCh != nil {
+	// If we're committing a subtrie in parallel, collect the node for the parent
+	// committer to deliver in order. Otherwise if we're using channel-based
+	// leaf-reporting, send to channel. The leaf channel will be active only when
+	// there an active leaf-callback
+	if c.collect {
+		c.leaves = append(c.leaves, &leaf{
+			size: size,
+			hash: common.BytesToHash(hash),
+			node: n,
+		})
+	} else if c.leafCh != nil {
 		c.leafCh <- &leaf{
 			size: size,
 			hash: common.BytesToHash(hash),
@@ -203,6 +286,27 @@ func (c *committer) store(n node, db *Database) node {
 	return hash
 }
 
+// deliver stores the nodes collected by a parallel worker, either passing them to
+// the leaf channel or inserting them into the database directly.
+func (c *committer) deliver(leaves []*leaf, db *Database) {
+	switch {
+	case len(leaves) == 0:
+		return
+	case c.collect:
+		c.leaves = append(c.leaves, leaves...)
+	case c.leafCh != nil:
+		for _, item := range leaves {
+			c.leafCh <- item
+		}
+	case db != nil:
+		db.lock.Lock()
+		for _, item := range leaves {
+			db.insert(item.hash, item.size, item.node)
+		}
+		db.lock.Unlock()
+	}
+}
+
 // commitLoop does the actual insert + leaf callback for nodes.
 func (c *committer) commitLoop(db *Database) {
 	for item := range c.leafCh {
@@ -53,6 +53,10 @@ type Trie struct {
 	// hashing operation. This number will not directly map to the number of
 	// actually unhashed nodes
 	unhashed int
+
+	// Keep track of the number of leafs which have been inserted since the last
+	// commit operation, deciding whgdtuer to commit the subtries in parallel
+	uncommitted int
 }
 
 // newFlag returns the cache flag value for a newly created node.
@@ -245,6 +249,7 @@ func (t *Trie) Update(key, value []byte) {
 // If a node was not found in the database, a MissingNodeError is returned.
 func (t *Trie) TryUpdate(key, value []byte) error {
 	t.unhashed++
+	t.uncommitted++
 	k := keybytesToHex(key)
 	if len(value) != 0 {
 		_, n, err := t.insert(t.root, nil, k, valueNode(value))
@@ -342,6 +347,7 @@ func (t *Trie) Delete(key []byte) {
 // If a node was not found in the database, a MissingNodeError is returned.
 func (t *Trie) TryDelete(key []byte) error {
 	t.unhashed++
+	t.uncommitted++
 	k := keybytesToHex(key)
 	_, n, err := t.delete(t.root, nil, k)
 	if err != nil {
@@ -524,6 +530,9 @@ func (t *Trie) Commit(onleaf LeafCallback) (root common.Hash, err error) {
 			h.commitLoop(t.db)
 		}()
 	}
+	// If the number of changes is below 100, we let one thread handle it
+	h.parallel = t.uncommitted >= 100
+
 	var newRoot hashNode
 	newRoot, err = h.Commit(t.root, t.db)
 	if onleaf != nil {
@@ -538,6 +547,7 @@ func (t *Trie) Commit(onleaf LeafCallback) (root common.Hash, err error) {
 		return common.Hash{}, err
 	}
 	t.root = newRoot
+	t.uncommitted = 0
 	return rootHash, nil
 }
 
@@ -558,4 +568,5 @@ func (t *Trie) hashRoot() (node, node, error) {
 func (t *Trie) Reset() {
 	t.root = nil
 	t.unhashed = 0
+	t.uncommitted = 0
 }
@@ -671,6 +671,49 @@ func makeAccounts(size int) (addresses [][20]byte, accounts [][]byte) {
 }
 
 // spgdtueDb is a dummy db backend which accumulates writes in a spgdtue
+// Tests that committing the subtries in parallel produces the same root, and
+// stores the nodes and invokes the leaf callback in the same order as a serial
+// commit.
+func TestParallelCommit(t *testing.T) {
+	addresses, accounts := makeAccounts(2000)
+
+	commit := func(parallel bool) (common.Hash, []common.Hash, []common.Hash) {
+		db := NewDatabase(memorydb.New())
+		trie, _ := New(common.Hash{}, db)
+		for i := 0; i < len(addresses); i++ {
+			trie.Update(crypto.Keccak256(addresses[i][:]), accounts[i])
+		}
+		if !parallel {
+			trie.uncommitted = 0
+		}
+		var leaves []common.Hash
+		root, err := trie.Commit(func(path []byte, leaf []byte, parent common.Hash) error {
+			leaves = append(leaves, parent)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to commit trie: %v", err)
+		}
+		var nodes []common.Hash
+		for hash := db.oldest; hash != (common.Hash{}); hash = db.dirties[hash].flushNext {
+			nodes = append(nodes, hash)
+		}
+		return root, nodes, leaves
+	}
+	serialRoot, serialNodes, serialLeaves := commit(false)
+	parallelRoot, parallelNodes, parallelLeaves := commit(true)
+
+	if serialRoot != parallelRoot {
+		t.Fatalf("root mismatch: serial %x, parallel %x", serialRoot, parallelRoot)
+	}
+	if !reflect.DeepEqual(serialNodes, parallelNodes) {
+		t.Fatalf("node insertion order mismatch")
+	}
+	if !reflect.DeepEqual(serialLeaves, parallelLeaves) {
+		t.Fatalf("leaf callback order mismatch")
+	}
+}
+
 type spgdtueDb struct {
 	spgdtue hash.Hash
 	id      string